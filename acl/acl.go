@@ -68,6 +68,11 @@ type ACL struct {
 	variables         *iradix.Tree[capabilitySet]
 	wildcardVariables *iradix.Tree[capabilitySet]
 
+	// identityClaims map namespaces to the set of the extra workload identity
+	// claims allowed in the namespace.
+	identityClaims         *iradix.Tree[capabilitySet]
+	wildcardIdentityClaims *iradix.Tree[capabilitySet]
+
 	// The attributes below store the policy value for policies that don't have
 	// fine-grained capabilities.
 	agent    string
@@ -123,6 +128,9 @@ func NewACL(management bool, policies []*Policy) (*ACL, error) {
 	svTxn := iradix.New[capabilitySet]().Txn()
 	wsvTxn := iradix.New[capabilitySet]().Txn()
 
+	icTxn := iradix.New[capabilitySet]().Txn()
+	wicTxn := iradix.New[capabilitySet]().Txn()
+
 	for _, policy := range policies {
 	NAMESPACES:
 		for _, ns := range policy.Namespaces {
@@ -177,6 +185,21 @@ func NewACL(management bool, policies []*Policy) (*ACL, error) {
 				}
 			}
 
+			if len(ns.IdentityClaims) > 0 {
+				txn := icTxn
+				if globDefinition {
+					txn = wicTxn
+				}
+				claims, ok := txn.Get([]byte(ns.Name))
+				if !ok {
+					claims = make(capabilitySet)
+					txn.Insert([]byte(ns.Name), claims)
+				}
+				for _, claim := range ns.IdentityClaims {
+					claims.Set(claim)
+				}
+			}
+
 			// Deny always takes precedence
 			if capabilities.Check(NamespaceCapabilityDeny) {
 				continue NAMESPACES
@@ -304,6 +327,9 @@ func NewACL(management bool, policies []*Policy) (*ACL, error) {
 	acl.variables = svTxn.Commit()
 	acl.wildcardVariables = wsvTxn.Commit()
 
+	acl.identityClaims = icTxn.Commit()
+	acl.wildcardIdentityClaims = wicTxn.Commit()
+
 	acl.client = PolicyDeny
 	acl.server = PolicyDeny
 	acl.isLeader = false
@@ -532,11 +558,37 @@ func (a *ACL) AllowVariableOperation(ns, path, op string, claim *ACLClaim) bool
 	return capabilities.Check(op)
 }
 
+// AllowIdentityClaim checks if the workload identities of the jobs in the
+// namespace may set the extra claim.
+func (a *ACL) AllowIdentityClaim(ns, claim string) bool {
+	if a == nil {
+		return false
+	}
+
+	// Hot path management tokens or when ACLs are disabled
+	if a.aclsDisabled || a.management {
+		return true
+	}
+
+	claims, ok := a.identityClaims.Get([]byte(ns))
+	if !ok {
+		claims, ok = a.findClosestMatchingGlob(a.wildcardIdentityClaims, ns)
+		if !ok {
+			return false
+		}
+	}
+	return claims.Check(claim) || claims.Check("*")
+}
+
 type ACLClaim struct {
 	Namespace string
 	Job       string
 	Group     string
 	Task      string
+
+	// WriteVariables is set when the workload policy lets workloads write
+	// the variables of their job.
+	WriteVariables bool
 }
 
 // AllowVariableSearch is a very loose check that the token has *any* access to
@@ -649,6 +701,8 @@ func (a *ACL) matchingHostVolumeCapabilitySet(name string) (capabilitySet, bool)
 
 var workloadVariablesCapabilitySet = capabilitySet{"read": struct{}{}, "list": struct{}{}}
 
+var workloadVariablesWriteCapabilitySet = capabilitySet{"read": struct{}{}, "list": struct{}{}, "write": struct{}{}}
+
 // matchingVariablesCapabilitySet looks for a capabilitySet in the following order:
 // - matching the namespace and path from a policy
 // - automatic access based on the claim
@@ -664,10 +718,14 @@ func (a *ACL) matchingVariablesCapabilitySet(ns, path string, claim *ACLClaim) (
 	}
 	if claim != nil && ns == claim.Namespace {
 		switch path {
-		case "nomad/jobs",
-			fmt.Sprintf("nomad/jobs/%s", claim.Job),
+		case "nomad/jobs":
+			return workloadVariablesCapabilitySet, true
+		case fmt.Sprintf("nomad/jobs/%s", claim.Job),
 			fmt.Sprintf("nomad/jobs/%s/%s", claim.Job, claim.Group),
 			fmt.Sprintf("nomad/jobs/%s/%s/%s", claim.Job, claim.Group, claim.Task):
+			if claim.WriteVariables {
+				return workloadVariablesWriteCapabilitySet, true
+			}
 			return workloadVariablesCapabilitySet, true
 		default:
 		}
@@ -900,6 +900,38 @@ func TestVariablesMatching(t *testing.T) {
 			claim: &ACLClaim{Namespace: "ns", Job: "example", Group: "foo", Task: "bar"},
 			allow: true,
 		},
+		{
+			name: "claim write without workload write",
+			policy: `namespace "ns" {
+					variables { path "other" { capabilities = ["read"] }}}`,
+			ns:    "ns",
+			path:  "nomad/jobs/example/foo",
+			op:    "write",
+			claim: &ACLClaim{Namespace: "ns", Job: "example", Group: "foo", Task: "bar"},
+			allow: false,
+		},
+		{
+			name: "claim write with workload write",
+			policy: `namespace "ns" {
+					variables { path "other" { capabilities = ["read"] }}}`,
+			ns:   "ns",
+			path: "nomad/jobs/example/foo",
+			op:   "write",
+			claim: &ACLClaim{Namespace: "ns", Job: "example", Group: "foo", Task: "bar",
+				WriteVariables: true},
+			allow: true,
+		},
+		{
+			name: "claim write jobs root with workload write",
+			policy: `namespace "ns" {
+					variables { path "other" { capabilities = ["read"] }}}`,
+			ns:   "ns",
+			path: "nomad/jobs",
+			op:   "write",
+			claim: &ACLClaim{Namespace: "ns", Job: "example", Group: "foo", Task: "bar",
+				WriteVariables: true},
+			allow: false,
+		},
 	}
 
 	for _, tc := range tests {
@@ -1031,6 +1063,39 @@ func TestACL_matchingCapabilitySet_difference(t *testing.T) {
 
 }
 
+func TestAllowIdentityClaim(t *testing.T) {
+	ci.Parallel(t)
+
+	policy, err := Parse(`
+namespace "default" {
+  policy          = "write"
+  identity_claims = ["team"]
+}
+namespace "default" {
+  identity_claims = ["cost-center"]
+}
+namespace "prod-*" {
+  identity_claims = ["*"]
+}
+namespace "dev" {
+  policy = "write"
+}
+`)
+	must.NoError(t, err)
+	aclObj, err := NewACL(false, []*Policy{policy})
+	must.NoError(t, err)
+
+	must.True(t, aclObj.AllowIdentityClaim("default", "team"))
+	must.True(t, aclObj.AllowIdentityClaim("default", "cost-center"))
+	must.False(t, aclObj.AllowIdentityClaim("default", "owner"))
+	must.True(t, aclObj.AllowIdentityClaim("prod-api", "owner"))
+	must.False(t, aclObj.AllowIdentityClaim("dev", "team"))
+	must.False(t, aclObj.AllowIdentityClaim("other", "team"))
+
+	must.True(t, ManagementACL.AllowIdentityClaim("dev", "team"))
+	must.True(t, ACLsDisabledACL.AllowIdentityClaim("dev", "team"))
+}
+
 func TestAgentDebug(t *testing.T) {
 	ci.Parallel(t)
 
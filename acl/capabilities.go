@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package acl
+
+import (
+	"sort"
+
+	iradix "github.com/hashicorp/go-immutable-radix/v2"
+)
+
+// Capabilities summarizes the effective capabilities of an ACL object, for
+// operators checking what their token or workload identity is allowed to do.
+type Capabilities struct {
+	// Management is true for management tokens, which are allowed to do
+	// anything.
+	Management bool
+
+	// Namespaces, NodePools, and HostVolumes map names and glob patterns to
+	// their sorted capabilities.
+	Namespaces  map[string][]string `json:",omitempty"`
+	NodePools   map[string][]string `json:",omitempty"`
+	HostVolumes map[string][]string `json:",omitempty"`
+
+	// Agent, Node, Operator, Quota, and Plugin are the policy dispositions of
+	// the policies without fine-grained capabilities, such as "read".
+	Agent    string `json:",omitempty"`
+	Node     string `json:",omitempty"`
+	Operator string `json:",omitempty"`
+	Quota    string `json:",omitempty"`
+	Plugin   string `json:",omitempty"`
+}
+
+// Capabilities returns the effective capabilities of the ACL object.
+func (a *ACL) Capabilities() *Capabilities {
+	if a.management {
+		return &Capabilities{Management: true}
+	}
+
+	return &Capabilities{
+		Namespaces:  capabilitySets(a.namespaces, a.wildcardNamespaces),
+		NodePools:   capabilitySets(a.nodePools, a.wildcardNodePools),
+		HostVolumes: capabilitySets(a.hostVolumes, a.wildcardHostVolumes),
+		Agent:       a.agent,
+		Node:        a.node,
+		Operator:    a.operator,
+		Quota:       a.quota,
+		Plugin:      a.plugin,
+	}
+}
+
+// capabilitySets returns the sorted capabilities of the concrete and glob
+// entries of the trees.
+func capabilitySets(trees ...*iradix.Tree[capabilitySet]) map[string][]string {
+	out := make(map[string][]string)
+	for _, tree := range trees {
+		if tree == nil {
+			continue
+		}
+		tree.Root().Walk(func(k []byte, v capabilitySet) bool {
+			caps := make([]string, 0, len(v))
+			for c := range v {
+				caps = append(caps, c)
+			}
+			sort.Strings(caps)
+			out[string(k)] = caps
+			return false
+		})
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package acl
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestACL_Capabilities(t *testing.T) {
+	ci.Parallel(t)
+
+	must.Eq(t, &Capabilities{Management: true}, ManagementACL.Capabilities())
+
+	p, err := Parse(`
+namespace "default" {
+  capabilities = ["list-jobs", "read-job"]
+}
+namespace "prod-*" {
+  policy = "read"
+}
+node_pool "gpu" {
+  policy = "write"
+}
+node {
+  policy = "read"
+}
+operator {
+  policy = "deny"
+}
+`)
+	must.NoError(t, err)
+	aclObj, err := NewACL(false, []*Policy{p})
+	must.NoError(t, err)
+
+	caps := aclObj.Capabilities()
+	must.False(t, caps.Management)
+	must.Eq(t, []string{"list-jobs", "read-job"}, caps.Namespaces["default"])
+	must.SliceContains(t, caps.Namespaces["prod-*"], NamespaceCapabilityReadJob)
+	must.SliceContains(t, caps.NodePools["gpu"], NodePoolCapabilityWrite)
+	must.Nil(t, caps.HostVolumes)
+	must.Eq(t, PolicyRead, caps.Node)
+	must.Eq(t, PolicyDeny, caps.Operator)
+	must.Eq(t, "", caps.Agent)
+}
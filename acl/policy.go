@@ -134,6 +134,11 @@ type NamespacePolicy struct {
 	Policy       string
 	Capabilities []string
 	Variables    *VariablesPolicy `hcl:"variables"`
+
+	// IdentityClaims is the allowlist of the extra claims that the workload
+	// identities of the jobs submitted in the namespace may set. "*" allows
+	// any claim.
+	IdentityClaims []string `hcl:"identity_claims"`
 }
 
 // NodePoolPolicy is the policfy for a specific node pool.
@@ -407,6 +412,12 @@ func Parse(rules string) (*Policy, error) {
 
 		}
 
+		for _, claim := range ns.IdentityClaims {
+			if claim == "" {
+				return nil, fmt.Errorf("Invalid empty identity claim in namespace %s", ns.Name)
+			}
+		}
+
 	}
 
 	for _, np := range p.NodePools {
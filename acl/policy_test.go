@@ -861,6 +861,31 @@ func TestParse(t *testing.T) {
 			"Invalid plugin policy",
 			nil,
 		},
+		{
+			`
+			namespace "default" {
+				identity_claims = ["team", "cost-center"]
+			}
+			`,
+			"",
+			&Policy{
+				Namespaces: []*NamespacePolicy{
+					{
+						Name:           "default",
+						IdentityClaims: []string{"team", "cost-center"},
+					},
+				},
+			},
+		},
+		{
+			`
+			namespace "default" {
+				identity_claims = [""]
+			}
+			`,
+			"Invalid empty identity claim in namespace default",
+			nil,
+		},
 	}
 
 	for idx, tc := range tcases {
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package acl
+
+import (
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+const (
+	// WorkloadServicesJob lets workloads list and read the services
+	// registered by their own job.
+	WorkloadServicesJob = "job"
+
+	// WorkloadServicesNamespace lets workloads list and read all the services
+	// registered in any namespace.
+	WorkloadServicesNamespace = "namespace"
+)
+
+// WorkloadPolicy is the implicit policy of workload identities. It grants the
+// tasks of a job access to the objects of their own job, without policies
+// attached to the job, so jobs can introspect themselves. It's configured
+// for the whole cluster in the acl block of the servers.
+type WorkloadPolicy struct {
+	// Alloc is the access of workloads to their own allocation, either
+	// "read" or "deny".
+	Alloc string `hcl:"alloc"`
+
+	// Services is the access of workloads to service registrations, either
+	// "job", "namespace" or "deny".
+	Services string `hcl:"services"`
+
+	// Variables is the access of workloads to the variables of their job,
+	// under the nomad/jobs/<job> path, either "read" or "write". Workloads
+	// can always read the variables of their job.
+	Variables string `hcl:"variables"`
+}
+
+// DefaultWorkloadPolicy returns the workload policy used when the servers
+// don't configure one: workloads can read their allocation, list the
+// services of their job and write the variables of their job.
+func DefaultWorkloadPolicy() *WorkloadPolicy {
+	return &WorkloadPolicy{
+		Alloc:     PolicyRead,
+		Services:  WorkloadServicesJob,
+		Variables: PolicyWrite,
+	}
+}
+
+func (p *WorkloadPolicy) Copy() *WorkloadPolicy {
+	if p == nil {
+		return nil
+	}
+	np := *p
+	return &np
+}
+
+// Merge returns a new workload policy with the fields set in b overriding
+// the fields of p.
+func (p *WorkloadPolicy) Merge(b *WorkloadPolicy) *WorkloadPolicy {
+	if p == nil {
+		return b.Copy()
+	}
+	result := *p
+	if b == nil {
+		return &result
+	}
+	if b.Alloc != "" {
+		result.Alloc = b.Alloc
+	}
+	if b.Services != "" {
+		result.Services = b.Services
+	}
+	if b.Variables != "" {
+		result.Variables = b.Variables
+	}
+	return &result
+}
+
+// Validate returns an error if a field of the workload policy has an
+// unsupported value. Empty fields are valid and left to their default.
+func (p *WorkloadPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	var mErr *multierror.Error
+	switch p.Alloc {
+	case "", PolicyRead, PolicyDeny:
+	default:
+		mErr = multierror.Append(mErr, fmt.Errorf("invalid alloc value %q: must be %q or %q",
+			p.Alloc, PolicyRead, PolicyDeny))
+	}
+	switch p.Services {
+	case "", WorkloadServicesJob, WorkloadServicesNamespace, PolicyDeny:
+	default:
+		mErr = multierror.Append(mErr, fmt.Errorf("invalid services value %q: must be %q, %q or %q",
+			p.Services, WorkloadServicesJob, WorkloadServicesNamespace, PolicyDeny))
+	}
+	switch p.Variables {
+	case "", PolicyRead, PolicyWrite:
+	default:
+		mErr = multierror.Append(mErr, fmt.Errorf("invalid variables value %q: must be %q or %q",
+			p.Variables, PolicyRead, PolicyWrite))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// AllowAllocRead returns true if workloads can read their own allocation.
+func (p *WorkloadPolicy) AllowAllocRead() bool {
+	return p != nil && p.Alloc == PolicyRead
+}
+
+// AllowVariablesWrite returns true if workloads can write the variables of
+// their job.
+func (p *WorkloadPolicy) AllowVariablesWrite() bool {
+	return p != nil && p.Variables == PolicyWrite
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package acl
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestWorkloadPolicy_Merge(t *testing.T) {
+	ci.Parallel(t)
+
+	p := DefaultWorkloadPolicy()
+	must.Eq(t, p, p.Merge(nil))
+	must.Eq(t, p, (*WorkloadPolicy)(nil).Merge(p))
+
+	merged := p.Merge(&WorkloadPolicy{Services: WorkloadServicesNamespace})
+	must.Eq(t, &WorkloadPolicy{
+		Alloc:     PolicyRead,
+		Services:  WorkloadServicesNamespace,
+		Variables: PolicyWrite,
+	}, merged)
+	must.Eq(t, WorkloadServicesJob, p.Services)
+}
+
+func TestWorkloadPolicy_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	must.NoError(t, DefaultWorkloadPolicy().Validate())
+	must.NoError(t, (*WorkloadPolicy)(nil).Validate())
+	must.NoError(t, (&WorkloadPolicy{}).Validate())
+	must.NoError(t, (&WorkloadPolicy{
+		Alloc:     PolicyDeny,
+		Services:  PolicyDeny,
+		Variables: PolicyRead,
+	}).Validate())
+
+	err := (&WorkloadPolicy{
+		Alloc:     PolicyWrite,
+		Services:  "all",
+		Variables: PolicyDeny,
+	}).Validate()
+	must.ErrorContains(t, err, `invalid alloc value "write"`)
+	must.ErrorContains(t, err, `invalid services value "all"`)
+	must.ErrorContains(t, err, `invalid variables value "deny"`)
+}
+
+func TestWorkloadPolicy_Allow(t *testing.T) {
+	ci.Parallel(t)
+
+	p := DefaultWorkloadPolicy()
+	must.True(t, p.AllowAllocRead())
+	must.True(t, p.AllowVariablesWrite())
+
+	p = &WorkloadPolicy{Alloc: PolicyDeny, Variables: PolicyRead}
+	must.False(t, p.AllowAllocRead())
+	must.False(t, p.AllowVariablesWrite())
+
+	p = nil
+	must.False(t, p.AllowAllocRead())
+	must.False(t, p.AllowVariablesWrite())
+}
@@ -116,6 +116,46 @@ func (a *ACLTokens) List(q *QueryOptions) ([]*ACLTokenListStub, *QueryMeta, erro
 	return resp, qm, nil
 }
 
+// ListExpiring is used to list the tokens which are not expired yet, but
+// expire within the given duration.
+func (a *ACLTokens) ListExpiring(within time.Duration, q *QueryOptions) ([]*ACLTokenListStub, *QueryMeta, error) {
+	if within <= 0 {
+		return nil, nil, errors.New("expiring within duration must be positive")
+	}
+	if q == nil {
+		q = &QueryOptions{}
+	}
+	if q.Params == nil {
+		q.Params = make(map[string]string)
+	}
+	q.Params["expiring_within"] = within.String()
+
+	var resp []*ACLTokenListStub
+	qm, err := a.client.query("/v1/acl/tokens", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// Renew is used to renew a token created by an auth method, by providing a
+// new login token for this auth method. Tokens which already expired can be
+// renewed during the token renewal grace period of their auth method.
+func (a *ACLTokens) Renew(req *ACLTokenRenewRequest, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	if req.SecretID == "" {
+		return nil, nil, errors.New("missing secret ID")
+	}
+	if req.LoginToken == "" {
+		return nil, nil, errors.New("missing login token")
+	}
+	var resp ACLToken
+	wm, err := a.client.put("/v1/acl/token/renew", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
 // Create is used to create a token
 func (a *ACLTokens) Create(token *ACLToken, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
 	if token.AccessorID != "" {
@@ -178,10 +218,33 @@ func (a *ACLTokens) Self(q *QueryOptions) (*ACLToken, *QueryMeta, error) {
 	return &resp, wm, nil
 }
 
+// WhoAmI describes the identity of the request, along with its ACL policies
+// and effective capabilities. Unlike Self, it also works for workload
+// identities and when ACLs are disabled.
+func (a *ACLTokens) WhoAmI(q *QueryOptions) (*ACLWhoAmI, *QueryMeta, error) {
+	var resp ACLWhoAmI
+	qm, err := a.client.query("/v1/acl/whoami", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // UpsertOneTimeToken is used to create a one-time token
 func (a *ACLTokens) UpsertOneTimeToken(q *WriteOptions) (*OneTimeToken, *WriteMeta, error) {
+	return a.UpsertOneTimeTokenWithTTL(0, q)
+}
+
+// UpsertOneTimeTokenWithTTL is used to create a one-time token that expires
+// after the given TTL. A zero TTL uses the server default of 10 minutes, which
+// is also the maximum.
+func (a *ACLTokens) UpsertOneTimeTokenWithTTL(ttl time.Duration, q *WriteOptions) (*OneTimeToken, *WriteMeta, error) {
+	var req any
+	if ttl != 0 {
+		req = &OneTimeTokenUpsertRequest{TTL: ttl}
+	}
 	var resp *OneTimeTokenUpsertResponse
-	wm, err := a.client.put("/v1/acl/token/onetime", nil, &resp, q)
+	wm, err := a.client.put("/v1/acl/token/onetime", req, &resp, q)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -447,6 +510,21 @@ func (a *ACLBindingRules) Get(bindingRuleID string, q *QueryOptions) (*ACLBindin
 	return &resp, qm, nil
 }
 
+// Test is used to evaluate a sample claim set against the binding rules of
+// an auth method. It returns the roles and policies an ACL token created by
+// logging in with these claims would be granted, without creating it.
+func (a *ACLBindingRules) Test(req *ACLBindingRulesTestRequest, q *QueryOptions) (*ACLBindingRulesTestResponse, *QueryMeta, error) {
+	if req.AuthMethodName == "" {
+		return nil, nil, errors.New("missing auth method name")
+	}
+	var resp ACLBindingRulesTestResponse
+	qm, err := a.client.putQuery("/v1/acl/binding-rules/test", req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // ACLOIDC is used to query the ACL OIDC endpoints.
 //
 // Deprecated: ACLOIDC is deprecated, use ACLAuth instead.
@@ -558,6 +636,10 @@ type ACLToken struct {
 	// creation. This is a string version of a time.Duration like "2m".
 	ExpirationTTL time.Duration `json:",omitempty"`
 
+	// AuthMethod is the name of the auth method which created the token by
+	// logging in. Such tokens can be renewed with ACLTokens.Renew.
+	AuthMethod string `json:",omitempty"`
+
 	CreateIndex uint64
 	ModifyIndex uint64
 }
@@ -623,6 +705,59 @@ func (a *ACLToken) UnmarshalJSON(data []byte) (err error) {
 	return nil
 }
 
+// ACLWhoAmI is the response of the ACLTokens.WhoAmI API.
+type ACLWhoAmI struct {
+	// Identity is the authenticated identity of the request. The SecretID of
+	// its ACL token is never returned.
+	Identity *ACLIdentity
+
+	// Policies are the names of the ACL policies applied to the identity,
+	// including the policies of the roles of ACL tokens.
+	Policies []string
+
+	// Capabilities are the effective capabilities of the identity. They are
+	// only set when ACLs are enabled.
+	Capabilities *ACLCapabilities
+}
+
+// ACLIdentity is an authenticated identity. Only one of its fields is set.
+type ACLIdentity struct {
+	ACLToken *ACLToken
+	Claims   *ACLIdentityClaims
+	ClientID string
+}
+
+// ACLIdentityClaims are the claims of an authenticated workload identity.
+type ACLIdentityClaims struct {
+	Namespace    string `json:"nomad_namespace"`
+	JobID        string `json:"nomad_job_id"`
+	AllocationID string `json:"nomad_allocation_id"`
+	TaskName     string `json:"nomad_task,omitempty"`
+	ServiceName  string `json:"nomad_service,omitempty"`
+	Subject      string `json:"sub,omitempty"`
+}
+
+// ACLCapabilities are the effective capabilities of an identity.
+type ACLCapabilities struct {
+	// Management is true for management tokens, which are allowed to do
+	// anything.
+	Management bool
+
+	// Namespaces, NodePools, and HostVolumes map names and glob patterns to
+	// their sorted capabilities.
+	Namespaces  map[string][]string
+	NodePools   map[string][]string
+	HostVolumes map[string][]string
+
+	// Agent, Node, Operator, Quota, and Plugin are the policy dispositions of
+	// the policies without fine-grained capabilities, such as "read".
+	Agent    string
+	Node     string
+	Operator string
+	Quota    string
+	Plugin   string
+}
+
 type ACLTokenListStub struct {
 	AccessorID string
 	Name       string
@@ -637,10 +772,25 @@ type ACLTokenListStub struct {
 	// indicates no expiration has been set on the token.
 	ExpirationTime *time.Time `json:",omitempty"`
 
+	// AuthMethod is the name of the auth method which created the token.
+	AuthMethod string `json:",omitempty"`
+
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
+// ACLTokenRenewRequest is the request object to renew an ACL token created by
+// an auth method.
+type ACLTokenRenewRequest struct {
+	// SecretID is the secret ID of the token to renew. This is a required
+	// parameter.
+	SecretID string
+
+	// LoginToken is a token which can be used to login with the auth method
+	// which created the ACL token. This is a required parameter.
+	LoginToken string
+}
+
 type OneTimeToken struct {
 	OneTimeSecretID string
 	AccessorID      string
@@ -649,6 +799,10 @@ type OneTimeToken struct {
 	ModifyIndex     uint64
 }
 
+type OneTimeTokenUpsertRequest struct {
+	TTL time.Duration
+}
+
 type OneTimeTokenUpsertResponse struct {
 	OneTimeToken *OneTimeToken
 }
@@ -765,6 +919,10 @@ type ACLAuthMethod struct {
 	// auth-method.
 	Config *ACLAuthMethodConfig
 
+	// TokenRenewalGracePeriod is the duration after the expiration of the
+	// tokens created by this method during which they can still be renewed.
+	TokenRenewalGracePeriod time.Duration
+
 	CreateTime  time.Time
 	ModifyTime  time.Time
 	CreateIndex uint64
@@ -772,28 +930,36 @@ type ACLAuthMethod struct {
 }
 
 // MarshalJSON implements the json.Marshaler interface and allows
-// ACLAuthMethod.MaxTokenTTL to be marshaled correctly.
+// ACLAuthMethod.MaxTokenTTL and ACLAuthMethod.TokenRenewalGracePeriod to be
+// marshaled correctly.
 func (m *ACLAuthMethod) MarshalJSON() ([]byte, error) {
 	type Alias ACLAuthMethod
 	exported := &struct {
-		MaxTokenTTL string
+		MaxTokenTTL             string
+		TokenRenewalGracePeriod string
 		*Alias
 	}{
-		MaxTokenTTL: m.MaxTokenTTL.String(),
-		Alias:       (*Alias)(m),
+		MaxTokenTTL:             m.MaxTokenTTL.String(),
+		TokenRenewalGracePeriod: m.TokenRenewalGracePeriod.String(),
+		Alias:                   (*Alias)(m),
 	}
 	if m.MaxTokenTTL == 0 {
 		exported.MaxTokenTTL = ""
 	}
+	if m.TokenRenewalGracePeriod == 0 {
+		exported.TokenRenewalGracePeriod = ""
+	}
 	return json.Marshal(exported)
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface and allows
-// ACLAuthMethod.MaxTokenTTL to be unmarshalled correctly.
+// ACLAuthMethod.MaxTokenTTL and ACLAuthMethod.TokenRenewalGracePeriod to be
+// unmarshalled correctly.
 func (m *ACLAuthMethod) UnmarshalJSON(data []byte) error {
 	type Alias ACLAuthMethod
 	aux := &struct {
-		MaxTokenTTL string
+		MaxTokenTTL             string
+		TokenRenewalGracePeriod string
 		*Alias
 	}{
 		Alias: (*Alias)(m),
@@ -807,6 +973,11 @@ func (m *ACLAuthMethod) UnmarshalJSON(data []byte) error {
 			return err
 		}
 	}
+	if aux.TokenRenewalGracePeriod != "" {
+		if m.TokenRenewalGracePeriod, err = time.ParseDuration(aux.TokenRenewalGracePeriod); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1054,6 +1225,58 @@ type ACLBindingRuleListStub struct {
 	ModifyIndex uint64
 }
 
+// ACLBindingRulesTestRequest is the request object to evaluate a sample claim
+// set against the claim mappings and binding rules of an auth method.
+type ACLBindingRulesTestRequest struct {
+
+	// AuthMethodName is the name of the auth method whose binding rules are
+	// evaluated. This is a required parameter.
+	AuthMethodName string
+
+	// Claims is the sample claim set, as found in a JWT or in the ID token
+	// and user info of an OIDC provider.
+	Claims map[string]any
+}
+
+// ACLBindingRulesTestResponse details the roles and policies an ACL token
+// created by logging in with the claims of an ACLBindingRulesTestRequest would
+// be granted.
+type ACLBindingRulesTestResponse struct {
+
+	// ClaimMappings and ListClaimMappings are the values of the claims mapped
+	// by the auth method, which binding rules select and interpolate in their
+	// bind names.
+	ClaimMappings     map[string]string
+	ListClaimMappings map[string][]string
+
+	// MatchingRules are the binding rules whose selector matched the claims,
+	// in the order they were applied.
+	MatchingRules []*ACLBindingRuleTestResult
+
+	// Management indicates the token would be a management token.
+	Management bool
+
+	// Roles and Policies are the ACL roles and policies the token would be
+	// granted.
+	Roles    []*ACLTokenRoleLink
+	Policies []string
+}
+
+// ACLBindingRuleTestResult is the result of a binding rule whose selector
+// matched the claims of an ACLBindingRulesTestRequest.
+type ACLBindingRuleTestResult struct {
+	ID          string
+	Description string
+	BindType    string
+
+	// BindName is the bind name of the rule with the claims interpolated.
+	BindName string
+
+	// Bound indicates the ACL role or policy named by BindName exists, so it
+	// is granted to the token.
+	Bound bool
+}
+
 // ACLOIDCAuthURLRequest is the request to make when starting the OIDC
 // authentication login flow.
 type ACLOIDCAuthURLRequest struct {
@@ -421,6 +421,29 @@ func TestACLTokens_Self(t *testing.T) {
 	must.Eq(t, out, out2)
 }
 
+func TestACLTokens_WhoAmI(t *testing.T) {
+	testutil.Parallel(t)
+
+	c, s, root := makeACLClient(t, nil, nil)
+	defer s.Stop()
+	at := c.ACLTokens()
+
+	// The management token has every capability.
+	out, _, err := at.WhoAmI(nil)
+	must.NoError(t, err)
+	must.NotNil(t, out.Identity.ACLToken)
+	must.Eq(t, root.AccessorID, out.Identity.ACLToken.AccessorID)
+	must.Eq(t, "", out.Identity.ACLToken.SecretID)
+	must.True(t, out.Capabilities.Management)
+
+	// Requests without a token use the anonymous policy.
+	c.SetSecretID("")
+	out, _, err = c.ACLTokens().WhoAmI(nil)
+	must.NoError(t, err)
+	must.Eq(t, []string{"anonymous"}, out.Policies)
+	must.False(t, out.Capabilities.Management)
+}
+
 func TestACLTokens_Delete(t *testing.T) {
 	testutil.Parallel(t)
 
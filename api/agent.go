@@ -64,6 +64,22 @@ func (a *Agent) Self() (*AgentSelf, error) {
 	return out, nil
 }
 
+// SelfFull is like Self but the secrets of the agent configuration, such as
+// tokens, are not redacted. It requires a management token.
+func (a *Agent) SelfFull() (*AgentSelf, error) {
+	var out *AgentSelf
+
+	q := &QueryOptions{Params: map[string]string{"full": "true"}}
+	_, err := a.client.query("/v1/agent/self", &out, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying self endpoint: %s", err)
+	}
+
+	a.populateCache(out)
+
+	return out, nil
+}
+
 // populateCache is used to insert various pieces of static
 // data into the agent handle. This is used during subsequent
 // lookups for the same data later on to save the round trip.
@@ -444,6 +460,13 @@ type ServerMembers struct {
 }
 
 type AgentSelf struct {
+	// SchemaVersion is the version of the schema of the response, which is
+	// incremented when fields are removed or change meaning.
+	SchemaVersion int `json:"schema_version"`
+
+	// Redacted is true if the secrets of Config were redacted.
+	Redacted bool `json:"redacted"`
+
 	Config map[string]interface{}       `json:"config"`
 	Member AgentMember                  `json:"member"`
 	Stats  map[string]map[string]string `json:"stats"`
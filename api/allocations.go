@@ -134,6 +134,50 @@ func (a *Allocations) Checks(allocID string, q *QueryOptions) (AllocCheckStatuse
 	return resp, err
 }
 
+// Hooks returns the alloc runner and task runner hooks of an allocation that
+// are running.
+//
+// Note: for cluster topologies where API consumers don't have network access to
+// Nomad clients, set api.ClientConnTimeout to a small value (ex 1ms) to avoid
+// long pauses on this API call.
+func (a *Allocations) Hooks(alloc *Allocation, q *QueryOptions) ([]*AllocRunningHook, error) {
+	var resp []*AllocRunningHook
+	_, err := a.client.query("/v1/client/allocation/"+alloc.ID+"/hooks", &resp, q)
+	return resp, err
+}
+
+// Identities returns the decoded signed workload identities of an allocation.
+// The identities can be filtered by task and by name with the "task" and
+// "name" query parameters.
+//
+// Note: for cluster topologies where API consumers don't have network access to
+// Nomad clients, set api.ClientConnTimeout to a small value (ex 1ms) to avoid
+// long pauses on this API call.
+func (a *Allocations) Identities(alloc *Allocation, q *QueryOptions) ([]*AllocIdentity, error) {
+	var resp []*AllocIdentity
+	_, err := a.client.query("/v1/client/allocation/"+alloc.ID+"/identities", &resp, q)
+	return resp, err
+}
+
+// OverrideHook skips or fails a running hook of an allocation, or of one of
+// its tasks if task is set. The action is either AllocHookOverrideSkip or
+// AllocHookOverrideFail.
+//
+// Note: for cluster topologies where API consumers don't have network access to
+// Nomad clients, set api.ClientConnTimeout to a small value (ex 1ms) to avoid
+// long pauses on this API call.
+func (a *Allocations) OverrideHook(alloc *Allocation, task, hook, action string, q *QueryOptions) error {
+	req := AllocHookOverrideRequest{
+		Task:   task,
+		Hook:   hook,
+		Action: action,
+	}
+
+	var resp GenericResponse
+	_, err := a.client.putQuery("/v1/client/allocation/"+alloc.ID+"/hooks", &req, &resp, q)
+	return err
+}
+
 // GC forces a garbage collection of client state for an allocation.
 //
 // Note: for cluster topologies where API consumers don't have network access to
@@ -243,6 +287,48 @@ func (a *Allocations) Services(allocID string, q *QueryOptions) ([]*ServiceRegis
 	return resp, qm, err
 }
 
+// Timeline returns the timeline of the placement of the allocation, from its
+// creation by the scheduler until it was reported healthy.
+func (a *Allocations) Timeline(allocID string, q *QueryOptions) (*AllocTimeline, *QueryMeta, error) {
+	var resp AllocTimeline
+	qm, err := a.client.query("/v1/allocation/"+allocID+"/timeline", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// AllocTimeline is used for serialization of allocation timelines.
+type AllocTimeline struct {
+	AllocID         string
+	CreatedAt       time.Time
+	ReceivedAt      time.Time
+	RunningAt       time.Time
+	HealthyAt       time.Time
+	PendingDuration time.Duration
+	HealthDuration  time.Duration
+	Tasks           map[string]*TaskTimeline
+}
+
+// TaskTimeline is the timeline of a single task within an allocation.
+type TaskTimeline struct {
+	ReceivedAt time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Hooks      []*TimelineSpan
+	ImagePull  *TimelineSpan
+	Restarts   uint64
+}
+
+// TimelineSpan is a named interval of an allocation timeline.
+type TimelineSpan struct {
+	Name     string
+	Phase    string `json:",omitempty"`
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
 // Allocation is used for serialization of allocations.
 type Allocation struct {
 	ID                    string
@@ -272,6 +358,7 @@ type Allocation struct {
 	NextAllocation        string
 	RescheduleTracker     *RescheduleTracker
 	NetworkStatus         *AllocNetworkStatus
+	MigrationStatus       *AllocMigrationStatus
 	PreemptedAllocations  []string
 	PreemptedByAllocation string
 	CreateIndex           uint64
@@ -410,6 +497,21 @@ type AllocNetworkStatus struct {
 	InterfaceName string
 	Address       string
 	DNS           *DNSConfig
+	NamespacePath string
+	BridgeName    string
+}
+
+// AllocMigrationStatus captures the progress of the migration of the ephemeral
+// disk data of a previous allocation.
+type AllocMigrationStatus struct {
+	PreviousAllocID string
+	BytesMigrated   int64
+	BytesTotal      int64
+	StartedAt       time.Time
+	UpdatedAt       time.Time
+	ETA             time.Duration
+	Complete        bool
+	Error           string
 }
 
 type AllocatedResources struct {
@@ -507,6 +609,43 @@ type AllocationRestartRequest struct {
 	AllTasks bool
 }
 
+const (
+	// AllocHookOverrideSkip continues as if the overridden hook had
+	// succeeded.
+	AllocHookOverrideSkip = "skip"
+
+	// AllocHookOverrideFail continues as if the overridden hook had failed.
+	AllocHookOverrideFail = "fail"
+)
+
+// AllocRunningHook is an alloc runner or task runner hook whose call hasn't
+// returned yet. Task is empty for alloc runner hooks.
+type AllocRunningHook struct {
+	Task      string
+	Name      string
+	Phase     string
+	StartedAt time.Time
+}
+
+// AllocIdentity is the decoded signed workload identity of a task or service
+// of an allocation. Task is empty for the identities of group services, and
+// ExpiresAt is zero for identities which don't expire.
+type AllocIdentity struct {
+	Name      string
+	Task      string
+	Service   string
+	Audience  []string
+	Claims    map[string]any
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+type AllocHookOverrideRequest struct {
+	Task   string
+	Hook   string
+	Action string
+}
+
 type AllocSignalRequest struct {
 	Task   string
 	Signal string
@@ -594,6 +594,9 @@ func (c *Client) getNodeClientImpl(nodeID string, timeout time.Duration, q *Quer
 	if node.HTTPAddr == "" {
 		return nil, fmt.Errorf("http addr of node %q (%s) is not advertised", node.Name, nodeID)
 	}
+	if node.ReverseTunnel {
+		return nil, fmt.Errorf("node %q (%s) is only reachable through the servers", node.Name, nodeID)
+	}
 
 	var region string
 	switch {
@@ -927,17 +930,24 @@ func (*Client) autoUnzip(resp *http.Response) error {
 // rawQuery makes a GET request to the specified endpoint but returns just the
 // response body.
 func (c *Client) rawQuery(endpoint string, q *QueryOptions) (io.ReadCloser, error) {
+	body, _, err := c.rawQueryWithHeaders(endpoint, q)
+	return body, err
+}
+
+// rawQueryWithHeaders makes a GET request to the specified endpoint and
+// returns the response body and headers
+func (c *Client) rawQueryWithHeaders(endpoint string, q *QueryOptions) (io.ReadCloser, http.Header, error) {
 	r, err := c.newRequest("GET", endpoint)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	r.setQueryOptions(q)
 	_, resp, err := requireOK(c.doRequest(r))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return resp.Body, nil
+	return resp.Body, resp.Header, nil
 }
 
 // websocket makes a websocket request to the specific endpoint
@@ -490,6 +490,19 @@ func TestClient_NodeClient(t *testing.T) {
 			must.Eq(t, c.ExpectedTLSServerName, nodeClient.config.TLSConfig.TLSServerName)
 		})
 	}
+
+	// Nodes in reverse tunnel mode must be reached through the servers
+	reverseTunnelNode := func(string, *QueryOptions) (*Node, *QueryMeta, error) {
+		return &Node{
+			ID:            generateUUID(),
+			Name:          "nat",
+			Status:        "ready",
+			HTTPAddr:      addr,
+			ReverseTunnel: true,
+		}, nil, nil
+	}
+	_, err = clientNoRegion.getNodeClientImpl("testID", -1, optionNoRegion, reverseTunnelNode)
+	must.EqError(t, err, `node "nat" (testID) is only reachable through the servers`)
 }
 
 func TestCloneHttpClient(t *testing.T) {
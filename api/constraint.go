@@ -12,6 +12,7 @@ const (
 	ConstraintSetContains       = "set_contains"
 	ConstraintSetContainsAll    = "set_contains_all"
 	ConstraintSetContainsAny    = "set_contains_any"
+	ConstraintCIDRContains      = "cidr_contains"
 	ConstraintAttributeIsSet    = "is_set"
 	ConstraintAttributeIsNotSet = "is_not_set"
 )
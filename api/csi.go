@@ -233,8 +233,9 @@ const (
 )
 
 const (
-	CSIVolumeTypeHost = "host"
-	CSIVolumeTypeCSI  = "csi"
+	CSIVolumeTypeHost  = "host"
+	CSIVolumeTypeLocal = "local"
+	CSIVolumeTypeCSI   = "csi"
 )
 
 // CSIMountOptions contain optional additional configuration that can be used
@@ -87,6 +87,18 @@ func (e *Evaluations) Allocations(evalID string, q *QueryOptions) ([]*Allocation
 	return resp, qm, nil
 }
 
+// Trace is used to retrieve the trace of the decisions made by the scheduler
+// while processing an evaluation. Traces are only recorded when enabled in
+// the scheduler configuration.
+func (e *Evaluations) Trace(evalID string, q *QueryOptions) (*EvalTrace, *QueryMeta, error) {
+	var resp EvalTrace
+	qm, err := e.client.query("/v1/evaluation/"+evalID+"/trace", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 const (
 	EvalStatusBlocked   = "blocked"
 	EvalStatusPending   = "pending"
@@ -116,6 +128,7 @@ type Evaluation struct {
 	BlockedEval          string
 	RelatedEvals         []*EvaluationStub
 	FailedTGAllocs       map[string]*AllocationMetric
+	Warnings             []*JobWarning
 	ClassEligibility     map[string]bool
 	EscapedComputedClass bool
 	QuotaLimitReached    string
@@ -161,6 +174,37 @@ type EvalDeleteResponse struct {
 	Count int
 }
 
+// EvalTrace is used to serialize the trace of an evaluation.
+type EvalTrace struct {
+	EvalID      string
+	Namespace   string
+	JobID       string
+	Placements  []*EvalTracePlacement
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// EvalTracePlacement records the nodes considered for placing a single
+// allocation.
+type EvalTracePlacement struct {
+	TaskGroup           string
+	AllocName           string
+	NodeID              string
+	NodesEvaluated      int
+	Filtered            []*EvalTraceNode
+	Exhausted           []*EvalTraceNode
+	Truncated           bool
+	Scores              []*NodeScoreMeta
+	PreemptionAttempted bool
+	PreemptedAllocs     []string
+}
+
+// EvalTraceNode records why a node was not selected for a placement.
+type EvalTraceNode struct {
+	NodeID string
+	Reason string
+}
+
 type EvalCountResponse struct {
 	Count int
 	QueryMeta
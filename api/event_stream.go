@@ -22,6 +22,18 @@ const (
 	TopicNodePool   Topic = "NodePool"
 	TopicService    Topic = "Service"
 	TopicAll        Topic = "*"
+
+	// TopicWorkloadIdentity is the topic of the lifecycle events of the
+	// workload identities of allocations: WorkloadIdentitySigned,
+	// WorkloadIdentityRenewed, WorkloadIdentityRenewalFailed, and
+	// WorkloadIdentityExpired.
+	TopicWorkloadIdentity Topic = "WorkloadIdentity"
+
+	// TopicChangeFeed is the topic of the change feed, which includes every
+	// change to the jobs, allocations, evaluations, deployments, nodes, node
+	// pools, and namespaces. It's only enabled if the servers are configured
+	// with enable_change_feed, and must be explicitly subscribed to.
+	TopicChangeFeed Topic = "ChangeFeed"
 )
 
 // Events is a set of events for a corresponding index. Events returned for the
@@ -45,6 +57,7 @@ type Event struct {
 	Topic      Topic
 	Type       string
 	Key        string
+	Namespace  string
 	FilterKeys []string
 	Index      uint64
 	Payload    map[string]interface{}
@@ -120,14 +133,53 @@ func (e *Event) Service() (*ServiceRegistration, error) {
 	return out.Service, nil
 }
 
+// WorkloadIdentity returns a WorkloadIdentityEvent struct from a given event
+// payload. If the Event Topic is WorkloadIdentity this will return a valid
+// WorkloadIdentityEvent.
+func (e *Event) WorkloadIdentity() (*WorkloadIdentityEvent, error) {
+	out, err := e.decodePayload()
+	if err != nil {
+		return nil, err
+	}
+	return out.WorkloadIdentity, nil
+}
+
+// WorkloadIdentityEvent is a lifecycle event of a workload identity of an
+// allocation.
+type WorkloadIdentityEvent struct {
+	AllocID   string
+	Namespace string
+	JobID     string
+	NodeID    string
+
+	// IdentityName is the name of the identity, and WorkloadIdentifier the
+	// name of the task or service it belongs to.
+	IdentityName       string
+	WorkloadIdentifier string
+
+	// WorkloadType is 0 for the identities of tasks and 1 for services.
+	WorkloadType int
+
+	// Type is the type of the event.
+	Type string
+
+	// Expiration is when the identity expires, or zero if it doesn't.
+	Expiration time.Time
+
+	// Error is why the identity failed to renew, for
+	// WorkloadIdentityRenewalFailed events.
+	Error string
+}
+
 type eventPayload struct {
-	Allocation *Allocation          `mapstructure:"Allocation"`
-	Deployment *Deployment          `mapstructure:"Deployment"`
-	Evaluation *Evaluation          `mapstructure:"Evaluation"`
-	Job        *Job                 `mapstructure:"Job"`
-	Node       *Node                `mapstructure:"Node"`
-	NodePool   *NodePool            `mapstructure:"NodePool"`
-	Service    *ServiceRegistration `mapstructure:"Service"`
+	Allocation       *Allocation            `mapstructure:"Allocation"`
+	Deployment       *Deployment            `mapstructure:"Deployment"`
+	Evaluation       *Evaluation            `mapstructure:"Evaluation"`
+	Job              *Job                   `mapstructure:"Job"`
+	Node             *Node                  `mapstructure:"Node"`
+	NodePool         *NodePool              `mapstructure:"NodePool"`
+	Service          *ServiceRegistration   `mapstructure:"Service"`
+	WorkloadIdentity *WorkloadIdentityEvent `mapstructure:"WorkloadIdentity"`
 }
 
 func (e *Event) decodePayload() (*eventPayload, error) {
@@ -331,6 +331,27 @@ func TestEventStream_PayloadValueHelpers(t *testing.T) {
 				must.Eq(t, "some-service-namespace-id", a.Namespace)
 			},
 		},
+		{
+			desc:  "workload_identity",
+			input: []byte(`{"Topic": "WorkloadIdentity", "Type": "WorkloadIdentityRenewalFailed", "Payload": {"WorkloadIdentity":{"AllocID":"some-alloc-id","JobID":"some-job-id","IdentityName":"vault","WorkloadIdentifier":"web","WorkloadType":0,"Type":"WorkloadIdentityRenewalFailed","Expiration":"2020-11-05T11:52:54Z","Error":"no servers"}}}`),
+			expectFn: func(t *testing.T, event Event) {
+				eventTime, err := time.Parse(time.RFC3339, "2020-11-05T11:52:54Z")
+				must.NoError(t, err)
+				must.Eq(t, TopicWorkloadIdentity, event.Topic)
+
+				wi, err := event.WorkloadIdentity()
+				must.NoError(t, err)
+				must.Eq(t, &WorkloadIdentityEvent{
+					AllocID:            "some-alloc-id",
+					JobID:              "some-job-id",
+					IdentityName:       "vault",
+					WorkloadIdentifier: "web",
+					Type:               "WorkloadIdentityRenewalFailed",
+					Expiration:         eventTime,
+					Error:              "no servers",
+				}, wi)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -137,6 +138,32 @@ func (a *AllocFS) Cat(alloc *Allocation, path string, q *QueryOptions) (io.ReadC
 		})
 }
 
+// Archive is used to download a directory of an allocation as a gzipped tar
+// archive. Only the files matching one of the include patterns are archived,
+// or all files if none are given, and files matching one of the exclude
+// patterns are skipped. The request fails if the files to archive exceed
+// maxBytes, or the client default if maxBytes is 0.
+// Note: for cluster topologies where API consumers don't have network access to
+// Nomad clients, set api.ClientConnTimeout to a small value (ex 1ms) to avoid
+// long pauses on this API call.
+func (a *AllocFS) Archive(alloc *Allocation, path string, include, exclude []string,
+	maxBytes int64, q *QueryOptions) (io.ReadCloser, error) {
+	reqPath := fmt.Sprintf("/v1/client/fs/archive/%s", alloc.ID)
+	return queryClientNode(a.client, alloc, reqPath, q,
+		func(q *QueryOptions) {
+			q.Params["path"] = path
+			if len(include) > 0 {
+				q.Params["include"] = strings.Join(include, ",")
+			}
+			if len(exclude) > 0 {
+				q.Params["exclude"] = strings.Join(exclude, ",")
+			}
+			if maxBytes > 0 {
+				q.Params["max_bytes"] = strconv.FormatInt(maxBytes, 10)
+			}
+		})
+}
+
 // Stream streams the content of a file blocking on EOF.
 // The parameters are:
 // * path: path to file to stream.
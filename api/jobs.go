@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"time"
@@ -87,6 +88,35 @@ type JobsParseRequest struct {
 	Canonicalize bool
 }
 
+const (
+	// JobsConvertFormatHCL1 is the format of HCL1 jobspecs to convert
+	JobsConvertFormatHCL1 = "hcl1"
+
+	// JobsConvertFormatJSON is the format of JSON jobspecs to convert
+	JobsConvertFormatJSON = "json"
+)
+
+// JobsConvertRequest is used for arguments of the /v1/jobs/convert endpoint
+type JobsConvertRequest struct {
+	// JobSource is the jobspec to convert
+	JobSource string
+
+	// Format is the format of the JobSource, either hcl1 or json. Defaults to
+	// hcl1.
+	Format string `json:",omitempty"`
+}
+
+// JobsConvertResponse is the response of the /v1/jobs/convert endpoint
+type JobsConvertResponse struct {
+	// JobHCL is the converted HCL2 jobspec
+	JobHCL string
+
+	// Warnings are the differences between the job parsed from the original
+	// jobspec and the job parsed from the converted one, which must be
+	// reviewed before using the converted jobspec.
+	Warnings []string
+}
+
 // Jobs returns a handle on the jobs endpoints.
 func (c *Client) Jobs() *Jobs {
 	return &Jobs{client: c}
@@ -111,6 +141,14 @@ func (j *Jobs) ParseHCLOpts(req *JobsParseRequest) (*Job, error) {
 	return &job, err
 }
 
+// Convert is used to request the server convert an HCL1 or JSON jobspec to
+// HCL2, preserving its comments where possible.
+func (j *Jobs) Convert(req *JobsConvertRequest) (*JobsConvertResponse, error) {
+	var resp JobsConvertResponse
+	_, err := j.client.put("/v1/jobs/convert", req, &resp, nil)
+	return &resp, err
+}
+
 func (j *Jobs) Validate(job *Job, q *WriteOptions) (*JobValidateResponse, *WriteMeta, error) {
 	var resp JobValidateResponse
 	req := &JobValidateRequest{Job: job}
@@ -234,6 +272,13 @@ func (j *Jobs) Scale(jobID, group string, count *int, message string, error bool
 		Message: message,
 		Meta:    meta,
 	}
+	return j.ScaleWithRequest(jobID, req, q)
+}
+
+// ScaleWithRequest is used to perform a scaling action on a job with a
+// ScalingRequest, which allows to set all of its fields, such as the
+// annotation of the scaling event.
+func (j *Jobs) ScaleWithRequest(jobID string, req *ScalingRequest, q *WriteOptions) (*JobRegisterResponse, *WriteMeta, error) {
 	var resp JobRegisterResponse
 	qm, err := j.client.put(fmt.Sprintf("/v1/job/%s/scale", url.PathEscape(jobID)), req, &resp, q)
 	if err != nil {
@@ -242,6 +287,40 @@ func (j *Jobs) Scale(jobID, group string, count *int, message string, error bool
 	return &resp, qm, nil
 }
 
+// OverrideSchedule is used to force a job with an active schedule active or
+// inactive regardless of its windows. A nil override clears the current one.
+func (j *Jobs) OverrideSchedule(jobID string, override *JobActiveScheduleOverride, q *WriteOptions) (*JobRegisterResponse, *WriteMeta, error) {
+	var resp JobRegisterResponse
+	req := &JobOverrideScheduleRequest{Override: override}
+	qm, err := j.client.put(fmt.Sprintf("/v1/job/%s/schedule", url.PathEscape(jobID)), req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Suspend is used to suspend a batch job. Its running allocations are
+// stopped, but the job keeps its version so the allocations that didn't
+// complete are placed again once it's resumed.
+func (j *Jobs) Suspend(jobID string, q *WriteOptions) (*JobSuspendResponse, *WriteMeta, error) {
+	var resp JobSuspendResponse
+	qm, err := j.client.put(fmt.Sprintf("/v1/job/%s/suspend", url.PathEscape(jobID)), nil, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Resume is used to resume a suspended batch job.
+func (j *Jobs) Resume(jobID string, q *WriteOptions) (*JobSuspendResponse, *WriteMeta, error) {
+	var resp JobSuspendResponse
+	qm, err := j.client.put(fmt.Sprintf("/v1/job/%s/resume", url.PathEscape(jobID)), nil, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
 // ScaleStatus is used to retrieve information about a particular
 // job given its unique ID.
 func (j *Jobs) ScaleStatus(jobID string, q *QueryOptions) (*JobScaleStatusResponse, *QueryMeta, error) {
@@ -340,6 +419,66 @@ func (j *Jobs) Evaluations(jobID string, q *QueryOptions) ([]*Evaluation, *Query
 	return resp, qm, nil
 }
 
+// Failures is used to query the failures of the allocations of the given job
+// ID, aggregated by reason over the given period in windows of the given
+// duration. Zero values use the server defaults of 24 hours and 1 hour.
+func (j *Jobs) Failures(jobID string, since, window time.Duration, q *QueryOptions) (*JobFailureHistory, *QueryMeta, error) {
+	u, err := url.Parse("/v1/job/" + url.PathEscape(jobID) + "/failures")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := u.Query()
+	if since > 0 {
+		v.Add("since", since.String())
+	}
+	if window > 0 {
+		v.Add("window", window.String())
+	}
+	u.RawQuery = v.Encode()
+
+	var resp JobFailureHistory
+	qm, err := j.client.query(u.String(), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// LocalVolumeClaims is used to query the local volume claims that bind the
+// allocations of the given job ID to nodes.
+func (j *Jobs) LocalVolumeClaims(jobID string, q *QueryOptions) ([]*LocalVolumeClaim, *QueryMeta, error) {
+	var resp []*LocalVolumeClaim
+	qm, err := j.client.query("/v1/job/"+url.PathEscape(jobID)+"/local-volume-claims", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// DeleteLocalVolumeClaims is used to delete the local volume claim of the
+// allocation name of the given job ID, or all the claims of the job if
+// allocName is empty. This allows the allocations to be placed on other nodes,
+// and the ID of the evaluation created to place them is returned.
+func (j *Jobs) DeleteLocalVolumeClaims(jobID, allocName string, q *WriteOptions) (string, *WriteMeta, error) {
+	u, err := url.Parse("/v1/job/" + url.PathEscape(jobID) + "/local-volume-claims")
+	if err != nil {
+		return "", nil, err
+	}
+	if allocName != "" {
+		v := u.Query()
+		v.Add("alloc_name", allocName)
+		u.RawQuery = v.Encode()
+	}
+
+	var resp LocalVolumeClaimDeleteResponse
+	wm, err := j.client.delete(u.String(), nil, &resp, q)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.EvalID, wm, nil
+}
+
 // Deregister is used to remove an existing job. If purge is set to true, the job
 // is deregistered and purged from the system versus still being queryable and
 // eventually GC'ed from the system. Most callers should not specify purge.
@@ -374,6 +513,12 @@ type DeregisterOptions struct {
 	// task shutdown_delay configuration and ignore the delay for any
 	// allocations stopped as a result of this Deregister call.
 	NoShutdownDelay bool
+
+	// ServiceDrain, if set, deregisters the services of the allocations
+	// stopped as a result of this Deregister call, and waits for the
+	// duration before killing their tasks. It can't be combined with
+	// NoShutdownDelay.
+	ServiceDrain time.Duration
 }
 
 // DeregisterOpts is used to remove an existing job. See DeregisterOptions
@@ -389,6 +534,9 @@ func (j *Jobs) DeregisterOpts(jobID string, opts *DeregisterOptions, q *WriteOpt
 	if opts != nil {
 		endpoint += fmt.Sprintf("?purge=%t&global=%t&eval_priority=%v&no_shutdown_delay=%t",
 			opts.Purge, opts.Global, opts.EvalPriority, opts.NoShutdownDelay)
+		if opts.ServiceDrain > 0 {
+			endpoint += "&service_drain=" + url.QueryEscape(opts.ServiceDrain.String())
+		}
 	}
 
 	wm, err := j.client.delete(endpoint, nil, &resp, q)
@@ -548,15 +696,18 @@ type periodicForceResponse struct {
 
 // UpdateStrategy defines a task groups update strategy.
 type UpdateStrategy struct {
-	Stagger          *time.Duration `mapstructure:"stagger" hcl:"stagger,optional"`
-	MaxParallel      *int           `mapstructure:"max_parallel" hcl:"max_parallel,optional"`
-	HealthCheck      *string        `mapstructure:"health_check" hcl:"health_check,optional"`
-	MinHealthyTime   *time.Duration `mapstructure:"min_healthy_time" hcl:"min_healthy_time,optional"`
-	HealthyDeadline  *time.Duration `mapstructure:"healthy_deadline" hcl:"healthy_deadline,optional"`
-	ProgressDeadline *time.Duration `mapstructure:"progress_deadline" hcl:"progress_deadline,optional"`
-	Canary           *int           `mapstructure:"canary" hcl:"canary,optional"`
-	AutoRevert       *bool          `mapstructure:"auto_revert" hcl:"auto_revert,optional"`
-	AutoPromote      *bool          `mapstructure:"auto_promote" hcl:"auto_promote,optional"`
+	Stagger                 *time.Duration `mapstructure:"stagger" hcl:"stagger,optional"`
+	MaxParallel             *int           `mapstructure:"max_parallel" hcl:"max_parallel,optional"`
+	HealthCheck             *string        `mapstructure:"health_check" hcl:"health_check,optional"`
+	MinHealthyTime          *time.Duration `mapstructure:"min_healthy_time" hcl:"min_healthy_time,optional"`
+	HealthyDeadline         *time.Duration `mapstructure:"healthy_deadline" hcl:"healthy_deadline,optional"`
+	ProgressDeadline        *time.Duration `mapstructure:"progress_deadline" hcl:"progress_deadline,optional"`
+	Canary                  *int           `mapstructure:"canary" hcl:"canary,optional"`
+	AutoRevert              *bool          `mapstructure:"auto_revert" hcl:"auto_revert,optional"`
+	AutoPromote             *bool          `mapstructure:"auto_promote" hcl:"auto_promote,optional"`
+	MaxSurge                *int           `mapstructure:"max_surge" hcl:"max_surge,optional"`
+	HealthChecks            []string       `mapstructure:"health_checks" hcl:"health_checks,optional"`
+	MinHealthyChecksPercent *int           `mapstructure:"min_healthy_checks_percent" hcl:"min_healthy_checks_percent,optional"`
 }
 
 // DefaultUpdateStrategy provides a baseline that can be used to upgrade
@@ -618,6 +769,18 @@ func (u *UpdateStrategy) Copy() *UpdateStrategy {
 		copy.AutoPromote = pointerOf(*u.AutoPromote)
 	}
 
+	if u.MaxSurge != nil {
+		copy.MaxSurge = pointerOf(*u.MaxSurge)
+	}
+
+	if u.HealthChecks != nil {
+		copy.HealthChecks = slices.Clone(u.HealthChecks)
+	}
+
+	if u.MinHealthyChecksPercent != nil {
+		copy.MinHealthyChecksPercent = pointerOf(*u.MinHealthyChecksPercent)
+	}
+
 	return copy
 }
 
@@ -661,6 +824,18 @@ func (u *UpdateStrategy) Merge(o *UpdateStrategy) {
 	if o.AutoPromote != nil {
 		u.AutoPromote = pointerOf(*o.AutoPromote)
 	}
+
+	if o.MaxSurge != nil {
+		u.MaxSurge = pointerOf(*o.MaxSurge)
+	}
+
+	if o.HealthChecks != nil {
+		u.HealthChecks = slices.Clone(o.HealthChecks)
+	}
+
+	if o.MinHealthyChecksPercent != nil {
+		u.MinHealthyChecksPercent = pointerOf(*o.MinHealthyChecksPercent)
+	}
 }
 
 func (u *UpdateStrategy) Canonicalize() {
@@ -745,6 +920,18 @@ func (u *UpdateStrategy) Empty() bool {
 		return false
 	}
 
+	if u.MaxSurge != nil && *u.MaxSurge != 0 {
+		return false
+	}
+
+	if len(u.HealthChecks) != 0 {
+		return false
+	}
+
+	if u.MinHealthyChecksPercent != nil && *u.MinHealthyChecksPercent != 0 {
+		return false
+	}
+
 	return true
 }
 
@@ -911,6 +1098,45 @@ type ParameterizedJobConfig struct {
 	MetaOptional []string `mapstructure:"meta_optional" hcl:"meta_optional,optional"`
 }
 
+// JobActiveSchedule restricts the times a service job runs to a set of
+// windows, outside of which its groups are scaled to zero.
+type JobActiveSchedule struct {
+	TimeZone *string            `mapstructure:"time_zone" hcl:"time_zone,optional"`
+	Windows  []*JobActiveWindow `mapstructure:"window" hcl:"window,block"`
+
+	/* Fields set by server, not sourced from job config file */
+
+	Override        *JobActiveScheduleOverride
+	SuspendedCounts map[string]int
+}
+
+// JobActiveWindow is a window in which a job with an active schedule runs,
+// starting at each time matching the cron expression.
+type JobActiveWindow struct {
+	Cron     string        `hcl:"cron,optional"`
+	Duration time.Duration `hcl:"duration,optional"`
+}
+
+// JobActiveScheduleOverride forces a job with an active schedule active or
+// inactive until it expires, or until it's cleared if Until is zero.
+type JobActiveScheduleOverride struct {
+	Active bool
+	Until  time.Time
+}
+
+func (s *JobActiveSchedule) Canonicalize() {
+	if s.TimeZone == nil {
+		s.TimeZone = pointerOf("UTC")
+	}
+}
+
+// JobOverrideScheduleRequest is used to override the active schedule of a
+// job. A nil override clears the current one.
+type JobOverrideScheduleRequest struct {
+	Override *JobActiveScheduleOverride
+	WriteRequest
+}
+
 // JobSubmission is used to hold information about the original content of a job
 // specification being submitted to Nomad.
 //
@@ -969,6 +1195,7 @@ type Job struct {
 	AllAtOnce        *bool                   `mapstructure:"all_at_once" hcl:"all_at_once,optional"`
 	Datacenters      []string                `hcl:"datacenters,optional"`
 	NodePool         *string                 `mapstructure:"node_pool" hcl:"node_pool,optional"`
+	MaxAllocsPerNode *int                    `mapstructure:"max_allocs_per_node" hcl:"max_allocs_per_node,optional"`
 	Constraints      []*Constraint           `hcl:"constraint,block"`
 	Affinities       []*Affinity             `hcl:"affinity,block"`
 	TaskGroups       []*TaskGroup            `hcl:"group,block"`
@@ -977,15 +1204,18 @@ type Job struct {
 	Spreads          []*Spread               `hcl:"spread,block"`
 	Periodic         *PeriodicConfig         `hcl:"periodic,block"`
 	ParameterizedJob *ParameterizedJobConfig `hcl:"parameterized,block"`
+	ActiveSchedule   *JobActiveSchedule      `mapstructure:"active_schedule" hcl:"active_schedule,block"`
 	Reschedule       *ReschedulePolicy       `hcl:"reschedule,block"`
 	Migrate          *MigrateStrategy        `hcl:"migrate,block"`
 	Meta             map[string]string       `hcl:"meta,block"`
+	Env              map[string]string       `hcl:"env,block"`
 	ConsulToken      *string                 `mapstructure:"consul_token" hcl:"consul_token,optional"`
 	VaultToken       *string                 `mapstructure:"vault_token" hcl:"vault_token,optional"`
 
 	/* Fields set by server, not sourced from job config file */
 
 	Stop                     *bool
+	Suspended                *bool
 	ParentID                 *string
 	Dispatched               bool
 	DispatchIdempotencyToken *string
@@ -1088,6 +1318,9 @@ func (j *Job) Canonicalize() {
 	if j.Periodic != nil {
 		j.Periodic.Canonicalize()
 	}
+	if j.ActiveSchedule != nil {
+		j.ActiveSchedule.Canonicalize()
+	}
 	if j.Update != nil {
 		j.Update.Canonicalize()
 	} else if *j.Type == JobTypeService {
@@ -1125,12 +1358,51 @@ type JobSummary struct {
 	Namespace string
 	Summary   map[string]TaskGroupSummary
 	Children  *JobChildrenSummary
+	Health    *JobHealth
+	Warnings  []*JobWarning
 
 	// Raft Indexes
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
+const (
+	JobDeploymentTrendNone        = "none"
+	JobDeploymentTrendStable      = "stable"
+	JobDeploymentTrendRecovering  = "recovering"
+	JobDeploymentTrendProgressing = "progressing"
+	JobDeploymentTrendDegrading   = "degrading"
+	JobDeploymentTrendFailing     = "failing"
+)
+
+// JobHealth is a summary of the health of the allocations and deployments of
+// a job, computed when the job summary is read with the "health" query
+// parameter set. Task restarts are counted over the window set by the
+// "restart_window" query parameter, 15 minutes by default.
+type JobHealth struct {
+	DesiredAllocs     int
+	HealthyAllocs     int
+	HealthyPercent    int
+	Restarts          int
+	RestartWindow     time.Duration
+	DeploymentTrend   string
+	RecentDeployments []string
+}
+
+const (
+	JobWarningTypeRegister         = "register"
+	JobWarningTypeAffinity         = "affinity"
+	JobWarningTypeOversubscription = "oversubscription"
+)
+
+// JobWarning is a non-fatal warning raised when the job was registered or
+// scheduled, which is persisted in the job summary.
+type JobWarning struct {
+	Type      string
+	TaskGroup string
+	Message   string
+}
+
 // JobChildrenSummary contains the summary of children job status
 type JobChildrenSummary struct {
 	Pending int64
@@ -1171,6 +1443,7 @@ type JobListStub struct {
 	Periodic          bool
 	ParameterizedJob  bool
 	Stop              bool
+	Suspended         bool
 	Status            string
 	StatusDescription string
 	JobSummary        *JobSummary
@@ -1377,6 +1650,14 @@ type JobRegisterResponse struct {
 	QueryMeta
 }
 
+// JobSuspendResponse is used to respond to suspending or resuming a job
+type JobSuspendResponse struct {
+	EvalID          string
+	EvalCreateIndex uint64
+	JobModifyIndex  uint64
+	QueryMeta
+}
+
 // JobDeregisterResponse is used to respond to a job deregistration
 type JobDeregisterResponse struct {
 	EvalID          string
@@ -1403,6 +1684,19 @@ type JobPlanResponse struct {
 	// Warnings contains any warnings about the given job. These may include
 	// deprecation warnings.
 	Warnings string
+
+	// Lints are the violations of the lint rules configured on the servers
+	// with the warn level. They are also included in Warnings.
+	Lints []*JobLint
+}
+
+// JobLint is a violation of a job lint rule configured on the servers.
+type JobLint struct {
+	Rule      string
+	Level     string
+	TaskGroup string
+	Task      string
+	Message   string
 }
 
 type JobDiff struct {
@@ -1544,3 +1838,63 @@ func (j *Jobs) ActionExec(ctx context.Context,
 
 	return s.run(ctx)
 }
+
+const (
+	JobFailureReasonOOM         = "oom"
+	JobFailureReasonExitCode    = "exit-code"
+	JobFailureReasonDriverError = "driver-error"
+	JobFailureReasonConstraint  = "constraint"
+	JobFailureReasonExhausted   = "exhausted"
+)
+
+// LocalVolumeClaim binds the allocations of a task group with local volumes
+// that share a name to the node of their first placement.
+type LocalVolumeClaim struct {
+	Namespace   string
+	JobID       string
+	TaskGroup   string
+	AllocName   string
+	NodeID      string
+	AllocID     string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// LocalVolumeClaimDeleteResponse is used to respond to deleting local volume
+// claims.
+type LocalVolumeClaimDeleteResponse struct {
+	EvalID          string
+	EvalCreateIndex uint64
+	WriteMeta
+}
+
+// JobFailureHistory is the failures of the allocations of a job aggregated
+// by reason over a period of time, split into windows of equal duration.
+type JobFailureHistory struct {
+	Namespace   string
+	JobID       string
+	Start       time.Time
+	End         time.Time
+	Window      time.Duration
+	Failures    []*JobFailureCount
+	Restarts    int
+	Reschedules int
+	Windows     []*JobFailureWindow
+}
+
+// JobFailureWindow is the failures of a job within a single window.
+type JobFailureWindow struct {
+	Start       time.Time
+	End         time.Time
+	Failures    []*JobFailureCount
+	Restarts    int
+	Reschedules int
+}
+
+// JobFailureCount is the number of failures of a job for a given reason.
+type JobFailureCount struct {
+	Reason   string
+	Detail   string `json:",omitempty"`
+	Count    int
+	LastSeen time.Time
+}
@@ -6,6 +6,8 @@ package api
 import (
 	"fmt"
 	"net/url"
+	"strconv"
+	"time"
 )
 
 // Keyring is used to access the Variables keyring.
@@ -34,16 +36,18 @@ type RootKeyMeta struct {
 	CreateIndex uint64
 	ModifyIndex uint64
 	State       RootKeyState
+	PublishTime int64
 }
 
 // RootKeyState enum describes the lifecycle of a root key.
 type RootKeyState string
 
 const (
-	RootKeyStateInactive   RootKeyState = "inactive"
-	RootKeyStateActive                  = "active"
-	RootKeyStateRekeying                = "rekeying"
-	RootKeyStateDeprecated              = "deprecated"
+	RootKeyStateInactive     RootKeyState = "inactive"
+	RootKeyStateActive                    = "active"
+	RootKeyStateRekeying                  = "rekeying"
+	RootKeyStatePrepublished              = "prepublished"
+	RootKeyStateDeprecated                = "deprecated"
 )
 
 // List lists all the keyring metadata
@@ -78,6 +82,12 @@ func (k *Keyring) Rotate(opts *KeyringRotateOptions, w *WriteOptions) (*RootKeyM
 		if opts.Full {
 			qp.Set("full", "true")
 		}
+		if opts.PublishTime > 0 {
+			qp.Set("publish_time", strconv.FormatInt(opts.PublishTime, 10))
+		}
+		if opts.PrepublishDuration > 0 {
+			qp.Set("prepublish", opts.PrepublishDuration.String())
+		}
 	}
 	resp := &struct{ Key *RootKeyMeta }{}
 	wm, err := k.client.put("/v1/operator/keyring/rotate?"+qp.Encode(), nil, resp, w)
@@ -88,4 +98,39 @@ func (k *Keyring) Rotate(opts *KeyringRotateOptions, w *WriteOptions) (*RootKeyM
 type KeyringRotateOptions struct {
 	Full      bool
 	Algorithm EncryptionAlgorithm
+
+	// PublishTime is the time in Unix nanoseconds at which the new key
+	// becomes active. If set, the key is prepublished in the JWKS endpoint
+	// until then.
+	PublishTime int64
+
+	// PrepublishDuration is the time after which the new key becomes active,
+	// measured by the clock of the server. It cannot be combined with
+	// PublishTime.
+	PrepublishDuration time.Duration
+}
+
+// JSONWebKey is the public part of a key used to sign workload identities as
+// returned by the JWKS endpoint. Only the key metadata is decoded.
+type JSONWebKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+// JSONWebKeySet is the set of keys returned by the JWKS endpoint.
+type JSONWebKeySet struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
+
+// ListPublic lists the public keys used to sign workload identities from the
+// JWKS endpoint.
+func (k *Keyring) ListPublic(q *QueryOptions) (*JSONWebKeySet, *QueryMeta, error) {
+	var resp JSONWebKeySet
+	qm, err := k.client.query("/.well-known/jwks.json", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
 }
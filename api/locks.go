@@ -177,6 +177,94 @@ func (l *Locks) LockTTL() time.Duration {
 	return l.ttl
 }
 
+// Semaphore returns a new handle on a semaphore allowing up to limit holders
+// at the same time. The semaphore is made of limit locks, one per slot, held
+// on the variables "<path>/slot-<n>" under the path of the given variable.
+func (c *Client) Semaphore(wo WriteOptions, v Variable, limit int, opts ...LocksOption) (*Semaphore, error) {
+	if v.Path == "" {
+		return nil, LockNoPathErr
+	}
+	if limit < 1 {
+		return nil, fmt.Errorf("semaphore limit must be at least 1, got %d", limit)
+	}
+
+	s := &Semaphore{
+		slots: make([]*Locks, 0, limit),
+	}
+	for i := 0; i < limit; i++ {
+		sv := v
+		sv.Path = fmt.Sprintf("%s/slot-%d", v.Path, i)
+		if v.Lock != nil {
+			lock := *v.Lock
+			sv.Lock = &lock
+		}
+
+		l, err := c.Locks(wo, sv, opts...)
+		if err != nil {
+			return nil, err
+		}
+		s.slots = append(s.slots, l)
+	}
+
+	return s, nil
+}
+
+// Semaphore is a Locker held by up to a fixed number of holders at the same
+// time. Each holder acquires one of the slots of the semaphore, which are
+// regular locks, so the semaphore can be used with a LockLeaser.
+//
+// Important: It will be on the user to remove the variables created for the
+// slots.
+type Semaphore struct {
+	slots []*Locks
+	held  *Locks
+}
+
+// Acquire tries to acquire each slot of the semaphore in order until one is
+// free. It returns the path of the variable holding the acquired slot, or
+// ErrLockConflict if all the slots are held.
+func (s *Semaphore) Acquire(ctx context.Context) (string, error) {
+	for _, slot := range s.slots {
+		path, err := slot.Acquire(ctx)
+		if err != nil {
+			if errors.Is(err, ErrLockConflict) {
+				continue
+			}
+			return "", err
+		}
+
+		s.held = slot
+		return path, nil
+	}
+
+	return "", fmt.Errorf("acquire conflict: all %d slots are held %w", len(s.slots), ErrLockConflict)
+}
+
+// Release releases the slot held by the caller. In case no slot is held,
+// Release returns ErrLockConflict.
+func (s *Semaphore) Release(ctx context.Context) error {
+	if s.held == nil {
+		return fmt.Errorf("release conflict %w", ErrLockConflict)
+	}
+
+	err := s.held.Release(ctx)
+	s.held = nil
+	return err
+}
+
+// Renew extends the ttl of the slot held by the caller. In case no slot is
+// held, Renew returns ErrLockConflict.
+func (s *Semaphore) Renew(ctx context.Context) error {
+	if s.held == nil {
+		return fmt.Errorf("renew conflict %w", ErrLockConflict)
+	}
+	return s.held.Renew(ctx)
+}
+
+func (s *Semaphore) LockTTL() time.Duration {
+	return s.slots[0].LockTTL()
+}
+
 // Locker is the interface that wraps the lock handler. It is used by the lock
 // leaser to handle all lock operations.
 type Locker interface {
@@ -6,10 +6,12 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/nomad/api/internal/testutil"
 	"github.com/shoenig/test/must"
 )
 
@@ -497,3 +499,54 @@ func Test_EarlyReturn(t *testing.T) {
 	must.False(t, lock.locked)
 	must.Zero(t, lock.renewsCounter)
 }
+
+func TestSemaphore(t *testing.T) {
+	testutil.Parallel(t)
+
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+
+	v := Variable{
+		Namespace: "default",
+		Path:      fmt.Sprintf("semaphore/%v", time.Now().UnixMilli()),
+		Lock: &VariableLock{
+			TTL:       "15s",
+			LockDelay: "1ms",
+		},
+	}
+
+	_, err := c.Semaphore(WriteOptions{}, v, 0)
+	must.ErrorContains(t, err, "limit must be at least 1")
+
+	s1, err := c.Semaphore(WriteOptions{}, v, 2)
+	must.NoError(t, err)
+	s2, err := c.Semaphore(WriteOptions{}, v, 2)
+	must.NoError(t, err)
+	s3, err := c.Semaphore(WriteOptions{}, v, 2)
+	must.NoError(t, err)
+	must.Eq(t, 15*time.Second, s1.LockTTL())
+
+	ctx := context.Background()
+
+	// Each holder gets its own slot until the semaphore is full.
+	path1, err := s1.Acquire(ctx)
+	must.NoError(t, err)
+	must.Eq(t, v.Path+"/slot-0", path1)
+
+	path2, err := s2.Acquire(ctx)
+	must.NoError(t, err)
+	must.Eq(t, v.Path+"/slot-1", path2)
+
+	_, err = s3.Acquire(ctx)
+	must.ErrorIs(t, err, ErrLockConflict)
+	must.ErrorIs(t, s3.Renew(ctx), ErrLockConflict)
+	must.ErrorIs(t, s3.Release(ctx), ErrLockConflict)
+
+	must.NoError(t, s1.Renew(ctx))
+
+	// Releasing a slot lets another holder acquire it.
+	must.NoError(t, s1.Release(ctx))
+	path3, err := s3.Acquire(ctx)
+	must.NoError(t, err)
+	must.Eq(t, v.Path+"/slot-0", path3)
+}
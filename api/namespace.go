@@ -6,6 +6,7 @@ package api
 import (
 	"fmt"
 	"sort"
+	"time"
 )
 
 // Namespaces is used to query the namespace endpoints.
@@ -70,16 +71,17 @@ func (n *Namespaces) Delete(namespace string, q *WriteOptions) (*WriteMeta, erro
 
 // Namespace is used to serialize a namespace.
 type Namespace struct {
-	Name                  string
-	Description           string
-	Quota                 string
-	Capabilities          *NamespaceCapabilities          `hcl:"capabilities,block"`
-	NodePoolConfiguration *NamespaceNodePoolConfiguration `hcl:"node_pool_config,block"`
-	VaultConfiguration    *NamespaceVaultConfiguration    `hcl:"vault,block"`
-	ConsulConfiguration   *NamespaceConsulConfiguration   `hcl:"consul,block"`
-	Meta                  map[string]string
-	CreateIndex           uint64
-	ModifyIndex           uint64
+	Name                       string
+	Description                string
+	Quota                      string
+	Capabilities               *NamespaceCapabilities               `hcl:"capabilities,block"`
+	NodePoolConfiguration      *NamespaceNodePoolConfiguration      `hcl:"node_pool_config,block"`
+	VaultConfiguration         *NamespaceVaultConfiguration         `hcl:"vault,block"`
+	ConsulConfiguration        *NamespaceConsulConfiguration        `hcl:"consul,block"`
+	TokenExchangeConfiguration *NamespaceTokenExchangeConfiguration `hcl:"token_exchange,block"`
+	Meta                       map[string]string
+	CreateIndex                uint64
+	ModifyIndex                uint64
 }
 
 // NamespaceCapabilities represents a set of capabilities allowed for this
@@ -141,6 +143,18 @@ type NamespaceConsulConfiguration struct {
 	Denied []string
 }
 
+// NamespaceTokenExchangeConfiguration stores the audiences that workloads in
+// the namespace can exchange their workload identities for.
+type NamespaceTokenExchangeConfiguration struct {
+	// Audiences is the list of audiences that can be requested. This field
+	// supports wildcard globbing through the use of `*` for multi-character
+	// matching.
+	Audiences []string `mapstructure:"audiences" hcl:"audiences"`
+
+	// MaxTTL is the maximum lifetime of the exchanged tokens.
+	MaxTTL time.Duration `mapstructure:"max_ttl" hcl:"max_ttl"`
+}
+
 // NamespaceIndexSort is a wrapper to sort Namespaces by CreateIndex. We
 // reverse the test so that we get the highest index first.
 type NamespaceIndexSort []*Namespace
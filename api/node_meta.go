@@ -3,10 +3,16 @@
 
 package api
 
+import "time"
+
 // NodeMetaApplyRequest contains the Node meta update.
 type NodeMetaApplyRequest struct {
 	NodeID string
 	Meta   map[string]*string
+
+	// TTL is how long the keys set by this request are kept before they are
+	// removed. Zero keeps them until they are unset.
+	TTL time.Duration
 }
 
 // NodeMetaResponse contains the merged Node metadata.
@@ -19,6 +25,22 @@ type NodeMetaResponse struct {
 
 	// Static is the static Node metadata (set via agent configuration)
 	Static map[string]string
+
+	// DynamicInfo describes the writer and expiration of the dynamic Node
+	// metadata keys that are set.
+	DynamicInfo map[string]*NodeMetaInfo
+}
+
+// NodeMetaInfo describes a dynamic Node metadata key.
+type NodeMetaInfo struct {
+	// Writer is the identity of the request that last set the key.
+	Writer string
+
+	// ModifyTime is when the key was last set.
+	ModifyTime time.Time
+
+	// ExpireTime is when the key is removed, or zero if it doesn't expire.
+	ExpireTime time.Time
 }
 
 // NodeMeta is a client for manipulating dynamic Node metadata.
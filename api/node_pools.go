@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 )
 
 const (
@@ -119,6 +120,7 @@ type NodePool struct {
 	Description            string                          `hcl:"description,optional"`
 	Meta                   map[string]string               `hcl:"meta,block"`
 	SchedulerConfiguration *NodePoolSchedulerConfiguration `hcl:"scheduler_config,block"`
+	HeartbeatConfig        *NodePoolHeartbeatConfig        `hcl:"heartbeat,block"`
 	CreateIndex            uint64
 	ModifyIndex            uint64
 }
@@ -129,3 +131,17 @@ type NodePoolSchedulerConfiguration struct {
 	SchedulerAlgorithm            SchedulerAlgorithm `hcl:"scheduler_algorithm,optional"`
 	MemoryOversubscriptionEnabled *bool              `hcl:"memory_oversubscription_enabled,optional"`
 }
+
+// NodePoolHeartbeatConfig is used to serialize the heartbeat configuration of
+// a node pool.
+type NodePoolHeartbeatConfig struct {
+	MinHeartbeatTTL     time.Duration
+	HeartbeatGrace      time.Duration
+	MaxClientDisconnect time.Duration
+
+	// The durations are written as strings like "10s" in node pool
+	// specifications.
+	MinHeartbeatTTLHCL     string `hcl:"min_heartbeat_ttl,optional" json:"-"`
+	HeartbeatGraceHCL      string `hcl:"heartbeat_grace,optional" json:"-"`
+	MaxClientDisconnectHCL string `hcl:"max_client_disconnect,optional" json:"-"`
+}
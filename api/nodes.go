@@ -442,6 +442,28 @@ func (n *Nodes) Allocations(nodeID string, q *QueryOptions) ([]*Allocation, *Que
 	return resp, qm, nil
 }
 
+// Utilization returns the utilization rollups of the node, oldest first. The
+// rollups are kept by the leader and are lost on leader election.
+func (n *Nodes) Utilization(nodeID string, q *QueryOptions) ([]*NodeUtilizationRollup, *QueryMeta, error) {
+	var resp []*NodeUtilizationRollup
+	qm, err := n.client.query("/v1/node/"+nodeID+"/utilization", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// UtilizationList returns the latest utilization rollup of every node that
+// reported its utilization, by node ID.
+func (n *Nodes) UtilizationList(q *QueryOptions) (map[string]*NodeUtilizationRollup, *QueryMeta, error) {
+	var resp map[string]*NodeUtilizationRollup
+	qm, err := n.client.query("/v1/nodes/utilization", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
 func (n *Nodes) CSIVolumes(nodeID string, q *QueryOptions) ([]*CSIVolumeListStub, error) {
 	var resp []*CSIVolumeListStub
 	path := fmt.Sprintf("/v1/volumes?type=csi&node_id=%s", nodeID)
@@ -545,6 +567,7 @@ type Node struct {
 	Name                  string
 	HTTPAddr              string
 	TLSEnabled            bool
+	ReverseTunnel         bool
 	Attributes            map[string]string
 	Resources             *Resources
 	Reserved              *Resources
@@ -554,6 +577,7 @@ type Node struct {
 	Meta                  map[string]string
 	NodeClass             string
 	NodePool              string
+	MaxAllocs             int
 	CgroupParent          string
 	Drain                 bool
 	DrainStrategy         *DrainStrategy
@@ -568,10 +592,37 @@ type Node struct {
 	CSIControllerPlugins  map[string]*CSIInfo
 	CSINodePlugins        map[string]*CSIInfo
 	LastDrain             *DrainMetadata
+	Utilization           *NodeUtilization
 	CreateIndex           uint64
 	ModifyIndex           uint64
 }
 
+// NodeUtilization is the recent utilization reported by a node, used for
+// load-aware scoring.
+type NodeUtilization struct {
+	CPULoad        float64
+	MemoryPressure float64
+
+	// CPUUsedMHz, MemoryUsedMB, and DiskUsedMB are only reported by the
+	// clients configured with report_resource_usage.
+	CPUUsedMHz   int64
+	MemoryUsedMB int64
+	DiskUsedMB   int64
+
+	UpdatedAt int64
+}
+
+// NodeUtilizationRollup summarizes the utilization a node reported over 5
+// minutes starting at Start, in nanoseconds since the Unix epoch.
+type NodeUtilizationRollup struct {
+	Start             int64
+	Samples           int
+	CPULoadAvg        float64
+	CPULoadMax        float64
+	MemoryPressureAvg float64
+	MemoryPressureMax float64
+}
+
 type NodeResources struct {
 	Cpu      NodeCpuResources
 	Memory   NodeMemoryResources
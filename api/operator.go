@@ -120,6 +120,28 @@ func (op *Operator) RaftRemovePeerByID(id string, q *WriteOptions) error {
 	return nil
 }
 
+// RaftSetVoter is used to set whether a peer is a voter or a non-voting
+// server by ID. Non-voting servers serve stale reads without being part of
+// the quorum.
+func (op *Operator) RaftSetVoter(id string, voter bool, q *WriteOptions) error {
+	r, err := op.c.newRequest("PUT", "/v1/operator/raft/voter")
+	if err != nil {
+		return err
+	}
+	r.setWriteOptions(q)
+
+	r.params.Set("id", id)
+	r.params.Set("voter", strconv.FormatBool(voter))
+
+	_, resp, err := requireOK(op.c.doRequest(r))
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	return nil
+}
+
 // RaftTransferLeadershipByAddress is used to transfer leadership to a
 // different peer using its address in the form of "IP:port".
 func (op *Operator) RaftTransferLeadershipByAddress(address string, q *WriteOptions) error {
@@ -172,6 +194,10 @@ type SchedulerConfiguration struct {
 	// MemoryOversubscriptionEnabled specifies whether memory oversubscription is enabled
 	MemoryOversubscriptionEnabled bool
 
+	// LoadAwareScoringEnabled specifies whether the utilization reported by
+	// nodes in their heartbeats is used to score nodes
+	LoadAwareScoringEnabled bool
+
 	// RejectJobRegistration disables new job registrations except with a
 	// management ACL token
 	RejectJobRegistration bool
@@ -180,6 +206,18 @@ type SchedulerConfiguration struct {
 	// until the configuration is updated and written to the Nomad servers.
 	PauseEvalBroker bool
 
+	// EvalTraceConfig specifies whether schedulers should retain a trace of
+	// the decisions made while processing evaluations.
+	EvalTraceConfig EvalTraceConfig
+
+	// GCConfig overrides the garbage collection thresholds and batch sizes of
+	// the server configuration per object type.
+	GCConfig GCConfig
+
+	// BatchFairShareConfig specifies whether the evaluations of batch jobs are
+	// dequeued by the fair share of their namespace instead of by priority.
+	BatchFairShareConfig BatchFairShareConfig
+
 	// CreateIndex/ModifyIndex store the create/modify indexes of this configuration.
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -221,6 +259,35 @@ type PreemptionConfig struct {
 	ServiceSchedulerEnabled  bool
 }
 
+// EvalTraceConfig specifies whether evaluation traces are recorded and the
+// bounds of their retention.
+type EvalTraceConfig struct {
+	Enabled   bool
+	MaxNodes  int
+	MaxTraces int
+}
+
+// BatchFairShareConfig specifies whether the evaluations of batch jobs are
+// dequeued by the fair share of their namespace, and the weight of each
+// namespace. Namespaces without a weight have a weight of 1.
+type BatchFairShareConfig struct {
+	Enabled          bool
+	NamespaceWeights map[string]int
+}
+
+// GCConfig overrides the garbage collection thresholds and batch sizes of the
+// servers per object type. Zero values fall back to the server configuration.
+type GCConfig struct {
+	JobThreshold            time.Duration
+	JobBatchSize            int
+	EvalThreshold           time.Duration
+	BatchEvalThreshold      time.Duration
+	EvalBatchSize           int
+	DeploymentThreshold     time.Duration
+	DeploymentBatchSize     int
+	CSIVolumeClaimThreshold time.Duration
+}
+
 // SchedulerGetConfiguration is used to query the current Scheduler configuration.
 func (op *Operator) SchedulerGetConfiguration(q *QueryOptions) (*SchedulerConfigurationResponse, *QueryMeta, error) {
 	var resp SchedulerConfigurationResponse
@@ -46,6 +46,11 @@ type AutopilotConfiguration struct {
 	// upgrade versions when performing migrations.
 	EnableCustomUpgrades bool
 
+	// NonVoters are the IDs of the servers kept as non-voting servers, in
+	// addition to the servers started with non_voting_server. Use
+	// Operator.RaftSetVoter to change the voter status of a single server.
+	NonVoters []string
+
 	// CreateIndex holds the index corresponding the creation of this configuration.
 	// This is a read-only field.
 	CreateIndex uint64
@@ -31,6 +31,12 @@ func (raw *Raw) Response(endpoint string, q *QueryOptions) (io.ReadCloser, error
 	return raw.c.rawQuery(endpoint, q)
 }
 
+// ResponseWithHeaders is used to make a GET request against an endpoint and
+// returns the response body and headers
+func (raw *Raw) ResponseWithHeaders(endpoint string, q *QueryOptions) (io.ReadCloser, http.Header, error) {
+	return raw.c.rawQueryWithHeaders(endpoint, q)
+}
+
 // Write is used to do a PUT request against an endpoint
 // and serialize/deserialized using the standard Nomad conventions.
 func (raw *Raw) Write(endpoint string, in, out interface{}, q *WriteOptions) (*WriteMeta, error) {
@@ -139,9 +139,10 @@ type Port struct {
 }
 
 type DNSConfig struct {
-	Servers  []string `mapstructure:"servers" hcl:"servers,optional"`
-	Searches []string `mapstructure:"searches" hcl:"searches,optional"`
-	Options  []string `mapstructure:"options" hcl:"options,optional"`
+	Servers      []string `mapstructure:"servers" hcl:"servers,optional"`
+	Searches     []string `mapstructure:"searches" hcl:"searches,optional"`
+	Options      []string `mapstructure:"options" hcl:"options,optional"`
+	ServiceHosts []string `mapstructure:"service_hosts" hcl:"service_hosts,optional"`
 }
 
 // NetworkResource is used to describe required network
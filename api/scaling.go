@@ -56,6 +56,9 @@ type ScalingRequest struct {
 	Message string
 	Error   bool
 	Meta    map[string]interface{}
+	// Annotation is a free-form note explaining why the scaling action was
+	// performed, stored with the scaling event.
+	Annotation string
 	WriteRequest
 	// this is effectively a job update, so we need the ability to override policy.
 	PolicyOverride bool
@@ -117,6 +120,8 @@ type ScalingEvent struct {
 	Message       string
 	Meta          map[string]interface{}
 	EvalID        *string
+	Actor         string
+	Annotation    string
 	Time          uint64
 	CreateIndex   uint64
 }
@@ -245,6 +245,10 @@ type Service struct {
 	OnUpdate          string            `mapstructure:"on_update" hcl:"on_update,optional"`
 	Identity          *WorkloadIdentity `hcl:"identity,block"`
 
+	// Certificate requests a TLS certificate for the service from the
+	// workload CA of the servers. Only valid with the "nomad" provider.
+	Certificate *ServiceCertificate `hcl:"certificate,block"`
+
 	// Provider defines which backend system provides the service registration,
 	// either "consul" (default) or "nomad".
 	Provider string `hcl:"provider,optional"`
@@ -253,6 +257,13 @@ type Service struct {
 	Cluster string `hcl:"cluster,optional`
 }
 
+// ServiceCertificate requests a TLS certificate for a service, delivered to the
+// secrets directory of the tasks implementing it and renewed before it expires.
+type ServiceCertificate struct {
+	// TTL is how long the certificate is valid for. Defaults to 24 hours.
+	TTL time.Duration `mapstructure:"ttl" hcl:"ttl,optional"`
+}
+
 const (
 	OnUpdateRequireHealthy = "require_healthy"
 	OnUpdateIgnoreWarn     = "ignore_warnings"
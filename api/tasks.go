@@ -93,6 +93,11 @@ type RestartPolicy struct {
 	Delay           *time.Duration `hcl:"delay,optional"`
 	Mode            *string        `hcl:"mode,optional"`
 	RenderTemplates *bool          `mapstructure:"render_templates" hcl:"render_templates,optional"`
+
+	// DisconnectedMode controls whether tasks that fail while the client is
+	// disconnected from the servers are restarted locally ("restart") or
+	// failed ("fail").
+	DisconnectedMode *string `mapstructure:"disconnected_mode" hcl:"disconnected_mode,optional"`
 }
 
 func (r *RestartPolicy) Merge(rp *RestartPolicy) {
@@ -111,6 +116,18 @@ func (r *RestartPolicy) Merge(rp *RestartPolicy) {
 	if rp.RenderTemplates != nil {
 		r.RenderTemplates = rp.RenderTemplates
 	}
+	if rp.DisconnectedMode != nil {
+		r.DisconnectedMode = rp.DisconnectedMode
+	}
+}
+
+// DisconnectStrategy configures how the allocations of a task group are
+// handled when their client reconnects after being disconnected.
+type DisconnectStrategy struct {
+	// Reconcile is the strategy used to pick between an allocation that
+	// reconnects and its replacements. One of "best_score", "keep_original"
+	// or "keep_replacement".
+	Reconcile *string `mapstructure:"reconcile" hcl:"reconcile,optional"`
 }
 
 // Reschedule configures how Tasks are rescheduled  when they crash or fail.
@@ -453,12 +470,15 @@ type TaskGroup struct {
 	Migrate                   *MigrateStrategy          `hcl:"migrate,block"`
 	Networks                  []*NetworkResource        `hcl:"network,block"`
 	Meta                      map[string]string         `hcl:"meta,block"`
+	Env                       map[string]string         `hcl:"env,block"`
 	Services                  []*Service                `hcl:"service,block"`
 	ShutdownDelay             *time.Duration            `mapstructure:"shutdown_delay" hcl:"shutdown_delay,optional"`
 	StopAfterClientDisconnect *time.Duration            `mapstructure:"stop_after_client_disconnect" hcl:"stop_after_client_disconnect,optional"`
 	MaxClientDisconnect       *time.Duration            `mapstructure:"max_client_disconnect" hcl:"max_client_disconnect,optional"`
 	Scaling                   *ScalingPolicy            `hcl:"scaling,block"`
 	Consul                    *Consul                   `hcl:"consul,block"`
+	Disconnect                *DisconnectStrategy       `hcl:"disconnect,block"`
+	KillEscalation            []*KillEscalationStep     `hcl:"kill_escalation,block"`
 }
 
 // NewTaskGroup creates a new TaskGroup.
@@ -696,6 +716,14 @@ func (l *TaskLifecycle) Empty() bool {
 	return l == nil || (l.Hook == "")
 }
 
+// KillEscalationStep is a step of the escalation used to kill a task. The
+// signal is sent to the task, and the next step starts if the task is still
+// running after the timeout.
+type KillEscalationStep struct {
+	Signal  string        `mapstructure:"signal" hcl:"signal,optional"`
+	Timeout time.Duration `mapstructure:"timeout" hcl:"timeout,optional"`
+}
+
 // Task is a single process in a task group.
 type Task struct {
 	Name            string                 `hcl:"name,label"`
@@ -724,6 +752,7 @@ type Task struct {
 	KillSignal      string                 `mapstructure:"kill_signal" hcl:"kill_signal,optional"`
 	Kind            string                 `hcl:"kind,optional"`
 	ScalingPolicies []*ScalingPolicy       `hcl:"scaling,block"`
+	KillEscalation  []*KillEscalationStep  `hcl:"kill_escalation,block"`
 
 	// Identity is the default Nomad Workload Identity and will be added to
 	// Identities with the name "default"
@@ -761,6 +790,9 @@ func (t *Task) Canonicalize(tg *TaskGroup, job *Job) {
 	for _, tmpl := range t.Templates {
 		tmpl.Canonicalize()
 	}
+	for _, wid := range t.Identities {
+		wid.Canonicalize()
+	}
 	for _, s := range t.Services {
 		s.Canonicalize(t, tg, job)
 	}
@@ -1032,12 +1064,52 @@ type TaskState struct {
 	StartedAt   time.Time
 	FinishedAt  time.Time
 	Events      []*TaskEvent
+	HookTimings []*TaskHookTiming
+	Result      *TaskResult
 
 	// Experimental -  TaskHandle is based on drivers.TaskHandle and used
 	// by remote task drivers to migrate task handles between allocations.
 	TaskHandle *TaskHandle
 }
 
+// TaskHookTiming records the last run of a task hook.
+type TaskHookTiming struct {
+	Name       string
+	Phase      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// TaskResult classifies how the last run of a task ended.
+type TaskResult struct {
+	Class            string
+	ExitCode         int
+	Signal           int
+	OOMKilled        bool
+	DriverErrorClass string
+	HookName         string
+	HookPhase        string
+	Error            string
+	Time             time.Time
+}
+
+const (
+	TaskResultSuccess     = "success"
+	TaskResultExitFailure = "exit_failure"
+	TaskResultSignaled    = "signaled"
+	TaskResultOOMKilled   = "oom_killed"
+	TaskResultKilled      = "killed"
+	TaskResultDriverError = "driver_error"
+	TaskResultHookFailure = "hook_failure"
+)
+
+const (
+	TaskDriverErrorConfig       = "config"
+	TaskDriverErrorPluginExited = "plugin_exited"
+	TaskDriverErrorStart        = "start"
+	TaskDriverErrorWait         = "wait"
+)
+
 // Experimental - TaskHandle is based on drivers.TaskHandle and used by remote
 // task drivers to migrate task handles between allocations.
 type TaskHandle struct {
@@ -1066,6 +1138,9 @@ const (
 	TaskLeaderDead             = "Leader Task Dead"
 	TaskBuildingTaskDir        = "Building Task Directory"
 	TaskClientReconnected      = "Reconnected"
+	TaskDiskExceeded           = "Disk Resources Exceeded"
+	TaskDiskQuotaWarning       = "Disk Quota Warning"
+	TaskMigratingData          = "Migrating Data"
 )
 
 // TaskEvent is an event that effects the state of a task and contains meta-data
@@ -1166,10 +1241,58 @@ type WorkloadIdentity struct {
 	Audience     []string      `mapstructure:"aud" hcl:"aud,optional"`
 	ChangeMode   string        `mapstructure:"change_mode" hcl:"change_mode,optional"`
 	ChangeSignal string        `mapstructure:"change_signal" hcl:"change_signal,optional"`
+	ChangeScript *ChangeScript `mapstructure:"change_script" hcl:"change_script,block"`
 	Env          bool          `hcl:"env,optional"`
 	File         bool          `hcl:"file,optional"`
 	ServiceName  string        `hcl:"service_name,optional"`
 	TTL          time.Duration `mapstructure:"ttl" hcl:"ttl,optional"`
+	X509         bool          `mapstructure:"x509" hcl:"x509,optional"`
+
+	Projection *WorkloadIdentityProjection `mapstructure:"projection" hcl:"projection,block"`
+	SPIFFE     *WorkloadIdentitySPIFFE     `mapstructure:"spiffe" hcl:"spiffe,block"`
+	Cloud      *WorkloadIdentityCloud      `mapstructure:"cloud" hcl:"cloud,block"`
+
+	// ExtraClaims maps the names of extra claims of the identity to the keys
+	// of the task, group or job meta they are sourced from.
+	ExtraClaims map[string]string `mapstructure:"extra_claims" hcl:"extra_claims,optional"`
+}
+
+func (wi *WorkloadIdentity) Canonicalize() {
+	if wi == nil {
+		return
+	}
+	if wi.ChangeScript != nil {
+		wi.ChangeScript.Canonicalize()
+	}
+}
+
+// WorkloadIdentityProjection projects a workload identity into a directory of
+// its task, like Kubernetes projects the tokens of service accounts.
+type WorkloadIdentityProjection struct {
+	Destination string `mapstructure:"destination" hcl:"destination"`
+	File        string `mapstructure:"file" hcl:"file,optional"`
+	Perms       string `mapstructure:"perms" hcl:"perms,optional"`
+	CABundle    bool   `mapstructure:"ca_bundle" hcl:"ca_bundle,optional"`
+}
+
+// WorkloadIdentitySPIFFE writes SPIFFE SVIDs derived from a workload identity
+// into the secrets directory of its task.
+type WorkloadIdentitySPIFFE struct {
+	JWT  bool `mapstructure:"jwt" hcl:"jwt,optional"`
+	X509 bool `mapstructure:"x509" hcl:"x509,optional"`
+}
+
+// WorkloadIdentityCloud exchanges a workload identity for the credentials of
+// a cloud provider, which are written into the secrets directory of its task.
+type WorkloadIdentityCloud struct {
+	Provider                 string   `mapstructure:"provider" hcl:"provider"`
+	RoleARN                  string   `mapstructure:"role_arn" hcl:"role_arn,optional"`
+	Region                   string   `mapstructure:"region" hcl:"region,optional"`
+	WorkloadIdentityProvider string   `mapstructure:"workload_identity_provider" hcl:"workload_identity_provider,optional"`
+	ServiceAccount           string   `mapstructure:"service_account" hcl:"service_account,optional"`
+	TenantID                 string   `mapstructure:"tenant_id" hcl:"tenant_id,optional"`
+	ClientID                 string   `mapstructure:"client_id" hcl:"client_id,optional"`
+	Scopes                   []string `mapstructure:"scopes" hcl:"scopes,optional"`
 }
 
 type Action struct {
@@ -439,8 +439,31 @@ type VariableLock struct {
 	// before another client may acquire the lock. This helps protect against
 	// split-brains. This is a string version of a time.Duration like "2m".
 	LockDelay string
+
+	// Session ties the lock to the lifetime of the caller, either "alloc" or
+	// "token". While the session is alive, the lock is held even if it isn't
+	// renewed. The lock is released after its TTL once the allocation of the
+	// workload identity stops or the ACL token expires or is deleted.
+	Session string
+
+	// AllocID is the allocation holding the lock when Session is "alloc".
+	// It's set by Nomad.
+	AllocID string
+
+	// AccessorID is the accessor of the ACL token holding the lock when
+	// Session is "token". It's set by Nomad.
+	AccessorID string
 }
 
+const (
+	// VariableLockSessionAlloc ties a lock to the allocation of the workload
+	// identity that acquired it.
+	VariableLockSessionAlloc = "alloc"
+
+	// VariableLockSessionToken ties a lock to the ACL token that acquired it.
+	VariableLockSessionToken = "token"
+)
+
 // VariableItems are the key/value pairs of a Variable.
 type VariableItems map[string]string
 
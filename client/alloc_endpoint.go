@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/armon/go-metrics"
+	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/hashicorp/go-msgpack/codec"
 	"github.com/hashicorp/nomad/acl"
 	cstructs "github.com/hashicorp/nomad/client/structs"
@@ -163,6 +164,153 @@ func (a *Allocations) Checks(args *cstructs.AllocChecksRequest, reply *cstructs.
 	return nil
 }
 
+// Hooks is used to list the running hooks of an allocation and its tasks.
+func (a *Allocations) Hooks(args *cstructs.AllocHooksRequest, reply *cstructs.AllocHooksResponse) error {
+	defer metrics.MeasureSince([]string{"client", "allocations", "hooks"}, time.Now())
+
+	ar, err := a.c.getAllocRunner(args.AllocID)
+	if err != nil {
+		return err
+	}
+
+	// Check read-job permission
+	if aclObj, aclErr := a.c.ResolveToken(args.AuthToken); aclErr != nil {
+		return aclErr
+	} else if !aclObj.AllowNsOp(ar.Alloc().Namespace, acl.NamespaceCapabilityReadJob) {
+		return nstructs.ErrPermissionDenied
+	}
+
+	reply.Hooks = ar.RunningHooks()
+	return nil
+}
+
+// Identities is used to list the decoded signed workload identities of an
+// allocation and its tasks.
+func (a *Allocations) Identities(args *cstructs.AllocIdentitiesRequest, reply *cstructs.AllocIdentitiesResponse) error {
+	defer metrics.MeasureSince([]string{"client", "allocations", "identities"}, time.Now())
+
+	ar, err := a.c.getAllocRunner(args.AllocID)
+	if err != nil {
+		return err
+	}
+	alloc := ar.Alloc()
+
+	// Check read-job permission
+	if aclObj, aclErr := a.c.ResolveToken(args.AuthToken); aclErr != nil {
+		return aclErr
+	} else if !aclObj.AllowNsOp(alloc.Namespace, acl.NamespaceCapabilityReadJob) {
+		return nstructs.ErrPermissionDenied
+	}
+
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil {
+		return fmt.Errorf("unknown task group %q", alloc.TaskGroup)
+	}
+	if args.Task != "" && tg.LookupTask(args.Task) == nil {
+		return fmt.Errorf("unknown task name %q", args.Task)
+	}
+
+	reply.Identities = []*cstructs.AllocIdentity{}
+	for _, swid := range ar.SignedIdentities() {
+		id, err := decodeAllocIdentity(tg, swid)
+		if err != nil {
+			return fmt.Errorf("failed to decode identity %q of %q: %w",
+				swid.IdentityName, swid.WorkloadIdentifier, err)
+		}
+		if args.Task != "" && id.Task != args.Task {
+			continue
+		}
+		if args.Name != "" && id.Name != args.Name {
+			continue
+		}
+		reply.Identities = append(reply.Identities, id)
+	}
+	return nil
+}
+
+// decodeAllocIdentity decodes the claims of the signed workload identity of a
+// task or service of the task group. The signature isn't verified as the
+// identity was retrieved from the servers by the client itself.
+func decodeAllocIdentity(tg *nstructs.TaskGroup, swid *nstructs.SignedWorkloadIdentity) (*cstructs.AllocIdentity, error) {
+	token, err := jwt.ParseSigned(swid.JWT)
+	if err != nil {
+		return nil, err
+	}
+	var claims jwt.Claims
+	allClaims := map[string]any{}
+	if err := token.UnsafeClaimsWithoutVerification(&claims, &allClaims); err != nil {
+		return nil, err
+	}
+
+	id := &cstructs.AllocIdentity{
+		Name:      swid.IdentityName,
+		Audience:  claims.Audience,
+		Claims:    allClaims,
+		ExpiresAt: swid.Expiration,
+	}
+	if claims.IssuedAt != nil {
+		id.IssuedAt = claims.IssuedAt.Time().UTC()
+	}
+	if id.ExpiresAt.IsZero() && claims.Expiry != nil {
+		id.ExpiresAt = claims.Expiry.Time().UTC()
+	}
+
+	switch swid.WorkloadType {
+	case nstructs.WorkloadTypeTask:
+		id.Task = swid.WorkloadIdentifier
+	case nstructs.WorkloadTypeService:
+		id.Service = swid.WorkloadIdentifier
+		for _, task := range tg.Tasks {
+			for _, service := range task.Services {
+				if service.Name == swid.WorkloadIdentifier {
+					id.Task = task.Name
+				}
+			}
+		}
+	}
+	return id, nil
+}
+
+// OverrideHook is used to skip or fail a running hook of an allocation or of
+// one of its tasks, for example a hook stuck waiting on an unhealthy plugin.
+func (a *Allocations) OverrideHook(args *cstructs.AllocHookOverrideRequest, reply *nstructs.GenericResponse) error {
+	defer metrics.MeasureSince([]string{"client", "allocations", "override_hook"}, time.Now())
+
+	ar, err := a.c.getAllocRunner(args.AllocID)
+	if err != nil {
+		return err
+	}
+
+	// Check namespace alloc-lifecycle permission.
+	if aclObj, err := a.c.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if !aclObj.AllowNsOp(ar.Alloc().Namespace, acl.NamespaceCapabilityAllocLifecycle) {
+		return nstructs.ErrPermissionDenied
+	}
+
+	return ar.OverrideHook(args.Task, args.Hook, args.Action)
+}
+
+// AuthorizeMigration is used by the servers to authorize another client to
+// snapshot an allocation with a one-time token, when introducing that client
+// to migrate the data of the allocation. Only servers can make RPCs to
+// clients, so the request isn't authenticated further.
+func (a *Allocations) AuthorizeMigration(args *nstructs.AllocMigrationAuthorizeRequest, reply *nstructs.GenericResponse) error {
+	defer metrics.MeasureSince([]string{"client", "allocations", "authorize_migration"}, time.Now())
+
+	if args.Token == "" {
+		return errors.New("missing migration token")
+	}
+	if _, err := a.c.GetAlloc(args.PrevAllocID); err != nil {
+		return err
+	}
+
+	a.c.migrationGrants.add(args.Token, args.PrevAllocID, args.ExpiresAt)
+	a.c.logger.Debug("authorized migration of allocation",
+		"alloc_id", args.PrevAllocID, "dest_alloc_id", args.AllocID, "dest_node_id", args.NodeID)
+	return nil
+}
+
 // exec is used to execute command in a running task
 func (a *Allocations) exec(conn io.ReadWriteCloser) {
 	defer metrics.MeasureSince([]string{"client", "allocations", "exec"}, time.Now())
@@ -323,6 +471,14 @@ func (a *Allocations) execImpl(encoder *codec.Encoder, decoder *codec.Decoder, e
 
 	err = h(ctx, req.Cmd, req.Tty, newExecStream(decoder, encoder))
 	if err != nil {
+		// Drivers may implement exec without advertising it, so it's only
+		// reported as unsupported once it fails. Tasks injected into the
+		// allocation such as Connect sidecars may not use the driver of the
+		// job.
+		if !capabilities.Exec {
+			return pointer.Of(int64(http.StatusBadRequest)),
+				fmt.Errorf("driver of task %q does not support exec: %v", req.Task, err)
+		}
 		code := pointer.Of(int64(500))
 		return code, err
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/lib/proclib"
 	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/client/widmgr"
 	"github.com/hashicorp/nomad/helper/pluginutils/catalog"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad"
@@ -28,6 +29,7 @@ import (
 	"github.com/hashicorp/nomad/plugins/drivers"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/unix"
 )
@@ -579,6 +581,106 @@ func TestAllocations_Stats_ACL(t *testing.T) {
 	}
 }
 
+func TestAllocations_Identities_ACL(t *testing.T) {
+	ci.Parallel(t)
+
+	server, addr, root, cleanupS := testACLServer(t, nil)
+	defer cleanupS()
+
+	client, cleanupC := TestClient(t, func(c *config.Config) {
+		c.Servers = []string{addr}
+		c.ACLEnabled = true
+	})
+	defer cleanupC()
+
+	waitTilNodeReady(client, t)
+
+	alloc := mock.Alloc()
+	alloc.NodeID = client.NodeID()
+	alloc.Job.TaskGroups[0].Tasks[0].Driver = "mock_driver"
+	alloc.Job.TaskGroups[0].Tasks[0].Config = map[string]interface{}{
+		"run_for": "20s",
+	}
+	state := server.State()
+	must.NoError(t, state.UpsertJob(nstructs.MsgTypeTestSetup, 100, nil, alloc.Job))
+	must.NoError(t, state.UpsertAllocs(nstructs.MsgTypeTestSetup, 101, []*nstructs.Allocation{alloc}))
+	must.Wait(t, wait.InitialSuccess(
+		wait.ErrorFunc(func() error {
+			_, err := client.getAllocRunner(alloc.ID)
+			return err
+		}),
+		wait.Timeout(10*time.Second),
+		wait.Gap(50*time.Millisecond),
+	))
+
+	// Try request without a token and expect failure
+	req := &cstructs.AllocIdentitiesRequest{AllocID: alloc.ID}
+	var resp cstructs.AllocIdentitiesResponse
+	err := client.ClientRPC("Allocations.Identities", req, &resp)
+	must.ErrorContains(t, err, nstructs.ErrPermissionDenied.Error())
+
+	// Try request with an invalid token and expect failure
+	token := mock.CreatePolicyAndToken(t, server.State(), 1005, "invalid", mock.NodePolicy(acl.PolicyDeny))
+	req.AuthToken = token.SecretID
+	err = client.ClientRPC("Allocations.Identities", req, &resp)
+	must.ErrorContains(t, err, nstructs.ErrPermissionDenied.Error())
+
+	// Try request with a valid token
+	token = mock.CreatePolicyAndToken(t, server.State(), 1007, "test-valid",
+		mock.NamespacePolicy(nstructs.DefaultNamespace, "", []string{acl.NamespaceCapabilityReadJob}))
+	req.AuthToken = token.SecretID
+	must.NoError(t, client.ClientRPC("Allocations.Identities", req, &resp))
+	must.NotNil(t, resp.Identities)
+
+	// Try request with an unknown task
+	req.AuthToken = root.SecretID
+	req.Task = "nope"
+	err = client.ClientRPC("Allocations.Identities", req, &resp)
+	must.ErrorContains(t, err, `unknown task name "nope"`)
+}
+
+func TestAllocations_decodeAllocIdentity(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	tg := alloc.Job.TaskGroups[0]
+	task := tg.Tasks[0]
+	service := task.Services[0]
+	widSpecs := []*nstructs.WorkloadIdentity{
+		{Name: "vault_default", Audience: []string{"vault.io"}, TTL: time.Hour},
+		{Name: "consul-service_web", ServiceName: service.Name, Audience: []string{"consul.io"}},
+	}
+	task.Identities = widSpecs[:1]
+	service.Identity = widSpecs[1]
+
+	signer := widmgr.NewMockWIDSigner(widSpecs)
+	swids, err := signer.SignIdentities(0, []*nstructs.WorkloadIdentityRequest{
+		{AllocID: alloc.ID, WIHandle: *task.IdentityHandle(widSpecs[0])},
+		{AllocID: alloc.ID, WIHandle: *service.IdentityHandle()},
+	})
+	must.NoError(t, err)
+
+	id, err := decodeAllocIdentity(tg, swids[0])
+	must.NoError(t, err)
+	must.Eq(t, "vault_default", id.Name)
+	must.Eq(t, task.Name, id.Task)
+	must.Eq(t, "", id.Service)
+	must.Eq(t, []string{"vault.io"}, id.Audience)
+	must.Eq(t, swids[0].Expiration, id.ExpiresAt)
+	must.Eq[any](t, alloc.ID, id.Claims["nomad_allocation_id"])
+
+	// Service identities are attributed to the task of the service
+	id, err = decodeAllocIdentity(tg, swids[1])
+	must.NoError(t, err)
+	must.Eq(t, "consul-service_web", id.Name)
+	must.Eq(t, task.Name, id.Task)
+	must.Eq(t, service.Name, id.Service)
+	must.Eq(t, []string{"consul.io"}, id.Audience)
+
+	_, err = decodeAllocIdentity(tg, &nstructs.SignedWorkloadIdentity{JWT: "invalid"})
+	must.Error(t, err)
+}
+
 func TestAlloc_Checks(t *testing.T) {
 	ci.Parallel(t)
 
@@ -880,6 +982,175 @@ func TestAlloc_ExecStreaming_DisableRemoteExec(t *testing.T) {
 	}
 }
 
+func TestAlloc_ExecStreaming_DriverNoExec(t *testing.T) {
+	ci.Parallel(t)
+
+	// Start a server and client with a driver that doesn't support exec
+	s, cleanupS := nomad.TestServer(t, nil)
+	defer cleanupS()
+	testutil.WaitForLeader(t, s.RPC)
+
+	c, cleanupC := TestClient(t, func(c *config.Config) {
+		c.Servers = []string{s.GetConfig().RPCAddr.String()}
+
+		pluginConfig := []*nconfig.PluginConfig{
+			{
+				Name:   "mock_driver",
+				Config: map[string]interface{}{"disable_exec": true},
+			},
+		}
+		c.PluginLoader = catalog.TestPluginLoaderWithOptions(t, "", map[string]string{}, pluginConfig)
+	})
+	defer cleanupC()
+
+	job := mock.BatchJob()
+	job.TaskGroups[0].Count = 1
+	job.TaskGroups[0].Tasks[0].Config = map[string]interface{}{
+		"run_for": "20s",
+	}
+
+	// Wait for client to be running job
+	testutil.WaitForRunning(t, s.RPC, job)
+
+	// Get the allocation ID
+	args := nstructs.AllocListRequest{}
+	args.Region = "global"
+	resp := nstructs.AllocListResponse{}
+	must.NoError(t, s.RPC("Alloc.List", &args, &resp))
+	must.Len(t, 1, resp.Allocations)
+
+	// Make the request
+	req := &cstructs.AllocExecRequest{
+		AllocID:      resp.Allocations[0].ID,
+		Task:         job.TaskGroups[0].Tasks[0].Name,
+		Cmd:          []string{"placeholder command"},
+		QueryOptions: nstructs.QueryOptions{Region: "global"},
+	}
+
+	// Get the handler
+	handler, err := c.StreamingRpcHandler("Allocations.Exec")
+	must.NoError(t, err)
+
+	// Create a pipe
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	defer p2.Close()
+
+	errCh := make(chan error)
+	frames := make(chan *drivers.ExecTaskStreamingResponseMsg)
+
+	// Start the handler
+	go handler(p2)
+	go decodeFrames(t, p1, frames, errCh)
+
+	// Send the request
+	encoder := codec.NewEncoder(p1, nstructs.MsgpackHandle)
+	must.NoError(t, encoder.Encode(req))
+
+	select {
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out")
+	case err := <-errCh:
+		must.ErrorContains(t, err, "does not support exec")
+	case f := <-frames:
+		t.Fatalf("received unexpected frame: %#v", f)
+	}
+}
+
+// TestAlloc_ExecStreaming_DriverNoExec_Implemented asserts that exec is still
+// attempted for drivers which implement it without advertising it.
+func TestAlloc_ExecStreaming_DriverNoExec_Implemented(t *testing.T) {
+	ci.Parallel(t)
+
+	// Start a server and client with a driver that doesn't advertise exec
+	s, cleanupS := nomad.TestServer(t, nil)
+	defer cleanupS()
+	testutil.WaitForLeader(t, s.RPC)
+
+	c, cleanupC := TestClient(t, func(c *config.Config) {
+		c.Servers = []string{s.GetConfig().RPCAddr.String()}
+
+		pluginConfig := []*nconfig.PluginConfig{
+			{
+				Name:   "mock_driver",
+				Config: map[string]interface{}{"disable_exec": true},
+			},
+		}
+		c.PluginLoader = catalog.TestPluginLoaderWithOptions(t, "", map[string]string{}, pluginConfig)
+	})
+	defer cleanupC()
+
+	expectedStdout := "Hello from the other side\n"
+	job := mock.BatchJob()
+	job.TaskGroups[0].Count = 1
+	job.TaskGroups[0].Tasks[0].Config = map[string]interface{}{
+		"run_for": "20s",
+		"exec_command": map[string]interface{}{
+			"run_for":       "1ms",
+			"stdout_string": expectedStdout,
+			"exit_code":     3,
+		},
+	}
+
+	// Wait for client to be running job
+	testutil.WaitForRunning(t, s.RPC, job)
+
+	// Get the allocation ID
+	args := nstructs.AllocListRequest{}
+	args.Region = "global"
+	resp := nstructs.AllocListResponse{}
+	must.NoError(t, s.RPC("Alloc.List", &args, &resp))
+	must.Len(t, 1, resp.Allocations)
+
+	// Make the request
+	req := &cstructs.AllocExecRequest{
+		AllocID:      resp.Allocations[0].ID,
+		Task:         job.TaskGroups[0].Tasks[0].Name,
+		Cmd:          []string{"placeholder command"},
+		QueryOptions: nstructs.QueryOptions{Region: "global"},
+	}
+
+	// Get the handler
+	handler, err := c.StreamingRpcHandler("Allocations.Exec")
+	must.NoError(t, err)
+
+	// Create a pipe
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	defer p2.Close()
+
+	errCh := make(chan error)
+	frames := make(chan *drivers.ExecTaskStreamingResponseMsg)
+
+	// Start the handler
+	go handler(p2)
+	go decodeFrames(t, p1, frames, errCh)
+
+	// Send the request
+	encoder := codec.NewEncoder(p1, nstructs.MsgpackHandle)
+	must.NoError(t, encoder.Encode(req))
+
+	timeout := time.After(3 * time.Second)
+	receivedStdout := ""
+	for {
+		select {
+		case <-timeout:
+			t.Fatalf("timed out, received stdout %q", receivedStdout)
+		case err := <-errCh:
+			must.NoError(t, err)
+		case f := <-frames:
+			if f.Stdout != nil {
+				receivedStdout += string(f.Stdout.Data)
+			}
+			if f.Exited && f.Result != nil {
+				must.Eq(t, expectedStdout, receivedStdout)
+				must.Eq(t, 3, f.Result.ExitCode)
+				return
+			}
+		}
+	}
+}
+
 func TestAlloc_ExecStreaming_ACL_Basic(t *testing.T) {
 	ci.Parallel(t)
 
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// migrationGrant authorizes a client to snapshot an allocation once.
+type migrationGrant struct {
+	allocID   string
+	expiresAt time.Time
+}
+
+// migrationGrants tracks the one-time tokens the servers authorized other
+// clients to snapshot the allocations of this client with, when introducing
+// them to migrate the data of an allocation directly from this client.
+type migrationGrants struct {
+	grants map[string]*migrationGrant
+	lock   sync.Mutex
+}
+
+func newMigrationGrants() *migrationGrants {
+	return &migrationGrants{
+		grants: make(map[string]*migrationGrant),
+	}
+}
+
+// add authorizes the token to snapshot the allocation until it expires.
+func (g *migrationGrants) add(token, allocID string, expiresAt time.Time) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	// Tokens are rarely granted, so prune the expired ones here instead of
+	// running a reaper.
+	now := time.Now()
+	for t, grant := range g.grants {
+		if now.After(grant.expiresAt) {
+			delete(g.grants, t)
+		}
+	}
+
+	g.grants[token] = &migrationGrant{
+		allocID:   allocID,
+		expiresAt: expiresAt,
+	}
+}
+
+// consume returns true if the token is authorized to snapshot the allocation,
+// and revokes it so it can't be used again.
+func (g *migrationGrants) consume(token, allocID string) bool {
+	if token == "" {
+		return false
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	grant, ok := g.grants[token]
+	if !ok || grant.allocID != allocID {
+		return false
+	}
+	delete(g.grants, token)
+	return time.Now().Before(grant.expiresAt)
+}
@@ -74,6 +74,10 @@ var (
 	// AllocHTTPSocket is the path relative to the task dir root for the unix
 	// socket connected to Consul's HTTP endpoint.
 	AllocHTTPSocket = filepath.Join(SharedAllocName, TmpDirName, "consul_http.sock")
+
+	// AllocIdentities is the name of the directory of the alloc dir the
+	// workload identities projected into the tasks are written to.
+	AllocIdentities = "identities"
 )
 
 // AllocDir allows creating, destroying, and accessing an allocation's
@@ -90,6 +94,15 @@ type AllocDir struct {
 	// TaskDirs is a mapping of task names to their non-shared directory.
 	TaskDirs map[string]*TaskDir
 
+	// IdentitiesDir is the directory the projected workload identities are
+	// written to. It's only created by BuildIdentitiesDir, and isn't shared
+	// with the tasks, which only mount their own projections.
+	IdentitiesDir string
+
+	// SecretsKeyring encrypts the secrets dirs of the tasks with fscrypt if
+	// set. It must be set before the task dirs are created.
+	SecretsKeyring *SecretsKeyring
+
 	// clientAllocDir is the client agent's root alloc directory. It must
 	// be excluded from chroots and is configured via client.alloc_dir.
 	clientAllocDir string
@@ -108,6 +121,7 @@ type AllocDirFS interface {
 	Stat(path string) (*cstructs.AllocFileInfo, error)
 	ReadAt(path string, offset int64) (io.ReadCloser, error)
 	Snapshot(w io.Writer) error
+	SnapshotSize() (int64, error)
 	BlockUntilExists(ctx context.Context, path string) (chan error, error)
 	ChangeEvents(ctx context.Context, path string, curOffset int64) (*watch.FileChanges, error)
 }
@@ -122,6 +136,7 @@ func NewAllocDir(logger hclog.Logger, clientAllocDir, allocID string) *AllocDir
 		AllocDir:       allocDir,
 		SharedDir:      filepath.Join(allocDir, SharedAllocName),
 		TaskDirs:       make(map[string]*TaskDir),
+		IdentitiesDir:  filepath.Join(allocDir, AllocIdentities),
 		logger:         logger,
 	}
 }
@@ -131,7 +146,7 @@ func (d *AllocDir) NewTaskDir(name string) *TaskDir {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	td := newTaskDir(d.logger, d.clientAllocDir, d.AllocDir, name)
+	td := newTaskDir(d.logger, d.clientAllocDir, d.AllocDir, name, d.SecretsKeyring)
 	d.TaskDirs[name] = td
 	return td
 }
@@ -146,11 +161,7 @@ func (d *AllocDir) Snapshot(w io.Writer) error {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	allocDataDir := filepath.Join(d.SharedDir, SharedDataDir)
-	rootPaths := []string{allocDataDir}
-	for _, taskdir := range d.TaskDirs {
-		rootPaths = append(rootPaths, taskdir.LocalDir)
-	}
+	rootPaths := d.snapshotPaths()
 
 	tw := tar.NewWriter(w)
 	defer tw.Close()
@@ -221,6 +232,41 @@ func (d *AllocDir) Snapshot(w io.Writer) error {
 	return nil
 }
 
+// SnapshotSize returns the size of the regular files a snapshot of the alloc
+// dir would include, which allows the migration of the snapshot to report its
+// progress.
+func (d *AllocDir) SnapshotSize() (int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var size int64
+	for _, path := range d.snapshotPaths() {
+		err := filepath.Walk(path, func(path string, fileInfo os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fileInfo.Mode().IsRegular() {
+				size += fileInfo.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to compute the snapshot size of %s: %v", path, err)
+		}
+	}
+	return size, nil
+}
+
+// snapshotPaths returns the directories included in snapshots. The caller
+// must hold the lock.
+func (d *AllocDir) snapshotPaths() []string {
+	paths := []string{filepath.Join(d.SharedDir, SharedDataDir)}
+	for _, taskdir := range d.TaskDirs {
+		paths = append(paths, taskdir.LocalDir)
+	}
+	return paths
+}
+
 // Move other alloc directory's shared path and local dir to this alloc dir.
 func (d *AllocDir) Move(other *AllocDir, tasks []*structs.Task) error {
 	d.mu.RLock()
@@ -304,7 +350,7 @@ func (d *AllocDir) UnmountAll() error {
 		}
 
 		if pathExists(dir.SecretsDir) {
-			if err := removeSecretDir(dir.SecretsDir); err != nil {
+			if err := dir.removeSecretsDir(); err != nil {
 				mErr.Errors = append(mErr.Errors,
 					fmt.Errorf("failed to remove the secret dir %q: %v", dir.SecretsDir, err))
 			}
@@ -323,6 +369,13 @@ func (d *AllocDir) UnmountAll() error {
 		}
 	}
 
+	if pathExists(d.IdentitiesDir) {
+		if err := removeSecretDir(d.IdentitiesDir); err != nil {
+			mErr.Errors = append(mErr.Errors,
+				fmt.Errorf("failed to remove the identities dir %q: %v", d.IdentitiesDir, err))
+		}
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -361,6 +414,12 @@ func (d *AllocDir) Build() error {
 	return nil
 }
 
+// BuildIdentitiesDir creates the directory the projected workload identities
+// are written to, backed by a tmpfs like the secrets dirs of the tasks.
+func (d *AllocDir) BuildIdentitiesDir() error {
+	return createSecretDir(d.IdentitiesDir)
+}
+
 // List returns the list of files at a path relative to the alloc dir
 func (d *AllocDir) List(path string) ([]*cstructs.AllocFileInfo, error) {
 	if escapes, err := escapingfs.PathEscapesAllocDir(d.AllocDir, "", path); err != nil {
@@ -659,6 +718,10 @@ func splitPath(path string) ([]fileInfo, error) {
 	return dirs, nil
 }
 
+// SnapshotSizeHeader is the HTTP header the size of a snapshot is returned in
+// before its archive is streamed. See SnapshotSize().
+const SnapshotSizeHeader = "X-Nomad-Snapshot-Size"
+
 // SnapshotErrorFilename returns the filename which will exist if there was an
 // error snapshotting a tar.
 func SnapshotErrorFilename(allocID string) string {
@@ -217,6 +217,11 @@ func TestAllocDir_Snapshot(t *testing.T) {
 	if len(links) != 2 {
 		t.Fatalf("bad links: %#v", links)
 	}
+
+	// The size only includes the contents of the regular files
+	size, err := d.SnapshotSize()
+	require.NoError(t, err)
+	require.Equal(t, int64(6), size)
 }
 
 func TestAllocDir_Move(t *testing.T) {
@@ -0,0 +1,27 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build !linux
+
+package allocdir
+
+import (
+	"errors"
+	"os"
+)
+
+// createEncryptedSecretDir fails since fscrypt is only available on Linux.
+func createEncryptedSecretDir(dir, keyFile string, keyring *SecretsKeyring) error {
+	return errors.New("encrypting the secrets dir is only supported on Linux")
+}
+
+// removeEncryptedSecretDir removes the secrets dir and its key file.
+func removeEncryptedSecretDir(dir, keyFile string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Remove(keyFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocdir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/hashicorp/nomad/helper/crypto"
+	"golang.org/x/sys/unix"
+)
+
+// fscryptKeySize is the size of the fscrypt master keys of the secrets dirs.
+const fscryptKeySize = unix.FSCRYPT_MAX_KEY_SIZE
+
+// fscryptAddKeyArg is the argument of FS_IOC_ADD_ENCRYPTION_KEY, which is
+// followed by the raw key.
+type fscryptAddKeyArg struct {
+	unix.FscryptAddKeyArg
+	raw [fscryptKeySize]byte
+}
+
+// createEncryptedSecretDir creates the secrets dir at the given path,
+// encrypted with fscrypt by its own key. The key is wrapped by the secrets
+// keyring and written to keyFile. If the secrets dir already exists, its key
+// is added back to the filesystem, which unlocks it after a reboot.
+func createEncryptedSecretDir(dir, keyFile string, keyring *SecretsKeyring) error {
+	if unix.Geteuid() != 0 {
+		return errors.New("encrypting the secrets dir requires running the client as root")
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	key, _, err := keyring.readKey(keyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		if key, err = crypto.Bytes(fscryptKeySize); err != nil {
+			return fmt.Errorf("failed to generate secrets dir key: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to read secrets dir key: %w", err)
+	}
+
+	identifier, err := fscryptAddKey(dir, key)
+	if err != nil {
+		return err
+	}
+	if err := keyring.writeKey(keyFile, key, identifier); err != nil {
+		return fmt.Errorf("failed to write secrets dir key: %w", err)
+	}
+
+	// Setting the policy of a directory already encrypted with the same
+	// policy is a no-op, while it fails for non-empty unencrypted directories
+	// so secrets are never silently left in plaintext.
+	if err := fscryptSetPolicy(dir, identifier); err != nil {
+		return fmt.Errorf("failed to encrypt secrets dir %q, its filesystem may not support encryption: %w", dir, err)
+	}
+	return nil
+}
+
+// removeEncryptedSecretDir removes the key of the encrypted secrets dir from
+// the filesystem, which locks it, and removes it along with its key file.
+func removeEncryptedSecretDir(dir, keyFile string) error {
+	wrapped, err := readWrappedSecretsKey(keyFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if wrapped != nil && len(wrapped.Identifier) > 0 {
+		if err := fscryptRemoveKey(dir, wrapped.Identifier); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Remove(keyFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// fscryptAddKey adds the master key to the filesystem of dir, which unlocks
+// the directories encrypted with it, and returns its identifier. Adding a key
+// which was already added is a no-op.
+func fscryptAddKey(dir string, key []byte) ([]byte, error) {
+	var arg fscryptAddKeyArg
+	arg.Key_spec.Type = unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER
+	arg.Raw_size = uint32(copy(arg.raw[:], key))
+	defer clear(arg.raw[:])
+
+	if err := fscryptIoctl(dir, unix.FS_IOC_ADD_ENCRYPTION_KEY, unsafe.Pointer(&arg)); err != nil {
+		return nil, os.NewSyscallError("ioctl FS_IOC_ADD_ENCRYPTION_KEY", err)
+	}
+
+	identifier := make([]byte, unix.FSCRYPT_KEY_IDENTIFIER_SIZE)
+	copy(identifier, arg.Key_spec.U[:])
+	return identifier, nil
+}
+
+// fscryptSetPolicy encrypts the empty directory with the key of the
+// identifier.
+func fscryptSetPolicy(dir string, identifier []byte) error {
+	policy := unix.FscryptPolicyV2{
+		Version:                   unix.FSCRYPT_POLICY_V2,
+		Contents_encryption_mode:  unix.FSCRYPT_MODE_AES_256_XTS,
+		Filenames_encryption_mode: unix.FSCRYPT_MODE_AES_256_CTS,
+		Flags:                     unix.FSCRYPT_POLICY_FLAGS_PAD_32,
+	}
+	copy(policy.Master_key_identifier[:], identifier)
+
+	if err := fscryptIoctl(dir, unix.FS_IOC_SET_ENCRYPTION_POLICY, unsafe.Pointer(&policy)); err != nil {
+		return os.NewSyscallError("ioctl FS_IOC_SET_ENCRYPTION_POLICY", err)
+	}
+	return nil
+}
+
+// fscryptRemoveKey removes the key of the identifier from the filesystem of
+// dir, which locks the directories encrypted with it. Removing a key which
+// isn't present is a no-op.
+func fscryptRemoveKey(dir string, identifier []byte) error {
+	var arg unix.FscryptRemoveKeyArg
+	arg.Key_spec.Type = unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER
+	copy(arg.Key_spec.U[:], identifier)
+
+	err := fscryptIoctl(dir, unix.FS_IOC_REMOVE_ENCRYPTION_KEY, unsafe.Pointer(&arg))
+	if err != nil && !errors.Is(err, unix.ENOKEY) && !errors.Is(err, os.ErrNotExist) {
+		return os.NewSyscallError("ioctl FS_IOC_REMOVE_ENCRYPTION_KEY", err)
+	}
+	return nil
+}
+
+func fscryptIoctl(dir string, req uint, arg unsafe.Pointer) error {
+	f, err := os.OpenFile(dir, os.O_RDONLY|syscall.O_DIRECTORY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), uintptr(req), uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
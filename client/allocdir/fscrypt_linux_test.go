@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocdir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/shoenig/test/must"
+	"golang.org/x/sys/unix"
+)
+
+// TestLinuxEncryptedSecretDir asserts encrypted secret dir creation, rekeying,
+// and removal. The temporary dir must be on a filesystem supporting fscrypt.
+func TestLinuxEncryptedSecretDir(t *testing.T) {
+	ci.Parallel(t)
+	if unix.Geteuid() != 0 {
+		t.Skip("Must be run as root")
+	}
+
+	clientAllocDir := t.TempDir()
+	allocDir := filepath.Join(clientAllocDir, "alloc")
+	secretsDir := filepath.Join(allocDir, "web", TaskSecrets)
+	keyFile := filepath.Join(allocDir, ".web"+secretsKeySuffix)
+
+	keyring, err := NewSecretsKeyring(filepath.Join(t.TempDir(), "secrets-keyring.json"))
+	must.NoError(t, err)
+
+	if err := createEncryptedSecretDir(secretsDir, keyFile, keyring); err != nil {
+		t.Skipf("fscrypt not supported: %v", err)
+	}
+	t.Cleanup(func() { removeEncryptedSecretDir(secretsDir, keyFile) })
+
+	secret := filepath.Join(secretsDir, "nomad_token")
+	must.NoError(t, os.WriteFile(secret, []byte("token"), 0o600))
+
+	// The secrets dir remains readable after its key is rewrapped and added
+	// back to the filesystem
+	must.NoError(t, keyring.Rotate(clientAllocDir))
+	must.NoError(t, createEncryptedSecretDir(secretsDir, keyFile, keyring))
+	buf, err := os.ReadFile(secret)
+	must.NoError(t, err)
+	must.Eq(t, "token", string(buf))
+
+	// Removing the key from the filesystem, as on reboots, locks the
+	// secrets dir until its key is added back
+	wrapped, err := readWrappedSecretsKey(keyFile)
+	must.NoError(t, err)
+	must.NoError(t, fscryptRemoveKey(secretsDir, wrapped.Identifier))
+	_, err = os.ReadFile(secret)
+	must.Error(t, err)
+	must.NoError(t, createEncryptedSecretDir(secretsDir, keyFile, keyring))
+	buf, err = os.ReadFile(secret)
+	must.NoError(t, err)
+	must.Eq(t, "token", string(buf))
+
+	// Unencrypted secrets dirs with content are never encrypted in place
+	plainDir := filepath.Join(allocDir, "api", TaskSecrets)
+	must.NoError(t, os.MkdirAll(plainDir, 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(plainDir, "nomad_token"), []byte("token"), 0o600))
+	must.Error(t, createEncryptedSecretDir(plainDir, filepath.Join(allocDir, ".api"+secretsKeySuffix), keyring))
+
+	must.NoError(t, removeEncryptedSecretDir(secretsDir, keyFile))
+	must.NoError(t, removeEncryptedSecretDir(secretsDir, keyFile))
+	must.FileNotExists(t, secretsDir)
+	must.FileNotExists(t, keyFile)
+}
+
+// TestLinuxTaskDir_EncryptedSecrets asserts task dirs encrypt their secrets
+// dir when the alloc dir has a secrets keyring.
+func TestLinuxTaskDir_EncryptedSecrets(t *testing.T) {
+	ci.Parallel(t)
+	if unix.Geteuid() != 0 {
+		t.Skip("Must be run as root")
+	}
+
+	keyring, err := NewSecretsKeyring(filepath.Join(t.TempDir(), "secrets-keyring.json"))
+	must.NoError(t, err)
+
+	allocDir := NewAllocDir(testlog.HCLogger(t), t.TempDir(), "alloc")
+	allocDir.SecretsKeyring = keyring
+	must.NoError(t, allocDir.Build())
+	defer allocDir.Destroy()
+
+	td := allocDir.NewTaskDir("web")
+	if err := td.Build(false, nil); err != nil {
+		t.Skipf("fscrypt not supported: %v", err)
+	}
+	must.FileExists(t, td.SecretsKeyFile)
+	must.NoError(t, td.UnlockSecretsDir())
+	must.NoError(t, os.WriteFile(filepath.Join(td.SecretsDir, "nomad_token"), []byte("token"), 0o600))
+
+	must.NoError(t, allocDir.Destroy())
+	must.FileNotExists(t, td.SecretsDir)
+	must.FileNotExists(t, td.SecretsKeyFile)
+}
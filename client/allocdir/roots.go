@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocdir
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+const (
+	// RootPlacementMostFree places new alloc dirs in the root with the most
+	// free disk space.
+	RootPlacementMostFree = "most_free"
+
+	// RootPlacementRoundRobin places new alloc dirs in each root in turn.
+	RootPlacementRoundRobin = "round_robin"
+
+	// RootPlacementJobType places the alloc dirs of the allocations of the
+	// same job type in the same root, to isolate the disk I/O of batch jobs
+	// from service jobs for example.
+	RootPlacementJobType = "job_type"
+)
+
+// RootPlacements are the valid policies of placement of new alloc dirs.
+var RootPlacements = []string{
+	RootPlacementMostFree,
+	RootPlacementRoundRobin,
+	RootPlacementJobType,
+}
+
+// jobTypes is the order of the job types assigned to the roots by the job_type
+// placement.
+var jobTypes = []string{
+	structs.JobTypeService,
+	structs.JobTypeBatch,
+	structs.JobTypeSystem,
+	structs.JobTypeSysBatch,
+}
+
+// Roots selects the root of the alloc dir of each allocation among the alloc
+// dir roots of the client, such as to spread the ephemeral disk usage of the
+// allocations over several disks.
+type Roots struct {
+	roots     []string
+	placement string
+
+	// next is the index of the root of the next alloc dir placed by the
+	// round_robin placement
+	next int
+	lock sync.Mutex
+
+	// diskFree returns the free disk space of the root, and is overridden
+	// in tests
+	diskFree func(root string) (uint64, error)
+}
+
+// NewRoots returns the Roots selecting among roots, the first of which is the
+// root of the alloc dirs when placement is unknown.
+func NewRoots(roots []string, placement string) *Roots {
+	return &Roots{
+		roots:     roots,
+		placement: placement,
+		diskFree: func(root string) (uint64, error) {
+			usage, err := disk.Usage(root)
+			if err != nil {
+				return 0, err
+			}
+			return usage.Free, nil
+		},
+	}
+}
+
+// All returns all the roots.
+func (r *Roots) All() []string {
+	return r.roots
+}
+
+// Find returns the root containing the alloc dir of the allocation, and false
+// if the alloc dir doesn't exist.
+func (r *Roots) Find(allocID string) (string, bool) {
+	for _, root := range r.roots {
+		if _, err := os.Stat(filepath.Join(root, allocID)); err == nil {
+			return root, true
+		}
+	}
+	return "", false
+}
+
+// Select returns the root of the alloc dir of the allocation. The root of an
+// existing alloc dir is kept, such as when the client restores allocations
+// after a restart, and allocations with a sticky ephemeral disk are placed in
+// the root of their previous allocation so its data can be moved.
+func (r *Roots) Select(alloc *structs.Allocation) string {
+	if len(r.roots) == 1 {
+		return r.roots[0]
+	}
+	if root, ok := r.Find(alloc.ID); ok {
+		return root
+	}
+	if alloc.PreviousAllocation != "" {
+		if tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup); tg != nil && tg.EphemeralDisk != nil && tg.EphemeralDisk.Sticky {
+			if root, ok := r.Find(alloc.PreviousAllocation); ok {
+				return root
+			}
+		}
+	}
+
+	switch r.placement {
+	case RootPlacementRoundRobin:
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		root := r.roots[r.next%len(r.roots)]
+		r.next++
+		return root
+	case RootPlacementJobType:
+		for i, jobType := range jobTypes {
+			if alloc.Job != nil && alloc.Job.Type == jobType {
+				return r.roots[i%len(r.roots)]
+			}
+		}
+		return r.roots[0]
+	case RootPlacementMostFree:
+		return r.mostFree()
+	default:
+		return r.roots[0]
+	}
+}
+
+// mostFree returns the root with the most free disk space, ignoring the roots
+// whose disk space can't be determined.
+func (r *Roots) mostFree() string {
+	selected := r.roots[0]
+	var maxFree uint64
+	for _, root := range r.roots {
+		free, err := r.diskFree(root)
+		if err != nil {
+			continue
+		}
+		if free > maxFree {
+			selected, maxFree = root, free
+		}
+	}
+	return selected
+}
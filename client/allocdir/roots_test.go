@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocdir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestRoots_Select(t *testing.T) {
+	ci.Parallel(t)
+
+	roots := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+
+	t.Run("single root", func(t *testing.T) {
+		r := NewRoots(roots[:1], RootPlacementRoundRobin)
+		must.Eq(t, roots[0], r.Select(mock.Alloc()))
+		must.Eq(t, roots[0], r.Select(mock.Alloc()))
+	})
+
+	t.Run("round robin", func(t *testing.T) {
+		r := NewRoots(roots, RootPlacementRoundRobin)
+		for i := 0; i < 6; i++ {
+			must.Eq(t, roots[i%3], r.Select(mock.Alloc()))
+		}
+	})
+
+	t.Run("most free", func(t *testing.T) {
+		r := NewRoots(roots, RootPlacementMostFree)
+		free := map[string]uint64{roots[0]: 10, roots[1]: 30, roots[2]: 20}
+		r.diskFree = func(root string) (uint64, error) {
+			return free[root], nil
+		}
+		must.Eq(t, roots[1], r.Select(mock.Alloc()))
+
+		// Roots whose disk space is unknown are ignored
+		r.diskFree = func(root string) (uint64, error) {
+			if root == roots[1] {
+				return 0, errors.New("unknown")
+			}
+			return free[root], nil
+		}
+		must.Eq(t, roots[2], r.Select(mock.Alloc()))
+	})
+
+	t.Run("job type", func(t *testing.T) {
+		r := NewRoots(roots[:2], RootPlacementJobType)
+		must.Eq(t, roots[0], r.Select(mock.Alloc()))
+		must.Eq(t, roots[1], r.Select(mock.BatchAlloc()))
+		must.Eq(t, roots[0], r.Select(mock.SystemAlloc()))
+		must.Eq(t, roots[1], r.Select(mock.SysBatchAlloc()))
+	})
+
+	t.Run("existing alloc dir", func(t *testing.T) {
+		r := NewRoots(roots, RootPlacementRoundRobin)
+		alloc := mock.Alloc()
+		must.NoError(t, os.Mkdir(filepath.Join(roots[2], alloc.ID), 0o755))
+		must.Eq(t, roots[2], r.Select(alloc))
+		must.Eq(t, roots[2], r.Select(alloc))
+	})
+
+	t.Run("sticky ephemeral disk", func(t *testing.T) {
+		r := NewRoots(roots, RootPlacementRoundRobin)
+		prev := mock.Alloc()
+		must.NoError(t, os.Mkdir(filepath.Join(roots[1], prev.ID), 0o755))
+
+		alloc := mock.Alloc()
+		alloc.PreviousAllocation = prev.ID
+		must.Eq(t, roots[0], r.Select(alloc))
+
+		alloc = mock.Alloc()
+		alloc.PreviousAllocation = prev.ID
+		alloc.Job.TaskGroups[0].EphemeralDisk = &structs.EphemeralDisk{Sticky: true}
+		must.Eq(t, roots[1], r.Select(alloc))
+	})
+}
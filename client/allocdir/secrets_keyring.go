@@ -0,0 +1,353 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocdir
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper/crypto"
+	"github.com/hashicorp/nomad/helper/uuid"
+)
+
+const (
+	// secretsRootKeySize is the size of the AES-256-GCM root keys wrapping
+	// the keys of the secrets dirs.
+	secretsRootKeySize = 32
+
+	// secretsKeySuffix is the suffix of the files of the alloc dir holding
+	// the wrapped keys of the encrypted secrets dirs of its tasks.
+	secretsKeySuffix = ".secrets.key"
+)
+
+// SecretsKeyring holds the root keys of the client wrapping the keys of the
+// encrypted secrets dirs of the tasks. Each secrets dir is encrypted with its
+// own key, which is stored in the alloc dir wrapped by the active root key.
+//
+// Rotating the root key rewraps the keys of the secrets dirs, so the content
+// of the secrets dirs doesn't have to be encrypted again and the directories
+// mounted into the tasks are left untouched.
+type SecretsKeyring struct {
+	path string
+
+	// lock is held for reading while keys are wrapped and written, and for
+	// writing while the keys are rewrapped, so no key written during a
+	// rotation can be wrapped by a pruned root key.
+	lock   sync.RWMutex
+	active string
+	keys   map[string]*secretsRootKey
+}
+
+// secretsRootKey is a root key of the secrets keyring.
+type secretsRootKey struct {
+	ID         string
+	Key        []byte
+	CreateTime time.Time
+
+	aead cipher.AEAD
+}
+
+// secretsKeyringFile is the content of the file of the secrets keyring.
+type secretsKeyringFile struct {
+	Active string
+	Keys   []*secretsRootKey
+}
+
+// wrappedSecretsKey is the content of the file holding the key of an
+// encrypted secrets dir.
+type wrappedSecretsKey struct {
+	// RootKeyID is the ID of the root key wrapping the key.
+	RootKeyID string
+
+	// Identifier is the fscrypt identifier of the key, which is needed to
+	// remove it from the filesystem.
+	Identifier []byte
+
+	// Ciphertext is the nonce followed by the wrapped key.
+	Ciphertext []byte
+}
+
+// NewSecretsKeyring loads the secrets keyring stored at path, creating it
+// with a new root key if it doesn't exist.
+func NewSecretsKeyring(path string) (*SecretsKeyring, error) {
+	k := &SecretsKeyring{
+		path: path,
+		keys: make(map[string]*secretsRootKey),
+	}
+
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := k.addKeyLocked(); err != nil {
+			return nil, err
+		}
+		return k, k.saveLocked()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read secrets keyring: %w", err)
+	}
+
+	var file secretsKeyringFile
+	if err := json.Unmarshal(buf, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets keyring %q: %w", path, err)
+	}
+	for _, key := range file.Keys {
+		if err := key.init(); err != nil {
+			return nil, err
+		}
+		k.keys[key.ID] = key
+	}
+	if _, ok := k.keys[file.Active]; !ok {
+		return nil, fmt.Errorf("active key %q of secrets keyring %q not found", file.Active, path)
+	}
+	k.active = file.Active
+	return k, nil
+}
+
+func (key *secretsRootKey) init() error {
+	block, err := aes.NewCipher(key.Key)
+	if err != nil {
+		return fmt.Errorf("could not create cipher: %w", err)
+	}
+	key.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("could not create cipher: %w", err)
+	}
+	return nil
+}
+
+// ActiveKey returns the ID and the creation time of the active root key.
+func (k *SecretsKeyring) ActiveKey() (string, time.Time) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return k.active, k.keys[k.active].CreateTime
+}
+
+// Rotate creates a new active root key and rewraps the keys of the secrets
+// dirs of the allocations of the alloc dirs of the client with it.
+func (k *SecretsKeyring) Rotate(clientAllocDirs ...string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if err := k.addKeyLocked(); err != nil {
+		return err
+	}
+	if err := k.saveLocked(); err != nil {
+		return err
+	}
+	return k.rewrapLocked(clientAllocDirs)
+}
+
+// Rewrap rewraps the keys of the secrets dirs that are not wrapped by the
+// active root key, and removes the root keys which no longer wrap any key. It
+// completes rotations which failed to rewrap some keys.
+func (k *SecretsKeyring) Rewrap(clientAllocDirs ...string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.rewrapLocked(clientAllocDirs)
+}
+
+func (k *SecretsKeyring) rewrapLocked(clientAllocDirs []string) error {
+	// The keys of all the alloc dirs are collected before pruning, since the
+	// root keys are shared by the alloc dirs
+	var paths []string
+	for _, clientAllocDir := range clientAllocDirs {
+		dirPaths, err := secretsKeyFiles(clientAllocDir)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, dirPaths...)
+	}
+
+	// Keep the root keys of the keys which failed to be rewrapped, so their
+	// secrets dirs remain usable until the next attempt. Nothing is pruned
+	// if a key can't be read, since its root key is unknown.
+	var mErr multierror.Error
+	inUse := map[string]bool{k.active: true}
+	prune := true
+	for _, path := range paths {
+		wrapped, err := readWrappedSecretsKey(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			prune = false
+			mErr.Errors = append(mErr.Errors, err)
+			continue
+		}
+		if wrapped.RootKeyID == k.active {
+			continue
+		}
+
+		key, err := k.unwrapLocked(wrapped)
+		if err == nil {
+			err = k.writeKeyLocked(path, key, wrapped.Identifier)
+		}
+		if err != nil {
+			inUse[wrapped.RootKeyID] = true
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("failed to rewrap key %q: %w", path, err))
+		}
+	}
+
+	if prune {
+		for id := range k.keys {
+			if !inUse[id] {
+				delete(k.keys, id)
+			}
+		}
+		if err := k.saveLocked(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// writeKey wraps the key of a secrets dir with the active root key and
+// writes it to path.
+func (k *SecretsKeyring) writeKey(path string, key, identifier []byte) error {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return k.writeKeyLocked(path, key, identifier)
+}
+
+func (k *SecretsKeyring) writeKeyLocked(path string, key, identifier []byte) error {
+	rootKey := k.keys[k.active]
+	nonce, err := crypto.Bytes(rootKey.aead.NonceSize())
+	if err != nil {
+		return fmt.Errorf("failed to generate key wrapper nonce: %w", err)
+	}
+
+	buf, err := json.Marshal(&wrappedSecretsKey{
+		RootKeyID:  rootKey.ID,
+		Identifier: identifier,
+		Ciphertext: rootKey.aead.Seal(nonce, nonce, key, []byte(rootKey.ID)),
+	})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf)
+}
+
+// readKey returns the unwrapped key of a secrets dir, along with its fscrypt
+// identifier.
+func (k *SecretsKeyring) readKey(path string) ([]byte, []byte, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	wrapped, err := readWrappedSecretsKey(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := k.unwrapLocked(wrapped)
+	return key, wrapped.Identifier, err
+}
+
+func (k *SecretsKeyring) unwrapLocked(wrapped *wrappedSecretsKey) ([]byte, error) {
+	rootKey, ok := k.keys[wrapped.RootKeyID]
+	if !ok {
+		return nil, fmt.Errorf("root key %q of secrets keyring not found", wrapped.RootKeyID)
+	}
+
+	nonceSize := rootKey.aead.NonceSize()
+	if len(wrapped.Ciphertext) < nonceSize {
+		return nil, errors.New("wrapped key is too short")
+	}
+	nonce, ciphertext := wrapped.Ciphertext[:nonceSize], wrapped.Ciphertext[nonceSize:]
+	key, err := rootKey.aead.Open(nil, nonce, ciphertext, []byte(rootKey.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key: %w", err)
+	}
+	return key, nil
+}
+
+func (k *SecretsKeyring) addKeyLocked() error {
+	buf, err := crypto.Bytes(secretsRootKeySize)
+	if err != nil {
+		return fmt.Errorf("failed to generate root key: %w", err)
+	}
+	key := &secretsRootKey{
+		ID:         uuid.Generate(),
+		Key:        buf,
+		CreateTime: time.Now().UTC(),
+	}
+	if err := key.init(); err != nil {
+		return err
+	}
+	k.keys[key.ID] = key
+	k.active = key.ID
+	return nil
+}
+
+func (k *SecretsKeyring) saveLocked() error {
+	file := secretsKeyringFile{Active: k.active}
+	for _, key := range k.keys {
+		file.Keys = append(file.Keys, key)
+	}
+	buf, err := json.Marshal(&file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0o700); err != nil {
+		return err
+	}
+	return writeFileAtomic(k.path, buf)
+}
+
+func readWrappedSecretsKey(path string) (*wrappedSecretsKey, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var wrapped wrappedSecretsKey
+	if err := json.Unmarshal(buf, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse key %q: %w", path, err)
+	}
+	return &wrapped, nil
+}
+
+// secretsKeyFiles returns the paths of the wrapped keys of the secrets dirs of
+// the allocations of the alloc dir of the client.
+func secretsKeyFiles(clientAllocDir string) ([]string, error) {
+	allocs, err := os.ReadDir(clientAllocDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, alloc := range allocs {
+		if !alloc.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(clientAllocDir, alloc.Name()))
+		if err != nil {
+			// The allocation may have been garbage collected meanwhile
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.Type().IsRegular() && strings.HasPrefix(name, ".") && strings.HasSuffix(name, secretsKeySuffix) {
+				paths = append(paths, filepath.Join(clientAllocDir, alloc.Name(), name))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// writeFileAtomic writes a file only readable by its owner, replacing the
+// existing file if any.
+func writeFileAtomic(path string, buf []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
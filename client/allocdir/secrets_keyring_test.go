@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocdir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestSecretsKeyring_Persistence(t *testing.T) {
+	ci.Parallel(t)
+
+	path := filepath.Join(t.TempDir(), "state", "secrets-keyring.json")
+	keyring, err := NewSecretsKeyring(path)
+	must.NoError(t, err)
+	activeID, created := keyring.ActiveKey()
+	must.NotEq(t, "", activeID)
+	must.False(t, created.IsZero())
+
+	info, err := os.Stat(path)
+	must.NoError(t, err)
+	must.Eq(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Keys wrapped by the keyring can be unwrapped once it is loaded again
+	keyFile := filepath.Join(t.TempDir(), ".web"+secretsKeySuffix)
+	must.NoError(t, keyring.writeKey(keyFile, []byte("data key"), []byte("identifier")))
+
+	reloaded, err := NewSecretsKeyring(path)
+	must.NoError(t, err)
+	reloadedID, _ := reloaded.ActiveKey()
+	must.Eq(t, activeID, reloadedID)
+
+	key, identifier, err := reloaded.readKey(keyFile)
+	must.NoError(t, err)
+	must.Eq(t, []byte("data key"), key)
+	must.Eq(t, []byte("identifier"), identifier)
+}
+
+func TestSecretsKeyring_Rotate(t *testing.T) {
+	ci.Parallel(t)
+
+	clientAllocDir := t.TempDir()
+	keyring, err := NewSecretsKeyring(filepath.Join(t.TempDir(), "secrets-keyring.json"))
+	must.NoError(t, err)
+	oldID, _ := keyring.ActiveKey()
+
+	// Write the keys of the secrets dirs of two allocations
+	var keyFiles []string
+	for _, alloc := range []string{"alloc1", "alloc2"} {
+		must.NoError(t, os.MkdirAll(filepath.Join(clientAllocDir, alloc, "web"), 0o755))
+		keyFile := filepath.Join(clientAllocDir, alloc, ".web"+secretsKeySuffix)
+		must.NoError(t, keyring.writeKey(keyFile, []byte(alloc), nil))
+		keyFiles = append(keyFiles, keyFile)
+	}
+
+	must.NoError(t, keyring.Rotate(clientAllocDir))
+	newID, _ := keyring.ActiveKey()
+	must.NotEq(t, oldID, newID)
+
+	// The keys are rewrapped by the new root key, and the old one is removed
+	for i, keyFile := range keyFiles {
+		wrapped, err := readWrappedSecretsKey(keyFile)
+		must.NoError(t, err)
+		must.Eq(t, newID, wrapped.RootKeyID)
+
+		key, _, err := keyring.readKey(keyFile)
+		must.NoError(t, err)
+		must.Eq(t, []byte([]string{"alloc1", "alloc2"}[i]), key)
+	}
+	must.MapLen(t, 1, keyring.keys)
+
+	// Keys which can't be rewrapped keep their root key
+	must.NoError(t, os.Chmod(filepath.Dir(keyFiles[0]), 0o500))
+	t.Cleanup(func() { os.Chmod(filepath.Dir(keyFiles[0]), 0o755) })
+	if os.Geteuid() == 0 {
+		// Root ignores the permissions of directories
+		return
+	}
+	must.Error(t, keyring.Rotate(clientAllocDir))
+	must.MapLen(t, 2, keyring.keys)
+	must.MapContainsKey(t, keyring.keys, newID)
+
+	must.NoError(t, os.Chmod(filepath.Dir(keyFiles[0]), 0o755))
+	must.NoError(t, keyring.Rewrap(clientAllocDir))
+	must.MapLen(t, 1, keyring.keys)
+	must.MapNotContainsKey(t, keyring.keys, newID)
+}
+
+func TestSecretsKeyring_RotateAllocDirs(t *testing.T) {
+	ci.Parallel(t)
+
+	keyring, err := NewSecretsKeyring(filepath.Join(t.TempDir(), "secrets-keyring.json"))
+	must.NoError(t, err)
+
+	// The keys of the secrets dirs of all the alloc dirs are rewrapped
+	var keyFiles []string
+	clientAllocDirs := []string{t.TempDir(), t.TempDir()}
+	for _, clientAllocDir := range clientAllocDirs {
+		must.NoError(t, os.MkdirAll(filepath.Join(clientAllocDir, "alloc", "web"), 0o755))
+		keyFile := filepath.Join(clientAllocDir, "alloc", ".web"+secretsKeySuffix)
+		must.NoError(t, keyring.writeKey(keyFile, []byte("data key"), nil))
+		keyFiles = append(keyFiles, keyFile)
+	}
+
+	must.NoError(t, keyring.Rotate(clientAllocDirs...))
+	newID, _ := keyring.ActiveKey()
+	for _, keyFile := range keyFiles {
+		wrapped, err := readWrappedSecretsKey(keyFile)
+		must.NoError(t, err)
+		must.Eq(t, newID, wrapped.RootKeyID)
+	}
+	must.MapLen(t, 1, keyring.keys)
+}
@@ -45,6 +45,14 @@ type TaskDir struct {
 	// <task_dir>/private/
 	PrivateDir string
 
+	// SecretsKeyFile is the path to the wrapped key of the secrets dir on the
+	// host, if it is encrypted
+	// <alloc_dir>/.<task_name>.secrets.key
+	SecretsKeyFile string
+
+	// secretsKeyring encrypts the secrets dir if set
+	secretsKeyring *SecretsKeyring
+
 	// skip embedding these paths in chroots. Used for avoiding embedding
 	// client.alloc_dir recursively.
 	skip map[string]struct{}
@@ -56,7 +64,7 @@ type TaskDir struct {
 // create paths on disk.
 //
 // Call AllocDir.NewTaskDir to create new TaskDirs
-func newTaskDir(logger hclog.Logger, clientAllocDir, allocDir, taskName string) *TaskDir {
+func newTaskDir(logger hclog.Logger, clientAllocDir, allocDir, taskName string, keyring *SecretsKeyring) *TaskDir {
 	taskDir := filepath.Join(allocDir, taskName)
 
 	logger = logger.Named("task_dir").With("task_name", taskName)
@@ -73,6 +81,8 @@ func newTaskDir(logger hclog.Logger, clientAllocDir, allocDir, taskName string)
 		LocalDir:       filepath.Join(taskDir, TaskLocal),
 		SecretsDir:     filepath.Join(taskDir, TaskSecrets),
 		PrivateDir:     filepath.Join(taskDir, TaskPrivate),
+		SecretsKeyFile: filepath.Join(allocDir, "."+taskName+secretsKeySuffix),
+		secretsKeyring: keyring,
 		skip:           skip,
 		logger:         logger,
 	}
@@ -127,7 +137,11 @@ func (t *TaskDir) Build(createChroot bool, chroot map[string]string) error {
 	}
 
 	// Create the secret directory
-	if err := createSecretDir(t.SecretsDir); err != nil {
+	if t.secretsKeyring != nil {
+		if err := createEncryptedSecretDir(t.SecretsDir, t.SecretsKeyFile, t.secretsKeyring); err != nil {
+			return err
+		}
+	} else if err := createSecretDir(t.SecretsDir); err != nil {
 		return err
 	}
 
@@ -154,6 +168,25 @@ func (t *TaskDir) Build(createChroot bool, chroot map[string]string) error {
 	return nil
 }
 
+// UnlockSecretsDir adds the key of the encrypted secrets dir back to its
+// filesystem, which is needed after the host rebooted since the keys only
+// live in the kernel. It's a no-op if the secrets dir isn't encrypted.
+func (t *TaskDir) UnlockSecretsDir() error {
+	if t.secretsKeyring == nil || !pathExists(t.SecretsKeyFile) {
+		return nil
+	}
+	return createEncryptedSecretDir(t.SecretsDir, t.SecretsKeyFile, t.secretsKeyring)
+}
+
+// removeSecretsDir removes the secrets dir, along with its key if it is
+// encrypted.
+func (t *TaskDir) removeSecretsDir() error {
+	if pathExists(t.SecretsKeyFile) {
+		return removeEncryptedSecretDir(t.SecretsDir, t.SecretsKeyFile)
+	}
+	return removeSecretDir(t.SecretsDir)
+}
+
 // buildChroot takes a mapping of absolute directory or file paths on the host
 // to their intended, relative location within the task directory. This
 // attempts hardlink and then defaults to copying. If the path exists on the
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allochealth
+
+import (
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// healthChecks selects the service checks gating the health of an
+// allocation, and the share of them that must be passing, as set in the
+// update block of its group.
+type healthChecks struct {
+	// names is the set of the gating checks, or nil if all the checks gate
+	// the health of the allocation.
+	names map[string]struct{}
+
+	// minPercent is the percentage of the gating checks that must be passing.
+	minPercent int
+}
+
+// newHealthChecks returns the checks gating the health of an allocation
+// according to the update strategy. All the checks must be passing if the
+// update strategy is nil.
+func newHealthChecks(update *structs.UpdateStrategy) *healthChecks {
+	h := &healthChecks{minPercent: 100}
+	if update == nil {
+		return h
+	}
+
+	if len(update.HealthChecks) > 0 {
+		h.names = make(map[string]struct{}, len(update.HealthChecks))
+		for _, name := range update.HealthChecks {
+			h.names[name] = struct{}{}
+		}
+	}
+	if update.MinHealthyChecksPercent > 0 {
+		h.minPercent = update.MinHealthyChecksPercent
+	}
+	return h
+}
+
+// gates returns whether the check with the given name gates the health of the
+// allocation.
+func (h *healthChecks) gates(name string) bool {
+	if h.names == nil {
+		return true
+	}
+	_, ok := h.names[name]
+	return ok
+}
+
+// count returns the number of Consul and Nomad checks of the services that
+// gate the health of the allocation.
+func (h *healthChecks) count(services []*structs.Service) (consul, nomad int) {
+	for _, service := range services {
+		for _, check := range service.Checks {
+			if !h.gates(check.Name) {
+				continue
+			}
+			switch service.Provider {
+			case structs.ServiceProviderNomad:
+				nomad++
+			default:
+				consul++
+			}
+		}
+	}
+	return
+}
+
+// healthy returns whether enough of the gating checks are passing.
+func (h *healthChecks) healthy(passing, total int) bool {
+	return passing*100 >= total*h.minPercent
+}
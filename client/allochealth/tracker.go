@@ -62,6 +62,9 @@ type Tracker struct {
 	// group including task level checks.
 	nomadCheckCount int
 
+	// checks selects the service checks gating the health of the allocation
+	checks *healthChecks
+
 	// allocUpdates is a listener for retrieving new alloc updates
 	allocUpdates *cstructs.AllocListener
 
@@ -141,6 +144,14 @@ func NewTracker(
 		lifecycleTasks:      map[string]string{},
 	}
 
+	// The update block of the group may restrict the checks gating the health
+	// of the allocations of a deployment.
+	if alloc.DeploymentID != "" {
+		t.checks = newHealthChecks(t.tg.Update)
+	} else {
+		t.checks = newHealthChecks(nil)
+	}
+
 	// Build the map of TaskEnv for each task. Create the group-level TaskEnv
 	// first because taskEnvBuilder is mutated in every loop and we can't undo
 	// a call to UpdateTask().
@@ -157,12 +168,12 @@ func NewTracker(
 
 		t.taskEnvs[task.Name] = taskEnvBuilder.UpdateTask(alloc, task).Build()
 
-		c, n := countChecks(task.Services)
+		c, n := t.checks.count(task.Services)
 		t.consulCheckCount += c
 		t.nomadCheckCount += n
 	}
 
-	c, n := countChecks(t.tg.Services)
+	c, n := t.checks.count(t.tg.Services)
 	t.consulCheckCount += c
 	t.nomadCheckCount += n
 
@@ -170,18 +181,6 @@ func NewTracker(
 	return t
 }
 
-func countChecks(services []*structs.Service) (consul, nomad int) {
-	for _, service := range services {
-		switch service.Provider {
-		case structs.ServiceProviderNomad:
-			nomad += len(service.Checks)
-		default:
-			consul += len(service.Checks)
-		}
-	}
-	return
-}
-
 // Start starts the watcher.
 func (t *Tracker) Start() {
 	go t.watchTaskEvents()
@@ -226,7 +225,7 @@ func (t *Tracker) TaskEvents() map[string]*structs.TaskEvent {
 	// Go through are task information and build the event map
 	for task, state := range t.taskHealth {
 		useChecks := t.tg.Update.HealthCheck == structs.UpdateStrategyHealthCheck_Checks
-		if e, ok := state.event(deadline, t.tg.Update.HealthyDeadline, t.tg.Update.MinHealthyTime, useChecks); ok {
+		if e, ok := state.event(deadline, t.tg.Update.HealthyDeadline, t.tg.Update.MinHealthyTime, useChecks, t.checks); ok {
 			events[task] = structs.NewTaskEvent(AllocHealthEventSource).SetMessage(e)
 		}
 	}
@@ -537,7 +536,7 @@ OUTER:
 		}
 
 		// scan for missing or unhealthy consul checks
-		if !evaluateConsulChecks(interpolatedServices, allocReg) {
+		if !evaluateConsulChecks(interpolatedServices, allocReg, t.checks) {
 			t.setCheckHealth(false)
 			passed = false
 		}
@@ -556,7 +555,7 @@ OUTER:
 	}
 }
 
-func evaluateConsulChecks(services []*structs.Service, registrations *serviceregistration.AllocRegistration) bool {
+func evaluateConsulChecks(services []*structs.Service, registrations *serviceregistration.AllocRegistration, checks *healthChecks) bool {
 	// First, identify any case where a check definition is missing or outdated
 	// on the Consul side. Note that because check names are not unique, we must
 	// also keep track of the counts on each side and make sure those also match.
@@ -564,13 +563,17 @@ func evaluateConsulChecks(services []*structs.Service, registrations *servicereg
 	regChecks := make(map[string]int)
 	for _, service := range services {
 		for _, check := range service.Checks {
-			expChecks[check.Name]++
+			if checks.gates(check.Name) {
+				expChecks[check.Name]++
+			}
 		}
 	}
 	for _, task := range registrations.Tasks {
 		for _, service := range task.Services {
 			for _, check := range service.Checks {
-				regChecks[check.Name]++
+				if checks.gates(check.Name) {
+					regChecks[check.Name]++
+				}
 			}
 		}
 	}
@@ -580,26 +583,33 @@ func evaluateConsulChecks(services []*structs.Service, registrations *servicereg
 	}
 
 	// Now we can simply scan the status of each Check reported by Consul.
+	passing, total := 0, 0
 	for _, task := range registrations.Tasks {
 		for _, service := range task.Services {
 			for _, check := range service.Checks {
+				if !checks.gates(check.Name) {
+					continue
+				}
+				total++
+
 				onUpdate := service.CheckOnUpdate[check.CheckID]
 				switch check.Status {
 				case api.HealthWarning:
 					if onUpdate != structs.OnUpdateIgnoreWarn && onUpdate != structs.OnUpdateIgnore {
-						return false
+						continue
 					}
 				case api.HealthCritical:
 					if onUpdate != structs.OnUpdateIgnore {
-						return false
+						continue
 					}
 				}
+				passing++
 			}
 		}
 	}
 
-	// All checks are present and healthy.
-	return true
+	// All checks are present, and enough of them are healthy.
+	return checks.healthy(passing, total)
 }
 
 // watchNomadEvents is a watcher for the health of the allocation's Nomad checks.
@@ -646,29 +656,29 @@ func (t *Tracker) watchNomadEvents() {
 			primed = false
 		}
 
-		// scan to see if any checks are failing
-		passing := true
+		// scan to see if enough checks are passing
+		passingChecks, total := 0, 0
 		for _, result := range results {
+			if !t.checks.gates(result.Check) {
+				continue
+			}
+			total++
+
 			switch result.Status {
 			case structs.CheckSuccess:
-				continue
+				passingChecks++
 			case structs.CheckFailure:
 				if result.Mode == structs.Readiness {
-					continue
+					passingChecks++
 				}
-				passing = false
 			default:
 				// i.e. pending check; do not consider healthy or ready
-				passing = false
-			}
-
-			if !passing {
-				break // 1+ check is failing; no need to continue
 			}
 		}
+		passing := t.checks.healthy(passingChecks, total)
 
 		if !passing {
-			// at least one check is failing, transition to unhealthy
+			// too many checks are failing, transition to unhealthy
 			t.setCheckHealth(false)
 			primed = false
 			waiter.disable()
@@ -694,13 +704,9 @@ type taskHealthState struct {
 // event takes the deadline time for the allocation to be healthy and the update
 // strategy of the group. It returns true if the task has contributed to the
 // allocation being unhealthy and if so, an event description of why.
-func (t *taskHealthState) event(deadline time.Time, healthyDeadline, minHealthyTime time.Duration, useChecks bool) (string, bool) {
-	desiredChecks := 0
-	for _, s := range t.task.Services {
-		if nc := len(s.Checks); nc > 0 {
-			desiredChecks += nc
-		}
-	}
+func (t *taskHealthState) event(deadline time.Time, healthyDeadline, minHealthyTime time.Duration, useChecks bool, checks *healthChecks) (string, bool) {
+	consulChecks, nomadChecks := checks.count(t.task.Services)
+	desiredChecks := consulChecks + nomadChecks
 	requireChecks := (desiredChecks > 0) && useChecks
 
 	if t.state != nil {
@@ -728,23 +734,28 @@ func (t *taskHealthState) event(deadline time.Time, healthyDeadline, minHealthyT
 		var notPassing []string
 		passing := 0
 
-	OUTER:
 		for _, sreg := range t.taskRegistrations.Services {
+			serviceHealthy := true
 			for _, check := range sreg.Checks {
-				if check.Status != api.HealthPassing {
-					notPassing = append(notPassing, sreg.Service.Service)
-					continue OUTER
-				} else {
+				if !checks.gates(check.Name) {
+					continue
+				}
+				if check.Status == api.HealthPassing {
 					passing++
+				} else {
+					serviceHealthy = false
 				}
 			}
+			if !serviceHealthy {
+				notPassing = append(notPassing, sreg.Service.Service)
+			}
 		}
 
-		if len(notPassing) != 0 {
+		if len(notPassing) != 0 && !checks.healthy(passing, desiredChecks) {
 			return fmt.Sprintf("Services not healthy by deadline: %s", strings.Join(notPassing, ", ")), true
 		}
 
-		if passing != desiredChecks {
+		if !checks.healthy(passing, desiredChecks) {
 			return fmt.Sprintf("Only %d out of %d checks registered and passing", passing, desiredChecks), true
 		}
 
@@ -1197,6 +1197,7 @@ func TestTracker_evaluateConsulChecks(t *testing.T) {
 	cases := []struct {
 		name          string
 		tg            *structs.TaskGroup
+		update        *structs.UpdateStrategy
 		registrations *serviceregistration.AllocRegistration
 		exp           bool
 	}{
@@ -1310,6 +1311,154 @@ func TestTracker_evaluateConsulChecks(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "failing check not gating health",
+			exp:    true,
+			update: &structs.UpdateStrategy{HealthChecks: []string{"c2"}},
+			tg: &structs.TaskGroup{
+				Services: []*structs.Service{{
+					Name: "group-s1",
+					Checks: []*structs.ServiceCheck{
+						{Name: "c1"},
+						{Name: "c2"},
+					},
+				}},
+			},
+			registrations: &serviceregistration.AllocRegistration{
+				Tasks: map[string]*serviceregistration.ServiceRegistrations{
+					"group": {
+						Services: map[string]*serviceregistration.ServiceRegistration{
+							"abc123": {
+								ServiceID: "abc123",
+								Checks: []*consulapi.AgentCheck{
+									{
+										Name:      "c1",
+										Status:    consulapi.HealthCritical,
+										ServiceID: "abc123",
+									},
+									{
+										Name:      "c2",
+										Status:    consulapi.HealthPassing,
+										ServiceID: "abc123",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "failing check gating health",
+			exp:    false,
+			update: &structs.UpdateStrategy{HealthChecks: []string{"c1"}},
+			tg: &structs.TaskGroup{
+				Services: []*structs.Service{{
+					Name: "group-s1",
+					Checks: []*structs.ServiceCheck{
+						{Name: "c1"},
+						{Name: "c2"},
+					},
+				}},
+			},
+			registrations: &serviceregistration.AllocRegistration{
+				Tasks: map[string]*serviceregistration.ServiceRegistrations{
+					"group": {
+						Services: map[string]*serviceregistration.ServiceRegistration{
+							"abc123": {
+								ServiceID: "abc123",
+								Checks: []*consulapi.AgentCheck{
+									{
+										Name:      "c1",
+										Status:    consulapi.HealthCritical,
+										ServiceID: "abc123",
+									},
+									{
+										Name:      "c2",
+										Status:    consulapi.HealthPassing,
+										ServiceID: "abc123",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "enough checks passing",
+			exp:    true,
+			update: &structs.UpdateStrategy{MinHealthyChecksPercent: 50},
+			tg: &structs.TaskGroup{
+				Services: []*structs.Service{{
+					Name: "group-s1",
+					Checks: []*structs.ServiceCheck{
+						{Name: "c1"},
+						{Name: "c2"},
+					},
+				}},
+			},
+			registrations: &serviceregistration.AllocRegistration{
+				Tasks: map[string]*serviceregistration.ServiceRegistrations{
+					"group": {
+						Services: map[string]*serviceregistration.ServiceRegistration{
+							"abc123": {
+								ServiceID: "abc123",
+								Checks: []*consulapi.AgentCheck{
+									{
+										Name:      "c1",
+										Status:    consulapi.HealthCritical,
+										ServiceID: "abc123",
+									},
+									{
+										Name:      "c2",
+										Status:    consulapi.HealthPassing,
+										ServiceID: "abc123",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "not enough checks passing",
+			exp:    false,
+			update: &structs.UpdateStrategy{MinHealthyChecksPercent: 75},
+			tg: &structs.TaskGroup{
+				Services: []*structs.Service{{
+					Name: "group-s1",
+					Checks: []*structs.ServiceCheck{
+						{Name: "c1"},
+						{Name: "c2"},
+					},
+				}},
+			},
+			registrations: &serviceregistration.AllocRegistration{
+				Tasks: map[string]*serviceregistration.ServiceRegistrations{
+					"group": {
+						Services: map[string]*serviceregistration.ServiceRegistration{
+							"abc123": {
+								ServiceID: "abc123",
+								Checks: []*consulapi.AgentCheck{
+									{
+										Name:      "c1",
+										Status:    consulapi.HealthCritical,
+										ServiceID: "abc123",
+									},
+									{
+										Name:      "c2",
+										Status:    consulapi.HealthPassing,
+										ServiceID: "abc123",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "failing task check",
 			exp:  false,
@@ -1470,7 +1619,7 @@ func TestTracker_evaluateConsulChecks(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := evaluateConsulChecks(tc.tg.ConsulServices(), tc.registrations)
+			result := evaluateConsulChecks(tc.tg.ConsulServices(), tc.registrations, newHealthChecks(tc.update))
 			must.Eq(t, tc.exp, result)
 		})
 	}
@@ -5,13 +5,17 @@ package allocrunner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	log "github.com/hashicorp/go-hclog"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/hookoverride"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/allocrunner/state"
 	"github.com/hashicorp/nomad/client/allocrunner/tasklifecycle"
@@ -33,8 +37,10 @@ import (
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/client/vaultclient"
 	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/allochook"
 	"github.com/hashicorp/nomad/plugins/device"
 	"github.com/hashicorp/nomad/plugins/drivers"
 	"golang.org/x/exp/maps"
@@ -113,6 +119,14 @@ type allocRunner struct {
 	// destroyedLock to access.
 	shutdownLaunched bool
 
+	// shutdownCtx is canceled when Shutdown is called, and killCtx when
+	// Destroy is called, so the alloc hooks still running don't block the
+	// alloc runner.
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
+	killCtx        context.Context
+	killCancel     context.CancelFunc
+
 	// destroyedLock guards destroyed, destroyLaunched, shutdownLaunched,
 	// and serializes Shutdown/Destroy calls.
 	destroyedLock sync.Mutex
@@ -138,6 +152,10 @@ type allocRunner struct {
 	// transitions.
 	runnerHooks []interfaces.RunnerHook
 
+	// hookTracker tracks the running prerun and postrun hooks so operators
+	// can override them.
+	hookTracker hookoverride.Tracker
+
 	// hookResources holds the output from allocrunner hooks so that later
 	// allocrunner hooks or task runner hooks can read them
 	hookResources *cstructs.AllocHookResources
@@ -211,6 +229,13 @@ type allocRunner struct {
 
 	// widmgr manages workload identity signatures
 	widmgr widmgr.IdentityManager
+
+	// clientDisconnectedFunc returns true while the client is disconnected
+	// from the servers.
+	clientDisconnectedFunc func() bool
+
+	// allocHookPlugins are the clients of the external alloc hook plugins
+	allocHookPlugins []*allochook.Client
 }
 
 // NewAllocRunner returns a new allocation runner.
@@ -255,6 +280,8 @@ func NewAllocRunner(config *config.AllocRunnerConfig) (interfaces.AllocRunner, e
 		partitions:               config.Partitions,
 		hookResources:            cstructs.NewAllocHookResources(),
 		widsigner:                config.WIDSigner,
+		clientDisconnectedFunc:   config.ClientDisconnectedFunc,
+		allocHookPlugins:         config.AllocHookPlugins,
 	}
 
 	// Create the logger based on the allocation ID
@@ -264,7 +291,12 @@ func NewAllocRunner(config *config.AllocRunnerConfig) (interfaces.AllocRunner, e
 	ar.allocBroadcaster = cstructs.NewAllocBroadcaster(ar.logger)
 
 	// Create alloc dir
-	ar.allocDir = allocdir.NewAllocDir(ar.logger, config.ClientConfig.AllocDir, alloc.ID)
+	allocDirRoot := config.AllocDirRoot
+	if allocDirRoot == "" {
+		allocDirRoot = config.ClientConfig.AllocDir
+	}
+	ar.allocDir = allocdir.NewAllocDir(ar.logger, allocDirRoot, alloc.ID)
+	ar.allocDir.SecretsKeyring = config.SecretsKeyring
 
 	ar.taskCoordinator = tasklifecycle.NewCoordinator(ar.logger, tg.Tasks, ar.waitCh)
 
@@ -272,10 +304,30 @@ func NewAllocRunner(config *config.AllocRunnerConfig) (interfaces.AllocRunner, e
 	ar.shutdownDelayCtx = shutdownDelayCtx
 	ar.shutdownDelayCancelFn = shutdownDelayCancel
 
+	ar.shutdownCtx, ar.shutdownCancel = context.WithCancel(context.Background())
+	ar.killCtx, ar.killCancel = context.WithCancel(context.Background())
+
 	// initialize the workload identity manager
 	widmgr := widmgr.NewWIDMgr(ar.widsigner, alloc, ar.stateDB, ar.logger)
+	widmgr.SetHookResources(ar.hookResources)
+	widmgr.SetGracePeriod(config.ClientConfig.IdentityGracePeriod)
+	if config.ClientConfig.IdentityRenewMinWait > 0 {
+		widmgr.SetMinWait(config.ClientConfig.IdentityRenewMinWait)
+	}
+	widmgr.SetJitter(config.ClientConfig.IdentityRenewJitter)
+	widmgr.SetRenewalLimiter(config.IdentityRenewalLimiter)
+	widmgr.SetExpiredFunc(ar.identitiesExpired)
 	ar.widmgr = widmgr
 
+	// Time out and instrument the prerun and postrun hooks
+	ar.hookTracker.Timeout = config.ClientConfig.HookTimeoutFor
+	ar.hookTracker.Labels = []metrics.Label{
+		{Name: "job", Value: alloc.Job.Name},
+		{Name: "task_group", Value: alloc.TaskGroup},
+		{Name: "alloc_id", Value: alloc.ID},
+		{Name: "namespace", Value: alloc.Namespace},
+	}
+
 	// Initialize the runners hooks.
 	if err := ar.initRunnerHooks(config.ClientConfig); err != nil {
 		return nil, err
@@ -293,30 +345,31 @@ func NewAllocRunner(config *config.AllocRunnerConfig) (interfaces.AllocRunner, e
 func (ar *allocRunner) initTaskRunners(tasks []*structs.Task) error {
 	for _, task := range tasks {
 		trConfig := &taskrunner.Config{
-			Alloc:               ar.alloc,
-			ClientConfig:        ar.clientConfig,
-			Task:                task,
-			TaskDir:             ar.allocDir.NewTaskDir(task.Name),
-			Logger:              ar.logger,
-			StateDB:             ar.stateDB,
-			StateUpdater:        ar,
-			DynamicRegistry:     ar.dynamicRegistry,
-			Consul:              ar.consulClient,
-			ConsulProxiesFunc:   ar.consulProxiesClientFunc,
-			ConsulSI:            ar.sidsClient,
-			VaultFunc:           ar.vaultClientFunc,
-			DeviceStatsReporter: ar.deviceStatsReporter,
-			CSIManager:          ar.csiManager,
-			DeviceManager:       ar.devicemanager,
-			DriverManager:       ar.driverManager,
-			ServersContactedCh:  ar.serversContactedCh,
-			StartConditionMetCh: ar.taskCoordinator.StartConditionForTask(task),
-			ShutdownDelayCtx:    ar.shutdownDelayCtx,
-			ServiceRegWrapper:   ar.serviceRegWrapper,
-			Getter:              ar.getter,
-			Wranglers:           ar.wranglers,
-			AllocHookResources:  ar.hookResources,
-			WIDMgr:              ar.widmgr,
+			Alloc:                  ar.alloc,
+			ClientConfig:           ar.clientConfig,
+			Task:                   task,
+			TaskDir:                ar.allocDir.NewTaskDir(task.Name),
+			Logger:                 ar.logger,
+			StateDB:                ar.stateDB,
+			StateUpdater:           ar,
+			DynamicRegistry:        ar.dynamicRegistry,
+			Consul:                 ar.consulClient,
+			ConsulProxiesFunc:      ar.consulProxiesClientFunc,
+			ConsulSI:               ar.sidsClient,
+			VaultFunc:              ar.vaultClientFunc,
+			DeviceStatsReporter:    ar.deviceStatsReporter,
+			CSIManager:             ar.csiManager,
+			DeviceManager:          ar.devicemanager,
+			DriverManager:          ar.driverManager,
+			ServersContactedCh:     ar.serversContactedCh,
+			StartConditionMetCh:    ar.taskCoordinator.StartConditionForTask(task),
+			ShutdownDelayCtx:       ar.shutdownDelayCtx,
+			ServiceRegWrapper:      ar.serviceRegWrapper,
+			Getter:                 ar.getter,
+			Wranglers:              ar.wranglers,
+			AllocHookResources:     ar.hookResources,
+			WIDMgr:                 ar.widmgr,
+			ClientDisconnectedFunc: ar.clientDisconnectedFunc,
 		}
 
 		// Create, but do not Run, the task runner
@@ -358,10 +411,26 @@ func (ar *allocRunner) Run() {
 
 	// Run the prestart hooks if non-terminal
 	if ar.shouldRun() {
-		if err := ar.prerun(); err != nil {
+		if err := ar.prerun(); err != nil && ar.shutdownCtx.Err() != nil {
+			// The hooks were interrupted by the shutdown of the client, so
+			// don't fail the tasks since they're restored with the client.
+			ar.logger.Debug("prerun interrupted by shutdown", "error", err)
+		} else if err != nil {
 			ar.logger.Error("prerun failed", "error", err)
 
+			var perr *prerunError
+			hookFailed := errors.As(err, &perr)
+			var terr *hookoverride.TimeoutError
+			timedOut := errors.As(err, &terr)
 			for _, tr := range ar.tasks {
+				if hookFailed {
+					tr.SetResult(structs.NewTaskHookFailureResult(
+						perr.hook, structs.AllocHookPhasePrerun, perr.err))
+				}
+				if timedOut {
+					tr.EmitEvent(hookoverride.TimeoutEvent(terr))
+				}
+
 				// emit event and mark task to be cleaned up during runTasks()
 				tr.MarkFailedKill(fmt.Sprintf("failed to setup alloc: %v", err))
 			}
@@ -461,6 +530,7 @@ func (ar *allocRunner) Restore() error {
 
 	// Restore task runners
 	for _, tr := range ar.tasks {
+		tr.SetNetworkStatus(ns)
 		if err := tr.Restore(); err != nil {
 			return err
 		}
@@ -664,6 +734,17 @@ func (ar *allocRunner) handleTaskStateUpdates() {
 	}
 }
 
+// identitiesExpired emits an event on the task whose workload identities
+// expired past the identity grace period of the client without being renewed.
+func (ar *allocRunner) identitiesExpired(task string) {
+	tr, ok := ar.tasks[task]
+	if !ok {
+		return
+	}
+	tr.EmitEvent(structs.NewTaskEvent(structs.TaskIdentitiesExpired).
+		SetDisplayMessage("Workload identities expired and could not be renewed"))
+}
+
 // hasNonSidecarTasks returns false if all the passed tasks are sidecar tasks
 func hasNonSidecarTasks(tasks []*taskrunner.TaskRunner) bool {
 	for _, tr := range tasks {
@@ -689,6 +770,38 @@ func hasSidecarTasks(tasks map[string]*taskrunner.TaskRunner) bool {
 // killTasks kills all task runners, leader (if there is one) first. Errors are
 // logged except taskrunner.ErrTaskNotRunning which is ignored. Task states
 // after Kill has been called are returned.
+// drainServices deregisters the services of the running tasks, and waits for
+// the service drain of the allocation before returning. It returns
+// immediately if the allocation has no service drain, or no running tasks.
+func (ar *allocRunner) drainServices() {
+	drain := ar.Alloc().DesiredTransition.ServiceDrainDuration()
+	if drain <= 0 {
+		return
+	}
+
+	draining := false
+	for _, tr := range ar.tasks {
+		if tr.DrainServices(drain) {
+			draining = true
+		}
+	}
+	if !draining {
+		return
+	}
+
+	ar.logger.Debug("waiting for service drain before killing tasks", "service_drain", drain)
+
+	timer, cancel := helper.NewSafeTimer(drain)
+	defer cancel()
+
+	select {
+	// The drain is skipped like the shutdown delays when the allocation is
+	// stopped again with no shutdown delay.
+	case <-timer.C:
+	case <-ar.shutdownDelayCtx.Done():
+	}
+}
+
 func (ar *allocRunner) killTasks() map[string]*structs.TaskState {
 	var mu sync.Mutex
 	states := make(map[string]*structs.TaskState, len(ar.tasks))
@@ -696,6 +809,10 @@ func (ar *allocRunner) killTasks() map[string]*structs.TaskState {
 	// run alloc prekill hooks
 	ar.preKillHooks()
 
+	// Drain the services of the tasks if the job was stopped with a service
+	// drain, so load balancers remove them before the tasks are killed.
+	ar.drainServices()
+
 	// Kill leader first, synchronously
 	for name, tr := range ar.tasks {
 		if !tr.IsLeader() {
@@ -798,6 +915,7 @@ func (ar *allocRunner) clientAlloc(taskStates map[string]*structs.TaskState) *st
 	if d := ar.state.DeploymentStatus; d != nil {
 		a.DeploymentStatus = d.Copy()
 	}
+	a.MigrationStatus = ar.state.MigrationStatus.Copy()
 
 	// Compute the ClientStatus
 	if ar.state.ClientStatus != "" {
@@ -902,6 +1020,10 @@ func (ar *allocRunner) SetNetworkStatus(s *structs.AllocNetworkStatus) {
 	ar.stateLock.Lock()
 	defer ar.stateLock.Unlock()
 	ar.state.NetworkStatus = s.Copy()
+
+	for _, tr := range ar.tasks {
+		tr.SetNetworkStatus(s)
+	}
 }
 
 func (ar *allocRunner) NetworkStatus() *structs.AllocNetworkStatus {
@@ -1122,6 +1244,7 @@ func (ar *allocRunner) Destroy() {
 	}
 
 	ar.destroyLaunched = true
+	ar.killCancel()
 
 	// Synchronize calls to shutdown/destroy
 	if ar.shutdownLaunched {
@@ -1198,6 +1321,7 @@ func (ar *allocRunner) Shutdown() {
 	}
 
 	ar.shutdownLaunched = true
+	ar.shutdownCancel()
 
 	go func() {
 		ar.logger.Trace("shutting down")
@@ -1301,6 +1425,47 @@ func (ar *allocRunner) RestartTask(taskName string, event *structs.TaskEvent) er
 	return tr.Restart(context.TODO(), event, false)
 }
 
+// RunningHooks returns the running hooks of the allocation and its tasks.
+func (ar *allocRunner) RunningHooks() []*cstructs.RunningHook {
+	hooks := ar.hookTracker.Running()
+	for _, tr := range ar.tasks {
+		hooks = append(hooks, tr.RunningHooks()...)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].StartedAt.Before(hooks[j].StartedAt)
+	})
+	return hooks
+}
+
+// SignedIdentities returns the latest signed workload identities of the
+// allocation.
+func (ar *allocRunner) SignedIdentities() []*structs.SignedWorkloadIdentity {
+	return ar.widmgr.List()
+}
+
+// OverrideHook skips or fails a running hook of the allocation, or of one of
+// its tasks if the task name is set. The override is recorded in a task
+// event.
+func (ar *allocRunner) OverrideHook(taskName, hookName, action string) error {
+	if taskName != "" {
+		tr, ok := ar.tasks[taskName]
+		if !ok {
+			return fmt.Errorf("Could not find task runner for task: %s", taskName)
+		}
+		return tr.OverrideHook(hookName, action)
+	}
+
+	if err := ar.hookTracker.Override(hookName, action); err != nil {
+		return err
+	}
+
+	ar.logger.Info("hook overridden by operator", "name", hookName, "action", action)
+	emitter := &allocTaskEventEmitter{ar}
+	emitter.EmitTaskEvent(structs.NewTaskEvent(structs.TaskHookOverridden).
+		SetMessage(hookoverride.EventMessage(hookName, action)))
+	return nil
+}
+
 // RestartRunning restarts all tasks that are currently running.
 func (ar *allocRunner) RestartRunning(event *structs.TaskEvent) error {
 	return ar.restartTasks(context.TODO(), event, false, false)
@@ -1492,6 +1657,8 @@ func (ar *allocRunner) GetUpdatePriority(a *structs.Allocation) cstructs.AllocUp
 		return cstructs.AllocUpdatePriorityTypical
 	case !last.NetworkStatus.Equal(a.NetworkStatus):
 		return cstructs.AllocUpdatePriorityTypical
+	case !last.MigrationStatus.Equal(a.MigrationStatus):
+		return cstructs.AllocUpdatePriorityTypical
 	}
 
 	if !maps.EqualFunc(last.TaskStates, a.TaskStates, func(st, o *structs.TaskState) bool {
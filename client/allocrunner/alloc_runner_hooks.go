@@ -4,10 +4,13 @@
 package allocrunner
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/LK4D4/joincontext"
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/client/allocrunner/hookoverride"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	clientconfig "github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/consul"
@@ -81,6 +84,41 @@ func (a *allocHealthSetter) SetHealth(healthy, isDeploy bool, trackerTaskEvents
 	a.ar.allocBroadcaster.Send(calloc)
 }
 
+// allocMigrationStatusSetter is a shim to allow the disk migration hook to
+// report the progress of the migration to the server.
+type allocMigrationStatusSetter struct {
+	ar *allocRunner
+}
+
+// SetMigrationStatus updates the migration status of the alloc and the server.
+func (a *allocMigrationStatusSetter) SetMigrationStatus(status *structs.AllocMigrationStatus) {
+	a.ar.stateLock.Lock()
+	a.ar.state.MigrationStatus = status.Copy()
+	a.ar.stateLock.Unlock()
+
+	states := make(map[string]*structs.TaskState, len(a.ar.tasks))
+	for name, tr := range a.ar.tasks {
+		states[name] = tr.TaskState()
+	}
+
+	calloc := a.ar.clientAlloc(states)
+	a.ar.stateUpdater.AllocStateUpdated(calloc)
+	a.ar.allocBroadcaster.Send(calloc)
+}
+
+// allocTaskEventEmitter is a shim to allow alloc hooks to emit task events
+// for all the tasks of the allocation.
+type allocTaskEventEmitter struct {
+	ar *allocRunner
+}
+
+// EmitTaskEvent emits a copy of the event for each task.
+func (a *allocTaskEventEmitter) EmitTaskEvent(event *structs.TaskEvent) {
+	for _, tr := range a.ar.tasks {
+		tr.EmitEvent(event.Copy())
+	}
+}
+
 // initRunnerHooks initializes the runners hooks.
 func (ar *allocRunner) initRunnerHooks(config *clientconfig.Config) error {
 	hookLogger := ar.logger.Named("runner_hook")
@@ -114,50 +152,89 @@ func (ar *allocRunner) initRunnerHooks(config *clientconfig.Config) error {
 	// newNetworkHook and newChecksHook.
 	builtTaskEnv := newEnvBuilder().Build()
 
-	// Create the alloc directory hook. This is run first to ensure the
-	// directory path exists for other hooks.
+	// The CA bundle the client verifies the servers with, which identity
+	// projections may include.
+	var caFile string
+	if config.TLSConfig != nil {
+		caFile = config.TLSConfig.CAFile
+	}
+
+	// The hooks run in ascending order of priority. The alloc directory hook
+	// runs early to ensure the directory path exists for other hooks.
 	alloc := ar.Alloc()
-	ar.runnerHooks = []interfaces.RunnerHook{
-		newIdentityHook(hookLogger, ar.widmgr),
-		newAllocDirHook(hookLogger, ar.allocDir),
-		newConsulHook(consulHookConfig{
-			alloc:                   ar.alloc,
-			allocdir:                ar.allocDir,
-			widmgr:                  ar.widmgr,
-			consulConfigs:           ar.clientConfig.GetConsulConfigs(hookLogger),
-			consulClientConstructor: consul.NewConsulClient,
-			hookResources:           ar.hookResources,
-			logger:                  hookLogger,
-		}),
-		newUpstreamAllocsHook(hookLogger, ar.prevAllocWatcher),
-		newDiskMigrationHook(hookLogger, ar.prevAllocMigrator, ar.allocDir),
-		newCPUPartsHook(hookLogger, ar.partitions, alloc),
-		newAllocHealthWatcherHook(hookLogger, alloc, newEnvBuilder, hs, ar.Listener(), ar.consulClient, ar.checkStore),
-		newNetworkHook(hookLogger, ns, alloc, nm, nc, ar, builtTaskEnv),
-		newGroupServiceHook(groupServiceHookConfig{
-			alloc:             alloc,
-			providerNamespace: alloc.ServiceProviderNamespace(),
-			serviceRegWrapper: ar.serviceRegWrapper,
-			hookResources:     ar.hookResources,
-			restarter:         ar,
-			taskEnvBuilder:    newEnvBuilder(),
-			networkStatus:     ar,
-			logger:            hookLogger,
-			shutdownDelayCtx:  ar.shutdownDelayCtx,
-		}),
-		newConsulGRPCSocketHook(hookLogger, alloc, ar.allocDir, config.ConsulConfig, config.Node.Attributes),
-		newConsulHTTPSocketHook(hookLogger, alloc, ar.allocDir, config.ConsulConfig),
-		newCSIHook(alloc, hookLogger, ar.csiManager, ar.rpcClient, ar, ar.hookResources, ar.clientConfig.Node.SecretID),
-		newChecksHook(hookLogger, alloc, ar.checkStore, ar, builtTaskEnv),
+	var hooks interfaces.HookList[interfaces.RunnerHook]
+	hooks.Add(interfaces.AllocHookPriorityIdentity, newIdentityHook(hookLogger, ar.widmgr))
+	hooks.Add(interfaces.AllocHookPriorityAllocDir, newAllocDirHook(hookLogger, ar.allocDir))
+	hooks.Add(interfaces.AllocHookPriorityIdentityProjection, newIdentityProjectionHook(hookLogger, alloc, ar.allocDir, ar.widmgr, ar.hookResources, caFile))
+	hooks.Add(interfaces.AllocHookPriorityDispatch, newDispatchPayloadHook(hookLogger, alloc, ar.rpcClient, ar.hookResources, ar.clientConfig.Node.SecretID))
+	hooks.Add(interfaces.AllocHookPriorityConsul, newConsulHook(consulHookConfig{
+		alloc:                   ar.alloc,
+		allocdir:                ar.allocDir,
+		widmgr:                  ar.widmgr,
+		consulConfigs:           ar.clientConfig.GetConsulConfigs(hookLogger),
+		consulClientConstructor: consul.NewConsulClient,
+		hookResources:           ar.hookResources,
+		logger:                  hookLogger,
+	}))
+	hooks.Add(interfaces.AllocHookPriorityUpstreamAllocs, newUpstreamAllocsHook(hookLogger, ar.prevAllocWatcher))
+	hooks.Add(interfaces.AllocHookPriorityDiskMigration, newDiskMigrationHook(hookLogger, ar.prevAllocMigrator, ar.allocDir,
+		&allocMigrationStatusSetter{ar}, &allocTaskEventEmitter{ar}))
+	hooks.Add(interfaces.AllocHookPriorityDiskQuota, newDiskQuotaHook(hookLogger, config.DiskQuota, alloc, ar.allocDir, &allocTaskEventEmitter{ar}))
+	hooks.Add(interfaces.AllocHookPriorityCPUParts, newCPUPartsHook(hookLogger, ar.partitions, alloc))
+	hooks.Add(interfaces.AllocHookPriorityHealth, newAllocHealthWatcherHook(hookLogger, alloc, newEnvBuilder, hs, ar.Listener(), ar.consulClient, ar.checkStore))
+	hooks.Add(interfaces.AllocHookPriorityNetwork, newNetworkHook(hookLogger, ns, alloc, nm, nc, ar, builtTaskEnv))
+	hooks.Add(interfaces.AllocHookPriorityServiceHosts, newServiceHostsHook(hookLogger, alloc, ar.allocDir.AllocDir, ar.rpcClient))
+	hooks.Add(interfaces.AllocHookPriorityGroupServices, newGroupServiceHook(groupServiceHookConfig{
+		alloc:             alloc,
+		providerNamespace: alloc.ServiceProviderNamespace(),
+		serviceRegWrapper: ar.serviceRegWrapper,
+		hookResources:     ar.hookResources,
+		restarter:         ar,
+		taskEnvBuilder:    newEnvBuilder(),
+		networkStatus:     ar,
+		logger:            hookLogger,
+		shutdownDelayCtx:  ar.shutdownDelayCtx,
+	}))
+	hooks.Add(interfaces.AllocHookPriorityConsulSockets, newConsulGRPCSocketHook(hookLogger, alloc, ar.allocDir, config.ConsulConfig, config.Node.Attributes))
+	hooks.Add(interfaces.AllocHookPriorityConsulSockets, newConsulHTTPSocketHook(hookLogger, alloc, ar.allocDir, config.ConsulConfig))
+	hooks.Add(interfaces.AllocHookPriorityCSIVolumes, newCSIHook(alloc, hookLogger, ar.csiManager, ar.rpcClient, ar, ar.hookResources, ar.clientConfig.Node.SecretID))
+	hooks.Add(interfaces.AllocHookPriorityChecks, newChecksHook(hookLogger, alloc, ar.checkStore, ar, builtTaskEnv))
+
+	// The hooks of the alloc hook plugins run after the built-in hooks,
+	// unless the plugins are configured with a priority.
+	for _, plugin := range ar.allocHookPlugins {
+		hooks.Add(interfaces.AllocHookPriorityDefault, plugin.NewHook(alloc, ar.allocDir.AllocDir, ar))
+	}
+
+	// Extra hooks run after the built-in hooks, unless they set their own
+	// priority.
+	for _, hook := range config.ExtraAllocHooks {
+		hooks.Add(interfaces.AllocHookPriorityDefault, hook)
 	}
-	if config.ExtraAllocHooks != nil {
-		ar.runnerHooks = append(ar.runnerHooks, config.ExtraAllocHooks...)
+	sorted, err := hooks.Sorted()
+	if err != nil {
+		return err
 	}
+	ar.runnerHooks = sorted
 
 	return nil
 }
 
 // prerun is used to run the runners prerun hooks.
+// prerunError is returned by prerun when a hook fails.
+type prerunError struct {
+	hook string
+	err  error
+}
+
+func (e *prerunError) Error() string {
+	return fmt.Sprintf("pre-run hook %q failed: %v", e.hook, e.err)
+}
+
+func (e *prerunError) Unwrap() error {
+	return e.err
+}
+
 func (ar *allocRunner) prerun() error {
 	if ar.logger.IsTrace() {
 		start := time.Now()
@@ -168,10 +245,17 @@ func (ar *allocRunner) prerun() error {
 		}()
 	}
 
-	for _, hook := range ar.runnerHooks {
+	// Stop waiting on the hooks once the alloc runner is shut down or
+	// destroyed
+	joinedCtx, joinedCancel := joincontext.Join(ar.killCtx, ar.shutdownCtx)
+	defer joinedCancel()
+
+	// Hooks declaring their dependencies run as soon as the hooks they
+	// require are done, concurrently with the other hooks.
+	return interfaces.RunHooks(ar.runnerHooks, func(hook interfaces.RunnerHook) error {
 		pre, ok := hook.(interfaces.RunnerPrerunHook)
 		if !ok {
-			continue
+			return nil
 		}
 
 		name := pre.Name()
@@ -181,17 +265,21 @@ func (ar *allocRunner) prerun() error {
 			ar.logger.Trace("running pre-run hook", "name", name, "start", start)
 		}
 
-		if err := pre.Prerun(); err != nil {
-			return fmt.Errorf("pre-run hook %q failed: %v", name, err)
+		skipped, err := ar.hookTracker.Run(joinedCtx, name, hookoverride.PhasePrerun,
+			func(context.Context) error { return pre.Prerun() })
+		if err != nil {
+			return &prerunError{hook: name, err: err}
+		}
+		if skipped {
+			ar.logger.Warn("pre-run hook skipped by operator", "name", name)
 		}
 
 		if ar.logger.IsTrace() {
 			end := time.Now()
 			ar.logger.Trace("finished pre-run hook", "name", name, "end", end, "duration", end.Sub(start))
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // update runs the alloc runner update hooks. Update hooks are run
@@ -261,9 +349,16 @@ func (ar *allocRunner) postrun() error {
 			ar.logger.Trace("running post-run hook", "name", name, "start", start)
 		}
 
-		if err := post.Postrun(); err != nil {
+		// Postrun hooks clean up after destroyed allocs, so they only stop
+		// being waited on when the alloc runner is shut down.
+		skipped, err := ar.hookTracker.Run(ar.shutdownCtx, name, hookoverride.PhasePostrun,
+			func(context.Context) error { return post.Postrun() })
+		if err != nil {
 			return fmt.Errorf("hook %q failed: %v", name, err)
 		}
+		if skipped {
+			ar.logger.Warn("post-run hook skipped by operator", "name", name)
+		}
 
 		if ar.logger.IsTrace() {
 			end := time.Now()
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -26,6 +27,7 @@ import (
 	regMock "github.com/hashicorp/nomad/client/serviceregistration/mock"
 	"github.com/hashicorp/nomad/client/state"
 	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -1080,6 +1082,79 @@ func TestAllocRunner_TaskGroup_ShutdownDelay(t *testing.T) {
 	require.True(t, leaderFinished.Sub(groupRemoveOp.OccurredAt) > shutdownDelay)
 }
 
+// TestAllocRunner_ServiceDrain asserts that the services of an alloc stopped
+// with a service drain are deregistered, and its tasks killed after the drain.
+func TestAllocRunner_ServiceDrain(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	alloc.Job.TaskGroups[0].RestartPolicy.Attempts = 0
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.RestartPolicy.Attempts = 0
+	task.Driver = "mock_driver"
+	task.Config = map[string]interface{}{
+		"run_for": "10s",
+	}
+
+	conf, cleanup := testAllocRunnerConfig(t, alloc)
+	defer cleanup()
+	ar, err := NewAllocRunner(conf)
+	must.NoError(t, err)
+	defer destroy(ar)
+	go ar.Run()
+
+	// Wait for the task to start
+	upd := conf.StateUpdater.(*MockStateUpdater)
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			last := upd.Last()
+			return last != nil && last.TaskStates[task.Name] != nil &&
+				last.TaskStates[task.Name].State == structs.TaskStateRunning
+		}),
+		wait.Timeout(10*time.Second),
+		wait.Gap(50*time.Millisecond),
+	))
+
+	// Stop the alloc with a service drain
+	drain := 500 * time.Millisecond
+	shutdownInit := time.Now()
+	update := alloc.Copy()
+	update.DesiredStatus = structs.AllocDesiredStatusStop
+	update.DesiredTransition.ServiceDrain = pointer.Of(drain)
+	ar.Update(update)
+
+	var state *structs.TaskState
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			state = upd.Last().TaskStates[task.Name]
+			return !state.FinishedAt.IsZero()
+		}),
+		wait.Timeout(10*time.Second),
+		wait.Gap(50*time.Millisecond),
+	))
+
+	// The services of the task are deregistered before the drain, and the
+	// task is killed after it.
+	var removeOp *regMock.Operation
+	for _, op := range conf.Consul.(*regMock.ServiceRegistrationHandler).GetOps() {
+		if op.Op == "remove" && op.Name == task.Name {
+			removeOp = &op
+			break
+		}
+	}
+	must.NotNil(t, removeOp)
+	must.Less(t, drain, removeOp.OccurredAt.Sub(shutdownInit))
+	must.Greater(t, drain, state.FinishedAt.Sub(removeOp.OccurredAt))
+
+	var draining bool
+	for _, ev := range state.Events {
+		if ev.Type == structs.TaskDrainingServices {
+			draining = true
+		}
+	}
+	must.True(t, draining)
+}
+
 // TestAllocRunner_TaskLeader_StopTG asserts that when stopping an alloc with a
 // leader the leader is stopped before other tasks.
 func TestAllocRunner_TaskLeader_StopTG(t *testing.T) {
@@ -2470,6 +2545,40 @@ func TestAllocRunner_PreKill_RunOnDone(t *testing.T) {
 	))
 }
 
+type allocPriorityHook struct {
+	name     string
+	priority int
+}
+
+func (h *allocPriorityHook) Name() string { return h.name }
+
+func (h *allocPriorityHook) Priority() int { return h.priority }
+
+// TestAllocRunner_ExtraHooks_Priority asserts that extra hooks run after the
+// built-in hooks, unless they set their own priority.
+func TestAllocRunner_ExtraHooks_Priority(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	conf, cleanup := testAllocRunnerConfig(t, alloc)
+	t.Cleanup(cleanup)
+	conf.ClientConfig.ExtraAllocHooks = []interfaces.RunnerHook{
+		&allocPreKillHook{},
+		&allocPriorityHook{name: "before_network", priority: interfaces.AllocHookPriorityNetwork - 1},
+	}
+
+	arIface, err := NewAllocRunner(conf)
+	must.NoError(t, err)
+	ar := arIface.(*allocRunner)
+
+	var names []string
+	for _, hook := range ar.runnerHooks {
+		names = append(names, hook.Name())
+	}
+	must.Eq(t, "test_prekill", names[len(names)-1])
+	must.Eq(t, slices.Index(names, "network")-1, slices.Index(names, "before_network"))
+}
+
 func TestAllocRunner_GetUpdatePriority(t *testing.T) {
 	ci.Parallel(t)
 
@@ -2531,3 +2640,62 @@ func TestAllocRunner_GetUpdatePriority(t *testing.T) {
 	calloc = ar.clientAlloc(map[string]*structs.TaskState{})
 	must.Eq(t, cstructs.AllocUpdatePriorityUrgent, ar.GetUpdatePriority(calloc))
 }
+
+// allocStuckPrerunHook is a prerun hook that ignores cancellation and blocks
+// until it's released.
+type allocStuckPrerunHook struct {
+	startedCh chan struct{}
+	releaseCh chan struct{}
+}
+
+func (*allocStuckPrerunHook) Name() string { return "test_stuck" }
+
+func (h *allocStuckPrerunHook) Prerun() error {
+	close(h.startedCh)
+	<-h.releaseCh
+	return nil
+}
+
+// TestAllocRunner_Shutdown_StuckPrerunHook asserts that a prerun hook stuck
+// forever doesn't block the shutdown of the alloc runner, and that the tasks
+// aren't failed because of it.
+func TestAllocRunner_Shutdown_StuckPrerunHook(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "mock_driver"
+	task.Config = map[string]interface{}{"run_for": "10s"}
+
+	conf, cleanup := testAllocRunnerConfig(t, alloc.Copy())
+	t.Cleanup(cleanup)
+
+	hook := &allocStuckPrerunHook{
+		startedCh: make(chan struct{}),
+		releaseCh: make(chan struct{}),
+	}
+	t.Cleanup(func() { close(hook.releaseCh) })
+	conf.ClientConfig.ExtraAllocHooks = []interfaces.RunnerHook{hook}
+
+	arIface, err := NewAllocRunner(conf)
+	must.NoError(t, err)
+	ar := arIface.(*allocRunner)
+	go ar.Run()
+
+	select {
+	case <-hook.startedCh:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for prerun hook to start")
+	}
+
+	ar.Shutdown()
+	select {
+	case <-ar.ShutdownCh():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for alloc runner to shut down")
+	}
+
+	for name, state := range ar.AllocState().TaskStates {
+		must.False(t, state.Failed, must.Sprintf("task %q failed", name))
+	}
+}
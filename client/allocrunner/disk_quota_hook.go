@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/lib/diskquota"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	diskQuotaHookName = "disk_quota"
+)
+
+// taskEventEmitter emits a task event for all the tasks of an allocation.
+type taskEventEmitter interface {
+	EmitTaskEvent(*structs.TaskEvent)
+}
+
+// diskQuotaLevel is the usage level of a disk quota, the hook emits task events
+// when an allocation reaches a higher level.
+type diskQuotaLevel int
+
+const (
+	diskQuotaOK diskQuotaLevel = iota
+	diskQuotaWarning
+	diskQuotaExceeded
+)
+
+// diskQuotaHook enforces the ephemeral disk size of an allocation with a
+// filesystem project quota on its shared alloc dir and the local dirs of its
+// tasks, and emits task events when the allocation approaches or reaches its
+// quota.
+type diskQuotaHook struct {
+	logger   log.Logger
+	config   *config.DiskQuotaConfig
+	allocDir *allocdir.AllocDir
+	emitter  taskEventEmitter
+
+	projectID uint32
+	limitMB   int64
+
+	// level is the last usage level an event was emitted for.
+	level diskQuotaLevel
+
+	// enforced is true once the quota is set, and cancel stops the watcher.
+	enforced bool
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+}
+
+func newDiskQuotaHook(logger log.Logger, conf *config.DiskQuotaConfig, alloc *structs.Allocation,
+	allocDir *allocdir.AllocDir, emitter taskEventEmitter) *diskQuotaHook {
+	h := &diskQuotaHook{
+		config:    conf,
+		allocDir:  allocDir,
+		emitter:   emitter,
+		projectID: diskquota.ProjectID(alloc.ID),
+	}
+	if alloc.AllocatedResources != nil {
+		h.limitMB = alloc.AllocatedResources.Shared.DiskMB
+	}
+	h.logger = logger.Named(h.Name())
+	return h
+}
+
+func (h *diskQuotaHook) Name() string {
+	return diskQuotaHookName
+}
+
+func (h *diskQuotaHook) Prerun() error {
+	if h.config == nil || !h.config.Enabled || h.limitMB <= 0 {
+		return nil
+	}
+
+	// The task dirs are built after the alloc hooks run, so create their
+	// local dirs for the files written in them to inherit the project.
+	paths := []string{h.allocDir.SharedDir}
+	for _, taskDir := range h.allocDir.TaskDirs {
+		if err := os.MkdirAll(taskDir.LocalDir, 0777); err != nil {
+			return fmt.Errorf("failed to create task local dir: %w", err)
+		}
+		paths = append(paths, taskDir.LocalDir)
+	}
+
+	if err := diskquota.Set(paths, h.projectID, uint64(h.limitMB)*1024*1024); err != nil {
+		h.logger.Warn("failed to enforce ephemeral disk size with a project quota", "error", err)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h.mu.Lock()
+	h.enforced = true
+	h.cancel = cancel
+	h.mu.Unlock()
+
+	go h.watch(ctx)
+	return nil
+}
+
+// watch checks the usage of the quota until the context is cancelled.
+func (h *diskQuotaHook) watch(ctx context.Context) {
+	ticker := time.NewTicker(h.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		usage, err := diskquota.Get(h.allocDir.SharedDir, h.projectID)
+		if err != nil {
+			h.logger.Debug("failed to get project quota usage", "error", err)
+			continue
+		}
+		h.checkUsage(usage)
+	}
+}
+
+// checkUsage emits a task event when the usage reaches a higher level than
+// the last checked usage.
+func (h *diskQuotaHook) checkUsage(usage *diskquota.Usage) {
+	limit := uint64(h.limitMB) * 1024 * 1024
+	if limit == 0 {
+		return
+	}
+	percent := float64(usage.UsedBytes) / float64(limit) * 100
+
+	level := diskQuotaOK
+	switch {
+	case usage.UsedBytes >= limit:
+		level = diskQuotaExceeded
+	case percent >= h.config.WarningThreshold:
+		level = diskQuotaWarning
+	}
+
+	// Only emit events when the usage increases, and allow warning again if
+	// the usage decreased since the last one.
+	defer func() { h.level = level }()
+	if level <= h.level {
+		return
+	}
+
+	var event *structs.TaskEvent
+	switch level {
+	case diskQuotaWarning:
+		event = structs.NewTaskEvent(structs.TaskDiskQuotaWarning).
+			SetMessage(fmt.Sprintf("Allocation is using %.0f%% of its %d MB ephemeral disk", percent, h.limitMB))
+	case diskQuotaExceeded:
+		event = structs.NewTaskEvent(structs.TaskDiskExceeded).
+			SetMessage(fmt.Sprintf("Allocation reached its %d MB ephemeral disk quota, writes to the allocation directory will fail", h.limitMB))
+	}
+	h.emitter.EmitTaskEvent(event.SetDiskLimit(h.limitMB))
+}
+
+func (h *diskQuotaHook) Postrun() error {
+	h.stop()
+	return nil
+}
+
+func (h *diskQuotaHook) Shutdown() {
+	h.stop()
+}
+
+// Destroy clears the limit of the project so it doesn't apply to the next
+// allocation with the same project ID.
+func (h *diskQuotaHook) Destroy() error {
+	if !h.stop() {
+		return nil
+	}
+
+	// The alloc dir may already be destroyed, so use the client alloc dir to
+	// find the filesystem.
+	if err := diskquota.Clear(filepath.Dir(h.allocDir.AllocDir), h.projectID); err != nil {
+		h.logger.Warn("failed to clear project quota", "error", err)
+	}
+	return nil
+}
+
+// stop stops the watcher and returns true if the quota was enforced.
+func (h *diskQuotaHook) stop() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+	return h.enforced
+}
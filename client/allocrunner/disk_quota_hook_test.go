@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/lib/diskquota"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+var (
+	_ interfaces.RunnerPrerunHook  = (*diskQuotaHook)(nil)
+	_ interfaces.RunnerPostrunHook = (*diskQuotaHook)(nil)
+	_ interfaces.RunnerDestroyHook = (*diskQuotaHook)(nil)
+	_ interfaces.ShutdownHook      = (*diskQuotaHook)(nil)
+)
+
+type mockTaskEventEmitter struct {
+	events []*structs.TaskEvent
+}
+
+func (m *mockTaskEventEmitter) EmitTaskEvent(event *structs.TaskEvent) {
+	m.events = append(m.events, event)
+}
+
+func TestDiskQuotaHook_Disabled(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	alloc := mock.Alloc()
+	allocDir := allocdir.NewAllocDir(logger, t.TempDir(), alloc.ID)
+
+	hook := newDiskQuotaHook(logger, nil, alloc, allocDir, &mockTaskEventEmitter{})
+	must.NoError(t, hook.Prerun())
+	must.False(t, hook.stop())
+
+	conf := &config.DiskQuotaConfig{Enabled: false}
+	hook = newDiskQuotaHook(logger, conf, alloc, allocDir, &mockTaskEventEmitter{})
+	must.NoError(t, hook.Prerun())
+	must.False(t, hook.stop())
+	must.NoError(t, hook.Destroy())
+}
+
+func TestDiskQuotaHook_checkUsage(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	alloc := mock.Alloc()
+	alloc.AllocatedResources.Shared.DiskMB = 100
+	allocDir := allocdir.NewAllocDir(logger, t.TempDir(), alloc.ID)
+
+	conf := &config.DiskQuotaConfig{
+		Enabled:          true,
+		WarningThreshold: 90,
+		CheckInterval:    time.Second,
+	}
+	emitter := &mockTaskEventEmitter{}
+	hook := newDiskQuotaHook(logger, conf, alloc, allocDir, emitter)
+
+	const mb = 1024 * 1024
+
+	// Below the warning threshold
+	hook.checkUsage(&diskquota.Usage{UsedBytes: 50 * mb})
+	must.SliceEmpty(t, emitter.events)
+
+	// Warned once
+	hook.checkUsage(&diskquota.Usage{UsedBytes: 91 * mb})
+	hook.checkUsage(&diskquota.Usage{UsedBytes: 95 * mb})
+	must.Len(t, 1, emitter.events)
+	must.Eq(t, structs.TaskDiskQuotaWarning, emitter.events[0].Type)
+	must.StrContains(t, emitter.events[0].Message, "91% of its 100 MB")
+	must.Eq(t, "100", emitter.events[0].Details["disk_limit"])
+
+	// Exceeded
+	hook.checkUsage(&diskquota.Usage{UsedBytes: 100 * mb})
+	hook.checkUsage(&diskquota.Usage{UsedBytes: 100 * mb})
+	must.Len(t, 2, emitter.events)
+	must.Eq(t, structs.TaskDiskExceeded, emitter.events[1].Type)
+
+	// Warned again after the usage decreased
+	hook.checkUsage(&diskquota.Usage{UsedBytes: 10 * mb})
+	hook.checkUsage(&diskquota.Usage{UsedBytes: 92 * mb})
+	must.Len(t, 3, emitter.events)
+	must.Eq(t, structs.TaskDiskQuotaWarning, emitter.events[2].Type)
+}
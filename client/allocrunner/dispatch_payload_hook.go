@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/config"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// dispatchPayloadHook fetches the dispatch payload of the allocation from the
+// servers when it's kept in their payload store instead of the job, so the
+// dispatch taskrunner hook can write it to the task directories.
+type dispatchPayloadHook struct {
+	alloc         *structs.Allocation
+	rpcClient     config.RPCer
+	hookResources *cstructs.AllocHookResources
+	nodeSecret    string
+	logger        log.Logger
+}
+
+func newDispatchPayloadHook(
+	logger log.Logger,
+	alloc *structs.Allocation,
+	rpcClient config.RPCer,
+	hookResources *cstructs.AllocHookResources,
+	nodeSecret string,
+) *dispatchPayloadHook {
+	h := &dispatchPayloadHook{
+		alloc:         alloc,
+		rpcClient:     rpcClient,
+		hookResources: hookResources,
+		nodeSecret:    nodeSecret,
+	}
+	h.logger = logger.Named(h.Name())
+	return h
+}
+
+func (*dispatchPayloadHook) Name() string {
+	return "dispatch_payload"
+}
+
+func (h *dispatchPayloadHook) Prerun() error {
+	if h.alloc.Job == nil || h.alloc.Job.PayloadRef == "" {
+		return nil
+	}
+
+	// Failures don't fail the allocation here, since the payload isn't needed
+	// by tasks that were already started before the client restarted. The
+	// dispatch taskrunner hook fails the tasks that need it instead.
+	req := &structs.JobDispatchPayloadRequest{
+		AllocID: h.alloc.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    h.alloc.Job.Region,
+			AuthToken: h.nodeSecret,
+		},
+	}
+	var resp structs.JobDispatchPayloadResponse
+	if err := h.rpcClient.RPC("Job.DispatchPayload", req, &resp); err != nil {
+		h.logger.Error("failed to fetch dispatch payload", "error", err)
+		return nil
+	}
+
+	h.hookResources.SetDispatchPayload(resp.Payload)
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+var _ interfaces.RunnerPrerunHook = (*dispatchPayloadHook)(nil)
+
+// dispatchPayloadRPCer serves the dispatch payload of an allocation, or fails
+// if it's nil.
+type dispatchPayloadRPCer struct {
+	payload []byte
+	reqs    []*structs.JobDispatchPayloadRequest
+}
+
+func (r *dispatchPayloadRPCer) RPC(method string, args any, reply any) error {
+	r.reqs = append(r.reqs, args.(*structs.JobDispatchPayloadRequest))
+	if r.payload == nil {
+		return errors.New("payload store unavailable")
+	}
+	reply.(*structs.JobDispatchPayloadResponse).Payload = r.payload
+	return nil
+}
+
+func TestDispatchPayloadHook(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	t.Run("not stored", func(t *testing.T) {
+		alloc := mock.BatchAlloc()
+		rpcer := &dispatchPayloadRPCer{payload: []byte("payload")}
+		hookResources := cstructs.NewAllocHookResources()
+
+		h := newDispatchPayloadHook(logger, alloc, rpcer, hookResources, "secret")
+		must.NoError(t, h.Prerun())
+		must.SliceEmpty(t, rpcer.reqs)
+		must.Nil(t, hookResources.GetDispatchPayload())
+	})
+
+	t.Run("stored", func(t *testing.T) {
+		alloc := mock.BatchAlloc()
+		alloc.Job.PayloadRef = "jobs/default/example/payload"
+		rpcer := &dispatchPayloadRPCer{payload: []byte("payload")}
+		hookResources := cstructs.NewAllocHookResources()
+
+		h := newDispatchPayloadHook(logger, alloc, rpcer, hookResources, "secret")
+		must.NoError(t, h.Prerun())
+		must.Len(t, 1, rpcer.reqs)
+		must.Eq(t, alloc.ID, rpcer.reqs[0].AllocID)
+		must.Eq(t, "secret", rpcer.reqs[0].AuthToken)
+		must.Eq(t, []byte("payload"), hookResources.GetDispatchPayload())
+	})
+
+	t.Run("fetch fails", func(t *testing.T) {
+		alloc := mock.BatchAlloc()
+		alloc.Job.PayloadRef = "jobs/default/example/payload"
+		rpcer := &dispatchPayloadRPCer{}
+		hookResources := cstructs.NewAllocHookResources()
+
+		h := newDispatchPayloadHook(logger, alloc, rpcer, hookResources, "secret")
+		must.NoError(t, h.Prerun())
+		must.Nil(t, hookResources.GetDispatchPayload())
+	})
+}
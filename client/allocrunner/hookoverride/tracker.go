@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package hookoverride tracks the running alloc runner and task runner hooks,
+// so operators can skip or fail hooks that are stuck instead of restarting
+// the client. Hooks also fail on their own once they exceed the hook timeout
+// of the client.
+package hookoverride
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// ActionSkip makes the runner continue as if the hook had succeeded.
+	ActionSkip = "skip"
+
+	// ActionFail makes the runner continue as if the hook had failed.
+	ActionFail = "fail"
+)
+
+const (
+	// PhasePrerun and PhasePostrun are the phases of the alloc runner hooks
+	// that are tracked. The prestart phase of the task runner hooks is
+	// tracked as structs.TaskHookPhasePrestart.
+	PhasePrerun  = structs.AllocHookPhasePrerun
+	PhasePostrun = "postrun"
+)
+
+// ErrFailedByOperator is the error of the hooks failed with ActionFail.
+var ErrFailedByOperator = errors.New("hook failed by operator")
+
+// TimeoutError is the error of the hooks that didn't return before their
+// timeout.
+type TimeoutError struct {
+	Name    string
+	Phase   string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("hook %q timed out after %s", e.Name, e.Timeout)
+}
+
+type runningHook struct {
+	cstructs.RunningHook
+	overrideCh chan string
+}
+
+// Tracker tracks the running hooks of a runner. Only one call of a hook may
+// run at a time. Its fields must be set before the first hook runs.
+type Tracker struct {
+	// Timeout returns how long the hook with the given name may run before
+	// failing with a TimeoutError. Hooks run without a timeout if Timeout is
+	// nil or returns zero.
+	Timeout func(name string) time.Duration
+
+	// Labels are added to the hook and phase labels of the hook metrics.
+	Labels []metrics.Label
+
+	l       sync.Mutex
+	running map[string]*runningHook
+}
+
+// Run calls the hook function and returns its error. If the hook is
+// overridden or times out before the function returns, the context passed to
+// the function is canceled and Run returns immediately, with skipped set for
+// ActionSkip, ErrFailedByOperator for ActionFail, and a TimeoutError once
+// the timeout is reached. Run also returns the error of ctx once it's done,
+// so hooks that ignore their context don't block the runner when it's shut
+// down or killed. The function may still be running after Run returns, and
+// leaks its goroutine until it does, so the caller must not use anything it
+// writes to once overridden.
+//
+// The duration of each run is recorded in the client.hook.duration metric,
+// and its failures in client.hook.failed and client.hook.timed_out.
+func (t *Tracker) Run(ctx context.Context, name, phase string, fn func(context.Context) error) (skipped bool, err error) {
+	start := time.Now()
+	hook := &runningHook{
+		RunningHook: cstructs.RunningHook{
+			Name:      name,
+			Phase:     phase,
+			StartedAt: start,
+		},
+		overrideCh: make(chan string, 1),
+	}
+
+	labels := append(slices.Clone(t.Labels),
+		metrics.Label{Name: "hook", Value: name},
+		metrics.Label{Name: "phase", Value: phase},
+	)
+	defer func() {
+		metrics.MeasureSinceWithLabels([]string{"client", "hook", "duration"}, start, labels)
+		if err == nil {
+			return
+		}
+		metrics.IncrCounterWithLabels([]string{"client", "hook", "failed"}, 1, labels)
+		var terr *TimeoutError
+		if errors.As(err, &terr) {
+			metrics.IncrCounterWithLabels([]string{"client", "hook", "timed_out"}, 1, labels)
+		}
+	}()
+
+	t.l.Lock()
+	if t.running == nil {
+		t.running = make(map[string]*runningHook)
+	}
+	t.running[name] = hook
+	t.l.Unlock()
+
+	defer func() {
+		t.l.Lock()
+		delete(t.running, name)
+		t.l.Unlock()
+	}()
+
+	hookCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var timeoutCh <-chan time.Time
+	var timeout time.Duration
+	if t.Timeout != nil {
+		timeout = t.Timeout(name)
+	}
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(hookCtx)
+	}()
+
+	select {
+	case err := <-errCh:
+		return false, err
+	case action := <-hook.overrideCh:
+		if action == ActionSkip {
+			return true, nil
+		}
+		return false, ErrFailedByOperator
+	case <-timeoutCh:
+		return false, &TimeoutError{Name: name, Phase: phase, Timeout: timeout}
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// Running returns the running hooks, sorted by start time.
+func (t *Tracker) Running() []*cstructs.RunningHook {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	hooks := make([]*cstructs.RunningHook, 0, len(t.running))
+	for _, hook := range t.running {
+		h := hook.RunningHook
+		hooks = append(hooks, &h)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].StartedAt.Before(hooks[j].StartedAt)
+	})
+	return hooks
+}
+
+// Override skips or fails the running hook with the given name.
+func (t *Tracker) Override(name, action string) error {
+	switch action {
+	case ActionSkip, ActionFail:
+	default:
+		return fmt.Errorf("invalid hook override action %q: must be %q or %q", action, ActionSkip, ActionFail)
+	}
+
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	hook, ok := t.running[name]
+	if !ok {
+		return fmt.Errorf("hook %q is not running", name)
+	}
+
+	select {
+	case hook.overrideCh <- action:
+		return nil
+	default:
+		return fmt.Errorf("hook %q is already overridden", name)
+	}
+}
+
+// TimeoutEvent returns the task event recording that a hook timed out.
+func TimeoutEvent(err *TimeoutError) *structs.TaskEvent {
+	return structs.NewTaskEvent(structs.TaskHookTimedOut).
+		SetMessage(fmt.Sprintf("Hook %q timed out after %s", err.Name, err.Timeout)).
+		SetHookTimeout(err.Name, err.Phase, err.Timeout).
+		SetFailsTask()
+}
+
+// EventMessage returns the message of the task event recording an override.
+func EventMessage(name, action string) string {
+	if action == ActionSkip {
+		return fmt.Sprintf("Hook %q skipped by operator", name)
+	}
+	return fmt.Sprintf("Hook %q failed by operator", name)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hookoverride
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+// runBlocking runs a hook blocking until its context is canceled, and
+// returns the result of the run once it's overridden with the action.
+func runBlocking(t *testing.T, tracker *Tracker, action string) (bool, error) {
+	type result struct {
+		skipped bool
+		err     error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		skipped, err := tracker.Run(context.Background(), "stuck", PhasePrerun, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		resultCh <- result{skipped, err}
+	}()
+
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool { return len(tracker.Running()) == 1 }),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+	running := tracker.Running()[0]
+	must.Eq(t, "stuck", running.Name)
+	must.Eq(t, PhasePrerun, running.Phase)
+
+	must.NoError(t, tracker.Override("stuck", action))
+	res := <-resultCh
+	must.SliceEmpty(t, tracker.Running())
+	return res.skipped, res.err
+}
+
+func TestTracker_Run(t *testing.T) {
+	ci.Parallel(t)
+
+	var tracker Tracker
+
+	// Hooks that aren't overridden return their own result.
+	skipped, err := tracker.Run(context.Background(), "ok", PhasePrerun, func(context.Context) error {
+		return nil
+	})
+	must.NoError(t, err)
+	must.False(t, skipped)
+
+	hookErr := errors.New("hook error")
+	_, err = tracker.Run(context.Background(), "err", PhasePrerun, func(context.Context) error {
+		return hookErr
+	})
+	must.ErrorIs(t, err, hookErr)
+	must.SliceEmpty(t, tracker.Running())
+
+	// Overridden hooks return early.
+	skipped, err = runBlocking(t, &tracker, ActionSkip)
+	must.NoError(t, err)
+	must.True(t, skipped)
+
+	skipped, err = runBlocking(t, &tracker, ActionFail)
+	must.ErrorIs(t, err, ErrFailedByOperator)
+	must.False(t, skipped)
+}
+
+func TestTracker_Run_Canceled(t *testing.T) {
+	ci.Parallel(t)
+
+	var tracker Tracker
+
+	// Hooks ignoring their context don't block the runner once its context
+	// is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	releaseCh := make(chan struct{})
+	t.Cleanup(func() { close(releaseCh) })
+
+	skipped, err := tracker.Run(ctx, "stuck", PhasePrerun, func(context.Context) error {
+		<-releaseCh
+		return nil
+	})
+	must.False(t, skipped)
+	must.ErrorIs(t, err, context.Canceled)
+	must.SliceEmpty(t, tracker.Running())
+}
+
+func TestTracker_Run_Timeout(t *testing.T) {
+	ci.Parallel(t)
+
+	tracker := Tracker{
+		Timeout: func(name string) time.Duration {
+			if name == "stuck" {
+				return 10 * time.Millisecond
+			}
+			return 0
+		},
+	}
+
+	// Hooks returning before their timeout return their own result.
+	_, err := tracker.Run(context.Background(), "ok", PhasePrerun, func(context.Context) error {
+		return nil
+	})
+	must.NoError(t, err)
+
+	// Hooks running past their timeout are canceled.
+	canceledCh := make(chan struct{})
+	skipped, err := tracker.Run(context.Background(), "stuck", PhasePrerun, func(ctx context.Context) error {
+		<-ctx.Done()
+		close(canceledCh)
+		return ctx.Err()
+	})
+	must.False(t, skipped)
+	must.EqError(t, err, `hook "stuck" timed out after 10ms`)
+
+	var terr *TimeoutError
+	must.True(t, errors.As(err, &terr))
+	must.Eq(t, PhasePrerun, terr.Phase)
+	<-canceledCh
+	must.SliceEmpty(t, tracker.Running())
+
+	event := TimeoutEvent(terr)
+	must.Eq(t, "Hook timed out", event.Type)
+	must.True(t, event.FailsTask)
+	must.Eq(t, "stuck", event.Details["hook_name"])
+	must.Eq(t, PhasePrerun, event.Details["hook_phase"])
+	must.Eq(t, "10ms", event.Details["hook_timeout"])
+}
+
+func TestTracker_Override_Invalid(t *testing.T) {
+	ci.Parallel(t)
+
+	var tracker Tracker
+	must.EqError(t, tracker.Override("stuck", ActionSkip), `hook "stuck" is not running`)
+	must.EqError(t, tracker.Override("stuck", "retry"),
+		`invalid hook override action "retry": must be "skip" or "fail"`)
+}
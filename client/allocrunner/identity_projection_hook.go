@@ -0,0 +1,253 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocdir"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const identityProjectionHookName = "identity_projection"
+
+// identityProjectionHook projects the workload identities configured with a
+// projection block into directories of a tmpfs in the alloc dir, which the
+// volume task runner hook mounts into their tasks. Like the service account
+// tokens projected by Kubernetes, each directory holds the token, the
+// namespace of the allocation, and optionally the CA bundle of the client, so
+// applications written for Kubernetes can read their identity unchanged. The
+// token is rewritten each time the identity is renewed.
+type identityProjectionHook struct {
+	alloc         *structs.Allocation
+	allocDir      *allocdir.AllocDir
+	widmgr        widmgr.IdentityManager
+	hookResources *cstructs.AllocHookResources
+	caFile        string
+	logger        log.Logger
+
+	// lock synchronizes stop, which is set by Prerun and called when the
+	// allocation stops.
+	lock sync.Mutex
+	stop context.CancelFunc
+}
+
+func newIdentityProjectionHook(
+	logger log.Logger,
+	alloc *structs.Allocation,
+	allocDir *allocdir.AllocDir,
+	widmgr widmgr.IdentityManager,
+	hookResources *cstructs.AllocHookResources,
+	caFile string,
+) *identityProjectionHook {
+	return &identityProjectionHook{
+		alloc:         alloc,
+		allocDir:      allocDir,
+		widmgr:        widmgr,
+		hookResources: hookResources,
+		caFile:        caFile,
+		logger:        logger.Named(identityProjectionHookName),
+	}
+}
+
+func (*identityProjectionHook) Name() string {
+	return identityProjectionHookName
+}
+
+// identityProjection is a workload identity projected into a directory of
+// the identities dir.
+type identityProjection struct {
+	handle     *structs.WIHandle
+	projection *structs.WorkloadIdentityProjection
+	dir        string
+}
+
+func (h *identityProjectionHook) Prerun() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.stop != nil {
+		return nil
+	}
+
+	projections := h.projections()
+	if len(projections) == 0 {
+		return nil
+	}
+
+	if err := h.allocDir.BuildIdentitiesDir(); err != nil {
+		return fmt.Errorf("failed to create identities dir: %w", err)
+	}
+
+	ca, err := h.caBundle(projections)
+	if err != nil {
+		return err
+	}
+
+	mounts := map[string][]*cstructs.IdentityProjection{}
+	for _, p := range projections {
+		swid, err := h.widmgr.Get(*p.handle)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(p.dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create projection dir: %w", err)
+		}
+		if err := p.write(structs.WIProjectionNamespaceFile, []byte(h.alloc.Namespace)); err != nil {
+			return err
+		}
+		if p.projection.CABundle && ca != nil {
+			if err := p.write(structs.WIProjectionCAFile, ca); err != nil {
+				return err
+			}
+		}
+		if err := p.write(p.projection.File, []byte(swid.JWT)); err != nil {
+			return err
+		}
+
+		task := p.handle.WorkloadIdentifier
+		mounts[task] = append(mounts[task], &cstructs.IdentityProjection{
+			HostPath: p.dir,
+			TaskPath: p.projection.Destination,
+		})
+	}
+	h.hookResources.SetIdentityProjections(mounts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.stop = cancel
+	for _, p := range projections {
+		go h.watch(ctx, p)
+	}
+	return nil
+}
+
+// Postrun implements interfaces.RunnerPostrunHook and is called when the
+// allocation stops.
+func (h *identityProjectionHook) Postrun() error {
+	h.stopWatching()
+	return nil
+}
+
+// Destroy implements interfaces.RunnerDestroyHook and is called on
+// allocation GC. The projections are removed along with the alloc dir.
+func (h *identityProjectionHook) Destroy() error {
+	h.stopWatching()
+	return nil
+}
+
+// Shutdown implements interfaces.ShutdownHook and is called when the client
+// gracefully shuts down.
+func (h *identityProjectionHook) Shutdown() {
+	h.stopWatching()
+}
+
+func (h *identityProjectionHook) stopWatching() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.stop != nil {
+		h.stop()
+		h.stop = nil
+	}
+}
+
+// watch rewrites the token of the projection each time its identity is
+// renewed, until the context is canceled.
+func (h *identityProjectionHook) watch(ctx context.Context, p *identityProjection) {
+	ch, cancel := h.widmgr.Watch(*p.handle)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case swid, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := p.write(p.projection.File, []byte(swid.JWT)); err != nil {
+				h.logger.Error("failed to write projected identity",
+					"task", p.handle.WorkloadIdentifier, "identity", p.handle.IdentityName, "error", err)
+			}
+		}
+	}
+}
+
+// projections returns the identities of the tasks of the allocation that are
+// projected.
+func (h *identityProjectionHook) projections() []*identityProjection {
+	tg := h.alloc.Job.LookupTaskGroup(h.alloc.TaskGroup)
+	if tg == nil {
+		return nil
+	}
+
+	var projections []*identityProjection
+	for _, task := range tg.Tasks {
+		for _, wid := range append([]*structs.WorkloadIdentity{task.Identity}, task.Identities...) {
+			if wid == nil || wid.Projection == nil {
+				continue
+			}
+			projections = append(projections, &identityProjection{
+				handle:     task.IdentityHandle(wid),
+				projection: wid.Projection,
+				dir:        filepath.Join(h.allocDir.IdentitiesDir, task.Name, wid.Name),
+			})
+		}
+	}
+	return projections
+}
+
+// caBundle returns the CA bundle of the client if a projection includes it.
+func (h *identityProjectionHook) caBundle(projections []*identityProjection) ([]byte, error) {
+	for _, p := range projections {
+		if !p.projection.CABundle {
+			continue
+		}
+		if h.caFile == "" {
+			h.logger.Warn("identity projection includes the CA bundle but the client has no CA file configured",
+				"task", p.handle.WorkloadIdentifier, "identity", p.handle.IdentityName)
+			return nil, nil
+		}
+		ca, err := os.ReadFile(h.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		return ca, nil
+	}
+	return nil, nil
+}
+
+// write atomically writes a file of the projection, so the tasks never read
+// a partially written token.
+func (p *identityProjection) write(name string, data []byte) error {
+	f, err := os.CreateTemp(p.dir, "."+name)
+	if err != nil {
+		return fmt.Errorf("failed to write projected %s: %w", name, err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write projected %s: %w", name, err)
+	}
+	if err := f.Chmod(p.projection.FileMode()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write projected %s: %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write projected %s: %w", name, err)
+	}
+	if err := os.Rename(f.Name(), filepath.Join(p.dir, name)); err != nil {
+		return fmt.Errorf("failed to write projected %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	cstate "github.com/hashicorp/nomad/client/state"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+var (
+	_ interfaces.RunnerPrerunHook  = (*identityProjectionHook)(nil)
+	_ interfaces.RunnerPostrunHook = (*identityProjectionHook)(nil)
+	_ interfaces.RunnerDestroyHook = (*identityProjectionHook)(nil)
+	_ interfaces.ShutdownHook      = (*identityProjectionHook)(nil)
+)
+
+func TestIdentityProjectionHook(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	// the default identity and a renewed named identity are projected, and
+	// another named identity isn't
+	alloc := mock.Alloc()
+	alloc.Namespace = "platform"
+	task := alloc.LookupTask("web")
+	task.Identity = &structs.WorkloadIdentity{
+		Name:       structs.WorkloadIdentityDefaultName,
+		Projection: &structs.WorkloadIdentityProjection{Destination: "/var/run/nomad"},
+	}
+	task.Identities = []*structs.WorkloadIdentity{
+		{
+			Name:     "vault",
+			Audience: []string{"vault.io"},
+			TTL:      time.Second,
+			Projection: &structs.WorkloadIdentityProjection{
+				Destination: "/var/run/secrets/tokens",
+				File:        "vault-token",
+				CABundle:    true,
+			},
+		},
+		{
+			Name:     "consul",
+			Audience: []string{"consul.io"},
+		},
+	}
+	for _, wid := range append([]*structs.WorkloadIdentity{task.Identity}, task.Identities...) {
+		wid.Canonicalize()
+	}
+	alloc.SignedIdentities = map[string]string{task.Name: "web-default"}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	must.NoError(t, os.WriteFile(caFile, []byte("the ca"), 0o644))
+
+	db := cstate.NewMemDB(logger)
+	mgr := widmgr.NewWIDMgr(widmgr.NewMockWIDSigner(task.Identities), alloc, db, logger)
+	mgr.SetMinWait(time.Second)
+	must.NoError(t, mgr.Run())
+	t.Cleanup(mgr.Shutdown)
+
+	allocDir, cleanupDir := allocdir.TestAllocDir(t, logger, "IdentityProjection", alloc.ID)
+	t.Cleanup(cleanupDir)
+	t.Cleanup(func() { allocDir.UnmountAll() }) // the identities dir is a tmpfs
+
+	hookResources := cstructs.NewAllocHookResources()
+	h := newIdentityProjectionHook(logger, alloc, allocDir, mgr, hookResources, caFile)
+	must.NoError(t, h.Prerun())
+	t.Cleanup(func() { h.Postrun() })
+
+	defaultDir := filepath.Join(allocDir.IdentitiesDir, task.Name, structs.WorkloadIdentityDefaultName)
+	vaultDir := filepath.Join(allocDir.IdentitiesDir, task.Name, "vault")
+
+	must.Eq(t, []*cstructs.IdentityProjection{
+		{HostPath: defaultDir, TaskPath: "/var/run/nomad"},
+		{HostPath: vaultDir, TaskPath: "/var/run/secrets/tokens"},
+	}, hookResources.GetIdentityProjections(task.Name))
+
+	readFile := func(dir, name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		must.NoError(t, err)
+		return string(b)
+	}
+
+	must.Eq(t, "web-default", readFile(defaultDir, structs.WIProjectionDefaultFile))
+	must.Eq(t, "platform", readFile(defaultDir, structs.WIProjectionNamespaceFile))
+	must.FileNotExists(t, filepath.Join(defaultDir, structs.WIProjectionCAFile))
+	must.DirNotExists(t, filepath.Join(allocDir.IdentitiesDir, task.Name, "consul"))
+
+	fi, err := os.Stat(filepath.Join(defaultDir, structs.WIProjectionDefaultFile))
+	must.NoError(t, err)
+	must.Eq(t, os.FileMode(0o644), fi.Mode().Perm())
+
+	must.Eq(t, "platform", readFile(vaultDir, structs.WIProjectionNamespaceFile))
+	must.Eq(t, "the ca", readFile(vaultDir, structs.WIProjectionCAFile))
+	token := readFile(vaultDir, "vault-token")
+	must.StrContains(t, token, ".")
+
+	// the token is rewritten when the identity is renewed
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			return readFile(vaultDir, "vault-token") != token
+		}),
+		wait.Timeout(10*time.Second),
+		wait.Gap(100*time.Millisecond),
+	))
+
+	// Prerun is idempotent
+	must.NoError(t, h.Prerun())
+	must.NoError(t, h.Postrun())
+	must.NoError(t, h.Destroy())
+}
+
+func TestIdentityProjectionHook_NoProjections(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	alloc := mock.Alloc()
+
+	allocDir, cleanupDir := allocdir.TestAllocDir(t, logger, "IdentityProjection", alloc.ID)
+	t.Cleanup(cleanupDir)
+
+	hookResources := cstructs.NewAllocHookResources()
+	h := newIdentityProjectionHook(logger, alloc, allocDir, widmgr.NewMockWIDMgr(nil), hookResources, "")
+	must.NoError(t, h.Prerun())
+	must.NoError(t, h.Postrun())
+
+	must.DirNotExists(t, allocDir.IdentitiesDir)
+	must.SliceEmpty(t, hookResources.GetIdentityProjections("web"))
+}
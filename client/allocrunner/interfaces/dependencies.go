@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package interfaces
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// HookDependencies is implemented by alloc runner and task runner hooks that
+// declare the hooks they depend on, instead of relying on the order of the
+// built-in hooks.
+//
+// A hook implementing HookDependencies is moved after the hooks it requires
+// if its priority would run it earlier, and runs as soon as they have
+// completed in the prerun and prestart phases, concurrently with the other
+// hooks that don't depend on it. It must therefore declare every hook it
+// relies on, such as the alloc or task directory hooks. Hooks that don't
+// implement HookDependencies keep running after all the hooks placed before
+// them.
+//
+// For example, a task runner hook requiring the "identity" and "vault" hooks
+// runs once the task has its workload identities and Vault token, without
+// waiting for the artifacts of the task to be downloaded.
+type HookDependencies interface {
+	// Requires returns the names of the hooks that must complete before the
+	// hook runs. Hooks that are not set up for the runner, such as the Vault
+	// hook of a task without a vault block, are ignored.
+	Requires() []string
+}
+
+// namedHook is the method set shared by the alloc runner and task runner
+// hooks.
+type namedHook interface {
+	Name() string
+}
+
+// requires returns the names of the hooks the hook depends on, and whether
+// the hook declares its dependencies.
+func requires(hook any) ([]string, bool) {
+	d, ok := hook.(HookDependencies)
+	if !ok {
+		return nil, false
+	}
+	return d.Requires(), true
+}
+
+func hookName(hook any) string {
+	if n, ok := hook.(namedHook); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// Sorted returns the hooks in ascending order of priority, with the hooks
+// implementing HookDependencies moved after the hooks they require. It
+// returns an error if the dependencies of the hooks form a cycle.
+func (l *HookList[H]) Sorted() ([]H, error) {
+	byName := make(map[string][]int, len(l.hooks))
+	for i, hook := range l.hooks {
+		name := hookName(hook)
+		byName[name] = append(byName[name], i)
+	}
+
+	// Build the graph of the declared dependencies, ignoring the hooks that
+	// are not part of the list.
+	inDegree := make([]int, len(l.hooks))
+	dependents := make([][]int, len(l.hooks))
+	for i, hook := range l.hooks {
+		names, _ := requires(hook)
+		for _, name := range names {
+			for _, j := range byName[name] {
+				if j == i {
+					continue
+				}
+				dependents[j] = append(dependents[j], i)
+				inDegree[i]++
+			}
+		}
+	}
+
+	// Sort the hooks topologically, always picking the ready hook with the
+	// lowest priority so the hooks without dependencies keep their order.
+	var ready []int
+	for i := range l.hooks {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	sorted := make([]H, 0, len(l.hooks))
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, l.hooks[i])
+
+		for _, j := range dependents[i] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				k, _ := slices.BinarySearch(ready, j)
+				ready = slices.Insert(ready, k, j)
+			}
+		}
+	}
+
+	if len(sorted) != len(l.hooks) {
+		var cycle []string
+		for i, hook := range l.hooks {
+			if inDegree[i] > 0 {
+				cycle = append(cycle, hookName(hook))
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle between hooks: %s", strings.Join(cycle, ", "))
+	}
+	return sorted, nil
+}
+
+// RunHooks calls run for each of the hooks, which must be sorted with
+// HookList.Sorted. Hooks that don't implement HookDependencies run after all
+// the hooks before them, while hooks that do run as soon as the hooks they
+// require have completed, concurrently with the others. A hook doesn't run if
+// a hook it waits for fails.
+//
+// RunHooks returns once all the started hooks have returned, with the error
+// of the first hook that failed.
+func RunHooks[H any](hooks []H, run func(H) error) error {
+	byName := make(map[string][]int, len(hooks))
+	for i, hook := range hooks {
+		name := hookName(hook)
+		byName[name] = append(byName[name], i)
+	}
+
+	type result struct {
+		doneCh chan struct{}
+		failed bool
+	}
+	results := make([]*result, len(hooks))
+	for i := range hooks {
+		results[i] = &result{doneCh: make(chan struct{})}
+	}
+
+	var wg sync.WaitGroup
+	var l sync.Mutex
+	var firstErr error
+
+	for i, hook := range hooks {
+		var waitFor []int
+		if names, ok := requires(hook); ok {
+			for _, name := range names {
+				for _, j := range byName[name] {
+					if j < i {
+						waitFor = append(waitFor, j)
+					}
+				}
+			}
+		} else {
+			for j := 0; j < i; j++ {
+				waitFor = append(waitFor, j)
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, hook H, waitFor []int) {
+			defer wg.Done()
+			res := results[i]
+			defer close(res.doneCh)
+
+			for _, j := range waitFor {
+				<-results[j].doneCh
+				if results[j].failed {
+					res.failed = true
+					return
+				}
+			}
+
+			if err := run(hook); err != nil {
+				res.failed = true
+				l.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				l.Unlock()
+			}
+		}(i, hook, waitFor)
+	}
+
+	wg.Wait()
+	return firstErr
+}
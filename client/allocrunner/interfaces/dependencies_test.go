@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package interfaces
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+type testDependentHook struct {
+	testHook
+	requires []string
+}
+
+func (h testDependentHook) Requires() []string { return h.requires }
+
+func hookNames(hooks []RunnerHook) []string {
+	var names []string
+	for _, hook := range hooks {
+		names = append(names, hook.Name())
+	}
+	return names
+}
+
+func TestHookList_Sorted(t *testing.T) {
+	ci.Parallel(t)
+
+	var hooks HookList[RunnerHook]
+	hooks.Add(AllocHookPriorityIdentity, testHook("identity"))
+	hooks.Add(AllocHookPriorityAllocDir, testHook("alloc_dir"))
+	hooks.Add(AllocHookPriorityAllocDir, testDependentHook{testHook("vault"), []string{"identity", "template"}})
+	hooks.Add(AllocHookPriorityNetwork, testDependentHook{testHook("template"), []string{"identity", "missing"}})
+	hooks.Add(AllocHookPriorityGroupServices, testHook("group_services"))
+
+	sorted, err := hooks.Sorted()
+	must.NoError(t, err)
+	must.Eq(t, []string{
+		"identity",
+		"alloc_dir",
+		"template",
+		"vault",
+		"group_services",
+	}, hookNames(sorted))
+
+	// Cycles are rejected
+	hooks.Add(AllocHookPriorityDefault, testDependentHook{testHook("identity_a"), []string{"identity_b"}})
+	hooks.Add(AllocHookPriorityDefault, testDependentHook{testHook("identity_b"), []string{"identity_a"}})
+	_, err = hooks.Sorted()
+	must.ErrorContains(t, err, "dependency cycle between hooks: identity_a, identity_b")
+}
+
+func TestRunHooks(t *testing.T) {
+	ci.Parallel(t)
+
+	var hooks HookList[RunnerHook]
+	hooks.Add(AllocHookPriorityIdentity, testHook("identity"))
+	hooks.Add(AllocHookPriorityAllocDir, testHook("alloc_dir"))
+	hooks.Add(AllocHookPriorityNetwork, testDependentHook{testHook("slow"), []string{"identity"}})
+	hooks.Add(AllocHookPriorityNetwork, testDependentHook{testHook("fast"), []string{"identity"}})
+	hooks.Add(AllocHookPriorityGroupServices, testHook("group_services"))
+	sorted, err := hooks.Sorted()
+	must.NoError(t, err)
+
+	var l sync.Mutex
+	var ran []string
+	fastDoneCh := make(chan struct{})
+	err = RunHooks(sorted, func(hook RunnerHook) error {
+		// The slow hook can only finish if the fast hook runs concurrently
+		switch hook.Name() {
+		case "slow":
+			select {
+			case <-fastDoneCh:
+			case <-time.After(5 * time.Second):
+				return errors.New("hooks without dependencies between them didn't run concurrently")
+			}
+		case "fast":
+			defer close(fastDoneCh)
+		}
+
+		l.Lock()
+		defer l.Unlock()
+		ran = append(ran, hook.Name())
+		return nil
+	})
+	must.NoError(t, err)
+	must.Len(t, 5, ran)
+	must.Eq(t, "identity", ran[0])
+	must.Eq(t, "group_services", ran[4])
+
+	// The hooks waiting for a failed hook don't run
+	ran = nil
+	err = RunHooks(sorted, func(hook RunnerHook) error {
+		l.Lock()
+		defer l.Unlock()
+		ran = append(ran, hook.Name())
+		if hook.Name() == "alloc_dir" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	must.EqError(t, err, "failed")
+	must.SliceContainsAll(t, []string{"identity", "alloc_dir", "slow", "fast"}, ran)
+	must.SliceNotContains(t, ran, "group_services")
+}
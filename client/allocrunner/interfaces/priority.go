@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package interfaces
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// HookPriority is implemented by alloc runner and task runner hooks that set
+// their position relative to the built-in hooks. Hooks run in ascending order
+// of priority in every phase of the lifecycle, and hooks of equal priority run
+// in the order they were added. For example, an alloc runner hook with a
+// priority between AllocHookPriorityNetwork and AllocHookPriorityGroupServices
+// runs after the network of the allocation is set up, and before its services
+// are registered.
+type HookPriority interface {
+	Priority() int
+}
+
+// Priorities of the built-in alloc runner hooks.
+const (
+	AllocHookPriorityIdentity           = 100
+	AllocHookPriorityAllocDir           = 200
+	AllocHookPriorityIdentityProjection = 220
+	AllocHookPriorityDispatch           = 250
+	AllocHookPriorityConsul             = 300
+	AllocHookPriorityUpstreamAllocs     = 400
+	AllocHookPriorityDiskMigration      = 500
+	AllocHookPriorityDiskQuota          = 600
+	AllocHookPriorityCPUParts           = 700
+	AllocHookPriorityHealth             = 800
+	AllocHookPriorityNetwork            = 900
+	AllocHookPriorityServiceHosts       = 950
+	AllocHookPriorityGroupServices      = 1000
+	AllocHookPriorityConsulSockets      = 1100
+	AllocHookPriorityCSIVolumes         = 1200
+	AllocHookPriorityChecks             = 1300
+
+	// AllocHookPriorityDefault is the priority of the extra hooks that don't
+	// implement HookPriority, which run after all the built-in hooks.
+	AllocHookPriorityDefault = 10000
+)
+
+// Priorities of the built-in task runner hooks.
+const (
+	TaskHookPriorityValidate       = 100
+	TaskHookPriorityTaskDir        = 200
+	TaskHookPriorityIdentity       = 300
+	TaskHookPriorityServiceCerts   = 350
+	TaskHookPriorityCloudCreds     = 375
+	TaskHookPriorityLogMon         = 400
+	TaskHookPriorityDispatch       = 500
+	TaskHookPriorityVolumes        = 600
+	TaskHookPriorityArtifacts      = 700
+	TaskHookPriorityStats          = 800
+	TaskHookPriorityDevices        = 900
+	TaskHookPriorityAPI            = 1000
+	TaskHookPriorityIdentitySocket = 1050
+	TaskHookPriorityWrangler       = 1100
+	TaskHookPriorityCSIPlugin      = 1200
+	TaskHookPriorityVault          = 1300
+	TaskHookPriorityTemplates      = 1400
+	TaskHookPriorityServices       = 1500
+	TaskHookPrioritySIDS           = 1600
+	TaskHookPriorityEnvoyVersion   = 1700
+	TaskHookPriorityEnvoyBootstrap = 1800
+	TaskHookPriorityConnectNative  = 1800
+	TaskHookPriorityScriptChecks   = 1900
+	TaskHookPriorityRemoteTask     = 2000
+
+	// TaskHookPriorityDefault is the priority of the extra hooks that don't
+	// implement HookPriority, which run after all the built-in hooks.
+	TaskHookPriorityDefault = 10000
+)
+
+// TaskHookFactory builds an extra hook for a task of an allocation. It may
+// return nil to skip the task.
+type TaskHookFactory func(alloc *structs.Allocation, task *structs.Task) TaskHook
+
+// HookList is a list of hooks sorted by priority.
+type HookList[H any] struct {
+	hooks      []H
+	priorities []int
+}
+
+// Add adds a hook to the list with the given priority, unless the hook
+// implements HookPriority, in which case its own priority is used.
+func (l *HookList[H]) Add(priority int, hook H) {
+	if p, ok := any(hook).(HookPriority); ok {
+		priority = p.Priority()
+	}
+
+	// Insert the hook after the hooks of lower or equal priority, so hooks
+	// of equal priority keep the order they were added in.
+	i := sort.Search(len(l.priorities), func(i int) bool {
+		return l.priorities[i] > priority
+	})
+	l.hooks = slices.Insert(l.hooks, i, hook)
+	l.priorities = slices.Insert(l.priorities, i, priority)
+}
+
+// Hooks returns the hooks in ascending order of priority.
+func (l *HookList[H]) Hooks() []H {
+	return slices.Clone(l.hooks)
+}
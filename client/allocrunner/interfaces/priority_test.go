@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package interfaces
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+type testHook string
+
+func (h testHook) Name() string { return string(h) }
+
+type testPriorityHook struct {
+	testHook
+	priority int
+}
+
+func (h testPriorityHook) Priority() int { return h.priority }
+
+func TestHookList(t *testing.T) {
+	ci.Parallel(t)
+
+	var hooks HookList[RunnerHook]
+	hooks.Add(AllocHookPriorityDefault, testHook("extra"))
+	hooks.Add(AllocHookPriorityAllocDir, testHook("alloc_dir"))
+	hooks.Add(AllocHookPriorityNetwork, testHook("network"))
+	hooks.Add(AllocHookPriorityDefault, testPriorityHook{testHook("before_network"), AllocHookPriorityNetwork - 1})
+	hooks.Add(AllocHookPriorityDefault, testPriorityHook{testHook("after_network"), AllocHookPriorityNetwork})
+	hooks.Add(AllocHookPriorityConsulSockets, testHook("grpc_socket"))
+	hooks.Add(AllocHookPriorityConsulSockets, testHook("http_socket"))
+
+	var names []string
+	for _, hook := range hooks.Hooks() {
+		names = append(names, hook.Name())
+	}
+	must.Eq(t, []string{
+		"alloc_dir",
+		"before_network",
+		"network",
+		"after_network",
+		"grpc_socket",
+		"http_socket",
+		"extra",
+	}, names)
+}
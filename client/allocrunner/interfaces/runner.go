@@ -43,6 +43,10 @@ type AllocRunner interface {
 	RestartRunning(taskEvent *structs.TaskEvent) error
 	RestartAll(taskEvent *structs.TaskEvent) error
 
+	RunningHooks() []*cstructs.RunningHook
+	OverrideHook(taskName, hookName, action string) error
+	SignedIdentities() []*structs.SignedWorkloadIdentity
+
 	GetTaskEventHandler(taskName string) drivermanager.EventHandler
 	GetTaskExecHandler(taskName string) drivermanager.TaskExecHandler
 	GetTaskDriverCapabilities(taskName string) (*drivers.Capabilities, error)
@@ -6,24 +6,58 @@ package allocrunner
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
 )
 
+const (
+	// migrationStatusInterval is the interval the progress of a migration is
+	// reported to the server on.
+	migrationStatusInterval = 5 * time.Second
+
+	// migrationEventStep is the percentage of the data to migrate between two
+	// task events reporting the progress of a migration.
+	migrationEventStep = 25
+)
+
+// migrationStatusSetter reports the progress of a migration to the server.
+type migrationStatusSetter interface {
+	SetMigrationStatus(*structs.AllocMigrationStatus)
+}
+
 // diskMigrationHook migrates ephemeral disk volumes. Depends on alloc dir
 // being built but must be run before anything else manipulates the alloc dir.
 type diskMigrationHook struct {
 	allocDir     *allocdir.AllocDir
 	allocWatcher config.PrevAllocMigrator
+	statusSetter migrationStatusSetter
+	emitter      taskEventEmitter
 	logger       log.Logger
+
+	// interval is the interval the progress of the migration is reported on.
+	interval time.Duration
+
+	// lastStep is the last multiple of migrationEventStep a task event was
+	// emitted for, or -1 before the migration start event, and ended is true
+	// once the event for the end of the migration was emitted.
+	lastStep int
+	ended    bool
 }
 
-func newDiskMigrationHook(logger log.Logger, allocWatcher config.PrevAllocMigrator, allocDir *allocdir.AllocDir) *diskMigrationHook {
+func newDiskMigrationHook(logger log.Logger, allocWatcher config.PrevAllocMigrator, allocDir *allocdir.AllocDir,
+	statusSetter migrationStatusSetter, emitter taskEventEmitter) *diskMigrationHook {
 	h := &diskMigrationHook{
 		allocDir:     allocDir,
 		allocWatcher: allocWatcher,
+		statusSetter: statusSetter,
+		emitter:      emitter,
+		interval:     migrationStatusInterval,
+		lastStep:     -1,
 	}
 	h.logger = logger.Named(h.Name())
 	return h
@@ -41,8 +75,22 @@ func (h *diskMigrationHook) Prerun() error {
 		return err
 	}
 
+	// Report the progress of the migration while it runs, and wait for the
+	// reporter to exit before reporting the final status.
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		h.watchStatus(stopCh)
+	}()
+
 	// Wait for data to be migrated from a previous alloc if applicable
-	if err := h.allocWatcher.Migrate(ctx, h.allocDir); err != nil {
+	err := h.allocWatcher.Migrate(ctx, h.allocDir)
+	close(stopCh)
+	<-doneCh
+	h.reportStatus(h.allocWatcher.MigrationStatus())
+
+	if err != nil {
 		if err == context.Canceled {
 			return err
 		}
@@ -59,3 +107,61 @@ func (h *diskMigrationHook) Prerun() error {
 
 	return nil
 }
+
+// watchStatus reports the progress of the migration until stopCh is closed.
+func (h *diskMigrationHook) watchStatus(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+		h.reportStatus(h.allocWatcher.MigrationStatus())
+	}
+}
+
+// reportStatus updates the migration status of the allocation, and emits a
+// task event when the migration starts, progresses by another
+// migrationEventStep percent, or ends.
+func (h *diskMigrationHook) reportStatus(status *structs.AllocMigrationStatus) {
+	if status == nil || h.ended {
+		return
+	}
+	h.statusSetter.SetMigrationStatus(status)
+
+	var msg string
+	step := 0
+	if percent := status.Percent(); percent > 0 {
+		step = int(percent) / migrationEventStep * migrationEventStep
+	}
+
+	switch {
+	case status.Error != "":
+		msg = fmt.Sprintf("Failed migrating data from previous allocation after %s: %s",
+			humanize.IBytes(uint64(status.BytesMigrated)), status.Error)
+	case status.Complete:
+		msg = fmt.Sprintf("Migrated %s from previous allocation in %s",
+			humanize.IBytes(uint64(status.BytesMigrated)),
+			status.UpdatedAt.Sub(status.StartedAt).Round(time.Second))
+	case h.lastStep < 0:
+		msg = "Migrating data from previous allocation"
+		if status.BytesTotal > 0 {
+			msg = fmt.Sprintf("Migrating %s from previous allocation", humanize.IBytes(uint64(status.BytesTotal)))
+		}
+	case step > h.lastStep && step < 100:
+		msg = fmt.Sprintf("Migrated %s of %s from previous allocation (%d%%), %s left",
+			humanize.IBytes(uint64(status.BytesMigrated)), humanize.IBytes(uint64(status.BytesTotal)),
+			step, status.ETA.Round(time.Second))
+	default:
+		return
+	}
+
+	h.lastStep = max(step, 0)
+	h.ended = status.Complete || status.Error != ""
+	h.emitter.EmitTaskEvent(structs.NewTaskEvent(structs.TaskMigratingData).
+		SetMessage(msg).
+		SetMigrationStatus(status))
+}
@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+var _ interfaces.RunnerPrerunHook = (*diskMigrationHook)(nil)
+
+type mockMigrationStatusSetter struct {
+	statuses []*structs.AllocMigrationStatus
+}
+
+func (m *mockMigrationStatusSetter) SetMigrationStatus(s *structs.AllocMigrationStatus) {
+	m.statuses = append(m.statuses, s)
+}
+
+// mockPrevAllocMigrator completes a migration with the given status.
+type mockPrevAllocMigrator struct {
+	status *structs.AllocMigrationStatus
+}
+
+func (m *mockPrevAllocMigrator) Wait(context.Context) error { return nil }
+func (m *mockPrevAllocMigrator) IsWaiting() bool            { return false }
+func (m *mockPrevAllocMigrator) IsMigrating() bool          { return false }
+
+func (m *mockPrevAllocMigrator) Migrate(context.Context, *allocdir.AllocDir) error {
+	return nil
+}
+
+func (m *mockPrevAllocMigrator) MigrationStatus() *structs.AllocMigrationStatus {
+	return m.status.Copy()
+}
+
+func TestDiskMigrationHook_reportStatus(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	setter := &mockMigrationStatusSetter{}
+	emitter := &mockTaskEventEmitter{}
+	hook := newDiskMigrationHook(logger, &mockPrevAllocMigrator{}, nil, setter, emitter)
+
+	now := time.Now()
+	status := &structs.AllocMigrationStatus{
+		PreviousAllocID: "prev",
+		BytesTotal:      1000,
+		StartedAt:       now,
+		UpdatedAt:       now,
+	}
+	report := func(migrated int64, complete bool) {
+		status.BytesMigrated = migrated
+		status.Complete = complete
+		status.ETA = time.Duration(1000-migrated) * time.Millisecond
+		hook.reportStatus(status.Copy())
+	}
+
+	// Migrations that haven't started aren't reported
+	hook.reportStatus(nil)
+	must.SliceEmpty(t, setter.statuses)
+
+	report(0, false)
+	must.Len(t, 1, emitter.events)
+	must.Eq(t, structs.TaskMigratingData, emitter.events[0].Type)
+	must.Eq(t, "Migrating 1000 B from previous allocation", emitter.events[0].Message)
+	must.Eq(t, "1000", emitter.events[0].Details["bytes_total"])
+
+	// Events are only emitted when the migration progressed by another step
+	report(100, false)
+	must.Len(t, 1, emitter.events)
+	report(300, false)
+	must.Len(t, 2, emitter.events)
+	must.Eq(t, "Migrated 300 B of 1000 B from previous allocation (25%), 1s left", emitter.events[1].Message)
+	must.Eq(t, "300", emitter.events[1].Details["bytes_migrated"])
+	report(400, false)
+	must.Len(t, 2, emitter.events)
+	report(1000, false)
+	must.Len(t, 2, emitter.events)
+
+	report(1000, true)
+	must.Len(t, 3, emitter.events)
+	must.StrContains(t, emitter.events[2].Message, "Migrated 1000 B from previous allocation")
+
+	// The end of the migration is only reported once
+	report(1000, true)
+	must.Len(t, 3, emitter.events)
+	must.Len(t, 6, setter.statuses)
+	must.True(t, setter.statuses[5].Complete)
+}
+
+func TestDiskMigrationHook_Prerun(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	allocDir := allocdir.NewAllocDir(logger, t.TempDir(), "alloc")
+	must.NoError(t, allocDir.Build())
+
+	// Migrations without a status, such as local ones, are not reported
+	setter := &mockMigrationStatusSetter{}
+	emitter := &mockTaskEventEmitter{}
+	hook := newDiskMigrationHook(logger, &mockPrevAllocMigrator{}, allocDir, setter, emitter)
+	must.NoError(t, hook.Prerun())
+	must.SliceEmpty(t, setter.statuses)
+	must.SliceEmpty(t, emitter.events)
+
+	// The final status of a remote migration is reported
+	migrator := &mockPrevAllocMigrator{
+		status: &structs.AllocMigrationStatus{
+			PreviousAllocID: "prev",
+			Error:           "node not found",
+		},
+	}
+	hook = newDiskMigrationHook(logger, migrator, allocDir, setter, emitter)
+	must.NoError(t, hook.Prerun())
+	must.Len(t, 1, setter.statuses)
+	must.Eq(t, "node not found", setter.statuses[0].Error)
+	must.Len(t, 1, emitter.events)
+	must.Eq(t, "Failed migrating data from previous allocation after 0 B: node not found", emitter.events[0].Message)
+}
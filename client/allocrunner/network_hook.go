@@ -172,6 +172,9 @@ func (h *networkHook) Prerun() error {
 			}
 		}
 
+		if status != nil {
+			status.NamespacePath = spec.Path
+		}
 		h.networkStatusSetter.SetNetworkStatus(status)
 	}
 	return nil
@@ -131,7 +131,12 @@ func (b *bridgeNetworkConfigurator) Setup(ctx context.Context, alloc *structs.Al
 		return nil, fmt.Errorf("failed to initialize table forwarding rules: %v", err)
 	}
 
-	return b.cni.Setup(ctx, alloc, spec)
+	status, err := b.cni.Setup(ctx, alloc, spec)
+	if err != nil {
+		return nil, err
+	}
+	status.BridgeName = b.bridgeName
+	return status, nil
 }
 
 // Teardown calls the CNI plugins with the delete action
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/drivers/shared/hostnames"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// serviceHostsWaitTime is the maximum time the lookups of the services
+	// block waiting for changes.
+	serviceHostsWaitTime = 5 * time.Minute
+
+	// serviceHostsRetryInterval is the time to wait before retrying a failed
+	// lookup of a service.
+	serviceHostsRetryInterval = 10 * time.Second
+)
+
+// serviceHostsHook writes the addresses of the Nomad services set in the DNS
+// configuration of the network of the group to the hosts file shared by the
+// tasks of the allocation, and keeps them up to date as the services change.
+type serviceHostsHook struct {
+	alloc     *structs.Allocation
+	allocDir  string
+	rpcClient config.RPCer
+	logger    log.Logger
+
+	// services is the names of the services to write to the hosts file.
+	services []string
+
+	// addrs is the addresses of each service, guarded by lock.
+	addrs map[string][]string
+	lock  sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newServiceHostsHook(logger log.Logger, alloc *structs.Allocation, allocDir string, rpcClient config.RPCer) *serviceHostsHook {
+	h := &serviceHostsHook{
+		alloc:     alloc,
+		allocDir:  allocDir,
+		rpcClient: rpcClient,
+		addrs:     make(map[string][]string),
+	}
+	h.ctx, h.cancel = context.WithCancel(context.Background())
+	h.logger = logger.Named(h.Name())
+
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg != nil && len(tg.Networks) > 0 && tg.Networks[0].DNS != nil {
+		h.services = tg.Networks[0].DNS.ServiceHosts
+	}
+	return h
+}
+
+func (*serviceHostsHook) Name() string {
+	return "service_hosts"
+}
+
+func (h *serviceHostsHook) Prerun() error {
+	if len(h.services) == 0 {
+		return nil
+	}
+
+	// The services are looked up once before the tasks start, so their
+	// entries are in the hosts file from the start. Failures to look up the
+	// services don't fail the allocation, and are retried in the background.
+	for _, service := range h.services {
+		addrs, index, err := h.lookup(service, 0)
+		if err != nil {
+			h.logger.Warn("failed to look up service", "service", service, "error", err)
+		} else {
+			h.setAddrs(service, addrs)
+		}
+		go h.watch(service, index)
+	}
+	h.render()
+	return nil
+}
+
+func (h *serviceHostsHook) Postrun() error {
+	h.cancel()
+	return nil
+}
+
+func (h *serviceHostsHook) Shutdown() {
+	h.cancel()
+}
+
+// watch looks up the service with blocking queries until the hook is
+// stopped, and renders the hosts file when the addresses of the service
+// change.
+func (h *serviceHostsHook) watch(service string, index uint64) {
+	timer, stop := helper.NewSafeTimer(serviceHostsRetryInterval)
+	defer stop()
+
+	for {
+		addrs, newIndex, err := h.lookup(service, index)
+		if h.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			h.logger.Warn("failed to look up service", "service", service, "error", err)
+			timer.Reset(serviceHostsRetryInterval)
+			select {
+			case <-h.ctx.Done():
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+
+		index = newIndex
+		if h.setAddrs(service, addrs) {
+			h.render()
+		}
+	}
+}
+
+// lookup returns the sorted addresses of the service, and the index of the
+// lookup to block on for changes.
+func (h *serviceHostsHook) lookup(service string, index uint64) ([]string, uint64, error) {
+	req := &structs.ServiceRegistrationByNameRequest{
+		ServiceName: service,
+		QueryOptions: structs.QueryOptions{
+			Region:        h.alloc.Job.Region,
+			Namespace:     h.alloc.Namespace,
+			AuthToken:     h.authToken(),
+			AllowStale:    true,
+			MinQueryIndex: index,
+			MaxQueryTime:  serviceHostsWaitTime,
+		},
+	}
+	var resp structs.ServiceRegistrationByNameResponse
+	if err := h.rpcClient.RPC("ServiceRegistration.GetService", req, &resp); err != nil {
+		return nil, index, err
+	}
+
+	var addrs []string
+	for _, reg := range resp.Services {
+		if reg.Address != "" && !slices.Contains(addrs, reg.Address) {
+			addrs = append(addrs, reg.Address)
+		}
+	}
+	slices.Sort(addrs)
+
+	// The index must be positive for the next lookup to block.
+	return addrs, max(resp.Index, 1), nil
+}
+
+// authToken returns the default workload identity of the first task of the
+// allocation that has one.
+func (h *serviceHostsHook) authToken() string {
+	tg := h.alloc.Job.LookupTaskGroup(h.alloc.TaskGroup)
+	for _, task := range tg.Tasks {
+		if token, ok := h.alloc.SignedIdentities[task.Name]; ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// setAddrs sets the addresses of the service, and returns whether they
+// changed.
+func (h *serviceHostsHook) setAddrs(service string, addrs []string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if slices.Equal(h.addrs[service], addrs) {
+		return false
+	}
+	h.addrs[service] = addrs
+	return true
+}
+
+// render writes the addresses of the services to the hosts file.
+func (h *serviceHostsHook) render() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	var entries []string
+	for _, service := range h.services {
+		for _, addr := range h.addrs[service] {
+			entries = append(entries, fmt.Sprintf("%s %s", addr, service))
+		}
+	}
+	if err := hostnames.WriteServiceHosts(h.allocDir, entries); err != nil {
+		h.logger.Error("failed to write service hosts", "error", err)
+	}
+}
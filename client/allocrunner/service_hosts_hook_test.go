@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allocrunner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+var (
+	_ interfaces.RunnerPrerunHook  = (*serviceHostsHook)(nil)
+	_ interfaces.RunnerPostrunHook = (*serviceHostsHook)(nil)
+	_ interfaces.ShutdownHook      = (*serviceHostsHook)(nil)
+)
+
+// serviceHostsRPCer serves the lookups of the services from a map, and
+// unblocks the blocking lookups when the services are set.
+type serviceHostsRPCer struct {
+	lock     sync.Mutex
+	index    uint64
+	services map[string][]*structs.ServiceRegistration
+	updateCh chan struct{}
+	tokens   []string
+}
+
+func (r *serviceHostsRPCer) set(name string, addrs ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var regs []*structs.ServiceRegistration
+	for _, addr := range addrs {
+		regs = append(regs, &structs.ServiceRegistration{ServiceName: name, Address: addr})
+	}
+	r.services[name] = regs
+	r.index++
+	close(r.updateCh)
+	r.updateCh = make(chan struct{})
+}
+
+func (r *serviceHostsRPCer) RPC(_ string, args any, reply any) error {
+	req := args.(*structs.ServiceRegistrationByNameRequest)
+	resp := reply.(*structs.ServiceRegistrationByNameResponse)
+
+	r.lock.Lock()
+	r.tokens = append(r.tokens, req.AuthToken)
+	for r.index <= req.MinQueryIndex {
+		updateCh := r.updateCh
+		r.lock.Unlock()
+		select {
+		case <-updateCh:
+		case <-time.After(50 * time.Millisecond):
+		}
+		r.lock.Lock()
+	}
+	defer r.lock.Unlock()
+
+	resp.Services = r.services[req.ServiceName]
+	resp.Index = r.index
+	return nil
+}
+
+func TestServiceHostsHook(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	alloc.SignedIdentities = map[string]string{"web": "token"}
+	alloc.Job.TaskGroups[0].Networks = structs.Networks{{
+		Mode: "bridge",
+		DNS:  &structs.DNSConfig{ServiceHosts: []string{"db", "cache"}},
+	}}
+
+	rpcer := &serviceHostsRPCer{
+		index:    1,
+		services: map[string][]*structs.ServiceRegistration{},
+		updateCh: make(chan struct{}),
+	}
+	rpcer.set("db", "10.0.0.2", "10.0.0.1", "10.0.0.1")
+
+	allocDir := t.TempDir()
+	read := func() string {
+		content, err := os.ReadFile(filepath.Join(allocDir, "hosts"))
+		must.NoError(t, err)
+		return string(content)
+	}
+
+	h := newServiceHostsHook(testlog.HCLogger(t), alloc, allocDir, rpcer)
+	must.NoError(t, h.Prerun())
+	t.Cleanup(func() { must.NoError(t, h.Postrun()) })
+
+	// The services are written before the tasks start.
+	content := read()
+	must.StrContains(t, content, "\n10.0.0.1 db\n10.0.0.2 db\n")
+	must.StrNotContains(t, content, "cache")
+
+	// Changes to the services are written as they happen.
+	rpcer.set("cache", "10.0.0.3")
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			return strings.Contains(read(), "\n10.0.0.1 db\n10.0.0.2 db\n10.0.0.3 cache\n")
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	rpcer.lock.Lock()
+	defer rpcer.lock.Unlock()
+	for _, token := range rpcer.tokens {
+		must.Eq(t, "token", token)
+	}
+}
+
+func TestServiceHostsHook_NoServiceHosts(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	allocDir := t.TempDir()
+
+	h := newServiceHostsHook(testlog.HCLogger(t), alloc, allocDir, nil)
+	must.NoError(t, h.Prerun())
+	must.NoError(t, h.Postrun())
+
+	_, err := os.Stat(filepath.Join(allocDir, "hosts"))
+	must.True(t, os.IsNotExist(err))
+}
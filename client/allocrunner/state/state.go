@@ -27,6 +27,10 @@ type State struct {
 
 	// NetworkStatus captures network details not known until runtime
 	NetworkStatus *structs.AllocNetworkStatus
+
+	// MigrationStatus captures the progress of the migration of the previous
+	// allocation's data
+	MigrationStatus *structs.AllocMigrationStatus
 }
 
 // SetDeploymentStatus is a helper for updating the client-controlled
@@ -65,6 +69,7 @@ func (s *State) Copy() *State {
 		DeploymentStatus:  s.DeploymentStatus.Copy(),
 		TaskStates:        taskStates,
 		NetworkStatus:     s.NetworkStatus.Copy(),
+		MigrationStatus:   s.MigrationStatus.Copy(),
 	}
 }
 
@@ -91,3 +96,20 @@ type CSIVolumeStub struct {
 	ExternalNodeID   string
 	MountInfo        *csimanager.MountInfo
 }
+
+// IdentityRenewal is the renewal schedule of a workload identity, persisted so
+// the renewals are restored rather than all recalculated at once after a
+// client restart.
+type IdentityRenewal struct {
+	structs.WIHandle
+
+	// Expiration is the expiration of the identity when the renewal was
+	// scheduled.
+	Expiration time.Time
+
+	// LastRenewal is the time the identity was last renewed, if ever.
+	LastRenewal time.Time
+
+	// NextRenewal is the time the identity is scheduled to be renewed.
+	NextRenewal time.Time
+}
@@ -0,0 +1,395 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/go-cleanhttp"
+	log "github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/users"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// cloudCredentialsMinWait is the minimum time to wait before refreshing
+	// cloud credentials.
+	cloudCredentialsMinWait = 10 * time.Second
+
+	// cloudCredentialsRetryWait is the base time to wait before retrying to
+	// refresh cloud credentials after a failure.
+	cloudCredentialsRetryWait = 10 * time.Second
+
+	// cloudCredentialsTimeout is the timeout of the requests exchanging an
+	// identity for cloud credentials.
+	cloudCredentialsTimeout = 30 * time.Second
+
+	// gcpDefaultScope and azureDefaultScope are the scopes of the access
+	// tokens when the identity doesn't set any.
+	gcpDefaultScope   = "https://www.googleapis.com/auth/cloud-platform"
+	azureDefaultScope = "https://management.azure.com/.default"
+)
+
+// cloudEndpoints are the base URLs of the services exchanging identities for
+// cloud credentials, which tests override. The AWS SDK resolves the endpoint
+// of STS from the region if AWSSTS is empty.
+type cloudEndpoints struct {
+	AWSSTS  string
+	GCPSTS  string
+	GCPIAM  string
+	AzureAD string
+}
+
+var defaultCloudEndpoints = cloudEndpoints{
+	GCPSTS:  "https://sts.googleapis.com",
+	GCPIAM:  "https://iamcredentials.googleapis.com",
+	AzureAD: "https://login.microsoftonline.com",
+}
+
+// cloudCredentialsHook exchanges the workload identities of the task that set
+// a cloud block for the credentials of their cloud provider, and writes them
+// to the task's secrets directory. The credentials are refreshed each time
+// the identity is renewed, and before they expire. AWS credentials are
+// written as a shared credentials file named nomad_<name>_credentials, and
+// GCP and Azure access tokens to a file named nomad_<name>_access_token.
+type cloudCredentialsHook struct {
+	alloc      *structs.Allocation
+	task       *structs.Task
+	secretsDir string
+	widmgr     widmgr.IdentityManager
+	client     *http.Client
+	endpoints  cloudEndpoints
+	logger     log.Logger
+
+	// started is set once the refresh loops are running, since Prestart runs
+	// again when the task restarts.
+	started     bool
+	startedLock sync.Mutex
+
+	stopCtx context.Context
+	stop    context.CancelFunc
+}
+
+func newCloudCredentialsHook(tr *TaskRunner, logger log.Logger) *cloudCredentialsHook {
+	stopCtx, stop := context.WithCancel(context.Background())
+	h := &cloudCredentialsHook{
+		alloc:      tr.Alloc(),
+		task:       tr.Task(),
+		secretsDir: tr.taskDir.SecretsDir,
+		widmgr:     tr.widmgr,
+		client:     &http.Client{Transport: cleanhttp.DefaultPooledTransport()},
+		endpoints:  defaultCloudEndpoints,
+		stopCtx:    stopCtx,
+		stop:       stop,
+	}
+	h.logger = logger.Named(h.Name())
+	return h
+}
+
+// cloudIdentities returns the identities of the task that set a cloud block.
+func cloudIdentities(task *structs.Task) []*structs.WorkloadIdentity {
+	var identities []*structs.WorkloadIdentity
+	for _, wid := range task.Identities {
+		if wid.Cloud != nil {
+			identities = append(identities, wid)
+		}
+	}
+	return identities
+}
+
+func (*cloudCredentialsHook) Name() string {
+	return "cloud_credentials"
+}
+
+func (h *cloudCredentialsHook) Prestart(context.Context, *interfaces.TaskPrestartRequest, *interfaces.TaskPrestartResponse) error {
+	h.startedLock.Lock()
+	defer h.startedLock.Unlock()
+	if h.started {
+		return nil
+	}
+
+	identities := cloudIdentities(h.task)
+	tokens := make([]string, len(identities))
+	expiries := make([]time.Time, len(identities))
+	for i, wid := range identities {
+		id := structs.WIHandle{WorkloadIdentifier: h.task.Name, IdentityName: wid.Name}
+		signed, err := h.widmgr.Get(id)
+		if err != nil {
+			return structs.NewRecoverableError(
+				fmt.Errorf("failed to get identity %q: %w", wid.Name, err), true)
+		}
+		expiry, err := h.setCredentials(wid, signed.JWT)
+		if err != nil {
+			return structs.NewRecoverableError(
+				fmt.Errorf("failed to set cloud credentials of identity %q: %w", wid.Name, err), true)
+		}
+		tokens[i], expiries[i] = signed.JWT, expiry
+	}
+
+	for i, wid := range identities {
+		go h.refresh(wid, tokens[i], expiries[i])
+	}
+	h.started = true
+	return nil
+}
+
+// refresh refreshes the credentials of the identity each time it's renewed,
+// and at about half of their life, until the hook is stopped.
+func (h *cloudCredentialsHook) refresh(wid *structs.WorkloadIdentity, token string, expiry time.Time) {
+	id := structs.WIHandle{WorkloadIdentifier: h.task.Name, IdentityName: wid.Name}
+	signedIdentitiesChan, stopWatching := h.widmgr.Watch(id)
+	defer stopWatching()
+
+	timer, stop := helper.NewSafeTimer(helper.ExpiryToRenewTime(expiry, time.Now, cloudCredentialsMinWait))
+	defer stop()
+
+	for {
+		select {
+		case <-h.stopCtx.Done():
+			return
+		case signed, ok := <-signedIdentitiesChan:
+			if !ok {
+				return
+			}
+			// The watch is primed with the token the credentials were
+			// already exchanged for
+			if signed == nil || signed.JWT == token {
+				continue
+			}
+			token = signed.JWT
+		case <-timer.C:
+		}
+
+		wait := cloudCredentialsRetryWait + helper.RandomStagger(cloudCredentialsRetryWait)
+		expiry, err := h.setCredentials(wid, token)
+		if err != nil {
+			h.logger.Error("failed to refresh cloud credentials", "identity", wid.Name, "error", err)
+		} else {
+			h.logger.Trace("refreshed cloud credentials", "identity", wid.Name, "expiry", expiry)
+			wait = helper.ExpiryToRenewTime(expiry, time.Now, cloudCredentialsMinWait)
+		}
+		timer.Reset(wait)
+	}
+}
+
+// setCredentials exchanges the token of the identity for the credentials of
+// its cloud provider and writes them to the task's secrets directory. It
+// returns the expiry of the credentials.
+func (h *cloudCredentialsHook) setCredentials(wid *structs.WorkloadIdentity, token string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(h.stopCtx, cloudCredentialsTimeout)
+	defer cancel()
+
+	var (
+		name    string
+		content []byte
+		expiry  time.Time
+		err     error
+	)
+	switch wid.Cloud.Provider {
+	case structs.WICloudProviderAWS:
+		name = fmt.Sprintf("nomad_%s_credentials", wid.Name)
+		content, expiry, err = h.exchangeAWS(ctx, wid.Cloud, token)
+	case structs.WICloudProviderGCP:
+		name = fmt.Sprintf("nomad_%s_access_token", wid.Name)
+		content, expiry, err = h.exchangeGCP(ctx, wid.Cloud, token)
+	case structs.WICloudProviderAzure:
+		name = fmt.Sprintf("nomad_%s_access_token", wid.Name)
+		content, expiry, err = h.exchangeAzure(ctx, wid.Cloud, token)
+	default:
+		err = fmt.Errorf("unknown cloud provider %q", wid.Cloud.Provider)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := users.WriteFileFor(filepath.Join(h.secretsDir, name), content, h.task.User); err != nil {
+		return time.Time{}, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return expiry, nil
+}
+
+// exchangeAWS assumes the AWS IAM role with the token, and returns the
+// credentials as a shared credentials file.
+func (h *cloudCredentialsHook) exchangeAWS(ctx context.Context, conf *structs.WorkloadIdentityCloud, token string) ([]byte, time.Time, error) {
+	region := conf.Region
+	if region == "" {
+		region = endpoints.UsEast1RegionID
+	}
+	awsConf := &aws.Config{
+		Region:              aws.String(region),
+		Credentials:         credentials.AnonymousCredentials,
+		HTTPClient:          h.client,
+		STSRegionalEndpoint: endpoints.RegionalSTSEndpoint,
+	}
+	if h.endpoints.AWSSTS != "" {
+		awsConf.Endpoint = aws.String(h.endpoints.AWSSTS)
+	}
+	sess, err := session.NewSession(awsConf)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	out, err := sts.New(sess).AssumeRoleWithWebIdentityWithContext(ctx, &sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(conf.RoleARN),
+		RoleSessionName:  aws.String("nomad-" + h.alloc.ID),
+		WebIdentityToken: aws.String(token),
+	})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to assume role %q: %w", conf.RoleARN, err)
+	}
+	creds := out.Credentials
+	if creds == nil {
+		return nil, time.Time{}, errors.New("failed to assume role: no credentials returned")
+	}
+
+	content := fmt.Sprintf("[default]\naws_access_key_id = %s\naws_secret_access_key = %s\naws_session_token = %s\n",
+		aws.StringValue(creds.AccessKeyId), aws.StringValue(creds.SecretAccessKey), aws.StringValue(creds.SessionToken))
+	return []byte(content), aws.TimeValue(creds.Expiration), nil
+}
+
+// exchangeGCP exchanges the token for a federated access token with the
+// security token service, and for an access token of the service account if
+// set.
+func (h *cloudCredentialsHook) exchangeGCP(ctx context.Context, conf *structs.WorkloadIdentityCloud, token string) ([]byte, time.Time, error) {
+	audience := conf.WorkloadIdentityProvider
+	if !strings.HasPrefix(audience, "//") {
+		audience = "//iam.googleapis.com/" + audience
+	}
+	scopes := conf.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{gcpDefaultScope}
+	}
+
+	var federated struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err := h.post(ctx, h.endpoints.GCPSTS+"/v1/token", url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"audience":             {audience},
+		"scope":                {strings.Join(scopes, " ")},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		"subject_token_type":   {"urn:ietf:params:oauth:token-type:jwt"},
+		"subject_token":        {token},
+	}, nil, &federated)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to exchange token: %w", err)
+	}
+	if conf.ServiceAccount == "" {
+		return []byte(federated.AccessToken), time.Now().Add(time.Duration(federated.ExpiresIn) * time.Second), nil
+	}
+
+	var impersonated struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	endpoint := fmt.Sprintf("%s/v1/projects/-/serviceAccounts/%s:generateAccessToken",
+		h.endpoints.GCPIAM, url.PathEscape(conf.ServiceAccount))
+	err = h.post(ctx, endpoint, map[string][]string{"scope": scopes}, &federated.AccessToken, &impersonated)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to impersonate service account %q: %w", conf.ServiceAccount, err)
+	}
+	return []byte(impersonated.AccessToken), impersonated.ExpireTime, nil
+}
+
+// exchangeAzure exchanges the token for an access token of the application
+// with Microsoft Entra ID, using the token as a client assertion.
+func (h *cloudCredentialsHook) exchangeAzure(ctx context.Context, conf *structs.WorkloadIdentityCloud, token string) ([]byte, time.Time, error) {
+	scopes := conf.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{azureDefaultScope}
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", h.endpoints.AzureAD, url.PathEscape(conf.TenantID))
+	err := h.post(ctx, endpoint, url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {conf.ClientID},
+		"scope":                 {strings.Join(scopes, " ")},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {token},
+	}, nil, &resp)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to exchange token: %w", err)
+	}
+	return []byte(resp.AccessToken), time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second), nil
+}
+
+// post sends a request to the endpoint and decodes its JSON response into
+// out. The body is form encoded if it's url.Values and JSON encoded
+// otherwise, and the bearer token is sent if set.
+func (h *cloudCredentialsHook) post(ctx context.Context, endpoint string, body any, bearer *string, out any) error {
+	var (
+		buf         []byte
+		contentType string
+	)
+	if form, ok := body.(url.Values); ok {
+		buf, contentType = []byte(form.Encode()), "application/x-www-form-urlencoded"
+	} else {
+		var err error
+		if buf, err = json.Marshal(body); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if bearer != nil {
+		req.Header.Set("Authorization", "Bearer "+*bearer)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Responses are small, so limit what's read from misbehaving endpoints
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response code %d: %s", resp.StatusCode, respBody)
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// Stop implements interfaces.TaskStopHook
+func (h *cloudCredentialsHook) Stop(context.Context, *interfaces.TaskStopRequest, *interfaces.TaskStopResponse) error {
+	h.stop()
+	return nil
+}
+
+// Shutdown implements interfaces.ShutdownHook
+func (h *cloudCredentialsHook) Shutdown() {
+	h.stop()
+}
@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	cstate "github.com/hashicorp/nomad/client/state"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
+)
+
+var _ interfaces.TaskPrestartHook = (*cloudCredentialsHook)(nil)
+var _ interfaces.TaskStopHook = (*cloudCredentialsHook)(nil)
+var _ interfaces.ShutdownHook = (*cloudCredentialsHook)(nil)
+
+// testCloudServer returns a server mocking the endpoints of the cloud
+// providers exchanging identities for credentials.
+func testCloudServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/aws/", func(w http.ResponseWriter, r *http.Request) {
+		must.NoError(t, r.ParseForm())
+		must.Eq(t, "AssumeRoleWithWebIdentity", r.PostForm.Get("Action"))
+		must.Eq(t, "arn:aws:iam::123456789012:role/web", r.PostForm.Get("RoleArn"))
+		must.StrHasPrefix(t, "nomad-", r.PostForm.Get("RoleSessionName"))
+		must.NotEq(t, "", r.PostForm.Get("WebIdentityToken"))
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKID</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`)
+	})
+	mux.HandleFunc("/gcp/sts/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		must.NoError(t, r.ParseForm())
+		must.Eq(t, "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/nomad/providers/nomad",
+			r.PostForm.Get("audience"))
+		must.Eq(t, gcpDefaultScope, r.PostForm.Get("scope"))
+		must.NotEq(t, "", r.PostForm.Get("subject_token"))
+		fmt.Fprint(w, `{"access_token": "federated", "expires_in": 3600}`)
+	})
+	mux.HandleFunc("/gcp/iam/v1/projects/-/serviceAccounts/", func(w http.ResponseWriter, r *http.Request) {
+		must.Eq(t, "/gcp/iam/v1/projects/-/serviceAccounts/web@project.iam.gserviceaccount.com:generateAccessToken", r.URL.Path)
+		must.Eq(t, "Bearer federated", r.Header.Get("Authorization"))
+		var body struct{ Scope []string }
+		must.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		must.Eq(t, []string{gcpDefaultScope}, body.Scope)
+		fmt.Fprintf(w, `{"accessToken": "impersonated", "expireTime": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	mux.HandleFunc("/azure/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		must.NoError(t, r.ParseForm())
+		must.Eq(t, "client", r.PostForm.Get("client_id"))
+		must.Eq(t, "https://vault.azure.net/.default", r.PostForm.Get("scope"))
+		must.NotEq(t, "", r.PostForm.Get("client_assertion"))
+		fmt.Fprint(w, `{"access_token": "azure", "expires_in": 3600}`)
+	})
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestCloudCredentialsHook_Prestart asserts the identities of the task are
+// exchanged for the credentials of their cloud provider, which are written to
+// the secrets dir.
+func TestCloudCredentialsHook_Prestart(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	task := alloc.LookupTask("web")
+	task.Identities = []*structs.WorkloadIdentity{
+		{
+			Name:     "aws",
+			Audience: []string{"sts.amazonaws.com"},
+			TTL:      time.Hour,
+			Cloud:    &structs.WorkloadIdentityCloud{Provider: "aws", RoleARN: "arn:aws:iam::123456789012:role/web"},
+		},
+		{
+			Name:     "gcp",
+			Audience: []string{"gcp"},
+			TTL:      time.Hour,
+			Cloud: &structs.WorkloadIdentityCloud{
+				Provider:                 "gcp",
+				WorkloadIdentityProvider: "projects/1/locations/global/workloadIdentityPools/nomad/providers/nomad",
+				ServiceAccount:           "web@project.iam.gserviceaccount.com",
+			},
+		},
+		{
+			Name:     "azure",
+			Audience: []string{"api://AzureADTokenExchange"},
+			TTL:      time.Hour,
+			Cloud: &structs.WorkloadIdentityCloud{
+				Provider: "azure",
+				TenantID: "tenant",
+				ClientID: "client",
+				Scopes:   []string{"https://vault.azure.net/.default"},
+			},
+		},
+		{
+			Name:     "consul",
+			Audience: []string{"consul.io"},
+			TTL:      time.Hour,
+		},
+	}
+	must.SliceLen(t, 3, cloudIdentities(task))
+
+	ts := testCloudServer(t)
+	secretsDir := t.TempDir()
+	stopCtx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+	mockWIDMgr := widmgr.NewWIDMgr(widmgr.NewMockWIDSigner(task.Identities), alloc, db, logger)
+	must.NoError(t, mockWIDMgr.Run())
+	t.Cleanup(mockWIDMgr.Shutdown)
+
+	h := &cloudCredentialsHook{
+		alloc:      alloc,
+		task:       task,
+		secretsDir: secretsDir,
+		widmgr:     mockWIDMgr,
+		client:     ts.Client(),
+		endpoints: cloudEndpoints{
+			AWSSTS:  ts.URL + "/aws/",
+			GCPSTS:  ts.URL + "/gcp/sts",
+			GCPIAM:  ts.URL + "/gcp/iam",
+			AzureAD: ts.URL + "/azure",
+		},
+		logger:  logger,
+		stopCtx: stopCtx,
+		stop:    stop,
+	}
+
+	must.NoError(t, h.Prestart(context.Background(), nil, nil))
+	must.True(t, h.started)
+
+	awsCreds := string(testutil.MustReadFile(t, secretsDir, "nomad_aws_credentials"))
+	must.True(t, strings.HasPrefix(awsCreds, "[default]\n"))
+	must.StrContains(t, awsCreds, "aws_access_key_id = AKID\n")
+	must.StrContains(t, awsCreds, "aws_secret_access_key = secret\n")
+	must.StrContains(t, awsCreds, "aws_session_token = session\n")
+	must.Eq(t, "impersonated", string(testutil.MustReadFile(t, secretsDir, "nomad_gcp_access_token")))
+	must.Eq(t, "azure", string(testutil.MustReadFile(t, secretsDir, "nomad_azure_access_token")))
+
+	must.NoError(t, h.Stop(context.Background(), nil, nil))
+	must.Error(t, h.stopCtx.Err())
+}
+
+// TestCloudCredentialsHook_Prestart_Error asserts a failure to exchange an
+// identity fails the prestart with a recoverable error.
+func TestCloudCredentialsHook_Prestart_Error(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	task := alloc.LookupTask("web")
+	task.Identities = []*structs.WorkloadIdentity{{
+		Name:     "azure",
+		Audience: []string{"api://AzureADTokenExchange"},
+		TTL:      time.Hour,
+		Cloud:    &structs.WorkloadIdentityCloud{Provider: "azure", TenantID: "other", ClientID: "client"},
+	}}
+
+	ts := testCloudServer(t)
+	stopCtx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+	mockWIDMgr := widmgr.NewWIDMgr(widmgr.NewMockWIDSigner(task.Identities), alloc, db, logger)
+	must.NoError(t, mockWIDMgr.Run())
+	t.Cleanup(mockWIDMgr.Shutdown)
+
+	h := &cloudCredentialsHook{
+		alloc:      alloc,
+		task:       task,
+		secretsDir: t.TempDir(),
+		widmgr:     mockWIDMgr,
+		client:     ts.Client(),
+		endpoints:  cloudEndpoints{AzureAD: ts.URL + "/azure"},
+		logger:     logger,
+		stopCtx:    stopCtx,
+		stop:       stop,
+	}
+
+	err := h.Prestart(context.Background(), nil, nil)
+	must.ErrorContains(t, err, "unexpected response code 404")
+	must.True(t, structs.IsRecoverable(err))
+	must.False(t, h.started)
+}
@@ -5,12 +5,14 @@ package taskrunner
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 
 	"github.com/golang/snappy"
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -18,12 +20,20 @@ import (
 type dispatchHook struct {
 	payload []byte
 
+	// stored is true if the payload is kept in the payload store of the
+	// servers, in which case it's fetched by the dispatch payload allocrunner
+	// hook into hookResources.
+	stored        bool
+	hookResources *cstructs.AllocHookResources
+
 	logger hclog.Logger
 }
 
-func newDispatchHook(alloc *structs.Allocation, logger hclog.Logger) *dispatchHook {
+func newDispatchHook(alloc *structs.Allocation, hookResources *cstructs.AllocHookResources, logger hclog.Logger) *dispatchHook {
 	h := &dispatchHook{
-		payload: alloc.Job.Payload,
+		payload:       alloc.Job.Payload,
+		stored:        alloc.Job.PayloadRef != "",
+		hookResources: hookResources,
 	}
 	h.logger = logger.Named(h.Name())
 	return h
@@ -36,6 +46,13 @@ func (*dispatchHook) Name() string {
 }
 
 func (h *dispatchHook) Prestart(ctx context.Context, req *interfaces.TaskPrestartRequest, resp *interfaces.TaskPrestartResponse) error {
+	if h.stored && req.Task.DispatchPayload != nil && req.Task.DispatchPayload.File != "" {
+		h.payload = h.hookResources.GetDispatchPayload()
+		if len(h.payload) == 0 {
+			return errors.New("dispatch payload kept in the payload store was not fetched")
+		}
+	}
+
 	if len(h.payload) == 0 || req.Task.DispatchPayload == nil || req.Task.DispatchPayload.File == "" {
 		// No dispatch payload
 		resp.Done = true
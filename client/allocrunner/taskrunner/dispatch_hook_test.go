@@ -13,9 +13,11 @@ import (
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,7 +42,7 @@ func TestTaskRunner_DispatchHook_NoPayload(t *testing.T) {
 	taskDir := allocDir.NewTaskDir(task.Name)
 	require.NoError(taskDir.Build(false, nil))
 
-	h := newDispatchHook(alloc, logger)
+	h := newDispatchHook(alloc, cstructs.NewAllocHookResources(), logger)
 
 	req := interfaces.TaskPrestartRequest{
 		Task:    task,
@@ -86,7 +88,7 @@ func TestTaskRunner_DispatchHook_Ok(t *testing.T) {
 	taskDir := allocDir.NewTaskDir(task.Name)
 	require.NoError(taskDir.Build(false, nil))
 
-	h := newDispatchHook(alloc, logger)
+	h := newDispatchHook(alloc, cstructs.NewAllocHookResources(), logger)
 
 	req := interfaces.TaskPrestartRequest{
 		Task:    task,
@@ -131,7 +133,7 @@ func TestTaskRunner_DispatchHook_Error(t *testing.T) {
 	taskDir := allocDir.NewTaskDir(task.Name)
 	require.NoError(taskDir.Build(false, nil))
 
-	h := newDispatchHook(alloc, logger)
+	h := newDispatchHook(alloc, cstructs.NewAllocHookResources(), logger)
 
 	req := interfaces.TaskPrestartRequest{
 		Task:    task,
@@ -148,3 +150,50 @@ func TestTaskRunner_DispatchHook_Error(t *testing.T) {
 	require.NoError(err)
 	require.Empty(files)
 }
+
+// TestTaskRunner_DispatchHook_Stored asserts that dispatch payloads kept in the
+// payload store of the servers are written from the alloc hook resources, and
+// that the hook fails if they weren't fetched.
+func TestTaskRunner_DispatchHook_Stored(t *testing.T) {
+	ci.Parallel(t)
+
+	ctx := context.Background()
+	logger := testlog.HCLogger(t)
+
+	alloc := mock.BatchAlloc()
+	alloc.Job.ParameterizedJob = &structs.ParameterizedJobConfig{
+		Payload: structs.DispatchPayloadRequired,
+	}
+	alloc.Job.PayloadRef = "jobs/default/example/payload"
+	expected := []byte("hello world")
+
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.DispatchPayload = &structs.DispatchPayloadConfig{
+		File: "out",
+	}
+
+	allocDir := allocdir.NewAllocDir(logger, "nomadtest_dispatchstored", alloc.ID)
+	defer allocDir.Destroy()
+	taskDir := allocDir.NewTaskDir(task.Name)
+	must.NoError(t, taskDir.Build(false, nil))
+
+	req := interfaces.TaskPrestartRequest{
+		Task:    task,
+		TaskDir: taskDir,
+	}
+
+	// The payload wasn't fetched
+	hookResources := cstructs.NewAllocHookResources()
+	h := newDispatchHook(alloc, hookResources, logger)
+	resp := interfaces.TaskPrestartResponse{}
+	must.Error(t, h.Prestart(ctx, &req, &resp))
+	must.False(t, resp.Done)
+
+	hookResources.SetDispatchPayload(snappy.Encode(nil, expected))
+	must.NoError(t, h.Prestart(ctx, &req, &resp))
+	must.True(t, resp.Done)
+
+	result, err := os.ReadFile(filepath.Join(req.TaskDir.LocalDir, task.DispatchPayload.File))
+	must.NoError(t, err)
+	must.Eq(t, expected, result)
+}
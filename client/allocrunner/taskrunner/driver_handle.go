@@ -5,6 +5,7 @@ package taskrunner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -20,13 +21,20 @@ func NewDriverHandle(
 	task *structs.Task,
 	maxKillTimeout time.Duration,
 	net *drivers.DriverNetwork) *DriverHandle {
-	return &DriverHandle{
+	h := &DriverHandle{
 		driver:      driver,
 		net:         net,
 		taskID:      taskID,
 		killSignal:  task.KillSignal,
 		killTimeout: min(task.KillTimeout, maxKillTimeout),
 	}
+	for _, step := range task.KillEscalation {
+		h.killEscalation = append(h.killEscalation, &structs.KillEscalationStep{
+			Signal:  step.Signal,
+			Timeout: min(step.Timeout, maxKillTimeout),
+		})
+	}
+	return h
 }
 
 // DriverHandle encapsulates a driver plugin client and task identifier and exposes
@@ -37,6 +45,10 @@ type DriverHandle struct {
 	taskID      string
 	killSignal  string
 	killTimeout time.Duration
+
+	// killEscalation is the sequence of signals sent to kill the task, which
+	// replaces killSignal and killTimeout if set.
+	killEscalation []*structs.KillEscalationStep
 }
 
 func (h *DriverHandle) ID() string {
@@ -47,13 +59,65 @@ func (h *DriverHandle) WaitCh(ctx context.Context) (<-chan *drivers.ExitResult,
 	return h.driver.WaitTask(ctx, h.taskID)
 }
 
-// SetKillSignal allows overriding the signal sent to kill the task.
+// SetKillSignal allows overriding the signal sent to kill the task. It
+// disables the kill escalation of the task.
 func (h *DriverHandle) SetKillSignal(signal string) {
 	h.killSignal = signal
+	h.killEscalation = nil
 }
 
+// Kill stops the task. If the task has a kill escalation, the signal of each
+// step but the last is sent in turn, until the task exits within the timeout
+// of the step, and the task is then stopped with the signal and timeout of the
+// last step.
 func (h *DriverHandle) Kill() error {
-	return h.driver.StopTask(h.taskID, h.killTimeout, h.killSignal)
+	if len(h.killEscalation) == 0 {
+		return h.driver.StopTask(h.taskID, h.killTimeout, h.killSignal)
+	}
+
+	last := len(h.killEscalation) - 1
+	for _, step := range h.killEscalation[:last] {
+		exited, err := h.signalAndWait(step)
+		if err != nil {
+			return err
+		}
+		if exited {
+			return nil
+		}
+	}
+
+	step := h.killEscalation[last]
+	return h.driver.StopTask(h.taskID, step.Timeout, step.Signal)
+}
+
+// signalAndWait sends the signal of the kill escalation step to the task, and
+// returns whether the task exited within the timeout of the step. The task
+// is assumed to still be running if it can't be signalled or waited on.
+func (h *DriverHandle) signalAndWait(step *structs.KillEscalationStep) (bool, error) {
+	if err := h.driver.SignalTask(h.taskID, step.Signal); err != nil {
+		if errors.Is(err, drivers.ErrTaskNotFound) {
+			return false, drivers.ErrTaskNotFound
+		}
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), step.Timeout)
+	defer cancel()
+
+	waitCh, err := h.driver.WaitTask(ctx, h.taskID)
+	if err != nil {
+		if errors.Is(err, drivers.ErrTaskNotFound) {
+			return false, drivers.ErrTaskNotFound
+		}
+		return false, nil
+	}
+
+	select {
+	case result, ok := <-waitCh:
+		return ok && result != nil, nil
+	case <-ctx.Done():
+		return false, nil
+	}
 }
 
 func (h *DriverHandle) Stats(ctx context.Context, interval time.Duration) (<-chan *cstructs.TaskResourceUsage, error) {
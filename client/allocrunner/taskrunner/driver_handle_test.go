@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/shoenig/test/must"
+)
+
+// escalationDriver is a driver whose task exits once it receives the exit
+// signal.
+type escalationDriver struct {
+	drivers.DriverPlugin
+
+	exitSignal string
+	exitCh     chan *drivers.ExitResult
+
+	signals []string
+	stopped []string
+}
+
+func (d *escalationDriver) SignalTask(_ string, signal string) error {
+	d.signals = append(d.signals, signal)
+	if signal == d.exitSignal {
+		d.exitCh <- &drivers.ExitResult{Signal: 15}
+	}
+	return nil
+}
+
+func (d *escalationDriver) WaitTask(ctx context.Context, _ string) (<-chan *drivers.ExitResult, error) {
+	ch := make(chan *drivers.ExitResult, 1)
+	go func() {
+		defer close(ch)
+		select {
+		case result := <-d.exitCh:
+			ch <- result
+		case <-ctx.Done():
+		}
+	}()
+	return ch, nil
+}
+
+func (d *escalationDriver) StopTask(_ string, _ time.Duration, signal string) error {
+	d.stopped = append(d.stopped, signal)
+	return nil
+}
+
+func TestDriverHandle_KillEscalation(t *testing.T) {
+	ci.Parallel(t)
+
+	task := &structs.Task{
+		KillSignal:  "SIGINT",
+		KillTimeout: time.Second,
+		KillEscalation: []*structs.KillEscalationStep{
+			{Signal: "SIGTERM", Timeout: 10 * time.Millisecond},
+			{Signal: "SIGQUIT", Timeout: time.Second},
+			{Signal: "SIGUSR1", Timeout: time.Second},
+		},
+	}
+
+	// The task ignores the first signal and exits on the second one, so
+	// the last step is never reached.
+	driver := &escalationDriver{exitSignal: "SIGQUIT", exitCh: make(chan *drivers.ExitResult, 1)}
+	handle := NewDriverHandle(driver, "id", task, time.Minute, nil)
+	must.NoError(t, handle.Kill())
+	must.Eq(t, []string{"SIGTERM", "SIGQUIT"}, driver.signals)
+	must.SliceEmpty(t, driver.stopped)
+
+	// The task ignores all the signals, so it's stopped with the signal of
+	// the last step.
+	driver = &escalationDriver{exitCh: make(chan *drivers.ExitResult, 1)}
+	task.KillEscalation[1].Timeout = 10 * time.Millisecond
+	handle = NewDriverHandle(driver, "id", task, time.Minute, nil)
+	must.NoError(t, handle.Kill())
+	must.Eq(t, []string{"SIGTERM", "SIGQUIT"}, driver.signals)
+	must.Eq(t, []string{"SIGUSR1"}, driver.stopped)
+
+	// Overriding the kill signal disables the escalation.
+	driver = &escalationDriver{exitCh: make(chan *drivers.ExitResult, 1)}
+	handle = NewDriverHandle(driver, "id", task, time.Minute, nil)
+	handle.SetKillSignal("SIGHUP")
+	must.NoError(t, handle.Kill())
+	must.SliceEmpty(t, driver.signals)
+	must.Eq(t, []string{"SIGHUP"}, driver.stopped)
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/fs"
 	"maps"
+	"net"
 	"slices"
 	"strings"
 	"time"
@@ -40,6 +41,10 @@ type parameters struct {
 	Destination string              `json:"artifact_destination"`
 	Headers     map[string][]string `json:"artifact_headers"`
 
+	// ResolvedAddrs are the addresses of the hosts of the artifact source
+	// resolved by the client's DNS cache, indexed by host.
+	ResolvedAddrs map[string][]string `json:"resolved_addrs,omitempty"`
+
 	// Task Filesystem
 	AllocDir string `json:"alloc_dir"`
 	TaskDir  string `json:"task_dir"`
@@ -110,6 +115,8 @@ func (p *parameters) Equal(o *parameters) bool {
 		return false
 	case !maps.EqualFunc(p.Headers, o.Headers, headersCompareFn):
 		return false
+	case !maps.EqualFunc(p.ResolvedAddrs, o.ResolvedAddrs, slices.Equal[[]string]):
+		return false
 	}
 
 	return true
@@ -127,10 +134,39 @@ const (
 	umask = fs.ModeSetuid | fs.ModeSetgid
 )
 
+// dialContext returns a dial function that connects to the addresses resolved
+// by the client for the hosts of the artifact source, and otherwise falls back
+// to dial.
+func (p *parameters) dialContext(dial func(context.Context, string, string) (net.Conn, error)) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return dial(ctx, network, address)
+		}
+		for _, addr := range p.ResolvedAddrs[host] {
+			conn, err := dial(ctx, network, net.JoinHostPort(addr, port))
+			if err == nil {
+				return conn, nil
+			}
+			if ctx.Err() != nil {
+				return nil, err
+			}
+		}
+		return dial(ctx, network, address)
+	}
+}
+
 func (p *parameters) client(ctx context.Context) *getter.Client {
+	httpClient := cleanhttp.DefaultClient()
+	if len(p.ResolvedAddrs) > 0 {
+		transport := cleanhttp.DefaultTransport()
+		transport.DialContext = p.dialContext(transport.DialContext)
+		httpClient.Transport = transport
+	}
+
 	httpGetter := &getter.HttpGetter{
 		Netrc:  true,
-		Client: cleanhttp.DefaultClient(),
+		Client: httpClient,
 		Header: p.Headers,
 
 		// Do not support the custom X-Terraform-Get header and
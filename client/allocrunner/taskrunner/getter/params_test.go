@@ -6,6 +6,9 @@ package getter
 import (
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -147,3 +150,29 @@ func TestParameters_Equal_headers(t *testing.T) {
 	p2.Headers["East"] = []string{"New York"}
 	must.NotEqual(t, p1, p2)
 }
+
+func TestParameters_client_resolvedAddrs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Host)
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	must.NoError(t, err)
+
+	p := &parameters{
+		Source: "http://artifacts.example.invalid:" + u.Port() + "/file.txt",
+		ResolvedAddrs: map[string][]string{
+			"artifacts.example.invalid": {"127.0.0.1"},
+		},
+	}
+	c := p.client(context.Background())
+	httpGetter := c.Getters["http"].(*getter.HttpGetter)
+
+	// The host of the source is dialed at its resolved address
+	resp, err := httpGetter.Client.Get(p.Source)
+	must.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	must.NoError(t, err)
+	must.Eq(t, "artifacts.example.invalid:"+u.Port(), string(b))
+}
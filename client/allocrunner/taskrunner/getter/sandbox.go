@@ -4,24 +4,31 @@
 package getter
 
 import (
+	"context"
+	"net/url"
+
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/dnscache"
 	"github.com/hashicorp/nomad/client/interfaces"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
-// New creates a Sandbox with the given ArtifactConfig.
-func New(ac *config.ArtifactConfig, logger hclog.Logger) *Sandbox {
+// New creates a Sandbox with the given ArtifactConfig. If resolver is set, the
+// hosts of HTTP artifact sources are resolved with it.
+func New(ac *config.ArtifactConfig, resolver *dnscache.Cache, logger hclog.Logger) *Sandbox {
 	return &Sandbox{
-		logger: logger.Named("artifact"),
-		ac:     ac,
+		logger:   logger.Named("artifact"),
+		ac:       ac,
+		resolver: resolver,
 	}
 }
 
 // A Sandbox is used to download artifacts.
 type Sandbox struct {
-	logger hclog.Logger
-	ac     *config.ArtifactConfig
+	logger   hclog.Logger
+	ac       *config.ArtifactConfig
+	resolver *dnscache.Cache
 }
 
 func (s *Sandbox) Get(env interfaces.EnvReplacer, artifact *structs.TaskArtifact) error {
@@ -65,8 +72,31 @@ func (s *Sandbox) Get(env interfaces.EnvReplacer, artifact *structs.TaskArtifact
 		TaskDir:  taskDir,
 	}
 
+	if s.resolver != nil {
+		params.ResolvedAddrs = s.resolve(source)
+	}
+
 	if err = s.runCmd(params); err != nil {
 		return err
 	}
 	return nil
 }
+
+// resolve returns the addresses of the host of HTTP artifact sources resolved
+// with the DNS cache of the client, so the download doesn't depend on the
+// availability of the resolver. Other sources are resolved by the download
+// sub-process.
+func (s *Sandbox) resolve(source string) map[string][]string {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil
+	}
+
+	addrs, err := s.resolver.LookupHost(context.Background(), u.Hostname())
+	if err != nil {
+		// Let the sub-process resolve the host and report the error
+		s.logger.Debug("failed to resolve artifact source", "host", u.Hostname(), "error", err)
+		return nil
+	}
+	return map[string][]string{u.Hostname(): addrs}
+}
@@ -4,12 +4,15 @@
 package getter
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/dnscache"
 	"github.com/hashicorp/nomad/client/testutil"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -34,7 +37,7 @@ func TestSandbox_Get_http(t *testing.T) {
 	logger := testlog.HCLogger(t)
 
 	ac := artifactConfig(10 * time.Second)
-	sbox := New(ac, logger)
+	sbox := New(ac, nil, logger)
 
 	_, taskDir := SetupDir(t)
 	env := noopTaskEnv(taskDir)
@@ -51,3 +54,30 @@ func TestSandbox_Get_http(t *testing.T) {
 	must.NoError(t, err)
 	must.StrContains(t, string(b), "module github.com/hashicorp/go-set")
 }
+
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := r[host]; ok {
+		return addrs, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestSandbox_resolve(t *testing.T) {
+	logger := testlog.HCLogger(t)
+	resolver := dnscache.NewWithResolver(logger, staticResolver{
+		"artifacts.example.com": {"10.0.0.1"},
+	}, time.Minute, time.Hour)
+	sbox := New(artifactConfig(10*time.Second), resolver, logger)
+
+	must.Eq(t, map[string][]string{"artifacts.example.com": {"10.0.0.1"}},
+		sbox.resolve("https://artifacts.example.com:8443/file.tar.gz"))
+
+	// Unknown hosts are left to the sub-process
+	must.Nil(t, sbox.resolve("https://unknown.example.com/file.tar.gz"))
+
+	// Only HTTP sources are resolved
+	must.Nil(t, sbox.resolve("git::https://artifacts.example.com/repo.git"))
+	must.Nil(t, sbox.resolve("s3::https://s3.amazonaws.com/bucket/file"))
+}
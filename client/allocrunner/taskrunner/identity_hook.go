@@ -5,8 +5,16 @@ package taskrunner
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/consul-template/signals"
@@ -41,10 +49,17 @@ type identityHook struct {
 	tokenDir   string
 	envBuilder *taskenv.Builder
 	lifecycle  ti.TaskLifecycle
+	events     ti.EventEmitter
 	ts         tokenSetter
 	widmgr     widmgr.IdentityManager
 	logger     log.Logger
 
+	// driverHandle is the task driver executor used to run scripts when the
+	// change mode of an identity is set to script. It's set by Poststart and
+	// may be nil.
+	driverHandle ti.ScriptExecutor
+	handleLock   sync.Mutex
+
 	stopCtx context.Context
 	stop    context.CancelFunc
 }
@@ -57,6 +72,7 @@ func newIdentityHook(tr *TaskRunner, logger log.Logger) *identityHook {
 		tokenDir:   tr.taskDir.SecretsDir,
 		envBuilder: tr.envBuilder,
 		lifecycle:  tr,
+		events:     tr,
 		ts:         tr,
 		widmgr:     tr.widmgr,
 		stopCtx:    stopCtx,
@@ -113,6 +129,22 @@ func (h *identityHook) Prestart(ctx context.Context, _ *interfaces.TaskPrestartR
 	return nil
 }
 
+func (h *identityHook) Poststart(_ context.Context, req *interfaces.TaskPoststartRequest, _ *interfaces.TaskPoststartResponse) error {
+	h.handleLock.Lock()
+	defer h.handleLock.Unlock()
+
+	h.driverHandle = req.DriverExec
+	if h.driverHandle != nil {
+		return nil
+	}
+	for _, widspec := range h.task.Identities {
+		if widspec.ChangeMode == structs.WIChangeModeScript {
+			return fmt.Errorf("identity %q has change mode set to 'script' but the driver it uses does not provide exec capability", widspec.Name)
+		}
+	}
+	return nil
+}
+
 func (h *identityHook) watchIdentity(wid *structs.WorkloadIdentity, runCh chan struct{}) {
 	id := structs.WIHandle{WorkloadIdentifier: h.task.Name, IdentityName: wid.Name}
 	signedIdentitiesChan, stopWatching := h.widmgr.Watch(id)
@@ -141,6 +173,18 @@ func (h *identityHook) watchIdentity(wid *structs.WorkloadIdentity, runCh chan s
 				h.logger.Error(err.Error())
 			}
 
+			if wid.RequiresCertificate() {
+				if err := h.setCertificate(wid); err != nil {
+					h.logger.Error("failed to set certificate", "identity", wid.Name, "error", err)
+				}
+			}
+
+			if wid.SPIFFE != nil && wid.SPIFFE.JWT {
+				if err := h.writeSPIFFEFiles(map[string][]byte{structs.WISPIFFEJWTFile: []byte(signedWID.JWT)}); err != nil {
+					h.logger.Error("failed to set JWT-SVID", "identity", wid.Name, "error", err)
+				}
+			}
+
 			// Skip ChangeMode on firstRun and notify caller it can proceed
 			if firstRun {
 				select {
@@ -179,6 +223,8 @@ func (h *identityHook) watchIdentity(wid *structs.WorkloadIdentity, runCh chan s
 					return
 				}
 
+			case structs.WIChangeModeScript:
+				h.runScript(wid)
 			}
 
 			// Note: any code added here will not run on first run
@@ -202,6 +248,48 @@ func (h *identityHook) signalTask(wid *structs.WorkloadIdentity) error {
 	return h.lifecycle.Signal(event, wid.ChangeSignal)
 }
 
+// runScript runs the change script of an identity in the task, and kills the
+// task if the script fails and is configured to fail on error.
+func (h *identityHook) runScript(wid *structs.WorkloadIdentity) {
+	script := wid.ChangeScript
+
+	h.handleLock.Lock()
+	handle := h.driverHandle
+	h.handleLock.Unlock()
+
+	var failureMsg string
+	if handle == nil {
+		failureMsg = fmt.Sprintf("Identity[%s]: failed to run script %v with arguments %v because task driver doesn't support the exec operation",
+			wid.Name, script.Command, script.Args)
+	} else {
+		_, exitCode, err := handle.Exec(script.Timeout, script.Command, script.Args)
+		switch {
+		case err != nil:
+			failureMsg = fmt.Sprintf("Identity[%s]: failed to run script %v with arguments %v on change: %v Exit code: %v",
+				wid.Name, script.Command, script.Args, err, exitCode)
+		case exitCode != 0:
+			failureMsg = fmt.Sprintf("Identity[%s]: ran script %v with arguments %v on change but it exited with code: %v",
+				wid.Name, script.Command, script.Args, exitCode)
+		}
+	}
+
+	if failureMsg == "" {
+		h.events.EmitEvent(structs.NewTaskEvent(structs.TaskHookMessage).
+			SetDisplayMessage(fmt.Sprintf("Identity[%s]: successfully ran script %v with arguments: %v",
+				wid.Name, script.Command, script.Args)))
+		return
+	}
+
+	h.events.EmitEvent(structs.NewTaskEvent(structs.TaskHookFailed).SetDisplayMessage(failureMsg))
+	if script.FailOnError {
+		// Ignore error from kill because if that fails there's really nothing
+		// to be done.
+		_ = h.lifecycle.Kill(h.stopCtx, structs.NewTaskEvent(structs.TaskKilling).
+			SetFailsTask().
+			SetDisplayMessage(fmt.Sprintf("Identity[%s]: script failed, task is being killed", wid.Name)))
+	}
+}
+
 // setDefaultToken adds the Nomad token to the task's environment and writes it to a
 // file if requested by the jobsepc.
 func (h *identityHook) setDefaultToken() error {
@@ -242,6 +330,76 @@ func (h *identityHook) setAltToken(widspec *structs.WorkloadIdentity, rawJWT str
 	return nil
 }
 
+// setCertificate generates a new private key for an identity using x509 or a
+// SPIFFE X.509-SVID, and writes it to the task's secrets directory along with
+// its certificate signed by the servers and the certificate of the CA.
+func (h *identityHook) setCertificate(widspec *structs.WorkloadIdentity) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate private key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: h.alloc.ID},
+	}, key)
+	if err != nil {
+		return fmt.Errorf("failed to create certificate signing request: %w", err)
+	}
+
+	id := structs.WIHandle{WorkloadIdentifier: h.task.Name, IdentityName: widspec.Name}
+	resp, err := h.widmgr.SignCertificate(id, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+	if err != nil {
+		return fmt.Errorf("failed to sign certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	if widspec.X509 {
+		files := map[string][]byte{
+			fmt.Sprintf("nomad_%s.key", widspec.Name):    keyPEM,
+			fmt.Sprintf("nomad_%s.crt", widspec.Name):    resp.Certificate,
+			fmt.Sprintf("nomad_%s_ca.crt", widspec.Name): resp.CACertificate,
+		}
+		for name, content := range files {
+			if err := users.WriteFileFor(filepath.Join(h.tokenDir, name), content, h.task.User); err != nil {
+				return fmt.Errorf("failed to write certificate for identity %q: %w", widspec.Name, err)
+			}
+		}
+	}
+
+	if widspec.SPIFFE != nil && widspec.SPIFFE.X509 {
+		err := h.writeSPIFFEFiles(map[string][]byte{
+			structs.WISPIFFEKeyFile:    keyPEM,
+			structs.WISPIFFECertFile:   resp.Certificate,
+			structs.WISPIFFEBundleFile: resp.CACertificate,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write X.509-SVID for identity %q: %w", widspec.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// writeSPIFFEFiles writes the files of the SVIDs of an identity to the spiffe
+// directory of the task's secrets directory.
+func (h *identityHook) writeSPIFFEFiles(files map[string][]byte) error {
+	dir := filepath.Join(h.tokenDir, structs.WISPIFFEDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create spiffe dir: %w", err)
+	}
+	for name, content := range files {
+		if err := users.WriteFileFor(filepath.Join(dir, name), content, h.task.User); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Stop implements interfaces.TaskStopHook
 func (h *identityHook) Stop(context.Context, *interfaces.TaskStopRequest, *interfaces.TaskStopResponse) error {
 	h.stop()
@@ -5,6 +5,9 @@ package taskrunner
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"path/filepath"
 	"testing"
 	"time"
@@ -23,6 +26,7 @@ import (
 )
 
 var _ interfaces.TaskPrestartHook = (*identityHook)(nil)
+var _ interfaces.TaskPoststartHook = (*identityHook)(nil)
 var _ interfaces.TaskStopHook = (*identityHook)(nil)
 var _ interfaces.ShutdownHook = (*identityHook)(nil)
 
@@ -38,6 +42,18 @@ func (m *MockTokenSetter) setNomadToken(token string) {
 	m.defaultToken = token
 }
 
+// mockScriptExecutor is a mock implementation of ScriptExecutor recording
+// the commands it runs.
+type mockScriptExecutor struct {
+	exitCode int
+	cmds     chan string
+}
+
+func (m *mockScriptExecutor) Exec(_ time.Duration, cmd string, _ []string) ([]byte, int, error) {
+	m.cmds <- cmd
+	return nil, m.exitCode, nil
+}
+
 // TestIdentityHook_RenewAll asserts token renewal happens when expected.
 func TestIdentityHook_RenewAll(t *testing.T) {
 	ci.Parallel(t)
@@ -244,6 +260,123 @@ func TestIdentityHook_RenewOne(t *testing.T) {
 	must.Eq(t, newVault, testutil.MustReadFile(t, secretsDir, "nomad_vault.jwt"))
 }
 
+// TestIdentityHook_Certificate asserts the certificate and private key of
+// identities using x509 are written to the secrets dir.
+func TestIdentityHook_Certificate(t *testing.T) {
+	ci.Parallel(t)
+
+	node := mock.Node()
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	task := alloc.LookupTask("web")
+	task.Identities = []*structs.WorkloadIdentity{
+		{
+			Name:     "mtls",
+			Audience: []string{"mtls"},
+			X509:     true,
+			TTL:      time.Hour,
+		},
+	}
+
+	secretsDir := t.TempDir()
+
+	stopCtx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+	mockSigner := widmgr.NewMockWIDSigner(task.Identities)
+	mockWIDMgr := widmgr.NewWIDMgr(mockSigner, alloc, db, logger)
+
+	h := &identityHook{
+		alloc:      alloc,
+		task:       task,
+		tokenDir:   secretsDir,
+		envBuilder: taskenv.NewBuilder(node, alloc, task, alloc.Job.Region),
+		ts:         &MockTokenSetter{},
+		widmgr:     mockWIDMgr,
+		logger:     logger,
+		stopCtx:    stopCtx,
+		stop:       stop,
+	}
+
+	must.NoError(t, h.widmgr.Run())
+	must.NoError(t, h.Prestart(context.Background(), nil, nil))
+	must.NoError(t, h.Stop(context.Background(), nil, nil))
+
+	certPEM := testutil.MustReadFile(t, secretsDir, "nomad_mtls.crt")
+	keyPEM := testutil.MustReadFile(t, secretsDir, "nomad_mtls.key")
+	_, err := tls.X509KeyPair(certPEM, keyPEM)
+	must.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	must.True(t, roots.AppendCertsFromPEM(testutil.MustReadFile(t, secretsDir, "nomad_mtls_ca.crt")))
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	must.NoError(t, err)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	must.NoError(t, err)
+}
+
+func TestIdentityHook_SPIFFE(t *testing.T) {
+	ci.Parallel(t)
+
+	node := mock.Node()
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	task := alloc.LookupTask("web")
+	task.Identities = []*structs.WorkloadIdentity{
+		{
+			Name:     "spire",
+			Audience: []string{"spire"},
+			TTL:      time.Hour,
+			SPIFFE:   &structs.WorkloadIdentitySPIFFE{JWT: true, X509: true},
+		},
+	}
+
+	secretsDir := t.TempDir()
+
+	stopCtx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+	mockSigner := widmgr.NewMockWIDSigner(task.Identities)
+	mockWIDMgr := widmgr.NewWIDMgr(mockSigner, alloc, db, logger)
+
+	h := &identityHook{
+		alloc:      alloc,
+		task:       task,
+		tokenDir:   secretsDir,
+		envBuilder: taskenv.NewBuilder(node, alloc, task, alloc.Job.Region),
+		ts:         &MockTokenSetter{},
+		widmgr:     mockWIDMgr,
+		logger:     logger,
+		stopCtx:    stopCtx,
+		stop:       stop,
+	}
+
+	must.NoError(t, h.widmgr.Run())
+	must.NoError(t, h.Prestart(context.Background(), nil, nil))
+	must.NoError(t, h.Stop(context.Background(), nil, nil))
+
+	// The SVIDs are written to the spiffe dir only
+	spiffeDir := filepath.Join(secretsDir, structs.WISPIFFEDir)
+	swid, err := mockWIDMgr.Get(structs.WIHandle{WorkloadIdentifier: "web", IdentityName: "spire"})
+	must.NoError(t, err)
+	must.Eq(t, swid.JWT, string(testutil.MustReadFile(t, spiffeDir, structs.WISPIFFEJWTFile)))
+	must.FileNotExists(t, filepath.Join(secretsDir, "nomad_spire.crt"))
+	must.FileNotExists(t, filepath.Join(secretsDir, "nomad_spire.jwt"))
+
+	certPEM := testutil.MustReadFile(t, spiffeDir, structs.WISPIFFECertFile)
+	keyPEM := testutil.MustReadFile(t, spiffeDir, structs.WISPIFFEKeyFile)
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	must.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	must.True(t, roots.AppendCertsFromPEM(testutil.MustReadFile(t, spiffeDir, structs.WISPIFFEBundleFile)))
+}
+
 // TestIdentityHook_ErrorWriting assert Prestart returns an error if the
 // default token could not be written when requested.
 func TestIdentityHook_ErrorWriting(t *testing.T) {
@@ -272,3 +405,91 @@ func TestIdentityHook_ErrorWriting(t *testing.T) {
 	err := h.Prestart(context.Background(), nil, nil)
 	must.ErrorContains(t, err, "failed to write nomad token")
 }
+
+// TestIdentityHook_ChangeScript asserts the change script of an identity is
+// run in the task when the identity is renewed.
+func TestIdentityHook_ChangeScript(t *testing.T) {
+	ci.Parallel(t)
+
+	ttl := 2 * time.Second
+
+	node := mock.Node()
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	alloc.SignedIdentities = map[string]string{"web": "does.not.matter"}
+	task := alloc.LookupTask("web")
+	task.Identities = []*structs.WorkloadIdentity{
+		{
+			Name:       "vault",
+			Audience:   []string{"vault"},
+			File:       true,
+			TTL:        ttl,
+			ChangeMode: structs.WIChangeModeScript,
+			ChangeScript: &structs.ChangeScript{
+				Command:     "/bin/reauth",
+				Timeout:     time.Second,
+				FailOnError: true,
+			},
+		},
+	}
+
+	stopCtx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+	mockWIDMgr := widmgr.NewWIDMgr(widmgr.NewMockWIDSigner(task.Identities), alloc, db, logger)
+	mockWIDMgr.SetMinWait(time.Second)
+	mockLifecycle := trtesting.NewMockTaskHooks()
+	mockEvents := &trtesting.MockEmitter{}
+
+	h := &identityHook{
+		alloc:      alloc,
+		task:       task,
+		tokenDir:   t.TempDir(),
+		envBuilder: taskenv.NewBuilder(node, alloc, task, alloc.Job.Region),
+		ts:         &MockTokenSetter{},
+		lifecycle:  mockLifecycle,
+		events:     mockEvents,
+		widmgr:     mockWIDMgr,
+		logger:     logger,
+		stopCtx:    stopCtx,
+		stop:       stop,
+	}
+
+	// Poststart fails if the driver can't exec scripts
+	err := h.Poststart(context.Background(), &interfaces.TaskPoststartRequest{}, nil)
+	must.ErrorContains(t, err, "does not provide exec capability")
+
+	executor := &mockScriptExecutor{cmds: make(chan string, 10)}
+	must.NoError(t, h.Poststart(context.Background(),
+		&interfaces.TaskPoststartRequest{DriverExec: executor}, nil))
+
+	must.NoError(t, h.widmgr.Run())
+	must.NoError(t, h.Prestart(context.Background(), nil, nil))
+	t.Cleanup(func() { h.Stop(context.Background(), nil, nil) })
+
+	// the script isn't run for the initial identity, only once it's renewed
+	select {
+	case cmd := <-executor.cmds:
+		must.Eq(t, "/bin/reauth", cmd)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for script")
+	}
+	must.NoError(t, h.Stop(context.Background(), nil, nil))
+
+	events := mockEvents.Events()
+	must.SliceNotEmpty(t, events)
+	must.Eq(t, structs.TaskHookMessage, events[0].Type)
+	must.Nil(t, mockLifecycle.KillEvent())
+
+	// a failing script kills the task if it fails on error
+	failing := &mockScriptExecutor{exitCode: 1, cmds: make(chan string, 1)}
+	must.NoError(t, h.Poststart(context.Background(),
+		&interfaces.TaskPoststartRequest{DriverExec: failing}, nil))
+	h.runScript(task.Identities[0])
+	must.Eq(t, "/bin/reauth", <-failing.cmds)
+	events = mockEvents.Events()
+	must.Eq(t, structs.TaskHookFailed, events[len(events)-1].Type)
+	must.NotNil(t, mockLifecycle.KillEvent())
+}
@@ -0,0 +1,212 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	ti "github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper/users"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	identitySocketHookName = "identity_socket"
+
+	// identitySocketPath is the path of the identity endpoint, followed by
+	// the name of the identity.
+	identitySocketPath = "/v1/identity/"
+
+	// identitySocketStopWaitTime is how long to wait for the requests in
+	// flight when stopping the identity endpoint.
+	identitySocketStopWaitTime = 3 * time.Second
+)
+
+// identitySocketHook serves the workload identities of a task over a unix
+// socket in its secrets dir, so the task can fetch its latest identities at
+// runtime, similar to the metadata services of cloud providers.
+//
+// Each task has its own socket which only serves the identities of that task.
+// The socket is owned by the user of the task, like the identity files written
+// to the secrets dir by the identity hook, so it is exactly as reachable as
+// these files.
+//
+// As with the Task API, the hook soft-fails since tasks can still read their
+// identities from the secrets dir, but failing to create the socket is
+// reported as a task event so tasks relying on it can be debugged.
+type identitySocketHook struct {
+	alloc  *structs.Allocation
+	task   *structs.Task
+	widmgr widmgr.IdentityManager
+	events ti.EventEmitter
+	logger hclog.Logger
+
+	// lock synchronizes srv, which is set by Prestart and read by Stop.
+	lock sync.Mutex
+	srv  *http.Server
+}
+
+func newIdentitySocketHook(tr *TaskRunner, logger hclog.Logger) *identitySocketHook {
+	h := &identitySocketHook{
+		alloc:  tr.Alloc(),
+		task:   tr.Task(),
+		widmgr: tr.widmgr,
+		events: tr,
+	}
+	h.logger = logger.Named(h.Name())
+	return h
+}
+
+func (*identitySocketHook) Name() string {
+	return identitySocketHookName
+}
+
+func (h *identitySocketHook) Prestart(_ context.Context, req *interfaces.TaskPrestartRequest, _ *interfaces.TaskPrestartResponse) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.srv != nil || h.widmgr == nil || !h.hasIdentities() {
+		// Either there is nothing to serve, or the endpoint is already
+		// started because the task is restarting.
+		return nil
+	}
+
+	sockPath := taskIdentitySocketPath(req.TaskDir)
+	listener, err := users.SocketFileFor(h.logger, sockPath, req.Task.User)
+	if err != nil {
+		h.logger.Warn("failed to start identity endpoint", "path", sockPath, "error", err)
+		h.events.EmitEvent(structs.NewTaskEvent(structs.TaskHookFailed).
+			SetDisplayMessage(fmt.Sprintf("Identity socket: failed to create %s, identities are only available as files: %v", sockPath, err)))
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(identitySocketPath, h.handleIdentity)
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	h.srv = srv
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			h.logger.Error("identity endpoint stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+func (h *identitySocketHook) Stop(_ context.Context, req *interfaces.TaskStopRequest, _ *interfaces.TaskStopResponse) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.srv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), identitySocketStopWaitTime)
+		defer cancel()
+		if err := h.srv.Shutdown(ctx); err != nil {
+			// Only log a failure to stop, worst case is the server leaks a
+			// goroutine.
+			h.logger.Warn("error stopping identity endpoint", "error", err)
+		}
+		h.srv = nil
+	}
+
+	// Best-effort at cleaning things up. Alloc dir cleanup will remove it if
+	// this fails for any reason.
+	_ = os.RemoveAll(taskIdentitySocketPath(req.TaskDir))
+	return nil
+}
+
+// taskIdentitySocketPath returns the path to the identity socket of the task.
+//
+// As with the Task API socket, the path needs to be as short as possible
+// because of the limits on the length of unix socket paths (108 bytes on
+// Linux), which the secrets dir of deeply nested data dirs already nears.
+func taskIdentitySocketPath(taskDir *allocdir.TaskDir) string {
+	return filepath.Join(taskDir.SecretsDir, "id.sock")
+}
+
+// hasIdentities returns true if the task has a workload identity.
+func (h *identitySocketHook) hasIdentities() bool {
+	if _, ok := h.alloc.SignedIdentities[h.task.Name]; ok {
+		return true
+	}
+	return len(h.task.Identities) > 0
+}
+
+// hasIdentity returns true if the task has an identity with the given name.
+func (h *identitySocketHook) hasIdentity(name string) bool {
+	if name == structs.WorkloadIdentityDefaultName {
+		_, ok := h.alloc.SignedIdentities[h.task.Name]
+		return ok
+	}
+	for _, id := range h.task.Identities {
+		if id.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// identityResponse is the response of the identity endpoint.
+type identityResponse struct {
+	Name       string
+	Task       string
+	JWT        string
+	Expiration *time.Time `json:",omitempty"`
+}
+
+// handleIdentity serves GET /v1/identity/{name}, which returns the latest
+// signed identity of the task with the given name.
+func (h *identitySocketHook) handleIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, identitySocketPath)
+	if name == "" || strings.Contains(name, "/") || !h.hasIdentity(name) {
+		http.Error(w, fmt.Sprintf("identity %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	swid, err := h.widmgr.Get(structs.WIHandle{
+		IdentityName:       name,
+		WorkloadIdentifier: h.task.Name,
+		WorkloadType:       structs.WorkloadTypeTask,
+	})
+	if err != nil {
+		h.logger.Warn("failed to get identity", "identity", name, "error", err)
+		http.Error(w, "identity unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	resp := identityResponse{
+		Name: name,
+		Task: h.task.Name,
+		JWT:  swid.JWT,
+	}
+	if !swid.Expiration.IsZero() {
+		resp.Expiration = &swid.Expiration
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Debug("failed to write identity", "error", err)
+	}
+}
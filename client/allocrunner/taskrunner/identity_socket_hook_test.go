@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	trtesting "github.com/hashicorp/nomad/client/allocrunner/taskrunner/testing"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+var (
+	_ interfaces.TaskPrestartHook = (*identitySocketHook)(nil)
+	_ interfaces.TaskStopHook     = (*identitySocketHook)(nil)
+)
+
+func TestIdentitySocketHook(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	// two tasks with a default identity, and a named identity on the first
+	alloc := mock.Alloc()
+	tg := alloc.Job.TaskGroups[0]
+	web := tg.Tasks[0]
+	sidecar := web.Copy()
+	sidecar.Name = "sidecar"
+	tg.Tasks = append(tg.Tasks, sidecar)
+	web.Identities = []*structs.WorkloadIdentity{{Name: "vault"}}
+	alloc.SignedIdentities = map[string]string{web.Name: "web-default", sidecar.Name: "sidecar-default"}
+
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	mgr := widmgr.NewMockWIDMgr([]*structs.SignedWorkloadIdentity{
+		{
+			WorkloadIdentityRequest: structs.WorkloadIdentityRequest{WIHandle: *web.IdentityHandle(web.Identities[0])},
+			JWT:                     "web-vault",
+			Expiration:              expiration,
+		},
+		{
+			WorkloadIdentityRequest: structs.WorkloadIdentityRequest{WIHandle: structs.WIHandle{
+				IdentityName:       structs.WorkloadIdentityDefaultName,
+				WorkloadIdentifier: sidecar.Name,
+			}},
+			JWT: "sidecar-default",
+		},
+	})
+
+	// start starts the endpoint of the task and returns a function to get
+	// identities from it
+	start := func(task *structs.Task) (*identitySocketHook, *allocdir.TaskDir, func(string) (int, *identityResponse)) {
+		h := &identitySocketHook{alloc: alloc, task: task, widmgr: mgr, events: &trtesting.MockEmitter{}, logger: logger}
+		taskDir := &allocdir.TaskDir{SecretsDir: t.TempDir()}
+		must.NoError(t, h.Prestart(context.Background(),
+			&interfaces.TaskPrestartRequest{Task: task, TaskDir: taskDir},
+			&interfaces.TaskPrestartResponse{}))
+		t.Cleanup(func() { h.Stop(context.Background(), &interfaces.TaskStopRequest{TaskDir: taskDir}, nil) })
+
+		sockPath := taskIdentitySocketPath(taskDir)
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", sockPath)
+				},
+			},
+		}
+		get := func(path string) (int, *identityResponse) {
+			resp, err := client.Get("http://identity" + path)
+			must.NoError(t, err)
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return resp.StatusCode, nil
+			}
+			var out identityResponse
+			must.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+			return resp.StatusCode, &out
+		}
+		return h, taskDir, get
+	}
+
+	webHook, webDir, webGet := start(web)
+	_, _, sidecarGet := start(sidecar)
+
+	code, out := webGet("/v1/identity/vault")
+	must.Eq(t, http.StatusOK, code)
+	must.Eq(t, "vault", out.Name)
+	must.Eq(t, web.Name, out.Task)
+	must.Eq(t, "web-vault", out.JWT)
+	must.NotNil(t, out.Expiration)
+	must.True(t, expiration.Equal(*out.Expiration))
+
+	// the identity isn't signed yet
+	code, _ = webGet("/v1/identity/default")
+	must.Eq(t, http.StatusServiceUnavailable, code)
+
+	code, out = sidecarGet("/v1/identity/default")
+	must.Eq(t, http.StatusOK, code)
+	must.Eq(t, sidecar.Name, out.Task)
+	must.Eq(t, "sidecar-default", out.JWT)
+	must.Nil(t, out.Expiration)
+
+	// tasks can't get the identities of other tasks
+	code, _ = sidecarGet("/v1/identity/vault")
+	must.Eq(t, http.StatusNotFound, code)
+	code, out = sidecarGet("/v1/identity/default?task=" + web.Name)
+	must.Eq(t, http.StatusOK, code)
+	must.Eq(t, "sidecar-default", out.JWT)
+
+	code, _ = webGet("/v1/identity/consul")
+	must.Eq(t, http.StatusNotFound, code)
+
+	// the endpoint is stopped with the task
+	must.NoError(t, webHook.Stop(context.Background(), &interfaces.TaskStopRequest{TaskDir: webDir}, nil))
+	must.FileNotExists(t, taskIdentitySocketPath(webDir))
+}
+
+func TestIdentitySocketHook_NoIdentities(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	alloc.SignedIdentities = nil
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+
+	h := &identitySocketHook{alloc: alloc, task: task, widmgr: widmgr.NewMockWIDMgr(nil), events: &trtesting.MockEmitter{}, logger: testlog.HCLogger(t)}
+	taskDir := &allocdir.TaskDir{SecretsDir: t.TempDir()}
+	must.NoError(t, h.Prestart(context.Background(),
+		&interfaces.TaskPrestartRequest{Task: task, TaskDir: taskDir},
+		&interfaces.TaskPrestartResponse{}))
+	must.FileNotExists(t, taskIdentitySocketPath(taskDir))
+	must.NoError(t, h.Stop(context.Background(), &interfaces.TaskStopRequest{TaskDir: taskDir}, nil))
+}
+
+func TestIdentitySocketHook_ListenFailed(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	alloc.SignedIdentities = map[string]string{task.Name: "web-default"}
+
+	// a secrets dir too long for a unix socket path
+	secretsDir := filepath.Join(t.TempDir(), strings.Repeat("a", 100))
+	must.NoError(t, os.MkdirAll(secretsDir, 0o755))
+
+	events := &trtesting.MockEmitter{}
+	h := &identitySocketHook{alloc: alloc, task: task, widmgr: widmgr.NewMockWIDMgr(nil), events: events, logger: testlog.HCLogger(t)}
+	taskDir := &allocdir.TaskDir{SecretsDir: secretsDir}
+
+	// the hook soft-fails but reports the failure to the task
+	must.NoError(t, h.Prestart(context.Background(),
+		&interfaces.TaskPrestartRequest{Task: task, TaskDir: taskDir},
+		&interfaces.TaskPrestartResponse{}))
+	must.Nil(t, h.srv)
+	must.Len(t, 1, events.Events())
+	must.Eq(t, structs.TaskHookFailed, events.Events()[0].Type)
+	must.StrContains(t, events.Events()[0].DisplayMessage, "Identity socket: failed to create")
+}
@@ -5,7 +5,11 @@ package taskrunner
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/hashicorp/nomad/client/allocrunner/hookoverride"
+	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -171,3 +175,40 @@ func (tr *TaskRunner) Kill(ctx context.Context, event *structs.TaskEvent) error
 func (tr *TaskRunner) IsRunning() bool {
 	return tr.getDriverHandle() != nil
 }
+
+// DrainServices deregisters the services of the running task ahead of it
+// being killed, by running its pre-kill hooks, and records the service drain
+// in a task event. The hooks run again when the task is killed, which is a
+// no-op for deregistered services. Returns false if the task isn't running.
+func (tr *TaskRunner) DrainServices(drain time.Duration) bool {
+	if !tr.IsRunning() {
+		return false
+	}
+
+	tr.preKill()
+	tr.EmitEvent(structs.NewTaskEvent(structs.TaskDrainingServices).
+		SetDisplayMessage(fmt.Sprintf("Waiting for service drain of %s before killing the task.", drain)))
+	return true
+}
+
+// RunningHooks returns the prestart hooks of the task that are running.
+func (tr *TaskRunner) RunningHooks() []*cstructs.RunningHook {
+	hooks := tr.hookTracker.Running()
+	for _, hook := range hooks {
+		hook.Task = tr.taskName
+	}
+	return hooks
+}
+
+// OverrideHook skips or fails a running prestart hook of the task, and
+// records the override in a task event.
+func (tr *TaskRunner) OverrideHook(name, action string) error {
+	if err := tr.hookTracker.Override(name, action); err != nil {
+		return err
+	}
+
+	tr.logger.Info("hook overridden by operator", "name", name, "action", action)
+	tr.EmitEvent(structs.NewTaskEvent(structs.TaskHookOverridden).
+		SetMessage(hookoverride.EventMessage(name, action)))
+	return nil
+}
@@ -21,6 +21,7 @@ const (
 	ReasonUnrecoverableError = "Error was unrecoverable"
 	ReasonWithinPolicy       = "Restart within policy"
 	ReasonDelay              = "Exceeded allowed attempts, applying a delay"
+	ReasonDisconnected       = `Task failed while client was disconnected and disconnected mode is "fail"`
 )
 
 func NewRestartTracker(policy *structs.RestartPolicy, jobType string, tlc *structs.TaskLifecycleConfig) *RestartTracker {
@@ -60,6 +61,7 @@ type RestartTracker struct {
 	killed           bool      // Whether the task has been killed
 	restartTriggered bool      // Whether the task has been signalled to be restarted
 	failure          bool      // Whether a failure triggered the restart
+	disconnected     bool      // Whether the client is disconnected from the servers
 	count            int       // Current number of attempts.
 	onSuccess        bool      // Whether to restart on successful exit code.
 	startTime        time.Time // When the interval began
@@ -117,6 +119,24 @@ func (r *RestartTracker) SetRestartTriggered(failure bool) *RestartTracker {
 	return r
 }
 
+// IsRestartTriggered returns whether the task has been signalled to be
+// restarted without considering the restart policy, and the restart hasn't
+// been handled yet.
+func (r *RestartTracker) IsRestartTriggered() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.restartTriggered
+}
+
+// SetDisconnected is used to mark whether the client is disconnected from the
+// servers when the task failed.
+func (r *RestartTracker) SetDisconnected(disconnected bool) *RestartTracker {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.disconnected = disconnected
+	return r
+}
+
 // SetKilled is used to mark that the task has been killed.
 func (r *RestartTracker) SetKilled() *RestartTracker {
 	r.lock.Lock()
@@ -161,6 +181,7 @@ func (r *RestartTracker) GetState() (string, time.Duration) {
 		r.restartTriggered = false
 		r.failure = false
 		r.killed = false
+		r.disconnected = false
 	}()
 
 	// Hot path if task was killed
@@ -220,6 +241,13 @@ func (r *RestartTracker) GetState() (string, time.Duration) {
 		}
 	}
 
+	// Don't restart tasks locally while the client is disconnected if the
+	// policy requires them to fail instead.
+	if r.disconnected && r.policy.DisconnectedMode == structs.RestartPolicyDisconnectedModeFail {
+		r.reason = ReasonDisconnected
+		return structs.TaskNotRestarting, 0
+	}
+
 	// If this task has been restarted due to failures more times
 	// than the restart policy allows within an interval fail
 	// according to the restart policy's mode.
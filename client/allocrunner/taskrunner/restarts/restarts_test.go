@@ -82,6 +82,27 @@ func TestClient_RestartTracker_ModeFail(t *testing.T) {
 	}
 }
 
+func TestClient_RestartTracker_Disconnected(t *testing.T) {
+	ci.Parallel(t)
+	p := testPolicy(true, structs.RestartPolicyModeDelay)
+	rt := NewRestartTracker(p, structs.JobTypeService, nil)
+
+	// Tasks restart while disconnected by default.
+	state, _ := rt.SetExitResult(testExitResult(127)).SetDisconnected(true).GetState()
+	require.Equal(t, structs.TaskRestarting, state)
+
+	// Tasks fail while disconnected in "fail" mode.
+	p.DisconnectedMode = structs.RestartPolicyDisconnectedModeFail
+	rt.SetPolicy(p)
+	state, _ = rt.SetExitResult(testExitResult(127)).SetDisconnected(true).GetState()
+	require.Equal(t, structs.TaskNotRestarting, state)
+	require.Equal(t, ReasonDisconnected, rt.GetReason())
+
+	// Tasks restart again once connected.
+	state, _ = rt.SetExitResult(testExitResult(127)).GetState()
+	require.Equal(t, structs.TaskRestarting, state)
+}
+
 func TestClient_RestartTracker_NoRestartOnSuccess(t *testing.T) {
 	ci.Parallel(t)
 	p := testPolicy(false, structs.RestartPolicyModeDelay)
@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/users"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// serviceCertMinWait is the minimum time to wait before renewing a
+	// service certificate.
+	serviceCertMinWait = 10 * time.Second
+
+	// serviceCertRetryWait is the base time to wait before retrying to renew
+	// a service certificate after a failure.
+	serviceCertRetryWait = 10 * time.Second
+)
+
+// serviceCertHook writes the TLS certificates of the Nomad services of the
+// task and its group that set a certificate to the task's secrets directory,
+// and renews them before they expire. The files of a service are named
+// nomad_service_<name>.key, nomad_service_<name>.crt and
+// nomad_service_<name>_ca.crt.
+type serviceCertHook struct {
+	alloc      *structs.Allocation
+	task       *structs.Task
+	services   []*structs.Service
+	secretsDir string
+	widmgr     widmgr.IdentityManager
+	logger     log.Logger
+
+	// started is set once the renewal loops are running, since Prestart runs
+	// again when the task restarts.
+	started     bool
+	startedLock sync.Mutex
+
+	stopCtx context.Context
+	stop    context.CancelFunc
+}
+
+func newServiceCertHook(tr *TaskRunner, services []*structs.Service, logger log.Logger) *serviceCertHook {
+	stopCtx, stop := context.WithCancel(context.Background())
+	h := &serviceCertHook{
+		alloc:      tr.Alloc(),
+		task:       tr.Task(),
+		services:   services,
+		secretsDir: tr.taskDir.SecretsDir,
+		widmgr:     tr.widmgr,
+		stopCtx:    stopCtx,
+		stop:       stop,
+	}
+	h.logger = logger.Named(h.Name())
+	return h
+}
+
+// certificateServices returns the Nomad services of the task and its group
+// that set a certificate.
+func certificateServices(alloc *structs.Allocation, task *structs.Task) []*structs.Service {
+	var services []*structs.Service
+	add := func(list []*structs.Service) {
+		for _, service := range list {
+			if service.Provider == structs.ServiceProviderNomad && service.Certificate != nil {
+				services = append(services, service)
+			}
+		}
+	}
+
+	if tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup); tg != nil {
+		add(tg.Services)
+	}
+	add(task.Services)
+	return services
+}
+
+func (*serviceCertHook) Name() string {
+	return "service_cert"
+}
+
+func (h *serviceCertHook) Prestart(context.Context, *interfaces.TaskPrestartRequest, *interfaces.TaskPrestartResponse) error {
+	h.startedLock.Lock()
+	defer h.startedLock.Unlock()
+	if h.started {
+		return nil
+	}
+
+	expiries := make([]time.Time, len(h.services))
+	for i, service := range h.services {
+		expiry, err := h.setCertificate(service)
+		if err != nil {
+			return structs.NewRecoverableError(
+				fmt.Errorf("failed to set certificate of service %q: %w", service.Name, err), true)
+		}
+		expiries[i] = expiry
+	}
+
+	for i, service := range h.services {
+		go h.renew(service, expiries[i])
+	}
+	h.started = true
+	return nil
+}
+
+// renew renews the certificate of the service at about half of its life
+// until the hook is stopped.
+func (h *serviceCertHook) renew(service *structs.Service, expiry time.Time) {
+	timer, stop := helper.NewSafeTimer(helper.ExpiryToRenewTime(expiry, time.Now, serviceCertMinWait))
+	defer stop()
+
+	for {
+		select {
+		case <-h.stopCtx.Done():
+			return
+		case <-timer.C:
+		}
+
+		wait := serviceCertRetryWait + helper.RandomStagger(serviceCertRetryWait)
+		expiry, err := h.setCertificate(service)
+		if err != nil {
+			h.logger.Error("failed to renew service certificate", "service", service.Name, "error", err)
+		} else {
+			h.logger.Trace("renewed service certificate", "service", service.Name, "expiry", expiry)
+			wait = helper.ExpiryToRenewTime(expiry, time.Now, serviceCertMinWait)
+		}
+		timer.Reset(wait)
+	}
+}
+
+// setCertificate generates a new private key for the service, and writes it
+// to the task's secrets directory along with its certificate signed by the
+// servers and the certificates of the CA. It returns the expiry of the
+// certificate.
+func (h *serviceCertHook) setCertificate(service *structs.Service) (time.Time, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: service.Name},
+	}, key)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create certificate signing request: %w", err)
+	}
+
+	id := structs.WIHandle{WorkloadIdentifier: service.Name, WorkloadType: structs.WorkloadTypeService}
+	resp, err := h.widmgr.SignCertificate(id, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(resp.Certificate)
+	if block == nil {
+		return time.Time{}, errors.New("invalid certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{fmt.Sprintf("nomad_service_%s_ca.crt", service.Name), resp.CACertificate},
+		{fmt.Sprintf("nomad_service_%s.crt", service.Name), resp.Certificate},
+		{fmt.Sprintf("nomad_service_%s.key", service.Name), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})},
+	}
+	for _, file := range files {
+		if err := users.WriteFileFor(filepath.Join(h.secretsDir, file.name), file.content, h.task.User); err != nil {
+			return time.Time{}, fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+	return cert.NotAfter, nil
+}
+
+// Stop implements interfaces.TaskStopHook
+func (h *serviceCertHook) Stop(context.Context, *interfaces.TaskStopRequest, *interfaces.TaskStopResponse) error {
+	h.stop()
+	return nil
+}
+
+// Shutdown implements interfaces.ShutdownHook
+func (h *serviceCertHook) Shutdown() {
+	h.stop()
+}
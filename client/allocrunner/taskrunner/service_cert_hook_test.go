@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	cstate "github.com/hashicorp/nomad/client/state"
+	"github.com/hashicorp/nomad/client/widmgr"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
+)
+
+var _ interfaces.TaskPrestartHook = (*serviceCertHook)(nil)
+var _ interfaces.TaskStopHook = (*serviceCertHook)(nil)
+var _ interfaces.ShutdownHook = (*serviceCertHook)(nil)
+
+func TestServiceCertHook_certificateServices(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	tg.Services = []*structs.Service{
+		{Name: "group", Provider: structs.ServiceProviderNomad, Certificate: &structs.ServiceCertificate{}},
+		{Name: "group-no-cert", Provider: structs.ServiceProviderNomad},
+	}
+	task := alloc.LookupTask("web")
+	task.Services = []*structs.Service{
+		{Name: "consul", Provider: structs.ServiceProviderConsul, Certificate: &structs.ServiceCertificate{}},
+		{Name: "task", Provider: structs.ServiceProviderNomad, Certificate: &structs.ServiceCertificate{}},
+	}
+
+	services := certificateServices(alloc, task)
+	must.SliceLen(t, 2, services)
+	must.Eq(t, "group", services[0].Name)
+	must.Eq(t, "task", services[1].Name)
+}
+
+// TestServiceCertHook_Prestart asserts the certificates of the services are
+// written to the secrets dir.
+func TestServiceCertHook_Prestart(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.Alloc()
+	task := alloc.LookupTask("web")
+	service := &structs.Service{
+		Name:        "api",
+		Provider:    structs.ServiceProviderNomad,
+		Certificate: &structs.ServiceCertificate{},
+	}
+
+	secretsDir := t.TempDir()
+	stopCtx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+	mockSigner := widmgr.NewMockWIDSigner(task.Identities)
+
+	h := &serviceCertHook{
+		alloc:      alloc,
+		task:       task,
+		services:   []*structs.Service{service},
+		secretsDir: secretsDir,
+		widmgr:     widmgr.NewWIDMgr(mockSigner, alloc, db, logger),
+		logger:     logger,
+		stopCtx:    stopCtx,
+		stop:       stop,
+	}
+
+	must.NoError(t, h.Prestart(context.Background(), nil, nil))
+	must.True(t, h.started)
+
+	certPEM := testutil.MustReadFile(t, secretsDir, "nomad_service_api.crt")
+	keyPEM := testutil.MustReadFile(t, secretsDir, "nomad_service_api.key")
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	must.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	must.True(t, roots.AppendCertsFromPEM(testutil.MustReadFile(t, secretsDir, "nomad_service_api_ca.crt")))
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	must.NoError(t, err)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	must.NoError(t, err)
+
+	// Prestart doesn't renew the certificates again when the task restarts
+	must.NoError(t, h.Prestart(context.Background(), nil, nil))
+	must.Eq(t, certPEM, testutil.MustReadFile(t, secretsDir, "nomad_service_api.crt"))
+
+	must.NoError(t, h.Stop(context.Background(), nil, nil))
+	must.Error(t, h.stopCtx.Err())
+}
@@ -48,6 +48,10 @@ func (h *taskDirHook) Name() string {
 func (h *taskDirHook) Prestart(ctx context.Context, req *interfaces.TaskPrestartRequest, resp *interfaces.TaskPrestartResponse) error {
 	fsi := h.runner.driverCapabilities.FSIsolation
 	if v, ok := req.PreviousState[TaskDirHookIsDoneDataKey]; ok && v == "true" {
+		// The key of an encrypted secrets dir is lost when the host reboots
+		if err := h.runner.taskDir.UnlockSecretsDir(); err != nil {
+			return err
+		}
 		setEnvvars(h.runner.envBuilder, fsi, h.runner.taskDir, h.runner.clientConfig)
 		resp.State = map[string]string{
 			TaskDirHookIsDoneDataKey: "true",
@@ -17,6 +17,7 @@ import (
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/hookoverride"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/restarts"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
@@ -175,6 +176,10 @@ type TaskRunner struct {
 	// transistions.
 	runnerHooks []interfaces.TaskHook
 
+	// hookTracker tracks the running prestart hooks so operators can
+	// override them.
+	hookTracker hookoverride.Tracker
+
 	// hookResources captures the resources provided by hooks
 	hookResources *hookResources
 
@@ -221,6 +226,10 @@ type TaskRunner struct {
 	resourceUsage     *cstructs.TaskResourceUsage
 	resourceUsageLock sync.Mutex
 
+	// usage is the resource usage of the current run of the task, attached
+	// to its exit event when it fails. Guarded by resourceUsageLock.
+	usage usageSnapshot
+
 	// deviceStatsReporter is used to lookup resource usage for alloc devices
 	deviceStatsReporter cinterfaces.DeviceStatsReporter
 
@@ -270,6 +279,10 @@ type TaskRunner struct {
 
 	// widmgr manages workload identities
 	widmgr widmgr.IdentityManager
+
+	// clientDisconnectedFunc returns true while the client is disconnected
+	// from the servers.
+	clientDisconnectedFunc func() bool
 }
 
 type Config struct {
@@ -345,6 +358,10 @@ type Config struct {
 
 	// WIDMgr manages workload identities
 	WIDMgr widmgr.IdentityManager
+
+	// ClientDisconnectedFunc returns true while the client is disconnected
+	// from the servers.
+	ClientDisconnectedFunc func() bool
 }
 
 func NewTaskRunner(config *Config) (*TaskRunner, error) {
@@ -407,6 +424,7 @@ func NewTaskRunner(config *Config) (*TaskRunner, error) {
 		getter:                  config.Getter,
 		wranglers:               config.Wranglers,
 		widmgr:                  config.WIDMgr,
+		clientDisconnectedFunc:  config.ClientDisconnectedFunc,
 	}
 
 	// Create the logger based on the allocation ID
@@ -448,11 +466,18 @@ func NewTaskRunner(config *Config) (*TaskRunner, error) {
 
 	// Initialize the runners hooks. Must come after initDriver so hooks
 	// can use tr.driverCapabilities
-	tr.initHooks()
+	if err := tr.initHooks(); err != nil {
+		tr.logger.Error("failed to initialize task hooks", "error", err)
+		return nil, err
+	}
 
 	// Initialize base labels
 	tr.initLabels()
 
+	// Time out and instrument the prestart hooks
+	tr.hookTracker.Timeout = tr.clientConfig.HookTimeoutFor
+	tr.hookTracker.Labels = tr.baseLabels
+
 	// Initialize initial task received event
 	tr.appendEvent(structs.NewTaskEvent(structs.TaskReceived))
 
@@ -637,6 +662,7 @@ MAIN:
 			// is called.
 			if resultCh, err := handle.WaitCh(context.Background()); err != nil {
 				tr.logger.Error("wait task failed", "error", err)
+				tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorWait, err))
 			} else {
 				select {
 				case <-tr.killCtx.Done():
@@ -774,6 +800,7 @@ func (tr *TaskRunner) handleTaskExitResult(result *drivers.ExitResult) (retryWai
 		// Initialize a new driver handle
 		if err := tr.initDriver(); err != nil {
 			tr.logger.Error("failed to initialize driver after it exited unexpectedly", "error", err, "driver", dn)
+			tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorPluginExited, err))
 			return false
 		}
 
@@ -784,6 +811,7 @@ func (tr *TaskRunner) handleTaskExitResult(result *drivers.ExitResult) (retryWai
 		tr.stateLock.RUnlock()
 		if !tr.restoreHandle(h, net) {
 			tr.logger.Error("failed to restore handle on driver after it exited unexpectedly", "driver", dn)
+			tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorPluginExited, result.Err))
 			return false
 		}
 
@@ -797,14 +825,27 @@ func (tr *TaskRunner) handleTaskExitResult(result *drivers.ExitResult) (retryWai
 	return false
 }
 
-// emitExitResultEvent emits a TaskTerminated event for an ExitResult.
+// emitExitResultEvent emits a TaskTerminated event for an ExitResult, and
+// records the result of the task run.
 func (tr *TaskRunner) emitExitResultEvent(result *drivers.ExitResult) {
+	killed := tr.killCtx.Err() != nil || tr.restartTracker.IsRestartTriggered()
+	tr.SetResult(structs.NewTaskExitResult(result.ExitCode, result.Signal, result.OOMKilled, killed))
+
 	event := structs.NewTaskEvent(structs.TaskTerminated).
 		SetExitCode(result.ExitCode).
 		SetSignal(result.Signal).
 		SetOOMKilled(result.OOMKilled).
 		SetExitMessage(result.Err)
 
+	// Attach the resource usage of the run for post-mortems of failures, and
+	// reset it for the next run
+	tr.resourceUsageLock.Lock()
+	if result.OOMKilled || result.ExitCode != 0 {
+		tr.usage.setOn(event)
+	}
+	tr.usage = usageSnapshot{}
+	tr.resourceUsageLock.Unlock()
+
 	tr.EmitEvent(event)
 
 	if result.OOMKilled {
@@ -830,6 +871,10 @@ func (tr *TaskRunner) handleUpdates() {
 // shouldRestart determines whether the task should be restarted and updates
 // the task state unless the task is killed or terminated.
 func (tr *TaskRunner) shouldRestart() (bool, time.Duration) {
+	if tr.clientDisconnectedFunc != nil {
+		tr.restartTracker.SetDisconnected(tr.clientDisconnectedFunc())
+	}
+
 	// Determine if we should restart
 	state, when := tr.restartTracker.GetState()
 	reason := tr.restartTracker.GetReason()
@@ -870,7 +915,9 @@ func (tr *TaskRunner) runDriver() error {
 	// Build hcl context variables
 	vars, errs, err := tr.envBuilder.Build().AllValues()
 	if err != nil {
-		return fmt.Errorf("error building environment variables: %v", err)
+		envErr := fmt.Errorf("error building environment variables: %v", err)
+		tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorConfig, envErr))
+		return envErr
 	}
 
 	// Handle per-key errors
@@ -891,12 +938,14 @@ func (tr *TaskRunner) runDriver() error {
 	val, diag, diagErrs := hclutils.ParseHclInterface(tr.task.Config, tr.taskSchema, vars)
 	if diag.HasErrors() {
 		parseErr := multierror.Append(errors.New("failed to parse config: "), diagErrs...)
+		tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorConfig, parseErr))
 		tr.EmitEvent(structs.NewTaskEvent(structs.TaskFailedValidation).SetValidationError(parseErr))
 		return parseErr
 	}
 
 	if err := taskConfig.EncodeDriverConfig(val); err != nil {
 		encodeErr := fmt.Errorf("failed to encode driver config: %v", err)
+		tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorConfig, encodeErr))
 		tr.EmitEvent(structs.NewTaskEvent(structs.TaskFailedValidation).SetValidationError(encodeErr))
 		return encodeErr
 	}
@@ -922,6 +971,7 @@ func (tr *TaskRunner) runDriver() error {
 			tr.logger.Info("failed to start task because plugin shutdown unexpectedly; attempting to recover")
 			if err := tr.initDriver(); err != nil {
 				taskErr := fmt.Errorf("failed to initialize driver after it exited unexpectedly: %v", err)
+				tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorPluginExited, taskErr))
 				tr.EmitEvent(structs.NewTaskEvent(structs.TaskDriverFailure).SetDriverError(taskErr))
 				return taskErr
 			}
@@ -929,12 +979,14 @@ func (tr *TaskRunner) runDriver() error {
 			handle, net, err = tr.driver.StartTask(taskConfig)
 			if err != nil {
 				taskErr := fmt.Errorf("failed to start task after driver exited unexpectedly: %v", err)
+				tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorStart, taskErr))
 				tr.EmitEvent(structs.NewTaskEvent(structs.TaskDriverFailure).SetDriverError(taskErr))
 				return taskErr
 			}
 		} else {
 			// Do *NOT* wrap the error here without maintaining whether or not is Recoverable.
 			// You must emit a task event failure to be considered Recoverable
+			tr.SetResult(structs.NewTaskDriverErrorResult(structs.TaskDriverErrorStart, err))
 			tr.EmitEvent(structs.NewTaskEvent(structs.TaskDriverFailure).SetDriverError(err))
 			return err
 		}
@@ -1266,6 +1318,14 @@ func (tr *TaskRunner) restoreHandle(taskHandle *drivers.TaskHandle, net *drivers
 	return true
 }
 
+// SetResult records the result of the last run of the task. The result is
+// persisted and sent to the servers along with the next task event.
+func (tr *TaskRunner) SetResult(result *structs.TaskResult) {
+	tr.stateLock.Lock()
+	defer tr.stateLock.Unlock()
+	tr.state.Result = result
+}
+
 // UpdateState sets the task runners allocation state and triggers a server
 // update.
 func (tr *TaskRunner) UpdateState(state string, event *structs.TaskEvent) {
@@ -1436,6 +1496,12 @@ func (tr *TaskRunner) SetNetworkIsolation(n *drivers.NetworkIsolationSpec) {
 	tr.networkIsolationLock.Unlock()
 }
 
+// SetNetworkStatus is called by the alloc runner with the status of the
+// network of the allocation, to expose it to the task environment.
+func (tr *TaskRunner) SetNetworkStatus(s *structs.AllocNetworkStatus) {
+	tr.envBuilder.SetNetworkStatus(s)
+}
+
 // triggerUpdate if there isn't already an update pending. Should be called
 // instead of calling updateHooks directly to serialize runs of update hooks.
 // TaskRunner state should be updated prior to triggering update hooks.
@@ -1484,6 +1550,7 @@ func (tr *TaskRunner) LatestResourceUsage() *cstructs.TaskResourceUsage {
 func (tr *TaskRunner) UpdateStats(ru *cstructs.TaskResourceUsage) {
 	tr.resourceUsageLock.Lock()
 	tr.resourceUsage = ru
+	tr.usage.update(ru)
 	tr.resourceUsageLock.Unlock()
 	if ru != nil {
 		tr.emitStats(ru)
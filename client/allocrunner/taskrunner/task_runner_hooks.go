@@ -5,12 +5,14 @@ package taskrunner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/LK4D4/joincontext"
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/client/allocrunner/hookoverride"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -49,7 +51,7 @@ func (h *hookResources) getMounts() []*drivers.MountConfig {
 }
 
 // initHooks initializes the tasks hooks.
-func (tr *TaskRunner) initHooks() {
+func (tr *TaskRunner) initHooks() error {
 	hookLogger := tr.logger.Named("task_hook")
 	task := tr.Task()
 
@@ -58,26 +60,32 @@ func (tr *TaskRunner) initHooks() {
 	// Add the hook resources
 	tr.hookResources = &hookResources{}
 
-	// Create the task directory hook. This is run first to ensure the
-	// directory path exists for other hooks.
+	// The hooks run in ascending order of priority. The task directory hook
+	// runs early to ensure the directory path exists for other hooks.
 	alloc := tr.Alloc()
-	tr.runnerHooks = []interfaces.TaskHook{
-		newValidateHook(tr.clientConfig, hookLogger),
-		newTaskDirHook(tr, hookLogger),
-		newIdentityHook(tr, hookLogger),
-		newLogMonHook(tr, hookLogger),
-		newDispatchHook(alloc, hookLogger),
-		newVolumeHook(tr, hookLogger),
-		newArtifactHook(tr, tr.getter, hookLogger),
-		newStatsHook(tr, tr.clientConfig.StatsCollectionInterval, hookLogger),
-		newDeviceHook(tr.devicemanager, hookLogger),
-		newAPIHook(tr.shutdownCtx, tr.clientConfig.APIListenerRegistrar, hookLogger),
-		newWranglerHook(tr.wranglers, task.Name, alloc.ID, task.UsesCores(), hookLogger),
+	var hooks interfaces.HookList[interfaces.TaskHook]
+	hooks.Add(interfaces.TaskHookPriorityValidate, newValidateHook(tr.clientConfig, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityTaskDir, newTaskDirHook(tr, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityIdentity, newIdentityHook(tr, hookLogger))
+	if services := certificateServices(alloc, task); len(services) > 0 {
+		hooks.Add(interfaces.TaskHookPriorityServiceCerts, newServiceCertHook(tr, services, hookLogger))
 	}
+	if len(cloudIdentities(task)) > 0 {
+		hooks.Add(interfaces.TaskHookPriorityCloudCreds, newCloudCredentialsHook(tr, hookLogger))
+	}
+	hooks.Add(interfaces.TaskHookPriorityLogMon, newLogMonHook(tr, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityDispatch, newDispatchHook(alloc, tr.allocHookResources, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityVolumes, newVolumeHook(tr, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityArtifacts, newArtifactHook(tr, tr.getter, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityStats, newStatsHook(tr, tr.clientConfig.StatsCollectionInterval, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityDevices, newDeviceHook(tr.devicemanager, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityAPI, newAPIHook(tr.shutdownCtx, tr.clientConfig.APIListenerRegistrar, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityIdentitySocket, newIdentitySocketHook(tr, hookLogger))
+	hooks.Add(interfaces.TaskHookPriorityWrangler, newWranglerHook(tr.wranglers, task.Name, alloc.ID, task.UsesCores(), hookLogger))
 
 	// If the task has a CSI block, add the hook.
 	if task.CSIPluginConfig != nil {
-		tr.runnerHooks = append(tr.runnerHooks, newCSIPluginSupervisorHook(
+		hooks.Add(interfaces.TaskHookPriorityCSIPlugin, newCSIPluginSupervisorHook(
 			&csiPluginSupervisorHookConfig{
 				clientStateDirPath: tr.clientConfig.StateDir,
 				events:             tr,
@@ -90,7 +98,7 @@ func (tr *TaskRunner) initHooks() {
 
 	// If Vault is enabled, add the hook
 	if task.Vault != nil && tr.vaultClientFunc != nil {
-		tr.runnerHooks = append(tr.runnerHooks, newVaultHook(&vaultHookConfig{
+		hooks.Add(interfaces.TaskHookPriorityVault, newVaultHook(&vaultHookConfig{
 			vaultBlock:       task.Vault,
 			vaultConfigsFunc: tr.clientConfig.GetVaultConfigs,
 			clientFunc:       tr.vaultClientFunc,
@@ -113,7 +121,7 @@ func (tr *TaskRunner) initHooks() {
 
 	// If there are templates is enabled, add the hook
 	if len(task.Templates) != 0 {
-		tr.runnerHooks = append(tr.runnerHooks, newTemplateHook(&templateHookConfig{
+		hooks.Add(interfaces.TaskHookPriorityTemplates, newTemplateHook(&templateHookConfig{
 			alloc:               tr.Alloc(),
 			logger:              hookLogger,
 			lifecycle:           tr,
@@ -129,7 +137,7 @@ func (tr *TaskRunner) initHooks() {
 
 	// Always add the service hook. A task with no services on initial registration
 	// may be updated to include services, which must be handled with this hook.
-	tr.runnerHooks = append(tr.runnerHooks, newServiceHook(serviceHookConfig{
+	hooks.Add(interfaces.TaskHookPriorityServices, newServiceHook(serviceHookConfig{
 		alloc:             tr.Alloc(),
 		task:              tr.Task(),
 		providerNamespace: serviceProviderNamespace,
@@ -145,7 +153,7 @@ func (tr *TaskRunner) initHooks() {
 		// Enable the Service Identity hook only if the Nomad client is configured
 		// with a consul token, indicating that Consul ACLs are enabled
 		if tr.clientConfig.ConsulConfig.Token != "" {
-			tr.runnerHooks = append(tr.runnerHooks, newSIDSHook(sidsHookConfig{
+			hooks.Add(interfaces.TaskHookPrioritySIDS, newSIDSHook(sidsHookConfig{
 				alloc:              tr.Alloc(),
 				task:               tr.Task(),
 				sidsClient:         tr.siClient,
@@ -156,12 +164,12 @@ func (tr *TaskRunner) initHooks() {
 		}
 
 		if task.UsesConnectSidecar() {
-			tr.runnerHooks = append(tr.runnerHooks,
-				newEnvoyVersionHook(newEnvoyVersionHookConfig(alloc, tr.consulProxiesClientFunc, hookLogger)),
-				newEnvoyBootstrapHook(newEnvoyBootstrapHookConfig(alloc, tr.clientConfig.ConsulConfig, consulNamespace, hookLogger)),
-			)
+			hooks.Add(interfaces.TaskHookPriorityEnvoyVersion,
+				newEnvoyVersionHook(newEnvoyVersionHookConfig(alloc, tr.consulProxiesClientFunc, hookLogger)))
+			hooks.Add(interfaces.TaskHookPriorityEnvoyBootstrap,
+				newEnvoyBootstrapHook(newEnvoyBootstrapHookConfig(alloc, tr.clientConfig.ConsulConfig, consulNamespace, hookLogger)))
 		} else if task.Kind.IsConnectNative() {
-			tr.runnerHooks = append(tr.runnerHooks, newConnectNativeHook(
+			hooks.Add(interfaces.TaskHookPriorityConnectNative, newConnectNativeHook(
 				newConnectNativeHookConfig(alloc, tr.clientConfig.ConsulConfig, hookLogger),
 			))
 		}
@@ -170,7 +178,7 @@ func (tr *TaskRunner) initHooks() {
 	// Always add the script checks hook. A task with no script check hook on
 	// initial registration may be updated to include script checks, which must
 	// be handled with this hook.
-	tr.runnerHooks = append(tr.runnerHooks, newScriptCheckHook(scriptCheckHookConfig{
+	hooks.Add(interfaces.TaskHookPriorityScriptChecks, newScriptCheckHook(scriptCheckHookConfig{
 		alloc:  tr.Alloc(),
 		task:   tr.Task(),
 		consul: tr.consulServiceClient,
@@ -180,8 +188,23 @@ func (tr *TaskRunner) initHooks() {
 	// If this task driver has remote capabilities, add the remote task
 	// hook.
 	if tr.driverCapabilities.RemoteTasks {
-		tr.runnerHooks = append(tr.runnerHooks, newRemoteTaskHook(tr, hookLogger))
+		hooks.Add(interfaces.TaskHookPriorityRemoteTask, newRemoteTaskHook(tr, hookLogger))
+	}
+
+	// Extra hooks run after the built-in hooks, unless they set their own
+	// priority.
+	for _, factory := range tr.clientConfig.ExtraTaskHooks {
+		if hook := factory(alloc, task); hook != nil {
+			hooks.Add(interfaces.TaskHookPriorityDefault, hook)
+		}
 	}
+
+	sorted, err := hooks.Sorted()
+	if err != nil {
+		return err
+	}
+	tr.runnerHooks = sorted
+	return nil
 }
 
 func (tr *TaskRunner) emitHookError(err error, hookName string) {
@@ -221,10 +244,12 @@ func (tr *TaskRunner) prestart() error {
 
 	alloc := tr.Alloc()
 
-	for _, hook := range tr.runnerHooks {
+	// Hooks declaring their dependencies run as soon as the hooks they
+	// require are done, concurrently with the other hooks.
+	return interfaces.RunHooks(tr.runnerHooks, func(hook interfaces.TaskHook) error {
 		pre, ok := hook.(interfaces.TaskPrestartHook)
 		if !ok {
-			continue
+			return nil
 		}
 
 		name := pre.Name()
@@ -250,7 +275,7 @@ func (tr *TaskRunner) prestart() error {
 					tr.envBuilder.SetHookEnv(name, origHookState.Env)
 				}
 
-				continue
+				return nil
 			}
 
 			// Give the hook it's old data
@@ -261,18 +286,37 @@ func (tr *TaskRunner) prestart() error {
 		req.NomadToken = tr.getNomadToken()
 
 		// Time the prestart hook
-		var start time.Time
+		start := time.Now()
 		if tr.logger.IsTrace() {
-			start = time.Now()
 			tr.logger.Trace("running prestart hook", "name", name, "start", start)
 		}
 
 		// Run the prestart hook
 		var resp interfaces.TaskPrestartResponse
-		if err := pre.Prestart(joinedCtx, &req, &resp); err != nil {
-			tr.emitHookError(err, name)
+		skipped, err := tr.hookTracker.Run(joinedCtx, name, structs.TaskHookPhasePrestart,
+			func(ctx context.Context) error { return pre.Prestart(ctx, &req, &resp) })
+		tr.setHookTiming(name, structs.TaskHookPhasePrestart, start, time.Now())
+		if err != nil {
+			// Hooks failed by an operator are retried according to the
+			// restart policy of the task.
+			if errors.Is(err, hookoverride.ErrFailedByOperator) {
+				err = structs.NewRecoverableError(err, true)
+			}
+			tr.SetResult(structs.NewTaskHookFailureResult(name, structs.TaskHookPhasePrestart, err))
+			var terr *hookoverride.TimeoutError
+			if errors.As(err, &terr) {
+				tr.EmitEvent(hookoverride.TimeoutEvent(terr))
+			} else {
+				tr.emitHookError(err, name)
+			}
 			return structs.WrapRecoverable(fmt.Sprintf("prestart hook %q failed: %v", name, err), err)
 		}
+		if skipped {
+			// The response may still be written by the hook, so it's
+			// ignored.
+			tr.logger.Warn("prestart hook skipped by operator", "name", name)
+			return nil
+		}
 
 		// Store the hook state
 		{
@@ -313,9 +357,8 @@ func (tr *TaskRunner) prestart() error {
 			end := time.Now()
 			tr.logger.Trace("finished prestart hook", "name", name, "end", end, "duration", end.Sub(start))
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // poststart is used to run the runners poststart hooks.
@@ -344,9 +387,8 @@ func (tr *TaskRunner) poststart() error {
 		}
 
 		name := post.Name()
-		var start time.Time
+		start := time.Now()
 		if tr.logger.IsTrace() {
-			start = time.Now()
 			tr.logger.Trace("running poststart hook", "name", name, "start", start)
 		}
 
@@ -357,7 +399,9 @@ func (tr *TaskRunner) poststart() error {
 			TaskEnv:       tr.envBuilder.Build(),
 		}
 		var resp interfaces.TaskPoststartResponse
-		if err := post.Poststart(tr.killCtx, &req, &resp); err != nil {
+		err := post.Poststart(tr.killCtx, &req, &resp)
+		tr.setHookTiming(name, structs.TaskHookPhasePoststart, start, time.Now())
+		if err != nil {
 			tr.emitHookError(err, name)
 			merr.Errors = append(merr.Errors, fmt.Errorf("poststart hook %q failed: %v", name, err))
 		}
@@ -370,9 +414,26 @@ func (tr *TaskRunner) poststart() error {
 		}
 	}
 
+	// Hook timings are only sent to the servers along with task state
+	// updates, so trigger one now that all the poststart hooks ran.
+	tr.stateUpdater.TaskStateUpdated()
+
 	return merr.ErrorOrNil()
 }
 
+// setHookTiming records the time a hook took to run in the task state so it
+// can be reported in the allocation timeline.
+func (tr *TaskRunner) setHookTiming(name, phase string, start, end time.Time) {
+	tr.stateLock.Lock()
+	defer tr.stateLock.Unlock()
+	tr.state.SetHookTiming(&structs.TaskHookTiming{
+		Name:       name,
+		Phase:      phase,
+		StartedAt:  start,
+		FinishedAt: end,
+	})
+}
+
 // exited is used to run the exited hooks before a task is stopped.
 func (tr *TaskRunner) exited() error {
 	if tr.logger.IsTrace() {
@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/golang/snappy"
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/allocrunner/hookoverride"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/getter"
 	"github.com/hashicorp/nomad/client/config"
@@ -46,6 +48,7 @@ import (
 	"github.com/kr/pretty"
 	"github.com/shoenig/test"
 	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -900,6 +903,201 @@ func (h *mockEnvHook) Prestart(ctx context.Context, req *interfaces.TaskPrestart
 	return nil
 }
 
+// mockPriorityHook is a test hook ordered relative to the built-in hooks.
+type mockPriorityHook struct {
+	mockEnvHook
+	priority int
+}
+
+func (*mockPriorityHook) Name() string {
+	return "mock_priority_hook"
+}
+
+func (h *mockPriorityHook) Priority() int {
+	return h.priority
+}
+
+// TestTaskRunner_ExtraHooks_Priority asserts that extra hooks are built for
+// each task, and run after the built-in hooks unless they set their own
+// priority.
+func TestTaskRunner_ExtraHooks_Priority(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	conf, cleanup := testTaskRunnerConfig(t, alloc, task.Name, nil)
+	defer cleanup()
+	conf.ClientConfig.ExtraTaskHooks = []interfaces.TaskHookFactory{
+		func(*structs.Allocation, *structs.Task) interfaces.TaskHook {
+			return &mockEnvHook{}
+		},
+		func(*structs.Allocation, *structs.Task) interfaces.TaskHook {
+			return &mockPriorityHook{priority: interfaces.TaskHookPriorityVolumes - 1}
+		},
+		func(*structs.Allocation, *structs.Task) interfaces.TaskHook {
+			return nil
+		},
+	}
+
+	tr, err := NewTaskRunner(conf)
+	must.NoError(t, err)
+
+	var names []string
+	for _, hook := range tr.runnerHooks {
+		names = append(names, hook.Name())
+	}
+	must.Eq(t, "mock_env_hook", names[len(names)-1])
+	must.Eq(t, slices.Index(names, "volumes")-1, slices.Index(names, "mock_priority_hook"))
+}
+
+// mockBlockingHook is a test hook whose prestart blocks until its context is
+// canceled.
+type mockBlockingHook struct{}
+
+func (*mockBlockingHook) Name() string {
+	return "mock_blocking_hook"
+}
+
+func (*mockBlockingHook) Prestart(ctx context.Context, _ *interfaces.TaskPrestartRequest, _ *interfaces.TaskPrestartResponse) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// TestTaskRunner_OverrideHook asserts that operators can skip or fail a stuck
+// prestart hook.
+func TestTaskRunner_OverrideHook(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	conf, cleanup := testTaskRunnerConfig(t, alloc, task.Name, nil)
+	defer cleanup()
+
+	tr, err := NewTaskRunner(conf)
+	must.NoError(t, err)
+	tr.runnerHooks = []interfaces.TaskHook{&mockBlockingHook{}}
+
+	runPrestart := func(action string) error {
+		errCh := make(chan error, 1)
+		go func() { errCh <- tr.prestart() }()
+
+		must.Wait(t, wait.InitialSuccess(
+			wait.BoolFunc(func() bool { return len(tr.RunningHooks()) == 1 }),
+			wait.Timeout(5*time.Second),
+			wait.Gap(10*time.Millisecond),
+		))
+		hooks := tr.RunningHooks()
+		must.Eq(t, task.Name, hooks[0].Task)
+		must.Eq(t, "mock_blocking_hook", hooks[0].Name)
+		must.Eq(t, structs.TaskHookPhasePrestart, hooks[0].Phase)
+
+		must.NoError(t, tr.OverrideHook("mock_blocking_hook", action))
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for prestart")
+			return nil
+		}
+	}
+
+	// Skipped hooks let the prestart continue.
+	must.NoError(t, runPrestart(hookoverride.ActionSkip))
+	must.SliceEmpty(t, tr.RunningHooks())
+
+	// Failed hooks fail the prestart with a recoverable error, so they are
+	// retried according to the restart policy.
+	err = runPrestart(hookoverride.ActionFail)
+	must.ErrorContains(t, err, hookoverride.ErrFailedByOperator.Error())
+	must.True(t, structs.IsRecoverable(err))
+
+	result := tr.TaskState().Result
+	must.NotNil(t, result)
+	must.Eq(t, structs.TaskResultHookFailure, result.Class)
+	must.Eq(t, "mock_blocking_hook", result.HookName)
+	must.Eq(t, structs.TaskHookPhasePrestart, result.HookPhase)
+
+	var overrides []string
+	for _, ev := range tr.TaskState().Events {
+		if ev.Type == structs.TaskHookOverridden {
+			overrides = append(overrides, ev.Message)
+		}
+	}
+	must.Eq(t, []string{
+		`Hook "mock_blocking_hook" skipped by operator`,
+		`Hook "mock_blocking_hook" failed by operator`,
+	}, overrides)
+
+	// Hooks that aren't running can't be overridden.
+	must.ErrorContains(t, tr.OverrideHook("mock_blocking_hook", hookoverride.ActionSkip), "is not running")
+}
+
+// TestTaskRunner_HookTimeout asserts that prestart hooks running past the
+// hook timeout of the client fail the task.
+func TestTaskRunner_HookTimeout(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	conf, cleanup := testTaskRunnerConfig(t, alloc, task.Name, nil)
+	defer cleanup()
+	conf.ClientConfig.HookTimeouts = map[string]time.Duration{
+		"mock_blocking_hook": 50 * time.Millisecond,
+	}
+
+	tr, err := NewTaskRunner(conf)
+	must.NoError(t, err)
+	tr.runnerHooks = []interfaces.TaskHook{&mockBlockingHook{}}
+
+	err = tr.prestart()
+	must.ErrorContains(t, err, `hook "mock_blocking_hook" timed out after 50ms`)
+	must.False(t, structs.IsRecoverable(err))
+	must.SliceEmpty(t, tr.RunningHooks())
+
+	result := tr.TaskState().Result
+	must.NotNil(t, result)
+	must.Eq(t, structs.TaskResultHookFailure, result.Class)
+	must.Eq(t, "mock_blocking_hook", result.HookName)
+
+	events := tr.TaskState().Events
+	last := events[len(events)-1]
+	must.Eq(t, structs.TaskHookTimedOut, last.Type)
+	must.Eq(t, "mock_blocking_hook", last.Details["hook_name"])
+	must.Eq(t, structs.TaskHookPhasePrestart, last.Details["hook_phase"])
+	must.Eq(t, "50ms", last.Details["hook_timeout"])
+}
+
+// TestTaskRunner_Result asserts the result of the last run of a task is
+// recorded on its state.
+func TestTaskRunner_Result(t *testing.T) {
+	ci.Parallel(t)
+
+	alloc := mock.BatchAlloc()
+	alloc.Job.TaskGroups[0].RestartPolicy.Attempts = 0
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Config = map[string]interface{}{
+		"exit_code":   3,
+		"exit_signal": 9,
+		"run_for":     "1ns",
+	}
+	conf, cleanup := testTaskRunnerConfig(t, alloc, task.Name, nil)
+	defer cleanup()
+
+	tr, err := NewTaskRunner(conf)
+	must.NoError(t, err)
+	defer tr.Kill(context.Background(), structs.NewTaskEvent("cleanup"))
+	go tr.Run()
+
+	testWaitForTaskToDie(t, tr)
+
+	result := tr.TaskState().Result
+	must.NotNil(t, result)
+	must.Eq(t, structs.TaskResultSignaled, result.Class)
+	must.Eq(t, 3, result.ExitCode)
+	must.Eq(t, 9, result.Signal)
+	must.False(t, result.OOMKilled)
+}
+
 // TestTaskRunner_Restore_HookEnv asserts that re-running prestart hooks with
 // hook environments set restores the environment without re-running done
 // hooks.
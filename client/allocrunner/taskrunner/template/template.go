@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,6 +18,7 @@ import (
 	"time"
 
 	ctconf "github.com/hashicorp/consul-template/config"
+	dep "github.com/hashicorp/consul-template/dependency"
 	"github.com/hashicorp/consul-template/manager"
 	"github.com/hashicorp/consul-template/signals"
 	envparse "github.com/hashicorp/go-envparse"
@@ -45,6 +48,18 @@ const (
 var (
 	sourceEscapesErr = errors.New("template source path escapes alloc directory")
 	destEscapesErr   = errors.New("template destination path escapes alloc directory")
+
+	// vaultSecretMissingRe matches the error returned by consul-template when
+	// a Vault secret doesn't exist. For KV v2 secrets this is also the error
+	// returned when the secret, or the version pinned with the "version"
+	// query parameter, has been deleted or destroyed.
+	vaultSecretMissingRe = regexp.MustCompile(`no secret exists at (\S+)`)
+
+	// templateFuncs are the functions Nomad adds to the ones of
+	// consul-template.
+	templateFuncs = map[string]any{
+		"requireSecret": requireSecret,
+	}
 )
 
 // TaskTemplateManager is used to run a set of templates for a given task
@@ -93,6 +108,10 @@ type TaskTemplateManagerConfig struct {
 	// ClientConfig is the Nomad Client configuration
 	ClientConfig *config.Config
 
+	// ConsulConfig is the Consul configuration of the cluster used by the
+	// task. The default Consul configuration of the client is used if nil.
+	ConsulConfig *structsc.ConsulConfig
+
 	// ConsulNamespace is the Consul namespace for the task
 	ConsulNamespace string
 
@@ -291,7 +310,7 @@ WAIT:
 			tm.config.Lifecycle.Kill(context.Background(),
 				structs.NewTaskEvent(structs.TaskKilling).
 					SetFailsTask().
-					SetDisplayMessage(fmt.Sprintf("Template failed: %v", err)))
+					SetDisplayMessage(templateFailedMessage(err)))
 		case <-tm.runner.TemplateRenderedCh():
 			// A template has been rendered, figure out what to do
 			events := tm.runner.RenderEvents()
@@ -385,6 +404,47 @@ WAIT:
 	}
 }
 
+// templateFailedMessage returns the task event message for a fatal template
+// error. Errors caused by missing Vault secrets are expanded since the same
+// error is returned when KV v2 secrets are soft-deleted, which would otherwise
+// be hard to tell apart from a typo in the secret path.
+func templateFailedMessage(err error) string {
+	m := vaultSecretMissingRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return fmt.Sprintf("Template failed: %v", err)
+	}
+
+	return fmt.Sprintf("Template failed: Vault secret %s does not exist, or the "+
+		"requested KV v2 version has been deleted or destroyed", m[1])
+}
+
+// requireSecret is a template function failing the template when a Vault KV v2
+// secret read with the secret function has been deleted or destroyed. Vault
+// returns the metadata of these versions without their data, so the template
+// would otherwise render empty values for them.
+func requireSecret(s *dep.Secret) (*dep.Secret, error) {
+	// The secret is nil until it has been read from Vault.
+	if s == nil {
+		return nil, nil
+	}
+
+	md, ok := s.Data["metadata"].(map[string]any)
+	if !ok {
+		return s, nil
+	}
+	if destroyed, _ := md["destroyed"].(bool); destroyed {
+		return nil, fmt.Errorf("version %v of the Vault KV v2 secret has been destroyed", md["version"])
+	}
+	if deletion, _ := md["deletion_time"].(string); deletion != "" {
+		// Versions can be scheduled for deletion with delete_version_after.
+		deletedAt, err := time.Parse(time.RFC3339Nano, deletion)
+		if err != nil || !deletedAt.After(time.Now()) {
+			return nil, fmt.Errorf("version %v of the Vault KV v2 secret has been deleted", md["version"])
+		}
+	}
+	return s, nil
+}
+
 // handleTemplateRerenders is used to handle template render events after they
 // have all rendered. It takes action based on which set of templates re-render.
 // The passed allRenderedTime is the time at which all templates have rendered.
@@ -405,7 +465,7 @@ func (tm *TaskTemplateManager) handleTemplateRerenders(allRenderedTime time.Time
 			tm.config.Lifecycle.Kill(context.Background(),
 				structs.NewTaskEvent(structs.TaskKilling).
 					SetFailsTask().
-					SetDisplayMessage(fmt.Sprintf("Template failed: %v", err)))
+					SetDisplayMessage(templateFailedMessage(err)))
 		case <-tm.runner.TemplateRenderedCh():
 			tm.onTemplateRendered(handledRenders, allRenderedTime)
 		}
@@ -707,6 +767,7 @@ func parseTemplateConfigs(config *TaskTemplateManagerConfig) (map[*ctconf.Templa
 		ct.LeftDelim = &tmpl.LeftDelim
 		ct.RightDelim = &tmpl.RightDelim
 		ct.ErrMissingKey = &tmpl.ErrMissingKey
+		ct.ExtFuncMap = templateFuncs
 		ct.FunctionDenylist = config.ClientConfig.TemplateConfig.FunctionDenylist
 		if sandboxEnabled {
 			ct.SandboxPath = &config.TaskDir
@@ -818,9 +879,13 @@ func newRunnerConfig(config *TaskTemplateManagerConfig,
 		}
 	}
 
-	// Set up the Consul config
-	if cc.ConsulConfig != nil {
-		conf.Consul.Address = &cc.ConsulConfig.Addr
+	// Set up the Consul config of the cluster used by the task
+	consulConfig := config.ConsulConfig
+	if consulConfig == nil {
+		consulConfig = cc.ConsulConfig
+	}
+	if consulConfig != nil {
+		conf.Consul.Address = &consulConfig.Addr
 
 		// if we're using WI, use the token from consul_hook
 		// NOTE: from Nomad 1.9 on, WI will be the only supported way of
@@ -828,28 +893,28 @@ func newRunnerConfig(config *TaskTemplateManagerConfig,
 		if config.ConsulToken != "" {
 			conf.Consul.Token = &config.ConsulToken
 		} else {
-			conf.Consul.Token = &cc.ConsulConfig.Token
+			conf.Consul.Token = &consulConfig.Token
 		}
 
 		// Get the Consul namespace from agent config. This is the lower level
 		// of precedence (beyond default).
-		if cc.ConsulConfig.Namespace != "" {
-			conf.Consul.Namespace = &cc.ConsulConfig.Namespace
+		if consulConfig.Namespace != "" {
+			conf.Consul.Namespace = &consulConfig.Namespace
 		}
 
-		if cc.ConsulConfig.EnableSSL != nil && *cc.ConsulConfig.EnableSSL {
-			verify := cc.ConsulConfig.VerifySSL != nil && *cc.ConsulConfig.VerifySSL
+		if consulConfig.EnableSSL != nil && *consulConfig.EnableSSL {
+			verify := consulConfig.VerifySSL != nil && *consulConfig.VerifySSL
 			conf.Consul.SSL = &ctconf.SSLConfig{
 				Enabled: pointer.Of(true),
 				Verify:  &verify,
-				Cert:    &cc.ConsulConfig.CertFile,
-				Key:     &cc.ConsulConfig.KeyFile,
-				CaCert:  &cc.ConsulConfig.CAFile,
+				Cert:    &consulConfig.CertFile,
+				Key:     &consulConfig.KeyFile,
+				CaCert:  &consulConfig.CAFile,
 			}
 		}
 
-		if cc.ConsulConfig.Auth != "" {
-			parts := strings.SplitN(cc.ConsulConfig.Auth, ":", 2)
+		if consulConfig.Auth != "" {
+			parts := strings.SplitN(consulConfig.Auth, ":", 2)
 			if len(parts) != 2 {
 				return nil, fmt.Errorf("Failed to parse Consul Auth config")
 			}
@@ -861,6 +926,13 @@ func newRunnerConfig(config *TaskTemplateManagerConfig,
 			}
 		}
 
+		// Route requests through the shared proxy, which handles TLS to
+		// the Consul agent.
+		if addr := cc.TemplateConsulAddrs[consulConfig.Name]; addr != "" {
+			conf.Consul.Address = &addr
+			conf.Consul.SSL = &ctconf.SSLConfig{Enabled: pointer.Of(false)}
+		}
+
 		// Set the user-specified Consul RetryConfig
 		if cc.TemplateConfig.ConsulRetry != nil {
 			var err error
@@ -923,6 +995,29 @@ func newRunnerConfig(config *TaskTemplateManagerConfig,
 			}
 		}
 
+		// Route requests through the shared proxy, which handles TLS to
+		// the Vault cluster.
+		if dialer := cc.TemplateVaultDialers[config.VaultConfig.Name]; dialer != nil {
+			addr, err := proxiedVaultAddr(config.VaultConfig.Addr)
+			if err != nil {
+				return nil, err
+			}
+			conf.Vault.Address = &addr
+			conf.Vault.Transport.CustomDialer = dialer
+			conf.Vault.SSL = &ctconf.SSLConfig{
+				Enabled:    pointer.Of(false),
+				Verify:     pointer.Of(false),
+				Cert:       &emptyStr,
+				Key:        &emptyStr,
+				CaCert:     &emptyStr,
+				CaPath:     &emptyStr,
+				ServerName: &emptyStr,
+			}
+		} else if cc.DNSResolver != nil {
+			// Resolve the host of the cluster with the client's DNS cache
+			conf.Vault.Transport.CustomDialer = cc.DNSResolver
+		}
+
 		// Set the user-specified Vault RetryConfig
 		if cc.TemplateConfig.VaultRetry != nil {
 			var err error
@@ -956,6 +1051,18 @@ func newRunnerConfig(config *TaskTemplateManagerConfig,
 	return conf, nil
 }
 
+// proxiedVaultAddr returns the Vault address to use when requests are routed
+// through the shared proxy. The proxy terminates TLS, so the runner must use
+// plain HTTP to connect to it.
+func proxiedVaultAddr(addr string) (string, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Vault address %q: %w", addr, err)
+	}
+	u.Scheme = "http"
+	return u.String(), nil
+}
+
 // loadTemplateEnv loads task environment variables from all templates.
 func loadTemplateEnv(tmpls []*structs.Template, taskEnv *taskenv.TaskEnv) (map[string]string, error) {
 	all := make(map[string]string, 50)
@@ -5,8 +5,11 @@ package template
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -20,13 +23,16 @@ import (
 	"time"
 
 	templateconfig "github.com/hashicorp/consul-template/config"
+	dep "github.com/hashicorp/consul-template/dependency"
 	ctestutil "github.com/hashicorp/consul/sdk/testutil"
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/client/allocdir"
 	trtesting "github.com/hashicorp/nomad/client/allocrunner/taskrunner/testing"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/dnscache"
 	"github.com/hashicorp/nomad/client/taskenv"
 	clienttestutil "github.com/hashicorp/nomad/client/testutil"
+	"github.com/hashicorp/nomad/helper/bufconndialer"
 	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/helper/users"
@@ -1733,6 +1739,106 @@ func TestTaskTemplateManager_Config_ServerName(t *testing.T) {
 	}
 }
 
+// TestTaskTemplateManager_Config_CacheProxy asserts the shared Consul and Vault
+// proxies are used by consul-template when their dialers are set.
+func TestTaskTemplateManager_Config_CacheProxy(t *testing.T) {
+	ci.Parallel(t)
+	c := config.DefaultConfig()
+	c.Node = mock.Node()
+	c.ConsulConfig.EnableSSL = pointer.Of(true)
+	c.VaultConfigs = map[string]*sconfig.VaultConfig{
+		structs.VaultDefaultCluster: {
+			Name:    structs.VaultDefaultCluster,
+			Enabled: pointer.Of(true),
+			Addr:    "https://vault.example.com:8200",
+		},
+	}
+	c.VaultConfig = c.VaultConfigs[structs.VaultDefaultCluster]
+
+	otherConsul := c.ConsulConfig.Copy()
+	otherConsul.Name = "other"
+	otherConsul.Addr = "consul.other.example.com:8501"
+	c.ConsulConfigs = map[string]*sconfig.ConsulConfig{
+		structs.ConsulDefaultCluster: c.ConsulConfig,
+		"other":                      otherConsul,
+	}
+
+	_, vaultDialer := bufconndialer.New()
+	c.TemplateConsulAddrs = map[string]string{
+		structs.ConsulDefaultCluster: "unix:///nomad/template_proxy/consul_default.sock",
+		"other":                      "unix:///nomad/template_proxy/consul_other.sock",
+	}
+	c.TemplateVaultDialers = map[string]*bufconndialer.BufConnWrapper{
+		structs.VaultDefaultCluster: vaultDialer,
+	}
+
+	config := &TaskTemplateManagerConfig{
+		ClientConfig: c,
+		VaultToken:   "token",
+		VaultConfig:  c.VaultConfigs[structs.VaultDefaultCluster],
+	}
+	ctconf, err := newRunnerConfig(config, nil)
+	must.NoError(t, err)
+
+	must.Eq(t, "unix:///nomad/template_proxy/consul_default.sock", *ctconf.Consul.Address)
+	must.False(t, *ctconf.Consul.SSL.Enabled)
+
+	// The proxy of the Consul cluster of the task is used
+	config.ConsulConfig = otherConsul
+	ctconf, err = newRunnerConfig(config, nil)
+	must.NoError(t, err)
+	must.Eq(t, "unix:///nomad/template_proxy/consul_other.sock", *ctconf.Consul.Address)
+	must.False(t, *ctconf.Consul.SSL.Enabled)
+
+	// The cluster is reached directly without the proxy
+	c.TemplateConsulAddrs = nil
+	ctconf, err = newRunnerConfig(config, nil)
+	must.NoError(t, err)
+	must.Eq(t, otherConsul.Addr, *ctconf.Consul.Address)
+
+	must.Eq[any](t, vaultDialer, ctconf.Vault.Transport.CustomDialer)
+	must.False(t, *ctconf.Vault.SSL.Enabled)
+	must.Eq(t, "http://vault.example.com:8200", *ctconf.Vault.Address)
+}
+
+// TestTaskTemplateManager_Config_DNSResolver asserts the client's DNS cache is
+// used by consul-template to reach Vault when the proxy isn't.
+func TestTaskTemplateManager_Config_DNSResolver(t *testing.T) {
+	ci.Parallel(t)
+	c := config.DefaultConfig()
+	c.Node = mock.Node()
+	c.VaultConfigs = map[string]*sconfig.VaultConfig{
+		structs.VaultDefaultCluster: {
+			Name:    structs.VaultDefaultCluster,
+			Enabled: pointer.Of(true),
+			Addr:    "https://vault.example.com:8200",
+		},
+	}
+	c.VaultConfig = c.VaultConfigs[structs.VaultDefaultCluster]
+	c.DNSResolver = dnscache.New(testlog.HCLogger(t), time.Minute, time.Hour)
+
+	config := &TaskTemplateManagerConfig{
+		ClientConfig: c,
+		VaultToken:   "token",
+		VaultConfig:  c.VaultConfigs[structs.VaultDefaultCluster],
+	}
+	ctconf, err := newRunnerConfig(config, nil)
+	must.NoError(t, err)
+
+	must.Eq[any](t, c.DNSResolver, ctconf.Vault.Transport.CustomDialer)
+	must.True(t, *ctconf.Vault.SSL.Enabled)
+	must.Eq(t, "https://vault.example.com:8200", *ctconf.Vault.Address)
+
+	// The proxy takes precedence over the DNS cache
+	_, vaultDialer := bufconndialer.New()
+	c.TemplateVaultDialers = map[string]*bufconndialer.BufConnWrapper{
+		structs.VaultDefaultCluster: vaultDialer,
+	}
+	ctconf, err = newRunnerConfig(config, nil)
+	must.NoError(t, err)
+	must.Eq[any](t, vaultDialer, ctconf.Vault.Transport.CustomDialer)
+}
+
 // TestTaskTemplateManager_Config_VaultNamespace asserts the Vault namespace setting is
 // propagated to consul-template's configuration.
 func TestTaskTemplateManager_Config_VaultNamespace(t *testing.T) {
@@ -2456,6 +2562,201 @@ func TestTaskTemplateManager_Template_ErrMissingKey_Set(t *testing.T) {
 	}
 }
 
+func TestTaskTemplateManager_templateFailedMessage(t *testing.T) {
+	ci.Parallel(t)
+
+	testCases := []struct {
+		name   string
+		err    error
+		expect string
+	}{
+		{
+			name:   "generic error",
+			err:    errors.New("template: :1: function \"foo\" not defined"),
+			expect: `Template failed: template: :1: function "foo" not defined`,
+		},
+		{
+			name:   "missing or deleted vault secret",
+			err:    errors.New("no secret exists at secret/data/app"),
+			expect: "Template failed: Vault secret secret/data/app does not exist, or the requested KV v2 version has been deleted or destroyed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			must.Eq(t, tc.expect, templateFailedMessage(tc.err))
+		})
+	}
+}
+
+func TestTaskTemplateManager_requireSecret(t *testing.T) {
+	ci.Parallel(t)
+
+	secret := func(metadata map[string]any) *dep.Secret {
+		return &dep.Secret{Data: map[string]any{"data": nil, "metadata": metadata}}
+	}
+
+	testCases := []struct {
+		name   string
+		secret *dep.Secret
+		expErr string
+	}{
+		{
+			name:   "not read yet",
+			secret: nil,
+		},
+		{
+			name:   "kv v1",
+			secret: &dep.Secret{Data: map[string]any{"password": "foo"}},
+		},
+		{
+			name:   "live version",
+			secret: secret(map[string]any{"version": 1, "deletion_time": "", "destroyed": false}),
+		},
+		{
+			name: "scheduled deletion",
+			secret: secret(map[string]any{
+				"version": 1, "deletion_time": time.Now().Add(time.Hour).Format(time.RFC3339Nano), "destroyed": false,
+			}),
+		},
+		{
+			name:   "deleted version",
+			secret: secret(map[string]any{"version": 2, "deletion_time": "2024-01-01T00:00:00Z", "destroyed": false}),
+			expErr: "version 2 of the Vault KV v2 secret has been deleted",
+		},
+		{
+			name:   "destroyed version",
+			secret: secret(map[string]any{"version": 3, "deletion_time": "", "destroyed": true}),
+			expErr: "version 3 of the Vault KV v2 secret has been destroyed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := requireSecret(tc.secret)
+			if tc.expErr != "" {
+				must.EqError(t, err, tc.expErr)
+				must.Nil(t, s)
+			} else {
+				must.NoError(t, err)
+				must.Eq(t, tc.secret, s)
+			}
+		})
+	}
+}
+
+// newKVv2TestServer returns a fake Vault server with a KV v2 secret at
+// secret/data/app. Version 1 of the secret is live, version 2 is destroyed and
+// version 3 is deleted.
+func newKVv2TestServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/sys/internal/ui/mounts/secret/data/app":
+			fmt.Fprint(w, `{"data":{"path":"secret/","type":"kv","options":{"version":"2"}}}`)
+		case "/v1/secret/data/app":
+			switch r.URL.Query().Get("version") {
+			case "1":
+				fmt.Fprint(w, `{"data":{"data":{"password":"v1"},"metadata":{"version":1,"deletion_time":"","destroyed":false}}}`)
+			case "2":
+				// Vault returns the metadata of deleted and destroyed versions
+				// along with a 404.
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"data":{"data":null,"metadata":{"version":2,"deletion_time":"","destroyed":true}}}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"data":{"data":null,"metadata":{"version":3,"deletion_time":"2024-01-01T00:00:00Z","destroyed":false}}}`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestTaskTemplateManager_Vault_KVv2Versions asserts templates can pin the
+// version of a KV v2 secret, and fail rather than render empty values when the
+// version has been deleted or destroyed.
+func TestTaskTemplateManager_Vault_KVv2Versions(t *testing.T) {
+	ci.Parallel(t)
+
+	srv := newKVv2TestServer(t)
+
+	testCases := []struct {
+		name      string
+		embedded  string
+		expRender string
+		expKill   string
+	}{
+		{
+			name:      "pinned version",
+			embedded:  `{{with secret "secret/data/app?version=1"}}{{.Data.data.password}}{{end}}`,
+			expRender: "v1",
+		},
+		{
+			name:      "destroyed version renders empty",
+			embedded:  `{{with secret "secret/data/app?version=2"}}{{.Data.data.password}}{{end}}`,
+			expRender: "<no value>",
+		},
+		{
+			name:     "destroyed version with requireSecret",
+			embedded: `{{with secret "secret/data/app?version=2" | requireSecret}}{{.Data.data.password}}{{end}}`,
+			expKill:  "version 2 of the Vault KV v2 secret has been destroyed",
+		},
+		{
+			name:     "deleted version",
+			embedded: `{{with secret "secret/data/app?version=3"}}{{.Data.data.password}}{{end}}`,
+			expKill:  "Vault secret secret/data/app does not exist, or the requested KV v2 version has been deleted or destroyed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := "my.tmpl"
+			template := &structs.Template{
+				EmbeddedTmpl: tc.embedded,
+				DestPath:     file,
+				ChangeMode:   structs.TemplateChangeModeNoop,
+			}
+
+			harness := newTestHarness(t, []*structs.Template{template}, false, false)
+			harness.config.VaultConfigs = map[string]*sconfig.VaultConfig{
+				structs.VaultDefaultCluster: {
+					Name:    structs.VaultDefaultCluster,
+					Enabled: pointer.Of(true),
+					Addr:    srv.URL,
+				},
+			}
+			harness.config.VaultConfig = harness.config.VaultConfigs[structs.VaultDefaultCluster]
+			harness.config.TemplateConfig.VaultRetry = &config.RetryConfig{
+				Attempts: pointer.Of(1),
+				Backoff:  pointer.Of(10 * time.Millisecond),
+			}
+			harness.vaultToken = "root"
+			harness.start(t)
+			defer harness.stop()
+
+			select {
+			case <-harness.mockHooks.UnblockCh:
+				if tc.expKill != "" {
+					t.Fatal("expected the task to be killed")
+				}
+			case e := <-harness.mockHooks.KillCh:
+				must.StrContains(t, e.DisplayMessage, tc.expKill)
+				return
+			case <-time.After(time.Duration(5*testutil.TestMultiplier()) * time.Second):
+				t.Fatal("timeout")
+			}
+
+			raw, err := os.ReadFile(filepath.Join(harness.taskDir, file))
+			must.NoError(t, err)
+			must.Eq(t, tc.expRender, string(raw))
+		})
+	}
+}
+
 // TestTaskTemplateManager_writeToFile_Disabled asserts the consul-template function
 // writeToFile is disabled by default.
 func TestTaskTemplateManager_writeToFile_Disabled(t *testing.T) {
@@ -206,12 +206,16 @@ func (h *templateHook) newManager() (unblock chan struct{}, err error) {
 		}
 	}
 
+	tg := h.config.alloc.Job.LookupTaskGroup(h.config.alloc.TaskGroup)
+	consulConfig := h.config.clientConfig.ConsulConfigs[h.task.GetConsulClusterName(tg)]
+
 	m, err := template.NewTaskTemplateManager(&template.TaskTemplateManagerConfig{
 		UnblockCh:            unblock,
 		Lifecycle:            h.config.lifecycle,
 		Events:               h.config.events,
 		Templates:            h.config.templates,
 		ClientConfig:         h.config.clientConfig,
+		ConsulConfig:         consulConfig,
 		ConsulNamespace:      h.config.consulNamespace,
 		ConsulToken:          h.consulToken,
 		VaultToken:           h.vaultToken,
@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// usageSnapshot is the resource usage of a run of a task, which is attached to
+// the event of the task exiting when it's OOM killed or fails, for post-mortems
+// without external monitoring. The processes of the task are gone by the time
+// it exited, so the snapshot is updated from each stats sample instead.
+type usageSnapshot struct {
+	// sampled is set once a stats sample was recorded.
+	sampled bool
+
+	// peakRSS is the highest RSS of the task across the samples, in bytes.
+	peakRSS uint64
+
+	// throttledTime is the total time the task was throttled for by the CPU
+	// quota of its cgroup, in nanoseconds.
+	throttledTime uint64
+
+	// openFDs is the number of file descriptors the processes of the task
+	// had open in the last sample, or -1 if they couldn't be counted.
+	openFDs int
+}
+
+// update records a stats sample of the task.
+func (s *usageSnapshot) update(ru *cstructs.TaskResourceUsage) {
+	if ru == nil || ru.ResourceUsage == nil {
+		return
+	}
+	s.sampled = true
+	if ms := ru.ResourceUsage.MemoryStats; ms != nil {
+		s.peakRSS = max(s.peakRSS, ms.RSS)
+	}
+	if cs := ru.ResourceUsage.CpuStats; cs != nil {
+		s.throttledTime = cs.ThrottledTime
+	}
+	s.openFDs = countOpenFDs(ru.Pids)
+}
+
+// setOn attaches the snapshot to the event, if a sample was recorded.
+func (s *usageSnapshot) setOn(event *structs.TaskEvent) {
+	if !s.sampled {
+		return
+	}
+	event.SetResourceUsage(s.peakRSS, time.Duration(s.throttledTime), s.openFDs)
+}
+
+// countOpenFDs returns the number of file descriptors opened by the processes,
+// or -1 if the driver doesn't report the processes of its tasks or they can't
+// be inspected.
+func countOpenFDs(pids map[string]*cstructs.ResourceUsage) int {
+	if len(pids) == 0 {
+		return -1
+	}
+
+	total := 0
+	for pid := range pids {
+		n, err := strconv.ParseInt(pid, 10, 32)
+		if err != nil {
+			return -1
+		}
+		proc, err := process.NewProcess(int32(n))
+		if err != nil {
+			// The process may have exited since the sample
+			continue
+		}
+		fds, err := proc.NumFDs()
+		if err != nil {
+			return -1
+		}
+		total += int(fds)
+	}
+	return total
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package taskrunner
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestUsageSnapshot(t *testing.T) {
+	ci.Parallel(t)
+
+	sample := func(rss, throttled uint64) *cstructs.TaskResourceUsage {
+		return &cstructs.TaskResourceUsage{
+			ResourceUsage: &cstructs.ResourceUsage{
+				MemoryStats: &cstructs.MemoryStats{RSS: rss},
+				CpuStats:    &cstructs.CpuStats{ThrottledTime: throttled},
+			},
+		}
+	}
+
+	// No sample was recorded
+	var s usageSnapshot
+	s.update(nil)
+	event := structs.NewTaskEvent(structs.TaskTerminated)
+	s.setOn(event)
+	must.MapNotContainsKey(t, event.Details, "peak_memory_rss")
+
+	// The peak RSS and the last throttled time are recorded
+	s.update(sample(100, 1000))
+	s.update(sample(300, 2000))
+	s.update(sample(200, 3000))
+	must.Eq(t, usageSnapshot{sampled: true, peakRSS: 300, throttledTime: 3000, openFDs: -1}, s)
+
+	event = structs.NewTaskEvent(structs.TaskTerminated)
+	s.setOn(event)
+	must.Eq(t, "300", event.Details["peak_memory_rss"])
+	must.Eq(t, (3 * time.Microsecond).String(), event.Details["cpu_throttled_time"])
+	must.MapNotContainsKey(t, event.Details, "open_fds")
+}
+
+func TestUsageSnapshot_countOpenFDs(t *testing.T) {
+	ci.Parallel(t)
+
+	must.Eq(t, -1, countOpenFDs(nil))
+	must.Eq(t, -1, countOpenFDs(map[string]*cstructs.ResourceUsage{"invalid": {}}))
+
+	if runtime.GOOS != "linux" {
+		t.Skip("counting open file descriptors is only supported on Linux")
+	}
+	pids := map[string]*cstructs.ResourceUsage{strconv.Itoa(os.Getpid()): {}}
+	must.Positive(t, countOpenFDs(pids))
+}
@@ -5,13 +5,16 @@ package taskrunner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os/user"
 
 	log "github.com/hashicorp/go-hclog"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/taskenv"
+	"github.com/hashicorp/nomad/helper/users"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -37,6 +40,9 @@ func (h *validateHook) Prestart(ctx context.Context, req *interfaces.TaskPrestar
 	if err := validateTask(req.Task, req.TaskEnv, h.config); err != nil {
 		return err
 	}
+	if err := lookupTaskUser(req.Task, h.config); err != nil {
+		return err
+	}
 
 	resp.Done = true
 	return nil
@@ -71,3 +77,38 @@ func validateTask(task *structs.Task, taskEnv *taskenv.TaskEnv, conf *config.Con
 	}
 	return mErr.ErrorOrNil()
 }
+
+// lookupTaskUser resolves the user of the task with the directory service of
+// the host when "user.directory_lookup" is enabled, so a task running as a
+// user unknown to the host fails before its driver starts it. Lookups are
+// cached, and timeouts are recoverable so the task is restarted once the
+// directory service is reachable again.
+func lookupTaskUser(task *structs.Task, conf *config.Config) error {
+	if task.User == "" || !conf.ReadBoolDefault("user.directory_lookup", false) {
+		return nil
+	}
+	lookupDrivers := conf.ReadStringListToMapDefault("user.directory_lookup_drivers", config.DefaultUserLookupDrivers)
+	if _, ok := lookupDrivers[task.Driver]; !ok {
+		return nil
+	}
+
+	if !users.NSSSupported() {
+		return fmt.Errorf("failed to resolve user %q: resolving users with the directory service requires a Nomad binary built with cgo", task.User)
+	}
+
+	timeout := conf.ReadDurationDefault("user.directory_lookup_timeout", config.DefaultUserLookupTimeout)
+	_, err := users.LookupTimeout(task.User, timeout)
+
+	var unknownErr user.UnknownUserError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, users.ErrLookupTimeout):
+		return structs.NewRecoverableError(
+			fmt.Errorf("failed to resolve user %q: directory service did not respond within %s", task.User, timeout), true)
+	case errors.As(err, &unknownErr):
+		return fmt.Errorf("failed to resolve user %q: user not found by the directory service of the host", task.User)
+	default:
+		return fmt.Errorf("failed to resolve user %q: %w", task.User, err)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/taskenv"
+	"github.com/hashicorp/nomad/helper/users"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -65,3 +67,36 @@ func TestTaskRunner_Validate_ServiceName(t *testing.T) {
 	task.Services[0].Name = "${BAD}"
 	require.Error(t, validateTask(task, builder.Build(), conf))
 }
+
+func TestTaskRunner_Validate_LookupUser(t *testing.T) {
+	ci.Parallel(t)
+
+	if !users.NSSSupported() {
+		t.Skip("user lookups don't use NSS")
+	}
+
+	conf := config.DefaultConfig()
+	task := &structs.Task{
+		Driver: "exec",
+		User:   "doesnotexist",
+	}
+
+	// Users aren't resolved unless enabled
+	must.NoError(t, lookupTaskUser(task, conf))
+
+	conf.Options = map[string]string{"user.directory_lookup": "true"}
+	err := lookupTaskUser(task, conf)
+	must.EqError(t, err, `failed to resolve user "doesnotexist": user not found by the directory service of the host`)
+	must.False(t, structs.IsRecoverable(err))
+
+	task.User = "nobody"
+	must.NoError(t, lookupTaskUser(task, conf))
+
+	// Users of drivers which don't run tasks as host users aren't resolved
+	task.Driver = "docker"
+	task.User = "doesnotexist"
+	must.NoError(t, lookupTaskUser(task, conf))
+
+	conf.Options["user.directory_lookup_drivers"] = "docker"
+	must.Error(t, lookupTaskUser(task, conf))
+}
@@ -38,6 +38,10 @@ const (
 	// vaultTokenFile is the name of the file holding the Vault token inside the
 	// task's secret directory
 	vaultTokenFile = "vault_token"
+
+	// vaultRevokeTimeout is the maximum amount of time to wait for Vault to
+	// revoke the task token when the task stops
+	vaultRevokeTimeout = 10 * time.Second
 )
 
 type vaultTokenUpdateHandler interface {
@@ -123,6 +127,15 @@ type vaultHook struct {
 	// deriveTokenFunc is the function used to derive Vault tokens.
 	deriveTokenFunc deriveTokenFunc
 
+	// revokeOnStop is true if the hook must revoke the Vault token, and all
+	// the leases created with it, when the task stops. Tokens derived using
+	// the legacy flow are revoked by the Nomad servers instead.
+	revokeOnStop bool
+
+	// revoked is true if the token has already been revoked, making Stop
+	// idempotent.
+	revoked bool
+
 	// future is used to wait on retrieving a Vault token
 	future *tokenFuture
 }
@@ -151,6 +164,7 @@ func newVaultHook(config *vaultHookConfig) *vaultHook {
 	switch {
 	case wid != nil:
 		h.deriveTokenFunc = h.deriveVaultTokenJWT
+		h.revokeOnStop = true
 	default:
 		h.deriveTokenFunc = h.deriveVaultTokenLegacy
 	}
@@ -223,6 +237,28 @@ func (h *vaultHook) Prestart(ctx context.Context, req *interfaces.TaskPrestartRe
 func (h *vaultHook) Stop(ctx context.Context, req *interfaces.TaskStopRequest, resp *interfaces.TaskStopResponse) error {
 	// Shutdown any created manager
 	h.cancel()
+
+	// The task will not run again, so revoke its token and the leases it
+	// created instead of waiting for them to expire. Stop may be called
+	// without Prestart so the client may not have been set.
+	if !h.revokeOnStop || h.revoked || h.client == nil {
+		return nil
+	}
+
+	token := h.future.Get()
+	if token == "" {
+		return nil
+	}
+
+	revokeCtx, cancel := context.WithTimeout(context.Background(), vaultRevokeTimeout)
+	defer cancel()
+
+	if err := h.client.RevokeToken(revokeCtx, token); err != nil {
+		h.logger.Warn("failed to revoke Vault token, leases will be revoked when they expire", "error", err)
+		return nil
+	}
+
+	h.revoked = true
 	return nil
 }
 
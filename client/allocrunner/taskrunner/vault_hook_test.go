@@ -320,10 +320,61 @@ func TestTaskRunner_VaultHook(t *testing.T) {
 				wait.Timeout(5*time.Second),
 				wait.Gap(100*time.Millisecond),
 			))
+
+			// Tokens derived with workload identities must be revoked when
+			// the hook stops, legacy tokens are revoked by the servers.
+			if tc.expectLegacy {
+				must.SliceEmpty(t, client.RevokedTokens())
+			} else {
+				must.Eq(t, []string{updater.currentToken}, client.RevokedTokens())
+			}
+
+			// Stop must be idempotent and not revoke the token twice.
+			revoked := len(client.RevokedTokens())
+			err = hook.Stop(ctx, nil, nil)
+			must.NoError(t, err)
+			must.SliceLen(t, revoked, client.RevokedTokens())
 		})
 	}
 }
 
+func TestTaskRunner_VaultHook_revokeError(t *testing.T) {
+	ci.Parallel(t)
+
+	hook := setupTestVaultHook(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	req := &interfaces.TaskPrestartRequest{
+		TaskEnv: taskenv.NewEmptyTaskEnv(),
+		TaskDir: &allocdir.TaskDir{
+			SecretsDir: t.TempDir(),
+			PrivateDir: t.TempDir(),
+		},
+		Task: hook.task,
+	}
+	var resp interfaces.TaskPrestartResponse
+	must.NoError(t, hook.Prestart(ctx, req, &resp))
+
+	client := hook.client.(*vaultclient.MockVaultClient)
+	token := hook.future.Get()
+	must.NotEq(t, "", token)
+
+	// Failing to revoke the token doesn't fail the stop of the task.
+	client.SetRevokeTokenError(token, errors.New("vault unavailable"))
+	must.NoError(t, hook.Stop(ctx, nil, nil))
+	must.SliceEmpty(t, client.RevokedTokens())
+	must.False(t, hook.revoked)
+
+	// The token is revoked if the hook is stopped again once Vault is
+	// available.
+	client.SetRevokeTokenError(token, nil)
+	must.NoError(t, hook.Stop(ctx, nil, nil))
+	must.Eq(t, []string{token}, client.RevokedTokens())
+	must.True(t, hook.revoked)
+}
+
 func TestTaskRunner_VaultHook_recover(t *testing.T) {
 	ci.Parallel(t)
 
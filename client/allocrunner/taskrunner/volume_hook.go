@@ -41,7 +41,7 @@ func validateHostVolumes(requestedByAlias map[string]*structs.VolumeRequest, cli
 	for _, req := range requestedByAlias {
 		// This is a defensive check, but this function should only ever receive
 		// host-type volumes.
-		if req.Type != structs.VolumeTypeHost {
+		if !req.IsHostVolume() {
 			continue
 		}
 
@@ -75,7 +75,7 @@ func (h *volumeHook) hostVolumeMountConfigurations(taskMounts []*structs.VolumeM
 
 		// This is a defensive check, but this function should only ever receive
 		// host-type volumes.
-		if req.Type != structs.VolumeTypeHost {
+		if !req.IsHostVolume() {
 			continue
 		}
 
@@ -103,14 +103,21 @@ func (h *volumeHook) hostVolumeMountConfigurations(taskMounts []*structs.VolumeM
 }
 
 // partitionVolumesByType takes a map of volume-alias to volume-request and
-// returns them in the form of volume-type:(volume-alias:volume-request)
+// returns them in the form of volume-type:(volume-alias:volume-request). Local
+// volumes are mounted from host volumes, so they are returned with the host
+// volumes.
 func partitionVolumesByType(xs map[string]*structs.VolumeRequest) map[string]map[string]*structs.VolumeRequest {
 	result := make(map[string]map[string]*structs.VolumeRequest)
 	for name, req := range xs {
-		txs, ok := result[req.Type]
+		volType := req.Type
+		if req.IsHostVolume() {
+			volType = structs.VolumeTypeHost
+		}
+
+		txs, ok := result[volType]
 		if !ok {
 			txs = make(map[string]*structs.VolumeRequest)
-			result[req.Type] = txs
+			result[volType] = txs
 		}
 		txs[name] = req
 	}
@@ -208,6 +215,36 @@ func (h *volumeHook) prepareCSIVolumes(req *interfaces.TaskPrestartRequest, volu
 	return mounts, nil
 }
 
+// prepareIdentityProjections returns the mounts of the directories the
+// identities of the task are projected into by the identity projection
+// allocrunner hook.
+func (h *volumeHook) prepareIdentityProjections() ([]*drivers.MountConfig, error) {
+	projections := h.runner.allocHookResources.GetIdentityProjections(h.runner.task.Name)
+	if len(projections) == 0 {
+		return nil, nil
+	}
+
+	caps, err := h.runner.DriverCapabilities()
+	if err != nil {
+		return nil, fmt.Errorf("could not validate task driver capabilities: %v", err)
+	}
+	if caps.MountConfigs == drivers.MountConfigSupportNone {
+		return nil, fmt.Errorf(
+			"task driver %q for %q does not support identity projections",
+			h.runner.task.Driver, h.runner.task.Name)
+	}
+
+	mounts := make([]*drivers.MountConfig, 0, len(projections))
+	for _, p := range projections {
+		mounts = append(mounts, &drivers.MountConfig{
+			HostPath: p.HostPath,
+			TaskPath: p.TaskPath,
+			Readonly: true,
+		})
+	}
+	return mounts, nil
+}
+
 func (h *volumeHook) Prestart(ctx context.Context, req *interfaces.TaskPrestartRequest, resp *interfaces.TaskPrestartResponse) error {
 	h.taskEnv = req.TaskEnv
 	interpolateVolumeMounts(req.Task.VolumeMounts, h.taskEnv)
@@ -224,6 +261,11 @@ func (h *volumeHook) Prestart(ctx context.Context, req *interfaces.TaskPrestartR
 		return err
 	}
 
+	identityMounts, err := h.prepareIdentityProjections()
+	if err != nil {
+		return err
+	}
+
 	// Because this hook is also ran on restores, we only add mounts that do not
 	// already exist. Although this loop is somewhat expensive, there are only
 	// a small number of mounts that exist within most individual tasks. We may
@@ -235,6 +277,9 @@ func (h *volumeHook) Prestart(ctx context.Context, req *interfaces.TaskPrestartR
 	for _, m := range csiVolumeMounts {
 		mounts = ensureMountpointInserted(mounts, m)
 	}
+	for _, m := range identityMounts {
+		mounts = ensureMountpointInserted(mounts, m)
+	}
 	h.runner.hookResources.setMounts(mounts)
 
 	return nil
@@ -10,6 +10,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -282,6 +283,11 @@ func (p *localPrevAlloc) Migrate(ctx context.Context, dest *allocdir.AllocDir) e
 	return dest.Move(p.prevAllocDir, p.tasks)
 }
 
+// MigrationStatus returns nil as local data is moved without being copied.
+func (p *localPrevAlloc) MigrationStatus() *structs.AllocMigrationStatus {
+	return nil
+}
+
 // remotePrevAlloc is a prevAllocWatcher for previous allocations on remote
 // nodes as an updated allocation.
 type remotePrevAlloc struct {
@@ -320,6 +326,11 @@ type remotePrevAlloc struct {
 	// migrateToken allows a client to migrate data in an ACL-protected remote
 	// volume
 	migrateToken string
+
+	// status is the progress of the migration once it started. Writers must
+	// acquire the statusLock and readers should use MigrationStatus.
+	status     *structs.AllocMigrationStatus
+	statusLock sync.RWMutex
 }
 
 // IsWaiting returns true if there's a concurrent call inside Wait
@@ -338,6 +349,35 @@ func (p *remotePrevAlloc) IsMigrating() bool {
 	return b
 }
 
+// MigrationStatus returns the progress of the migration, or nil if it hasn't
+// started.
+func (p *remotePrevAlloc) MigrationStatus() *structs.AllocMigrationStatus {
+	p.statusLock.RLock()
+	defer p.statusLock.RUnlock()
+	return p.status.Copy()
+}
+
+// updateStatus applies fn to the status of the migration, if started, and
+// updates its estimated time left.
+func (p *remotePrevAlloc) updateStatus(fn func(*structs.AllocMigrationStatus)) {
+	p.statusLock.Lock()
+	defer p.statusLock.Unlock()
+
+	s := p.status
+	if s == nil {
+		return
+	}
+	fn(s)
+
+	s.UpdatedAt = time.Now()
+	s.ETA = 0
+	elapsed := s.UpdatedAt.Sub(s.StartedAt)
+	if !s.Complete && s.BytesMigrated > 0 && s.BytesTotal > s.BytesMigrated && elapsed > 0 {
+		rate := float64(s.BytesMigrated) / elapsed.Seconds()
+		s.ETA = time.Duration(float64(s.BytesTotal-s.BytesMigrated) / rate * float64(time.Second))
+	}
+}
+
 // Wait until the remote previous allocation has terminated.
 func (p *remotePrevAlloc) Wait(ctx context.Context) error {
 	p.waitingLock.Lock()
@@ -448,22 +488,42 @@ func (p *remotePrevAlloc) Migrate(ctx context.Context, dest *allocdir.AllocDir)
 		return nil
 	}
 
-	addr, err := p.getNodeAddr(ctx, p.nodeID)
-	if err != nil {
+	now := time.Now()
+	p.statusLock.Lock()
+	p.status = &structs.AllocMigrationStatus{
+		PreviousAllocID: p.prevAllocID,
+		StartedAt:       now,
+		UpdatedAt:       now,
+	}
+	p.statusLock.Unlock()
+
+	failed := func(err error) error {
+		p.updateStatus(func(s *structs.AllocMigrationStatus) {
+			s.Error = err.Error()
+		})
 		return err
 	}
 
-	prevAllocDir, err := p.migrateAllocDir(ctx, addr)
+	addr, token, err := p.introduce(ctx)
 	if err != nil {
-		return err
+		return failed(err)
+	}
+
+	prevAllocDir, err := p.migrateAllocDir(ctx, dest, addr, token)
+	if err != nil {
+		return failed(err)
 	}
 
 	if err := dest.Move(prevAllocDir, p.tasks); err != nil {
 		// cleanup on error
 		prevAllocDir.Destroy()
-		return err
+		return failed(err)
 	}
 
+	p.updateStatus(func(s *structs.AllocMigrationStatus) {
+		s.Complete = true
+	})
+
 	if err := prevAllocDir.Destroy(); err != nil {
 		p.logger.Error("error destroying alloc dir",
 			"error", err, "previous_alloc_dir", prevAllocDir.AllocDir)
@@ -471,6 +531,37 @@ func (p *remotePrevAlloc) Migrate(ctx context.Context, dest *allocdir.AllocDir)
 	return nil
 }
 
+// introduce asks the servers to introduce this node to the node of the
+// previous alloc, and returns the address of that node and the one-time token
+// it accepts to snapshot the previous alloc. The data is then streamed
+// directly between the nodes, over mTLS if TLS is enabled. If the servers or
+// the node of the previous alloc can't make the introduction, such as when
+// they are running an older version, the address of the node is looked up and
+// the migrate token is used instead.
+func (p *remotePrevAlloc) introduce(ctx context.Context) (string, string, error) {
+	req := structs.AllocMigrationIntroductionRequest{
+		AllocID: p.allocID,
+		QueryOptions: structs.QueryOptions{
+			Region:    p.config.Region,
+			AuthToken: p.config.Node.SecretID,
+		},
+	}
+
+	var resp structs.AllocMigrationIntroductionResponse
+	err := p.rpc.RPC("Alloc.MigrationIntroduction", &req, &resp)
+	if err == nil && resp.NodeID == p.nodeID {
+		return resp.Address, resp.Token, nil
+	}
+	p.logger.Debug("failed to get introduced to the node of the previous alloc, using its migrate token",
+		"error", err, "node_id", p.nodeID)
+
+	addr, err := p.getNodeAddr(ctx, p.nodeID)
+	if err != nil {
+		return "", "", err
+	}
+	return addr, p.migrateToken, nil
+}
+
 // getNodeAddr gets the node from the server with the given Node ID
 func (p *remotePrevAlloc) getNodeAddr(ctx context.Context, nodeID string) (string, error) {
 	req := structs.NodeSpecificRequest{
@@ -509,11 +600,13 @@ func (p *remotePrevAlloc) getNodeAddr(ctx context.Context, nodeID string) (strin
 	return scheme + resp.Node.HTTPAddr, nil
 }
 
-// migrate a remote alloc dir to local node. Caller is responsible for calling
-// Destroy on the returned allocdir if no error occurs.
-func (p *remotePrevAlloc) migrateAllocDir(ctx context.Context, nodeAddr string) (*allocdir.AllocDir, error) {
-	// Create the previous alloc dir
-	prevAllocDir := allocdir.NewAllocDir(p.logger, p.config.AllocDir, p.prevAllocID)
+// migrate a remote alloc dir to local node, authenticating with the given
+// token. Caller is responsible for calling Destroy on the returned allocdir if
+// no error occurs.
+func (p *remotePrevAlloc) migrateAllocDir(ctx context.Context, dest *allocdir.AllocDir, nodeAddr, token string) (*allocdir.AllocDir, error) {
+	// Create the previous alloc dir in the root of the destination, so its
+	// data can be moved without copying it across disks
+	prevAllocDir := allocdir.NewAllocDir(p.logger, filepath.Dir(dest.AllocDir), p.prevAllocID)
 	if err := prevAllocDir.Build(); err != nil {
 		return nil, fmt.Errorf("error building alloc dir for previous alloc %q: %v", p.prevAllocID, err)
 	}
@@ -533,13 +626,20 @@ func (p *remotePrevAlloc) migrateAllocDir(ctx context.Context, nodeAddr string)
 	}
 
 	url := fmt.Sprintf("/v1/client/allocation/%v/snapshot", p.prevAllocID)
-	qo := &nomadapi.QueryOptions{AuthToken: p.migrateToken}
-	resp, err := apiClient.Raw().Response(url, qo)
+	qo := &nomadapi.QueryOptions{AuthToken: token}
+	resp, header, err := apiClient.Raw().ResponseWithHeaders(url, qo)
 	if err != nil {
 		prevAllocDir.Destroy()
 		return nil, fmt.Errorf("error getting snapshot from previous alloc %q: %v", p.prevAllocID, err)
 	}
 
+	// Older clients don't report the size of their snapshots
+	if size, err := strconv.ParseInt(header.Get(allocdir.SnapshotSizeHeader), 10, 64); err == nil {
+		p.updateStatus(func(s *structs.AllocMigrationStatus) {
+			s.BytesTotal = size
+		})
+	}
+
 	if err := p.streamAllocDir(ctx, resp, prevAllocDir.AllocDir); err != nil {
 		prevAllocDir.Destroy()
 		return nil, err
@@ -648,6 +748,9 @@ func (p *remotePrevAlloc) streamAllocDir(ctx context.Context, resp io.ReadCloser
 						f.Close()
 						return fmt.Errorf("error writing to file %q: %v", f.Name(), err)
 					}
+					p.updateStatus(func(s *structs.AllocMigrationStatus) {
+						s.BytesMigrated += int64(n)
+					})
 				}
 
 				if err != nil {
@@ -679,5 +782,8 @@ func (NoopPrevAlloc) Wait(context.Context) error { return nil }
 // Migrate returns nil immediately.
 func (NoopPrevAlloc) Migrate(context.Context, *allocdir.AllocDir) error { return nil }
 
+// MigrationStatus returns nil.
+func (NoopPrevAlloc) MigrationStatus() *structs.AllocMigrationStatus { return nil }
+
 func (NoopPrevAlloc) IsWaiting() bool   { return false }
 func (NoopPrevAlloc) IsMigrating() bool { return false }
@@ -276,3 +276,47 @@ func TestPrevAlloc_StreamAllocDir_Error(t *testing.T) {
 		t.Fatalf("expected foo.txt to be size 1 but found %d", fi.Size())
 	}
 }
+
+// TestPrevAlloc_StreamAllocDir_Status asserts that the bytes streamed are
+// counted in the migration status.
+func TestPrevAlloc_StreamAllocDir_Status(t *testing.T) {
+	ci.Parallel(t)
+
+	dest := t.TempDir()
+
+	now := time.Now()
+	prevAlloc := &remotePrevAlloc{
+		logger:      testlog.HCLogger(t),
+		allocID:     "123",
+		prevAllocID: "abc",
+		migrate:     true,
+		status: &structs.AllocMigrationStatus{
+			PreviousAllocID: "abc",
+			BytesTotal:      4096,
+			StartedAt:       now.Add(-time.Second),
+			UpdatedAt:       now,
+		},
+	}
+
+	tarBuf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(tarBuf)
+	contents := bytes.Repeat([]byte{'a'}, 3072)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "foo.txt",
+		Mode:     0666,
+		Size:     int64(len(contents)),
+		ModTime:  now,
+		Typeflag: tar.TypeReg,
+	}))
+	_, err := tw.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	require.NoError(t, prevAlloc.streamAllocDir(context.Background(), io.NopCloser(tarBuf), dest))
+
+	status := prevAlloc.MigrationStatus()
+	require.Equal(t, int64(3072), status.BytesMigrated)
+	require.Equal(t, float64(75), status.Percent())
+	require.Positive(t, status.ETA)
+	require.False(t, status.Complete)
+}
@@ -10,6 +10,7 @@ import (
 	"net/rpc"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -58,10 +59,13 @@ import (
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/structs"
 	nconfig "github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/plugins/allochook"
 	"github.com/hashicorp/nomad/plugins/csi"
 	"github.com/hashicorp/nomad/plugins/device"
 	vaultapi "github.com/hashicorp/vault/api"
 	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
 	"golang.org/x/exp/maps"
 )
 
@@ -101,6 +105,10 @@ const (
 	// we switch to using the TTL specified by the servers.
 	initialHeartbeatStagger = 10 * time.Second
 
+	// nodeStatusUpdateInterval is how often the client sends a full status
+	// update, which refreshes its server list, instead of a light heartbeat.
+	nodeStatusUpdateInterval = 1 * time.Minute
+
 	// nodeUpdateRetryIntv is how often the client checks for updates to the
 	// node attributes or meta map.
 	nodeUpdateRetryIntv = 5 * time.Second
@@ -162,6 +170,14 @@ type Client struct {
 	config      *config.Config
 	metaDynamic map[string]*string // dynamic node metadata
 
+	// metaInfo holds the writer and expiration of the dynamic node metadata
+	// keys that are set, and is guarded by configLock.
+	metaInfo map[string]*structs.NodeMetaInfo
+
+	// metaExpiryCh is used to wake up the expiration of the dynamic node
+	// metadata when it is updated.
+	metaExpiryCh chan struct{}
+
 	// metaStatic are the Node's static metadata set via the agent configuration
 	// and defaults during client initialization. Since this map is never updated
 	// at runtime it may be accessed outside of locks.
@@ -186,6 +202,19 @@ type Client struct {
 	heartbeatLock   sync.Mutex
 	heartbeatStop   *heartbeatStop
 
+	// lastStatusUpdate and lastUtilization track the last full status update
+	// to decide when heartbeats can't be light, and lightHeartbeatsDisabled
+	// is set when the servers don't support them.
+	lastStatusUpdate        time.Time
+	lastUtilization         *structs.NodeUtilization
+	lightHeartbeatsDisabled bool
+
+	// clockSkew is the clock skew with the servers estimated from the last
+	// heartbeat, and clockSkewed is set while it exceeds the threshold.
+	clockSkew     time.Duration
+	clockSkewed   bool
+	clockSkewLock sync.RWMutex
+
 	// triggerDiscoveryCh triggers Consul discovery; see triggerDiscovery
 	triggerDiscoveryCh chan struct{}
 
@@ -208,6 +237,11 @@ type Client struct {
 	allocs    map[string]interfaces.AllocRunner
 	allocLock sync.RWMutex
 
+	// staticAllocs is the set of allocations of static jobs that run until
+	// the servers place allocations for the same jobs. It is guarded by
+	// allocLock.
+	staticAllocs map[string]struct{}
+
 	// allocrunnerFactory is the function called to create new allocrunners
 	allocrunnerFactory config.AllocRunnerFactory
 
@@ -217,6 +251,10 @@ type Client struct {
 	invalidAllocs     map[string]struct{}
 	invalidAllocsLock sync.Mutex
 
+	// migrationGrants are the one-time tokens other clients can snapshot
+	// allocations with to migrate their data.
+	migrationGrants *migrationGrants
+
 	// pendingUpdates stores allocations that need to be synced to the server.
 	pendingUpdates *pendingClientUpdates
 
@@ -338,6 +376,21 @@ type Client struct {
 
 	// widsigner signs workload identities
 	widsigner widmgr.IdentitySigner
+
+	// identityRenewals limits the workload identity renewals of all the
+	// allocations running in parallel
+	identityRenewals *widmgr.RenewalLimiter
+
+	// allocHookPlugins are the clients of the external alloc hook plugins
+	allocHookPlugins []*allochook.Client
+
+	// secretsKeyring wraps the keys of the encrypted secrets dirs of the
+	// tasks, if they are encrypted
+	secretsKeyring *allocdir.SecretsKeyring
+
+	// allocDirRoots selects the root of the alloc dir of each allocation
+	// among the alloc dir roots of the client
+	allocDirRoots *allocdir.Roots
 }
 
 var (
@@ -367,7 +420,11 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulProxie
 	}
 
 	if cfg.StateDBFactory == nil {
-		cfg.StateDBFactory = state.GetStateDBFactory(cfg.DevMode)
+		factory, err := state.GetStateStoreFactory(cfg.StateStore, cfg.DevMode)
+		if err != nil {
+			return nil, err
+		}
+		cfg.StateDBFactory = factory
 	}
 
 	// Create the logger
@@ -386,18 +443,21 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulProxie
 		logger:               logger,
 		rpcLogger:            logger.Named("rpc"),
 		allocs:               make(map[string]interfaces.AllocRunner),
+		staticAllocs:         make(map[string]struct{}),
 		pendingUpdates:       newPendingClientUpdates(),
 		shutdownCh:           make(chan struct{}),
 		triggerDiscoveryCh:   make(chan struct{}),
+		metaExpiryCh:         make(chan struct{}, 1),
 		triggerNodeUpdate:    make(chan struct{}, 8),
 		triggerEmitNodeEvent: make(chan *structs.NodeEvent, 8),
 		fpInitialized:        make(chan struct{}),
 		invalidAllocs:        make(map[string]struct{}),
+		migrationGrants:      newMigrationGrants(),
 		serversContactedCh:   make(chan struct{}),
 		serversContactedOnce: sync.Once{},
 		registeredCh:         make(chan struct{}),
 		registeredOnce:       sync.Once{},
-		getter:               getter.New(cfg.Artifact, logger),
+		getter:               getter.New(cfg.Artifact, cfg.DNSResolver, logger),
 		EnterpriseClient:     newEnterpriseClient(logger),
 		allocrunnerFactory:   cfg.AllocRunnerFactory,
 	}
@@ -446,12 +506,16 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulProxie
 		return nil, fmt.Errorf("node setup failed: %v", err)
 	}
 
-	// Add workload identity signer after node secret has been generated/loaded
-	c.widsigner = widmgr.NewSigner(widmgr.SignerConfig{
-		NodeSecret: c.secretNodeID(),
-		Region:     cfg.Region,
-		RPC:        c,
+	// Add workload identity signer after node secret has been generated/loaded.
+	// Signing requests of all allocs are coalesced into batched RPCs.
+	c.widsigner = widmgr.NewSigningBroker(widmgr.SigningBrokerConfig{
+		Signer: widmgr.NewSigner(widmgr.SignerConfig{
+			NodeSecret: c.secretNodeID(),
+			Region:     cfg.Region,
+			RPC:        c,
+		}),
 	})
+	c.identityRenewals = widmgr.NewRenewalLimiter(cfg.IdentityMaxParallelRenewals)
 
 	c.fingerprintManager = NewFingerprintManager(
 		cfg.PluginSingletonLoader,
@@ -482,6 +546,24 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulProxie
 	})
 	c.wranglers = wranglers
 
+	// Set up the clients of the alloc hook plugins
+	for _, conf := range cfg.AllocHookPlugins {
+		plugin, err := allochook.NewClient(conf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup alloc hook plugin: %v", err)
+		}
+		c.allocHookPlugins = append(c.allocHookPlugins, plugin)
+	}
+
+	// Set up the keyring of the encrypted secrets dirs
+	if cfg.EncryptSecretsDir {
+		keyring, err := allocdir.NewSecretsKeyring(filepath.Join(cfg.StateDir, "secrets-keyring.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup secrets keyring: %v", err)
+		}
+		c.secretsKeyring = keyring
+	}
+
 	// Build the allow/denylists of drivers.
 	// COMPAT(1.0) uses inclusive language. white/blacklist are there for backward compatible reasons only.
 	allowlistDrivers := cfg.ReadStringListToMap("driver.allowlist", "driver.whitelist")
@@ -609,9 +691,20 @@ func NewClient(cfg *config.Config, consulCatalog consul.CatalogAPI, consulProxie
 		return nil, fmt.Errorf("failed to restore state")
 	}
 
+	// Run the static jobs before the servers are contacted.
+	c.startStaticJobs()
+
 	// Begin periodic snapshotting of state.
 	c.shutdownGroup.Go(c.periodicSnapshot)
 
+	// Start expiring the dynamic node metadata
+	c.shutdownGroup.Go(c.expireNodeMeta)
+
+	// Start rotating the root key of the secrets keyring
+	if c.secretsKeyring != nil {
+		c.shutdownGroup.Go(c.rotateSecretsKeyring)
+	}
+
 	// Begin syncing allocations to the server
 	c.shutdownGroup.Go(c.allocSync)
 
@@ -705,7 +798,19 @@ func (c *Client) init() error {
 		})
 	}
 
+	// Ensure the additional alloc dir roots exist
+	for _, root := range conf.AllocDirs {
+		if err := os.MkdirAll(root, 0711); err != nil {
+			return fmt.Errorf("failed creating alloc dir %q: %s", root, err)
+		}
+	}
+	c.allocDirRoots = allocdir.NewRoots(conf.AllocDirRoots(), conf.AllocDirPlacement)
+
 	c.logger.Info("using alloc directory", "alloc_dir", conf.AllocDir)
+	if len(conf.AllocDirs) > 0 {
+		c.logger.Info("using additional alloc directories",
+			"alloc_dirs", conf.AllocDirs, "placement", conf.AllocDirPlacement)
+	}
 
 	reserved := "<none>"
 	if conf.Node != nil && conf.Node.ReservedResources != nil {
@@ -908,6 +1013,11 @@ func (c *Client) Shutdown() error {
 	// Must close connection pool to unblock alloc watcher
 	c.connPool.Shutdown()
 
+	// Close the connections to the alloc hook plugins
+	for _, plugin := range c.allocHookPlugins {
+		plugin.Close()
+	}
+
 	// Wait for goroutines to stop
 	c.shutdownGroup.Wait()
 
@@ -1093,6 +1203,12 @@ func (c *Client) computeAllocatedDeviceGroupStats(devices []*structs.AllocatedDe
 // allocation, and has been created by a trusted party that has privileged
 // knowledge of the client's secret identifier
 func (c *Client) ValidateMigrateToken(allocID, migrateToken string) bool {
+	// The one-time tokens of the migrations introduced by the servers are
+	// accepted whether or not ACLs are enabled.
+	if c.migrationGrants.consume(migrateToken, allocID) {
+		return true
+	}
+
 	conf := c.GetConfig()
 	if !conf.ACLEnabled {
 		return true
@@ -1600,6 +1716,10 @@ func (c *Client) setupNode() error {
 		return fmt.Errorf("error syncing dynamic node metadata: %w", err)
 	}
 
+	if err := c.restoreNodeMetaInfo(); err != nil {
+		return err
+	}
+
 	c.config = newConfig
 	return nil
 }
@@ -1746,7 +1866,7 @@ func (c *Client) registerAndHeartbeat() {
 		case <-c.shutdownCh:
 			return
 		}
-		if err := c.updateNodeStatus(); err != nil {
+		if err := c.heartbeat(); err != nil {
 			// The servers have changed such that this node has not been
 			// registered before
 			if strings.Contains(err.Error(), "node not found") {
@@ -1774,6 +1894,18 @@ func (c *Client) lastHeartbeat() time.Time {
 	return c.heartbeatStop.getLastOk()
 }
 
+// disconnected returns true if the client missed its heartbeat TTL, so the
+// servers may consider it disconnected.
+func (c *Client) disconnected() bool {
+	c.heartbeatLock.Lock()
+	haveHeartbeated := c.haveHeartbeated
+	last := c.lastHeartbeat()
+	ttl := c.heartbeatTTL
+	c.heartbeatLock.Unlock()
+
+	return haveHeartbeated && time.Since(last) > ttl
+}
+
 // getHeartbeatRetryIntv is used to retrieve the time to wait before attempting
 // another heartbeat.
 func (c *Client) getHeartbeatRetryIntv(err error) time.Duration {
@@ -2050,15 +2182,117 @@ func (c *Client) registerNode(authToken string) error {
 	c.heartbeatStop.setLastOk(time.Now())
 	c.heartbeatTTL = resp.HeartbeatTTL
 
+	// The servers may have changed, so send a full status update next.
+	c.lastStatusUpdate = time.Time{}
+	c.lightHeartbeatsDisabled = false
+
 	return nil
 }
 
+// nodeUtilization returns the recent utilization of the host sent in
+// heartbeats for load-aware scoring and display, or nil if it can't be
+// collected.
+func (c *Client) nodeUtilization() *structs.NodeUtilization {
+	avg, err := load.Avg()
+	if err != nil {
+		c.logger.Trace("failed to collect load average", "error", err)
+		return nil
+	}
+	vm, err := mem.VirtualMemory()
+	if err != nil || vm.Total == 0 {
+		c.logger.Trace("failed to collect memory stats", "error", err)
+		return nil
+	}
+
+	utilization := &structs.NodeUtilization{
+		CPULoad:        avg.Load1 / float64(runtime.NumCPU()),
+		MemoryPressure: float64(vm.Total-vm.Available) / float64(vm.Total),
+	}
+
+	// Summarize the resources used on the host from the last collected host
+	// stats if the client opted into reporting them.
+	if c.GetConfig().ReportResourceUsage {
+		if hStats := c.hostStatsCollector.Stats(); hStats != nil {
+			utilization.CPUUsedMHz = int64(hStats.CPUTicksConsumed)
+			if hStats.Memory != nil {
+				utilization.MemoryUsedMB = int64(hStats.Memory.Used / MB)
+			}
+			if hStats.AllocDirStats != nil {
+				utilization.DiskUsedMB = int64(hStats.AllocDirStats.Used / MB)
+			}
+		}
+	}
+
+	return utilization
+}
+
+// heartbeat renews the heartbeat TTL of the node with a light heartbeat, or
+// with a full status update when the node status, its server list or the
+// utilization it reported need to be updated.
+func (c *Client) heartbeat() error {
+	utilization := c.nodeUtilization()
+	now := time.Now()
+
+	c.heartbeatLock.Lock()
+	fullUpdate := !c.haveHeartbeated || c.lightHeartbeatsDisabled ||
+		now.Sub(c.lastStatusUpdate) >= nodeStatusUpdateInterval ||
+		c.lastUtilization.NeedsUpdate(utilization, now)
+	c.heartbeatLock.Unlock()
+
+	if !fullUpdate {
+		statusUpdateRequired, err := c.lightHeartbeat()
+		switch {
+		case err != nil && strings.Contains(err.Error(), "node not found"):
+			return err
+		case err != nil:
+			c.logger.Debug("light heartbeat failed, updating node status", "error", err)
+			if strings.Contains(err.Error(), "can't find method") {
+				c.heartbeatLock.Lock()
+				c.lightHeartbeatsDisabled = true
+				c.heartbeatLock.Unlock()
+			}
+		case !statusUpdateRequired:
+			return nil
+		}
+	}
+	return c.updateNodeStatus(utilization)
+}
+
+// lightHeartbeat renews the heartbeat TTL of the node without updating its
+// status, and returns true if the servers require a status update instead.
+func (c *Client) lightHeartbeat() (bool, error) {
+	req := structs.NodeHeartbeatRequest{
+		NodeID: c.NodeID(),
+		WriteRequest: structs.WriteRequest{
+			Region:    c.Region(),
+			AuthToken: c.secretNodeID(),
+		},
+	}
+	start := time.Now()
+	var resp structs.NodeHeartbeatResponse
+	if err := c.RPC("Node.Heartbeat", &req, &resp); err != nil {
+		return false, err
+	}
+	c.updateClockSkew(start, time.Now(), resp.ServerTime)
+	if resp.StatusUpdateRequired {
+		return true, nil
+	}
+
+	c.heartbeatLock.Lock()
+	c.heartbeatStop.setLastOk(time.Now())
+	c.heartbeatTTL = resp.HeartbeatTTL
+	c.heartbeatLock.Unlock()
+	c.logger.Trace("next heartbeat", "period", resp.HeartbeatTTL)
+	return false, nil
+}
+
 // updateNodeStatus is used to heartbeat and update the status of the node
-func (c *Client) updateNodeStatus() error {
+func (c *Client) updateNodeStatus(utilization *structs.NodeUtilization) error {
 	start := time.Now()
 	req := structs.NodeUpdateStatusRequest{
-		NodeID: c.NodeID(),
-		Status: structs.NodeStatusReady,
+		NodeID:      c.NodeID(),
+		Status:      structs.NodeStatusReady,
+		Utilization: utilization,
 		WriteRequest: structs.WriteRequest{
 			Region:    c.Region(),
 			AuthToken: c.secretNodeID(),
@@ -2070,6 +2304,7 @@ func (c *Client) updateNodeStatus() error {
 		return fmt.Errorf("failed to update status: %v", err)
 	}
 	end := time.Now()
+	c.updateClockSkew(start, end, resp.ServerTime)
 
 	if len(resp.EvalIDs) != 0 {
 		c.logger.Debug("evaluations triggered by node update", "num_evals", len(resp.EvalIDs))
@@ -2083,6 +2318,11 @@ func (c *Client) updateNodeStatus() error {
 	c.heartbeatStop.setLastOk(time.Now())
 	c.heartbeatTTL = resp.HeartbeatTTL
 	c.haveHeartbeated = true
+	c.lastStatusUpdate = end
+	if utilization != nil {
+		c.lastUtilization = utilization.Copy()
+		c.lastUtilization.UpdatedAt = end.UnixNano()
+	}
 	c.heartbeatLock.Unlock()
 	c.logger.Trace("next heartbeat", "period", resp.HeartbeatTTL)
 
@@ -2161,6 +2401,11 @@ func (c *Client) AllocStateUpdated(alloc *structs.Allocation) {
 		}
 	}
 
+	// The servers don't know about the allocations of static jobs.
+	if c.isStaticAlloc(alloc.ID) {
+		return
+	}
+
 	// Strip all the information that can be reconstructed at the server.  Only
 	// send the fields that are updatable by the client.
 	stripped := new(structs.Allocation)
@@ -2528,6 +2773,9 @@ func (c *Client) runAllocs(update *allocUpdates) {
 	c.allocLock.RLock()
 	existing := make(map[string]uint64, len(c.allocs))
 	for id, ar := range c.allocs {
+		if _, ok := c.staticAllocs[id]; ok {
+			continue
+		}
 		existing[id] = ar.Alloc().AllocModifyIndex
 	}
 	c.allocLock.RUnlock()
@@ -2569,6 +2817,9 @@ func (c *Client) runAllocs(update *allocUpdates) {
 		}
 	}
 
+	// Stop the static job allocations the servers have replaced.
+	c.reconcileStaticAllocs()
+
 	// Mark servers as having been contacted so blocked tasks that failed
 	// to restore can now restart.
 	c.serversContactedOnce.Do(func() {
@@ -2698,6 +2949,10 @@ func (c *Client) addAlloc(alloc *structs.Allocation, migrateToken string) error
 		return nil
 	}
 
+	if err := c.checkClockSkew(alloc); err != nil {
+		return err
+	}
+
 	// Initialize local copy of alloc before creating the alloc runner so
 	// we can't end up with an alloc runner that does not have an alloc.
 	if err := c.stateDB.PutAllocation(alloc); err != nil {
@@ -2749,30 +3004,40 @@ func (c *Client) newAllocRunnerConfig(
 	prevAllocWatcher config.PrevAllocWatcher,
 	prevAllocMigrator config.PrevAllocMigrator,
 ) *config.AllocRunnerConfig {
+	var allocDirRoot string
+	if c.allocDirRoots != nil {
+		allocDirRoot = c.allocDirRoots.Select(alloc)
+	}
+
 	return &config.AllocRunnerConfig{
-		Alloc:               alloc,
-		CSIManager:          c.csimanager,
-		CheckStore:          c.checkStore,
-		ClientConfig:        c.GetConfig(),
-		Consul:              c.consulServices,
-		ConsulProxiesFunc:   c.consulProxiesFunc,
-		ConsulSI:            c.tokensClient,
-		DeviceManager:       c.devicemanager,
-		DeviceStatsReporter: c,
-		DriverManager:       c.drivermanager,
-		DynamicRegistry:     c.dynamicRegistry,
-		Getter:              c.getter,
-		Logger:              c.logger,
-		PrevAllocMigrator:   prevAllocMigrator,
-		PrevAllocWatcher:    prevAllocWatcher,
-		RPCClient:           c,
-		ServiceRegWrapper:   c.serviceRegWrapper,
-		StateDB:             c.stateDB,
-		StateUpdater:        c,
-		VaultFunc:           c.VaultClient,
-		WIDSigner:           c.widsigner,
-		Wranglers:           c.wranglers,
-		Partitions:          c.partitions,
+		Alloc:                  alloc,
+		AllocDirRoot:           allocDirRoot,
+		CSIManager:             c.csimanager,
+		CheckStore:             c.checkStore,
+		ClientConfig:           c.GetConfig(),
+		Consul:                 c.consulServices,
+		ConsulProxiesFunc:      c.consulProxiesFunc,
+		ConsulSI:               c.tokensClient,
+		DeviceManager:          c.devicemanager,
+		DeviceStatsReporter:    c,
+		DriverManager:          c.drivermanager,
+		DynamicRegistry:        c.dynamicRegistry,
+		Getter:                 c.getter,
+		Logger:                 c.logger,
+		PrevAllocMigrator:      prevAllocMigrator,
+		PrevAllocWatcher:       prevAllocWatcher,
+		RPCClient:              c,
+		ServiceRegWrapper:      c.serviceRegWrapper,
+		StateDB:                c.stateDB,
+		StateUpdater:           c,
+		VaultFunc:              c.VaultClient,
+		WIDSigner:              c.widsigner,
+		IdentityRenewalLimiter: c.identityRenewals,
+		Wranglers:              c.wranglers,
+		Partitions:             c.partitions,
+		ClientDisconnectedFunc: c.disconnected,
+		AllocHookPlugins:       c.allocHookPlugins,
+		SecretsKeyring:         c.secretsKeyring,
 	}
 }
 
@@ -3310,6 +3575,9 @@ func (c *Client) emitClientMetrics() {
 	metrics.SetGaugeWithLabels([]string{"client", "allocations", "pending"}, float32(pending), labels)
 	metrics.SetGaugeWithLabels([]string{"client", "allocations", "running"}, float32(running), labels)
 	metrics.SetGaugeWithLabels([]string{"client", "allocations", "terminal"}, float32(terminal), labels)
+
+	skew := c.ClockSkew()
+	metrics.SetGaugeWithLabels([]string{"client", "clock_skew"}, float32(skew.Milliseconds()), labels)
 }
 
 // labels takes the base labels and appends the node state
@@ -138,6 +138,11 @@ func (ar *emptyAllocRunner) RestartTask(taskName string, taskEvent *structs.Task
 }
 func (ar *emptyAllocRunner) RestartRunning(taskEvent *structs.TaskEvent) error { return nil }
 func (ar *emptyAllocRunner) RestartAll(taskEvent *structs.TaskEvent) error     { return nil }
+func (ar *emptyAllocRunner) RunningHooks() []*cstructs.RunningHook             { return nil }
+func (ar *emptyAllocRunner) OverrideHook(taskName, hookName, action string) error {
+	return nil
+}
+func (ar *emptyAllocRunner) SignedIdentities() []*structs.SignedWorkloadIdentity { return nil }
 
 func (ar *emptyAllocRunner) GetTaskEventHandler(taskName string) drivermanager.EventHandler {
 	return nil
@@ -15,6 +15,7 @@ import (
 
 	memdb "github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/allocrunner"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	trstate "github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
@@ -916,6 +917,28 @@ func TestClient_SaveRestoreState(t *testing.T) {
 
 }
 
+func TestClient_AllocDirRoots(t *testing.T) {
+	ci.Parallel(t)
+
+	roots := []string{
+		filepath.Join(t.TempDir(), "disk1"),
+		filepath.Join(t.TempDir(), "disk2"),
+	}
+	c, cleanup := TestClient(t, func(c *config.Config) {
+		c.AllocDirs = roots
+		c.AllocDirPlacement = allocdir.RootPlacementRoundRobin
+	})
+	defer cleanup()
+
+	// The roots are created and the alloc dirs are placed in each in turn
+	for _, root := range roots {
+		must.DirExists(t, root)
+	}
+	must.Eq(t, c.GetConfig().AllocDir, c.newAllocRunnerConfig(mock.Alloc(), nil, nil).AllocDirRoot)
+	must.Eq(t, roots[0], c.newAllocRunnerConfig(mock.Alloc(), nil, nil).AllocDirRoot)
+	must.Eq(t, roots[1], c.newAllocRunnerConfig(mock.Alloc(), nil, nil).AllocDirRoot)
+}
+
 func TestClient_AddAllocError(t *testing.T) {
 	ci.Parallel(t)
 	require := require.New(t)
@@ -1171,6 +1194,45 @@ func TestClient_ValidateMigrateToken_ACLDisabled(t *testing.T) {
 	assert.Equal(c.ValidateMigrateToken("", ""), true)
 }
 
+func TestClient_ValidateMigrateToken_Authorized(t *testing.T) {
+	ci.Parallel(t)
+
+	c, cleanup := TestClient(t, func(c *config.Config) {
+		c.ACLEnabled = true
+	})
+	defer cleanup()
+
+	alloc := mock.Alloc()
+	alloc.Job.TaskGroups[0].Tasks[0].Driver = "mock_driver"
+	alloc.Job.TaskGroups[0].Tasks[0].Config = map[string]interface{}{
+		"run_for": "10s",
+	}
+	must.NoError(t, c.addAlloc(alloc, ""))
+
+	// Migrations can't be authorized for unknown allocations
+	req := &structs.AllocMigrationAuthorizeRequest{
+		PrevAllocID: uuid.Generate(),
+		Token:       uuid.Generate(),
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	var resp structs.GenericResponse
+	must.Error(t, c.ClientRPC("Allocations.AuthorizeMigration", req, &resp))
+
+	req.PrevAllocID = alloc.ID
+	must.NoError(t, c.ClientRPC("Allocations.AuthorizeMigration", req, &resp))
+
+	// The token is only valid for the allocation, and only once
+	must.False(t, c.ValidateMigrateToken(uuid.Generate(), req.Token))
+	must.True(t, c.ValidateMigrateToken(alloc.ID, req.Token))
+	must.False(t, c.ValidateMigrateToken(alloc.ID, req.Token))
+
+	// Expired tokens are rejected
+	req.Token = uuid.Generate()
+	req.ExpiresAt = time.Now().Add(-time.Second)
+	must.NoError(t, c.ClientRPC("Allocations.AuthorizeMigration", req, &resp))
+	must.False(t, c.ValidateMigrateToken(alloc.ID, req.Token))
+}
+
 func TestClient_ReloadTLS_UpgradePlaintextToTLS(t *testing.T) {
 	ci.Parallel(t)
 	assert := assert.New(t)
@@ -2140,3 +2202,22 @@ func TestClient_AllocPrerunErrorDuringRestore(t *testing.T) {
 	must.Eq(t, expectEvents, actual)
 	test.StrContains(t, ts.Events[3].DisplayMessage, allocrunner.ErrFailHookError.Error())
 }
+
+func TestClient_nodeUtilization_ResourceUsage(t *testing.T) {
+	ci.Parallel(t)
+
+	client, cleanup := TestClient(t, nil)
+	defer cleanup()
+
+	// The resource usage is only reported when opted into.
+	utilization := client.nodeUtilization()
+	must.NotNil(t, utilization)
+	must.False(t, utilization.HasResourceUsage())
+
+	client.UpdateConfig(func(c *config.Config) {
+		c.ReportResourceUsage = true
+	})
+	utilization = client.nodeUtilization()
+	must.NotNil(t, utilization)
+	must.Positive(t, utilization.MemoryUsedMB)
+}
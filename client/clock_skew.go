@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// estimateClockSkew returns the skew of the clock of a server relative to the
+// clock of the client, from the time the server returned in the response to a
+// request sent at start and answered at end. The skew is positive when the
+// clock of the client is behind. The server handled the request at some point
+// between start and end, so only the skew beyond the round trip is measured.
+func estimateClockSkew(start, end time.Time, serverTime int64) time.Duration {
+	switch {
+	case serverTime > end.UnixNano():
+		return time.Duration(serverTime - end.UnixNano())
+	case serverTime < start.UnixNano():
+		return time.Duration(serverTime - start.UnixNano())
+	default:
+		return 0
+	}
+}
+
+// updateClockSkew records the clock skew estimated from a heartbeat, and
+// emits a node event when it crosses the clock skew threshold.
+func (c *Client) updateClockSkew(start, end time.Time, serverTime int64) {
+	// Servers which don't return their time don't detect clock skew
+	if serverTime == 0 {
+		return
+	}
+
+	skew := estimateClockSkew(start, end, serverTime)
+	threshold := c.GetConfig().ClockSkewThreshold
+	skewed := threshold > 0 && skew.Abs() > threshold
+
+	c.clockSkewLock.Lock()
+	c.clockSkew = skew
+	wasSkewed := c.clockSkewed
+	c.clockSkewed = skewed
+	c.clockSkewLock.Unlock()
+
+	switch {
+	case skewed && !wasSkewed:
+		c.logger.Warn("clock skew with the servers exceeds threshold, workload identities may fail to renew",
+			"skew", skew, "threshold", threshold)
+		c.triggerNodeEvent(structs.NewNodeEvent().
+			SetSubsystem(structs.NodeEventSubsystemHeartbeat).
+			SetMessage("Clock skew with the servers exceeds threshold").
+			AddDetail("skew", skew.String()).
+			AddDetail("threshold", threshold.String()))
+	case !skewed && wasSkewed:
+		c.logger.Info("clock skew with the servers is back under threshold", "skew", skew, "threshold", threshold)
+		c.triggerNodeEvent(structs.NewNodeEvent().
+			SetSubsystem(structs.NodeEventSubsystemHeartbeat).
+			SetMessage("Clock skew with the servers is back under threshold").
+			AddDetail("skew", skew.String()))
+	}
+}
+
+// ClockSkew returns the last clock skew estimated between the client and the
+// servers, which is positive when the clock of the client is behind.
+func (c *Client) ClockSkew() time.Duration {
+	c.clockSkewLock.RLock()
+	defer c.clockSkewLock.RUnlock()
+	return c.clockSkew
+}
+
+// checkClockSkew returns an error if the allocation has expiring workload
+// identities and the clock skew with the servers exceeds the max clock skew,
+// since the client would consider them expired too early or too late.
+func (c *Client) checkClockSkew(alloc *structs.Allocation) error {
+	maxSkew := c.GetConfig().MaxClockSkew
+	if maxSkew == 0 || !hasExpiringIdentities(alloc) {
+		return nil
+	}
+	if skew := c.ClockSkew(); skew.Abs() > maxSkew {
+		return fmt.Errorf("clock skew of %s with the servers exceeds the max clock skew of %s", skew, maxSkew)
+	}
+	return nil
+}
+
+// hasExpiringIdentities returns true if the tasks or services of the
+// allocation have workload identities with a TTL.
+func hasExpiringIdentities(alloc *structs.Allocation) bool {
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil {
+		return false
+	}
+
+	expiring := func(services []*structs.Service) bool {
+		for _, service := range services {
+			if service.Identity != nil && service.Identity.TTL > 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	if expiring(tg.Services) {
+		return true
+	}
+	for _, task := range tg.Tasks {
+		for _, id := range task.Identities {
+			if id.TTL > 0 {
+				return true
+			}
+		}
+		if expiring(task.Services) {
+			return true
+		}
+	}
+	return false
+}
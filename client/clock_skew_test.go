@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestClient_estimateClockSkew(t *testing.T) {
+	ci.Parallel(t)
+
+	start := time.Now()
+	end := start.Add(100 * time.Millisecond)
+
+	testCases := []struct {
+		name       string
+		serverTime time.Time
+		exp        time.Duration
+	}{
+		{
+			name:       "during round trip",
+			serverTime: start.Add(50 * time.Millisecond),
+			exp:        0,
+		},
+		{
+			name:       "client behind",
+			serverTime: end.Add(time.Minute),
+			exp:        time.Minute,
+		},
+		{
+			name:       "client ahead",
+			serverTime: start.Add(-time.Minute),
+			exp:        -time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			must.Eq(t, tc.exp, estimateClockSkew(start, end, tc.serverTime.UnixNano()))
+		})
+	}
+}
+
+func TestClient_ClockSkew(t *testing.T) {
+	ci.Parallel(t)
+
+	c, cleanup := TestClient(t, func(c *config.Config) {
+		c.ClockSkewThreshold = 5 * time.Second
+		c.MaxClockSkew = 30 * time.Second
+	})
+	defer cleanup()
+
+	alloc := mock.Alloc()
+	expiring := alloc.Copy()
+	expiring.Job.TaskGroups[0].Tasks[0].Identities = []*structs.WorkloadIdentity{
+		{Name: "vault_default", Audience: []string{"vault.io"}, TTL: time.Hour},
+	}
+
+	// Servers which don't return their time are ignored
+	now := time.Now()
+	c.updateClockSkew(now, now, 0)
+	must.Zero(t, c.ClockSkew())
+
+	// Skew under the threshold
+	c.updateClockSkew(now, now, now.Add(2*time.Second).UnixNano())
+	must.Eq(t, 2*time.Second, c.ClockSkew())
+	must.False(t, c.clockSkewed)
+
+	// Skew over the threshold but under the max
+	c.updateClockSkew(now, now, now.Add(-10*time.Second).UnixNano())
+	must.Eq(t, -10*time.Second, c.ClockSkew())
+	must.True(t, c.clockSkewed)
+	must.NoError(t, c.checkClockSkew(expiring))
+
+	// Skew over the max refuses allocations with expiring identities only
+	c.updateClockSkew(now, now, now.Add(time.Minute).UnixNano())
+	must.ErrorContains(t, c.checkClockSkew(expiring), "exceeds the max clock skew of 30s")
+	must.NoError(t, c.checkClockSkew(alloc))
+
+	// Skew back under the threshold
+	c.updateClockSkew(now, now, now.UnixNano())
+	must.False(t, c.clockSkewed)
+	must.NoError(t, c.checkClockSkew(expiring))
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/hashicorp/nomad/client/vaultclient"
 	"github.com/hashicorp/nomad/client/widmgr"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/allochook"
 )
 
 // AllocRunnerFactory returns an AllocRunner interface built from the
@@ -119,6 +120,25 @@ type AllocRunnerConfig struct {
 
 	// WIDMgr manages workload identities
 	WIDMgr widmgr.IdentityManager
+
+	// IdentityRenewalLimiter limits the renewals of the workload identities
+	// the client runs in parallel.
+	IdentityRenewalLimiter *widmgr.RenewalLimiter
+
+	// ClientDisconnectedFunc returns true while the client is disconnected
+	// from the servers.
+	ClientDisconnectedFunc func() bool
+
+	// AllocHookPlugins are the clients of the external alloc hook plugins,
+	// which are called in the lifecycle of the allocation.
+	AllocHookPlugins []*allochook.Client
+
+	// SecretsKeyring encrypts the secrets dirs of the tasks if set.
+	SecretsKeyring *allocdir.SecretsKeyring
+
+	// AllocDirRoot is the root the alloc dir is created in, among the alloc
+	// dir roots of the client. Defaults to the AllocDir of the ClientConfig.
+	AllocDirRoot string
 }
 
 // PrevAllocWatcher allows AllocRunners to wait for a previous allocation to
@@ -140,6 +160,10 @@ type PrevAllocMigrator interface {
 	// IsMigrating returns true if a concurrent caller is in Migrate
 	IsMigrating() bool
 
+	// MigrationStatus returns the progress of the migration, or nil if no
+	// data is transferred from a remote node.
+	MigrationStatus() *structs.AllocMigrationStatus
+
 	// Migrate data from previous alloc
 	Migrate(ctx context.Context, dest *allocdir.AllocDir) error
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/hashicorp/consul-template/config"
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/client/dnscache"
 	"github.com/hashicorp/nomad/client/lib/numalib"
 	"github.com/hashicorp/nomad/client/lib/numalib/hw"
 	"github.com/hashicorp/nomad/client/state"
@@ -53,6 +54,20 @@ var (
 		"java",
 	}, ",")
 
+	// DefaultUserLookupDrivers is the set of drivers whose task users are
+	// resolved with the directory service of the host when
+	// "user.directory_lookup" is enabled. These drivers run tasks as users of
+	// the host rather than users of an image.
+	DefaultUserLookupDrivers = strings.Join([]string{
+		"exec",
+		"raw_exec",
+		"java",
+	}, ",")
+
+	// DefaultUserLookupTimeout is how long resolving a task user with the
+	// directory service of the host may take before the task fails.
+	DefaultUserLookupTimeout = 5 * time.Second
+
 	// DefaultChrootEnv is a mapping of directories on the host OS to attempt to embed inside each
 	// task's chroot.
 	DefaultChrootEnv = map[string]string{
@@ -74,6 +89,14 @@ var (
 	DefaultTemplateMaxStale = 87600 * time.Hour
 
 	DefaultTemplateFunctionDenylist = []string{"plugin", "writeToFile"}
+
+	// DefaultSecretsKeyRotationPeriod is how often the root key of the
+	// secrets keyring is rotated by default.
+	DefaultSecretsKeyRotationPeriod = 30 * 24 * time.Hour
+
+	// DefaultClockSkewThreshold is the clock skew between the client and the
+	// servers above which the client emits a node event by default.
+	DefaultClockSkewThreshold = 10 * time.Second
 )
 
 // RPCHandler can be provided to the Client if there is a local server
@@ -96,9 +119,23 @@ type Config struct {
 	// StateDir is where we store our state
 	StateDir string
 
+	// StateStore is the state store used to persist the client state in the
+	// StateDir, either "boltdb" or "sqlite". Defaults to "boltdb".
+	StateStore string
+
 	// AllocDir is where we store data for allocations
 	AllocDir string
 
+	// AllocDirs are the additional roots the data of allocations may be
+	// stored in, such as on other disks, selected by the AllocDirPlacement
+	// policy.
+	AllocDirs []string
+
+	// AllocDirPlacement is the policy selecting the alloc dir root of new
+	// allocations when AllocDirs are set, either "most_free", "round_robin"
+	// or "job_type". Defaults to "most_free".
+	AllocDirPlacement string
+
 	// Logger provides a logger to the client
 	Logger log.InterceptLogger
 
@@ -227,6 +264,50 @@ type Config struct {
 	// random UUID.
 	NoHostUUID bool
 
+	// IdentityGracePeriod is how long after the workload identities of a task
+	// expire they are still served while they can't be renewed.
+	IdentityGracePeriod time.Duration
+
+	// IdentityRenewMinWait is the minimum time to wait before renewing the
+	// workload identities, which is also the base of the retry backoff. The
+	// identity manager default is used if zero.
+	IdentityRenewMinWait time.Duration
+
+	// IdentityRenewJitter is the max random delay added to the renewals of the
+	// workload identities, to spread out the renewals due at the same time.
+	IdentityRenewJitter time.Duration
+
+	// IdentityMaxParallelRenewals is the max number of workload identity
+	// renewals the client runs in parallel. It is unlimited if zero.
+	IdentityMaxParallelRenewals int
+
+	// HookTimeout is how long the alloc runner and task runner hooks may run
+	// before they fail, along with their allocation. Hooks run without a
+	// timeout if zero.
+	HookTimeout time.Duration
+
+	// HookTimeouts overrides HookTimeout for the hooks with the given names.
+	HookTimeouts map[string]time.Duration
+
+	// EncryptSecretsDir encrypts the secrets dirs of the tasks with fscrypt,
+	// using keys wrapped by the secrets keyring of the client, instead of
+	// backing them with a tmpfs.
+	EncryptSecretsDir bool
+
+	// SecretsKeyRotationPeriod is how often the root key of the secrets
+	// keyring is rotated.
+	SecretsKeyRotationPeriod time.Duration
+
+	// ClockSkewThreshold is the clock skew between the client and the servers
+	// above which the client emits a node event, since skew breaks the expiry
+	// and renewal of the workload identities.
+	ClockSkewThreshold time.Duration
+
+	// MaxClockSkew is the clock skew between the client and the servers above
+	// which the client refuses to run new allocations with expiring workload
+	// identities. It is disabled if zero.
+	MaxClockSkew time.Duration
+
 	// ACLEnabled controls if ACL enforcement and management is enabled.
 	ACLEnabled bool
 
@@ -243,6 +324,10 @@ type Config struct {
 	// DisableRemoteExec disables remote exec targeting tasks on this client
 	DisableRemoteExec bool
 
+	// ReportResourceUsage reports a summary of the resources used on the
+	// host in heartbeats, to be stored on the node.
+	ReportResourceUsage bool
+
 	// TemplateConfig includes configuration for template rendering
 	TemplateConfig *ClientTemplateConfig
 
@@ -322,6 +407,15 @@ type Config struct {
 	// used for template functions which require access to the Nomad API.
 	TemplateDialer *bufconndialer.BufConnWrapper
 
+	// TemplateConsulAddrs and TemplateVaultDialers are used by consul-template
+	// to connect to the in-process proxies shared by all template runners.
+	// They are only set if the template cache_proxy option is enabled, and
+	// are indexed by cluster name. consul-template doesn't support custom
+	// dialers for Consul, so the Consul proxies are reached through the
+	// unix:// addresses of their sockets.
+	TemplateConsulAddrs  map[string]string
+	TemplateVaultDialers map[string]*bufconndialer.BufConnWrapper
+
 	// APIListenerRegistrar allows the client to register listeners created at
 	// runtime (eg the Task API) with the agent's HTTP server. Since the agent
 	// creates the HTTP *after* the client starts, we have to use this shim to
@@ -336,8 +430,34 @@ type Config struct {
 	// Drain configuration from the agent's config file.
 	Drain *DrainConfig
 
+	// DiskQuota configuration from the agent's config file.
+	DiskQuota *DiskQuotaConfig
+
+	// DNSCache configuration from the agent's config file.
+	DNSCache *DNSCacheConfig
+
+	// DNSResolver caches the resolution of the hosts of the upstream
+	// services used by template runners and artifact downloads. It is only
+	// set if the dns_cache block is enabled.
+	DNSResolver *dnscache.Cache
+
+	// AllocHookPlugins are the external gRPC services that take part in the
+	// lifecycle of the allocations run by the client.
+	AllocHookPlugins []*structsc.AllocHookPluginConfig
+
+	// StaticJobs are the jobs the client runs locally on startup, before it
+	// connects to the servers. Their allocations are stopped once the servers
+	// place allocations of the same job and task group on the node.
+	StaticJobs []*structs.Job
+
 	// ExtraAllocHooks are run with other allocation hooks, mainly for testing.
+	// Hooks implementing interfaces.HookPriority are ordered relative to the
+	// built-in hooks, and run after them otherwise.
 	ExtraAllocHooks []interfaces.RunnerHook
+
+	// ExtraTaskHooks build hooks run with other task hooks, mainly for
+	// testing. They are ordered like ExtraAllocHooks.
+	ExtraTaskHooks []interfaces.TaskHookFactory
 }
 
 type APIListenerRegistrar interface {
@@ -365,6 +485,11 @@ type ClientTemplateConfig struct {
 	// the task directory.
 	DisableSandbox bool `hcl:"disable_file_sandbox"`
 
+	// CacheProxy routes the Consul and Vault requests of all template runners
+	// through in-process proxies that share connections to the upstream
+	// clusters and coalesce identical blocking queries.
+	CacheProxy bool `hcl:"cache_proxy"`
+
 	// This is the maximum interval to allow "stale" data. By default, only the
 	// Consul leader will respond to queries; any requests to a follower will
 	// forward to the leader. In large clusters with many requests, this is not as
@@ -470,6 +595,7 @@ func (c *ClientTemplateConfig) IsEmpty() bool {
 	}
 
 	return !c.DisableSandbox &&
+		!c.CacheProxy &&
 		c.FunctionDenylist == nil &&
 		c.FunctionBlacklist == nil &&
 		c.BlockQueryWaitTime == nil &&
@@ -758,7 +884,9 @@ func (c *Config) Copy() *Config {
 	nc := *c
 	nc.Node = nc.Node.Copy()
 	nc.Servers = slices.Clone(nc.Servers)
+	nc.AllocDirs = slices.Clone(c.AllocDirs)
 	nc.Options = maps.Clone(nc.Options)
+	nc.HookTimeouts = maps.Clone(nc.HookTimeouts)
 	nc.HostVolumes = structs.CopyMapStringClientHostVolumeConfig(nc.HostVolumes)
 	nc.ConsulConfig = c.ConsulConfig.Copy()
 	nc.ConsulConfigs = helper.DeepCopyMap(c.ConsulConfigs)
@@ -767,6 +895,8 @@ func (c *Config) Copy() *Config {
 	nc.TemplateConfig = c.TemplateConfig.Copy()
 	nc.ReservableCores = slices.Clone(c.ReservableCores)
 	nc.Artifact = c.Artifact.Copy()
+	nc.AllocHookPlugins = helper.CopySlice(c.AllocHookPlugins)
+	nc.StaticJobs = helper.CopySlice(c.StaticJobs)
 	return &nc
 }
 
@@ -813,6 +943,9 @@ func DefaultConfig() *Config {
 		CgroupParent:       "nomad.slice", // SETH todo
 		MaxDynamicPort:     structs.DefaultMinDynamicPort,
 		MinDynamicPort:     structs.DefaultMaxDynamicPort,
+
+		SecretsKeyRotationPeriod: DefaultSecretsKeyRotationPeriod,
+		ClockSkewThreshold:       DefaultClockSkewThreshold,
 	}
 
 	cfg.ConsulConfigs = map[string]*structsc.ConsulConfig{
@@ -823,6 +956,26 @@ func DefaultConfig() *Config {
 	return cfg
 }
 
+// HookTimeoutFor returns the timeout of the hook with the given name.
+func (c *Config) HookTimeoutFor(name string) time.Duration {
+	if timeout, ok := c.HookTimeouts[name]; ok {
+		return timeout
+	}
+	return c.HookTimeout
+}
+
+// AllocDirRoots returns all the roots the data of allocations may be stored
+// in, starting with the AllocDir.
+func (c *Config) AllocDirRoots() []string {
+	roots := []string{c.AllocDir}
+	for _, root := range c.AllocDirs {
+		if !slices.Contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
 // Read returns the specified configuration value or "".
 func (c *Config) Read(id string) string {
 	return c.Options[id]
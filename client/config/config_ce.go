@@ -12,18 +12,28 @@ import (
 	structsc "github.com/hashicorp/nomad/nomad/structs/config"
 )
 
-// GetVaultConfigs returns the set of Vault configurations available for this
-// client. In Nomad CE we only use the default Vault.
+// GetVaultConfigs returns the set of enabled Vault configurations available
+// for this client, keyed by cluster name.
 func (c *Config) GetVaultConfigs(logger hclog.Logger) map[string]*structsc.VaultConfig {
-	if c.VaultConfig == nil || !c.VaultConfig.IsEnabled() {
-		return nil
+	if len(c.VaultConfigs) == 0 {
+		if c.VaultConfig == nil || !c.VaultConfig.IsEnabled() {
+			return nil
+		}
+		return map[string]*structsc.VaultConfig{structs.VaultDefaultCluster: c.VaultConfig}
 	}
 
-	if len(c.VaultConfigs) > 1 {
-		logger.Warn("multiple Vault configurations are only supported in Nomad Enterprise")
+	configs := make(map[string]*structsc.VaultConfig, len(c.VaultConfigs))
+	for name, conf := range c.VaultConfigs {
+		if conf == nil || !conf.IsEnabled() {
+			continue
+		}
+		configs[name] = conf
+	}
+	if len(configs) == 0 {
+		return nil
 	}
 
-	return map[string]*structsc.VaultConfig{structs.VaultDefaultCluster: c.VaultConfig}
+	return configs
 }
 
 // GetConsulConfigs returns the set of Consul configurations the fingerprint needs
@@ -0,0 +1,40 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build !ent
+
+package config
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/nomad/structs"
+	structsc "github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/shoenig/test/must"
+)
+
+func TestConfig_GetVaultConfigs(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := hclog.NewNullLogger()
+
+	c := DefaultConfig()
+	must.Nil(t, c.GetVaultConfigs(logger))
+
+	c.VaultConfigs[structs.VaultDefaultCluster].Enabled = pointer.Of(true)
+	c.VaultConfigs["infra"] = &structsc.VaultConfig{
+		Name:    "infra",
+		Enabled: pointer.Of(true),
+	}
+	c.VaultConfigs["disabled"] = &structsc.VaultConfig{
+		Name:    "disabled",
+		Enabled: pointer.Of(false),
+	}
+
+	configs := c.GetVaultConfigs(logger)
+	must.MapLen(t, 2, configs)
+	must.MapContainsKeys(t, configs, []string{structs.VaultDefaultCluster, "infra"})
+}
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+const (
+	// DefaultDiskQuotaWarningThreshold is the default percentage of the
+	// ephemeral disk size after which allocations are warned.
+	DefaultDiskQuotaWarningThreshold = 90.0
+
+	// DefaultDiskQuotaCheckInterval is the default interval at which the
+	// disk usage of allocations is checked.
+	DefaultDiskQuotaCheckInterval = 30 * time.Second
+)
+
+// DiskQuotaConfig describes how the client enforces the ephemeral disk size of
+// its allocations with filesystem project quotas.
+type DiskQuotaConfig struct {
+	// Enabled enforces the ephemeral disk size of allocations with project
+	// quotas.
+	Enabled bool
+
+	// WarningThreshold is the percentage of the ephemeral disk size after
+	// which a task event warns that an allocation is approaching its quota.
+	WarningThreshold float64
+
+	// CheckInterval is the interval at which the disk usage of allocations
+	// is checked.
+	CheckInterval time.Duration
+}
+
+// DiskQuotaConfigFromAgent creates the internal read-only copy of the client
+// agent's DiskQuotaConfig.
+func DiskQuotaConfigFromAgent(c *config.DiskQuotaConfig) (*DiskQuotaConfig, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	dc := &DiskQuotaConfig{
+		WarningThreshold: DefaultDiskQuotaWarningThreshold,
+		CheckInterval:    DefaultDiskQuotaCheckInterval,
+	}
+
+	if c.Enabled != nil {
+		dc.Enabled = *c.Enabled
+	}
+	if c.WarningThreshold != nil {
+		dc.WarningThreshold = *c.WarningThreshold
+		if dc.WarningThreshold <= 0 || dc.WarningThreshold > 100 {
+			return nil, fmt.Errorf("warning_threshold must be between 0 and 100, got %v", dc.WarningThreshold)
+		}
+	}
+	if c.CheckInterval != nil {
+		var err error
+		dc.CheckInterval, err = time.ParseDuration(*c.CheckInterval)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing check_interval: %w", err)
+		}
+		if dc.CheckInterval <= 0 {
+			return nil, fmt.Errorf("check_interval must be positive, got %v", dc.CheckInterval)
+		}
+	}
+
+	return dc, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+const (
+	// DefaultDNSCacheTTL is the default duration a resolution is cached.
+	DefaultDNSCacheTTL = 30 * time.Second
+
+	// DefaultDNSCacheMaxStale is the default duration a resolution is still
+	// used after its TTL.
+	DefaultDNSCacheMaxStale = time.Hour
+)
+
+// DNSCacheConfig describes how the client caches the resolution of the hosts
+// of upstream services.
+type DNSCacheConfig struct {
+	// Enabled caches the resolution of upstream hosts.
+	Enabled bool
+
+	// TTL is how long a resolution is used before the host is resolved
+	// again.
+	TTL time.Duration
+
+	// MaxStale is how long after its TTL a resolution is still used while
+	// the host is resolved again in the background, or if the resolver
+	// fails.
+	MaxStale time.Duration
+}
+
+// DNSCacheConfigFromAgent creates the internal read-only copy of the client
+// agent's DNSCacheConfig.
+func DNSCacheConfigFromAgent(c *config.DNSCacheConfig) (*DNSCacheConfig, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	dc := &DNSCacheConfig{
+		TTL:      DefaultDNSCacheTTL,
+		MaxStale: DefaultDNSCacheMaxStale,
+	}
+
+	if c.Enabled != nil {
+		dc.Enabled = *c.Enabled
+	}
+	if c.TTL != nil {
+		var err error
+		dc.TTL, err = time.ParseDuration(*c.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ttl: %w", err)
+		}
+		if dc.TTL <= 0 {
+			return nil, fmt.Errorf("ttl must be positive, got %v", dc.TTL)
+		}
+	}
+	if c.MaxStale != nil {
+		var err error
+		dc.MaxStale, err = time.ParseDuration(*c.MaxStale)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing max_stale: %w", err)
+		}
+		if dc.MaxStale < 0 {
+			return nil, fmt.Errorf("max_stale must not be negative, got %v", dc.MaxStale)
+		}
+	}
+
+	return dc, nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package dnscache implements a cache of the resolution of the hosts of the
+// upstream services allocations depend on when starting, such as Consul,
+// Vault, and artifact sources.
+//
+// Every template runner and artifact download resolves its upstream hosts
+// independently, so an outage of the resolver of a client stalls the start of
+// every allocation on the client, even though the upstream services are
+// healthy. The cache serves resolutions for a TTL, and keeps serving expired
+// resolutions for a bounded duration while the hosts are resolved again in
+// the background, or while the resolver is failing.
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// lookupTimeout bounds the lookups made by the cache, independently of
+	// the callers waiting on them.
+	lookupTimeout = 10 * time.Second
+
+	// dialTimeout and dialKeepAlive match the dialer of the default HTTP
+	// transports.
+	dialTimeout   = 30 * time.Second
+	dialKeepAlive = 30 * time.Second
+)
+
+// Resolver resolves hosts to addresses. It is implemented by net.Resolver.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Cache caches the resolution of hosts. It is safe for concurrent use.
+type Cache struct {
+	// ttl is how long a resolution is used before the host is resolved
+	// again.
+	ttl time.Duration
+
+	// maxStale is how long after its TTL a resolution is still used.
+	maxStale time.Duration
+
+	resolver Resolver
+	dialer   *net.Dialer
+
+	// now is overridden in tests.
+	now func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]*entry
+
+	// group coalesces concurrent lookups of the same host.
+	group singleflight.Group
+
+	logger hclog.Logger
+}
+
+// entry is a cached resolution.
+type entry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// New returns a cache that uses the default resolver.
+func New(logger hclog.Logger, ttl, maxStale time.Duration) *Cache {
+	return NewWithResolver(logger, net.DefaultResolver, ttl, maxStale)
+}
+
+// NewWithResolver returns a cache that uses the given resolver.
+func NewWithResolver(logger hclog.Logger, resolver Resolver, ttl, maxStale time.Duration) *Cache {
+	return &Cache{
+		ttl:      ttl,
+		maxStale: maxStale,
+		resolver: resolver,
+		dialer: &net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: dialKeepAlive,
+		},
+		now:     time.Now,
+		entries: make(map[string]*entry),
+		logger:  logger,
+	}
+}
+
+// LookupHost returns the addresses of the host. IP addresses are returned
+// as-is. Resolutions are served from the cache until their TTL, and then for
+// up to max_stale while the host is resolved again in the background.
+func (c *Cache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	host = strings.ToLower(host)
+
+	now := c.now()
+	c.lock.Lock()
+	e := c.entries[host]
+	c.lock.Unlock()
+
+	switch {
+	case e != nil && now.Before(e.expires):
+		metrics.IncrCounter([]string{"client", "dns_cache", "hit"}, 1)
+		return e.addrs, nil
+	case e != nil && now.Before(e.expires.Add(c.maxStale)):
+		metrics.IncrCounter([]string{"client", "dns_cache", "stale"}, 1)
+		go c.refresh(host)
+		return e.addrs, nil
+	}
+
+	metrics.IncrCounter([]string{"client", "dns_cache", "miss"}, 1)
+	select {
+	case res := <-c.group.DoChan(host, func() (any, error) { return c.resolve(host) }):
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]string), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refresh resolves the host again, keeping its stale resolution if the
+// resolver fails.
+func (c *Cache) refresh(host string) {
+	_, err, _ := c.group.Do(host, func() (any, error) { return c.resolve(host) })
+	if err != nil {
+		c.logger.Warn("failed to refresh stale resolution", "host", host, "error", err)
+	}
+}
+
+// resolve looks up the host and caches its addresses. It must only be called
+// through the singleflight group.
+func (c *Cache) resolve(host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = &net.DNSError{Err: "no addresses", Name: host, IsNotFound: true}
+	}
+	if err != nil {
+		metrics.IncrCounter([]string{"client", "dns_cache", "lookup_error"}, 1)
+		return nil, err
+	}
+
+	now := c.now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Prune the resolutions that can no longer be served
+	for name, e := range c.entries {
+		if !now.Before(e.expires.Add(c.maxStale)) {
+			delete(c.entries, name)
+		}
+	}
+	c.entries[host] = &entry{addrs: addrs, expires: now.Add(c.ttl)}
+
+	return addrs, nil
+}
+
+// DialContext connects to the address on the named network, resolving the
+// host of TCP addresses with the cache. Each resolved address is tried in
+// order until one succeeds.
+func (c *Cache) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return c.dialer.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := c.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs error
+	for _, addr := range addrs {
+		conn, err := c.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = errors.Join(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errs
+}
+
+// Dial connects to the address on the named network. See DialContext.
+func (c *Cache) Dial(network, address string) (net.Conn, error) {
+	return c.DialContext(context.Background(), network, address)
+}
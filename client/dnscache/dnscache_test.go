@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+// mockResolver is a Resolver that counts its lookups.
+type mockResolver struct {
+	lock    sync.Mutex
+	addrs   map[string][]string
+	err     error
+	lookups int
+}
+
+func (m *mockResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.lookups++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.addrs[host], nil
+}
+
+func (m *mockResolver) set(addrs map[string][]string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.addrs = addrs
+	m.err = err
+}
+
+func (m *mockResolver) count() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.lookups
+}
+
+func TestCache_LookupHost(t *testing.T) {
+	ci.Parallel(t)
+
+	resolver := &mockResolver{addrs: map[string][]string{
+		"consul.example.com": {"10.0.0.1", "10.0.0.2"},
+	}}
+	cache := NewWithResolver(testlog.HCLogger(t), resolver, time.Minute, time.Hour)
+	now := time.Now()
+	var nowLock sync.Mutex
+	cache.now = func() time.Time {
+		nowLock.Lock()
+		defer nowLock.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		nowLock.Lock()
+		defer nowLock.Unlock()
+		now = now.Add(d)
+	}
+	ctx := context.Background()
+
+	// IP addresses are not resolved
+	addrs, err := cache.LookupHost(ctx, "192.168.1.1")
+	must.NoError(t, err)
+	must.Eq(t, []string{"192.168.1.1"}, addrs)
+	must.Zero(t, resolver.count())
+
+	// A miss is resolved, and then served from the cache
+	addrs, err = cache.LookupHost(ctx, "consul.example.com")
+	must.NoError(t, err)
+	must.Eq(t, []string{"10.0.0.1", "10.0.0.2"}, addrs)
+	addrs, err = cache.LookupHost(ctx, "CONSUL.example.com")
+	must.NoError(t, err)
+	must.Eq(t, []string{"10.0.0.1", "10.0.0.2"}, addrs)
+	must.Eq(t, 1, resolver.count())
+
+	// An expired resolution is still served while the resolver fails
+	resolver.set(nil, errors.New("resolver unavailable"))
+	advance(2 * time.Minute)
+	addrs, err = cache.LookupHost(ctx, "consul.example.com")
+	must.NoError(t, err)
+	must.Eq(t, []string{"10.0.0.1", "10.0.0.2"}, addrs)
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool { return resolver.count() == 2 }),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	// An expired resolution is refreshed in the background once the
+	// resolver recovers
+	resolver.set(map[string][]string{"consul.example.com": {"10.0.0.3"}}, nil)
+	_, err = cache.LookupHost(ctx, "consul.example.com")
+	must.NoError(t, err)
+	must.Wait(t, wait.InitialSuccess(
+		wait.ErrorFunc(func() error {
+			addrs, err := cache.LookupHost(ctx, "consul.example.com")
+			if err != nil {
+				return err
+			}
+			if len(addrs) != 1 || addrs[0] != "10.0.0.3" {
+				return errors.New("resolution not refreshed")
+			}
+			return nil
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	// A resolution past max_stale is no longer served
+	resolver.set(nil, errors.New("resolver unavailable"))
+	advance(2 * time.Hour)
+	_, err = cache.LookupHost(ctx, "consul.example.com")
+	must.ErrorContains(t, err, "resolver unavailable")
+
+	// An empty resolution is an error
+	resolver.set(map[string][]string{}, nil)
+	_, err = cache.LookupHost(ctx, "vault.example.com")
+	must.ErrorContains(t, err, "no addresses")
+}
+
+func TestCache_DialContext(t *testing.T) {
+	ci.Parallel(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	must.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, err := net.SplitHostPort(ln.Addr().String())
+	must.NoError(t, err)
+
+	// Nothing listens on the first address, so the second one is used
+	resolver := &mockResolver{addrs: map[string][]string{
+		"vault.example.com": {"127.0.0.2", "127.0.0.1"},
+	}}
+	cache := NewWithResolver(testlog.HCLogger(t), resolver, time.Minute, time.Hour)
+
+	conn, err := cache.DialContext(context.Background(), "tcp", net.JoinHostPort("vault.example.com", port))
+	must.NoError(t, err)
+	must.Eq(t, ln.Addr().String(), conn.RemoteAddr().String())
+	conn.Close()
+
+	// Resolver errors are returned
+	resolver.set(nil, errors.New("resolver unavailable"))
+	_, err = cache.Dial("tcp", net.JoinHostPort("nomad.example.com", port))
+	must.ErrorContains(t, err, "resolver unavailable")
+}
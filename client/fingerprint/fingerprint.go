@@ -44,6 +44,7 @@ var (
 		"plugins_cni": NewPluginsCNIFingerprint,
 		"signal":      NewSignalFingerprint,
 		"storage":     NewStorageFingerprint,
+		"user":        NewUserFingerprint,
 		"vault":       NewVaultFingerprint,
 	}
 
@@ -5,6 +5,7 @@ package fingerprint
 
 import (
 	"runtime"
+	"strconv"
 	"strings"
 
 	log "github.com/hashicorp/go-hclog"
@@ -47,6 +48,9 @@ func (f *HostFingerprint) Fingerprint(req *FingerprintRequest, resp *Fingerprint
 	resp.AddAttribute("kernel.arch", hostInfo.KernelArch)
 
 	resp.AddAttribute("unique.hostname", hostInfo.Hostname)
+	if hostInfo.BootTime != 0 {
+		resp.AddAttribute("unique.host.boot_time", strconv.FormatUint(hostInfo.BootTime, 10))
+	}
 	resp.Detected = true
 
 	return nil
@@ -134,8 +134,28 @@ func (f *NetworkFingerprint) Fingerprint(req *FingerprintRequest, resp *Fingerpr
 }
 
 func (f *NetworkFingerprint) createNodeNetworkResources(ifaces []net.Interface, disallowLinkLocal bool, conf *config.Config) ([]*structs.NodeNetworkResource, error) {
+	ifaceIPs := make([][]net.IP, len(ifaces))
+	for i, iface := range ifaces {
+		addrs, err := f.interfaceDetector.Addrs(&iface)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			// Find the IP Addr and the CIDR from the Address
+			switch v := (addr).(type) {
+			case *net.IPNet:
+				ifaceIPs[i] = append(ifaceIPs[i], v.IP)
+			case *net.IPAddr:
+				ifaceIPs[i] = append(ifaceIPs[i], v.IP)
+			default:
+				ifaceIPs[i] = append(ifaceIPs[i], nil)
+			}
+		}
+	}
+	matchers := hostNetworkMatchers(ifaces, ifaceIPs, conf)
+
 	nets := make([]*structs.NodeNetworkResource, 0)
-	for _, iface := range ifaces {
+	for i, iface := range ifaces {
 		speed := f.linkSpeed(iface.Name)
 		if speed == 0 {
 			speed = defaultNetworkSpeed
@@ -148,36 +168,23 @@ func (f *NetworkFingerprint) createNodeNetworkResources(ifaces []net.Interface,
 			MacAddress: iface.HardwareAddr.String(),
 			Speed:      speed,
 		}
-		addrs, err := f.interfaceDetector.Addrs(&iface)
-		if err != nil {
-			return nil, err
-		}
 		var networkAddrs, linkLocalAddrs []structs.NodeNetworkAddress
-		for _, addr := range addrs {
-			// Find the IP Addr and the CIDR from the Address
-			var ip net.IP
+		for _, ip := range ifaceIPs[i] {
 			var family structs.NodeNetworkAF
-			switch v := (addr).(type) {
-			case *net.IPNet:
-				ip = v.IP
-			case *net.IPAddr:
-				ip = v.IP
-			}
-
 			if ip.To4() != nil {
 				family = structs.NodeNetworkAF_IPv4
 			} else {
 				family = structs.NodeNetworkAF_IPv6
 			}
-			for _, alias := range deriveAddressAliases(iface, ip, conf) {
+			for _, alias := range deriveAddressAliases(iface, ip, conf, matchers) {
 				newAddr := structs.NodeNetworkAddress{
 					Address: ip.String(),
 					Family:  family,
 					Alias:   alias,
 				}
 
-				if hostNetwork, ok := conf.HostNetworks[alias]; ok {
-					newAddr.ReservedPorts = hostNetwork.ReservedPorts
+				if matcher, ok := matchers[alias]; ok {
+					newAddr.ReservedPorts = matcher.reservedPorts
 				}
 
 				if newAddr.Alias != "" {
@@ -207,37 +214,91 @@ func (f *NetworkFingerprint) createNodeNetworkResources(ifaces []net.Interface,
 	return nets, nil
 }
 
-func deriveAddressAliases(iface net.Interface, addr net.IP, config *config.Config) (aliases []string) {
-	for name, conf := range config.HostNetworks {
-		var cidrMatch, ifaceMatch bool
-		if conf.CIDR != "" {
-			for _, cidr := range strings.Split(conf.CIDR, ",") {
-				_, ipnet, err := net.ParseCIDR(cidr)
-				if err != nil {
-					continue
-				}
+// hostNetworkMatcher matches the addresses of a host network by CIDR and
+// interface.
+type hostNetworkMatcher struct {
+	cidr          string
+	iface         string
+	reservedPorts string
+}
 
-				if ipnet.Contains(addr) {
-					cidrMatch = true
-					break
-				}
-			}
-		} else {
-			cidrMatch = true
-		}
-		if conf.Interface != "" {
-			ifaceName, err := template.Parse(conf.Interface)
+// matches returns whether the address of the interface belongs to the host
+// network.
+func (m *hostNetworkMatcher) matches(iface net.Interface, addr net.IP) bool {
+	if m.cidr != "" {
+		var cidrMatch bool
+		for _, cidr := range strings.Split(m.cidr, ",") {
+			_, ipnet, err := net.ParseCIDR(cidr)
 			if err != nil {
 				continue
 			}
 
-			if ifaceName == iface.Name {
-				ifaceMatch = true
+			if ipnet.Contains(addr) {
+				cidrMatch = true
+				break
+			}
+		}
+		if !cidrMatch {
+			return false
+		}
+	}
+	if m.iface != "" {
+		ifaceName, err := template.Parse(m.iface)
+		if err != nil {
+			return false
+		}
+
+		if ifaceName != iface.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// hostNetworkMatchers returns the matcher of each host network for the
+// addresses of the node. The CIDR and interface of a host network are used
+// if they match any address of the node, otherwise its first fallback that
+// does is used.
+func hostNetworkMatchers(ifaces []net.Interface, ifaceIPs [][]net.IP, config *config.Config) map[string]*hostNetworkMatcher {
+	matchesAny := func(m *hostNetworkMatcher) bool {
+		for i, iface := range ifaces {
+			for _, ip := range ifaceIPs[i] {
+				if m.matches(iface, ip) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	matchers := make(map[string]*hostNetworkMatcher, len(config.HostNetworks))
+	for name, conf := range config.HostNetworks {
+		matcher := &hostNetworkMatcher{
+			cidr:          conf.CIDR,
+			iface:         conf.Interface,
+			reservedPorts: conf.ReservedPorts,
+		}
+		if !matchesAny(matcher) {
+			for _, fallback := range conf.Fallbacks {
+				fallbackMatcher := &hostNetworkMatcher{
+					cidr:          fallback.CIDR,
+					iface:         fallback.Interface,
+					reservedPorts: fallback.ReservedPorts,
+				}
+				if matchesAny(fallbackMatcher) {
+					matcher = fallbackMatcher
+					break
+				}
 			}
-		} else {
-			ifaceMatch = true
 		}
-		if cidrMatch && ifaceMatch {
+		matchers[name] = matcher
+	}
+	return matchers
+}
+
+func deriveAddressAliases(iface net.Interface, addr net.IP, config *config.Config, matchers map[string]*hostNetworkMatcher) (aliases []string) {
+	for name, matcher := range matchers {
+		if matcher.matches(iface, addr) {
 			aliases = append(aliases, name)
 		}
 	}
@@ -599,3 +599,56 @@ func TestNetworkFingerPrint_HostNetworkReservedPorts(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkFingerPrint_HostNetworkFallbacks(t *testing.T) {
+	ci.Parallel(t)
+
+	f := &NetworkFingerprint{
+		logger:            testlog.HCLogger(t),
+		interfaceDetector: &NetworkInterfaceDetectorMultipleInterfaces{},
+	}
+	node := &structs.Node{
+		Attributes: make(map[string]string),
+	}
+	cfg := &config.Config{
+		NetworkInterface: "eth3",
+		HostNetworks: map[string]*structs.ClientHostNetworkConfig{
+			// None of the addresses match the CIDR, so the first fallback
+			// that matches is used.
+			"public": {
+				Name:          "public",
+				CIDR:          "10.0.0.0/8",
+				ReservedPorts: "443",
+				Fallbacks: []*structs.ClientHostNetworkFallback{
+					{Interface: "eth9", ReservedPorts: "8080"},
+					{Interface: "eth3", ReservedPorts: "22"},
+					{Interface: "eth0", ReservedPorts: "80"},
+				},
+			},
+			// The CIDR matches, so the fallbacks are ignored.
+			"private": {
+				Name: "private",
+				CIDR: "169.254.0.0/16",
+				Fallbacks: []*structs.ClientHostNetworkFallback{
+					{Interface: "eth0", ReservedPorts: "80"},
+				},
+			},
+		},
+	}
+
+	request := &FingerprintRequest{Config: cfg, Node: node}
+	var response FingerprintResponse
+	err := f.Fingerprint(request, &response)
+	require.NoError(t, err)
+
+	got := []string{}
+	for _, network := range response.NodeResources.NodeNetworks {
+		for _, address := range network.Addresses {
+			if address.Alias != "default" {
+				got = append(got, fmt.Sprintf("%s/%s/%s", network.Device, address.Alias, address.ReservedPorts))
+			}
+		}
+	}
+	sort.Strings(got)
+	require.Equal(t, []string{"eth3/private/", "eth3/public/22", "eth4/private/"}, got)
+}
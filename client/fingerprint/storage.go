@@ -44,6 +44,22 @@ func (f *StorageFingerprint) Fingerprint(req *FingerprintRequest, resp *Fingerpr
 		return fmt.Errorf("failed to determine disk space for %s: %v", storageDir, err)
 	}
 
+	// The disk space of the additional alloc dir roots is available to the
+	// allocations too, unless they share a volume
+	volumes := map[string]bool{volume: true}
+	for _, root := range cfg.AllocDirs {
+		rootVolume, rootTotal, rootFree, err := f.diskFree(root)
+		if err != nil {
+			return fmt.Errorf("failed to determine disk space for %s: %v", root, err)
+		}
+		if volumes[rootVolume] {
+			continue
+		}
+		volumes[rootVolume] = true
+		total += rootTotal
+		free += rootFree
+	}
+
 	if cfg.DiskTotalMB > 0 {
 		total = uint64(cfg.DiskTotalMB) * bytesPerMegabyte
 	}
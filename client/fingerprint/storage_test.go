@@ -8,8 +8,10 @@ import (
 	"testing"
 
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
 )
 
 func TestStorageFingerprint(t *testing.T) {
@@ -47,3 +49,28 @@ func TestStorageFingerprint(t *testing.T) {
 		t.Errorf("Expected node.Resources.DiskMB to be non-zero")
 	}
 }
+
+func TestStorageFingerprint_AllocDirs(t *testing.T) {
+	ci.Parallel(t)
+
+	fp := NewStorageFingerprint(testlog.HCLogger(t))
+
+	allocDir := t.TempDir()
+	request := &FingerprintRequest{
+		Config: &config.Config{AllocDir: allocDir},
+		Node:   &structs.Node{Attributes: map[string]string{}},
+	}
+	var response FingerprintResponse
+	must.NoError(t, fp.Fingerprint(request, &response))
+
+	// Roots on the same volume don't add disk space
+	request.Config = &config.Config{AllocDir: allocDir, AllocDirs: []string{t.TempDir()}}
+	var multiResponse FingerprintResponse
+	must.NoError(t, fp.Fingerprint(request, &multiResponse))
+	must.Eq(t, response.Attributes["unique.storage.volume"], multiResponse.Attributes["unique.storage.volume"])
+	must.Eq(t, response.Attributes["unique.storage.bytestotal"], multiResponse.Attributes["unique.storage.bytestotal"])
+
+	// Roots must exist
+	request.Config = &config.Config{AllocDir: allocDir, AllocDirs: []string{"/nonexistent/alloc"}}
+	must.Error(t, fp.Fingerprint(request, &FingerprintResponse{}))
+}
@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package fingerprint
+
+import (
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/helper/users"
+)
+
+const (
+	userDirectoryLookupKey = "user.directory_lookup"
+)
+
+// UserFingerprint is used to fingerprint whether the client resolves the
+// users of tasks with the directory service of the host, so jobs running as
+// users managed by the directory service can be constrained to these nodes.
+type UserFingerprint struct {
+	StaticFingerprinter
+	logger       log.Logger
+	nssSupported func() bool
+}
+
+// NewUserFingerprint is used to create a user fingerprint
+func NewUserFingerprint(logger log.Logger) Fingerprint {
+	return &UserFingerprint{
+		logger:       logger.Named("user"),
+		nssSupported: users.NSSSupported,
+	}
+}
+
+func (f *UserFingerprint) Fingerprint(req *FingerprintRequest, resp *FingerprintResponse) error {
+	if !req.Config.ReadBoolDefault("user.directory_lookup", false) {
+		return nil
+	}
+	if !f.nssSupported() {
+		f.logger.Warn("user.directory_lookup is enabled but this Nomad binary was built without cgo and cannot resolve users with the directory service")
+		return nil
+	}
+
+	resp.AddAttribute(userDirectoryLookupKey, "true")
+	resp.Detected = true
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestUserFingerprint(t *testing.T) {
+	ci.Parallel(t)
+
+	f := NewUserFingerprint(testlog.HCLogger(t))
+	f.(*UserFingerprint).nssSupported = func() bool { return true }
+	node := &structs.Node{Attributes: map[string]string{}}
+
+	// Disabled by default
+	var resp FingerprintResponse
+	must.NoError(t, f.Fingerprint(&FingerprintRequest{Config: &config.Config{}, Node: node}, &resp))
+	must.MapNotContainsKey(t, resp.Attributes, userDirectoryLookupKey)
+
+	cfg := &config.Config{Options: map[string]string{"user.directory_lookup": "true"}}
+	resp = FingerprintResponse{}
+	must.NoError(t, f.Fingerprint(&FingerprintRequest{Config: cfg, Node: node}, &resp))
+	must.True(t, resp.Detected)
+	must.Eq(t, "true", resp.Attributes[userDirectoryLookupKey])
+
+	// Not advertised when users can't be resolved with NSS
+	f.(*UserFingerprint).nssSupported = func() bool { return false }
+	resp = FingerprintResponse{}
+	must.NoError(t, f.Fingerprint(&FingerprintRequest{Config: cfg, Node: node}, &resp))
+	must.MapNotContainsKey(t, resp.Attributes, userDirectoryLookupKey)
+}
@@ -4,7 +4,10 @@
 package client
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
@@ -82,6 +85,7 @@ func NewFileSystemEndpoint(c *Client) *FileSystem {
 	f := &FileSystem{c}
 	f.c.streamingRpcs.Register("FileSystem.Logs", f.logs)
 	f.c.streamingRpcs.Register("FileSystem.Stream", f.stream)
+	f.c.streamingRpcs.Register("FileSystem.Archive", f.archive)
 	return f
 }
 
@@ -343,6 +347,301 @@ OUTER:
 	}
 }
 
+// archive is used to stream a directory of an allocation as a gzipped tar
+// archive.
+func (f *FileSystem) archive(conn io.ReadWriteCloser) {
+	defer metrics.MeasureSince([]string{"client", "file_system", "archive"}, time.Now())
+	defer conn.Close()
+
+	// Decode the arguments
+	var req cstructs.FsArchiveRequest
+	decoder := codec.NewDecoder(conn, structs.MsgpackHandle)
+	encoder := codec.NewEncoder(conn, structs.MsgpackHandle)
+
+	if err := decoder.Decode(&req); err != nil {
+		handleStreamResultError(err, pointer.Of(int64(http.StatusInternalServerError)), encoder)
+		return
+	}
+
+	if req.AllocID == "" {
+		handleStreamResultError(allocIDNotPresentErr, pointer.Of(int64(http.StatusBadRequest)), encoder)
+		return
+	}
+
+	ar, err := f.c.getAllocRunner(req.AllocID)
+	if err != nil {
+		handleStreamResultError(structs.NewErrUnknownAllocation(req.AllocID), pointer.Of(int64(http.StatusNotFound)), encoder)
+		return
+	}
+	if ar.IsDestroyed() {
+		handleStreamResultError(
+			fmt.Errorf("state for allocation %s not found on client", req.AllocID),
+			pointer.Of(int64(http.StatusNotFound)),
+			encoder,
+		)
+		return
+	}
+	alloc := ar.Alloc()
+
+	// Check read permissions
+	if aclObj, err := f.c.ResolveToken(req.QueryOptions.AuthToken); err != nil {
+		handleStreamResultError(err, pointer.Of(int64(http.StatusForbidden)), encoder)
+		return
+	} else if !aclObj.AllowNsOp(alloc.Namespace, acl.NamespaceCapabilityReadFS) {
+		handleStreamResultError(structs.ErrPermissionDenied, pointer.Of(int64(http.StatusForbidden)), encoder)
+		return
+	}
+
+	// Validate the arguments
+	if req.Path == "" {
+		req.Path = "/"
+	}
+	if req.MaxBytes <= 0 {
+		req.MaxBytes = cstructs.DefaultFsArchiveMaxBytes
+	}
+	for _, pattern := range append(req.Include, req.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			handleStreamResultError(fmt.Errorf("invalid pattern %q: %v", pattern, err),
+				pointer.Of(int64(http.StatusBadRequest)), encoder)
+			return
+		}
+	}
+
+	fs, err := f.c.GetAllocFS(req.AllocID)
+	if err != nil {
+		code := pointer.Of(int64(http.StatusInternalServerError))
+		if structs.IsErrUnknownAllocation(err) {
+			code = pointer.Of(int64(http.StatusNotFound))
+		}
+
+		handleStreamResultError(err, code, encoder)
+		return
+	}
+
+	fileInfo, err := fs.Stat(req.Path)
+	if err != nil {
+		handleStreamResultError(err, pointer.Of(int64(http.StatusBadRequest)), encoder)
+		return
+	}
+	if !fileInfo.IsDir {
+		handleStreamResultError(
+			fmt.Errorf("file %q is not a directory", req.Path),
+			pointer.Of(int64(http.StatusBadRequest)), encoder)
+		return
+	}
+
+	// Collect the files to archive first so the size cap is enforced before
+	// anything is sent.
+	entries, err := archiveEntries(fs, req.Path, req.Include, req.Exclude)
+	if err != nil {
+		handleStreamResultError(err, pointer.Of(int64(http.StatusInternalServerError)), encoder)
+		return
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.info.Size
+	}
+	if total > req.MaxBytes {
+		handleStreamResultError(
+			fmt.Errorf("directory %q contains %d bytes, more than the maximum of %d", req.Path, total, req.MaxBytes),
+			pointer.Of(int64(http.StatusBadRequest)), encoder)
+		return
+	}
+
+	root := filepath.Base(filepath.Clean("/" + req.Path))
+	if root == "/" {
+		root = alloc.ID[:8]
+	}
+	out := &streamPayloadWriter{conn: conn, encoder: encoder}
+	if err := writeArchive(out, fs, root, entries); err != nil {
+		handleStreamResultError(err, pointer.Of(int64(http.StatusInternalServerError)), encoder)
+		return
+	}
+}
+
+// archiveEntry is a file or directory to add to an archive.
+type archiveEntry struct {
+	// path is the path of the file relative to the alloc dir.
+	path string
+
+	// name is the path of the file relative to the archived directory.
+	name string
+
+	info *cstructs.AllocFileInfo
+}
+
+// archiveEntries walks the directory and returns the directories and regular
+// files it contains that match the include and exclude patterns. Symlinks and
+// other special files are skipped.
+func archiveEntries(fs allocdir.AllocDirFS, dir string, include, exclude []string) ([]*archiveEntry, error) {
+	matches := func(patterns []string, name string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				return true
+			}
+			if ok, _ := filepath.Match(pattern, filepath.Base(name)); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	// walk adds the entries of the directory at rel. When filter is false,
+	// the include patterns matched a parent directory so all its entries
+	// are added.
+	var entries []*archiveEntry
+	var walk func(rel string, filter bool) error
+	walk = func(rel string, filter bool) error {
+		files, err := fs.List(filepath.Join(dir, rel))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			name := filepath.Join(rel, file.Name)
+			entry := &archiveEntry{path: filepath.Join(dir, name), name: name, info: file}
+
+			if matches(exclude, name) {
+				continue
+			}
+			included := !filter || matches(include, name)
+
+			if file.IsDir {
+				// Directories are walked even if they don't match the
+				// include patterns since the patterns may match the files
+				// they contain, but are only kept if they match or have
+				// entries that do.
+				dirIndex := len(entries)
+				entries = append(entries, entry)
+				if err := walk(name, !included); err != nil {
+					return err
+				}
+				if !included && len(entries) == dirIndex+1 {
+					entries = entries[:dirIndex]
+				}
+				continue
+			}
+			if !strings.HasPrefix(file.FileMode, "-") || !included {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	}
+
+	if err := walk("", len(include) > 0); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// writeArchive writes the entries as a gzipped tar archive with all the
+// entries under the root directory.
+func writeArchive(w io.Writer, fs allocdir.AllocDirFS, root string, entries []*archiveEntry) error {
+	buf := bufio.NewWriterSize(w, streamFrameSize)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     root + "/",
+		Mode:     0o755,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:    filepath.ToSlash(filepath.Join(root, entry.name)),
+			Mode:    archiveFileMode(entry.info.FileMode),
+			ModTime: entry.info.ModTime,
+		}
+		if entry.info.IsDir {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Files that can't be read, such as the files of the secrets
+		// directories or files removed since they were listed, are skipped.
+		r, err := fs.ReadAt(entry.path, 0)
+		if err != nil {
+			continue
+		}
+
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = entry.info.Size
+		if err := tw.WriteHeader(hdr); err != nil {
+			r.Close()
+			return err
+		}
+
+		// The file may have changed since it was listed, so copy exactly the
+		// size written in the header and pad it if it shrank.
+		n, err := io.CopyN(tw, r, hdr.Size)
+		r.Close()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n < hdr.Size {
+			if _, err := io.CopyN(tw, zeroReader{}, hdr.Size-n); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// archiveFileMode parses the permission bits of the file mode string of an
+// AllocFileInfo, such as "-rw-r--r--".
+func archiveFileMode(mode string) int64 {
+	if len(mode) < 9 {
+		return 0o644
+	}
+	perms := mode[len(mode)-9:]
+	var m int64
+	for i, c := range perms {
+		if c != '-' {
+			m |= 1 << (8 - i)
+		}
+	}
+	return m
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// streamPayloadWriter sends everything written to it as the payload of
+// StreamErrWrapper messages.
+type streamPayloadWriter struct {
+	conn    io.Writer
+	encoder *codec.Encoder
+}
+
+func (w *streamPayloadWriter) Write(p []byte) (int, error) {
+	if err := w.encoder.Encode(cstructs.StreamErrWrapper{Payload: p}); err != nil {
+		return 0, err
+	}
+	w.encoder.Reset(w.conn)
+	return len(p), nil
+}
+
 // logs is is used to stream a task's logs.
 func (f *FileSystem) logs(conn io.ReadWriteCloser) {
 	defer metrics.MeasureSince([]string{"client", "file_system", "logs"}, time.Now())
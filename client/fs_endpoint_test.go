@@ -4,6 +4,9 @@
 package client
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
@@ -2186,3 +2189,88 @@ func TestFS_logsImpl_Follow(t *testing.T) {
 		t.Fatalf("did not receive data: got %q", string(received))
 	}
 }
+
+func TestFS_archiveEntries(t *testing.T) {
+	ci.Parallel(t)
+
+	ad := tempAllocDir(t)
+	defer ad.Destroy()
+
+	for name, contents := range map[string]string{
+		"alloc/data/a.txt":       "a",
+		"alloc/data/b.log":       "bb",
+		"alloc/data/sub/c.txt":   "ccc",
+		"alloc/data/sub/d.log":   "dddd",
+		"alloc/data/cache/e.bin": "eeeee",
+	} {
+		path := filepath.Join(ad.AllocDir, name)
+		must.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		must.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+
+	names := func(entries []*archiveEntry) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.name)
+		}
+		return out
+	}
+
+	// All files
+	entries, err := archiveEntries(ad, "alloc/data", nil, nil)
+	must.NoError(t, err)
+	must.SliceContainsAll(t, []string{
+		"a.txt", "b.log", "cache", "cache/e.bin", "sub", "sub/c.txt", "sub/d.log",
+	}, names(entries))
+
+	// Include patterns match file names in any directory, and directories
+	// without any included file are dropped
+	entries, err = archiveEntries(ad, "alloc/data", []string{"*.txt"}, nil)
+	must.NoError(t, err)
+	must.SliceContainsAll(t, []string{"a.txt", "sub", "sub/c.txt"}, names(entries))
+
+	// Including a directory includes all its files
+	entries, err = archiveEntries(ad, "alloc/data", []string{"sub"}, nil)
+	must.NoError(t, err)
+	must.SliceContainsAll(t, []string{"sub", "sub/c.txt", "sub/d.log"}, names(entries))
+
+	// Excluding a directory skips all its files
+	entries, err = archiveEntries(ad, "alloc/data", nil, []string{"cache", "*.log"})
+	must.NoError(t, err)
+	must.SliceContainsAll(t, []string{"a.txt", "sub", "sub/c.txt"}, names(entries))
+
+	// Write the archive and check its contents
+	entries, err = archiveEntries(ad, "alloc/data", nil, []string{"cache"})
+	must.NoError(t, err)
+
+	var buf bytes.Buffer
+	must.NoError(t, writeArchive(&buf, ad, "data", entries))
+
+	gz, err := gzip.NewReader(&buf)
+	must.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		must.NoError(t, err)
+		if hdr.Typeflag == tar.TypeDir {
+			files[hdr.Name] = ""
+			continue
+		}
+		contents, err := io.ReadAll(tr)
+		must.NoError(t, err)
+		files[hdr.Name] = string(contents)
+	}
+	must.Eq(t, map[string]string{
+		"data/":          "",
+		"data/a.txt":     "a",
+		"data/b.log":     "bb",
+		"data/sub/":      "",
+		"data/sub/c.txt": "ccc",
+		"data/sub/d.log": "dddd",
+	}, files)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package diskquota limits the disk usage of directory trees with filesystem
+// project quotas, as supported by XFS and ext4.
+//
+// Every file and directory in the trees is assigned the same project ID and
+// the directories are marked to have their new entries inherit it, so the
+// filesystem accounts all their blocks to the project and refuses writes once
+// the project exceeds its limit.
+package diskquota
+
+import (
+	"errors"
+	"hash/fnv"
+)
+
+// ErrNotSupported is returned when project quotas aren't supported for a
+// path, either because of the platform or of its filesystem.
+var ErrNotSupported = errors.New("project quotas are not supported")
+
+const (
+	// minProjectID is the lowest project ID assigned to allocations. Lower
+	// project IDs are left for the operators' own projects.
+	minProjectID = 1 << 16
+)
+
+// Usage is the disk usage of a project.
+type Usage struct {
+	// UsedBytes is the size of the blocks used by the project.
+	UsedBytes uint64
+
+	// LimitBytes is the hard limit of the project, 0 if it isn't limited.
+	LimitBytes uint64
+}
+
+// ProjectID returns the project ID for the given allocation ID. Project IDs
+// are derived from allocation IDs so they can be found again after a client
+// restart without persisting them.
+func ProjectID(allocID string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(allocID))
+	return minProjectID + h.Sum32()%(1<<31-minProjectID)
+}
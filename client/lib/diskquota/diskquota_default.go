@@ -0,0 +1,21 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build !linux
+
+package diskquota
+
+// Set is not supported on non-Linux systems.
+func Set(_ []string, _ uint32, _ uint64) error {
+	return ErrNotSupported
+}
+
+// Get is not supported on non-Linux systems.
+func Get(_ string, _ uint32) (*Usage, error) {
+	return nil, ErrNotSupported
+}
+
+// Clear is not supported on non-Linux systems.
+func Clear(_ string, _ uint32) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,241 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build linux
+
+package diskquota
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/moby/sys/mountinfo"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// quotactl commands and flags from linux/quota.h
+	qGetQuota  = 0x800007
+	qSetQuota  = 0x800008
+	prjQuota   = 2
+	qifBLimits = 1
+	qifSpace   = 2
+
+	// quotaBlockSize is the size of the blocks quota limits are set in.
+	quotaBlockSize = 1024
+
+	// ioctl requests and flags from linux/fs.h
+	fsIocFsGetXattr    = 0x801c581f
+	fsIocFsSetXattr    = 0x401c5820
+	fsXflagProjInherit = 0x200
+)
+
+// supportedFilesystems are the filesystems with project quotas.
+var supportedFilesystems = map[string]bool{
+	"xfs":  true,
+	"ext4": true,
+}
+
+// ifDqblk is the if_dqblk struct from linux/quota.h
+type ifDqblk struct {
+	bHardLimit uint64
+	bSoftLimit uint64
+	curSpace   uint64
+	iHardLimit uint64
+	iSoftLimit uint64
+	curInodes  uint64
+	bTime      uint64
+	iTime      uint64
+	valid      uint32
+}
+
+// fsxattr is the fsxattr struct from linux/fs.h
+type fsxattr struct {
+	xflags     uint32
+	extSize    uint32
+	nextents   uint32
+	projID     uint32
+	cowExtSize uint32
+	pad        [8]byte
+}
+
+// Set assigns the project ID to every directory and regular file in the
+// trees at paths, marks the directories so their new entries inherit it, and
+// limits the disk usage of the project to limitBytes.
+func Set(paths []string, projectID uint32, limitBytes uint64) error {
+	devices := map[string]bool{}
+	for _, path := range paths {
+		device, err := device(path)
+		if err != nil {
+			return err
+		}
+		devices[device] = true
+
+		if err := setProjectTree(path, projectID); err != nil {
+			return err
+		}
+	}
+
+	for device := range devices {
+		dq := ifDqblk{
+			bHardLimit: (limitBytes + quotaBlockSize - 1) / quotaBlockSize,
+			valid:      qifBLimits,
+		}
+		if err := quotactl(qSetQuota, device, projectID, &dq); err != nil {
+			return fmt.Errorf("failed to set quota of project %d on %s: %w", projectID, device, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the disk usage of the project on the filesystem of path.
+func Get(path string, projectID uint32) (*Usage, error) {
+	device, err := device(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dq ifDqblk
+	if err := quotactl(qGetQuota, device, projectID, &dq); err != nil {
+		return nil, fmt.Errorf("failed to get quota of project %d on %s: %w", projectID, device, err)
+	}
+
+	usage := &Usage{LimitBytes: dq.bHardLimit * quotaBlockSize}
+	if dq.valid&qifSpace != 0 {
+		usage.UsedBytes = dq.curSpace
+	}
+	return usage, nil
+}
+
+// Clear removes the limit of the project on the filesystem of path.
+func Clear(path string, projectID uint32) error {
+	device, err := device(path)
+	if err != nil {
+		return err
+	}
+
+	dq := ifDqblk{valid: qifBLimits}
+	if err := quotactl(qSetQuota, device, projectID, &dq); err != nil {
+		return fmt.Errorf("failed to clear quota of project %d on %s: %w", projectID, device, err)
+	}
+	return nil
+}
+
+// device returns the block device of the filesystem path is on.
+func device(path string) (string, error) {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	mounts, err := mountinfo.GetMounts(mountinfo.ParentsFilter(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read mounts: %w", err)
+	}
+
+	// The filesystem of path is the one of the closest parent mount.
+	var mount *mountinfo.Info
+	for _, m := range mounts {
+		if mount == nil || len(m.Mountpoint) > len(mount.Mountpoint) {
+			mount = m
+		}
+	}
+	if mount == nil {
+		return "", fmt.Errorf("failed to find the filesystem of %s", path)
+	}
+	if !supportedFilesystems[mount.FSType] {
+		return "", fmt.Errorf("%w on %s filesystem %s", ErrNotSupported, mount.FSType, mount.Mountpoint)
+	}
+	return mount.Source, nil
+}
+
+// setProjectTree assigns the project ID to the tree at root. Entries on other
+// filesystems, such as mount points, are skipped.
+func setProjectTree(root string, projectID uint32) error {
+	var rootStat unix.Stat_t
+	if err := unix.Stat(root, &rootStat); err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		var stat unix.Stat_t
+		if err := unix.Lstat(path, &stat); err != nil {
+			return err
+		}
+		if stat.Dev != rootStat.Dev {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		return setProject(path, projectID, d.IsDir())
+	})
+}
+
+// setProject assigns the project ID to the file at path. Directories are also
+// marked for their new entries to inherit it.
+func setProject(path string, projectID uint32, dir bool) error {
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	var attr fsxattr
+	if err := ioctl(fd, fsIocFsGetXattr, &attr); err != nil {
+		return fmt.Errorf("failed to get attributes of %s: %w", path, err)
+	}
+
+	attr.projID = projectID
+	if dir {
+		attr.xflags |= fsXflagProjInherit
+	}
+	if err := ioctl(fd, fsIocFsSetXattr, &attr); err != nil {
+		return fmt.Errorf("failed to set project of %s: %w", path, err)
+	}
+	return nil
+}
+
+func ioctl(fd int, req uintptr, attr *fsxattr) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(attr)))
+	return quotaError(errno)
+}
+
+func quotactl(cmd uint32, device string, projectID uint32, dq *ifDqblk) error {
+	special, err := unix.BytePtrFromString(device)
+	if err != nil {
+		return err
+	}
+
+	// QCMD(cmd, type) from linux/quota.h
+	qcmd := cmd<<8 | prjQuota
+	_, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, uintptr(qcmd),
+		uintptr(unsafe.Pointer(special)), uintptr(projectID), uintptr(unsafe.Pointer(dq)), 0, 0)
+	return quotaError(errno)
+}
+
+// quotaError converts the errors returned when project quotas aren't enabled
+// to ErrNotSupported.
+func quotaError(errno unix.Errno) error {
+	switch {
+	case errno == 0:
+		return nil
+	case errors.Is(errno, unix.ENOTTY), errors.Is(errno, unix.EOPNOTSUPP), errors.Is(errno, unix.ENOSYS):
+		return fmt.Errorf("%w: %v", ErrNotSupported, errno)
+	case errors.Is(errno, unix.ESRCH):
+		return fmt.Errorf("%w: quotas are not enabled on the filesystem", ErrNotSupported)
+	default:
+		return errno
+	}
+}
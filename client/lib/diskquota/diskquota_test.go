@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package diskquota
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/shoenig/test/must"
+)
+
+func TestProjectID(t *testing.T) {
+	ci.Parallel(t)
+
+	allocID := uuid.Generate()
+	id := ProjectID(allocID)
+	must.Eq(t, id, ProjectID(allocID))
+	must.GreaterEq(t, minProjectID, id)
+	must.Less(t, 1<<31, id)
+	must.NotEq(t, id, ProjectID(uuid.Generate()))
+}
+
+func TestSet(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := t.TempDir()
+	must.NoError(t, os.MkdirAll(filepath.Join(dir, "data"), 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "data", "file"), []byte("hello"), 0o644))
+
+	projectID := ProjectID(uuid.Generate())
+	err := Set([]string{dir}, projectID, 1<<20)
+	if errors.Is(err, ErrNotSupported) {
+		t.Skipf("project quotas not supported: %v", err)
+	}
+	must.NoError(t, err)
+	t.Cleanup(func() { _ = Clear(dir, projectID) })
+
+	usage, err := Get(dir, projectID)
+	must.NoError(t, err)
+	must.Eq(t, 1<<20, usage.LimitBytes)
+	must.Positive(t, usage.UsedBytes)
+
+	must.NoError(t, Clear(dir, projectID))
+	usage, err = Get(dir, projectID)
+	must.NoError(t, err)
+	must.Zero(t, usage.LimitBytes)
+}
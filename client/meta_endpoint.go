@@ -25,7 +25,8 @@ func (n *NodeMeta) Apply(args *structs.NodeMetaApplyRequest, reply *structs.Node
 	defer metrics.MeasureSince([]string{"client", "node_meta", "apply"}, time.Now())
 
 	// Check node write permissions
-	if aclObj, err := n.c.ResolveToken(args.AuthToken); err != nil {
+	aclObj, ident, err := n.c.resolveTokenAndACL(args.AuthToken)
+	if err != nil {
 		return err
 	} else if !aclObj.AllowNodeWrite() {
 		return structs.ErrPermissionDenied
@@ -35,55 +36,24 @@ func (n *NodeMeta) Apply(args *structs.NodeMetaApplyRequest, reply *structs.Node
 		return structs.NewErrRPCCoded(http.StatusBadRequest, err.Error())
 	}
 
-	var stateErr error
-	var dyn map[string]*string
-
-	newNode := n.c.UpdateNode(func(node *structs.Node) {
-		// First update the Client's state store. This must be done
-		// atomically with updating the metadata inmemory to avoid
-		// bad interleaving between concurrent updates.
-		dyn = maps.Clone(n.c.metaDynamic)
-		maps.Copy(dyn, args.Meta)
-
-		// Delete null values from the dynamic metadata if they are also not
-		// static. Static null values must be kept so their removal is
-		// persisted in client state.
-		for k, v := range args.Meta {
-			_, static := n.c.metaStatic[k]
-			if v == nil && !static {
-				delete(dyn, k)
-			}
-		}
-
-		if stateErr = n.c.stateDB.PutNodeMeta(dyn); stateErr != nil {
-			return
-		}
-
-		// Apply updated dynamic metadata to client and node now that the part of
-		// the operation that can fail succeeded (persistence). Must clone as dyn
-		// is read outside of UpdateNode.
-		n.c.metaDynamic = maps.Clone(dyn)
-
-		for k, v := range args.Meta {
-			if v == nil {
-				delete(node.Meta, k)
-				continue
-			}
-
-			node.Meta[k] = *v
-		}
-	})
-
-	if stateErr != nil {
-		return stateErr
+	now := time.Now()
+	info := &structs.NodeMetaInfo{
+		Writer:     nodeMetaWriter(ident),
+		ModifyTime: now,
+	}
+	if args.TTL > 0 {
+		info.ExpireTime = now.Add(args.TTL)
 	}
 
-	// Trigger an async node update
-	n.c.updateNode()
+	newNode, dyn, dynInfo, err := n.c.applyNodeMeta(args.Meta, info)
+	if err != nil {
+		return err
+	}
 
 	reply.Meta = newNode.Meta
 	reply.Dynamic = dyn
 	reply.Static = n.c.metaStatic
+	reply.DynamicInfo = dynInfo
 	return nil
 }
 
@@ -105,5 +75,15 @@ func (n *NodeMeta) Read(args *structs.NodeSpecificRequest, reply *structs.NodeMe
 	reply.Meta = n.c.config.Node.Meta
 	reply.Dynamic = maps.Clone(n.c.metaDynamic)
 	reply.Static = n.c.metaStatic
+	reply.DynamicInfo = maps.Clone(n.c.metaInfo)
 	return nil
 }
+
+// nodeMetaWriter returns the writer recorded for the dynamic node metadata set
+// by the identity.
+func nodeMetaWriter(ident *structs.AuthenticatedIdentity) string {
+	if ident == nil || ident.ACLToken == structs.AnonymousACLToken {
+		return "anonymous"
+	}
+	return ident.String()
+}
@@ -4,7 +4,9 @@
 package client
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/ci"
@@ -60,6 +62,10 @@ func TestNodeMeta_ACL(t *testing.T) {
 	readReq.AuthToken = tokenGood.SecretID
 	err = c1.ClientRPC("NodeMeta.Read", readReq, &resp)
 	must.NoError(t, err)
+
+	// The token that set the metadata is recorded as its writer
+	must.MapContainsKey(t, resp.DynamicInfo, "foo")
+	must.Eq(t, "token:"+tokenGood.AccessorID, resp.DynamicInfo["foo"].Writer)
 }
 
 func TestNodeMeta_Validation(t *testing.T) {
@@ -81,8 +87,16 @@ func TestNodeMeta_Validation(t *testing.T) {
 
 	resp := struct{}{}
 
-	// An empty map is an error
+	// A negative TTL is an error
+	applyReq.Meta["foo"] = pointer.Of("bar")
+	applyReq.TTL = -time.Second
 	err := c1.ClientRPC("NodeMeta.Apply", applyReq, &resp)
+	must.ErrorContains(t, err, "ttl must be >= 0")
+	delete(applyReq.Meta, "foo")
+	applyReq.TTL = 0
+
+	// An empty map is an error
+	err = c1.ClientRPC("NodeMeta.Apply", applyReq, &resp)
 	must.ErrorContains(t, err, "missing required Meta")
 
 	// empty keys are prohibited
@@ -181,3 +195,71 @@ func TestNodeMeta_unset(t *testing.T) {
 	must.MapNotContainsKey(t, resp.Dynamic, "dynamic_meta")
 	must.MapNotContainsKey(t, resp.Meta, "dynamic_meta")
 }
+
+func TestNodeMeta_TTL(t *testing.T) {
+	ci.Parallel(t)
+
+	s, cleanupS := nomad.TestServer(t, nil)
+	defer cleanupS()
+	testutil.WaitForLeader(t, s.RPC)
+
+	c1, cleanup := TestClient(t, func(c *config.Config) {
+		c.Servers = []string{s.GetConfig().RPCAddr.String()}
+		c.Node.Meta["static_meta"] = "static"
+	})
+	defer cleanup()
+
+	// Set dynamic node metadata that expires, overriding static node
+	// metadata, along with dynamic node metadata that doesn't.
+	applyReq := &structs.NodeMetaApplyRequest{
+		NodeID: c1.NodeID(),
+		Meta: map[string]*string{
+			"static_meta": pointer.Of("dynamic"),
+			"ttl_meta":    pointer.Of("true"),
+		},
+		TTL: 500 * time.Millisecond,
+	}
+	var resp structs.NodeMetaResponse
+	err := c1.ClientRPC("NodeMeta.Apply", applyReq, &resp)
+	must.NoError(t, err)
+	must.Eq(t, "dynamic", resp.Meta["static_meta"])
+	must.Eq(t, "true", resp.Meta["ttl_meta"])
+	must.Eq(t, "anonymous", resp.DynamicInfo["ttl_meta"].Writer)
+	must.False(t, resp.DynamicInfo["ttl_meta"].ExpireTime.IsZero())
+
+	applyReq = &structs.NodeMetaApplyRequest{
+		NodeID: c1.NodeID(),
+		Meta: map[string]*string{
+			"dynamic_meta": pointer.Of("true"),
+		},
+	}
+	err = c1.ClientRPC("NodeMeta.Apply", applyReq, &resp)
+	must.NoError(t, err)
+	must.True(t, resp.DynamicInfo["dynamic_meta"].ExpireTime.IsZero())
+
+	// The expired keys must be removed, and the static value restored
+	readReq := &structs.NodeSpecificRequest{
+		NodeID: c1.NodeID(),
+	}
+	testutil.WaitForResult(func() (bool, error) {
+		var resp structs.NodeMetaResponse
+		if err := c1.ClientRPC("NodeMeta.Read", readReq, &resp); err != nil {
+			return false, err
+		}
+		if _, ok := resp.Dynamic["ttl_meta"]; ok {
+			return false, fmt.Errorf("expected ttl_meta to expire")
+		}
+		return true, nil
+	}, func(err error) {
+		must.NoError(t, err)
+	})
+
+	err = c1.ClientRPC("NodeMeta.Read", readReq, &resp)
+	must.NoError(t, err)
+	must.MapNotContainsKey(t, resp.Meta, "ttl_meta")
+	must.MapNotContainsKey(t, resp.DynamicInfo, "ttl_meta")
+	must.MapNotContainsKey(t, resp.Dynamic, "static_meta")
+	must.Eq(t, "static", resp.Meta["static_meta"])
+	must.Eq(t, "true", resp.Meta["dynamic_meta"])
+	must.MapContainsKey(t, resp.DynamicInfo, "dynamic_meta")
+}
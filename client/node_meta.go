@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package client
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"golang.org/x/exp/maps"
+)
+
+// nodeMetaExpiryRetryInterval is the time to wait before retrying to remove
+// expired dynamic node metadata after a failure.
+const nodeMetaExpiryRetryInterval = 10 * time.Second
+
+// applyNodeMeta applies and persists dynamic node metadata updates, where nil
+// values unset keys. The info describes the keys being set. A nil info
+// removes the keys because they expired, which restores their static value.
+//
+// The node, the dynamic node metadata, and its info are returned.
+func (c *Client) applyNodeMeta(meta map[string]*string, info *structs.NodeMetaInfo) (*structs.Node, map[string]*string, map[string]*structs.NodeMetaInfo, error) {
+	var stateErr error
+	var dyn map[string]*string
+	var dynInfo map[string]*structs.NodeMetaInfo
+
+	newNode := c.UpdateNode(func(node *structs.Node) {
+		// First update the Client's state store. This must be done
+		// atomically with updating the metadata inmemory to avoid
+		// bad interleaving between concurrent updates.
+		dyn = maps.Clone(c.metaDynamic)
+		dynInfo = maps.Clone(c.metaInfo)
+		if dyn == nil {
+			dyn = make(map[string]*string, len(meta))
+		}
+		if dynInfo == nil {
+			dynInfo = make(map[string]*structs.NodeMetaInfo, len(meta))
+		}
+
+		for k, v := range meta {
+			_, static := c.metaStatic[k]
+			switch {
+			case v != nil:
+				dyn[k] = v
+				dynInfo[k] = info
+			case static && info != nil:
+				// Static null values must be kept so their removal is
+				// persisted in client state.
+				dyn[k] = nil
+				delete(dynInfo, k)
+			default:
+				delete(dyn, k)
+				delete(dynInfo, k)
+			}
+		}
+
+		// The info is persisted first so a failure to persist the metadata
+		// can't leave keys without their expiration.
+		if stateErr = c.stateDB.PutNodeMetaInfo(dynInfo); stateErr != nil {
+			return
+		}
+		if stateErr = c.stateDB.PutNodeMeta(dyn); stateErr != nil {
+			return
+		}
+
+		// Apply updated dynamic metadata to client and node now that the part of
+		// the operation that can fail succeeded (persistence). Must clone as dyn
+		// is read outside of UpdateNode.
+		c.metaDynamic = maps.Clone(dyn)
+		c.metaInfo = maps.Clone(dynInfo)
+
+		for k, v := range meta {
+			staticValue, static := c.metaStatic[k]
+			switch {
+			case v != nil:
+				node.Meta[k] = *v
+			case static && info == nil:
+				node.Meta[k] = staticValue
+			default:
+				delete(node.Meta, k)
+			}
+		}
+	})
+
+	if stateErr != nil {
+		return nil, nil, nil, stateErr
+	}
+
+	// Trigger an async node update
+	c.updateNode()
+
+	// Wake up the expiration of the metadata in case it changed
+	select {
+	case c.metaExpiryCh <- struct{}{}:
+	default:
+	}
+
+	c.triggerNodeEvent(nodeMetaEvent(meta, info))
+
+	return newNode, dyn, dynInfo, nil
+}
+
+// nodeMetaEvent returns the node event describing a dynamic node metadata
+// update.
+func nodeMetaEvent(meta map[string]*string, info *structs.NodeMetaInfo) *structs.NodeEvent {
+	var set, unset []string
+	for k, v := range meta {
+		if v == nil {
+			unset = append(unset, k)
+		} else {
+			set = append(set, k)
+		}
+	}
+	slices.Sort(set)
+	slices.Sort(unset)
+
+	event := structs.NewNodeEvent().SetSubsystem(structs.NodeEventSubsystemMeta)
+	if info == nil {
+		return event.SetMessage("Node metadata expired").
+			AddDetail("expired", strings.Join(unset, ","))
+	}
+
+	event.SetMessage("Node metadata updated").AddDetail("writer", info.Writer)
+	if len(set) > 0 {
+		event.AddDetail("set", strings.Join(set, ","))
+	}
+	if len(unset) > 0 {
+		event.AddDetail("unset", strings.Join(unset, ","))
+	}
+	if !info.ExpireTime.IsZero() && len(set) > 0 {
+		event.AddDetail("expires", info.ExpireTime.UTC().Format(time.RFC3339))
+	}
+	return event
+}
+
+// expireNodeMeta is a long lived goroutine that removes the dynamic node
+// metadata keys once their TTL expires.
+func (c *Client) expireNodeMeta() {
+	timer, stop := helper.NewSafeTimer(0)
+	defer stop()
+
+	for {
+		expired, next := c.nodeMetaExpirations(time.Now())
+		if len(expired) > 0 {
+			meta := make(map[string]*string, len(expired))
+			for _, k := range expired {
+				meta[k] = nil
+			}
+			if _, _, _, err := c.applyNodeMeta(meta, nil); err != nil {
+				c.logger.Error("failed to remove expired node metadata", "keys", expired, "error", err)
+				next = time.Now().Add(nodeMetaExpiryRetryInterval)
+			} else {
+				c.logger.Debug("removed expired node metadata", "keys", expired)
+				continue
+			}
+		}
+
+		var timerCh <-chan time.Time
+		if !next.IsZero() {
+			timer.Reset(time.Until(next))
+			timerCh = timer.C
+		}
+
+		select {
+		case <-timerCh:
+		case <-c.metaExpiryCh:
+		case <-c.shutdownCh:
+			return
+		}
+	}
+}
+
+// nodeMetaExpirations returns the dynamic node metadata keys that are expired
+// at the given time, and when the next key expires, if any.
+func (c *Client) nodeMetaExpirations(now time.Time) ([]string, time.Time) {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+
+	var expired []string
+	var next time.Time
+	for k, info := range c.metaInfo {
+		switch {
+		case info == nil || info.ExpireTime.IsZero():
+		case !now.Before(info.ExpireTime):
+			expired = append(expired, k)
+		case next.IsZero() || info.ExpireTime.Before(next):
+			next = info.ExpireTime
+		}
+	}
+	slices.Sort(expired)
+	return expired, next
+}
+
+// restoreNodeMetaInfo restores the info of the dynamic node metadata keys
+// that are set.
+func (c *Client) restoreNodeMetaInfo() error {
+	info, err := c.stateDB.GetNodeMetaInfo()
+	if err != nil {
+		return fmt.Errorf("error reading dynamic node metadata info: %w", err)
+	}
+	if info == nil {
+		info = make(map[string]*structs.NodeMetaInfo)
+	}
+
+	for k := range info {
+		if c.metaDynamic[k] == nil {
+			delete(info, k)
+		}
+	}
+	c.metaInfo = info
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package client
+
+import (
+	"time"
+
+	"github.com/hashicorp/nomad/helper"
+)
+
+// secretsKeyringRetryInterval is how long to wait before rewrapping the keys
+// of the secrets dirs again after they failed to be rewrapped.
+const secretsKeyringRetryInterval = time.Minute
+
+// rotateSecretsKeyring periodically rotates the root key of the keyring of
+// the encrypted secrets dirs, rewrapping their keys with the new root key.
+func (c *Client) rotateSecretsKeyring() {
+	timer, stop := helper.NewSafeTimer(0)
+	defer stop()
+
+	retry := false
+	for {
+		wait := secretsKeyringRetryInterval
+		if !retry {
+			_, created := c.secretsKeyring.ActiveKey()
+			wait = time.Until(created.Add(c.GetConfig().SecretsKeyRotationPeriod))
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+		case <-c.shutdownCh:
+			return
+		}
+
+		allocDirs := c.GetConfig().AllocDirRoots()
+		var err error
+		if retry {
+			err = c.secretsKeyring.Rewrap(allocDirs...)
+		} else {
+			err = c.secretsKeyring.Rotate(allocDirs...)
+		}
+
+		retry = err != nil
+		if err != nil {
+			c.logger.Error("failed to rewrap the keys of the secrets dirs, retrying", "error", err)
+			continue
+		}
+		keyID, _ := c.secretsKeyring.ActiveKey()
+		c.logger.Info("rotated the root key of the secrets keyring", "key_id", keyID)
+	}
+}
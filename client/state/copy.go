@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"fmt"
+)
+
+// CopyState copies the client state of one state database into another, such
+// as when switching state stores. Allocations that can't be restored from src
+// are skipped.
+func CopyState(src, dst StateDB) error {
+	allocs, _, err := src.GetAllAllocations()
+	if err != nil {
+		return fmt.Errorf("failed to get allocations: %v", err)
+	}
+
+	for _, alloc := range allocs {
+		if err := copyAllocState(src, dst, alloc.ID); err != nil {
+			return fmt.Errorf("failed to copy state of alloc %s: %v", alloc.ID, err)
+		}
+		if err := dst.PutAllocation(alloc); err != nil {
+			return err
+		}
+
+		tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+		if tg == nil {
+			continue
+		}
+		for _, task := range tg.Tasks {
+			ls, ts, err := src.GetTaskRunnerState(alloc.ID, task.Name)
+			if err != nil {
+				return fmt.Errorf("failed to copy state of alloc %s task %s: %v", alloc.ID, task.Name, err)
+			}
+			if ls != nil {
+				if err := dst.PutTaskRunnerLocalState(alloc.ID, task.Name, ls); err != nil {
+					return err
+				}
+			}
+			if ts != nil {
+				if err := dst.PutTaskState(alloc.ID, task.Name, ts); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	results, err := src.GetCheckResults()
+	if err != nil {
+		return fmt.Errorf("failed to get check results: %v", err)
+	}
+	for allocID, allocResults := range results {
+		for _, qr := range allocResults {
+			if err := dst.PutCheckResult(allocID, qr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return copyNodeState(src, dst)
+}
+
+// copyAllocState copies the entries of an allocation, other than the
+// allocation itself and its tasks.
+func copyAllocState(src, dst StateDB, allocID string) error {
+	ds, err := src.GetDeploymentStatus(allocID)
+	if err != nil {
+		return err
+	}
+	if ds != nil {
+		if err := dst.PutDeploymentStatus(allocID, ds); err != nil {
+			return err
+		}
+	}
+
+	ns, err := src.GetNetworkStatus(allocID)
+	if err != nil {
+		return err
+	}
+	if ns != nil {
+		if err := dst.PutNetworkStatus(allocID, ns); err != nil {
+			return err
+		}
+	}
+
+	acked, err := src.GetAcknowledgedState(allocID)
+	if err != nil {
+		return err
+	}
+	if acked != nil {
+		if err := dst.PutAcknowledgedState(allocID, acked); err != nil {
+			return err
+		}
+	}
+
+	volumes, err := src.GetAllocVolumes(allocID)
+	if err != nil {
+		return err
+	}
+	if volumes != nil {
+		if err := dst.PutAllocVolumes(allocID, volumes); err != nil {
+			return err
+		}
+	}
+
+	identities, err := src.GetAllocIdentities(allocID)
+	if err != nil {
+		return err
+	}
+	if identities != nil {
+		if err := dst.PutAllocIdentities(allocID, identities); err != nil {
+			return err
+		}
+	}
+
+	renewals, err := src.GetAllocIdentityRenewals(allocID)
+	if err != nil {
+		return err
+	}
+	if renewals != nil {
+		if err := dst.PutAllocIdentityRenewals(allocID, renewals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyNodeState copies the entries not scoped to an allocation.
+func copyNodeState(src, dst StateDB) error {
+	devices, err := src.GetDevicePluginState()
+	if err != nil {
+		return err
+	}
+	if devices != nil {
+		if err := dst.PutDevicePluginState(devices); err != nil {
+			return err
+		}
+	}
+
+	drivers, err := src.GetDriverPluginState()
+	if err != nil {
+		return err
+	}
+	if drivers != nil {
+		if err := dst.PutDriverPluginState(drivers); err != nil {
+			return err
+		}
+	}
+
+	registry, err := src.GetDynamicPluginRegistryState()
+	if err != nil {
+		return err
+	}
+	if registry != nil {
+		if err := dst.PutDynamicPluginRegistryState(registry); err != nil {
+			return err
+		}
+	}
+
+	meta, err := src.GetNodeMeta()
+	if err != nil {
+		return err
+	}
+	if len(meta) > 0 {
+		if err := dst.PutNodeMeta(meta); err != nil {
+			return err
+		}
+	}
+
+	metaInfo, err := src.GetNodeMetaInfo()
+	if err != nil {
+		return err
+	}
+	if len(metaInfo) > 0 {
+		if err := dst.PutNodeMetaInfo(metaInfo); err != nil {
+			return err
+		}
+	}
+
+	reg, err := src.GetNodeRegistration()
+	if err != nil {
+		return err
+	}
+	if reg != nil && reg.HasRegistered {
+		if err := dst.PutNodeRegistration(reg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -100,6 +100,10 @@ var (
 	// under
 	allocIdentityKey = []byte("alloc_identities")
 
+	// allocIdentityRenewalsKey is the key the renewal schedules of the
+	// workload identities are stored under
+	allocIdentityRenewalsKey = []byte("alloc_identity_renewals")
+
 	// checkResultsBucket is the bucket name in which check query results are stored
 	checkResultsBucket = []byte("check_results")
 
@@ -133,6 +137,10 @@ var (
 	// nodeMetaKey is the key at which dynamic node metadata is stored.
 	nodeMetaKey = []byte("meta")
 
+	// nodeMetaInfoKey is the key at which the writers and expirations of the
+	// dynamic node metadata keys are stored.
+	nodeMetaInfoKey = []byte("info")
+
 	// nodeBucket is the bucket name in which data about the node is stored.
 	nodeBucket = []byte("node")
 
@@ -148,6 +156,16 @@ func taskBucketName(taskName string) []byte {
 // NewStateDBFunc creates a StateDB given a state directory.
 type NewStateDBFunc func(logger hclog.Logger, stateDir string) (StateDB, error)
 
+const (
+	// StateStoreBoltDB is the state store persisting the client state in a
+	// BoltDB database. It's the default state store.
+	StateStoreBoltDB = "boltdb"
+
+	// StateStoreSQLite is the state store persisting the client state in a
+	// SQLite database.
+	StateStoreSQLite = "sqlite"
+)
+
 // GetStateDBFactory returns a func for creating a StateDB
 func GetStateDBFactory(devMode bool) NewStateDBFunc {
 	// Return a noop state db implementation when in debug mode
@@ -160,6 +178,23 @@ func GetStateDBFactory(devMode bool) NewStateDBFunc {
 	return NewBoltStateDB
 }
 
+// GetStateStoreFactory returns a func for creating the StateDB of a state
+// store, or an error if the state store is unknown.
+func GetStateStoreFactory(store string, devMode bool) (NewStateDBFunc, error) {
+	switch store {
+	case "", StateStoreBoltDB:
+		return GetStateDBFactory(devMode), nil
+	case StateStoreSQLite:
+		if devMode {
+			return GetStateDBFactory(devMode), nil
+		}
+		return NewSQLiteStateDB, nil
+	default:
+		return nil, fmt.Errorf("unknown state store %q, must be one of %q or %q",
+			store, StateStoreBoltDB, StateStoreSQLite)
+	}
+}
+
 // BoltStateDB persists and restores Nomad client state in a boltdb. All
 // methods are safe for concurrent access.
 type BoltStateDB struct {
@@ -568,6 +603,57 @@ func (s *BoltStateDB) GetAllocIdentities(allocID string) ([]*structs.SignedWorkl
 	return entry.Identities, nil
 }
 
+// allocIdentityRenewalsEntry wraps the renewal schedules of the identities
+type allocIdentityRenewalsEntry struct {
+	Renewals []*arstate.IdentityRenewal
+}
+
+// PutAllocIdentityRenewals stores the renewal schedules of the workload
+// identities of an allocation. They will be cleared when the allocation bucket
+// is deleted.
+func (s *BoltStateDB) PutAllocIdentityRenewals(allocID string, renewals []*arstate.IdentityRenewal, opts ...WriteOption) error {
+	return s.updateWithOptions(opts, func(tx *boltdd.Tx) error {
+		allocBkt, err := getAllocationBucket(tx, allocID)
+		if err != nil {
+			return err
+		}
+
+		entry := allocIdentityRenewalsEntry{
+			Renewals: renewals,
+		}
+		return allocBkt.Put(allocIdentityRenewalsKey, &entry)
+	})
+}
+
+// GetAllocIdentityRenewals returns the renewal schedules of the workload
+// identities of an allocation, if any.
+func (s *BoltStateDB) GetAllocIdentityRenewals(allocID string) ([]*arstate.IdentityRenewal, error) {
+	var entry allocIdentityRenewalsEntry
+
+	err := s.db.View(func(tx *boltdd.Tx) error {
+		allAllocsBkt := tx.Bucket(allocationsBucketName)
+		if allAllocsBkt == nil {
+			return nil // No previous state at all
+		}
+
+		allocBkt := allAllocsBkt.Bucket([]byte(allocID))
+		if allocBkt == nil {
+			return nil // No previous state for this alloc
+		}
+
+		return allocBkt.Get(allocIdentityRenewalsKey, &entry)
+	})
+
+	if boltdd.IsErrNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.Renewals, nil
+}
+
 // GetTaskRunnerState returns the LocalState and TaskState for a
 // TaskRunner. LocalState or TaskState will be nil if they do not exist.
 //
@@ -1020,6 +1106,40 @@ func getNodeMeta(b *boltdd.Bucket) (map[string]*string, error) {
 	return m, nil
 }
 
+// PutNodeMetaInfo sets the writers and expirations of the dynamic node
+// metadata keys.
+//
+// This overwrites existing dynamic node metadata info entirely.
+func (s *BoltStateDB) PutNodeMetaInfo(info map[string]*structs.NodeMetaInfo) error {
+	return s.db.Update(func(tx *boltdd.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(nodeMetaBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(nodeMetaInfoKey, info)
+	})
+}
+
+// GetNodeMetaInfo retrieves the writers and expirations of the dynamic node
+// metadata keys.
+func (s *BoltStateDB) GetNodeMetaInfo() (map[string]*structs.NodeMetaInfo, error) {
+	info := make(map[string]*structs.NodeMetaInfo)
+	err := s.db.View(func(tx *boltdd.Tx) error {
+		b := tx.Bucket(nodeMetaBucket)
+		if b == nil {
+			return nil
+		}
+
+		if err := b.Get(nodeMetaInfoKey, &info); err != nil && !boltdd.IsErrNotFound(err) {
+			return err
+		}
+		return nil
+	})
+
+	return info, err
+}
+
 func (s *BoltStateDB) PutNodeRegistration(reg *cstructs.NodeRegistration) error {
 	return s.db.Update(func(tx *boltdd.Tx) error {
 		b, err := tx.CreateBucketIfNotExists(nodeBucket)
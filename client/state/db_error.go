@@ -78,6 +78,14 @@ func (m *ErrDB) GetAllocIdentities(_ string) ([]*structs.SignedWorkloadIdentity,
 	return nil, fmt.Errorf("Error!")
 }
 
+func (m *ErrDB) PutAllocIdentityRenewals(_ string, _ []*arstate.IdentityRenewal, _ ...WriteOption) error {
+	return fmt.Errorf("Error!")
+}
+
+func (m *ErrDB) GetAllocIdentityRenewals(_ string) ([]*arstate.IdentityRenewal, error) {
+	return nil, fmt.Errorf("Error!")
+}
+
 func (m *ErrDB) GetTaskRunnerState(allocID string, taskName string) (*state.LocalState, *structs.TaskState, error) {
 	return nil, nil, fmt.Errorf("Error!")
 }
@@ -146,6 +154,14 @@ func (m *ErrDB) GetNodeMeta() (map[string]*string, error) {
 	return nil, fmt.Errorf("Error!")
 }
 
+func (m *ErrDB) PutNodeMetaInfo(map[string]*structs.NodeMetaInfo) error {
+	return fmt.Errorf("Error!")
+}
+
+func (m *ErrDB) GetNodeMetaInfo() (map[string]*structs.NodeMetaInfo, error) {
+	return nil, fmt.Errorf("Error!")
+}
+
 func (m *ErrDB) PutNodeRegistration(reg *cstructs.NodeRegistration) error {
 	return fmt.Errorf("Error!")
 }
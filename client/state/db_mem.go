@@ -46,6 +46,9 @@ type MemDB struct {
 	// alloc_id -> []identities
 	identities map[string][]*structs.SignedWorkloadIdentity
 
+	// alloc_id -> []identity renewals
+	identityRenewals map[string][]*arstate.IdentityRenewal
+
 	// devicemanager -> plugin-state
 	devManagerPs *dmstate.PluginState
 
@@ -58,6 +61,9 @@ type MemDB struct {
 	// key -> value or nil
 	nodeMeta map[string]*string
 
+	// key -> writer and expiration
+	nodeMetaInfo map[string]*structs.NodeMetaInfo
+
 	nodeRegistration *cstructs.NodeRegistration
 
 	logger hclog.Logger
@@ -76,6 +82,7 @@ func NewMemDB(logger hclog.Logger) *MemDB {
 		taskState:         make(map[string]map[string]*structs.TaskState),
 		checks:            make(checks.ClientResults),
 		identities:        make(map[string][]*structs.SignedWorkloadIdentity),
+		identityRenewals:  make(map[string][]*arstate.IdentityRenewal),
 		logger:            logger,
 	}
 }
@@ -172,6 +179,19 @@ func (m *MemDB) GetAllocIdentities(allocID string) ([]*structs.SignedWorkloadIde
 	return m.identities[allocID], nil
 }
 
+func (m *MemDB) PutAllocIdentityRenewals(allocID string, renewals []*arstate.IdentityRenewal, _ ...WriteOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identityRenewals[allocID] = renewals
+	return nil
+}
+
+func (m *MemDB) GetAllocIdentityRenewals(allocID string) ([]*arstate.IdentityRenewal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.identityRenewals[allocID], nil
+}
+
 func (m *MemDB) GetTaskRunnerState(allocID string, taskName string) (*state.LocalState, *structs.TaskState, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -249,6 +269,7 @@ func (m *MemDB) DeleteAllocationBucket(allocID string, _ ...WriteOption) error {
 	delete(m.taskState, allocID)
 	delete(m.localTaskState, allocID)
 	delete(m.identities, allocID)
+	delete(m.identityRenewals, allocID)
 
 	return nil
 }
@@ -341,6 +362,19 @@ func (m *MemDB) GetNodeMeta() (map[string]*string, error) {
 	return m.nodeMeta, nil
 }
 
+func (m *MemDB) PutNodeMetaInfo(info map[string]*structs.NodeMetaInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodeMetaInfo = info
+	return nil
+}
+
+func (m *MemDB) GetNodeMetaInfo() (map[string]*structs.NodeMetaInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nodeMetaInfo, nil
+}
+
 func (m *MemDB) PutNodeRegistration(reg *cstructs.NodeRegistration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -69,6 +69,14 @@ func (n NoopDB) GetAllocIdentities(_ string) ([]*structs.SignedWorkloadIdentity,
 	return nil, nil
 }
 
+func (n NoopDB) PutAllocIdentityRenewals(_ string, _ []*arstate.IdentityRenewal, _ ...WriteOption) error {
+	return nil
+}
+
+func (n NoopDB) GetAllocIdentityRenewals(_ string) ([]*arstate.IdentityRenewal, error) {
+	return nil, nil
+}
+
 func (n NoopDB) GetTaskRunnerState(allocID string, taskName string) (*state.LocalState, *structs.TaskState, error) {
 	return nil, nil, nil
 }
@@ -137,6 +145,14 @@ func (n NoopDB) GetNodeMeta() (map[string]*string, error) {
 	return nil, nil
 }
 
+func (n NoopDB) PutNodeMetaInfo(map[string]*structs.NodeMetaInfo) error {
+	return nil
+}
+
+func (n NoopDB) GetNodeMetaInfo() (map[string]*structs.NodeMetaInfo, error) {
+	return nil, nil
+}
+
 func (n NoopDB) PutNodeRegistration(reg *cstructs.NodeRegistration) error {
 	return nil
 }
@@ -0,0 +1,609 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-msgpack/codec"
+	arstate "github.com/hashicorp/nomad/client/allocrunner/state"
+	trstate "github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
+	dmstate "github.com/hashicorp/nomad/client/devicemanager/state"
+	"github.com/hashicorp/nomad/client/dynamicplugins"
+	driverstate "github.com/hashicorp/nomad/client/pluginmanager/drivermanager/state"
+	"github.com/hashicorp/nomad/client/serviceregistration/checks"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+
+	// register the sqlite driver of database/sql
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// sqliteStateFile is the name of the SQLite state database in the state
+	// dir.
+	sqliteStateFile = "state.sqlite"
+
+	// sqliteBusyTimeout is how long a write waits for the lock held by another
+	// connection, in milliseconds.
+	sqliteBusyTimeout = 5000
+)
+
+// sqliteSchema creates the tables of the SQLite state database. Values are
+// msgpack encoded, like in the BoltDB state database.
+//
+// The alloc_state table holds the entries of the allocations, and the entries
+// of their tasks if task is set. The global_state table holds the entries not
+// scoped to an allocation, in the bucket they're stored in by the BoltDB state
+// database.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS alloc_state (
+	alloc_id TEXT NOT NULL,
+	task     TEXT NOT NULL,
+	key      TEXT NOT NULL,
+	value    BLOB NOT NULL,
+	PRIMARY KEY (alloc_id, task, key)
+);
+
+CREATE TABLE IF NOT EXISTS check_results (
+	alloc_id TEXT NOT NULL,
+	check_id TEXT NOT NULL,
+	value    BLOB NOT NULL,
+	PRIMARY KEY (alloc_id, check_id)
+);
+
+CREATE TABLE IF NOT EXISTS global_state (
+	bucket TEXT NOT NULL,
+	key    TEXT NOT NULL,
+	value  BLOB NOT NULL,
+	PRIMARY KEY (bucket, key)
+);
+`
+
+// SQLiteStateDB persists and restores Nomad client state in a SQLite database
+// using write-ahead logging, so a crash can't leave it partially written and
+// readers don't block on writers. All methods are safe for concurrent access.
+type SQLiteStateDB struct {
+	stateDir string
+	db       *sql.DB
+	logger   hclog.Logger
+}
+
+// NewSQLiteStateDB creates or opens an existing SQLite state database or
+// returns an error.
+//
+// When the database is created and the state dir holds a BoltDB state
+// database, the state is copied from it so the client can restore its
+// allocations after switching state stores.
+func NewSQLiteStateDB(logger hclog.Logger, stateDir string) (StateDB, error) {
+	fn := filepath.Join(stateDir, sqliteStateFile)
+
+	// Check to see if the DB already exists
+	fi, err := os.Stat(fn)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	firstRun := fi == nil
+
+	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)&_pragma=busy_timeout(%d)&_txlock=immediate",
+		fn, sqliteBusyTimeout)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %v", err)
+	}
+
+	sdb := &SQLiteStateDB{
+		stateDir: stateDir,
+		db:       db,
+		logger:   logger,
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create state database: %v", err)
+	}
+	if err := os.Chmod(fn, 0600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set permissions on state database: %v", err)
+	}
+
+	if firstRun {
+		if err := sdb.importBoltState(); err != nil {
+			db.Close()
+			os.Remove(fn)
+			return nil, err
+		}
+	}
+
+	return sdb, nil
+}
+
+func (s *SQLiteStateDB) Name() string {
+	return "sqlite"
+}
+
+// importBoltState copies the state of the BoltDB state database in the state
+// dir, if any.
+func (s *SQLiteStateDB) importBoltState() error {
+	if _, err := os.Stat(filepath.Join(s.stateDir, "state.db")); err != nil {
+		return nil
+	}
+
+	bdb, err := NewBoltStateDB(s.logger, s.stateDir)
+	if err != nil {
+		return fmt.Errorf("failed to open boltdb state to import: %v", err)
+	}
+	defer bdb.Close()
+
+	if err := bdb.Upgrade(); err != nil {
+		return fmt.Errorf("failed to upgrade boltdb state to import: %v", err)
+	}
+	if err := CopyState(bdb, s); err != nil {
+		return fmt.Errorf("failed to import boltdb state: %v", err)
+	}
+
+	s.logger.Info("imported client state from boltdb")
+	return nil
+}
+
+// Upgrade is a noop, since there's a single version of the schema of the
+// SQLite state database.
+func (s *SQLiteStateDB) Upgrade() error {
+	return nil
+}
+
+// GetAllAllocations gets all allocations persisted by this client and returns
+// a map of alloc ids to errors for any allocations that could not be restored.
+func (s *SQLiteStateDB) GetAllAllocations() ([]*structs.Allocation, map[string]error, error) {
+	rows, err := s.db.Query(`SELECT alloc_id, value FROM alloc_state WHERE task = '' AND key = ?`,
+		string(allocKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	allocs := make([]*structs.Allocation, 0, 10)
+	errs := map[string]error{}
+	for rows.Next() {
+		var allocID string
+		var data []byte
+		if err := rows.Scan(&allocID, &data); err != nil {
+			return nil, nil, err
+		}
+
+		var ae allocEntry
+		if err := decodeState(data, &ae); err != nil {
+			errs[allocID] = fmt.Errorf("failed to decode alloc: %v", err)
+			continue
+		}
+
+		// Handle upgrade path
+		ae.Alloc.Canonicalize()
+		ae.Alloc.Job.Canonicalize()
+
+		allocs = append(allocs, ae.Alloc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return allocs, errs, nil
+}
+
+// PutAllocation stores an allocation or returns an error.
+func (s *SQLiteStateDB) PutAllocation(alloc *structs.Allocation, _ ...WriteOption) error {
+	return s.putAllocValue(alloc.ID, "", allocKey, &allocEntry{Alloc: alloc})
+}
+
+// PutDeploymentStatus stores an allocation's DeploymentStatus or returns an
+// error.
+func (s *SQLiteStateDB) PutDeploymentStatus(allocID string, ds *structs.AllocDeploymentStatus) error {
+	return s.putAllocValue(allocID, "", allocDeployStatusKey, &deployStatusEntry{DeploymentStatus: ds})
+}
+
+// GetDeploymentStatus retrieves an allocation's DeploymentStatus or returns an
+// error.
+func (s *SQLiteStateDB) GetDeploymentStatus(allocID string) (*structs.AllocDeploymentStatus, error) {
+	var entry deployStatusEntry
+	if _, err := s.getAllocValue(allocID, "", allocDeployStatusKey, &entry); err != nil {
+		return nil, err
+	}
+	return entry.DeploymentStatus, nil
+}
+
+// PutNetworkStatus stores an allocation's AllocNetworkStatus or returns an
+// error.
+func (s *SQLiteStateDB) PutNetworkStatus(allocID string, ns *structs.AllocNetworkStatus, _ ...WriteOption) error {
+	return s.putAllocValue(allocID, "", allocNetworkStatusKey, &networkStatusEntry{NetworkStatus: ns})
+}
+
+// GetNetworkStatus retrieves an allocation's AllocNetworkStatus or returns an
+// error.
+func (s *SQLiteStateDB) GetNetworkStatus(allocID string) (*structs.AllocNetworkStatus, error) {
+	var entry networkStatusEntry
+	if _, err := s.getAllocValue(allocID, "", allocNetworkStatusKey, &entry); err != nil {
+		return nil, err
+	}
+	return entry.NetworkStatus, nil
+}
+
+// PutAcknowledgedState stores an allocation's last acknowledged state or
+// returns an error if it could not be stored.
+func (s *SQLiteStateDB) PutAcknowledgedState(allocID string, state *arstate.State, _ ...WriteOption) error {
+	return s.putAllocValue(allocID, "", acknowledgedStateKey, &acknowledgedStateEntry{State: state})
+}
+
+// GetAcknowledgedState retrieves an allocation's last acknowledged state
+func (s *SQLiteStateDB) GetAcknowledgedState(allocID string) (*arstate.State, error) {
+	var entry acknowledgedStateEntry
+	if _, err := s.getAllocValue(allocID, "", acknowledgedStateKey, &entry); err != nil {
+		return nil, err
+	}
+	return entry.State, nil
+}
+
+// PutAllocVolumes stores stubs of an allocation's dynamic volume mounts so
+// they can be restored.
+func (s *SQLiteStateDB) PutAllocVolumes(allocID string, state *arstate.AllocVolumes, _ ...WriteOption) error {
+	return s.putAllocValue(allocID, "", allocVolumeKey, &allocVolumeStatesEntry{State: state})
+}
+
+// GetAllocVolumes retrieves stubs of an allocation's dynamic volume mounts so
+// they can be restored.
+func (s *SQLiteStateDB) GetAllocVolumes(allocID string) (*arstate.AllocVolumes, error) {
+	var entry allocVolumeStatesEntry
+	if _, err := s.getAllocValue(allocID, "", allocVolumeKey, &entry); err != nil {
+		return nil, err
+	}
+	return entry.State, nil
+}
+
+// PutAllocIdentities stores the signed workload identities of an allocation.
+func (s *SQLiteStateDB) PutAllocIdentities(allocID string, identities []*structs.SignedWorkloadIdentity, _ ...WriteOption) error {
+	return s.putAllocValue(allocID, "", allocIdentityKey, &allocIdentitiesEntry{Identities: identities})
+}
+
+// GetAllocIdentities retrieves the signed workload identities of an
+// allocation.
+func (s *SQLiteStateDB) GetAllocIdentities(allocID string) ([]*structs.SignedWorkloadIdentity, error) {
+	var entry allocIdentitiesEntry
+	if _, err := s.getAllocValue(allocID, "", allocIdentityKey, &entry); err != nil {
+		return nil, err
+	}
+	return entry.Identities, nil
+}
+
+// PutAllocIdentityRenewals stores the renewal schedules of the workload
+// identities of an allocation.
+func (s *SQLiteStateDB) PutAllocIdentityRenewals(allocID string, renewals []*arstate.IdentityRenewal, _ ...WriteOption) error {
+	return s.putAllocValue(allocID, "", allocIdentityRenewalsKey, &allocIdentityRenewalsEntry{Renewals: renewals})
+}
+
+// GetAllocIdentityRenewals retrieves the renewal schedules of the workload
+// identities of an allocation.
+func (s *SQLiteStateDB) GetAllocIdentityRenewals(allocID string) ([]*arstate.IdentityRenewal, error) {
+	var entry allocIdentityRenewalsEntry
+	if _, err := s.getAllocValue(allocID, "", allocIdentityRenewalsKey, &entry); err != nil {
+		return nil, err
+	}
+	return entry.Renewals, nil
+}
+
+// GetTaskRunnerState returns the LocalState and TaskState for a
+// TaskRunner. LocalState or TaskState will be nil if they do not exist.
+//
+// If an error is encountered both LocalState and TaskState will be nil.
+func (s *SQLiteStateDB) GetTaskRunnerState(allocID, taskName string) (*trstate.LocalState, *structs.TaskState, error) {
+	ls := &trstate.LocalState{}
+	found, err := s.getAllocValue(allocID, taskName, taskLocalStateKey, ls)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read local task runner state: %v", err)
+	}
+	if !found {
+		ls = nil
+	}
+
+	ts := &structs.TaskState{}
+	found, err = s.getAllocValue(allocID, taskName, taskStateKey, ts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read task state: %v", err)
+	}
+	if !found {
+		ts = nil
+	}
+
+	return ls, ts, nil
+}
+
+// PutTaskRunnerLocalState stores TaskRunner's LocalState or returns an error.
+func (s *SQLiteStateDB) PutTaskRunnerLocalState(allocID, taskName string, val *trstate.LocalState) error {
+	if err := s.putAllocValue(allocID, taskName, taskLocalStateKey, val); err != nil {
+		return fmt.Errorf("failed to write task_runner state: %v", err)
+	}
+	return nil
+}
+
+// PutTaskState stores a task's state or returns an error.
+func (s *SQLiteStateDB) PutTaskState(allocID, taskName string, state *structs.TaskState) error {
+	return s.putAllocValue(allocID, taskName, taskStateKey, state)
+}
+
+// DeleteTaskBucket is used to delete the entries of a task if they exist.
+func (s *SQLiteStateDB) DeleteTaskBucket(allocID, taskName string) error {
+	_, err := s.db.Exec(`DELETE FROM alloc_state WHERE alloc_id = ? AND task = ?`, allocID, taskName)
+	return err
+}
+
+// DeleteAllocationBucket is used to delete the entries of an allocation and
+// of its tasks if they exist.
+func (s *SQLiteStateDB) DeleteAllocationBucket(allocID string, _ ...WriteOption) error {
+	_, err := s.db.Exec(`DELETE FROM alloc_state WHERE alloc_id = ?`, allocID)
+	return err
+}
+
+// PutDevicePluginState stores the device manager's plugin state or returns an
+// error.
+func (s *SQLiteStateDB) PutDevicePluginState(ps *dmstate.PluginState) error {
+	return s.putGlobalValue(devManagerBucket, managerPluginStateKey, ps)
+}
+
+// GetDevicePluginState stores the device manager's plugin state or returns an
+// error.
+func (s *SQLiteStateDB) GetDevicePluginState() (*dmstate.PluginState, error) {
+	ps := &dmstate.PluginState{}
+	found, err := s.getGlobalValue(devManagerBucket, managerPluginStateKey, ps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device manager plugin state: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return ps, nil
+}
+
+// PutDriverPluginState stores the driver manager's plugin state or returns an
+// error.
+func (s *SQLiteStateDB) PutDriverPluginState(ps *driverstate.PluginState) error {
+	return s.putGlobalValue(driverManagerBucket, managerPluginStateKey, ps)
+}
+
+// GetDriverPluginState stores the driver manager's plugin state or returns an
+// error.
+func (s *SQLiteStateDB) GetDriverPluginState() (*driverstate.PluginState, error) {
+	ps := &driverstate.PluginState{}
+	found, err := s.getGlobalValue(driverManagerBucket, managerPluginStateKey, ps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read driver manager plugin state: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return ps, nil
+}
+
+// PutDynamicPluginRegistryState stores the dynamic plugin registry's
+// state or returns an error.
+func (s *SQLiteStateDB) PutDynamicPluginRegistryState(ps *dynamicplugins.RegistryState) error {
+	return s.putGlobalValue(dynamicPluginBucketName, registryStateKey, ps)
+}
+
+// GetDynamicPluginRegistryState stores the dynamic plugin registry's
+// registry state or returns an error.
+func (s *SQLiteStateDB) GetDynamicPluginRegistryState() (*dynamicplugins.RegistryState, error) {
+	ps := &dynamicplugins.RegistryState{}
+	found, err := s.getGlobalValue(dynamicPluginBucketName, registryStateKey, ps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dynamic plugin registry state: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return ps, nil
+}
+
+// PutCheckResult puts qr into the state store.
+func (s *SQLiteStateDB) PutCheckResult(allocID string, qr *structs.CheckQueryResult) error {
+	data, err := encodeState(qr)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO check_results (alloc_id, check_id, value) VALUES (?, ?, ?)`,
+		allocID, string(qr.ID), data)
+	return err
+}
+
+// GetCheckResults gets the check results of all allocations from the state
+// store.
+func (s *SQLiteStateDB) GetCheckResults() (checks.ClientResults, error) {
+	m := make(checks.ClientResults)
+
+	rows, err := s.db.Query(`SELECT alloc_id, value FROM check_results`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var allocID string
+		var data []byte
+		if err := rows.Scan(&allocID, &data); err != nil {
+			return nil, err
+		}
+
+		var qr structs.CheckQueryResult
+		if err := decodeState(data, &qr); err != nil {
+			return nil, fmt.Errorf("failed to decode data into passed object: %v", err)
+		}
+		m.Insert(allocID, &qr)
+	}
+	return m, rows.Err()
+}
+
+func (s *SQLiteStateDB) DeleteCheckResults(allocID string, checkIDs []structs.CheckID) error {
+	return s.update(func(tx *sql.Tx) error {
+		for _, id := range checkIDs {
+			if _, err := tx.Exec(`DELETE FROM check_results WHERE alloc_id = ? AND check_id = ?`,
+				allocID, string(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStateDB) PurgeCheckResults(allocID string) error {
+	_, err := s.db.Exec(`DELETE FROM check_results WHERE alloc_id = ?`, allocID)
+	return err
+}
+
+// PutNodeMeta sets dynamic node metadata for merging with the copy from the
+// Client's config.
+//
+// This overwrites existing dynamic node metadata entirely.
+func (s *SQLiteStateDB) PutNodeMeta(meta map[string]*string) error {
+	return s.putGlobalValue(nodeMetaBucket, nodeMetaKey, meta)
+}
+
+// GetNodeMeta retrieves node metadata for merging with the copy from
+// the Client's config.
+func (s *SQLiteStateDB) GetNodeMeta() (map[string]*string, error) {
+	m := make(map[string]*string)
+	found, err := s.getGlobalValue(nodeMetaBucket, nodeMetaKey, &m)
+	if err != nil || !found {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PutNodeMetaInfo sets the writers and expirations of the dynamic node
+// metadata keys.
+//
+// This overwrites existing dynamic node metadata info entirely.
+func (s *SQLiteStateDB) PutNodeMetaInfo(info map[string]*structs.NodeMetaInfo) error {
+	return s.putGlobalValue(nodeMetaBucket, nodeMetaInfoKey, info)
+}
+
+// GetNodeMetaInfo retrieves the writers and expirations of the dynamic node
+// metadata keys.
+func (s *SQLiteStateDB) GetNodeMetaInfo() (map[string]*structs.NodeMetaInfo, error) {
+	info := make(map[string]*structs.NodeMetaInfo)
+	if _, err := s.getGlobalValue(nodeMetaBucket, nodeMetaInfoKey, &info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (s *SQLiteStateDB) PutNodeRegistration(reg *cstructs.NodeRegistration) error {
+	return s.putGlobalValue(nodeBucket, nodeRegistrationKey, reg)
+}
+
+func (s *SQLiteStateDB) GetNodeRegistration() (*cstructs.NodeRegistration, error) {
+	var reg cstructs.NodeRegistration
+	found, err := s.getGlobalValue(nodeBucket, nodeRegistrationKey, &reg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+// Close releases all database resources. The write-ahead log is checkpointed
+// into the database file by the last connection to close.
+func (s *SQLiteStateDB) Close() error {
+	return s.db.Close()
+}
+
+// putAllocValue stores an entry of an allocation, or of one of its tasks if
+// task is set.
+func (s *SQLiteStateDB) putAllocValue(allocID, task string, key []byte, val any) error {
+	data, err := encodeState(val)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO alloc_state (alloc_id, task, key, value) VALUES (?, ?, ?, ?)`,
+		allocID, task, string(key), data)
+	if err != nil {
+		return fmt.Errorf("failed to write data at key %s: %v", key, err)
+	}
+	return nil
+}
+
+// getAllocValue decodes an entry of an allocation, or of one of its tasks if
+// task is set, into obj. It returns false if the entry doesn't exist.
+func (s *SQLiteStateDB) getAllocValue(allocID, task string, key []byte, obj any) (bool, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT value FROM alloc_state WHERE alloc_id = ? AND task = ? AND key = ?`,
+		allocID, task, string(key)).Scan(&data)
+	return decodeRow(data, err, obj)
+}
+
+// putGlobalValue stores an entry not scoped to an allocation.
+func (s *SQLiteStateDB) putGlobalValue(bucket, key []byte, val any) error {
+	data, err := encodeState(val)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO global_state (bucket, key, value) VALUES (?, ?, ?)`,
+		string(bucket), string(key), data)
+	if err != nil {
+		return fmt.Errorf("failed to write data at key %s: %v", key, err)
+	}
+	return nil
+}
+
+// getGlobalValue decodes an entry not scoped to an allocation into obj. It
+// returns false if the entry doesn't exist.
+func (s *SQLiteStateDB) getGlobalValue(bucket, key []byte, obj any) (bool, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT value FROM global_state WHERE bucket = ? AND key = ?`,
+		string(bucket), string(key)).Scan(&data)
+	return decodeRow(data, err, obj)
+}
+
+// update runs fn in a transaction, which is committed if fn returns no error.
+func (s *SQLiteStateDB) update(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// decodeRow decodes the value of a row queried for a single value.
+func decodeRow(data []byte, err error, obj any) (bool, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := decodeState(data, obj); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func encodeState(val any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := codec.NewEncoder(&buf, structs.MsgpackHandle).Encode(val); err != nil {
+		return nil, fmt.Errorf("failed to encode passed object: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeState(data []byte, obj any) error {
+	if err := codec.NewDecoderBytes(data, structs.MsgpackHandle).Decode(obj); err != nil {
+		return fmt.Errorf("failed to decode data into passed object: %v", err)
+	}
+	return nil
+}
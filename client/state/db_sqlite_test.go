@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	trstate "github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/shoenig/test/must"
+)
+
+// TestSQLiteStateDB_ImportBolt asserts the state of a client switched from
+// the boltdb state store is imported into the sqlite state store.
+func TestSQLiteStateDB_ImportBolt(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := t.TempDir()
+	logger := testlog.HCLogger(t)
+
+	bolt, err := NewBoltStateDB(logger, dir)
+	must.NoError(t, err)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	must.NoError(t, bolt.PutAllocation(alloc))
+	must.NoError(t, bolt.PutTaskRunnerLocalState(alloc.ID, task.Name, &trstate.LocalState{
+		TaskHandle: drivers.NewTaskHandle(1),
+	}))
+	must.NoError(t, bolt.PutTaskState(alloc.ID, task.Name, structs.NewTaskState()))
+	must.NoError(t, bolt.PutNodeMeta(map[string]*string{"foo": nil}))
+	must.NoError(t, bolt.Close())
+
+	db, err := NewSQLiteStateDB(logger, dir)
+	must.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	must.FileExists(t, filepath.Join(dir, "state.sqlite"))
+
+	allocs, errs, err := db.GetAllAllocations()
+	must.NoError(t, err)
+	must.MapEmpty(t, errs)
+	must.Len(t, 1, allocs)
+	must.Eq(t, alloc.ID, allocs[0].ID)
+
+	ls, ts, err := db.GetTaskRunnerState(alloc.ID, task.Name)
+	must.NoError(t, err)
+	must.NotNil(t, ls.TaskHandle)
+	must.NotNil(t, ts)
+
+	meta, err := db.GetNodeMeta()
+	must.NoError(t, err)
+	must.MapContainsKey(t, meta, "foo")
+}
+
+func TestGetStateStoreFactory(t *testing.T) {
+	ci.Parallel(t)
+
+	for _, store := range []string{"", StateStoreBoltDB, StateStoreSQLite} {
+		factory, err := GetStateStoreFactory(store, false)
+		must.NoError(t, err)
+
+		db, err := factory(testlog.HCLogger(t), t.TempDir())
+		must.NoError(t, err)
+		if store == StateStoreSQLite {
+			must.Eq(t, "sqlite", db.Name())
+		} else {
+			must.Eq(t, "boltdb", db.Name())
+		}
+		must.NoError(t, db.Close())
+	}
+
+	_, err := GetStateStoreFactory("leveldb", false)
+	must.ErrorContains(t, err, "unknown state store")
+}
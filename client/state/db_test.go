@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/nomad/ci"
+	arstate "github.com/hashicorp/nomad/client/allocrunner/state"
 	trstate "github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
 	dmstate "github.com/hashicorp/nomad/client/devicemanager/state"
 	"github.com/hashicorp/nomad/client/dynamicplugins"
@@ -26,6 +27,7 @@ import (
 // assert each implementation satisfies StateDB interface
 var (
 	_ StateDB = (*BoltStateDB)(nil)
+	_ StateDB = (*SQLiteStateDB)(nil)
 	_ StateDB = (*MemDB)(nil)
 	_ StateDB = (*NoopDB)(nil)
 	_ StateDB = (*ErrDB)(nil)
@@ -51,9 +53,23 @@ func setupBoltStateDB(t *testing.T) *BoltStateDB {
 	return db.(*BoltStateDB)
 }
 
+func setupSQLiteStateDB(t *testing.T) *SQLiteStateDB {
+	db, err := NewSQLiteStateDB(testlog.HCLogger(t), t.TempDir())
+	must.NoError(t, err)
+
+	t.Cleanup(func() {
+		if closeErr := db.Close(); closeErr != nil {
+			t.Errorf("error closing sqlite db: %v", closeErr)
+		}
+	})
+
+	return db.(*SQLiteStateDB)
+}
+
 func testDB(t *testing.T, f func(*testing.T, StateDB)) {
 	dbs := []StateDB{
 		setupBoltStateDB(t),
+		setupSQLiteStateDB(t),
 		NewMemDB(testlog.HCLogger(t)),
 	}
 
@@ -309,6 +325,43 @@ func TestStateDB_TaskState(t *testing.T) {
 	})
 }
 
+// TestStateDB_IdentityRenewals asserts the renewal schedules of the identities
+// of an allocation are stored, and deleted with the allocation.
+func TestStateDB_IdentityRenewals(t *testing.T) {
+	ci.Parallel(t)
+
+	testDB(t, func(t *testing.T, db StateDB) {
+		alloc := mock.Alloc()
+		must.NoError(t, db.PutAllocation(alloc))
+
+		renewals, err := db.GetAllocIdentityRenewals(alloc.ID)
+		must.NoError(t, err)
+		must.SliceEmpty(t, renewals)
+
+		now := time.Now().Round(0)
+		renewal := &arstate.IdentityRenewal{
+			WIHandle:    structs.WIHandle{WorkloadIdentifier: "web", IdentityName: "vault"},
+			Expiration:  now.Add(time.Hour),
+			LastRenewal: now,
+			NextRenewal: now.Add(45 * time.Minute),
+		}
+		must.NoError(t, db.PutAllocIdentityRenewals(alloc.ID, []*arstate.IdentityRenewal{renewal}))
+
+		renewals, err = db.GetAllocIdentityRenewals(alloc.ID)
+		must.NoError(t, err)
+		must.SliceLen(t, 1, renewals)
+		must.Eq(t, renewal.WIHandle, renewals[0].WIHandle)
+		must.True(t, renewal.Expiration.Equal(renewals[0].Expiration))
+		must.True(t, renewal.LastRenewal.Equal(renewals[0].LastRenewal))
+		must.True(t, renewal.NextRenewal.Equal(renewals[0].NextRenewal))
+
+		must.NoError(t, db.DeleteAllocationBucket(alloc.ID))
+		renewals, err = db.GetAllocIdentityRenewals(alloc.ID)
+		must.NoError(t, err)
+		must.SliceEmpty(t, renewals)
+	})
+}
+
 // TestStateDB_DeviceManager asserts the behavior of device manager state related StateDB
 // methods.
 func TestStateDB_DeviceManager(t *testing.T) {
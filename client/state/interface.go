@@ -69,6 +69,14 @@ type StateDB interface {
 	// an allocation.
 	GetAllocIdentities(allocID string) ([]*structs.SignedWorkloadIdentity, error)
 
+	// PutAllocIdentityRenewals stores the renewal schedules of the workload
+	// identities of an allocation.
+	PutAllocIdentityRenewals(allocID string, renewals []*arstate.IdentityRenewal, opts ...WriteOption) error
+
+	// GetAllocIdentityRenewals returns the renewal schedules of the workload
+	// identities of an allocation.
+	GetAllocIdentityRenewals(allocID string) ([]*arstate.IdentityRenewal, error)
+
 	// GetTaskRunnerState returns the LocalState and TaskState for a
 	// TaskRunner. Either state may be nil if it is not found, but if an
 	// error is encountered only the error will be non-nil.
@@ -134,6 +142,16 @@ type StateDB interface {
 	// the Client's config.
 	GetNodeMeta() (map[string]*string, error)
 
+	// PutNodeMetaInfo sets the writers and expirations of the dynamic node
+	// metadata keys.
+	//
+	// This overwrites existing dynamic node metadata info entirely.
+	PutNodeMetaInfo(map[string]*structs.NodeMetaInfo) error
+
+	// GetNodeMetaInfo retrieves the writers and expirations of the dynamic
+	// node metadata keys.
+	GetNodeMetaInfo() (map[string]*structs.NodeMetaInfo, error)
+
 	PutNodeRegistration(*cstructs.NodeRegistration) error
 	GetNodeRegistration() (*cstructs.NodeRegistration, error)
 
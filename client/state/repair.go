@@ -0,0 +1,294 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	trstate "github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
+	"github.com/hashicorp/nomad/helper/boltdd"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// repairBackupFile is the name of the backup of the state database made before
+// it's repaired.
+const repairBackupFile = "state.db.repair.backup"
+
+// StateSummary summarizes the content of a client state database, for the
+// inspection of the state of a stopped client.
+type StateSummary struct {
+	// Version is the schema version of the database, and NeedsUpgrade is
+	// true if the schema must be upgraded before the client can use it.
+	Version      string
+	NeedsUpgrade bool
+
+	Allocations []*AllocSummary
+}
+
+// AllocSummary summarizes the state of an allocation.
+type AllocSummary struct {
+	ID           string
+	JobID        string `json:",omitempty"`
+	TaskGroup    string `json:",omitempty"`
+	ClientStatus string `json:",omitempty"`
+	Tasks        []*TaskSummary
+
+	// Errors are the entries of the allocation that can't be decoded.
+	Errors []string `json:",omitempty"`
+}
+
+// TaskSummary summarizes the state of a task of an allocation.
+type TaskSummary struct {
+	Name   string
+	Driver string `json:",omitempty"`
+	State  string `json:",omitempty"`
+
+	// HasHandle is true if the task has a driver handle the client reattaches
+	// to on restore.
+	HasHandle bool
+
+	// Errors are the entries of the task that can't be decoded.
+	Errors []string `json:",omitempty"`
+}
+
+// RepairAction is an entry of the state database dropped by Repair.
+type RepairAction struct {
+	AllocID string
+	Task    string `json:",omitempty"`
+	Key     string `json:",omitempty"`
+	Reason  string
+}
+
+func (a *RepairAction) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "alloc %s", a.AllocID)
+	if a.Task != "" {
+		fmt.Fprintf(&b, " task %s", a.Task)
+	}
+	if a.Key != "" {
+		fmt.Fprintf(&b, " key %q", a.Key)
+	}
+	fmt.Fprintf(&b, ": %s", a.Reason)
+	return b.String()
+}
+
+// allocValueKeys are the keys of the entries of an allocation bucket, other
+// than the allocation itself, with a func returning the value they decode to.
+var allocValueKeys = []struct {
+	key   []byte
+	entry func() any
+}{
+	{allocDeployStatusKey, func() any { return &deployStatusEntry{} }},
+	{allocNetworkStatusKey, func() any { return &networkStatusEntry{} }},
+	{acknowledgedStateKey, func() any { return &acknowledgedStateEntry{} }},
+	{allocVolumeKey, func() any { return &allocVolumeStatesEntry{} }},
+	{allocIdentityKey, func() any { return &allocIdentitiesEntry{} }},
+	{allocIdentityRenewalsKey, func() any { return &allocIdentityRenewalsEntry{} }},
+}
+
+// Inspect returns a summary of the allocations and tasks in the state
+// database, including the entries that can't be decoded. It's meant to be used
+// on the state of a stopped client.
+func (s *BoltStateDB) Inspect() (*StateSummary, error) {
+	upgrade09, upgrade13, err := NeedsUpgrade(s.db.BoltDB())
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &StateSummary{
+		NeedsUpgrade: upgrade09 || upgrade13,
+		Allocations:  []*AllocSummary{},
+	}
+
+	err = s.db.View(func(tx *boltdd.Tx) error {
+		if bkt := tx.Bucket(metaBucketName); bkt != nil {
+			summary.Version = string(bkt.BoltBucket().Get(metaVersionKey))
+		}
+
+		return walkAllocs(tx, func(action *RepairAction) {
+			// Corrupt entries are reported by the summaries
+		}, func(as *AllocSummary) {
+			summary.Allocations = append(summary.Allocations, as)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Repair drops the entries of the state database that can't be decoded, so
+// the client can restore the remaining allocations instead of failing or
+// requiring its data dir to be wiped. An allocation that can't be decoded is
+// dropped entirely, while only the corrupt entries of the other allocations
+// and tasks are dropped. The database is backed up before it's modified.
+//
+// If dryRun is true the entries are only returned and not dropped.
+func (s *BoltStateDB) Repair(dryRun bool) ([]*RepairAction, error) {
+	var actions []*RepairAction
+	err := s.db.View(func(tx *boltdd.Tx) error {
+		return walkAllocs(tx, func(action *RepairAction) {
+			actions = append(actions, action)
+		}, nil)
+	})
+	if err != nil || dryRun || len(actions) == 0 {
+		return actions, err
+	}
+
+	backupFileName := filepath.Join(s.stateDir, repairBackupFile)
+	if err := backupDB(s.db.BoltDB(), backupFileName); err != nil {
+		return nil, fmt.Errorf("error backing up state db: %v", err)
+	}
+
+	err = s.db.Update(func(tx *boltdd.Tx) error {
+		allocations := tx.Bucket(allocationsBucketName)
+		for _, action := range actions {
+			if err := dropEntry(allocations, action); err != nil {
+				return fmt.Errorf("failed to drop %s: %v", action, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("successfully repaired state", "dropped", len(actions), "backup", backupFileName)
+	return actions, nil
+}
+
+// dropEntry drops the entry of a repair action from the allocations bucket.
+func dropEntry(allocations *boltdd.Bucket, action *RepairAction) error {
+	allocKeyName := []byte(action.AllocID)
+	switch {
+	case action.Key == "":
+		// The whole allocation, which may be a value instead of a bucket
+		if allocations.Bucket(allocKeyName) == nil {
+			return allocations.BoltBucket().Delete(allocKeyName)
+		}
+		return allocations.DeleteBucket(allocKeyName)
+	case action.Task == "":
+		return allocations.Bucket(allocKeyName).Delete([]byte(action.Key))
+	default:
+		return allocations.Bucket(allocKeyName).
+			Bucket(taskBucketName(action.Task)).
+			Delete([]byte(action.Key))
+	}
+}
+
+// walkAllocs decodes the entries of each allocation of the state database,
+// calling corrupt for each entry that can't be decoded and, if set, summary
+// for each allocation.
+func walkAllocs(tx *boltdd.Tx, corrupt func(*RepairAction), summary func(*AllocSummary)) error {
+	allocations := tx.Bucket(allocationsBucketName)
+	if allocations == nil {
+		return nil
+	}
+
+	c := allocations.BoltBucket().Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		allocID := string(k)
+		as := &AllocSummary{ID: allocID, Tasks: []*TaskSummary{}}
+
+		// Once the whole allocation is dropped, its other entries needn't be
+		allocDropped := false
+		drop := func(task, key, reason string) {
+			if allocDropped {
+				return
+			}
+			allocDropped = key == ""
+			corrupt(&RepairAction{AllocID: allocID, Task: task, Key: key, Reason: reason})
+		}
+
+		allocBkt := allocations.Bucket(k)
+		if v != nil || allocBkt == nil {
+			as.Errors = append(as.Errors, "missing alloc bucket")
+			drop("", "", "missing alloc bucket")
+			if summary != nil {
+				summary(as)
+			}
+			continue
+		}
+
+		var ae allocEntry
+		var tg *structs.TaskGroup
+		if err := allocBkt.Get(allocKey, &ae); err != nil || ae.Alloc == nil {
+			reason := "missing alloc"
+			if err != nil && !boltdd.IsErrNotFound(err) {
+				reason = fmt.Sprintf("failed to decode alloc: %v", err)
+			}
+			as.Errors = append(as.Errors, reason)
+			drop("", "", reason)
+		} else {
+			as.JobID = ae.Alloc.JobID
+			as.TaskGroup = ae.Alloc.TaskGroup
+			as.ClientStatus = ae.Alloc.ClientStatus
+			if ae.Alloc.Job != nil {
+				tg = ae.Alloc.Job.LookupTaskGroup(ae.Alloc.TaskGroup)
+			}
+		}
+
+		for _, kv := range allocValueKeys {
+			if err := allocBkt.Get(kv.key, kv.entry()); err != nil && !boltdd.IsErrNotFound(err) {
+				reason := fmt.Sprintf("failed to decode %s: %v", kv.key, err)
+				as.Errors = append(as.Errors, reason)
+				drop("", string(kv.key), reason)
+			}
+		}
+
+		tc := allocBkt.BoltBucket().Cursor()
+		for tk, tv := tc.First(); tk != nil; tk, tv = tc.Next() {
+			if tv != nil || !bytes.HasPrefix(tk, []byte("task-")) {
+				continue
+			}
+			ts := walkTask(allocBkt.Bucket(tk), strings.TrimPrefix(string(tk), "task-"), drop)
+			if tg != nil {
+				if task := tg.LookupTask(ts.Name); task != nil {
+					ts.Driver = task.Driver
+				}
+			}
+			as.Tasks = append(as.Tasks, ts)
+		}
+		sort.Slice(as.Tasks, func(i, j int) bool { return as.Tasks[i].Name < as.Tasks[j].Name })
+
+		if summary != nil {
+			summary(as)
+		}
+	}
+	return nil
+}
+
+// walkTask decodes the entries of a task bucket, calling drop for each entry
+// that can't be decoded.
+func walkTask(taskBkt *boltdd.Bucket, taskName string, drop func(task, key, reason string)) *TaskSummary {
+	ts := &TaskSummary{Name: taskName}
+
+	var ls trstate.LocalState
+	if err := taskBkt.Get(taskLocalStateKey, &ls); err != nil {
+		if !boltdd.IsErrNotFound(err) {
+			reason := fmt.Sprintf("failed to decode local task runner state: %v", err)
+			ts.Errors = append(ts.Errors, reason)
+			drop(taskName, string(taskLocalStateKey), reason)
+		}
+	} else {
+		ts.HasHandle = ls.TaskHandle != nil
+	}
+
+	var tstate structs.TaskState
+	if err := taskBkt.Get(taskStateKey, &tstate); err != nil {
+		if !boltdd.IsErrNotFound(err) {
+			reason := fmt.Sprintf("failed to decode task state: %v", err)
+			ts.Errors = append(ts.Errors, reason)
+			drop(taskName, string(taskStateKey), reason)
+		}
+	} else {
+		ts.State = tstate.State
+	}
+
+	return ts
+}
@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	trstate "github.com/hashicorp/nomad/client/allocrunner/taskrunner/state"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/shoenig/test/must"
+	"go.etcd.io/bbolt"
+)
+
+// corruptEntry overwrites an entry of the allocations bucket with bytes that
+// can't be decoded. The path is the buckets leading to the entry.
+func corruptEntry(t *testing.T, db *BoltStateDB, path ...[]byte) {
+	err := db.DB().BoltDB().Update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket(allocationsBucketName)
+		for _, name := range path[:len(path)-1] {
+			bkt = bkt.Bucket(name)
+		}
+		return bkt.Put(path[len(path)-1], []byte{0xc1})
+	})
+	must.NoError(t, err)
+}
+
+func TestStateDB_Repair(t *testing.T) {
+	ci.Parallel(t)
+
+	db := setupBoltStateDB(t)
+
+	// an alloc with a running task and a corrupt task state
+	alloc1 := mock.Alloc()
+	task := alloc1.Job.TaskGroups[0].Tasks[0]
+	must.NoError(t, db.PutAllocation(alloc1))
+	must.NoError(t, db.PutTaskRunnerLocalState(alloc1.ID, task.Name, &trstate.LocalState{
+		TaskHandle: drivers.NewTaskHandle(1),
+	}))
+	must.NoError(t, db.PutTaskState(alloc1.ID, task.Name, structs.NewTaskState()))
+	corruptEntry(t, db, []byte(alloc1.ID), taskBucketName(task.Name), taskStateKey)
+
+	// a corrupt alloc
+	alloc2 := mock.Alloc()
+	must.NoError(t, db.PutAllocation(alloc2))
+	must.NoError(t, db.PutTaskState(alloc2.ID, task.Name, structs.NewTaskState()))
+	corruptEntry(t, db, []byte(alloc2.ID), allocKey)
+
+	// a healthy alloc
+	alloc3 := mock.Alloc()
+	must.NoError(t, db.PutAllocation(alloc3))
+
+	summary, err := db.Inspect()
+	must.NoError(t, err)
+	must.Eq(t, string(metaVersion), summary.Version)
+	must.False(t, summary.NeedsUpgrade)
+	must.Len(t, 3, summary.Allocations)
+
+	summaries := map[string]*AllocSummary{}
+	for _, as := range summary.Allocations {
+		summaries[as.ID] = as
+	}
+
+	as := summaries[alloc1.ID]
+	must.Eq(t, alloc1.JobID, as.JobID)
+	must.SliceEmpty(t, as.Errors)
+	must.Len(t, 1, as.Tasks)
+	must.Eq(t, task.Name, as.Tasks[0].Name)
+	must.Eq(t, task.Driver, as.Tasks[0].Driver)
+	must.True(t, as.Tasks[0].HasHandle)
+	must.Len(t, 1, as.Tasks[0].Errors)
+
+	must.Len(t, 1, summaries[alloc2.ID].Errors)
+	must.SliceEmpty(t, summaries[alloc3.ID].Errors)
+
+	// a dry run doesn't modify the state
+	actions, err := db.Repair(true)
+	must.NoError(t, err)
+	must.Len(t, 2, actions)
+	must.FileNotExists(t, filepath.Join(db.stateDir, repairBackupFile))
+
+	_, errs, err := db.GetAllAllocations()
+	must.NoError(t, err)
+	must.MapLen(t, 1, errs)
+
+	actions, err = db.Repair(false)
+	must.NoError(t, err)
+	must.Len(t, 2, actions)
+	must.FileExists(t, filepath.Join(db.stateDir, repairBackupFile))
+
+	byAlloc := map[string]*RepairAction{}
+	for _, action := range actions {
+		byAlloc[action.AllocID] = action
+	}
+	must.Eq(t, task.Name, byAlloc[alloc1.ID].Task)
+	must.Eq(t, string(taskStateKey), byAlloc[alloc1.ID].Key)
+	must.Eq(t, "", byAlloc[alloc2.ID].Key)
+
+	// the corrupt alloc is dropped, and the running task keeps its handle
+	allocs, errs, err := db.GetAllAllocations()
+	must.NoError(t, err)
+	must.MapEmpty(t, errs)
+	must.Len(t, 2, allocs)
+
+	ls, ts, err := db.GetTaskRunnerState(alloc1.ID, task.Name)
+	must.NoError(t, err)
+	must.NotNil(t, ls.TaskHandle)
+	must.Nil(t, ts)
+
+	// nothing is left to repair
+	actions, err = db.Repair(false)
+	must.NoError(t, err)
+	must.SliceEmpty(t, actions)
+}
@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package client
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocwatcher"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// startStaticJobs runs the allocations of the static jobs of the client so the
+// node runs its workloads before it connects to the servers. Allocations
+// restored from the client state are kept as is unless their job changed.
+func (c *Client) startStaticJobs() {
+	conf := c.GetConfig()
+	if len(conf.StaticJobs) == 0 {
+		return
+	}
+
+	for _, job := range conf.StaticJobs {
+		for _, alloc := range staticJobAllocs(conf.Node, job) {
+			c.allocLock.Lock()
+			c.staticAllocs[alloc.ID] = struct{}{}
+			ar, restored := c.allocs[alloc.ID]
+			c.allocLock.Unlock()
+
+			if restored {
+				if !ar.Alloc().Job.SpecChanged(job) {
+					continue
+				}
+
+				// Wait for the allocation of the previous version of the
+				// job to be destroyed before replacing it.
+				c.logger.Info("static job changed, replacing allocation",
+					"job_id", job.ID, "alloc_id", alloc.ID)
+				c.allocLock.Lock()
+				delete(c.allocs, alloc.ID)
+				c.allocLock.Unlock()
+				ar.Destroy()
+				<-ar.DestroyCh()
+			}
+
+			if err := c.addStaticAlloc(alloc); err != nil {
+				c.logger.Error("error running static job allocation",
+					"error", err, "job_id", job.ID, "alloc_id", alloc.ID)
+			}
+		}
+	}
+}
+
+// addStaticAlloc runs an allocation of a static job. Unlike addAlloc it
+// doesn't wait for previous allocations, which are only known to the servers.
+func (c *Client) addStaticAlloc(alloc *structs.Allocation) error {
+	c.allocLock.Lock()
+	defer c.allocLock.Unlock()
+
+	if err := c.stateDB.PutAllocation(alloc); err != nil {
+		return err
+	}
+
+	arConf := c.newAllocRunnerConfig(alloc, allocwatcher.NoopPrevAlloc{}, allocwatcher.NoopPrevAlloc{})
+	ar, err := c.allocrunnerFactory(arConf)
+	if err != nil {
+		return err
+	}
+	c.allocs[alloc.ID] = ar
+	c.heartbeatStop.allocHook(alloc)
+
+	go ar.Run()
+	return nil
+}
+
+// isStaticAlloc returns true if the allocation belongs to a static job of the
+// client. These allocations are unknown to the servers, so they are neither
+// synced nor removed when the client pulls its allocations.
+func (c *Client) isStaticAlloc(allocID string) bool {
+	c.allocLock.RLock()
+	defer c.allocLock.RUnlock()
+	_, ok := c.staticAllocs[allocID]
+	return ok
+}
+
+// reconcileStaticAllocs stops the allocations of static jobs once the servers
+// placed allocations of the same job and task group on the node, handing
+// their workloads over to the servers.
+func (c *Client) reconcileStaticAllocs() {
+	c.allocLock.Lock()
+	if len(c.staticAllocs) == 0 {
+		c.allocLock.Unlock()
+		return
+	}
+
+	placed := make(map[string]struct{})
+	for id, ar := range c.allocs {
+		if _, ok := c.staticAllocs[id]; ok {
+			continue
+		}
+		if alloc := ar.Alloc(); !alloc.ServerTerminalStatus() {
+			placed[staticJobGroupKey(alloc)] = struct{}{}
+		}
+	}
+
+	var replaced []string
+	for id := range c.staticAllocs {
+		ar, ok := c.allocs[id]
+		if !ok {
+			delete(c.staticAllocs, id)
+			continue
+		}
+		if _, ok := placed[staticJobGroupKey(ar.Alloc())]; ok {
+			delete(c.staticAllocs, id)
+			replaced = append(replaced, id)
+		}
+	}
+	c.allocLock.Unlock()
+
+	for _, id := range replaced {
+		c.logger.Info("stopping static job allocation replaced by the servers", "alloc_id", id)
+		c.removeAlloc(id)
+	}
+}
+
+// staticJobAllocs returns the allocations to run on the node for a static
+// job: one per task group for system jobs, and as many as the task group count
+// otherwise.
+func staticJobAllocs(node *structs.Node, job *structs.Job) []*structs.Allocation {
+	now := time.Now().UnixNano()
+
+	var allocs []*structs.Allocation
+	for _, tg := range job.TaskGroups {
+		count := tg.Count
+		if job.Type == structs.JobTypeSystem || job.Type == structs.JobTypeSysBatch {
+			count = 1
+		}
+
+		for i := 0; i < count; i++ {
+			allocs = append(allocs, &structs.Allocation{
+				ID:                 staticAllocID(node.ID, job, tg.Name, i),
+				Namespace:          job.Namespace,
+				Name:               structs.AllocName(job.ID, tg.Name, uint(i)),
+				NodeID:             node.ID,
+				NodeName:           node.Name,
+				JobID:              job.ID,
+				Job:                job,
+				TaskGroup:          tg.Name,
+				AllocatedResources: staticAllocatedResources(tg),
+				DesiredStatus:      structs.AllocDesiredStatusRun,
+				ClientStatus:       structs.AllocClientStatusPending,
+				CreateTime:         now,
+				ModifyTime:         now,
+			})
+		}
+	}
+	return allocs
+}
+
+// staticAllocatedResources returns the resources of an allocation of a static
+// task group, as the scheduler would allocate them without networks.
+func staticAllocatedResources(tg *structs.TaskGroup) *structs.AllocatedResources {
+	resources := &structs.AllocatedResources{
+		Tasks:          make(map[string]*structs.AllocatedTaskResources, len(tg.Tasks)),
+		TaskLifecycles: make(map[string]*structs.TaskLifecycleConfig, len(tg.Tasks)),
+	}
+	if tg.EphemeralDisk != nil {
+		resources.Shared.DiskMB = int64(tg.EphemeralDisk.SizeMB)
+	}
+
+	for _, task := range tg.Tasks {
+		taskResources := &structs.AllocatedTaskResources{}
+		if task.Resources != nil {
+			taskResources.Cpu.CpuShares = int64(task.Resources.CPU)
+			taskResources.Memory.MemoryMB = int64(task.Resources.MemoryMB)
+			taskResources.Memory.MemoryMaxMB = int64(task.Resources.MemoryMaxMB)
+		}
+		resources.Tasks[task.Name] = taskResources
+		resources.TaskLifecycles[task.Name] = task.Lifecycle
+	}
+	return resources
+}
+
+// staticAllocID returns a stable ID for an instance of a task group of a
+// static job, so restarted clients restore the same allocations instead of
+// running new ones.
+func staticAllocID(nodeID string, job *structs.Job, group string, index int) string {
+	buf := sha256.Sum256([]byte(strings.Join([]string{
+		nodeID, job.Namespace, job.ID, group, strconv.Itoa(index),
+	}, "\x00")))
+
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%12x",
+		buf[0:4],
+		buf[4:6],
+		buf[6:8],
+		buf[8:10],
+		buf[10:16])
+}
+
+// staticJobGroupKey identifies the task group of the job of an allocation.
+func staticJobGroupKey(alloc *structs.Allocation) string {
+	return strings.Join([]string{alloc.Namespace, alloc.JobID, alloc.TaskGroup}, "\x00")
+}
@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
+)
+
+func TestClient_staticJobAllocs(t *testing.T) {
+	ci.Parallel(t)
+
+	node := mock.Node()
+
+	job := mock.Job()
+	job.TaskGroups[0].Count = 3
+	allocs := staticJobAllocs(node, job)
+	must.Len(t, 3, allocs)
+	for i, alloc := range allocs {
+		must.Eq(t, staticAllocID(node.ID, job, "web", i), alloc.ID)
+		must.Eq(t, structs.AllocName(job.ID, "web", uint(i)), alloc.Name)
+		must.Eq(t, node.ID, alloc.NodeID)
+		must.Eq(t, structs.AllocDesiredStatusRun, alloc.DesiredStatus)
+		must.Eq(t, int64(500), alloc.AllocatedResources.Tasks["web"].Cpu.CpuShares)
+		must.Eq(t, int64(256), alloc.AllocatedResources.Tasks["web"].Memory.MemoryMB)
+	}
+
+	sysJob := mock.SystemJob()
+	sysJob.TaskGroups[0].Count = 3
+	must.Len(t, 1, staticJobAllocs(node, sysJob))
+}
+
+func TestClient_staticAllocID(t *testing.T) {
+	ci.Parallel(t)
+
+	nodeID := uuid.Generate()
+	job := mock.Job()
+
+	id := staticAllocID(nodeID, job, "web", 0)
+	must.Eq(t, 36, len(id))
+	must.Eq(t, id, staticAllocID(nodeID, job.Copy(), "web", 0))
+	must.NotEq(t, id, staticAllocID(nodeID, job, "web", 1))
+	must.NotEq(t, id, staticAllocID(uuid.Generate(), job, "web", 0))
+}
+
+func TestClient_StaticJobs(t *testing.T) {
+	ci.Parallel(t)
+
+	job := mock.Job()
+	job.TaskGroups[0].Count = 1
+	job.TaskGroups[0].Networks = nil
+	job.TaskGroups[0].Services = nil
+	job.TaskGroups[0].Tasks[0].Driver = "mock_driver"
+	job.TaskGroups[0].Tasks[0].Config = map[string]interface{}{
+		"run_for": "30s",
+	}
+	job.TaskGroups[0].Tasks[0].Services = nil
+	job.TaskGroups[0].Tasks[0].Resources.Networks = nil
+
+	// The client never connects to servers, so the static job allocation must
+	// run on its own.
+	c, cleanup := TestClient(t, func(c *config.Config) {
+		c.StaticJobs = []*structs.Job{job}
+	})
+	defer cleanup()
+
+	allocID := staticAllocID(c.NodeID(), job, "web", 0)
+	must.True(t, c.isStaticAlloc(allocID))
+
+	testutil.WaitForResult(func() (bool, error) {
+		ar, err := c.getAllocRunner(allocID)
+		if err != nil {
+			return false, err
+		}
+		status := ar.AllocState().ClientStatus
+		return status == structs.AllocClientStatusRunning, fmt.Errorf("alloc status: %s", status)
+	}, func(err error) {
+		t.Fatal(err)
+	})
+
+	// Static allocations are not reported to servers.
+	c.pendingUpdates.lock.Lock()
+	_, ok := c.pendingUpdates.updates[allocID]
+	c.pendingUpdates.lock.Unlock()
+	must.False(t, ok)
+}
@@ -4,7 +4,9 @@
 package structs
 
 import (
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/nomad/client/pluginmanager/csimanager"
 	"github.com/hashicorp/nomad/helper"
@@ -18,16 +20,48 @@ type AllocHookResources struct {
 	csiMounts    map[string]*csimanager.MountInfo
 	consulTokens map[string]map[string]string // Consul cluster -> service identity -> token
 
+	identityRenewals map[string]IdentityRenewalStatus // task -> renewal status
+
+	identityProjections map[string][]*IdentityProjection // task -> projections
+
+	dispatchPayload []byte
+
 	mu sync.RWMutex
 }
 
 func NewAllocHookResources() *AllocHookResources {
 	return &AllocHookResources{
-		csiMounts:    map[string]*csimanager.MountInfo{},
-		consulTokens: map[string]map[string]string{},
+		csiMounts:        map[string]*csimanager.MountInfo{},
+		consulTokens:     map[string]map[string]string{},
+		identityRenewals: map[string]IdentityRenewalStatus{},
 	}
 }
 
+// IdentityProjection is a directory of the alloc dir a workload identity is
+// projected into, and the path of the task it's mounted to.
+type IdentityProjection struct {
+	HostPath string
+	TaskPath string
+}
+
+// GetIdentityProjections returns the identity projections of a task
+// previously written by the identity projection allocrunner hook.
+func (a *AllocHookResources) GetIdentityProjections(task string) []*IdentityProjection {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return slices.Clone(a.identityProjections[task])
+}
+
+// SetIdentityProjections stores the identity projections of the tasks for
+// later use by the volume taskrunner hook.
+func (a *AllocHookResources) SetIdentityProjections(m map[string][]*IdentityProjection) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.identityProjections = m
+}
+
 // GetCSIMounts returns a copy of the CSI mount info previously written by the
 // CSI allocrunner hook
 func (a *AllocHookResources) GetCSIMounts() map[string]*csimanager.MountInfo {
@@ -66,3 +100,68 @@ func (a *AllocHookResources) SetConsulTokens(m map[string]map[string]string) {
 		a.consulTokens[k] = v
 	}
 }
+
+// GetDispatchPayload returns the snappy compressed dispatch payload previously
+// fetched from the servers by the dispatch payload allocrunner hook
+func (a *AllocHookResources) GetDispatchPayload() []byte {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.dispatchPayload
+}
+
+// SetDispatchPayload stores the dispatch payload for later use by the dispatch
+// taskrunner hook
+func (a *AllocHookResources) SetDispatchPayload(payload []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.dispatchPayload = payload
+}
+
+// IdentityRenewalStatus is the status of the renewal of the workload identities
+// of a task.
+type IdentityRenewalStatus struct {
+	// LastRenewal is the time the identities were last renewed.
+	LastRenewal time.Time
+
+	// NextRenewal is the time the identities will be renewed next.
+	NextRenewal time.Time
+
+	// Failures is the number of consecutive failed renewals.
+	Failures int
+
+	// LastError is the error of the last renewal if it failed.
+	LastError string
+
+	// Expiration is the time the first of the identities expires.
+	Expiration time.Time
+
+	// Stale is true while the identities expired and couldn't be renewed,
+	// but are still served during the identity grace period of the client.
+	Stale bool
+
+	// Expired is true once the identities expired past the identity grace
+	// period of the client without being renewed.
+	Expired bool
+}
+
+// GetIdentityRenewal returns the renewal status of the workload identities of
+// a task, and false if they aren't renewed.
+func (a *AllocHookResources) GetIdentityRenewal(task string) (IdentityRenewalStatus, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	status, ok := a.identityRenewals[task]
+	return status, ok
+}
+
+// SetIdentityRenewal stores the renewal status of the workload identities of a
+// task. This method is called by the workload identity manager after each
+// renewal attempt.
+func (a *AllocHookResources) SetIdentityRenewal(task string, status IdentityRenewalStatus) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.identityRenewals[task] = status
+}
@@ -137,6 +137,34 @@ type FsStreamRequest struct {
 	structs.QueryOptions
 }
 
+// FsArchiveRequest is the initial request for streaming a directory of an
+// allocation as a gzipped tar archive.
+type FsArchiveRequest struct {
+	// AllocID is the allocation to archive the directory of
+	AllocID string
+
+	// Path is the path to the directory to archive
+	Path string
+
+	// Include and Exclude are glob patterns matched against the path of the
+	// files relative to the archived directory and against their name. When
+	// Include is set, only the files matching one of its patterns are
+	// archived. The files matching one of the Exclude patterns are never
+	// archived.
+	Include []string
+	Exclude []string
+
+	// MaxBytes is the maximum total size of the archived files before
+	// compression. Defaults to DefaultFsArchiveMaxBytes.
+	MaxBytes int64
+
+	structs.QueryOptions
+}
+
+// DefaultFsArchiveMaxBytes is the default maximum total size of the files of
+// an archive.
+const DefaultFsArchiveMaxBytes = 1 << 30
+
 // FsLogsRequest is the initial request for accessing allocation logs.
 type FsLogsRequest struct {
 	// AllocID is the allocation to stream logs from
@@ -210,6 +238,103 @@ type AllocChecksResponse struct {
 	Results map[structs.CheckID]*structs.CheckQueryResult
 }
 
+// AllocHooksRequest is used to list the running hooks of an allocation.
+type AllocHooksRequest struct {
+	structs.QueryOptions
+	AllocID string
+}
+
+// AllocHooksResponse is used to return the running hooks of an allocation.
+type AllocHooksResponse struct {
+	structs.QueryMeta
+	Hooks []*RunningHook
+}
+
+// RunningHook is an alloc runner or task runner hook whose call hasn't
+// returned yet.
+type RunningHook struct {
+	// Task is the name of the task of task runner hooks, and is empty for
+	// alloc runner hooks.
+	Task string
+
+	// Name is the name of the hook.
+	Name string
+
+	// Phase is the lifecycle phase the hook is running in, which is one of
+	// "prerun" or "postrun" for alloc runner hooks, and "prestart" for task
+	// runner hooks.
+	Phase string
+
+	// StartedAt is the time the hook was called.
+	StartedAt time.Time
+}
+
+// AllocHookOverrideRequest is used to skip or fail a running hook of an
+// allocation.
+type AllocHookOverrideRequest struct {
+	structs.QueryOptions
+	AllocID string
+
+	// Task is the name of the task of a task runner hook. It must be empty
+	// for alloc runner hooks.
+	Task string
+
+	// Hook is the name of the hook.
+	Hook string
+
+	// Action is either "skip", to continue as if the hook had succeeded, or
+	// "fail", to continue as if the hook had failed.
+	Action string
+}
+
+// AllocIdentitiesRequest is used to list the signed workload identities of an
+// allocation.
+type AllocIdentitiesRequest struct {
+	structs.QueryOptions
+	AllocID string
+
+	// Task is an optional filter to only list the identities of the task,
+	// including the identities of its services.
+	Task string
+
+	// Name is an optional filter to only list the identities with the name.
+	Name string
+}
+
+// AllocIdentitiesResponse is used to return the signed workload identities of
+// an allocation.
+type AllocIdentitiesResponse struct {
+	structs.QueryMeta
+	Identities []*AllocIdentity
+}
+
+// AllocIdentity is the decoded signed workload identity of a task or service
+// of an allocation. The signed JWT itself is never returned.
+type AllocIdentity struct {
+	// Name is the name of the identity.
+	Name string
+
+	// Task is the name of the task of the identity, and is empty for the
+	// identities of group services.
+	Task string
+
+	// Service is the name of the service of service identities.
+	Service string
+
+	// Audience is the audience claim of the identity.
+	Audience []string
+
+	// Claims are all the claims of the identity.
+	Claims map[string]any
+
+	// IssuedAt is the time the identity was signed.
+	IssuedAt time.Time
+
+	// ExpiresAt is the time the identity expires, and is zero if the identity
+	// doesn't expire.
+	ExpiresAt time.Time
+}
+
 // AllocStatsRequest is used to request the resource usage of a given
 // allocation, potentially filtering by task
 type AllocStatsRequest struct {
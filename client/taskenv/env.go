@@ -88,6 +88,18 @@ const (
 	// Region is the environment variable for passing the region in which the alloc is running.
 	Region = "NOMAD_REGION"
 
+	// AllocNetNSPath is the environment variable for passing the path of the
+	// network namespace of the allocation on the client.
+	AllocNetNSPath = "NOMAD_ALLOC_NETNS_PATH"
+
+	// AllocInterface is the environment variable for passing the name of the
+	// interface of the allocation in its network namespace.
+	AllocInterface = "NOMAD_ALLOC_INTERFACE"
+
+	// AllocBridge is the environment variable for passing the name of the host
+	// bridge the network namespace of the allocation is attached to.
+	AllocBridge = "NOMAD_ALLOC_BRIDGE"
+
 	// AddrPrefix is the prefix for passing both dynamic and static port
 	// allocations to tasks.
 	// E.g $NOMAD_ADDR_http=127.0.0.1:80
@@ -441,6 +453,10 @@ type Builder struct {
 	// was defined).
 	driverNetwork *drivers.DriverNetwork
 
+	// networkStatus is the status of the network of the allocation (or nil
+	// if it has no network namespace).
+	networkStatus *structs.AllocNetworkStatus
+
 	// network resources from the task; must be lazily turned into env vars
 	// because portMaps and advertiseIP can change after builder creation
 	// and affect network env vars.
@@ -556,6 +572,7 @@ func (b *Builder) buildEnv(allocDir, localDir, secretsDir string,
 
 	// Build the network related env vars
 	buildNetworkEnv(envMap, b.networks, b.driverNetwork)
+	buildNetworkStatusEnv(envMap, b.networkStatus)
 
 	// Build the addr of the other tasks
 	for k, v := range b.otherPorts {
@@ -763,6 +780,13 @@ func (b *Builder) setAlloc(alloc *structs.Allocation) *Builder {
 		b.taskMeta[fmt.Sprintf("%s%s", MetaPrefix, k)] = v
 	}
 
+	// Set the env of the job and group the task doesn't override
+	for k, v := range alloc.Job.CombinedGroupEnv(alloc.TaskGroup) {
+		if _, ok := b.envvars[k]; !ok {
+			b.envvars[k] = v
+		}
+	}
+
 	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
 
 	b.otherPorts = make(map[string]string, len(tg.Tasks)*2)
@@ -913,6 +937,33 @@ func (b *Builder) SetDriverNetwork(n *drivers.DriverNetwork) *Builder {
 	return b
 }
 
+// SetNetworkStatus of the network namespace of the allocation.
+func (b *Builder) SetNetworkStatus(s *structs.AllocNetworkStatus) *Builder {
+	scopy := s.Copy()
+	b.mu.Lock()
+	b.networkStatus = scopy
+	b.mu.Unlock()
+	return b
+}
+
+// buildNetworkStatusEnv env vars in the given map.
+//
+//	NOMAD_ALLOC_NETNS_PATH, NOMAD_ALLOC_INTERFACE, NOMAD_ALLOC_BRIDGE
+func buildNetworkStatusEnv(envMap map[string]string, status *structs.AllocNetworkStatus) {
+	if status == nil {
+		return
+	}
+	if status.NamespacePath != "" {
+		envMap[AllocNetNSPath] = status.NamespacePath
+	}
+	if status.InterfaceName != "" {
+		envMap[AllocInterface] = status.InterfaceName
+	}
+	if status.BridgeName != "" {
+		envMap[AllocBridge] = status.BridgeName
+	}
+}
+
 // buildNetworkEnv env vars in the given map.
 //
 //	Auto:   NOMAD_PORT_<label>
@@ -762,6 +762,31 @@ func TestEnvironment_InterpolateEmptyOptionalMeta(t *testing.T) {
 	require.Empty(env.ReplaceEnv("${NOMAD_META_metaopt2}"))
 }
 
+// TestEnvironment_JobGroupEnv asserts the env of the job and group is set in
+// the tasks, with the task env taking precedence over the group's, and the
+// group's over the job's.
+func TestEnvironment_JobGroupEnv(t *testing.T) {
+	ci.Parallel(t)
+
+	a := mock.Alloc()
+	a.Job.Env = map[string]string{"JOB": "job", "SCOPE": "job", "OWNER": "${NOMAD_META_owner}"}
+	a.Job.Meta = map[string]string{"owner": "armon"}
+	tg := a.Job.LookupTaskGroup(a.TaskGroup)
+	tg.Env = map[string]string{"GROUP": "group", "SCOPE": "group"}
+	task := tg.Tasks[0]
+	task.Env = map[string]string{"SCOPE": "task"}
+
+	env := NewBuilder(mock.Node(), a, task, "global").Build().Map()
+	require.Equal(t, "job", env["JOB"])
+	require.Equal(t, "group", env["GROUP"])
+	require.Equal(t, "task", env["SCOPE"])
+	require.Equal(t, "armon", env["OWNER"])
+
+	delete(task.Env, "SCOPE")
+	env = NewBuilder(mock.Node(), a, task, "global").Build().Map()
+	require.Equal(t, "group", env["SCOPE"])
+}
+
 // TestEnvironment_Upsteams asserts that group.service.upstreams entries are
 // added to the environment.
 func TestEnvironment_Upstreams(t *testing.T) {
@@ -814,6 +839,31 @@ func TestEnvironment_Upstreams(t *testing.T) {
 	require.Equal(t, "1234", env["bar"])
 }
 
+func TestEnvironment_NetworkStatus(t *testing.T) {
+	ci.Parallel(t)
+
+	a := mock.Alloc()
+	task := a.Job.TaskGroups[0].Tasks[0]
+
+	// Allocations without a network namespace don't set the variables
+	env := NewBuilder(mock.Node(), a, task, "global").Build().Map()
+	require.NotContains(t, env, AllocNetNSPath)
+	require.NotContains(t, env, AllocInterface)
+	require.NotContains(t, env, AllocBridge)
+
+	b := NewBuilder(mock.Node(), a, task, "global")
+	b.SetNetworkStatus(&structs.AllocNetworkStatus{
+		InterfaceName: "eth0",
+		Address:       "172.26.64.2",
+		NamespacePath: "/var/run/netns/" + a.ID,
+		BridgeName:    "nomad",
+	})
+	env = b.Build().Map()
+	require.Equal(t, "/var/run/netns/"+a.ID, env["NOMAD_ALLOC_NETNS_PATH"])
+	require.Equal(t, "eth0", env["NOMAD_ALLOC_INTERFACE"])
+	require.Equal(t, "nomad", env["NOMAD_ALLOC_BRIDGE"])
+}
+
 func TestEnvironment_SetPortMapEnvs(t *testing.T) {
 	ci.Parallel(t)
 
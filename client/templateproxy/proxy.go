@@ -0,0 +1,360 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package templateproxy implements an in-process HTTP proxy used by the
+// consul-template runners of all the tasks running on a client to reach
+// Consul and Vault.
+//
+// Every task with templates runs its own consul-template runner, and each
+// runner creates its own HTTP clients and watches. On busy clients this means
+// hundreds of independent connections and identical blocking queries against
+// the same clusters. When enabled, runners dial the proxy through an in-memory
+// listener instead, and the proxy forwards requests over a single pool of
+// connections, coalescing concurrent GET requests for the same URL with the
+// same token so that a blocking query watched by many templates is sent
+// upstream only once.
+package templateproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	metrics "github.com/armon/go-metrics"
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/dnscache"
+	"github.com/hashicorp/nomad/helper/bufconndialer"
+	structsc "github.com/hashicorp/nomad/nomad/structs/config"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// UpstreamConsul and UpstreamVault are the kinds of upstream clusters a
+	// proxy can forward requests to.
+	UpstreamConsul = "consul"
+	UpstreamVault  = "vault"
+)
+
+// hopHeaders are the hop-by-hop headers that must not be forwarded by
+// proxies, as defined by RFC 7230 section 6.1.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Proxy forwards HTTP requests received from an in-memory listener to an
+// upstream Consul or Vault cluster.
+type Proxy struct {
+	// kind is the type of upstream, either UpstreamConsul or UpstreamVault.
+	kind string
+
+	// name is the name of the upstream cluster.
+	name string
+
+	// upstream is the base URL of the upstream cluster.
+	upstream *url.URL
+
+	// client is the HTTP client shared by all forwarded requests. It holds
+	// the TLS configuration and connection pool to the upstream cluster.
+	client *http.Client
+
+	listener net.Listener
+	dialer   *bufconndialer.BufConnWrapper
+	server   *http.Server
+
+	// socketPath is the path of the unix socket the proxy also listens on,
+	// if any.
+	socketPath string
+
+	// group coalesces identical GET requests in flight.
+	group singleflight.Group
+
+	// ctx is used for upstream requests so that the cancellation of the
+	// request that triggered a coalesced upstream request doesn't fail the
+	// other requests waiting on it. It is cancelled on Shutdown.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	logger hclog.Logger
+}
+
+// response is a buffered upstream response that can be written to multiple
+// callers.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// NewConsulProxy returns a proxy to the Consul agent in the given
+// configuration. If resolver is set, the host of the agent is resolved with
+// it.
+func NewConsulProxy(logger hclog.Logger, conf *structsc.ConsulConfig, resolver *dnscache.Cache) (*Proxy, error) {
+	if conf == nil {
+		return nil, errors.New("nil consul config")
+	}
+
+	apiConf, err := conf.ApiConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul API config: %w", err)
+	}
+
+	transport := cleanhttp.DefaultPooledTransport()
+	if resolver != nil {
+		transport.DialContext = resolver.DialContext
+	}
+
+	client, err := consulapi.NewHttpClient(transport, apiConf.TLSConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul HTTP client: %w", err)
+	}
+
+	upstream := &url.URL{Scheme: apiConf.Scheme, Host: apiConf.Address}
+	return newProxy(logger, UpstreamConsul, conf.Name, upstream, client), nil
+}
+
+// NewVaultProxy returns a proxy to the Vault cluster in the given
+// configuration. If resolver is set, the host of the cluster is resolved with
+// it.
+func NewVaultProxy(logger hclog.Logger, conf *structsc.VaultConfig, resolver *dnscache.Cache) (*Proxy, error) {
+	if conf == nil {
+		return nil, errors.New("nil vault config")
+	}
+
+	apiConf, err := conf.ApiConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault API config: %w", err)
+	}
+
+	upstream, err := url.Parse(apiConf.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vault address %q: %w", apiConf.Address, err)
+	}
+
+	// The Vault API client sets a default request timeout, clear it since
+	// requests are already bound by the runner that made them.
+	client := apiConf.HttpClient
+	client.Timeout = 0
+	if transport, ok := client.Transport.(*http.Transport); ok && resolver != nil {
+		transport.DialContext = resolver.DialContext
+	}
+
+	return newProxy(logger, UpstreamVault, conf.Name, upstream, client), nil
+}
+
+func newProxy(logger hclog.Logger, kind, name string, upstream *url.URL, client *http.Client) *Proxy {
+	ctx, cancel := context.WithCancel(context.Background())
+	listener, dialer := bufconndialer.New()
+
+	p := &Proxy{
+		kind:     kind,
+		name:     name,
+		upstream: upstream,
+		client:   client,
+		listener: listener,
+		dialer:   dialer,
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   logger.Named(kind).With("cluster", name),
+	}
+	p.server = &http.Server{Handler: p}
+
+	return p
+}
+
+// Dialer returns the dialer consul-template runners must use to connect to
+// the proxy.
+func (p *Proxy) Dialer() *bufconndialer.BufConnWrapper {
+	return p.dialer
+}
+
+// Run starts accepting requests in a new goroutine.
+func (p *Proxy) Run() {
+	go p.serve(p.listener)
+}
+
+// ListenUnix starts accepting requests on a unix socket at the given path in
+// a new goroutine, for runners that can't use the dialer of the proxy.
+// consul-template only supports custom dialers for Vault and Nomad, so its
+// Consul clients must reach the proxy through a socket. The directory of the
+// socket must only be accessible by the agent.
+func (p *Proxy) ListenUnix(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove socket: %w", err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	p.socketPath = path
+	go p.serve(listener)
+	return nil
+}
+
+func (p *Proxy) serve(listener net.Listener) {
+	err := p.server.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		p.logger.Error("proxy stopped serving requests", "error", err)
+	}
+}
+
+// Shutdown stops the proxy and cancels all the upstream requests in flight.
+func (p *Proxy) Shutdown() {
+	p.cancel()
+	if err := p.server.Close(); err != nil {
+		p.logger.Warn("failed to close proxy", "error", err)
+	}
+	if p.socketPath != "" {
+		_ = os.RemoveAll(p.socketPath)
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	labels := []metrics.Label{
+		{Name: "upstream", Value: p.kind},
+		{Name: "cluster", Value: p.name},
+	}
+	metrics.IncrCounterWithLabels([]string{"client", "template_proxy", "request"}, 1, labels)
+
+	var resp *response
+	var err error
+
+	if r.Method == http.MethodGet {
+		var v any
+		var shared bool
+		v, err, shared = p.group.Do(requestKey(r), func() (any, error) {
+			return p.forward(p.ctx, r)
+		})
+		if shared {
+			metrics.IncrCounterWithLabels([]string{"client", "template_proxy", "coalesced"}, 1, labels)
+		}
+		if err == nil {
+			resp = v.(*response)
+		}
+	} else {
+		resp, err = p.forward(r.Context(), r)
+	}
+
+	if err != nil {
+		metrics.IncrCounterWithLabels([]string{"client", "template_proxy", "error"}, 1, labels)
+		p.logger.Debug("failed to forward request", "path", r.URL.Path, "error", err)
+		http.Error(w, fmt.Sprintf("%s proxy: %v", p.kind, err), http.StatusBadGateway)
+		return
+	}
+
+	for k, vv := range resp.header {
+		for _, v := range vv {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+// forward sends the request to the upstream cluster and buffers the response.
+func (p *Proxy) forward(ctx context.Context, r *http.Request) (*response, error) {
+	var body io.Reader
+	if r.Body != nil {
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	target := *p.upstream
+	target.Path = singleJoiningSlash(p.upstream.Path, r.URL.Path)
+	target.RawQuery = r.URL.RawQuery
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	removeHopHeaders(req.Header)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	header := resp.Header.Clone()
+	removeHopHeaders(header)
+	header.Del("Content-Length")
+
+	return &response{
+		status: resp.StatusCode,
+		header: header,
+		body:   respBody,
+	}, nil
+}
+
+// tokenHeaders are the headers that carry the tokens of requests, or the
+// Vault namespace the tokens are scoped to.
+var tokenHeaders = []string{
+	"Authorization",
+	"X-Consul-Token",
+	"X-Vault-Token",
+	"X-Vault-Namespace",
+}
+
+// requestKey returns the key used to coalesce requests. Requests are
+// coalesced if they have the same method, path, query, and tokens, so
+// responses are never shared across identities. Other headers, such as the
+// user agent of consul-template, don't change the response and are ignored.
+func requestKey(r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(r.URL.RequestURI())
+
+	for _, k := range tokenHeaders {
+		b.WriteByte('\n')
+		b.WriteString(k)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header.Values(k), ","))
+	}
+
+	return b.String()
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
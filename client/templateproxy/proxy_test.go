@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package templateproxy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+// testProxy returns a running proxy to the given upstream handler and an HTTP
+// client that connects to it.
+func testProxy(t *testing.T, handler http.Handler) (*Proxy, *http.Client) {
+	return testProxyWithIncoming(t, handler, nil)
+}
+
+// testProxyWithIncoming is like testProxy but counts the requests received by
+// the proxy in incoming.
+func testProxyWithIncoming(t *testing.T, handler http.Handler, incoming *atomic.Int32) (*Proxy, *http.Client) {
+	t.Helper()
+
+	upstream := httptest.NewServer(handler)
+	t.Cleanup(upstream.Close)
+
+	u, err := url.Parse(upstream.URL)
+	must.NoError(t, err)
+
+	p := newProxy(testlog.HCLogger(t), UpstreamConsul, "default", u, upstream.Client())
+	if incoming != nil {
+		p.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			incoming.Add(1)
+			p.ServeHTTP(w, r)
+		})
+	}
+	p.Run()
+	t.Cleanup(p.Shutdown)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: p.Dialer().DialContext,
+		},
+	}
+	return p, client
+}
+
+func TestProxy_Forward(t *testing.T) {
+	ci.Parallel(t)
+
+	_, client := testProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Consul-Index", "42")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%s %s?%s token=%s body=%s",
+			r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Consul-Token"), body)
+	}))
+
+	req, err := http.NewRequest(http.MethodPut, "http://consul/v1/kv/foo?cas=1", strings.NewReader("bar"))
+	must.NoError(t, err)
+	req.Header.Set("X-Consul-Token", "secret")
+
+	resp, err := client.Do(req)
+	must.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	must.NoError(t, err)
+	must.Eq(t, http.StatusCreated, resp.StatusCode)
+	must.Eq(t, "42", resp.Header.Get("X-Consul-Index"))
+	must.Eq(t, "PUT /v1/kv/foo?cas=1 token=secret body=bar", string(body))
+}
+
+func TestProxy_ListenUnix(t *testing.T) {
+	ci.Parallel(t)
+
+	p, _ := testProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Consul-Index", "42")
+		fmt.Fprintf(w, `[{"Key":"foo","Value":%q}]`,
+			base64.StdEncoding.EncodeToString([]byte(r.URL.Path+" "+r.Header.Get("X-Consul-Token"))))
+	}))
+
+	sockPath := filepath.Join(t.TempDir(), "consul.sock")
+	must.NoError(t, p.ListenUnix(sockPath))
+
+	// The Consul API client used by consul-template reaches the proxy
+	// through the unix:// address of its socket
+	client, err := consulapi.NewClient(&consulapi.Config{
+		Address: "unix://" + sockPath,
+		Token:   "secret",
+	})
+	must.NoError(t, err)
+
+	pair, meta, err := client.KV().Get("foo", nil)
+	must.NoError(t, err)
+	must.NotNil(t, pair)
+	must.Eq(t, "/v1/kv/foo secret", string(pair.Value))
+	must.Eq(t, 42, meta.LastIndex)
+
+	p.Shutdown()
+	must.FileNotExists(t, sockPath)
+}
+
+func TestProxy_Coalesce(t *testing.T) {
+	ci.Parallel(t)
+
+	const numRequests = 10
+
+	var incomingRequests, upstreamRequests atomic.Int32
+	release := make(chan struct{})
+
+	_, client := testProxyWithIncoming(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamRequests.Add(1)
+		<-release
+		fmt.Fprintf(w, "token=%s", r.Header.Get("X-Consul-Token"))
+	}), &incomingRequests)
+
+	get := func(token string) (string, error) {
+		req, err := http.NewRequest(http.MethodGet, "http://consul/v1/kv/foo?index=10&wait=60s", nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("X-Consul-Token", token)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	// Issue the same blocking query multiple times with the same token, and
+	// once with a different token which must not be coalesced.
+	var wg sync.WaitGroup
+	results := make([]string, numRequests+1)
+	errs := make([]error, numRequests+1)
+	for i := 0; i <= numRequests; i++ {
+		token := "a"
+		if i == numRequests {
+			token = "b"
+		}
+
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			results[i], errs[i] = get(token)
+		}(i, token)
+	}
+
+	// Wait for all requests to reach the proxy before releasing them. Only
+	// the two distinct requests must have reached the upstream.
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			return incomingRequests.Load() == numRequests+1 && upstreamRequests.Load() == 2
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < numRequests; i++ {
+		must.NoError(t, errs[i])
+		must.Eq(t, "token=a", results[i])
+	}
+	must.NoError(t, errs[numRequests])
+	must.Eq(t, "token=b", results[numRequests])
+	must.Eq(t, 2, upstreamRequests.Load())
+}
+
+func TestProxy_requestKey(t *testing.T) {
+	ci.Parallel(t)
+
+	newReq := func(method, target, token string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("X-Vault-Token", token)
+		return req
+	}
+
+	base := requestKey(newReq(http.MethodGet, "/v1/secret/data/foo", "a"))
+	must.Eq(t, base, requestKey(newReq(http.MethodGet, "/v1/secret/data/foo", "a")))
+	must.NotEq(t, base, requestKey(newReq(http.MethodGet, "/v1/secret/data/foo", "b")))
+	must.NotEq(t, base, requestKey(newReq(http.MethodGet, "/v1/secret/data/foo?version=2", "a")))
+	must.NotEq(t, base, requestKey(newReq(http.MethodGet, "/v1/secret/data/bar", "a")))
+
+	// Only the headers of the tokens are part of the key
+	req := newReq(http.MethodGet, "/v1/secret/data/foo", "a")
+	req.Header.Set("User-Agent", "consul-template")
+	must.Eq(t, base, requestKey(req))
+	req.Header.Set("X-Vault-Namespace", "ns1")
+	must.NotEq(t, base, requestKey(req))
+}
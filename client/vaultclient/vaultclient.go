@@ -71,6 +71,10 @@ type VaultClient interface {
 	// StopRenewToken removes the token from the min-heap, stopping its
 	// renewal.
 	StopRenewToken(string) error
+
+	// RevokeToken revokes the given token and all the leases created with
+	// it, such as dynamic secrets read by templates.
+	RevokeToken(context.Context, string) error
 }
 
 // Implementation of VaultClient interface to interact with vault and perform
@@ -381,6 +385,40 @@ func (c *vaultClient) RenewToken(token string, increment int) (<-chan error, err
 	return errCh, nil
 }
 
+// RevokeToken revokes the supplied token using the token's own permissions.
+// Vault revokes all leases created with a token when the token is revoked, so
+// this also revokes any dynamic secret issued to the task, such as the ones
+// read by templates, instead of waiting for them to expire.
+func (c *vaultClient) RevokeToken(ctx context.Context, token string) error {
+	if !c.config.IsEnabled() {
+		return fmt.Errorf("vault client not enabled")
+	}
+	if token == "" {
+		return fmt.Errorf("missing token")
+	}
+
+	// Stop renewing the token before revoking it so the renewal loop doesn't
+	// report a failure for a token that is expected to be gone.
+	if err := c.stopRenew(token); err != nil {
+		c.logger.Warn("failed to stop token renewal", "error", err)
+	}
+
+	c.lock.Lock()
+	defer c.unlockAndUnset()
+
+	// Use the token supplied to revoke itself
+	c.client.SetToken(token)
+
+	_, err := c.client.Logical().WriteWithContext(ctx, "auth/token/revoke-self", nil)
+	if err != nil {
+		metrics.IncrCounter([]string{"client", "vault", "revoke_token_failure"}, 1)
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+
+	metrics.IncrCounter([]string{"client", "vault", "revoke_token"}, 1)
+	return nil
+}
+
 // renew is a common method to handle renewal of both tokens and secret leases.
 // It invokes a token renewal or a secret's lease renewal. If renewal is
 // successful, min-heap is updated based on the duration after which it needs
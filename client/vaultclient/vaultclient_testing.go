@@ -25,6 +25,13 @@ type MockVaultClient struct {
 	// stoppedTokens tracks the tokens that have stopped renewing
 	stoppedTokens []string
 
+	// revokedTokens tracks the tokens that have been revoked
+	revokedTokens []string
+
+	// revokeTokenErrors is used to return an error when RevokeToken is
+	// called with the given token
+	revokeTokenErrors map[string]error
+
 	// renewTokens are the tokens that have been renewed and their error
 	// channels
 	renewTokens map[string]chan error
@@ -147,6 +154,31 @@ func (vc *MockVaultClient) StopRenewToken(token string) error {
 	return nil
 }
 
+func (vc *MockVaultClient) RevokeToken(_ context.Context, token string) error {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if err := vc.revokeTokenErrors[token]; err != nil {
+		return err
+	}
+
+	vc.revokedTokens = append(vc.revokedTokens, token)
+	return nil
+}
+
+// SetRevokeTokenError sets the error returned when revoking the token. A nil
+// error makes the revocation succeed again.
+func (vc *MockVaultClient) SetRevokeTokenError(token string, err error) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.revokeTokenErrors == nil {
+		vc.revokeTokenErrors = make(map[string]error, 10)
+	}
+
+	vc.revokeTokenErrors[token] = err
+}
+
 func (vc *MockVaultClient) Start() {}
 
 func (vc *MockVaultClient) Stop() {}
@@ -174,6 +206,13 @@ func (vc *MockVaultClient) StoppedTokens() []string {
 	return vc.stoppedTokens
 }
 
+// RevokedTokens tracks the tokens that have been revoked
+func (vc *MockVaultClient) RevokedTokens() []string {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	return vc.revokedTokens
+}
+
 // RenewTokens are the tokens that have been renewed and their error
 // channels
 func (vc *MockVaultClient) RenewTokens() map[string]chan error {
@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package widmgr
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// DefaultSigningWindow is how long the SigningBroker waits for other
+	// signing requests before sending a batch to the servers.
+	DefaultSigningWindow = 25 * time.Millisecond
+
+	// DefaultSigningBatchSize is the number of identities after which the
+	// SigningBroker sends a batch without waiting for the window to end.
+	DefaultSigningBatchSize = 512
+)
+
+// SigningBrokerConfig wraps the configuration parameters of the SigningBroker.
+type SigningBrokerConfig struct {
+	// Signer issues the batched Alloc.SignIdentities RPCs
+	Signer *Signer
+
+	// Window is how long to wait for more requests before sending a batch.
+	// Defaults to DefaultSigningWindow.
+	Window time.Duration
+
+	// BatchSize is the number of identities that causes a batch to be sent
+	// immediately. Defaults to DefaultSigningBatchSize.
+	BatchSize int
+}
+
+// SigningBroker implements IdentitySigner by coalescing the signing requests
+// of all the alloc runners of a client into batched Alloc.SignIdentities RPCs.
+// This reduces the number of RPCs when many allocations start at once, such
+// as when a client restarts.
+type SigningBroker struct {
+	signer    *Signer
+	window    time.Duration
+	batchSize int
+
+	// pending are the requests waiting for the next batch and numPending the
+	// number of identities they request
+	pending    []*signingRequest
+	numPending int
+	timer      *time.Timer
+	mu         sync.Mutex
+}
+
+// signingRequest is a call to SignIdentities waiting to be part of a batch.
+type signingRequest struct {
+	minIndex uint64
+	req      []*structs.WorkloadIdentityRequest
+
+	signed []*structs.SignedWorkloadIdentity
+	err    error
+	doneCh chan struct{}
+}
+
+// NewSigningBroker returns a SigningBroker sending its batches with the
+// signer.
+func NewSigningBroker(c SigningBrokerConfig) *SigningBroker {
+	b := &SigningBroker{
+		signer:    c.Signer,
+		window:    c.Window,
+		batchSize: c.BatchSize,
+	}
+	if b.window <= 0 {
+		b.window = DefaultSigningWindow
+	}
+	if b.batchSize <= 0 {
+		b.batchSize = DefaultSigningBatchSize
+	}
+	return b
+}
+
+// SignIdentities queues the requests for the next batch and blocks until the
+// batch is signed. The results and errors are the same as if the requests had
+// been sent on their own with Signer.SignIdentities.
+func (b *SigningBroker) SignIdentities(minIndex uint64, req []*structs.WorkloadIdentityRequest) ([]*structs.SignedWorkloadIdentity, error) {
+	if len(req) == 0 {
+		return nil, fmt.Errorf("no identities to sign")
+	}
+
+	r := &signingRequest{
+		minIndex: minIndex,
+		req:      req,
+		doneCh:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, r)
+	b.numPending += len(req)
+	if b.numPending >= b.batchSize {
+		batch := b.takePendingLocked()
+		b.mu.Unlock()
+		go b.send(batch)
+	} else {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.window, b.flush)
+		}
+		b.mu.Unlock()
+	}
+
+	<-r.doneCh
+	return r.signed, r.err
+}
+
+// SignCertificate is not batched and is passed through to the signer.
+func (b *SigningBroker) SignCertificate(req *structs.WorkloadIdentityRequest, csr []byte) (*structs.AllocCertificateResponse, error) {
+	return b.signer.SignCertificate(req, csr)
+}
+
+// ReportIdentityEvents is not batched and is passed through to the signer.
+func (b *SigningBroker) ReportIdentityEvents(events []*structs.WorkloadIdentityEvent) error {
+	return b.signer.ReportIdentityEvents(events)
+}
+
+// flush sends the pending requests once the window has ended.
+func (b *SigningBroker) flush() {
+	b.mu.Lock()
+	batch := b.takePendingLocked()
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.send(batch)
+	}
+}
+
+// takePendingLocked returns the pending requests and resets the batch. The
+// caller must hold the lock.
+func (b *SigningBroker) takePendingLocked() []*signingRequest {
+	batch := b.pending
+	b.pending = nil
+	b.numPending = 0
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return batch
+}
+
+// send issues a single RPC for all the requests of the batch and hands each
+// caller its own results.
+func (b *SigningBroker) send(batch []*signingRequest) {
+	var minIndex uint64
+	var reqs []*structs.WorkloadIdentityRequest
+	seen := make(map[structs.WorkloadIdentityRequest]struct{})
+	for _, r := range batch {
+		// Block on the newest allocation of the batch: once the server knows
+		// about it, it knows about all the older ones as well.
+		minIndex = max(minIndex, r.minIndex)
+
+		for _, req := range r.req {
+			if _, ok := seen[*req]; ok {
+				continue
+			}
+			seen[*req] = struct{}{}
+			reqs = append(reqs, req)
+		}
+	}
+
+	reply, err := b.signer.signIdentities(minIndex, reqs)
+	for _, r := range batch {
+		if err != nil {
+			r.err = err
+		} else {
+			r.signed, r.err = matchSignedIdentities(r.req, reply)
+		}
+		close(r.doneCh)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package widmgr
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+// batchRPCer answers Alloc.SignIdentities by signing every request except the
+// ones for rejected allocs, and records the requests it receives.
+type batchRPCer struct {
+	rejected map[string]bool
+
+	calls []*structs.AllocIdentitiesRequest
+	mu    sync.Mutex
+}
+
+func (r *batchRPCer) RPC(method string, args any, reply any) error {
+	req := args.(*structs.AllocIdentitiesRequest)
+	resp := reply.(*structs.AllocIdentitiesResponse)
+
+	r.mu.Lock()
+	r.calls = append(r.calls, req)
+	r.mu.Unlock()
+
+	for _, idReq := range req.Identities {
+		if r.rejected[idReq.AllocID] {
+			resp.Rejections = append(resp.Rejections, &structs.WorkloadIdentityRejection{
+				WorkloadIdentityRequest: *idReq,
+				Reason:                  structs.WIRejectionReasonMissingAlloc,
+			})
+			continue
+		}
+		resp.SignedIdentities = append(resp.SignedIdentities, &structs.SignedWorkloadIdentity{
+			WorkloadIdentityRequest: *idReq,
+			JWT:                     idReq.AllocID + "/" + idReq.IdentityName,
+		})
+	}
+	return nil
+}
+
+func (r *batchRPCer) numCalls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+func testIdentityRequests(allocID string, names ...string) []*structs.WorkloadIdentityRequest {
+	reqs := make([]*structs.WorkloadIdentityRequest, 0, len(names))
+	for _, name := range names {
+		reqs = append(reqs, &structs.WorkloadIdentityRequest{
+			AllocID: allocID,
+			WIHandle: structs.WIHandle{
+				IdentityName:       name,
+				WorkloadIdentifier: "web",
+				WorkloadType:       structs.WorkloadTypeTask,
+			},
+		})
+	}
+	return reqs
+}
+
+func TestSigningBroker_Coalesce(t *testing.T) {
+	ci.Parallel(t)
+
+	goodAlloc1, goodAlloc2, badAlloc := uuid.Generate(), uuid.Generate(), uuid.Generate()
+	rpc := &batchRPCer{rejected: map[string]bool{badAlloc: true}}
+	broker := NewSigningBroker(SigningBrokerConfig{
+		Signer: NewSigner(SignerConfig{RPC: rpc}),
+		Window: 100 * time.Millisecond,
+	})
+
+	type result struct {
+		signed []*structs.SignedWorkloadIdentity
+		err    error
+	}
+	sign := func(minIndex uint64, reqs []*structs.WorkloadIdentityRequest) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			signed, err := broker.SignIdentities(minIndex, reqs)
+			ch <- result{signed, err}
+		}()
+		return ch
+	}
+
+	res1 := sign(10, testIdentityRequests(goodAlloc1, "default", "vault"))
+	res2 := sign(30, testIdentityRequests(goodAlloc2, "default"))
+	res3 := sign(20, testIdentityRequests(badAlloc, "default"))
+
+	r1 := <-res1
+	must.NoError(t, r1.err)
+	must.Len(t, 2, r1.signed)
+	must.Eq(t, goodAlloc1+"/default", r1.signed[0].JWT)
+	must.Eq(t, goodAlloc1+"/vault", r1.signed[1].JWT)
+
+	r2 := <-res2
+	must.NoError(t, r2.err)
+	must.Len(t, 1, r2.signed)
+	must.Eq(t, goodAlloc2+"/default", r2.signed[0].JWT)
+
+	// A rejection only fails the caller that requested it
+	r3 := <-res3
+	must.ErrorContains(t, r3.err, structs.WIRejectionReasonMissingAlloc)
+
+	// All requests were sent in a single RPC blocking on the newest alloc
+	must.Eq(t, 1, rpc.numCalls())
+	must.Len(t, 4, rpc.calls[0].Identities)
+	must.Eq(t, 29, rpc.calls[0].MinQueryIndex)
+}
+
+func TestSigningBroker_BatchSize(t *testing.T) {
+	ci.Parallel(t)
+
+	rpc := &batchRPCer{}
+	broker := NewSigningBroker(SigningBrokerConfig{
+		Signer:    NewSigner(SignerConfig{RPC: rpc}),
+		Window:    time.Hour,
+		BatchSize: 2,
+	})
+
+	// Reaching the batch size sends the batch without waiting for the window
+	allocID := uuid.Generate()
+	signed, err := broker.SignIdentities(1, testIdentityRequests(allocID, "default", "consul"))
+	must.NoError(t, err)
+	must.Len(t, 2, signed)
+	must.Eq(t, 1, rpc.numCalls())
+}
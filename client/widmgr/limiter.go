@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package widmgr
+
+import "context"
+
+// RenewalLimiter limits the number of workload identity renewals running in
+// parallel across the allocations of a client, so renewals due at the same
+// time don't all hit the servers at once. A nil RenewalLimiter doesn't limit
+// the renewals.
+type RenewalLimiter struct {
+	slots chan struct{}
+}
+
+// NewRenewalLimiter returns a RenewalLimiter allowing max renewals in
+// parallel, or nil if max is not positive.
+func NewRenewalLimiter(max int) *RenewalLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &RenewalLimiter{slots: make(chan struct{}, max)}
+}
+
+// acquire blocks until a renewal can run or the context is done.
+func (l *RenewalLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot of a renewal once it is done.
+func (l *RenewalLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
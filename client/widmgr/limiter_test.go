@@ -0,0 +1,35 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package widmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestRenewalLimiter(t *testing.T) {
+	ci.Parallel(t)
+
+	// A nil limiter doesn't limit renewals
+	var unlimited *RenewalLimiter
+	must.Nil(t, NewRenewalLimiter(0))
+	must.NoError(t, unlimited.acquire(context.Background()))
+	unlimited.release()
+
+	l := NewRenewalLimiter(2)
+	must.NoError(t, l.acquire(context.Background()))
+	must.NoError(t, l.acquire(context.Background()))
+
+	// Renewals block while the limit is reached
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	must.ErrorIs(t, l.acquire(ctx), context.DeadlineExceeded)
+
+	l.release()
+	must.NoError(t, l.acquire(context.Background()))
+}
@@ -6,8 +6,13 @@ package widmgr
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-jose/go-jose/v3"
@@ -26,6 +31,10 @@ type MockWIDSigner struct {
 	key     *rsa.PrivateKey
 	keyID   string
 	mockNow time.Time // allows moving the clock
+
+	// events are the workload identity events reported to the mock
+	events     []*structs.WorkloadIdentityEvent
+	eventsLock sync.Mutex
 }
 
 func NewMockWIDSigner(wids []*structs.WorkloadIdentity) *MockWIDSigner {
@@ -114,6 +123,63 @@ func (m *MockWIDSigner) SignIdentities(minIndex uint64, req []*structs.WorkloadI
 	return swids, nil
 }
 
+// SignCertificate signs the certificate signing request with a self-signed CA
+// generated for the mock.
+func (m *MockWIDSigner) SignCertificate(req *structs.WorkloadIdentityRequest, csrPEM []byte) (*structs.AllocCertificateResponse, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return nil, fmt.Errorf("invalid certificate signing request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mock workload CA"},
+		NotBefore:             m.now().Add(-time.Minute),
+		NotAfter:              m.now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, m.key.Public(), m.key)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: req.AllocID},
+		NotBefore:    m.now().Add(-time.Minute),
+		NotAfter:     m.now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, csr.PublicKey, m.key)
+	if err != nil {
+		return nil, err
+	}
+	return &structs.AllocCertificateResponse{
+		Certificate:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		CACertificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+	}, nil
+}
+
+// ReportIdentityEvents records the events so tests can assert them with
+// Events.
+func (m *MockWIDSigner) ReportIdentityEvents(events []*structs.WorkloadIdentityEvent) error {
+	m.eventsLock.Lock()
+	defer m.eventsLock.Unlock()
+	m.events = append(m.events, events...)
+	return nil
+}
+
+// Events returns the workload identity events reported to the mock.
+func (m *MockWIDSigner) Events() []*structs.WorkloadIdentityEvent {
+	m.eventsLock.Lock()
+	defer m.eventsLock.Unlock()
+	return slices.Clone(m.events)
+}
+
 // MockWIDMgr mocks IdentityManager interface allowing to only get identities
 // signed by the mock signer.
 type MockWIDMgr struct {
@@ -139,9 +205,23 @@ func (m MockWIDMgr) Get(id structs.WIHandle) (*structs.SignedWorkloadIdentity, e
 	return sid, nil
 }
 
+func (m MockWIDMgr) List() []*structs.SignedWorkloadIdentity {
+	sids := make([]*structs.SignedWorkloadIdentity, 0, len(m.swids))
+	for _, sid := range m.swids {
+		sids = append(sids, sid)
+	}
+	sortSignedIdentities(sids)
+	return sids
+}
+
 // Watch does not do anything, this mock doesn't support watching.
 func (m MockWIDMgr) Watch(identity structs.WIHandle) (<-chan *structs.SignedWorkloadIdentity, func()) {
 	return nil, nil
 }
 
+// SignCertificate is not supported by this mock.
+func (m MockWIDMgr) SignCertificate(structs.WIHandle, []byte) (*structs.AllocCertificateResponse, error) {
+	return nil, fmt.Errorf("certificates not supported by mock")
+}
+
 func (m MockWIDMgr) Shutdown() {}
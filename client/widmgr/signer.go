@@ -18,6 +18,8 @@ type RPCer interface {
 // workload identities. At runtime it is implemented by *widmgr.Signer.
 type IdentitySigner interface {
 	SignIdentities(minIndex uint64, req []*structs.WorkloadIdentityRequest) ([]*structs.SignedWorkloadIdentity, error)
+	SignCertificate(req *structs.WorkloadIdentityRequest, csr []byte) (*structs.AllocCertificateResponse, error)
+	ReportIdentityEvents(events []*structs.WorkloadIdentityEvent) error
 }
 
 // SignerConfig wraps the configuration parameters the workload identity manager
@@ -32,7 +34,8 @@ type SignerConfig struct {
 	RPC RPCer
 }
 
-// Signer fetches and validates workload identities.
+// Signer fetches and validates workload identities. Clients wrap it in a
+// SigningBroker to batch the requests of their allocations.
 type Signer struct {
 	nodeSecret string
 	region     string
@@ -62,6 +65,16 @@ func (s *Signer) SignIdentities(minIndex uint64, req []*structs.WorkloadIdentity
 		return nil, fmt.Errorf("no identities to sign")
 	}
 
+	reply, err := s.signIdentities(minIndex, req)
+	if err != nil {
+		return nil, err
+	}
+	return matchSignedIdentities(req, reply)
+}
+
+// signIdentities issues the Alloc.SignIdentities RPC for the requests, which
+// may span multiple allocations, and returns the raw response.
+func (s *Signer) signIdentities(minIndex uint64, req []*structs.WorkloadIdentityRequest) (*structs.AllocIdentitiesResponse, error) {
 	args := structs.AllocIdentitiesRequest{
 		Identities: req,
 		QueryOptions: structs.QueryOptions{
@@ -80,16 +93,35 @@ func (s *Signer) SignIdentities(minIndex uint64, req []*structs.WorkloadIdentity
 	if err := s.rpc.RPC("Alloc.SignIdentities", &args, &reply); err != nil {
 		return nil, err
 	}
+	return &reply, nil
+}
+
+// matchSignedIdentities returns the signed identities of the reply that
+// answer req, in the order they were requested. The reply may contain answers
+// to requests of other allocations when it comes from a batch, so only the
+// signatures and rejections matching req are considered.
+func matchSignedIdentities(req []*structs.WorkloadIdentityRequest, reply *structs.AllocIdentitiesResponse) ([]*structs.SignedWorkloadIdentity, error) {
+	wanted := make(map[structs.WorkloadIdentityRequest]struct{}, len(req))
+	for _, r := range req {
+		wanted[*r] = struct{}{}
+	}
 
-	if n := len(reply.Rejections); n == 1 {
+	var rejections []*structs.WorkloadIdentityRejection
+	for _, r := range reply.Rejections {
+		if _, ok := wanted[r.WorkloadIdentityRequest]; ok {
+			rejections = append(rejections, r)
+		}
+	}
+
+	if n := len(rejections); n == 1 {
 		return nil, fmt.Errorf(
 			"%d/%d signing request was rejected: %v",
-			n, len(req), reply.Rejections[0].Reason,
+			n, len(req), rejections[0].Reason,
 		)
 	} else if n > 1 {
 		var mErr *multierror.Error
-		for _, r := range reply.Rejections {
-			mErr = multierror.Append(
+		for _, r := range rejections {
+			mErr = multierror.Append(mErr,
 				fmt.Errorf(
 					"%d/%d signing request was rejected: %v",
 					n, len(req), r.Reason,
@@ -102,9 +134,57 @@ func (s *Signer) SignIdentities(minIndex uint64, req []*structs.WorkloadIdentity
 		return nil, fmt.Errorf("empty signed identity response")
 	}
 
-	if exp, act := len(reply.SignedIdentities), len(req); exp != act {
+	signed := make(map[structs.WorkloadIdentityRequest]*structs.SignedWorkloadIdentity, len(reply.SignedIdentities))
+	for _, sid := range reply.SignedIdentities {
+		if _, ok := wanted[sid.WorkloadIdentityRequest]; ok {
+			signed[sid.WorkloadIdentityRequest] = sid
+		}
+	}
+
+	if exp, act := len(wanted), len(signed); exp != act {
 		return nil, fmt.Errorf("expected %d signed identities but received %d", exp, act)
 	}
 
-	return reply.SignedIdentities, nil
+	out := make([]*structs.SignedWorkloadIdentity, 0, len(req))
+	for _, r := range req {
+		out = append(out, signed[*r])
+	}
+	return out, nil
+}
+
+// SignCertificate wraps the Alloc.SignCertificate RPC and retrieves the X.509
+// certificate of a workload identity for the PEM encoded certificate signing
+// request.
+func (s *Signer) SignCertificate(req *structs.WorkloadIdentityRequest, csr []byte) (*structs.AllocCertificateResponse, error) {
+	args := structs.AllocCertificateRequest{
+		WorkloadIdentityRequest: *req,
+		CSR:                     csr,
+		QueryOptions: structs.QueryOptions{
+			Region:    s.region,
+			AuthToken: s.nodeSecret,
+		},
+	}
+	reply := structs.AllocCertificateResponse{}
+	if err := s.rpc.RPC("Alloc.SignCertificate", &args, &reply); err != nil {
+		return nil, err
+	}
+
+	if len(reply.Certificate) == 0 {
+		return nil, fmt.Errorf("empty certificate response")
+	}
+	return &reply, nil
+}
+
+// ReportIdentityEvents wraps the Alloc.IdentityEvents RPC and publishes
+// lifecycle events of workload identities on the event stream.
+func (s *Signer) ReportIdentityEvents(events []*structs.WorkloadIdentityEvent) error {
+	args := structs.AllocIdentityEventsRequest{
+		Events: events,
+		WriteRequest: structs.WriteRequest{
+			Region:    s.region,
+			AuthToken: s.nodeSecret,
+		},
+	}
+	reply := structs.GenericResponse{}
+	return s.rpc.RPC("Alloc.IdentityEvents", &args, &reply)
 }
@@ -5,13 +5,17 @@ package widmgr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	arstate "github.com/hashicorp/nomad/client/allocrunner/state"
 	cstate "github.com/hashicorp/nomad/client/state"
+	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -21,7 +25,9 @@ import (
 type IdentityManager interface {
 	Run() error
 	Get(structs.WIHandle) (*structs.SignedWorkloadIdentity, error)
+	List() []*structs.SignedWorkloadIdentity
 	Watch(structs.WIHandle) (<-chan *structs.SignedWorkloadIdentity, func())
+	SignCertificate(id structs.WIHandle, csr []byte) (*structs.AllocCertificateResponse, error)
 	Shutdown()
 }
 
@@ -30,6 +36,7 @@ type WIDMgr struct {
 	defaultSignedIdentities map[string]string // signed by the plan applier
 	minIndex                uint64
 	widSpecs                map[structs.WIHandle]*structs.WorkloadIdentity // workload handle -> WI
+	widTasks                map[structs.WIHandle]string                    // workload handle -> task, empty for group services
 	signer                  IdentitySigner
 	db                      cstate.StateDB
 
@@ -43,10 +50,34 @@ type WIDMgr struct {
 	watchers     map[structs.WIHandle][]chan *structs.SignedWorkloadIdentity
 	watchersLock sync.Mutex
 
+	// renewals are the renewal schedules of the identities, which are stored
+	// in the state DB with the renewed identities so they're restored after a
+	// client restart. stateLock serializes the writes to the state DB.
+	renewals  map[structs.WIHandle]*arstate.IdentityRenewal
+	stateLock sync.Mutex
+
 	// minWait is the minimum amount of time to wait before renewing. Settable to
 	// ease testing.
 	minWait time.Duration
 
+	// jitter is the max random delay added to each renewal, and limiter
+	// limits the renewals running in parallel across the allocations of the
+	// client, if set.
+	jitter  time.Duration
+	limiter *RenewalLimiter
+
+	// hookResources receives the renewal status of the identities of each
+	// task, if set.
+	hookResources *cstructs.AllocHookResources
+
+	// gracePeriod is how long after they expire the last signed identities
+	// are served while they can't be renewed.
+	gracePeriod time.Duration
+
+	// expiredFunc is called with the name of a task when its identities
+	// expire past the grace period, if set.
+	expiredFunc func(task string)
+
 	stopCtx context.Context
 	stop    context.CancelFunc
 
@@ -55,6 +86,7 @@ type WIDMgr struct {
 
 func NewWIDMgr(signer IdentitySigner, a *structs.Allocation, db cstate.StateDB, logger hclog.Logger) *WIDMgr {
 	widspecs := map[structs.WIHandle]*structs.WorkloadIdentity{}
+	widtasks := map[structs.WIHandle]string{}
 	tg := a.Job.LookupTaskGroup(a.TaskGroup)
 
 	for _, service := range tg.Services {
@@ -67,11 +99,13 @@ func NewWIDMgr(signer IdentitySigner, a *structs.Allocation, db cstate.StateDB,
 		// Omit default identity as it does not expire
 		for _, id := range task.Identities {
 			widspecs[*task.IdentityHandle(id)] = id
+			widtasks[*task.IdentityHandle(id)] = task.Name
 		}
 
 		for _, service := range task.Services {
 			if service.Identity != nil {
 				widspecs[*service.IdentityHandle()] = service.Identity
+				widtasks[*service.IdentityHandle()] = task.Name
 			}
 		}
 	}
@@ -85,11 +119,13 @@ func NewWIDMgr(signer IdentitySigner, a *structs.Allocation, db cstate.StateDB,
 		defaultSignedIdentities: a.SignedIdentities,
 		minIndex:                a.CreateIndex,
 		widSpecs:                widspecs,
+		widTasks:                widtasks,
 		signer:                  signer,
 		db:                      db,
 		minWait:                 10 * time.Second,
 		lastToken:               map[structs.WIHandle]*structs.SignedWorkloadIdentity{},
 		watchers:                map[structs.WIHandle][]chan *structs.SignedWorkloadIdentity{},
+		renewals:                map[structs.WIHandle]*arstate.IdentityRenewal{},
 		stopCtx:                 stopCtx,
 		stop:                    stop,
 		logger:                  logger.Named("widmgr"),
@@ -101,11 +137,44 @@ func (m *WIDMgr) SetMinWait(t time.Duration) {
 	m.minWait = t
 }
 
+// SetJitter sets the max random delay added to each renewal, so the renewals
+// of the allocations of a client are spread out. It must be called before Run.
+func (m *WIDMgr) SetJitter(d time.Duration) {
+	m.jitter = d
+}
+
+// SetRenewalLimiter sets the limiter shared by the identity managers of the
+// client to limit the renewals running in parallel. It must be called before
+// Run.
+func (m *WIDMgr) SetRenewalLimiter(l *RenewalLimiter) {
+	m.limiter = l
+}
+
+// SetHookResources sets the alloc hook resources the renewal status of the
+// identities of each task is stored in. It must be called before Run.
+func (m *WIDMgr) SetHookResources(r *cstructs.AllocHookResources) {
+	m.hookResources = r
+}
+
+// SetGracePeriod sets how long after they expire the last signed identities
+// are served while they can't be renewed, such as when the client is
+// disconnected from the servers. It must be called before Run.
+func (m *WIDMgr) SetGracePeriod(d time.Duration) {
+	m.gracePeriod = d
+}
+
+// SetExpiredFunc sets the func called with the name of a task when its
+// identities expire past the grace period. It must be called before Run.
+func (m *WIDMgr) SetExpiredFunc(f func(task string)) {
+	m.expiredFunc = f
+}
+
 // Run blocks until identities are initially signed and then renews them in a
-// goroutine. The goroutine is stopped when WIDMgr.Shutdown is called.
+// goroutine per task. The goroutines are stopped when WIDMgr.Shutdown is
+// called.
 //
 // If an error is returned the identities could not be fetched and the renewal
-// goroutine was not started.
+// goroutines were not started.
 func (m *WIDMgr) Run() error {
 	if len(m.widSpecs) == 0 && len(m.defaultSignedIdentities) == 0 {
 		m.logger.Debug("no workload identities to retrieve or renew")
@@ -118,13 +187,20 @@ func (m *WIDMgr) Run() error {
 	if err != nil {
 		m.logger.Warn("failed to get signed identities from state DB, refreshing from server: %w", err)
 	}
+	if err := m.restoreRenewals(); err != nil {
+		m.logger.Warn("failed to get identity renewals from state DB, rescheduling them", "error", err)
+	}
 	if hasExpired {
 		if err := m.getInitialIdentities(); err != nil {
-			return fmt.Errorf("failed to fetch signed identities: %w", err)
+			if !m.inGracePeriod(time.Now()) {
+				return fmt.Errorf("failed to fetch signed identities: %w", err)
+			}
+			m.logger.Warn("failed to fetch signed identities, serving the stored identities during the grace period",
+				"error", err, "grace_period", m.gracePeriod)
 		}
 	}
 
-	go m.renew()
+	m.startRenewals()
 
 	return nil
 }
@@ -146,6 +222,30 @@ func (m *WIDMgr) Get(id structs.WIHandle) (*structs.SignedWorkloadIdentity, erro
 	return token, nil
 }
 
+// List returns the latest signed identities of the allocation, sorted by
+// workload and identity name. It must be called after Run and does not block.
+func (m *WIDMgr) List() []*structs.SignedWorkloadIdentity {
+	m.lastTokenLock.RLock()
+	defer m.lastTokenLock.RUnlock()
+
+	tokens := make([]*structs.SignedWorkloadIdentity, 0, len(m.lastToken))
+	for _, token := range m.lastToken {
+		tokens = append(tokens, token)
+	}
+	sortSignedIdentities(tokens)
+	return tokens
+}
+
+// SignCertificate retrieves the X.509 certificate of the identity for the PEM
+// encoded certificate signing request.
+func (m *WIDMgr) SignCertificate(id structs.WIHandle, csr []byte) (*structs.AllocCertificateResponse, error) {
+	req := &structs.WorkloadIdentityRequest{
+		AllocID:  m.allocID,
+		WIHandle: id,
+	}
+	return m.signer.SignCertificate(req, csr)
+}
+
 func (m *WIDMgr) get(id structs.WIHandle) *structs.SignedWorkloadIdentity {
 	m.lastTokenLock.RLock()
 	defer m.lastTokenLock.RUnlock()
@@ -171,7 +271,6 @@ func (m *WIDMgr) Watch(id structs.WIHandle) (<-chan *structs.SignedWorkloadIdent
 
 	// Buffer of 1 so sends don't block on receives
 	c := make(chan *structs.SignedWorkloadIdentity, 1)
-	m.watchers[id] = make([]chan *structs.SignedWorkloadIdentity, 0)
 	m.watchers[id] = append(m.watchers[id], c)
 
 	// Create a cancel func for watchers to deregister when they exit.
@@ -239,6 +338,46 @@ func (m *WIDMgr) restoreStoredIdentities() (bool, error) {
 	return hasExpired, nil
 }
 
+// restoreRenewals restores the renewal schedules of the identities saved
+// before a client agent restart.
+func (m *WIDMgr) restoreRenewals() error {
+	renewals, err := m.db.GetAllocIdentityRenewals(m.allocID)
+	if err != nil {
+		return err
+	}
+
+	m.stateLock.Lock()
+	defer m.stateLock.Unlock()
+
+	for _, renewal := range renewals {
+		m.renewals[renewal.WIHandle] = renewal
+	}
+	return nil
+}
+
+// inGracePeriod returns true if every identity was restored from the state DB
+// and none expired past the grace period, so they can be served while they
+// can't be renewed.
+func (m *WIDMgr) inGracePeriod(now time.Time) bool {
+	if m.gracePeriod == 0 {
+		return false
+	}
+
+	m.lastTokenLock.RLock()
+	defer m.lastTokenLock.RUnlock()
+
+	for id := range m.widSpecs {
+		token := m.lastToken[id]
+		if token == nil {
+			return false
+		}
+		if !token.Expiration.IsZero() && !now.Before(token.Expiration.Add(m.gracePeriod)) {
+			return false
+		}
+	}
+	return true
+}
+
 // getInitialIdentities fetches all signed identities or returns an error. It
 // should be run once when the WIDMgr first runs.
 func (m *WIDMgr) getInitialIdentities() error {
@@ -270,6 +409,11 @@ func (m *WIDMgr) getInitialIdentities() error {
 	m.lastTokenLock.Lock()
 	defer m.lastTokenLock.Unlock()
 
+	// Store default identity tokens
+	for id, token := range defaultTokens {
+		m.lastToken[id] = token
+	}
+
 	reqs := make([]*structs.WorkloadIdentityRequest, 0, len(m.widSpecs))
 	for wiHandle := range m.widSpecs {
 		reqs = append(reqs, &structs.WorkloadIdentityRequest{
@@ -288,11 +432,6 @@ func (m *WIDMgr) getInitialIdentities() error {
 		}
 	}
 
-	// Store default identity tokens
-	for id, token := range defaultTokens {
-		m.lastToken[id] = token
-	}
-
 	// Index initial workload identities by name
 	for _, swid := range signedWIDs {
 		m.lastToken[swid.WIHandle] = swid
@@ -301,21 +440,20 @@ func (m *WIDMgr) getInitialIdentities() error {
 	return m.db.PutAllocIdentities(m.allocID, signedWIDs)
 }
 
-// renew fetches new signed workload identity tokens before the existing tokens
-// expire.
-func (m *WIDMgr) renew() {
-	if len(m.widSpecs) == 0 {
-		return
-	}
-
-	reqs := make([]*structs.WorkloadIdentityRequest, 0, len(m.widSpecs))
+// startRenewals starts a goroutine renewing the identities of each task, so a
+// task failing to renew its identities doesn't delay the renewals of the
+// others. The identities of group services are renewed by their own goroutine.
+func (m *WIDMgr) startRenewals() {
+	reqs := map[string][]*structs.WorkloadIdentityRequest{}
 	for workloadHandle, widspec := range m.widSpecs {
 		if widspec.TTL == 0 {
 			continue
 		}
-		reqs = append(reqs, &structs.WorkloadIdentityRequest{
+		task := m.widTasks[workloadHandle]
+		reqs[task] = append(reqs[task], &structs.WorkloadIdentityRequest{
 			AllocID:  m.allocID,
 			WIHandle: workloadHandle,
+			Renewal:  true,
 		})
 	}
 
@@ -324,20 +462,28 @@ func (m *WIDMgr) renew() {
 		return
 	}
 
+	for task, taskReqs := range reqs {
+		go m.renew(task, taskReqs)
+	}
+}
+
+// renew fetches new signed workload identity tokens for the identities of a
+// task before the existing tokens expire.
+func (m *WIDMgr) renew(task string, reqs []*structs.WorkloadIdentityRequest) {
+	logger := m.logger
+	if task != "" {
+		logger = logger.With("task", task)
+	}
+
 	renewNow := false
 	minExp := time.Time{}
 
-	for workloadHandle, wid := range m.widSpecs {
-		if wid.TTL == 0 {
-			// No ttl, so no need to renew it
-			continue
-		}
-
-		token := m.get(workloadHandle)
+	for _, req := range reqs {
+		token := m.get(req.WIHandle)
 		if token == nil {
 			// Missing a signature, treat this case as already expired so
 			// we get a token ASAP
-			m.logger.Debug("missing token for identity", "identity", wid.Name)
+			logger.Debug("missing token for identity", "identity", req.IdentityName)
 			renewNow = true
 			continue
 		}
@@ -348,8 +494,13 @@ func (m *WIDMgr) renew() {
 	}
 
 	var wait time.Duration
+	status := cstructs.IdentityRenewalStatus{Expiration: minExp}
 	if !renewNow {
-		wait = helper.ExpiryToRenewTime(minExp, time.Now, m.minWait)
+		wait = m.renewWait(minExp)
+		if last, next, ok := m.restoredSchedule(reqs); ok {
+			status.LastRenewal = last
+			wait = m.restoredWait(next, minExp)
+		}
 	}
 
 	timer, timerStop := helper.NewStoppedTimer()
@@ -365,31 +516,46 @@ func (m *WIDMgr) renew() {
 			return
 		}
 
-		m.logger.Debug("waiting to renew identities", "num", len(reqs), "wait", wait)
+		status.NextRenewal = time.Now().Add(wait)
+		m.setRenewalStatus(task, status)
+		m.putRenewals(reqs, status)
+
+		logger.Debug("waiting to renew identities", "num", len(reqs), "wait", wait)
 		timer.Reset(wait)
 		select {
 		case <-timer.C:
-			m.logger.Trace("getting new signed identities", "num", len(reqs))
+			logger.Trace("getting new signed identities", "num", len(reqs))
 		case <-m.stopCtx.Done():
 			// close watchers and shutdown
 			m.Shutdown()
 			return
 		}
 
-		// Renew all tokens together since its cheap
+		if err := m.limiter.acquire(m.stopCtx); err != nil {
+			m.Shutdown()
+			return
+		}
+
+		// Renew all tokens of the task together since its cheap
 		// FIXME this will have to be revisited once we support identity change modes
 		tokens, err := m.signer.SignIdentities(m.minIndex, reqs)
-		if err != nil {
-			retry++
-			wait = helper.Backoff(m.minWait, time.Hour, retry) + helper.RandomStagger(m.minWait)
-			m.logger.Error("error renewing workload identities", "error", err, "next", wait)
-			continue
+		m.limiter.release()
+		if err == nil && len(tokens) == 0 {
+			err = errors.New("no tokens")
 		}
-
-		if len(tokens) == 0 {
+		if err != nil {
 			retry++
-			wait = helper.Backoff(m.minWait, time.Hour, retry) + helper.RandomStagger(m.minWait)
-			m.logger.Error("error renewing workload identities", "error", "no tokens", "next", wait)
+			wait = helper.Backoff(m.minWait, time.Hour, retry) + helper.RandomStagger(m.minWait) + helper.RandomStagger(m.jitter)
+			status.Failures = int(retry)
+			status.LastError = err.Error()
+			logger.Error("error renewing workload identities", "error", err, "next", wait)
+			m.reportEvents(reqs, structs.TypeWorkloadIdentityRenewalFailed, minExp, err)
+
+			expired := status.Expired
+			wait = m.checkExpiration(task, minExp, &status, wait)
+			if !expired && status.Expired {
+				m.reportEvents(reqs, structs.TypeWorkloadIdentityExpired, minExp, nil)
+			}
 			continue
 		}
 
@@ -413,10 +579,183 @@ func (m *WIDMgr) renew() {
 			}
 		}
 
+		m.putIdentities()
+
 		// Success! Set next renewal and reset retries
-		wait = helper.ExpiryToRenewTime(minExp, time.Now, m.minWait)
+		wait = m.renewWait(minExp)
 		retry = 0
+		status = cstructs.IdentityRenewalStatus{LastRenewal: time.Now(), Expiration: minExp}
+	}
+}
+
+// renewWait returns how long to wait before renewing identities expiring at
+// exp. The jitter is bounded so they're still renewed before they expire.
+func (m *WIDMgr) renewWait(exp time.Time) time.Duration {
+	wait := helper.ExpiryToRenewTime(exp, time.Now, m.minWait)
+	if room := time.Until(exp) - wait; room > 0 {
+		wait += helper.RandomStagger(min(m.jitter, room))
+	}
+	return wait
+}
+
+// restoredSchedule returns when the identities of the requests were last
+// renewed and when they were scheduled to be renewed next before the client
+// agent restarted. It returns false if any of the identities has no schedule,
+// or was signed again since.
+func (m *WIDMgr) restoredSchedule(reqs []*structs.WorkloadIdentityRequest) (time.Time, time.Time, bool) {
+	m.stateLock.Lock()
+	defer m.stateLock.Unlock()
+
+	var last, next time.Time
+	for _, req := range reqs {
+		renewal := m.renewals[req.WIHandle]
+		token := m.get(req.WIHandle)
+		if renewal == nil || token == nil || renewal.NextRenewal.IsZero() ||
+			!renewal.Expiration.Equal(token.Expiration) {
+			return time.Time{}, time.Time{}, false
+		}
+		if renewal.LastRenewal.After(last) {
+			last = renewal.LastRenewal
+		}
+		if next.IsZero() || renewal.NextRenewal.Before(next) {
+			next = renewal.NextRenewal
+		}
+	}
+	return last, next, true
+}
+
+// restoredWait returns how long to wait before renewing identities expiring at
+// exp, which were scheduled to be renewed at next before the client agent
+// restarted. The schedule is kept so the renewals of the allocations stay
+// staggered, and the renewals missed while the client was down are spread over
+// the jitter, or the minimum wait, instead of all running at once.
+func (m *WIDMgr) restoredWait(next, exp time.Time) time.Duration {
+	if wait := time.Until(next); wait > 0 {
+		return wait
+	}
+	if room := time.Until(exp); room > 0 {
+		return helper.RandomStagger(min(max(m.jitter, m.minWait), room))
+	}
+	return 0
+}
+
+// putRenewals stores the renewal schedule of the identities of the requests in
+// the state DB. Failing to store it only means the renewals are rescheduled
+// after a client agent restart, so errors are only logged.
+func (m *WIDMgr) putRenewals(reqs []*structs.WorkloadIdentityRequest, status cstructs.IdentityRenewalStatus) {
+	m.stateLock.Lock()
+	defer m.stateLock.Unlock()
+
+	for _, req := range reqs {
+		token := m.get(req.WIHandle)
+		if token == nil {
+			delete(m.renewals, req.WIHandle)
+			continue
+		}
+		m.renewals[req.WIHandle] = &arstate.IdentityRenewal{
+			WIHandle:    req.WIHandle,
+			Expiration:  token.Expiration,
+			LastRenewal: status.LastRenewal,
+			NextRenewal: status.NextRenewal,
+		}
 	}
+
+	renewals := make([]*arstate.IdentityRenewal, 0, len(m.renewals))
+	for _, renewal := range m.renewals {
+		renewals = append(renewals, renewal)
+	}
+	if err := m.db.PutAllocIdentityRenewals(m.allocID, renewals); err != nil {
+		m.logger.Warn("failed to store identity renewals", "error", err)
+	}
+}
+
+// putIdentities stores the last signed identities in the state DB, so the
+// renewed identities are restored after a client agent restart instead of
+// being signed again.
+func (m *WIDMgr) putIdentities() {
+	m.stateLock.Lock()
+	defer m.stateLock.Unlock()
+
+	identities := make([]*structs.SignedWorkloadIdentity, 0, len(m.widSpecs))
+	m.lastTokenLock.RLock()
+	for id := range m.widSpecs {
+		if token := m.lastToken[id]; token != nil {
+			identities = append(identities, token)
+		}
+	}
+	m.lastTokenLock.RUnlock()
+
+	if err := m.db.PutAllocIdentities(m.allocID, identities); err != nil {
+		m.logger.Warn("failed to store signed identities", "error", err)
+	}
+}
+
+// checkExpiration updates the renewal status of the identities of a task that
+// failed to renew, which expire at minExp. They're stale once they expire, and
+// expired once the grace period has passed, at which point the expired func is
+// called. It returns the time to wait before renewing them, shortened so the
+// status is updated when they expire and when the grace period ends.
+func (m *WIDMgr) checkExpiration(task string, minExp time.Time, status *cstructs.IdentityRenewalStatus, wait time.Duration) time.Duration {
+	if minExp.IsZero() || status.Expired {
+		return wait
+	}
+
+	now := time.Now()
+	deadline := minExp.Add(m.gracePeriod)
+	switch {
+	case now.Before(minExp):
+		return min(wait, minExp.Sub(now))
+	case now.Before(deadline):
+		if !status.Stale {
+			m.logger.Warn("workload identities expired, serving them during the grace period",
+				"task", task, "expiration", minExp, "grace_period", m.gracePeriod)
+		}
+		status.Stale = true
+		return min(wait, deadline.Sub(now))
+	default:
+		m.logger.Error("workload identities expired", "task", task, "expiration", minExp)
+		status.Stale = false
+		status.Expired = true
+		if m.expiredFunc != nil && task != "" {
+			m.expiredFunc(task)
+		}
+		return wait
+	}
+}
+
+// reportEvents reports an event of the identities of the requests to the
+// servers in the background, so they're published on the event stream. The
+// servers may be unreachable when identities fail to renew, so errors are
+// only logged.
+func (m *WIDMgr) reportEvents(reqs []*structs.WorkloadIdentityRequest, eventType string, expiration time.Time, err error) {
+	events := make([]*structs.WorkloadIdentityEvent, 0, len(reqs))
+	for _, req := range reqs {
+		event := &structs.WorkloadIdentityEvent{
+			AllocID:    req.AllocID,
+			WIHandle:   req.WIHandle,
+			Type:       eventType,
+			Expiration: expiration,
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		events = append(events, event)
+	}
+
+	go func() {
+		if err := m.signer.ReportIdentityEvents(events); err != nil {
+			m.logger.Debug("failed to report workload identity events", "type", eventType, "error", err)
+		}
+	}()
+}
+
+// setRenewalStatus stores the renewal status of the identities of a task in
+// the alloc hook resources. The status of group services isn't stored.
+func (m *WIDMgr) setRenewalStatus(task string, status cstructs.IdentityRenewalStatus) {
+	if m.hookResources == nil || task == "" {
+		return
+	}
+	m.hookResources.SetIdentityRenewal(task, status)
 }
 
 // send must be called while holding the m.watchersLock
@@ -439,3 +778,17 @@ func (m *WIDMgr) send(id structs.WIHandle, token *structs.SignedWorkloadIdentity
 		c <- token
 	}
 }
+
+// sortSignedIdentities sorts signed identities by workload and identity name,
+// with the identities of tasks before the identities of services.
+func sortSignedIdentities(tokens []*structs.SignedWorkloadIdentity) {
+	slices.SortFunc(tokens, func(a, b *structs.SignedWorkloadIdentity) int {
+		if a.WorkloadType != b.WorkloadType {
+			return int(a.WorkloadType) - int(b.WorkloadType)
+		}
+		if a.WorkloadIdentifier != b.WorkloadIdentifier {
+			return strings.Compare(a.WorkloadIdentifier, b.WorkloadIdentifier)
+		}
+		return strings.Compare(a.IdentityName, b.IdentityName)
+	})
+}
@@ -4,14 +4,19 @@
 package widmgr
 
 import (
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/nomad/ci"
 	cstate "github.com/hashicorp/nomad/client/state"
+	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
 )
 
 func TestWIDMgr_Restore(t *testing.T) {
@@ -62,3 +67,288 @@ func TestWIDMgr_Restore(t *testing.T) {
 	must.NoError(t, err)
 	must.True(t, hasExpired)
 }
+
+func TestWIDMgr_RenewPerTask(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+
+	// The identity of the sidecar is unknown to the signer, so its renewals
+	// fail while the identity of the web task is renewed.
+	alloc := mock.Alloc()
+	tg := alloc.Job.TaskGroups[0]
+	web := tg.Tasks[0]
+	web.Services = nil
+	web.Identities = []*structs.WorkloadIdentity{{Name: "good", TTL: time.Hour}}
+	sidecar := web.Copy()
+	sidecar.Name = "sidecar"
+	sidecar.Identities = []*structs.WorkloadIdentity{{Name: "bad", TTL: time.Hour}}
+	tg.Tasks = append(tg.Tasks, sidecar)
+
+	signer := NewMockWIDSigner(web.Identities)
+	hookResources := cstructs.NewAllocHookResources()
+	mgr := NewWIDMgr(signer, alloc, db, logger)
+	mgr.SetMinWait(time.Millisecond)
+	mgr.SetHookResources(hookResources)
+	t.Cleanup(mgr.Shutdown)
+
+	mgr.startRenewals()
+
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			status, ok := hookResources.GetIdentityRenewal("sidecar")
+			return ok && status.Failures > 0
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	status, ok := hookResources.GetIdentityRenewal("sidecar")
+	must.True(t, ok)
+	must.StrContains(t, status.LastError, `unknown identity: "bad"`)
+	must.True(t, status.LastRenewal.IsZero())
+
+	status, ok = hookResources.GetIdentityRenewal("web")
+	must.True(t, ok)
+	must.Zero(t, status.Failures)
+	must.False(t, status.LastRenewal.IsZero())
+	must.True(t, status.NextRenewal.After(status.LastRenewal))
+
+	token, err := mgr.Get(*web.IdentityHandle(web.Identities[0]))
+	must.NoError(t, err)
+	must.NotEq(t, "", token.JWT)
+	must.True(t, token.Renewal)
+
+	// The renewed identity is stored
+	stored, err := db.GetAllocIdentities(alloc.ID)
+	must.NoError(t, err)
+	must.SliceContains(t, stored, token)
+}
+
+func TestWIDMgr_List(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	service := task.Services[0]
+	widSpecs := []*structs.WorkloadIdentity{
+		{ServiceName: service.MakeUniqueIdentityName()},
+		{Name: "extra", TTL: time.Hour},
+		{Name: "consul", TTL: time.Hour},
+	}
+	service.Identity = widSpecs[0]
+	task.Identities = widSpecs[1:]
+
+	mgr := NewWIDMgr(NewMockWIDSigner(widSpecs), alloc, db, logger)
+	must.SliceEmpty(t, mgr.List())
+	must.NoError(t, mgr.getInitialIdentities())
+
+	// Task identities are sorted by name before service identities
+	tokens := mgr.List()
+	must.Len(t, 3, tokens)
+	must.Eq(t, *task.IdentityHandle(widSpecs[2]), tokens[0].WIHandle)
+	must.Eq(t, *task.IdentityHandle(widSpecs[1]), tokens[1].WIHandle)
+	must.Eq(t, *service.IdentityHandle(), tokens[2].WIHandle)
+	for _, token := range tokens {
+		must.NotEq(t, "", token.JWT)
+	}
+}
+
+// TestWIDMgr_RestoreRenewals asserts the renewal schedules of the identities
+// are restored after a client restart, and the renewals missed while the
+// client was down are staggered.
+func TestWIDMgr_RestoreRenewals(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Services = nil
+	task.Identities = []*structs.WorkloadIdentity{{Name: "extra", TTL: time.Hour}}
+	signer := NewMockWIDSigner(task.Identities)
+
+	// run returns the next renewal of the identities of a manager started
+	// with the state of the previous one
+	run := func() time.Time {
+		hookResources := cstructs.NewAllocHookResources()
+		mgr := NewWIDMgr(signer, alloc, db, logger)
+		mgr.SetHookResources(hookResources)
+		must.NoError(t, mgr.Run())
+		defer mgr.Shutdown()
+
+		var status cstructs.IdentityRenewalStatus
+		must.Wait(t, wait.InitialSuccess(
+			wait.BoolFunc(func() bool {
+				var ok bool
+				status, ok = hookResources.GetIdentityRenewal(task.Name)
+				return ok
+			}),
+			wait.Timeout(5*time.Second),
+			wait.Gap(10*time.Millisecond),
+		))
+		return status.NextRenewal
+	}
+
+	next := run()
+	renewals, err := db.GetAllocIdentityRenewals(alloc.ID)
+	must.NoError(t, err)
+	must.SliceLen(t, 1, renewals)
+	must.True(t, next.Equal(renewals[0].NextRenewal))
+
+	// The renewal is kept after a restart
+	restored := run()
+	must.Between(t, -time.Second, restored.Sub(next), time.Second)
+
+	// The renewal was missed while the client was down
+	renewals[0].NextRenewal = time.Now().Add(-time.Minute)
+	must.NoError(t, db.PutAllocIdentityRenewals(alloc.ID, renewals))
+	restored = run()
+	must.Between(t, 0, time.Until(restored), 10*time.Second)
+}
+
+// errSigner fails to sign identities, as when the client is disconnected from
+// the servers.
+type errSigner struct {
+	*MockWIDSigner
+}
+
+func (errSigner) SignIdentities(uint64, []*structs.WorkloadIdentityRequest) ([]*structs.SignedWorkloadIdentity, error) {
+	return nil, errors.New("no servers")
+}
+
+func TestWIDMgr_Run_GracePeriod(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Services = nil
+	task.Identities = []*structs.WorkloadIdentity{{Name: "extra", TTL: time.Hour}}
+	wid := *task.IdentityHandle(task.Identities[0])
+
+	// store an identity that expired a second ago
+	signer := NewMockWIDSigner(task.Identities)
+	signer.mockNow = time.Now().Add(-time.Hour - time.Second)
+	must.NoError(t, NewWIDMgr(signer, alloc, db, logger).getInitialIdentities())
+
+	// without a grace period the expired identity isn't served
+	mgr := NewWIDMgr(errSigner{signer}, alloc, db, logger)
+	must.ErrorContains(t, mgr.Run(), "no servers")
+
+	// the identity is served during the grace period
+	mgr = NewWIDMgr(errSigner{signer}, alloc, db, logger)
+	mgr.SetGracePeriod(time.Minute)
+	must.NoError(t, mgr.Run())
+	t.Cleanup(mgr.Shutdown)
+
+	token, err := mgr.Get(wid)
+	must.NoError(t, err)
+	must.NotEq(t, "", token.JWT)
+
+	// the grace period is over
+	mgr = NewWIDMgr(errSigner{signer}, alloc, db, logger)
+	mgr.SetGracePeriod(time.Millisecond)
+	must.ErrorContains(t, mgr.Run(), "no servers")
+}
+
+func TestWIDMgr_GracePeriod(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	db := cstate.NewMemDB(logger)
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Services = nil
+	task.Identities = []*structs.WorkloadIdentity{{Name: "extra", TTL: time.Hour}}
+
+	// sign an identity expiring shortly, and fail to renew it
+	signer := NewMockWIDSigner(task.Identities)
+	signer.mockNow = time.Now().Add(-time.Hour + 500*time.Millisecond)
+
+	hookResources := cstructs.NewAllocHookResources()
+	expiredCh := make(chan string, 1)
+	mgr := NewWIDMgr(signer, alloc, db, logger)
+	mgr.SetMinWait(time.Millisecond)
+	mgr.SetHookResources(hookResources)
+	mgr.SetGracePeriod(time.Second)
+	mgr.SetExpiredFunc(func(task string) { expiredCh <- task })
+	must.NoError(t, mgr.getInitialIdentities())
+	mgr.signer = errSigner{signer}
+	t.Cleanup(mgr.Shutdown)
+
+	mgr.startRenewals()
+
+	// the identities are stale once they expire
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			status, _ := hookResources.GetIdentityRenewal(task.Name)
+			return status.Stale
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	// and expired once the grace period is over
+	select {
+	case name := <-expiredCh:
+		must.Eq(t, task.Name, name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected identities to expire")
+	}
+	status, ok := hookResources.GetIdentityRenewal(task.Name)
+	must.True(t, ok)
+	must.True(t, status.Expired)
+	must.False(t, status.Stale)
+	must.Positive(t, status.Failures)
+
+	// the last identity is still served
+	_, err := mgr.Get(*task.IdentityHandle(task.Identities[0]))
+	must.NoError(t, err)
+
+	// the renewal failures and the expiration are reported in the background
+	hasEvent := func(eventType string) bool {
+		return slices.ContainsFunc(signer.Events(), func(e *structs.WorkloadIdentityEvent) bool {
+			return e.Type == eventType && e.AllocID == alloc.ID && e.IdentityName == "extra"
+		})
+	}
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			return hasEvent(structs.TypeWorkloadIdentityRenewalFailed) &&
+				hasEvent(structs.TypeWorkloadIdentityExpired)
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+	for _, event := range signer.Events() {
+		if event.Type == structs.TypeWorkloadIdentityRenewalFailed {
+			must.Eq(t, "no servers", event.Error)
+		}
+	}
+}
+
+func TestWIDMgr_renewWait(t *testing.T) {
+	ci.Parallel(t)
+
+	mgr := NewWIDMgr(nil, mock.Alloc(), nil, testlog.HCLogger(t))
+	mgr.SetMinWait(time.Second)
+	mgr.SetJitter(time.Hour)
+
+	// The jitter is bounded by the expiration
+	for i := 0; i < 100; i++ {
+		exp := time.Now().Add(time.Minute)
+		must.Between(t, 30*time.Second, mgr.renewWait(exp), time.Minute)
+	}
+
+	// Identities expiring before the min wait are renewed after it
+	exp := time.Now().Add(time.Second)
+	must.Between(t, time.Second, mgr.renewWait(exp), 2*time.Second)
+}
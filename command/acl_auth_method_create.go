@@ -27,6 +27,7 @@ type ACLAuthMethodCreateCommand struct {
 	methodType    string
 	tokenLocality string
 	maxTokenTTL   time.Duration
+	renewalGrace  time.Duration
 	isDefault     bool
 	config        string
 	json          bool
@@ -59,6 +60,10 @@ ACL Auth Method Create Options:
     Sets the duration of time all tokens created by this auth method should be
     valid for.
 
+  -token-renewal-grace-period
+    Sets the duration after the expiration of the tokens created by this auth
+    method during which they can still be renewed by logging in again.
+
   -token-locality
     Defines the kind of token that this auth method should produce. This can be
     either 'local' or 'global'.
@@ -84,14 +89,15 @@ ACL Auth Method Create Options:
 func (a *ACLAuthMethodCreateCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(a.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"-name":           complete.PredictAnything,
-			"-type":           complete.PredictSet("OIDC", "JWT"),
-			"-max-token-ttl":  complete.PredictAnything,
-			"-token-locality": complete.PredictSet("local", "global"),
-			"-default":        complete.PredictSet("true", "false"),
-			"-config":         complete.PredictNothing,
-			"-json":           complete.PredictNothing,
-			"-t":              complete.PredictAnything,
+			"-name":                       complete.PredictAnything,
+			"-type":                       complete.PredictSet("OIDC", "JWT"),
+			"-max-token-ttl":              complete.PredictAnything,
+			"-token-renewal-grace-period": complete.PredictAnything,
+			"-token-locality":             complete.PredictSet("local", "global"),
+			"-default":                    complete.PredictSet("true", "false"),
+			"-config":                     complete.PredictNothing,
+			"-json":                       complete.PredictNothing,
+			"-t":                          complete.PredictAnything,
 		})
 }
 
@@ -114,6 +120,7 @@ func (a *ACLAuthMethodCreateCommand) Run(args []string) int {
 	flags.StringVar(&a.methodType, "type", "", "")
 	flags.StringVar(&a.tokenLocality, "token-locality", "", "")
 	flags.DurationVar(&a.maxTokenTTL, "max-token-ttl", 0, "")
+	flags.DurationVar(&a.renewalGrace, "token-renewal-grace-period", 0, "")
 	flags.BoolVar(&a.isDefault, "default", false, "")
 	flags.StringVar(&a.config, "config", "", "")
 	flags.BoolVar(&a.json, "json", false, "")
@@ -142,6 +149,10 @@ func (a *ACLAuthMethodCreateCommand) Run(args []string) int {
 		a.Ui.Error("Max token TTL must be set to a value between min and max TTL configured for the server.")
 		return 1
 	}
+	if a.renewalGrace < 0 {
+		a.Ui.Error("Token renewal grace period must not be negative")
+		return 1
+	}
 	if !slices.Contains([]string{"OIDC", "JWT"}, strings.ToUpper(a.methodType)) {
 		a.Ui.Error("ACL auth method type must be set to 'OIDC' or 'JWT'")
 		return 1
@@ -166,12 +177,13 @@ func (a *ACLAuthMethodCreateCommand) Run(args []string) int {
 
 	// Set up the auth method with the passed parameters.
 	authMethod := api.ACLAuthMethod{
-		Name:          a.name,
-		Type:          strings.ToUpper(a.methodType),
-		TokenLocality: a.tokenLocality,
-		MaxTokenTTL:   a.maxTokenTTL,
-		Default:       a.isDefault,
-		Config:        &configJSON,
+		Name:                    a.name,
+		Type:                    strings.ToUpper(a.methodType),
+		TokenLocality:           a.tokenLocality,
+		MaxTokenTTL:             a.maxTokenTTL,
+		TokenRenewalGracePeriod: a.renewalGrace,
+		Default:                 a.isDefault,
+		Config:                  &configJSON,
 	}
 
 	// Get the HTTP client.
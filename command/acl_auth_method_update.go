@@ -27,6 +27,7 @@ type ACLAuthMethodUpdateCommand struct {
 	methodType    string
 	tokenLocality string
 	maxTokenTTL   time.Duration
+	renewalGrace  time.Duration
 	isDefault     bool
 	config        string
 	json          bool
@@ -55,6 +56,10 @@ ACL Auth Method Update Options:
     Updates the duration of time all tokens created by this auth method should be
     valid for.
 
+  -token-renewal-grace-period
+    Updates the duration after the expiration of the tokens created by this auth
+    method during which they can still be renewed by logging in again.
+
   -token-locality
     Updates the kind of token that this auth method should produce. This can be
     either 'local' or 'global'.
@@ -81,13 +86,14 @@ ACL Auth Method Update Options:
 func (a *ACLAuthMethodUpdateCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(a.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"-type":           complete.PredictSet("OIDC", "JWT"),
-			"-max-token-ttl":  complete.PredictAnything,
-			"-token-locality": complete.PredictSet("local", "global"),
-			"-default":        complete.PredictSet("true", "false"),
-			"-config":         complete.PredictNothing,
-			"-json":           complete.PredictNothing,
-			"-t":              complete.PredictAnything,
+			"-type":                       complete.PredictSet("OIDC", "JWT"),
+			"-max-token-ttl":              complete.PredictAnything,
+			"-token-renewal-grace-period": complete.PredictAnything,
+			"-token-locality":             complete.PredictSet("local", "global"),
+			"-default":                    complete.PredictSet("true", "false"),
+			"-config":                     complete.PredictNothing,
+			"-json":                       complete.PredictNothing,
+			"-t":                          complete.PredictAnything,
 		})
 }
 
@@ -109,6 +115,7 @@ func (a *ACLAuthMethodUpdateCommand) Run(args []string) int {
 	flags.StringVar(&a.methodType, "type", "", "")
 	flags.StringVar(&a.tokenLocality, "token-locality", "", "")
 	flags.DurationVar(&a.maxTokenTTL, "max-token-ttl", 0, "")
+	flags.DurationVar(&a.renewalGrace, "token-renewal-grace-period", 0, "")
 	flags.StringVar(&a.config, "config", "", "")
 	flags.BoolVar(&a.isDefault, "default", false, "")
 	flags.BoolVar(&a.json, "json", false, "")
@@ -142,7 +149,7 @@ func (a *ACLAuthMethodUpdateCommand) Run(args []string) int {
 
 	// Check if any command-specific flags were set
 	setFlags := []string{}
-	for _, f := range []string{"type", "token-locality", "max-token-ttl", "config", "default"} {
+	for _, f := range []string{"type", "token-locality", "max-token-ttl", "token-renewal-grace-period", "config", "default"} {
 		if flagPassed(flags, f) {
 			setFlags = append(setFlags, f)
 		}
@@ -178,6 +185,14 @@ func (a *ACLAuthMethodUpdateCommand) Run(args []string) int {
 		updatedMethod.MaxTokenTTL = a.maxTokenTTL
 	}
 
+	if slices.Contains(setFlags, "token-renewal-grace-period") {
+		if a.renewalGrace < 0 {
+			a.Ui.Error("Token renewal grace period must not be negative")
+			return 1
+		}
+		updatedMethod.TokenRenewalGracePeriod = a.renewalGrace
+	}
+
 	if slices.Contains(setFlags, "default") {
 		updatedMethod.Default = a.isDefault
 	}
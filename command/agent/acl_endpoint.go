@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -123,6 +124,13 @@ func (s *HTTPServer) ACLTokensRequest(resp http.ResponseWriter, req *http.Reques
 	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
 		return nil, nil
 	}
+	if raw := req.URL.Query().Get("expiring_within"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return nil, CodedError(400, fmt.Sprintf("invalid expiring_within value %q", raw))
+		}
+		args.ExpiringWithin = d
+	}
 
 	var out structs.ACLTokenListResponse
 	if err := s.agent.RPC("ACL.ListTokens", &args, &out); err != nil {
@@ -294,8 +302,13 @@ func (s *HTTPServer) UpsertOneTimeToken(resp http.ResponseWriter, req *http.Requ
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
-	// the request body is empty but we need to parse to get the auth token
+	// the request body is optional but we need to parse to get the auth token
 	args := structs.OneTimeTokenUpsertRequest{}
+	if req.ContentLength > 0 {
+		if err := decodeBody(req, &args); err != nil {
+			return nil, CodedError(http.StatusBadRequest, err.Error())
+		}
+	}
 	s.parseWriteRequest(req, &args.WriteRequest)
 
 	var out structs.OneTimeTokenUpsertResponse
@@ -878,6 +891,59 @@ func (s *HTTPServer) ACLOIDCCompleteAuthRequest(resp http.ResponseWriter, req *h
 	return out.ACLToken, nil
 }
 
+// ACLWhoAmIRequest describes the identity of the request, along with its ACL
+// policies and effective capabilities, and is callable via the /v1/acl/whoami
+// HTTP API.
+func (s *HTTPServer) ACLWhoAmIRequest(resp http.ResponseWriter, req *http.Request) (any, error) {
+	if req.Method != http.MethodGet {
+		return nil, CodedError(http.StatusMethodNotAllowed, ErrInvalidMethod)
+	}
+
+	args := structs.GenericRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.ACLWhoAmIResponse
+	if err := s.agent.RPC("ACL.WhoAmI", &args, &out); err != nil {
+		return nil, err
+	}
+	setMeta(resp, &out.QueryMeta)
+
+	// The caller already knows its secret, so don't echo it back where it
+	// could end up in logs or terminal scrollback.
+	if out.Identity != nil && out.Identity.ACLToken != nil {
+		out.Identity.ACLToken = out.Identity.ACLToken.Copy()
+		out.Identity.ACLToken.SecretID = ""
+	}
+	return out, nil
+}
+
+// ACLBindingRulesTestRequest evaluates a sample claim set against the binding
+// rules of an auth method and is callable via the /v1/acl/binding-rules/test
+// HTTP API.
+func (s *HTTPServer) ACLBindingRulesTestRequest(resp http.ResponseWriter, req *http.Request) (any, error) {
+	// The endpoint only supports PUT or POST requests.
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		return nil, CodedError(http.StatusMethodNotAllowed, ErrInvalidMethod)
+	}
+
+	var args structs.ACLBindingRulesTestRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(http.StatusBadRequest, err.Error())
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.ACLBindingRulesTestResponse
+	if err := s.agent.RPC(structs.ACLTestBindingRulesRPCMethod, &args, &out); err != nil {
+		return nil, err
+	}
+	setMeta(resp, &out.QueryMeta)
+	return out, nil
+}
+
 // ACLLoginRequest performs a non-interactive authentication request
 func (s *HTTPServer) ACLLoginRequest(resp http.ResponseWriter, req *http.Request) (any, error) {
 	// The endpoint only supports PUT or POST requests.
@@ -896,3 +962,128 @@ func (s *HTTPServer) ACLLoginRequest(resp http.ResponseWriter, req *http.Request
 	setIndex(resp, out.Index)
 	return out.ACLToken, nil
 }
+
+// ACLTokenRenewRequest renews an ACL token created by an auth method and is
+// callable via the /v1/acl/token/renew HTTP API.
+func (s *HTTPServer) ACLTokenRenewRequest(resp http.ResponseWriter, req *http.Request) (any, error) {
+	// The endpoint only supports PUT or POST requests.
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		return nil, CodedError(http.StatusMethodNotAllowed, ErrInvalidMethod)
+	}
+	var args structs.ACLTokenRenewRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(http.StatusBadRequest, err.Error())
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+	var out structs.ACLTokenRenewResponse
+	if err := s.agent.RPC(structs.ACLRenewTokenRPCMethod, &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out.ACLToken, nil
+}
+
+// ACLTokenExchangeRequest exchanges a workload identity for a short-lived ACL
+// token and is callable via the /v1/acl/token/exchange HTTP API. The request
+// and response follow the OAuth 2.0 token exchange defined in RFC 8693, with
+// the requested ACL policies passed as the space-delimited scope. If a JWT is
+// requested, the workload identity is exchanged for a JWT with the requested
+// audience instead.
+func (s *HTTPServer) ACLTokenExchangeRequest(resp http.ResponseWriter, req *http.Request) (any, error) {
+	// The endpoint only supports PUT or POST requests.
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		return nil, CodedError(http.StatusMethodNotAllowed, ErrInvalidMethod)
+	}
+	if err := req.ParseForm(); err != nil {
+		return nil, CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	if grantType := req.PostForm.Get("grant_type"); grantType != structs.OAuthGrantTypeTokenExchange {
+		return nil, CodedError(http.StatusBadRequest,
+			fmt.Sprintf("unsupported grant_type %q", grantType))
+	}
+	switch tokenType := req.PostForm.Get("subject_token_type"); tokenType {
+	case structs.OAuthTokenTypeJWT, structs.OAuthTokenTypeIDToken:
+	default:
+		return nil, CodedError(http.StatusBadRequest,
+			fmt.Sprintf("unsupported subject_token_type %q", tokenType))
+	}
+
+	var ttl time.Duration
+	if ttlStr := req.PostForm.Get("ttl"); ttlStr != "" {
+		d, err := time.ParseDuration(ttlStr)
+		if err != nil {
+			return nil, CodedError(http.StatusBadRequest, fmt.Sprintf("invalid ttl: %v", err))
+		}
+		ttl = d
+	}
+
+	switch tokenType := req.PostForm.Get("requested_token_type"); tokenType {
+	case "", structs.OAuthTokenTypeAccessToken:
+	case structs.OAuthTokenTypeJWT:
+		return s.aclTokenExchangeJWTRequest(resp, req, ttl)
+	default:
+		return nil, CodedError(http.StatusBadRequest,
+			fmt.Sprintf("unsupported requested_token_type %q", tokenType))
+	}
+
+	args := structs.ACLWorkloadIdentityExchangeRequest{
+		SubjectToken: req.PostForm.Get("subject_token"),
+		Policies:     strings.Fields(req.PostForm.Get("scope")),
+		TTL:          ttl,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.ACLWorkloadIdentityExchangeResponse
+	if err := s.agent.RPC(structs.ACLExchangeWorkloadIdentityRPCMethod, &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+
+	// Responses containing tokens must not be cached, as required by RFC
+	// 8693 section 2.2.
+	resp.Header().Set("Cache-Control", "no-store")
+
+	token := out.ACLToken
+	exchangeResp := &structs.OAuthTokenExchangeResponse{
+		AccessToken:     token.SecretID,
+		IssuedTokenType: structs.OAuthTokenTypeAccessToken,
+		TokenType:       "Bearer",
+		Scope:           strings.Join(token.Policies, " "),
+	}
+	if token.ExpirationTime != nil {
+		exchangeResp.ExpiresIn = int64(time.Until(*token.ExpirationTime).Seconds())
+	}
+	return exchangeResp, nil
+}
+
+// aclTokenExchangeJWTRequest exchanges a workload identity for a JWT with the
+// audience of the request. Only a single audience can be requested, since
+// the point of the exchange is to get tokens accepted by services which
+// reject long audience lists.
+func (s *HTTPServer) aclTokenExchangeJWTRequest(resp http.ResponseWriter, req *http.Request, ttl time.Duration) (any, error) {
+	audiences := req.PostForm["audience"]
+	if len(audiences) != 1 {
+		return nil, CodedError(http.StatusBadRequest, "exactly one audience must be requested")
+	}
+
+	args := structs.ACLWorkloadIdentityJWTExchangeRequest{
+		SubjectToken: req.PostForm.Get("subject_token"),
+		Audience:     audiences[0],
+		TTL:          ttl,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.ACLWorkloadIdentityJWTExchangeResponse
+	if err := s.agent.RPC(structs.ACLExchangeWorkloadIdentityJWTRPCMethod, &args, &out); err != nil {
+		return nil, err
+	}
+
+	resp.Header().Set("Cache-Control", "no-store")
+	return &structs.OAuthTokenExchangeResponse{
+		AccessToken:     out.JWT,
+		IssuedTokenType: structs.OAuthTokenTypeJWT,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(time.Until(out.Expiration).Seconds()),
+	}, nil
+}
@@ -344,6 +344,47 @@ func TestHTTP_ACLTokenList(t *testing.T) {
 	})
 }
 
+func TestHTTP_ACLTokenList_ExpiringWithin(t *testing.T) {
+	ci.Parallel(t)
+	httpACLTest(t, nil, func(s *TestAgent) {
+		p1 := mock.ACLToken()
+		p1.AccessorID = ""
+		p1.ExpirationTTL = 2 * time.Hour
+		p2 := mock.ACLToken()
+		p2.AccessorID = ""
+		args := structs.ACLTokenUpsertRequest{
+			Tokens: []*structs.ACLToken{p1, p2},
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				AuthToken: s.RootToken.SecretID,
+			},
+		}
+		var resp structs.ACLTokenUpsertResponse
+		must.NoError(t, s.Agent.RPC("ACL.UpsertTokens", &args, &resp))
+
+		// Only the token expiring within the duration is listed.
+		req, err := http.NewRequest(http.MethodGet, "/v1/acl/tokens?expiring_within=3h", nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+		setToken(req, s.RootToken)
+
+		obj, err := s.Server.ACLTokensRequest(respW, req)
+		must.NoError(t, err)
+		tokens := obj.([]*structs.ACLTokenListStub)
+		must.Len(t, 1, tokens)
+		must.Eq(t, resp.Tokens[0].AccessorID, tokens[0].AccessorID)
+
+		// Invalid durations are rejected.
+		req, err = http.NewRequest(http.MethodGet, "/v1/acl/tokens?expiring_within=soon", nil)
+		must.NoError(t, err)
+		respW = httptest.NewRecorder()
+		setToken(req, s.RootToken)
+
+		_, err = s.Server.ACLTokensRequest(respW, req)
+		must.ErrorContains(t, err, "invalid expiring_within value")
+	})
+}
+
 func TestHTTP_ACLTokenQuery(t *testing.T) {
 	ci.Parallel(t)
 	httpACLTest(t, nil, func(s *TestAgent) {
@@ -1687,6 +1728,93 @@ func TestHTTPServer_ACLBindingRuleSpecificRequest(t *testing.T) {
 	}
 }
 
+func TestHTTPServer_ACLBindingRulesTestRequest(t *testing.T) {
+	ci.Parallel(t)
+
+	testCases := []struct {
+		name   string
+		testFn func(srv *TestAgent)
+	}{
+		{
+			name: "incorrect method",
+			testFn: func(srv *TestAgent) {
+
+				// Build the HTTP request.
+				req, err := http.NewRequest(http.MethodGet, "/v1/acl/binding-rules/test", nil)
+				must.NoError(t, err)
+				respW := httptest.NewRecorder()
+
+				// Ensure we have a token set.
+				setToken(req, srv.RootToken)
+
+				// Send the HTTP request.
+				obj, err := srv.Server.ACLBindingRulesTestRequest(respW, req)
+				must.EqError(t, err, "Invalid method")
+				must.Nil(t, obj)
+			},
+		},
+		{
+			name: "success",
+			testFn: func(srv *TestAgent) {
+
+				// Generate and upsert a JWT ACL auth method, and a binding
+				// rule granting a policy which doesn't exist.
+				mockedAuthMethod := mock.ACLJWTAuthMethod()
+				mockedAuthMethod.Config.ClaimMappings = map[string]string{}
+				mockedAuthMethod.Config.ListClaimMappings = map[string]string{
+					"http://nomad.internal/policies": "policies",
+				}
+				must.NoError(t, srv.server.State().UpsertACLAuthMethods(
+					10, []*structs.ACLAuthMethod{mockedAuthMethod}))
+
+				mockBindingRule := mock.ACLBindingRule()
+				mockBindingRule.AuthMethod = mockedAuthMethod.Name
+				mockBindingRule.BindType = structs.ACLBindingRuleBindTypePolicy
+				mockBindingRule.Selector = "engineering in list.policies"
+				mockBindingRule.BindName = "engineering-rw"
+				must.NoError(t, srv.server.State().UpsertACLBindingRules(
+					20, []*structs.ACLBindingRule{mockBindingRule}, true))
+
+				// Generate the request body.
+				requestBody := structs.ACLBindingRulesTestRequest{
+					AuthMethodName: mockedAuthMethod.Name,
+					Claims: map[string]interface{}{
+						"http://nomad.internal/policies": []string{"engineering"},
+					},
+				}
+
+				// Build the HTTP request.
+				req, err := http.NewRequest(http.MethodPost, "/v1/acl/binding-rules/test", encodeReq(&requestBody))
+				must.NoError(t, err)
+				respW := httptest.NewRecorder()
+
+				// Ensure we have a token set.
+				setToken(req, srv.RootToken)
+
+				// Send the HTTP request.
+				obj, err := srv.Server.ACLBindingRulesTestRequest(respW, req)
+				must.NoError(t, err)
+				must.NotEq(t, "", respW.Header().Get("X-Nomad-Index"))
+
+				testResp, ok := obj.(structs.ACLBindingRulesTestResponse)
+				must.True(t, ok)
+				must.Eq(t, map[string][]string{"policies": {"engineering"}}, testResp.ListClaimMappings)
+				must.Len(t, 1, testResp.MatchingRules)
+				must.Eq(t, mockBindingRule.ID, testResp.MatchingRules[0].ID)
+				must.Eq(t, "engineering-rw", testResp.MatchingRules[0].BindName)
+				must.False(t, testResp.MatchingRules[0].Bound)
+				must.SliceEmpty(t, testResp.Policies)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			httpACLTest(t, nil, tc.testFn)
+		})
+	}
+}
+
 func TestHTTPServer_ACLOIDCAuthURLRequest(t *testing.T) {
 	ci.Parallel(t)
 
@@ -2036,3 +2164,165 @@ func TestHTTPServer_ACLLoginRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPServer_ACLTokenRenewRequest(t *testing.T) {
+	ci.Parallel(t)
+	httpACLTest(t, nil, func(s *TestAgent) {
+
+		// Build the HTTP request with an incorrect method.
+		req, err := http.NewRequest(http.MethodGet, "/v1/acl/token/renew", nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.ACLTokenRenewRequest(respW, req)
+		must.EqError(t, err, "Invalid method")
+		must.Nil(t, obj)
+
+		// Tokens which were not created by an auth method can't be renewed.
+		requestBody := structs.ACLTokenRenewRequest{
+			SecretID:   s.RootToken.SecretID,
+			LoginToken: "my-login-token",
+		}
+		req, err = http.NewRequest(http.MethodPost, "/v1/acl/token/renew", encodeReq(&requestBody))
+		must.NoError(t, err)
+		respW = httptest.NewRecorder()
+
+		_, err = s.Server.ACLTokenRenewRequest(respW, req)
+		must.ErrorContains(t, err, "token was not created by an auth method")
+	})
+}
+
+func TestHTTPServer_ACLWhoAmIRequest(t *testing.T) {
+	ci.Parallel(t)
+	httpACLTest(t, nil, func(s *TestAgent) {
+
+		// Build the HTTP request with an incorrect method.
+		req, err := http.NewRequest(http.MethodPost, "/v1/acl/whoami", nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.ACLWhoAmIRequest(respW, req)
+		must.EqError(t, err, "Invalid method")
+		must.Nil(t, obj)
+
+		// The identity of the token is returned without its secret.
+		req, err = http.NewRequest(http.MethodGet, "/v1/acl/whoami", nil)
+		must.NoError(t, err)
+		setToken(req, s.RootToken)
+		respW = httptest.NewRecorder()
+
+		obj, err = s.Server.ACLWhoAmIRequest(respW, req)
+		must.NoError(t, err)
+		out := obj.(structs.ACLWhoAmIResponse)
+		must.NotNil(t, out.Identity.ACLToken)
+		must.Eq(t, s.RootToken.AccessorID, out.Identity.ACLToken.AccessorID)
+		must.Eq(t, "", out.Identity.ACLToken.SecretID)
+		must.True(t, out.Capabilities.Management)
+		must.NotEq(t, "", s.RootToken.SecretID)
+	})
+}
+
+func TestHTTPServer_ACLTokenExchangeRequest(t *testing.T) {
+	ci.Parallel(t)
+
+	newReq := func(method string, form url.Values) *http.Request {
+		req, err := http.NewRequest(method, "/v1/acl/token/exchange",
+			bytes.NewBufferString(form.Encode()))
+		must.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	validForm := func() url.Values {
+		return url.Values{
+			"grant_type":         []string{structs.OAuthGrantTypeTokenExchange},
+			"subject_token":      []string{"not-a-jwt"},
+			"subject_token_type": []string{structs.OAuthTokenTypeJWT},
+			"scope":              []string{"policy-a policy-b"},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		method      string
+		modifyForm  func(url.Values)
+		expectedErr string
+	}{
+		{
+			name:        "incorrect method",
+			method:      http.MethodGet,
+			expectedErr: "Invalid method",
+		},
+		{
+			name:        "unsupported grant type",
+			method:      http.MethodPost,
+			modifyForm:  func(f url.Values) { f.Set("grant_type", "client_credentials") },
+			expectedErr: `unsupported grant_type "client_credentials"`,
+		},
+		{
+			name:        "unsupported subject token type",
+			method:      http.MethodPost,
+			modifyForm:  func(f url.Values) { f.Set("subject_token_type", structs.OAuthTokenTypeAccessToken) },
+			expectedErr: "unsupported subject_token_type",
+		},
+		{
+			name:        "unsupported requested token type",
+			method:      http.MethodPost,
+			modifyForm:  func(f url.Values) { f.Set("requested_token_type", "urn:ietf:params:oauth:token-type:saml2") },
+			expectedErr: "unsupported requested_token_type",
+		},
+		{
+			name:   "jwt without audience",
+			method: http.MethodPost,
+			modifyForm: func(f url.Values) {
+				f.Set("requested_token_type", structs.OAuthTokenTypeJWT)
+			},
+			expectedErr: "exactly one audience must be requested",
+		},
+		{
+			name:   "jwt with multiple audiences",
+			method: http.MethodPost,
+			modifyForm: func(f url.Values) {
+				f.Set("requested_token_type", structs.OAuthTokenTypeJWT)
+				f["audience"] = []string{"vault.io", "consul.io"}
+			},
+			expectedErr: "exactly one audience must be requested",
+		},
+		{
+			name:   "jwt with invalid subject token",
+			method: http.MethodPost,
+			modifyForm: func(f url.Values) {
+				f.Set("requested_token_type", structs.OAuthTokenTypeJWT)
+				f.Set("audience", "vault.io")
+			},
+			expectedErr: "unable to validate subject token",
+		},
+		{
+			name:        "invalid ttl",
+			method:      http.MethodPost,
+			modifyForm:  func(f url.Values) { f.Set("ttl", "forever") },
+			expectedErr: "invalid ttl",
+		},
+		{
+			name:        "invalid subject token",
+			method:      http.MethodPost,
+			expectedErr: "unable to validate subject token",
+		},
+	}
+
+	httpACLTest(t, nil, func(s *TestAgent) {
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				form := validForm()
+				if tc.modifyForm != nil {
+					tc.modifyForm(form)
+				}
+
+				respW := httptest.NewRecorder()
+				obj, err := s.Server.ACLTokenExchangeRequest(respW, newReq(tc.method, form))
+				must.ErrorContains(t, err, tc.expectedErr)
+				must.Nil(t, obj)
+			})
+		}
+	})
+}
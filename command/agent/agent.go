@@ -8,10 +8,12 @@ import (
 	"fmt"
 	"io"
 	golog "log"
+	"maps"
 	"net"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -22,11 +24,14 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	uuidparse "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/nomad/client"
+	"github.com/hashicorp/nomad/client/allocdir"
 	clientconfig "github.com/hashicorp/nomad/client/config"
 	clientconsul "github.com/hashicorp/nomad/client/consul"
+	"github.com/hashicorp/nomad/client/dnscache"
 	"github.com/hashicorp/nomad/client/lib/idset"
 	"github.com/hashicorp/nomad/client/lib/numalib/hw"
 	"github.com/hashicorp/nomad/client/state"
+	"github.com/hashicorp/nomad/client/templateproxy"
 	"github.com/hashicorp/nomad/command/agent/consul"
 	"github.com/hashicorp/nomad/command/agent/event"
 	"github.com/hashicorp/nomad/helper"
@@ -61,6 +66,10 @@ const (
 	// MaxRaftMultiplier is a fairly arbitrary upper bound that limits the
 	// amount of performance detuning that's possible.
 	MaxRaftMultiplier = 10
+
+	// maxRaftSnapshotChunkSize bounds the size of the chunks of Raft
+	// snapshots, which are buffered in memory when written and restored.
+	maxRaftSnapshotChunkSize = 256 * 1024 * 1024
 )
 
 // Agent is a long running daemon that is used to run both
@@ -127,6 +136,11 @@ type Agent struct {
 	builtinListener net.Listener
 	builtinDialer   *bufconndialer.BufConnWrapper
 
+	// templateProxies are the in-process proxies to Consul and Vault shared
+	// by all consul-template runners when the client template cache_proxy
+	// option is enabled.
+	templateProxies []*templateproxy.Proxy
+
 	// taskAPIServer is an HTTP server for attaching per-task listeners. Always
 	// requires auth.
 	taskAPIServer *builtinAPI
@@ -164,6 +178,9 @@ func NewAgent(config *Config, logger log.InterceptLogger, logOutput io.Writer, i
 	if err := a.setupEnterpriseAgent(logger); err != nil {
 		return nil, err
 	}
+	if a.server != nil {
+		a.server.SetAuditor(a.auditor)
+	}
 	if a.client == nil && a.server == nil {
 		return nil, fmt.Errorf("must have at least client or server mode enabled")
 	}
@@ -250,6 +267,18 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 		conf.RaftConfig.SnapshotThreshold = uint64(*vPtr)
 	}
 
+	if vPtr := agentConfig.Server.RaftSnapshotChunkSize; vPtr != nil {
+		size, err := humanize.ParseBytes(*vPtr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse raft_snapshot_chunk_size: %w", err)
+		}
+		if size > maxRaftSnapshotChunkSize {
+			return nil, fmt.Errorf("raft_snapshot_chunk_size must be at most %s, got %q",
+				humanize.IBytes(maxRaftSnapshotChunkSize), *vPtr)
+		}
+		conf.RaftSnapshotChunkSize = int(size)
+	}
+
 	conf.RaftConfig.ElectionTimeout *= time.Duration(raftMultiplier)
 	conf.RaftConfig.HeartbeatTimeout *= time.Duration(raftMultiplier)
 	conf.RaftConfig.LeaderLeaseTimeout *= time.Duration(raftMultiplier)
@@ -286,6 +315,12 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	if agentConfig.ACL.TokenMaxExpirationTTL != 0 {
 		conf.ACLTokenMaxExpirationTTL = agentConfig.ACL.TokenMaxExpirationTTL
 	}
+	if agentConfig.ACL.WorkloadPolicy != nil {
+		if err := agentConfig.ACL.WorkloadPolicy.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid acl workload_policy: %v", err)
+		}
+		conf.ACLWorkloadPolicy = conf.ACLWorkloadPolicy.Merge(agentConfig.ACL.WorkloadPolicy)
+	}
 	if agentConfig.Sentinel != nil {
 		conf.SentinelConfig = agentConfig.Sentinel
 	}
@@ -307,6 +342,9 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 		}
 		conf.EventBufferSize = int64(*agentConfig.Server.EventBufferSize)
 	}
+	if agentConfig.Server.EnableChangeFeed != nil {
+		conf.EnableChangeFeed = *agentConfig.Server.EnableChangeFeed
+	}
 	if agentConfig.Autopilot != nil {
 		if agentConfig.Autopilot.CleanupDeadServers != nil {
 			conf.AutopilotConfig.CleanupDeadServers = *agentConfig.Autopilot.CleanupDeadServers
@@ -358,6 +396,13 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 		conf.JobTrackedVersions = *agentConfig.Server.JobTrackedVersions
 	}
 
+	if agentConfig.Server.JobTrackedScalingEvents != nil {
+		if *agentConfig.Server.JobTrackedScalingEvents <= 0 {
+			return nil, fmt.Errorf("job_tracked_scaling_events must be greater than 0")
+		}
+		conf.JobTrackedScalingEvents = *agentConfig.Server.JobTrackedScalingEvents
+	}
+
 	conf.OIDCIssuer = agentConfig.Server.OIDCIssuer
 
 	// Set up the bind addresses
@@ -515,6 +560,38 @@ func convertServerConfig(agentConfig *Config) (*nomad.Config, error) {
 	if agentConfig.Server.DefaultSchedulerConfig != nil {
 		conf.DefaultSchedulerConfig = *agentConfig.Server.DefaultSchedulerConfig
 	}
+	conf.ScoringPlugins = helper.CopySlice(agentConfig.Server.ScoringPlugins)
+	conf.ServiceSyncs = helper.CopySlice(agentConfig.Server.ServiceSyncs)
+	if err := agentConfig.Server.JobLint.Validate(); err != nil {
+		return nil, fmt.Errorf("job_lint: %v", err)
+	}
+	conf.JobLint = agentConfig.Server.JobLint.Copy()
+	conf.UserDirectoryLookupDrivers = slices.Clone(agentConfig.Server.UserDirectoryLookupDrivers)
+	if err := agentConfig.Server.JobMetricsLabels.Validate(); err != nil {
+		return nil, fmt.Errorf("job_metrics_labels: %v", err)
+	}
+	conf.JobMetricsLabels = agentConfig.Server.JobMetricsLabels.Copy()
+	if err := agentConfig.Server.WorkloadCA.Validate(); err != nil {
+		return nil, fmt.Errorf("workload_ca: %v", err)
+	}
+	conf.WorkloadCA = agentConfig.Server.WorkloadCA.Copy()
+	conf.SignerPlugin = agentConfig.Server.SignerPlugin.Copy()
+	if err := agentConfig.Server.PayloadStore.Validate(); err != nil {
+		return nil, fmt.Errorf("payload_store: %v", err)
+	}
+	conf.PayloadStore = agentConfig.Server.PayloadStore.Copy()
+	if agentConfig.Audit != nil {
+		if err := agentConfig.Audit.Access.Validate(); err != nil {
+			return nil, fmt.Errorf("audit access: %v", err)
+		}
+		conf.AuditAccess = agentConfig.Audit.Access.Copy()
+	}
+	for namespace, weight := range agentConfig.Server.NamespaceUnblockWeights {
+		if weight < 1 {
+			return nil, fmt.Errorf("namespace_unblock_weights: weight of namespace %q must be at least 1", namespace)
+		}
+	}
+	conf.NamespaceUnblockWeights = maps.Clone(agentConfig.Server.NamespaceUnblockWeights)
 
 	// Add the Consul and Vault configs
 	conf.ConsulConfig = agentConfig.Consul
@@ -724,9 +801,18 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 	if agentConfig.Client.StateDir != "" {
 		conf.StateDir = agentConfig.Client.StateDir
 	}
+	conf.StateStore = agentConfig.Client.StateStore
 	if agentConfig.Client.AllocDir != "" {
 		conf.AllocDir = agentConfig.Client.AllocDir
 	}
+	conf.AllocDirs = agentConfig.Client.AllocDirs
+	conf.AllocDirPlacement = agentConfig.Client.AllocDirPlacement
+	if conf.AllocDirPlacement == "" {
+		conf.AllocDirPlacement = allocdir.RootPlacementMostFree
+	} else if !slices.Contains(allocdir.RootPlacements, conf.AllocDirPlacement) {
+		return nil, fmt.Errorf("invalid alloc_dir_placement %q, must be one of %s",
+			conf.AllocDirPlacement, strings.Join(allocdir.RootPlacements, ", "))
+	}
 	if agentConfig.Client.NetworkInterface != "" {
 		conf.NetworkInterface = agentConfig.Client.NetworkInterface
 	}
@@ -759,6 +845,7 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 	conf.MaxDynamicPort = agentConfig.Client.MaxDynamicPort
 	conf.MinDynamicPort = agentConfig.Client.MinDynamicPort
 	conf.DisableRemoteExec = agentConfig.Client.DisableRemoteExec
+	conf.ReportResourceUsage = agentConfig.Client.ReportResourceUsage
 
 	if agentConfig.Client.TemplateConfig != nil {
 		conf.TemplateConfig = agentConfig.Client.TemplateConfig.Copy()
@@ -777,9 +864,11 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 	conf.Node.Meta = agentConfig.Client.Meta
 	conf.Node.NodeClass = agentConfig.Client.NodeClass
 	conf.Node.NodePool = agentConfig.Client.NodePool
+	conf.Node.MaxAllocs = agentConfig.Client.MaxAllocs
 
 	// Set up the HTTP advertise address
 	conf.Node.HTTPAddr = agentConfig.AdvertiseAddrs.HTTP
+	conf.Node.ReverseTunnel = agentConfig.Client.ReverseTunnel
 
 	// Canonicalize Node struct
 	conf.Node.Canonicalize()
@@ -857,6 +946,33 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 	conf.GCDiskUsageThreshold = agentConfig.Client.GCDiskUsageThreshold
 	conf.GCInodeUsageThreshold = agentConfig.Client.GCInodeUsageThreshold
 	conf.GCMaxAllocs = agentConfig.Client.GCMaxAllocs
+
+	conf.IdentityGracePeriod = agentConfig.Client.IdentityGracePeriod
+
+	conf.HookTimeout = agentConfig.Client.HookTimeout
+	if len(agentConfig.Client.HookTimeouts) > 0 {
+		conf.HookTimeouts = make(map[string]time.Duration, len(agentConfig.Client.HookTimeouts))
+		for name, v := range agentConfig.Client.HookTimeouts {
+			timeout, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing timeout of hook %q: %v", name, err)
+			}
+			conf.HookTimeouts[name] = timeout
+		}
+	}
+	conf.EncryptSecretsDir = agentConfig.Client.EncryptSecretsDir
+	if agentConfig.Client.SecretsKeyRotationPeriod != 0 {
+		conf.SecretsKeyRotationPeriod = agentConfig.Client.SecretsKeyRotationPeriod
+	}
+	if agentConfig.Client.ClockSkewThreshold != 0 {
+		conf.ClockSkewThreshold = agentConfig.Client.ClockSkewThreshold
+	}
+	conf.MaxClockSkew = agentConfig.Client.MaxClockSkew
+	if identity := agentConfig.Client.Identity; identity != nil {
+		conf.IdentityRenewMinWait = identity.RenewMinWait
+		conf.IdentityRenewJitter = identity.RenewJitter
+		conf.IdentityMaxParallelRenewals = identity.MaxParallelRenewals
+	}
 	if agentConfig.Client.NoHostUUID != nil {
 		conf.NoHostUUID = *agentConfig.Client.NoHostUUID
 	} else {
@@ -898,6 +1014,28 @@ func convertClientConfig(agentConfig *Config) (*clientconfig.Config, error) {
 	}
 	conf.Drain = drainConfig
 
+	diskQuotaConfig, err := clientconfig.DiskQuotaConfigFromAgent(agentConfig.Client.DiskQuota)
+	if err != nil {
+		return nil, fmt.Errorf("invalid disk_quota config: %v", err)
+	}
+	conf.DiskQuota = diskQuotaConfig
+
+	dnsCacheConfig, err := clientconfig.DNSCacheConfigFromAgent(agentConfig.Client.DNSCache)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dns_cache config: %v", err)
+	}
+	conf.DNSCache = dnsCacheConfig
+
+	if dir := agentConfig.Client.StaticJobsDir; dir != "" {
+		staticJobs, err := parseStaticJobs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid static_jobs_dir: %v", err)
+		}
+		conf.StaticJobs = staticJobs
+	}
+
+	conf.AllocHookPlugins = helper.CopySlice(agentConfig.Client.AllocHookPlugins)
+
 	return conf, nil
 }
 
@@ -1106,7 +1244,10 @@ func (a *Agent) setupClient() error {
 		}
 	}
 	if conf.StateDBFactory == nil {
-		conf.StateDBFactory = state.GetStateDBFactory(conf.DevMode)
+		conf.StateDBFactory, err = state.GetStateStoreFactory(conf.StateStore, conf.DevMode)
+		if err != nil {
+			return fmt.Errorf("client setup failed: %v", err)
+		}
 	}
 
 	// Set up a custom listener and dialer. This is used by Nomad clients when
@@ -1116,6 +1257,22 @@ func (a *Agent) setupClient() error {
 	a.builtinListener, a.builtinDialer = bufconndialer.New()
 	conf.TemplateDialer = a.builtinDialer
 
+	// Set up the DNS cache shared by template runners and artifact
+	// downloads. This needs to happen before the template proxies are set up,
+	// since they resolve their upstreams with it.
+	if conf.DNSCache != nil && conf.DNSCache.Enabled {
+		conf.DNSResolver = dnscache.New(a.logger.Named("dns_cache"),
+			conf.DNSCache.TTL, conf.DNSCache.MaxStale)
+	}
+
+	// Set up the proxies to Consul and Vault shared by template runners. As
+	// with the builtin dialer this needs to happen before we call NewClient.
+	if conf.TemplateConfig != nil && conf.TemplateConfig.CacheProxy {
+		if err := a.setupTemplateProxies(conf); err != nil {
+			return fmt.Errorf("client setup failed: %v", err)
+		}
+	}
+
 	// Initialize builtin Task API server here for use in the client, but it
 	// won't accept connections until the HTTP servers are created.
 	a.taskAPIServer = newBuiltinAPI()
@@ -1220,6 +1377,58 @@ func (a *Agent) Leave() error {
 	return nil
 }
 
+// setupTemplateProxies starts the in-process proxies used by consul-template
+// runners to reach each Consul and Vault cluster, and sets their dialers in
+// the client configuration.
+func (a *Agent) setupTemplateProxies(conf *clientconfig.Config) error {
+	logger := a.logger.Named("template_proxy")
+
+	// The sockets of the Consul proxies are created in a directory only
+	// accessible by the agent, so tasks can't use them.
+	socketDir := filepath.Join(conf.StateDir, "template_proxy")
+	if conf.StateDir == "" {
+		dir, err := os.MkdirTemp("", "nomad-template-proxy")
+		if err != nil {
+			return fmt.Errorf("failed to create template proxy dir: %w", err)
+		}
+		socketDir = dir
+	}
+	if err := os.MkdirAll(socketDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create template proxy dir: %w", err)
+	}
+	if err := os.Chmod(socketDir, 0o700); err != nil {
+		return fmt.Errorf("failed to set template proxy dir permissions: %w", err)
+	}
+
+	conf.TemplateConsulAddrs = make(map[string]string, len(conf.ConsulConfigs))
+	for name, consulConfig := range conf.ConsulConfigs {
+		proxy, err := templateproxy.NewConsulProxy(logger, consulConfig, conf.DNSResolver)
+		if err != nil {
+			return err
+		}
+		sockPath := filepath.Join(socketDir, "consul_"+name+".sock")
+		if err := proxy.ListenUnix(sockPath); err != nil {
+			return fmt.Errorf("failed to start Consul proxy for cluster %q: %w", name, err)
+		}
+		a.templateProxies = append(a.templateProxies, proxy)
+		conf.TemplateConsulAddrs[name] = "unix://" + sockPath
+	}
+
+	vaultConfigs := conf.GetVaultConfigs(logger)
+	conf.TemplateVaultDialers = make(map[string]*bufconndialer.BufConnWrapper, len(vaultConfigs))
+	for name, vaultConfig := range vaultConfigs {
+		proxy, err := templateproxy.NewVaultProxy(logger, vaultConfig, conf.DNSResolver)
+		if err != nil {
+			return err
+		}
+		proxy.Run()
+		a.templateProxies = append(a.templateProxies, proxy)
+		conf.TemplateVaultDialers[name] = proxy.Dialer()
+	}
+
+	return nil
+}
+
 // Shutdown is used to terminate the agent.
 func (a *Agent) Shutdown() error {
 	a.shutdownLock.Lock()
@@ -1238,6 +1447,10 @@ func (a *Agent) Shutdown() error {
 		if err := a.client.Shutdown(); err != nil {
 			a.logger.Error("client shutdown failed", "error", err)
 		}
+
+		for _, proxy := range a.templateProxies {
+			proxy.Shutdown()
+		}
 	}
 	if a.server != nil {
 		if err := a.server.Shutdown(); err != nil {
@@ -71,6 +71,17 @@ func (s *HTTPServer) AgentSelfRequest(resp http.ResponseWriter, req *http.Reques
 		return nil, structs.ErrPermissionDenied
 	}
 
+	// Secrets are only returned to management tokens asking for the full
+	// configuration.
+	full, err := parseBool(req, "full")
+	if err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	redacted := full == nil || !*full
+	if !redacted && !aclObj.IsManagement() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	// Get the member as a server
 	var member serf.Member
 	if srv := s.agent.Server(); srv != nil {
@@ -78,36 +89,16 @@ func (s *HTTPServer) AgentSelfRequest(resp http.ResponseWriter, req *http.Reques
 	}
 
 	self := agentSelf{
-		Member: nomadMember(member),
-		Stats:  s.agent.Stats(),
-	}
-
-	self.Config = s.agent.GetConfig().Copy()
-
-	if self.Config != nil && self.Config.Vault != nil && self.Config.Vault.Token != "" {
-		self.Config.Vault.Token = "<redacted>"
-	}
-	for _, vaultConfig := range self.Config.Vaults {
-		if vaultConfig.Token != "" {
-			vaultConfig.Token = "<redacted>"
-		}
+		SchemaVersion: agentSelfSchemaVersion,
+		Redacted:      redacted,
+		Member:        nomadMember(member),
+		Stats:         s.agent.Stats(),
 	}
 
-	if self.Config != nil && self.Config.ACL != nil && self.Config.ACL.ReplicationToken != "" {
-		self.Config.ACL.ReplicationToken = "<redacted>"
-	}
-
-	if self.Config != nil && self.Config.Consul != nil && self.Config.Consul.Token != "" {
-		self.Config.Consul.Token = "<redacted>"
-	}
-	for _, consulConfig := range self.Config.Consuls {
-		if consulConfig.Token != "" {
-			consulConfig.Token = "<redacted>"
-		}
-	}
-
-	if self.Config != nil && self.Config.Telemetry != nil && self.Config.Telemetry.CirconusAPIToken != "" {
-		self.Config.Telemetry.CirconusAPIToken = "<redacted>"
+	if redacted {
+		self.Config = s.agent.GetConfig().Redacted()
+	} else {
+		self.Config = s.agent.GetConfig().Copy()
 	}
 
 	return self, nil
@@ -569,7 +560,18 @@ func (s *HTTPServer) KeyringOperationRequest(resp http.ResponseWriter, req *http
 	return kresp, nil
 }
 
+// agentSelfSchemaVersion is the version of the schema of the agent self
+// response. It's incremented when fields are removed or change meaning, so
+// tools reading debug bundles can detect incompatible changes.
+const agentSelfSchemaVersion = 1
+
 type agentSelf struct {
+	// SchemaVersion is the version of the schema of the response.
+	SchemaVersion int `json:"schema_version"`
+
+	// Redacted is true if the secrets of the configuration were redacted.
+	Redacted bool `json:"redacted"`
+
 	Config *Config                      `json:"config"`
 	Member Member                       `json:"member,omitempty"`
 	Stats  map[string]map[string]string `json:"stats"`
@@ -96,6 +96,33 @@ func TestHTTP_AgentSelf(t *testing.T) {
 		require.NoError(err)
 		self = obj.(agentSelf)
 		require.Equal("<redacted>", self.Config.Telemetry.CirconusAPIToken)
+
+		// Assign Consul credentials and a license and require they are
+		// redacted.
+		s.Config.Consul.Auth = "user:password"
+		s.Config.Server.LicenseEnv = "license"
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.AgentSelfRequest(respW, req)
+		require.NoError(err)
+		self = obj.(agentSelf)
+		require.Equal("<redacted>", self.Config.Consul.Auth)
+		require.Equal("<redacted>", self.Config.Server.LicenseEnv)
+		require.Equal(agentSelfSchemaVersion, self.SchemaVersion)
+		require.True(self.Redacted)
+
+		// Request the full configuration and require secrets are returned.
+		fullReq, err := http.NewRequest(http.MethodGet, "/v1/agent/self?full=true", nil)
+		require.NoError(err)
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.AgentSelfRequest(respW, fullReq)
+		require.NoError(err)
+		self = obj.(agentSelf)
+		require.False(self.Redacted)
+		require.Equal("badc0deb-adc0-deba-dc0d-ebadc0debadc", self.Config.Vault.Token)
+		require.Equal("user:password", self.Config.Consul.Auth)
+
+		// The agent configuration is left untouched.
+		require.Equal("badc0deb-adc0-deba-dc0d-ebadc0debadc", s.Config.Consul.Token)
 	})
 }
 
@@ -152,6 +179,26 @@ func TestHTTP_AgentSelf_ACL(t *testing.T) {
 			require.NotNil(self.Config)
 			require.NotNil(self.Stats)
 		}
+
+		// Try requesting the full configuration, which requires a management
+		// token
+		fullReq, err := http.NewRequest(http.MethodGet, "/v1/agent/self?full=true", nil)
+		require.Nil(err)
+		{
+			respW := httptest.NewRecorder()
+			token := mock.CreatePolicyAndToken(t, state, 1009, "agent-write", mock.AgentPolicy(acl.PolicyWrite))
+			setToken(fullReq, token)
+			_, err := s.Server.AgentSelfRequest(respW, fullReq)
+			require.NotNil(err)
+			require.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+		}
+		{
+			respW := httptest.NewRecorder()
+			setToken(fullReq, s.RootToken)
+			obj, err := s.Server.AgentSelfRequest(respW, fullReq)
+			require.Nil(err)
+			require.False(obj.(agentSelf).Redacted)
+		}
 	})
 }
 
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/ci"
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/helper/pointer"
@@ -652,6 +653,98 @@ func TestAgent_ServerConfig_RaftSnapshotThreshold(t *testing.T) {
 	}
 }
 
+func TestAgent_ServerConfig_JobTrackedScalingEvents(t *testing.T) {
+	ci.Parallel(t)
+
+	conf := DevConfig(nil)
+	must.NoError(t, conf.normalizeAddrs())
+
+	nc, err := convertServerConfig(conf)
+	must.NoError(t, err)
+	must.Eq(t, structs.JobTrackedScalingEvents, nc.JobTrackedScalingEvents)
+
+	conf.Server.JobTrackedScalingEvents = pointer.Of(50)
+	nc, err = convertServerConfig(conf)
+	must.NoError(t, err)
+	must.Eq(t, 50, nc.JobTrackedScalingEvents)
+
+	conf.Server.JobTrackedScalingEvents = pointer.Of(0)
+	_, err = convertServerConfig(conf)
+	must.ErrorContains(t, err, "job_tracked_scaling_events must be greater than 0")
+}
+
+func TestAgent_ServerConfig_ACLWorkloadPolicy(t *testing.T) {
+	ci.Parallel(t)
+
+	conf := DevConfig(nil)
+	must.NoError(t, conf.normalizeAddrs())
+
+	nc, err := convertServerConfig(conf)
+	must.NoError(t, err)
+	must.Eq(t, acl.DefaultWorkloadPolicy(), nc.ACLWorkloadPolicy)
+
+	conf.ACL.WorkloadPolicy = &acl.WorkloadPolicy{Services: acl.WorkloadServicesNamespace}
+	nc, err = convertServerConfig(conf)
+	must.NoError(t, err)
+	must.Eq(t, &acl.WorkloadPolicy{
+		Alloc:     acl.PolicyRead,
+		Services:  acl.WorkloadServicesNamespace,
+		Variables: acl.PolicyWrite,
+	}, nc.ACLWorkloadPolicy)
+
+	conf.ACL.WorkloadPolicy = &acl.WorkloadPolicy{Alloc: acl.PolicyWrite}
+	_, err = convertServerConfig(conf)
+	must.ErrorContains(t, err, `invalid alloc value "write"`)
+}
+
+func TestAgent_ServerConfig_RaftSnapshotChunkSize(t *testing.T) {
+	ci.Parallel(t)
+
+	cases := []struct {
+		name   string
+		value  *string
+		expect int
+		err    string
+	}{
+		{
+			name:   "empty",
+			value:  nil,
+			expect: 0,
+		},
+		{
+			name:   "good",
+			value:  pointer.Of("4MiB"),
+			expect: 4 * 1024 * 1024,
+		},
+		{
+			name:  "invalid",
+			value: pointer.Of("four"),
+			err:   "failed to parse raft_snapshot_chunk_size",
+		},
+		{
+			name:  "too large",
+			value: pointer.Of("1GiB"),
+			err:   "raft_snapshot_chunk_size must be at most 256 MiB",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := DevConfig(nil)
+			must.NoError(t, conf.normalizeAddrs())
+
+			conf.Server.RaftSnapshotChunkSize = tc.value
+			nc, err := convertServerConfig(conf)
+			if tc.err != "" {
+				must.ErrorContains(t, err, tc.err)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expect, nc.RaftSnapshotChunkSize)
+		})
+	}
+}
+
 func TestAgent_ServerConfig_RaftProtocol_3(t *testing.T) {
 	ci.Parallel(t)
 
@@ -729,6 +822,30 @@ func TestAgent_ClientConfig_discovery(t *testing.T) {
 	require.False(t, c.NomadServiceDiscovery)
 }
 
+func TestAgent_ClientConfig_AllocDirs(t *testing.T) {
+	ci.Parallel(t)
+
+	conf := DevConfig(nil)
+	must.NoError(t, conf.normalizeAddrs())
+	a := &Agent{config: conf}
+
+	// The placement defaults to most_free
+	c, err := a.clientConfig()
+	must.NoError(t, err)
+	must.Eq(t, "most_free", c.AllocDirPlacement)
+
+	conf.Client.AllocDirs = []string{"/mnt/disk1/alloc", "/mnt/disk2/alloc"}
+	conf.Client.AllocDirPlacement = "job_type"
+	c, err = a.clientConfig()
+	must.NoError(t, err)
+	must.Eq(t, []string{"/mnt/disk1/alloc", "/mnt/disk2/alloc"}, c.AllocDirs)
+	must.Eq(t, "job_type", c.AllocDirPlacement)
+
+	conf.Client.AllocDirPlacement = "random"
+	_, err = a.clientConfig()
+	must.ErrorContains(t, err, `invalid alloc_dir_placement "random"`)
+}
+
 func TestAgent_ClientConfig_JobMaxSourceSize(t *testing.T) {
 	ci.Parallel(t)
 
@@ -744,6 +861,25 @@ func TestAgent_ClientConfig_JobMaxSourceSize(t *testing.T) {
 	must.Eq(t, 1e6, serverConf.JobMaxSourceSize)
 }
 
+func TestAgent_ClientConfig_HookTimeouts(t *testing.T) {
+	ci.Parallel(t)
+
+	conf := DevConfig(nil)
+	must.NoError(t, conf.normalizeAddrs())
+	conf.Client.HookTimeout = 30 * time.Minute
+	conf.Client.HookTimeouts = map[string]string{"artifacts": "1h"}
+
+	agent := &Agent{config: conf}
+	clientConf, err := agent.clientConfig()
+	must.NoError(t, err)
+	must.Eq(t, 30*time.Minute, clientConf.HookTimeoutFor("identity"))
+	must.Eq(t, time.Hour, clientConf.HookTimeoutFor("artifacts"))
+
+	conf.Client.HookTimeouts = map[string]string{"artifacts": "soon"}
+	_, err = agent.clientConfig()
+	must.ErrorContains(t, err, `Error parsing timeout of hook "artifacts"`)
+}
+
 // Clients should inherit telemetry configuration
 func TestAgent_Client_TelemetryConfiguration(t *testing.T) {
 	ci.Parallel(t)
@@ -16,6 +16,7 @@ import (
 	"github.com/golang/snappy"
 	"github.com/gorilla/websocket"
 	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/hashicorp/nomad/client/allocdir"
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/plugins/drivers"
@@ -93,6 +94,8 @@ func (s *HTTPServer) AllocSpecificRequest(resp http.ResponseWriter, req *http.Re
 		return s.allocStop(allocID, resp, req)
 	case "services":
 		return s.allocServiceRegistrations(resp, req, allocID)
+	case "timeline":
+		return s.allocTimeline(allocID, resp, req)
 	}
 
 	return nil, CodedError(404, resourceNotFoundErr)
@@ -140,6 +143,32 @@ func (s *HTTPServer) allocGet(allocID string, resp http.ResponseWriter, req *htt
 	return alloc, nil
 }
 
+// allocTimeline returns the timeline of the placement of the allocation.
+func (s *HTTPServer) allocTimeline(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != http.MethodGet {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.AllocSpecificRequest{
+		AllocID: allocID,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.SingleAllocResponse
+	if err := s.agent.RPC("Alloc.GetAlloc", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	if out.Alloc == nil {
+		return nil, CodedError(404, "alloc not found")
+	}
+
+	return structs.NewAllocTimeline(out.Alloc), nil
+}
+
 func (s *HTTPServer) allocStop(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if !(req.Method == "POST" || req.Method == "PUT") {
 		return nil, CodedError(405, ErrInvalidMethod)
@@ -222,6 +251,17 @@ func (s *HTTPServer) ClientAllocRequest(resp http.ResponseWriter, req *http.Requ
 		return s.allocChecks(allocID, resp, req)
 	case "stats":
 		return s.allocStats(allocID, resp, req)
+	case "hooks":
+		switch req.Method {
+		case http.MethodGet:
+			return s.allocHooks(allocID, resp, req)
+		case http.MethodPut, http.MethodPost:
+			return s.allocHookOverride(allocID, resp, req)
+		default:
+			return nil, CodedError(405, ErrInvalidMethod)
+		}
+	case "identities":
+		return s.allocIdentities(allocID, resp, req)
 	case "exec":
 		return s.allocExec(allocID, resp, req)
 	case "snapshot":
@@ -403,6 +443,12 @@ func (s *HTTPServer) allocSnapshot(allocID string, resp http.ResponseWriter, req
 	if err != nil {
 		return nil, fmt.Errorf(allocNotFoundErr)
 	}
+
+	// The size only allows the migrating client to report its progress, so
+	// don't fail the snapshot if it can't be computed.
+	if size, err := allocFS.SnapshotSize(); err == nil {
+		resp.Header().Set(allocdir.SnapshotSizeHeader, strconv.FormatInt(size, 10))
+	}
 	if err := allocFS.Snapshot(resp); err != nil {
 		return nil, fmt.Errorf("error making snapshot: %v", err)
 	}
@@ -477,6 +523,122 @@ func (s *HTTPServer) allocChecks(allocID string, resp http.ResponseWriter, req *
 	return reply.Results, rpcErr
 }
 
+func (s *HTTPServer) allocHooks(allocID string, resp http.ResponseWriter, req *http.Request) (any, error) {
+	// Build the request and parse the ACL token
+	args := cstructs.AllocHooksRequest{
+		AllocID: allocID,
+	}
+	s.parse(resp, req, &args.QueryOptions.Region, &args.QueryOptions)
+
+	// Determine the handler to use
+	useLocalClient, useClientRPC, useServerRPC := s.rpcHandlerForAlloc(allocID)
+
+	// Make the RPC
+	var reply cstructs.AllocHooksResponse
+	var rpcErr error
+	switch {
+	case useLocalClient:
+		rpcErr = s.agent.Client().ClientRPC("Allocations.Hooks", &args, &reply)
+	case useClientRPC:
+		rpcErr = s.agent.Client().RPC("ClientAllocations.Hooks", &args, &reply)
+	case useServerRPC:
+		rpcErr = s.agent.Server().RPC("ClientAllocations.Hooks", &args, &reply)
+	default:
+		rpcErr = CodedError(400, "No local Node and node_id not provided")
+	}
+
+	if rpcErr != nil {
+		if structs.IsErrNoNodeConn(rpcErr) || structs.IsErrUnknownAllocation(rpcErr) {
+			rpcErr = CodedError(404, rpcErr.Error())
+		}
+		return nil, rpcErr
+	}
+
+	if reply.Hooks == nil {
+		reply.Hooks = make([]*cstructs.RunningHook, 0)
+	}
+	return reply.Hooks, nil
+}
+
+func (s *HTTPServer) allocIdentities(allocID string, resp http.ResponseWriter, req *http.Request) (any, error) {
+	// Build the request and parse the ACL token
+	query := req.URL.Query()
+	args := cstructs.AllocIdentitiesRequest{
+		AllocID: allocID,
+		Task:    query.Get("task"),
+		Name:    query.Get("name"),
+	}
+	s.parse(resp, req, &args.QueryOptions.Region, &args.QueryOptions)
+
+	// Determine the handler to use
+	useLocalClient, useClientRPC, useServerRPC := s.rpcHandlerForAlloc(allocID)
+
+	// Make the RPC
+	var reply cstructs.AllocIdentitiesResponse
+	var rpcErr error
+	switch {
+	case useLocalClient:
+		rpcErr = s.agent.Client().ClientRPC("Allocations.Identities", &args, &reply)
+	case useClientRPC:
+		rpcErr = s.agent.Client().RPC("ClientAllocations.Identities", &args, &reply)
+	case useServerRPC:
+		rpcErr = s.agent.Server().RPC("ClientAllocations.Identities", &args, &reply)
+	default:
+		rpcErr = CodedError(400, "No local Node and node_id not provided")
+	}
+
+	if rpcErr != nil {
+		if structs.IsErrNoNodeConn(rpcErr) || structs.IsErrUnknownAllocation(rpcErr) {
+			rpcErr = CodedError(404, rpcErr.Error())
+		}
+		return nil, rpcErr
+	}
+
+	if reply.Identities == nil {
+		reply.Identities = make([]*cstructs.AllocIdentity, 0)
+	}
+	return reply.Identities, nil
+}
+
+func (s *HTTPServer) allocHookOverride(allocID string, resp http.ResponseWriter, req *http.Request) (any, error) {
+	// Build the request and parse the ACL token
+	args := cstructs.AllocHookOverrideRequest{}
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, fmt.Sprintf("Failed to decode body: %v", err))
+	}
+	s.parse(resp, req, &args.QueryOptions.Region, &args.QueryOptions)
+	args.AllocID = allocID
+
+	if args.Hook == "" {
+		return nil, CodedError(400, "missing hook name")
+	}
+
+	// Determine the handler to use
+	useLocalClient, useClientRPC, useServerRPC := s.rpcHandlerForAlloc(allocID)
+
+	// Make the RPC
+	var reply structs.GenericResponse
+	var rpcErr error
+	switch {
+	case useLocalClient:
+		rpcErr = s.agent.Client().ClientRPC("Allocations.OverrideHook", &args, &reply)
+	case useClientRPC:
+		rpcErr = s.agent.Client().RPC("ClientAllocations.OverrideHook", &args, &reply)
+	case useServerRPC:
+		rpcErr = s.agent.Server().RPC("ClientAllocations.OverrideHook", &args, &reply)
+	default:
+		rpcErr = CodedError(400, "No local Node and node_id not provided")
+	}
+
+	if rpcErr != nil {
+		if structs.IsErrNoNodeConn(rpcErr) || structs.IsErrUnknownAllocation(rpcErr) {
+			rpcErr = CodedError(404, rpcErr.Error())
+		}
+	}
+
+	return reply, rpcErr
+}
+
 func (s *HTTPServer) allocExec(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	// Build the request and parse the ACL token
 	task := req.URL.Query().Get("task")
@@ -5,6 +5,7 @@ package agent
 
 import (
 	"archive/tar"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/snappy"
 	"github.com/hashicorp/nomad/acl"
@@ -24,6 +26,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -200,6 +203,49 @@ func TestHTTP_AllocQuery(t *testing.T) {
 	})
 }
 
+func TestHTTP_AllocTimeline(t *testing.T) {
+	ci.Parallel(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		state := s.Agent.server.State()
+		alloc := mock.Alloc()
+		alloc.CreateTime = time.Now().Add(-time.Minute).UnixNano()
+		alloc.TaskStates = map[string]*structs.TaskState{
+			"web": {
+				State:     structs.TaskStateRunning,
+				StartedAt: time.Now(),
+				HookTimings: []*structs.TaskHookTiming{{
+					Name:       "validate",
+					Phase:      structs.TaskHookPhasePrestart,
+					StartedAt:  time.Now().Add(-time.Second),
+					FinishedAt: time.Now(),
+				}},
+			},
+		}
+		must.NoError(t, state.UpsertJobSummary(999, mock.JobSummary(alloc.JobID)))
+		must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1000, []*structs.Allocation{alloc}))
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/allocation/"+alloc.ID+"/timeline", nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.AllocSpecificRequest(respW, req)
+		must.NoError(t, err)
+		must.NotEq(t, "", respW.Header().Get("X-Nomad-Index"))
+
+		timeline := obj.(*structs.AllocTimeline)
+		must.Eq(t, alloc.ID, timeline.AllocID)
+		must.Positive(t, timeline.PendingDuration)
+		must.MapContainsKey(t, timeline.Tasks, "web")
+		must.Len(t, 1, timeline.Tasks["web"].Hooks)
+
+		// Unknown allocations return a 404.
+		req, err = http.NewRequest(http.MethodGet, "/v1/allocation/"+uuid.Generate()+"/timeline", nil)
+		must.NoError(t, err)
+		_, err = s.Server.AllocSpecificRequest(httptest.NewRecorder(), req)
+		must.ErrorContains(t, err, "alloc not found")
+	})
+}
+
 func TestHTTP_AllocQuery_Payload(t *testing.T) {
 	ci.Parallel(t)
 	httpTest(t, nil, func(s *TestAgent) {
@@ -668,6 +714,34 @@ func TestHTTP_AllocStats_ACL(t *testing.T) {
 	})
 }
 
+func TestHTTP_AllocIdentities(t *testing.T) {
+	ci.Parallel(t)
+
+	httpTest(t, nil, func(s *TestAgent) {
+		// Local node, local resp
+		path := fmt.Sprintf("/v1/client/allocation/%s/identities?task=web", uuid.Generate())
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		must.NoError(t, err)
+
+		respW := httptest.NewRecorder()
+		_, err = s.Server.ClientAllocRequest(respW, req)
+		must.True(t, structs.IsErrUnknownAllocation(err))
+
+		var codedErr HTTPCodedError
+		must.True(t, errors.As(err, &codedErr))
+		must.Eq(t, http.StatusNotFound, codedErr.Code())
+
+		// Local node, server resp
+		srv := s.server
+		s.server = nil
+		defer func() { s.server = srv }()
+
+		respW = httptest.NewRecorder()
+		_, err = s.Server.ClientAllocRequest(respW, req)
+		must.True(t, structs.IsErrUnknownAllocation(err))
+	})
+}
+
 func TestHTTP_AllocSnapshot(t *testing.T) {
 	ci.Parallel(t)
 	httpTest(t, nil, func(s *TestAgent) {
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net/url"
 	"os"
 	"os/signal"
@@ -32,6 +33,7 @@ import (
 	flaghelper "github.com/hashicorp/nomad/helper/flags"
 	gatedwriter "github.com/hashicorp/nomad/helper/gated-writer"
 	"github.com/hashicorp/nomad/helper/logging"
+	"github.com/hashicorp/nomad/helper/otlpmetrics"
 	"github.com/hashicorp/nomad/helper/winsvc"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/nomad/structs/config"
@@ -449,6 +451,13 @@ func (c *Command) IsValidConfig(config, cmdConfig *Config) bool {
 				hn.Name, hn.ReservedPorts, err))
 			return false
 		}
+		for i, fallback := range hn.Fallbacks {
+			if _, err := structs.ParsePortRanges(fallback.ReservedPorts); err != nil {
+				c.Ui.Error(fmt.Sprintf("host_network[%q].fallback[%d].reserved_ports %q invalid: %v",
+					hn.Name, i, fallback.ReservedPorts, err))
+				return false
+			}
+		}
 	}
 
 	if err := config.Client.Artifact.Validate(); err != nil {
@@ -800,7 +809,7 @@ func (c *Command) Run(args []string) int {
 	}
 
 	// Initialize the telemetry
-	inmem, err := c.setupTelemetry(config)
+	inmem, err := c.setupTelemetry(config, logger)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error initializing telemetry: %s", err))
 		return 1
@@ -1124,7 +1133,7 @@ func (c *Command) handleReload() {
 }
 
 // setupTelemetry is used ot setup the telemetry sub-systems
-func (c *Command) setupTelemetry(config *Config) (*metrics.InmemSink, error) {
+func (c *Command) setupTelemetry(config *Config, logger hclog.Logger) (*metrics.InmemSink, error) {
 	/* Setup telemetry
 	Aggregate on 10 second intervals for 1 minute. Expose the
 	metrics over stderr when there is a SIGUSR1 received.
@@ -1237,6 +1246,29 @@ func (c *Command) setupTelemetry(config *Config) (*metrics.InmemSink, error) {
 		fanout = append(fanout, sink)
 	}
 
+	// Configure the OTLP sink
+	if telConfig.OTLPEndpoint != "" {
+		attrs := map[string]string{
+			"service.name":     "nomad",
+			"nomad.region":     config.Region,
+			"nomad.datacenter": config.Datacenter,
+			"nomad.node.name":  config.NodeName,
+		}
+		maps.Copy(attrs, telConfig.OTLPResourceAttributes)
+
+		sink, err := otlpmetrics.NewSink(&otlpmetrics.Config{
+			Endpoint:           telConfig.OTLPEndpoint,
+			Headers:            telConfig.OTLPHeaders,
+			Interval:           telConfig.otlpInterval,
+			ResourceAttributes: attrs,
+			Logger:             logger,
+		})
+		if err != nil {
+			return inm, err
+		}
+		fanout = append(fanout, sink)
+	}
+
 	// Initialize the global sink
 	if len(fanout) > 0 {
 		fanout = append(fanout, inm)
@@ -418,6 +418,23 @@ func TestIsValidConfig(t *testing.T) {
 			},
 			err: `host_network["test"].reserved_ports "3-2147483647" invalid: port must be < 65536 but found 2147483647`,
 		},
+		{
+			name: "BadHostNetworkFallbackReservedPorts",
+			conf: Config{
+				Client: &ClientConfig{
+					Enabled: true,
+					HostNetworks: []*structs.ClientHostNetworkConfig{
+						{
+							Name: "test",
+							Fallbacks: []*structs.ClientHostNetworkFallback{
+								{CIDR: "10.0.0.0/8", ReservedPorts: "3-2147483647"},
+							},
+						},
+					},
+				},
+			},
+			err: `host_network["test"].fallback[0].reserved_ports "3-2147483647" invalid: port must be < 65536 but found 2147483647`,
+		},
 		{
 			name: "BadArtifact",
 			conf: Config{
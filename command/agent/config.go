@@ -23,6 +23,7 @@ import (
 	"github.com/hashicorp/go-secure-stdlib/listenerutil"
 	sockaddr "github.com/hashicorp/go-sockaddr"
 	"github.com/hashicorp/go-sockaddr/template"
+	"github.com/hashicorp/nomad/acl"
 	client "github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/fingerprint"
 	"github.com/hashicorp/nomad/helper"
@@ -219,9 +220,22 @@ type ClientConfig struct {
 	// StateDir is the state directory
 	StateDir string `hcl:"state_dir"`
 
+	// StateStore is the state store used to persist the client state, either
+	// "boltdb" or "sqlite".
+	StateStore string `hcl:"state_store"`
+
 	// AllocDir is the directory for storing allocation data
 	AllocDir string `hcl:"alloc_dir"`
 
+	// AllocDirs are additional directories for storing allocation data, such
+	// as on other disks, selected for each allocation by AllocDirPlacement
+	AllocDirs []string `hcl:"alloc_dirs"`
+
+	// AllocDirPlacement is the policy selecting the directory of the data of
+	// new allocations among AllocDir and AllocDirs, either "most_free",
+	// "round_robin" or "job_type"
+	AllocDirPlacement string `hcl:"alloc_dir_placement"`
+
 	// Servers is a list of known server addresses. These are as "host:port"
 	Servers []string `hcl:"servers"`
 
@@ -236,6 +250,10 @@ type ClientConfig struct {
 	// pool is created and replicated.
 	NodePool string `hcl:"node_pool"`
 
+	// MaxAllocs is the maximum number of allocations the scheduler places on
+	// the node. Zero means unlimited.
+	MaxAllocs int `hcl:"max_allocs"`
+
 	// Options is used for configuration of nomad internals,
 	// like fingerprinters and drivers. The format is:
 	//
@@ -320,9 +338,56 @@ type ClientConfig struct {
 	// random UUID.
 	NoHostUUID *bool `hcl:"no_host_uuid"`
 
+	// IdentityGracePeriod is how long after the workload identities of a task
+	// expire the client keeps serving them while it fails to renew them, such
+	// as when it's disconnected from the servers.
+	IdentityGracePeriod    time.Duration
+	IdentityGracePeriodHCL string `hcl:"identity_grace_period" json:"-"`
+
+	// HookTimeout is how long the alloc runner and task runner hooks may run
+	// before they fail, along with their allocation.
+	HookTimeout    time.Duration
+	HookTimeoutHCL string `hcl:"hook_timeout" json:"-"`
+
+	// HookTimeouts overrides HookTimeout for the hooks with the given names.
+	HookTimeouts map[string]string `hcl:"hook_timeouts"`
+
+	// EncryptSecretsDir encrypts the secrets dirs of the tasks with fscrypt
+	// instead of backing them with a tmpfs.
+	EncryptSecretsDir bool `hcl:"encrypt_secrets_dir"`
+
+	// SecretsKeyRotationPeriod is how often the root key wrapping the keys of
+	// the encrypted secrets dirs is rotated.
+	SecretsKeyRotationPeriod    time.Duration
+	SecretsKeyRotationPeriodHCL string `hcl:"secrets_key_rotation_period" json:"-"`
+
+	// ClockSkewThreshold is the clock skew between the client and the servers
+	// above which the client emits a node event.
+	ClockSkewThreshold    time.Duration
+	ClockSkewThresholdHCL string `hcl:"clock_skew_threshold" json:"-"`
+
+	// MaxClockSkew is the clock skew between the client and the servers above
+	// which the client refuses to run new allocations with expiring workload
+	// identities.
+	MaxClockSkew    time.Duration
+	MaxClockSkewHCL string `hcl:"max_clock_skew" json:"-"`
+
+	// Identity tunes the renewal of the workload identities.
+	Identity *ClientIdentityConfig `hcl:"identity"`
+
 	// DisableRemoteExec disables remote exec targeting tasks on this client
 	DisableRemoteExec bool `hcl:"disable_remote_exec"`
 
+	// ReverseTunnel marks the HTTP address of the client as unreachable by
+	// users, so the exec, logs, and filesystem APIs of its allocations are
+	// accessed through the servers, over the connection the client maintains
+	// to them.
+	ReverseTunnel bool `hcl:"reverse_tunnel"`
+
+	// ReportResourceUsage reports a summary of the resources used on the
+	// host in heartbeats, to be stored on the node.
+	ReportResourceUsage bool `hcl:"report_resource_usage"`
+
 	// TemplateConfig includes configuration for template rendering
 	TemplateConfig *client.ClientTemplateConfig `hcl:"template"`
 
@@ -379,6 +444,22 @@ type ClientConfig struct {
 	// Drain specifies whether to drain the client on shutdown; ignored in dev mode.
 	Drain *config.DrainConfig `hcl:"drain_on_shutdown"`
 
+	// DiskQuota configures the enforcement of the allocations' ephemeral
+	// disk size with filesystem project quotas.
+	DiskQuota *config.DiskQuotaConfig `hcl:"disk_quota"`
+
+	// DNSCache configures the caching of the resolution of the hosts of the
+	// upstream services used by template runners and artifact downloads.
+	DNSCache *config.DNSCacheConfig `hcl:"dns_cache"`
+
+	// StaticJobsDir is a directory of job specifications that the client runs
+	// locally on startup, before it connects to the servers.
+	StaticJobsDir string `hcl:"static_jobs_dir"`
+
+	// AllocHookPlugins configures the external gRPC services that take part
+	// in the lifecycle of the allocations run by the client.
+	AllocHookPlugins []*config.AllocHookPluginConfig `hcl:"alloc_hook_plugin"`
+
 	// ExtraKeysHCL is used by hcl to surface unexpected keys
 	ExtraKeysHCL []string `hcl:",unusedKeys" json:"-"`
 }
@@ -392,16 +473,21 @@ func (c *ClientConfig) Copy() *ClientConfig {
 	nc.Servers = slices.Clone(c.Servers)
 	nc.Options = maps.Clone(c.Options)
 	nc.Meta = maps.Clone(c.Meta)
+	nc.HookTimeouts = maps.Clone(c.HookTimeouts)
 	nc.ChrootEnv = maps.Clone(c.ChrootEnv)
 	nc.Reserved = c.Reserved.Copy()
 	nc.NoHostUUID = pointer.Copy(c.NoHostUUID)
 	nc.TemplateConfig = c.TemplateConfig.Copy()
 	nc.ServerJoin = c.ServerJoin.Copy()
+	nc.Identity = c.Identity.Copy()
 	nc.HostVolumes = helper.CopySlice(c.HostVolumes)
 	nc.HostNetworks = helper.CopySlice(c.HostNetworks)
 	nc.NomadServiceDiscovery = pointer.Copy(c.NomadServiceDiscovery)
 	nc.Artifact = c.Artifact.Copy()
 	nc.Drain = c.Drain.Copy()
+	nc.DiskQuota = c.DiskQuota.Copy()
+	nc.DNSCache = c.DNSCache.Copy()
+	nc.AllocHookPlugins = helper.CopySlice(c.AllocHookPlugins)
 	nc.ExtraKeysHCL = slices.Clone(c.ExtraKeysHCL)
 	return &nc
 }
@@ -448,6 +534,11 @@ type ACLConfig struct {
 	TokenMaxExpirationTTL    time.Duration
 	TokenMaxExpirationTTLHCL string `hcl:"token_max_expiration_ttl" json:"-"`
 
+	// WorkloadPolicy is the implicit policy granting workload identities
+	// access to the objects of their own job. Fields left unset keep their
+	// default value.
+	WorkloadPolicy *acl.WorkloadPolicy `hcl:"workload_policy"`
+
 	// ExtraKeysHCL is used by hcl to surface unexpected keys
 	ExtraKeysHCL []string `hcl:",unusedKeys" json:"-"`
 }
@@ -458,6 +549,7 @@ func (a *ACLConfig) Copy() *ACLConfig {
 	}
 
 	na := *a
+	na.WorkloadPolicy = a.WorkloadPolicy.Copy()
 	na.ExtraKeysHCL = slices.Clone(a.ExtraKeysHCL)
 	return &na
 }
@@ -632,6 +724,45 @@ type ServerConfig struct {
 	// This value is ignored.
 	DefaultSchedulerConfig *structs.SchedulerConfiguration `hcl:"default_scheduler_config"`
 
+	// ScoringPlugins configures the external gRPC services that adjust the
+	// score of nodes during placements.
+	ScoringPlugins []*config.ScoringPluginConfig `hcl:"scoring_plugin"`
+
+	// ServiceSyncs configures the export of the Nomad native services to
+	// external registries.
+	ServiceSyncs []*config.ServiceSyncConfig `hcl:"service_sync"`
+
+	// JobLint configures the lint rules evaluated when jobs are planned or
+	// registered.
+	JobLint *config.JobLintConfig `hcl:"job_lint"`
+
+	// UserDirectoryLookupDrivers is the list of drivers whose task users are
+	// managed by the directory service of the clients. Task groups with tasks
+	// of these drivers running as a user are constrained to the clients with
+	// user.directory_lookup enabled.
+	UserDirectoryLookupDrivers []string `hcl:"user_directory_lookup_drivers"`
+
+	// JobMetricsLabels controls the labels attached to the metrics emitted
+	// per job.
+	JobMetricsLabels *config.JobMetricsLabelsConfig `hcl:"job_metrics_labels"`
+
+	// WorkloadCA configures the CA used to sign the X.509 certificates of
+	// workload identities.
+	WorkloadCA *config.WorkloadCAConfig `hcl:"workload_ca"`
+
+	// SignerPlugin configures the external gRPC service that signs workload
+	// identities in place of the keyring, such as a service backed by a
+	// cloud KMS or an HSM.
+	SignerPlugin *config.SignerPluginConfig `hcl:"signer_plugin"`
+
+	// PayloadStore configures the object store the dispatch payloads and job
+	// sources above a size threshold are kept in instead of the Raft log.
+	PayloadStore *config.PayloadStoreConfig `hcl:"payload_store"`
+
+	// NamespaceUnblockWeights is the share of the unblocked evaluations each
+	// namespace gets when capacity becomes available.
+	NamespaceUnblockWeights map[string]int `hcl:"namespace_unblock_weights"`
+
 	// PlanRejectionTracker configures the node plan rejection tracker that
 	// detects potentially bad nodes.
 	PlanRejectionTracker *PlanRejectionTracker `hcl:"plan_rejection_tracker"`
@@ -645,6 +776,11 @@ type ServerConfig struct {
 	// for the EventBufferSize is 1.
 	EventBufferSize *int `hcl:"event_buffer_size"`
 
+	// EnableChangeFeed configures whether this server's state store will
+	// publish the change feed events, used to maintain an external copy of
+	// the state.
+	EnableChangeFeed *bool `hcl:"enable_change_feed"`
+
 	// LicensePath is the path to search for an enterprise license.
 	LicensePath string `hcl:"license_path"`
 
@@ -688,6 +824,12 @@ type ServerConfig struct {
 	// setting used. This can be tuned during operation using a hot reload.
 	RaftTrailingLogs *int `hcl:"raft_trailing_logs"`
 
+	// RaftSnapshotChunkSize is the size of the checksummed chunks Raft
+	// snapshots are written in, such as "4MB". Snapshots are written without
+	// chunks if unset or zero, as servers running older versions of Nomad
+	// can't restore chunked snapshots.
+	RaftSnapshotChunkSize *string `hcl:"raft_snapshot_chunk_size"`
+
 	// JobDefaultPriority is the default Job priority if not specified.
 	JobDefaultPriority *int `hcl:"job_default_priority"`
 
@@ -702,6 +844,10 @@ type ServerConfig struct {
 	// JobTrackedVersions is the number of historic job versions that are kept.
 	JobTrackedVersions *int `hcl:"job_tracked_versions"`
 
+	// JobTrackedScalingEvents is the number of scaling events that are kept
+	// for a single task group.
+	JobTrackedScalingEvents *int `hcl:"job_tracked_scaling_events"`
+
 	// OIDCIssuer if set enables OIDC Discovery and uses this value as the
 	// issuer. Third parties such as AWS IAM OIDC Provider expect the issuer to
 	// be a publically accessible HTTPS URL signed by a trusted well-known CA.
@@ -721,8 +867,18 @@ func (s *ServerConfig) Copy() *ServerConfig {
 	ns.RetryJoin = slices.Clone(s.RetryJoin)
 	ns.ServerJoin = s.ServerJoin.Copy()
 	ns.DefaultSchedulerConfig = s.DefaultSchedulerConfig.Copy()
+	ns.ScoringPlugins = helper.CopySlice(s.ScoringPlugins)
+	ns.ServiceSyncs = helper.CopySlice(s.ServiceSyncs)
+	ns.JobLint = s.JobLint.Copy()
+	ns.UserDirectoryLookupDrivers = slices.Clone(s.UserDirectoryLookupDrivers)
+	ns.JobMetricsLabels = s.JobMetricsLabels.Copy()
+	ns.WorkloadCA = s.WorkloadCA.Copy()
+	ns.SignerPlugin = s.SignerPlugin.Copy()
+	ns.PayloadStore = s.PayloadStore.Copy()
+	ns.NamespaceUnblockWeights = maps.Clone(s.NamespaceUnblockWeights)
 	ns.PlanRejectionTracker = s.PlanRejectionTracker.Copy()
 	ns.EnableEventBroker = pointer.Copy(s.EnableEventBroker)
+	ns.EnableChangeFeed = pointer.Copy(s.EnableChangeFeed)
 	ns.EventBufferSize = pointer.Copy(s.EventBufferSize)
 	ns.JobMaxSourceSize = pointer.Copy(s.JobMaxSourceSize)
 	ns.licenseAdditionalPublicKeys = slices.Clone(s.licenseAdditionalPublicKeys)
@@ -732,9 +888,11 @@ func (s *ServerConfig) Copy() *ServerConfig {
 	ns.RaftSnapshotInterval = pointer.Copy(s.RaftSnapshotInterval)
 	ns.RaftSnapshotThreshold = pointer.Copy(s.RaftSnapshotThreshold)
 	ns.RaftTrailingLogs = pointer.Copy(s.RaftTrailingLogs)
+	ns.RaftSnapshotChunkSize = pointer.Copy(s.RaftSnapshotChunkSize)
 	ns.JobDefaultPriority = pointer.Copy(s.JobDefaultPriority)
 	ns.JobMaxPriority = pointer.Copy(s.JobMaxPriority)
 	ns.JobTrackedVersions = pointer.Copy(s.JobTrackedVersions)
+	ns.JobTrackedScalingEvents = pointer.Copy(s.JobTrackedScalingEvents)
 	return &ns
 }
 
@@ -927,6 +1085,62 @@ func (s *ServerJoin) Merge(b *ServerJoin) *ServerJoin {
 	return &result
 }
 
+// ClientIdentityConfig tunes the renewal of the workload identities by the
+// client, so the renewals of large clusters can be spread out, such as after
+// the keyring of the servers is rotated.
+type ClientIdentityConfig struct {
+	// RenewMinWait is the minimum time to wait before renewing the workload
+	// identities, which is also the base of the retry backoff.
+	RenewMinWait    time.Duration
+	RenewMinWaitHCL string `hcl:"renew_min_wait" json:"-"`
+
+	// RenewJitter is the max random delay added to the renewals.
+	RenewJitter    time.Duration
+	RenewJitterHCL string `hcl:"renew_jitter" json:"-"`
+
+	// MaxParallelRenewals is the max number of renewals the client runs in
+	// parallel. It is unlimited if zero.
+	MaxParallelRenewals int `hcl:"max_parallel_renewals"`
+}
+
+func (c *ClientIdentityConfig) Copy() *ClientIdentityConfig {
+	if c == nil {
+		return nil
+	}
+	nc := *c
+	return &nc
+}
+
+func (c *ClientIdentityConfig) Merge(b *ClientIdentityConfig) *ClientIdentityConfig {
+	if c == nil {
+		return b.Copy()
+	}
+
+	result := *c
+
+	if b == nil {
+		return &result
+	}
+
+	if b.RenewMinWait != 0 {
+		result.RenewMinWait = b.RenewMinWait
+	}
+	if b.RenewMinWaitHCL != "" {
+		result.RenewMinWaitHCL = b.RenewMinWaitHCL
+	}
+	if b.RenewJitter != 0 {
+		result.RenewJitter = b.RenewJitter
+	}
+	if b.RenewJitterHCL != "" {
+		result.RenewJitterHCL = b.RenewJitterHCL
+	}
+	if b.MaxParallelRenewals != 0 {
+		result.MaxParallelRenewals = b.MaxParallelRenewals
+	}
+
+	return &result
+}
+
 // EncryptBytes returns the encryption key configured.
 func (s *ServerConfig) EncryptBytes() ([]byte, error) {
 	return base64.StdEncoding.DecodeString(s.EncryptKey)
@@ -965,6 +1179,25 @@ type Telemetry struct {
 	// rate is well-controlled but cardinality of requesters is high.
 	DisableRPCRateMetricsLabels bool `hcl:"disable_rpc_rate_metrics_labels"`
 
+	// OTLPEndpoint is the URL of the OTLP/HTTP metrics endpoint of an
+	// OpenTelemetry collector, such as http://localhost:4318/v1/metrics. The
+	// metrics are pushed to the collector if set.
+	OTLPEndpoint string `hcl:"otlp_endpoint"`
+
+	// OTLPHeaders are added to the requests sent to the OpenTelemetry
+	// collector, for example to authenticate them.
+	OTLPHeaders map[string]string `hcl:"otlp_headers"`
+
+	// OTLPInterval is the interval between two pushes of the metrics to the
+	// OpenTelemetry collector.
+	OTLPInterval string        `hcl:"otlp_interval"`
+	otlpInterval time.Duration `hcl:"-"`
+
+	// OTLPResourceAttributes are added to the resource attributes of the
+	// metrics pushed to the OpenTelemetry collector, which include the
+	// region, datacenter, and node name of the agent.
+	OTLPResourceAttributes map[string]string `hcl:"otlp_resource_attributes"`
+
 	// Circonus: see https://github.com/circonus-labs/circonus-gometrics
 	// for more details on the various configuration options.
 	// Valid configuration combinations:
@@ -1048,6 +1281,8 @@ func (t *Telemetry) Copy() *Telemetry {
 	nt := *t
 	nt.DataDogTags = slices.Clone(t.DataDogTags)
 	nt.PrefixFilter = slices.Clone(t.PrefixFilter)
+	nt.OTLPHeaders = maps.Clone(t.OTLPHeaders)
+	nt.OTLPResourceAttributes = maps.Clone(t.OTLPResourceAttributes)
 	nt.FilterDefault = pointer.Copy(t.FilterDefault)
 	nt.ExtraKeysHCL = slices.Clone(t.ExtraKeysHCL)
 	return &nt
@@ -1362,8 +1597,9 @@ func DefaultConfig() *Config {
 				LimitResults:  100,
 				MinTermLength: 2,
 			},
-			JobMaxSourceSize:   pointer.Of("1M"),
-			JobTrackedVersions: pointer.Of(structs.JobDefaultTrackedVersions),
+			JobMaxSourceSize:        pointer.Of("1M"),
+			JobTrackedVersions:      pointer.Of(structs.JobDefaultTrackedVersions),
+			JobTrackedScalingEvents: pointer.Of(structs.JobTrackedScalingEvents),
 		},
 		ACL: &ACLConfig{
 			Enabled:   false,
@@ -1687,6 +1923,48 @@ func (c *Config) Copy() *Config {
 	return &nc
 }
 
+// redactedValue replaces the secrets of a redacted configuration.
+const redactedValue = "<redacted>"
+
+// Redacted returns a copy of the configuration where secrets, such as tokens
+// and license keys, are replaced by redactedValue so it can be shared safely.
+// Fields left empty are not redacted so their absence remains visible.
+func (c *Config) Redacted() *Config {
+	if c == nil {
+		return nil
+	}
+	nc := c.Copy()
+
+	redact := func(s *string) {
+		if *s != "" {
+			*s = redactedValue
+		}
+	}
+
+	if nc.ACL != nil {
+		redact(&nc.ACL.ReplicationToken)
+	}
+	if nc.Server != nil {
+		redact(&nc.Server.EncryptKey)
+		redact(&nc.Server.LicenseEnv)
+	}
+	if nc.Telemetry != nil {
+		redact(&nc.Telemetry.CirconusAPIToken)
+	}
+
+	// The default Consul and Vault configurations are also in Consuls and
+	// Vaults.
+	for _, consulConfig := range nc.Consuls {
+		redact(&consulConfig.Token)
+		redact(&consulConfig.Auth)
+	}
+	for _, vaultConfig := range nc.Vaults {
+		redact(&vaultConfig.Token)
+	}
+
+	return nc
+}
+
 // normalizeAddrs normalizes Addresses and AdvertiseAddrs to always be
 // initialized and have reasonable defaults.
 func (c *Config) normalizeAddrs() error {
@@ -1939,6 +2217,9 @@ func (a *ACLConfig) Merge(b *ACLConfig) *ACLConfig {
 	if b.ReplicationToken != "" {
 		result.ReplicationToken = b.ReplicationToken
 	}
+	if b.WorkloadPolicy != nil {
+		result.WorkloadPolicy = result.WorkloadPolicy.Merge(b.WorkloadPolicy)
+	}
 	return &result
 }
 
@@ -2076,6 +2357,10 @@ func (s *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 		result.EventBufferSize = b.EventBufferSize
 	}
 
+	if b.EnableChangeFeed != nil {
+		result.EnableChangeFeed = b.EnableChangeFeed
+	}
+
 	result.JobMaxSourceSize = pointer.Merge(s.JobMaxSourceSize, b.JobMaxSourceSize)
 
 	if b.PlanRejectionTracker != nil {
@@ -2087,6 +2372,48 @@ func (s *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 		result.DefaultSchedulerConfig = &c
 	}
 
+	if len(b.ScoringPlugins) != 0 {
+		result.ScoringPlugins = config.ScoringPluginConfigSetMerge(result.ScoringPlugins, b.ScoringPlugins)
+	}
+
+	if len(b.ServiceSyncs) != 0 {
+		result.ServiceSyncs = config.ServiceSyncConfigSetMerge(result.ServiceSyncs, b.ServiceSyncs)
+	}
+
+	if b.JobLint != nil {
+		result.JobLint = result.JobLint.Merge(b.JobLint)
+	}
+
+	if len(b.UserDirectoryLookupDrivers) != 0 {
+		result.UserDirectoryLookupDrivers = slices.Clone(b.UserDirectoryLookupDrivers)
+	}
+
+	if b.JobMetricsLabels != nil {
+		result.JobMetricsLabels = result.JobMetricsLabels.Merge(b.JobMetricsLabels)
+	}
+
+	if b.WorkloadCA != nil {
+		result.WorkloadCA = result.WorkloadCA.Merge(b.WorkloadCA)
+	}
+
+	if b.SignerPlugin != nil {
+		result.SignerPlugin = result.SignerPlugin.Merge(b.SignerPlugin)
+	}
+
+	if b.PayloadStore != nil {
+		result.PayloadStore = result.PayloadStore.Merge(b.PayloadStore)
+	}
+
+	if len(b.NamespaceUnblockWeights) != 0 {
+		result.NamespaceUnblockWeights = maps.Clone(result.NamespaceUnblockWeights)
+		if result.NamespaceUnblockWeights == nil {
+			result.NamespaceUnblockWeights = make(map[string]int)
+		}
+		for namespace, weight := range b.NamespaceUnblockWeights {
+			result.NamespaceUnblockWeights[namespace] = weight
+		}
+	}
+
 	if b.DeploymentQueryRateLimit != 0 {
 		result.DeploymentQueryRateLimit = b.DeploymentQueryRateLimit
 	}
@@ -2122,10 +2449,18 @@ func (s *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 		result.RaftTrailingLogs = pointer.Of(*b.RaftTrailingLogs)
 	}
 
+	if b.RaftSnapshotChunkSize != nil {
+		result.RaftSnapshotChunkSize = pointer.Of(*b.RaftSnapshotChunkSize)
+	}
+
 	if b.JobTrackedVersions != nil {
 		result.JobTrackedVersions = b.JobTrackedVersions
 	}
 
+	if b.JobTrackedScalingEvents != nil {
+		result.JobTrackedScalingEvents = b.JobTrackedScalingEvents
+	}
+
 	if b.OIDCIssuer != "" {
 		result.OIDCIssuer = b.OIDCIssuer
 	}
@@ -2156,15 +2491,27 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.StateDir != "" {
 		result.StateDir = b.StateDir
 	}
+	if b.StateStore != "" {
+		result.StateStore = b.StateStore
+	}
 	if b.AllocDir != "" {
 		result.AllocDir = b.AllocDir
 	}
+	if len(b.AllocDirs) != 0 {
+		result.AllocDirs = slices.Clone(b.AllocDirs)
+	}
+	if b.AllocDirPlacement != "" {
+		result.AllocDirPlacement = b.AllocDirPlacement
+	}
 	if b.NodeClass != "" {
 		result.NodeClass = b.NodeClass
 	}
 	if b.NodePool != "" {
 		result.NodePool = b.NodePool
 	}
+	if b.MaxAllocs != 0 {
+		result.MaxAllocs = b.MaxAllocs
+	}
 	if b.NetworkInterface != "" {
 		result.NetworkInterface = b.NetworkInterface
 	}
@@ -2213,6 +2560,39 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.GCIntervalHCL != "" {
 		result.GCIntervalHCL = b.GCIntervalHCL
 	}
+	if b.IdentityGracePeriod != 0 {
+		result.IdentityGracePeriod = b.IdentityGracePeriod
+	}
+	if b.IdentityGracePeriodHCL != "" {
+		result.IdentityGracePeriodHCL = b.IdentityGracePeriodHCL
+	}
+	if b.HookTimeout != 0 {
+		result.HookTimeout = b.HookTimeout
+	}
+	if b.HookTimeoutHCL != "" {
+		result.HookTimeoutHCL = b.HookTimeoutHCL
+	}
+	if b.EncryptSecretsDir {
+		result.EncryptSecretsDir = b.EncryptSecretsDir
+	}
+	if b.SecretsKeyRotationPeriod != 0 {
+		result.SecretsKeyRotationPeriod = b.SecretsKeyRotationPeriod
+	}
+	if b.SecretsKeyRotationPeriodHCL != "" {
+		result.SecretsKeyRotationPeriodHCL = b.SecretsKeyRotationPeriodHCL
+	}
+	if b.ClockSkewThreshold != 0 {
+		result.ClockSkewThreshold = b.ClockSkewThreshold
+	}
+	if b.ClockSkewThresholdHCL != "" {
+		result.ClockSkewThresholdHCL = b.ClockSkewThresholdHCL
+	}
+	if b.MaxClockSkew != 0 {
+		result.MaxClockSkew = b.MaxClockSkew
+	}
+	if b.MaxClockSkewHCL != "" {
+		result.MaxClockSkewHCL = b.MaxClockSkewHCL
+	}
 	if b.GCParallelDestroys != 0 {
 		result.GCParallelDestroys = b.GCParallelDestroys
 	}
@@ -2233,6 +2613,12 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.DisableRemoteExec {
 		result.DisableRemoteExec = b.DisableRemoteExec
 	}
+	if b.ReverseTunnel {
+		result.ReverseTunnel = true
+	}
+	if b.ReportResourceUsage {
+		result.ReportResourceUsage = b.ReportResourceUsage
+	}
 
 	if b.TemplateConfig != nil {
 		result.TemplateConfig = b.TemplateConfig
@@ -2257,6 +2643,14 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 		result.Meta[k] = v
 	}
 
+	// Add the hook timeouts
+	if len(b.HookTimeouts) > 0 && result.HookTimeouts == nil {
+		result.HookTimeouts = make(map[string]string, len(b.HookTimeouts))
+	}
+	for k, v := range b.HookTimeouts {
+		result.HookTimeouts[k] = v
+	}
+
 	// Add the chroot_env map values
 	if result.ChrootEnv == nil {
 		result.ChrootEnv = make(map[string]string)
@@ -2269,6 +2663,10 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 		result.ServerJoin = result.ServerJoin.Merge(b.ServerJoin)
 	}
 
+	if b.Identity != nil {
+		result.Identity = result.Identity.Merge(b.Identity)
+	}
+
 	if len(a.HostVolumes) == 0 && len(b.HostVolumes) != 0 {
 		result.HostVolumes = structs.CopySliceClientHostVolumeConfig(b.HostVolumes)
 	} else if len(b.HostVolumes) != 0 {
@@ -2312,8 +2710,18 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 		result.CgroupParent = b.CgroupParent
 	}
 
+	if b.StaticJobsDir != "" {
+		result.StaticJobsDir = b.StaticJobsDir
+	}
+
+	if len(b.AllocHookPlugins) != 0 {
+		result.AllocHookPlugins = config.AllocHookPluginConfigSetMerge(result.AllocHookPlugins, b.AllocHookPlugins)
+	}
+
 	result.Artifact = a.Artifact.Merge(b.Artifact)
 	result.Drain = a.Drain.Merge(b.Drain)
+	result.DiskQuota = a.DiskQuota.Merge(b.DiskQuota)
+	result.DNSCache = a.DNSCache.Merge(b.DNSCache)
 
 	return &result
 }
@@ -2410,6 +2818,21 @@ func (a *Telemetry) Merge(b *Telemetry) *Telemetry {
 	if b.DisableRPCRateMetricsLabels {
 		result.DisableRPCRateMetricsLabels = b.DisableRPCRateMetricsLabels
 	}
+	if b.OTLPEndpoint != "" {
+		result.OTLPEndpoint = b.OTLPEndpoint
+	}
+	if b.OTLPHeaders != nil {
+		result.OTLPHeaders = maps.Clone(b.OTLPHeaders)
+	}
+	if b.OTLPInterval != "" {
+		result.OTLPInterval = b.OTLPInterval
+	}
+	if b.otlpInterval != 0 {
+		result.otlpInterval = b.otlpInterval
+	}
+	if b.OTLPResourceAttributes != nil {
+		result.OTLPResourceAttributes = maps.Clone(b.OTLPResourceAttributes)
+	}
 
 	return &result
 }
@@ -2486,17 +2909,27 @@ func (r *Resources) Merge(b *Resources) *Resources {
 // directory. Called for each -config to build up the runtime config value. Do not apply any
 // default values, defaults should be added once in DefaultConfig
 func LoadConfig(path string) (*Config, error) {
+	return loadConfig(path, nil)
+}
+
+// loadConfig loads the configuration at the given path. parents are the
+// configuration files including it.
+func loadConfig(path string, parents []string) (*Config, error) {
 	fi, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
 
 	if fi.IsDir() {
-		return LoadConfigDir(path)
+		return loadConfigDir(path, parents)
 	}
 
 	cleaned := filepath.Clean(path)
-	config, err := ParseConfigFile(cleaned)
+	abs, err := filepath.Abs(cleaned)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parseConfigFile(abs, parents)
 	if err != nil {
 		return nil, fmt.Errorf("Error loading %s: %s", cleaned, err)
 	}
@@ -2508,6 +2941,12 @@ func LoadConfig(path string) (*Config, error) {
 // LoadConfigDir loads all the configurations in the given directory
 // in alphabetical order.
 func LoadConfigDir(dir string) (*Config, error) {
+	return loadConfigDir(dir, nil)
+}
+
+// loadConfigDir loads all the configurations in the given directory in
+// alphabetical order. parents are the configuration files including it.
+func loadConfigDir(dir string, parents []string) (*Config, error) {
 	f, err := os.Open(dir)
 	if err != nil {
 		return nil, err
@@ -2564,7 +3003,11 @@ func LoadConfigDir(dir string) (*Config, error) {
 
 	var result *Config
 	for _, f := range files {
-		config, err := ParseConfigFile(f)
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, err
+		}
+		config, err := parseConfigFile(abs, parents)
 		if err != nil {
 			return nil, fmt.Errorf("Error loading %s: %s", f, err)
 		}
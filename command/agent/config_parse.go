@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/hcl"
@@ -23,13 +24,22 @@ import (
 
 // ParseConfigFile returns an agent.Config from parsed from a file.
 func ParseConfigFile(path string) (*Config, error) {
-	// slurp
-	var buf bytes.Buffer
 	path, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
 	}
+	return parseConfigFile(path, nil)
+}
 
+// parseConfigFile parses a configuration file and merges the configurations
+// it includes over it. parents are the files including it, to detect cycles.
+func parseConfigFile(path string, parents []string) (*Config, error) {
+	if slices.Contains(parents, path) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(parents, path), " -> "))
+	}
+
+	// slurp
+	var buf bytes.Buffer
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -43,6 +53,7 @@ func ParseConfigFile(path string) (*Config, error) {
 	c := &Config{
 		Client: &ClientConfig{
 			ServerJoin: &ServerJoin{},
+			Identity:   &ClientIdentityConfig{},
 			TemplateConfig: &client.ClientTemplateConfig{
 				Wait:        &client.WaitConfig{},
 				WaitBounds:  &client.WaitConfig{},
@@ -69,14 +80,19 @@ func ParseConfigFile(path string) (*Config, error) {
 		Reporting: config.DefaultReporting(),
 	}
 
-	err = hcl.Decode(c, buf.String())
+	src, includes, err := configFileSource(path, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	err = hcl.Decode(c, src)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode HCL file %s: %w", path, err)
 	}
 
 	// Re-parse the file to extract the multiple Vault configurations, which we
 	// need to parse by hand because we don't have a label on the block
-	root, err := hcl.Parse(buf.String())
+	root, err := hcl.Parse(src)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HCL file %s: %w", path, err)
 	}
@@ -96,16 +112,29 @@ func ParseConfigFile(path string) (*Config, error) {
 			return nil, fmt.Errorf("error parsing 'consul': %w", err)
 		}
 	}
+	matches = list.Filter("client")
+	if len(matches.Items) > 0 {
+		if err := parseHostNetworkFallbacks(c, matches); err != nil {
+			return nil, fmt.Errorf("error parsing 'client': %w", err)
+		}
+	}
 
 	// convert strings to time.Durations
 	tds := []durationConversionMap{
 		{"gc_interval", &c.Client.GCInterval, &c.Client.GCIntervalHCL, nil},
+		{"client.identity_grace_period", &c.Client.IdentityGracePeriod, &c.Client.IdentityGracePeriodHCL, nil},
+		{"client.hook_timeout", &c.Client.HookTimeout, &c.Client.HookTimeoutHCL, nil},
+		{"client.secrets_key_rotation_period", &c.Client.SecretsKeyRotationPeriod, &c.Client.SecretsKeyRotationPeriodHCL, nil},
+		{"client.clock_skew_threshold", &c.Client.ClockSkewThreshold, &c.Client.ClockSkewThresholdHCL, nil},
+		{"client.max_clock_skew", &c.Client.MaxClockSkew, &c.Client.MaxClockSkewHCL, nil},
 		{"acl.token_ttl", &c.ACL.TokenTTL, &c.ACL.TokenTTLHCL, nil},
 		{"acl.policy_ttl", &c.ACL.PolicyTTL, &c.ACL.PolicyTTLHCL, nil},
 		{"acl.policy_ttl", &c.ACL.RoleTTL, &c.ACL.RoleTTLHCL, nil},
 		{"acl.token_min_expiration_ttl", &c.ACL.TokenMinExpirationTTL, &c.ACL.TokenMinExpirationTTLHCL, nil},
 		{"acl.token_max_expiration_ttl", &c.ACL.TokenMaxExpirationTTL, &c.ACL.TokenMaxExpirationTTLHCL, nil},
 		{"client.server_join.retry_interval", &c.Client.ServerJoin.RetryInterval, &c.Client.ServerJoin.RetryIntervalHCL, nil},
+		{"client.identity.renew_min_wait", &c.Client.Identity.RenewMinWait, &c.Client.Identity.RenewMinWaitHCL, nil},
+		{"client.identity.renew_jitter", &c.Client.Identity.RenewJitter, &c.Client.Identity.RenewJitterHCL, nil},
 		{"server.heartbeat_grace", &c.Server.HeartbeatGrace, &c.Server.HeartbeatGraceHCL, nil},
 		{"server.min_heartbeat_ttl", &c.Server.MinHeartbeatTTL, &c.Server.MinHeartbeatTTLHCL, nil},
 		{"server.failover_heartbeat_ttl", &c.Server.FailoverHeartbeatTTL, &c.Server.FailoverHeartbeatTTLHCL, nil},
@@ -116,6 +145,7 @@ func ParseConfigFile(path string) (*Config, error) {
 		{"autopilot.server_stabilization_time", &c.Autopilot.ServerStabilizationTime, &c.Autopilot.ServerStabilizationTimeHCL, nil},
 		{"autopilot.last_contact_threshold", &c.Autopilot.LastContactThreshold, &c.Autopilot.LastContactThresholdHCL, nil},
 		{"telemetry.collection_interval", &c.Telemetry.collectionInterval, &c.Telemetry.CollectionInterval, nil},
+		{"telemetry.otlp_interval", &c.Telemetry.otlpInterval, &c.Telemetry.OTLPInterval, nil},
 		{"client.template.block_query_wait", nil, &c.Client.TemplateConfig.BlockQueryWaitTimeHCL,
 			func(d *time.Duration) {
 				c.Client.TemplateConfig.BlockQueryWaitTime = d
@@ -220,6 +250,30 @@ func ParseConfigFile(path string) (*Config, error) {
 		}
 	}
 
+	// Add alloc hook plugins for time.Duration parsing
+	for _, plugin := range c.Client.AllocHookPlugins {
+		tds = append(tds, durationConversionMap{
+			fmt.Sprintf("client.alloc_hook_plugin.%s.timeout", plugin.Name), &plugin.Timeout, &plugin.TimeoutHCL, nil})
+	}
+
+	// Add scoring plugins for time.Duration parsing
+	for _, plugin := range c.Server.ScoringPlugins {
+		tds = append(tds, durationConversionMap{
+			fmt.Sprintf("server.scoring_plugin.%s.timeout", plugin.Name), &plugin.Timeout, &plugin.TimeoutHCL, nil})
+	}
+
+	// Add the signer plugin for time.Duration parsing
+	if plugin := c.Server.SignerPlugin; plugin != nil {
+		tds = append(tds, durationConversionMap{
+			"server.signer_plugin.timeout", &plugin.Timeout, &plugin.TimeoutHCL, nil})
+	}
+
+	// Add service syncs for time.Duration parsing
+	for _, sync := range c.Server.ServiceSyncs {
+		tds = append(tds, durationConversionMap{
+			fmt.Sprintf("server.service_sync.%s.interval", sync.Name), &sync.Interval, &sync.IntervalHCL, nil})
+	}
+
 	// Add enterprise audit sinks for time.Duration parsing
 	for i, sink := range c.Audit.Sinks {
 		tds = append(tds, durationConversionMap{
@@ -241,6 +295,16 @@ func ParseConfigFile(path string) (*Config, error) {
 	// Set client template config or its members to nil if not set.
 	finalizeClientTemplateConfig(c)
 
+	// Merge the included configurations in order, so they override the
+	// configuration including them.
+	for _, include := range includes {
+		ic, err := loadConfig(include, append(parents, path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to include %s: %w", include, err)
+		}
+		c = c.Merge(ic)
+	}
+
 	return c, nil
 }
 
@@ -292,7 +356,7 @@ func extraKeys(c *Config) error {
 		helper.RemoveEqualFold(&c.ExtraKeysHCL, "plugin")
 	}
 
-	for _, k := range []string{"options", "meta", "chroot_env", "servers", "server_join"} {
+	for _, k := range []string{"options", "meta", "chroot_env", "hook_timeouts", "servers", "server_join"} {
 		helper.RemoveEqualFold(&c.ExtraKeysHCL, k)
 		helper.RemoveEqualFold(&c.ExtraKeysHCL, "client")
 	}
@@ -312,6 +376,12 @@ func extraKeys(c *Config) error {
 		helper.RemoveEqualFold(&c.Client.ExtraKeysHCL, "host_network")
 	}
 
+	// Remove alloc hook plugin extra keys
+	for _, p := range c.Client.AllocHookPlugins {
+		helper.RemoveEqualFold(&c.Client.ExtraKeysHCL, p.Name)
+		helper.RemoveEqualFold(&c.Client.ExtraKeysHCL, "alloc_hook_plugin")
+	}
+
 	// Remove AuditConfig extra keys
 	for _, f := range c.Audit.Filters {
 		helper.RemoveEqualFold(&c.Audit.ExtraKeysHCL, f.Name)
@@ -328,10 +398,22 @@ func extraKeys(c *Config) error {
 		helper.RemoveEqualFold(&c.ExtraKeysHCL, "server")
 	}
 
-	for _, k := range []string{"preemption_config"} {
+	for _, k := range []string{"preemption_config", "eval_trace_config", "batch_fair_share_config"} {
 		helper.RemoveEqualFold(&c.Server.ExtraKeysHCL, k)
 	}
 
+	// Remove scoring plugin extra keys
+	for _, p := range c.Server.ScoringPlugins {
+		helper.RemoveEqualFold(&c.Server.ExtraKeysHCL, p.Name)
+		helper.RemoveEqualFold(&c.Server.ExtraKeysHCL, "scoring_plugin")
+	}
+
+	// Remove service sync extra keys
+	for _, s := range c.Server.ServiceSyncs {
+		helper.RemoveEqualFold(&c.Server.ExtraKeysHCL, s.Name)
+		helper.RemoveEqualFold(&c.Server.ExtraKeysHCL, "service_sync")
+	}
+
 	for _, k := range []string{"datadog_tags"} {
 		helper.RemoveEqualFold(&c.ExtraKeysHCL, k)
 		helper.RemoveEqualFold(&c.ExtraKeysHCL, "telemetry")
@@ -439,6 +521,58 @@ func parseVaults(c *Config, list *ast.ObjectList) error {
 	return nil
 }
 
+// parseHostNetworkFallbacks decodes the `fallback` blocks of the
+// `host_network` blocks. The hcl.Decode method can't parse these correctly as
+// HCL1 because they're nested in labeled blocks, which would result in the
+// attributes of each block getting split across several fallbacks.
+func parseHostNetworkFallbacks(c *Config, list *ast.ObjectList) error {
+	var hostNetworks []*ast.ObjectItem
+	for _, obj := range list.Items {
+		ot, ok := obj.Val.(*ast.ObjectType)
+		if !ok {
+			return fmt.Errorf("client should be an object")
+		}
+		for _, hn := range ot.List.Filter("host_network").Items {
+			// The label of the blocks parsed from JSON is a key of their value.
+			if len(hn.Keys) == 0 {
+				if ot, ok := hn.Val.(*ast.ObjectType); ok {
+					hostNetworks = append(hostNetworks, ot.List.Items...)
+				}
+				continue
+			}
+			hostNetworks = append(hostNetworks, hn)
+		}
+	}
+
+	for _, obj := range hostNetworks {
+		name, ok := obj.Keys[0].Token.Value().(string)
+		if !ok {
+			return fmt.Errorf("host_network should have a name")
+		}
+
+		ot, ok := obj.Val.(*ast.ObjectType)
+		if !ok {
+			return fmt.Errorf("host_network %q should be an object", name)
+		}
+
+		var fallbacks []*structs.ClientHostNetworkFallback
+		for _, item := range ot.List.Filter("fallback").Items {
+			var fallback structs.ClientHostNetworkFallback
+			if err := hcl.DecodeObject(&fallback, item.Val); err != nil {
+				return err
+			}
+			fallbacks = append(fallbacks, &fallback)
+		}
+
+		for _, hn := range c.Client.HostNetworks {
+			if hn.Name == name {
+				hn.Fallbacks = fallbacks
+			}
+		}
+	}
+	return nil
+}
+
 // parseConsuls decodes the `consul` blocks. The hcl.Decode method can't parse
 // these correctly as HCL1 because they don't have labels, which would result in
 // all the blocks getting merged regardless of name.
@@ -0,0 +1,315 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	hcl1 "github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad/jobspec2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	// configVarEnvPrefix is the prefix of the environment variables that set
+	// the values of the variables of agent configuration files.
+	configVarEnvPrefix = "NOMAD_AGENT_VAR_"
+
+	// configIncludeKey is the top-level attribute listing the files and
+	// directories an agent configuration file includes.
+	configIncludeKey = "include"
+
+	// configVariableKey is the top-level block declaring a variable of an
+	// agent configuration file.
+	configVariableKey = "variable"
+)
+
+var configVariableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "default"},
+		{Name: "description"},
+	},
+}
+
+// envFunc returns the value of an environment variable of the agent, or an
+// empty string if it isn't set.
+var envFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "name", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+		return cty.StringVal(os.Getenv(args[0].AsString())), nil
+	},
+})
+
+// hcl2Config is an agent configuration file evaluated with HCL2.
+type hcl2Config struct {
+	// JSON is the evaluated configuration, which is decoded as the agent
+	// configuration files written in JSON.
+	JSON []byte
+
+	// Includes are the absolute paths of the files and directories the
+	// configuration file includes, in order.
+	Includes []string
+}
+
+// evalHCL2Config evaluates an agent configuration file parsed with HCL2. The
+// variables, expressions and functions of the file are resolved, and the
+// result is converted to JSON so it's decoded like other configuration files.
+func evalHCL2Config(path string, file *hcl.File) (*hcl2Config, hcl.Diagnostics) {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported configuration",
+			Detail:   fmt.Sprintf("%s is not a native HCL configuration file.", path),
+		}}
+	}
+
+	dir := filepath.Dir(path)
+	functions := jobspec2.Functions(dir, true)
+	functions["env"] = envFunc
+
+	ctx := &hcl.EvalContext{Functions: functions}
+	vars, diags := evalConfigVariables(ctx, body)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	ctx.Variables = map[string]cty.Value{"var": cty.ObjectVal(vars)}
+
+	var config hcl2Config
+	if attr, ok := body.Attributes[configIncludeKey]; ok {
+		includes, moreDiags := evalConfigIncludes(ctx, dir, attr)
+		diags = append(diags, moreDiags...)
+		config.Includes = includes
+	}
+
+	out, moreDiags := evalConfigBody(ctx, body, true)
+	diags = append(diags, moreDiags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	buf, err := json.Marshal(out)
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to encode configuration",
+			Detail:   err.Error(),
+			Subject:  body.SrcRange.Ptr(),
+		})
+	}
+	config.JSON = buf
+	return &config, diags
+}
+
+// evalConfigVariables returns the values of the variables declared by the
+// top-level variable blocks of a configuration file. A variable is set by
+// the environment variable made of configVarEnvPrefix and its name, or
+// otherwise by its default value.
+func evalConfigVariables(ctx *hcl.EvalContext, body *hclsyntax.Body) (map[string]cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	vars := make(map[string]cty.Value)
+
+	for _, block := range body.Blocks {
+		if block.Type != configVariableKey {
+			continue
+		}
+		if len(block.Labels) != 1 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable block",
+				Detail:   "A variable block requires a single label, the name of the variable.",
+				Subject:  block.DefRange().Ptr(),
+			})
+			continue
+		}
+
+		name := block.Labels[0]
+		if !hclsyntax.ValidIdentifier(name) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable name",
+				Detail:   fmt.Sprintf("%q is not a valid identifier.", name),
+				Subject:  block.LabelRanges[0].Ptr(),
+			})
+			continue
+		}
+		if _, ok := vars[name]; ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate variable",
+				Detail:   fmt.Sprintf("The variable %q is already declared.", name),
+				Subject:  block.LabelRanges[0].Ptr(),
+			})
+			continue
+		}
+
+		content, moreDiags := block.Body.Content(configVariableSchema)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		if value, ok := os.LookupEnv(configVarEnvPrefix + name); ok {
+			vars[name] = cty.StringVal(value)
+			continue
+		}
+
+		attr, ok := content.Attributes["default"]
+		if !ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unset variable",
+				Detail: fmt.Sprintf("The variable %q has no default value, so it must be set with the %s%s environment variable.",
+					name, configVarEnvPrefix, name),
+				Subject: block.DefRange().Ptr(),
+			})
+			continue
+		}
+
+		// Default values can only refer to functions, not to other variables.
+		value, moreDiags := attr.Expr.Value(&hcl.EvalContext{Functions: ctx.Functions})
+		diags = append(diags, moreDiags...)
+		vars[name] = value
+	}
+
+	return vars, diags
+}
+
+// evalConfigIncludes returns the absolute paths listed by the include
+// attribute of a configuration file. Relative paths are relative to the
+// directory of the configuration file.
+func evalConfigIncludes(ctx *hcl.EvalContext, dir string, attr *hclsyntax.Attribute) ([]string, hcl.Diagnostics) {
+	value, diags := attr.Expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	value, err := convert.Convert(value, cty.List(cty.String))
+	if err != nil || value.IsNull() || !value.IsWhollyKnown() {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid include",
+			Detail:   "The include attribute must be a list of paths.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+
+	var includes []string
+	for _, v := range value.AsValueSlice() {
+		if v.IsNull() {
+			continue
+		}
+		path := v.AsString()
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		includes = append(includes, filepath.Clean(path))
+	}
+	return includes, diags
+}
+
+// evalConfigBody converts a body to the structure of the JSON configuration
+// files: attributes are evaluated, and blocks are lists of objects nested in
+// one object per label.
+func evalConfigBody(ctx *hcl.EvalContext, body *hclsyntax.Body, root bool) (map[string]interface{}, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	out := make(map[string]interface{}, len(body.Attributes)+len(body.Blocks))
+
+	for name, attr := range body.Attributes {
+		if root && name == configIncludeKey {
+			continue
+		}
+
+		value, moreDiags := attr.Expr.Value(ctx)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() || value.IsNull() {
+			continue
+		}
+		if !value.IsWhollyKnown() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unknown value",
+				Detail:   fmt.Sprintf("The value of %q can't be determined.", name),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+
+		buf, err := ctyjson.Marshal(value, value.Type())
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value",
+				Detail:   fmt.Sprintf("The value of %q can't be encoded: %v.", name, err),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+		out[name] = json.RawMessage(buf)
+	}
+
+	for _, block := range body.Blocks {
+		if root && block.Type == configVariableKey {
+			continue
+		}
+		if _, ok := body.Attributes[block.Type]; ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate definition",
+				Detail:   fmt.Sprintf("%q is defined both as an attribute and as a block.", block.Type),
+				Subject:  block.DefRange().Ptr(),
+			})
+			continue
+		}
+
+		nested, moreDiags := evalConfigBody(ctx, block.Body, false)
+		diags = append(diags, moreDiags...)
+
+		var value interface{} = nested
+		for i := len(block.Labels) - 1; i >= 0; i-- {
+			value = map[string]interface{}{block.Labels[i]: []interface{}{value}}
+		}
+
+		blocks, _ := out[block.Type].([]interface{})
+		out[block.Type] = append(blocks, value)
+	}
+
+	return out, diags
+}
+
+// configFileSource returns the source of a configuration file to decode, and
+// the paths it includes. Configuration files other than JSON are evaluated
+// with HCL2, unless they use syntax only supported by HCL1.
+func configFileSource(path string, src []byte) (string, []string, error) {
+	if filepath.Ext(path) == ".json" {
+		return string(src), nil, nil
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		// Configuration files written for HCL1 may use syntax HCL2 doesn't
+		// accept, such as quoted attribute names.
+		if _, err := hcl1.Parse(string(src)); err == nil {
+			return string(src), nil, nil
+		}
+		return "", nil, diags
+	}
+
+	config, diags := evalHCL2Config(path, file)
+	if diags.HasErrors() {
+		return "", nil, diags
+	}
+	return string(config.JSON), config.Includes, nil
+}
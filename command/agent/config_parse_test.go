@@ -5,11 +5,13 @@ package agent
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -44,17 +46,28 @@ var basicConfig = &Config{
 		Serf: "127.0.0.4",
 	},
 	Client: &ClientConfig{
-		Enabled:   true,
-		StateDir:  "/tmp/client-state",
-		AllocDir:  "/tmp/alloc",
-		Servers:   []string{"a.b.c:80", "127.0.0.1:1234"},
-		NodeClass: "linux-medium-64bit",
+		Enabled:           true,
+		StateDir:          "/tmp/client-state",
+		StateStore:        "sqlite",
+		AllocDir:          "/tmp/alloc",
+		AllocDirs:         []string{"/mnt/disk1/alloc", "/mnt/disk2/alloc"},
+		AllocDirPlacement: "round_robin",
+		Servers:           []string{"a.b.c:80", "127.0.0.1:1234"},
+		NodeClass:         "linux-medium-64bit",
+		MaxAllocs:         100,
 		ServerJoin: &ServerJoin{
 			RetryJoin:        []string{"1.1.1.1", "2.2.2.2"},
 			RetryInterval:    time.Duration(15) * time.Second,
 			RetryIntervalHCL: "15s",
 			RetryMaxAttempts: 3,
 		},
+		Identity: &ClientIdentityConfig{
+			RenewMinWait:        30 * time.Second,
+			RenewMinWaitHCL:     "30s",
+			RenewJitter:         2 * time.Minute,
+			RenewJitterHCL:      "2m",
+			MaxParallelRenewals: 16,
+		},
 		Meta: map[string]string{
 			"foo": "bar",
 			"baz": "zip",
@@ -80,20 +93,62 @@ var basicConfig = &Config{
 			DiskMB:        10,
 			ReservedPorts: "1,100,10-12",
 		},
-		GCInterval:            6 * time.Second,
-		GCIntervalHCL:         "6s",
-		GCParallelDestroys:    6,
-		GCDiskUsageThreshold:  82,
-		GCInodeUsageThreshold: 91,
-		GCMaxAllocs:           50,
-		NoHostUUID:            pointer.Of(false),
-		DisableRemoteExec:     true,
+		GCInterval:                  6 * time.Second,
+		GCIntervalHCL:               "6s",
+		GCParallelDestroys:          6,
+		GCDiskUsageThreshold:        82,
+		GCInodeUsageThreshold:       91,
+		GCMaxAllocs:                 50,
+		NoHostUUID:                  pointer.Of(false),
+		IdentityGracePeriod:         15 * time.Minute,
+		IdentityGracePeriodHCL:      "15m",
+		HookTimeout:                 30 * time.Minute,
+		HookTimeoutHCL:              "30m",
+		HookTimeouts:                map[string]string{"artifacts": "1h"},
+		EncryptSecretsDir:           true,
+		SecretsKeyRotationPeriod:    240 * time.Hour,
+		SecretsKeyRotationPeriodHCL: "240h",
+		ClockSkewThreshold:          5 * time.Second,
+		ClockSkewThresholdHCL:       "5s",
+		MaxClockSkew:                30 * time.Second,
+		MaxClockSkewHCL:             "30s",
+		DisableRemoteExec:           true,
+		ReverseTunnel:               true,
+		ReportResourceUsage:         true,
 		HostVolumes: []*structs.ClientHostVolumeConfig{
 			{Name: "tmp", Path: "/tmp"},
 		},
+		HostNetworks: []*structs.ClientHostNetworkConfig{
+			{
+				Name:          "public",
+				CIDR:          "10.0.0.0/8",
+				ReservedPorts: "22",
+				Fallbacks: []*structs.ClientHostNetworkFallback{
+					{Interface: "eth1", ReservedPorts: "22,80"},
+				},
+			},
+		},
 		CNIPath:             "/tmp/cni_path",
 		BridgeNetworkName:   "custom_bridge_name",
 		BridgeNetworkSubnet: "custom_bridge_subnet",
+		DiskQuota: &config.DiskQuotaConfig{
+			Enabled:          pointer.Of(true),
+			WarningThreshold: pointer.Of(80.0),
+			CheckInterval:    pointer.Of("1m"),
+		},
+		DNSCache: &config.DNSCacheConfig{
+			Enabled:  pointer.Of(true),
+			TTL:      pointer.Of("1m"),
+			MaxStale: pointer.Of("2h"),
+		},
+		AllocHookPlugins: []*config.AllocHookPluginConfig{{
+			Name:       "netadvertise",
+			Address:    "unix:///run/netadvertise.sock",
+			Priority:   950,
+			Requires:   []string{"network"},
+			Timeout:    30 * time.Second,
+			TimeoutHCL: "30s",
+		}},
 	},
 	Server: &ServerConfig{
 		Enabled:                   true,
@@ -132,12 +187,57 @@ var basicConfig = &Config{
 		EncryptKey:                "abc",
 		EnableEventBroker:         pointer.Of(false),
 		EventBufferSize:           pointer.Of(200),
+		EnableChangeFeed:          pointer.Of(true),
 		PlanRejectionTracker: &PlanRejectionTracker{
 			Enabled:       pointer.Of(true),
 			NodeThreshold: 100,
 			NodeWindow:    41 * time.Minute,
 			NodeWindowHCL: "41m",
 		},
+		ScoringPlugins: []*config.ScoringPluginConfig{{
+			Name:       "locality",
+			Address:    "127.0.0.1:9190",
+			JobTypes:   []string{"service"},
+			Timeout:    50 * time.Millisecond,
+			TimeoutHCL: "50ms",
+			CAFile:     "/path/to/ca",
+		}},
+		ServiceSyncs: []*config.ServiceSyncConfig{{
+			Name:        "consul-east",
+			Provider:    "consul",
+			Namespaces:  []string{"default"},
+			Tags:        []string{"public"},
+			Interval:    time.Minute,
+			IntervalHCL: "1m",
+			Config:      map[string]string{"address": "127.0.0.1:8500"},
+		}},
+		WorkloadCA: &config.WorkloadCAConfig{
+			CertFile:    "/path/to/workload-ca.pem",
+			KeyFile:     "/path/to/workload-ca-key.pem",
+			TrustDomain: "nomad.example.com",
+		},
+		SignerPlugin: &config.SignerPluginConfig{
+			Address:    "unix:///run/nomad-kms-signer.sock",
+			Timeout:    3 * time.Second,
+			TimeoutHCL: "3s",
+		},
+		PayloadStore: &config.PayloadStoreConfig{
+			Provider:  "s3",
+			Threshold: pointer.Of("64KiB"),
+			Config:    map[string]string{"bucket": "nomad-payloads", "region": "us-east-1"},
+		},
+		JobLint: &config.JobLintConfig{
+			MissingHealthChecks: config.JobLintLevelWarn,
+			LatestImageTag:      config.JobLintLevelDeny,
+		},
+		UserDirectoryLookupDrivers: []string{"exec", "java"},
+		JobMetricsLabels: &config.JobMetricsLabelsConfig{
+			Labels:    []string{"job", "namespace"},
+			AllowJobs: []string{"api-*"},
+			OtherJobs: "other",
+			Rename:    map[string]string{"job": "nomad_job"},
+		},
+		NamespaceUnblockWeights: map[string]int{"prod": 3},
 		ServerJoin: &ServerJoin{
 			RetryJoin:        []string{"1.1.1.1", "2.2.2.2"},
 			RetryInterval:    time.Duration(15) * time.Second,
@@ -169,6 +269,11 @@ var basicConfig = &Config{
 		TokenMaxExpirationTTLHCL: "100h",
 		TokenMaxExpirationTTL:    100 * time.Hour,
 		ReplicationToken:         "foobar",
+		WorkloadPolicy: &acl.WorkloadPolicy{
+			Alloc:     "deny",
+			Services:  "namespace",
+			Variables: "read",
+		},
 	},
 	Audit: &config.AuditConfig{
 		Enabled: pointer.Of(true),
@@ -194,6 +299,10 @@ var basicConfig = &Config{
 				Operations: []string{"*"},
 			},
 		},
+		Access: &config.AuditAccessConfig{
+			Enabled:    pointer.Of(true),
+			SampleRate: pointer.Of(0.5),
+		},
 	},
 	Telemetry: &Telemetry{
 		StatsiteAddr:             "127.0.0.1:1234",
@@ -205,6 +314,11 @@ var basicConfig = &Config{
 		collectionInterval:       3 * time.Second,
 		PublishAllocationMetrics: true,
 		PublishNodeMetrics:       true,
+		OTLPEndpoint:             "http://localhost:4318/v1/metrics",
+		OTLPHeaders:              map[string]string{"Authorization": "Bearer token"},
+		OTLPInterval:             "30s",
+		otlpInterval:             30 * time.Second,
+		OTLPResourceAttributes:   map[string]string{"deployment.environment": "prod"},
 	},
 	LeaveOnInt:                true,
 	LeaveOnTerm:               true,
@@ -643,6 +757,9 @@ func (c *Config) addDefaults() {
 	if c.Client.ServerJoin == nil {
 		c.Client.ServerJoin = &ServerJoin{}
 	}
+	if c.Client.Identity == nil {
+		c.Client.Identity = &ClientIdentityConfig{}
+	}
 	if c.ACL == nil {
 		c.ACL = &ACLConfig{}
 	}
@@ -749,7 +866,7 @@ var sample0 = &Config{
 		RPC:  "host.example.com",
 		Serf: "host.example.com",
 	},
-	Client: &ClientConfig{ServerJoin: &ServerJoin{}},
+	Client: &ClientConfig{ServerJoin: &ServerJoin{}, Identity: &ClientIdentityConfig{}},
 	Server: &ServerConfig{
 		Enabled:         true,
 		BootstrapExpect: 3,
@@ -863,7 +980,7 @@ var sample1 = &Config{
 		RPC:  "host.example.com",
 		Serf: "host.example.com",
 	},
-	Client: &ClientConfig{ServerJoin: &ServerJoin{}},
+	Client: &ClientConfig{ServerJoin: &ServerJoin{}, Identity: &ClientIdentityConfig{}},
 	Server: &ServerConfig{
 		Enabled:         true,
 		BootstrapExpect: 3,
@@ -1173,3 +1290,82 @@ func TestConfig_MultipleConsul(t *testing.T) {
 	// overridden
 	must.Eq(t, "nomad-client", cfg.Consuls["other"].ClientServiceName)
 }
+
+func TestConfig_ParseHCL2(t *testing.T) {
+	t.Setenv("NOMAD_TEST_HCL2_DIR", "/opt/nomad")
+
+	t.Run("defaults", func(t *testing.T) {
+		c, err := LoadConfig("testdata/hcl2/agent.hcl")
+		must.NoError(t, err)
+
+		must.Eq(t, "global", c.Region)
+		must.Eq(t, "DC1", c.Datacenter)
+		must.Eq(t, "/opt/nomad/data", c.DataDir)
+		must.True(t, c.Client.Enabled)
+		must.Eq(t, map[string]string{"rack": "r1", "zone": "global-a"}, c.Client.Meta)
+		must.Len(t, 1, c.Client.HostVolumes)
+		must.Eq(t, "certs", c.Client.HostVolumes[0].Name)
+		must.True(t, c.Client.HostVolumes[0].ReadOnly)
+
+		// included files are merged in order over the including file
+		must.True(t, c.Server.Enabled)
+		must.Eq(t, 3, c.Server.BootstrapExpect)
+		must.Eq(t, "DEBUG", c.LogLevel)
+		must.Len(t, 3, c.Files)
+	})
+
+	t.Run("variables from environment", func(t *testing.T) {
+		t.Setenv("NOMAD_AGENT_VAR_region", "east")
+
+		c, err := LoadConfig("testdata/hcl2/agent.hcl")
+		must.NoError(t, err)
+		must.Eq(t, "east", c.Region)
+		must.Eq(t, "east-a", c.Client.Meta["zone"])
+	})
+}
+
+func TestConfig_ParseHCL2_Errors(t *testing.T) {
+	ci.Parallel(t)
+
+	cases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "syntax error",
+			config: "region = \"global\"\nclient {\n  enabled = \n}\n",
+			err:    "agent.hcl:3,13-4,1: Invalid expression",
+		},
+		{
+			name:   "unknown variable",
+			config: "region = var.region\n",
+			err:    "agent.hcl:1,13-20: Unsupported attribute",
+		},
+		{
+			name:   "unset variable",
+			config: "variable \"region\" {}\nregion = var.region\n",
+			err:    "agent.hcl:1,1-20: Unset variable",
+		},
+		{
+			name:   "invalid include",
+			config: "include = 3\n",
+			err:    "agent.hcl:1,11-12: Invalid include",
+		},
+		{
+			name:   "include cycle",
+			config: "include = [\"agent.hcl\"]\n",
+			err:    "include cycle",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "agent.hcl")
+			must.NoError(t, os.WriteFile(path, []byte(tc.config), 0o644))
+
+			_, err := LoadConfig(path)
+			must.ErrorContains(t, err, tc.err)
+		})
+	}
+}
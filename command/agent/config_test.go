@@ -145,6 +145,7 @@ func TestConfig_Merge(t *testing.T) {
 			RaftSnapshotThreshold:  pointer.Of(100),
 			RaftSnapshotInterval:   pointer.Of("30m"),
 			RaftTrailingLogs:       pointer.Of(200),
+			RaftSnapshotChunkSize:  pointer.Of("1MB"),
 			NumSchedulers:          pointer.Of(1),
 			NodeGCThreshold:        "1h",
 			BatchEvalGCThreshold:   "4h",
@@ -334,6 +335,9 @@ func TestConfig_Merge(t *testing.T) {
 			DisableDispatchedJobSummaryMetrics: true,
 			DisableRPCRateMetricsLabels:        true,
 			FilterDefault:                      pointer.Of(false),
+			OTLPEndpoint:                       "http://localhost:4318/v1/metrics",
+			OTLPHeaders:                        map[string]string{"Authorization": "Bearer token"},
+			OTLPResourceAttributes:             map[string]string{"deployment.environment": "prod"},
 		},
 		Client: &ClientConfig{
 			Enabled:   true,
@@ -386,6 +390,7 @@ func TestConfig_Merge(t *testing.T) {
 			RaftSnapshotThreshold:  pointer.Of(100),
 			RaftSnapshotInterval:   pointer.Of("30m"),
 			RaftTrailingLogs:       pointer.Of(200),
+			RaftSnapshotChunkSize:  pointer.Of("4MB"),
 			NumSchedulers:          pointer.Of(2),
 			EnabledSchedulers:      []string{structs.JobTypeBatch},
 			NodeGCThreshold:        "12h",
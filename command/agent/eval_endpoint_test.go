@@ -339,6 +339,35 @@ func TestHTTP_EvalQuery(t *testing.T) {
 	})
 }
 
+func TestHTTP_EvalTrace(t *testing.T) {
+	ci.Parallel(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		state := s.Agent.server.State()
+		eval := mock.Eval()
+		must.NoError(t, state.UpsertEvals(structs.MsgTypeTestSetup, 1000, []*structs.Evaluation{eval}))
+		must.NoError(t, state.UpsertEvalTrace(structs.MsgTypeTestSetup, 1001, &structs.EvalTrace{
+			EvalID:    eval.ID,
+			Namespace: eval.Namespace,
+			JobID:     eval.JobID,
+		}))
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/evaluation/"+eval.ID+"/trace", nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.EvalSpecificRequest(respW, req)
+		must.NoError(t, err)
+		must.Eq(t, "1001", respW.Result().Header.Get("X-Nomad-Index"))
+		must.Eq(t, eval.ID, obj.(*structs.EvalTrace).EvalID)
+
+		// Missing traces return a 404
+		req, err = http.NewRequest(http.MethodGet, "/v1/evaluation/"+uuid.Generate()+"/trace", nil)
+		must.NoError(t, err)
+		_, err = s.Server.EvalSpecificRequest(httptest.NewRecorder(), req)
+		must.ErrorContains(t, err, "eval trace not found")
+	})
+}
+
 func TestHTTP_EvalQueryWithRelated(t *testing.T) {
 	ci.Parallel(t)
 	httpTest(t, nil, func(s *TestAgent) {
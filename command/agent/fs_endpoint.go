@@ -10,6 +10,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -41,6 +42,8 @@ func (s *HTTPServer) FsRequest(resp http.ResponseWriter, req *http.Request) (int
 		return s.wrapUntrustedContent(s.FileCatRequest)(resp, req)
 	case strings.HasPrefix(path, "stream/"):
 		return s.Stream(resp, req)
+	case strings.HasPrefix(path, "archive/"):
+		return s.Archive(resp, req)
 	case strings.HasPrefix(path, "logs/"):
 		// Logs are *trusted* content because the endpoint
 		// explicitly sets the Content-Type to text/plain or
@@ -257,6 +260,92 @@ func (s *HTTPServer) Stream(resp http.ResponseWriter, req *http.Request) (interf
 	return s.fsStreamImpl(resp, req, "FileSystem.Stream", fsReq, fsReq.AllocID)
 }
 
+// Archive streams a directory as a gzipped tar archive. The parameters are:
+//   - path: path to the directory to archive, defaults to the alloc dir.
+//   - include: comma separated glob patterns of the files to archive.
+//   - exclude: comma separated glob patterns of the files to skip.
+//   - max_bytes: the maximum total size of the archived files.
+func (s *HTTPServer) Archive(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var allocID string
+	var err error
+
+	q := req.URL.Query()
+
+	if allocID = strings.TrimPrefix(req.URL.Path, "/v1/client/fs/archive/"); allocID == "" {
+		return nil, allocIDNotPresentErr
+	}
+
+	path := q.Get("path")
+	if path == "" {
+		path = "/"
+	}
+
+	var maxBytes int64
+	if maxBytesStr := q.Get("max_bytes"); maxBytesStr != "" {
+		if maxBytes, err = strconv.ParseInt(maxBytesStr, 10, 64); err != nil {
+			return nil, CodedError(400, fmt.Sprintf("error parsing max_bytes: %v", err))
+		}
+	}
+
+	// Create the request arguments
+	fsReq := &cstructs.FsArchiveRequest{
+		AllocID:  allocID,
+		Path:     path,
+		Include:  splitPatterns(q.Get("include")),
+		Exclude:  splitPatterns(q.Get("exclude")),
+		MaxBytes: maxBytes,
+	}
+	s.parse(resp, req, &fsReq.QueryOptions.Region, &fsReq.QueryOptions)
+
+	// Only set the archive headers once the archive is being sent, so errors
+	// are returned with their usual content type.
+	name := allocID
+	if len(name) > 8 {
+		name = name[:8]
+	}
+	if base := filepath.Base(filepath.Clean("/" + path)); base != "/" {
+		name += "-" + base
+	}
+	archiveResp := &archiveResponseWriter{ResponseWriter: resp, filename: name + ".tar.gz"}
+
+	// Make the request
+	return s.fsStreamImpl(archiveResp, req, "FileSystem.Archive", fsReq, fsReq.AllocID)
+}
+
+// splitPatterns splits a comma separated list of patterns.
+func splitPatterns(s string) []string {
+	var patterns []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// archiveResponseWriter sets the headers of archive downloads before the first
+// write of the response body.
+type archiveResponseWriter struct {
+	http.ResponseWriter
+	filename string
+	wrote    bool
+}
+
+func (w *archiveResponseWriter) Write(p []byte) (int, error) {
+	if !w.wrote {
+		w.wrote = true
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *archiveResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Logs streams the content of a log blocking on EOF. The parameters are:
 //   - task: task name to stream logs for.
 //   - type: stdout/stderr to stream.
@@ -4,6 +4,8 @@
 package agent
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -19,6 +21,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -509,6 +512,51 @@ func TestHTTP_FS_Stream_Follow(t *testing.T) {
 	})
 }
 
+func TestHTTP_FS_Archive(t *testing.T) {
+	ci.Parallel(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		a := mockFSAlloc(s.client.NodeID(), nil)
+		addAllocToClient(s, a, terminalClientAlloc)
+
+		path := fmt.Sprintf("/v1/client/fs/archive/%s?path=alloc/logs&include=*.stdout.*", a.ID)
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+		_, err = s.Server.Archive(respW, req)
+		must.NoError(t, err)
+		must.Eq(t, "application/gzip", respW.Header().Get("Content-Type"))
+
+		gz, err := gzip.NewReader(respW.Body)
+		must.NoError(t, err)
+		tr := tar.NewReader(gz)
+
+		files := map[string]string{}
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			must.NoError(t, err)
+			contents, err := io.ReadAll(tr)
+			must.NoError(t, err)
+			files[hdr.Name] = string(contents)
+		}
+		must.Eq(t, map[string]string{
+			"logs/":             "",
+			"logs/web.stdout.0": defaultLoggerMockDriverStdout,
+		}, files)
+
+		// The archive size is limited
+		path = fmt.Sprintf("/v1/client/fs/archive/%s?path=alloc/logs&max_bytes=1", a.ID)
+		req, err = http.NewRequest(http.MethodGet, path, nil)
+		must.NoError(t, err)
+		respW = httptest.NewRecorder()
+		_, err = s.Server.Archive(respW, req)
+		must.ErrorContains(t, err, "more than the maximum")
+		must.Eq(t, "", respW.Header().Get("Content-Type"))
+	})
+}
+
 func TestHTTP_FS_Logs(t *testing.T) {
 	ci.Parallel(t)
 	require := require.New(t)
@@ -382,9 +382,11 @@ func (s *HTTPServer) ResolveToken(req *http.Request) (*acl.ACL, error) {
 func (s *HTTPServer) registerHandlers(enableDebug bool) {
 	s.mux.HandleFunc("/v1/jobs", s.wrap(s.JobsRequest))
 	s.mux.HandleFunc("/v1/jobs/parse", s.wrap(s.JobsParseRequest))
+	s.mux.HandleFunc("/v1/jobs/convert", s.wrap(s.JobsConvertRequest))
 	s.mux.HandleFunc("/v1/job/", s.wrap(s.JobSpecificRequest))
 
 	s.mux.HandleFunc("/v1/nodes", s.wrap(s.NodesRequest))
+	s.mux.HandleFunc("/v1/nodes/utilization", s.wrap(s.NodesUtilizationRequest))
 	s.mux.HandleFunc("/v1/node/", s.wrap(s.NodeSpecificRequest))
 
 	s.mux.HandleFunc("/v1/node/pools", s.wrap(s.NodePoolsRequest))
@@ -412,10 +414,13 @@ func (s *HTTPServer) registerHandlers(enableDebug bool) {
 
 	s.mux.HandleFunc("/v1/acl/token/onetime", s.wrap(s.UpsertOneTimeToken))
 	s.mux.HandleFunc("/v1/acl/token/onetime/exchange", s.wrap(s.ExchangeOneTimeToken))
+	s.mux.HandleFunc("/v1/acl/token/exchange", s.wrap(s.ACLTokenExchangeRequest))
+	s.mux.HandleFunc("/v1/acl/token/renew", s.wrap(s.ACLTokenRenewRequest))
 	s.mux.HandleFunc("/v1/acl/bootstrap", s.wrap(s.ACLTokenBootstrap))
 	s.mux.HandleFunc("/v1/acl/tokens", s.wrap(s.ACLTokensRequest))
 	s.mux.HandleFunc("/v1/acl/token", s.wrap(s.ACLTokenSpecificRequest))
 	s.mux.HandleFunc("/v1/acl/token/", s.wrap(s.ACLTokenSpecificRequest))
+	s.mux.HandleFunc("/v1/acl/whoami", s.wrap(s.ACLWhoAmIRequest))
 
 	// Register our ACL role handlers.
 	s.mux.HandleFunc("/v1/acl/roles", s.wrap(s.ACLRoleListRequest))
@@ -431,6 +436,7 @@ func (s *HTTPServer) registerHandlers(enableDebug bool) {
 	s.mux.HandleFunc("/v1/acl/binding-rules", s.wrap(s.ACLBindingRuleListRequest))
 	s.mux.HandleFunc("/v1/acl/binding-rule", s.wrap(s.ACLBindingRuleRequest))
 	s.mux.HandleFunc("/v1/acl/binding-rule/", s.wrap(s.ACLBindingRuleSpecificRequest))
+	s.mux.HandleFunc("/v1/acl/binding-rules/test", s.wrap(s.ACLBindingRulesTestRequest))
 
 	// Register out ACL OIDC SSO and auth handlers.
 	s.mux.HandleFunc("/v1/acl/oidc/auth-url", s.wrap(s.ACLOIDCAuthURLRequest))
@@ -456,6 +462,7 @@ func (s *HTTPServer) registerHandlers(enableDebug bool) {
 
 	// Register our service registration handlers.
 	s.mux.HandleFunc("/v1/services", s.wrap(s.ServiceRegistrationListRequest))
+	s.mux.HandleFunc("/v1/services/prometheus", s.wrap(s.ServiceRegistrationPrometheusRequest))
 	s.mux.HandleFunc("/v1/service/", s.wrap(s.ServiceRegistrationRequest))
 
 	// Monitor is *not* an untrusted endpoint despite the log contents
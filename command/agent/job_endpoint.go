@@ -4,12 +4,14 @@
 package agent
 
 import (
+	"encoding/json"
 	"fmt"
 	"maps"
 	"net/http"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/snappy"
 	"github.com/gorilla/websocket"
@@ -76,6 +78,12 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/evaluations"):
 		jobID := strings.TrimSuffix(path, "/evaluations")
 		return s.jobEvaluations(resp, req, jobID)
+	case strings.HasSuffix(path, "/failures"):
+		jobID := strings.TrimSuffix(path, "/failures")
+		return s.jobFailures(resp, req, jobID)
+	case strings.HasSuffix(path, "/local-volume-claims"):
+		jobID := strings.TrimSuffix(path, "/local-volume-claims")
+		return s.jobLocalVolumeClaims(resp, req, jobID)
 	case strings.HasSuffix(path, "/periodic/force"):
 		jobID := strings.TrimSuffix(path, "/periodic/force")
 		return s.periodicForceRequest(resp, req, jobID)
@@ -106,6 +114,15 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/scale"):
 		jobID := strings.TrimSuffix(path, "/scale")
 		return s.jobScale(resp, req, jobID)
+	case strings.HasSuffix(path, "/schedule"):
+		jobID := strings.TrimSuffix(path, "/schedule")
+		return s.jobOverrideSchedule(resp, req, jobID)
+	case strings.HasSuffix(path, "/suspend"):
+		jobID := strings.TrimSuffix(path, "/suspend")
+		return s.jobSuspend(resp, req, jobID, true)
+	case strings.HasSuffix(path, "/resume"):
+		jobID := strings.TrimSuffix(path, "/resume")
+		return s.jobSuspend(resp, req, jobID, false)
 	case strings.HasSuffix(path, "/services"):
 		jobID := strings.TrimSuffix(path, "/services")
 		return s.jobServiceRegistrations(resp, req, jobID)
@@ -296,6 +313,110 @@ func (s *HTTPServer) jobEvaluations(resp http.ResponseWriter, req *http.Request,
 	return out.Evaluations, nil
 }
 
+const (
+	// defaultJobFailuresSince and defaultJobFailuresWindow are the default
+	// period and window duration of the job failure history.
+	defaultJobFailuresSince  = 24 * time.Hour
+	defaultJobFailuresWindow = time.Hour
+
+	// maxJobFailuresWindows is the maximum number of windows of a job
+	// failure history.
+	maxJobFailuresWindows = 1000
+)
+
+func (s *HTTPServer) jobLocalVolumeClaims(resp http.ResponseWriter, req *http.Request, jobID string) (interface{}, error) {
+	switch req.Method {
+	case http.MethodGet:
+		args := structs.LocalVolumeClaimListRequest{
+			JobID: jobID,
+		}
+		if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+			return nil, nil
+		}
+
+		var out structs.LocalVolumeClaimListResponse
+		if err := s.agent.RPC("Job.LocalVolumeClaims", &args, &out); err != nil {
+			return nil, err
+		}
+
+		setMeta(resp, &out.QueryMeta)
+		if out.Claims == nil {
+			out.Claims = make([]*structs.LocalVolumeClaim, 0)
+		}
+		return out.Claims, nil
+
+	case http.MethodDelete:
+		args := structs.LocalVolumeClaimDeleteRequest{
+			JobID:     jobID,
+			AllocName: req.URL.Query().Get("alloc_name"),
+		}
+		s.parseWriteRequest(req, &args.WriteRequest)
+
+		var out structs.LocalVolumeClaimDeleteResponse
+		if err := s.agent.RPC("Job.DeleteLocalVolumeClaims", &args, &out); err != nil {
+			return nil, err
+		}
+		setIndex(resp, out.Index)
+		return out, nil
+
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+func (s *HTTPServer) jobFailures(resp http.ResponseWriter, req *http.Request, jobID string) (interface{}, error) {
+	if req.Method != http.MethodGet {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	since, window := defaultJobFailuresSince, defaultJobFailuresWindow
+	query := req.URL.Query()
+	if raw := query.Get("since"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return nil, CodedError(400, fmt.Sprintf("invalid since value %q", raw))
+		}
+		since = d
+	}
+	if raw := query.Get("window"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return nil, CodedError(400, fmt.Sprintf("invalid window value %q", raw))
+		}
+		window = d
+	}
+	if since/window > maxJobFailuresWindows {
+		return nil, CodedError(400, fmt.Sprintf("window too small, at most %d windows are allowed", maxJobFailuresWindows))
+	}
+
+	args := structs.JobSpecificRequest{
+		JobID: jobID,
+		All:   true,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var allocs structs.JobAllocationsResponse
+	if err := s.agent.RPC("Job.Allocations", &args, &allocs); err != nil {
+		return nil, err
+	}
+
+	var evals structs.JobEvaluationsResponse
+	if err := s.agent.RPC("Job.Evaluations", &args, &evals); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &allocs.QueryMeta)
+	if evals.Index > allocs.Index {
+		setIndex(resp, evals.Index)
+	}
+
+	end := time.Now().UTC()
+	return structs.NewJobFailureHistory(args.RequestNamespace(), jobID,
+		allocs.Allocations, evals.Evaluations, end.Add(-since), end, window), nil
+}
+
 func (s *HTTPServer) jobDeployments(resp http.ResponseWriter, req *http.Request, jobID string) (interface{}, error) {
 	if req.Method != http.MethodGet {
 		return nil, CodedError(405, ErrInvalidMethod)
@@ -590,6 +711,15 @@ func (s *HTTPServer) jobDelete(resp http.ResponseWriter, req *http.Request, jobI
 	}
 	args.NoShutdownDelay = noShutdownDelay
 
+	// Identify the service_drain query param and parse.
+	if serviceDrainStr := req.URL.Query().Get("service_drain"); serviceDrainStr != "" {
+		serviceDrain, err := time.ParseDuration(serviceDrainStr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse value of %q (%v) as a duration: %v", "service_drain", serviceDrainStr, err)
+		}
+		args.ServiceDrain = serviceDrain
+	}
+
 	// Validate the evaluation priority if the user supplied a non-default
 	// value. It's more efficient to do it here, within the agent rather than
 	// sending a bad request for the server to reject.
@@ -668,6 +798,7 @@ func (s *HTTPServer) jobScaleAction(resp http.ResponseWriter, req *http.Request,
 		Message:        args.Message,
 		Error:          args.Error,
 		Meta:           args.Meta,
+		Annotation:     args.Annotation,
 	}
 	// parseWriteRequest overrides Namespace, Region and AuthToken
 	// based on values from the original http request
@@ -681,6 +812,56 @@ func (s *HTTPServer) jobScaleAction(resp http.ResponseWriter, req *http.Request,
 	return out, nil
 }
 
+func (s *HTTPServer) jobOverrideSchedule(resp http.ResponseWriter, req *http.Request, jobID string) (interface{}, error) {
+
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var args api.JobOverrideScheduleRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+
+	overrideReq := structs.JobOverrideScheduleRequest{
+		JobID: jobID,
+	}
+	if args.Override != nil {
+		overrideReq.Override = &structs.JobActiveScheduleOverride{
+			Active: args.Override.Active,
+			Until:  args.Override.Until,
+		}
+	}
+	s.parseWriteRequest(req, &overrideReq.WriteRequest)
+
+	var out structs.JobRegisterResponse
+	if err := s.agent.RPC("Job.OverrideSchedule", &overrideReq, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
+func (s *HTTPServer) jobSuspend(resp http.ResponseWriter, req *http.Request, jobID string, suspend bool) (interface{}, error) {
+
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.JobSuspendRequest{
+		JobID:   jobID,
+		Suspend: suspend,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var out structs.JobSuspendResponse
+	if err := s.agent.RPC("Job.Suspend", &args, &out); err != nil {
+		return nil, err
+	}
+	setIndex(resp, out.Index)
+	return out, nil
+}
+
 func (s *HTTPServer) jobVersions(resp http.ResponseWriter, req *http.Request, jobID string) (interface{}, error) {
 
 	diffsStr := req.URL.Query().Get("diffs")
@@ -774,6 +955,18 @@ func (s *HTTPServer) jobSummaryRequest(resp http.ResponseWriter, req *http.Reque
 	args := structs.JobSummaryRequest{
 		JobID: jobID,
 	}
+	health, err := parseBool(req, "health")
+	if err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	args.Health = health != nil && *health
+	if raw := req.URL.Query().Get("restart_window"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return nil, CodedError(400, fmt.Sprintf("invalid restart_window value %q", raw))
+		}
+		args.RestartWindow = d
+	}
 	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
 		return nil, nil
 	}
@@ -871,6 +1064,86 @@ func (s *HTTPServer) JobsParseRequest(resp http.ResponseWriter, req *http.Reques
 	return jobStruct, nil
 }
 
+// JobsConvertRequest converts an HCL1 or JSON jobspec to HCL2. The converted
+// jobspec is parsed again and compared to the original, and the fields which
+// differ are returned as warnings.
+func (s *HTTPServer) JobsConvertRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != http.MethodPut && req.Method != http.MethodPost {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var namespace string
+	parseNamespace(req, &namespace)
+
+	aclObj, err := s.ResolveToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Converting a job requires the same permissions as parsing it
+	hasParseJob := aclObj.AllowNsOp(namespace, acl.NamespaceCapabilityParseJob)
+	hasSubmitJob := aclObj.AllowNsOp(namespace, acl.NamespaceCapabilitySubmitJob)
+
+	allowed := hasParseJob || hasSubmitJob
+	if !allowed {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	args := &api.JobsConvertRequest{}
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+	if args.JobSource == "" {
+		return nil, CodedError(400, "Job spec is empty")
+	}
+
+	var original *api.Job
+	var converted []byte
+	switch args.Format {
+	case "", api.JobsConvertFormatHCL1:
+		original, err = jobspec.Parse(strings.NewReader(args.JobSource))
+		if err != nil {
+			return nil, CodedError(400, fmt.Sprintf("Failed to parse job: %v", err))
+		}
+		converted, err = jobspec2.ConvertHCL1([]byte(args.JobSource))
+	case api.JobsConvertFormatJSON:
+		// JSON jobspecs may wrap the job in a Job field, as returned by the
+		// job inspect API
+		var either struct {
+			NestedJob *api.Job `json:"Job"`
+			api.Job
+		}
+		if err := json.Unmarshal([]byte(args.JobSource), &either); err != nil {
+			return nil, CodedError(400, fmt.Sprintf("Failed to parse job: %v", err))
+		}
+		original = &either.Job
+		if either.NestedJob != nil {
+			original = either.NestedJob
+		}
+		converted, err = jobspec2.EncodeJob(original)
+	default:
+		return nil, CodedError(400, fmt.Sprintf("Unsupported job format %q", args.Format))
+	}
+	if err != nil {
+		return nil, CodedError(400, fmt.Sprintf("Failed to convert job: %v", err))
+	}
+
+	out := &api.JobsConvertResponse{JobHCL: string(converted)}
+	parsed, err := jobspec2.ParseWithConfig(&jobspec2.ParseConfig{
+		Path:    "input.hcl",
+		Body:    converted,
+		AllowFS: false,
+	})
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("Failed to parse converted job: %v", err))
+		return out, nil
+	}
+	for _, path := range jobspec2.JobDiff(original, parsed) {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("Converted job differs from the original at %s", path))
+	}
+	return out, nil
+}
+
 // jobServiceRegistrations returns a list of all service registrations assigned
 // to the job identifier. It is callable via the
 // /v1/job/:jobID/services HTTP API and uses the
@@ -1034,6 +1307,7 @@ func ApiJobToStructJob(job *api.Job) *structs.Job {
 		NodePool:       *job.NodePool,
 		Payload:        job.Payload,
 		Meta:           job.Meta,
+		Env:            job.Env,
 		ConsulToken:    *job.ConsulToken,
 		VaultToken:     *job.VaultToken,
 		VaultNamespace: *job.VaultNamespace,
@@ -1041,6 +1315,10 @@ func ApiJobToStructJob(job *api.Job) *structs.Job {
 		Affinities:     ApiAffinitiesToStructs(job.Affinities),
 	}
 
+	if job.MaxAllocsPerNode != nil {
+		j.MaxAllocsPerNode = *job.MaxAllocsPerNode
+	}
+
 	// Update has been pushed into the task groups. stagger and max_parallel are
 	// preserved at the job level, but all other values are discarded. The job.Update
 	// api value is merged into TaskGroups already in api.Canonicalize
@@ -1087,6 +1365,18 @@ func ApiJobToStructJob(job *api.Job) *structs.Job {
 		}
 	}
 
+	if job.ActiveSchedule != nil {
+		j.ActiveSchedule = &structs.JobActiveSchedule{
+			TimeZone: *job.ActiveSchedule.TimeZone,
+		}
+		for _, w := range job.ActiveSchedule.Windows {
+			j.ActiveSchedule.Windows = append(j.ActiveSchedule.Windows, &structs.JobActiveWindow{
+				Cron:     w.Cron,
+				Duration: w.Duration,
+			})
+		}
+	}
+
 	if job.Multiregion != nil {
 		j.Multiregion = &structs.Multiregion{}
 		j.Multiregion.Strategy = &structs.MultiregionStrategy{
@@ -1121,6 +1411,7 @@ func ApiTgToStructsTG(job *structs.Job, taskGroup *api.TaskGroup, tg *structs.Ta
 	tg.Name = *taskGroup.Name
 	tg.Count = *taskGroup.Count
 	tg.Meta = taskGroup.Meta
+	tg.Env = taskGroup.Env
 	tg.Constraints = ApiConstraintsToStructs(taskGroup.Constraints)
 	tg.Affinities = ApiAffinitiesToStructs(taskGroup.Affinities)
 	tg.Networks = ApiNetworkResourceToStructs(taskGroup.Networks)
@@ -1134,6 +1425,9 @@ func ApiTgToStructsTG(job *structs.Job, taskGroup *api.TaskGroup, tg *structs.Ta
 		Mode:            *taskGroup.RestartPolicy.Mode,
 		RenderTemplates: *taskGroup.RestartPolicy.RenderTemplates,
 	}
+	if taskGroup.RestartPolicy.DisconnectedMode != nil {
+		tg.RestartPolicy.DisconnectedMode = *taskGroup.RestartPolicy.DisconnectedMode
+	}
 
 	if taskGroup.ShutdownDelay != nil {
 		tg.ShutdownDelay = taskGroup.ShutdownDelay
@@ -1147,6 +1441,15 @@ func ApiTgToStructsTG(job *structs.Job, taskGroup *api.TaskGroup, tg *structs.Ta
 		tg.MaxClientDisconnect = taskGroup.MaxClientDisconnect
 	}
 
+	tg.KillEscalation = apiKillEscalationToStructs(taskGroup.KillEscalation)
+
+	if taskGroup.Disconnect != nil {
+		tg.Disconnect = &structs.DisconnectStrategy{}
+		if taskGroup.Disconnect.Reconcile != nil {
+			tg.Disconnect.Reconcile = *taskGroup.Disconnect.Reconcile
+		}
+	}
+
 	if taskGroup.ReschedulePolicy != nil {
 		tg.ReschedulePolicy = &structs.ReschedulePolicy{
 			Attempts:      *taskGroup.ReschedulePolicy.Attempts,
@@ -1187,7 +1490,7 @@ func ApiTgToStructsTG(job *structs.Job, taskGroup *api.TaskGroup, tg *structs.Ta
 	if len(taskGroup.Volumes) > 0 {
 		tg.Volumes = map[string]*structs.VolumeRequest{}
 		for k, v := range taskGroup.Volumes {
-			if v == nil || (v.Type != structs.VolumeTypeHost && v.Type != structs.VolumeTypeCSI) {
+			if v == nil || (v.Type != structs.VolumeTypeHost && v.Type != structs.VolumeTypeLocal && v.Type != structs.VolumeTypeCSI) {
 				// Ignore volumes we don't understand in this iteration currently.
 				// - This is because we don't currently have a way to return errors here.
 				continue
@@ -1225,7 +1528,8 @@ func ApiTgToStructsTG(job *structs.Job, taskGroup *api.TaskGroup, tg *structs.Ta
 			Canary:           *taskGroup.Update.Canary,
 		}
 
-		// boolPtr fields may be nil, others will have pointers to default values via Canonicalize
+		// boolPtr fields, MaxSurge, and the health checks fields may be nil,
+		// others will have pointers to default values via Canonicalize
 		if taskGroup.Update.AutoRevert != nil {
 			tg.Update.AutoRevert = *taskGroup.Update.AutoRevert
 		}
@@ -1233,6 +1537,15 @@ func ApiTgToStructsTG(job *structs.Job, taskGroup *api.TaskGroup, tg *structs.Ta
 		if taskGroup.Update.AutoPromote != nil {
 			tg.Update.AutoPromote = *taskGroup.Update.AutoPromote
 		}
+
+		if taskGroup.Update.MaxSurge != nil {
+			tg.Update.MaxSurge = *taskGroup.Update.MaxSurge
+		}
+
+		tg.Update.HealthChecks = slices.Clone(taskGroup.Update.HealthChecks)
+		if taskGroup.Update.MinHealthyChecksPercent != nil {
+			tg.Update.MinHealthyChecksPercent = *taskGroup.Update.MinHealthyChecksPercent
+		}
 	}
 
 	if len(taskGroup.Tasks) > 0 {
@@ -1266,6 +1579,7 @@ func ApiTaskToStructsTask(job *structs.Job, group *structs.TaskGroup,
 	structsTask.KillTimeout = *apiTask.KillTimeout
 	structsTask.ShutdownDelay = apiTask.ShutdownDelay
 	structsTask.KillSignal = apiTask.KillSignal
+	structsTask.KillEscalation = apiKillEscalationToStructs(apiTask.KillEscalation)
 	structsTask.Kind = structs.TaskKind(apiTask.Kind)
 	structsTask.Constraints = ApiConstraintsToStructs(apiTask.Constraints)
 	structsTask.Affinities = ApiAffinitiesToStructs(apiTask.Affinities)
@@ -1296,6 +1610,9 @@ func ApiTaskToStructsTask(job *structs.Job, group *structs.TaskGroup,
 			Mode:            *apiTask.RestartPolicy.Mode,
 			RenderTemplates: *apiTask.RestartPolicy.RenderTemplates,
 		}
+		if apiTask.RestartPolicy.DisconnectedMode != nil {
+			structsTask.RestartPolicy.DisconnectedMode = *apiTask.RestartPolicy.DisconnectedMode
+		}
 	}
 
 	if len(apiTask.VolumeMounts) > 0 {
@@ -1516,9 +1833,10 @@ func ApiNetworkResourceToStructs(in []*api.NetworkResource) []*structs.NetworkRe
 
 		if nw.DNS != nil {
 			out[i].DNS = &structs.DNSConfig{
-				Servers:  nw.DNS.Servers,
-				Searches: nw.DNS.Searches,
-				Options:  nw.DNS.Options,
+				Servers:      nw.DNS.Servers,
+				Searches:     nw.DNS.Searches,
+				Options:      nw.DNS.Options,
+				ServiceHosts: nw.DNS.ServiceHosts,
 			}
 		}
 
@@ -1628,6 +1946,12 @@ func ApiServicesToStructs(in []*api.Service, group bool) []*structs.Service {
 			out[i].Identity = apiWorkloadIdentityToStructs(s.Identity)
 		}
 
+		if s.Certificate != nil {
+			out[i].Certificate = &structs.ServiceCertificate{
+				TTL: s.Certificate.TTL,
+			}
+		}
+
 	}
 
 	return out
@@ -1642,12 +1966,71 @@ func apiWorkloadIdentityToStructs(in *api.WorkloadIdentity) *structs.WorkloadIde
 		Audience:     slices.Clone(in.Audience),
 		ChangeMode:   in.ChangeMode,
 		ChangeSignal: in.ChangeSignal,
+		ChangeScript: apiChangeScriptToStructsChangeScript(in.ChangeScript),
 		Env:          in.Env,
 		File:         in.File,
 		ServiceName:  in.ServiceName,
+		TTL:          in.TTL,
+		X509:         in.X509,
+		Projection:   apiWorkloadIdentityProjectionToStructs(in.Projection),
+		SPIFFE:       apiWorkloadIdentitySPIFFEToStructs(in.SPIFFE),
+		Cloud:        apiWorkloadIdentityCloudToStructs(in.Cloud),
+		ExtraClaims:  maps.Clone(in.ExtraClaims),
+	}
+}
+
+func apiWorkloadIdentitySPIFFEToStructs(in *api.WorkloadIdentitySPIFFE) *structs.WorkloadIdentitySPIFFE {
+	if in == nil {
+		return nil
+	}
+	return &structs.WorkloadIdentitySPIFFE{
+		JWT:  in.JWT,
+		X509: in.X509,
+	}
+}
+
+func apiWorkloadIdentityCloudToStructs(in *api.WorkloadIdentityCloud) *structs.WorkloadIdentityCloud {
+	if in == nil {
+		return nil
+	}
+	return &structs.WorkloadIdentityCloud{
+		Provider:                 in.Provider,
+		RoleARN:                  in.RoleARN,
+		Region:                   in.Region,
+		WorkloadIdentityProvider: in.WorkloadIdentityProvider,
+		ServiceAccount:           in.ServiceAccount,
+		TenantID:                 in.TenantID,
+		ClientID:                 in.ClientID,
+		Scopes:                   slices.Clone(in.Scopes),
 	}
 }
 
+func apiWorkloadIdentityProjectionToStructs(in *api.WorkloadIdentityProjection) *structs.WorkloadIdentityProjection {
+	if in == nil {
+		return nil
+	}
+	return &structs.WorkloadIdentityProjection{
+		Destination: in.Destination,
+		File:        in.File,
+		Perms:       in.Perms,
+		CABundle:    in.CABundle,
+	}
+}
+
+func apiKillEscalationToStructs(in []*api.KillEscalationStep) []*structs.KillEscalationStep {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]*structs.KillEscalationStep, len(in))
+	for i, step := range in {
+		out[i] = &structs.KillEscalationStep{
+			Signal:  step.Signal,
+			Timeout: step.Timeout,
+		}
+	}
+	return out
+}
+
 func ApiConsulConnectToStructs(in *api.ConsulConnect) *structs.ConsulConnect {
 	if in == nil {
 		return nil
@@ -4,6 +4,7 @@
 package agent
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -429,6 +430,60 @@ func TestHTTP_JobsParse_HCLVar(t *testing.T) {
 	})
 }
 
+func TestHTTP_JobsConvert(t *testing.T) {
+	ci.Parallel(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		// An HCL1 job is converted with its comments
+		buf := encodeReq(api.JobsConvertRequest{
+			JobSource: "# example job\njob \"example\" {\n  datacenters = [\"dc1\"]\n}\n",
+		})
+		req, err := http.NewRequest(http.MethodPost, "/v1/jobs/convert", buf)
+		must.NoError(t, err)
+
+		obj, err := s.Server.JobsConvertRequest(httptest.NewRecorder(), req)
+		must.NoError(t, err)
+
+		out := obj.(*api.JobsConvertResponse)
+		must.Eq(t, "# example job\njob \"example\" {\n  datacenters = [\"dc1\"]\n}\n", out.JobHCL)
+		must.SliceEmpty(t, out.Warnings)
+
+		// A JSON job is encoded as HCL2
+		job := mock.Job()
+		apiJob := &api.Job{
+			ID:          &job.ID,
+			Datacenters: job.Datacenters,
+			TaskGroups: []*api.TaskGroup{{
+				Name:  pointer.Of("web"),
+				Count: pointer.Of(10),
+			}},
+		}
+		jobJSON, err := json.Marshal(map[string]any{"Job": apiJob})
+		must.NoError(t, err)
+		buf = encodeReq(api.JobsConvertRequest{
+			JobSource: string(jobJSON),
+			Format:    api.JobsConvertFormatJSON,
+		})
+		req, err = http.NewRequest(http.MethodPost, "/v1/jobs/convert", buf)
+		must.NoError(t, err)
+
+		obj, err = s.Server.JobsConvertRequest(httptest.NewRecorder(), req)
+		must.NoError(t, err)
+
+		out = obj.(*api.JobsConvertResponse)
+		must.StrContains(t, out.JobHCL, fmt.Sprintf("job %q {", job.ID))
+		must.StrContains(t, out.JobHCL, "count = 10")
+		must.SliceEmpty(t, out.Warnings)
+
+		// Unsupported formats are rejected
+		buf = encodeReq(api.JobsConvertRequest{JobSource: "{}", Format: "yaml"})
+		req, err = http.NewRequest(http.MethodPost, "/v1/jobs/convert", buf)
+		must.NoError(t, err)
+
+		_, err = s.Server.JobsConvertRequest(httptest.NewRecorder(), req)
+		must.ErrorContains(t, err, `Unsupported job format "yaml"`)
+	})
+}
+
 func TestHTTP_JobsParse_ACL(t *testing.T) {
 	ci.Parallel(t)
 
@@ -1466,6 +1521,47 @@ func TestHTTP_JobEvaluate_ForceReschedule(t *testing.T) {
 	})
 }
 
+func TestHTTP_JobFailures(t *testing.T) {
+	ci.Parallel(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		state := s.Agent.server.State()
+		job := mock.Job()
+		must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.TaskStates = map[string]*structs.TaskState{
+			"web": {Events: []*structs.TaskEvent{
+				structs.NewTaskEvent(structs.TaskTerminated).SetExitCode(1),
+				structs.NewTaskEvent(structs.TaskRestarting),
+			}},
+		}
+		must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1001, []*structs.Allocation{alloc}))
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/job/"+job.ID+"/failures?since=2h&window=30m", nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.JobSpecificRequest(respW, req)
+		must.NoError(t, err)
+		must.Eq(t, "1001", respW.Result().Header.Get("X-Nomad-Index"))
+
+		history := obj.(*structs.JobFailureHistory)
+		must.Len(t, 4, history.Windows)
+		must.Eq(t, 1, history.Restarts)
+		must.Len(t, 1, history.Failures)
+		must.Eq(t, structs.JobFailureReasonExitCode, history.Failures[0].Reason)
+		must.Eq(t, "1", history.Failures[0].Detail)
+
+		// Invalid windows are rejected
+		req, err = http.NewRequest(http.MethodGet, "/v1/job/"+job.ID+"/failures?since=24h&window=1s", nil)
+		must.NoError(t, err)
+		_, err = s.Server.JobSpecificRequest(httptest.NewRecorder(), req)
+		must.ErrorContains(t, err, "window too small")
+	})
+}
+
 func TestHTTP_JobEvaluations(t *testing.T) {
 	ci.Parallel(t)
 	httpTest(t, nil, func(s *TestAgent) {
@@ -3735,6 +3831,29 @@ func TestJobs_ApiJobToStructsJobUpdate(t *testing.T) {
 	require.Equal(t, group2, *structsJob.TaskGroups[1].Update)
 }
 
+func TestJobs_ApiJobToStructsJobActiveSchedule(t *testing.T) {
+	ci.Parallel(t)
+
+	apiJob := &api.Job{
+		ActiveSchedule: &api.JobActiveSchedule{
+			TimeZone: pointer.Of("Europe/Paris"),
+			Windows: []*api.JobActiveWindow{
+				{Cron: "0 8 * * 1-5", Duration: 10 * time.Hour},
+			},
+			// The override is managed by the server and can't be submitted.
+			Override: &api.JobActiveScheduleOverride{Active: true},
+		},
+	}
+
+	structsJob := ApiJobToStructJob(apiJob)
+	must.Eq(t, &structs.JobActiveSchedule{
+		TimeZone: "Europe/Paris",
+		Windows: []*structs.JobActiveWindow{
+			{Cron: "0 8 * * 1-5", Duration: 10 * time.Hour},
+		},
+	}, structsJob.ActiveSchedule)
+}
+
 // TestJobs_Matching_Resources asserts:
 //
 //	api.{Default,Min}Resources == structs.{Default,Min}Resources
@@ -4,8 +4,12 @@
 package agent
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +26,11 @@ const jwksMinMaxAge = 15 * time.Minute
 // Sets and returns the public keys used for signing workload identities. Third
 // parties may use this endpoint to validate workload identities. Consumers
 // should cache this endpoint, preferably until an unknown kid is encountered.
+//
+// When the all_regions query parameter is set, the keys of all federated
+// regions are returned so a single JWKS URL can be used to validate workload
+// identities from any region. Regions that can't be reached are reported in
+// the response rather than failing the request.
 func (s *HTTPServer) JWKSRequest(resp http.ResponseWriter, req *http.Request) (any, error) {
 	if req.Method != http.MethodGet {
 		return nil, CodedError(405, ErrInvalidMethod)
@@ -32,11 +41,28 @@ func (s *HTTPServer) JWKSRequest(resp http.ResponseWriter, req *http.Request) (a
 		return nil, nil
 	}
 
+	allRegions, err := parseBool(req, "all_regions")
+	if err != nil {
+		return nil, CodedError(http.StatusBadRequest, err.Error())
+	}
+
 	var rpcReply structs.KeyringListPublicResponse
-	if err := s.agent.RPC("Keyring.ListPublic", &args, &rpcReply); err != nil {
-		return nil, err
+	var regionErrors []jwksRegionError
+	if allRegions != nil && *allRegions {
+		var regions []string
+		if err := s.agent.RPC("Region.List", &args, &regions); err != nil {
+			return nil, err
+		}
+		regionErrors = s.listPublicKeysRegions(&args, regions, &rpcReply)
+		if len(regionErrors) == len(regions) && len(regions) > 0 {
+			return nil, fmt.Errorf("failed to list public keys of any region: %s", regionErrors[0].Error)
+		}
+	} else {
+		if err := s.agent.RPC("Keyring.ListPublic", &args, &rpcReply); err != nil {
+			return nil, err
+		}
+		setMeta(resp, &rpcReply.QueryMeta)
 	}
-	setMeta(resp, &rpcReply.QueryMeta)
 
 	// Key set will change after max(CreateTime) + RotationThreshold.
 	var newestKey int64
@@ -46,10 +72,17 @@ func (s *HTTPServer) JWKSRequest(resp http.ResponseWriter, req *http.Request) (a
 			newestKey = pubKey.CreateTime
 		}
 
+		// Keys are only ever used to sign workload identities, so default the
+		// use parameter for servers that don't set it.
+		use := pubKey.Use
+		if use == "" {
+			use = structs.PubKeyUseSig
+		}
+
 		jwk := jose.JSONWebKey{
 			KeyID:     pubKey.KeyID,
 			Algorithm: pubKey.Algorithm,
-			Use:       pubKey.Use,
+			Use:       use,
 		}
 
 		// Convert public key bytes to an ed25519 public key
@@ -63,20 +96,104 @@ func (s *HTTPServer) JWKSRequest(resp http.ResponseWriter, req *http.Request) (a
 		jwks = append(jwks, jwk)
 	}
 
-	// Have nonzero create times and threshold so set a reasonable cache time.
-	if newestKey > 0 && rpcReply.RotationThreshold > 0 {
+	// Have nonzero create times and threshold so set a reasonable cache time,
+	// otherwise let consumers cache for the minimum amount of time.
+	// Partial key sets are only cached for the minimum amount of time so the
+	// keys of the missing regions are picked up quickly.
+	maxAge := jwksMinMaxAge
+	if newestKey > 0 && rpcReply.RotationThreshold > 0 && len(regionErrors) == 0 {
 		exp := time.Unix(0, newestKey).Add(rpcReply.RotationThreshold)
-		maxAge := helper.ExpiryToRenewTime(exp, time.Now, jwksMinMaxAge)
-		resp.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(maxAge.Seconds())))
+		maxAge = helper.ExpiryToRenewTime(exp, time.Now, jwksMinMaxAge)
+	}
+	resp.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+
+	// The key set is identified by its key IDs, so consumers polling this
+	// endpoint can revalidate their cached copy cheaply.
+	etag := jwksETag(jwks)
+	resp.Header().Set("ETag", etag)
+	if match := req.Header.Get("If-None-Match"); match != "" && match == etag {
+		resp.WriteHeader(http.StatusNotModified)
+		return nil, nil
 	}
 
 	out := &jose.JSONWebKeySet{
 		Keys: jwks,
 	}
+	if allRegions != nil && *allRegions {
+		return &jwksAllRegions{JSONWebKeySet: out, RegionErrors: regionErrors}, nil
+	}
 
 	return out, nil
 }
 
+// jwksAllRegions is the key set returned when the keys of all regions are
+// requested. The errors of the regions that couldn't be queried are added as
+// an extra member of the key set, which other consumers must ignore.
+type jwksAllRegions struct {
+	*jose.JSONWebKeySet
+	RegionErrors []jwksRegionError `json:"region_errors,omitempty"`
+}
+
+// jwksRegionError is the error returned by a region when listing its public
+// keys.
+type jwksRegionError struct {
+	Region string `json:"region"`
+	Error  string `json:"error"`
+}
+
+// listPublicKeysRegions queries the public keys of the regions and merges them
+// into reply. Each region has its own keyring, but keys are deduplicated by
+// their ID in case a region is queried more than once. The errors of the
+// regions that couldn't be queried are returned so the keys of the other
+// regions can still be served.
+func (s *HTTPServer) listPublicKeysRegions(args *structs.GenericRequest, regions []string, reply *structs.KeyringListPublicResponse) []jwksRegionError {
+	var regionErrors []jwksRegionError
+	seen := make(map[string]struct{})
+	for _, region := range regions {
+		// Blocking queries aren't supported across regions since each region
+		// has its own raft index.
+		regionArgs := *args
+		regionArgs.Region = region
+		regionArgs.MinQueryIndex = 0
+
+		var regionReply structs.KeyringListPublicResponse
+		if err := s.agent.RPC("Keyring.ListPublic", &regionArgs, &regionReply); err != nil {
+			s.logger.Warn("failed to list public keys of region", "region", region, "error", err)
+			regionErrors = append(regionErrors, jwksRegionError{Region: region, Error: err.Error()})
+			continue
+		}
+
+		// Use the shortest rotation threshold so consumers never cache the
+		// key set for longer than any individual region would allow.
+		if reply.RotationThreshold == 0 || regionReply.RotationThreshold < reply.RotationThreshold {
+			reply.RotationThreshold = regionReply.RotationThreshold
+		}
+
+		for _, pubKey := range regionReply.PublicKeys {
+			if _, ok := seen[pubKey.KeyID]; ok {
+				continue
+			}
+			seen[pubKey.KeyID] = struct{}{}
+			reply.PublicKeys = append(reply.PublicKeys, pubKey)
+		}
+	}
+
+	return regionErrors
+}
+
+// jwksETag returns a strong ETag for the key set built from the sorted IDs of
+// its keys.
+func jwksETag(jwks []jose.JSONWebKey) string {
+	ids := make([]string, 0, len(jwks))
+	for _, jwk := range jwks {
+		ids = append(ids, jwk.KeyID)
+	}
+	sort.Strings(ids)
+
+	sum := sha256.Sum256([]byte(strings.Join(ids, ",")))
+	return fmt.Sprintf("%q", hex.EncodeToString(sum[:16]))
+}
+
 // OIDCDiscoveryRequest implements the OIDC Discovery protocol for using
 // workload identity JWTs with external services.
 //
@@ -99,6 +216,9 @@ func (s *HTTPServer) OIDCDiscoveryRequest(resp http.ResponseWriter, req *http.Re
 		return nil, CodedError(http.StatusNotFound, "OIDC Discovery endpoint disabled")
 	}
 
+	// The discovery configuration only changes when servers are reconfigured.
+	resp.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMinMaxAge.Seconds())))
+
 	return rpcReply.OIDCDiscovery, nil
 }
 
@@ -167,6 +287,24 @@ func (s *HTTPServer) keyringRotateRequest(resp http.ResponseWriter, req *http.Re
 		args.Full = true
 	}
 
+	if publishTime := query.Get("publish_time"); publishTime != "" {
+		t, err := strconv.ParseInt(publishTime, 10, 64)
+		if err != nil {
+			return nil, CodedError(http.StatusBadRequest,
+				fmt.Sprintf("invalid publish_time: %v", err))
+		}
+		args.PublishTime = t
+	}
+
+	if prepublish := query.Get("prepublish"); prepublish != "" {
+		d, err := time.ParseDuration(prepublish)
+		if err != nil {
+			return nil, CodedError(http.StatusBadRequest,
+				fmt.Sprintf("invalid prepublish duration: %v", err))
+		}
+		args.PrepublishDuration = d
+	}
+
 	var out structs.KeyringRotateRootKeyResponse
 	if err := s.agent.RPC("Keyring.Rotate", &args, &out); err != nil {
 		return nil, err
@@ -108,11 +108,61 @@ func TestHTTP_Keyring_JWKS(t *testing.T) {
 		// Assert that caching headers are set to < the rotation threshold
 		cacheHeaders := respW.Header().Values("Cache-Control")
 		must.SliceLen(t, 1, cacheHeaders)
-		must.StrHasPrefix(t, "max-age=", cacheHeaders[0])
+		must.StrHasPrefix(t, "public, max-age=", cacheHeaders[0])
 		parts := strings.Split(cacheHeaders[0], "=")
 		ttl, err := strconv.Atoi(parts[1])
 		must.NoError(t, err)
 		must.Less(t, int(threshold.Seconds()), ttl)
+
+		// Assert keys have their use and algorithm set
+		must.Eq(t, structs.PubKeyUseSig, jwks.Keys[0].Use)
+		must.NotEq(t, "", jwks.Keys[0].Algorithm)
+
+		// Assert that requests with a matching ETag are not modified
+		etag := respW.Header().Get("ETag")
+		must.NotEq(t, "", etag)
+
+		respW = httptest.NewRecorder()
+		req.Header.Set("If-None-Match", etag)
+		obj, err = s.Server.JWKSRequest(respW, req)
+		must.NoError(t, err)
+		must.Nil(t, obj)
+		must.Eq(t, http.StatusNotModified, respW.Code)
+	})
+}
+
+// TestHTTP_Keyring_JWKS_AllRegions asserts the JWKS endpoint can aggregate the
+// keys of all regions.
+func TestHTTP_Keyring_JWKS_AllRegions(t *testing.T) {
+	ci.Parallel(t)
+
+	httpTest(t, nil, func(s *TestAgent) {
+		respW := httptest.NewRecorder()
+
+		req, err := http.NewRequest(http.MethodGet, structs.JWKSPath+"?all_regions=true", nil)
+		must.NoError(t, err)
+
+		obj, err := s.Server.JWKSRequest(respW, req)
+		must.NoError(t, err)
+
+		jwks := obj.(*jwksAllRegions)
+		must.SliceLen(t, 1, jwks.Keys)
+		must.SliceEmpty(t, jwks.RegionErrors)
+		must.StrHasPrefix(t, "public, max-age=", respW.Header().Get("Cache-Control"))
+
+		// An unreachable region is reported without failing the request.
+		args := structs.GenericRequest{QueryOptions: structs.QueryOptions{Region: s.Config.Region}}
+		var reply structs.KeyringListPublicResponse
+		regionErrors := s.Server.listPublicKeysRegions(&args, []string{s.Config.Region, "unknown"}, &reply)
+		must.SliceLen(t, 1, reply.PublicKeys)
+		must.SliceLen(t, 1, regionErrors)
+		must.Eq(t, "unknown", regionErrors[0].Region)
+		must.StrContains(t, regionErrors[0].Error, "region")
+
+		req, err = http.NewRequest(http.MethodGet, structs.JWKSPath+"?all_regions=maybe", nil)
+		must.NoError(t, err)
+		_, err = s.Server.JWKSRequest(httptest.NewRecorder(), req)
+		must.ErrorContains(t, err, "Failed to parse value")
 	})
 }
 
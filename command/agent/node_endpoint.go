@@ -42,6 +42,31 @@ func (s *HTTPServer) NodesRequest(resp http.ResponseWriter, req *http.Request) (
 	return out.Nodes, nil
 }
 
+func (s *HTTPServer) NodesUtilizationRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != http.MethodGet {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.NodeSpecificRequest{}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.NodeUtilizationResponse
+	if err := s.agent.RPC("Node.Utilization", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	latest := make(map[string]*structs.NodeUtilizationRollup, len(out.Rollups))
+	for id, rollups := range out.Rollups {
+		if n := len(rollups); n > 0 {
+			latest[id] = rollups[n-1]
+		}
+	}
+	return latest, nil
+}
+
 func (s *HTTPServer) NodeSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	path := strings.TrimPrefix(req.URL.Path, "/v1/node/")
 	switch {
@@ -57,6 +82,9 @@ func (s *HTTPServer) NodeSpecificRequest(resp http.ResponseWriter, req *http.Req
 	case strings.HasSuffix(path, "/eligibility"):
 		nodeName := strings.TrimSuffix(path, "/eligibility")
 		return s.nodeToggleEligibility(resp, req, nodeName)
+	case strings.HasSuffix(path, "/utilization"):
+		nodeName := strings.TrimSuffix(path, "/utilization")
+		return s.nodeUtilization(resp, req, nodeName)
 	case strings.HasSuffix(path, "/purge"):
 		nodeName := strings.TrimSuffix(path, "/purge")
 		return s.nodePurge(resp, req, nodeName)
@@ -110,6 +138,34 @@ func (s *HTTPServer) nodeAllocations(resp http.ResponseWriter, req *http.Request
 	return out.Allocs, nil
 }
 
+func (s *HTTPServer) nodeUtilization(resp http.ResponseWriter, req *http.Request,
+	nodeID string) (interface{}, error) {
+	if req.Method != http.MethodGet {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	if nodeID == "" {
+		return nil, CodedError(400, "missing node ID")
+	}
+	args := structs.NodeSpecificRequest{
+		NodeID: nodeID,
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out structs.NodeUtilizationResponse
+	if err := s.agent.RPC("Node.Utilization", &args, &out); err != nil {
+		return nil, err
+	}
+
+	setMeta(resp, &out.QueryMeta)
+	rollups := out.Rollups[nodeID]
+	if rollups == nil {
+		rollups = make([]*structs.NodeUtilizationRollup, 0)
+	}
+	return rollups, nil
+}
+
 func (s *HTTPServer) nodeToggleDrain(resp http.ResponseWriter, req *http.Request,
 	nodeID string) (interface{}, error) {
 	if req.Method != http.MethodPut && req.Method != http.MethodPost {
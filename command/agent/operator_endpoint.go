@@ -32,6 +32,8 @@ func (s *HTTPServer) OperatorRequest(resp http.ResponseWriter, req *http.Request
 		return s.OperatorRaftPeer(resp, req)
 	case strings.HasPrefix(path, "transfer-leadership"):
 		return s.OperatorRaftTransferLeadership(resp, req)
+	case strings.HasPrefix(path, "voter"):
+		return s.OperatorRaftVoter(resp, req)
 	default:
 		return nil, CodedError(404, ErrInvalidMethod)
 	}
@@ -149,6 +151,36 @@ func (s *HTTPServer) OperatorRaftTransferLeadership(resp http.ResponseWriter, re
 	return out, nil
 }
 
+// OperatorRaftVoter is used to set whether a Raft peer is a voter or a
+// non-voting server.
+func (s *HTTPServer) OperatorRaftVoter(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		return nil, CodedError(http.StatusMethodNotAllowed, ErrInvalidMethod)
+	}
+
+	params := req.URL.Query()
+	id := params.Get("id")
+	if id == "" {
+		return nil, CodedError(http.StatusBadRequest, "must specify id")
+	}
+	voter, err := strconv.ParseBool(params.Get("voter"))
+	if err != nil {
+		return nil, CodedError(http.StatusBadRequest, fmt.Sprintf("Error parsing voter value: %v", err))
+	}
+
+	args := structs.RaftSetVoterRequest{
+		ID:    raft.ServerID(id),
+		Voter: voter,
+	}
+	s.parseWriteRequest(req, &args.WriteRequest)
+
+	var reply struct{}
+	if err := s.agent.RPC("Operator.RaftSetVoter", &args, &reply); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
 // OperatorAutopilotConfiguration is used to inspect the current Autopilot configuration.
 // This supports the stale query mode in case the cluster doesn't have a leader.
 func (s *HTTPServer) OperatorAutopilotConfiguration(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
@@ -174,6 +206,7 @@ func (s *HTTPServer) OperatorAutopilotConfiguration(resp http.ResponseWriter, re
 			EnableRedundancyZones:   reply.EnableRedundancyZones,
 			DisableUpgradeMigration: reply.DisableUpgradeMigration,
 			EnableCustomUpgrades:    reply.EnableCustomUpgrades,
+			NonVoters:               reply.NonVoters,
 			CreateIndex:             reply.CreateIndex,
 			ModifyIndex:             reply.ModifyIndex,
 		}
@@ -198,6 +231,7 @@ func (s *HTTPServer) OperatorAutopilotConfiguration(resp http.ResponseWriter, re
 			EnableRedundancyZones:   conf.EnableRedundancyZones,
 			DisableUpgradeMigration: conf.DisableUpgradeMigration,
 			EnableCustomUpgrades:    conf.EnableCustomUpgrades,
+			NonVoters:               conf.NonVoters,
 		}
 
 		// Check for cas value
@@ -315,6 +349,7 @@ func (s *HTTPServer) schedulerUpdateConfig(resp http.ResponseWriter, req *http.R
 	args.Config = structs.SchedulerConfiguration{
 		SchedulerAlgorithm:            structs.SchedulerAlgorithm(conf.SchedulerAlgorithm),
 		MemoryOversubscriptionEnabled: conf.MemoryOversubscriptionEnabled,
+		LoadAwareScoringEnabled:       conf.LoadAwareScoringEnabled,
 		RejectJobRegistration:         conf.RejectJobRegistration,
 		PauseEvalBroker:               conf.PauseEvalBroker,
 		PreemptionConfig: structs.PreemptionConfig{
@@ -322,6 +357,25 @@ func (s *HTTPServer) schedulerUpdateConfig(resp http.ResponseWriter, req *http.R
 			SysBatchSchedulerEnabled: conf.PreemptionConfig.SysBatchSchedulerEnabled,
 			BatchSchedulerEnabled:    conf.PreemptionConfig.BatchSchedulerEnabled,
 			ServiceSchedulerEnabled:  conf.PreemptionConfig.ServiceSchedulerEnabled},
+		EvalTraceConfig: structs.EvalTraceConfig{
+			Enabled:   conf.EvalTraceConfig.Enabled,
+			MaxNodes:  conf.EvalTraceConfig.MaxNodes,
+			MaxTraces: conf.EvalTraceConfig.MaxTraces,
+		},
+		GCConfig: structs.GCConfig{
+			JobThreshold:            conf.GCConfig.JobThreshold,
+			JobBatchSize:            conf.GCConfig.JobBatchSize,
+			EvalThreshold:           conf.GCConfig.EvalThreshold,
+			BatchEvalThreshold:      conf.GCConfig.BatchEvalThreshold,
+			EvalBatchSize:           conf.GCConfig.EvalBatchSize,
+			DeploymentThreshold:     conf.GCConfig.DeploymentThreshold,
+			DeploymentBatchSize:     conf.GCConfig.DeploymentBatchSize,
+			CSIVolumeClaimThreshold: conf.GCConfig.CSIVolumeClaimThreshold,
+		},
+		BatchFairShareConfig: structs.BatchFairShareConfig{
+			Enabled:          conf.BatchFairShareConfig.Enabled,
+			NamespaceWeights: conf.BatchFairShareConfig.NamespaceWeights,
+		},
 	}
 
 	if err := args.Config.Validate(); err != nil {
@@ -93,6 +93,26 @@ func TestHTTP_OperatorRaftPeer(t *testing.T) {
 	})
 }
 
+func TestHTTP_OperatorRaftVoter(t *testing.T) {
+	ci.Parallel(t)
+	httpTest(t, nil, func(s *TestAgent) {
+		for _, query := range []string{"", "?id=nope", "?id=nope&voter=maybe"} {
+			req, err := http.NewRequest(http.MethodPut, "/v1/operator/raft/voter"+query, nil)
+			must.NoError(t, err)
+			_, err = s.Server.OperatorRaftVoter(httptest.NewRecorder(), req)
+			must.Error(t, err)
+			must.Eq(t, http.StatusBadRequest, err.(HTTPCodedError).Code())
+		}
+
+		// If we get this error, it proves we sent the id all the way
+		// through.
+		req, err := http.NewRequest(http.MethodPut, "/v1/operator/raft/voter?id=nope&voter=false", nil)
+		must.NoError(t, err)
+		_, err = s.Server.OperatorRaftVoter(httptest.NewRecorder(), req)
+		must.ErrorContains(t, err, "id \"nope\" was not found in the Raft configuration")
+	})
+}
+
 func TestHTTP_OperatorRaftTransferLeadership(t *testing.T) {
 	ci.Parallel(t)
 	configCB := func(c *Config) {
@@ -462,6 +482,10 @@ func TestOperator_SchedulerSetConfiguration(t *testing.T) {
   "PreemptionConfig": {
     "SystemSchedulerEnabled": true,
     "ServiceSchedulerEnabled": true
+  },
+  "GCConfig": {
+    "JobThreshold": 3600000000000,
+    "EvalBatchSize": 100
   }
 }`))
 		req, _ := http.NewRequest(http.MethodPut, "/v1/operator/scheduler/configuration", body)
@@ -488,6 +512,10 @@ func TestOperator_SchedulerSetConfiguration(t *testing.T) {
 		require.True(t, reply.SchedulerConfig.PreemptionConfig.ServiceSchedulerEnabled)
 		require.True(t, reply.SchedulerConfig.MemoryOversubscriptionEnabled)
 		require.True(t, reply.SchedulerConfig.PauseEvalBroker)
+		require.Equal(t, structs.GCConfig{
+			JobThreshold:  time.Hour,
+			EvalBatchSize: 100,
+		}, reply.SchedulerConfig.GCConfig)
 	})
 }
 
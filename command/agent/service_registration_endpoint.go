@@ -4,7 +4,9 @@
 package agent
 
 import (
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -43,6 +45,83 @@ func (s *HTTPServer) ServiceRegistrationListRequest(
 	return reply.Services, nil
 }
 
+// PrometheusTargetGroup is a group of targets sharing the same labels, as
+// defined by the Prometheus HTTP service discovery.
+type PrometheusTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// prometheusLabelPrefix is the prefix of the meta labels of the targets. They
+// match the labels of the Prometheus Nomad service discovery, so relabeling
+// rules work with both.
+const prometheusLabelPrefix = "__meta_nomad_"
+
+// ServiceRegistrationPrometheusRequest lists the healthy instances of services
+// using the structs.ServiceRegistrationListInstancesRPCMethod RPC endpoint,
+// in the format of the Prometheus HTTP service discovery. It is callable via
+// the /v1/services/prometheus HTTP API.
+func (s *HTTPServer) ServiceRegistrationPrometheusRequest(
+	resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	// The endpoint only supports GET requests.
+	if req.Method != http.MethodGet {
+		return nil, CodedError(http.StatusMethodNotAllowed, ErrInvalidMethod)
+	}
+
+	query := req.URL.Query()
+	args := structs.ServiceRegistrationInstancesRequest{
+		ServiceName: query.Get("service"),
+		Tags:        query["tag"],
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var reply structs.ServiceRegistrationInstancesResponse
+	if err := s.agent.RPC(structs.ServiceRegistrationListInstancesRPCMethod, &args, &reply); err != nil {
+		return nil, err
+	}
+	setMeta(resp, &reply.QueryMeta)
+
+	groups := make([]*PrometheusTargetGroup, 0, len(reply.Instances))
+	for _, instance := range reply.Instances {
+		port := strconv.Itoa(instance.Port)
+		labels := map[string]string{
+			"address":         instance.Address,
+			"dc":              instance.Datacenter,
+			"namespace":       instance.Namespace,
+			"node_id":         instance.NodeID,
+			"node_name":       instance.NodeName,
+			"service":         instance.ServiceName,
+			"service_address": instance.Address,
+			"service_id":      instance.ID,
+			"service_port":    port,
+			"job_id":          instance.JobID,
+			"alloc_id":        instance.AllocID,
+			"alloc_name":      instance.AllocName,
+			"task_group":      instance.TaskGroup,
+			"tags":            "",
+		}
+
+		// Tags are joined with leading and trailing separators, so a single
+		// tag can be matched with a regular expression such as ".*,tag,.*".
+		if len(instance.Tags) > 0 {
+			labels["tags"] = "," + strings.Join(instance.Tags, ",") + ","
+		}
+
+		prefixed := make(map[string]string, len(labels))
+		for name, value := range labels {
+			prefixed[prometheusLabelPrefix+name] = value
+		}
+		groups = append(groups, &PrometheusTargetGroup{
+			Targets: []string{net.JoinHostPort(instance.Address, port)},
+			Labels:  prefixed,
+		})
+	}
+	return groups, nil
+}
+
 // ServiceRegistrationRequest is callable via the /v1/service/ HTTP API and
 // handles service reads and individual service registration deletions.
 func (s *HTTPServer) ServiceRegistrationRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
@@ -398,3 +398,65 @@ func TestHTTPServer_ServiceRegistrationRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPServer_ServiceRegistrationPrometheusRequest(t *testing.T) {
+	ci.Parallel(t)
+
+	httpTest(t, nil, func(s *TestAgent) {
+		testState := s.Agent.server.State()
+
+		node := mock.Node()
+		must.NoError(t, testState.UpsertNode(structs.MsgTypeTestSetup, 10, node))
+
+		alloc := mock.Alloc()
+		alloc.NodeID = node.ID
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		must.NoError(t, testState.UpsertAllocs(structs.MsgTypeTestSetup, 15, []*structs.Allocation{alloc}))
+
+		service := mock.ServiceRegistrations()[0]
+		service.NodeID = node.ID
+		service.JobID = alloc.JobID
+		service.AllocID = alloc.ID
+		must.NoError(t, testState.UpsertServiceRegistrations(
+			structs.MsgTypeTestSetup, 20, []*structs.ServiceRegistration{service}))
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/services/prometheus?service=example-cache&tag=foo", nil)
+		must.NoError(t, err)
+		respW := httptest.NewRecorder()
+
+		obj, err := s.Server.ServiceRegistrationPrometheusRequest(respW, req)
+		must.NoError(t, err)
+		must.Eq(t, "20", respW.Header().Get("X-Nomad-Index"))
+		must.Eq(t, []*PrometheusTargetGroup{{
+			Targets: []string{fmt.Sprintf("%s:%d", service.Address, service.Port)},
+			Labels: map[string]string{
+				"__meta_nomad_address":         service.Address,
+				"__meta_nomad_dc":              service.Datacenter,
+				"__meta_nomad_namespace":       service.Namespace,
+				"__meta_nomad_node_id":         node.ID,
+				"__meta_nomad_node_name":       node.Name,
+				"__meta_nomad_service":         service.ServiceName,
+				"__meta_nomad_service_address": service.Address,
+				"__meta_nomad_service_id":      service.ID,
+				"__meta_nomad_service_port":    fmt.Sprint(service.Port),
+				"__meta_nomad_job_id":          alloc.JobID,
+				"__meta_nomad_alloc_id":        alloc.ID,
+				"__meta_nomad_alloc_name":      alloc.Name,
+				"__meta_nomad_task_group":      alloc.TaskGroup,
+				"__meta_nomad_tags":            ",foo,",
+			},
+		}}, obj.([]*PrometheusTargetGroup))
+
+		// Instances not matching the filters return an empty list.
+		req, err = http.NewRequest(http.MethodGet, "/v1/services/prometheus?tag=nope", nil)
+		must.NoError(t, err)
+		obj, err = s.Server.ServiceRegistrationPrometheusRequest(httptest.NewRecorder(), req)
+		must.NoError(t, err)
+		must.SliceEmpty(t, obj.([]*PrometheusTargetGroup))
+
+		req, err = http.NewRequest(http.MethodPost, "/v1/services/prometheus", nil)
+		must.NoError(t, err)
+		_, err = s.Server.ServiceRegistrationPrometheusRequest(httptest.NewRecorder(), req)
+		must.ErrorContains(t, err, ErrInvalidMethod)
+	})
+}
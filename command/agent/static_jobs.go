@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/jobspec2"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// staticJobExtensions are the extensions of the job specification files read
+// from the static jobs directory.
+var staticJobExtensions = []string{".hcl", ".nomad", ".json"}
+
+// parseStaticJobs parses the job specifications of the static jobs directory
+// of the client, in lexical order of their file names.
+func parseStaticJobs(dir string) ([]*structs.Job, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var jobs []*structs.Job
+	seen := make(map[structs.NamespacedID]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !isStaticJobFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		job, err := parseStaticJob(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse static job %q: %v", path, err)
+		}
+
+		id := job.NamespacedID()
+		if other, ok := seen[id]; ok {
+			return nil, fmt.Errorf("static job %q in %q is already defined in %q", job.ID, path, other)
+		}
+		seen[id] = path
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// parseStaticJob parses and validates the job specification of a static job.
+func parseStaticJob(path string) (*structs.Job, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	apiJob, err := jobspec2.ParseWithConfig(&jobspec2.ParseConfig{
+		Path:    path,
+		BaseDir: filepath.Dir(path),
+		Body:    body,
+		AllowFS: true,
+		Strict:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	job := ApiJobToStructJob(apiJob)
+	job.Canonicalize()
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateStaticJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// validateStaticJob returns an error if the job uses features that require
+// the servers, since static jobs run before the client connects to them.
+func validateStaticJob(job *structs.Job) error {
+	var mErr *multierror.Error
+
+	if job.IsPeriodic() || job.IsParameterized() {
+		mErr = multierror.Append(mErr, errors.New("static jobs can't be periodic or parameterized"))
+	}
+
+	for _, tg := range job.TaskGroups {
+		if len(tg.Networks) > 0 {
+			mErr = multierror.Append(mErr, fmt.Errorf("group %q: static jobs don't support network blocks", tg.Name))
+		}
+		if len(tg.Volumes) > 0 {
+			mErr = multierror.Append(mErr, fmt.Errorf("group %q: static jobs don't support volume blocks", tg.Name))
+		}
+		for _, service := range tg.Services {
+			if service.Provider == structs.ServiceProviderNomad {
+				mErr = multierror.Append(mErr, fmt.Errorf("group %q: static jobs don't support Nomad services", tg.Name))
+			}
+		}
+
+		for _, task := range tg.Tasks {
+			if len(task.Identities) > 0 {
+				mErr = multierror.Append(mErr, fmt.Errorf("task %q: static jobs don't support alternate workload identities", task.Name))
+			}
+			if task.Vault != nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("task %q: static jobs don't support Vault", task.Name))
+			}
+			if task.Resources != nil && (task.Resources.Cores > 0 || len(task.Resources.Networks) > 0 || len(task.Resources.Devices) > 0) {
+				mErr = multierror.Append(mErr, fmt.Errorf("task %q: static jobs only support cpu and memory resources", task.Name))
+			}
+			for _, service := range task.Services {
+				if service.Provider == structs.ServiceProviderNomad {
+					mErr = multierror.Append(mErr, fmt.Errorf("task %q: static jobs don't support Nomad services", task.Name))
+				}
+			}
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+func isStaticJobFile(name string) bool {
+	for _, ext := range staticJobExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
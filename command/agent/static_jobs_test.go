@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestAgent_parseStaticJobs(t *testing.T) {
+	ci.Parallel(t)
+
+	const job = `
+job "%s" {
+  group "app" {
+    task "app" {
+      driver = "raw_exec"
+      config {
+        command = "/bin/sleep"
+      }
+    }
+  }
+}
+`
+	writeJob := func(t *testing.T, dir, file, id string) {
+		t.Helper()
+		must.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(fmt.Sprintf(job, id)), 0o644))
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		dir := t.TempDir()
+		writeJob(t, dir, "b.nomad.hcl", "b")
+		writeJob(t, dir, "a.nomad", "a")
+		must.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o644))
+
+		jobs, err := parseStaticJobs(dir)
+		must.NoError(t, err)
+		must.Len(t, 2, jobs)
+		must.Eq(t, "a", jobs[0].ID)
+		must.Eq(t, "b", jobs[1].ID)
+		must.Eq(t, "default", jobs[0].Namespace)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		dir := t.TempDir()
+		writeJob(t, dir, "a.nomad", "a")
+		writeJob(t, dir, "b.nomad", "a")
+
+		_, err := parseStaticJobs(dir)
+		must.ErrorContains(t, err, "already defined")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		dir := t.TempDir()
+		must.NoError(t, os.WriteFile(filepath.Join(dir, "a.nomad"), []byte(`
+job "a" {
+  group "app" {
+    network {
+      port "http" {}
+    }
+    task "app" {
+      driver = "raw_exec"
+      config {
+        command = "/bin/sleep"
+      }
+    }
+  }
+}
+`), 0o644))
+
+		_, err := parseStaticJobs(dir)
+		must.ErrorContains(t, err, "static jobs don't support network blocks")
+	})
+
+	t.Run("missing dir", func(t *testing.T) {
+		_, err := parseStaticJobs(filepath.Join(t.TempDir(), "missing"))
+		must.Error(t, err)
+	})
+}
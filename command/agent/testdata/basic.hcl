@@ -44,9 +44,13 @@ advertise {
 client {
   enabled    = true
   state_dir  = "/tmp/client-state"
+  state_store = "sqlite"
   alloc_dir  = "/tmp/alloc"
+  alloc_dirs = ["/mnt/disk1/alloc", "/mnt/disk2/alloc"]
+  alloc_dir_placement = "round_robin"
   servers    = ["a.b.c:80", "127.0.0.1:1234"]
   node_class = "linux-medium-64bit"
+  max_allocs = 100
 
   meta {
     foo = "bar"
@@ -59,6 +63,12 @@ client {
     retry_interval = "15s"
   }
 
+  identity {
+    renew_min_wait        = "30s"
+    renew_jitter          = "2m"
+    max_parallel_renewals = 16
+  }
+
   options {
     foo = "bar"
     baz = "zip"
@@ -69,6 +79,10 @@ client {
     "/opt/myapp/bin" = "/bin"
   }
 
+  hook_timeouts {
+    artifacts = "1h"
+  }
+
   network_interface = "eth0"
   network_speed     = 100
   cpu_total_compute = 4444
@@ -89,21 +103,58 @@ client {
     collection_interval = "5s"
   }
 
-  gc_interval              = "6s"
-  gc_parallel_destroys     = 6
-  gc_disk_usage_threshold  = 82
-  gc_inode_usage_threshold = 91
-  gc_max_allocs            = 50
-  no_host_uuid             = false
-  disable_remote_exec      = true
+  gc_interval                 = "6s"
+  gc_parallel_destroys        = 6
+  gc_disk_usage_threshold     = 82
+  gc_inode_usage_threshold    = 91
+  gc_max_allocs               = 50
+  no_host_uuid                = false
+  identity_grace_period       = "15m"
+  hook_timeout                = "30m"
+  encrypt_secrets_dir         = true
+  secrets_key_rotation_period = "240h"
+  clock_skew_threshold        = "5s"
+  max_clock_skew              = "30s"
+  disable_remote_exec         = true
+  reverse_tunnel              = true
+  report_resource_usage       = true
 
   host_volume "tmp" {
     path = "/tmp"
   }
 
+  host_network "public" {
+    cidr           = "10.0.0.0/8"
+    reserved_ports = "22"
+
+    fallback {
+      interface      = "eth1"
+      reserved_ports = "22,80"
+    }
+  }
+
   cni_path              = "/tmp/cni_path"
   bridge_network_name   = "custom_bridge_name"
   bridge_network_subnet = "custom_bridge_subnet"
+
+  disk_quota {
+    enabled           = true
+    warning_threshold = 80
+    check_interval    = "1m"
+  }
+
+  dns_cache {
+    enabled   = true
+    ttl       = "1m"
+    max_stale = "2h"
+  }
+
+  alloc_hook_plugin "netadvertise" {
+    address  = "unix:///run/netadvertise.sock"
+    priority = 950
+    requires = ["network"]
+    timeout  = "30s"
+  }
 }
 
 server {
@@ -139,6 +190,7 @@ server {
   raft_multiplier               = 4
   enable_event_broker           = false
   event_buffer_size             = 200
+  enable_change_feed            = true
   job_default_priority          = 100
   job_max_priority              = 200
 
@@ -164,6 +216,66 @@ server {
     }
   }
 
+  scoring_plugin "locality" {
+    address   = "127.0.0.1:9190"
+    job_types = ["service"]
+    timeout   = "50ms"
+    ca_file   = "/path/to/ca"
+  }
+
+  service_sync "consul-east" {
+    provider   = "consul"
+    namespaces = ["default"]
+    tags       = ["public"]
+    interval   = "1m"
+
+    config {
+      address = "127.0.0.1:8500"
+    }
+  }
+
+  workload_ca {
+    cert_file    = "/path/to/workload-ca.pem"
+    key_file     = "/path/to/workload-ca-key.pem"
+    trust_domain = "nomad.example.com"
+  }
+
+  signer_plugin {
+    address = "unix:///run/nomad-kms-signer.sock"
+    timeout = "3s"
+  }
+
+  payload_store {
+    provider  = "s3"
+    threshold = "64KiB"
+
+    config {
+      bucket = "nomad-payloads"
+      region = "us-east-1"
+    }
+  }
+
+  job_lint {
+    missing_health_checks = "warn"
+    latest_image_tag      = "deny"
+  }
+
+  user_directory_lookup_drivers = ["exec", "java"]
+
+  job_metrics_labels {
+    labels     = ["job", "namespace"]
+    allow_jobs = ["api-*"]
+    other_jobs = "other"
+
+    rename {
+      job = "nomad_job"
+    }
+  }
+
+  namespace_unblock_weights {
+    prod = 3
+  }
+
   license_path = "/tmp/nomad.hclic"
 }
 
@@ -175,6 +287,12 @@ acl {
   token_min_expiration_ttl = "1h"
   token_max_expiration_ttl = "100h"
   replication_token        = "foobar"
+
+  workload_policy {
+    alloc     = "deny"
+    services  = "namespace"
+    variables = "read"
+  }
 }
 
 audit {
@@ -196,6 +314,11 @@ audit {
     stages     = ["*"]
     operations = ["*"]
   }
+
+  access {
+    enabled     = true
+    sample_rate = 0.5
+  }
 }
 
 telemetry {
@@ -206,6 +329,16 @@ telemetry {
   collection_interval        = "3s"
   publish_allocation_metrics = true
   publish_node_metrics       = true
+  otlp_endpoint              = "http://localhost:4318/v1/metrics"
+  otlp_interval              = "30s"
+
+  otlp_headers {
+    Authorization = "Bearer token"
+  }
+
+  otlp_resource_attributes {
+    "deployment.environment" = "prod"
+  }
 }
 
 leave_on_interrupt = true
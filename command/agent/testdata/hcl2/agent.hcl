@@ -0,0 +1,31 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: BUSL-1.1
+
+variable "region" {
+  default = "global"
+}
+
+variable "datacenter" {
+  description = "The datacenter of the agent"
+  default     = "dc1"
+}
+
+include = ["include.d"]
+
+region     = var.region
+datacenter = upper(var.datacenter)
+data_dir   = "${env("NOMAD_TEST_HCL2_DIR")}/data"
+
+client {
+  enabled = true
+
+  meta {
+    rack = "r1"
+    zone = "${var.region}-a"
+  }
+
+  host_volume "certs" {
+    path      = "/etc/ssl/certs"
+    read_only = true
+  }
+}
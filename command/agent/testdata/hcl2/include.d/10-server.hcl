@@ -0,0 +1,9 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: BUSL-1.1
+
+server {
+  enabled          = true
+  bootstrap_expect = 3
+}
+
+log_level = "INFO"
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/posener/complete"
 )
 
@@ -21,8 +22,11 @@ Usage: nomad agent-info [options]
 
   Display status information about the local agent.
 
+  Secrets of the agent configuration, such as tokens and keys, are redacted
+  unless the -full flag is set.
+
   When ACLs are enabled, this command requires a token with the 'agent:read'
-  capability.
+  capability, or a management token with the -full flag.
 
 General Options:
 
@@ -30,6 +34,10 @@ General Options:
 
 Agent Info Options:
 
+  -full
+    Output the agent configuration without redacting its secrets. Requires a
+    management token.
+
   -json
     Output the node in its JSON format.
 
@@ -46,6 +54,7 @@ func (c *AgentInfoCommand) Synopsis() string {
 func (c *AgentInfoCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
+			"-full": complete.PredictNothing,
 			"-json": complete.PredictNothing,
 			"-t":    complete.PredictAnything,
 		})
@@ -58,11 +67,12 @@ func (c *AgentInfoCommand) AutocompleteArgs() complete.Predictor {
 func (c *AgentInfoCommand) Name() string { return "agent-info" }
 
 func (c *AgentInfoCommand) Run(args []string) int {
-	var json bool
+	var json, full bool
 	var tmpl string
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&full, "full", false, "")
 	flags.BoolVar(&json, "json", false, "")
 	flags.StringVar(&tmpl, "t", "", "")
 
@@ -87,7 +97,12 @@ func (c *AgentInfoCommand) Run(args []string) int {
 	}
 
 	// Query the agent info
-	info, err := client.Agent().Self()
+	var info *api.AgentSelf
+	if full {
+		info, err = client.Agent().SelfFull()
+	} else {
+		info, err = client.Agent().Self()
+	}
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error querying agent info: %s", err))
 		return 1
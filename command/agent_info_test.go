@@ -43,6 +43,24 @@ func TestAgentInfoCommand_Run_JSON(t *testing.T) {
 	must.StrContains(t, out, `"config"`)
 }
 
+func TestAgentInfoCommand_Run_Full(t *testing.T) {
+	ci.Parallel(t)
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := cli.NewMockUi()
+	cmd := &AgentInfoCommand{Meta: Meta{Ui: ui}}
+
+	code := cmd.Run([]string{"-address=" + url, "-t", "{{.Redacted}}"})
+	must.Zero(t, code)
+	must.StrContains(t, ui.OutputWriter.String(), "true")
+	ui.OutputWriter.Reset()
+
+	code = cmd.Run([]string{"-address=" + url, "-full", "-t", "{{.Redacted}}"})
+	must.Zero(t, code)
+	must.StrContains(t, ui.OutputWriter.String(), "false")
+}
+
 func TestAgentInfoCommand_Run_Gotemplate(t *testing.T) {
 	ci.Parallel(t)
 	srv, _, url := testServer(t, false, nil)
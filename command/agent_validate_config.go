@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"strings"
+
+	flaghelper "github.com/hashicorp/nomad/helper/flags"
+	"github.com/posener/complete"
+)
+
+type AgentValidateConfigCommand struct {
+	Meta
+}
+
+func (c *AgentValidateConfigCommand) Help() string {
+	helpText := `
+Usage: nomad agent validate-config [options] [<config_path>...]
+
+  Validate the configuration of a Nomad agent without starting it. The
+  configuration files are loaded and merged the same way as by the agent, and
+  any error is reported with the file, line, and column where it occurs.
+
+  Configuration files written in HCL may declare variables, which are set by
+  the NOMAD_AGENT_VAR_<name> environment variables, and include other files
+  and directories. This command does not require an ACL token.
+
+  Returns 0 if the configuration is valid, or 1 if there are problems.
+
+Validate Config Options:
+
+  -config=<path>
+    The path to either a single config file or a directory of config files
+    to validate. May be specified multiple times. Paths given as arguments are
+    validated after the paths given with this option.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AgentValidateConfigCommand) Synopsis() string {
+	return "Validate the configuration of an agent"
+}
+
+func (c *AgentValidateConfigCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-config": complete.PredictOr(
+			complete.PredictFiles("*.hcl"),
+			complete.PredictFiles("*.json"),
+			complete.PredictDirs("*"),
+		),
+	}
+}
+
+func (c *AgentValidateConfigCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictOr(
+		complete.PredictFiles("*.hcl"),
+		complete.PredictFiles("*.json"),
+		complete.PredictDirs("*"),
+	)
+}
+
+func (c *AgentValidateConfigCommand) Name() string { return "agent validate-config" }
+
+func (c *AgentValidateConfigCommand) Run(args []string) int {
+	var configPath []string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.Var((*flaghelper.StringFlag)(&configPath), "config", "")
+	if err := flags.Parse(args); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	configPath = append(configPath, flags.Args()...)
+	if len(configPath) < 1 {
+		c.Ui.Error("Must specify at least one config file or directory")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	return validateAgentConfig(c.Ui, configPath)
+}
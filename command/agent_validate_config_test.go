@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestAgentValidateConfigCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &AgentValidateConfigCommand{}
+}
+
+func TestAgentValidateConfigCommand_Valid(t *testing.T) {
+	ci.Parallel(t)
+	dir := t.TempDir()
+
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "agent.hcl"), []byte(`
+variable "data_dir" {
+  default = "/opt/nomad"
+}
+
+data_dir = var.data_dir
+include  = ["client.d"]
+`), 0o644))
+	must.NoError(t, os.Mkdir(filepath.Join(dir, "client.d"), 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "client.d", "client.hcl"), []byte(`
+client {
+  enabled = true
+}
+`), 0o644))
+
+	ui := cli.NewMockUi()
+	cmd := &AgentValidateConfigCommand{Meta: Meta{Ui: ui}}
+	code := cmd.Run([]string{"-config", filepath.Join(dir, "agent.hcl")})
+	must.Zero(t, code)
+	must.StrContains(t, ui.OutputWriter.String(), "Configuration is valid!")
+}
+
+func TestAgentValidateConfigCommand_Fails(t *testing.T) {
+	ci.Parallel(t)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "agent.hcl")
+	must.NoError(t, os.WriteFile(path, []byte(`
+data_dir = "/opt/nomad"
+client {
+  enabled = var.enabled
+}
+`), 0o644))
+
+	ui := cli.NewMockUi()
+	cmd := &AgentValidateConfigCommand{Meta: Meta{Ui: ui}}
+
+	// no paths
+	must.One(t, cmd.Run(nil))
+	must.StrContains(t, ui.ErrorWriter.String(), "Must specify at least one config file or directory")
+	ui.ErrorWriter.Reset()
+
+	// errors are reported with their location
+	must.One(t, cmd.Run([]string{path}))
+	must.StrContains(t, ui.ErrorWriter.String(), "agent.hcl:4,16-24: Unsupported attribute")
+}
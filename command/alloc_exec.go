@@ -33,7 +33,15 @@ func (l *AllocExecCommand) Help() string {
 	helpText := `
 Usage: nomad alloc exec [options] <allocation> <command>
 
-  Run command inside the environment of the given allocation and task.
+  Run command inside the environment of the given allocation and task. Any
+  task of the allocation can be targeted, including the sidecar tasks Nomad
+  injects for Consul Connect, if its task driver supports exec.
+
+  The exit code of the command is returned as the exit code of this command,
+  so it can be used in scripts with -tty=false:
+
+      $ nomad alloc exec -tty=false -task connect-proxy-api <allocation> \
+          cat /secrets/envoy_bootstrap.json
 
   When ACLs are enabled, this command requires a token with the 'alloc-exec',
   'read-job', and 'list-jobs' capabilities for the allocation's namespace. If
@@ -56,9 +64,10 @@ Exec Specific Options:
   -i
     Pass stdin to the container, defaults to true.  Pass -i=false to disable.
 
-  -t
+  -t, -tty
     Allocate a pseudo-tty, defaults to true if stdin is detected to be a tty session.
-    Pass -t=false to disable explicitly.
+    Pass -tty=false to disable explicitly, so the output of the command is not
+    altered by a terminal.
 
   -e <escape_char>
     Sets the escape character for sessions with a pty (default: '~').  The escape
@@ -76,11 +85,12 @@ func (l *AllocExecCommand) Synopsis() string {
 func (l *AllocExecCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(l.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"--task": complete.PredictAnything,
-			"-job":   complete.PredictAnything,
-			"-i":     complete.PredictNothing,
-			"-t":     complete.PredictNothing,
-			"-e":     complete.PredictSet("none", "~"),
+			"-task": complete.PredictAnything,
+			"-job":  complete.PredictAnything,
+			"-i":    complete.PredictNothing,
+			"-t":    complete.PredictNothing,
+			"-tty":  complete.PredictNothing,
+			"-e":    complete.PredictSet("none", "~"),
 		})
 }
 
@@ -110,6 +120,7 @@ func (l *AllocExecCommand) Run(args []string) int {
 	flags.BoolVar(&job, "job", false, "")
 	flags.BoolVar(&stdinOpt, "i", true, "")
 	flags.BoolVar(&ttyOpt, "t", isTty(), "")
+	flags.BoolVar(&ttyOpt, "tty", isTty(), "")
 	flags.StringVar(&escapeChar, "e", "~", "")
 	flags.StringVar(&task, "task", "", "")
 
@@ -77,6 +77,11 @@ func TestAllocExecCommand_Fails(t *testing.T) {
 			[]string{"-address=" + url, "-e", "es", "26470238-5CF2-438F-8772-DC67CFB0705C", "/bin/bash"},
 			`-e requires 'none' or a single character`,
 		},
+		{
+			"tty without stdin",
+			[]string{"-address=" + url, "-tty", "-i=false", "26470238-5CF2-438F-8772-DC67CFB0705C", "/bin/bash"},
+			`-i must be enabled if running with tty`,
+		},
 	}
 
 	for _, c := range cases {
@@ -78,6 +78,17 @@ FS Specific Options:
 
   -c
     Sets the tail location in number of bytes relative to the end of the file.
+
+  -archive
+    Write the directory at the given path to stdout as a gzipped tar archive.
+
+  -include <patterns>
+    Comma separated list of glob patterns of the files to archive. Patterns are
+    matched against the paths relative to the archived directory. Defaults to
+    all files.
+
+  -exclude <patterns>
+    Comma separated list of glob patterns of the files to skip when archiving.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -97,6 +108,9 @@ func (f *AllocFSCommand) AutocompleteFlags() complete.Flags {
 			"-tail":    complete.PredictNothing,
 			"-n":       complete.PredictAnything,
 			"-c":       complete.PredictAnything,
+			"-archive": complete.PredictNothing,
+			"-include": complete.PredictAnything,
+			"-exclude": complete.PredictAnything,
 		})
 }
 
@@ -118,8 +132,9 @@ func (f *AllocFSCommand) AutocompleteArgs() complete.Predictor {
 func (f *AllocFSCommand) Name() string { return "alloc fs" }
 
 func (f *AllocFSCommand) Run(args []string) int {
-	var verbose, machine, job, stat, tail, follow bool
+	var verbose, machine, job, stat, tail, follow, archive bool
 	var numLines, numBytes int64
+	var include, exclude string
 
 	flags := f.Meta.FlagSet(f.Name(), FlagSetClient)
 	flags.Usage = func() { f.Ui.Output(f.Help()) }
@@ -131,6 +146,9 @@ func (f *AllocFSCommand) Run(args []string) int {
 	flags.BoolVar(&tail, "tail", false, "")
 	flags.Int64Var(&numLines, "n", -1, "")
 	flags.Int64Var(&numBytes, "c", -1, "")
+	flags.BoolVar(&archive, "archive", false, "")
+	flags.StringVar(&include, "include", "", "")
+	flags.StringVar(&exclude, "exclude", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -153,6 +171,17 @@ func (f *AllocFSCommand) Run(args []string) int {
 		return 1
 	}
 
+	if archive && (stat || follow || tail) {
+		f.Ui.Error("The -archive flag can't be used with -stat, -f, or -tail")
+		f.Ui.Error(commandErrorText(f))
+		return 1
+	}
+	if !archive && (include != "" || exclude != "") {
+		f.Ui.Error("The -include and -exclude flags require -archive")
+		f.Ui.Error(commandErrorText(f))
+		return 1
+	}
+
 	path := "/"
 	if len(args) == 2 {
 		path = args[1]
@@ -245,6 +274,28 @@ func (f *AllocFSCommand) Run(args []string) int {
 		return 0
 	}
 
+	// If we want an archive, stream it to stdout and exit.
+	if archive {
+		if !file.IsDir {
+			f.Ui.Error(fmt.Sprintf("Path %q is not a directory", path))
+			return 1
+		}
+
+		r, err := client.AllocFS().Archive(alloc, path,
+			stringToSlice(include), stringToSlice(exclude), 0, nil)
+		if err != nil {
+			f.Ui.Error(fmt.Sprintf("Error archiving alloc dir: %s", err))
+			return 1
+		}
+		defer r.Close()
+
+		if _, err := io.Copy(os.Stdout, r); err != nil {
+			f.Ui.Error(fmt.Sprintf("Error writing archive: %s", err))
+			return 1
+		}
+		return 0
+	}
+
 	// Determine if the path is a file or a directory.
 	if file.IsDir {
 		// We have a directory, list it.
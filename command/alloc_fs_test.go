@@ -54,6 +54,23 @@ func TestFSCommand_Fails(t *testing.T) {
 
 	ui.ErrorWriter.Reset()
 
+	// Fails on invalid archive flags
+	code = cmd.Run([]string{"-archive", "-stat", "foobar"})
+	must.One(t, code)
+
+	out = ui.ErrorWriter.String()
+	must.StrContains(t, out, "-archive flag can't be used")
+
+	ui.ErrorWriter.Reset()
+
+	code = cmd.Run([]string{"-include=*.log", "foobar"})
+	must.One(t, code)
+
+	out = ui.ErrorWriter.String()
+	must.StrContains(t, out, "require -archive")
+
+	ui.ErrorWriter.Reset()
+
 	// Fails on connection failure
 	code = cmd.Run([]string{"-address=nope", "foobar"})
 	must.One(t, code)
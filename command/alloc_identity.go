@@ -0,0 +1,339 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/posener/complete"
+)
+
+type AllocIdentityCommand struct {
+	Meta
+}
+
+func (c *AllocIdentityCommand) Help() string {
+	helpText := `
+Usage: nomad alloc identity [options] <allocation>
+
+  Outputs the decoded claims and expiration of the signed workload identities
+  of a running allocation. The signed identities themselves are never output.
+
+  When ACLs are enabled, this command requires a token with the 'read-job'
+  capability for the allocation's namespace.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault) + `
+
+Identity Specific Options:
+
+  -task <task-name>
+    Only output the identities of the task, including the identities of its
+    services.
+
+  -name <identity-name>
+    Only output the identities with the name.
+
+  -follow
+    After outputting the identities, stream the events of the signatures,
+    renewals, and expirations of the identities until interrupted.
+
+  -verbose
+    Show full information.
+
+  -json
+    Output the identities in a JSON format.
+
+  -t
+    Format and display the identities using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AllocIdentityCommand) Synopsis() string {
+	return "Output the workload identities of an allocation"
+}
+
+func (c *AllocIdentityCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-task":    complete.PredictAnything,
+			"-name":    complete.PredictAnything,
+			"-follow":  complete.PredictNothing,
+			"-verbose": complete.PredictNothing,
+			"-json":    complete.PredictNothing,
+			"-t":       complete.PredictAnything,
+		})
+}
+
+func (c *AllocIdentityCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := c.Meta.Client()
+		if err != nil {
+			return nil
+		}
+		resp, _, err := client.Search().PrefixSearch(a.Last, contexts.Allocs, nil)
+		if err != nil {
+			return nil
+		}
+		return resp.Matches[contexts.Allocs]
+	})
+}
+
+func (c *AllocIdentityCommand) Name() string { return "alloc identity" }
+
+func (c *AllocIdentityCommand) Run(args []string) int {
+	var follow, json, verbose bool
+	var task, name, tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&task, "task", "", "")
+	flags.StringVar(&name, "name", "", "")
+	flags.BoolVar(&follow, "follow", false, "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got only one argument
+	args = flags.Args()
+	if numArgs := len(args); numArgs < 1 {
+		c.Ui.Error("An allocation ID is required")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	} else if numArgs > 1 {
+		c.Ui.Error("This command takes one argument (allocation ID)")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %v", err))
+		return 1
+	}
+
+	allocID := args[0]
+	// Truncate the id unless full length is requested
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Query the allocation info
+	if len(allocID) == 1 {
+		c.Ui.Error("Alloc ID must contain at least two characters.")
+		return 1
+	}
+
+	allocID = sanitizeUUIDPrefix(allocID)
+	allocations, _, err := client.Allocations().PrefixList(allocID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying allocation: %v", err))
+		return 1
+	}
+	if len(allocations) == 0 {
+		c.Ui.Error(fmt.Sprintf("No allocation(s) with prefix or id %q found", allocID))
+		return 1
+	}
+	if len(allocations) > 1 {
+		out := formatAllocListStubs(allocations, verbose, length)
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple allocations\n\n%s", out))
+		return 1
+	}
+
+	alloc := &api.Allocation{ID: allocations[0].ID, Namespace: allocations[0].Namespace}
+	q := &api.QueryOptions{Namespace: alloc.Namespace, Params: map[string]string{}}
+	if task != "" {
+		q.Params["task"] = task
+	}
+	if name != "" {
+		q.Params["name"] = name
+	}
+	identities, err := client.Allocations().Identities(alloc, q)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying allocation identities: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, identities)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+	} else if len(identities) == 0 {
+		c.Ui.Output("No identities found")
+	} else {
+		c.Ui.Output(formatAllocIdentities(identities))
+	}
+
+	if !follow {
+		return 0
+	}
+	return c.followIdentities(client, alloc, identities, task != "" || name != "")
+}
+
+// followIdentities outputs the lifecycle events of the identities of the
+// allocation until interrupted. If filtered is set, only the events of the
+// identities are output.
+func (c *AllocIdentityCommand) followIdentities(client *api.Client, alloc *api.Allocation, identities []*api.AllocIdentity, filtered bool) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalCh)
+	go func() {
+		select {
+		case <-signalCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	topics := map[api.Topic][]string{api.TopicWorkloadIdentity: {alloc.ID}}
+	eventsCh, err := client.EventStream().Stream(ctx, topics, 0, &api.QueryOptions{Namespace: alloc.Namespace})
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error streaming identity events: %s", err))
+		return 1
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+		case events, ok := <-eventsCh:
+			if !ok {
+				return 0
+			}
+			if events.Err != nil {
+				if ctx.Err() != nil {
+					return 0
+				}
+				c.Ui.Error(fmt.Sprintf("Error streaming identity events: %s", events.Err))
+				return 1
+			}
+			if events.IsHeartbeat() {
+				continue
+			}
+
+			for _, event := range events.Events {
+				idEvent, err := event.WorkloadIdentity()
+				if err != nil || idEvent == nil {
+					continue
+				}
+				if filtered && !identityEventMatches(idEvent, identities) {
+					continue
+				}
+				c.Ui.Output(formatAllocIdentityEvent(time.Now(), idEvent))
+			}
+		}
+	}
+}
+
+// identityEventMatches returns true if the event is about one of the
+// identities.
+func identityEventMatches(event *api.WorkloadIdentityEvent, identities []*api.AllocIdentity) bool {
+	for _, id := range identities {
+		if id.Name != event.IdentityName {
+			continue
+		}
+		if event.WorkloadType == 0 && id.Service == "" && id.Task == event.WorkloadIdentifier {
+			return true
+		}
+		if event.WorkloadType != 0 && id.Service == event.WorkloadIdentifier {
+			return true
+		}
+	}
+	return false
+}
+
+func formatAllocIdentities(identities []*api.AllocIdentity) string {
+	blocks := make([]string, 0, len(identities))
+	for _, id := range identities {
+		task := id.Task
+		if task == "" {
+			task = "(group)"
+		}
+		expires := "never"
+		if !id.ExpiresAt.IsZero() {
+			expires = fmt.Sprintf("%s (%s)", formatTime(id.ExpiresAt),
+				prettyTimeDiff(id.ExpiresAt, time.Now()))
+		}
+
+		list := []string{
+			fmt.Sprintf("Name|%s", id.Name),
+			fmt.Sprintf("Task|%s", task),
+		}
+		if id.Service != "" {
+			list = append(list, fmt.Sprintf("Service|%s", id.Service))
+		}
+		list = append(list,
+			fmt.Sprintf("Audience|%s", strings.Join(id.Audience, ",")),
+			fmt.Sprintf("Issued At|%s", formatTime(id.IssuedAt)),
+			fmt.Sprintf("Expires At|%s", expires),
+		)
+
+		claims := make([]string, 0, len(id.Claims))
+		for claim, value := range id.Claims {
+			claims = append(claims, fmt.Sprintf("%s|%s", claim, formatClaim(value)))
+		}
+		sort.Strings(claims)
+
+		blocks = append(blocks, formatKV(list)+"\n\nClaims\n"+formatKV(claims))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// formatClaim formats the value of a JWT claim decoded from JSON.
+func formatClaim(value any) string {
+	switch v := value.(type) {
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, value := range v {
+			values = append(values, formatClaim(value))
+		}
+		return strings.Join(values, ",")
+	case float64:
+		// Numeric date claims are decoded as floats
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%v", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func formatAllocIdentityEvent(now time.Time, event *api.WorkloadIdentityEvent) string {
+	workload := "task"
+	if event.WorkloadType != 0 {
+		workload = "service"
+	}
+	out := fmt.Sprintf("%s: %s: identity %q of %s %q",
+		formatTime(now), event.Type, event.IdentityName, workload, event.WorkloadIdentifier)
+	if !event.Expiration.IsZero() {
+		out += fmt.Sprintf(", expires at %s", formatTime(event.Expiration))
+	}
+	if event.Error != "" {
+		out += fmt.Sprintf(": %s", event.Error)
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestAllocIdentityCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = (*AllocIdentityCommand)(nil)
+}
+
+func TestAllocIdentityCommand_Fails(t *testing.T) {
+	ci.Parallel(t)
+	srv, _, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := cli.NewMockUi()
+	cmd := &AllocIdentityCommand{Meta: Meta{Ui: ui}}
+
+	// fails on misuse
+	code := cmd.Run([]string{"some", "bad", "args"})
+	must.One(t, code)
+	must.StrContains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+
+	ui.ErrorWriter.Reset()
+
+	// fails on connection failure
+	code = cmd.Run([]string{"-address=nope", "foobar"})
+	must.One(t, code)
+	must.StrContains(t, ui.ErrorWriter.String(), "Error querying allocation")
+
+	ui.ErrorWriter.Reset()
+
+	// fails on missing allocation
+	code = cmd.Run([]string{"-address=" + url, "26470238-5CF2-438F-8772-DC67CFB0705C"})
+	must.One(t, code)
+	must.StrContains(t, ui.ErrorWriter.String(), "No allocation(s) with prefix or id")
+}
+
+func TestAllocIdentityCommand_formatAllocIdentities(t *testing.T) {
+	ci.Parallel(t)
+
+	issued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	out := formatAllocIdentities([]*api.AllocIdentity{
+		{
+			Name:     "vault_default",
+			Task:     "web",
+			Audience: []string{"vault.io"},
+			Claims: map[string]any{
+				"aud":                 []any{"vault.io"},
+				"iat":                 float64(issued.Unix()),
+				"nomad_job_id":        "example",
+				"nomad_allocation_id": "d3e3b2a1",
+			},
+			IssuedAt:  issued,
+			ExpiresAt: issued.Add(time.Hour),
+		},
+		{
+			Name:    "consul-service_api",
+			Service: "api",
+		},
+	})
+
+	must.StrContains(t, out, "Name       = vault_default")
+	must.StrContains(t, out, "Task       = web")
+	must.StrContains(t, out, "Audience   = vault.io")
+	must.StrContains(t, out, "Issued At  = 2026-01-02T03:04:05Z")
+	must.StrContains(t, out, "Expires At = 2026-01-02T04:04:05Z (")
+	must.StrContains(t, out, "iat                 = 1767323045")
+	must.StrContains(t, out, "nomad_job_id        = example")
+
+	// Group service identities have no task and don't expire
+	must.StrContains(t, out, "Task       = (group)")
+	must.StrContains(t, out, "Service    = api")
+	must.StrContains(t, out, "Expires At = never")
+}
+
+func TestAllocIdentityCommand_identityEventMatches(t *testing.T) {
+	ci.Parallel(t)
+
+	identities := []*api.AllocIdentity{
+		{Name: "vault_default", Task: "web"},
+		{Name: "consul-service_api", Task: "web", Service: "api"},
+	}
+
+	must.True(t, identityEventMatches(&api.WorkloadIdentityEvent{
+		IdentityName: "vault_default", WorkloadIdentifier: "web",
+	}, identities))
+	must.True(t, identityEventMatches(&api.WorkloadIdentityEvent{
+		IdentityName: "consul-service_api", WorkloadIdentifier: "api", WorkloadType: 1,
+	}, identities))
+	must.False(t, identityEventMatches(&api.WorkloadIdentityEvent{
+		IdentityName: "vault_default", WorkloadIdentifier: "sidecar",
+	}, identities))
+	must.False(t, identityEventMatches(&api.WorkloadIdentityEvent{
+		IdentityName: "consul-service_api", WorkloadIdentifier: "web",
+	}, identities))
+}
@@ -304,6 +304,10 @@ func formatAllocBasicInfo(alloc *api.Allocation, client *api.Client, uuidLength
 		}
 	}
 
+	if ms := alloc.MigrationStatus; ms != nil && !ms.Complete {
+		basic = append(basic, fmt.Sprintf("Data Migration|%s", formatAllocMigrationStatus(ms)))
+	}
+
 	if alloc.RescheduleTracker != nil && len(alloc.RescheduleTracker.Events) > 0 {
 		attempts, total := alloc.RescheduleInfo(time.Unix(0, alloc.ModifyTime))
 		// Show this section only if the reschedule policy limits the number of attempts
@@ -336,6 +340,25 @@ func formatAllocBasicInfo(alloc *api.Allocation, client *api.Client, uuidLength
 	return formatKV(basic), nil
 }
 
+// formatAllocMigrationStatus formats the progress of an ongoing or failed
+// migration of the previous allocation's data.
+func formatAllocMigrationStatus(ms *api.AllocMigrationStatus) string {
+	migrated := humanize.IBytes(uint64(ms.BytesMigrated))
+	if ms.Error != "" {
+		return fmt.Sprintf("failed after %s: %s", migrated, ms.Error)
+	}
+	if ms.BytesTotal <= 0 {
+		return fmt.Sprintf("%s migrated", migrated)
+	}
+
+	percent := min(float64(ms.BytesMigrated)/float64(ms.BytesTotal)*100, 100)
+	out := fmt.Sprintf("%s/%s (%.0f%%)", migrated, humanize.IBytes(uint64(ms.BytesTotal)), percent)
+	if ms.ETA > 0 {
+		out += fmt.Sprintf(", %s left", ms.ETA.Round(time.Second))
+	}
+	return out
+}
+
 func formatAllocNetworkInfo(alloc *api.Allocation) string {
 	nw := alloc.AllocatedResources.Shared.Networks[0]
 	addrs := []string{"Label|Dynamic|Address"}
@@ -849,7 +872,7 @@ FOUND:
 	for _, volMount := range task.VolumeMounts {
 		volReq := tg.Volumes[*volMount.Volume]
 		switch volReq.Type {
-		case api.CSIVolumeTypeHost:
+		case api.CSIVolumeTypeHost, api.CSIVolumeTypeLocal:
 			hostVolumesOutput = append(hostVolumesOutput,
 				fmt.Sprintf("%s|%v", volReq.Name, *volMount.ReadOnly))
 		case api.CSIVolumeTypeCSI:
@@ -26,4 +26,20 @@ node_pool "example" {
   #   scheduler_algorithm             = "spread"
   #   memory_oversubscription_enabled = true
   # }
+
+  # The heartbeat configuration of the nodes in this node pool. Unset values
+  # use the server configuration.
+  #
+  # * min_heartbeat_ttl and heartbeat_grace override the server options of the
+  #   same name for the nodes in the pool.
+  #
+  # * max_client_disconnect is applied to the task groups of the jobs in the
+  #   pool that don't set max_client_disconnect or
+  #   stop_after_client_disconnect when the jobs are registered.
+
+  # heartbeat {
+  #   min_heartbeat_ttl     = "10s"
+  #   heartbeat_grace       = "10s"
+  #   max_client_disconnect = "1h"
+  # }
 }
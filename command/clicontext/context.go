@@ -0,0 +1,186 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package clicontext stores the named cluster contexts of the CLI, so
+// operators working with multiple clusters or regions can switch between them
+// with "nomad context use" instead of exporting NOMAD_ADDR and NOMAD_TOKEN.
+//
+// The contexts are stored in a contexts.json file of the CLI configuration
+// directory, while their tokens are stored in a CredentialStore.
+package clicontext
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+const (
+	// ConfigDirEnv is the environment variable overriding the CLI
+	// configuration directory.
+	ConfigDirEnv = "NOMAD_CLI_CONFIG_DIR"
+
+	// ContextEnv is the environment variable selecting the context to use
+	// instead of the current context.
+	ContextEnv = "NOMAD_CONTEXT"
+
+	contextsFile = "contexts.json"
+)
+
+// validName is the format of the context names.
+var validName = regexp.MustCompile("^[a-zA-Z0-9-_.]{1,128}$")
+
+// Context is the connection configuration of a cluster.
+type Context struct {
+	Name          string `json:"-"`
+	Address       string `json:"address,omitempty"`
+	Region        string `json:"region,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	CACert        string `json:"ca_cert,omitempty"`
+	CAPath        string `json:"ca_path,omitempty"`
+	ClientCert    string `json:"client_cert,omitempty"`
+	ClientKey     string `json:"client_key,omitempty"`
+	TLSServerName string `json:"tls_server_name,omitempty"`
+	Insecure      bool   `json:"insecure,omitempty"`
+}
+
+// Config is the content of the contexts file.
+type Config struct {
+	// Current is the name of the context used by the CLI, unless overridden
+	// by the NOMAD_CONTEXT environment variable.
+	Current string `json:"current,omitempty"`
+
+	// CredentialHelper is the name of the Docker credential helper storing
+	// the tokens of the contexts, such as "osxkeychain" or "pass". The tokens
+	// are stored in a file of the configuration directory if empty.
+	CredentialHelper string `json:"credential_helper,omitempty"`
+
+	Contexts map[string]*Context `json:"contexts,omitempty"`
+}
+
+// Store reads and writes the contexts of a configuration directory.
+type Store struct {
+	dir string
+}
+
+// NewStore returns the store of the configuration directory, which defaults
+// to the nomad directory of the user configuration directory.
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		dir = os.Getenv(ConfigDirEnv)
+	}
+	if dir == "" {
+		userDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the CLI configuration directory: %w", err)
+		}
+		dir = filepath.Join(userDir, "nomad")
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the configuration directory of the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Load returns the configuration of the contexts, which is empty if no
+// context has been created yet.
+func (s *Store) Load() (*Config, error) {
+	config := &Config{Contexts: make(map[string]*Context)}
+
+	buf, err := os.ReadFile(filepath.Join(s.dir, contextsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read contexts: %w", err)
+	}
+
+	if err := json.Unmarshal(buf, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(s.dir, contextsFile), err)
+	}
+	if config.Contexts == nil {
+		config.Contexts = make(map[string]*Context)
+	}
+	for name, c := range config.Contexts {
+		c.Name = name
+	}
+	return config, nil
+}
+
+// Save writes the configuration of the contexts.
+func (s *Store) Save(config *Config) error {
+	buf, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(s.dir, contextsFile), buf)
+}
+
+// Active returns the context used by the CLI, which is the context named by
+// NOMAD_CONTEXT if set and the current context otherwise. It returns nil if
+// no context is in use.
+func (s *Store) Active() (*Context, error) {
+	config, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	name := os.Getenv(ContextEnv)
+	if name == "" {
+		name = config.Current
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	c, ok := config.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q does not exist", name)
+	}
+	return c, nil
+}
+
+// Credentials returns the store of the tokens of the contexts.
+func (s *Store) Credentials(config *Config) CredentialStore {
+	if config.CredentialHelper != "" {
+		return &helperCredentialStore{helper: config.CredentialHelper}
+	}
+	return &fileCredentialStore{path: filepath.Join(s.dir, credentialsFile)}
+}
+
+// Names returns the sorted names of the contexts.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateName returns an error if the name can't be used for a context.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid context name %q: must be 1-128 alphanumeric, dash, underscore, or dot characters", name)
+	}
+	return nil
+}
+
+// writeFile atomically writes a file only readable by the user, creating its
+// directory if needed.
+func writeFile(path string, buf []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
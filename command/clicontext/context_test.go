@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package clicontext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestStore(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := filepath.Join(t.TempDir(), "nomad")
+	store, err := NewStore(dir)
+	must.NoError(t, err)
+
+	// No context is in use before any is created
+	config, err := store.Load()
+	must.NoError(t, err)
+	must.MapEmpty(t, config.Contexts)
+	active, err := store.Active()
+	must.NoError(t, err)
+	must.Nil(t, active)
+
+	config.Contexts["prod-eu"] = &Context{Address: "https://eu.example.com:4646", Region: "eu"}
+	config.Contexts["dev"] = &Context{Address: "http://127.0.0.1:4646"}
+	config.Current = "prod-eu"
+	must.NoError(t, store.Save(config))
+
+	info, err := os.Stat(filepath.Join(dir, contextsFile))
+	must.NoError(t, err)
+	must.Eq(t, os.FileMode(0o600), info.Mode().Perm())
+
+	config, err = store.Load()
+	must.NoError(t, err)
+	must.Eq(t, []string{"dev", "prod-eu"}, config.Names())
+
+	active, err = store.Active()
+	must.NoError(t, err)
+	must.Eq(t, &Context{Name: "prod-eu", Address: "https://eu.example.com:4646", Region: "eu"}, active)
+
+	// Missing contexts are reported
+	config.Current = "missing"
+	must.NoError(t, store.Save(config))
+	_, err = store.Active()
+	must.EqError(t, err, `context "missing" does not exist`)
+}
+
+func TestValidateName(t *testing.T) {
+	ci.Parallel(t)
+
+	must.NoError(t, ValidateName("prod-eu_1.a"))
+	must.ErrorContains(t, ValidateName(""), "invalid context name")
+	must.ErrorContains(t, ValidateName("prod/eu"), "invalid context name")
+}
@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package clicontext
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	credentialsFile = "credentials.json"
+
+	// helperPrefix is the prefix of the executables implementing the Docker
+	// credential helper protocol, such as docker-credential-osxkeychain,
+	// docker-credential-pass, and docker-credential-secretservice.
+	helperPrefix = "docker-credential-"
+
+	// helperUsername is the username of the credentials stored with a helper.
+	helperUsername = "nomad"
+)
+
+// CredentialStore stores the ACL tokens of the contexts.
+type CredentialStore interface {
+	// Get returns the token of the context, or an empty string if none is
+	// stored.
+	Get(context string) (string, error)
+
+	// Store sets the token of the context.
+	Store(context, token string) error
+
+	// Erase removes the token of the context, if any.
+	Erase(context string) error
+}
+
+// fileCredentialStore stores the tokens in a JSON file only readable by the
+// user.
+type fileCredentialStore struct {
+	path string
+}
+
+func (s *fileCredentialStore) load() (map[string]string, error) {
+	tokens := make(map[string]string)
+	buf, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if err := json.Unmarshal(buf, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return tokens, nil
+}
+
+func (s *fileCredentialStore) save(tokens map[string]string) error {
+	buf, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(s.path, buf)
+}
+
+func (s *fileCredentialStore) Get(context string) (string, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return tokens[context], nil
+}
+
+func (s *fileCredentialStore) Store(context, token string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[context] = token
+	return s.save(tokens)
+}
+
+func (s *fileCredentialStore) Erase(context string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[context]; !ok {
+		return nil
+	}
+	delete(tokens, context)
+	return s.save(tokens)
+}
+
+// helperCredentialStore stores the tokens with a Docker credential helper,
+// so they can be kept in the macOS keychain, pass, or the Secret Service of
+// the desktop. See https://github.com/docker/docker-credential-helpers
+type helperCredentialStore struct {
+	helper string
+}
+
+// helperCredentials is the payload exchanged with credential helpers.
+type helperCredentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// serverURL returns the key of the token of the context in the helper.
+func serverURL(context string) string {
+	return "nomad://" + context
+}
+
+func (s *helperCredentialStore) run(action, input string) ([]byte, error) {
+	helper := helperPrefix + s.helper
+	cmd := exec.Command(helper, action)
+	cmd.Stdin = strings.NewReader(input)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(string(out))
+		}
+		return out, fmt.Errorf("%s %s failed: %v: %s", helper, action, err, msg)
+	}
+	return out, nil
+}
+
+func (s *helperCredentialStore) Get(context string) (string, error) {
+	out, err := s.run("get", serverURL(context))
+	if err != nil {
+		// Helpers report missing credentials on their output
+		if strings.Contains(string(out), "credentials not found") {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var creds helperCredentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return "", fmt.Errorf("failed to parse the output of %s%s: %w", helperPrefix, s.helper, err)
+	}
+	return creds.Secret, nil
+}
+
+func (s *helperCredentialStore) Store(context, token string) error {
+	buf, err := json.Marshal(&helperCredentials{
+		ServerURL: serverURL(context),
+		Username:  helperUsername,
+		Secret:    token,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.run("store", string(buf))
+	return err
+}
+
+func (s *helperCredentialStore) Erase(context string) error {
+	out, err := s.run("erase", serverURL(context))
+	if err != nil && !strings.Contains(string(out), "credentials not found") {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package clicontext
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func testCredentialStore(t *testing.T, store CredentialStore) {
+	token, err := store.Get("prod-eu")
+	must.NoError(t, err)
+	must.Eq(t, "", token)
+
+	must.NoError(t, store.Store("prod-eu", "secret"))
+	must.NoError(t, store.Store("dev", "dev-secret"))
+	token, err = store.Get("prod-eu")
+	must.NoError(t, err)
+	must.Eq(t, "secret", token)
+
+	must.NoError(t, store.Erase("prod-eu"))
+	must.NoError(t, store.Erase("prod-eu"))
+	token, err = store.Get("prod-eu")
+	must.NoError(t, err)
+	must.Eq(t, "", token)
+
+	token, err = store.Get("dev")
+	must.NoError(t, err)
+	must.Eq(t, "dev-secret", token)
+}
+
+func TestFileCredentialStore(t *testing.T) {
+	ci.Parallel(t)
+
+	path := filepath.Join(t.TempDir(), credentialsFile)
+	testCredentialStore(t, &fileCredentialStore{path: path})
+
+	info, err := os.Stat(path)
+	must.NoError(t, err)
+	must.Eq(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+// testHelper is a Docker credential helper storing the credentials in files
+// of the directory set in its environment.
+const testHelper = `#!/bin/sh
+read -r input
+key=$(echo "$input" | sed -e 's|.*nomad://\([^"]*\).*|\1|')
+case "$1" in
+  store) echo "$input" > "$CREDS_DIR/$key" ;;
+  get)
+    if [ ! -f "$CREDS_DIR/$key" ]; then
+      echo "credentials not found in native keychain"
+      exit 1
+    fi
+    cat "$CREDS_DIR/$key" ;;
+  erase)
+    if [ ! -f "$CREDS_DIR/$key" ]; then
+      echo "credentials not found in native keychain"
+      exit 1
+    fi
+    rm "$CREDS_DIR/$key" ;;
+esac
+`
+
+func TestHelperCredentialStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test credential helper is a shell script")
+	}
+
+	binDir := t.TempDir()
+	must.NoError(t, os.WriteFile(filepath.Join(binDir, helperPrefix+"test"), []byte(testHelper), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("CREDS_DIR", t.TempDir())
+
+	testCredentialStore(t, &helperCredentialStore{helper: "test"})
+
+	// Helper failures are reported
+	_, err := (&helperCredentialStore{helper: "missing"}).Get("prod-eu")
+	must.ErrorContains(t, err, "docker-credential-missing get failed")
+}
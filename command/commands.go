@@ -265,6 +265,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"alloc identity": func() (cli.Command, error) {
+			return &AllocIdentityCommand{
+				Meta: meta,
+			}, nil
+		},
 		"alloc logs": func() (cli.Command, error) {
 			return &AllocLogsCommand{
 				Meta: meta,
@@ -297,6 +302,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				ShutdownCh: make(chan struct{}),
 			}, nil
 		},
+		"agent validate-config": func() (cli.Command, error) {
+			return &AgentValidateConfigCommand{
+				Meta: meta,
+			}, nil
+		},
 		"agent-info": func() (cli.Command, error) {
 			return &AgentInfoCommand{
 				Meta: meta,
@@ -317,6 +327,31 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"context": func() (cli.Command, error) {
+			return &ContextCommand{
+				Meta: meta,
+			}, nil
+		},
+		"context create": func() (cli.Command, error) {
+			return &ContextCreateCommand{
+				Meta: meta,
+			}, nil
+		},
+		"context delete": func() (cli.Command, error) {
+			return &ContextDeleteCommand{
+				Meta: meta,
+			}, nil
+		},
+		"context list": func() (cli.Command, error) {
+			return &ContextListCommand{
+				Meta: meta,
+			}, nil
+		},
+		"context use": func() (cli.Command, error) {
+			return &ContextUseCommand{
+				Meta: meta,
+			}, nil
+		},
 		// operator debug was released in 0.12 as debug. This top-level alias preserves compatibility
 		"debug": func() (cli.Command, error) {
 			return &OperatorDebugCommand{
@@ -363,6 +398,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"doctor": func() (cli.Command, error) {
+			return &DoctorCommand{
+				Meta: meta,
+			}, nil
+		},
 		"eval": func() (cli.Command, error) {
 			return &EvalCommand{
 				Meta: meta,
@@ -431,6 +471,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"job convert": func() (cli.Command, error) {
+			return &JobConvertCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job deployments": func() (cli.Command, error) {
 			return &JobDeploymentsCommand{
 				Meta: meta,
@@ -481,6 +526,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"job resume": func() (cli.Command, error) {
+			return &JobResumeCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job revert": func() (cli.Command, error) {
 			return &JobRevertCommand{
 				Meta: meta,
@@ -496,6 +546,16 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"job scale-history": func() (cli.Command, error) {
+			return &JobScaleHistoryCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job schedule-override": func() (cli.Command, error) {
+			return &JobScheduleOverrideCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job scaling-events": func() (cli.Command, error) {
 			return &JobScalingEventsCommand{
 				Meta: meta,
@@ -511,6 +571,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"job suspend": func() (cli.Command, error) {
+			return &JobSuspendCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job validate": func() (cli.Command, error) {
 			return &JobValidateCommand{
 				Meta: meta,
@@ -531,6 +596,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"logout": func() (cli.Command, error) {
+			return &LogoutCommand{
+				Meta: meta,
+			}, nil
+		},
 		"logs": func() (cli.Command, error) {
 			return &AllocLogsCommand{
 				Meta: meta,
@@ -691,11 +761,36 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 			}, nil
 		},
 
+		"operator change-feed": func() (cli.Command, error) {
+			return &OperatorChangeFeedCommand{
+				Meta: meta,
+			}, nil
+		},
+		"operator change-feed export": func() (cli.Command, error) {
+			return &OperatorChangeFeedExportCommand{
+				Meta: meta,
+			}, nil
+		},
 		"operator client-state": func() (cli.Command, error) {
 			return &OperatorClientStateCommand{
 				Meta: meta,
 			}, nil
 		},
+		"operator client-state inspect": func() (cli.Command, error) {
+			return &OperatorClientStateInspectCommand{
+				Meta: meta,
+			}, nil
+		},
+		"operator client-state migrate": func() (cli.Command, error) {
+			return &OperatorClientStateMigrateCommand{
+				Meta: meta,
+			}, nil
+		},
+		"operator client-state repair": func() (cli.Command, error) {
+			return &OperatorClientStateRepairCommand{
+				Meta: meta,
+			}, nil
+		},
 		"operator debug": func() (cli.Command, error) {
 			return &OperatorDebugCommand{
 				Meta: meta,
@@ -754,6 +849,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"operator raft set-voter": func() (cli.Command, error) {
+			return &OperatorRaftSetVoterCommand{
+				Meta: meta,
+			}, nil
+		},
 		"operator raft transfer-leadership": func() (cli.Command, error) {
 			return &OperatorRaftTransferLeadershipCommand{
 				Meta: meta,
@@ -824,6 +924,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"operator snapshot verify": func() (cli.Command, error) {
+			return &OperatorSnapshotVerifyCommand{
+				Meta: meta,
+			}, nil
+		},
 		"operator snapshot state": func() (cli.Command, error) {
 			return &OperatorSnapshotStateCommand{
 				Meta: meta,
@@ -1219,6 +1324,11 @@ func Commands(metaPtr *Meta, agentUi cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"whoami": func() (cli.Command, error) {
+			return &WhoAmICommand{
+				Meta: meta,
+			}, nil
+		},
 	}
 
 	deprecated := map[string]cli.CommandFactory{
@@ -10,6 +10,7 @@ import (
 
 	multierror "github.com/hashicorp/go-multierror"
 	agent "github.com/hashicorp/nomad/command/agent"
+	"github.com/mitchellh/cli"
 )
 
 type ConfigValidateCommand struct {
@@ -47,7 +48,6 @@ func (c *ConfigValidateCommand) Synopsis() string {
 func (c *ConfigValidateCommand) Name() string { return "config validate" }
 
 func (c *ConfigValidateCommand) Run(args []string) int {
-	var mErr multierror.Error
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	if err := flags.Parse(args); err != nil {
@@ -61,6 +61,13 @@ func (c *ConfigValidateCommand) Run(args []string) int {
 		return 1
 	}
 
+	return validateAgentConfig(c.Ui, configPath)
+}
+
+// validateAgentConfig loads and merges the agent configuration files and
+// directories at the given paths, in order, and validates the result.
+func validateAgentConfig(ui cli.Ui, configPath []string) int {
+	var mErr multierror.Error
 	config := agent.DefaultConfig()
 
 	for _, path := range configPath {
@@ -71,22 +78,22 @@ func (c *ConfigValidateCommand) Run(args []string) int {
 			continue
 		}
 		if fc == nil || reflect.DeepEqual(fc, &agent.Config{}) {
-			c.Ui.Warn(fmt.Sprintf("No configuration loaded from %s", path))
+			ui.Warn(fmt.Sprintf("No configuration loaded from %s", path))
 		}
 
 		config = config.Merge(fc)
 	}
 	if err := mErr.ErrorOrNil(); err != nil {
-		c.Ui.Error(err.Error())
+		ui.Error(err.Error())
 		return 1
 	}
-	cmd := agent.Command{Ui: c.Ui}
+	cmd := agent.Command{Ui: ui}
 	valid := cmd.IsValidConfig(config, agent.DefaultConfig())
 	if !valid {
-		c.Ui.Error("Configuration is invalid")
+		ui.Error("Configuration is invalid")
 		return 1
 	}
 
-	c.Ui.Output("Configuration is valid!")
+	ui.Output("Configuration is valid!")
 	return 0
 }
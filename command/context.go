@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"strings"
+
+	"github.com/hashicorp/nomad/command/clicontext"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+type ContextCommand struct {
+	Meta
+}
+
+func (c *ContextCommand) Help() string {
+	helpText := `
+Usage: nomad context <subcommand> [options] [args]
+
+  This command groups subcommands for managing the contexts of the CLI. A
+  context stores the address, region, namespace, TLS configuration, and ACL
+  token used to reach a cluster, so operators working with multiple clusters
+  can switch between them instead of exporting NOMAD_ADDR and NOMAD_TOKEN.
+
+  Create a context:
+
+      $ nomad context create -address=https://nomad.eu.example.com:4646 \
+          -region=eu prod-eu
+
+  Use a context for the next commands:
+
+      $ nomad context use prod-eu
+
+  Store the ACL token of the context:
+
+      $ nomad login
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ContextCommand) Synopsis() string {
+	return "Interact with CLI contexts"
+}
+
+func (c *ContextCommand) Name() string { return "context" }
+
+func (c *ContextCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// ContextPredictor returns a predictor of the names of the CLI contexts.
+func ContextPredictor() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		store, err := clicontext.NewStore("")
+		if err != nil {
+			return nil
+		}
+		config, err := store.Load()
+		if err != nil {
+			return nil
+		}
+
+		var names []string
+		for _, name := range config.Names() {
+			if strings.HasPrefix(name, a.Last) {
+				names = append(names, name)
+			}
+		}
+		return names
+	})
+}
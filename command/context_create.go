@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/command/clicontext"
+	"github.com/posener/complete"
+)
+
+type ContextCreateCommand struct {
+	Meta
+}
+
+func (c *ContextCreateCommand) Help() string {
+	helpText := `
+Usage: nomad context create [options] <name>
+
+  Create is used to create or replace a CLI context from the address, region,
+  namespace, and TLS flags, which are then used by all the commands run with
+  the context. Environment variables such as NOMAD_ADDR and flags override the
+  configuration of the context. If the -token flag is set, the token is saved
+  in the credential store of the CLI.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault) + `
+
+Create Options:
+
+  -use
+    Use the context for the next commands, as with "nomad context use".
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ContextCreateCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-use": complete.PredictNothing,
+		})
+}
+
+func (c *ContextCreateCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ContextCreateCommand) Synopsis() string {
+	return "Create or replace a CLI context"
+}
+
+func (c *ContextCreateCommand) Name() string { return "context create" }
+
+func (c *ContextCreateCommand) Run(args []string) int {
+	var use bool
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&use, "use", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <name>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	name := args[0]
+	if err := clicontext.ValidateName(name); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	store, err := clicontext.NewStore("")
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	config, err := store.Load()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading contexts: %s", err))
+		return 1
+	}
+
+	config.Contexts[name] = &clicontext.Context{
+		Name:          name,
+		Address:       c.flagAddress,
+		Region:        c.region,
+		Namespace:     c.namespace,
+		CACert:        c.caCert,
+		CAPath:        c.caPath,
+		ClientCert:    c.clientCert,
+		ClientKey:     c.clientKey,
+		TLSServerName: c.tlsServerName,
+		Insecure:      c.insecure,
+	}
+	if use {
+		config.Current = name
+	}
+
+	if c.token != "" {
+		if err := store.Credentials(config).Store(name, c.token); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error storing token: %s", err))
+			return 1
+		}
+	}
+
+	if err := store.Save(config); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error saving contexts: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully created context %q", name))
+	if use {
+		c.Ui.Output(fmt.Sprintf("Using context %q", name))
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/command/clicontext"
+	"github.com/posener/complete"
+)
+
+type ContextDeleteCommand struct {
+	Meta
+}
+
+func (c *ContextDeleteCommand) Help() string {
+	helpText := `
+Usage: nomad context delete <name>
+
+  Delete is used to remove a CLI context and its token from the credential
+  store of the CLI.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ContextDeleteCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func (c *ContextDeleteCommand) AutocompleteArgs() complete.Predictor {
+	return ContextPredictor()
+}
+
+func (c *ContextDeleteCommand) Synopsis() string {
+	return "Delete a CLI context"
+}
+
+func (c *ContextDeleteCommand) Name() string { return "context delete" }
+
+func (c *ContextDeleteCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <name>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	name := args[0]
+
+	store, err := clicontext.NewStore("")
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	config, err := store.Load()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading contexts: %s", err))
+		return 1
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		c.Ui.Error(fmt.Sprintf("Context %q does not exist", name))
+		return 1
+	}
+
+	if err := store.Credentials(config).Erase(name); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error erasing token: %s", err))
+		return 1
+	}
+
+	delete(config.Contexts, name)
+	if config.Current == name {
+		config.Current = ""
+	}
+	if err := store.Save(config); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error saving contexts: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully deleted context %q", name))
+	return 0
+}
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/command/clicontext"
+	"github.com/posener/complete"
+)
+
+type ContextListCommand struct {
+	Meta
+}
+
+func (c *ContextListCommand) Help() string {
+	helpText := `
+Usage: nomad context list [options]
+
+  List is used to list the CLI contexts. The context in use is marked with an
+  asterisk.
+
+List Options:
+
+  -json
+    Output the contexts in their JSON format.
+
+  -t
+    Format and display the contexts using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ContextListCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-json": complete.PredictNothing,
+		"-t":    complete.PredictAnything,
+	}
+}
+
+func (c *ContextListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ContextListCommand) Synopsis() string {
+	return "List CLI contexts"
+}
+
+func (c *ContextListCommand) Name() string { return "context list" }
+
+func (c *ContextListCommand) Run(args []string) int {
+	var json bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if args = flags.Args(); len(args) > 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	store, err := clicontext.NewStore("")
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	config, err := store.Load()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading contexts: %s", err))
+		return 1
+	}
+	active, err := store.Active()
+	if err != nil {
+		c.Ui.Warn(err.Error())
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, config)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+
+	if len(config.Contexts) == 0 {
+		c.Ui.Output("No contexts found")
+		return 0
+	}
+
+	rows := []string{"Current|Name|Address|Region|Namespace"}
+	for _, name := range config.Names() {
+		ctx := config.Contexts[name]
+		current := ""
+		if active != nil && active.Name == name {
+			current = "*"
+		}
+		rows = append(rows, fmt.Sprintf("%s|%s|%s|%s|%s",
+			current, name, ctx.Address, ctx.Region, ctx.Namespace))
+	}
+	c.Ui.Output(formatList(rows))
+	return 0
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/command/clicontext"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestContextCommands(t *testing.T) {
+	t.Setenv(clicontext.ConfigDirEnv, t.TempDir())
+	t.Setenv(clicontext.ContextEnv, "")
+	t.Setenv("NOMAD_ADDR", "")
+	t.Setenv("NOMAD_TOKEN", "")
+
+	run := func(cmd interface{ Run([]string) int }, ui *cli.MockUi, args ...string) {
+		t.Helper()
+		code := cmd.Run(args)
+		must.Zero(t, code, must.Sprint(ui.ErrorWriter.String()))
+	}
+
+	ui := cli.NewMockUi()
+	run(&ContextCreateCommand{Meta: Meta{Ui: ui}}, ui,
+		"-address=https://eu.example.com:4646", "-region=eu", "-token=secret", "-use", "prod-eu")
+	must.StrContains(t, ui.OutputWriter.String(), `Using context "prod-eu"`)
+
+	ui = cli.NewMockUi()
+	run(&ContextCreateCommand{Meta: Meta{Ui: ui}}, ui, "-address=http://127.0.0.1:4646", "dev")
+
+	// Commands use the address and token of the current context
+	config := (&Meta{Ui: ui}).clientConfig()
+	must.Eq(t, "https://eu.example.com:4646", config.Address)
+	must.Eq(t, "eu", config.Region)
+	must.Eq(t, "secret", config.SecretID)
+
+	// Environment variables and flags override the context
+	t.Setenv("NOMAD_ADDR", "https://env.example.com:4646")
+	config = (&Meta{Ui: ui, region: "us"}).clientConfig()
+	must.Eq(t, "https://env.example.com:4646", config.Address)
+	must.Eq(t, "us", config.Region)
+	must.Eq(t, "secret", config.SecretID)
+	t.Setenv("NOMAD_ADDR", "")
+
+	ui = cli.NewMockUi()
+	run(&ContextListCommand{Meta: Meta{Ui: ui}}, ui)
+	out := ui.OutputWriter.String()
+	must.StrContains(t, out, "*        prod-eu  https://eu.example.com:4646")
+	must.StrContains(t, out, "dev      http://127.0.0.1:4646")
+
+	// Logging out erases the token of the current context
+	ui = cli.NewMockUi()
+	run(&LogoutCommand{Meta: Meta{Ui: ui}}, ui)
+	must.StrContains(t, ui.OutputWriter.String(), `Successfully logged out of context "prod-eu"`)
+	must.Eq(t, "", (&Meta{Ui: ui}).clientConfig().SecretID)
+
+	// NOMAD_CONTEXT overrides the current context
+	t.Setenv(clicontext.ContextEnv, "dev")
+	must.Eq(t, "http://127.0.0.1:4646", (&Meta{Ui: ui}).clientConfig().Address)
+	t.Setenv(clicontext.ContextEnv, "")
+
+	ui = cli.NewMockUi()
+	run(&ContextUseCommand{Meta: Meta{Ui: ui}}, ui, "dev")
+	must.Eq(t, "http://127.0.0.1:4646", (&Meta{Ui: ui}).clientConfig().Address)
+
+	ui = cli.NewMockUi()
+	run(&ContextDeleteCommand{Meta: Meta{Ui: ui}}, ui, "dev")
+	must.StrContains(t, ui.OutputWriter.String(), `Successfully deleted context "dev"`)
+	must.Eq(t, "http://127.0.0.1:4646", (&Meta{Ui: ui}).clientConfig().Address)
+
+	ui = cli.NewMockUi()
+	must.One(t, (&ContextUseCommand{Meta: Meta{Ui: ui}}).Run([]string{"dev"}))
+	must.StrContains(t, ui.ErrorWriter.String(), `Context "dev" does not exist`)
+
+	ui = cli.NewMockUi()
+	must.One(t, (&LogoutCommand{Meta: Meta{Ui: ui}}).Run(nil))
+	must.StrContains(t, ui.ErrorWriter.String(), "No CLI context in use")
+}
+
+func TestContextCreateCommand_InvalidName(t *testing.T) {
+	ci.Parallel(t)
+
+	ui := cli.NewMockUi()
+	cmd := &ContextCreateCommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"prod/eu"}))
+	must.StrContains(t, ui.ErrorWriter.String(), "invalid context name")
+}
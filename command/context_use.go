@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/command/clicontext"
+	"github.com/posener/complete"
+)
+
+type ContextUseCommand struct {
+	Meta
+}
+
+func (c *ContextUseCommand) Help() string {
+	helpText := `
+Usage: nomad context use <name>
+
+  Use is used to select the CLI context used by the next commands. The
+  NOMAD_CONTEXT environment variable overrides the selected context, and
+  environment variables such as NOMAD_ADDR and NOMAD_TOKEN override the
+  configuration of the context.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ContextUseCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func (c *ContextUseCommand) AutocompleteArgs() complete.Predictor {
+	return ContextPredictor()
+}
+
+func (c *ContextUseCommand) Synopsis() string {
+	return "Select the CLI context to use"
+}
+
+func (c *ContextUseCommand) Name() string { return "context use" }
+
+func (c *ContextUseCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <name>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	name := args[0]
+
+	store, err := clicontext.NewStore("")
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	config, err := store.Load()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading contexts: %s", err))
+		return 1
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		c.Ui.Error(fmt.Sprintf("Context %q does not exist", name))
+		return 1
+	}
+
+	config.Current = name
+	if err := store.Save(config); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error saving contexts: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Using context %q", name))
+	for _, env := range []string{clicontext.ContextEnv, "NOMAD_ADDR", "NOMAD_TOKEN"} {
+		if os.Getenv(env) != "" {
+			c.Ui.Warn(fmt.Sprintf("Warning: the %s environment variable overrides the context", env))
+		}
+	}
+	return 0
+}
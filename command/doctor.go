@@ -0,0 +1,345 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+const (
+	// doctorCertExpiryWarning is how long before its expiry the certificate of
+	// the agent is reported.
+	doctorCertExpiryWarning = 30 * 24 * time.Hour
+
+	// doctorMaxClockSkew is the clock skew between the CLI and the agent above
+	// which the skew is reported. The Date header only has a resolution of a
+	// second, so smaller skews can't be measured.
+	doctorMaxClockSkew = 5 * time.Second
+)
+
+// Statuses of the doctor checks.
+const (
+	doctorStatusOK      = "ok"
+	doctorStatusWarn    = "warn"
+	doctorStatusFail    = "fail"
+	doctorStatusSkipped = "skipped"
+)
+
+type DoctorCommand struct {
+	Meta
+}
+
+// doctorCheck is the result of a doctor check.
+type doctorCheck struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+// doctorReport is the machine-readable output of nomad doctor.
+type doctorReport struct {
+	Address string
+
+	// Status is the worst status of the checks.
+	Status string
+
+	Checks []*doctorCheck
+}
+
+func (r *doctorReport) add(name, status, format string, a ...any) {
+	r.Checks = append(r.Checks, &doctorCheck{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, a...),
+	})
+
+	switch {
+	case status == doctorStatusFail:
+		r.Status = doctorStatusFail
+	case status == doctorStatusWarn && r.Status != doctorStatusFail:
+		r.Status = doctorStatusWarn
+	}
+}
+
+func (c *DoctorCommand) Help() string {
+	helpText := `
+Usage: nomad doctor [options]
+
+  Doctor checks the connectivity and authentication of the CLI to the cluster,
+  to troubleshoot its configuration. It checks that:
+
+    * The agent is reachable and the cluster has a leader.
+    * The TLS certificate of the agent is trusted and not about to expire.
+    * ACLs are enabled and the token is valid.
+    * The Raft peers are healthy, if the token has operator:read.
+    * The clocks of the CLI and the agent are in sync.
+
+  Doctor exits with code 1 if any check failed.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault|usageOptsNoNamespace) + `
+
+Doctor Options:
+
+  -json
+    Output the results of the checks in their JSON format.
+
+  -t
+    Format and display the results of the checks using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DoctorCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-json": complete.PredictNothing,
+			"-t":    complete.PredictAnything,
+		})
+}
+
+func (c *DoctorCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *DoctorCommand) Synopsis() string {
+	return "Check the connectivity and authentication to the cluster"
+}
+
+func (c *DoctorCommand) Name() string { return "doctor" }
+
+func (c *DoctorCommand) Run(args []string) int {
+	var json bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if args = flags.Args(); len(args) != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	config := c.clientConfig()
+	client, err := api.NewClient(config)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	report := c.diagnose(config, client)
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, report)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+	} else {
+		rows := []string{"Check|Status|Message"}
+		for _, check := range report.Checks {
+			rows = append(rows, fmt.Sprintf("%s|%s|%s",
+				check.Name, c.colorStatus(check.Status), check.Message))
+		}
+		c.Ui.Output(formatList(rows))
+	}
+
+	if report.Status == doctorStatusFail {
+		return 1
+	}
+	return 0
+}
+
+// diagnose runs the checks against the agent of the configuration.
+func (c *DoctorCommand) diagnose(config *api.Config, client *api.Client) *doctorReport {
+	report := &doctorReport{
+		Address: config.Address,
+		Status:  doctorStatusOK,
+	}
+
+	resp, err := c.probe(config)
+	if err != nil {
+		report.add("reachability", doctorStatusFail, "failed to reach %s: %v", config.Address, err)
+		for _, name := range []string{"tls", "acl", "raft", "clock"} {
+			report.add(name, doctorStatusSkipped, "agent is unreachable")
+		}
+		return report
+	}
+
+	leader, err := client.Status().Leader()
+	switch {
+	case err != nil:
+		report.add("reachability", doctorStatusFail, "failed to query the leader: %v", err)
+	case leader == "":
+		report.add("reachability", doctorStatusFail, "cluster has no leader")
+	default:
+		report.add("reachability", doctorStatusOK, "agent reached, cluster leader is %s", leader)
+	}
+
+	checkTLS(report, config, resp)
+	checkACL(report, client)
+	checkRaft(report, client)
+	checkClock(report, resp)
+	return report
+}
+
+// probe queries the agent directly, since the API client doesn't expose the
+// TLS connection state or the headers of its responses.
+func (c *DoctorCommand) probe(config *api.Config) (*http.Response, error) {
+	httpClient := cleanhttp.DefaultClient()
+	httpClient.Timeout = 10 * time.Second
+
+	u, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		httpClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if err := api.ConfigureTLS(httpClient, config.TLSConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(config.Address, "/")+"/v1/status/leader", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+func checkTLS(report *doctorReport, config *api.Config, resp *http.Response) {
+	if resp.TLS == nil {
+		report.add("tls", doctorStatusWarn, "TLS is not in use, the connection to %s is not encrypted", config.Address)
+		return
+	}
+	if len(resp.TLS.PeerCertificates) == 0 {
+		report.add("tls", doctorStatusFail, "agent presented no certificate")
+		return
+	}
+
+	cert := resp.TLS.PeerCertificates[0]
+	remaining := time.Until(cert.NotAfter)
+	switch {
+	case config.TLSConfig != nil && config.TLSConfig.Insecure:
+		report.add("tls", doctorStatusWarn, "certificate verification is disabled, certificate expires %s",
+			cert.NotAfter.Format(time.RFC3339))
+	case remaining <= 0:
+		report.add("tls", doctorStatusFail, "certificate expired %s", cert.NotAfter.Format(time.RFC3339))
+	case remaining < doctorCertExpiryWarning:
+		report.add("tls", doctorStatusWarn, "certificate expires in %s, on %s",
+			remaining.Round(time.Hour), cert.NotAfter.Format(time.RFC3339))
+	default:
+		report.add("tls", doctorStatusOK, "certificate is trusted and expires %s",
+			cert.NotAfter.Format(time.RFC3339))
+	}
+}
+
+func checkACL(report *doctorReport, client *api.Client) {
+	whoami, _, err := client.ACLTokens().WhoAmI(nil)
+	if err != nil {
+		report.add("acl", doctorStatusFail, "failed to authenticate: %v", err)
+		return
+	}
+
+	identity := whoami.Identity
+	switch {
+	case whoami.Capabilities == nil:
+		report.add("acl", doctorStatusWarn, "ACLs are disabled, all requests are allowed")
+	case identity == nil:
+		report.add("acl", doctorStatusWarn, "no identity was resolved for the token")
+	case identity.ACLToken != nil && identity.ACLToken.AccessorID == "anonymous":
+		report.add("acl", doctorStatusWarn, "no token is set, requests use the anonymous policy")
+	case identity.ACLToken != nil:
+		report.add("acl", doctorStatusOK, "authenticated with %s token %q (%s)",
+			identity.ACLToken.Type, identity.ACLToken.Name, identity.ACLToken.AccessorID)
+	case identity.Claims != nil:
+		report.add("acl", doctorStatusOK, "authenticated with the workload identity of allocation %s",
+			identity.Claims.AllocationID)
+	default:
+		report.add("acl", doctorStatusOK, "authenticated as client node %s", identity.ClientID)
+	}
+}
+
+func checkRaft(report *doctorReport, client *api.Client) {
+	health, _, err := client.Operator().AutopilotServerHealth(nil)
+	switch {
+	case err != nil && strings.Contains(err.Error(), api.PermissionDeniedErrorContent):
+		report.add("raft", doctorStatusSkipped, "token lacks operator:read")
+		return
+	case err != nil:
+		report.add("raft", doctorStatusFail, "failed to query server health: %v", err)
+		return
+	}
+
+	healthy := 0
+	for _, server := range health.Servers {
+		if server.Healthy {
+			healthy++
+		}
+	}
+
+	switch {
+	case !health.Healthy:
+		report.add("raft", doctorStatusFail, "%d of %d servers are healthy", healthy, len(health.Servers))
+	case health.FailureTolerance == 0 && len(health.Servers) > 1:
+		report.add("raft", doctorStatusWarn, "%d servers are healthy, but the cluster can't tolerate any failure",
+			len(health.Servers))
+	default:
+		report.add("raft", doctorStatusOK, "%d servers are healthy, failure tolerance is %d",
+			len(health.Servers), health.FailureTolerance)
+	}
+}
+
+func checkClock(report *doctorReport, resp *http.Response) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		report.add("clock", doctorStatusSkipped, "agent returned no valid Date header")
+		return
+	}
+
+	skew := time.Since(date).Truncate(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > doctorMaxClockSkew {
+		report.add("clock", doctorStatusWarn, "clocks of the CLI and the agent differ by %s", skew)
+		return
+	}
+	report.add("clock", doctorStatusOK, "clocks of the CLI and the agent are in sync")
+}
+
+func (c *DoctorCommand) colorStatus(status string) string {
+	switch status {
+	case doctorStatusOK:
+		return c.Colorize().Color("[green]" + status + "[reset]")
+	case doctorStatusWarn:
+		return c.Colorize().Color("[yellow]" + status + "[reset]")
+	case doctorStatusFail:
+		return c.Colorize().Color("[red]" + status + "[reset]")
+	}
+	return status
+}
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestDoctorCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &DoctorCommand{}
+}
+
+func TestDoctorCommand_Run(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, _, url := testServer(t, true, func(c *agent.Config) {
+		c.ACL.Enabled = true
+	})
+	defer srv.Shutdown()
+
+	checks := func(out []byte) map[string]string {
+		var report doctorReport
+		must.NoError(t, json.Unmarshal(out, &report))
+		statuses := make(map[string]string)
+		for _, check := range report.Checks {
+			statuses[check.Name] = check.Status
+		}
+		return statuses
+	}
+
+	// A management token passes all the checks, but TLS is not in use.
+	ui := cli.NewMockUi()
+	cmd := &DoctorCommand{Meta: Meta{Ui: ui}}
+	must.Zero(t, cmd.Run([]string{"-address=" + url, "-token=" + srv.RootToken.SecretID, "-json"}))
+	must.Eq(t, map[string]string{
+		"reachability": doctorStatusOK,
+		"tls":          doctorStatusWarn,
+		"acl":          doctorStatusOK,
+		"raft":         doctorStatusOK,
+		"clock":        doctorStatusOK,
+	}, checks(ui.OutputWriter.Bytes()))
+
+	// The anonymous policy can't read the Raft health.
+	ui = cli.NewMockUi()
+	cmd = &DoctorCommand{Meta: Meta{Ui: ui}}
+	must.Zero(t, cmd.Run([]string{"-address=" + url, "-token=", "-json"}))
+	statuses := checks(ui.OutputWriter.Bytes())
+	must.Eq(t, doctorStatusWarn, statuses["acl"])
+	must.Eq(t, doctorStatusSkipped, statuses["raft"])
+
+	// An unknown token fails the ACL check.
+	ui = cli.NewMockUi()
+	cmd = &DoctorCommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"-address=" + url, "-token=not-a-token"}))
+	must.StrContains(t, ui.OutputWriter.String(), "failed to authenticate")
+}
+
+func TestDoctorCommand_Unreachable(t *testing.T) {
+	ci.Parallel(t)
+
+	ui := cli.NewMockUi()
+	cmd := &DoctorCommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"-address=http://127.0.0.1:1", "-json"}))
+
+	var report doctorReport
+	must.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &report))
+	must.Eq(t, doctorStatusFail, report.Status)
+	must.Len(t, 5, report.Checks)
+	must.Eq(t, doctorStatusFail, report.Checks[0].Status)
+	must.Eq(t, doctorStatusSkipped, report.Checks[1].Status)
+}
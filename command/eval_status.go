@@ -38,6 +38,12 @@ Eval Status Options:
   -verbose
     Show full information.
 
+  -verbose-explain
+    Show the trace of the decisions made by the scheduler for each
+    placement: the nodes filtered and why, the nodes exhausted, the scores of
+    the top nodes, and the preemptions considered. Traces are only recorded
+    when enabled in the scheduler configuration.
+
   -json
     Output the evaluation in its JSON format.
 
@@ -55,10 +61,11 @@ func (c *EvalStatusCommand) Synopsis() string {
 func (c *EvalStatusCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"-json":    complete.PredictNothing,
-			"-monitor": complete.PredictNothing,
-			"-t":       complete.PredictAnything,
-			"-verbose": complete.PredictNothing,
+			"-json":            complete.PredictNothing,
+			"-monitor":         complete.PredictNothing,
+			"-t":               complete.PredictAnything,
+			"-verbose":         complete.PredictNothing,
+			"-verbose-explain": complete.PredictNothing,
 		})
 }
 
@@ -84,13 +91,14 @@ func (c *EvalStatusCommand) AutocompleteArgs() complete.Predictor {
 func (c *EvalStatusCommand) Name() string { return "eval status" }
 
 func (c *EvalStatusCommand) Run(args []string) int {
-	var monitor, verbose, json bool
+	var monitor, verbose, explain, json bool
 	var tmpl string
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&monitor, "monitor", false, "")
 	flags.BoolVar(&verbose, "verbose", false, "")
+	flags.BoolVar(&explain, "verbose-explain", false, "")
 	flags.BoolVar(&json, "json", false, "")
 	flags.StringVar(&tmpl, "t", "", "")
 
@@ -247,9 +255,73 @@ func (c *EvalStatusCommand) Run(args []string) int {
 		}
 	}
 
+	if explain {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Scheduler Trace[reset]"))
+		trace, _, err := client.Evaluations().Trace(eval.ID, nil)
+		if err != nil {
+			if strings.Contains(err.Error(), "404") {
+				c.Ui.Output("No trace was recorded for this evaluation")
+				return 0
+			}
+			c.Ui.Error(fmt.Sprintf("Error querying evaluation trace: %s", err))
+			return 1
+		}
+		c.Ui.Output(formatEvalTrace(trace, length))
+	}
+
 	return 0
 }
 
+// formatEvalTrace formats the placements of an evaluation trace.
+func formatEvalTrace(trace *api.EvalTrace, length int) string {
+	if len(trace.Placements) == 0 {
+		return "No placements were attempted"
+	}
+
+	var out []string
+	for _, p := range trace.Placements {
+		result := "not placed"
+		if p.NodeID != "" {
+			result = fmt.Sprintf("placed on node %q", limit(p.NodeID, length))
+		}
+		out = append(out, fmt.Sprintf("Allocation %q (%s, %d nodes evaluated):",
+			p.AllocName, result, p.NodesEvaluated))
+
+		if len(p.Filtered) > 0 {
+			rows := []string{"Filtered Node|Reason"}
+			for _, n := range p.Filtered {
+				rows = append(rows, fmt.Sprintf("%s|%s", limit(n.NodeID, length), n.Reason))
+			}
+			out = append(out, formatList(rows))
+		}
+		if len(p.Exhausted) > 0 {
+			rows := []string{"Exhausted Node|Dimension"}
+			for _, n := range p.Exhausted {
+				rows = append(rows, fmt.Sprintf("%s|%s", limit(n.NodeID, length), n.Reason))
+			}
+			out = append(out, formatList(rows))
+		}
+		if p.Truncated {
+			out = append(out, "  * Some nodes were omitted from the trace")
+		}
+		if len(p.Scores) > 0 {
+			out = append(out, formatAllocMetrics(&api.AllocationMetric{
+				NodesEvaluated: p.NodesEvaluated,
+				ScoreMetaData:  p.Scores,
+			}, true, "  "))
+		}
+		if p.PreemptionAttempted {
+			out = append(out, "  * No node was feasible without preemption")
+		}
+		for _, id := range p.PreemptedAllocs {
+			out = append(out, fmt.Sprintf("  * Preempts allocation %q", limit(id, length)))
+		}
+		out = append(out, "")
+	}
+
+	return strings.TrimSuffix(strings.Join(out, "\n"), "\n")
+}
+
 func sortedTaskGroupFromMetrics(groups map[string]*api.AllocationMetric) []string {
 	tgs := make([]string, 0, len(groups))
 	for tg := range groups {
@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type JobConvertCommand struct {
+	Meta
+	JobGetter
+}
+
+func (c *JobConvertCommand) Help() string {
+	helpText := `
+Usage: nomad job convert [options] <path>
+
+  Converts an HCL1 or JSON job file to HCL2, and writes the converted job file
+  to stdout. The comments of HCL1 job files are preserved where possible.
+
+  If the supplied path is "-", the jobfile is read from stdin. Otherwise
+  it is read from the file at the supplied path or downloaded and
+  read from URL specified.
+
+  The job is converted by the Nomad agent, which parses the converted job file
+  and compares it to the original. Any difference is reported as a warning and
+  must be reviewed before using the converted job file.
+
+  When ACLs are enabled, this command requires a token with the 'parse-job' or
+  'submit-job' capability for the namespace.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault) + `
+
+Convert Options:
+
+  -json
+    Parses the job file as JSON. If the outer object has a Job field, such as
+    from "nomad job inspect" or "nomad run -output", the value of the field is
+    used as the job. By default the job file is parsed as HCL1.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobConvertCommand) Synopsis() string {
+	return "Convert an HCL1 or JSON job specification to HCL2"
+}
+
+func (c *JobConvertCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-json": complete.PredictNothing,
+		})
+}
+
+func (c *JobConvertCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictOr(
+		complete.PredictFiles("*.nomad"),
+		complete.PredictFiles("*.hcl"),
+		complete.PredictFiles("*.json"),
+	)
+}
+
+func (c *JobConvertCommand) Name() string { return "job convert" }
+
+func (c *JobConvertCommand) Run(args []string) int {
+	flagSet := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flagSet.Usage = func() { c.Ui.Output(c.Help()) }
+	flagSet.BoolVar(&c.JobGetter.JSON, "json", false, "")
+
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flagSet.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <path>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Job files which aren't JSON are HCL1 job files
+	c.JobGetter.HCL1 = !c.JobGetter.JSON
+
+	sub, _, err := c.JobGetter.Get(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error getting job struct: %s", err))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 255
+	}
+
+	resp, err := client.Jobs().Convert(&api.JobsConvertRequest{
+		JobSource: sub.Source,
+		Format:    sub.Format,
+	})
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error converting job: %s", err))
+		return 1
+	}
+
+	for _, warning := range resp.Warnings {
+		c.Ui.Warn(fmt.Sprintf("Warning: %s", warning))
+	}
+	c.Ui.Output(strings.TrimSuffix(resp.JobHCL, "\n"))
+	return 0
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobConvertCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &JobConvertCommand{}
+}
+
+func TestJobConvertCommand_Fails(t *testing.T) {
+	ci.Parallel(t)
+
+	ui := cli.NewMockUi()
+	cmd := &JobConvertCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	code := cmd.Run([]string{"some", "bad", "args"})
+	must.One(t, code)
+	must.StrContains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+	ui.ErrorWriter.Reset()
+
+	// Fails when the job file doesn't exist
+	code = cmd.Run([]string{"/unicorns/leprechauns"})
+	must.One(t, code)
+	must.StrContains(t, ui.ErrorWriter.String(), "Error getting job struct")
+}
+
+func TestJobConvertCommand_Run(t *testing.T) {
+	ci.Parallel(t)
+
+	_, _, addr := testServer(t, false, nil)
+
+	jobFile := filepath.Join(t.TempDir(), "example.nomad")
+	must.NoError(t, os.WriteFile(jobFile, []byte(`# example job
+job "example" {
+  datacenters = ["dc1"]
+
+  group "cache" {
+    task "redis" {
+      driver = "docker"
+
+      config {
+        image = "redis:7"
+      }
+    }
+  }
+}
+`), 0o644))
+
+	ui := cli.NewMockUi()
+	cmd := &JobConvertCommand{Meta: Meta{Ui: ui}}
+	code := cmd.Run([]string{"-address", addr, jobFile})
+	must.Zero(t, code, must.Sprint(ui.ErrorWriter.String()))
+	must.StrContains(t, ui.OutputWriter.String(), "# example job\njob \"example\" {")
+	must.StrContains(t, ui.OutputWriter.String(), `image = "redis:7"`)
+	must.Eq(t, "", ui.ErrorWriter.String())
+
+	ui = cli.NewMockUi()
+	cmd = &JobConvertCommand{Meta: Meta{Ui: ui}}
+	code = cmd.Run([]string{"-address", addr, "-json", "testdata/example-short.json"})
+	must.Zero(t, code, must.Sprint(ui.ErrorWriter.String()))
+	must.StrContains(t, ui.OutputWriter.String(), `job "example" {`)
+	must.Eq(t, "", ui.ErrorWriter.String())
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// Ensure JobResumeCommand satisfies the cli.Command interface.
+var _ cli.Command = &JobResumeCommand{}
+
+// JobResumeCommand implements cli.Command.
+type JobResumeCommand struct {
+	Meta
+}
+
+// Help satisfies the cli.Command Help function.
+func (j *JobResumeCommand) Help() string {
+	helpText := `
+Usage: nomad job resume [options] <job>
+
+  Resume a batch job suspended with "nomad job suspend". The allocations of
+  the job that didn't complete before it was suspended are placed again.
+
+  Upon successful resumption, this command will immediately enter an
+  interactive monitor. It is safe to exit the monitor early using ctrl+c.
+
+  When ACLs are enabled, this command requires a token with the 'submit-job'
+  capability for the job's namespace. The 'list-jobs' capability is required
+  to run the command with a job prefix instead of the exact job ID. The
+  'read-job' capability is required to monitor the resulting evaluation when
+  -detach is not used.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault) + `
+
+Resume Options:
+
+  -detach
+    Return immediately instead of entering monitor mode. The evaluation ID
+    will be printed to the screen, which can be used to examine the evaluation
+    using the eval-status command.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis satisfies the cli.Command Synopsis function.
+func (j *JobResumeCommand) Synopsis() string {
+	return "Resume a suspended batch job"
+}
+
+func (j *JobResumeCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(j.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-detach":  complete.PredictNothing,
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (j *JobResumeCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := j.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, "jobs", nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches["jobs"]
+	})
+}
+
+// Name returns the name of this command.
+func (j *JobResumeCommand) Name() string { return "job resume" }
+
+// Run satisfies the cli.Command Run function.
+func (j *JobResumeCommand) Run(args []string) int {
+	var detach, verbose bool
+
+	flags := j.Meta.FlagSet(j.Name(), FlagSetClient)
+	flags.Usage = func() { j.Ui.Output(j.Help()) }
+	flags.BoolVar(&detach, "detach", false, "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		j.Ui.Error("This command takes one argument: <job>")
+		j.Ui.Error(commandErrorText(j))
+		return 1
+	}
+
+	// Get the HTTP client.
+	client, err := j.Meta.Client()
+	if err != nil {
+		j.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Check if the job exists
+	jobIDPrefix := strings.TrimSpace(args[0])
+	jobID, namespace, err := j.JobIDByPrefix(client, jobIDPrefix, nil)
+	if err != nil {
+		j.Ui.Error(err.Error())
+		return 1
+	}
+
+	w := &api.WriteOptions{Namespace: namespace}
+	resp, _, err := client.Jobs().Resume(jobID, w)
+	if err != nil {
+		j.Ui.Error(fmt.Sprintf("Error resuming job: %s", err))
+		return 1
+	}
+
+	// If we are to detach, log the evaluation ID and exit.
+	if detach {
+		j.Ui.Output("Evaluation ID: " + resp.EvalID)
+		return 0
+	}
+
+	// Truncate the ID unless full length is requested.
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Create and monitor the evaluation.
+	mon := newMonitor(j.Ui, client, length)
+	return mon.monitor(resp.EvalID)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// Ensure JobScaleHistoryCommand satisfies the cli.Command interface.
+var _ cli.Command = &JobScaleHistoryCommand{}
+
+// JobScaleHistoryCommand implements cli.Command.
+type JobScaleHistoryCommand struct {
+	Meta
+}
+
+// Help satisfies the cli.Command Help function.
+func (j *JobScaleHistoryCommand) Help() string {
+	helpText := `
+Usage: nomad job scale-history [options] <job>
+
+  Display the scaling history of the specified job: who scaled each of its
+  groups, from which count to which count, and why. The number of scaling
+  events kept for each group is set by the job_tracked_scaling_events server
+  configuration.
+
+  When ACLs are enabled, this command requires a token with either the
+  'read-job' or 'read-job-scaling' capability for the job's namespace. The
+  'list-jobs' capability is required to run the command with a job prefix
+  instead of the exact job ID.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault) + `
+
+Scale-History Options:
+
+  -group <group>
+    Only display the scaling events of the given group.
+
+  -limit <n>
+    Only display the n most recent scaling events. Defaults to all the
+    scaling events kept by the servers.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis satisfies the cli.Command Synopsis function.
+func (j *JobScaleHistoryCommand) Synopsis() string {
+	return "Display the scaling history of a job"
+}
+
+func (j *JobScaleHistoryCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(j.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-group":   complete.PredictAnything,
+			"-limit":   complete.PredictAnything,
+			"-verbose": complete.PredictNothing,
+		})
+}
+
+func (j *JobScaleHistoryCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := j.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, "jobs", nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches["jobs"]
+	})
+}
+
+// Name returns the name of this command.
+func (j *JobScaleHistoryCommand) Name() string { return "job scale-history" }
+
+// Run satisfies the cli.Command Run function.
+func (j *JobScaleHistoryCommand) Run(args []string) int {
+	var verbose bool
+	var group string
+	var limit int
+
+	flags := j.Meta.FlagSet(j.Name(), FlagSetClient)
+	flags.Usage = func() { j.Ui.Output(j.Help()) }
+	flags.BoolVar(&verbose, "verbose", false, "")
+	flags.StringVar(&group, "group", "", "")
+	flags.IntVar(&limit, "limit", 0, "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		j.Ui.Error("This command takes one argument: <job>")
+		j.Ui.Error(commandErrorText(j))
+		return 1
+	}
+	if limit < 0 {
+		j.Ui.Error("-limit must not be negative")
+		return 1
+	}
+
+	// Get the HTTP client.
+	client, err := j.Meta.Client()
+	if err != nil {
+		j.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Check if the job exists
+	jobIDPrefix := strings.TrimSpace(args[0])
+	jobID, namespace, err := j.JobIDByPrefix(client, jobIDPrefix, nil)
+	if err != nil {
+		j.Ui.Error(err.Error())
+		return 1
+	}
+
+	q := &api.QueryOptions{Namespace: namespace}
+	status, _, err := client.Jobs().ScaleStatus(jobID, q)
+	if err != nil {
+		j.Ui.Error(fmt.Sprintf("Error listing scaling events: %s", err))
+		return 1
+	}
+
+	if group != "" {
+		tg, ok := status.TaskGroups[group]
+		if !ok {
+			j.Ui.Error(fmt.Sprintf("Group %q not found in job %q", group, jobID))
+			return 1
+		}
+		status.TaskGroups = map[string]api.TaskGroupScaleStatus{group: tg}
+	}
+
+	events := sortedScalingEventList(status)
+	if len(events) == 0 {
+		j.Ui.Output("No scaling events found")
+		return 0
+	}
+
+	j.Ui.Output(formatList(formatScaleHistory(events, verbose, limit)))
+	return 0
+}
+
+// formatScaleHistory formats the scaling events of a job, most recent first.
+// A limit of zero formats all the events.
+func formatScaleHistory(events scalingEventList, verbose bool, limit int) []string {
+	if limit == 0 || limit > len(events) {
+		limit = len(events)
+	}
+
+	output := make([]string, 0, limit+1)
+	header := "Date|Task Group|PrevCount|Count|Actor|Annotation"
+	if verbose {
+		header += "|Message|Error|Eval ID"
+	}
+	output = append(output, header)
+
+	for _, e := range events[:limit] {
+		row := fmt.Sprintf("%s|%s|%d|%s|%s|%s",
+			formatTime(time.Unix(0, int64(e.event.Time))),
+			e.name,
+			e.event.PreviousCount,
+			valueOrNil(e.event.Count),
+			e.event.Actor,
+			e.event.Annotation)
+		if verbose {
+			row += fmt.Sprintf("|%s|%v|%s",
+				e.event.Message, e.event.Error, valueOrNil(e.event.EvalID))
+		}
+		output = append(output, row)
+	}
+	return output
+}
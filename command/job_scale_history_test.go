@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobScaleHistoryCommand_Run(t *testing.T) {
+	ci.Parallel(t)
+	srv, client, url := testServer(t, false, nil)
+	defer srv.Shutdown()
+
+	ui := cli.NewMockUi()
+	cmd := &JobScaleHistoryCommand{Meta: Meta{Ui: ui}}
+
+	_, _, err := client.Jobs().Register(testJob("scale_history_test_job"), nil)
+	must.NoError(t, err)
+
+	// Missing job argument
+	must.One(t, cmd.Run([]string{"-address=" + url}))
+	must.StrContains(t, ui.ErrorWriter.String(), "This command takes one argument: <job>")
+
+	must.Zero(t, cmd.Run([]string{"-address=" + url, "scale_history_test_job"}))
+	must.StrContains(t, ui.OutputWriter.String(), "No scaling events found")
+
+	// Record two informational scaling events with annotations.
+	for _, annotation := range []string{"first annotation", "second annotation"} {
+		_, _, err = client.Jobs().ScaleWithRequest("scale_history_test_job", &api.ScalingRequest{
+			Target:     map[string]string{"Job": "scale_history_test_job", "Group": "group1"},
+			Message:    "test message",
+			Annotation: annotation,
+		}, nil)
+		must.NoError(t, err)
+	}
+
+	ui.OutputWriter.Reset()
+	must.Zero(t, cmd.Run([]string{"-address=" + url, "-verbose", "scale_history_test_job"}))
+	out := ui.OutputWriter.String()
+	must.StrContains(t, out, "Actor")
+	must.StrContains(t, out, "first annotation")
+	must.StrContains(t, out, "second annotation")
+	must.StrContains(t, out, "test message")
+
+	// Only the most recent event is displayed with a limit.
+	ui.OutputWriter.Reset()
+	must.Zero(t, cmd.Run([]string{"-address=" + url, "-limit=1", "scale_history_test_job"}))
+	out = ui.OutputWriter.String()
+	must.StrContains(t, out, "second annotation")
+	must.StrNotContains(t, out, "first annotation")
+
+	// Unknown group
+	ui.ErrorWriter.Reset()
+	must.One(t, cmd.Run([]string{"-address=" + url, "-group=nope", "scale_history_test_job"}))
+	must.StrContains(t, ui.ErrorWriter.String(), `Group "nope" not found`)
+}
@@ -0,0 +1,217 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// Ensure JobScheduleOverrideCommand satisfies the cli.Command interface.
+var _ cli.Command = &JobScheduleOverrideCommand{}
+
+// JobScheduleOverrideCommand implements cli.Command.
+type JobScheduleOverrideCommand struct {
+	Meta
+}
+
+// Help satisfies the cli.Command Help function.
+func (j *JobScheduleOverrideCommand) Help() string {
+	helpText := `
+Usage: nomad job schedule-override [options] <job>
+
+  Force a job with an active schedule to run or to be scaled to zero regardless
+  of the windows of its schedule, or clear the current override. The override
+  is kept when the job is registered again, until it expires or is cleared.
+
+  Upon successful override, if the count of any group changed, this command
+  will immediately enter an interactive monitor. It is safe to exit the
+  monitor early using ctrl+c.
+
+  When ACLs are enabled, this command requires a token with either the
+  'scale-job' or 'submit-job' capability for the job's namespace. The
+  'list-jobs' capability is required to run the command with a job prefix
+  instead of the exact job ID. The 'read-job' capability is required to
+  monitor the resulting evaluation when -detach is not used.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault) + `
+
+Schedule Override Options:
+
+  -active
+    Force the job to run, as if it was inside a window of its schedule.
+
+  -inactive
+    Force the job to be scaled to zero, as if it was outside of the windows of
+    its schedule.
+
+  -clear
+    Clear the current override, so the job follows its schedule again.
+
+  -duration
+    The duration of the override, such as "2h". Defaults to keeping the
+    override until it's cleared.
+
+  -detach
+    Return immediately instead of entering monitor mode. The evaluation ID
+    will be printed to the screen, which can be used to examine the evaluation
+    using the eval-status command.
+
+  -verbose
+    Display full information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis satisfies the cli.Command Synopsis function.
+func (j *JobScheduleOverrideCommand) Synopsis() string {
+	return "Override the active schedule of a job"
+}
+
+func (j *JobScheduleOverrideCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(j.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-active":   complete.PredictNothing,
+			"-inactive": complete.PredictNothing,
+			"-clear":    complete.PredictNothing,
+			"-duration": complete.PredictAnything,
+			"-detach":   complete.PredictNothing,
+			"-verbose":  complete.PredictNothing,
+		})
+}
+
+func (j *JobScheduleOverrideCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		client, err := j.Meta.Client()
+		if err != nil {
+			return nil
+		}
+
+		resp, _, err := client.Search().PrefixSearch(a.Last, "jobs", nil)
+		if err != nil {
+			return []string{}
+		}
+		return resp.Matches["jobs"]
+	})
+}
+
+// Name returns the name of this command.
+func (j *JobScheduleOverrideCommand) Name() string { return "job schedule-override" }
+
+// Run satisfies the cli.Command Run function.
+func (j *JobScheduleOverrideCommand) Run(args []string) int {
+	var active, inactive, clear, detach, verbose bool
+	var duration time.Duration
+
+	flags := j.Meta.FlagSet(j.Name(), FlagSetClient)
+	flags.Usage = func() { j.Ui.Output(j.Help()) }
+	flags.BoolVar(&active, "active", false, "")
+	flags.BoolVar(&inactive, "inactive", false, "")
+	flags.BoolVar(&clear, "clear", false, "")
+	flags.DurationVar(&duration, "duration", 0, "")
+	flags.BoolVar(&detach, "detach", false, "")
+	flags.BoolVar(&verbose, "verbose", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		j.Ui.Error("This command takes one argument: <job>")
+		j.Ui.Error(commandErrorText(j))
+		return 1
+	}
+
+	var modes int
+	for _, mode := range []bool{active, inactive, clear} {
+		if mode {
+			modes++
+		}
+	}
+	if modes != 1 {
+		j.Ui.Error("Exactly one of -active, -inactive, or -clear must be set")
+		j.Ui.Error(commandErrorText(j))
+		return 1
+	}
+	if duration < 0 || (clear && duration != 0) {
+		j.Ui.Error("The -duration flag must be positive and can't be used with -clear")
+		j.Ui.Error(commandErrorText(j))
+		return 1
+	}
+
+	// Get the HTTP client.
+	client, err := j.Meta.Client()
+	if err != nil {
+		j.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Check if the job exists
+	jobIDPrefix := strings.TrimSpace(args[0])
+	jobID, namespace, err := j.JobIDByPrefix(client, jobIDPrefix, nil)
+	if err != nil {
+		j.Ui.Error(err.Error())
+		return 1
+	}
+
+	var override *api.JobActiveScheduleOverride
+	if !clear {
+		override = &api.JobActiveScheduleOverride{Active: active}
+		if duration > 0 {
+			override.Until = time.Now().Add(duration)
+		}
+	}
+
+	w := &api.WriteOptions{Namespace: namespace}
+	resp, _, err := client.Jobs().OverrideSchedule(jobID, override, w)
+	if err != nil {
+		j.Ui.Error(fmt.Sprintf("Error overriding job schedule: %s", err))
+		return 1
+	}
+
+	// The counts of the groups didn't change, so there is nothing to monitor.
+	if resp.EvalID == "" {
+		j.Ui.Output(fmt.Sprintf("Updated the active schedule of job %q", jobID))
+		return 0
+	}
+
+	// If we are to detach, log the evaluation ID and exit.
+	if detach {
+		j.Ui.Output("Evaluation ID: " + resp.EvalID)
+		return 0
+	}
+
+	// Truncate the ID unless full length is requested.
+	length := shortId
+	if verbose {
+		length = fullId
+	}
+
+	// Create and monitor the evaluation.
+	mon := newMonitor(j.Ui, client, length)
+	return mon.monitor(resp.EvalID)
+}
+
+// formatActiveSchedule returns the state of the active schedule of a job.
+func formatActiveSchedule(s *api.JobActiveSchedule) string {
+	state := "active"
+	if s.SuspendedCounts != nil {
+		state = "suspended"
+	}
+	if o := s.Override; o != nil {
+		if o.Until.IsZero() {
+			state += " (overridden)"
+		} else {
+			state += fmt.Sprintf(" (overridden until %s)", formatTime(o.Until))
+		}
+	}
+	return state
+}
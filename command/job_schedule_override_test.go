@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobScheduleOverrideCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &JobScheduleOverrideCommand{}
+}
+
+func TestJobScheduleOverrideCommand_Fails(t *testing.T) {
+	ci.Parallel(t)
+
+	cases := []struct {
+		args   []string
+		expErr string
+	}{
+		{args: []string{"-active"}, expErr: commandErrorText(&JobScheduleOverrideCommand{})},
+		{args: []string{"example"}, expErr: "Exactly one of -active, -inactive, or -clear must be set"},
+		{args: []string{"-active", "-clear", "example"}, expErr: "Exactly one of -active, -inactive, or -clear must be set"},
+		{args: []string{"-clear", "-duration=1h", "example"}, expErr: "can't be used with -clear"},
+		{args: []string{"-address=nope", "-active", "example"}, expErr: "Error querying job prefix"},
+	}
+
+	for _, tc := range cases {
+		ui := cli.NewMockUi()
+		cmd := &JobScheduleOverrideCommand{Meta: Meta{Ui: ui}}
+		must.One(t, cmd.Run(tc.args))
+		must.StrContains(t, ui.ErrorWriter.String(), tc.expErr)
+	}
+}
+
+func TestFormatActiveSchedule(t *testing.T) {
+	ci.Parallel(t)
+
+	s := &api.JobActiveSchedule{}
+	must.Eq(t, "active", formatActiveSchedule(s))
+
+	s.SuspendedCounts = map[string]int{"web": 1}
+	s.Override = &api.JobActiveScheduleOverride{}
+	must.Eq(t, "suspended (overridden)", formatActiveSchedule(s))
+
+	until := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	s.Override.Until = until
+	must.Eq(t, "suspended (overridden until "+formatTime(until)+")", formatActiveSchedule(s))
+}
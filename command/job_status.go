@@ -182,11 +182,15 @@ func (c *JobStatusCommand) Run(args []string) int {
 		fmt.Sprintf("Datacenters|%s", strings.Join(job.Datacenters, ",")),
 		fmt.Sprintf("Namespace|%s", *job.Namespace),
 		fmt.Sprintf("Node Pool|%s", nodePool),
-		fmt.Sprintf("Status|%s", getStatusString(*job.Status, job.Stop)),
+		fmt.Sprintf("Status|%s", getStatusString(*job.Status, job.Stop, job.Suspended)),
 		fmt.Sprintf("Periodic|%v", periodic),
 		fmt.Sprintf("Parameterized|%v", parameterized),
 	}
 
+	if job.ActiveSchedule != nil {
+		basic = append(basic, fmt.Sprintf("Active Schedule|%s", formatActiveSchedule(job.ActiveSchedule)))
+	}
+
 	if job.DispatchIdempotencyToken != nil && *job.DispatchIdempotencyToken != "" {
 		basic = append(basic, fmt.Sprintf("Idempotency Token|%v", *job.DispatchIdempotencyToken))
 	}
@@ -521,7 +525,10 @@ func formatAllocList(allocations []*api.Allocation, verbose bool, uuidLength int
 // where appropriate
 func (c *JobStatusCommand) outputJobSummary(client *api.Client, job *api.Job) error {
 	// Query the summary
-	q := &api.QueryOptions{Namespace: *job.Namespace}
+	q := &api.QueryOptions{
+		Namespace: *job.Namespace,
+		Params:    map[string]string{"health": "true"},
+	}
 	summary, _, err := client.Jobs().Summary(*job.ID, q)
 	if err != nil {
 		return fmt.Errorf("Error querying job summary: %s", err)
@@ -553,6 +560,26 @@ func (c *JobStatusCommand) outputJobSummary(client *api.Client, job *api.Job) er
 			)
 		}
 		c.Ui.Output(formatList(summaries))
+
+		if health := summary.Health; health != nil {
+			c.Ui.Output(c.Colorize().Color("\n[bold]Health[reset]"))
+			c.Ui.Output(formatKV([]string{
+				fmt.Sprintf("Healthy|%d/%d (%d%%)", health.HealthyAllocs, health.DesiredAllocs, health.HealthyPercent),
+				fmt.Sprintf("Restarts (last %s)|%d", health.RestartWindow, health.Restarts),
+				fmt.Sprintf("Deployment Trend|%s", health.DeploymentTrend),
+			}))
+		}
+	}
+
+	if len(summary.Warnings) > 0 {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Warnings[reset]"))
+		warnings := make([]string, len(summary.Warnings)+1)
+		warnings[0] = "Type|Task Group|Message"
+		for idx, warning := range summary.Warnings {
+			warnings[idx+1] = fmt.Sprintf("%s|%s|%s",
+				warning.Type, warning.TaskGroup, warning.Message)
+		}
+		c.Ui.Output(formatList(warnings))
 	}
 
 	// Always display the summary if we are periodic or parameterized, but
@@ -677,7 +704,7 @@ func createStatusListOutput(jobs []*api.JobListStub, displayNS bool) string {
 				job.JobSummary.Namespace,
 				getTypeString(job),
 				job.Priority,
-				getStatusString(job.Status, &job.Stop),
+				getStatusString(job.Status, &job.Stop, &job.Suspended),
 				formatTime(time.Unix(0, job.SubmitTime)))
 		}
 	} else {
@@ -687,7 +714,7 @@ func createStatusListOutput(jobs []*api.JobListStub, displayNS bool) string {
 				job.ID,
 				getTypeString(job),
 				job.Priority,
-				getStatusString(job.Status, &job.Stop),
+				getStatusString(job.Status, &job.Stop, &job.Suspended),
 				formatTime(time.Unix(0, job.SubmitTime)))
 		}
 	}
@@ -708,9 +735,12 @@ func getTypeString(job *api.JobListStub) string {
 	return t
 }
 
-func getStatusString(status string, stop *bool) string {
+func getStatusString(status string, stop, suspended *bool) string {
 	if stop != nil && *stop {
 		return fmt.Sprintf("%s (stopped)", status)
 	}
+	if suspended != nil && *suspended {
+		return fmt.Sprintf("%s (suspended)", status)
+	}
 	return status
 }
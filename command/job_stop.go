@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/api/contexts"
@@ -59,6 +60,13 @@ Stop Options:
     this flag will result in failed network connections to the allocations
     being stopped.
 
+  -service-drain=<duration>
+    Deregister the services of the allocations being stopped, native and
+    Consul, and wait for the given duration before killing their tasks, so
+    load balancers can remove the allocations first. The drain is in addition
+    to the group and task shutdown_delay configuration, and can't be combined
+    with -no-shutdown-delay.
+
   -purge
     Purge is used to stop the job and purge it from the system. If not set, the
     job will still be queryable and will be purged by the garbage collector.
@@ -84,6 +92,7 @@ func (c *JobStopCommand) AutocompleteFlags() complete.Flags {
 			"-purge":             complete.PredictNothing,
 			"-global":            complete.PredictNothing,
 			"-no-shutdown-delay": complete.PredictNothing,
+			"-service-drain":     complete.PredictAnything,
 			"-yes":               complete.PredictNothing,
 			"-verbose":           complete.PredictNothing,
 		})
@@ -109,6 +118,7 @@ func (c *JobStopCommand) Name() string { return "job stop" }
 func (c *JobStopCommand) Run(args []string) int {
 	var detach, purge, verbose, global, autoYes, noShutdownDelay bool
 	var evalPriority int
+	var serviceDrain time.Duration
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
@@ -119,11 +129,18 @@ func (c *JobStopCommand) Run(args []string) int {
 	flags.BoolVar(&autoYes, "yes", false, "")
 	flags.BoolVar(&purge, "purge", false, "")
 	flags.IntVar(&evalPriority, "eval-priority", 0, "")
+	flags.DurationVar(&serviceDrain, "service-drain", 0, "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
 
+	if serviceDrain > 0 && noShutdownDelay {
+		c.Ui.Error("The -service-drain and -no-shutdown-delay flags can't be combined")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
 	// Check that we got exactly one job
 	args = flags.Args()
 	if len(args) < 1 {
@@ -216,7 +233,13 @@ func (c *JobStopCommand) Run(args []string) int {
 			}
 
 			// Invoke the stop
-			opts := &api.DeregisterOptions{Purge: purge, Global: global, EvalPriority: evalPriority, NoShutdownDelay: noShutdownDelay}
+			opts := &api.DeregisterOptions{
+				Purge:           purge,
+				Global:          global,
+				EvalPriority:    evalPriority,
+				NoShutdownDelay: noShutdownDelay,
+				ServiceDrain:    serviceDrain,
+			}
 			wq := &api.WriteOptions{Namespace: *job.Namespace}
 			evalID, _, err := client.Jobs().DeregisterOpts(*job.ID, opts, wq)
 			if err != nil {
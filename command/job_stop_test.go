@@ -113,6 +113,15 @@ func TestStopCommand_Fails(t *testing.T) {
 
 	ui.ErrorWriter.Reset()
 
+	// Fails on conflicting flags
+	code = cmd.Run([]string{"-address=" + url, "-service-drain=30s", "-no-shutdown-delay", "nope"})
+	must.One(t, code)
+
+	out = ui.ErrorWriter.String()
+	must.StrContains(t, out, "The -service-drain and -no-shutdown-delay flags can't be combined")
+
+	ui.ErrorWriter.Reset()
+
 	// Fails on nonexistent job ID
 	code = cmd.Run([]string{"-address=" + url, "nope"})
 	must.One(t, code)
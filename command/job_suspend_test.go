@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobSuspendCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &JobSuspendCommand{}
+	var _ cli.Command = &JobResumeCommand{}
+}
+
+func TestJobSuspendCommand_Fails(t *testing.T) {
+	ci.Parallel(t)
+
+	cases := []struct {
+		cmd    func(ui cli.Ui) cli.Command
+		args   []string
+		expErr string
+	}{
+		{
+			cmd:    func(ui cli.Ui) cli.Command { return &JobSuspendCommand{Meta: Meta{Ui: ui}} },
+			expErr: commandErrorText(&JobSuspendCommand{}),
+		},
+		{
+			cmd:    func(ui cli.Ui) cli.Command { return &JobSuspendCommand{Meta: Meta{Ui: ui}} },
+			args:   []string{"-address=nope", "example"},
+			expErr: "Error querying job prefix",
+		},
+		{
+			cmd:    func(ui cli.Ui) cli.Command { return &JobResumeCommand{Meta: Meta{Ui: ui}} },
+			args:   []string{"one", "two"},
+			expErr: commandErrorText(&JobResumeCommand{}),
+		},
+		{
+			cmd:    func(ui cli.Ui) cli.Command { return &JobResumeCommand{Meta: Meta{Ui: ui}} },
+			args:   []string{"-address=nope", "example"},
+			expErr: "Error querying job prefix",
+		},
+	}
+
+	for _, tc := range cases {
+		ui := cli.NewMockUi()
+		must.One(t, tc.cmd(ui).Run(tc.args))
+		must.StrContains(t, ui.ErrorWriter.String(), tc.expErr)
+	}
+}
+
+func TestGetStatusString(t *testing.T) {
+	ci.Parallel(t)
+
+	yes, no := true, false
+	must.Eq(t, "running", getStatusString("running", nil, nil))
+	must.Eq(t, "dead (stopped)", getStatusString("dead", &yes, &yes))
+	must.Eq(t, "dead (suspended)", getStatusString("dead", &no, &yes))
+}
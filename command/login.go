@@ -15,6 +15,7 @@ import (
 	"github.com/posener/complete"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/command/clicontext"
 	"github.com/hashicorp/nomad/lib/auth/oidc"
 )
 
@@ -40,7 +41,9 @@ func (l *LoginCommand) Help() string {
 Usage: nomad login [options]
 
   The login command will exchange the provided third party credentials with the
-  requested auth method for a newly minted Nomad ACL token.
+  requested auth method for a newly minted Nomad ACL token. If a CLI context is
+  in use, the token is saved in the credential store of the CLI and used by the
+  next commands run with the context, until "nomad logout".
 
 General Options:
 
@@ -194,6 +197,12 @@ func (l *LoginCommand) Run(args []string) int {
 		return 1
 	}
 
+	saved, err := l.saveToken(token)
+	if err != nil {
+		l.Ui.Error(fmt.Sprintf("Error saving token: %v", err))
+		return 1
+	}
+
 	if l.json || l.template != "" {
 		out, err := Format(l.json, l.template, token)
 		if err != nil {
@@ -205,10 +214,35 @@ func (l *LoginCommand) Run(args []string) int {
 	}
 
 	l.Ui.Output(fmt.Sprintf("Successfully logged in via %s and %s\n", methodType, l.authMethodName))
+	if saved != "" {
+		l.Ui.Output(fmt.Sprintf("Saved the token for context %q\n", saved))
+	}
 	outputACLToken(l.Ui, token)
 	return 0
 }
 
+// saveToken saves the token in the credential store of the CLI context in
+// use, and returns the name of the context if any.
+func (l *LoginCommand) saveToken(token *api.ACLToken) (string, error) {
+	store, err := clicontext.NewStore("")
+	if err != nil {
+		return "", err
+	}
+	config, err := store.Load()
+	if err != nil {
+		return "", err
+	}
+	active, err := store.Active()
+	if err != nil || active == nil {
+		return "", err
+	}
+
+	if err := store.Credentials(config).Store(active.Name, token.SecretID); err != nil {
+		return "", err
+	}
+	return active.Name, nil
+}
+
 func (l *LoginCommand) loginOIDC(ctx context.Context, client *api.Client) (*api.ACLToken, error) {
 
 	callbackServer, err := oidc.NewCallbackServer(l.callbackAddr)
@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/command/clicontext"
+	"github.com/posener/complete"
+)
+
+type LogoutCommand struct {
+	Meta
+}
+
+func (c *LogoutCommand) Help() string {
+	helpText := `
+Usage: nomad logout
+
+  Logout is used to remove the ACL token of the CLI context in use from the
+  credential store of the CLI. The token itself is not revoked; it remains
+  valid until it expires or is deleted with "nomad acl token delete".
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *LogoutCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func (c *LogoutCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *LogoutCommand) Synopsis() string {
+	return "Remove the ACL token of the CLI context"
+}
+
+func (c *LogoutCommand) Name() string { return "logout" }
+
+func (c *LogoutCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if args = flags.Args(); len(args) > 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	store, err := clicontext.NewStore("")
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	config, err := store.Load()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading contexts: %s", err))
+		return 1
+	}
+	active, err := store.Active()
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if active == nil {
+		c.Ui.Error(`No CLI context in use, see "nomad context use"`)
+		return 1
+	}
+
+	if err := store.Credentials(config).Erase(active.Name); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error erasing token: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully logged out of context %q", active.Name))
+	return 0
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/command/clicontext"
 	"github.com/hashicorp/nomad/helper/pointer"
 	colorable "github.com/mattn/go-colorable"
 	"github.com/mitchellh/cli"
@@ -64,6 +65,12 @@ type Meta struct {
 	clientKey     string
 	tlsServerName string
 	insecure      bool
+
+	// cliContext is the active CLI context and cliContextToken its token,
+	// loaded once by clientConfig.
+	cliContext       *clicontext.Context
+	cliContextToken  string
+	cliContextLoaded bool
 }
 
 // FlagSet returns a FlagSet with the common flags that every
@@ -127,6 +134,7 @@ type ApiClientFactory func() (*api.Client, error)
 // the default command line arguments and env vars.
 func (m *Meta) clientConfig() *api.Config {
 	config := api.DefaultConfig()
+	m.applyContext(config)
 
 	if m.flagAddress != "" {
 		config.Address = m.flagAddress
@@ -171,6 +179,57 @@ func (m *Meta) clientConfig() *api.Config {
 	return config
 }
 
+// loadContext loads the active CLI context and its token, if any.
+func (m *Meta) loadContext() {
+	if m.cliContextLoaded {
+		return
+	}
+	m.cliContextLoaded = true
+
+	store, err := clicontext.NewStore("")
+	if err != nil {
+		return
+	}
+	config, err := store.Load()
+	if err == nil {
+		m.cliContext, err = store.Active()
+	}
+	if err == nil && m.cliContext != nil {
+		m.cliContextToken, err = store.Credentials(config).Get(m.cliContext.Name)
+	}
+	if err != nil && m.Ui != nil {
+		m.Ui.Warn(fmt.Sprintf("Error loading CLI context: %v", err))
+	}
+}
+
+// applyContext sets the configuration of the active CLI context that isn't
+// overridden by environment variables. Flags override both.
+func (m *Meta) applyContext(config *api.Config) {
+	m.loadContext()
+	c := m.cliContext
+	if c == nil {
+		return
+	}
+
+	set := func(env string, field *string, value string) {
+		if value != "" && os.Getenv(env) == "" {
+			*field = value
+		}
+	}
+	set("NOMAD_ADDR", &config.Address, c.Address)
+	set("NOMAD_REGION", &config.Region, c.Region)
+	set("NOMAD_NAMESPACE", &config.Namespace, c.Namespace)
+	set("NOMAD_TOKEN", &config.SecretID, m.cliContextToken)
+	set("NOMAD_CACERT", &config.TLSConfig.CACert, c.CACert)
+	set("NOMAD_CAPATH", &config.TLSConfig.CAPath, c.CAPath)
+	set("NOMAD_CLIENT_CERT", &config.TLSConfig.ClientCert, c.ClientCert)
+	set("NOMAD_CLIENT_KEY", &config.TLSConfig.ClientKey, c.ClientKey)
+	set("NOMAD_TLS_SERVER_NAME", &config.TLSConfig.TLSServerName, c.TLSServerName)
+	if c.Insecure && os.Getenv("NOMAD_SKIP_VERIFY") == "" {
+		config.TLSConfig.Insecure = true
+	}
+}
+
 func (m *Meta) Client() (*api.Client, error) {
 	return api.NewClient(m.clientConfig())
 }
@@ -229,6 +229,7 @@ func parseNamespaceSpecImpl(result *api.Namespace, list *ast.ObjectList) error {
 	delete(m, "node_pool_config")
 	delete(m, "vault")
 	delete(m, "consul")
+	delete(m, "token_exchange")
 
 	// Decode the rest
 	if err := mapstructure.WeakDecode(m, result); err != nil {
@@ -299,6 +300,30 @@ func parseNamespaceSpecImpl(result *api.Namespace, list *ast.ObjectList) error {
 		}
 	}
 
+	if teObj := list.Filter("token_exchange"); len(teObj.Items) > 0 {
+		for _, o := range teObj.Elem().Items {
+			var m map[string]interface{}
+			if err := hcl.DecodeObject(&m, o.Val); err != nil {
+				return err
+			}
+
+			var teConfig api.NamespaceTokenExchangeConfiguration
+			dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+				WeaklyTypedInput: true,
+				Result:           &teConfig,
+			})
+			if err != nil {
+				return err
+			}
+			if err := dec.Decode(m); err != nil {
+				return err
+			}
+			result.TokenExchangeConfiguration = &teConfig
+			break
+		}
+	}
+
 	if metaO := list.Filter("meta"); len(metaO.Items) > 0 {
 		for _, o := range metaO.Elem().Items {
 			var m map[string]interface{}
@@ -6,6 +6,7 @@ package command
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/ci"
@@ -126,6 +127,24 @@ meta {
 				},
 			},
 		},
+		{
+			name: "token exchange",
+			input: `
+name = "exchange"
+
+token_exchange {
+  audiences = ["vault.io", "*.example.com"]
+  max_ttl   = "30m"
+}
+`,
+			expected: &api.Namespace{
+				Name: "exchange",
+				TokenExchangeConfiguration: &api.NamespaceTokenExchangeConfiguration{
+					Audiences: []string{"vault.io", "*.example.com"},
+					MaxTTL:    30 * time.Minute,
+				},
+			},
+		},
 		{
 			name:  "minimal",
 			input: `name = "test-small"`,
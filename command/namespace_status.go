@@ -194,6 +194,18 @@ func (c *NamespaceStatusCommand) Run(args []string) int {
 		c.Ui.Output(formatKV(cConfigOut))
 	}
 
+	if ns.TokenExchangeConfiguration != nil {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Token Exchange Configuration[reset]"))
+		teConfig := ns.TokenExchangeConfiguration
+		teConfigOut := []string{
+			fmt.Sprintf("Audiences|%s", strings.Join(teConfig.Audiences, ", ")),
+		}
+		if teConfig.MaxTTL > 0 {
+			teConfigOut = append(teConfigOut, fmt.Sprintf("Max TTL|%s", teConfig.MaxTTL))
+		}
+		c.Ui.Output(formatKV(teConfigOut))
+	}
+
 	return 0
 }
 
@@ -6,6 +6,7 @@ package command
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/helper/pointer"
@@ -18,7 +19,7 @@ type NodeMetaApplyCommand struct {
 
 func (c *NodeMetaApplyCommand) Help() string {
 	helpText := `
-Usage: nomad node meta apply [-node-id ...] [-unset ...] key1=value1 ... kN=vN
+Usage: nomad node meta apply [-node-id ...] [-unset ...] [-ttl ...] key1=value1 ... kN=vN
 
 	Modify a node's metadata. This command only applies to client agents, and can
 	be used to update the scheduling metadata the node registers.
@@ -39,6 +40,10 @@ Node Meta Apply Options:
   -unset key1,...,keyN
     Unset the comma separated list of keys.
 
+  -ttl
+    Duration after which the keys set are removed from the node, restoring
+    their static value if any. Defaults to 0, which never expires the keys.
+
   Example:
     $ nomad node meta apply -unset testing,tempvar ready=1 role=preinit-db
 `
@@ -53,11 +58,13 @@ func (c *NodeMetaApplyCommand) Name() string { return "node meta apply" }
 
 func (c *NodeMetaApplyCommand) Run(args []string) int {
 	var unset, nodeID string
+	var ttl time.Duration
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.StringVar(&unset, "unset", "", "")
 	flags.StringVar(&nodeID, "node-id", "", "")
+	flags.DurationVar(&ttl, "ttl", 0, "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -92,6 +99,7 @@ func (c *NodeMetaApplyCommand) Run(args []string) int {
 	req := api.NodeMetaApplyRequest{
 		NodeID: nodeID,
 		Meta:   meta,
+		TTL:    ttl,
 	}
 
 	if _, err := client.Nodes().Meta().Apply(&req, nil); err != nil {
@@ -107,6 +115,7 @@ func (c *NodeMetaApplyCommand) AutocompleteFlags() complete.Flags {
 		complete.Flags{
 			"-node-id": complete.PredictNothing,
 			"-unset":   complete.PredictNothing,
+			"-ttl":     complete.PredictNothing,
 		})
 }
 
@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2/hclsimple"
 	"github.com/hashicorp/nomad/api"
@@ -114,6 +115,9 @@ func (c *NodePoolApplyCommand) Run(args []string) int {
 	} else {
 		err = hclsimple.Decode(path, content, nil, &poolSpec)
 	}
+	if err == nil && !jsonInput {
+		err = parseNodePoolHeartbeatConfig(poolSpec.NodePool)
+	}
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to parse input content: %v", err))
 		return 1
@@ -139,3 +143,32 @@ func (c *NodePoolApplyCommand) Run(args []string) int {
 type nodePoolSpec struct {
 	NodePool *api.NodePool `hcl:"node_pool,block"`
 }
+
+// parseNodePoolHeartbeatConfig parses the durations of the heartbeat block of
+// a node pool specification.
+func parseNodePoolHeartbeatConfig(pool *api.NodePool) error {
+	if pool == nil || pool.HeartbeatConfig == nil {
+		return nil
+	}
+	hb := pool.HeartbeatConfig
+
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"min_heartbeat_ttl", hb.MinHeartbeatTTLHCL, &hb.MinHeartbeatTTL},
+		{"heartbeat_grace", hb.HeartbeatGraceHCL, &hb.HeartbeatGrace},
+		{"max_client_disconnect", hb.MaxClientDisconnectHCL, &hb.MaxClientDisconnect},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", d.name, err)
+		}
+		*d.dst = v
+	}
+	return nil
+}
@@ -9,8 +9,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/mitchellh/cli"
 	"github.com/shoenig/test"
 	"github.com/shoenig/test/must"
@@ -64,6 +66,11 @@ node_pool "dev" {
   meta {
     test = "true"
   }
+
+  heartbeat {
+    min_heartbeat_ttl     = "5s"
+    max_client_disconnect = "1h"
+  }
 }`
 	_, err = file.WriteString(hclTestFile)
 	must.NoError(t, err)
@@ -78,6 +85,10 @@ node_pool "dev" {
 	must.NotNil(t, got)
 	must.NotNil(t, got.Meta)
 	must.Eq(t, "true", got.Meta["test"])
+	must.Eq(t, &structs.NodePoolHeartbeatConfig{
+		MinHeartbeatTTL:     5 * time.Second,
+		MaxClientDisconnect: time.Hour,
+	}, got.HeartbeatConfig)
 
 	// Create node pool with JSON file.
 	jsonTestFile := `
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/posener/complete"
 )
@@ -144,5 +145,23 @@ func (c *NodePoolInfoCommand) Run(args []string) int {
 		c.Ui.Output("No scheduler configuration")
 	}
 
+	if hb := pool.HeartbeatConfig; hb != nil {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Heartbeat Configuration[reset]"))
+		c.Ui.Output(formatKV([]string{
+			fmt.Sprintf("Min Heartbeat TTL|%s", formatNodePoolDuration(hb.MinHeartbeatTTL)),
+			fmt.Sprintf("Heartbeat Grace|%s", formatNodePoolDuration(hb.HeartbeatGrace)),
+			fmt.Sprintf("Max Client Disconnect|%s", formatNodePoolDuration(hb.MaxClientDisconnect)),
+		}))
+	}
+
 	return 0
 }
+
+// formatNodePoolDuration formats a duration of the node pool configuration,
+// where zero values use the server configuration.
+func formatNodePoolDuration(d time.Duration) string {
+	if d == 0 {
+		return "<none>"
+	}
+	return d.String()
+}
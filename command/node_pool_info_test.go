@@ -52,6 +52,7 @@ No scheduler configuration`
 	dev1JsonOutput := `
 {
     "Description": "Test pool",
+    "HeartbeatConfig": null,
     "Meta": {
         "env": "test"
     },
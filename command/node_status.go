@@ -37,6 +37,7 @@ type NodeStatusCommand struct {
 	list_allocs bool
 	self        bool
 	stats       bool
+	utilization bool
 	json        bool
 	perPage     int
 	pageToken   string
@@ -75,6 +76,11 @@ Node Status Options:
   -allocs
     Display a count of running allocations for each node.
 
+  -utilization
+    Display the CPU load and memory pressure reported by the nodes, averaged
+    over 5 minutes. When a single node is queried, display the trend of its
+    utilization over the last hour, or over the last 24 hours with -verbose.
+
   -short
     Display short output. Used only when a single node is being
     queried, and drops verbose output about node allocations.
@@ -113,18 +119,19 @@ func (c *NodeStatusCommand) Synopsis() string {
 func (c *NodeStatusCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"-allocs":     complete.PredictNothing,
-			"-filter":     complete.PredictAnything,
-			"-json":       complete.PredictNothing,
-			"-per-page":   complete.PredictAnything,
-			"-page-token": complete.PredictAnything,
-			"-self":       complete.PredictNothing,
-			"-short":      complete.PredictNothing,
-			"-stats":      complete.PredictNothing,
-			"-t":          complete.PredictAnything,
-			"-os":         complete.PredictAnything,
-			"-quiet":      complete.PredictAnything,
-			"-verbose":    complete.PredictNothing,
+			"-allocs":      complete.PredictNothing,
+			"-filter":      complete.PredictAnything,
+			"-json":        complete.PredictNothing,
+			"-per-page":    complete.PredictAnything,
+			"-page-token":  complete.PredictAnything,
+			"-self":        complete.PredictNothing,
+			"-short":       complete.PredictNothing,
+			"-stats":       complete.PredictNothing,
+			"-utilization": complete.PredictNothing,
+			"-t":           complete.PredictAnything,
+			"-os":          complete.PredictAnything,
+			"-quiet":       complete.PredictAnything,
+			"-verbose":     complete.PredictNothing,
 		})
 }
 
@@ -156,6 +163,7 @@ func (c *NodeStatusCommand) Run(args []string) int {
 	flags.BoolVar(&c.list_allocs, "allocs", false, "")
 	flags.BoolVar(&c.self, "self", false, "")
 	flags.BoolVar(&c.stats, "stats", false, "")
+	flags.BoolVar(&c.utilization, "utilization", false, "")
 	flags.BoolVar(&c.json, "json", false, "")
 	flags.StringVar(&c.tmpl, "t", "", "")
 	flags.StringVar(&c.filter, "filter", "", "")
@@ -266,6 +274,16 @@ func (c *NodeStatusCommand) Run(args []string) int {
 			out[0] += "|Running Allocs"
 		}
 
+		var utilization map[string]*api.NodeUtilizationRollup
+		if c.utilization {
+			out[0] += "|CPU Load|Memory Pressure"
+			utilization, _, err = client.Nodes().UtilizationList(nil)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Error querying node utilization: %s", err))
+				return 1
+			}
+		}
+
 		for i, node := range nodes {
 			out[i+1] = fmt.Sprintf("%s|%s|%s|%s|%s",
 				limit(node.ID, c.length),
@@ -294,6 +312,16 @@ func (c *NodeStatusCommand) Run(args []string) int {
 				out[i+1] += fmt.Sprintf("|%v",
 					len(numAllocs))
 			}
+
+			if c.utilization {
+				if rollup := utilization[node.ID]; rollup != nil {
+					out[i+1] += fmt.Sprintf("|%s|%s",
+						formatUtilization(rollup.CPULoadAvg),
+						formatUtilization(rollup.MemoryPressureAvg))
+				} else {
+					out[i+1] += "|<none>|<none>"
+				}
+			}
 		}
 
 		// Dump the output
@@ -567,6 +595,13 @@ func (c *NodeStatusCommand) formatNode(client *api.Client, node *api.Node) int {
 		c.Ui.Output(c.Colorize().Color("\n[bold]Device Resource Utilization[reset]"))
 		c.Ui.Output(formatList(getDeviceResourcesForNode(hostStats.DeviceStats, node)))
 	}
+	if c.utilization {
+		if err := c.outputNodeUtilization(client, node); err != nil {
+			c.Ui.Output("")
+			c.Ui.Error(fmt.Sprintf("error fetching node utilization: %v", err))
+		}
+	}
+
 	if hostStats != nil && c.stats {
 		c.Ui.Output(c.Colorize().Color("\n[bold]CPU Stats[reset]"))
 		c.printCpuStats(hostStats)
@@ -588,6 +623,44 @@ func (c *NodeStatusCommand) formatNode(client *api.Client, node *api.Node) int {
 	return 0
 }
 
+// outputNodeUtilization outputs the trend of the utilization of the node, from
+// the rollups kept by the servers.
+func (c *NodeStatusCommand) outputNodeUtilization(client *api.Client, node *api.Node) error {
+	rollups, _, err := client.Nodes().Utilization(node.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	// Only show the last hour unless running in verbose mode
+	if n := len(rollups); !c.verbose && n > 12 {
+		rollups = rollups[n-12:]
+	}
+
+	c.Ui.Output(c.Colorize().Color("\n[bold]Utilization Trend[reset]"))
+	if len(rollups) == 0 {
+		c.Ui.Output("No utilization reported")
+		return nil
+	}
+
+	out := make([]string, 0, len(rollups)+1)
+	out = append(out, "Time|CPU Load Avg|CPU Load Max|Memory Pressure Avg|Memory Pressure Max")
+	for _, rollup := range rollups {
+		out = append(out, fmt.Sprintf("%s|%s|%s|%s|%s",
+			formatUnixNanoTime(rollup.Start),
+			formatUtilization(rollup.CPULoadAvg),
+			formatUtilization(rollup.CPULoadMax),
+			formatUtilization(rollup.MemoryPressureAvg),
+			formatUtilization(rollup.MemoryPressureMax)))
+	}
+	c.Ui.Output(formatList(out))
+	return nil
+}
+
+// formatUtilization formats a CPU load or memory pressure as a percentage.
+func formatUtilization(v float64) string {
+	return fmt.Sprintf("%.0f%%", v*100)
+}
+
 func (c *NodeStatusCommand) outputAllocInfo(node *api.Node, nodeAllocs []*api.Allocation) error {
 	c.Ui.Output(c.Colorize().Color("\n[bold]Allocations[reset]"))
 	c.Ui.Output(formatAllocList(nodeAllocs, c.verbose, c.length))
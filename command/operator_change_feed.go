@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type OperatorChangeFeedCommand struct {
+	Meta
+}
+
+func (c *OperatorChangeFeedCommand) Help() string {
+	helpText := `
+Usage: nomad operator change-feed <subcommand> [options] [args]
+
+  This command groups subcommands for consuming the change feed of the
+  servers, which streams every change to the jobs, allocations, evaluations,
+  deployments, nodes, node pools, and namespaces of the cluster. The servers
+  must be configured with "enable_change_feed = true".
+
+  Export the change feed to a SQLite database:
+
+      $ nomad operator change-feed export nomad.sqlite
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorChangeFeedCommand) Synopsis() string {
+	return "Interact with the change feed of the servers"
+}
+
+func (c *OperatorChangeFeedCommand) Name() string { return "operator change-feed" }
+
+func (c *OperatorChangeFeedCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
@@ -0,0 +1,439 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+
+	// register the sqlite driver of database/sql
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// changeFeedObjectUpserted and changeFeedObjectDeleted are the types of
+	// the change feed events.
+	changeFeedObjectUpserted = "ObjectUpserted"
+	changeFeedObjectDeleted  = "ObjectDeleted"
+)
+
+// changeFeedSchema creates the tables of the database the change feed is
+// exported to. The objects table is the current state of the cluster, where
+// deleted objects are kept and flagged, and the changes table is the history
+// of the changes streamed since the export started.
+const changeFeedSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS objects (
+	tbl          TEXT NOT NULL,
+	namespace    TEXT NOT NULL,
+	key          TEXT NOT NULL,
+	modify_index INTEGER NOT NULL,
+	deleted      INTEGER NOT NULL,
+	object       TEXT NOT NULL,
+	PRIMARY KEY (tbl, namespace, key)
+);
+CREATE TABLE IF NOT EXISTS changes (
+	idx       INTEGER NOT NULL,
+	type      TEXT NOT NULL,
+	tbl       TEXT NOT NULL,
+	namespace TEXT NOT NULL,
+	key       TEXT NOT NULL,
+	object    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS changes_idx ON changes (idx);
+`
+
+type OperatorChangeFeedExportCommand struct {
+	Meta
+}
+
+func (c *OperatorChangeFeedExportCommand) Help() string {
+	helpText := `
+Usage: nomad operator change-feed export [options] <path>
+
+  Exports the change feed of the servers to a SQLite database, to maintain a
+  read model of the cluster for analytics and long-term history. The servers
+  must be configured with "enable_change_feed = true".
+
+  On its first run, the command seeds the database with the current jobs,
+  allocations, evaluations, deployments, nodes, node pools, and namespaces of
+  the cluster. It then streams their changes into the database until it's
+  interrupted, and resumes from the last change exported when it's run again.
+  Changes older than the event buffer of the servers can't be resumed from.
+
+  The database has the following tables:
+
+    objects: the last version of each object, including the objects deleted
+             since the export started.
+
+    changes: every change streamed since the export started.
+
+  When ACLs are enabled, this command requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault|usageOptsNoNamespace)
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorChangeFeedExportCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags(FlagSetClient)
+}
+
+func (c *OperatorChangeFeedExportCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *OperatorChangeFeedExportCommand) Synopsis() string {
+	return "Export the change feed of the servers to a SQLite database"
+}
+
+func (c *OperatorChangeFeedExportCommand) Name() string { return "operator change-feed export" }
+
+func (c *OperatorChangeFeedExportCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <path>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	store, err := openChangeFeedStore(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening database: %s", err))
+		return 1
+	}
+	defer store.Close()
+
+	index, err := store.lastIndex()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading database: %s", err))
+		return 1
+	}
+	if index == 0 {
+		index, err = bootstrapChangeFeed(client, store)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error exporting the state of the cluster: %s", err))
+			return 1
+		}
+		c.Ui.Output(fmt.Sprintf("Exported the state of the cluster at index %d", index))
+	} else {
+		c.Ui.Output(fmt.Sprintf("Resuming the export from index %d", index))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalCh)
+
+	go func() {
+		select {
+		case <-signalCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := exportChangeFeed(ctx, client, store, index); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error exporting the change feed: %s", err))
+		return 1
+	}
+	return 0
+}
+
+// changeFeedObject is a version of an object of the state of the cluster.
+type changeFeedObject struct {
+	Index     uint64
+	Type      string
+	Table     string
+	Namespace string
+	Key       string
+	Object    json.RawMessage
+}
+
+// changeFeedStore is the SQLite database the change feed is exported to.
+type changeFeedStore struct {
+	db *sql.DB
+}
+
+func openChangeFeedStore(path string) (*changeFeedStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only allows one writer
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(changeFeedSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &changeFeedStore{db: db}, nil
+}
+
+func (s *changeFeedStore) Close() error {
+	return s.db.Close()
+}
+
+// lastIndex returns the index of the last change exported, or zero if nothing
+// was exported yet.
+func (s *changeFeedStore) lastIndex() (uint64, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'last_index'`).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// apply writes the objects and sets the index of the last change exported in
+// one transaction. An object isn't overwritten by an older version, which
+// happens when the changes made while the state of the cluster was exported
+// are streamed. If history is set the changes are also recorded.
+func (s *changeFeedStore) apply(lastIndex uint64, objects []*changeFeedObject, history bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, obj := range objects {
+		_, err := tx.Exec(`
+INSERT INTO objects (tbl, namespace, key, modify_index, deleted, object)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (tbl, namespace, key) DO UPDATE SET
+	modify_index = excluded.modify_index,
+	deleted = excluded.deleted,
+	object = excluded.object
+WHERE excluded.modify_index >= objects.modify_index`,
+			obj.Table, obj.Namespace, obj.Key, obj.Index,
+			obj.Type == changeFeedObjectDeleted, string(obj.Object))
+		if err != nil {
+			return fmt.Errorf("failed to write %s %q: %v", obj.Table, obj.Key, err)
+		}
+
+		if !history {
+			continue
+		}
+		_, err = tx.Exec(`
+INSERT INTO changes (idx, type, tbl, namespace, key, object)
+VALUES (?, ?, ?, ?, ?, ?)`,
+			obj.Index, obj.Type, obj.Table, obj.Namespace, obj.Key, string(obj.Object))
+		if err != nil {
+			return fmt.Errorf("failed to record change of %s %q: %v", obj.Table, obj.Key, err)
+		}
+	}
+
+	_, err = tx.Exec(`
+INSERT INTO meta (key, value) VALUES ('last_index', ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		strconv.FormatUint(lastIndex, 10))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// changeFeedLists are the tables of the state of the cluster exported on the
+// first run, with the endpoints listing and reading their objects. If info is
+// empty the objects listed are complete.
+var changeFeedLists = []struct {
+	table string
+	list  string
+	info  string
+}{
+	{table: "namespaces", list: "/v1/namespaces"},
+	{table: "node_pools", list: "/v1/node/pools"},
+	{table: "nodes", list: "/v1/nodes", info: "/v1/node/"},
+	{table: "jobs", list: "/v1/jobs", info: "/v1/job/"},
+	{table: "allocs", list: "/v1/allocations", info: "/v1/allocation/"},
+	{table: "evals", list: "/v1/evaluations"},
+	{table: "deployment", list: "/v1/deployments"},
+}
+
+// changeFeedStub holds the fields identifying an object of any table.
+type changeFeedStub struct {
+	ID        string
+	Name      string
+	Namespace string
+}
+
+func (s *changeFeedStub) key() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Name
+}
+
+// bootstrapChangeFeed exports the current state of the cluster to the store,
+// and returns the index the change feed must be streamed from.
+func bootstrapChangeFeed(client *api.Client, store *changeFeedStore) (uint64, error) {
+	var start uint64
+	var objects []*changeFeedObject
+	for _, list := range changeFeedLists {
+		var raws []json.RawMessage
+		qm, err := client.Raw().Query(list.list, &raws, &api.QueryOptions{Namespace: "*"})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list %s: %v", list.table, err)
+		}
+		if qm.LastIndex > 0 && (start == 0 || qm.LastIndex < start) {
+			start = qm.LastIndex
+		}
+
+		for _, raw := range raws {
+			var stub changeFeedStub
+			if err := json.Unmarshal(raw, &stub); err != nil {
+				return 0, fmt.Errorf("failed to decode %s: %v", list.table, err)
+			}
+
+			if list.info != "" {
+				raw, err = changeFeedInfo(client, list.info, &stub)
+				if err != nil {
+					return 0, fmt.Errorf("failed to read %s %q: %v", list.table, stub.key(), err)
+				}
+				if raw == nil {
+					// Deleted since it was listed
+					continue
+				}
+			}
+
+			// The objects are written at the index of their list, so the
+			// changes made since are applied when they're streamed
+			objects = append(objects, &changeFeedObject{
+				Index:     qm.LastIndex,
+				Type:      changeFeedObjectUpserted,
+				Table:     list.table,
+				Namespace: stub.Namespace,
+				Key:       stub.key(),
+				Object:    raw,
+			})
+		}
+	}
+
+	// The change feed is streamed from the oldest list, so no change made
+	// while the tables were listed is missed
+	if err := store.apply(start, objects, false); err != nil {
+		return 0, err
+	}
+	return start, nil
+}
+
+// changeFeedInfo reads an object listed as a stub, or returns nil if it
+// doesn't exist anymore.
+func changeFeedInfo(client *api.Client, endpoint string, stub *changeFeedStub) (json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+	_, err := client.Raw().Query(endpoint+url.PathEscape(stub.key()), &obj,
+		&api.QueryOptions{Namespace: stub.Namespace})
+	if err != nil {
+		var respErr api.UnexpectedResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode() == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Like in the change feed, allocations don't include their job
+	delete(obj, "Job")
+	return json.Marshal(obj)
+}
+
+// exportChangeFeed streams the changes made after index to the store, until
+// the context is canceled.
+func exportChangeFeed(ctx context.Context, client *api.Client, store *changeFeedStore, index uint64) error {
+	topics := map[api.Topic][]string{api.TopicChangeFeed: {"*"}}
+	eventsCh, err := client.EventStream().Stream(ctx, topics, index+1, &api.QueryOptions{Namespace: "*"})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case events, ok := <-eventsCh:
+			if !ok {
+				return nil
+			}
+			if events.Err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return events.Err
+			}
+			if events.IsHeartbeat() || events.Index <= index {
+				continue
+			}
+
+			objects := make([]*changeFeedObject, 0, len(events.Events))
+			for _, event := range events.Events {
+				if event.Topic != api.TopicChangeFeed {
+					continue
+				}
+				obj, err := changeFeedObjectFromEvent(&event)
+				if err != nil {
+					return err
+				}
+				objects = append(objects, obj)
+			}
+			if err := store.apply(events.Index, objects, true); err != nil {
+				return err
+			}
+			index = events.Index
+		}
+	}
+}
+
+func changeFeedObjectFromEvent(event *api.Event) (*changeFeedObject, error) {
+	table, ok := event.Payload["Table"].(string)
+	if !ok {
+		return nil, fmt.Errorf("change feed event %q at index %d has no table", event.Key, event.Index)
+	}
+	obj, err := json.Marshal(event.Payload["Object"])
+	if err != nil {
+		return nil, err
+	}
+	return &changeFeedObject{
+		Index:     event.Index,
+		Type:      event.Type,
+		Table:     table,
+		Namespace: event.Namespace,
+		Key:       event.Key,
+		Object:    obj,
+	}, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+func TestOperatorChangeFeedExportCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &OperatorChangeFeedExportCommand{}
+}
+
+func TestOperatorChangeFeedExportCommand_Fails(t *testing.T) {
+	ci.Parallel(t)
+	ui := cli.NewMockUi()
+	cmd := &OperatorChangeFeedExportCommand{Meta: Meta{Ui: ui}}
+
+	must.One(t, cmd.Run([]string{"some", "bad", "args"}))
+	must.StrContains(t, ui.ErrorWriter.String(), commandErrorText(cmd))
+}
+
+func TestOperatorChangeFeedExportCommand_Export(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, client, _ := testServer(t, false, func(c *agent.Config) {
+		c.Server.EnableChangeFeed = pointer.Of(true)
+	})
+	defer srv.Shutdown()
+	testutil.WaitForLeader(t, srv.Agent.RPC)
+
+	// a job registered before the export is bootstrapped
+	_, _, err := client.Jobs().Register(testJob("job1"), nil)
+	must.NoError(t, err)
+
+	store, err := openChangeFeedStore(filepath.Join(t.TempDir(), "nomad.sqlite"))
+	must.NoError(t, err)
+	defer store.Close()
+
+	index, err := bootstrapChangeFeed(client, store)
+	must.NoError(t, err)
+	must.Positive(t, index)
+
+	lastIndex, err := store.lastIndex()
+	must.NoError(t, err)
+	must.Eq(t, index, lastIndex)
+	must.Eq(t, 0, changeFeedObjectState(t, store, "jobs", "job1"))
+	must.Eq(t, 0, changeFeedObjectState(t, store, "namespaces", "default"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- exportChangeFeed(ctx, client, store, index)
+	}()
+
+	// the changes made while exporting are streamed
+	_, _, err = client.Jobs().Register(testJob("job2"), nil)
+	must.NoError(t, err)
+	_, _, err = client.Jobs().Deregister("job1", true, nil)
+	must.NoError(t, err)
+
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			return changeFeedObjectState(t, store, "jobs", "job1") == 1 &&
+				changeFeedObjectState(t, store, "jobs", "job2") == 0
+		}),
+		wait.Timeout(10*time.Second),
+		wait.Gap(100*time.Millisecond),
+	))
+
+	cancel()
+	must.NoError(t, <-errCh)
+
+	var changes int
+	err = store.db.QueryRow(`SELECT COUNT(*) FROM changes WHERE tbl = 'jobs' AND key = 'job1' AND type = ?`,
+		changeFeedObjectDeleted).Scan(&changes)
+	must.NoError(t, err)
+	must.Eq(t, 1, changes)
+
+	lastIndex, err = store.lastIndex()
+	must.NoError(t, err)
+	must.Greater(t, index, lastIndex)
+}
+
+// changeFeedObjectState returns the deleted column of an object exported to
+// the store, or -1 if it wasn't exported.
+func changeFeedObjectState(t *testing.T, store *changeFeedStore, table, key string) int {
+	deleted := -1
+	rows, err := store.db.Query(`SELECT deleted FROM objects WHERE tbl = ? AND key = ?`, table, key)
+	must.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		must.NoError(t, rows.Scan(&deleted))
+	}
+	must.NoError(t, rows.Err())
+	return deleted
+}
@@ -6,6 +6,8 @@ package command
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/go-hclog"
@@ -23,6 +25,15 @@ func (c *OperatorClientStateCommand) Help() string {
 Usage: nomad operator client-state <path_to_nomad_dir>
 
   Emits a representation of the stored client state in JSON format.
+
+  The client state of a stopped client can also be inspected, repaired, and
+  migrated to the current schema with the subcommands:
+
+    $ nomad operator client-state inspect <path_to_client_dir>
+    $ nomad operator client-state repair <path_to_client_dir>
+    $ nomad operator client-state migrate <path_to_client_dir>
+
+  Please see the individual subcommand help for detailed usage information.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -130,3 +141,22 @@ type taskState struct {
 	RemoteState any
 	DriverState interface{}
 }
+
+// openClientState opens the client state database of a stopped client. The
+// path is either the client dir holding the state database or the data dir of
+// the agent. Unlike the client, it doesn't create a missing database.
+func openClientState(path string) (*state.BoltStateDB, error) {
+	stateDir := path
+	if _, err := os.Stat(filepath.Join(path, "client", "state.db")); err == nil {
+		stateDir = filepath.Join(path, "client")
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "state.db")); err != nil {
+		return nil, fmt.Errorf("no client state found in %s: %v", path, err)
+	}
+
+	db, err := state.NewBoltStateDB(hclog.NewNullLogger(), stateDir)
+	if err != nil {
+		return nil, err
+	}
+	return db.(*state.BoltStateDB), nil
+}
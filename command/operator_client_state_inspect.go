@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type OperatorClientStateInspectCommand struct {
+	Meta
+}
+
+func (c *OperatorClientStateInspectCommand) Help() string {
+	helpText := `
+Usage: nomad operator client-state inspect [options] <path>
+
+  Lists the allocations and tasks stored in the state of a client, along with
+  the entries that can't be decoded. The path is the data dir of the agent or
+  its client dir. The client must be stopped.
+
+Inspect Options:
+
+  -json
+    Output the client state in its JSON format.
+
+  -t
+    Format and display the client state using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorClientStateInspectCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-json": complete.PredictNothing,
+		"-t":    complete.PredictAnything,
+	}
+}
+
+func (c *OperatorClientStateInspectCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("*")
+}
+
+func (c *OperatorClientStateInspectCommand) Synopsis() string {
+	return "Inspect the state of a stopped client"
+}
+
+func (c *OperatorClientStateInspectCommand) Name() string { return "operator client-state inspect" }
+
+func (c *OperatorClientStateInspectCommand) Run(args []string) int {
+	var json bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <path>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	db, err := openClientState(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening client state: %s", err))
+		return 1
+	}
+	defer db.Close()
+
+	summary, err := db.Inspect()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error inspecting client state: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, summary)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(formatKV([]string{
+		fmt.Sprintf("Schema Version|%s", summary.Version),
+		fmt.Sprintf("Needs Migration|%t", summary.NeedsUpgrade),
+		fmt.Sprintf("Allocations|%d", len(summary.Allocations)),
+	}))
+
+	if len(summary.Allocations) == 0 {
+		return 0
+	}
+
+	allocs := []string{"Alloc ID|Job ID|Task Group|Status|Errors"}
+	tasks := []string{"Alloc ID|Task|Driver|State|Handle|Errors"}
+	var errs []string
+	for _, as := range summary.Allocations {
+		allocs = append(allocs, fmt.Sprintf("%s|%s|%s|%s|%d",
+			limit(as.ID, shortId), as.JobID, as.TaskGroup, as.ClientStatus, len(as.Errors)))
+		for _, err := range as.Errors {
+			errs = append(errs, fmt.Sprintf("alloc %s: %s", as.ID, err))
+		}
+
+		for _, ts := range as.Tasks {
+			tasks = append(tasks, fmt.Sprintf("%s|%s|%s|%s|%t|%d",
+				limit(as.ID, shortId), ts.Name, ts.Driver, ts.State, ts.HasHandle, len(ts.Errors)))
+			for _, err := range ts.Errors {
+				errs = append(errs, fmt.Sprintf("alloc %s task %s: %s", as.ID, ts.Name, err))
+			}
+		}
+	}
+
+	c.Ui.Output(c.Colorize().Color("\n[bold]Allocations[reset]"))
+	c.Ui.Output(formatList(allocs))
+	c.Ui.Output(c.Colorize().Color("\n[bold]Tasks[reset]"))
+	c.Ui.Output(formatList(tasks))
+
+	if len(errs) > 0 {
+		c.Ui.Output(c.Colorize().Color("\n[bold]Corrupt Entries[reset]"))
+		c.Ui.Output(strings.Join(errs, "\n"))
+		c.Ui.Output("\nRun \"nomad operator client-state repair\" to drop the corrupt entries.")
+	}
+	return 0
+}
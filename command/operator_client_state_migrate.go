@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type OperatorClientStateMigrateCommand struct {
+	Meta
+}
+
+func (c *OperatorClientStateMigrateCommand) Help() string {
+	helpText := `
+Usage: nomad operator client-state migrate <path>
+
+  Migrates the state of a client to the current schema version. Clients
+  migrate their state when they start, so this command is only needed to
+  migrate and inspect the state of a stopped client before it's started.
+
+  The path is the data dir of the agent or its client dir. The client must be
+  stopped. The state is backed up to "state.db.backup" in the client dir
+  before it's migrated.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorClientStateMigrateCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func (c *OperatorClientStateMigrateCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("*")
+}
+
+func (c *OperatorClientStateMigrateCommand) Synopsis() string {
+	return "Migrate the state of a stopped client to the current schema"
+}
+
+func (c *OperatorClientStateMigrateCommand) Name() string { return "operator client-state migrate" }
+
+func (c *OperatorClientStateMigrateCommand) Run(args []string) int {
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <path>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	db, err := openClientState(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening client state: %s", err))
+		return 1
+	}
+	defer db.Close()
+
+	summary, err := db.Inspect()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error inspecting client state: %s", err))
+		return 1
+	}
+	if !summary.NeedsUpgrade {
+		c.Ui.Output(fmt.Sprintf("Client state is already at schema version %s", summary.Version))
+		return 0
+	}
+
+	if err := db.Upgrade(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error migrating client state: %s", err))
+		return 1
+	}
+
+	summary, err = db.Inspect()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error inspecting client state: %s", err))
+		return 1
+	}
+	c.Ui.Output(fmt.Sprintf("Migrated client state to schema version %s", summary.Version))
+	return 0
+}
@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type OperatorClientStateRepairCommand struct {
+	Meta
+}
+
+func (c *OperatorClientStateRepairCommand) Help() string {
+	helpText := `
+Usage: nomad operator client-state repair [options] <path>
+
+  Drops the entries of the state of a client that can't be decoded, so the
+  client can restore its remaining allocations and reattach to their running
+  tasks instead of requiring its data dir to be wiped. An allocation that
+  can't be decoded is dropped entirely, while only the corrupt entries of the
+  other allocations and tasks are dropped.
+
+  The path is the data dir of the agent or its client dir. The client must be
+  stopped. The state is backed up to "state.db.repair.backup" in the client
+  dir before it's modified.
+
+Repair Options:
+
+  -dry-run
+    List the entries that would be dropped without modifying the state.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorClientStateRepairCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-dry-run": complete.PredictNothing,
+	}
+}
+
+func (c *OperatorClientStateRepairCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("*")
+}
+
+func (c *OperatorClientStateRepairCommand) Synopsis() string {
+	return "Drop the corrupt entries of the state of a stopped client"
+}
+
+func (c *OperatorClientStateRepairCommand) Name() string { return "operator client-state repair" }
+
+func (c *OperatorClientStateRepairCommand) Run(args []string) int {
+	var dryRun bool
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&dryRun, "dry-run", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <path>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	db, err := openClientState(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening client state: %s", err))
+		return 1
+	}
+	defer db.Close()
+
+	actions, err := db.Repair(dryRun)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error repairing client state: %s", err))
+		return 1
+	}
+
+	if len(actions) == 0 {
+		c.Ui.Output("No corrupt entries found")
+		return 0
+	}
+
+	verb := "Dropped"
+	if dryRun {
+		verb = "Would drop"
+	}
+	for _, action := range actions {
+		c.Ui.Output(fmt.Sprintf("%s %s", verb, action))
+	}
+	return 0
+}
@@ -4,11 +4,17 @@
 package command
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/state"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,3 +36,49 @@ func TestOperatorClientStateCommand(t *testing.T) {
 	require.Equal(t, 0, code)
 	require.Contains(t, ui.OutputWriter.String(), "{}")
 }
+
+func TestOperatorClientStateSubcommands(t *testing.T) {
+	ci.Parallel(t)
+
+	dataDir := t.TempDir()
+	clientDir := filepath.Join(dataDir, "client")
+	must.NoError(t, os.Mkdir(clientDir, 0o700))
+
+	// a missing state isn't created
+	ui := cli.NewMockUi()
+	inspect := &OperatorClientStateInspectCommand{Meta: Meta{Ui: ui}}
+	must.One(t, inspect.Run([]string{dataDir}))
+	must.StrContains(t, ui.ErrorWriter.String(), "no client state found")
+	must.FileNotExists(t, filepath.Join(clientDir, "state.db"))
+
+	alloc := mock.Alloc()
+	db, err := state.NewBoltStateDB(testlog.HCLogger(t), clientDir)
+	must.NoError(t, err)
+	must.NoError(t, db.PutAllocation(alloc))
+	must.NoError(t, db.Close())
+
+	// the state is found from the data dir or the client dir
+	for _, path := range []string{dataDir, clientDir} {
+		ui = cli.NewMockUi()
+		inspect = &OperatorClientStateInspectCommand{Meta: Meta{Ui: ui}}
+		must.Zero(t, inspect.Run([]string{path}))
+		out := ui.OutputWriter.String()
+		must.StrContains(t, out, "Needs Migration = false")
+		must.StrContains(t, out, alloc.ID[:8])
+	}
+
+	ui = cli.NewMockUi()
+	inspect = &OperatorClientStateInspectCommand{Meta: Meta{Ui: ui}}
+	must.Zero(t, inspect.Run([]string{"-json", dataDir}))
+	must.StrContains(t, ui.OutputWriter.String(), `"ID": "`+alloc.ID+`"`)
+
+	ui = cli.NewMockUi()
+	repair := &OperatorClientStateRepairCommand{Meta: Meta{Ui: ui}}
+	must.Zero(t, repair.Run([]string{"-dry-run", dataDir}))
+	must.StrContains(t, ui.OutputWriter.String(), "No corrupt entries found")
+
+	ui = cli.NewMockUi()
+	migrate := &OperatorClientStateMigrateCommand{Meta: Meta{Ui: ui}}
+	must.Zero(t, migrate.Run([]string{dataDir}))
+	must.StrContains(t, ui.OutputWriter.String(), "already at schema version")
+}
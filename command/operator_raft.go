@@ -29,6 +29,10 @@ Usage: nomad operator raft <subcommand> [options]
 
       $ nomad operator raft remove-peer -peer-address "IP:Port"
 
+  Make a Raft peer a non-voting server:
+
+      $ nomad operator raft set-voter -peer-id "ID" -voter=false
+
   Display info about the raft logs in the data directory:
 
       $ nomad operator raft info /var/nomad/data
@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type OperatorRaftSetVoterCommand struct {
+	Meta
+}
+
+func (c *OperatorRaftSetVoterCommand) Help() string {
+	helpText := `
+Usage: nomad operator raft set-voter [options]
+
+  Set whether the Nomad server with the given -peer-id is a voter or a
+  non-voting server.
+
+  Non-voting servers replicate the Raft log and serve stale reads and the event
+  stream, but don't participate in the Raft quorum. They can be used to offload
+  read traffic from the voters without slowing down writes. Autopilot applies
+  the change by demoting or promoting the server. The leader is never demoted,
+  so transfer the leadership before making it a non-voting server.
+
+  Servers started with the non_voting_server configuration are always
+  non-voting servers, regardless of this command.
+
+  This command requires Raft protocol version 3. If ACLs are enabled, this
+  command requires a token with the 'operator:write' capability.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault|usageOptsNoNamespace) + `
+
+Set Voter Options:
+
+  -peer-id="id"
+	The ID of the Nomad server to update.
+
+  -voter=[true|false]
+	Whether the server is a voter. Defaults to true.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorRaftSetVoterCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-peer-id": complete.PredictAnything,
+			"-voter":   complete.PredictSet("true", "false"),
+		})
+}
+
+func (c *OperatorRaftSetVoterCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *OperatorRaftSetVoterCommand) Synopsis() string {
+	return "Set whether a Nomad server is a voter or a non-voting server"
+}
+
+func (c *OperatorRaftSetVoterCommand) Name() string { return "operator raft set-voter" }
+
+func (c *OperatorRaftSetVoterCommand) Run(args []string) int {
+	var peerID string
+	var voter bool
+
+	flags := c.Meta.FlagSet("raft", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	flags.StringVar(&peerID, "peer-id", "", "")
+	flags.BoolVar(&voter, "voter", true, "")
+	if err := flags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse args: %v", err))
+		return 1
+	}
+
+	if len(flags.Args()) != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+	if peerID == "" {
+		c.Ui.Error("An id is required for the peer to update")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	// Set up a client.
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	if err := client.Operator().RaftSetVoter(peerID, voter, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error setting voter status: %v", err))
+		return 1
+	}
+
+	if voter {
+		c.Ui.Output(fmt.Sprintf("Set peer with id %q as a voter", peerID))
+	} else {
+		c.Ui.Output(fmt.Sprintf("Set peer with id %q as a non-voting server", peerID))
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestOperator_Raft_SetVoter_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &OperatorRaftSetVoterCommand{}
+}
+
+func TestOperator_Raft_SetVoter(t *testing.T) {
+	ci.Parallel(t)
+	s, client, addr := testServer(t, false, nil)
+	defer s.Shutdown()
+
+	ui := cli.NewMockUi()
+	c := &OperatorRaftSetVoterCommand{Meta: Meta{Ui: ui}}
+
+	// Missing ID
+	code := c.Run([]string{"-address=" + addr})
+	must.One(t, code)
+	must.StrContains(t, ui.ErrorWriter.String(), "An id is required for the peer to update")
+
+	// Unknown ID
+	ui.ErrorWriter.Reset()
+	code = c.Run([]string{"-address=" + addr, "-peer-id=nope", "-voter=false"})
+	must.One(t, code)
+	must.StrContains(t, ui.ErrorWriter.String(), `id "nope" was not found in the Raft configuration`)
+
+	raftConf, err := client.Operator().RaftGetConfiguration(nil)
+	must.NoError(t, err)
+	must.Len(t, 1, raftConf.Servers)
+	id := raftConf.Servers[0].ID
+
+	code = c.Run([]string{"-address=" + addr, "-peer-id=" + id, "-voter=false"})
+	must.Zero(t, code)
+	must.StrContains(t, ui.OutputWriter.String(), "as a non-voting server")
+
+	conf, _, err := client.Operator().AutopilotGetConfiguration(nil)
+	must.NoError(t, err)
+	must.Eq(t, []string{id}, conf.NonVoters)
+
+	code = c.Run([]string{"-address=" + addr, "-peer-id=" + id})
+	must.Zero(t, code)
+	must.StrContains(t, ui.OutputWriter.String(), "as a voter")
+
+	conf, _, err = client.Operator().AutopilotGetConfiguration(nil)
+	must.NoError(t, err)
+	must.SliceEmpty(t, conf.NonVoters)
+}
@@ -4,13 +4,22 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/nomad/api"
+	flaghelper "github.com/hashicorp/nomad/helper/flags"
 	"github.com/posener/complete"
 )
 
+// jwksVerifyTimeout is the timeout for fetching each JWKS URL when verifying
+// that a prepublished key is visible to external consumers.
+const jwksVerifyTimeout = 10 * time.Second
+
 // OperatorRootKeyringRotateCommand is a Command
 // implementation that rotates the variables encryption key.
 type OperatorRootKeyringRotateCommand struct {
@@ -34,7 +43,25 @@ Keyring Options:
   -full
     Decrypt all existing variables and re-encrypt with the new key. This command
     will immediately return and the re-encryption process will run
-    asynchronously on the leader.
+    asynchronously on the leader. Cannot be combined with -prepublish.
+
+  -prepublish <duration>
+    Create the new key without using it until the given duration has passed.
+    The key is served by the JWKS endpoint in the meantime, so that external
+    consumers of workload identities can fetch it before any identity is
+    signed with it. Only one key can be prepublished at a time.
+
+  -all-regions
+    Rotate the key in every federated region. The keyring of every region is
+    checked before any key is rotated, and the result of the rotation is
+    reported for each region. When combined with -prepublish, the new keys of
+    all regions become active at the same time.
+
+  -verify-jwks <url>
+    Verify that the JWKS at the given URL serves the new keys. May be
+    specified multiple times. The Nomad JWKS endpoint is always verified when
+    -prepublish is set. The command exits with a non-zero code if any JWKS
+    doesn't serve the new keys, but the keys stay rotated.
 
   -verbose
     Show full information.
@@ -50,8 +77,11 @@ func (c *OperatorRootKeyringRotateCommand) Synopsis() string {
 func (c *OperatorRootKeyringRotateCommand) AutocompleteFlags() complete.Flags {
 	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
 		complete.Flags{
-			"-full":    complete.PredictNothing,
-			"-verbose": complete.PredictNothing,
+			"-full":        complete.PredictNothing,
+			"-prepublish":  complete.PredictAnything,
+			"-all-regions": complete.PredictNothing,
+			"-verify-jwks": complete.PredictAnything,
+			"-verbose":     complete.PredictNothing,
 		})
 }
 
@@ -64,11 +94,16 @@ func (c *OperatorRootKeyringRotateCommand) Name() string {
 }
 
 func (c *OperatorRootKeyringRotateCommand) Run(args []string) int {
-	var rotateFull, verbose bool
+	var rotateFull, allRegions, verbose bool
+	var prepublish time.Duration
+	var verifyURLs []string
 
 	flags := c.Meta.FlagSet("root keyring rotate", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&rotateFull, "full", false, "full key rotation")
+	flags.DurationVar(&prepublish, "prepublish", 0, "prepublish key")
+	flags.BoolVar(&allRegions, "all-regions", false, "rotate in all regions")
+	flags.Var((*flaghelper.StringFlag)(&verifyURLs), "verify-jwks", "")
 	flags.BoolVar(&verbose, "verbose", false, "")
 
 	if err := flags.Parse(args); err != nil {
@@ -82,18 +117,187 @@ func (c *OperatorRootKeyringRotateCommand) Run(args []string) int {
 		return 1
 	}
 
+	if prepublish < 0 {
+		c.Ui.Error("The -prepublish duration must be positive")
+		return 1
+	}
+	if prepublish > 0 && rotateFull {
+		c.Ui.Error("The -prepublish and -full options cannot be combined")
+		return 1
+	}
+
 	client, err := c.Meta.Client()
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error creating nomad cli client: %s", err))
 		return 1
 	}
 
-	resp, _, err := client.Keyring().Rotate(
-		&api.KeyringRotateOptions{Full: rotateFull}, nil)
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("error: %s", err))
+	// An empty region targets the region of the agent.
+	regions := []string{""}
+	if allRegions {
+		regions, err = client.Regions().List()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error listing regions: %s", err))
+			return 1
+		}
+
+		// Check every region before rotating any key, so that an unreachable
+		// region doesn't leave the regions with different keys.
+		if !c.checkRegions(client, regions, prepublish > 0) {
+			c.Ui.Error("No key was rotated")
+			return 1
+		}
+	}
+
+	// The first region sets the publish time from its own clock, and the
+	// others reuse it so that the new keys of all regions become active at
+	// the same time.
+	opts := &api.KeyringRotateOptions{Full: rotateFull, PrepublishDuration: prepublish}
+
+	keys := make([]*api.RootKeyMeta, 0, len(regions))
+	results := make([]string, 0, len(regions)+1)
+	results = append(results, "Region|Result")
+	var failed []string
+	for _, region := range regions {
+		resp, _, err := client.Keyring().Rotate(opts, &api.WriteOptions{Region: region})
+		if err != nil {
+			if !allRegions {
+				c.Ui.Error(fmt.Sprintf("error: %s", err))
+				return 1
+			}
+			failed = append(failed, region)
+			results = append(results, fmt.Sprintf("%s|error: %s", region, err))
+			continue
+		}
+		keys = append(keys, resp)
+		results = append(results, fmt.Sprintf("%s|rotated", region))
+
+		if opts.PrepublishDuration > 0 {
+			opts.PrepublishDuration = 0
+			opts.PublishTime = resp.PublishTime
+		}
+	}
+
+	if allRegions {
+		c.Ui.Output(formatList(results))
+		c.Ui.Output("")
+	}
+	if len(keys) > 0 {
+		c.Ui.Output(renderVariablesKeysResponse(keys, verbose))
+	}
+	if len(failed) > 0 {
+		c.Ui.Error(fmt.Sprintf("\nFailed to rotate the key in regions: %s",
+			strings.Join(failed, ", ")))
+		return 1
+	}
+
+	if prepublish == 0 && len(verifyURLs) == 0 {
+		return 0
+	}
+
+	verified := true
+	if prepublish > 0 {
+		c.Ui.Output(fmt.Sprintf("\nNew keys will become active at %s",
+			formatUnixNanoTime(opts.PublishTime)))
+
+		// Verify the keys are served by Nomad itself first, this should
+		// always succeed unless reading from a stale server.
+		q := &api.QueryOptions{Params: map[string]string{}}
+		if allRegions {
+			q.Params["all_regions"] = "true"
+		}
+		jwks, _, err := client.Keyring().ListPublic(q)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error fetching Nomad JWKS: %s", err))
+			verified = false
+		} else if !c.reportJWKS("Nomad JWKS endpoint", jwks, keys) {
+			verified = false
+		}
+	}
+
+	httpClient := cleanhttp.DefaultClient()
+	httpClient.Timeout = jwksVerifyTimeout
+	for _, u := range verifyURLs {
+		jwks, err := fetchJWKS(httpClient, u)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error fetching JWKS at %s: %s", u, err))
+			verified = false
+			continue
+		}
+		if !c.reportJWKS(u, jwks, keys) {
+			verified = false
+		}
+	}
+
+	if !verified {
+		c.Ui.Error("\nThe keys were rotated but are not served by every JWKS")
 		return 1
 	}
-	c.Ui.Output(renderVariablesKeysResponse([]*api.RootKeyMeta{resp}, verbose))
 	return 0
 }
+
+// checkRegions returns true if the keyring of every region can be read and, if
+// a key is to be prepublished, none of them already has a prepublished key.
+func (c *OperatorRootKeyringRotateCommand) checkRegions(client *api.Client, regions []string, prepublish bool) bool {
+	ok := true
+	for _, region := range regions {
+		keys, _, err := client.Keyring().List(&api.QueryOptions{Region: region})
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error reading keyring of region %q: %s", region, err))
+			ok = false
+			continue
+		}
+		if !prepublish {
+			continue
+		}
+		for _, k := range keys {
+			if k.State == api.RootKeyStatePrepublished {
+				c.Ui.Error(fmt.Sprintf("Key %s of region %q is already prepublished", k.KeyID, region))
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+// reportJWKS outputs whether the JWKS from source serves all the keys, and
+// returns false if it doesn't.
+func (c *OperatorRootKeyringRotateCommand) reportJWKS(source string, jwks *api.JSONWebKeySet, keys []*api.RootKeyMeta) bool {
+	served := make(map[string]struct{}, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		served[k.KeyID] = struct{}{}
+	}
+
+	var missing []string
+	for _, k := range keys {
+		if _, ok := served[k.KeyID]; !ok {
+			missing = append(missing, k.KeyID)
+		}
+	}
+
+	if len(missing) == 0 {
+		c.Ui.Output(fmt.Sprintf("%s serves all new keys", source))
+		return true
+	}
+	c.Ui.Error(fmt.Sprintf("%s does not serve keys: %s", source, strings.Join(missing, ", ")))
+	return false
+}
+
+// fetchJWKS fetches and decodes the JWKS at the given URL.
+func fetchJWKS(client *http.Client, u string) (*api.JSONWebKeySet, error) {
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+
+	var jwks api.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+	return &jwks, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestOperatorRootKeyringRotateCommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &OperatorRootKeyringRotateCommand{}
+}
+
+func TestOperatorRootKeyringRotateCommand_Fails(t *testing.T) {
+	ci.Parallel(t)
+
+	testCases := []struct {
+		name   string
+		args   []string
+		expErr string
+	}{
+		{
+			name:   "extra arguments",
+			args:   []string{"foo"},
+			expErr: "This command requires no arguments",
+		},
+		{
+			name:   "negative prepublish",
+			args:   []string{"-prepublish", "-1h"},
+			expErr: "must be positive",
+		},
+		{
+			name:   "prepublish with full",
+			args:   []string{"-prepublish", "1h", "-full"},
+			expErr: "cannot be combined",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			cmd := &OperatorRootKeyringRotateCommand{Meta: Meta{Ui: ui}}
+			must.One(t, cmd.Run(tc.args))
+			must.StrContains(t, ui.ErrorWriter.String(), tc.expErr)
+		})
+	}
+}
+
+func TestOperatorRootKeyringRotateCommand_Prepublish(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, client, url := testServer(t, false, nil)
+	testutil.WaitForKeyring(t, srv.Agent.RPC, "global")
+
+	ui := cli.NewMockUi()
+	cmd := &OperatorRootKeyringRotateCommand{Meta: Meta{Ui: ui}}
+
+	before := time.Now().Add(time.Hour).UnixNano()
+	code := cmd.Run([]string{
+		"-address", url, "-prepublish", "1h", "-all-regions",
+		"-verify-jwks", url + "/.well-known/jwks.json",
+	})
+	after := time.Now().Add(time.Hour).UnixNano()
+	must.Zero(t, code, must.Sprint(ui.ErrorWriter.String()))
+
+	out := ui.OutputWriter.String()
+	must.RegexMatch(t, regexp.MustCompile(`global\s+rotated`), out)
+	must.StrContains(t, out, "prepublished")
+	must.StrContains(t, out, "Nomad JWKS endpoint serves all new keys")
+	must.StrContains(t, out, url+"/.well-known/jwks.json serves all new keys")
+
+	// The publish time is set by the server
+	keys, _, err := client.Keyring().List(nil)
+	must.NoError(t, err)
+	must.Len(t, 2, keys)
+
+	var prepublished *api.RootKeyMeta
+	for _, k := range keys {
+		if k.State == api.RootKeyStatePrepublished {
+			prepublished = k
+		}
+	}
+	must.NotNil(t, prepublished)
+	must.Between(t, before, prepublished.PublishTime, after)
+
+	// Regions are checked before rotating any key
+	ui = cli.NewMockUi()
+	cmd = &OperatorRootKeyringRotateCommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"-address", url, "-prepublish", "1h", "-all-regions"}))
+	must.StrContains(t, ui.ErrorWriter.String(), "is already prepublished")
+	must.StrContains(t, ui.ErrorWriter.String(), "No key was rotated")
+
+	keys, _, err = client.Keyring().List(nil)
+	must.NoError(t, err)
+	must.Len(t, 2, keys)
+}
+
+func TestOperatorRootKeyringRotateCommand_VerifyJWKS(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, _, url := testServer(t, false, nil)
+	testutil.WaitForKeyring(t, srv.Agent.RPC, "global")
+
+	// A JWKS which doesn't serve the new key
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"keys":[]}`)
+	}))
+	t.Cleanup(jwks.Close)
+
+	ui := cli.NewMockUi()
+	cmd := &OperatorRootKeyringRotateCommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"-address", url, "-verify-jwks", jwks.URL}))
+	must.StrContains(t, ui.OutputWriter.String(), "active")
+	must.StrContains(t, ui.ErrorWriter.String(), jwks.URL+" does not serve keys")
+	must.StrContains(t, ui.ErrorWriter.String(), "not served by every JWKS")
+
+	// A JWKS which can't be fetched
+	ui = cli.NewMockUi()
+	cmd = &OperatorRootKeyringRotateCommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"-address", url, "-verify-jwks", jwks.URL + "/missing"}))
+	must.StrContains(t, ui.ErrorWriter.String(), "Error fetching JWKS")
+}
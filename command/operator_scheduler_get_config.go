@@ -5,7 +5,9 @@ package command
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
@@ -80,12 +82,24 @@ func (o *OperatorSchedulerGetConfig) Run(args []string) int {
 	o.Ui.Output(formatKV([]string{
 		fmt.Sprintf("Scheduler Algorithm|%s", schedConfig.SchedulerAlgorithm),
 		fmt.Sprintf("Memory Oversubscription|%v", schedConfig.MemoryOversubscriptionEnabled),
+		fmt.Sprintf("Load-Aware Scoring|%v", schedConfig.LoadAwareScoringEnabled),
 		fmt.Sprintf("Reject Job Registration|%v", schedConfig.RejectJobRegistration),
 		fmt.Sprintf("Pause Eval Broker|%v", schedConfig.PauseEvalBroker),
+		fmt.Sprintf("Eval Trace|%v", schedConfig.EvalTraceConfig.Enabled),
 		fmt.Sprintf("Preemption System Scheduler|%v", schedConfig.PreemptionConfig.SystemSchedulerEnabled),
 		fmt.Sprintf("Preemption Service Scheduler|%v", schedConfig.PreemptionConfig.ServiceSchedulerEnabled),
 		fmt.Sprintf("Preemption Batch Scheduler|%v", schedConfig.PreemptionConfig.BatchSchedulerEnabled),
 		fmt.Sprintf("Preemption SysBatch Scheduler|%v", schedConfig.PreemptionConfig.SysBatchSchedulerEnabled),
+		fmt.Sprintf("Job GC Threshold|%s", formatGCSetting(schedConfig.GCConfig.JobThreshold)),
+		fmt.Sprintf("Job GC Batch Size|%s", formatGCSetting(schedConfig.GCConfig.JobBatchSize)),
+		fmt.Sprintf("Eval GC Threshold|%s", formatGCSetting(schedConfig.GCConfig.EvalThreshold)),
+		fmt.Sprintf("Batch Eval GC Threshold|%s", formatGCSetting(schedConfig.GCConfig.BatchEvalThreshold)),
+		fmt.Sprintf("Eval GC Batch Size|%s", formatGCSetting(schedConfig.GCConfig.EvalBatchSize)),
+		fmt.Sprintf("Deployment GC Threshold|%s", formatGCSetting(schedConfig.GCConfig.DeploymentThreshold)),
+		fmt.Sprintf("Deployment GC Batch Size|%s", formatGCSetting(schedConfig.GCConfig.DeploymentBatchSize)),
+		fmt.Sprintf("CSI Volume Claim GC Threshold|%s", formatGCSetting(schedConfig.GCConfig.CSIVolumeClaimThreshold)),
+		fmt.Sprintf("Batch Fair Share|%v", schedConfig.BatchFairShareConfig.Enabled),
+		fmt.Sprintf("Batch Fair Share Weights|%s", formatBatchFairShareWeights(schedConfig.BatchFairShareConfig.NamespaceWeights)),
 		fmt.Sprintf("Modify Index|%v", resp.SchedulerConfig.ModifyIndex),
 	}))
 	return 0
@@ -119,3 +133,26 @@ Scheduler Get Config Options:
 
 	return strings.TrimSpace(helpText)
 }
+
+// formatGCSetting formats a GC threshold or batch size, which uses the server
+// configuration when unset.
+func formatGCSetting[T time.Duration | int](v T) string {
+	if v == 0 {
+		return "<server default>"
+	}
+	return fmt.Sprint(v)
+}
+
+// formatBatchFairShareWeights returns the batch fair share weights of the
+// namespaces sorted by namespace.
+func formatBatchFairShareWeights(weights map[string]int) string {
+	if len(weights) == 0 {
+		return "<none>"
+	}
+	out := make([]string, 0, len(weights))
+	for ns, weight := range weights {
+		out = append(out, fmt.Sprintf("%s=%d", ns, weight))
+	}
+	sort.Strings(out)
+	return strings.Join(out, ", ")
+}
@@ -5,7 +5,9 @@ package command
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
 	flagHelper "github.com/hashicorp/nomad/helper/flags"
@@ -25,12 +27,28 @@ type OperatorSchedulerSetConfig struct {
 	checkIndex               string
 	schedulerAlgorithm       string
 	memoryOversubscription   flagHelper.BoolValue
+	loadAwareScoring         flagHelper.BoolValue
 	rejectJobRegistration    flagHelper.BoolValue
 	pauseEvalBroker          flagHelper.BoolValue
+	evalTrace                flagHelper.BoolValue
 	preemptBatchScheduler    flagHelper.BoolValue
 	preemptServiceScheduler  flagHelper.BoolValue
 	preemptSysBatchScheduler flagHelper.BoolValue
 	preemptSystemScheduler   flagHelper.BoolValue
+
+	// The GC flags are only merged into the configuration when set.
+	jobGCThreshold            *time.Duration
+	jobGCBatchSize            *int
+	evalGCThreshold           *time.Duration
+	batchEvalGCThreshold      *time.Duration
+	evalGCBatchSize           *int
+	deploymentGCThreshold     *time.Duration
+	deploymentGCBatchSize     *int
+	csiVolumeClaimGCThreshold *time.Duration
+
+	// The batch fair share weights are merged into the current weights.
+	batchFairShare        flagHelper.BoolValue
+	batchFairShareWeights map[string]int
 }
 
 func (o *OperatorSchedulerSetConfig) AutocompleteFlags() complete.Flags {
@@ -41,13 +59,25 @@ func (o *OperatorSchedulerSetConfig) AutocompleteFlags() complete.Flags {
 				string(api.SchedulerAlgorithmBinpack),
 				string(api.SchedulerAlgorithmSpread),
 			),
-			"-memory-oversubscription":    complete.PredictSet("true", "false"),
-			"-reject-job-registration":    complete.PredictSet("true", "false"),
-			"-pause-eval-broker":          complete.PredictSet("true", "false"),
-			"-preempt-batch-scheduler":    complete.PredictSet("true", "false"),
-			"-preempt-service-scheduler":  complete.PredictSet("true", "false"),
-			"-preempt-sysbatch-scheduler": complete.PredictSet("true", "false"),
-			"-preempt-system-scheduler":   complete.PredictSet("true", "false"),
+			"-memory-oversubscription":       complete.PredictSet("true", "false"),
+			"-load-aware-scoring":            complete.PredictSet("true", "false"),
+			"-reject-job-registration":       complete.PredictSet("true", "false"),
+			"-pause-eval-broker":             complete.PredictSet("true", "false"),
+			"-eval-trace":                    complete.PredictSet("true", "false"),
+			"-preempt-batch-scheduler":       complete.PredictSet("true", "false"),
+			"-preempt-service-scheduler":     complete.PredictSet("true", "false"),
+			"-preempt-sysbatch-scheduler":    complete.PredictSet("true", "false"),
+			"-preempt-system-scheduler":      complete.PredictSet("true", "false"),
+			"-job-gc-threshold":              complete.PredictAnything,
+			"-job-gc-batch-size":             complete.PredictAnything,
+			"-eval-gc-threshold":             complete.PredictAnything,
+			"-batch-eval-gc-threshold":       complete.PredictAnything,
+			"-eval-gc-batch-size":            complete.PredictAnything,
+			"-deployment-gc-threshold":       complete.PredictAnything,
+			"-deployment-gc-batch-size":      complete.PredictAnything,
+			"-csi-volume-claim-gc-threshold": complete.PredictAnything,
+			"-batch-fair-share":              complete.PredictSet("true", "false"),
+			"-batch-fair-share-weight":       complete.PredictAnything,
 		},
 	)
 }
@@ -66,12 +96,24 @@ func (o *OperatorSchedulerSetConfig) Run(args []string) int {
 	flags.StringVar(&o.checkIndex, "check-index", "", "")
 	flags.StringVar(&o.schedulerAlgorithm, "scheduler-algorithm", "", "")
 	flags.Var(&o.memoryOversubscription, "memory-oversubscription", "")
+	flags.Var(&o.loadAwareScoring, "load-aware-scoring", "")
 	flags.Var(&o.rejectJobRegistration, "reject-job-registration", "")
 	flags.Var(&o.pauseEvalBroker, "pause-eval-broker", "")
+	flags.Var(&o.evalTrace, "eval-trace", "")
 	flags.Var(&o.preemptBatchScheduler, "preempt-batch-scheduler", "")
 	flags.Var(&o.preemptServiceScheduler, "preempt-service-scheduler", "")
 	flags.Var(&o.preemptSysBatchScheduler, "preempt-sysbatch-scheduler", "")
 	flags.Var(&o.preemptSystemScheduler, "preempt-system-scheduler", "")
+	flags.Var(gcThresholdFlag(&o.jobGCThreshold), "job-gc-threshold", "")
+	flags.Var(gcBatchSizeFlag(&o.jobGCBatchSize), "job-gc-batch-size", "")
+	flags.Var(gcThresholdFlag(&o.evalGCThreshold), "eval-gc-threshold", "")
+	flags.Var(gcThresholdFlag(&o.batchEvalGCThreshold), "batch-eval-gc-threshold", "")
+	flags.Var(gcBatchSizeFlag(&o.evalGCBatchSize), "eval-gc-batch-size", "")
+	flags.Var(gcThresholdFlag(&o.deploymentGCThreshold), "deployment-gc-threshold", "")
+	flags.Var(gcBatchSizeFlag(&o.deploymentGCBatchSize), "deployment-gc-batch-size", "")
+	flags.Var(gcThresholdFlag(&o.csiVolumeClaimGCThreshold), "csi-volume-claim-gc-threshold", "")
+	flags.Var(&o.batchFairShare, "batch-fair-share", "")
+	flags.Var(batchFairShareWeightFlag(&o.batchFairShareWeights), "batch-fair-share-weight", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -128,12 +170,24 @@ func (o *OperatorSchedulerSetConfig) Run(args []string) int {
 		schedulerConfig.SchedulerAlgorithm = api.SchedulerAlgorithm(o.schedulerAlgorithm)
 	}
 	o.memoryOversubscription.Merge(&schedulerConfig.MemoryOversubscriptionEnabled)
+	o.loadAwareScoring.Merge(&schedulerConfig.LoadAwareScoringEnabled)
 	o.rejectJobRegistration.Merge(&schedulerConfig.RejectJobRegistration)
 	o.pauseEvalBroker.Merge(&schedulerConfig.PauseEvalBroker)
+	o.evalTrace.Merge(&schedulerConfig.EvalTraceConfig.Enabled)
 	o.preemptBatchScheduler.Merge(&schedulerConfig.PreemptionConfig.BatchSchedulerEnabled)
 	o.preemptServiceScheduler.Merge(&schedulerConfig.PreemptionConfig.ServiceSchedulerEnabled)
 	o.preemptSysBatchScheduler.Merge(&schedulerConfig.PreemptionConfig.SysBatchSchedulerEnabled)
 	o.preemptSystemScheduler.Merge(&schedulerConfig.PreemptionConfig.SystemSchedulerEnabled)
+	mergeGCFlag(o.jobGCThreshold, &schedulerConfig.GCConfig.JobThreshold)
+	mergeGCFlag(o.jobGCBatchSize, &schedulerConfig.GCConfig.JobBatchSize)
+	mergeGCFlag(o.evalGCThreshold, &schedulerConfig.GCConfig.EvalThreshold)
+	mergeGCFlag(o.batchEvalGCThreshold, &schedulerConfig.GCConfig.BatchEvalThreshold)
+	mergeGCFlag(o.evalGCBatchSize, &schedulerConfig.GCConfig.EvalBatchSize)
+	mergeGCFlag(o.deploymentGCThreshold, &schedulerConfig.GCConfig.DeploymentThreshold)
+	mergeGCFlag(o.deploymentGCBatchSize, &schedulerConfig.GCConfig.DeploymentBatchSize)
+	mergeGCFlag(o.csiVolumeClaimGCThreshold, &schedulerConfig.GCConfig.CSIVolumeClaimThreshold)
+	o.batchFairShare.Merge(&schedulerConfig.BatchFairShareConfig.Enabled)
+	mergeBatchFairShareWeights(o.batchFairShareWeights, &schedulerConfig.BatchFairShareConfig)
 
 	// Check-and-set the new configuration.
 	result, _, err := client.Operator().SchedulerCASConfiguration(schedulerConfig, nil)
@@ -182,6 +236,11 @@ Scheduler Set Config Options:
     excess memory capacity. Tasks must specify memory_max to take advantage of
     memory oversubscription.
 
+  -load-aware-scoring=[true|false]
+    When true, servers store the CPU load and memory pressure reported by
+    clients in their heartbeats, and schedulers prefer placing allocations on
+    the least utilized nodes.
+
   -reject-job-registration=[true|false]
     When true, the server will return permission denied errors for job registration,
     job dispatch, and job scale APIs, unless the ACL token for the request is a
@@ -193,6 +252,11 @@ Scheduler Set Config Options:
     When set to true, the eval broker which usually runs on the leader will be
     disabled. This will prevent the scheduler workers from receiving new work.
 
+  -eval-trace=[true|false]
+    When set to true, schedulers retain a trace of the decisions made while
+    processing each evaluation, which can be displayed with
+    "nomad eval status -verbose-explain".
+
   -preempt-batch-scheduler=[true|false]
     Specifies whether preemption for batch jobs is enabled. Note that if this
     is set to true, then batch jobs can preempt any other jobs.
@@ -208,6 +272,126 @@ Scheduler Set Config Options:
   -preempt-system-scheduler=[true|false]
     Specifies whether preemption for system jobs is enabled. Note that if this
     is set to true, then system jobs can preempt any other jobs.
+
+  -job-gc-threshold=<duration>
+    Specifies how old a job must be to be garbage collected, overriding the
+    job_gc_threshold of the server configuration. Set to 0 to use the server
+    configuration.
+
+  -job-gc-batch-size=<int>
+    Specifies the maximum number of jobs garbage collected per Raft write. Set
+    to 0 to use the default.
+
+  -eval-gc-threshold=<duration>
+    Specifies how old an evaluation must be to be garbage collected, overriding
+    the eval_gc_threshold of the server configuration. Allocations are garbage
+    collected with their evaluations. Set to 0 to use the server configuration.
+
+  -batch-eval-gc-threshold=<duration>
+    Specifies how old an evaluation of a batch job must be to be garbage
+    collected, overriding the batch_eval_gc_threshold of the server
+    configuration. Set to 0 to use the server configuration.
+
+  -eval-gc-batch-size=<int>
+    Specifies the maximum number of evaluations and allocations garbage
+    collected per Raft write. Set to 0 to use the default.
+
+  -deployment-gc-threshold=<duration>
+    Specifies how old a deployment must be to be garbage collected, overriding
+    the deployment_gc_threshold of the server configuration. Set to 0 to use
+    the server configuration.
+
+  -deployment-gc-batch-size=<int>
+    Specifies the maximum number of deployments garbage collected per Raft
+    write. Set to 0 to use the default.
+
+  -csi-volume-claim-gc-threshold=<duration>
+    Specifies how old a CSI volume must be for its claims to be garbage
+    collected, overriding the csi_volume_claim_gc_threshold of the server
+    configuration. Set to 0 to use the server configuration.
+
+  -batch-fair-share=[true|false]
+    When set to true, the evaluations of batch jobs are dequeued by the fair
+    share of their namespace, computed from the recent usage of the cluster by
+    the batch jobs of the namespace and its weight, instead of by priority.
+
+  -batch-fair-share-weight=<namespace>=<weight>
+    Sets the batch fair share weight of a namespace. A namespace with a weight
+    of 2 is entitled to twice the share of a namespace with the default weight
+    of 1. Set the weight to 0 to restore the default weight. This flag can be
+    specified multiple times.
 `
 	return strings.TrimSpace(helpText)
 }
+
+// gcThresholdFlag returns a flag value setting a GC threshold override.
+func gcThresholdFlag(dst **time.Duration) flagHelper.FuncDurationVar {
+	return func(d time.Duration) error {
+		if d < 0 {
+			return fmt.Errorf("threshold must not be negative")
+		}
+		*dst = &d
+		return nil
+	}
+}
+
+// gcBatchSizeFlag returns a flag value setting a GC batch size override.
+func gcBatchSizeFlag(dst **int) flagHelper.FuncVar {
+	return func(s string) error {
+		size, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		if size < 0 {
+			return fmt.Errorf("batch size must not be negative")
+		}
+		*dst = &size
+		return nil
+	}
+}
+
+// mergeGCFlag sets a GC setting of the scheduler configuration if its flag
+// was set.
+func mergeGCFlag[T time.Duration | int](flag *T, dst *T) {
+	if flag != nil {
+		*dst = *flag
+	}
+}
+
+// batchFairShareWeightFlag returns a flag value setting the batch fair share
+// weight of a namespace, in the form "<namespace>=<weight>".
+func batchFairShareWeightFlag(dst *map[string]int) flagHelper.FuncVar {
+	return func(s string) error {
+		ns, raw, ok := strings.Cut(s, "=")
+		if !ok || ns == "" {
+			return fmt.Errorf("weight must be in the form <namespace>=<weight>")
+		}
+		weight, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight must not be negative")
+		}
+		if *dst == nil {
+			*dst = make(map[string]int)
+		}
+		(*dst)[ns] = weight
+		return nil
+	}
+}
+
+// mergeBatchFairShareWeights sets the batch fair share weights set by flags,
+// removing the weights set to 0 so the namespaces use the default weight.
+func mergeBatchFairShareWeights(weights map[string]int, dst *api.BatchFairShareConfig) {
+	for ns, weight := range weights {
+		if weight == 0 {
+			delete(dst.NamespaceWeights, ns)
+			continue
+		}
+		if dst.NamespaceWeights == nil {
+			dst.NamespaceWeights = make(map[string]int)
+		}
+		dst.NamespaceWeights[ns] = weight
+	}
+}
@@ -6,6 +6,7 @@ package command
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
 	"github.com/hashicorp/nomad/ci"
@@ -51,6 +52,17 @@ func TestOperatorSchedulerSetConfig_Run(t *testing.T) {
 		"-preempt-service-scheduler=true",
 		"-preempt-sysbatch-scheduler=true",
 		"-preempt-system-scheduler=false",
+		"-job-gc-threshold=2h",
+		"-job-gc-batch-size=100",
+		"-eval-gc-threshold=30m",
+		"-batch-eval-gc-threshold=12h",
+		"-eval-gc-batch-size=200",
+		"-deployment-gc-threshold=90m",
+		"-deployment-gc-batch-size=50",
+		"-csi-volume-claim-gc-threshold=10m",
+		"-batch-fair-share=true",
+		"-batch-fair-share-weight=default=2",
+		"-batch-fair-share-weight=research=3",
 	}
 	require.EqualValues(t, 0, c.Run(modifyingArgs))
 	s := ui.OutputWriter.String()
@@ -69,6 +81,20 @@ func TestOperatorSchedulerSetConfig_Run(t *testing.T) {
 		MemoryOversubscriptionEnabled: true,
 		RejectJobRegistration:         true,
 		PauseEvalBroker:               true,
+		GCConfig: api.GCConfig{
+			JobThreshold:            2 * time.Hour,
+			JobBatchSize:            100,
+			EvalThreshold:           30 * time.Minute,
+			BatchEvalThreshold:      12 * time.Hour,
+			EvalBatchSize:           200,
+			DeploymentThreshold:     90 * time.Minute,
+			DeploymentBatchSize:     50,
+			CSIVolumeClaimThreshold: 10 * time.Minute,
+		},
+		BatchFairShareConfig: api.BatchFairShareConfig{
+			Enabled:          true,
+			NamespaceWeights: map[string]int{"default": 2, "research": 3},
+		},
 	}, modifiedConfig.SchedulerConfig)
 
 	ui.ErrorWriter.Reset()
@@ -81,6 +107,18 @@ func TestOperatorSchedulerSetConfig_Run(t *testing.T) {
 	ui.ErrorWriter.Reset()
 	ui.OutputWriter.Reset()
 
+	// Negative GC settings are rejected.
+	require.EqualValues(t, 1, c.Run([]string{"-address=" + addr, "-job-gc-batch-size=-1"}))
+	require.Contains(t, ui.ErrorWriter.String(), "batch size must not be negative")
+	ui.ErrorWriter.Reset()
+	ui.OutputWriter.Reset()
+
+	// Malformed batch fair share weights are rejected.
+	require.EqualValues(t, 1, c.Run([]string{"-address=" + addr, "-batch-fair-share-weight=default"}))
+	require.Contains(t, ui.ErrorWriter.String(), "weight must be in the form <namespace>=<weight>")
+	ui.ErrorWriter.Reset()
+	ui.OutputWriter.Reset()
+
 	// Try updating the config using an incorrect check-index value.
 	require.EqualValues(t, 1, c.Run([]string{
 		"-address=" + addr,
@@ -108,4 +146,6 @@ func schedulerConfigEquals(t *testing.T, expected, actual *api.SchedulerConfigur
 	require.Equal(t, expected.MemoryOversubscriptionEnabled, actual.MemoryOversubscriptionEnabled)
 	require.Equal(t, expected.PauseEvalBroker, actual.PauseEvalBroker)
 	require.Equal(t, expected.PreemptionConfig, actual.PreemptionConfig)
+	require.Equal(t, expected.GCConfig, actual.GCConfig)
+	require.Equal(t, expected.BatchFairShareConfig, actual.BatchFairShareConfig)
 }
@@ -32,6 +32,10 @@ Usage: nomad operator snapshot <subcommand> [options]
 
       $ nomad operator snapshot inspect backup.snap
 
+  Verify the integrity of a snapshot:
+
+      $ nomad operator snapshot verify backup.snap
+
   Run a daemon process that locally saves a snapshot every hour (available only in
   Nomad Enterprise) :
 
@@ -69,6 +69,10 @@ func TestOperatorSnapshotInspect_HandlesFailure(t *testing.T) {
 }
 
 func generateSnapshotFile(t *testing.T, prepare func(srv *agent.TestAgent, client *api.Client, url string)) string {
+	return generateSnapshotFileWithConfig(t, nil, prepare)
+}
+
+func generateSnapshotFileWithConfig(t *testing.T, cb func(c *agent.Config), prepare func(srv *agent.TestAgent, client *api.Client, url string)) string {
 	tmpDir := t.TempDir()
 
 	srv, api, url := testServer(t, false, func(c *agent.Config) {
@@ -78,6 +82,10 @@ func generateSnapshotFile(t *testing.T, prepare func(srv *agent.TestAgent, clien
 		c.AdvertiseAddrs.HTTP = "127.0.0.1"
 		c.AdvertiseAddrs.RPC = "127.0.0.1"
 		c.AdvertiseAddrs.Serf = "127.0.0.1"
+
+		if cb != nil {
+			cb(c)
+		}
 	})
 
 	defer srv.Shutdown()
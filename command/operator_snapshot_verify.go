@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/helper/raftutil"
+	"github.com/posener/complete"
+)
+
+type OperatorSnapshotVerifyCommand struct {
+	Meta
+}
+
+func (c *OperatorSnapshotVerifyCommand) Help() string {
+	helpText := `
+Usage: nomad operator snapshot verify <file>
+
+  Verifies the integrity of a snapshot file on disk without restoring it. The
+  checksums of the snapshot archive are verified, as well as the checksum of
+  each chunk of snapshots written in chunks.
+
+  To verify the file "backup.snap":
+    $ nomad operator snapshot verify backup.snap
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorSnapshotVerifyCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func (c *OperatorSnapshotVerifyCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*.snap")
+}
+
+func (c *OperatorSnapshotVerifyCommand) Synopsis() string {
+	return "Verifies the integrity of a Nomad snapshot file"
+}
+
+func (c *OperatorSnapshotVerifyCommand) Name() string { return "operator snapshot verify" }
+
+func (c *OperatorSnapshotVerifyCommand) Run(args []string) int {
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <filename>")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	path := args[0]
+	f, err := os.Open(path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening snapshot file: %s", err))
+		return 1
+	}
+	defer f.Close()
+
+	meta, info, err := raftutil.VerifyArchive(f)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error verifying snapshot: %s", err))
+		return 1
+	}
+
+	output := []string{
+		fmt.Sprintf("ID|%s", meta.ID),
+		fmt.Sprintf("Index|%d", meta.Index),
+		fmt.Sprintf("Term|%d", meta.Term),
+		fmt.Sprintf("State Size|%d", info.Size),
+		fmt.Sprintf("Chunked|%t", info.ChunkSize > 0),
+	}
+	if info.ChunkSize > 0 {
+		output = append(output,
+			fmt.Sprintf("Chunk Size|%d", info.ChunkSize),
+			fmt.Sprintf("Chunks|%d", info.Chunks),
+		)
+	}
+	c.Ui.Output(formatList(output))
+
+	if info.ChunkSize == 0 {
+		c.Ui.Warn("Snapshot was written without chunks, only the archive checksums were verified")
+	}
+	c.Ui.Output("Snapshot verified")
+	return 0
+}
@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestOperatorSnapshotVerify(t *testing.T) {
+	ci.Parallel(t)
+
+	t.Run("unchunked", func(t *testing.T) {
+		snapPath := generateSnapshotFile(t, nil)
+
+		ui := cli.NewMockUi()
+		cmd := &OperatorSnapshotVerifyCommand{Meta: Meta{Ui: ui}}
+		must.Zero(t, cmd.Run([]string{snapPath}))
+
+		out := ui.OutputWriter.String()
+		must.StrContains(t, out, "Chunked     false")
+		must.StrContains(t, out, "Snapshot verified")
+		must.StrContains(t, ui.ErrorWriter.String(), "only the archive checksums were verified")
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		snapPath := generateSnapshotFileWithConfig(t, func(c *agent.Config) {
+			c.Server.RaftSnapshotChunkSize = pointer.Of("1KiB")
+		}, nil)
+
+		ui := cli.NewMockUi()
+		cmd := &OperatorSnapshotVerifyCommand{Meta: Meta{Ui: ui}}
+		must.Zero(t, cmd.Run([]string{snapPath}))
+
+		out := ui.OutputWriter.String()
+		must.StrContains(t, out, "Chunked     true")
+		must.StrContains(t, out, "Chunk Size  1024")
+		must.StrContains(t, out, "Snapshot verified")
+		must.Eq(t, "", ui.ErrorWriter.String())
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "invalid.snap")
+		must.NoError(t, os.WriteFile(path, []byte("invalid data"), 0600))
+
+		ui := cli.NewMockUi()
+		cmd := &OperatorSnapshotVerifyCommand{Meta: Meta{Ui: ui}}
+		must.One(t, cmd.Run([]string{path}))
+		must.StrContains(t, ui.ErrorWriter.String(), "Error verifying snapshot")
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/cap/util"
 	"github.com/hashicorp/nomad/api/contexts"
@@ -15,7 +16,21 @@ import (
 
 var (
 	// uiContexts is the contexts the ui can open automatically.
-	uiContexts = []contexts.Context{contexts.Jobs, contexts.Allocs, contexts.Nodes}
+	uiContexts = []contexts.Context{
+		contexts.Jobs,
+		contexts.Allocs,
+		contexts.Nodes,
+		contexts.Evals,
+		contexts.Volumes,
+		contexts.Plugins,
+	}
+)
+
+const (
+	// defaultUIAuthenticateTTL is the default time-to-live of the one-time
+	// tokens created to authenticate the web UI. The token is exchanged as
+	// soon as the browser opens the URL, so it can expire quickly.
+	defaultUIAuthenticateTTL = time.Minute
 )
 
 type UiCommand struct {
@@ -28,7 +43,8 @@ Usage: nomad ui [options] <identifier>
 
 Open the Nomad Web UI in the default browser. An optional identifier may be
 provided, in which case the UI will be opened to view the details for that
-object. Supported identifiers are jobs, allocations and nodes.
+object. Supported identifiers are jobs, allocations, nodes, evaluations, CSI
+volumes and CSI plugins.
 
 General Options:
 
@@ -37,7 +53,11 @@ General Options:
 UI Options
 
   -authenticate: Exchange your Nomad ACL token for a one-time token in the
-    web UI, if ACLs are enabled.
+    web UI, if ACLs are enabled. The one-time token can only be used once and
+    expires quickly, so your ACL token never appears in the browser history.
+
+  -authenticate-ttl: The time-to-live of the one-time token created with
+    -authenticate. Defaults to 1m and can't exceed 10m.
 
   -show-url: Show the Nomad UI URL instead of opening with the default browser.
 `
@@ -46,7 +66,12 @@ UI Options
 }
 
 func (c *UiCommand) AutocompleteFlags() complete.Flags {
-	return c.Meta.AutocompleteFlags(FlagSetClient)
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-authenticate":     complete.PredictNothing,
+			"-authenticate-ttl": complete.PredictAnything,
+			"-show-url":         complete.PredictNothing,
+		})
 }
 
 func (c *UiCommand) AutocompleteArgs() complete.Predictor {
@@ -87,11 +112,13 @@ func (c *UiCommand) Name() string { return "ui" }
 
 func (c *UiCommand) Run(args []string) int {
 	var authenticate bool
+	var authenticateTTL time.Duration
 	var showUrl bool
 
 	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&authenticate, "authenticate", false, "")
+	flags.DurationVar(&authenticateTTL, "authenticate-ttl", defaultUIAuthenticateTTL, "")
 	flags.BoolVar(&showUrl, "show-url", false, "")
 
 	if err := flags.Parse(args); err != nil {
@@ -132,7 +159,7 @@ func (c *UiCommand) Run(args []string) int {
 	// Set one-time secret
 	var ottSecret string
 	if authenticate {
-		ott, _, err := client.ACLTokens().UpsertOneTimeToken(nil)
+		ott, _, err := client.ACLTokens().UpsertOneTimeTokenWithTTL(authenticateTTL, nil)
 		if err != nil {
 			c.Ui.Error(fmt.Sprintf("Could not get one-time token: %s", err))
 			return 1
@@ -183,17 +210,17 @@ func (c *UiCommand) Run(args []string) int {
 			}
 		}
 
-		switch match {
-		case contexts.Nodes:
-			url.Path = fmt.Sprintf("ui/clients/%s", fullID)
-		case contexts.Allocs:
-			url.Path = fmt.Sprintf("ui/allocations/%s", fullID)
-		case contexts.Jobs:
-			url.Path = fmt.Sprintf("ui/jobs/%s", fullID)
-		default:
+		path, params := uiPath(match, fullID, c.clientConfig().Namespace)
+		if path == "" {
 			c.Ui.Error(fmt.Sprintf("Unable to resolve ID: %q", id))
 			return 1
 		}
+		url.Path = path
+		qp := url.Query()
+		for k, v := range params {
+			qp[k] = v
+		}
+		url.RawQuery = qp.Encode()
 	}
 
 	var output string
@@ -219,6 +246,31 @@ func (c *UiCommand) Run(args []string) int {
 	return 0
 }
 
+// uiPath returns the path and query parameters of the web UI page of the
+// object with the given context and ID, or an empty path if the context has
+// no page. Namespaced objects are linked in the given namespace.
+func uiPath(ctx contexts.Context, id, namespace string) (string, url.Values) {
+	if namespace == "" || namespace == "*" {
+		namespace = "default"
+	}
+
+	switch ctx {
+	case contexts.Nodes:
+		return fmt.Sprintf("ui/clients/%s", id), nil
+	case contexts.Allocs:
+		return fmt.Sprintf("ui/allocations/%s", id), nil
+	case contexts.Jobs:
+		return fmt.Sprintf("ui/jobs/%s@%s", id, namespace), nil
+	case contexts.Evals:
+		return "ui/evaluations", url.Values{"currentEval": []string{id}}
+	case contexts.Volumes:
+		return fmt.Sprintf("ui/csi/volumes/%s@%s", id, namespace), nil
+	case contexts.Plugins:
+		return fmt.Sprintf("ui/csi/plugins/%s", id), nil
+	}
+	return "", nil
+}
+
 // logMultiMatchError is used to log an error message when multiple matches are
 // found. The error message logged displays the matched IDs per context.
 func (c *UiCommand) logMultiMatchError(id string, matches map[contexts.Context][]string) {
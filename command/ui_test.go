@@ -8,7 +8,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/nomad/api/contexts"
+	"github.com/hashicorp/nomad/ci"
 	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -112,3 +115,33 @@ func TestCommand_Ui(t *testing.T) {
 		})
 	}
 }
+
+func TestCommand_Ui_uiPath(t *testing.T) {
+	ci.Parallel(t)
+
+	cases := []struct {
+		ctx       contexts.Context
+		namespace string
+		expected  string
+	}{
+		{ctx: contexts.Nodes, expected: "ui/clients/abc"},
+		{ctx: contexts.Allocs, expected: "ui/allocations/abc"},
+		{ctx: contexts.Jobs, expected: "ui/jobs/abc@default"},
+		{ctx: contexts.Jobs, namespace: "*", expected: "ui/jobs/abc@default"},
+		{ctx: contexts.Jobs, namespace: "dev", expected: "ui/jobs/abc@dev"},
+		{ctx: contexts.Evals, expected: "ui/evaluations?currentEval=abc"},
+		{ctx: contexts.Volumes, namespace: "dev", expected: "ui/csi/volumes/abc@dev"},
+		{ctx: contexts.Plugins, expected: "ui/csi/plugins/abc"},
+		{ctx: contexts.Deployments, expected: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.ctx)+"/"+tc.namespace, func(t *testing.T) {
+			path, params := uiPath(tc.ctx, "abc", tc.namespace)
+			if len(params) > 0 {
+				path += "?" + params.Encode()
+			}
+			must.Eq(t, tc.expected, path)
+		})
+	}
+}
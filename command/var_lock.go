@@ -24,6 +24,8 @@ type VarLockCommand struct {
 	inFmt     string
 	ttl       string
 	lockDelay string
+	session   string
+	limit     int
 
 	varPutCommand *VarPutCommand
 }
@@ -81,6 +83,18 @@ Var lock Options:
   -backoff
 	Optional, indicates how long to wait between attempts to obtain the lock. 
 	By default the lease algorithm waits for 1.1 times the lock TTL.
+
+  -limit
+	Optional, maximum number of holders of the lock at the same time. With a
+	limit greater than 1, the lock is a semaphore made of one lock per slot,
+	stored in the variables "<path>/slot-<n>". Defaults to 1.
+
+  -session
+	Optional, ties the lock to the lifetime of the caller, either "alloc" or
+	"token". With "alloc", the lock is held until the allocation of the
+	workload identity used by the command stops. With "token", the lock is
+	held until the ACL token used by the command expires or is deleted.
+	The lock is still renewed while the child process runs.
    
   -shell
 	Optional, use a shell to run the command (can set a custom shell via		
@@ -123,6 +137,8 @@ func (c *VarLockCommand) Run(args []string) int {
 	flags.BoolVar(&earlyReturn, "early-return", false, "")
 	flags.Int64Var(&maxRetry, "max-retry", 5, "")
 	flags.DurationVar(&backoff, "backoff", 0, "")
+	flags.StringVar(&c.session, "session", "", "")
+	flags.IntVar(&c.limit, "limit", 1, "")
 
 	if fileInfo, _ := os.Stdout.Stat(); (fileInfo.Mode() & os.ModeCharDevice) != 0 {
 		flags.StringVar(&c.varPutCommand.outFmt, "out", "none", "")
@@ -202,6 +218,20 @@ func (c *VarLockCommand) Run(args []string) int {
 		sv.Lock.LockDelay = c.lockDelay
 	}
 
+	switch c.session {
+	case "", api.VariableLockSessionAlloc, api.VariableLockSessionToken:
+		sv.Lock.Session = c.session
+	default:
+		c.varPutCommand.Ui.Error(fmt.Sprintf("Invalid session %q: must be %q or %q",
+			c.session, api.VariableLockSessionAlloc, api.VariableLockSessionToken))
+		return 1
+	}
+
+	if c.limit < 1 {
+		c.varPutCommand.Ui.Error(fmt.Sprintf("Invalid limit %d: must be at least 1", c.limit))
+		return 1
+	}
+
 	// Get the HTTP client
 	client, err := c.varPutCommand.Meta.Client()
 	if err != nil {
@@ -215,7 +245,13 @@ func (c *VarLockCommand) Run(args []string) int {
 		lo = append(lo, api.LocksOptionWithMaxRetries(maxRetry))
 	}
 
-	l, err := client.Locks(api.WriteOptions{}, *sv, lo...)
+	var l api.Locker
+	if c.limit > 1 {
+		c.varPutCommand.verbose(fmt.Sprintf("Using a semaphore with %d slots", c.limit))
+		l, err = client.Semaphore(api.WriteOptions{}, *sv, c.limit, lo...)
+	} else {
+		l, err = client.Locks(api.WriteOptions{}, *sv, lo...)
+	}
 	if err != nil {
 		c.varPutCommand.Ui.Error(fmt.Sprintf("Error initializing lock handler: %s", err))
 		return 1
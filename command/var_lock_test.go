@@ -81,6 +81,30 @@ func TestVarLockCommand_Fails(t *testing.T) {
 		must.One(t, code)
 		must.StrContains(t, out, "Invalid Lock Delay: time")
 	})
+
+	t.Run("invalid_session", func(t *testing.T) {
+		ci.Parallel(t)
+		ui := cli.NewMockUi()
+		cmd := &VarLockCommand{
+			varPutCommand: &VarPutCommand{Meta: Meta{Ui: ui}},
+		}
+		code := cmd.Run([]string{"-session=node", "bar", "foo"})
+		out := ui.ErrorWriter.String()
+		must.One(t, code)
+		must.StrContains(t, out, `Invalid session "node"`)
+	})
+
+	t.Run("invalid_limit", func(t *testing.T) {
+		ci.Parallel(t)
+		ui := cli.NewMockUi()
+		cmd := &VarLockCommand{
+			varPutCommand: &VarPutCommand{Meta: Meta{Ui: ui}},
+		}
+		code := cmd.Run([]string{"-limit=0", "bar", "foo"})
+		out := ui.ErrorWriter.String()
+		must.One(t, code)
+		must.StrContains(t, out, "Invalid limit 0")
+	})
 }
 
 func TestVarLockCommand_Good(t *testing.T) {
@@ -150,3 +174,35 @@ func TestVarLockCommand_Good_NoShell(t *testing.T) {
 		_, _ = client.Variables().Delete("test/var/noShell", nil)
 	})
 }
+
+func TestVarLockCommand_Good_Semaphore(t *testing.T) {
+	ci.Parallel(t)
+
+	// Create a server
+	srv, client, url := testServer(t, true, nil)
+	defer srv.Shutdown()
+
+	ui := cli.NewMockUi()
+	cmd := &VarLockCommand{
+		varPutCommand: &VarPutCommand{Meta: Meta{Ui: ui}},
+	}
+
+	filePath := fmt.Sprintf("%v-semaphore.txt", time.Now().Unix())
+
+	code := cmd.Run([]string{"-address=" + url, "-limit=2", "test/var/semaphore", "touch ", filePath})
+	must.Zero(t, code, must.Sprintf("expected exit 0, got: %d; %v", code, ui.ErrorWriter.String()))
+
+	// The lock is held on the first slot of the semaphore.
+	sv, _, err := srv.APIClient().Variables().Peek("test/var/semaphore/slot-0", nil)
+	must.NoError(t, err)
+	must.NotNil(t, sv)
+
+	// Check for the file
+	_, err = os.ReadFile(filePath)
+	must.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.Remove(filePath)
+		_, _ = client.Variables().Delete("test/var/semaphore/slot-0", nil)
+	})
+}
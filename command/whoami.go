@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+)
+
+type WhoAmICommand struct {
+	Meta
+}
+
+func (c *WhoAmICommand) Help() string {
+	helpText := `
+Usage: nomad whoami [options]
+
+  Whoami describes the identity used by the CLI, which is the ACL token or
+  workload identity set with -token or NOMAD_TOKEN, or the token of the active
+  context. It outputs the identity, its ACL policies, including the policies of
+  its ACL roles, and its effective capabilities.
+
+General Options:
+
+  ` + generalOptionsUsage(usageOptsDefault|usageOptsNoNamespace) + `
+
+Whoami Options:
+
+  -json
+    Output the identity in its JSON format.
+
+  -t
+    Format and display the identity using a Go template.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *WhoAmICommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"-json": complete.PredictNothing,
+			"-t":    complete.PredictAnything,
+		})
+}
+
+func (c *WhoAmICommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *WhoAmICommand) Synopsis() string {
+	return "Describe the identity used by the CLI"
+}
+
+func (c *WhoAmICommand) Name() string { return "whoami" }
+
+func (c *WhoAmICommand) Run(args []string) int {
+	var json bool
+	var tmpl string
+
+	flags := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&json, "json", false, "")
+	flags.StringVar(&tmpl, "t", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if args = flags.Args(); len(args) != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(commandErrorText(c))
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	whoami, _, err := client.ACLTokens().WhoAmI(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error fetching identity: %s", err))
+		return 1
+	}
+
+	if json || len(tmpl) > 0 {
+		out, err := Format(json, tmpl, whoami)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(formatKV(whoAmIIdentity(whoami)))
+	c.outputCapabilities(whoami.Capabilities)
+	return 0
+}
+
+// whoAmIIdentity returns the key/value rows describing the identity.
+func whoAmIIdentity(whoami *api.ACLWhoAmI) []string {
+	policies := "<none>"
+	if len(whoami.Policies) > 0 {
+		policies = strings.Join(whoami.Policies, ",")
+	}
+
+	identity := whoami.Identity
+	switch {
+	case identity != nil && identity.ACLToken != nil:
+		token := identity.ACLToken
+		expiry := "<none>"
+		if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
+			expiry = formatTime(*token.ExpirationTime)
+		}
+		return []string{
+			"Identity|ACL token",
+			fmt.Sprintf("Accessor ID|%s", token.AccessorID),
+			fmt.Sprintf("Name|%s", token.Name),
+			fmt.Sprintf("Type|%s", token.Type),
+			fmt.Sprintf("Global|%v", token.Global),
+			fmt.Sprintf("Expiry Time|%s", expiry),
+			fmt.Sprintf("Policies|%s", policies),
+		}
+	case identity != nil && identity.Claims != nil:
+		claims := identity.Claims
+		return []string{
+			"Identity|Workload identity",
+			fmt.Sprintf("Namespace|%s", claims.Namespace),
+			fmt.Sprintf("Job ID|%s", claims.JobID),
+			fmt.Sprintf("Allocation ID|%s", claims.AllocationID),
+			fmt.Sprintf("Task|%s", claims.TaskName),
+			fmt.Sprintf("Service|%s", claims.ServiceName),
+			fmt.Sprintf("Policies|%s", policies),
+		}
+	case identity != nil && identity.ClientID != "":
+		return []string{
+			"Identity|Client node",
+			fmt.Sprintf("Node ID|%s", identity.ClientID),
+		}
+	default:
+		return []string{
+			"Identity|<none>",
+			fmt.Sprintf("Policies|%s", policies),
+		}
+	}
+}
+
+func (c *WhoAmICommand) outputCapabilities(caps *api.ACLCapabilities) {
+	if caps == nil {
+		c.Ui.Output("\nNo capabilities returned: ACLs are disabled or the identity could not be resolved")
+		return
+	}
+
+	c.Ui.Output(c.Colorize().Color("\n[bold]Capabilities[reset]"))
+	if caps.Management {
+		c.Ui.Output("Management token: all operations are allowed")
+		return
+	}
+
+	c.Ui.Output(formatKV([]string{
+		fmt.Sprintf("Agent|%s", orDeny(caps.Agent)),
+		fmt.Sprintf("Node|%s", orDeny(caps.Node)),
+		fmt.Sprintf("Operator|%s", orDeny(caps.Operator)),
+		fmt.Sprintf("Quota|%s", orDeny(caps.Quota)),
+		fmt.Sprintf("Plugin|%s", orDeny(caps.Plugin)),
+	}))
+
+	for _, section := range []struct {
+		title string
+		caps  map[string][]string
+	}{
+		{"Namespace", caps.Namespaces},
+		{"Node Pool", caps.NodePools},
+		{"Host Volume", caps.HostVolumes},
+	} {
+		if len(section.caps) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(section.caps))
+		for name := range section.caps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		rows := []string{fmt.Sprintf("%s|Capabilities", section.title)}
+		for _, name := range names {
+			rows = append(rows, fmt.Sprintf("%s|%s", name, strings.Join(section.caps[name], ",")))
+		}
+		c.Ui.Output(c.Colorize().Color(fmt.Sprintf("\n[bold]%s Capabilities[reset]", section.title)))
+		c.Ui.Output(formatList(rows))
+	}
+}
+
+// orDeny returns the policy disposition, which is deny when unset.
+func orDeny(policy string) string {
+	if policy == "" {
+		return "deny"
+	}
+	return policy
+}
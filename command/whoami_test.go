@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/command/agent"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/cli"
+	"github.com/shoenig/test/must"
+)
+
+func TestWhoAmICommand_Implements(t *testing.T) {
+	ci.Parallel(t)
+	var _ cli.Command = &WhoAmICommand{}
+}
+
+func TestWhoAmICommand_Run(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, _, url := testServer(t, true, func(c *agent.Config) {
+		c.ACL.Enabled = true
+	})
+	defer srv.Shutdown()
+
+	state := srv.Agent.Server().State()
+	policy := mock.ACLPolicy()
+	must.NoError(t, state.UpsertACLPolicies(structs.MsgTypeTestSetup, 1000, []*structs.ACLPolicy{policy}))
+	token := mock.ACLToken()
+	token.Policies = []string{policy.Name}
+	must.NoError(t, state.UpsertACLTokens(structs.MsgTypeTestSetup, 1010, []*structs.ACLToken{token}))
+
+	// Fails on arguments
+	ui := cli.NewMockUi()
+	cmd := &WhoAmICommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"-address=" + url, "foo"}))
+	must.StrContains(t, ui.ErrorWriter.String(), "This command takes no arguments")
+
+	// Outputs the identity and its capabilities
+	ui = cli.NewMockUi()
+	cmd = &WhoAmICommand{Meta: Meta{Ui: ui}}
+	must.Zero(t, cmd.Run([]string{"-address=" + url, "-token=" + token.SecretID}))
+	out := ui.OutputWriter.String()
+	must.StrContains(t, out, token.AccessorID)
+	must.StrContains(t, out, policy.Name)
+	must.StrContains(t, out, "Namespace Capabilities")
+	must.StrNotContains(t, out, token.SecretID)
+
+	// Outputs JSON
+	ui = cli.NewMockUi()
+	cmd = &WhoAmICommand{Meta: Meta{Ui: ui}}
+	must.Zero(t, cmd.Run([]string{"-address=" + url, "-token=" + srv.RootToken.SecretID, "-json"}))
+	var whoami api.ACLWhoAmI
+	must.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &whoami))
+	must.Eq(t, srv.RootToken.AccessorID, whoami.Identity.ACLToken.AccessorID)
+	must.True(t, whoami.Capabilities.Management)
+
+	// Fails with an unknown token
+	ui = cli.NewMockUi()
+	cmd = &WhoAmICommand{Meta: Meta{Ui: ui}}
+	must.One(t, cmd.Run([]string{"-address=" + url, "-token=" + mock.ACLToken().SecretID}))
+	must.StrContains(t, ui.ErrorWriter.String(), "Error fetching identity")
+}
@@ -0,0 +1,965 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build linux
+
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/hashicorp/consul-template/signals"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/lib/cgroupslib"
+	"github.com/hashicorp/nomad/client/lib/cpustats"
+	"github.com/hashicorp/nomad/client/lib/fifo"
+	"github.com/hashicorp/nomad/drivers/shared/capabilities"
+	"github.com/hashicorp/nomad/drivers/shared/eventer"
+	"github.com/hashicorp/nomad/helper/pluginutils/loader"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/plugins/base"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/hashicorp/nomad/plugins/drivers/utils"
+	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+	pstructs "github.com/hashicorp/nomad/plugins/shared/structs"
+)
+
+const (
+	// pluginName is the name of the plugin
+	pluginName = "containerd"
+
+	// fingerprintPeriod is the interval at which the driver will send fingerprint responses
+	fingerprintPeriod = 30 * time.Second
+
+	// taskHandleVersion is the version of task handle which this driver sets
+	// and understands how to decode driver state
+	taskHandleVersion = 1
+
+	// rpcTimeout is the timeout of the containerd calls that don't pull
+	// images or wait on tasks
+	rpcTimeout = 30 * time.Second
+)
+
+var (
+	// PluginID is the containerd plugin metadata registered in the plugin
+	// catalog.
+	PluginID = loader.PluginID{
+		Name:       pluginName,
+		PluginType: base.PluginTypeDriver,
+	}
+
+	// PluginConfig is the containerd driver factory function registered in
+	// the plugin catalog.
+	PluginConfig = &loader.InternalPluginConfig{
+		Config:  map[string]interface{}{},
+		Factory: func(ctx context.Context, l hclog.Logger) interface{} { return NewContainerdDriver(ctx, l) },
+	}
+
+	// pluginInfo is the response returned for the PluginInfo RPC
+	pluginInfo = &base.PluginInfoResponse{
+		Type:              base.PluginTypeDriver,
+		PluginApiVersions: []string{drivers.ApiVersion010},
+		PluginVersion:     "0.1.0",
+		Name:              pluginName,
+	}
+
+	// configSpec is the hcl specification returned by the ConfigSchema RPC
+	configSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		"address": hclspec.NewDefault(
+			hclspec.NewAttr("address", "string", false),
+			hclspec.NewLiteral(`"/run/containerd/containerd.sock"`),
+		),
+		"namespace": hclspec.NewDefault(
+			hclspec.NewAttr("namespace", "string", false),
+			hclspec.NewLiteral(`"nomad"`),
+		),
+		"runtime": hclspec.NewDefault(
+			hclspec.NewAttr("runtime", "string", false),
+			hclspec.NewLiteral(`"io.containerd.runc.v2"`),
+		),
+		"pull_timeout": hclspec.NewDefault(
+			hclspec.NewAttr("pull_timeout", "string", false),
+			hclspec.NewLiteral(`"5m"`),
+		),
+		"allow_privileged": hclspec.NewDefault(
+			hclspec.NewAttr("allow_privileged", "bool", false),
+			hclspec.NewLiteral("false"),
+		),
+		"allow_host_mounts": hclspec.NewDefault(
+			hclspec.NewAttr("allow_host_mounts", "bool", false),
+			hclspec.NewLiteral("false"),
+		),
+		"allow_caps": hclspec.NewDefault(
+			hclspec.NewAttr("allow_caps", "list(string)", false),
+			hclspec.NewLiteral(capabilities.HCLSpecLiteral),
+		),
+	})
+
+	// taskConfigSpec is the hcl specification for the driver config section of
+	// a task within a job. It is returned in the TaskConfigSchema RPC
+	taskConfigSpec = hclspec.NewObject(map[string]*hclspec.Spec{
+		"image":           hclspec.NewAttr("image", "string", true),
+		"command":         hclspec.NewAttr("command", "string", false),
+		"args":            hclspec.NewAttr("args", "list(string)", false),
+		"entrypoint":      hclspec.NewAttr("entrypoint", "list(string)", false),
+		"cwd":             hclspec.NewAttr("cwd", "string", false),
+		"hostname":        hclspec.NewAttr("hostname", "string", false),
+		"privileged":      hclspec.NewAttr("privileged", "bool", false),
+		"readonly_rootfs": hclspec.NewAttr("readonly_rootfs", "bool", false),
+		"extra_hosts":     hclspec.NewAttr("extra_hosts", "list(string)", false),
+		"cap_add":         hclspec.NewAttr("cap_add", "list(string)", false),
+		"cap_drop":        hclspec.NewAttr("cap_drop", "list(string)", false),
+		"pids_limit":      hclspec.NewAttr("pids_limit", "number", false),
+		"devices": hclspec.NewBlockList("devices", hclspec.NewObject(map[string]*hclspec.Spec{
+			"host_path":      hclspec.NewAttr("host_path", "string", true),
+			"container_path": hclspec.NewAttr("container_path", "string", false),
+			"permissions":    hclspec.NewAttr("permissions", "string", false),
+		})),
+		"mounts": hclspec.NewBlockList("mounts", hclspec.NewObject(map[string]*hclspec.Spec{
+			"type": hclspec.NewDefault(
+				hclspec.NewAttr("type", "string", false),
+				hclspec.NewLiteral(`"bind"`),
+			),
+			"source":  hclspec.NewAttr("source", "string", false),
+			"target":  hclspec.NewAttr("target", "string", true),
+			"options": hclspec.NewAttr("options", "list(string)", false),
+		})),
+		"auth": hclspec.NewBlock("auth", false, hclspec.NewObject(map[string]*hclspec.Spec{
+			"username": hclspec.NewAttr("username", "string", false),
+			"password": hclspec.NewAttr("password", "string", false),
+		})),
+	})
+
+	// driverCapabilities represents the RPC response for what features are
+	// implemented by the containerd task driver
+	driverCapabilities = &drivers.Capabilities{
+		SendSignals: true,
+		Exec:        true,
+		FSIsolation: drivers.FSIsolationImage,
+		NetIsolationModes: []drivers.NetIsolationMode{
+			drivers.NetIsolationModeHost,
+			drivers.NetIsolationModeGroup,
+		},
+		MountConfigs: drivers.MountConfigSupportAll,
+	}
+
+	// containerIDInvalidChars matches the characters containerd doesn't
+	// accept in container IDs
+	containerIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+)
+
+// Driver runs tasks as containers of a containerd daemon, without requiring
+// dockerd.
+type Driver struct {
+	// eventer is used to handle multiplexing of TaskEvents calls such that an
+	// event can be broadcast to all callers
+	eventer *eventer.Eventer
+
+	// config is the driver configuration set by the SetConfig RPC
+	config Config
+
+	// nomadConfig is the client config from nomad
+	nomadConfig *base.ClientDriverConfig
+
+	// tasks is the in memory datastore mapping taskIDs to driverHandles
+	tasks *taskStore
+
+	// ctx is the context for the driver. It is passed to other subsystems to
+	// coordinate shutdown
+	ctx context.Context
+
+	// logger will log to the Nomad agent
+	logger hclog.Logger
+
+	// client is the containerd client, created on first use
+	client     *containerd.Client
+	clientLock sync.Mutex
+
+	// A tri-state boolean to know if the fingerprinting has happened and
+	// whether it has been successful
+	fingerprintSuccess *bool
+	fingerprintLock    sync.Mutex
+
+	// compute contains cpu compute information
+	compute cpustats.Compute
+}
+
+// Config is the driver configuration set by the SetConfig RPC call
+type Config struct {
+	// Address is the path of the containerd socket.
+	Address string `codec:"address"`
+
+	// Namespace is the containerd namespace of the containers and images of
+	// the tasks.
+	Namespace string `codec:"namespace"`
+
+	// Runtime is the containerd runtime used to run the tasks.
+	Runtime string `codec:"runtime"`
+
+	// PullTimeout is the time an image pull may take.
+	PullTimeout string `codec:"pull_timeout"`
+	pullTimeout time.Duration
+
+	// AllowPrivileged allows tasks to run privileged containers.
+	AllowPrivileged bool `codec:"allow_privileged"`
+
+	// AllowHostMounts allows tasks to bind mount host paths outside of their
+	// allocation directory.
+	AllowHostMounts bool `codec:"allow_host_mounts"`
+
+	// AllowCaps configures which Linux Capabilities are enabled for tasks
+	// running on this node.
+	AllowCaps []string `codec:"allow_caps"`
+}
+
+func (c *Config) validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("address must be set")
+	}
+	if c.Namespace == "" {
+		return fmt.Errorf("namespace must be set")
+	}
+	if c.Runtime == "" {
+		return fmt.Errorf("runtime must be set")
+	}
+
+	d, err := time.ParseDuration(c.PullTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse pull_timeout %q: %v", c.PullTimeout, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("pull_timeout must be positive, got %q", c.PullTimeout)
+	}
+	c.pullTimeout = d
+
+	badCaps := capabilities.Supported().Difference(capabilities.New(c.AllowCaps))
+	if !badCaps.Empty() {
+		return fmt.Errorf("allow_caps configured with capabilities not supported by system: %s", badCaps)
+	}
+
+	return nil
+}
+
+// TaskConfig is the driver configuration of a task within a job
+type TaskConfig struct {
+	// Image is the reference of the image of the container.
+	Image string `codec:"image"`
+
+	// Command overrides the command of the image.
+	Command string `codec:"command"`
+
+	// Args are passed along to Command, or to the command of the image.
+	Args []string `codec:"args"`
+
+	// Entrypoint overrides the entrypoint of the image.
+	Entrypoint []string `codec:"entrypoint"`
+
+	// Cwd overrides the working directory of the image.
+	Cwd string `codec:"cwd"`
+
+	// Hostname is the hostname of the container.
+	Hostname string `codec:"hostname"`
+
+	// Privileged runs the container with all capabilities and devices.
+	Privileged bool `codec:"privileged"`
+
+	// ReadonlyRootfs mounts the root filesystem of the container read-only.
+	ReadonlyRootfs bool `codec:"readonly_rootfs"`
+
+	// ExtraHosts are added to the /etc/hosts of the container, as
+	// "<hostname>:<ip address>".
+	ExtraHosts []string `codec:"extra_hosts"`
+
+	// CapAdd is a set of linux capabilities to enable.
+	CapAdd []string `codec:"cap_add"`
+
+	// CapDrop is a set of linux capabilities to disable.
+	CapDrop []string `codec:"cap_drop"`
+
+	// PidsLimit limits the number of processes of the container.
+	PidsLimit int64 `codec:"pids_limit"`
+
+	// Devices are the host devices passed through to the container.
+	Devices []DeviceConfig `codec:"devices"`
+
+	// Mounts are the extra mounts of the container.
+	Mounts []MountConfig `codec:"mounts"`
+
+	// Auth are the credentials used to pull the image.
+	Auth AuthConfig `codec:"auth"`
+}
+
+// DeviceConfig is a host device passed through to the container.
+type DeviceConfig struct {
+	HostPath      string `codec:"host_path"`
+	ContainerPath string `codec:"container_path"`
+	Permissions   string `codec:"permissions"`
+}
+
+// MountConfig is a mount of the container.
+type MountConfig struct {
+	Type    string   `codec:"type"`
+	Source  string   `codec:"source"`
+	Target  string   `codec:"target"`
+	Options []string `codec:"options"`
+}
+
+// AuthConfig are the registry credentials used to pull the image.
+type AuthConfig struct {
+	Username string `codec:"username"`
+	Password string `codec:"password"`
+}
+
+func (tc *TaskConfig) validate(config *Config) error {
+	if tc.Image == "" {
+		return fmt.Errorf("image must be set")
+	}
+	if tc.Privileged && !config.AllowPrivileged {
+		return fmt.Errorf("privileged containers are not allowed on this client")
+	}
+	if tc.PidsLimit < 0 {
+		return fmt.Errorf("pids_limit must not be negative, got %d", tc.PidsLimit)
+	}
+
+	supported := capabilities.Supported()
+	badAdds := supported.Difference(capabilities.New(tc.CapAdd))
+	if !badAdds.Empty() {
+		return fmt.Errorf("cap_add configured with capabilities not supported by system: %s", badAdds)
+	}
+	badDrops := supported.Difference(capabilities.New(tc.CapDrop))
+	if !badDrops.Empty() {
+		return fmt.Errorf("cap_drop configured with capabilities not supported by system: %s", badDrops)
+	}
+
+	for _, m := range tc.Mounts {
+		switch m.Type {
+		case "bind":
+			if m.Source == "" {
+				return fmt.Errorf("bind mount of %q must set source", m.Target)
+			}
+		case "tmpfs":
+		default:
+			return fmt.Errorf("mount type must be %q or %q, got %q", "bind", "tmpfs", m.Type)
+		}
+	}
+
+	return nil
+}
+
+// TaskState is the state which is encoded in the handle returned in
+// StartTask. This information is needed to rebuild the task state and handler
+// during recovery.
+type TaskState struct {
+	TaskConfig  *drivers.TaskConfig
+	ContainerID string
+	StartedAt   time.Time
+}
+
+// NewContainerdDriver returns a new DriverPlugin implementation
+func NewContainerdDriver(ctx context.Context, logger hclog.Logger) drivers.DriverPlugin {
+	logger = logger.Named(pluginName)
+	return &Driver{
+		eventer: eventer.NewEventer(ctx, logger),
+		tasks:   newTaskStore(),
+		ctx:     ctx,
+		logger:  logger,
+	}
+}
+
+// setFingerprintSuccess marks the driver as having fingerprinted successfully
+func (d *Driver) setFingerprintSuccess() {
+	d.fingerprintLock.Lock()
+	d.fingerprintSuccess = pointer.Of(true)
+	d.fingerprintLock.Unlock()
+}
+
+// setFingerprintFailure marks the driver as having failed fingerprinting
+func (d *Driver) setFingerprintFailure() {
+	d.fingerprintLock.Lock()
+	d.fingerprintSuccess = pointer.Of(false)
+	d.fingerprintLock.Unlock()
+}
+
+// fingerprintSuccessful returns true if the driver has
+// never fingerprinted or has successfully fingerprinted
+func (d *Driver) fingerprintSuccessful() bool {
+	d.fingerprintLock.Lock()
+	defer d.fingerprintLock.Unlock()
+	return d.fingerprintSuccess == nil || *d.fingerprintSuccess
+}
+
+// getClient returns the containerd client, connecting to containerd the first
+// time it's called.
+func (d *Driver) getClient() (*containerd.Client, error) {
+	d.clientLock.Lock()
+	defer d.clientLock.Unlock()
+
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	client, err := containerd.New(d.config.Address,
+		containerd.WithDefaultNamespace(d.config.Namespace),
+		containerd.WithDefaultRuntime(d.config.Runtime),
+		containerd.WithTimeout(rpcTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to containerd at %q: %v", d.config.Address, err)
+	}
+	d.client = client
+	return client, nil
+}
+
+func (d *Driver) PluginInfo() (*base.PluginInfoResponse, error) {
+	return pluginInfo, nil
+}
+
+func (d *Driver) ConfigSchema() (*hclspec.Spec, error) {
+	return configSpec, nil
+}
+
+func (d *Driver) SetConfig(cfg *base.Config) error {
+	// unpack, validate, and set agent plugin config
+	var config Config
+	if len(cfg.PluginConfig) != 0 {
+		if err := base.MsgPackDecode(cfg.PluginConfig, &config); err != nil {
+			return err
+		}
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+	d.config = config
+
+	if cfg.AgentConfig != nil {
+		d.nomadConfig = cfg.AgentConfig.Driver
+		d.compute = cfg.AgentConfig.Compute()
+	}
+	return nil
+}
+
+func (d *Driver) TaskConfigSchema() (*hclspec.Spec, error) {
+	return taskConfigSpec, nil
+}
+
+// Capabilities is returned by the Capabilities RPC and indicates what
+// optional features this driver supports
+func (d *Driver) Capabilities() (*drivers.Capabilities, error) {
+	return driverCapabilities, nil
+}
+
+func (d *Driver) Fingerprint(ctx context.Context) (<-chan *drivers.Fingerprint, error) {
+	ch := make(chan *drivers.Fingerprint)
+	go d.handleFingerprint(ctx, ch)
+	return ch, nil
+}
+
+func (d *Driver) handleFingerprint(ctx context.Context, ch chan<- *drivers.Fingerprint) {
+	defer close(ch)
+	ticker := time.NewTimer(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			ticker.Reset(fingerprintPeriod)
+			ch <- d.buildFingerprint(ctx)
+		}
+	}
+}
+
+func (d *Driver) buildFingerprint(ctx context.Context) *drivers.Fingerprint {
+	fp := &drivers.Fingerprint{
+		Attributes:        map[string]*pstructs.Attribute{},
+		Health:            drivers.HealthStateHealthy,
+		HealthDescription: drivers.DriverHealthy,
+	}
+
+	if !utils.IsUnixRoot() {
+		fp.Health = drivers.HealthStateUndetected
+		fp.HealthDescription = drivers.DriverRequiresRootMessage
+		d.setFingerprintFailure()
+		return fp
+	}
+
+	if cgroupslib.GetMode() == cgroupslib.OFF {
+		fp.Health = drivers.HealthStateUnhealthy
+		fp.HealthDescription = drivers.NoCgroupMountMessage
+		d.setFingerprintFailure()
+		return fp
+	}
+
+	// Don't wait for the client to time out dialing when containerd isn't
+	// installed on this node.
+	if _, err := os.Stat(d.config.Address); err != nil {
+		if d.fingerprintSuccessful() {
+			d.logger.Debug("containerd socket not found", "address", d.config.Address, "error", err)
+		}
+		fp.Health = drivers.HealthStateUndetected
+		fp.HealthDescription = "Failed to connect to containerd"
+		d.setFingerprintFailure()
+		return fp
+	}
+
+	client, err := d.getClient()
+	if err != nil {
+		if d.fingerprintSuccessful() {
+			d.logger.Debug("could not connect to containerd", "error", err)
+		}
+		fp.Health = drivers.HealthStateUndetected
+		fp.HealthDescription = "Failed to connect to containerd"
+		d.setFingerprintFailure()
+		return fp
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+	version, err := client.Version(ctx)
+	if err != nil {
+		if d.fingerprintSuccessful() {
+			d.logger.Debug("could not get containerd version", "error", err)
+		}
+		fp.Health = drivers.HealthStateUndetected
+		fp.HealthDescription = "Failed to connect to containerd"
+		d.setFingerprintFailure()
+		return fp
+	}
+
+	fp.Attributes["driver.containerd"] = pstructs.NewBoolAttribute(true)
+	fp.Attributes["driver.containerd.version"] = pstructs.NewStringAttribute(version.Version)
+	fp.Attributes["driver.containerd.runtime"] = pstructs.NewStringAttribute(d.config.Runtime)
+	if d.config.AllowPrivileged {
+		fp.Attributes["driver.containerd.privileged.enabled"] = pstructs.NewBoolAttribute(true)
+	}
+	d.setFingerprintSuccess()
+	return fp
+}
+
+func (d *Driver) RecoverTask(handle *drivers.TaskHandle) error {
+	if handle == nil {
+		return fmt.Errorf("handle cannot be nil")
+	}
+
+	// If already attached to handle there's nothing to recover.
+	if _, ok := d.tasks.Get(handle.Config.ID); ok {
+		d.logger.Trace("nothing to recover; task already exists",
+			"task_id", handle.Config.ID,
+			"task_name", handle.Config.Name,
+		)
+		return nil
+	}
+
+	var taskState TaskState
+	if err := handle.GetDriverState(&taskState); err != nil {
+		d.logger.Error("failed to decode task state from handle", "error", err, "task_id", handle.Config.ID)
+		return fmt.Errorf("failed to decode task state from handle: %v", err)
+	}
+
+	client, err := d.getClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, rpcTimeout)
+	defer cancel()
+
+	container, err := client.LoadContainer(ctx, taskState.ContainerID)
+	if err != nil {
+		return fmt.Errorf("failed to load container %q: %v", taskState.ContainerID, err)
+	}
+
+	stdout, stderr, err := openLogFifos(taskState.TaskConfig)
+	if err != nil {
+		return err
+	}
+
+	task, err := container.Task(ctx, cio.NewAttach(cio.WithStreams(nil, stdout, stderr)))
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		return fmt.Errorf("failed to attach to task of container %q: %v", taskState.ContainerID, err)
+	}
+
+	exitCh, err := task.Wait(context.Background())
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		return fmt.Errorf("failed to wait on task of container %q: %v", taskState.ContainerID, err)
+	}
+
+	h := &taskHandle{
+		container:  container,
+		task:       task,
+		exitCh:     exitCh,
+		stdout:     stdout,
+		stderr:     stderr,
+		doneCh:     make(chan struct{}),
+		taskConfig: taskState.TaskConfig,
+		procState:  drivers.TaskStateRunning,
+		startedAt:  taskState.StartedAt,
+		exitResult: &drivers.ExitResult{},
+		logger:     d.logger.With("container_id", taskState.ContainerID),
+		compute:    d.compute,
+	}
+
+	d.tasks.Set(taskState.TaskConfig.ID, h)
+
+	go h.run()
+	return nil
+}
+
+func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drivers.DriverNetwork, error) {
+	if _, ok := d.tasks.Get(cfg.ID); ok {
+		return nil, nil, fmt.Errorf("task with ID %q already started", cfg.ID)
+	}
+
+	var driverConfig TaskConfig
+	if err := cfg.DecodeDriverConfig(&driverConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode driver config: %v", err)
+	}
+
+	if err := driverConfig.validate(&d.config); err != nil {
+		return nil, nil, fmt.Errorf("failed driver config validation: %v", err)
+	}
+
+	handle := drivers.NewTaskHandle(taskHandleVersion)
+	handle.Config = cfg
+
+	client, err := d.getClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	image, err := d.pullImage(client, cfg, &driverConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specOpts, err := d.specOpts(cfg, &driverConfig, image)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, rpcTimeout)
+	defer cancel()
+
+	// A container left behind by a previous attempt to start the task would
+	// prevent the new one from being created.
+	containerID := containerName(cfg)
+	if old, err := client.LoadContainer(ctx, containerID); err == nil {
+		d.logger.Debug("removing container left by a previous start", "container_id", containerID)
+		if err := deleteContainer(ctx, old); err != nil {
+			return nil, nil, fmt.Errorf("failed to remove existing container %q: %v", containerID, err)
+		}
+	}
+
+	container, err := client.NewContainer(ctx, containerID,
+		containerd.WithImage(image),
+		containerd.WithNewSnapshot(containerID+"-snapshot", image),
+		containerd.WithRuntime(d.config.Runtime, nil),
+		containerd.WithNewSpec(specOpts...),
+		containerd.WithContainerLabels(map[string]string{
+			"com.hashicorp.nomad.alloc_id":  cfg.AllocID,
+			"com.hashicorp.nomad.job_name":  cfg.JobName,
+			"com.hashicorp.nomad.task_name": cfg.Name,
+		}),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create container: %v", err)
+	}
+
+	stdout, stderr, err := openLogFifos(cfg)
+	if err != nil {
+		deleteContainer(ctx, container)
+		return nil, nil, err
+	}
+
+	task, err := container.NewTask(ctx, cio.NewCreator(cio.WithStreams(nil, stdout, stderr)))
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		deleteContainer(ctx, container)
+		return nil, nil, fmt.Errorf("failed to create task: %v", err)
+	}
+
+	// Wait must be called before the task is started so its exit is never
+	// missed.
+	exitCh, err := task.Wait(context.Background())
+	if err == nil {
+		err = task.Start(ctx)
+	}
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		deleteContainer(ctx, container)
+		return nil, nil, fmt.Errorf("failed to start task: %v", err)
+	}
+
+	h := &taskHandle{
+		container:  container,
+		task:       task,
+		exitCh:     exitCh,
+		stdout:     stdout,
+		stderr:     stderr,
+		doneCh:     make(chan struct{}),
+		taskConfig: cfg,
+		procState:  drivers.TaskStateRunning,
+		startedAt:  time.Now().Round(time.Millisecond),
+		exitResult: &drivers.ExitResult{},
+		logger:     d.logger.With("container_id", containerID),
+		compute:    d.compute,
+	}
+
+	driverState := TaskState{
+		TaskConfig:  cfg,
+		ContainerID: containerID,
+		StartedAt:   h.startedAt,
+	}
+
+	if err := handle.SetDriverState(&driverState); err != nil {
+		d.logger.Error("failed to start task, error setting driver state", "error", err)
+		h.destroy()
+		return nil, nil, fmt.Errorf("failed to set driver state: %v", err)
+	}
+
+	d.tasks.Set(cfg.ID, h)
+	go h.run()
+
+	return handle, nil, nil
+}
+
+// pullImage pulls and unpacks the image of the task, unless it's already
+// present.
+func (d *Driver) pullImage(client *containerd.Client, cfg *drivers.TaskConfig, driverConfig *TaskConfig) (containerd.Image, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.config.pullTimeout)
+	defer cancel()
+
+	ref, err := normalizeImageRef(driverConfig.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	if image, err := client.GetImage(ctx, ref); err == nil {
+		if unpacked, err := image.IsUnpacked(ctx, containerd.DefaultSnapshotter); err == nil && unpacked {
+			return image, nil
+		}
+	}
+
+	d.eventer.EmitEvent(&drivers.TaskEvent{
+		TaskID:    cfg.ID,
+		AllocID:   cfg.AllocID,
+		TaskName:  cfg.Name,
+		Timestamp: time.Now(),
+		Message:   "Downloading image",
+		Annotations: map[string]string{
+			"image": ref,
+		},
+	})
+
+	image, err := client.Pull(ctx, ref,
+		containerd.WithPullUnpack,
+		containerd.WithResolver(newResolver(&driverConfig.Auth)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull image %q: %v", ref, err)
+	}
+	return image, nil
+}
+
+// containerName returns the ID of the container of the task. containerd only
+// accepts a limited set of characters in IDs, so the task name is sanitized.
+func containerName(cfg *drivers.TaskConfig) string {
+	return containerIDInvalidChars.ReplaceAllString(cfg.Name, "_") + "-" + cfg.AllocID
+}
+
+// openLogFifos opens the fifos the task's stdout and stderr are written to.
+func openLogFifos(cfg *drivers.TaskConfig) (stdout, stderr io.WriteCloser, err error) {
+	stdout, err = fifo.OpenWriter(cfg.StdoutPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open stdout fifo: %v", err)
+	}
+	stderr, err = fifo.OpenWriter(cfg.StderrPath)
+	if err != nil {
+		stdout.Close()
+		return nil, nil, fmt.Errorf("failed to open stderr fifo: %v", err)
+	}
+	return stdout, stderr, nil
+}
+
+// deleteContainer deletes the task of the container, if any, then the
+// container and its snapshot.
+func deleteContainer(ctx context.Context, container containerd.Container) error {
+	if task, err := container.Task(ctx, nil); err == nil {
+		if _, err := task.Delete(ctx, containerd.WithProcessKill); err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+	}
+	if err := container.Delete(ctx, containerd.WithSnapshotCleanup); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (d *Driver) WaitTask(ctx context.Context, taskID string) (<-chan *drivers.ExitResult, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	ch := make(chan *drivers.ExitResult)
+	go d.handleWait(ctx, handle, ch)
+
+	return ch, nil
+}
+
+func (d *Driver) handleWait(ctx context.Context, handle *taskHandle, ch chan *drivers.ExitResult) {
+	defer close(ch)
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-d.ctx.Done():
+		return
+	case <-handle.doneCh:
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-d.ctx.Done():
+		return
+	case ch <- handle.result():
+	}
+}
+
+func (d *Driver) StopTask(taskID string, timeout time.Duration, signal string) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	sig := syscall.SIGTERM
+	if signal != "" {
+		s, err := parseSignal(signal)
+		if err != nil {
+			return err
+		}
+		sig = s
+	}
+
+	return handle.shutdown(sig, timeout)
+}
+
+func (d *Driver) DestroyTask(taskID string, force bool) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	if handle.IsRunning() && !force {
+		return fmt.Errorf("cannot destroy running task")
+	}
+
+	if err := handle.destroy(); err != nil {
+		return err
+	}
+
+	d.tasks.Delete(taskID)
+	return nil
+}
+
+func (d *Driver) InspectTask(taskID string) (*drivers.TaskStatus, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	return handle.TaskStatus(), nil
+}
+
+func (d *Driver) TaskStats(ctx context.Context, taskID string, interval time.Duration) (<-chan *drivers.TaskResourceUsage, error) {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	ch := make(chan *drivers.TaskResourceUsage)
+	go handle.collectStats(ctx, ch, interval)
+	return ch, nil
+}
+
+func (d *Driver) TaskEvents(ctx context.Context) (<-chan *drivers.TaskEvent, error) {
+	return d.eventer.TaskEvents(ctx)
+}
+
+func (d *Driver) SignalTask(taskID string, signal string) error {
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return drivers.ErrTaskNotFound
+	}
+
+	sig, err := parseSignal(signal)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, rpcTimeout)
+	defer cancel()
+	return handle.task.Kill(ctx, sig)
+}
+
+// parseSignal returns the signal with the given name.
+func parseSignal(signal string) (syscall.Signal, error) {
+	s, ok := signals.SignalLookup[signal]
+	if !ok {
+		return 0, fmt.Errorf("unknown signal %q", signal)
+	}
+	sig, ok := s.(syscall.Signal)
+	if !ok {
+		return 0, fmt.Errorf("unsupported signal %q", signal)
+	}
+	return sig, nil
+}
+
+func (d *Driver) ExecTask(taskID string, cmd []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
+	if len(cmd) == 0 {
+		return nil, fmt.Errorf("error cmd must have at least one value")
+	}
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, timeout)
+	defer cancel()
+	return handle.exec(ctx, cmd)
+}
+
+var _ drivers.ExecTaskStreamingDriver = (*Driver)(nil)
+
+func (d *Driver) ExecTaskStreaming(ctx context.Context, taskID string, opts *drivers.ExecOptions) (*drivers.ExitResult, error) {
+	defer opts.Stdout.Close()
+	defer opts.Stderr.Close()
+
+	if len(opts.Command) == 0 {
+		return nil, fmt.Errorf("command is required but was empty")
+	}
+	handle, ok := d.tasks.Get(taskID)
+	if !ok {
+		return nil, drivers.ErrTaskNotFound
+	}
+
+	return handle.execStreaming(ctx, opts)
+}
@@ -0,0 +1,386 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build linux
+
+package containerd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cgroupsv2 "github.com/containerd/cgroups/v2/stats"
+	"github.com/containerd/containerd/containers"
+	v2 "github.com/containerd/containerd/metrics/types/v2"
+	"github.com/containerd/containerd/oci"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/lib/cpustats"
+	"github.com/hashicorp/nomad/client/taskenv"
+	"github.com/hashicorp/nomad/helper/pluginutils/hclutils"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	dtestutil "github.com/hashicorp/nomad/plugins/drivers/testutils"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/shoenig/test/must"
+)
+
+// testConfig returns the plugin config with its defaults.
+func testConfig(t *testing.T) Config {
+	var config Config
+	parser := hclutils.NewConfigParser(configSpec)
+	parser.ParseHCL(t, "config {}", &config)
+	must.NoError(t, config.validate())
+	return config
+}
+
+// applySpecOpts applies the options to an empty spec.
+func applySpecOpts(t *testing.T, opts []oci.SpecOpts) *oci.Spec {
+	s := &oci.Spec{
+		Process: &specs.Process{},
+		Linux:   &specs.Linux{Resources: &specs.LinuxResources{}},
+	}
+	for _, opt := range opts {
+		must.NoError(t, opt(context.Background(), nil, &containers.Container{}, s))
+	}
+	return s
+}
+
+func TestConfig_validate(t *testing.T) {
+	ci.Parallel(t)
+
+	config := testConfig(t)
+	must.Eq(t, "/run/containerd/containerd.sock", config.Address)
+	must.Eq(t, "nomad", config.Namespace)
+	must.Eq(t, "io.containerd.runc.v2", config.Runtime)
+	must.Eq(t, 5*time.Minute, config.pullTimeout)
+	must.False(t, config.AllowPrivileged)
+
+	bad := config
+	bad.PullTimeout = "soon"
+	must.ErrorContains(t, bad.validate(), "failed to parse pull_timeout")
+
+	bad = config
+	bad.Namespace = ""
+	must.ErrorContains(t, bad.validate(), "namespace must be set")
+
+	bad = config
+	bad.AllowCaps = []string{"not_a_cap"}
+	must.ErrorContains(t, bad.validate(), "allow_caps configured with capabilities not supported")
+}
+
+func TestTaskConfig_validate(t *testing.T) {
+	ci.Parallel(t)
+
+	config := testConfig(t)
+
+	cases := []struct {
+		name string
+		tc   TaskConfig
+		err  string
+	}{
+		{
+			name: "ok",
+			tc: TaskConfig{
+				Image:  "redis:7",
+				Mounts: []MountConfig{{Type: "bind", Source: "local", Target: "/data"}, {Type: "tmpfs", Target: "/tmp"}},
+			},
+		},
+		{
+			name: "no image",
+			tc:   TaskConfig{},
+			err:  "image must be set",
+		},
+		{
+			name: "privileged",
+			tc:   TaskConfig{Image: "redis:7", Privileged: true},
+			err:  "privileged containers are not allowed",
+		},
+		{
+			name: "bad mount type",
+			tc:   TaskConfig{Image: "redis:7", Mounts: []MountConfig{{Type: "volume", Target: "/data"}}},
+			err:  "mount type must be",
+		},
+		{
+			name: "bind without source",
+			tc:   TaskConfig{Image: "redis:7", Mounts: []MountConfig{{Type: "bind", Target: "/data"}}},
+			err:  "must set source",
+		},
+		{
+			name: "bad cap",
+			tc:   TaskConfig{Image: "redis:7", CapAdd: []string{"not_a_cap"}},
+			err:  "cap_add configured with capabilities not supported",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.tc.validate(&config)
+			if tc.err == "" {
+				must.NoError(t, err)
+			} else {
+				must.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestTaskConfig_Parse(t *testing.T) {
+	ci.Parallel(t)
+
+	var tc TaskConfig
+	parser := hclutils.NewConfigParser(taskConfigSpec)
+	parser.ParseHCL(t, `
+config {
+  image      = "redis:7"
+  args       = ["--port", "6380"]
+  pids_limit = 100
+
+  devices {
+    host_path = "/dev/fuse"
+  }
+
+  mounts {
+    source = "local/data"
+    target = "/data"
+  }
+
+  auth {
+    username = "user"
+    password = "secret"
+  }
+}`, &tc)
+
+	must.Eq(t, "redis:7", tc.Image)
+	must.Eq(t, []string{"--port", "6380"}, tc.Args)
+	must.Eq(t, 100, tc.PidsLimit)
+	must.Eq(t, []DeviceConfig{{HostPath: "/dev/fuse"}}, tc.Devices)
+	must.Eq(t, []MountConfig{{Type: "bind", Source: "local/data", Target: "/data"}}, tc.Mounts)
+	must.Eq(t, AuthConfig{Username: "user", Password: "secret"}, tc.Auth)
+}
+
+func TestContainerName(t *testing.T) {
+	ci.Parallel(t)
+
+	allocID := uuid.Generate()
+	cfg := &drivers.TaskConfig{Name: "web/server 1", AllocID: allocID}
+	must.Eq(t, "web_server_1-"+allocID, containerName(cfg))
+}
+
+func TestNormalizeImageRef(t *testing.T) {
+	ci.Parallel(t)
+
+	ref, err := normalizeImageRef("redis")
+	must.NoError(t, err)
+	must.Eq(t, "docker.io/library/redis:latest", ref)
+
+	ref, err = normalizeImageRef("ghcr.io/example/app:1.2")
+	must.NoError(t, err)
+	must.Eq(t, "ghcr.io/example/app:1.2", ref)
+
+	_, err = normalizeImageRef("Not A Ref")
+	must.ErrorContains(t, err, "invalid image reference")
+}
+
+func TestResourceOpts(t *testing.T) {
+	ci.Parallel(t)
+
+	cfg := &drivers.TaskConfig{
+		Resources: &drivers.Resources{
+			NomadResources: &structs.AllocatedTaskResources{
+				Memory: structs.AllocatedMemoryResources{MemoryMB: 128, MemoryMaxMB: 256},
+			},
+			LinuxResources: &drivers.LinuxResources{
+				MemoryLimitBytes: 128 * 1024 * 1024,
+				CPUShares:        500,
+				CpusetCpus:       "0-1",
+			},
+		},
+	}
+	s := applySpecOpts(t, resourceOpts(cfg, &TaskConfig{PidsLimit: 10}))
+
+	res := s.Linux.Resources
+	must.Eq(t, 256*1024*1024, *res.Memory.Limit)
+	must.Eq(t, 128*1024*1024, *res.Memory.Reservation)
+	must.Eq(t, 500, *res.CPU.Shares)
+	must.Eq(t, "0-1", res.CPU.Cpus)
+	must.Eq(t, 10, res.Pids.Limit)
+}
+
+func TestNetworkOpts(t *testing.T) {
+	ci.Parallel(t)
+
+	// Tasks without network isolation use the host network.
+	s := applySpecOpts(t, networkOpts(&drivers.TaskConfig{}, &TaskConfig{}))
+	for _, ns := range s.Linux.Namespaces {
+		must.NotEq(t, specs.NetworkNamespace, ns.Type)
+	}
+
+	// Tasks in a group network join its namespace.
+	cfg := &drivers.TaskConfig{
+		NetworkIsolation: &drivers.NetworkIsolationSpec{
+			Mode:        drivers.NetIsolationModeGroup,
+			Path:        "/var/run/netns/alloc",
+			HostsConfig: &drivers.HostsConfig{Hostname: "alloc-host", Address: "172.26.64.2"},
+		},
+	}
+	s = applySpecOpts(t, networkOpts(cfg, &TaskConfig{}))
+	must.SliceContains(t, s.Linux.Namespaces, specs.LinuxNamespace{
+		Type: specs.NetworkNamespace,
+		Path: "/var/run/netns/alloc",
+	})
+	must.Eq(t, "alloc-host", s.Hostname)
+}
+
+func TestMounts(t *testing.T) {
+	ci.Parallel(t)
+
+	allocDir := t.TempDir()
+	cfg := &drivers.TaskConfig{
+		Name:     "web",
+		AllocDir: allocDir,
+		Env: map[string]string{
+			taskenv.AllocDir:     "/alloc",
+			taskenv.TaskLocalDir: "/local",
+			taskenv.SecretsDir:   "/secrets",
+		},
+		Mounts: []*drivers.MountConfig{{
+			HostPath:        "/srv/data",
+			TaskPath:        "/data",
+			Readonly:        true,
+			PropagationMode: structs.VolumeMountPropagationHostToTask,
+		}},
+	}
+	tc := &TaskConfig{
+		Mounts: []MountConfig{
+			{Type: "bind", Source: "local/conf", Target: "/etc/app"},
+			{Type: "tmpfs", Target: "/tmp"},
+		},
+	}
+
+	d := &Driver{config: testConfig(t)}
+	mounts, err := d.mounts(cfg, tc)
+	must.NoError(t, err)
+	must.Len(t, 6, mounts)
+
+	must.Eq(t, specs.Mount{
+		Type:        "bind",
+		Source:      filepath.Join(allocDir, "alloc"),
+		Destination: "/alloc",
+		Options:     []string{"rbind", "rw", "rprivate"},
+	}, mounts[0])
+	must.Eq(t, specs.Mount{
+		Type:        "bind",
+		Source:      "/srv/data",
+		Destination: "/data",
+		Options:     []string{"rbind", "ro", "rslave"},
+	}, mounts[3])
+	must.Eq(t, specs.Mount{
+		Type:        "bind",
+		Source:      filepath.Join(allocDir, "web", "local", "conf"),
+		Destination: "/etc/app",
+		Options:     []string{"rbind"},
+	}, mounts[4])
+	must.Eq(t, "tmpfs", mounts[5].Type)
+
+	// Host paths outside of the allocation require allow_host_mounts.
+	tc.Mounts = []MountConfig{{Type: "bind", Source: "/etc", Target: "/host-etc"}}
+	_, err = d.mounts(cfg, tc)
+	must.ErrorContains(t, err, "host mounts are not allowed")
+
+	d.config.AllowHostMounts = true
+	_, err = d.mounts(cfg, tc)
+	must.NoError(t, err)
+}
+
+func TestTaskResourceUsage_CgroupsV2(t *testing.T) {
+	ci.Parallel(t)
+
+	compute := cpustats.Compute{TotalCompute: 4000, NumCores: 4}
+	total, user, system := cpustats.New(compute), cpustats.New(compute), cpustats.New(compute)
+
+	usage := taskResourceUsage(&v2.Metrics{
+		Memory: &cgroupsv2.MemoryStat{Anon: 10, File: 20, Usage: 30},
+		CPU:    &cgroupsv2.CPUStat{UsageUsec: 100, NrThrottled: 2, ThrottledUsec: 3},
+	}, total, user, system)
+	must.NotNil(t, usage)
+
+	ms := usage.ResourceUsage.MemoryStats
+	must.Eq(t, 10, ms.RSS)
+	must.Eq(t, 20, ms.Cache)
+	must.Eq(t, 30, ms.Usage)
+	must.Eq(t, cgroupV2MeasuredMemStats, ms.Measured)
+
+	cs := usage.ResourceUsage.CpuStats
+	must.Eq(t, 2, cs.ThrottledPeriods)
+	must.Eq(t, 3000, cs.ThrottledTime)
+
+	must.Nil(t, taskResourceUsage("unknown", total, user, system))
+}
+
+// containerdCompatible skips the test unless it runs as root with a
+// containerd daemon listening on the default socket.
+func containerdCompatible(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Test requires root")
+	}
+	if _, err := os.Stat("/run/containerd/containerd.sock"); err != nil {
+		t.Skip("Test requires containerd")
+	}
+}
+
+func TestContainerdDriver_StartWaitExec(t *testing.T) {
+	ci.Parallel(t)
+	containerdCompatible(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := NewContainerdDriver(ctx, testlog.HCLogger(t)).(*Driver)
+	d.config = testConfig(t)
+	harness := dtestutil.NewDriverHarness(t, d)
+
+	task := &drivers.TaskConfig{
+		AllocID: uuid.Generate(),
+		ID:      uuid.Generate(),
+		Name:    "test",
+		Env: map[string]string{
+			taskenv.AllocDir:     "/alloc",
+			taskenv.TaskLocalDir: "/local",
+			taskenv.SecretsDir:   "/secrets",
+		},
+	}
+	tc := &TaskConfig{
+		Image:   "docker.io/library/busybox:1.36",
+		Command: "sleep",
+		Args:    []string{"30"},
+	}
+	must.NoError(t, task.EncodeConcreteDriverConfig(&tc))
+
+	cleanup := harness.MkAllocDir(task, true)
+	defer cleanup()
+
+	handle, _, err := harness.StartTask(task)
+	must.NoError(t, err)
+	defer harness.DestroyTask(task.ID, true)
+
+	res, err := harness.ExecTask(handle.Config.ID, []string{"echo", "hello"}, 10*time.Second)
+	must.NoError(t, err)
+	must.Zero(t, res.ExitResult.ExitCode)
+	must.Eq(t, "hello\n", string(res.Stdout))
+
+	ch, err := harness.WaitTask(context.Background(), handle.Config.ID)
+	must.NoError(t, err)
+	must.NoError(t, harness.StopTask(task.ID, 5*time.Second, "SIGTERM"))
+
+	select {
+	case result := <-ch:
+		must.Positive(t, result.ExitCode)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the task to stop")
+	}
+}
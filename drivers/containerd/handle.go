@@ -0,0 +1,375 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build linux
+
+package containerd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/errdefs"
+	v1 "github.com/containerd/containerd/metrics/types/v1"
+	v2 "github.com/containerd/containerd/metrics/types/v2"
+	"github.com/containerd/typeurl"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/lib/cpustats"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var (
+	// cgroupV1MeasuredMemStats are the memory stats measured with cgroups v1
+	cgroupV1MeasuredMemStats = []string{"RSS", "Cache", "Swap", "Usage", "Max Usage"}
+
+	// cgroupV2MeasuredMemStats are the memory stats measured with cgroups v2,
+	// which doesn't track the max usage
+	cgroupV2MeasuredMemStats = []string{"RSS", "Cache", "Swap", "Usage"}
+
+	// measuredCPUStats are the CPU stats measured with both cgroups versions
+	measuredCPUStats = []string{"System Mode", "User Mode", "Percent", "Throttled Periods", "Throttled Time"}
+)
+
+type taskHandle struct {
+	container containerd.Container
+	task      containerd.Task
+	exitCh    <-chan containerd.ExitStatus
+	logger    hclog.Logger
+	compute   cpustats.Compute
+
+	// stdout and stderr are the fifos the output of the task is copied to
+	stdout io.WriteCloser
+	stderr io.WriteCloser
+
+	// doneCh is closed once the task exited
+	doneCh chan struct{}
+
+	// stateLock syncs access to all fields below
+	stateLock sync.RWMutex
+
+	taskConfig  *drivers.TaskConfig
+	procState   drivers.TaskState
+	startedAt   time.Time
+	completedAt time.Time
+	exitResult  *drivers.ExitResult
+}
+
+func (h *taskHandle) TaskStatus() *drivers.TaskStatus {
+	h.stateLock.RLock()
+	defer h.stateLock.RUnlock()
+
+	return &drivers.TaskStatus{
+		ID:          h.taskConfig.ID,
+		Name:        h.taskConfig.Name,
+		State:       h.procState,
+		StartedAt:   h.startedAt,
+		CompletedAt: h.completedAt,
+		ExitResult:  h.exitResult,
+		DriverAttributes: map[string]string{
+			"container_id": h.container.ID(),
+		},
+	}
+}
+
+func (h *taskHandle) IsRunning() bool {
+	h.stateLock.RLock()
+	defer h.stateLock.RUnlock()
+	return h.procState == drivers.TaskStateRunning
+}
+
+// result returns a copy of the exit result of the task.
+func (h *taskHandle) result() *drivers.ExitResult {
+	h.stateLock.RLock()
+	defer h.stateLock.RUnlock()
+	return h.exitResult.Copy()
+}
+
+func (h *taskHandle) run() {
+	// Block until the task exits
+	status := <-h.exitCh
+
+	h.stateLock.Lock()
+	code, exitedAt, err := status.Result()
+	if err != nil {
+		h.exitResult.Err = err
+		h.procState = drivers.TaskStateUnknown
+		h.completedAt = time.Now()
+	} else {
+		h.procState = drivers.TaskStateExited
+		h.exitResult.ExitCode = int(code)
+		h.completedAt = exitedAt
+	}
+	h.stateLock.Unlock()
+
+	close(h.doneCh)
+}
+
+// shutdown sends the signal to the task, then kills it if it's still running
+// after the timeout.
+func (h *taskHandle) shutdown(sig syscall.Signal, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	if err := h.task.Kill(ctx, sig); err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to signal task: %v", err)
+	}
+
+	select {
+	case <-h.doneCh:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	if err := h.task.Kill(ctx, syscall.SIGKILL, containerd.WithKillAll); err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to kill task: %v", err)
+	}
+	return nil
+}
+
+// destroy kills the task if it's running and removes its container, then
+// closes the fifos of its output.
+func (h *taskHandle) destroy() error {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	if _, err := h.task.Delete(ctx, containerd.WithProcessKill); err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete task: %v", err)
+	}
+	if err := h.container.Delete(ctx, containerd.WithSnapshotCleanup); err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete container: %v", err)
+	}
+
+	h.stdout.Close()
+	h.stderr.Close()
+	return nil
+}
+
+// collectStats sends the resource usage of the task on every interval until
+// the context is canceled or the task exits.
+func (h *taskHandle) collectStats(ctx context.Context, ch chan<- *drivers.TaskResourceUsage, interval time.Duration) {
+	defer close(ch)
+
+	total := cpustats.New(h.compute)
+	user := cpustats.New(h.compute)
+	system := cpustats.New(h.compute)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.doneCh:
+			return
+		case <-timer.C:
+			timer.Reset(interval)
+		}
+
+		metric, err := h.task.Metrics(ctx)
+		if err != nil {
+			h.logger.Debug("failed to get task metrics", "error", err)
+			continue
+		}
+		data, err := typeurl.UnmarshalAny(metric.Data)
+		if err != nil {
+			h.logger.Debug("failed to decode task metrics", "error", err)
+			continue
+		}
+
+		usage := taskResourceUsage(data, total, user, system)
+		if usage == nil {
+			h.logger.Debug("unsupported task metrics", "type", fmt.Sprintf("%T", data))
+			continue
+		}
+		usage.Timestamp = metric.Timestamp.UnixNano()
+
+		select {
+		case <-ctx.Done():
+			return
+		case ch <- usage:
+		}
+	}
+}
+
+// taskResourceUsage converts the cgroups v1 or v2 metrics of a task to its
+// resource usage, or returns nil for unknown metrics.
+func taskResourceUsage(data any, total, user, system *cpustats.Tracker) *drivers.TaskResourceUsage {
+	ms := &cstructs.MemoryStats{}
+	cs := &cstructs.CpuStats{Measured: measuredCPUStats}
+
+	// CPU usages are in nanoseconds with cgroups v1 and in microseconds with
+	// cgroups v2.
+	switch m := data.(type) {
+	case *v1.Metrics:
+		if m.Memory != nil {
+			ms.RSS = m.Memory.RSS
+			ms.Cache = m.Memory.Cache
+			ms.MappedFile = m.Memory.MappedFile
+			if m.Memory.Swap != nil {
+				ms.Swap = m.Memory.Swap.Usage
+			}
+			if m.Memory.Usage != nil {
+				ms.Usage = m.Memory.Usage.Usage
+				ms.MaxUsage = m.Memory.Usage.Max
+			}
+			ms.Measured = cgroupV1MeasuredMemStats
+		}
+		if m.CPU != nil && m.CPU.Usage != nil {
+			cs.Percent = total.Percent(float64(m.CPU.Usage.Total))
+			cs.UserMode = user.Percent(float64(m.CPU.Usage.User))
+			cs.SystemMode = system.Percent(float64(m.CPU.Usage.Kernel))
+		}
+		if m.CPU != nil && m.CPU.Throttling != nil {
+			cs.ThrottledPeriods = m.CPU.Throttling.ThrottledPeriods
+			cs.ThrottledTime = m.CPU.Throttling.ThrottledTime
+		}
+	case *v2.Metrics:
+		if m.Memory != nil {
+			ms.RSS = m.Memory.Anon
+			ms.Cache = m.Memory.File
+			ms.MappedFile = m.Memory.FileMapped
+			ms.Swap = m.Memory.SwapUsage
+			ms.Usage = m.Memory.Usage
+			ms.Measured = cgroupV2MeasuredMemStats
+		}
+		if m.CPU != nil {
+			cs.Percent = total.Percent(float64(m.CPU.UsageUsec * 1000))
+			cs.UserMode = user.Percent(float64(m.CPU.UserUsec * 1000))
+			cs.SystemMode = system.Percent(float64(m.CPU.SystemUsec * 1000))
+			cs.ThrottledPeriods = m.CPU.NrThrottled
+			cs.ThrottledTime = m.CPU.ThrottledUsec * 1000
+		}
+	default:
+		return nil
+	}
+	cs.TotalTicks = total.TicksConsumed(cs.Percent)
+
+	return &drivers.TaskResourceUsage{
+		ResourceUsage: &cstructs.ResourceUsage{
+			MemoryStats: ms,
+			CpuStats:    cs,
+		},
+	}
+}
+
+// processSpec returns the spec of a process executed in the task, based on
+// the spec of its main process.
+func (h *taskHandle) processSpec(ctx context.Context, cmd []string, tty bool) (*specs.Process, error) {
+	spec, err := h.container.Spec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container spec: %v", err)
+	}
+	pspec := *spec.Process
+	pspec.Args = cmd
+	pspec.Terminal = tty
+	return &pspec, nil
+}
+
+// exec runs the command in the task and returns its output once it exits.
+func (h *taskHandle) exec(ctx context.Context, cmd []string) (*drivers.ExecTaskResult, error) {
+	pspec, err := h.processSpec(ctx, cmd, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	process, err := h.task.Exec(ctx, "exec-"+uuid.Short(), pspec,
+		cio.NewCreator(cio.WithStreams(nil, &stdout, &stderr)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec process: %v", err)
+	}
+	defer process.Delete(context.Background(), containerd.WithProcessKill)
+
+	exitCh, err := process.Wait(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait on exec process: %v", err)
+	}
+	if err := process.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start exec process: %v", err)
+	}
+
+	var status containerd.ExitStatus
+	select {
+	case status = <-exitCh:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("exec process timed out: %v", ctx.Err())
+	}
+	code, _, err := status.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for the output to be copied once the process exited.
+	process.IO().Wait()
+
+	return &drivers.ExecTaskResult{
+		Stdout: stdout.Bytes(),
+		Stderr: stderr.Bytes(),
+		ExitResult: &drivers.ExitResult{
+			ExitCode: int(code),
+		},
+	}, nil
+}
+
+// execStreaming runs the command in the task, streaming its input and output
+// and resizing its terminal until it exits.
+func (h *taskHandle) execStreaming(ctx context.Context, opts *drivers.ExecOptions) (*drivers.ExitResult, error) {
+	pspec, err := h.processSpec(ctx, opts.Command, opts.Tty)
+	if err != nil {
+		return nil, err
+	}
+
+	ioOpts := []cio.Opt{cio.WithStreams(opts.Stdin, opts.Stdout, opts.Stderr)}
+	if opts.Tty {
+		ioOpts = append(ioOpts, cio.WithTerminal)
+	}
+
+	process, err := h.task.Exec(ctx, "exec-"+uuid.Short(), pspec, cio.NewCreator(ioOpts...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec process: %v", err)
+	}
+	defer process.Delete(context.Background(), containerd.WithProcessKill)
+
+	exitCh, err := process.Wait(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait on exec process: %v", err)
+	}
+	if err := process.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start exec process: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case s, ok := <-opts.ResizeCh:
+			if !ok {
+				opts.ResizeCh = nil
+				continue
+			}
+			if err := process.Resize(ctx, uint32(s.Width), uint32(s.Height)); err != nil {
+				h.logger.Debug("failed to resize exec terminal", "error", err)
+			}
+		case status := <-exitCh:
+			code, _, err := status.Result()
+			if err != nil {
+				return nil, err
+			}
+			process.IO().Wait()
+			return &drivers.ExitResult{ExitCode: int(code)}, nil
+		}
+	}
+}
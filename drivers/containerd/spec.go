@@ -0,0 +1,319 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build linux
+
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/oci"
+	refdocker "github.com/containerd/containerd/reference/docker"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/hashicorp/nomad/client/taskenv"
+	"github.com/hashicorp/nomad/drivers/shared/capabilities"
+	"github.com/hashicorp/nomad/drivers/shared/hostnames"
+	"github.com/hashicorp/nomad/drivers/shared/resolvconf"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// normalizeImageRef returns the fully qualified reference of the image, as
+// containerd doesn't expand short names like "redis:7" the way dockerd does.
+func normalizeImageRef(image string) (string, error) {
+	named, err := refdocker.ParseDockerRef(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %v", image, err)
+	}
+	return named.String(), nil
+}
+
+// newResolver returns the resolver used to pull images from registries with
+// the credentials of the task, if any.
+func newResolver(auth *AuthConfig) remotes.Resolver {
+	var opts []docker.RegistryOpt
+	if auth.Username != "" || auth.Password != "" {
+		opts = append(opts, docker.WithAuthorizer(docker.NewDockerAuthorizer(
+			docker.WithAuthCreds(func(string) (string, string, error) {
+				return auth.Username, auth.Password, nil
+			}),
+		)))
+	}
+	return docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(opts...),
+	})
+}
+
+// specOpts returns the options used to build the OCI runtime spec of the
+// task's container on top of the configuration of its image.
+func (d *Driver) specOpts(cfg *drivers.TaskConfig, driverConfig *TaskConfig, image containerd.Image) ([]oci.SpecOpts, error) {
+	opts := []oci.SpecOpts{oci.WithImageConfig(image)}
+
+	// The entrypoint and command override the ones of the image the same way
+	// they do with the docker driver.
+	switch {
+	case len(driverConfig.Entrypoint) > 0:
+		args := slices.Clone(driverConfig.Entrypoint)
+		if driverConfig.Command != "" {
+			args = append(args, driverConfig.Command)
+		}
+		opts = append(opts, oci.WithProcessArgs(append(args, driverConfig.Args...)...))
+	case driverConfig.Command != "":
+		args := append([]string{driverConfig.Command}, driverConfig.Args...)
+		opts = append(opts, oci.WithImageConfigArgs(image, args))
+	case len(driverConfig.Args) > 0:
+		opts = append(opts, oci.WithImageConfigArgs(image, driverConfig.Args))
+	}
+
+	opts = append(opts, oci.WithEnv(cfg.EnvList()))
+	if driverConfig.Cwd != "" {
+		opts = append(opts, oci.WithProcessCwd(driverConfig.Cwd))
+	}
+	if cfg.User != "" {
+		opts = append(opts, oci.WithUser(cfg.User))
+	}
+
+	if driverConfig.Privileged {
+		opts = append(opts, oci.WithPrivileged, oci.WithAllDevicesAllowed, oci.WithHostDevices)
+	} else {
+		caps, err := capabilities.Calculate(
+			capabilities.NomadDefaults(), d.config.AllowCaps, driverConfig.CapAdd, driverConfig.CapDrop,
+		)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, oci.WithCapabilities(caps))
+	}
+	if driverConfig.ReadonlyRootfs {
+		opts = append(opts, oci.WithRootFSReadonly())
+	}
+
+	opts = append(opts, resourceOpts(cfg, driverConfig)...)
+
+	opts = append(opts, networkOpts(cfg, driverConfig)...)
+
+	mounts, err := d.mounts(cfg, driverConfig)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, oci.WithMounts(mounts))
+
+	for _, dev := range cfg.Devices {
+		opts = append(opts, oci.WithDevices(dev.HostPath, dev.TaskPath, devicePermissions(dev.Permissions)))
+	}
+	for _, dev := range driverConfig.Devices {
+		opts = append(opts, oci.WithDevices(dev.HostPath, dev.ContainerPath, devicePermissions(dev.Permissions)))
+	}
+
+	return opts, nil
+}
+
+// resourceOpts returns the options setting the resource limits of the
+// container from the resources of the task.
+func resourceOpts(cfg *drivers.TaskConfig, driverConfig *TaskConfig) []oci.SpecOpts {
+	var opts []oci.SpecOpts
+	if driverConfig.PidsLimit > 0 {
+		opts = append(opts, oci.WithPidsLimit(driverConfig.PidsLimit))
+	}
+	if cfg.Resources == nil || cfg.Resources.LinuxResources == nil {
+		return opts
+	}
+
+	res := cfg.Resources.LinuxResources
+	if res.CPUShares > 0 {
+		opts = append(opts, oci.WithCPUShares(uint64(res.CPUShares)))
+	}
+	if res.CPUQuota > 0 {
+		opts = append(opts, oci.WithCPUCFS(res.CPUQuota, uint64(res.CPUPeriod)))
+	}
+	if res.CpusetCpus != "" {
+		opts = append(opts, oci.WithCPUs(res.CpusetCpus))
+	}
+
+	// With memory oversubscription the task may use up to its max memory,
+	// and its memory is only reserved.
+	memory := res.MemoryLimitBytes
+	var reservation int64
+	if nr := cfg.Resources.NomadResources; nr != nil && nr.Memory.MemoryMaxMB > 0 {
+		memory = nr.Memory.MemoryMaxMB * 1024 * 1024
+		reservation = nr.Memory.MemoryMB * 1024 * 1024
+	}
+	if memory > 0 {
+		opts = append(opts, oci.WithMemoryLimit(uint64(memory)))
+	}
+	if reservation > 0 {
+		opts = append(opts, withMemoryReservation(reservation))
+	}
+	return opts
+}
+
+// withMemoryReservation sets the soft memory limit of the container.
+func withMemoryReservation(reservation int64) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		if s.Linux.Resources == nil {
+			s.Linux.Resources = &specs.LinuxResources{}
+		}
+		if s.Linux.Resources.Memory == nil {
+			s.Linux.Resources.Memory = &specs.LinuxMemory{}
+		}
+		s.Linux.Resources.Memory.Reservation = &reservation
+		return nil
+	}
+}
+
+// networkOpts returns the options joining the container to the network
+// namespace of the allocation, or to the host network.
+func networkOpts(cfg *drivers.TaskConfig, driverConfig *TaskConfig) []oci.SpecOpts {
+	var opts []oci.SpecOpts
+	if cfg.DNS == nil {
+		opts = append(opts, oci.WithHostResolvconf)
+	}
+
+	iso := cfg.NetworkIsolation
+	if iso == nil || iso.Mode == drivers.NetIsolationModeHost || iso.Path == "" {
+		opts = append(opts, oci.WithHostNamespace(specs.NetworkNamespace), oci.WithHostHostsFile)
+		if driverConfig.Hostname != "" {
+			opts = append(opts, oci.WithHostname(driverConfig.Hostname))
+		}
+		return opts
+	}
+
+	opts = append(opts, oci.WithLinuxNamespace(specs.LinuxNamespace{
+		Type: specs.NetworkNamespace,
+		Path: iso.Path,
+	}))
+
+	hostname := driverConfig.Hostname
+	if hostname == "" && iso.HostsConfig != nil {
+		hostname = iso.HostsConfig.Hostname
+	}
+	if hostname != "" {
+		opts = append(opts, oci.WithHostname(hostname))
+	}
+	return opts
+}
+
+// mounts returns the mounts of the container: the task directories, the
+// generated /etc/hosts and /etc/resolv.conf, the volumes of the task and the
+// mounts of its driver config.
+func (d *Driver) mounts(cfg *drivers.TaskConfig, driverConfig *TaskConfig) ([]specs.Mount, error) {
+	taskDir := cfg.TaskDir()
+	mounts := []specs.Mount{
+		bindMount(taskDir.SharedAllocDir, cfg.Env[taskenv.AllocDir], false, ""),
+		bindMount(taskDir.LocalDir, cfg.Env[taskenv.TaskLocalDir], false, ""),
+		bindMount(taskDir.SecretsDir, cfg.Env[taskenv.SecretsDir], false, ""),
+	}
+
+	if cfg.NetworkIsolation != nil {
+		hostsMount, err := hostnames.GenerateEtcHostsMount(cfg.AllocDir, cfg.NetworkIsolation, driverConfig.ExtraHosts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build mount for /etc/hosts: %v", err)
+		}
+		if hostsMount != nil {
+			mounts = append(mounts, bindMount(hostsMount.HostPath, hostsMount.TaskPath, hostsMount.Readonly, ""))
+		}
+	}
+
+	if cfg.DNS != nil {
+		dnsMount, err := resolvconf.GenerateDNSMount(taskDir.Dir, cfg.DNS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build mount for resolv.conf: %v", err)
+		}
+		mounts = append(mounts, bindMount(dnsMount.HostPath, dnsMount.TaskPath, dnsMount.Readonly, ""))
+	}
+
+	for _, m := range cfg.Mounts {
+		mounts = append(mounts, bindMount(m.HostPath, m.TaskPath, m.Readonly, m.PropagationMode))
+	}
+
+	for _, m := range driverConfig.Mounts {
+		switch m.Type {
+		case "tmpfs":
+			options := m.Options
+			if len(options) == 0 {
+				options = []string{"nosuid", "noexec", "nodev"}
+			}
+			mounts = append(mounts, specs.Mount{
+				Type:        "tmpfs",
+				Source:      "tmpfs",
+				Destination: m.Target,
+				Options:     options,
+			})
+		default:
+			// Relative sources are within the task directory, other host
+			// paths must be allowed by the client.
+			source := m.Source
+			if !filepath.IsAbs(source) {
+				source = filepath.Join(taskDir.Dir, source)
+			}
+			source = filepath.Clean(source)
+			if !d.config.AllowHostMounts && !isParentPath(cfg.AllocDir, source) {
+				return nil, fmt.Errorf("host mounts are not allowed on this client; cannot mount %q", m.Source)
+			}
+
+			options := m.Options
+			if !slices.Contains(options, "bind") && !slices.Contains(options, "rbind") {
+				options = append([]string{"rbind"}, options...)
+			}
+			mounts = append(mounts, specs.Mount{
+				Type:        "bind",
+				Source:      source,
+				Destination: m.Target,
+				Options:     options,
+			})
+		}
+	}
+
+	return mounts, nil
+}
+
+// bindMount returns a bind mount of a host path with the given propagation
+// mode of Nomad volume mounts.
+func bindMount(source, target string, readonly bool, propagation string) specs.Mount {
+	options := []string{"rbind", "rw"}
+	if readonly {
+		options[1] = "ro"
+	}
+	switch propagation {
+	case structs.VolumeMountPropagationHostToTask:
+		options = append(options, "rslave")
+	case structs.VolumeMountPropagationBidirectional:
+		options = append(options, "rshared")
+	default:
+		options = append(options, "rprivate")
+	}
+	return specs.Mount{
+		Type:        "bind",
+		Source:      source,
+		Destination: target,
+		Options:     options,
+	}
+}
+
+// isParentPath returns true if path is a child or a descendant of parent path.
+// Both inputs need to be absolute paths.
+func isParentPath(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// devicePermissions returns the cgroup permissions of a device, which default
+// to read, write and mknod.
+func devicePermissions(permissions string) string {
+	if permissions == "" {
+		return "rwm"
+	}
+	return strings.ToLower(permissions)
+}
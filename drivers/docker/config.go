@@ -14,6 +14,7 @@ import (
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/drivers/shared/capabilities"
+	"github.com/hashicorp/nomad/drivers/shared/exechook"
 	"github.com/hashicorp/nomad/helper/pluginutils/hclutils"
 	"github.com/hashicorp/nomad/helper/pluginutils/loader"
 	"github.com/hashicorp/nomad/plugins/base"
@@ -390,6 +391,8 @@ var (
 		"pid_mode":        hclspec.NewAttr("pid_mode", "string", false),
 		"ports":           hclspec.NewAttr("ports", "list(string)", false),
 		"port_map":        hclspec.NewAttr("port_map", "list(map(number))", false),
+		"post_start":      hclspec.NewBlock("post_start", false, exechook.Spec),
+		"pre_stop":        hclspec.NewBlock("pre_stop", false, exechook.Spec),
 		"privileged":      hclspec.NewAttr("privileged", "bool", false),
 		"image_pull_timeout": hclspec.NewDefault(
 			hclspec.NewAttr("image_pull_timeout", "string", false),
@@ -466,6 +469,8 @@ type TaskConfig struct {
 	PidMode           string             `codec:"pid_mode"`
 	Ports             []string           `codec:"ports"`
 	PortMap           hclutils.MapStrInt `codec:"port_map"`
+	PostStart         *exechook.Hook     `codec:"post_start"`
+	PreStop           *exechook.Hook     `codec:"pre_stop"`
 	Privileged        bool               `codec:"privileged"`
 	ImagePullTimeout  string             `codec:"image_pull_timeout"`
 	ReadonlyRootfs    bool               `codec:"readonly_rootfs"`
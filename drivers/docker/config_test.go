@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/drivers/shared/exechook"
 	"github.com/hashicorp/nomad/helper/pluginutils/hclutils"
 	"github.com/hashicorp/nomad/plugins/drivers"
 	"github.com/shoenig/test/must"
@@ -320,6 +321,14 @@ config {
     http = 80
     redis = 6379
   }
+  post_start {
+    command = "/bin/warmup"
+    args    = ["-fast"]
+    timeout = "10s"
+  }
+  pre_stop {
+    command = "/bin/drain"
+  }
   privileged = true
   readonly_rootfs = true
   runtime = "runc"
@@ -480,6 +489,14 @@ config {
 			"http":  80,
 			"redis": 6379,
 		},
+		PostStart: &exechook.Hook{
+			Command: "/bin/warmup",
+			Args:    []string{"-fast"},
+			Timeout: "10s",
+		},
+		PreStop: &exechook.Hook{
+			Command: "/bin/drain",
+		},
 		Privileged:     true,
 		ReadonlyRootfs: true,
 		Runtime:        "runc",
@@ -254,6 +254,7 @@ func (d *Driver) RecoverTask(handle *drivers.TaskHandle) error {
 		waitCh:                make(chan struct{}),
 		removeContainerOnExit: d.config.GC.Container,
 		net:                   handleState.DriverNetwork,
+		preStop:               handleState.PreStop,
 	}
 
 	if loggingIsEnabled(d.config, handle.Config) {
@@ -314,6 +315,13 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 
 	driverConfig.Image = strings.TrimPrefix(driverConfig.Image, "https://")
 
+	if err := driverConfig.PostStart.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid post_start: %v", err)
+	}
+	if err := driverConfig.PreStop.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid pre_stop: %v", err)
+	}
+
 	handle := drivers.NewTaskHandle(taskHandleVersion)
 	handle.Config = cfg
 
@@ -444,6 +452,7 @@ CREATE:
 		waitCh:                make(chan struct{}),
 		removeContainerOnExit: d.config.GC.Container,
 		net:                   net,
+		preStop:               driverConfig.PreStop,
 	}
 
 	if err := handle.SetDriverState(h.buildState()); err != nil {
@@ -459,6 +468,14 @@ CREATE:
 	d.tasks.Set(cfg.ID, h)
 	go h.run()
 
+	if err := driverConfig.PostStart.Run(h.execHook); err != nil {
+		d.logger.Error("post_start hook failed, terminating container", "container_id", container.ID, "error", err)
+		if err := d.DestroyTask(cfg.ID, true); err != nil {
+			d.logger.Warn("failed to destroy container after post_start hook failure", "container_id", container.ID, "error", err)
+		}
+		return nil, nil, fmt.Errorf("post_start hook failed: %v", err)
+	}
+
 	return handle, net, nil
 }
 
@@ -1556,6 +1573,7 @@ func (d *Driver) StopTask(taskID string, timeout time.Duration, signal string) e
 		return drivers.ErrTaskNotFound
 	}
 
+	h.runPreStop()
 	return h.Kill(timeout, signal)
 }
 
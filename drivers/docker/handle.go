@@ -20,6 +20,7 @@ import (
 	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/nomad/client/lib/cgroupslib"
 	"github.com/hashicorp/nomad/drivers/docker/docklog"
+	"github.com/hashicorp/nomad/drivers/shared/exechook"
 	"github.com/hashicorp/nomad/plugins/drivers"
 	pstructs "github.com/hashicorp/nomad/plugins/shared/structs"
 )
@@ -48,6 +49,10 @@ type taskHandle struct {
 	removeContainerOnExit bool
 	net                   *drivers.DriverNetwork
 
+	// preStop is run once before the container is stopped
+	preStop     *exechook.Hook
+	preStopOnce sync.Once
+
 	exitResult     *drivers.ExitResult
 	exitResultLock sync.Mutex
 }
@@ -64,12 +69,14 @@ type taskHandleState struct {
 
 	ContainerID   string
 	DriverNetwork *drivers.DriverNetwork
+	PreStop       *exechook.Hook
 }
 
 func (h *taskHandle) buildState() *taskHandleState {
 	s := &taskHandleState{
 		ContainerID:   h.containerID,
 		DriverNetwork: h.net,
+		PreStop:       h.preStop,
 	}
 	if h.dloggerPluginClient != nil {
 		s.ReattachConfig = pstructs.ReattachConfigFromGoPlugin(h.dloggerPluginClient.ReattachConfig())
@@ -77,6 +84,28 @@ func (h *taskHandle) buildState() *taskHandleState {
 	return s
 }
 
+// execHook runs the command of a post_start or pre_stop hook in the container.
+func (h *taskHandle) execHook(cmd []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return h.Exec(ctx, cmd[0], cmd[1:])
+}
+
+// runPreStop runs the pre_stop hook of the task the first time the task is
+// stopped, unless the container already exited. The container is stopped even
+// if the hook fails.
+func (h *taskHandle) runPreStop() {
+	h.preStopOnce.Do(func() {
+		if h.preStop == nil || h.ExitResult() != nil {
+			return
+		}
+		if err := h.preStop.Run(h.execHook); err != nil {
+			h.logger.Warn("pre_stop hook failed", "error", err)
+		}
+	})
+}
+
 func (h *taskHandle) Exec(ctx context.Context, cmd string, args []string) (*drivers.ExecTaskResult, error) {
 	fullCmd := make([]string, len(args)+1)
 	fullCmd[0] = cmd
@@ -18,6 +18,7 @@ import (
 	"github.com/hashicorp/nomad/client/lib/cpustats"
 	"github.com/hashicorp/nomad/drivers/shared/capabilities"
 	"github.com/hashicorp/nomad/drivers/shared/eventer"
+	"github.com/hashicorp/nomad/drivers/shared/exechook"
 	"github.com/hashicorp/nomad/drivers/shared/executor"
 	"github.com/hashicorp/nomad/drivers/shared/resolvconf"
 	"github.com/hashicorp/nomad/helper/pluginutils/loader"
@@ -87,12 +88,14 @@ var (
 	// taskConfigSpec is the hcl specification for the driver config section of
 	// a task within a job. It is returned in the TaskConfigSchema RPC
 	taskConfigSpec = hclspec.NewObject(map[string]*hclspec.Spec{
-		"command":  hclspec.NewAttr("command", "string", true),
-		"args":     hclspec.NewAttr("args", "list(string)", false),
-		"pid_mode": hclspec.NewAttr("pid_mode", "string", false),
-		"ipc_mode": hclspec.NewAttr("ipc_mode", "string", false),
-		"cap_add":  hclspec.NewAttr("cap_add", "list(string)", false),
-		"cap_drop": hclspec.NewAttr("cap_drop", "list(string)", false),
+		"command":    hclspec.NewAttr("command", "string", true),
+		"args":       hclspec.NewAttr("args", "list(string)", false),
+		"pid_mode":   hclspec.NewAttr("pid_mode", "string", false),
+		"ipc_mode":   hclspec.NewAttr("ipc_mode", "string", false),
+		"cap_add":    hclspec.NewAttr("cap_add", "list(string)", false),
+		"cap_drop":   hclspec.NewAttr("cap_drop", "list(string)", false),
+		"post_start": hclspec.NewBlock("post_start", false, exechook.Spec),
+		"pre_stop":   hclspec.NewBlock("pre_stop", false, exechook.Spec),
 	})
 
 	// driverCapabilities represents the RPC response for what features are
@@ -202,6 +205,13 @@ type TaskConfig struct {
 
 	// CapDrop is a set of linux capabilities to disable.
 	CapDrop []string `codec:"cap_drop"`
+
+	// PostStart is run inside the task after it starts. The task fails to
+	// start if it fails.
+	PostStart *exechook.Hook `codec:"post_start"`
+
+	// PreStop is run inside the task before it's stopped.
+	PreStop *exechook.Hook `codec:"pre_stop"`
 }
 
 func (tc *TaskConfig) validate() error {
@@ -227,6 +237,13 @@ func (tc *TaskConfig) validate() error {
 		return fmt.Errorf("cap_drop configured with capabilities not supported by system: %s", badDrops)
 	}
 
+	if err := tc.PostStart.Validate(); err != nil {
+		return fmt.Errorf("invalid post_start: %v", err)
+	}
+	if err := tc.PreStop.Validate(); err != nil {
+		return fmt.Errorf("invalid pre_stop: %v", err)
+	}
+
 	return nil
 }
 
@@ -238,6 +255,7 @@ type TaskState struct {
 	TaskConfig     *drivers.TaskConfig
 	Pid            int
 	StartedAt      time.Time
+	PreStop        *exechook.Hook
 }
 
 // NewExecDriver returns a new DrivePlugin implementation
@@ -414,6 +432,7 @@ func (d *Driver) RecoverTask(handle *drivers.TaskHandle) error {
 		startedAt:    taskState.StartedAt,
 		exitResult:   &drivers.ExitResult{},
 		logger:       d.logger,
+		preStop:      taskState.PreStop,
 	}
 
 	d.tasks.Set(taskState.TaskConfig.ID, h)
@@ -509,6 +528,7 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 		procState:    drivers.TaskStateRunning,
 		startedAt:    time.Now().Round(time.Millisecond),
 		logger:       d.logger,
+		preStop:      driverConfig.PreStop,
 	}
 
 	driverState := TaskState{
@@ -516,6 +536,7 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 		Pid:            ps.Pid,
 		TaskConfig:     cfg,
 		StartedAt:      h.startedAt,
+		PreStop:        driverConfig.PreStop,
 	}
 
 	if err := handle.SetDriverState(&driverState); err != nil {
@@ -527,6 +548,15 @@ func (d *Driver) StartTask(cfg *drivers.TaskConfig) (*drivers.TaskHandle, *drive
 
 	d.tasks.Set(cfg.ID, h)
 	go h.run()
+
+	if err := driverConfig.PostStart.Run(h.execHook); err != nil {
+		d.logger.Error("post_start hook failed, terminating task", "task_id", cfg.ID, "error", err)
+		if err := d.DestroyTask(cfg.ID, true); err != nil {
+			d.logger.Warn("failed to destroy task after post_start hook failure", "task_id", cfg.ID, "error", err)
+		}
+		return nil, nil, fmt.Errorf("post_start hook failed: %v", err)
+	}
+
 	return handle, nil, nil
 }
 
@@ -572,6 +602,7 @@ func (d *Driver) StopTask(taskID string, timeout time.Duration, signal string) e
 		return drivers.ErrTaskNotFound
 	}
 
+	handle.runPreStop()
 	if err := handle.exec.Shutdown(signal, timeout); err != nil {
 		if handle.pluginClient.Exited() {
 			return nil
@@ -22,6 +22,7 @@ import (
 	"github.com/hashicorp/nomad/client/lib/cgroupslib"
 	"github.com/hashicorp/nomad/client/lib/numalib"
 	ctestutils "github.com/hashicorp/nomad/client/testutil"
+	"github.com/hashicorp/nomad/drivers/shared/exechook"
 	"github.com/hashicorp/nomad/drivers/shared/executor"
 	"github.com/hashicorp/nomad/helper/pluginutils/hclutils"
 	"github.com/hashicorp/nomad/helper/testlog"
@@ -625,6 +626,94 @@ func TestExecDriver_HandlerExec(t *testing.T) {
 	require.NoError(t, harness.DestroyTask(task.ID, true))
 }
 
+func TestExecDriver_PostStartPreStop(t *testing.T) {
+	ci.Parallel(t)
+	ctestutils.ExecCompatible(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newExecDriverTest(t, ctx)
+	harness := dtestutil.NewDriverHarness(t, d)
+	allocID := uuid.Generate()
+	task := &drivers.TaskConfig{
+		AllocID:   allocID,
+		ID:        uuid.Generate(),
+		Name:      "sleep",
+		Resources: testResources(allocID, "sleep"),
+	}
+	cleanup := harness.MkAllocDir(task, false)
+	defer cleanup()
+
+	tc := &TaskConfig{
+		Command: "/bin/sleep",
+		Args:    []string{"9000"},
+		PostStart: &exechook.Hook{
+			Command: "/bin/sh",
+			Args:    []string{"-c", "echo started > /local/post_start"},
+		},
+		PreStop: &exechook.Hook{
+			Command: "/bin/sh",
+			Args:    []string{"-c", "echo stopping > /local/pre_stop"},
+			Timeout: "5s",
+		},
+	}
+	require.NoError(t, task.EncodeConcreteDriverConfig(&tc))
+
+	_, _, err := harness.StartTask(task)
+	require.NoError(t, err)
+	defer harness.DestroyTask(task.ID, true)
+
+	// the post_start hook ran before the task was started
+	out, err := os.ReadFile(filepath.Join(task.TaskDir().LocalDir, "post_start"))
+	require.NoError(t, err)
+	require.Equal(t, "started\n", string(out))
+	require.NoFileExists(t, filepath.Join(task.TaskDir().LocalDir, "pre_stop"))
+
+	// the pre_stop hook runs before the task is stopped
+	require.NoError(t, harness.StopTask(task.ID, 2*time.Second, "SIGINT"))
+	out, err = os.ReadFile(filepath.Join(task.TaskDir().LocalDir, "pre_stop"))
+	require.NoError(t, err)
+	require.Equal(t, "stopping\n", string(out))
+}
+
+func TestExecDriver_PostStartFailure(t *testing.T) {
+	ci.Parallel(t)
+	ctestutils.ExecCompatible(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newExecDriverTest(t, ctx)
+	harness := dtestutil.NewDriverHarness(t, d)
+	allocID := uuid.Generate()
+	task := &drivers.TaskConfig{
+		AllocID:   allocID,
+		ID:        uuid.Generate(),
+		Name:      "sleep",
+		Resources: testResources(allocID, "sleep"),
+	}
+	cleanup := harness.MkAllocDir(task, false)
+	defer cleanup()
+
+	tc := &TaskConfig{
+		Command: "/bin/sleep",
+		Args:    []string{"9000"},
+		PostStart: &exechook.Hook{
+			Command: "/bin/sh",
+			Args:    []string{"-c", "echo not ready; exit 3"},
+		},
+	}
+	require.NoError(t, task.EncodeConcreteDriverConfig(&tc))
+
+	// the task is destroyed if its post_start hook fails
+	_, _, err := harness.StartTask(task)
+	require.ErrorContains(t, err, "exited with code 3: not ready")
+
+	_, err = d.InspectTask(task.ID)
+	require.Equal(t, drivers.ErrTaskNotFound, err)
+}
+
 func TestExecDriver_DevicesAndMounts(t *testing.T) {
 	ci.Parallel(t)
 	ctestutils.ExecCompatible(t)
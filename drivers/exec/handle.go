@@ -11,6 +11,7 @@ import (
 
 	hclog "github.com/hashicorp/go-hclog"
 	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad/drivers/shared/exechook"
 	"github.com/hashicorp/nomad/drivers/shared/executor"
 	"github.com/hashicorp/nomad/plugins/drivers"
 )
@@ -29,6 +30,10 @@ type taskHandle struct {
 	startedAt   time.Time
 	completedAt time.Time
 	exitResult  *drivers.ExitResult
+
+	// preStop is run once before the task is stopped
+	preStop     *exechook.Hook
+	preStopOnce sync.Once
 }
 
 func (h *taskHandle) TaskStatus() *drivers.TaskStatus {
@@ -48,6 +53,32 @@ func (h *taskHandle) TaskStatus() *drivers.TaskStatus {
 	}
 }
 
+// execHook runs the command of a post_start or pre_stop hook in the task.
+func (h *taskHandle) execHook(cmd []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
+	out, exitCode, err := h.exec.Exec(time.Now().Add(timeout), cmd[0], cmd[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &drivers.ExecTaskResult{
+		Stdout:     out,
+		ExitResult: &drivers.ExitResult{ExitCode: exitCode},
+	}, nil
+}
+
+// runPreStop runs the pre_stop hook of the task the first time the task is
+// stopped, unless it already exited. The task is stopped even if the hook
+// fails.
+func (h *taskHandle) runPreStop() {
+	h.preStopOnce.Do(func() {
+		if h.preStop == nil || !h.IsRunning() {
+			return
+		}
+		if err := h.preStop.Run(h.execHook); err != nil {
+			h.logger.Warn("pre_stop hook failed", "task_id", h.taskConfig.ID, "error", err)
+		}
+	})
+}
+
 func (h *taskHandle) IsRunning() bool {
 	h.stateLock.RLock()
 	defer h.stateLock.RUnlock()
@@ -103,7 +103,7 @@ var (
 	// optional features this driver supports
 	driverCapabilities = &drivers.Capabilities{
 		SendSignals: false,
-		Exec:        false,
+		Exec:        true,
 		FSIsolation: drivers.FSIsolationNone,
 		NetIsolationModes: []drivers.NetIsolationMode{
 			drivers.NetIsolationModeHost,
@@ -260,6 +260,18 @@ func TestJavaCmdArgs(t *testing.T) {
 	}
 }
 
+func TestJavaDriver_Capabilities(t *testing.T) {
+	ci.Parallel(t)
+
+	d := NewDriver(context.Background(), testlog.HCLogger(t))
+	caps, err := d.Capabilities()
+	require.NoError(t, err)
+
+	// The driver implements exec with its executor, so it must be
+	// advertised for alloc exec to be allowed.
+	require.True(t, caps.Exec)
+}
+
 func TestJavaDriver_ExecTaskStreaming(t *testing.T) {
 	ci.Parallel(t)
 	javaCompatible(t)
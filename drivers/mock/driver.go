@@ -70,6 +70,10 @@ var (
 			hclspec.NewLiteral("false"),
 		),
 		"shutdown_periodic_duration": hclspec.NewAttr("shutdown_periodic_duration", "number", false),
+		"disable_exec": hclspec.NewDefault(
+			hclspec.NewAttr("disable_exec", "bool", false),
+			hclspec.NewLiteral("false"),
+		),
 	})
 
 	// taskConfigSpec is the hcl specification for the driver config section of
@@ -181,6 +185,10 @@ type Config struct {
 	// to "stop" a previously functioning driver after the specified duration
 	// for testing of periodic drivers and fingerprinters.
 	ShutdownPeriodicDuration time.Duration `codec:"shutdown_periodic_duration"`
+
+	// DisableExec is a toggle that can be used during tests to advertise the
+	// driver as not supporting exec
+	DisableExec bool `codec:"disable_exec"`
 }
 
 type Command struct {
@@ -302,6 +310,9 @@ func (d *Driver) SetConfig(cfg *base.Config) error {
 	if isolation != "" {
 		d.capabilities.FSIsolation = drivers.FSIsolation(isolation)
 	}
+	if config.DisableExec {
+		d.capabilities.Exec = false
+	}
 
 	return nil
 }
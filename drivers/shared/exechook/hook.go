@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package exechook implements the post_start and pre_stop blocks of the task
+// drivers, which run a command inside the task after it starts and before it's
+// stopped, like the container lifecycle hooks of Kubernetes.
+package exechook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/hashicorp/nomad/plugins/shared/hclspec"
+)
+
+const (
+	// DefaultTimeout is the time a hook command may run for if its timeout
+	// isn't set.
+	DefaultTimeout = 30 * time.Second
+
+	// maxOutput is the number of bytes of the output of a failed hook command
+	// included in its error.
+	maxOutput = 256
+)
+
+// Spec is the hcl specification of the post_start and pre_stop blocks of the
+// task config of a driver.
+var Spec = hclspec.NewObject(map[string]*hclspec.Spec{
+	"command": hclspec.NewAttr("command", "string", true),
+	"args":    hclspec.NewAttr("args", "list(string)", false),
+	"timeout": hclspec.NewAttr("timeout", "string", false),
+})
+
+// Hook is a command run inside a task by its driver.
+type Hook struct {
+	Command string   `codec:"command"`
+	Args    []string `codec:"args"`
+	Timeout string   `codec:"timeout"`
+}
+
+// Validate returns an error if the command of the hook isn't set or its
+// timeout isn't a valid duration.
+func (h *Hook) Validate() error {
+	if h == nil {
+		return nil
+	}
+	if h.Command == "" {
+		return errors.New("command must be set")
+	}
+	if h.Timeout != "" {
+		timeout, err := time.ParseDuration(h.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed to parse timeout %q: %v", h.Timeout, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeout %q must be positive", h.Timeout)
+		}
+	}
+	return nil
+}
+
+// ExecFunc runs a command inside a task with a timeout, as the ExecTask method
+// of the drivers does.
+type ExecFunc func(cmd []string, timeout time.Duration) (*drivers.ExecTaskResult, error)
+
+// Run runs the command of the hook with exec, and returns an error if it
+// fails or exits with a non-zero exit code. A nil hook is a noop.
+func (h *Hook) Run(exec ExecFunc) error {
+	if h == nil {
+		return nil
+	}
+
+	timeout := DefaultTimeout
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+			timeout = d
+		}
+	}
+
+	cmd := append([]string{h.Command}, h.Args...)
+	res, err := exec(cmd, timeout)
+	if err != nil {
+		return fmt.Errorf("failed to run %q: %w", h.Command, err)
+	}
+	if res.ExitResult != nil && !res.ExitResult.Successful() {
+		return fmt.Errorf("%q exited with code %d: %s",
+			h.Command, res.ExitResult.ExitCode, output(res))
+	}
+	return nil
+}
+
+// output returns the end of the output of a command, for its error.
+func output(res *drivers.ExecTaskResult) string {
+	out := bytes.TrimSpace(bytes.Join([][]byte{res.Stdout, res.Stderr}, nil))
+	if len(out) > maxOutput {
+		out = out[len(out)-maxOutput:]
+	}
+	return string(out)
+}
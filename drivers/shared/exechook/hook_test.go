@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package exechook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/shoenig/test/must"
+)
+
+func TestHook_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilHook *Hook
+	must.NoError(t, nilHook.Validate())
+	must.NoError(t, (&Hook{Command: "/bin/true", Timeout: "5s"}).Validate())
+	must.ErrorContains(t, (&Hook{}).Validate(), "command must be set")
+	must.ErrorContains(t, (&Hook{Command: "/bin/true", Timeout: "5"}).Validate(), "failed to parse timeout")
+	must.ErrorContains(t, (&Hook{Command: "/bin/true", Timeout: "-5s"}).Validate(), "must be positive")
+}
+
+func TestHook_Run(t *testing.T) {
+	ci.Parallel(t)
+
+	var gotCmd []string
+	var gotTimeout time.Duration
+	exec := func(res *drivers.ExecTaskResult, err error) ExecFunc {
+		return func(cmd []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
+			gotCmd, gotTimeout = cmd, timeout
+			return res, err
+		}
+	}
+
+	var nilHook *Hook
+	must.NoError(t, nilHook.Run(exec(nil, errors.New("not called"))))
+
+	hook := &Hook{Command: "/bin/warmup", Args: []string{"-fast"}}
+	must.NoError(t, hook.Run(exec(&drivers.ExecTaskResult{ExitResult: &drivers.ExitResult{}}, nil)))
+	must.Eq(t, []string{"/bin/warmup", "-fast"}, gotCmd)
+	must.Eq(t, DefaultTimeout, gotTimeout)
+
+	hook.Timeout = "5s"
+	err := hook.Run(exec(&drivers.ExecTaskResult{
+		Stdout:     []byte("warming up\n"),
+		Stderr:     []byte("not ready\n"),
+		ExitResult: &drivers.ExitResult{ExitCode: 2},
+	}, nil))
+	must.EqError(t, err, `"/bin/warmup" exited with code 2: warming up
+not ready`)
+	must.Eq(t, 5*time.Second, gotTimeout)
+
+	err = hook.Run(exec(nil, errors.New("container not running")))
+	must.EqError(t, err, `failed to run "/bin/warmup": container not running`)
+}
@@ -13,6 +13,9 @@ import (
 	"github.com/hashicorp/nomad/plugins/drivers"
 )
 
+// hostsHeader is the first line of the hosts files generated by Nomad.
+const hostsHeader = "# this file was generated by Nomad"
+
 // GenerateEtcHostsMount writes a /etc/hosts file using the network spec's
 // hosts configuration, and returns a mount config so that task drivers can
 // bind-mount it into the resulting task's filesystem. The extraHosts
@@ -28,7 +31,7 @@ func GenerateEtcHostsMount(taskDir string, conf *drivers.NetworkIsolationSpec, e
 	}
 
 	var content strings.Builder
-	fmt.Fprintf(&content, `# this file was generated by Nomad
+	fmt.Fprintf(&content, `%s
 127.0.0.1 localhost
 ::1 localhost
 ::1 ip6-localhost ip6-loopback
@@ -41,7 +44,7 @@ ff02::3 ip6-allhosts
 # this entry is the IP address and hostname of the allocation
 # shared with tasks in the task group's network
 %s %s
-`, hostsCfg.Address, hostsCfg.Hostname)
+`, hostsHeader, hostsCfg.Address, hostsCfg.Hostname)
 
 	if len(extraHosts) > 0 {
 		content.WriteString("\n# these entries are extra hosts added by the task config")
@@ -61,10 +64,20 @@ ff02::3 ip6-allhosts
 	path := filepath.Join(taskDir, "hosts")
 
 	// tasks within an alloc should be able to share and modify the file, so
-	// only write to it if it doesn't exist
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		err := os.WriteFile(path, []byte(content.String()), 0644)
-		if err != nil {
+	// only write to it if it doesn't exist, or if it only has the service
+	// hosts written by the client before the first task started
+	existing, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case !strings.HasPrefix(string(existing), hostsHeader):
+		content.WriteString("\n")
+		content.Write(existing)
+		if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
 			return nil, err
 		}
 	}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hostnames
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	serviceHostsBegin = "# begin of the entries of the services discovered by Nomad"
+	serviceHostsEnd   = "# end of the entries of the services discovered by Nomad"
+)
+
+// WriteServiceHosts writes the entries of the services discovered for an
+// allocation to the /etc/hosts file shared by its tasks, replacing the entries
+// written before. Each entry is expected to be formatted as
+// "<ip address> <hostname>". The rest of the file is left untouched.
+//
+// The file is written in place rather than replaced, so that the tasks that
+// bind-mount it see the changes.
+func WriteServiceHosts(allocDir string, entries []string) error {
+	path := filepath.Join(allocDir, "hosts")
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content := removeServiceHosts(string(existing))
+	if len(entries) > 0 {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		var section strings.Builder
+		section.WriteString(serviceHostsBegin + "\n")
+		for _, entry := range entries {
+			section.WriteString(entry + "\n")
+		}
+		section.WriteString(serviceHostsEnd + "\n")
+		content += section.String()
+	}
+
+	if content == string(existing) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// removeServiceHosts returns the content of a hosts file without the entries
+// of the services discovered by Nomad.
+func removeServiceHosts(content string) string {
+	begin := strings.Index(content, serviceHostsBegin+"\n")
+	if begin < 0 {
+		return content
+	}
+	end := strings.Index(content[begin:], serviceHostsEnd+"\n")
+	if end < 0 {
+		return content[:begin]
+	}
+	return content[:begin] + content[begin+end+len(serviceHostsEnd)+1:]
+}
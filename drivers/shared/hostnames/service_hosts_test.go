@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hostnames
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteServiceHosts(t *testing.T) {
+	allocDir := t.TempDir()
+	path := filepath.Join(allocDir, "hosts")
+	read := func() string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	// The service hosts are written before the first task starts.
+	require.NoError(t, WriteServiceHosts(allocDir, []string{"10.0.0.1 db"}))
+	require.Equal(t, serviceHostsBegin+"\n10.0.0.1 db\n"+serviceHostsEnd+"\n", read())
+
+	// The hosts file generated when the task starts keeps the service hosts.
+	spec := &drivers.NetworkIsolationSpec{
+		Mode: drivers.NetIsolationModeGroup,
+		HostsConfig: &drivers.HostsConfig{
+			Address:  "192.168.1.1",
+			Hostname: "xyzzy",
+		},
+	}
+	_, err := GenerateEtcHostsMount(allocDir, spec, nil)
+	require.NoError(t, err)
+	content := read()
+	require.True(t, strings.HasPrefix(content, hostsHeader))
+	require.Contains(t, content, "192.168.1.1 xyzzy\n")
+	require.Contains(t, content, "\n10.0.0.1 db\n")
+
+	// Changed service hosts replace the previous ones.
+	require.NoError(t, WriteServiceHosts(allocDir, []string{"10.0.0.2 db", "10.0.0.3 cache"}))
+	content = read()
+	require.NotContains(t, content, "10.0.0.1 db")
+	require.Contains(t, content, "192.168.1.1 xyzzy\n")
+	require.True(t, strings.HasSuffix(content,
+		serviceHostsBegin+"\n10.0.0.2 db\n10.0.0.3 cache\n"+serviceHostsEnd+"\n"))
+
+	// Removing all the service hosts removes the section.
+	require.NoError(t, WriteServiceHosts(allocDir, nil))
+	content = read()
+	require.NotContains(t, content, serviceHostsBegin)
+	require.Contains(t, content, "192.168.1.1 xyzzy\n")
+}
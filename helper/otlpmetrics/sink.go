@@ -0,0 +1,374 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package otlpmetrics provides a go-metrics sink that pushes the metrics of
+// the agent to an OpenTelemetry collector with the OTLP/HTTP protocol.
+package otlpmetrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/helper/useragent"
+)
+
+const (
+	// DefaultInterval is the default interval between two pushes of the
+	// metrics to the collector.
+	DefaultInterval = 10 * time.Second
+
+	// pushTimeout is the time to wait for the collector to accept a push.
+	pushTimeout = 10 * time.Second
+
+	// scopeName is the name of the instrumentation scope of the metrics.
+	scopeName = "github.com/hashicorp/nomad"
+)
+
+// OTLP aggregation temporalities
+const (
+	temporalityCumulative = 2
+)
+
+// Config is the configuration of a Sink.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP metrics endpoint of the
+	// collector, such as http://localhost:4318/v1/metrics.
+	Endpoint string
+
+	// Headers are added to the requests sent to the collector, for example
+	// to authenticate them.
+	Headers map[string]string
+
+	// Interval is the interval between two pushes. Defaults to
+	// DefaultInterval.
+	Interval time.Duration
+
+	// ResourceAttributes describe the agent the metrics are emitted by, such
+	// as its region, datacenter, and node.
+	ResourceAttributes map[string]string
+
+	Logger hclog.Logger
+}
+
+// Sink implements metrics.MetricSink by aggregating the metrics it receives
+// and pushing them to an OpenTelemetry collector at every interval. Gauges
+// are pushed with their latest value, counters as cumulative sums since the
+// sink started, and samples as summaries of the values received during the
+// interval.
+type Sink struct {
+	endpoint string
+	headers  map[string]string
+	interval time.Duration
+	resource []keyValue
+	client   *http.Client
+	logger   hclog.Logger
+
+	start    time.Time
+	gauges   map[string]*gaugeValue
+	counters map[string]*counterValue
+	samples  map[string]*sampleValue
+	l        sync.Mutex
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+}
+
+type series struct {
+	name   string
+	labels []metrics.Label
+}
+
+type gaugeValue struct {
+	series
+	value float32
+	time  time.Time
+}
+
+type counterValue struct {
+	series
+	value float64
+}
+
+type sampleValue struct {
+	series
+	count uint64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+// NewSink returns a Sink pushing the metrics to the endpoint, and starts
+// pushing them in the background until Shutdown is called.
+func NewSink(c *Config) (*Sink, error) {
+	if c.Endpoint == "" {
+		return nil, errors.New("OTLP endpoint must not be empty")
+	}
+	if !strings.HasPrefix(c.Endpoint, "http://") && !strings.HasPrefix(c.Endpoint, "https://") {
+		return nil, fmt.Errorf("OTLP endpoint %q must be an http or https URL", c.Endpoint)
+	}
+
+	s := &Sink{
+		endpoint: c.Endpoint,
+		headers:  c.Headers,
+		interval: c.Interval,
+		resource: attributes(c.ResourceAttributes),
+		client:   cleanhttp.DefaultPooledClient(),
+		logger:   c.Logger,
+		start:    time.Now(),
+		gauges:   make(map[string]*gaugeValue),
+		counters: make(map[string]*counterValue),
+		samples:  make(map[string]*sampleValue),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	if s.interval <= 0 {
+		s.interval = DefaultInterval
+	}
+	if s.logger == nil {
+		s.logger = hclog.NewNullLogger()
+	}
+	s.logger = s.logger.Named("otlp_metrics")
+
+	go s.run()
+	return s, nil
+}
+
+func (s *Sink) SetGauge(key []string, val float32) {
+	s.SetGaugeWithLabels(key, val, nil)
+}
+
+func (s *Sink) SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	id, ser := newSeries(key, labels)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.gauges[id] = &gaugeValue{series: ser, value: val, time: time.Now()}
+}
+
+func (s *Sink) EmitKey(key []string, val float32) {
+	s.SetGaugeWithLabels(key, val, nil)
+}
+
+func (s *Sink) IncrCounter(key []string, val float32) {
+	s.IncrCounterWithLabels(key, val, nil)
+}
+
+func (s *Sink) IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	id, ser := newSeries(key, labels)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	counter, ok := s.counters[id]
+	if !ok {
+		counter = &counterValue{series: ser}
+		s.counters[id] = counter
+	}
+	counter.value += float64(val)
+}
+
+func (s *Sink) AddSample(key []string, val float32) {
+	s.AddSampleWithLabels(key, val, nil)
+}
+
+func (s *Sink) AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	id, ser := newSeries(key, labels)
+	v := float64(val)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	sample, ok := s.samples[id]
+	if !ok {
+		sample = &sampleValue{series: ser, min: v, max: v}
+		s.samples[id] = sample
+	}
+	sample.count++
+	sample.sum += v
+	sample.min = min(sample.min, v)
+	sample.max = max(sample.max, v)
+}
+
+// Shutdown pushes the metrics one last time and stops the sink.
+func (s *Sink) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	<-s.doneCh
+}
+
+func (s *Sink) run() {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	intervalStart := s.start
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.stopCh:
+			s.flush(intervalStart)
+			return
+		}
+
+		now := time.Now()
+		s.flush(intervalStart)
+		intervalStart = now
+	}
+}
+
+// flush pushes the metrics to the collector. Failed pushes are logged and
+// not retried, since the next push has the latest gauges and counters.
+func (s *Sink) flush(intervalStart time.Time) {
+	body, err := json.Marshal(s.export(intervalStart, time.Now()))
+	if err != nil {
+		s.logger.Error("failed to encode metrics", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+	if err := s.push(ctx, body); err != nil {
+		s.logger.Warn("failed to push metrics", "endpoint", s.endpoint, "error", err)
+	}
+}
+
+func (s *Sink) push(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(useragent.Header, useragent.String())
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected response code %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// export returns the metrics request for the interval and resets the
+// samples.
+func (s *Sink) export(intervalStart, now time.Time) *exportRequest {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	nowNano := unixNano(now)
+	var out []*metric
+	for _, id := range sortedKeys(s.gauges) {
+		g := s.gauges[id]
+		out = append(out, &metric{
+			Name: g.name,
+			Gauge: &gauge{DataPoints: []*numberDataPoint{{
+				Attributes:   labelAttributes(g.labels),
+				TimeUnixNano: unixNano(g.time),
+				AsDouble:     float64(g.value),
+			}}},
+		})
+	}
+	for _, id := range sortedKeys(s.counters) {
+		c := s.counters[id]
+		out = append(out, &metric{
+			Name: c.name,
+			Sum: &sum{
+				DataPoints: []*numberDataPoint{{
+					Attributes:        labelAttributes(c.labels),
+					StartTimeUnixNano: unixNano(s.start),
+					TimeUnixNano:      nowNano,
+					AsDouble:          c.value,
+				}},
+				AggregationTemporality: temporalityCumulative,
+				IsMonotonic:            true,
+			},
+		})
+	}
+	for _, id := range sortedKeys(s.samples) {
+		sample := s.samples[id]
+		out = append(out, &metric{
+			Name: sample.name,
+			Summary: &summary{DataPoints: []*summaryDataPoint{{
+				Attributes:        labelAttributes(sample.labels),
+				StartTimeUnixNano: unixNano(intervalStart),
+				TimeUnixNano:      nowNano,
+				Count:             strconv.FormatUint(sample.count, 10),
+				Sum:               sample.sum,
+				QuantileValues: []*quantileValue{
+					{Quantile: 0, Value: sample.min},
+					{Quantile: 1, Value: sample.max},
+				},
+			}}},
+		})
+	}
+	s.samples = make(map[string]*sampleValue)
+
+	return &exportRequest{ResourceMetrics: []*resourceMetrics{{
+		Resource: &resource{Attributes: s.resource},
+		ScopeMetrics: []*scopeMetrics{{
+			Scope:   &scope{Name: scopeName},
+			Metrics: out,
+		}},
+	}}}
+}
+
+// newSeries returns the ID of the series of the metric, which is unique for
+// each name and set of labels.
+func newSeries(key []string, labels []metrics.Label) (string, series) {
+	name := strings.Join(key, ".")
+
+	var id strings.Builder
+	id.WriteString(name)
+	for _, label := range labels {
+		id.WriteString("\x00" + label.Name + "=" + label.Value)
+	}
+	return id.String(), series{name: name, labels: labels}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func attributes(m map[string]string) []keyValue {
+	out := make([]keyValue, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		out = append(out, keyValue{Key: k, Value: anyValue{StringValue: m[k]}})
+	}
+	return out
+}
+
+func labelAttributes(labels []metrics.Label) []keyValue {
+	out := make([]keyValue, 0, len(labels))
+	for _, label := range labels {
+		out = append(out, keyValue{Key: label.Name, Value: anyValue{StringValue: label.Value}})
+	}
+	return out
+}
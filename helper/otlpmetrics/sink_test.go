@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package otlpmetrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestSink(t *testing.T) {
+	ci.Parallel(t)
+
+	requestCh := make(chan *exportRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req exportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requestCh <- &req
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(&Config{
+		Endpoint: srv.URL + "/v1/metrics",
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+		Interval: time.Hour,
+		ResourceAttributes: map[string]string{
+			"service.name": "nomad",
+			"nomad.region": "global",
+		},
+	})
+	must.NoError(t, err)
+
+	labels := []metrics.Label{{Name: "namespace", Value: "default"}}
+	sink.SetGaugeWithLabels([]string{"nomad", "job_summary", "running"}, 1, labels)
+	sink.SetGaugeWithLabels([]string{"nomad", "job_summary", "running"}, 3, labels)
+	sink.IncrCounter([]string{"nomad", "rpc", "request"}, 2)
+	sink.IncrCounter([]string{"nomad", "rpc", "request"}, 3)
+	sink.AddSample([]string{"nomad", "raft", "apply"}, 4)
+	sink.AddSample([]string{"nomad", "raft", "apply"}, 2)
+
+	// Shutting down the sink pushes the metrics
+	sink.Shutdown()
+
+	var req *exportRequest
+	select {
+	case req = <-requestCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("metrics were not pushed")
+	}
+
+	must.Len(t, 1, req.ResourceMetrics)
+	rm := req.ResourceMetrics[0]
+	must.Eq(t, []keyValue{
+		{Key: "nomad.region", Value: anyValue{StringValue: "global"}},
+		{Key: "service.name", Value: anyValue{StringValue: "nomad"}},
+	}, rm.Resource.Attributes)
+
+	must.Len(t, 1, rm.ScopeMetrics)
+	out := rm.ScopeMetrics[0].Metrics
+	must.Len(t, 3, out)
+
+	must.Eq(t, "nomad.job_summary.running", out[0].Name)
+	must.NotNil(t, out[0].Gauge)
+	must.Eq(t, 3, out[0].Gauge.DataPoints[0].AsDouble)
+	must.Eq(t, []keyValue{{Key: "namespace", Value: anyValue{StringValue: "default"}}},
+		out[0].Gauge.DataPoints[0].Attributes)
+
+	must.Eq(t, "nomad.rpc.request", out[1].Name)
+	must.NotNil(t, out[1].Sum)
+	must.True(t, out[1].Sum.IsMonotonic)
+	must.Eq(t, temporalityCumulative, out[1].Sum.AggregationTemporality)
+	must.Eq(t, 5, out[1].Sum.DataPoints[0].AsDouble)
+
+	must.Eq(t, "nomad.raft.apply", out[2].Name)
+	must.NotNil(t, out[2].Summary)
+	dp := out[2].Summary.DataPoints[0]
+	must.Eq(t, "2", dp.Count)
+	must.Eq(t, 6, dp.Sum)
+	must.Eq(t, []*quantileValue{{Quantile: 0, Value: 2}, {Quantile: 1, Value: 4}}, dp.QuantileValues)
+}
+
+func TestSink_Export(t *testing.T) {
+	ci.Parallel(t)
+
+	sink := &Sink{
+		start:    time.Now(),
+		gauges:   make(map[string]*gaugeValue),
+		counters: make(map[string]*counterValue),
+		samples:  make(map[string]*sampleValue),
+	}
+
+	sink.IncrCounter([]string{"nomad", "rpc", "request"}, 1)
+	sink.AddSample([]string{"nomad", "raft", "apply"}, 1)
+	must.Len(t, 2, sink.export(sink.start, time.Now()).ResourceMetrics[0].ScopeMetrics[0].Metrics)
+
+	// Counters are cumulative, while the samples are reset at every interval
+	sink.IncrCounter([]string{"nomad", "rpc", "request"}, 1)
+	out := sink.export(sink.start, time.Now()).ResourceMetrics[0].ScopeMetrics[0].Metrics
+	must.Len(t, 1, out)
+	must.Eq(t, 2, out[0].Sum.DataPoints[0].AsDouble)
+}
+
+func TestNewSink_Invalid(t *testing.T) {
+	ci.Parallel(t)
+
+	_, err := NewSink(&Config{})
+	must.EqError(t, err, "OTLP endpoint must not be empty")
+
+	_, err = NewSink(&Config{Endpoint: "localhost:4318"})
+	must.ErrorContains(t, err, "must be an http or https URL")
+}
@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package otlpmetrics
+
+// The types below are the subset of the OTLP metrics protocol used by the
+// sink, encoded with the JSON mapping of protocol buffers as required by
+// OTLP/HTTP. 64-bit integers are encoded as strings by this mapping. See
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/metrics/v1/metrics.proto
+
+type exportRequest struct {
+	ResourceMetrics []*resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     *resource       `json:"resource"`
+	ScopeMetrics []*scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Scope   *scope    `json:"scope"`
+	Metrics []*metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type metric struct {
+	Name    string   `json:"name"`
+	Gauge   *gauge   `json:"gauge,omitempty"`
+	Sum     *sum     `json:"sum,omitempty"`
+	Summary *summary `json:"summary,omitempty"`
+}
+
+type gauge struct {
+	DataPoints []*numberDataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	DataPoints             []*numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                `json:"aggregationTemporality"`
+	IsMonotonic            bool               `json:"isMonotonic"`
+}
+
+type summary struct {
+	DataPoints []*summaryDataPoint `json:"dataPoints"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+type summaryDataPoint struct {
+	Attributes        []keyValue       `json:"attributes"`
+	StartTimeUnixNano string           `json:"startTimeUnixNano"`
+	TimeUnixNano      string           `json:"timeUnixNano"`
+	Count             string           `json:"count"`
+	Sum               float64          `json:"sum"`
+	QuantileValues    []*quantileValue `json:"quantileValues"`
+}
+
+type quantileValue struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
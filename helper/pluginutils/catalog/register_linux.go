@@ -0,0 +1,14 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package catalog
+
+import (
+	"github.com/hashicorp/nomad/drivers/containerd"
+)
+
+// This file is where all builtin plugins that only build on Linux should be
+// registered in the catalog.
+func init() {
+	Register(containerd.PluginID, containerd.PluginConfig)
+}
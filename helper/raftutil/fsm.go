@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/raft"
 	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
 )
@@ -90,6 +91,9 @@ func dummyFSM(logger hclog.Logger) (nomadFSM, error) {
 		Blocked:    blockedEvals,
 		Logger:     logger,
 		Region:     "default",
+
+		JobTrackedVersions:      structs.JobDefaultTrackedVersions,
+		JobTrackedScalingEvents: structs.JobTrackedScalingEvents,
 	}
 
 	return nomad.NewFSM(fsmConfig)
@@ -65,6 +65,10 @@ var msgTypeNames = map[structs.MessageType]string{
 	structs.ACLBindingRulesDeleteRequestType:             "ACLBindingRulesDeleteRequestType",
 	structs.NodePoolUpsertRequestType:                    "NodePoolUpsertRequestType",
 	structs.NodePoolDeleteRequestType:                    "NodePoolDeleteRequestType",
+	structs.EvalTraceUpsertRequestType:                   "EvalTraceUpsertRequestType",
+	structs.LocalVolumeClaimDeleteRequestType:            "LocalVolumeClaimDeleteRequestType",
+	structs.JobSuspendRequestType:                        "JobSuspendRequestType",
 	structs.NamespaceUpsertRequestType:                   "NamespaceUpsertRequestType",
 	structs.NamespaceDeleteRequestType:                   "NamespaceDeleteRequestType",
+	structs.AllocIdentityEventsRequestType:               "AllocIdentityEventsRequestType",
 }
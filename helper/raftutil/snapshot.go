@@ -50,3 +50,35 @@ func RestoreFromArchive(archive io.Reader, filter *nomad.FSMFilter) (*state.Stat
 		return fsm.State(), meta, nil
 	}
 }
+
+// VerifyArchive verifies the checksums of a snapshot archive and of the
+// chunks of the snapshot it contains.
+func VerifyArchive(archive io.Reader) (*raft.SnapshotMeta, *nomad.SnapshotStateInfo, error) {
+	// r is read by VerifySnapshotState, w is closed by CopySnapshot
+	r, w := io.Pipe()
+
+	errCh := make(chan error, 1)
+	metaCh := make(chan *raft.SnapshotMeta, 1)
+
+	go func() {
+		meta, err := snapshot.CopySnapshot(archive, w)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to read snapshot: %w", err)
+		} else {
+			metaCh <- meta
+		}
+	}()
+
+	info, err := nomad.VerifySnapshotState(r)
+	r.CloseWithError(err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify snapshot state: %w", err)
+	}
+
+	select {
+	case err := <-errCh:
+		return nil, nil, err
+	case meta := <-metaCh:
+		return meta, info, nil
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package snapshot
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// chunkHeaderSize is the size of the header of a chunk: the length of its
+// data followed by their CRC32 checksum.
+const chunkHeaderSize = 8
+
+// ErrChunkChecksum is returned when the data of a chunk don't match their
+// checksum.
+var ErrChunkChecksum = errors.New("snapshot chunk checksum mismatch")
+
+var chunkCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ChunkWriter writes a stream of data in chunks of a fixed size, each one
+// with a checksum of its data so corrupted snapshots are detected when they're
+// read. The stream ends with an empty chunk so truncated streams are detected
+// too. The chunks hold the same data as an unchunked stream: every snapshot is
+// still a full copy of the state.
+type ChunkWriter struct {
+	w    io.Writer
+	size int
+	buf  []byte
+}
+
+// NewChunkWriter returns a ChunkWriter writing chunks of the given size to w.
+func NewChunkWriter(w io.Writer, size int) *ChunkWriter {
+	return &ChunkWriter{
+		w:    w,
+		size: size,
+		buf:  make([]byte, 0, size),
+	}
+}
+
+// Write implements io.Writer.
+func (c *ChunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		free := c.size - len(c.buf)
+		if free > len(p) {
+			free = len(p)
+		}
+		c.buf = append(c.buf, p[:free]...)
+		p = p[free:]
+
+		if len(c.buf) == c.size {
+			if err := c.writeChunk(c.buf); err != nil {
+				return 0, err
+			}
+			c.buf = c.buf[:0]
+		}
+	}
+	return n, nil
+}
+
+// Flush writes the pending data and the empty chunk ending the stream. The
+// writer must not be used afterwards.
+func (c *ChunkWriter) Flush() error {
+	if len(c.buf) > 0 {
+		if err := c.writeChunk(c.buf); err != nil {
+			return err
+		}
+		c.buf = c.buf[:0]
+	}
+	return c.writeChunk(nil)
+}
+
+func (c *ChunkWriter) writeChunk(data []byte) error {
+	var header [chunkHeaderSize]byte
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.Checksum(data, chunkCRCTable))
+	if _, err := c.w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := c.w.Write(data)
+	return err
+}
+
+// ChunkReader reads a stream written by a ChunkWriter, verifying the checksum
+// of each chunk before returning its data. Reads are served from the current
+// chunk, so small reads don't reach the underlying reader.
+type ChunkReader struct {
+	r       io.Reader
+	maxSize int
+	buf     []byte
+	data    []byte
+	chunks  int
+	size    int64
+	done    bool
+}
+
+// NewChunkReader returns a ChunkReader reading chunks of at most maxSize
+// bytes from r.
+func NewChunkReader(r io.Reader, maxSize int) *ChunkReader {
+	return &ChunkReader{
+		r:       r,
+		maxSize: maxSize,
+		buf:     make([]byte, maxSize),
+	}
+}
+
+// Read implements io.Reader. It returns io.EOF once the empty chunk ending
+// the stream is read, and io.ErrUnexpectedEOF if the stream ends before it.
+func (c *ChunkReader) Read(p []byte) (int, error) {
+	for len(c.data) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, c.data)
+	c.data = c.data[n:]
+	return n, nil
+}
+
+// Chunks returns the number of chunks read, excluding the empty chunk ending
+// the stream.
+func (c *ChunkReader) Chunks() int {
+	return c.chunks
+}
+
+// Size returns the number of bytes of data read.
+func (c *ChunkReader) Size() int64 {
+	return c.size
+}
+
+func (c *ChunkReader) readChunk() error {
+	var header [chunkHeaderSize]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	if length > uint32(c.maxSize) {
+		return fmt.Errorf("snapshot chunk %d is larger than the chunk size: %d > %d",
+			c.chunks, length, c.maxSize)
+	}
+
+	data := c.buf[:length]
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if crc32.Checksum(data, chunkCRCTable) != checksum {
+		return fmt.Errorf("%w: chunk %d", ErrChunkChecksum, c.chunks)
+	}
+
+	if length == 0 {
+		c.done = true
+		return nil
+	}
+	c.chunks++
+	c.size += int64(length)
+	c.data = data
+	return nil
+}
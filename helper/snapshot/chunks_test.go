@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package snapshot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestChunks(t *testing.T) {
+	ci.Parallel(t)
+
+	data := make([]byte, 2500)
+	_, err := rand.Read(data)
+	must.NoError(t, err)
+
+	var buf bytes.Buffer
+	w := NewChunkWriter(&buf, 1024)
+	for _, p := range [][]byte{data[:10], data[10:1500], data[1500:]} {
+		n, err := w.Write(p)
+		must.NoError(t, err)
+		must.Eq(t, len(p), n)
+	}
+	must.NoError(t, w.Flush())
+
+	t.Run("roundtrip", func(t *testing.T) {
+		r := NewChunkReader(bytes.NewReader(buf.Bytes()), 1024)
+		out, err := io.ReadAll(r)
+		must.NoError(t, err)
+		must.Eq(t, data, out)
+		must.Eq(t, 3, r.Chunks())
+		must.Eq(t, int64(len(data)), r.Size())
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		corrupted := bytes.Clone(buf.Bytes())
+		corrupted[chunkHeaderSize+1024+chunkHeaderSize+10] ^= 0xff
+
+		_, err := io.ReadAll(NewChunkReader(bytes.NewReader(corrupted), 1024))
+		must.ErrorIs(t, err, ErrChunkChecksum)
+		must.ErrorContains(t, err, "chunk 1")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		truncated := buf.Bytes()[:buf.Len()-chunkHeaderSize]
+
+		_, err := io.ReadAll(NewChunkReader(bytes.NewReader(truncated), 1024))
+		must.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("oversized", func(t *testing.T) {
+		_, err := io.ReadAll(NewChunkReader(bytes.NewReader(buf.Bytes()), 512))
+		must.ErrorContains(t, err, "larger than the chunk size")
+	})
+}
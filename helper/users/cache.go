@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/lib/lang"
 	"oss.indeed.com/go/libtime"
 )
@@ -30,6 +31,14 @@ type (
 
 type lookupUserFunc func(string) (*user.User, error)
 
+// pendingLookup is an OS lookup in progress, shared by every caller looking up
+// the same user.
+type pendingLookup struct {
+	done chan struct{}
+	u    *user.User
+	err  error
+}
+
 type cache struct {
 	clock      libtime.Clock
 	lookupUser lookupUserFunc
@@ -37,6 +46,7 @@ type cache struct {
 	lock         sync.Mutex
 	users        userCache
 	userFailures userFailureCache
+	pending      map[string]*pendingLookup
 }
 
 func newCache() *cache {
@@ -45,12 +55,25 @@ func newCache() *cache {
 		lookupUser:   internalLookupUser,
 		users:        make(userCache),
 		userFailures: make(userFailureCache),
+		pending:      make(map[string]*pendingLookup),
 	}
 }
 
 func (c *cache) GetUser(username string) (*user.User, error) {
+	return c.getUser(username, nil)
+}
+
+// GetUserTimeout is like GetUser but gives up waiting for the OS lookup after
+// timeout. The lookup keeps running and its result is cached once it
+// completes.
+func (c *cache) GetUserTimeout(username string, timeout time.Duration) (*user.User, error) {
+	timer, stop := helper.NewSafeTimer(timeout)
+	defer stop()
+	return c.getUser(username, timer.C)
+}
+
+func (c *cache) getUser(username string, timeoutCh <-chan time.Time) (*user.User, error) {
 	c.lock.Lock()
-	defer c.lock.Unlock()
 
 	// record this moment as "now" for further cache operations
 	now := c.clock.Now()
@@ -59,6 +82,7 @@ func (c *cache) GetUser(username string) (*user.User, error) {
 	// is not yet expired
 	usr, exists := c.users[username]
 	if exists && !usr.expired(now, cacheTTL) {
+		c.lock.Unlock()
 		return usr.First, nil
 	}
 
@@ -67,22 +91,46 @@ func (c *cache) GetUser(username string) (*user.User, error) {
 	failure, exists2 := c.userFailures[username]
 	if exists2 {
 		if !failure.expired(now, failureTTL) {
+			c.lock.Unlock()
 			return nil, failure.First
 		}
 		// may as well cleanup expired case
 		delete(c.userFailures, username)
 	}
 
-	// need to perform an OS lookup
-	u, err := c.lookupUser(username)
+	// need to perform an OS lookup, unless one for this user is already in
+	// progress. The cache isn't locked during the lookup so a hung lookup
+	// doesn't block the lookups of other users.
+	p, exists3 := c.pending[username]
+	if !exists3 {
+		p = &pendingLookup{done: make(chan struct{})}
+		c.pending[username] = p
+		go c.lookup(username, p, now)
+	}
+	c.lock.Unlock()
 
-	// lookup was a failure, populate the failure cache
-	if err != nil {
-		c.userFailures[username] = &entry[error]{err, now}
-		return nil, err
+	select {
+	case <-p.done:
+		return p.u, p.err
+	case <-timeoutCh:
+		return nil, ErrLookupTimeout
 	}
+}
 
-	// lookup was a success, populate the user cache
-	c.users[username] = &entry[*user.User]{u, now}
-	return u, nil
+// lookup performs the OS lookup of the pending lookup and caches its result.
+func (c *cache) lookup(username string, p *pendingLookup, now time.Time) {
+	p.u, p.err = c.lookupUser(username)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if p.err != nil {
+		// lookup was a failure, populate the failure cache
+		c.userFailures[username] = &entry[error]{p.err, now}
+	} else {
+		// lookup was a success, populate the user cache
+		c.users[username] = &entry[*user.User]{p.u, now}
+	}
+	delete(c.pending, username)
+	close(p.done)
 }
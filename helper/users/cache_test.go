@@ -154,3 +154,80 @@ func TestCache_mock_miss(t *testing.T) {
 	must.Eq(t, 2, lookupCount)
 	must.Eq(t, 3, clockCount)
 }
+
+func TestLookupTimeout(t *testing.T) {
+	ci.Parallel(t)
+
+	u, err := LookupTimeout("nobody", time.Minute)
+	must.NoError(t, err)
+	must.Eq(t, "nobody", u.Username)
+
+	blockCh := make(chan struct{})
+	t.Cleanup(func() { close(blockCh) })
+	c := newCache()
+	c.lookupUser = func(username string) (*user.User, error) {
+		if username == "svc-foo" {
+			<-blockCh
+			return nil, errors.New("unreachable")
+		}
+		return &user.User{Username: username}, nil
+	}
+	_, err = c.GetUserTimeout("svc-foo", 10*time.Millisecond)
+	must.ErrorIs(t, err, ErrLookupTimeout)
+
+	// the hung lookup doesn't block the lookups of other users
+	u, err = c.GetUserTimeout("svc-bar", time.Minute)
+	must.NoError(t, err)
+	must.Eq(t, "svc-bar", u.Username)
+
+	// nor the lookups of the same user, which wait on the pending lookup
+	_, err = c.GetUserTimeout("svc-foo", 10*time.Millisecond)
+	must.ErrorIs(t, err, ErrLookupTimeout)
+	must.MapLen(t, 1, c.pending)
+}
+
+func TestLookupTimeout_cached(t *testing.T) {
+	ci.Parallel(t)
+
+	blockCh := make(chan struct{})
+	c := newCache()
+	c.lookupUser = func(username string) (*user.User, error) {
+		<-blockCh
+		return &user.User{Username: username}, nil
+	}
+	_, err := c.GetUserTimeout("svc-foo", 10*time.Millisecond)
+	must.ErrorIs(t, err, ErrLookupTimeout)
+
+	// the result of the abandoned lookup is cached once it completes
+	close(blockCh)
+	u, err := c.GetUserTimeout("svc-foo", time.Minute)
+	must.NoError(t, err)
+	must.Eq(t, "svc-foo", u.Username)
+	must.MapEmpty(t, c.pending)
+	must.MapLen(t, 1, c.users)
+}
+
+func TestLockedLookup_hung(t *testing.T) {
+	ci.Parallel(t)
+
+	startedCh := make(chan struct{})
+	blockCh := make(chan struct{})
+	t.Cleanup(func() { close(blockCh) })
+
+	lookup := func(username string) (*user.User, error) {
+		if username == "svc-foo" {
+			close(startedCh)
+			<-blockCh
+			return nil, errors.New("unreachable")
+		}
+		return &user.User{Username: username}, nil
+	}
+
+	go lockedLookup(lookup, "svc-foo", 10*time.Millisecond)
+	<-startedCh
+
+	// the hung lookup releases the process lock
+	u, err := lockedLookup(lookup, "svc-bar", 10*time.Millisecond)
+	must.NoError(t, err)
+	must.Eq(t, "svc-bar", u.Username)
+}
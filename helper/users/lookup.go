@@ -4,15 +4,18 @@
 package users
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"os/user"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
 )
 
 var globalCache = newCache()
@@ -24,15 +27,65 @@ func Lookup(username string) (*user.User, error) {
 	return globalCache.GetUser(username)
 }
 
+// NSSSupported returns true if users are looked up with the NSS modules of the
+// host, so users managed by a directory service such as SSSD or LDAP can be
+// resolved. It is false when Nomad is built without cgo.
+func NSSSupported() bool {
+	return nssSupported
+}
+
+// ErrLookupTimeout is returned by LookupTimeout when the user lookup doesn't
+// complete in time.
+var ErrLookupTimeout = errors.New("timed out looking up user")
+
+// LookupTimeout is like Lookup but gives up after timeout, such as when the
+// directory service of the host is unreachable. The lookup itself can't be
+// canceled, so its result is still cached once it completes.
+func LookupTimeout(username string, timeout time.Duration) (*user.User, error) {
+	return globalCache.GetUserTimeout(username, timeout)
+}
+
 // lock is used to serialize all user lookup at the process level, because
 // some NSS implementations are not concurrency safe
 var lock sync.Mutex
 
-// internalLookupUser username while holding a global process lock.
+// hungLookupTimeout is how long a user lookup may hold the process lock. A
+// lookup taking longer, such as one stuck on an unreachable directory service,
+// is considered hung and releases the lock so it doesn't block every later
+// lookup.
+const hungLookupTimeout = 10 * time.Second
+
+// internalLookupUser username while holding a global process lock, for up to
+// hungLookupTimeout.
 func internalLookupUser(username string) (*user.User, error) {
+	return lockedLookup(user.Lookup, username, hungLookupTimeout)
+}
+
+func lockedLookup(lookup lookupUserFunc, username string, hungTimeout time.Duration) (*user.User, error) {
+	type result struct {
+		u   *user.User
+		err error
+	}
+	resultCh := make(chan result, 1)
+
 	lock.Lock()
-	defer lock.Unlock()
-	return user.Lookup(username)
+	go func() {
+		u, err := lookup(username)
+		resultCh <- result{u, err}
+	}()
+
+	timer, stop := helper.NewSafeTimer(hungTimeout)
+	defer stop()
+
+	var r result
+	select {
+	case r = <-resultCh:
+		lock.Unlock()
+	case <-timer.C:
+		lock.Unlock()
+		r = <-resultCh
+	}
+	return r.u, r.err
 }
 
 // Current returns the current user, acquired while holding a global process
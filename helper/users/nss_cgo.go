@@ -0,0 +1,11 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build unix && cgo && !osusergo
+
+package users
+
+// nssSupported is true when user lookups go through the C library, which
+// resolves users with the NSS modules configured in /etc/nsswitch.conf, such
+// as SSSD or LDAP.
+const nssSupported = true
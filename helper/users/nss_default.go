@@ -0,0 +1,10 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build !unix || !cgo || osusergo
+
+package users
+
+// nssSupported is false when user lookups are implemented in pure Go, which
+// only reads the local /etc/passwd file on unix.
+const nssSupported = false
@@ -434,6 +434,9 @@ func parseUpdate(result **api.UpdateStrategy, list *ast.ObjectList) error {
 		"auto_revert",
 		"auto_promote",
 		"canary",
+		"max_surge",
+		"health_checks",
+		"min_healthy_checks_percent",
 	}
 	if err := checkHCLKeys(o.Val, valid); err != nil {
 		return err
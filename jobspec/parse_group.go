@@ -46,6 +46,8 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 			"consul",
 			"affinity",
 			"restart",
+			"disconnect",
+			"env",
 			"meta",
 			"task",
 			"ephemeral_disk",
@@ -55,6 +57,7 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 			"migrate",
 			"spread",
 			"shutdown_delay",
+			"kill_escalation",
 			"network",
 			"service",
 			"volume",
@@ -74,9 +77,11 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 		delete(m, "constraint")
 		delete(m, "consul")
 		delete(m, "affinity")
+		delete(m, "env")
 		delete(m, "meta")
 		delete(m, "task")
 		delete(m, "restart")
+		delete(m, "disconnect")
 		delete(m, "ephemeral_disk")
 		delete(m, "update")
 		delete(m, "vault")
@@ -86,6 +91,7 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 		delete(m, "service")
 		delete(m, "volume")
 		delete(m, "scaling")
+		delete(m, "kill_escalation")
 
 		// Build the group with the basic decode
 		var g api.TaskGroup
@@ -131,6 +137,13 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 			}
 		}
 
+		// Parse disconnect strategy
+		if o := listVal.Filter("disconnect"); len(o.Items) > 0 {
+			if err := parseDisconnect(&g.Disconnect, o); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("'%s', disconnect ->", n))
+			}
+		}
+
 		// Parse spread
 		if o := listVal.Filter("spread"); len(o.Items) > 0 {
 			if err := parseSpread(&g.Spreads, o); err != nil {
@@ -189,6 +202,19 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 			}
 		}
 
+		// Parse out the environment variables set in all the tasks
+		if envO := listVal.Filter("env"); len(envO.Items) > 0 {
+			for _, o := range envO.Elem().Items {
+				var m map[string]interface{}
+				if err := hcl.DecodeObject(&m, o.Val); err != nil {
+					return err
+				}
+				if err := mapstructure.WeakDecode(m, &g.Env); err != nil {
+					return err
+				}
+			}
+		}
+
 		// Parse any volume declarations
 		if o := listVal.Filter("volume"); len(o.Items) > 0 {
 			if err := parseVolumes(&g.Volumes, o); err != nil {
@@ -203,6 +229,13 @@ func parseGroups(result *api.Job, list *ast.ObjectList) error {
 			}
 		}
 
+		// Parse kill escalation
+		if o := listVal.Filter("kill_escalation"); len(o.Items) > 0 {
+			if err := parseKillEscalation(&g.KillEscalation, o); err != nil {
+				return multierror.Prefix(err, "kill_escalation ->")
+			}
+		}
+
 		// Parse tasks
 		if o := listVal.Filter("task"); len(o.Items) > 0 {
 			if err := parseTasks(&g.Tasks, o); err != nil {
@@ -306,6 +339,37 @@ func parseEphemeralDisk(result **api.EphemeralDisk, list *ast.ObjectList) error
 	return nil
 }
 
+func parseDisconnect(result **api.DisconnectStrategy, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'disconnect' block allowed")
+	}
+
+	// Get our disconnect object
+	obj := list.Items[0]
+
+	// Check for invalid keys
+	valid := []string{
+		"reconcile",
+	}
+	if err := checkHCLKeys(obj.Val, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
+		return err
+	}
+
+	var disconnect api.DisconnectStrategy
+	if err := mapstructure.WeakDecode(m, &disconnect); err != nil {
+		return err
+	}
+	*result = &disconnect
+
+	return nil
+}
+
 func parseRestartPolicy(final **api.RestartPolicy, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -322,6 +386,7 @@ func parseRestartPolicy(final **api.RestartPolicy, list *ast.ObjectList) error {
 		"delay",
 		"mode",
 		"render_templates",
+		"disconnected_mode",
 	}
 	if err := checkHCLKeys(obj.Val, valid); err != nil {
 		return err
@@ -33,9 +33,11 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 	delete(m, "constraint")
 	delete(m, "affinity")
 	delete(m, "meta")
+	delete(m, "env")
 	delete(m, "migrate")
 	delete(m, "parameterized")
 	delete(m, "periodic")
+	delete(m, "active_schedule")
 	delete(m, "reschedule")
 	delete(m, "update")
 	delete(m, "vault")
@@ -61,14 +63,17 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 
 	// Check for invalid keys
 	valid := []string{
+		"active_schedule",
 		"all_at_once",
 		"constraint",
 		"affinity",
 		"spread",
 		"datacenters",
+		"env",
 		"node_pool",
 		"group",
 		"id",
+		"max_allocs_per_node",
 		"meta",
 		"migrate",
 		"name",
@@ -132,6 +137,13 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		}
 	}
 
+	// If we have an active schedule, then parse that
+	if o := listVal.Filter("active_schedule"); len(o.Items) > 0 {
+		if err := parseActiveSchedule(&result.ActiveSchedule, o); err != nil {
+			return multierror.Prefix(err, "active_schedule ->")
+		}
+	}
+
 	// If we have a reschedule block, then parse that
 	if o := listVal.Filter("reschedule"); len(o.Items) > 0 {
 		if err := parseReschedulePolicy(&result.Reschedule, o); err != nil {
@@ -169,6 +181,19 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		}
 	}
 
+	// Parse out the environment variables set in all the tasks
+	if envO := listVal.Filter("env"); len(envO.Items) > 0 {
+		for _, o := range envO.Elem().Items {
+			var m map[string]interface{}
+			if err := hcl.DecodeObject(&m, o.Val); err != nil {
+				return err
+			}
+			if err := mapstructure.WeakDecode(m, &result.Env); err != nil {
+				return err
+			}
+		}
+	}
+
 	// If we have tasks outside, create TaskGroups for them
 	if o := listVal.Filter("task"); len(o.Items) > 0 {
 		var tasks []*api.Task
@@ -305,3 +330,73 @@ func parseParameterizedJob(result **api.ParameterizedJobConfig, list *ast.Object
 	*result = &d
 	return nil
 }
+
+func parseActiveSchedule(result **api.JobActiveSchedule, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'active_schedule' block allowed per job")
+	}
+
+	// Get our resource object
+	o := list.Items[0]
+
+	// Check for invalid keys
+	valid := []string{
+		"time_zone",
+		"window",
+	}
+	if err := checkHCLKeys(o.Val, valid); err != nil {
+		return err
+	}
+
+	// We need this later
+	var listVal *ast.ObjectList
+	if ot, ok := o.Val.(*ast.ObjectType); ok {
+		listVal = ot.List
+	} else {
+		return fmt.Errorf("active_schedule should be an object")
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, o.Val); err != nil {
+		return err
+	}
+	delete(m, "window")
+
+	var s api.JobActiveSchedule
+	if err := mapstructure.WeakDecode(m, &s); err != nil {
+		return err
+	}
+
+	for _, o := range listVal.Filter("window").Elem().Items {
+		valid := []string{
+			"cron",
+			"duration",
+		}
+		if err := checkHCLKeys(o.Val, valid); err != nil {
+			return multierror.Prefix(err, "window ->")
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Val); err != nil {
+			return err
+		}
+
+		var w api.JobActiveWindow
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &w,
+		})
+		if err != nil {
+			return err
+		}
+		if err := dec.Decode(m); err != nil {
+			return err
+		}
+		s.Windows = append(s.Windows, &w)
+	}
+
+	*result = &s
+	return nil
+}
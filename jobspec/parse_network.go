@@ -118,6 +118,7 @@ func parseDNS(dns *ast.ObjectItem) (*api.DNSConfig, error) {
 		"servers",
 		"searches",
 		"options",
+		"service_hosts",
 	}
 
 	if err := checkHCLKeys(dns.Val, valid); err != nil {
@@ -38,6 +38,7 @@ var (
 		"affinity",
 		"dispatch_payload",
 		"identity",
+		"kill_escalation",
 		"lifecycle",
 		"leader",
 		"restart",
@@ -110,6 +111,7 @@ func parseTask(item *ast.ObjectItem, keys []string) (*api.Task, error) {
 	delete(m, "lifecycle")
 	delete(m, "env")
 	delete(m, "identity")
+	delete(m, "kill_escalation")
 	delete(m, "logs")
 	delete(m, "meta")
 	delete(m, "resources")
@@ -298,6 +300,13 @@ func parseTask(item *ast.ObjectItem, keys []string) (*api.Task, error) {
 		t.Identity = v
 	}
 
+	// Parse kill escalation
+	if o := listVal.Filter("kill_escalation"); len(o.Items) > 0 {
+		if err := parseKillEscalation(&t.KillEscalation, o); err != nil {
+			return nil, multierror.Prefix(err, "kill_escalation ->")
+		}
+	}
+
 	// Parse templates
 	if o := listVal.Filter("template"); len(o.Items) > 0 {
 		if err := parseTemplates(&t.Templates, o); err != nil {
@@ -430,6 +439,41 @@ func parseArtifacts(result *[]*api.TaskArtifact, list *ast.ObjectList) error {
 	return nil
 }
 
+func parseKillEscalation(result *[]*api.KillEscalationStep, list *ast.ObjectList) error {
+	for _, o := range list.Elem().Items {
+		// Check for invalid keys
+		valid := []string{
+			"signal",
+			"timeout",
+		}
+		if err := checkHCLKeys(o.Val, valid); err != nil {
+			return err
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Val); err != nil {
+			return err
+		}
+
+		var step api.KillEscalationStep
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &step,
+		})
+		if err != nil {
+			return err
+		}
+		if err := dec.Decode(m); err != nil {
+			return err
+		}
+
+		*result = append(*result, &step)
+	}
+
+	return nil
+}
+
 func parseArtifactOption(result map[string]string, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -584,6 +584,22 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"active-schedule.hcl",
+			&api.Job{
+				ID:   stringToPtr("foo"),
+				Name: stringToPtr("foo"),
+				ActiveSchedule: &api.JobActiveSchedule{
+					TimeZone: stringToPtr("Europe/Paris"),
+					Windows: []*api.JobActiveWindow{
+						{Cron: "0 8 * * 1-5", Duration: 10 * time.Hour},
+						{Cron: "0 10 * * 6", Duration: 4 * time.Hour},
+					},
+				},
+			},
+			false,
+		},
+
 		{
 			"specify-job.hcl",
 			&api.Job{
@@ -0,0 +1,18 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+job "foo" {
+  active_schedule {
+    time_zone = "Europe/Paris"
+
+    window {
+      cron     = "0 8 * * 1-5"
+      duration = "10h"
+    }
+
+    window {
+      cron     = "0 10 * * 6"
+      duration = "4h"
+    }
+  }
+}
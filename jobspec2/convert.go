@@ -0,0 +1,819 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jobspec2
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl/hcl/ast"
+	hcl1parser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/nomad/api"
+)
+
+// ConvertHCL1 converts an HCL1 jobspec to HCL2. The structure of the jobspec
+// and its comments are preserved where possible, and the values are escaped
+// so they're decoded as they were by the HCL1 parser.
+func ConvertHCL1(src []byte) ([]byte, error) {
+	file, err := hcl1parser.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("error parsing: file doesn't contain a root object")
+	}
+
+	c := &hcl1Converter{
+		comments: file.Comments,
+		used:     map[*ast.CommentGroup]bool{},
+	}
+	c.body(list.Items, rootSchema)
+	c.flushComments(token.Pos{Offset: len(src) + 1})
+	return hclwrite.Format(c.w.Bytes()), nil
+}
+
+// EncodeJob encodes the job as an HCL2 jobspec, such as to convert a JSON
+// jobspec to HCL2. Only the fields set in the job are encoded.
+func EncodeJob(job *api.Job) ([]byte, error) {
+	if job == nil || job.ID == nil || *job.ID == "" {
+		return nil, fmt.Errorf("job ID is required")
+	}
+
+	var w hclWriter
+	w.openBlock("job", *job.ID)
+	encodeBody(&w, reflect.ValueOf(job).Elem(), "id")
+	w.closeBlock()
+	return hclwrite.Format(w.Bytes()), nil
+}
+
+// JobDiff returns the paths of the fields which differ between the job parsed
+// from the original jobspec and the job parsed from its conversion, to verify
+// the conversion is faithful. Both jobs are canonicalized, and the defaults the
+// HCL1 parser sets differently than the HCL2 parser are ignored.
+func JobDiff(original, converted *api.Job) []string {
+	original.Canonicalize()
+	converted.Canonicalize()
+	for _, job := range []*api.Job{original, converted} {
+		normalizeParserDefaults(job)
+	}
+
+	var r diffReporter
+	cmp.Equal(original, converted, cmpopts.EquateEmpty(), cmp.Reporter(&r))
+	return r.paths
+}
+
+// normalizeParserDefaults clears the template owners the HCL1 parser defaults
+// to -1, and the value of the distinct_hosts constraints which the HCL2 parser
+// sets to true.
+func normalizeParserDefaults(job *api.Job) {
+	normalizeConstraints(job.Constraints)
+	for _, tg := range job.TaskGroups {
+		normalizeConstraints(tg.Constraints)
+		for _, task := range tg.Tasks {
+			normalizeConstraints(task.Constraints)
+			for _, tmpl := range task.Templates {
+				if tmpl.Uid != nil && *tmpl.Uid == -1 {
+					tmpl.Uid = nil
+				}
+				if tmpl.Gid != nil && *tmpl.Gid == -1 {
+					tmpl.Gid = nil
+				}
+			}
+		}
+	}
+}
+
+func normalizeConstraints(constraints []*api.Constraint) {
+	for _, c := range constraints {
+		if c.Operand == api.ConstraintDistinctHosts && c.RTarget == "true" {
+			c.RTarget = ""
+		}
+	}
+}
+
+// diffReporter is a cmp.Reporter collecting the paths of the differences.
+type diffReporter struct {
+	path  cmp.Path
+	paths []string
+}
+
+func (r *diffReporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *diffReporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	var b strings.Builder
+	for _, ps := range r.path {
+		switch ps.(type) {
+		case cmp.StructField, cmp.SliceIndex, cmp.MapIndex:
+			b.WriteString(ps.String())
+		}
+	}
+	r.paths = append(r.paths, strings.TrimPrefix(b.String(), "."))
+}
+
+func (r *diffReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// hclSchema is the schema of a body, derived from the hcl tags of the api
+// struct it's decoded into. A nil type is a body without schema, such as the
+// config of a task, and a map type is a body of arbitrary attributes, such as
+// meta.
+type hclSchema struct {
+	t reflect.Type
+}
+
+var rootSchema = hclSchema{t: reflect.TypeOf(struct {
+	Job *api.Job `hcl:"job,block"`
+}{})}
+
+// field returns the schema of the block or attribute named name, and whether
+// it's a block. ok is false if the schema doesn't define it.
+func (s hclSchema) field(name string) (child hclSchema, block, ok bool) {
+	if s.t == nil {
+		return hclSchema{}, false, false
+	}
+	if s.t.Kind() == reflect.Map {
+		return hclSchema{}, false, true
+	}
+
+	for i := 0; i < s.t.NumField(); i++ {
+		f := s.t.Field(i)
+		tagName, kind := parseHCLTag(f)
+		if tagName != name {
+			continue
+		}
+		if kind != "block" {
+			return hclSchema{}, false, true
+		}
+
+		t := f.Type
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Map {
+			if t.Elem().Kind() == reflect.Interface {
+				return hclSchema{}, true, true
+			}
+			if t.Elem().Kind() != reflect.String {
+				t = t.Elem()
+				for t.Kind() == reflect.Pointer {
+					t = t.Elem()
+				}
+			}
+		}
+		if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			return hclSchema{}, true, true
+		}
+		return hclSchema{t: t}, true, true
+	}
+	return hclSchema{}, false, false
+}
+
+// parseHCLTag returns the name and kind of the hcl tag of the field, such as
+// "block" or "optional".
+func parseHCLTag(f reflect.StructField) (string, string) {
+	tag, ok := f.Tag.Lookup("hcl")
+	if !ok {
+		return "", ""
+	}
+	name, kind, _ := strings.Cut(tag, ",")
+	return name, kind
+}
+
+// hcl1Converter converts the items of an HCL1 AST to HCL2.
+type hcl1Converter struct {
+	w hclWriter
+
+	// comments are all the comments of the file, in order, of which the ones
+	// not attached to an item are written before the next item.
+	comments []*ast.CommentGroup
+	next     int
+	used     map[*ast.CommentGroup]bool
+
+	// lastLine is the last line of the source written, to preserve the blank
+	// lines between items.
+	lastLine int
+}
+
+// body writes the items of a body with the given schema.
+func (c *hcl1Converter) body(items []*ast.ObjectItem, schema hclSchema) {
+	for _, item := range items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+		c.markUsed(item.LeadComment)
+		c.markUsed(item.LineComment)
+		c.flushComments(item.Pos())
+
+		start := item.Pos().Line
+		if item.LeadComment != nil {
+			start = item.LeadComment.Pos().Line
+		}
+		if c.w.Len() > 0 && start > c.lastLine+1 {
+			c.w.blankLine()
+		}
+
+		if item.LeadComment != nil {
+			c.lastLine = start
+			c.writeComments(item.LeadComment)
+		}
+		c.item(item, schema)
+		c.lastLine = endLine(item.Val)
+	}
+}
+
+// item writes an item as a block or an attribute, depending on its value and
+// the schema.
+func (c *hcl1Converter) item(item *ast.ObjectItem, schema hclSchema) {
+	name := keyValue(item.Keys[0])
+	labels := make([]string, 0, len(item.Keys)-1)
+	for _, key := range item.Keys[1:] {
+		labels = append(labels, keyValue(key))
+	}
+	child, block, known := schema.field(name)
+	asBlock := block || !known
+	lineComment := commentText(item.LineComment)
+
+	switch val := item.Val.(type) {
+	case *ast.ObjectType:
+		if (asBlock && !hasInvalidKeys(val.List.Items)) || len(labels) > 0 {
+			c.w.openBlock(name, labels...)
+			c.w.appendComment(lineComment)
+			c.lastLine = val.Lbrace.Line
+			c.body(val.List.Items, child)
+			c.flushComments(val.Rbrace)
+			c.w.closeBlock()
+			return
+		}
+	case *ast.ListType:
+		if asBlock && len(val.List) > 0 && allObjects(val.List) {
+			for _, elem := range val.List {
+				obj := elem.(*ast.ObjectType)
+				c.w.openBlock(name)
+				c.lastLine = obj.Lbrace.Line
+				c.body(obj.List.Items, child)
+				c.flushComments(obj.Rbrace)
+				c.w.closeBlock()
+			}
+			c.w.appendComment(lineComment)
+			return
+		}
+	}
+
+	c.w.attr(name, hcl1Expr(item.Val))
+	c.w.appendComment(lineComment)
+}
+
+// flushComments writes the comments before pos which aren't attached to an
+// item.
+func (c *hcl1Converter) flushComments(pos token.Pos) {
+	for ; c.next < len(c.comments); c.next++ {
+		group := c.comments[c.next]
+		if group.Pos().Offset >= pos.Offset {
+			return
+		}
+		if c.used[group] {
+			continue
+		}
+		c.used[group] = true
+
+		c.writeComments(group)
+	}
+}
+
+// writeComments writes the comments of the group, preserving the blank lines
+// before them.
+func (c *hcl1Converter) writeComments(group *ast.CommentGroup) {
+	for _, comment := range group.List {
+		if c.w.Len() > 0 && comment.Start.Line > c.lastLine+1 {
+			c.w.blankLine()
+		}
+		c.w.comment(comment.Text)
+		c.lastLine = comment.Start.Line + strings.Count(comment.Text, "\n")
+	}
+}
+
+func (c *hcl1Converter) markUsed(group *ast.CommentGroup) {
+	if group != nil {
+		c.used[group] = true
+	}
+}
+
+// hcl1Expr returns the HCL2 expression of an HCL1 value.
+func hcl1Expr(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		switch n.Token.Type {
+		case token.STRING:
+			return quoteString(n.Token.Value().(string))
+		case token.HEREDOC:
+			marker, _, _ := strings.Cut(strings.TrimLeft(n.Token.Text[2:], "-"), "\n")
+			return heredoc(strings.TrimSpace(marker), n.Token.Value().(string))
+		case token.NUMBER:
+			return strconv.FormatInt(n.Token.Value().(int64), 10)
+		default:
+			return n.Token.Text
+		}
+	case *ast.ListType:
+		elems := make([]string, 0, len(n.List))
+		multiline := false
+		for _, elem := range n.List {
+			expr := hcl1Expr(elem)
+			multiline = multiline || strings.Contains(expr, "\n")
+			elems = append(elems, expr)
+		}
+		return listExpr(elems, multiline)
+	case *ast.ObjectType:
+		keys := make([]string, 0, len(n.List.Items))
+		values := make([]string, 0, len(n.List.Items))
+		for _, item := range n.List.Items {
+			if len(item.Keys) == 0 {
+				continue
+			}
+			// Nested keys of HCL1 objects, such as a "b" {}, are nested
+			// objects
+			val := hcl1Expr(item.Val)
+			for i := len(item.Keys) - 1; i > 0; i-- {
+				val = objectExpr([]string{keyValue(item.Keys[i])}, []string{val})
+			}
+			keys = append(keys, keyValue(item.Keys[0]))
+			values = append(values, val)
+		}
+		return objectExpr(keys, values)
+	default:
+		return "null"
+	}
+}
+
+// endLine returns the last line of an HCL1 value in its source.
+func endLine(node ast.Node) int {
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		return n.Token.Pos.Line + strings.Count(n.Token.Text, "\n")
+	case *ast.ListType:
+		return n.Rbrack.Line
+	case *ast.ObjectType:
+		return n.Rbrace.Line
+	default:
+		return node.Pos().Line
+	}
+}
+
+func keyValue(key *ast.ObjectKey) string {
+	if key.Token.Type == token.STRING {
+		return key.Token.Value().(string)
+	}
+	return key.Token.Text
+}
+
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	texts := make([]string, 0, len(group.List))
+	for _, comment := range group.List {
+		texts = append(texts, comment.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+func allObjects(nodes []ast.Node) bool {
+	for _, node := range nodes {
+		if _, ok := node.(*ast.ObjectType); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hasInvalidKeys returns true if any of the items has a key that isn't a valid
+// HCL2 identifier, so the body can only be written as an object.
+func hasInvalidKeys(items []*ast.ObjectItem) bool {
+	for _, item := range items {
+		if len(item.Keys) > 0 && !validIdentifier(keyValue(item.Keys[0])) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockAliases are the blocks decoded from blocks of another name, such as
+// the reserved ports which are port blocks with a static port.
+var blockAliases = map[string]string{
+	"reserved_ports": "port",
+}
+
+// encodeBody writes the fields of the api struct v which have an hcl tag,
+// except the labels and the skipped fields.
+func encodeBody(w *hclWriter, v reflect.Value, skip ...string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, kind := parseHCLTag(t.Field(i))
+		if name == "" || name == "-" || kind == "label" || kind == "remain" || contains(skip, name) {
+			continue
+		}
+
+		fv := v.Field(i)
+		if kind == "block" {
+			if alias, ok := blockAliases[name]; ok {
+				name = alias
+			}
+			encodeBlocks(w, name, fv)
+			continue
+		}
+		if isUnset(fv) {
+			continue
+		}
+		w.attr(name, goExpr(fv))
+	}
+}
+
+// encodeBlocks writes the blocks of a field, which may be a struct, a slice
+// or map of structs, or a map of arbitrary values such as meta or the config
+// of a task.
+func encodeBlocks(w *hclWriter, name string, v reflect.Value) {
+	if isUnset(v) {
+		return
+	}
+	v = indirect(v)
+
+	switch v.Kind() {
+	case reflect.Struct:
+		w.openBlock(name, structLabels(v)...)
+		encodeBody(w, v)
+		w.closeBlock()
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			encodeBlocks(w, name, v.Index(i))
+		}
+	case reflect.Map:
+		keys := sortedKeys(v)
+		elemType := v.Type().Elem()
+		for elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct {
+			for _, key := range keys {
+				if !validIdentifier(key) {
+					w.attr(name, goExpr(v))
+					return
+				}
+			}
+			w.openBlock(name)
+			for _, key := range keys {
+				encodeArbitrary(w, key, v.MapIndex(reflect.ValueOf(key)))
+			}
+			w.closeBlock()
+			return
+		}
+		for _, key := range keys {
+			elem := indirect(v.MapIndex(reflect.ValueOf(key)))
+			if !elem.IsValid() {
+				continue
+			}
+			w.openBlock(name, key)
+			encodeBody(w, elem)
+			w.closeBlock()
+		}
+	}
+}
+
+// encodeArbitrary writes a value of a body without schema. Lists of objects
+// are written as blocks, so they're decoded as they were by the HCL1 parser.
+func encodeArbitrary(w *hclWriter, name string, v reflect.Value) {
+	v = indirect(v)
+	if v.Kind() == reflect.Slice && v.Len() > 0 {
+		maps := true
+		for i := 0; i < v.Len(); i++ {
+			elem := indirect(v.Index(i))
+			if elem.Kind() != reflect.Map || hasInvalidMapKeys(elem) {
+				maps = false
+				break
+			}
+		}
+		if maps {
+			for i := 0; i < v.Len(); i++ {
+				elem := indirect(v.Index(i))
+				w.openBlock(name)
+				for _, key := range sortedKeys(elem) {
+					encodeArbitrary(w, key, elem.MapIndex(reflect.ValueOf(key)))
+				}
+				w.closeBlock()
+			}
+			return
+		}
+	}
+	w.attr(name, goExpr(v))
+}
+
+// structLabels returns the values of the label fields of the api struct. The
+// scaling policies of tasks are labelled by the resource they scale.
+func structLabels(v reflect.Value) []string {
+	if v.Type() == scalingPolicyType {
+		if resource, ok := strings.CutPrefix(v.FieldByName("Type").String(), "vertical_"); ok {
+			return []string{resource}
+		}
+	}
+
+	var labels []string
+	for i := 0; i < v.NumField(); i++ {
+		if _, kind := parseHCLTag(v.Type().Field(i)); kind == "label" {
+			if label := indirect(v.Field(i)); label.Kind() == reflect.String {
+				labels = append(labels, label.String())
+			}
+		}
+	}
+	return labels
+}
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	scalingPolicyType = reflect.TypeOf(api.ScalingPolicy{})
+)
+
+// goExpr returns the HCL2 expression of a Go value.
+func goExpr(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return "null"
+	}
+	if v.Type() == durationType {
+		return quoteString(formatDuration(time.Duration(v.Int())))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if s := v.String(); strings.Contains(strings.TrimSuffix(s, "\n"), "\n") && strings.HasSuffix(s, "\n") {
+			return heredoc("EOF", s)
+		}
+		return quoteString(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice, reflect.Array:
+		elems := make([]string, 0, v.Len())
+		multiline := false
+		for i := 0; i < v.Len(); i++ {
+			expr := goExpr(v.Index(i))
+			multiline = multiline || strings.Contains(expr, "\n")
+			elems = append(elems, expr)
+		}
+		return listExpr(elems, multiline)
+	case reflect.Map:
+		keys := sortedKeys(v)
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, goExpr(v.MapIndex(reflect.ValueOf(key))))
+		}
+		return objectExpr(keys, values)
+	case reflect.Struct:
+		var w hclWriter
+		encodeBody(&w, v)
+		return "{\n" + w.String() + "}"
+	default:
+		return "null"
+	}
+}
+
+// isUnset returns true if the field isn't set: nil, or the zero value of a
+// field that isn't a pointer.
+func isUnset(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func sortedKeys(v reflect.Value) []string {
+	keys := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hasInvalidMapKeys(v reflect.Value) bool {
+	for _, key := range v.MapKeys() {
+		if !validIdentifier(key.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDuration formats a duration without its zero units, such as "1h"
+// rather than "1h0m0s".
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+var (
+	identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+	// runtimeVariableRe matches the interpolations of runtime variables, such
+	// as ${attr.kernel.name}, which the HCL2 parser leaves to be interpolated
+	// by the client.
+	runtimeVariableRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*(\.[A-Za-z_][A-Za-z0-9_-]*)*$`)
+)
+
+func validIdentifier(s string) bool {
+	return identifierRe.MatchString(s)
+}
+
+// escapeTemplate escapes the template sequences of s, so it's decoded as is by
+// the HCL2 parser. The interpolations of runtime variables are kept as is
+// since the HCL2 parser leaves them to the client, like the HCL1 parser.
+func escapeTemplate(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if i+1 < len(s) && s[i+1] == '{' {
+			switch s[i] {
+			case '%':
+				b.WriteString("%%{")
+				i++
+				continue
+			case '$':
+				end := strings.IndexByte(s[i:], '}')
+				if end != -1 {
+					name := s[i+2 : i+end]
+					root, _, _ := strings.Cut(name, ".")
+					if runtimeVariableRe.MatchString(name) && root != inputVariablesAccessor && root != localsAccessor {
+						b.WriteString(s[i : i+end+1])
+						i += end
+						continue
+					}
+				}
+				b.WriteString("$${")
+				i++
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// quoteString returns s as a quoted HCL2 string.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range escapeTemplate(s) {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// heredoc returns s as an HCL2 heredoc, or as a quoted string if it can't be
+// written as one.
+func heredoc(marker, s string) string {
+	if marker == "" || !strings.HasSuffix(s, "\n") || strings.ContainsAny(s, "\r") {
+		return quoteString(s)
+	}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == marker {
+			return quoteString(s)
+		}
+	}
+	return "<<" + marker + "\n" + escapeTemplate(s) + marker
+}
+
+func listExpr(elems []string, multiline bool) string {
+	if !multiline {
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return "[\n" + strings.Join(elems, ",\n") + ",\n]"
+}
+
+func objectExpr(keys, values []string) string {
+	if len(keys) == 0 {
+		return "{}"
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, key := range keys {
+		if !validIdentifier(key) {
+			key = quoteString(key)
+		}
+		fmt.Fprintf(&b, "%s = %s\n", key, values[i])
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// hclWriter writes HCL2 source, which is formatted once complete.
+type hclWriter struct {
+	bytes.Buffer
+}
+
+func (w *hclWriter) openBlock(name string, labels ...string) {
+	w.WriteString(name)
+	for _, label := range labels {
+		w.WriteString(" ")
+		w.WriteString(quoteString(label))
+	}
+	w.WriteString(" {\n")
+}
+
+func (w *hclWriter) closeBlock() {
+	w.WriteString("}\n")
+}
+
+func (w *hclWriter) attr(name, expr string) {
+	if !validIdentifier(name) {
+		name = quoteString(name)
+	}
+	fmt.Fprintf(w, "%s = %s\n", name, expr)
+}
+
+func (w *hclWriter) comment(text string) {
+	w.WriteString(text)
+	w.WriteString("\n")
+}
+
+// appendComment appends a comment to the last line written.
+func (w *hclWriter) appendComment(text string) {
+	if text == "" {
+		return
+	}
+	w.Truncate(w.Len() - 1)
+	if strings.HasSuffix(w.String(), "\n") {
+		w.WriteString(text)
+	} else {
+		w.WriteString(" " + text)
+	}
+	w.WriteString("\n")
+}
+
+func (w *hclWriter) blankLine() {
+	w.WriteString("\n")
+}
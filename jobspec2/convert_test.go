@@ -0,0 +1,246 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jobspec2
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/jobspec"
+	"github.com/shoenig/test/must"
+)
+
+func TestConvertHCL1(t *testing.T) {
+	ci.Parallel(t)
+
+	src := `# Leading comment
+
+job "example" {
+  datacenters = ["dc1"] # inline
+
+  // group comment
+  group "cache" {
+    meta {
+      "my.key" = "v"
+    }
+
+    task "redis" {
+      driver = "docker"
+
+      config {
+        image = "redis:${attr.kernel.name}"
+        args  = ["%{x}", "${var.nope}"]
+
+        port_map {
+          db = 6379
+        }
+      }
+
+      template {
+        data = <<EOH
+{{ env "NOMAD_ALLOC_ID" }} ${node.unique.id}
+EOH
+        destination = "local/x"
+      }
+      # dangling comment
+    }
+  }
+}
+`
+
+	out, err := ConvertHCL1([]byte(src))
+	must.NoError(t, err)
+
+	expected := `# Leading comment
+
+job "example" {
+  datacenters = ["dc1"] # inline
+
+  // group comment
+  group "cache" {
+    meta = {
+      "my.key" = "v"
+    }
+
+    task "redis" {
+      driver = "docker"
+
+      config {
+        image = "redis:${attr.kernel.name}"
+        args  = ["%%{x}", "$${var.nope}"]
+
+        port_map {
+          db = 6379
+        }
+      }
+
+      template {
+        data        = <<EOH
+{{ env "NOMAD_ALLOC_ID" }} ${node.unique.id}
+EOH
+        destination = "local/x"
+      }
+      # dangling comment
+    }
+  }
+}
+`
+	must.Eq(t, expected, string(out))
+
+	job1, err := jobspec.Parse(strings.NewReader(src))
+	must.NoError(t, err)
+	job2, err := ParseWithConfig(&ParseConfig{Path: "input.hcl", Body: out})
+	must.NoError(t, err)
+	must.Eq(t, job1.TaskGroups[0].Meta, job2.TaskGroups[0].Meta)
+	must.Eq(t, job1.TaskGroups[0].Tasks[0].Config, job2.TaskGroups[0].Tasks[0].Config)
+	must.Eq(t, *job1.TaskGroups[0].Tasks[0].Templates[0].EmbeddedTmpl, *job2.TaskGroups[0].Tasks[0].Templates[0].EmbeddedTmpl)
+}
+
+func TestConvertHCL1_Fixtures(t *testing.T) {
+	ci.Parallel(t)
+
+	hclSpecDir := "../jobspec/test-fixtures/"
+	fis, err := os.ReadDir(hclSpecDir)
+	must.NoError(t, err)
+
+	for _, fi := range fis {
+		name := fi.Name()
+
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(hclSpecDir + name)
+			must.NoError(t, err)
+
+			job1, err := jobspec.Parse(bytes.NewReader(src))
+			if err != nil {
+				t.Skip("file is not parsable in v1")
+			}
+
+			out, err := ConvertHCL1(src)
+			must.NoError(t, err)
+			job2, err := ParseWithConfig(&ParseConfig{Path: name, Body: out})
+			must.NoError(t, err)
+
+			must.SliceEmpty(t, JobDiff(job1, job2))
+		})
+	}
+}
+
+func TestEncodeJob(t *testing.T) {
+	ci.Parallel(t)
+
+	job := &api.Job{
+		ID:          pointer.Of("example"),
+		Datacenters: []string{"dc1"},
+		Meta:        map[string]string{"team": "infra", "owner.email": "ops@example.com"},
+		Update: &api.UpdateStrategy{
+			MinHealthyTime: pointer.Of(time.Hour),
+		},
+		TaskGroups: []*api.TaskGroup{{
+			Name:  pointer.Of("cache"),
+			Count: pointer.Of(2),
+			Networks: []*api.NetworkResource{{
+				ReservedPorts: []api.Port{{Label: "db", Value: 6379}},
+			}},
+			Tasks: []*api.Task{{
+				Name:   "redis",
+				Driver: "docker",
+				Config: map[string]interface{}{
+					"image":    "redis:${attr.kernel.name}",
+					"port_map": []map[string]interface{}{{"db": 6379}},
+				},
+				Templates: []*api.Template{{
+					EmbeddedTmpl: pointer.Of("line 1\n${var.x} %{ y }\n"),
+					DestPath:     pointer.Of("local/x"),
+				}},
+				ScalingPolicies: []*api.ScalingPolicy{{
+					Type: "vertical_cpu",
+					Max:  pointer.Of(int64(500)),
+				}},
+			}},
+		}},
+	}
+
+	out, err := EncodeJob(job)
+	must.NoError(t, err)
+
+	parsed, err := ParseWithConfig(&ParseConfig{Path: "input.hcl", Body: out})
+	must.NoError(t, err)
+	must.Eq(t, *job.ID, *parsed.ID)
+	must.Eq(t, job.Datacenters, parsed.Datacenters)
+	must.Eq(t, job.Meta, parsed.Meta)
+	must.Eq(t, *job.Update.MinHealthyTime, *parsed.Update.MinHealthyTime)
+
+	tg := parsed.TaskGroups[0]
+	must.Eq(t, 2, *tg.Count)
+	must.Eq(t, job.TaskGroups[0].Networks, tg.Networks)
+	must.Eq(t, "docker", tg.Tasks[0].Driver)
+	must.Eq(t, "redis:${attr.kernel.name}", tg.Tasks[0].Config["image"])
+	must.Eq(t, *job.TaskGroups[0].Tasks[0].Templates[0].EmbeddedTmpl, *tg.Tasks[0].Templates[0].EmbeddedTmpl)
+	must.Eq(t, "vertical_cpu", tg.Tasks[0].ScalingPolicies[0].Type)
+	must.Eq(t, 500, *tg.Tasks[0].ScalingPolicies[0].Max)
+}
+
+func TestEncodeJob_Fixtures(t *testing.T) {
+	ci.Parallel(t)
+
+	hclSpecDir := "../jobspec/test-fixtures/"
+	fis, err := os.ReadDir(hclSpecDir)
+	must.NoError(t, err)
+
+	for _, fi := range fis {
+		name := fi.Name()
+
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(hclSpecDir + name)
+			must.NoError(t, err)
+
+			job1, err := jobspec.Parse(bytes.NewReader(src))
+			if err != nil {
+				t.Skip("file is not parsable in v1")
+			}
+
+			out, err := EncodeJob(job1)
+			must.NoError(t, err)
+			job2, err := ParseWithConfig(&ParseConfig{Path: name, Body: out})
+			must.NoError(t, err)
+
+			must.SliceEmpty(t, JobDiff(job1, job2))
+		})
+	}
+}
+
+func TestJobDiff(t *testing.T) {
+	ci.Parallel(t)
+
+	job1 := &api.Job{
+		ID:          pointer.Of("example"),
+		Datacenters: []string{"dc1"},
+		TaskGroups: []*api.TaskGroup{{
+			Name:  pointer.Of("cache"),
+			Count: pointer.Of(2),
+		}},
+	}
+	job2 := &api.Job{
+		ID:          pointer.Of("example"),
+		Datacenters: []string{"dc1"},
+		TaskGroups: []*api.TaskGroup{{
+			Name:  pointer.Of("cache"),
+			Count: pointer.Of(3),
+		}},
+	}
+	must.Eq(t, []string{"TaskGroups[0].Count"}, JobDiff(job1, job2))
+}
+
+func TestEncodeJob_MissingID(t *testing.T) {
+	ci.Parallel(t)
+
+	_, err := EncodeJob(&api.Job{Name: pointer.Of("example")})
+	must.EqError(t, err, "job ID is required")
+}
@@ -98,6 +98,7 @@ var affinitySpec = hcldec.ObjectSpec{
 	api.ConstraintSetContains:    &hcldec.AttrSpec{Name: api.ConstraintSetContains, Type: cty.String, Required: false},
 	api.ConstraintSetContainsAll: &hcldec.AttrSpec{Name: api.ConstraintSetContainsAll, Type: cty.String, Required: false},
 	api.ConstraintSetContainsAny: &hcldec.AttrSpec{Name: api.ConstraintSetContainsAny, Type: cty.String, Required: false},
+	api.ConstraintCIDRContains:   &hcldec.AttrSpec{Name: api.ConstraintCIDRContains, Type: cty.String, Required: false},
 }
 
 func decodeAffinity(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.Diagnostics {
@@ -164,6 +165,13 @@ func decodeAffinity(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.Di
 		a.RTarget = affinity
 	}
 
+	// If "cidr_contains" is provided, set the operand
+	// to "cidr_contains" and the value to the "RTarget"
+	if affinity := attr(api.ConstraintCIDRContains); affinity != "" {
+		a.Operand = api.ConstraintCIDRContains
+		a.RTarget = affinity
+	}
+
 	if a.Operand == "" {
 		a.Operand = "="
 	}
@@ -183,6 +191,7 @@ var constraintSpec = hcldec.ObjectSpec{
 	api.ConstraintSetContains:       &hcldec.AttrSpec{Name: api.ConstraintSetContains, Type: cty.String, Required: false},
 	api.ConstraintSetContainsAll:    &hcldec.AttrSpec{Name: api.ConstraintSetContainsAll, Type: cty.String, Required: false},
 	api.ConstraintSetContainsAny:    &hcldec.AttrSpec{Name: api.ConstraintSetContainsAny, Type: cty.String, Required: false},
+	api.ConstraintCIDRContains:      &hcldec.AttrSpec{Name: api.ConstraintCIDRContains, Type: cty.String, Required: false},
 	api.ConstraintAttributeIsSet:    &hcldec.AttrSpec{Name: api.ConstraintAttributeIsSet, Type: cty.String, Required: false},
 	api.ConstraintAttributeIsNotSet: &hcldec.AttrSpec{Name: api.ConstraintAttributeIsNotSet, Type: cty.String, Required: false},
 }
@@ -235,6 +244,13 @@ func decodeConstraint(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.
 		c.RTarget = constraint
 	}
 
+	// If "cidr_contains" is provided, set the operand
+	// to "cidr_contains" and the value to the "RTarget"
+	if constraint := attr(api.ConstraintCIDRContains); constraint != "" {
+		c.Operand = api.ConstraintCIDRContains
+		c.RTarget = constraint
+	}
+
 	// The shortcut form of the distinct_hosts constraint is a cty.Bool
 	// so it can not use the `attr` func defined earlier
 	if d := v.GetAttr(api.ConstraintDistinctHosts); !d.IsNull() {
@@ -258,6 +274,9 @@ func decodeTaskGroup(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.D
 
 	var diags hcl.Diagnostics
 
+	// special case env and meta
+	envAttr, body, moreDiags := decodeAsAttribute(body, ctx, "env")
+	diags = append(diags, moreDiags...)
 	metaAttr, body, moreDiags := decodeAsAttribute(body, ctx, "meta")
 	diags = append(diags, moreDiags...)
 
@@ -284,6 +303,9 @@ func decodeTaskGroup(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.D
 	d.RegisterBlockDecoder(reflect.TypeOf(api.Task{}), decodeTask)
 	diags = d.DecodeBody(tgBody, ctx, tg)
 
+	if envAttr != nil {
+		tg.Env = envAttr
+	}
 	if metaAttr != nil {
 		tg.Meta = metaAttr
 	}
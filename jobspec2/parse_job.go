@@ -55,6 +55,10 @@ func normalizeJob(jc *jobConfig) {
 				t.Vault = jc.Vault
 			}
 
+			for _, wid := range t.Identities {
+				normalizeChangeScript(wid.ChangeScript)
+			}
+
 			//COMPAT To preserve compatibility with pre-1.7 agents, move the default
 			//       identity to Task.Identity.
 			defaultIdx := -1
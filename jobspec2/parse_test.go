@@ -953,6 +953,88 @@ func TestParse_Meta_Alternatives(t *testing.T) {
 
 }
 
+func TestParse_Env_Alternatives(t *testing.T) {
+	ci.Parallel(t)
+
+	hcl := ` job "example" {
+  group "group" {
+    task "task" {
+      driver = "config"
+      config {}
+
+      env {
+        source = "task"
+      }
+    }
+
+    env {
+      source = "group"
+    }
+  }
+
+  env {
+    source = "job"
+  }
+}
+`
+
+	asBlock, err := ParseWithConfig(&ParseConfig{
+		Path: "input.hcl",
+		Body: []byte(hcl),
+	})
+	require.NoError(t, err)
+
+	hclAsAttr := strings.ReplaceAll(hcl, "env {", "env = {")
+	require.Equal(t, 3, strings.Count(hclAsAttr, "env = {"))
+
+	asAttr, err := ParseWithConfig(&ParseConfig{
+		Path: "input.hcl",
+		Body: []byte(hclAsAttr),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, asBlock, asAttr)
+	require.Equal(t, map[string]string{"source": "job"}, asBlock.Env)
+	require.Equal(t, map[string]string{"source": "group"}, asBlock.TaskGroups[0].Env)
+	require.Equal(t, map[string]string{"source": "task"}, asBlock.TaskGroups[0].Tasks[0].Env)
+}
+
+func TestParse_CIDRContains(t *testing.T) {
+	ci.Parallel(t)
+
+	hcl := `job "example" {
+  constraint {
+    attribute     = "${attr.unique.network.ip-address}"
+    cidr_contains = "10.0.0.0/8,192.168.0.0/16"
+  }
+
+  affinity {
+    attribute     = "${attr.unique.network.ip-address}"
+    cidr_contains = "10.1.0.0/16"
+    weight        = 50
+  }
+}
+`
+
+	job, err := ParseWithConfig(&ParseConfig{
+		Path: "input.hcl",
+		Body: []byte(hcl),
+	})
+	must.NoError(t, err)
+
+	must.Eq(t, []*api.Constraint{{
+		LTarget: "${attr.unique.network.ip-address}",
+		RTarget: "10.0.0.0/8,192.168.0.0/16",
+		Operand: api.ConstraintCIDRContains,
+	}}, job.Constraints)
+	must.Eq(t, []*api.Affinity{{
+		LTarget: "${attr.unique.network.ip-address}",
+		RTarget: "10.1.0.0/16",
+		Operand: api.ConstraintCIDRContains,
+		Weight:  pointer.Of(int8(50)),
+	}}, job.Affinities)
+}
+
 // TestParse_UndefinedVariables asserts that values with undefined variables are left
 // intact in the job representation
 func TestParse_UndefinedVariables(t *testing.T) {
@@ -1125,3 +1207,66 @@ func TestIdentity(t *testing.T) {
 	must.Eq(t, "foo", job.TaskGroups[0].Tasks[0].Identities[0].Name)
 	must.Eq(t, []string{"bar"}, job.TaskGroups[0].Tasks[0].Identities[0].Audience)
 }
+
+func TestIdentity_ChangeScript(t *testing.T) {
+	ci.Parallel(t)
+	hcl := `
+job "example" {
+  group "group" {
+    task "task" {
+      identity {
+        name        = "vault"
+        aud         = ["vault.io"]
+        ttl         = "1h"
+        change_mode = "script"
+
+        change_script {
+          command = "/local/reauth.sh"
+        }
+      }
+    }
+  }
+}
+`
+	job, err := ParseWithConfig(&ParseConfig{
+		Path:    "input.hcl",
+		Body:    []byte(hcl),
+		AllowFS: false,
+	})
+	must.NoError(t, err)
+
+	wid := job.TaskGroups[0].Tasks[0].Identities[0]
+	must.Eq(t, "script", wid.ChangeMode)
+	must.Eq(t, &api.ChangeScript{
+		Command:     pointer.Of("/local/reauth.sh"),
+		Args:        []string{},
+		Timeout:     pointer.Of(5 * time.Second),
+		FailOnError: pointer.Of(false),
+	}, wid.ChangeScript)
+}
+
+func TestService_Certificate(t *testing.T) {
+	ci.Parallel(t)
+	hcl := `
+job "example" {
+  group "group" {
+    service {
+      name     = "api"
+      provider = "nomad"
+
+      certificate {
+        ttl = "12h"
+      }
+    }
+  }
+}
+`
+	job, err := ParseWithConfig(&ParseConfig{
+		Path:    "input.hcl",
+		Body:    []byte(hcl),
+		AllowFS: false,
+	})
+	must.NoError(t, err)
+
+	must.Eq(t, &api.ServiceCertificate{TTL: 12 * time.Hour}, job.TaskGroups[0].Services[0].Certificate)
+}
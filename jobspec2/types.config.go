@@ -271,6 +271,8 @@ func (c *jobConfig) decodeJob(content *hcl.BodyContent, ctx *hcl.EvalContext) hc
 
 		c.JobID = b.Labels[0]
 
+		envAttr, body, mdiags := decodeAsAttribute(body, ctx, "env")
+		diags = append(diags, mdiags...)
 		metaAttr, body, mdiags := decodeAsAttribute(body, ctx, "meta")
 		diags = append(diags, mdiags...)
 
@@ -285,6 +287,9 @@ func (c *jobConfig) decodeJob(content *hcl.BodyContent, ctx *hcl.EvalContext) hc
 		diags = append(diags, c.decodeTopLevelExtras(extra, ctx)...)
 		diags = append(diags, hclDecoder.DecodeBody(remain, ctx, c.Job)...)
 
+		if envAttr != nil {
+			c.Job.Env = envAttr
+		}
 		if metaAttr != nil {
 			c.Job.Meta = metaAttr
 		}
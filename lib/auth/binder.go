@@ -40,6 +40,23 @@ type Bindings struct {
 	Management bool
 	Roles      []*structs.ACLTokenRoleLink
 	Policies   []string
+
+	// MatchingRules are the binding rules whose selector matched the
+	// identity, in the order they were applied.
+	MatchingRules []*MatchingRule
+}
+
+// MatchingRule is a binding rule whose selector matched the identity.
+type MatchingRule struct {
+	Rule *structs.ACLBindingRule
+
+	// BindName is the bind name of the rule with the identity's claim
+	// mappings interpolated.
+	BindName string
+
+	// Bound indicates the role or policy named by BindName exists and was
+	// added to the bindings. It's always true for management rules.
+	Bound bool
 }
 
 // None indicates that the resulting bindings would not give the created token
@@ -92,6 +109,9 @@ func (b *Binder) Bind(authMethod *structs.ACLAuthMethod, identity *Identity) (*B
 			return nil, fmt.Errorf("computed %q bind name for bind target is invalid: %q", rule.BindType, bindName)
 		}
 
+		match := &MatchingRule{Rule: rule, BindName: bindName}
+		bindings.MatchingRules = append(bindings.MatchingRules, match)
+
 		switch rule.BindType {
 		case structs.ACLBindingRuleBindTypeRole:
 			role, err := b.store.GetACLRoleByName(nil, bindName)
@@ -100,6 +120,7 @@ func (b *Binder) Bind(authMethod *structs.ACLAuthMethod, identity *Identity) (*B
 			}
 
 			if role != nil {
+				match.Bound = true
 				bindings.Roles = append(bindings.Roles, &structs.ACLTokenRoleLink{
 					ID: role.ID,
 				})
@@ -111,9 +132,11 @@ func (b *Binder) Bind(authMethod *structs.ACLAuthMethod, identity *Identity) (*B
 			}
 
 			if policy != nil {
+				match.Bound = true
 				bindings.Policies = append(bindings.Policies, policy.Name)
 			}
 		case structs.ACLBindingRuleBindTypeManagement:
+			match.Bound = true
 			bindings.Management = true
 			bindings.Policies = nil
 			bindings.Roles = nil
@@ -76,7 +76,10 @@ func TestBinder_Bind(t *testing.T) {
 		authMethod *structs.ACLAuthMethod
 		identity   *Identity
 		want       *Bindings
-		wantErr    bool
+		// wantMatches maps the bind names of the matching rules to whether
+		// they're bound.
+		wantMatches map[string]bool
+		wantErr     bool
 	}{
 		{
 			name:       "empty identity",
@@ -97,7 +100,11 @@ func TestBinder_Bind(t *testing.T) {
 					"editor": "vim",
 				},
 			},
-			want:    &Bindings{Roles: []*structs.ACLTokenRoleLink{{ID: targetRole.ID}}},
+			want: &Bindings{Roles: []*structs.ACLTokenRoleLink{{ID: targetRole.ID}}},
+			wantMatches: map[string]bool{
+				"vim-role":                 true,
+				"this-role-does-not-exist": false,
+			},
 			wantErr: false,
 		},
 		{
@@ -109,8 +116,9 @@ func TestBinder_Bind(t *testing.T) {
 				},
 				ClaimMappings: map[string]string{},
 			},
-			want:    &Bindings{Management: true},
-			wantErr: false,
+			want:        &Bindings{Management: true},
+			wantMatches: map[string]bool{"": true},
+			wantErr:     false,
 		},
 	}
 	for _, tt := range tests {
@@ -121,6 +129,18 @@ func TestBinder_Bind(t *testing.T) {
 			} else {
 				must.NoError(t, err)
 			}
+
+			matches := map[string]bool{}
+			for _, match := range got.MatchingRules {
+				matches[match.BindName] = match.Bound
+			}
+			if tt.wantMatches == nil {
+				must.MapEmpty(t, matches)
+			} else {
+				must.Eq(t, tt.wantMatches, matches)
+			}
+
+			got.MatchingRules = nil
 			must.Eq(t, got, tt.want)
 		})
 	}
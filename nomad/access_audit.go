@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+// Auditor receives the audit events of the server. It's implemented by the
+// audit logger of the agent.
+type Auditor interface {
+	// Event emits an event to the auditor.
+	Event(ctx context.Context, eventType string, payload interface{}) error
+
+	// Enabled details if the auditor is enabled or not.
+	Enabled() bool
+}
+
+// accessAuditor records who read or wrote which variables, and which keys of
+// the keyring were used to sign identities, as events of the audit log of the
+// agent. The reads and signatures are sampled, since they are much more
+// frequent than the writes.
+//
+// A nil accessAuditor is valid and records nothing, which is the case when
+// access events aren't enabled.
+type accessAuditor struct {
+	logger     log.Logger
+	sampleRate float64
+
+	// sample returns whether a sampled access is recorded. Settable to ease
+	// testing.
+	sample func(rate float64) bool
+
+	// auditor is set by the agent once its audit logger is set up, and
+	// guarded by lock.
+	auditor Auditor
+	lock    sync.RWMutex
+}
+
+// newAccessAuditor returns the access auditor configured by the agent, or nil
+// if access events aren't enabled.
+func newAccessAuditor(logger log.Logger, cfg *config.AuditAccessConfig) *accessAuditor {
+	if !cfg.IsEnabled() {
+		return nil
+	}
+	return &accessAuditor{
+		logger:     logger.Named("access_audit"),
+		sampleRate: cfg.GetSampleRate(),
+		sample: func(rate float64) bool {
+			return rand.Float64() < rate
+		},
+	}
+}
+
+// setAuditor sets the audit logger the events are emitted to.
+func (a *accessAuditor) setAuditor(auditor Auditor) {
+	if a == nil {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.auditor = auditor
+}
+
+// variable records an operation on a variable. Reads are sampled, writes are
+// always recorded.
+func (a *accessAuditor) variable(op, namespace, path, keyID string, identity *structs.AuthenticatedIdentity) {
+	if a == nil {
+		return
+	}
+
+	rate := 1.0
+	if op == structs.AccessAuditOpRead {
+		rate = a.sampleRate
+	}
+	a.record(structs.AccessAuditEventVariable, rate, func() *structs.AccessAuditEvent {
+		return &structs.AccessAuditEvent{
+			Operation: op,
+			Namespace: namespace,
+			Path:      path,
+			KeyID:     keyID,
+			Actor:     structs.NewAccessAuditActor(identity),
+		}
+	})
+}
+
+// signer returns a claim signer recording a sampled event for each identity
+// signed on behalf of the actor.
+func (a *accessAuditor) signer(signer claimSigner, actor structs.AccessAuditActor) claimSigner {
+	if a == nil {
+		return signer
+	}
+	return &auditedClaimSigner{signer: signer, auditor: a, actor: actor}
+}
+
+// record emits an event to the audit logger, if sampled.
+func (a *accessAuditor) record(eventType string, rate float64, event func() *structs.AccessAuditEvent) {
+	a.lock.RLock()
+	auditor := a.auditor
+	a.lock.RUnlock()
+
+	if auditor == nil || !auditor.Enabled() || !a.sample(rate) {
+		return
+	}
+
+	ev := event()
+	ev.ID = uuid.Generate()
+	ev.Timestamp = time.Now().UTC()
+	ev.SampleRate = rate
+	if err := auditor.Event(context.Background(), eventType, ev); err != nil {
+		a.logger.Warn("failed to record access event", "operation", ev.Operation, "error", err)
+	}
+}
+
+// auditedClaimSigner records the identities signed by a claim signer.
+type auditedClaimSigner struct {
+	signer  claimSigner
+	auditor *accessAuditor
+	actor   structs.AccessAuditActor
+}
+
+func (s *auditedClaimSigner) SignClaims(claims *structs.IdentityClaims) (string, string, error) {
+	token, keyID, err := s.signer.SignClaims(claims)
+	if err != nil {
+		return token, keyID, err
+	}
+
+	s.auditor.record(structs.AccessAuditEventKeyring, s.auditor.sampleRate, func() *structs.AccessAuditEvent {
+		return &structs.AccessAuditEvent{
+			Operation: structs.AccessAuditOpSign,
+			Namespace: claims.Namespace,
+			KeyID:     keyID,
+			AllocID:   claims.AllocationID,
+			Subject:   claims.Subject,
+			Actor:     s.actor,
+		}
+	})
+	return token, keyID, nil
+}
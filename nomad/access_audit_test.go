@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
+)
+
+// testAuditor keeps the events it receives.
+type testAuditor struct {
+	lock   sync.Mutex
+	types  []string
+	events []*structs.AccessAuditEvent
+}
+
+func (a *testAuditor) Event(_ context.Context, eventType string, payload interface{}) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.types = append(a.types, eventType)
+	a.events = append(a.events, payload.(*structs.AccessAuditEvent))
+	return nil
+}
+
+func (a *testAuditor) Enabled() bool { return true }
+
+func (a *testAuditor) recorded() ([]string, []*structs.AccessAuditEvent) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.types, a.events
+}
+
+// testClaimSigner signs the claims with a fixed key ID.
+type testClaimSigner struct {
+	err error
+}
+
+func (s *testClaimSigner) SignClaims(*structs.IdentityClaims) (string, string, error) {
+	if s.err != nil {
+		return "", "", s.err
+	}
+	return "token", "key-1", nil
+}
+
+func TestAccessAuditor_Disabled(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+	must.Nil(t, newAccessAuditor(logger, nil))
+	must.Nil(t, newAccessAuditor(logger, &config.AuditAccessConfig{Enabled: pointer.Of(false)}))
+
+	// a nil auditor records nothing and doesn't wrap the signer
+	var a *accessAuditor
+	a.setAuditor(&testAuditor{})
+	a.variable("set", "default", "foo", "key-1", nil)
+	signer := &testClaimSigner{}
+	must.Eq[claimSigner](t, signer, a.signer(signer, structs.AccessAuditActor{}))
+}
+
+func TestAccessAuditor_Sampling(t *testing.T) {
+	ci.Parallel(t)
+
+	a := newAccessAuditor(testlog.HCLogger(t), &config.AuditAccessConfig{
+		Enabled:    pointer.Of(true),
+		SampleRate: pointer.Of(0.0),
+	})
+	must.NotNil(t, a)
+
+	// nothing is recorded until the agent sets its auditor
+	a.variable("set", "default", "foo", "key-1", nil)
+
+	auditor := &testAuditor{}
+	a.setAuditor(auditor)
+
+	// reads and signatures are sampled, writes are always recorded
+	a.variable(structs.AccessAuditOpRead, "default", "foo", "key-1", nil)
+	_, _, err := a.signer(&testClaimSigner{}, structs.AccessAuditActor{}).SignClaims(&structs.IdentityClaims{})
+	must.NoError(t, err)
+	a.variable(string(structs.VarOpDelete), "default", "foo", "", nil)
+
+	types, events := auditor.recorded()
+	must.Eq(t, []string{structs.AccessAuditEventVariable}, types)
+	must.Eq(t, string(structs.VarOpDelete), events[0].Operation)
+	must.Eq(t, 1.0, events[0].SampleRate)
+
+	// the sampled accesses are recorded with the rate they were sampled at
+	a.sampleRate = 0.25
+	a.sample = func(rate float64) bool { return rate > 0 }
+
+	a.variable(structs.AccessAuditOpRead, "default", "foo", "key-1", nil)
+
+	actor := structs.AccessAuditActor{ClientID: "node-1"}
+	signer := a.signer(&testClaimSigner{}, actor)
+	claims := &structs.IdentityClaims{Namespace: "default", AllocationID: "alloc-1"}
+	claims.Subject = "global:default:example:web:server:default"
+	_, keyID, err := signer.SignClaims(claims)
+	must.NoError(t, err)
+	must.Eq(t, "key-1", keyID)
+
+	// failed signatures aren't recorded
+	_, _, err = a.signer(&testClaimSigner{err: errors.New("no key")}, actor).SignClaims(claims)
+	must.Error(t, err)
+
+	types, events = auditor.recorded()
+	must.Eq(t, []string{
+		structs.AccessAuditEventVariable,
+		structs.AccessAuditEventVariable,
+		structs.AccessAuditEventKeyring,
+	}, types)
+
+	must.Eq(t, structs.AccessAuditOpRead, events[1].Operation)
+	must.Eq(t, "key-1", events[1].KeyID)
+	must.Eq(t, 0.25, events[1].SampleRate)
+
+	sign := events[2]
+	must.Eq(t, structs.AccessAuditOpSign, sign.Operation)
+	must.Eq(t, "key-1", sign.KeyID)
+	must.Eq(t, "alloc-1", sign.AllocID)
+	must.Eq(t, claims.Subject, sign.Subject)
+	must.Eq(t, actor, sign.Actor)
+	must.NotEq(t, "", sign.ID)
+	must.False(t, sign.Timestamp.IsZero())
+}
+
+func TestAccessAuditor_Variables(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.AuditAccess = &config.AuditAccessConfig{Enabled: pointer.Of(true)}
+	})
+	t.Cleanup(cleanupS1)
+	codec := rpcClient(t, s1)
+	testutil.WaitForKeyring(t, s1.RPC, "global")
+
+	auditor := &testAuditor{}
+	s1.SetAuditor(auditor)
+
+	applyReq := &structs.VariablesApplyRequest{
+		Op: structs.VarOpSet,
+		Var: &structs.VariableDecrypted{
+			VariableMetadata: structs.VariableMetadata{Namespace: "default", Path: "secret/db"},
+			Items:            structs.VariableItems{"password": "hunter2"},
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var applyResp structs.VariablesApplyResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, structs.VariablesApplyRPCMethod, applyReq, &applyResp))
+
+	readReq := &structs.VariablesReadRequest{
+		Path: "secret/db",
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: "default",
+		},
+	}
+	var readResp structs.VariablesReadResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, structs.VariablesReadRPCMethod, readReq, &readResp))
+	must.NotNil(t, readResp.Data)
+
+	// reading a missing variable isn't recorded
+	readReq.Path = "secret/missing"
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, structs.VariablesReadRPCMethod, readReq, &readResp))
+	must.Nil(t, readResp.Data)
+
+	stored, err := s1.State().GetVariable(nil, "default", "secret/db")
+	must.NoError(t, err)
+
+	types, events := auditor.recorded()
+	must.Eq(t, []string{structs.AccessAuditEventVariable, structs.AccessAuditEventVariable}, types)
+	for i, op := range []string{string(structs.VarOpSet), structs.AccessAuditOpRead} {
+		must.Eq(t, op, events[i].Operation)
+		must.Eq(t, "default", events[i].Namespace)
+		must.Eq(t, "secret/db", events[i].Path)
+		must.Eq(t, stored.KeyID, events[i].KeyID)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 
 	policy "github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/lib/auth"
 	"github.com/hashicorp/nomad/lib/auth/jwt"
@@ -687,6 +689,12 @@ func (a *ACL) upsertTokens(
 			return structs.NewErrRPCCodedf(http.StatusBadRequest, "token %d invalid: %v", idx, err)
 		}
 
+		// The auth method which created the token can't be modified, so keep
+		// it when the caller doesn't set it.
+		if existingToken != nil {
+			token.AuthMethod = existingToken.AuthMethod
+		}
+
 		var normalizedRoleLinks []*structs.ACLTokenRoleLink
 		uniqueRoleIDs := make(map[string]struct{})
 
@@ -902,6 +910,17 @@ func (a *ACL) ListTokens(args *structs.ACLTokenListRequest, reply *structs.ACLTo
 				return err
 			}
 
+			// Only keep the tokens expiring within the requested duration.
+			if args.ExpiringWithin > 0 {
+				now := time.Now().UTC()
+				iter = memdb.NewFilterIterator(iter, func(raw interface{}) bool {
+					token := raw.(*structs.ACLToken)
+					return !token.HasExpirationTime() ||
+						token.IsExpired(now) ||
+						!token.IsExpired(now.Add(args.ExpiringWithin))
+				})
+			}
+
 			tokenizer := paginator.NewStructsTokenizer(iter, opts)
 
 			var tokens []*structs.ACLTokenListStub
@@ -1132,10 +1151,19 @@ func (a *ACL) UpsertOneTimeToken(args *structs.OneTimeTokenUpsertRequest, reply
 		return structs.ErrPermissionDenied
 	}
 
+	ttl := args.TTL
+	switch {
+	case ttl == 0:
+		ttl = structs.DefaultOneTimeTokenTTL
+	case ttl < 0 || ttl > structs.MaxOneTimeTokenTTL:
+		return structs.NewErrRPCCodedf(http.StatusBadRequest,
+			"one-time token TTL must be positive and at most %s", structs.MaxOneTimeTokenTTL)
+	}
+
 	ott := &structs.OneTimeToken{
 		OneTimeSecretID: uuid.Generate(),
 		AccessorID:      aclToken.AccessorID,
-		ExpiresAt:       time.Now().Add(10 * time.Minute),
+		ExpiresAt:       time.Now().Add(ttl),
 	}
 
 	// Update via Raft
@@ -2182,9 +2210,66 @@ func (a *ACL) WhoAmI(args *structs.GenericRequest, reply *structs.ACLWhoAmIRespo
 	}
 
 	reply.Identity = args.GetIdentity()
+
+	// Resolving the policies and capabilities of the identity is best effort,
+	// since they are only used to describe it.
+	if a.srv.config.ACLEnabled {
+		if aclObj, err := a.srv.ResolveACL(args); err != nil {
+			a.logger.Debug("failed to resolve ACL of identity", "error", err, "id", reply.Identity)
+		} else if aclObj != nil {
+			reply.Capabilities = aclObj.Capabilities()
+		}
+
+		policies, err := a.identityPolicies(reply.Identity)
+		if err != nil {
+			a.logger.Debug("failed to resolve policies of identity", "error", err, "id", reply.Identity)
+		}
+		reply.Policies = policies
+	}
 	return nil
 }
 
+// identityPolicies returns the sorted names of the ACL policies applied to the
+// identity.
+func (a *ACL) identityPolicies(identity *structs.AuthenticatedIdentity) ([]string, error) {
+	if identity == nil {
+		return nil, nil
+	}
+
+	names := set.New[string](0)
+	switch {
+	case identity.ACLToken != nil:
+		names.InsertSlice(identity.ACLToken.Policies)
+		for _, link := range identity.ACLToken.Roles {
+			role, err := a.srv.State().GetACLRoleByID(nil, link.ID)
+			if err != nil {
+				return nil, err
+			}
+			if role == nil {
+				continue
+			}
+			for _, policy := range role.Policies {
+				names.Insert(policy.Name)
+			}
+		}
+	case identity.Claims != nil:
+		policies, err := a.srv.ResolvePoliciesForClaims(identity.Claims)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			names.Insert(policy.Name)
+		}
+	}
+
+	if names.Empty() {
+		return nil, nil
+	}
+	out := names.Slice()
+	slices.Sort(out)
+	return out, nil
+}
+
 // UpsertBindingRules creates or updates ACL binding rules held within Nomad.
 func (a *ACL) UpsertBindingRules(
 	args *structs.ACLBindingRulesUpsertRequest, reply *structs.ACLBindingRulesUpsertResponse) error {
@@ -2536,6 +2621,111 @@ func (a *ACL) GetBindingRule(
 	})
 }
 
+// TestBindingRules evaluates a sample claim set against the claim mappings and
+// binding rules of an auth method, and returns the roles and policies an ACL
+// token created by logging in with these claims would be granted. No token is
+// created, so binding rules can be validated before users rely on them.
+func (a *ACL) TestBindingRules(
+	args *structs.ACLBindingRulesTestRequest, reply *structs.ACLBindingRulesTestResponse) error {
+
+	// Only allow operators to test ACL binding rules when ACLs are enabled.
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+
+	authErr := a.srv.Authenticate(a.ctx, args)
+	if done, err := a.srv.forward(structs.ACLTestBindingRulesRPCMethod, args, args, reply); done {
+		return err
+	}
+	a.srv.MeasureRPCRate("acl", structs.RateMetricRead, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "test_binding_rules"}, time.Now())
+
+	// Check management level permissions.
+	if acl, err := a.srv.ResolveACL(args); err != nil {
+		return err
+	} else if acl == nil || !acl.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	if err := args.Validate(); err != nil {
+		return structs.NewErrRPCCodedf(http.StatusBadRequest, "invalid binding rules test request: %v", err)
+	}
+
+	// Set up and return the blocking query.
+	return a.srv.blockingRPC(&blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, stateStore *state.StateStore) error {
+
+			authMethod, err := stateStore.GetACLAuthMethodByName(ws, args.AuthMethodName)
+			if err != nil {
+				return err
+			}
+			if authMethod == nil {
+				return structs.NewErrRPCCodedf(
+					http.StatusBadRequest,
+					"auth-method %q not found",
+					args.AuthMethodName,
+				)
+			}
+
+			// The claims are copied, since extracting them with the claim
+			// mappings of the auth method modifies them.
+			claims := make(map[string]interface{}, len(args.Claims))
+			for k, v := range args.Claims {
+				claims[k] = v
+			}
+
+			authClaims, err := auth.SelectorData(authMethod, claims, nil)
+			if err != nil {
+				return structs.NewErrRPCCodedf(http.StatusBadRequest, "unable to map claims: %v", err)
+			}
+
+			bindings, err := auth.NewBinder(stateStore).Bind(authMethod, auth.NewIdentity(authMethod.Config, authClaims))
+			if err != nil {
+				return err
+			}
+
+			reply.ClaimMappings = authClaims.Value
+			reply.ListClaimMappings = authClaims.List
+			reply.Management = bindings.Management
+			reply.Roles = bindings.Roles
+			reply.Policies = bindings.Policies
+			reply.MatchingRules = make([]*structs.ACLBindingRuleTestResult, 0, len(bindings.MatchingRules))
+			for _, match := range bindings.MatchingRules {
+				reply.MatchingRules = append(reply.MatchingRules, &structs.ACLBindingRuleTestResult{
+					ID:          match.Rule.ID,
+					Description: match.Rule.Description,
+					BindType:    match.Rule.BindType,
+					BindName:    match.BindName,
+					Bound:       match.Bound,
+				})
+			}
+
+			// Fill in the names of the roles, which the binder doesn't set.
+			for _, link := range reply.Roles {
+				role, err := stateStore.GetACLRoleByID(ws, link.ID)
+				if err != nil {
+					return err
+				}
+				if role != nil {
+					link.Name = role.Name
+				}
+			}
+
+			index, err := stateStore.Index(state.TableACLBindingRules)
+			if err != nil {
+				return err
+			}
+			reply.Index = max(index, authMethod.ModifyIndex)
+			return nil
+		},
+	})
+}
+
 // OIDCAuthURL starts the OIDC login workflow. The response URL should be used
 // by the caller to authenticate the user. Once this has been completed,
 // OIDCCompleteAuth can be used for the remainder of the workflow.
@@ -2773,6 +2963,7 @@ func (a *ACL) OIDCCompleteAuth(
 		Name:          "OIDC-" + authMethod.Name,
 		Global:        authMethod.TokenLocalityIsGlobal(),
 		ExpirationTTL: authMethod.MaxTokenTTL,
+		AuthMethod:    authMethod.Name,
 	}
 
 	if tokenBindings.Management {
@@ -2921,6 +3112,7 @@ func (a *ACL) Login(args *structs.ACLLoginRequest, reply *structs.ACLLoginRespon
 		Name:          "JWT-" + authMethod.Name,
 		Global:        authMethod.TokenLocalityIsGlobal(),
 		ExpirationTTL: authMethod.MaxTokenTTL,
+		AuthMethod:    authMethod.Name,
 	}
 
 	if tokenBindings.Management {
@@ -2952,3 +3144,326 @@ func (a *ACL) Login(args *structs.ACLLoginRequest, reply *structs.ACLLoginRespon
 
 	return nil
 }
+
+// RenewToken RPC renews an ACL token created by a JWT auth method. The caller
+// authenticates again with the auth method which created the token, and the
+// token gets a new expiration time along with the roles and policies the
+// binding rules currently grant. Tokens which already expired can be renewed
+// during the renewal grace period of their auth method.
+func (a *ACL) RenewToken(args *structs.ACLTokenRenewRequest, reply *structs.ACLTokenRenewResponse) error {
+
+	// The renewal flow can only be used when the Nomad cluster has ACL
+	// enabled.
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+
+	if done, err := a.srv.forward(structs.ACLRenewTokenRPCMethod, args, args, reply); done {
+		return err
+	}
+	a.srv.MeasureRPCRate("acl", structs.RateMetricWrite, args)
+	defer metrics.MeasureSince([]string{"nomad", "acl", "renew_token"}, time.Now())
+
+	if err := args.Validate(); err != nil {
+		return structs.NewErrRPCCodedf(http.StatusBadRequest, "invalid renew request: %v", err)
+	}
+
+	stateSnapshot, err := a.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	token, err := stateSnapshot.ACLTokenBySecretID(nil, args.SecretID)
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return structs.ErrTokenNotFound
+	}
+	if token.AuthMethod == "" {
+		return structs.NewErrRPCCoded(http.StatusBadRequest, "token was not created by an auth method")
+	}
+
+	// Global tokens can only be written by the authoritative region.
+	if token.Global {
+		args.Region = a.srv.config.AuthoritativeRegion
+
+		if done, err := a.srv.forward(structs.ACLRenewTokenRPCMethod, args, args, reply); done {
+			return err
+		}
+	}
+
+	authMethod, err := stateSnapshot.GetACLAuthMethodByName(nil, token.AuthMethod)
+	if err != nil {
+		return err
+	}
+	if authMethod == nil {
+		return structs.NewErrRPCCodedf(
+			http.StatusBadRequest,
+			"auth-method %q not found",
+			token.AuthMethod,
+		)
+	}
+	if authMethod.Type != structs.ACLAuthMethodTypeJWT {
+		return structs.NewErrRPCCodedf(
+			http.StatusBadRequest,
+			"unsupported auth-method type: %s",
+			authMethod.Type,
+		)
+	}
+
+	now := time.Now().UTC()
+	if !authMethod.TokenRenewable(token, now) {
+		return structs.NewErrRPCCoded(http.StatusBadRequest,
+			"token is expired and outside of the renewal grace period of its auth method")
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(aclLoginRequestExpiryTime))
+	defer cancel()
+
+	claims, err := jwt.Validate(ctx, args.LoginToken, authMethod.Config)
+	if err != nil {
+		return structs.NewErrRPCCodedf(
+			http.StatusUnauthorized,
+			"unable to validate provided token: %v",
+			err,
+		)
+	}
+
+	jwtClaims, err := auth.SelectorData(authMethod, claims, nil)
+	if err != nil {
+		return err
+	}
+
+	tokenBindings, err := auth.NewBinder(stateSnapshot).Bind(authMethod, auth.NewIdentity(authMethod.Config, jwtClaims))
+	if err != nil {
+		return err
+	}
+	if tokenBindings.None() && !tokenBindings.Management {
+		return structs.NewErrRPCCoded(http.StatusBadRequest, "no role or policy bindings matched")
+	}
+
+	// The token is written directly, since the token upsert validation does
+	// not allow updating the expiration time.
+	renewed := token.Copy()
+	renewed.ExpirationTime = pointer.Of(now.Add(authMethod.MaxTokenTTL))
+	if tokenBindings.Management {
+		renewed.Type = structs.ACLManagementToken
+		renewed.Policies = nil
+		renewed.Roles = nil
+	} else {
+		renewed.Type = structs.ACLClientToken
+		renewed.Policies = tokenBindings.Policies
+		renewed.Roles = tokenBindings.Roles
+	}
+	renewed.SetHash()
+
+	req := structs.ACLTokenUpsertRequest{
+		Tokens: []*structs.ACLToken{renewed},
+		WriteRequest: structs.WriteRequest{
+			Region: a.srv.Region(),
+		},
+	}
+	_, index, err := a.srv.raftApply(structs.ACLTokenUpsertRequestType, &req)
+	if err != nil {
+		return err
+	}
+
+	out, err := a.srv.State().ACLTokenByAccessorID(nil, renewed.AccessorID)
+	if err != nil {
+		return err
+	}
+	reply.ACLToken = out
+	reply.Index = index
+	return nil
+}
+
+// ExchangeWorkloadIdentity RPC exchanges a workload identity for a short-lived
+// ACL token, following the token exchange grant defined in RFC 8693. This
+// allows tasks to perform scoped API operations without having an ACL token
+// baked into their job.
+//
+// The requested policies must all be attached to the job, group, or task of
+// the workload identity, so the exchange never grants more privileges than
+// what operators have explicitly associated with the workload.
+func (a *ACL) ExchangeWorkloadIdentity(
+	args *structs.ACLWorkloadIdentityExchangeRequest, reply *structs.ACLWorkloadIdentityExchangeResponse) error {
+
+	// The exchange can only be used when the Nomad cluster has ACL enabled.
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+
+	if done, err := a.srv.forward(structs.ACLExchangeWorkloadIdentityRPCMethod, args, args, reply); done {
+		return err
+	}
+	a.srv.MeasureRPCRate("acl", structs.RateMetricWrite, args)
+	defer metrics.MeasureSince([]string{"nomad", "acl", "exchange_workload_identity"}, time.Now())
+
+	if err := args.Validate(); err != nil {
+		return structs.NewErrRPCCodedf(http.StatusBadRequest, "invalid exchange request: %v", err)
+	}
+
+	claims, err := a.verifyExchangeSubject(args.SubjectToken)
+	if err != nil {
+		return err
+	}
+
+	policies, err := a.srv.ResolvePoliciesForClaims(claims)
+	if err != nil {
+		a.logger.Warn("policies could not be resolved for claims", "error", err, "alloc_id", claims.AllocationID)
+		return structs.ErrPermissionDenied
+	}
+
+	attached := set.New[string](len(policies))
+	for _, policy := range policies {
+		attached.Insert(policy.Name)
+	}
+	requested := set.From(args.Policies)
+	for _, name := range requested.Slice() {
+		if !attached.Contains(name) {
+			return structs.NewErrRPCCodedf(http.StatusForbidden,
+				"policy %q is not attached to the workload", name)
+		}
+	}
+
+	// The token must never outlive the identity it was exchanged for.
+	ttl := args.TTL
+	if ttl == 0 {
+		ttl = structs.DefaultWorkloadIdentityExchangeTTL
+	}
+	if claims.Expiry != nil {
+		remaining := time.Until(claims.Expiry.Time())
+		if remaining < ttl {
+			ttl = remaining
+		}
+	}
+	if minTTL := a.srv.config.ACLTokenMinExpirationTTL; ttl < minTTL {
+		return structs.NewErrRPCCodedf(http.StatusBadRequest,
+			"subject token expires in %v, before the minimum ACL token TTL of %v",
+			ttl.Round(time.Second), minTTL)
+	}
+
+	// Name the token after the workload, within the ACL token name limit, so
+	// operators can identify where exchanged tokens come from.
+	name := "WI-" + claims.JobID
+	if claims.TaskName != "" {
+		name += "-" + claims.TaskName
+	}
+	if len(name) > 256 {
+		name = name[:256]
+	}
+
+	token := structs.ACLToken{
+		Name:          name,
+		Type:          structs.ACLClientToken,
+		Policies:      requested.Slice(),
+		ExpirationTTL: ttl,
+	}
+
+	tokenUpsertRequest := structs.ACLTokenUpsertRequest{
+		Tokens: []*structs.ACLToken{&token},
+		WriteRequest: structs.WriteRequest{
+			Region:    a.srv.Region(),
+			AuthToken: a.srv.getLeaderAcl(),
+		},
+	}
+
+	stateSnapshot, err := a.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	var tokenUpsertReply structs.ACLTokenUpsertResponse
+	if err := a.upsertTokens(&tokenUpsertRequest, &tokenUpsertReply, stateSnapshot); err != nil {
+		return err
+	}
+
+	reply.ACLToken = tokenUpsertReply.Tokens[0]
+	reply.Index = tokenUpsertReply.Index
+	return nil
+}
+
+// ExchangeWorkloadIdentityJWT RPC exchanges a workload identity for a JWT
+// with another audience, following the token exchange grant defined in RFC
+// 8693. This allows tasks to call external services that only accept tokens
+// with their own audience, without adding every such audience to the workload
+// identity.
+//
+// The namespace of the workload must have a token exchange configuration
+// allowing the requested audience.
+func (a *ACL) ExchangeWorkloadIdentityJWT(
+	args *structs.ACLWorkloadIdentityJWTExchangeRequest, reply *structs.ACLWorkloadIdentityJWTExchangeResponse) error {
+
+	// The exchange can only be used when the Nomad cluster has ACL enabled.
+	if !a.srv.config.ACLEnabled {
+		return aclDisabled
+	}
+
+	if done, err := a.srv.forward(structs.ACLExchangeWorkloadIdentityJWTRPCMethod, args, args, reply); done {
+		return err
+	}
+	a.srv.MeasureRPCRate("acl", structs.RateMetricWrite, args)
+	defer metrics.MeasureSince([]string{"nomad", "acl", "exchange_workload_identity_jwt"}, time.Now())
+
+	if err := args.Validate(); err != nil {
+		return structs.NewErrRPCCodedf(http.StatusBadRequest, "invalid exchange request: %v", err)
+	}
+
+	claims, err := a.verifyExchangeSubject(args.SubjectToken)
+	if err != nil {
+		return err
+	}
+
+	ns, err := a.srv.State().NamespaceByName(nil, claims.Namespace)
+	if err != nil {
+		return err
+	}
+	if ns == nil || ns.TokenExchangeConfiguration == nil {
+		return structs.NewErrRPCCodedf(http.StatusForbidden,
+			"token exchange is not enabled in namespace %q", claims.Namespace)
+	}
+	exchangeConfig := ns.TokenExchangeConfiguration
+	if !exchangeConfig.AllowsAudience(args.Audience) {
+		return structs.NewErrRPCCodedf(http.StatusForbidden,
+			"audience %q is not allowed in namespace %q", args.Audience, claims.Namespace)
+	}
+
+	exchanged := claims.ExchangedClaims(args.Audience, time.Now(), exchangeConfig.TTL(args.TTL))
+	signer := a.srv.accessAuditor.signer(a.srv.encrypter, structs.NewAccessAuditActor(args.GetIdentity()))
+	token, _, err := signer.SignClaims(exchanged)
+	if err != nil {
+		return err
+	}
+
+	reply.JWT = token
+	reply.Expiration = exchanged.Expiry.Time()
+	return nil
+}
+
+// verifyExchangeSubject verifies the subject token of a token exchange is a
+// workload identity signed by this cluster, and that its allocation still
+// exists and is not terminal. Identities don't necessarily expire, so this
+// prevents the identities of stopped or garbage collected allocations from
+// being exchanged.
+func (a *ACL) verifyExchangeSubject(subjectToken string) (*structs.IdentityClaims, error) {
+	claims, err := a.srv.VerifyClaim(subjectToken)
+	if err != nil {
+		return nil, structs.NewErrRPCCodedf(http.StatusUnauthorized, "unable to validate subject token: %v", err)
+	}
+
+	alloc, err := a.srv.State().AllocByID(nil, claims.AllocationID)
+	if err != nil {
+		return nil, err
+	}
+	if alloc == nil {
+		return nil, structs.NewErrRPCCodedf(http.StatusUnauthorized,
+			"unable to validate subject token: allocation does not exist")
+	}
+	if alloc.TerminalStatus() {
+		return nil, structs.NewErrRPCCodedf(http.StatusUnauthorized,
+			"unable to validate subject token: allocation is terminal")
+	}
+
+	return claims, nil
+}
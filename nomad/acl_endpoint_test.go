@@ -11,13 +11,16 @@ import (
 	"testing"
 	"time"
 
+	josejwt "github.com/go-jose/go-jose/v3/jwt"
 	"github.com/golang-jwt/jwt/v5"
 	capOIDC "github.com/hashicorp/cap/oidc"
 	"github.com/hashicorp/go-memdb"
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/shoenig/test/must"
@@ -1053,6 +1056,47 @@ func TestACLEndpoint_ListTokens(t *testing.T) {
 	assert.Equal(t, 2, len(resp3.Tokens))
 }
 
+func TestACLEndpoint_ListTokens_ExpiringWithin(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, root, cleanupS1 := TestACLServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create tokens which are expired, expire soon, expire later, and don't
+	// expire.
+	now := time.Now().UTC()
+	expired := mock.ACLToken()
+	expired.ExpirationTime = pointer.Of(now.Add(-time.Hour))
+	expiring := mock.ACLToken()
+	expiring.ExpirationTime = pointer.Of(now.Add(2 * time.Hour))
+	expiringGlobal := mock.ACLToken()
+	expiringGlobal.Global = true
+	expiringGlobal.ExpirationTime = pointer.Of(now.Add(3 * time.Hour))
+	later := mock.ACLToken()
+	later.ExpirationTime = pointer.Of(now.Add(48 * time.Hour))
+	must.NoError(t, s1.fsm.State().UpsertACLTokens(structs.MsgTypeTestSetup, 1000,
+		[]*structs.ACLToken{expired, expiring, expiringGlobal, later}))
+
+	get := &structs.ACLTokenListRequest{
+		ExpiringWithin: 24 * time.Hour,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: root.SecretID,
+		},
+	}
+	var resp structs.ACLTokenListResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.ListTokens", get, &resp))
+	must.Eq(t, 1000, resp.Index)
+
+	var accessorIDs []string
+	for _, token := range resp.Tokens {
+		accessorIDs = append(accessorIDs, token.AccessorID)
+	}
+	must.SliceContainsAll(t, []string{expiring.AccessorID, expiringGlobal.AccessorID}, accessorIDs)
+}
+
 func TestACLEndpoint_ListTokens_PaginationFiltering(t *testing.T) {
 	ci.Parallel(t)
 	s1, cleanupS1 := TestServer(t, func(c *Config) {
@@ -1953,6 +1997,18 @@ func TestACLEndpoint_OneTimeToken(t *testing.T) {
 	require.True(t, time.Now().Before(result.ExpiresAt))
 	require.Equal(t, aclToken.AccessorID, result.AccessorID)
 
+	// Generate a short-lived one-time token, this replaces the previous one
+	upReq.TTL = 30 * time.Second
+	err = msgpackrpc.CallWithCodec(codec, "ACL.UpsertOneTimeToken", upReq, &upResp)
+	require.NoError(t, err)
+	result = upResp.OneTimeToken
+	require.WithinDuration(t, time.Now().Add(30*time.Second), result.ExpiresAt, 5*time.Second)
+
+	// TTLs longer than the maximum are rejected
+	upReq.TTL = structs.MaxOneTimeTokenTTL + time.Second
+	err = msgpackrpc.CallWithCodec(codec, "ACL.UpsertOneTimeToken", upReq, &upResp)
+	require.ErrorContains(t, err, "one-time token TTL must be positive")
+
 	// make sure we can get it back out
 	ott, err := s1.fsm.State().OneTimeTokenBySecret(nil, result.OneTimeSecretID)
 	require.NoError(t, err)
@@ -3499,6 +3555,165 @@ func TestACL_GetBindingRule(t *testing.T) {
 	must.Greater(t, aclBindingRuleResp4.Index, result.reply.Index)
 }
 
+func TestACL_TestBindingRules(t *testing.T) {
+	ci.Parallel(t)
+
+	testServer, aclRootToken, testServerCleanupFn := TestACLServer(t, nil)
+	defer testServerCleanupFn()
+	codec := rpcClient(t, testServer)
+	testutil.WaitForLeader(t, testServer.RPC)
+
+	claims := map[string]interface{}{
+		"http://nomad.internal/policies": []string{"engineering"},
+		"http://nomad.internal/roles":    []string{"engineering"},
+		"http://nomad.internal/team":     "sre",
+	}
+
+	// Try testing binding rules without setting a correct auth token.
+	testReq1 := &structs.ACLBindingRulesTestRequest{
+		AuthMethodName: "test-jwt-auth-method",
+		Claims:         claims,
+		QueryOptions: structs.QueryOptions{
+			Region: DefaultRegion,
+		},
+	}
+	var testResp1 structs.ACLBindingRulesTestResponse
+	err := msgpackrpc.CallWithCodec(codec, structs.ACLTestBindingRulesRPCMethod, testReq1, &testResp1)
+	must.EqError(t, err, "Permission denied")
+
+	// Send a request without an auth method name to test validation.
+	testReq2 := &structs.ACLBindingRulesTestRequest{
+		Claims: claims,
+		QueryOptions: structs.QueryOptions{
+			Region:    DefaultRegion,
+			AuthToken: aclRootToken.SecretID,
+		},
+	}
+	var testResp2 structs.ACLBindingRulesTestResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLTestBindingRulesRPCMethod, testReq2, &testResp2)
+	must.ErrorContains(t, err, "missing auth method name")
+
+	// The auth method does not exist meaning it will fail.
+	testReq3 := &structs.ACLBindingRulesTestRequest{
+		AuthMethodName: "test-jwt-auth-method",
+		Claims:         claims,
+		QueryOptions: structs.QueryOptions{
+			Region:    DefaultRegion,
+			AuthToken: aclRootToken.SecretID,
+		},
+	}
+	var testResp3 structs.ACLBindingRulesTestResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLTestBindingRulesRPCMethod, testReq3, &testResp3)
+	must.ErrorContains(t, err, "400")
+	must.ErrorContains(t, err, "auth-method \"test-jwt-auth-method\" not found")
+
+	// Generate and upsert a JWT ACL auth method, along with a policy and a
+	// role the binding rules can grant.
+	mockedAuthMethod := mock.ACLJWTAuthMethod()
+	mockedAuthMethod.Config.ClaimMappings = map[string]string{
+		"http://nomad.internal/team": "team",
+	}
+	mockedAuthMethod.Config.ListClaimMappings = map[string]string{
+		"http://nomad.internal/roles":    "roles",
+		"http://nomad.internal/policies": "policies",
+	}
+	must.NoError(t, testServer.fsm.State().UpsertACLAuthMethods(10, []*structs.ACLAuthMethod{mockedAuthMethod}))
+
+	mockACLPolicy := mock.ACLPolicy()
+	must.NoError(t, testServer.fsm.State().UpsertACLPolicies(
+		structs.MsgTypeTestSetup, 20, []*structs.ACLPolicy{mockACLPolicy}))
+
+	mockACLRole := mock.ACLRole()
+	mockACLRole.Policies = []*structs.ACLRolePolicyLink{{Name: mockACLPolicy.Name}}
+	must.NoError(t, testServer.fsm.State().UpsertACLRoles(
+		structs.MsgTypeTestSetup, 30, []*structs.ACLRole{mockACLRole}, true))
+
+	// Without binding rules, the claims are mapped but nothing is granted.
+	testReq3.AuthMethodName = mockedAuthMethod.Name
+	var testResp4 structs.ACLBindingRulesTestResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLTestBindingRulesRPCMethod, testReq3, &testResp4)
+	must.NoError(t, err)
+	must.Eq(t, map[string]string{"team": "sre"}, testResp4.ClaimMappings)
+	must.Eq(t, map[string][]string{
+		"roles":    {"engineering"},
+		"policies": {"engineering"},
+	}, testResp4.ListClaimMappings)
+	must.SliceEmpty(t, testResp4.MatchingRules)
+	must.SliceEmpty(t, testResp4.Roles)
+	must.SliceEmpty(t, testResp4.Policies)
+	must.False(t, testResp4.Management)
+
+	// Upsert binding rules granting the policy and the role, a rule whose
+	// policy doesn't exist, and a rule whose selector doesn't match.
+	mockBindingRule1 := mock.ACLBindingRule()
+	mockBindingRule1.AuthMethod = mockedAuthMethod.Name
+	mockBindingRule1.BindType = structs.ACLBindingRuleBindTypePolicy
+	mockBindingRule1.Selector = "engineering in list.policies"
+	mockBindingRule1.BindName = mockACLPolicy.Name
+
+	mockBindingRule2 := mock.ACLBindingRule()
+	mockBindingRule2.AuthMethod = mockedAuthMethod.Name
+	mockBindingRule2.BindName = mockACLRole.Name
+
+	mockBindingRule3 := mock.ACLBindingRule()
+	mockBindingRule3.AuthMethod = mockedAuthMethod.Name
+	mockBindingRule3.BindType = structs.ACLBindingRuleBindTypePolicy
+	mockBindingRule3.Selector = "value.team == sre"
+	mockBindingRule3.BindName = "team-${value.team}"
+
+	mockBindingRule4 := mock.ACLBindingRule()
+	mockBindingRule4.AuthMethod = mockedAuthMethod.Name
+	mockBindingRule4.Selector = "sales in list.roles"
+
+	must.NoError(t, testServer.fsm.State().UpsertACLBindingRules(40, []*structs.ACLBindingRule{
+		mockBindingRule1, mockBindingRule2, mockBindingRule3, mockBindingRule4}, true))
+
+	var testResp5 structs.ACLBindingRulesTestResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLTestBindingRulesRPCMethod, testReq3, &testResp5)
+	must.NoError(t, err)
+	must.Eq(t, 40, testResp5.Index)
+	must.False(t, testResp5.Management)
+	must.Eq(t, []string{mockACLPolicy.Name}, testResp5.Policies)
+	must.Eq(t, []*structs.ACLTokenRoleLink{{ID: mockACLRole.ID, Name: mockACLRole.Name}}, testResp5.Roles)
+
+	matches := make(map[string]*structs.ACLBindingRuleTestResult)
+	for _, match := range testResp5.MatchingRules {
+		matches[match.ID] = match
+	}
+	must.MapLen(t, 3, matches)
+	must.True(t, matches[mockBindingRule1.ID].Bound)
+	must.True(t, matches[mockBindingRule2.ID].Bound)
+	must.False(t, matches[mockBindingRule3.ID].Bound)
+	must.Eq(t, "team-sre", matches[mockBindingRule3.ID].BindName)
+	must.MapNotContainsKey(t, matches, mockBindingRule4.ID)
+
+	// Add a binding rule generating management tokens, which overrides the
+	// other rules.
+	mockBindingRule5 := mock.ACLBindingRule()
+	mockBindingRule5.AuthMethod = mockedAuthMethod.Name
+	mockBindingRule5.BindType = structs.ACLBindingRuleBindTypeManagement
+	mockBindingRule5.Selector = "engineering in list.policies"
+	mockBindingRule5.BindName = ""
+	must.NoError(t, testServer.fsm.State().UpsertACLBindingRules(
+		50, []*structs.ACLBindingRule{mockBindingRule5}, true))
+
+	var testResp6 structs.ACLBindingRulesTestResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLTestBindingRulesRPCMethod, testReq3, &testResp6)
+	must.NoError(t, err)
+	must.True(t, testResp6.Management)
+	must.SliceEmpty(t, testResp6.Roles)
+	must.SliceEmpty(t, testResp6.Policies)
+
+	// Testing binding rules must not create any ACL token.
+	iter, err := testServer.fsm.State().ACLTokens(nil, state.SortDefault)
+	must.NoError(t, err)
+	var tokens int
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		tokens++
+	}
+	must.Eq(t, 1, tokens)
+}
+
 func TestACL_OIDCAuthURL(t *testing.T) {
 	ci.Parallel(t)
 
@@ -3906,3 +4121,485 @@ func TestACL_Login(t *testing.T) {
 	must.Len(t, 0, completeAuthResp5.ACLToken.Roles)
 	must.Eq(t, structs.ACLManagementToken, completeAuthResp5.ACLToken.Type)
 }
+
+func TestACL_RenewToken(t *testing.T) {
+	ci.Parallel(t)
+
+	testServer, aclRootToken, testServerCleanupFn := TestACLServer(t, nil)
+	defer testServerCleanupFn()
+	codec := rpcClient(t, testServer)
+	testutil.WaitForLeader(t, testServer.RPC)
+
+	// create a sample JWT and a pub key for verification
+	testToken, testPubKey, err := mock.SampleJWTokenWithKeys(jwt.MapClaims{
+		"http://nomad.internal/policies": []string{"engineering"},
+		"iat":                            time.Now().Unix(),
+		"nbf":                            time.Now().Unix(),
+		"exp":                            time.Now().Add(time.Hour).Unix(),
+		"iss":                            "nomad test suite",
+		"aud":                            []string{"engineering"},
+	}, nil)
+	must.NoError(t, err)
+
+	// Generate and upsert a JWT ACL auth method with a renewal grace period,
+	// and a binding rule granting a policy.
+	mockedAuthMethod := mock.ACLJWTAuthMethod()
+	mockedAuthMethod.TokenRenewalGracePeriod = time.Hour
+	mockedAuthMethod.Config.BoundAudiences = []string{"engineering"}
+	mockedAuthMethod.Config.JWTValidationPubKeys = []string{testPubKey}
+	mockedAuthMethod.Config.BoundIssuer = []string{"nomad test suite"}
+	mockedAuthMethod.Config.ClaimMappings = map[string]string{}
+	mockedAuthMethod.Config.ListClaimMappings = map[string]string{
+		"http://nomad.internal/policies": "policies",
+	}
+	must.NoError(t, testServer.fsm.State().UpsertACLAuthMethods(10, []*structs.ACLAuthMethod{mockedAuthMethod}))
+
+	mockACLPolicy := mock.ACLPolicy()
+	must.NoError(t, testServer.fsm.State().UpsertACLPolicies(
+		structs.MsgTypeTestSetup, 20, []*structs.ACLPolicy{mockACLPolicy}))
+
+	mockBindingRule := mock.ACLBindingRule()
+	mockBindingRule.AuthMethod = mockedAuthMethod.Name
+	mockBindingRule.BindType = structs.ACLBindingRuleBindTypePolicy
+	mockBindingRule.Selector = "engineering in list.policies"
+	mockBindingRule.BindName = mockACLPolicy.Name
+	must.NoError(t, testServer.fsm.State().UpsertACLBindingRules(
+		30, []*structs.ACLBindingRule{mockBindingRule}, true))
+
+	// Login, so we have a token created by the auth method.
+	loginReq := structs.ACLLoginRequest{
+		AuthMethodName: mockedAuthMethod.Name,
+		LoginToken:     testToken,
+		WriteRequest:   structs.WriteRequest{Region: DefaultRegion},
+	}
+	var loginResp structs.ACLLoginResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, structs.ACLLoginRPCMethod, &loginReq, &loginResp))
+	must.Eq(t, mockedAuthMethod.Name, loginResp.ACLToken.AuthMethod)
+
+	// send empty req to test validation
+	renewReq1 := structs.ACLTokenRenewRequest{
+		WriteRequest: structs.WriteRequest{Region: DefaultRegion},
+	}
+	var renewResp1 structs.ACLTokenRenewResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLRenewTokenRPCMethod, &renewReq1, &renewResp1)
+	must.ErrorContains(t, err, "missing token secret ID")
+	must.ErrorContains(t, err, "missing login token")
+
+	// Tokens which were not created by an auth method can't be renewed.
+	renewReq2 := structs.ACLTokenRenewRequest{
+		SecretID:     aclRootToken.SecretID,
+		LoginToken:   testToken,
+		WriteRequest: structs.WriteRequest{Region: DefaultRegion},
+	}
+	var renewResp2 structs.ACLTokenRenewResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLRenewTokenRPCMethod, &renewReq2, &renewResp2)
+	must.ErrorContains(t, err, "token was not created by an auth method")
+
+	// Expire the token, but within the renewal grace period of the auth
+	// method, and renew it.
+	expiredToken := loginResp.ACLToken.Copy()
+	expiredToken.ExpirationTime = pointer.Of(time.Now().UTC().Add(-30 * time.Minute))
+	must.NoError(t, testServer.fsm.State().UpsertACLTokens(
+		structs.MsgTypeTestSetup, 100, []*structs.ACLToken{expiredToken}))
+
+	renewReq3 := structs.ACLTokenRenewRequest{
+		SecretID:     loginResp.ACLToken.SecretID,
+		LoginToken:   testToken,
+		WriteRequest: structs.WriteRequest{Region: DefaultRegion},
+	}
+	var renewResp3 structs.ACLTokenRenewResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, structs.ACLRenewTokenRPCMethod, &renewReq3, &renewResp3))
+	must.NotNil(t, renewResp3.ACLToken)
+	must.Eq(t, loginResp.ACLToken.AccessorID, renewResp3.ACLToken.AccessorID)
+	must.Eq(t, []string{mockACLPolicy.Name}, renewResp3.ACLToken.Policies)
+	must.False(t, renewResp3.ACLToken.IsExpired(time.Now().Add(30*time.Minute)))
+	must.NotEq(t, expiredToken.Hash, renewResp3.ACLToken.Hash)
+
+	// Expire the token beyond the renewal grace period, which prevents
+	// renewing it.
+	expiredToken = renewResp3.ACLToken.Copy()
+	expiredToken.ExpirationTime = pointer.Of(time.Now().UTC().Add(-2 * time.Hour))
+	must.NoError(t, testServer.fsm.State().UpsertACLTokens(
+		structs.MsgTypeTestSetup, 200, []*structs.ACLToken{expiredToken}))
+
+	var renewResp4 structs.ACLTokenRenewResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLRenewTokenRPCMethod, &renewReq3, &renewResp4)
+	must.ErrorContains(t, err, "outside of the renewal grace period")
+}
+
+func TestACL_ExchangeWorkloadIdentity(t *testing.T) {
+	ci.Parallel(t)
+
+	testServer, _, testServerCleanupFn := TestACLServer(t, nil)
+	defer testServerCleanupFn()
+	codec := rpcClient(t, testServer)
+	testutil.WaitForLeader(t, testServer.RPC)
+	testutil.WaitForKeyring(t, testServer.RPC, "global")
+
+	store := testServer.fsm.State()
+
+	alloc := mock.Alloc()
+	alloc.ClientStatus = structs.AllocClientStatusRunning
+	must.NoError(t, store.UpsertAllocs(structs.MsgTypeTestSetup, 10, []*structs.Allocation{alloc}))
+
+	attached := mock.ACLPolicy()
+	attached.JobACL = &structs.JobACL{
+		Namespace: alloc.Namespace,
+		JobID:     alloc.JobID,
+		Group:     alloc.TaskGroup,
+	}
+	attached.SetHash()
+	unattached := mock.ACLPolicy()
+	must.NoError(t, store.UpsertACLPolicies(structs.MsgTypeTestSetup, 20,
+		[]*structs.ACLPolicy{attached, unattached}))
+
+	task := alloc.LookupTask("web")
+	wiHandle := &structs.WIHandle{
+		WorkloadIdentifier: task.Name,
+		WorkloadType:       structs.WorkloadTypeTask,
+	}
+	claims := structs.NewIdentityClaims(alloc.Job, alloc, wiHandle, task.Identity, time.Now())
+	subjectToken, _, err := testServer.encrypter.SignClaims(claims)
+	must.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		req         *structs.ACLWorkloadIdentityExchangeRequest
+		expectedErr string
+	}{
+		{
+			name:        "missing fields",
+			req:         &structs.ACLWorkloadIdentityExchangeRequest{},
+			expectedErr: "missing subject token",
+		},
+		{
+			name: "TTL too long",
+			req: &structs.ACLWorkloadIdentityExchangeRequest{
+				SubjectToken: subjectToken,
+				Policies:     []string{attached.Name},
+				TTL:          2 * structs.MaxWorkloadIdentityExchangeTTL,
+			},
+			expectedErr: "TTL must not be greater than",
+		},
+		{
+			name: "invalid subject token",
+			req: &structs.ACLWorkloadIdentityExchangeRequest{
+				SubjectToken: "not-a-jwt",
+				Policies:     []string{attached.Name},
+			},
+			expectedErr: "unable to validate subject token",
+		},
+		{
+			name: "policy not attached",
+			req: &structs.ACLWorkloadIdentityExchangeRequest{
+				SubjectToken: subjectToken,
+				Policies:     []string{attached.Name, unattached.Name},
+			},
+			expectedErr: "is not attached to the workload",
+		},
+		{
+			name: "valid",
+			req: &structs.ACLWorkloadIdentityExchangeRequest{
+				SubjectToken: subjectToken,
+				Policies:     []string{attached.Name, attached.Name},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Region = DefaultRegion
+
+			var resp structs.ACLWorkloadIdentityExchangeResponse
+			err := msgpackrpc.CallWithCodec(codec, structs.ACLExchangeWorkloadIdentityRPCMethod, tc.req, &resp)
+			if tc.expectedErr != "" {
+				must.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			must.NoError(t, err)
+
+			token := resp.ACLToken
+			must.NotNil(t, token)
+			must.Eq(t, structs.ACLClientToken, token.Type)
+			must.Eq(t, []string{attached.Name}, token.Policies)
+			must.Eq(t, "WI-"+alloc.JobID+"-web", token.Name)
+			must.False(t, token.Global)
+			must.NotNil(t, token.ExpirationTime)
+			must.True(t, token.ExpirationTime.Before(
+				time.Now().Add(structs.DefaultWorkloadIdentityExchangeTTL+time.Minute)))
+
+			// The token can now be used to authenticate requests.
+			aclObj, err := testServer.ResolveToken(token.SecretID)
+			must.NoError(t, err)
+			must.NotNil(t, aclObj)
+			must.False(t, aclObj.IsManagement())
+		})
+	}
+
+	// Identities expiring before the minimum ACL token TTL can't be exchanged.
+	expiring := structs.NewIdentityClaims(alloc.Job, alloc, wiHandle, task.Identity, time.Now())
+	expiring.Expiry = josejwt.NewNumericDate(time.Now().Add(30 * time.Second))
+	expiringToken, _, err := testServer.encrypter.SignClaims(expiring)
+	must.NoError(t, err)
+
+	var resp structs.ACLWorkloadIdentityExchangeResponse
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLExchangeWorkloadIdentityRPCMethod,
+		&structs.ACLWorkloadIdentityExchangeRequest{
+			SubjectToken: expiringToken,
+			Policies:     []string{attached.Name},
+			WriteRequest: structs.WriteRequest{Region: DefaultRegion},
+		}, &resp)
+	must.ErrorContains(t, err, "before the minimum ACL token TTL")
+
+	// Allocations stopped by the servers can no longer exchange their
+	// identities, even while they are still running.
+	serverStopped := alloc.Copy()
+	serverStopped.DesiredStatus = structs.AllocDesiredStatusStop
+	must.NoError(t, store.UpsertAllocs(structs.MsgTypeTestSetup, 25, []*structs.Allocation{serverStopped}))
+
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLExchangeWorkloadIdentityRPCMethod,
+		&structs.ACLWorkloadIdentityExchangeRequest{
+			SubjectToken: subjectToken,
+			Policies:     []string{attached.Name},
+			WriteRequest: structs.WriteRequest{Region: DefaultRegion},
+		}, &resp)
+	must.ErrorContains(t, err, "allocation is terminal")
+
+	// Terminal allocations can no longer exchange their identities.
+	stopped := alloc.Copy()
+	stopped.ClientStatus = structs.AllocClientStatusComplete
+	must.NoError(t, store.UpdateAllocsFromClient(structs.MsgTypeTestSetup, 30, []*structs.Allocation{stopped}))
+
+	err = msgpackrpc.CallWithCodec(codec, structs.ACLExchangeWorkloadIdentityRPCMethod,
+		&structs.ACLWorkloadIdentityExchangeRequest{
+			SubjectToken: subjectToken,
+			Policies:     []string{attached.Name},
+			WriteRequest: structs.WriteRequest{Region: DefaultRegion},
+		}, &resp)
+	must.ErrorContains(t, err, "allocation is terminal")
+}
+
+func TestACL_ExchangeWorkloadIdentityJWT(t *testing.T) {
+	ci.Parallel(t)
+
+	testServer, _, testServerCleanupFn := TestACLServer(t, nil)
+	defer testServerCleanupFn()
+	codec := rpcClient(t, testServer)
+	testutil.WaitForLeader(t, testServer.RPC)
+	testutil.WaitForKeyring(t, testServer.RPC, "global")
+
+	store := testServer.fsm.State()
+
+	ns := mock.Namespace()
+	ns.TokenExchangeConfiguration = &structs.NamespaceTokenExchangeConfiguration{
+		Audiences: []string{"vault.io", "*.example.com"},
+		MaxTTL:    30 * time.Minute,
+	}
+	other := mock.Namespace()
+	must.NoError(t, store.UpsertNamespaces(10, []*structs.Namespace{ns, other}))
+
+	newSubjectToken := func(namespace string) (string, *structs.Allocation) {
+		alloc := mock.Alloc()
+		alloc.Namespace = namespace
+		alloc.Job.Namespace = namespace
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		index, _ := store.LatestIndex()
+		must.NoError(t, store.UpsertAllocs(structs.MsgTypeTestSetup, index+1, []*structs.Allocation{alloc}))
+
+		task := alloc.LookupTask("web")
+		wiHandle := &structs.WIHandle{
+			WorkloadIdentifier: task.Name,
+			WorkloadType:       structs.WorkloadTypeTask,
+		}
+		claims := structs.NewIdentityClaims(alloc.Job, alloc, wiHandle, task.Identity, time.Now())
+		token, _, err := testServer.encrypter.SignClaims(claims)
+		must.NoError(t, err)
+		return token, alloc
+	}
+	subjectToken, _ := newSubjectToken(ns.Name)
+	otherSubjectToken, _ := newSubjectToken(other.Name)
+
+	// The identities of stopped and garbage collected allocations can't be
+	// exchanged, even though they haven't expired.
+	stoppedSubjectToken, stoppedAlloc := newSubjectToken(ns.Name)
+	stoppedAlloc = stoppedAlloc.Copy()
+	stoppedAlloc.DesiredStatus = structs.AllocDesiredStatusStop
+	index, _ := store.LatestIndex()
+	must.NoError(t, store.UpsertAllocs(structs.MsgTypeTestSetup, index+1, []*structs.Allocation{stoppedAlloc}))
+
+	gcSubjectToken, gcAlloc := newSubjectToken(ns.Name)
+	index, _ = store.LatestIndex()
+	must.NoError(t, store.DeleteEval(index+1, nil, []string{gcAlloc.ID}, false))
+
+	testCases := []struct {
+		name        string
+		req         *structs.ACLWorkloadIdentityJWTExchangeRequest
+		expectedErr string
+		expectedTTL time.Duration
+	}{
+		{
+			name:        "missing fields",
+			req:         &structs.ACLWorkloadIdentityJWTExchangeRequest{},
+			expectedErr: "missing subject token",
+		},
+		{
+			name: "invalid subject token",
+			req: &structs.ACLWorkloadIdentityJWTExchangeRequest{
+				SubjectToken: "not-a-jwt",
+				Audience:     "vault.io",
+			},
+			expectedErr: "unable to validate subject token",
+		},
+		{
+			name: "namespace without token exchange",
+			req: &structs.ACLWorkloadIdentityJWTExchangeRequest{
+				SubjectToken: otherSubjectToken,
+				Audience:     "vault.io",
+			},
+			expectedErr: "token exchange is not enabled",
+		},
+		{
+			name: "stopped allocation",
+			req: &structs.ACLWorkloadIdentityJWTExchangeRequest{
+				SubjectToken: stoppedSubjectToken,
+				Audience:     "vault.io",
+			},
+			expectedErr: "allocation is terminal",
+		},
+		{
+			name: "garbage collected allocation",
+			req: &structs.ACLWorkloadIdentityJWTExchangeRequest{
+				SubjectToken: gcSubjectToken,
+				Audience:     "vault.io",
+			},
+			expectedErr: "allocation does not exist",
+		},
+		{
+			name: "audience not allowed",
+			req: &structs.ACLWorkloadIdentityJWTExchangeRequest{
+				SubjectToken: subjectToken,
+				Audience:     "consul.io",
+			},
+			expectedErr: `audience "consul.io" is not allowed`,
+		},
+		{
+			name: "valid",
+			req: &structs.ACLWorkloadIdentityJWTExchangeRequest{
+				SubjectToken: subjectToken,
+				Audience:     "api.example.com",
+			},
+			expectedTTL: structs.DefaultWorkloadIdentityExchangeTTL,
+		},
+		{
+			name: "TTL capped by the namespace",
+			req: &structs.ACLWorkloadIdentityJWTExchangeRequest{
+				SubjectToken: subjectToken,
+				Audience:     "vault.io",
+				TTL:          time.Hour,
+			},
+			expectedTTL: 30 * time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Region = DefaultRegion
+
+			var resp structs.ACLWorkloadIdentityJWTExchangeResponse
+			err := msgpackrpc.CallWithCodec(codec, structs.ACLExchangeWorkloadIdentityJWTRPCMethod, tc.req, &resp)
+			if tc.expectedErr != "" {
+				must.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			must.NoError(t, err)
+
+			claims, err := testServer.encrypter.VerifyClaim(resp.JWT)
+			must.NoError(t, err)
+			must.Eq(t, []string{tc.req.Audience}, claims.Audience)
+			must.Eq(t, ns.Name, claims.Namespace)
+			must.Eq(t, "web", claims.TaskName)
+			must.Eq(t, resp.Expiration, claims.Expiry.Time())
+			must.True(t, resp.Expiration.Before(time.Now().Add(tc.expectedTTL+time.Second)))
+			must.True(t, resp.Expiration.After(time.Now().Add(tc.expectedTTL-time.Minute)))
+		})
+	}
+}
+
+func TestACL_ExchangeWorkloadIdentityJWT_ACLDisabled(t *testing.T) {
+	ci.Parallel(t)
+
+	testServer, testServerCleanupFn := TestServer(t, nil)
+	defer testServerCleanupFn()
+	codec := rpcClient(t, testServer)
+	testutil.WaitForLeader(t, testServer.RPC)
+
+	req := &structs.ACLWorkloadIdentityJWTExchangeRequest{
+		SubjectToken: "not-a-jwt",
+		Audience:     "vault.io",
+		WriteRequest: structs.WriteRequest{Region: DefaultRegion},
+	}
+	var resp structs.ACLWorkloadIdentityJWTExchangeResponse
+	err := msgpackrpc.CallWithCodec(codec, structs.ACLExchangeWorkloadIdentityJWTRPCMethod, req, &resp)
+	must.ErrorContains(t, err, aclDisabled.Error())
+}
+
+func TestACLEndpoint_WhoAmI(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, root, cleanupS1 := TestACLServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	policy1 := mock.ACLPolicy()
+	policy2 := mock.ACLPolicy()
+	policy2.Rules = `namespace "prod-*" { policy = "read" }`
+	must.NoError(t, s1.fsm.State().UpsertACLPolicies(
+		structs.MsgTypeTestSetup, 1000, []*structs.ACLPolicy{policy1, policy2}))
+
+	role := mock.ACLRole()
+	role.Policies = []*structs.ACLRolePolicyLink{{Name: policy2.Name}}
+	must.NoError(t, s1.fsm.State().UpsertACLRoles(
+		structs.MsgTypeTestSetup, 1010, []*structs.ACLRole{role}, false))
+
+	token := mock.ACLToken()
+	token.Policies = []string{policy1.Name}
+	token.Roles = []*structs.ACLTokenRoleLink{{ID: role.ID}}
+	must.NoError(t, s1.fsm.State().UpsertACLTokens(
+		structs.MsgTypeTestSetup, 1020, []*structs.ACLToken{token}))
+
+	whoami := func(secretID string) *structs.ACLWhoAmIResponse {
+		req := &structs.GenericRequest{
+			QueryOptions: structs.QueryOptions{
+				Region:    "global",
+				AuthToken: secretID,
+			},
+		}
+		var resp structs.ACLWhoAmIResponse
+		must.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.WhoAmI", req, &resp))
+		return &resp
+	}
+
+	// The policies of the token and of its roles are returned.
+	resp := whoami(token.SecretID)
+	must.NotNil(t, resp.Identity.ACLToken)
+	must.Eq(t, token.AccessorID, resp.Identity.ACLToken.AccessorID)
+	must.SliceContainsAll(t, []string{policy1.Name, policy2.Name}, resp.Policies)
+	must.NotNil(t, resp.Capabilities)
+	must.False(t, resp.Capabilities.Management)
+	must.MapContainsKeys(t, resp.Capabilities.Namespaces, []string{"default", "prod-*"})
+	must.Eq(t, "read", resp.Capabilities.Node)
+	must.Eq(t, "read", resp.Capabilities.Agent)
+
+	// Management tokens have no policies.
+	resp = whoami(root.SecretID)
+	must.Eq(t, root.AccessorID, resp.Identity.ACLToken.AccessorID)
+	must.SliceEmpty(t, resp.Policies)
+	must.True(t, resp.Capabilities.Management)
+
+	// Requests without a token use the anonymous policy.
+	resp = whoami("")
+	must.Eq(t, structs.AnonymousACLToken.AccessorID, resp.Identity.ACLToken.AccessorID)
+	must.Eq(t, []string{"anonymous"}, resp.Policies)
+	must.NotNil(t, resp.Capabilities)
+}
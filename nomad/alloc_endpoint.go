@@ -6,6 +6,7 @@ package nomad
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -175,7 +176,9 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 			reply.Alloc = out
 			if out != nil {
 				// Re-check namespace in case it differs from request.
-				if !allowNsOp(aclObj, out.Namespace) {
+				// Workloads may read their own allocation without
+				// read-job on its namespace.
+				if !allowNsOp(aclObj, out.Namespace) && !a.allowWorkloadRead(args, out) {
 					return structs.NewErrUnknownAllocation(args.AllocID)
 				}
 
@@ -196,6 +199,15 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 	return a.srv.blockingRPC(&opts)
 }
 
+// allowWorkloadRead returns true if the request is authenticated by a
+// workload identity of the allocation, and the workload policy of the servers
+// lets workloads read their own allocation.
+func (a *Alloc) allowWorkloadRead(args structs.RequestWithIdentity, alloc *structs.Allocation) bool {
+	claims := args.GetIdentity().GetClaims()
+	return claims != nil && claims.AllocationID == alloc.ID &&
+		a.srv.config.ACLWorkloadPolicy.AllowAllocRead()
+}
+
 // GetAllocs is used to lookup a set of allocations
 func (a *Alloc) GetAllocs(args *structs.AllocsGetRequest,
 	reply *structs.AllocsGetResponse) error {
@@ -443,6 +455,135 @@ func (a *Alloc) GetServiceRegistrations(
 	})
 }
 
+// migrationTokenTTL is how long the client of a previous allocation accepts
+// the one-time token of a migration introduction for.
+const migrationTokenTTL = 5 * time.Minute
+
+// MigrationIntroduction allows nodes to get introduced to the node of the
+// previous allocation of one of their allocations, which accepts a one-time
+// token to snapshot the previous allocation for its data to be migrated
+// directly between the nodes.
+//
+// This is an internal-only RPC and not exposed via the HTTP API.
+func (a *Alloc) MigrationIntroduction(args *structs.AllocMigrationIntroductionRequest, reply *structs.AllocMigrationIntroductionResponse) error {
+
+	aclObj, err := a.srv.AuthenticateClientOnly(a.ctx, args)
+	a.srv.MeasureRPCRate("alloc", structs.RateMetricWrite, args)
+	if err != nil {
+		return structs.ErrPermissionDenied
+	}
+
+	if done, err := a.srv.forward("Alloc.MigrationIntroduction", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "alloc", "migration_introduction"}, time.Now())
+
+	if !aclObj.AllowClientOp() {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.AllocID == "" {
+		return fmt.Errorf("missing allocation ID")
+	}
+
+	snap, err := a.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	alloc, err := getAlloc(snap, args.AllocID)
+	if err != nil {
+		return err
+	}
+
+	// Nodes can only migrate the data of their own allocations
+	if identity := args.GetIdentity(); identity == nil || identity.ClientID != alloc.NodeID {
+		return structs.ErrPermissionDenied
+	}
+	if alloc.PreviousAllocation == "" {
+		return fmt.Errorf("allocation %q has no previous allocation", alloc.ID)
+	}
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil || tg.EphemeralDisk == nil || !tg.EphemeralDisk.Migrate {
+		return fmt.Errorf("allocation %q does not migrate its ephemeral disk", alloc.ID)
+	}
+
+	prevAlloc, err := getAlloc(snap, alloc.PreviousAllocation)
+	if err != nil {
+		return err
+	}
+	prevNode, err := getNodeForRpc(snap, prevAlloc.NodeID)
+	if err != nil {
+		return err
+	}
+
+	authz := &structs.AllocMigrationAuthorizeRequest{
+		PrevAllocID: prevAlloc.ID,
+		AllocID:     alloc.ID,
+		NodeID:      alloc.NodeID,
+		Token:       uuid.Generate(),
+		ExpiresAt:   time.Now().Add(migrationTokenTTL),
+		QueryOptions: structs.QueryOptions{
+			Region:     a.srv.Region(),
+			AllowStale: true,
+		},
+	}
+	if err := a.srv.authorizeMigration(prevNode.ID, authz); err != nil {
+		return fmt.Errorf("failed to authorize migration on node %q: %w", prevNode.ID, err)
+	}
+
+	scheme := "http://"
+	if prevNode.TLSEnabled {
+		scheme = "https://"
+	}
+
+	reply.PrevAllocID = prevAlloc.ID
+	reply.NodeID = prevNode.ID
+	reply.Address = scheme + prevNode.HTTPAddr
+	reply.Token = authz.Token
+	reply.ExpiresAt = authz.ExpiresAt
+	a.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
+// AuthorizeMigration is used by servers to forward the authorization of a
+// migration introduction to the server connected to the node of the previous
+// allocation.
+//
+// This is an internal-only RPC and not exposed via the HTTP API.
+func (a *Alloc) AuthorizeMigration(args *structs.AllocMigrationAuthorizeRequest, reply *structs.GenericResponse) error {
+
+	aclObj, err := a.srv.AuthenticateServerOnly(a.ctx, args)
+	a.srv.MeasureRPCRate("alloc", structs.RateMetricWrite, args)
+	if err != nil || !aclObj.AllowServerOp() {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "alloc", "authorize_migration"}, time.Now())
+
+	snap, err := a.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	prevAlloc, err := getAlloc(snap, args.PrevAllocID)
+	if err != nil {
+		return err
+	}
+
+	return a.srv.authorizeMigration(prevAlloc.NodeID, args)
+}
+
+// authorizeMigration makes the node of the previous allocation of a migration
+// accept its token, through the server connected to the node if needed.
+func (s *Server) authorizeMigration(nodeID string, args *structs.AllocMigrationAuthorizeRequest) error {
+	var reply structs.GenericResponse
+
+	state, ok := s.getNodeConn(nodeID)
+	if !ok {
+		return findNodeConnAndForward(s, nodeID, "Alloc.AuthorizeMigration", args, &reply)
+	}
+	return NodeRpc(state.Session, "Allocations.AuthorizeMigration", args, &reply)
+}
+
 // SignIdentities allows nodes to retrieve workload identities for their
 // allocations.
 //
@@ -470,13 +611,26 @@ func (a *Alloc) SignIdentities(args *structs.AllocIdentitiesRequest, reply *stru
 		return fmt.Errorf("no identities requested")
 	}
 
+	// Clients batch the requests of many allocations together, so coalesce
+	// duplicate requests to only sign each identity once.
+	idReqs := make([]*structs.WorkloadIdentityRequest, 0, len(args.Identities))
+	seen := make(map[structs.WorkloadIdentityRequest]struct{}, len(args.Identities))
+	for _, idReq := range args.Identities {
+		if _, ok := seen[*idReq]; ok {
+			continue
+		}
+		seen[*idReq] = struct{}{}
+		idReqs = append(idReqs, idReq)
+	}
+	metrics.AddSample([]string{"nomad", "alloc", "sign_identities", "batch_size"}, float32(len(idReqs)))
+
 	// Tracks whether the min index was satisfied by the blocking query
 	thresholdMet := false
 
-	// Most if not all identity requests will be for the same alloc, so create a
-	// set of alloc IDs to avoid unnecessary looping in the blocking query.
-	allocs := make(map[string]*structs.Allocation, len(args.Identities))
-	for _, idReq := range args.Identities {
+	// Requests are usually for a handful of allocs, so create a set of alloc
+	// IDs to avoid unnecessary looping in the blocking query.
+	allocs := make(map[string]*structs.Allocation, len(idReqs))
+	for _, idReq := range idReqs {
 		allocs[idReq.AllocID] = nil // to be set while watching
 	}
 
@@ -532,10 +686,17 @@ func (a *Alloc) SignIdentities(args *structs.AllocIdentitiesRequest, reply *stru
 		return err
 	}
 
+	// The state is recent enough if the allocs table moved past the min index,
+	// even if none of the requested allocs were created after it. This happens
+	// with batches when the newest alloc has already been GC'd.
+	if reply.Index > args.QueryOptions.MinQueryIndex {
+		thresholdMet = true
+	}
+
 	// Index threshold was not met in the blocking query. Set rejections since
 	// allocs could not be found and should be considered invalid.
 	if !thresholdMet {
-		for _, idReq := range args.Identities {
+		for _, idReq := range idReqs {
 			reply.Rejections = append(reply.Rejections, &structs.WorkloadIdentityRejection{
 				WorkloadIdentityRequest: *idReq,
 				Reason:                  structs.WIRejectionReasonMissingAlloc,
@@ -546,9 +707,11 @@ func (a *Alloc) SignIdentities(args *structs.AllocIdentitiesRequest, reply *stru
 		return nil
 	}
 
-	// Threshold met, so create the response
+	// Threshold met, so create the response. The signatures are recorded on
+	// behalf of the client if access events are enabled.
 	now := time.Now().UTC()
-	for _, idReq := range args.Identities {
+	signer := a.srv.accessAuditor.signer(a.srv.encrypter, structs.NewAccessAuditActor(args.GetIdentity()))
+	for _, idReq := range idReqs {
 		out := allocs[idReq.AllocID]
 
 		if out == nil {
@@ -575,7 +738,7 @@ func (a *Alloc) SignIdentities(args *structs.AllocIdentitiesRequest, reply *stru
 				continue
 			}
 
-			widFound, err := a.signTasks(task, out, idReq, reply, now)
+			widFound, err := a.signTasks(signer, task, out, idReq, reply, now)
 			if err != nil {
 				return err
 			}
@@ -589,7 +752,7 @@ func (a *Alloc) SignIdentities(args *structs.AllocIdentitiesRequest, reply *stru
 			}
 
 		case structs.WorkloadTypeService:
-			widFound, err := a.signServices(job, out, idReq, reply, now)
+			widFound, err := a.signServices(signer, job, out, idReq, reply, now)
 			if err != nil {
 				return err
 			}
@@ -603,10 +766,136 @@ func (a *Alloc) SignIdentities(args *structs.AllocIdentitiesRequest, reply *stru
 			}
 		}
 	}
+
+	a.publishSignedIdentities(args.AuthToken, reply.SignedIdentities)
+	return nil
+}
+
+// publishSignedIdentities publishes the signatures and renewals of workload
+// identities on the event stream. They're published in the background by the
+// leader so signing doesn't wait on it, with the token of the node the
+// identities were signed for.
+func (a *Alloc) publishSignedIdentities(authToken string, signed []*structs.SignedWorkloadIdentity) {
+	if !a.srv.config.EnableEventBroker || len(signed) == 0 {
+		return
+	}
+
+	events := make([]*structs.WorkloadIdentityEvent, 0, len(signed))
+	for _, sid := range signed {
+		eventType := structs.TypeWorkloadIdentitySigned
+		if sid.Renewal {
+			eventType = structs.TypeWorkloadIdentityRenewed
+		}
+		events = append(events, &structs.WorkloadIdentityEvent{
+			AllocID:    sid.AllocID,
+			WIHandle:   sid.WIHandle,
+			Type:       eventType,
+			Expiration: sid.Expiration,
+		})
+	}
+
+	args := &structs.AllocIdentityEventsRequest{
+		Events: events,
+		WriteRequest: structs.WriteRequest{
+			Region:    a.srv.Region(),
+			AuthToken: authToken,
+		},
+	}
+	go func() {
+		var reply structs.GenericResponse
+		if err := a.srv.RPC("Alloc.IdentityEvents", args, &reply); err != nil {
+			a.logger.Warn("failed to publish workload identity events", "error", err)
+		}
+	}()
+}
+
+// IdentityEvents allows nodes to publish lifecycle events of the workload
+// identities of their allocations on the event stream, such as renewal
+// failures. The servers also publish the signatures and renewals of identities
+// through it on behalf of the nodes.
+//
+// The events don't change the state, so they aren't written to raft and are
+// only published by the event broker of the leader.
+//
+// This is an internal-only RPC and not exposed via the HTTP API.
+func (a *Alloc) IdentityEvents(args *structs.AllocIdentityEventsRequest, reply *structs.GenericResponse) error {
+
+	aclObj, err := a.srv.AuthenticateClientOnly(a.ctx, args)
+	a.srv.MeasureRPCRate("alloc", structs.RateMetricWrite, args)
+	if err != nil {
+		return structs.ErrPermissionDenied
+	}
+
+	if done, err := a.srv.forward("Alloc.IdentityEvents", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "alloc", "identity_events"}, time.Now())
+
+	if !aclObj.AllowClientOp() {
+		return structs.ErrPermissionDenied
+	}
+
+	if len(args.Events) == 0 {
+		return fmt.Errorf("no events to publish")
+	}
+
+	// The events are only published by the event broker, so skip them when
+	// it's disabled.
+	if !a.srv.config.EnableEventBroker {
+		return nil
+	}
+
+	snap, err := a.srv.State().Snapshot()
+	if err != nil {
+		return err
+	}
+
+	// Nodes can only publish events of their own allocations, and the details
+	// of the allocations are set from the state so they can be trusted.
+	nodeID := args.GetIdentity().ClientID
+	events := make([]*structs.WorkloadIdentityEvent, 0, len(args.Events))
+	for _, event := range args.Events {
+		switch event.Type {
+		case structs.TypeWorkloadIdentitySigned,
+			structs.TypeWorkloadIdentityRenewed,
+			structs.TypeWorkloadIdentityRenewalFailed,
+			structs.TypeWorkloadIdentityExpired:
+		default:
+			return fmt.Errorf("invalid workload identity event type %q", event.Type)
+		}
+
+		alloc, err := snap.AllocByID(nil, event.AllocID)
+		if err != nil {
+			return err
+		}
+		if alloc == nil {
+			// Alloc may have been GC'd since the event happened
+			continue
+		}
+		if alloc.NodeID != nodeID {
+			return structs.ErrPermissionDenied
+		}
+
+		event.Namespace = alloc.Namespace
+		event.JobID = alloc.JobID
+		event.NodeID = alloc.NodeID
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	index, err := snap.LatestIndex()
+	if err != nil {
+		return err
+	}
+	a.srv.State().PublishWorkloadIdentityEvents(index, events)
+	reply.Index = index
 	return nil
 }
 
 func (a *Alloc) signTasks(
+	signer claimSigner,
 	task *structs.Task,
 	alloc *structs.Allocation,
 	idReq *structs.WorkloadIdentityRequest,
@@ -619,13 +908,14 @@ func (a *Alloc) signTasks(
 		}
 
 		widFound = true
-		err = a.signIdentities(alloc, wid, idReq, reply, now)
+		err = a.signIdentities(signer, alloc, wid, idReq, reply, now)
 		break
 	}
 	return
 }
 
 func (a *Alloc) signServices(
+	signer claimSigner,
 	job *structs.Job,
 	alloc *structs.Allocation,
 	idReq *structs.WorkloadIdentityRequest,
@@ -638,13 +928,13 @@ func (a *Alloc) signServices(
 	for _, tg := range job.TaskGroups {
 		for _, service := range tg.Services {
 			if service.IdentityHandle().Equal(wid) {
-				return true, a.signIdentities(alloc, service.Identity, idReq, reply, now)
+				return true, a.signIdentities(signer, alloc, service.Identity, idReq, reply, now)
 			}
 		}
 		for _, task := range tg.Tasks {
 			for _, service := range task.Services {
 				if service.IdentityHandle().Equal(wid) {
-					return true, a.signIdentities(alloc, service.Identity, idReq, reply, now)
+					return true, a.signIdentities(signer, alloc, service.Identity, idReq, reply, now)
 				}
 			}
 		}
@@ -653,6 +943,7 @@ func (a *Alloc) signServices(
 }
 
 func (a *Alloc) signIdentities(
+	signer claimSigner,
 	alloc *structs.Allocation,
 	wid *structs.WorkloadIdentity,
 	idReq *structs.WorkloadIdentityRequest,
@@ -660,7 +951,12 @@ func (a *Alloc) signIdentities(
 	now time.Time,
 ) error {
 	claims := structs.NewIdentityClaims(alloc.Job, alloc, &idReq.WIHandle, wid, now)
-	token, _, err := a.srv.encrypter.SignClaims(claims)
+
+	// The subject of JWT-SVIDs is the SPIFFE ID of the task.
+	if wid.SPIFFE != nil && wid.SPIFFE.JWT && idReq.WorkloadType == structs.WorkloadTypeTask {
+		claims.Subject = structs.SPIFFEID(a.srv.spiffeTrustDomain(), alloc, idReq.WorkloadIdentifier).String()
+	}
+	token, _, err := signer.SignClaims(claims)
 	if err != nil {
 		return err
 	}
@@ -672,3 +968,98 @@ func (a *Alloc) signIdentities(
 
 	return nil
 }
+
+// SignCertificate allows nodes to retrieve X.509 certificates for the
+// workload identities of their allocations that set x509 or a SPIFFE
+// X.509-SVID, and for their Nomad services that set a certificate. The
+// certificates are signed by the workload CA of the servers.
+//
+// This is an internal-only RPC and not exposed via the HTTP API.
+func (a *Alloc) SignCertificate(args *structs.AllocCertificateRequest, reply *structs.AllocCertificateResponse) error {
+
+	aclObj, err := a.srv.AuthenticateClientOnly(a.ctx, args)
+	a.srv.MeasureRPCRate("alloc", structs.RateMetricRead, args)
+	if err != nil {
+		return structs.ErrPermissionDenied
+	}
+
+	if done, err := a.srv.forward("Alloc.SignCertificate", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "alloc", "sign_certificate"}, time.Now())
+
+	if !aclObj.AllowClientOp() {
+		return structs.ErrPermissionDenied
+	}
+
+	if a.srv.workloadCA == nil {
+		return fmt.Errorf("workload certificates are not enabled on the servers")
+	}
+
+	alloc, err := a.srv.State().AllocByID(nil, args.AllocID)
+	if err != nil {
+		return err
+	}
+	if alloc == nil {
+		return fmt.Errorf("%s: %s", structs.WIRejectionReasonMissingAlloc, args.AllocID)
+	}
+
+	// Nodes can only retrieve certificates for their own allocations.
+	if alloc.NodeID != args.GetIdentity().ClientID {
+		return structs.ErrPermissionDenied
+	}
+
+	now := time.Now().UTC()
+
+	if args.WorkloadType == structs.WorkloadTypeService {
+		service := lookupCertificateService(alloc, args.WorkloadIdentifier)
+		if service == nil {
+			return fmt.Errorf("%s: %s", structs.WIRejectionReasonMissingService, args.WorkloadIdentifier)
+		}
+		reply.Certificate, reply.CACertificate, err = a.srv.workloadCA.signService(args.CSR, alloc, service, now)
+		return err
+	}
+
+	if args.WorkloadType != structs.WorkloadTypeTask {
+		return fmt.Errorf("%s: %s", structs.WIRejectionReasonMissingIdentity, args.IdentityName)
+	}
+	task := alloc.LookupTask(args.WorkloadIdentifier)
+	if task == nil {
+		return fmt.Errorf("%s: %s", structs.WIRejectionReasonMissingTask, args.WorkloadIdentifier)
+	}
+
+	var wid *structs.WorkloadIdentity
+	for _, id := range task.Identities {
+		if id.Name == args.IdentityName && id.RequiresCertificate() {
+			wid = id
+			break
+		}
+	}
+	if wid == nil {
+		return fmt.Errorf("%s: %s", structs.WIRejectionReasonMissingIdentity, args.IdentityName)
+	}
+
+	reply.Certificate, reply.CACertificate, err = a.srv.workloadCA.signTask(args.CSR, alloc, task.Name, wid.TTL, now)
+	return err
+}
+
+// lookupCertificateService returns the Nomad service of the group or tasks of
+// the allocation with the name that sets a certificate, or nil if there is
+// none.
+func lookupCertificateService(alloc *structs.Allocation, name string) *structs.Service {
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil {
+		return nil
+	}
+
+	services := slices.Clone(tg.Services)
+	for _, task := range tg.Tasks {
+		services = append(services, task.Services...)
+	}
+	for _, service := range services {
+		if service.Name == name && service.Provider == structs.ServiceProviderNomad && service.Certificate != nil {
+			return service
+		}
+	}
+	return nil
+}
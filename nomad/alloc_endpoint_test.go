@@ -4,6 +4,7 @@
 package nomad
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/stream"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/shoenig/test/must"
@@ -873,6 +875,53 @@ func TestAllocEndpoint_GetAlloc_ACL(t *testing.T) {
 	}
 }
 
+func TestAllocEndpoint_GetAlloc_WorkloadPolicy(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, _, cleanupS1 := TestACLServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForKeyring(t, s1.RPC, "global")
+
+	alloc1 := mock.Alloc()
+	alloc2 := mock.Alloc()
+	must.NoError(t, s1.fsm.State().UpsertAllocs(
+		structs.MsgTypeTestSetup, 1000, []*structs.Allocation{alloc1, alloc2}))
+
+	wiHandle := &structs.WIHandle{
+		WorkloadIdentifier: "web",
+		WorkloadType:       structs.WorkloadTypeTask,
+	}
+	claims := structs.NewIdentityClaims(alloc1.Job, alloc1, wiHandle, alloc1.LookupTask("web").Identity, time.Now())
+	idToken, _, err := s1.encrypter.SignClaims(claims)
+	must.NoError(t, err)
+
+	get := func(allocID string) (*structs.SingleAllocResponse, error) {
+		req := &structs.AllocSpecificRequest{
+			AllocID: allocID,
+			QueryOptions: structs.QueryOptions{
+				Region:    "global",
+				AuthToken: idToken,
+			},
+		}
+		var resp structs.SingleAllocResponse
+		err := msgpackrpc.CallWithCodec(codec, "Alloc.GetAlloc", req, &resp)
+		return &resp, err
+	}
+
+	// The default workload policy lets workloads read their own allocation
+	resp, err := get(alloc1.ID)
+	must.NoError(t, err)
+	must.Eq(t, alloc1.ID, resp.Alloc.ID)
+
+	_, err = get(alloc2.ID)
+	must.True(t, structs.IsErrUnknownAllocation(err))
+
+	s1.config.ACLWorkloadPolicy = &acl.WorkloadPolicy{Alloc: acl.PolicyDeny}
+	_, err = get(alloc1.ID)
+	must.True(t, structs.IsErrUnknownAllocation(err))
+}
+
 func TestAllocEndpoint_GetAlloc_Blocking(t *testing.T) {
 	ci.Parallel(t)
 
@@ -1772,6 +1821,307 @@ func TestAlloc_SignIdentities_Bad(t *testing.T) {
 	must.Len(t, 1, resp.SignedIdentities)
 }
 
+func TestAlloc_SignIdentities_Batch(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	t.Cleanup(cleanupS1)
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForKeyring(t, s1.RPC, "global")
+	state := s1.fsm.State()
+
+	node := mock.Node()
+	must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, 100, node))
+
+	alloc1, alloc2 := mock.Alloc(), mock.Alloc()
+	for _, alloc := range []*structs.Allocation{alloc1, alloc2} {
+		alloc.Job.TaskGroups[0].Tasks[0].Identities = []*structs.WorkloadIdentity{
+			{Name: "alt", Audience: []string{"test"}},
+		}
+	}
+	must.NoError(t, state.UpsertJobSummary(101, mock.JobSummary(alloc1.JobID)))
+	must.NoError(t, state.UpsertJobSummary(102, mock.JobSummary(alloc2.JobID)))
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 103, []*structs.Allocation{alloc1}))
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 104, []*structs.Allocation{alloc2}))
+
+	idReq := func(allocID string) *structs.WorkloadIdentityRequest {
+		return &structs.WorkloadIdentityRequest{
+			AllocID: allocID,
+			WIHandle: structs.WIHandle{
+				WorkloadIdentifier: "web",
+				IdentityName:       "alt",
+			},
+		}
+	}
+
+	// A batch of multiple allocs with a duplicate request signs each identity
+	// once
+	req := &structs.AllocIdentitiesRequest{
+		Identities: []*structs.WorkloadIdentityRequest{
+			idReq(alloc1.ID), idReq(alloc2.ID), idReq(alloc1.ID),
+		},
+		QueryOptions: structs.QueryOptions{
+			Region:        "global",
+			AllowStale:    true,
+			MinQueryIndex: 103,
+			AuthToken:     node.SecretID,
+		},
+	}
+	var resp structs.AllocIdentitiesResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Alloc.SignIdentities", req, &resp))
+	must.Len(t, 0, resp.Rejections)
+	must.Len(t, 2, resp.SignedIdentities)
+	must.Eq(t, alloc1.ID, resp.SignedIdentities[0].AllocID)
+	must.Eq(t, alloc2.ID, resp.SignedIdentities[1].AllocID)
+
+	// If the newest alloc of the batch is gone but the state is recent enough,
+	// only its request is rejected
+	must.NoError(t, state.DeleteEval(105, nil, []string{alloc2.ID}, false))
+	req.Identities = []*structs.WorkloadIdentityRequest{idReq(alloc1.ID), idReq(alloc2.ID)}
+	req.MinQueryIndex = 103
+	resp = structs.AllocIdentitiesResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Alloc.SignIdentities", req, &resp))
+	must.Len(t, 1, resp.SignedIdentities)
+	must.Eq(t, alloc1.ID, resp.SignedIdentities[0].AllocID)
+	must.Len(t, 1, resp.Rejections)
+	must.Eq(t, alloc2.ID, resp.Rejections[0].AllocID)
+	must.Eq(t, structs.WIRejectionReasonMissingAlloc, resp.Rejections[0].Reason)
+}
+
+func TestAlloc_IdentityEvents(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	t.Cleanup(cleanupS1)
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForKeyring(t, s1.RPC, "global")
+	state := s1.fsm.State()
+
+	node, otherNode := mock.Node(), mock.Node()
+	must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, 100, node))
+	must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, 101, otherNode))
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	alloc.Job.TaskGroups[0].Tasks[0].Identities = []*structs.WorkloadIdentity{
+		{Name: "alt", Audience: []string{"test"}, TTL: time.Hour},
+	}
+	must.NoError(t, state.UpsertJobSummary(102, mock.JobSummary(alloc.JobID)))
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 103, []*structs.Allocation{alloc}))
+
+	broker, err := state.EventBroker()
+	must.NoError(t, err)
+	sub, err := broker.Subscribe(&stream.SubscribeRequest{
+		Topics:    map[structs.Topic][]string{structs.TopicWorkloadIdentity: {"*"}},
+		Namespace: "*",
+	})
+	must.NoError(t, err)
+	t.Cleanup(sub.Unsubscribe)
+
+	nextEvent := func(t *testing.T) structs.Event {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		events, err := sub.Next(ctx)
+		must.NoError(t, err)
+		must.Len(t, 1, events.Events)
+		return events.Events[0]
+	}
+
+	handle := structs.WIHandle{WorkloadIdentifier: "web", IdentityName: "alt"}
+
+	// Signing and renewing identities publishes events
+	for _, renewal := range []bool{false, true} {
+		req := &structs.AllocIdentitiesRequest{
+			Identities: []*structs.WorkloadIdentityRequest{
+				{AllocID: alloc.ID, WIHandle: handle, Renewal: renewal},
+			},
+			QueryOptions: structs.QueryOptions{
+				Region:        "global",
+				AllowStale:    true,
+				MinQueryIndex: 102,
+				AuthToken:     node.SecretID,
+			},
+		}
+		var resp structs.AllocIdentitiesResponse
+		must.NoError(t, msgpackrpc.CallWithCodec(codec, "Alloc.SignIdentities", req, &resp))
+		must.Len(t, 1, resp.SignedIdentities)
+
+		event := nextEvent(t)
+		must.Eq(t, structs.TopicWorkloadIdentity, event.Topic)
+		must.Eq(t, alloc.ID, event.Key)
+		must.Eq(t, alloc.Namespace, event.Namespace)
+		payload := event.Payload.(*structs.WorkloadIdentityStreamEvent).WorkloadIdentity
+		must.Eq(t, alloc.JobID, payload.JobID)
+		must.Eq(t, node.ID, payload.NodeID)
+		must.Eq(t, handle, payload.WIHandle)
+		must.Eq(t, resp.SignedIdentities[0].Expiration, payload.Expiration)
+		if renewal {
+			must.Eq(t, structs.TypeWorkloadIdentityRenewed, event.Type)
+		} else {
+			must.Eq(t, structs.TypeWorkloadIdentitySigned, event.Type)
+		}
+	}
+
+	// Nodes report the renewal failures of their allocations
+	req := &structs.AllocIdentityEventsRequest{
+		Events: []*structs.WorkloadIdentityEvent{{
+			AllocID:  alloc.ID,
+			WIHandle: handle,
+			Type:     structs.TypeWorkloadIdentityRenewalFailed,
+			Error:    "no servers",
+		}},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: node.SecretID,
+		},
+	}
+	lastIndex := s1.raft.LastIndex()
+	var resp structs.GenericResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Alloc.IdentityEvents", req, &resp))
+	must.Positive(t, resp.Index)
+
+	// The events aren't written to raft
+	must.Eq(t, lastIndex, s1.raft.LastIndex())
+
+	event := nextEvent(t)
+	must.Eq(t, structs.TypeWorkloadIdentityRenewalFailed, event.Type)
+	payload := event.Payload.(*structs.WorkloadIdentityStreamEvent).WorkloadIdentity
+	must.Eq(t, "no servers", payload.Error)
+	must.Eq(t, alloc.JobID, payload.JobID)
+
+	// Nodes can't report events of the allocations of other nodes
+	req.AuthToken = otherNode.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.IdentityEvents", req, &resp)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	// Only the lifecycle event types can be reported
+	req.AuthToken = node.SecretID
+	req.Events[0].Type = structs.TypeJobRegistered
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.IdentityEvents", req, &resp)
+	must.ErrorContains(t, err, "invalid workload identity event type")
+}
+
+func TestAlloc_MigrationIntroduction(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	t.Cleanup(cleanupS1)
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	prevNode := mock.Node()
+	prevNode.Attributes["nomad.version"] = "1.7.0"
+	node := mock.Node()
+	must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, 100, prevNode))
+	must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, 101, node))
+
+	prevAlloc := mock.Alloc()
+	prevAlloc.NodeID = prevNode.ID
+	alloc := mock.Alloc()
+	alloc.Job = prevAlloc.Job
+	alloc.JobID = prevAlloc.JobID
+	alloc.NodeID = node.ID
+	alloc.PreviousAllocation = prevAlloc.ID
+	must.NoError(t, state.UpsertJobSummary(102, mock.JobSummary(alloc.JobID)))
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 103, []*structs.Allocation{prevAlloc, alloc}))
+
+	req := &structs.AllocMigrationIntroductionRequest{
+		AllocID: alloc.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: prevNode.SecretID,
+		},
+	}
+	var resp structs.AllocMigrationIntroductionResponse
+
+	// Only the node of the allocation can get introduced
+	err := msgpackrpc.CallWithCodec(codec, "Alloc.MigrationIntroduction", req, &resp)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	// The allocation must migrate its ephemeral disk
+	req.AuthToken = node.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.MigrationIntroduction", req, &resp)
+	must.ErrorContains(t, err, "does not migrate its ephemeral disk")
+
+	// The node of the previous allocation must be connected to authorize
+	// the token
+	alloc = alloc.Copy()
+	alloc.Job = alloc.Job.Copy()
+	alloc.Job.TaskGroups[0].EphemeralDisk.Migrate = true
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 104, []*structs.Allocation{alloc}))
+	err = msgpackrpc.CallWithCodec(codec, "Alloc.MigrationIntroduction", req, &resp)
+	must.ErrorContains(t, err, "failed to authorize migration")
+	must.ErrorContains(t, err, structs.ErrNoNodeConn.Error())
+}
+
+func TestAlloc_SignIdentities_SPIFFE(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	t.Cleanup(cleanupS1)
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForKeyring(t, s1.RPC, "global")
+
+	node := mock.Node()
+	must.NoError(t, s1.fsm.State().UpsertNode(structs.MsgTypeTestSetup, 100, node))
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	alloc.Job.TaskGroups[0].Tasks[0].Identities = []*structs.WorkloadIdentity{
+		{
+			Name:     "spiffe",
+			Audience: []string{"spire"},
+			TTL:      time.Hour,
+			SPIFFE:   &structs.WorkloadIdentitySPIFFE{JWT: true},
+		},
+		{
+			Name:     "alt",
+			Audience: []string{"test"},
+		},
+	}
+	must.NoError(t, s1.fsm.State().UpsertJobSummary(999, mock.JobSummary(alloc.JobID)))
+	must.NoError(t, s1.fsm.State().UpsertAllocs(structs.MsgTypeTestSetup, 1000, []*structs.Allocation{alloc}))
+
+	req := &structs.AllocIdentitiesRequest{
+		Identities: []*structs.WorkloadIdentityRequest{
+			{
+				AllocID:  alloc.ID,
+				WIHandle: structs.WIHandle{WorkloadIdentifier: "web", IdentityName: "spiffe"},
+			},
+			{
+				AllocID:  alloc.ID,
+				WIHandle: structs.WIHandle{WorkloadIdentifier: "web", IdentityName: "alt"},
+			},
+		},
+		QueryOptions: structs.QueryOptions{
+			Region:     "global",
+			Namespace:  structs.DefaultNamespace,
+			AllowStale: true,
+			AuthToken:  node.SecretID,
+		},
+	}
+	var resp structs.AllocIdentitiesResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Alloc.SignIdentities", req, &resp))
+	must.Len(t, 2, resp.SignedIdentities)
+
+	subjects := map[string]string{}
+	for _, sid := range resp.SignedIdentities {
+		claims, err := s1.encrypter.VerifyClaim(sid.JWT)
+		must.NoError(t, err)
+		subjects[sid.IdentityName] = claims.Subject
+	}
+
+	// The subject of the JWT-SVID is the SPIFFE ID of the task, and the one
+	// of other identities is unchanged.
+	must.Eq(t, "spiffe://nomad/ns/default/job/"+alloc.JobID+"/group/web/task/web", subjects["spiffe"])
+	must.StrHasPrefix(t, "global:default:"+alloc.JobID+":web:web:alt", subjects["alt"])
+}
+
 // TestAlloc_SignIdentities_Blocking asserts that if a server is behind the
 // desired index the signing request will block until the index is reached.
 func TestAlloc_SignIdentities_Blocking(t *testing.T) {
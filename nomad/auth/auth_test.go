@@ -1139,9 +1139,10 @@ func TestResolveClaims(t *testing.T) {
 
 func testStateStore(t *testing.T) *state.StateStore {
 	sconfig := &state.StateStoreConfig{
-		Logger:             testlog.HCLogger(t),
-		Region:             "global",
-		JobTrackedVersions: structs.JobDefaultTrackedVersions,
+		Logger:                  testlog.HCLogger(t),
+		Region:                  "global",
+		JobTrackedVersions:      structs.JobDefaultTrackedVersions,
+		JobTrackedScalingEvents: structs.JobTrackedScalingEvents,
 	}
 	store, err := state.NewStateStore(sconfig)
 	must.NoError(t, err)
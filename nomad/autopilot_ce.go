@@ -7,13 +7,20 @@
 package nomad
 
 import (
+	"slices"
+
+	"github.com/hashicorp/raft"
 	autopilot "github.com/hashicorp/raft-autopilot"
 
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
+// nodeTypeNonVoter is the autopilot node type of the servers kept as
+// non-voting servers.
+const nodeTypeNonVoter autopilot.NodeType = "non-voter"
+
 func (s *Server) autopilotPromoter() autopilot.Promoter {
-	return autopilot.DefaultPromoter()
+	return &nonVoterPromoter{Promoter: autopilot.DefaultPromoter()}
 }
 
 // autopilotServerExt returns the autopilot-enterprise.Server extensions needed
@@ -22,8 +29,55 @@ func (s *Server) autopilotServerExt(_ *serverParts) interface{} {
 	return nil
 }
 
-// autopilotConfigExt returns the autopilot-enterprise.Config extensions needed
-// for ENT feature support, but this is the empty OSS implementation.
-func autopilotConfigExt(_ *structs.AutopilotConfig) interface{} {
-	return nil
+// autopilotConfigExt returns the set of IDs of the servers configured as
+// non-voting servers, used by the promoter.
+func autopilotConfigExt(c *structs.AutopilotConfig) interface{} {
+	nonVoters := make(map[raft.ServerID]struct{}, len(c.NonVoters))
+	for _, id := range c.NonVoters {
+		nonVoters[raft.ServerID(id)] = struct{}{}
+	}
+	return nonVoters
+}
+
+// nonVoterPromoter promotes stable servers like the default promoter, except
+// for non-voting servers: servers started with non_voting_server, or whose ID
+// is in the non-voters of the autopilot configuration. Non-voting servers
+// that are voters are demoted, unless they're the leader.
+type nonVoterPromoter struct {
+	autopilot.Promoter
+}
+
+func (p *nonVoterPromoter) GetNodeTypes(c *autopilot.Config, s *autopilot.State) map[raft.ServerID]autopilot.NodeType {
+	types := p.Promoter.GetNodeTypes(c, s)
+	for id, srv := range s.Servers {
+		if isNonVoterServer(c, &srv.Server) {
+			types[id] = nodeTypeNonVoter
+		}
+	}
+	return types
+}
+
+func (p *nonVoterPromoter) CalculatePromotionsAndDemotions(c *autopilot.Config, s *autopilot.State) autopilot.RaftChanges {
+	changes := p.Promoter.CalculatePromotionsAndDemotions(c, s)
+	changes.Promotions = slices.DeleteFunc(changes.Promotions, func(id raft.ServerID) bool {
+		srv, ok := s.Servers[id]
+		return ok && isNonVoterServer(c, &srv.Server)
+	})
+
+	for id, srv := range s.Servers {
+		if srv.State == autopilot.RaftVoter && isNonVoterServer(c, &srv.Server) {
+			changes.Demotions = append(changes.Demotions, id)
+		}
+	}
+	return changes
+}
+
+// isNonVoterServer returns true if the server must be a non-voting server.
+func isNonVoterServer(c *autopilot.Config, srv *autopilot.Server) bool {
+	if srv.Meta["nonvoter"] == "1" {
+		return true
+	}
+	nonVoters, _ := c.Ext.(map[raft.ServerID]struct{})
+	_, ok := nonVoters[srv.ID]
+	return ok
 }
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build !ent
+// +build !ent
+
+package nomad
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/raft"
+	autopilot "github.com/hashicorp/raft-autopilot"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestAutopilot_NonVoterPromoter(t *testing.T) {
+	ci.Parallel(t)
+
+	stable := &autopilot.ServerHealth{Healthy: true, StableSince: time.Now().Add(-time.Hour)}
+	server := func(id string, state autopilot.RaftState, meta map[string]string) *autopilot.ServerState {
+		return &autopilot.ServerState{
+			Server: autopilot.Server{ID: raft.ServerID(id), Meta: meta},
+			State:  state,
+			Health: *stable,
+		}
+	}
+
+	conf := &autopilot.Config{
+		ServerStabilizationTime: 10 * time.Second,
+		Ext:                     autopilotConfigExt(&structs.AutopilotConfig{NonVoters: []string{"configured", "voter", "leader"}}),
+	}
+	state := &autopilot.State{
+		Leader: "leader",
+		Servers: map[raft.ServerID]*autopilot.ServerState{
+			"leader":     server("leader", autopilot.RaftLeader, nil),
+			"new":        server("new", autopilot.RaftNonVoter, nil),
+			"tagged":     server("tagged", autopilot.RaftNonVoter, map[string]string{"nonvoter": "1"}),
+			"configured": server("configured", autopilot.RaftNonVoter, nil),
+			"voter":      server("voter", autopilot.RaftVoter, nil),
+		},
+	}
+
+	promoter := &nonVoterPromoter{Promoter: autopilot.DefaultPromoter()}
+	changes := promoter.CalculatePromotionsAndDemotions(conf, state)
+	must.Eq(t, []raft.ServerID{"new"}, changes.Promotions)
+	must.Eq(t, []raft.ServerID{"voter"}, changes.Demotions)
+
+	types := promoter.GetNodeTypes(conf, state)
+	must.Eq(t, autopilot.NodeVoter, types["new"])
+	must.Eq(t, nodeTypeNonVoter, types["tagged"])
+	must.Eq(t, nodeTypeNonVoter, types["configured"])
+}
+
+func TestAutopilot_DemoteNonVoter(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // reduces test log noise
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	s2, cleanupS2 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // reduces test log noise
+		c.BootstrapExpect = 0
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer cleanupS2()
+	TestJoin(t, s1, s2)
+
+	waitForSuffrage := func(suffrage raft.ServerSuffrage) {
+		testutil.WaitForResultUntil(10*time.Second, func() (bool, error) {
+			future := s1.raft.GetConfiguration()
+			if err := future.Error(); err != nil {
+				return false, err
+			}
+			for _, server := range future.Configuration().Servers {
+				if server.ID == raft.ServerID(s2.config.NodeID) {
+					if server.Suffrage != suffrage {
+						return false, fmt.Errorf("expected server to be %v: %v", suffrage, server)
+					}
+					return true, nil
+				}
+			}
+			return false, fmt.Errorf("server not found")
+		}, func(err error) { must.NoError(t, err) })
+	}
+	waitForSuffrage(raft.Voter)
+
+	// Demote the server, then promote it again.
+	args := &structs.RaftSetVoterRequest{
+		ID:           raft.ServerID(s2.config.NodeID),
+		Voter:        false,
+		WriteRequest: structs.WriteRequest{Region: s1.config.Region},
+	}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.RaftSetVoter", args, &struct{}{}))
+	waitForSuffrage(raft.Nonvoter)
+
+	args.Voter = true
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.RaftSetVoter", args, &struct{}{}))
+	waitForSuffrage(raft.Voter)
+}
@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"math"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// batchFairShareInterval is the interval at which the leader updates the
+	// fair share the evaluations of batch jobs are dequeued by.
+	batchFairShareInterval = 10 * time.Second
+
+	// batchFairShareHalfLife is the half-life of the recent usage of the
+	// namespaces, so the usage of the last minutes weighs more than the usage
+	// of the last hour.
+	batchFairShareHalfLife = 5 * time.Minute
+)
+
+// watchBatchFairShare is a long lived function that periodically updates the
+// fair share of the namespaces in the eval broker while the batch fair share
+// is enabled in the scheduler configuration. It runs on the leader.
+func (s *Server) watchBatchFairShare(stopCh chan struct{}) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	var usage map[string]float64
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-timer.C:
+			timer.Reset(batchFairShareInterval)
+			usage = s.updateBatchFairShare(usage, batchFairShareInterval)
+		}
+	}
+}
+
+// updateBatchFairShare updates the fair share of the namespaces in the eval
+// broker from their recent usage, and returns the updated usage.
+func (s *Server) updateBatchFairShare(usage map[string]float64, elapsed time.Duration) map[string]float64 {
+	_, schedConfig, err := s.State().SchedulerConfig()
+	if err != nil {
+		s.logger.Error("failed to get scheduler configuration", "error", err)
+		return usage
+	}
+	if schedConfig == nil || !schedConfig.BatchFairShareConfig.Enabled {
+		s.evalBroker.SetBatchFairShare(nil)
+		return nil
+	}
+
+	current, err := batchDominantShares(s.State())
+	if err != nil {
+		s.logger.Error("failed to compute batch fair share", "error", err)
+		return usage
+	}
+	usage = decayBatchUsage(usage, current, elapsed)
+
+	shares := make(map[string]float64, len(usage))
+	for ns, u := range usage {
+		shares[ns] = u / float64(schedConfig.BatchFairShareConfig.Weight(ns))
+	}
+	s.evalBroker.SetBatchFairShare(shares)
+	return usage
+}
+
+// batchDominantShares returns the dominant share of each namespace, which is
+// the largest fraction of the CPU or memory of the ready nodes allocated to
+// the running allocations of its batch jobs.
+func batchDominantShares(store *state.StateStore) (map[string]float64, error) {
+	ws := memdb.NewWatchSet()
+	nodes, err := store.Nodes(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	var cpu, memory float64
+	for raw := nodes.Next(); raw != nil; raw = nodes.Next() {
+		node := raw.(*structs.Node)
+		if !node.Ready() || node.NodeResources == nil {
+			continue
+		}
+		capacity := node.NodeResources.Comparable()
+		capacity.Subtract(node.ReservedResources.Comparable())
+		cpu += float64(capacity.Flattened.Cpu.CpuShares)
+		memory += float64(capacity.Flattened.Memory.MemoryMB)
+	}
+
+	shares := make(map[string]float64)
+	if cpu <= 0 || memory <= 0 {
+		return shares, nil
+	}
+
+	allocs, err := store.Allocs(ws, state.SortDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]*structs.ComparableResources)
+	for raw := allocs.Next(); raw != nil; raw = allocs.Next() {
+		alloc := raw.(*structs.Allocation)
+		if alloc.TerminalStatus() || alloc.Job == nil || alloc.Job.Type != structs.JobTypeBatch ||
+			alloc.AllocatedResources == nil {
+			continue
+		}
+		if u, ok := used[alloc.Namespace]; ok {
+			u.Add(alloc.AllocatedResources.Comparable())
+		} else {
+			used[alloc.Namespace] = alloc.AllocatedResources.Comparable()
+		}
+	}
+
+	for ns, u := range used {
+		shares[ns] = max(
+			float64(u.Flattened.Cpu.CpuShares)/cpu,
+			float64(u.Flattened.Memory.MemoryMB)/memory,
+		)
+	}
+	return shares, nil
+}
+
+// decayBatchUsage returns the recent usage of the namespaces, which is the
+// moving average of their dominant share decayed by batchFairShareHalfLife.
+func decayBatchUsage(usage, current map[string]float64, elapsed time.Duration) map[string]float64 {
+	decay := math.Pow(0.5, elapsed.Seconds()/batchFairShareHalfLife.Seconds())
+
+	out := make(map[string]float64, len(current))
+	for ns, u := range usage {
+		out[ns] = u * decay
+	}
+	for ns, share := range current {
+		out[ns] += share * (1 - decay)
+	}
+
+	// Forget the namespaces whose usage is negligible.
+	for ns, u := range out {
+		if u < 1e-6 {
+			delete(out, ns)
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
+)
+
+func TestBatchDominantShares(t *testing.T) {
+	ci.Parallel(t)
+
+	store := state.TestStateStore(t)
+
+	node := mock.Node()
+	node.ReservedResources = nil
+	must.NoError(t, store.UpsertNode(structs.MsgTypeTestSetup, 1000, node))
+
+	// The batch allocation uses half of the memory of the node.
+	alloc := mock.BatchAlloc()
+	alloc.NodeID = node.ID
+	alloc.AllocatedResources.Tasks["web"].Memory.MemoryMB = 4096
+
+	// Terminal allocations and allocations of service jobs are ignored.
+	stopped := mock.BatchAlloc()
+	stopped.NodeID = node.ID
+	stopped.DesiredStatus = structs.AllocDesiredStatusStop
+	stopped.ClientStatus = structs.AllocClientStatusComplete
+
+	service := mock.Alloc()
+	service.NodeID = node.ID
+	service.Namespace = "other"
+
+	must.NoError(t, store.UpsertAllocs(structs.MsgTypeTestSetup, 1001,
+		[]*structs.Allocation{alloc, stopped, service}))
+
+	shares, err := batchDominantShares(store)
+	must.NoError(t, err)
+	must.Eq(t, map[string]float64{"default": 0.5}, shares)
+}
+
+func TestDecayBatchUsage(t *testing.T) {
+	ci.Parallel(t)
+
+	usage := decayBatchUsage(nil, map[string]float64{"a": 1}, batchFairShareHalfLife)
+	must.Eq(t, map[string]float64{"a": 0.5}, usage)
+
+	// The usage of the namespaces that stopped running batch jobs decays
+	// until it's forgotten.
+	usage = decayBatchUsage(usage, map[string]float64{"b": 0.5}, batchFairShareHalfLife)
+	must.Eq(t, map[string]float64{"a": 0.25, "b": 0.25}, usage)
+
+	usage = decayBatchUsage(usage, nil, 100*batchFairShareHalfLife)
+	must.MapEmpty(t, usage)
+}
+
+func TestServer_updateBatchFairShare(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	defer cleanupS1()
+	testutil.WaitForLeader(t, s1.RPC)
+	store := s1.fsm.State()
+
+	node := mock.Node()
+	node.ReservedResources = nil
+	must.NoError(t, store.UpsertNode(structs.MsgTypeTestSetup, 1000, node))
+
+	alloc := mock.BatchAlloc()
+	alloc.NodeID = node.ID
+	alloc.AllocatedResources.Tasks["web"].Memory.MemoryMB = 4096
+	must.NoError(t, store.UpsertAllocs(structs.MsgTypeTestSetup, 1001, []*structs.Allocation{alloc}))
+
+	_, config, err := store.SchedulerConfig()
+	must.NoError(t, err)
+	config = config.Copy()
+	config.BatchFairShareConfig = structs.BatchFairShareConfig{
+		Enabled:          true,
+		NamespaceWeights: map[string]int{"default": 2},
+	}
+	must.NoError(t, store.SchedulerSetConfig(1002, config))
+
+	// The share is the usage decayed over a half-life divided by the weight.
+	usage := s1.updateBatchFairShare(nil, batchFairShareHalfLife)
+	must.Eq(t, map[string]float64{"default": 0.25}, usage)
+
+	s1.evalBroker.l.RLock()
+	must.Eq(t, map[string]float64{"default": 0.125}, s1.evalBroker.batchFairShare)
+	s1.evalBroker.l.RUnlock()
+
+	// Disabling the fair share restores the priority order.
+	config = config.Copy()
+	config.BatchFairShareConfig.Enabled = false
+	must.NoError(t, store.SchedulerSetConfig(1003, config))
+
+	must.Nil(t, s1.updateBatchFairShare(usage, batchFairShareHalfLife))
+
+	s1.evalBroker.l.RLock()
+	must.Nil(t, s1.evalBroker.batchFairShare)
+	s1.evalBroker.l.RUnlock()
+}
@@ -4,6 +4,8 @@
 package nomad
 
 import (
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -82,6 +84,11 @@ type BlockedEvals struct {
 
 	// stopCh is used to stop any created goroutines.
 	stopCh chan struct{}
+
+	// namespaceWeights is the share of the unblocked evaluations each
+	// namespace gets when they are enqueued. Namespaces without a weight have
+	// a weight of 1.
+	namespaceWeights map[string]int
 }
 
 // capacityUpdate stores unblock data.
@@ -115,6 +122,14 @@ func NewBlockedEvals(evalBroker *EvalBroker, logger hclog.Logger) *BlockedEvals
 	}
 }
 
+// SetNamespaceWeights sets the weights used to share the unblocked
+// evaluations across namespaces.
+func (b *BlockedEvals) SetNamespaceWeights(weights map[string]int) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.namespaceWeights = weights
+}
+
 // Enabled is used to check if the broker is enabled.
 func (b *BlockedEvals) Enabled() bool {
 	b.l.RLock()
@@ -599,7 +614,7 @@ func (b *BlockedEvals) unblock(computedClass, quota string, index uint64) {
 		}
 
 		// Enqueue all the unblocked evals into the broker.
-		b.evalBroker.EnqueueAll(unblocked)
+		b.enqueueFairShare(unblocked)
 	}
 }
 
@@ -645,10 +660,68 @@ func (b *BlockedEvals) UnblockFailed() {
 			b.stats.Unblock(eval)
 		}
 
-		b.evalBroker.EnqueueAll(unblocked)
+		b.enqueueFairShare(unblocked)
 	}
 }
 
+// enqueueFairShare enqueues the unblocked evaluations so that evaluations of
+// the same priority are dequeued in a weighted round robin across namespaces
+// instead of in the order they were created. This prevents the backlog of a
+// single namespace from using all the capacity that becomes available. The
+// unblocked evaluations of a priority take the place of each other in the
+// broker, so their order relative to other evaluations doesn't change.
+func (b *BlockedEvals) enqueueFairShare(unblocked map[*structs.Evaluation]string) {
+	byPriority := make(map[int][]*structs.Evaluation)
+	for eval := range unblocked {
+		byPriority[eval.Priority] = append(byPriority[eval.Priority], eval)
+	}
+
+	sortIndex := make(map[string]uint64, len(unblocked))
+	for _, evals := range byPriority {
+		indexes := make([]uint64, 0, len(evals))
+		for _, eval := range evals {
+			indexes = append(indexes, eval.CreateIndex)
+		}
+		slices.Sort(indexes)
+
+		for i, eval := range fairShareOrder(evals, b.namespaceWeights) {
+			sortIndex[eval.ID] = indexes[i]
+		}
+	}
+
+	b.evalBroker.EnqueueAllOrdered(unblocked, sortIndex)
+}
+
+// fairShareOrder returns the evaluations in a weighted round robin across
+// their namespaces. Each round takes as many of the oldest evaluations of a
+// namespace as its weight.
+func fairShareOrder(evals []*structs.Evaluation, weights map[string]int) []*structs.Evaluation {
+	byNamespace := make(map[string][]*structs.Evaluation)
+	for _, eval := range evals {
+		byNamespace[eval.Namespace] = append(byNamespace[eval.Namespace], eval)
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace, nsEvals := range byNamespace {
+		sort.Slice(nsEvals, func(i, j int) bool {
+			return nsEvals[i].CreateIndex < nsEvals[j].CreateIndex
+		})
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+
+	ordered := make([]*structs.Evaluation, 0, len(evals))
+	for len(ordered) < len(evals) {
+		for _, namespace := range namespaces {
+			weight := max(weights[namespace], 1)
+			n := min(weight, len(byNamespace[namespace]))
+			ordered = append(ordered, byNamespace[namespace][:n]...)
+			byNamespace[namespace] = byNamespace[namespace][n:]
+		}
+	}
+	return ordered
+}
+
 // GetDuplicates returns all the duplicate evaluations and blocks until the
 // passed timeout.
 func (b *BlockedEvals) GetDuplicates(timeout time.Duration) []*structs.Evaluation {
@@ -726,7 +799,7 @@ func (b *BlockedEvals) Stats() *BlockedStats {
 }
 
 // EmitStats is used to export metrics about the blocked eval tracker while enabled
-func (b *BlockedEvals) EmitStats(period time.Duration, stopCh <-chan struct{}) {
+func (b *BlockedEvals) EmitStats(period time.Duration, labeler *jobMetricsLabeler, stopCh <-chan struct{}) {
 	timer, stop := helper.NewSafeTimer(period)
 	defer stop()
 
@@ -740,14 +813,16 @@ func (b *BlockedEvals) EmitStats(period time.Duration, stopCh <-chan struct{}) {
 			metrics.SetGauge([]string{"nomad", "blocked_evals", "total_blocked"}, float32(stats.TotalBlocked))
 			metrics.SetGauge([]string{"nomad", "blocked_evals", "total_escaped"}, float32(stats.TotalEscaped))
 
+			gauges := newJobGauges()
 			for k, v := range stats.BlockedResources.ByJob {
-				labels := []metrics.Label{
-					{Name: "namespace", Value: k.Namespace},
-					{Name: "job", Value: k.ID},
+				labels, ok := labeler.labels(k.Namespace, k.ID, "")
+				if !ok {
+					continue
 				}
-				metrics.SetGaugeWithLabels([]string{"nomad", "blocked_evals", "job", "cpu"}, float32(v.CPU), labels)
-				metrics.SetGaugeWithLabels([]string{"nomad", "blocked_evals", "job", "memory"}, float32(v.MemoryMB), labels)
+				gauges.add([]string{"nomad", "blocked_evals", "job", "cpu"}, float32(v.CPU), labels)
+				gauges.add([]string{"nomad", "blocked_evals", "job", "memory"}, float32(v.MemoryMB), labels)
 			}
+			gauges.emit()
 
 			for k, v := range stats.BlockedResources.ByClassInDC {
 				labels := []metrics.Label{
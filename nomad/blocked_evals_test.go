@@ -189,6 +189,36 @@ func TestBlockedEvals_UnblockEscaped(t *testing.T) {
 	requireBlockedEvalsEnqueued(t, blocked, broker, 1)
 }
 
+func TestBlockedEvals_UnblockFairShare(t *testing.T) {
+	ci.Parallel(t)
+
+	blocked, broker := testBlockedEvals(t)
+	blocked.SetNamespaceWeights(map[string]int{"b": 2})
+
+	// Block evals of namespace a before the ones of namespace b, which
+	// would be dequeued first if they were unblocked in order.
+	for i, namespace := range []string{"a", "a", "a", "b", "b", "b"} {
+		e := mock.BlockedEval()
+		e.ID = fmt.Sprintf("%s%d", namespace, i+1)
+		e.Namespace = namespace
+		e.CreateIndex = uint64(i + 1)
+		e.EscapedComputedClass = true
+		blocked.Block(e)
+	}
+
+	blocked.Unblock("v1:123", 1000)
+	requireBlockedEvalsEnqueued(t, blocked, broker, 6)
+
+	var order []string
+	for i := 0; i < 6; i++ {
+		eval, _, err := broker.Dequeue([]string{structs.JobTypeService}, time.Second)
+		must.NoError(t, err)
+		must.NotNil(t, eval)
+		order = append(order, eval.ID)
+	}
+	must.Eq(t, []string{"a1", "b4", "b5", "a2", "b6", "a3"}, order)
+}
+
 func requireBlockedEvalsEnqueued(t *testing.T, blocked *BlockedEvals, broker *EvalBroker, enqueued int) {
 	testutil.WaitForResult(func() (bool, error) {
 		// Verify Unblock caused an enqueue
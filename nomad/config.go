@@ -13,6 +13,7 @@ import (
 
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/memberlist"
+	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
@@ -67,11 +68,24 @@ type Config struct {
 	// EventBufferSize is the amount of events to hold in memory.
 	EventBufferSize int64
 
+	// EnableChangeFeed is used to publish the change feed events, which
+	// include every change to the jobs, allocations, evaluations,
+	// deployments, nodes, node pools, and namespaces
+	EnableChangeFeed bool
+
 	// JobMaxSourceSize limits the maximum size of a jobs source hcl/json
 	// before being discarded automatically. A value of zero indicates no job
 	// sources will be stored.
 	JobMaxSourceSize int
 
+	// PayloadStore configures the object store the dispatch payloads and job
+	// sources above a size threshold are kept in instead of the Raft log.
+	PayloadStore *config.PayloadStoreConfig
+
+	// AuditAccess configures the audit events recording the accesses to
+	// variables and to the keys of the keyring.
+	AuditAccess *config.AuditAccessConfig
+
 	// LogOutput is the location to write logs to. If this is not set,
 	// logs will go to stderr.
 	LogOutput io.Writer
@@ -352,6 +366,10 @@ type Config struct {
 	// for ACL token expiration.
 	ACLTokenMaxExpirationTTL time.Duration
 
+	// ACLWorkloadPolicy is the implicit policy granting workload identities
+	// access to the objects of their own job.
+	ACLWorkloadPolicy *acl.WorkloadPolicy
+
 	// SentinelGCInterval is the interval that we GC unused policies.
 	SentinelGCInterval time.Duration
 
@@ -390,6 +408,39 @@ type Config struct {
 	// and this value is ignored.
 	DefaultSchedulerConfig structs.SchedulerConfiguration `hcl:"default_scheduler_config"`
 
+	// ScoringPlugins are the external gRPC services that adjust the score of
+	// nodes during placements.
+	ScoringPlugins []*config.ScoringPluginConfig
+
+	// SignerPlugin is the external gRPC service that signs workload
+	// identities in place of the keyring, if set.
+	SignerPlugin *config.SignerPluginConfig
+
+	// JobLint configures the lint rules evaluated when jobs are planned or
+	// registered.
+	JobLint *config.JobLintConfig
+
+	// UserDirectoryLookupDrivers is the list of drivers whose task users are
+	// managed by the directory service of the clients.
+	UserDirectoryLookupDrivers []string
+
+	// JobMetricsLabels controls the labels attached to the metrics emitted
+	// per job.
+	JobMetricsLabels *config.JobMetricsLabelsConfig
+
+	// WorkloadCA configures the CA used to sign the X.509 certificates of
+	// workload identities. Workload certificates are disabled if nil.
+	WorkloadCA *config.WorkloadCAConfig
+
+	// ServiceSyncs configure the export of the Nomad native services to
+	// external registries. The leader runs the syncs.
+	ServiceSyncs []*config.ServiceSyncConfig
+
+	// NamespaceUnblockWeights is the share of the unblocked evaluations each
+	// namespace gets when capacity becomes available. Namespaces without a
+	// weight have a weight of 1.
+	NamespaceUnblockWeights map[string]int
+
 	// RPCHandshakeTimeout is the deadline by which RPC handshakes must
 	// complete. The RPC handshake includes the first byte read as well as
 	// the TLS handshake and subsequent byte read if TLS is enabled.
@@ -430,6 +481,15 @@ type Config struct {
 	// JobTrackedVersions is the number of historic Job versions that are kept.
 	JobTrackedVersions int
 
+	// JobTrackedScalingEvents is the number of scaling events that are kept
+	// for a single task group.
+	JobTrackedScalingEvents int
+
+	// RaftSnapshotChunkSize is the size of the checksummed chunks Raft
+	// snapshots are written in. Snapshots are written without chunks if it's
+	// zero.
+	RaftSnapshotChunkSize int
+
 	Reporting *config.ReportingConfig
 
 	// OIDCIssuer is the URL for the OIDC Issuer field in Workload Identity JWTs.
@@ -457,11 +517,13 @@ func (c *Config) Copy() *Config {
 	nc.RaftConfig = pointer.Copy(c.RaftConfig)
 	nc.SerfConfig = pointer.Copy(c.SerfConfig)
 	nc.EnabledSchedulers = slices.Clone(c.EnabledSchedulers)
+	nc.UserDirectoryLookupDrivers = slices.Clone(c.UserDirectoryLookupDrivers)
 	nc.ConsulConfig = c.ConsulConfig.Copy()
 	nc.ConsulConfigs = helper.DeepCopyMap(c.ConsulConfigs)
 	nc.VaultConfig = c.VaultConfig.Copy()
 	nc.VaultConfigs = helper.DeepCopyMap(c.VaultConfigs)
 	nc.TLSConfig = c.TLSConfig.Copy()
+	nc.ACLWorkloadPolicy = c.ACLWorkloadPolicy.Copy()
 	nc.SentinelConfig = c.SentinelConfig.Copy()
 	nc.AutopilotConfig = c.AutopilotConfig.Copy()
 	nc.LicenseConfig = c.LicenseConfig.Copy()
@@ -593,6 +655,7 @@ func DefaultConfig() *Config {
 		EventBufferSize:                  100,
 		ACLTokenMinExpirationTTL:         1 * time.Minute,
 		ACLTokenMaxExpirationTTL:         24 * time.Hour,
+		ACLWorkloadPolicy:                acl.DefaultWorkloadPolicy(),
 		AutopilotConfig: &structs.AutopilotConfig{
 			CleanupDeadServers:      true,
 			LastContactThreshold:    200 * time.Millisecond,
@@ -614,6 +677,7 @@ func DefaultConfig() *Config {
 		JobDefaultPriority:       structs.JobDefaultPriority,
 		JobMaxPriority:           structs.JobDefaultMaxPriority,
 		JobTrackedVersions:       structs.JobDefaultTrackedVersions,
+		JobTrackedScalingEvents:  structs.JobTrackedScalingEvents,
 	}
 
 	c.ConsulConfigs = map[string]*config.ConsulConfig{structs.ConsulDefaultCluster: c.ConsulConfig}
@@ -28,6 +28,10 @@ type CoreScheduler struct {
 	srv    *Server
 	snap   *state.StateSnapshot
 	logger log.Logger
+
+	// gcConfig overrides the GC thresholds and batch sizes of the server
+	// configuration with the ones of the scheduler configuration.
+	gcConfig structs.GCConfig
 }
 
 // NewCoreScheduler is used to return a new system scheduler instance
@@ -37,6 +41,9 @@ func NewCoreScheduler(srv *Server, snap *state.StateSnapshot) scheduler.Schedule
 		snap:   snap,
 		logger: srv.logger.ResetNamed("core.sched"),
 	}
+	if _, schedConfig, err := snap.SchedulerConfig(); err == nil && schedConfig != nil {
+		s.gcConfig = schedConfig.GCConfig
+	}
 	return s
 }
 
@@ -117,7 +124,7 @@ func (c *CoreScheduler) jobGC(eval *structs.Evaluation) error {
 	}
 
 	oldThreshold := c.getThreshold(eval, "job",
-		"job_gc_threshold", c.srv.config.JobGCThreshold)
+		"job_gc_threshold", c.gcConfig.EffectiveThreshold(c.gcConfig.JobThreshold, c.srv.config.JobGCThreshold))
 
 	// Collect the allocations, evaluations and jobs to GC
 	var gcAlloc, gcEval []string
@@ -183,7 +190,7 @@ OUTER:
 // jobReap contacts the leader and issues a reap on the passed jobs
 func (c *CoreScheduler) jobReap(jobs []*structs.Job, leaderACL string) error {
 	// Call to the leader to issue the reap
-	for _, req := range c.partitionJobReap(jobs, leaderACL, structs.MaxUUIDsPerWriteRequest) {
+	for _, req := range c.partitionJobReap(jobs, leaderACL, c.gcConfig.EffectiveBatchSize(c.gcConfig.JobBatchSize)) {
 		var resp structs.JobBatchDeregisterResponse
 		if err := c.srv.RPC("Job.BatchDeregister", req, &resp); err != nil {
 			c.logger.Error("batch job reap failed", "error", err)
@@ -242,9 +249,9 @@ func (c *CoreScheduler) evalGC(eval *structs.Evaluation) error {
 	}
 
 	oldThreshold := c.getThreshold(eval, "eval",
-		"eval_gc_threshold", c.srv.config.EvalGCThreshold)
+		"eval_gc_threshold", c.gcConfig.EffectiveThreshold(c.gcConfig.EvalThreshold, c.srv.config.EvalGCThreshold))
 	batchOldThreshold := c.getThreshold(eval, "eval",
-		"batch_eval_gc_threshold", c.srv.config.BatchEvalGCThreshold)
+		"batch_eval_gc_threshold", c.gcConfig.EffectiveThreshold(c.gcConfig.BatchEvalThreshold, c.srv.config.BatchEvalGCThreshold))
 
 	// Collect the allocations and evaluations to GC
 	var gcAlloc, gcEval []string
@@ -319,10 +326,10 @@ func (c *CoreScheduler) gcEval(eval *structs.Evaluation, thresholdIndex uint64,
 		// Can collect if either holds:
 		//   - Job doesn't exist
 		//   - Job is Stopped and dead
-		//   - allowBatch and the job is dead
+		//   - allowBatch and the job is dead and not suspended
 		//
 		// If we cannot collect outright, check if a partial GC may occur
-		collect := job == nil || job.Status == structs.JobStatusDead && (job.Stop || allowBatch)
+		collect := job == nil || job.Status == structs.JobStatusDead && (job.Stop || (allowBatch && !job.Suspended))
 		if !collect {
 			oldAllocs := olderVersionTerminalAllocs(allocs, job, thresholdIndex)
 			gcEval := (len(oldAllocs) == len(allocs))
@@ -363,7 +370,7 @@ func olderVersionTerminalAllocs(allocs []*structs.Allocation, job *structs.Job,
 // allocs.
 func (c *CoreScheduler) evalReap(evals, allocs []string) error {
 	// Call to the leader to issue the reap
-	for _, req := range c.partitionEvalReap(evals, allocs, structs.MaxUUIDsPerWriteRequest) {
+	for _, req := range c.partitionEvalReap(evals, allocs, c.gcConfig.EffectiveBatchSize(c.gcConfig.EvalBatchSize)) {
 		var resp structs.GenericResponse
 		if err := c.srv.RPC("Eval.Reap", req, &resp); err != nil {
 			c.logger.Error("eval reap failed", "error", err)
@@ -526,7 +533,7 @@ func (c *CoreScheduler) deploymentGC(eval *structs.Evaluation) error {
 	}
 
 	oldThreshold := c.getThreshold(eval, "deployment",
-		"deployment_gc_threshold", c.srv.config.DeploymentGCThreshold)
+		"deployment_gc_threshold", c.gcConfig.EffectiveThreshold(c.gcConfig.DeploymentThreshold, c.srv.config.DeploymentGCThreshold))
 
 	// Collect the deployments to GC
 	var gcDeployment []string
@@ -575,7 +582,7 @@ OUTER:
 // deployments.
 func (c *CoreScheduler) deploymentReap(deployments []string) error {
 	// Call to the leader to issue the reap
-	for _, req := range c.partitionDeploymentReap(deployments, structs.MaxUUIDsPerWriteRequest) {
+	for _, req := range c.partitionDeploymentReap(deployments, c.gcConfig.EffectiveBatchSize(c.gcConfig.DeploymentBatchSize)) {
 		var resp structs.GenericResponse
 		if err := c.srv.RPC("Deployment.Reap", req, &resp); err != nil {
 			c.logger.Error("deployment reap failed", "error", err)
@@ -718,7 +725,7 @@ func (c *CoreScheduler) csiVolumeClaimGC(eval *structs.Evaluation) error {
 	}
 
 	oldThreshold := c.getThreshold(eval, "CSI volume claim",
-		"csi_volume_claim_gc_threshold", c.srv.config.CSIVolumeClaimGCThreshold)
+		"csi_volume_claim_gc_threshold", c.gcConfig.EffectiveThreshold(c.gcConfig.CSIVolumeClaimThreshold, c.srv.config.CSIVolumeClaimGCThreshold))
 
 	for i := iter.Next(); i != nil; i = iter.Next() {
 		vol := i.(*structs.CSIVolume)
@@ -836,6 +843,7 @@ func (c *CoreScheduler) expiredACLTokenGC(eval *structs.Evaluation, global bool)
 	var (
 		expiredAccessorIDs []string
 		num                int
+		authMethods        = make(map[string]*structs.ACLAuthMethod)
 	)
 
 	// The memdb iterator contains all tokens which include an expiration time,
@@ -861,6 +869,14 @@ func (c *CoreScheduler) expiredACLTokenGC(eval *structs.Evaluation, global bool)
 			continue
 		}
 
+		// Tokens created by an auth method are kept while they can still be
+		// renewed.
+		if renewable, err := c.aclTokenRenewable(token, now, authMethods); err != nil {
+			return err
+		} else if renewable {
+			continue
+		}
+
 		// Add the token accessor ID to the tracking array, thus marking it
 		// ready for deletion.
 		expiredAccessorIDs = append(expiredAccessorIDs, token.AccessorID)
@@ -896,6 +912,29 @@ func (c *CoreScheduler) expiredACLTokenGC(eval *structs.Evaluation, global bool)
 	return c.srv.RPC(structs.ACLDeleteTokensRPCMethod, req, &structs.GenericResponse{})
 }
 
+// aclTokenRenewable returns whether an expired ACL token can still be renewed
+// by the auth method which created it. The auth methods are looked up once
+// and cached in authMethods.
+func (c *CoreScheduler) aclTokenRenewable(
+	token *structs.ACLToken, now time.Time, authMethods map[string]*structs.ACLAuthMethod) (bool, error) {
+
+	if token.AuthMethod == "" {
+		return false, nil
+	}
+
+	authMethod, ok := authMethods[token.AuthMethod]
+	if !ok {
+		var err error
+		authMethod, err = c.snap.GetACLAuthMethodByName(nil, token.AuthMethod)
+		if err != nil {
+			return false, err
+		}
+		authMethods[token.AuthMethod] = authMethod
+	}
+
+	return authMethod != nil && authMethod.TokenRenewable(token, now), nil
+}
+
 // rootKeyRotateOrGC is used to rotate or garbage collect root keys
 func (c *CoreScheduler) rootKeyRotateOrGC(eval *structs.Evaluation) error {
 
@@ -929,8 +968,8 @@ func (c *CoreScheduler) rootKeyGC(eval *structs.Evaluation) error {
 			break
 		}
 		keyMeta := raw.(*structs.RootKeyMeta)
-		if keyMeta.Active() || keyMeta.Rekeying() {
-			continue // never GC the active key or one we're rekeying
+		if keyMeta.Active() || keyMeta.Rekeying() || keyMeta.Prepublished() {
+			continue // never GC the active key or one we're rekeying or publishing
 		}
 		if keyMeta.CreateIndex > oldThreshold {
 			continue // don't GC recent keys
@@ -961,14 +1000,36 @@ func (c *CoreScheduler) rootKeyGC(eval *structs.Evaluation) error {
 	return nil
 }
 
-// rootKeyRotate checks if the active key is old enough that we need
-// to kick off a rotation.
+// rootKeyRotate checks if a prepublished key is ready to become active, or if
+// the active key is old enough that we need to kick off a rotation.
 func (c *CoreScheduler) rootKeyRotate(eval *structs.Evaluation) (bool, error) {
 
+	ws := memdb.NewWatchSet()
+	iter, err := c.snap.RootKeyMetas(ws)
+	if err != nil {
+		return false, err
+	}
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		keyMeta := raw.(*structs.RootKeyMeta)
+		if !keyMeta.Prepublished() {
+			continue
+		}
+
+		// Never rotate automatically while a key is prepublished, as the
+		// operator has already scheduled the next rotation.
+		if keyMeta.PublishTime > time.Now().UnixNano() {
+			return false, nil
+		}
+		return true, c.rootKeyActivate(eval, keyMeta)
+	}
+
 	rotationThreshold := c.getThreshold(eval, "root key",
 		"root_key_rotation_threshold", c.srv.config.RootKeyRotationThreshold)
 
-	ws := memdb.NewWatchSet()
 	activeKey, err := c.snap.GetActiveRootKeyMeta(ws)
 	if err != nil {
 		return false, err
@@ -995,6 +1056,32 @@ func (c *CoreScheduler) rootKeyRotate(eval *structs.Evaluation) (bool, error) {
 	return true, nil
 }
 
+// rootKeyActivate sets a prepublished key as the active key once its publish
+// time has passed.
+func (c *CoreScheduler) rootKeyActivate(eval *structs.Evaluation, keyMeta *structs.RootKeyMeta) error {
+	rootKey, err := c.srv.encrypter.GetRootKey(keyMeta.KeyID)
+	if err != nil {
+		return err
+	}
+	rootKey.Meta = keyMeta.Copy()
+	rootKey.Meta.SetActive()
+
+	req := &structs.KeyringUpdateRootKeyRequest{
+		RootKey: rootKey,
+		WriteRequest: structs.WriteRequest{
+			Region:    c.srv.config.Region,
+			AuthToken: eval.LeaderACL,
+		},
+	}
+	if err := c.srv.RPC("Keyring.Update",
+		req, &structs.KeyringUpdateRootKeyResponse{}); err != nil {
+		c.logger.Error("prepublished root key activation failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
 // variablesReKey is optionally run after rotating the active
 // root key. It iterates over all the variables for the keys in the
 // re-keying state, decrypts them, and re-encrypts them in batches
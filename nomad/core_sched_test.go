@@ -1789,6 +1789,65 @@ func TestCoreScheduler_DeploymentGC(t *testing.T) {
 	assert.NotNil(out3, "Terminal Deployment With Allocs")
 }
 
+func TestCoreScheduler_DeploymentGC_SchedulerConfig(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	defer cleanupS1()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// COMPAT Remove in 0.6: Reset the FSM time table since we reconcile which sets index 0
+	s1.fsm.timetable.table = make([]TimeTableEntry, 1, 10)
+
+	store := s1.fsm.State()
+	d1, d2 := mock.Deployment(), mock.Deployment()
+	d1.Status = structs.DeploymentStatusFailed
+	d2.Status = structs.DeploymentStatusSuccessful
+	must.NoError(t, store.UpsertDeployment(1000, d1))
+	must.NoError(t, store.UpsertDeployment(1001, d2))
+
+	// The deployments are older than the threshold of the scheduler
+	// configuration but newer than the one of the server configuration.
+	tt := s1.fsm.TimeTable()
+	tt.Witness(2000, time.Now().UTC().Add(-10*time.Minute))
+
+	gcDeployments := func() {
+		snap, err := store.Snapshot()
+		must.NoError(t, err)
+		core := NewCoreScheduler(s1, snap)
+		gc := s1.coreJobEval(structs.CoreJobDeploymentGC, 2000)
+		must.NoError(t, core.Process(gc))
+	}
+
+	// The server configuration applies by default.
+	gcDeployments()
+	out, err := store.DeploymentByID(nil, d1.ID)
+	must.NoError(t, err)
+	must.NotNil(t, out)
+
+	_, schedConfig, err := store.SchedulerConfig()
+	must.NoError(t, err)
+	schedConfig = schedConfig.Copy()
+	schedConfig.GCConfig = structs.GCConfig{
+		DeploymentThreshold: time.Minute,
+		DeploymentBatchSize: 1,
+	}
+	must.NoError(t, store.SchedulerSetConfig(2001, schedConfig))
+
+	snap, err := store.Snapshot()
+	must.NoError(t, err)
+	core := NewCoreScheduler(s1, snap).(*CoreScheduler)
+	must.Len(t, 2, core.partitionDeploymentReap([]string{d1.ID, d2.ID},
+		core.gcConfig.EffectiveBatchSize(core.gcConfig.DeploymentBatchSize)))
+
+	gcDeployments()
+	for _, id := range []string{d1.ID, d2.ID} {
+		out, err := store.DeploymentByID(nil, id)
+		must.NoError(t, err)
+		must.Nil(t, out)
+	}
+}
+
 func TestCoreScheduler_DeploymentGC_Force(t *testing.T) {
 	ci.Parallel(t)
 	for _, withAcl := range []bool{false, true} {
@@ -2551,6 +2610,72 @@ func TestCoreScheduler_RootKeyGC(t *testing.T) {
 
 }
 
+// TestCoreScheduler_RootKeyRotate_Prepublished asserts prepublished keys are
+// activated once their publish time has passed, and prevent automatic
+// rotations until then.
+func TestCoreScheduler_RootKeyRotate_Prepublished(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, cleanup := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer cleanup()
+	testutil.WaitForKeyring(t, srv.RPC, "global")
+
+	store := srv.fsm.State()
+	key0, err := store.GetActiveRootKeyMeta(nil)
+	must.NoError(t, err)
+	must.NotNil(t, key0)
+
+	// prepublish a key in the future
+	rotateReq := &structs.KeyringRotateRootKeyRequest{
+		PublishTime:  time.Now().Add(time.Hour).UnixNano(),
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var rotateResp structs.KeyringRotateRootKeyResponse
+	must.NoError(t, srv.RPC("Keyring.Rotate", rotateReq, &rotateResp))
+	key1 := rotateResp.Key
+
+	runCoreJob := func() {
+		t.Helper()
+		snap, err := store.Snapshot()
+		must.NoError(t, err)
+		core := NewCoreScheduler(srv, snap)
+		index := key1.ModifyIndex + 1
+		eval := srv.coreJobEval(structs.CoreJobRootKeyRotateOrGC, index)
+		c := core.(*CoreScheduler)
+		must.NoError(t, c.rootKeyRotateOrGC(eval))
+	}
+
+	// the prepublished key must not be activated, and the active key must
+	// not be rotated even if it's older than the rotation threshold
+	srv.config.RootKeyRotationThreshold = 0
+	runCoreJob()
+
+	activeKey, err := store.GetActiveRootKeyMeta(nil)
+	must.NoError(t, err)
+	must.Eq(t, key0.KeyID, activeKey.KeyID)
+
+	// once the publish time has passed the key must become active
+	key1 = key1.Copy()
+	key1.PublishTime = time.Now().Add(-time.Minute).UnixNano()
+	must.NoError(t, store.UpsertRootKeyMeta(key1.ModifyIndex+10, key1, false))
+	runCoreJob()
+
+	activeKey, err = store.GetActiveRootKeyMeta(nil)
+	must.NoError(t, err)
+	must.Eq(t, key1.KeyID, activeKey.KeyID)
+
+	oldKey, err := store.RootKeyMetaByID(nil, key0.KeyID)
+	must.NoError(t, err)
+	must.True(t, oldKey.Inactive())
+
+	// the signing key must have been kept when activating the key
+	pubKey, err := srv.encrypter.GetPublicKey(key1.KeyID)
+	must.NoError(t, err)
+	must.Eq(t, structs.PubKeyAlgRS256, pubKey.Algorithm)
+}
+
 // TestCoreScheduler_VariablesRekey exercises variables rekeying
 func TestCoreScheduler_VariablesRekey(t *testing.T) {
 	ci.Parallel(t)
@@ -2703,9 +2828,26 @@ func TestCoreScheduler_ExpiredACLTokenGC(t *testing.T) {
 	unexpiredLocal := mock.ACLToken()
 	unexpiredLocal.ExpirationTime = pointer.Of(now.Add(2 * time.Hour))
 
+	// Craft expired tokens created by auth methods, one of which can still
+	// be renewed.
+	renewableAuthMethod := mock.ACLJWTAuthMethod()
+	renewableAuthMethod.TokenRenewalGracePeriod = 4 * time.Hour
+	authMethod := mock.ACLJWTAuthMethod()
+	authMethod.TokenRenewalGracePeriod = time.Hour
+	require.NoError(t, testServer.State().UpsertACLAuthMethods(5,
+		[]*structs.ACLAuthMethod{renewableAuthMethod, authMethod}))
+
+	renewableLocal := mock.ACLToken()
+	renewableLocal.AuthMethod = renewableAuthMethod.Name
+	renewableLocal.ExpirationTime = pointer.Of(now.Add(-2 * time.Hour))
+
+	unrenewableLocal := mock.ACLToken()
+	unrenewableLocal.AuthMethod = authMethod.Name
+	unrenewableLocal.ExpirationTime = pointer.Of(now.Add(-2 * time.Hour))
+
 	// Upsert these into state.
 	err := testServer.State().UpsertACLTokens(structs.MsgTypeTestSetup, 10, []*structs.ACLToken{
-		expiredGlobal, unexpiredGlobal, expiredLocal, unexpiredLocal,
+		expiredGlobal, unexpiredGlobal, expiredLocal, unexpiredLocal, renewableLocal, unrenewableLocal,
 	})
 	require.NoError(t, err)
 
@@ -2740,7 +2882,7 @@ func TestCoreScheduler_ExpiredACLTokenGC(t *testing.T) {
 	for raw := iter.Next(); raw != nil; raw = iter.Next() {
 		tokens = append(tokens, raw.(*structs.ACLToken))
 	}
-	require.ElementsMatch(t, []*structs.ACLToken{rootACLToken, unexpiredGlobal, unexpiredLocal}, tokens)
+	require.ElementsMatch(t, []*structs.ACLToken{rootACLToken, unexpiredGlobal, unexpiredLocal, renewableLocal}, tokens)
 }
 
 func TestCoreScheduler_ExpiredACLTokenGC_Force(t *testing.T) {
@@ -15,6 +15,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +31,7 @@ import (
 	"github.com/hashicorp/nomad/helper/crypto"
 	"github.com/hashicorp/nomad/helper/joseutil"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/signer"
 )
 
 const nomadKeystoreExtension = ".nks.json"
@@ -51,6 +53,10 @@ type Encrypter struct {
 
 	keyring map[string]*keyset
 	lock    sync.RWMutex
+
+	// signer is the signer plugin used to sign workload identities in place
+	// of the keyring, if configured
+	signer *externalSigner
 }
 
 // keyset contains the key material for variable encryption and workload
@@ -180,6 +186,15 @@ const keyIDHeader = "kid"
 // SignClaims adds the Issuer claim prior to signing.
 func (e *Encrypter) SignClaims(claims *structs.IdentityClaims) (string, string, error) {
 
+	// Add Issuer claim from server configuration
+	if e.issuer != "" {
+		claims.Issuer = e.issuer
+	}
+
+	if e.signer != nil {
+		return e.signer.signClaims(claims)
+	}
+
 	// If a key is rotated immediately following a leader election, plans that
 	// are in-flight may get signed before the new leader has the key. Allow for
 	// a short timeout-and-retry to avoid rejecting plans
@@ -201,11 +216,6 @@ func (e *Encrypter) SignClaims(claims *structs.IdentityClaims) (string, string,
 		}
 	}
 
-	// Add Issuer claim from server configuration
-	if e.issuer != "" {
-		claims.Issuer = e.issuer
-	}
-
 	opts := (&jose.SignerOptions{}).WithHeader("kid", keyset.rootKey.Meta.KeyID).WithType("JWT")
 
 	var sig jose.Signer
@@ -349,6 +359,23 @@ func (e *Encrypter) GetKey(keyID string) ([]byte, error) {
 	return keyset.rootKey.Key, nil
 }
 
+// GetRootKey retrieves the root key by ID from the keyring, including the key
+// material required to sign workload identities.
+func (e *Encrypter) GetRootKey(keyID string) (*structs.RootKey, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	keyset, err := e.keysetByIDLocked(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &structs.RootKey{
+		Meta:   keyset.rootKey.Meta.Copy(),
+		Key:    slices.Clone(keyset.rootKey.Key),
+		RSAKey: slices.Clone(keyset.rootKey.RSAKey),
+	}, nil
+}
+
 // activeKeySetLocked returns the keyset that belongs to the key marked as
 // active in the state store (so that it's consistent with raft). The
 // called must read-lock the keyring
@@ -483,10 +510,13 @@ func (e *Encrypter) loadKeyFromStore(path string) (*structs.RootKey, error) {
 // error if the key could not be found.
 func (e *Encrypter) GetPublicKey(keyID string) (*structs.KeyringPublicKey, error) {
 	e.lock.Lock()
-	defer e.lock.Unlock()
-
 	ks, err := e.keysetByIDLocked(keyID)
+	e.lock.Unlock()
 	if err != nil {
+		// The key may be a key of the signer plugin
+		if e.signer != nil {
+			return e.signer.publicKey(keyID)
+		}
 		return nil, err
 	}
 
@@ -507,6 +537,13 @@ func (e *Encrypter) GetPublicKey(keyID string) (*structs.KeyringPublicKey, error
 	return pubKey, nil
 }
 
+// SetSigner sets the signer plugin used to sign workload identities in place
+// of the keyring. The public keys of the keyring are still used to verify the
+// workload identities they signed.
+func (e *Encrypter) SetSigner(client *signer.Client) {
+	e.signer = newExternalSigner(client, e.srv.logger)
+}
+
 // newKMSWrapper returns a go-kms-wrapping interface the caller can use to
 // encrypt the RootKey with a key encryption key (KEK). This is a bit of
 // security theatre for local on-disk key material, but gives us a shim for
@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	log "github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/signer"
+)
+
+const (
+	// signerKeysRefreshInterval is how often the public keys of the signer
+	// plugin are refreshed, so that keys rotated outside of Nomad are picked
+	// up.
+	signerKeysRefreshInterval = time.Minute
+
+	// signerKeysMinRefreshInterval limits how often the public keys of the
+	// signer plugin are refreshed when verifying a workload identity signed
+	// by an unknown key.
+	signerKeysMinRefreshInterval = 5 * time.Second
+)
+
+// externalSigner signs workload identities with the keys of a signer plugin,
+// such as the keys of a cloud KMS or an HSM, in place of the keyring.
+type externalSigner struct {
+	client *signer.Client
+	logger log.Logger
+
+	// keys are the public keys of the plugin by key ID, as of refreshed
+	keys        map[string]*structs.KeyringPublicKey
+	activeKeyID string
+	refreshed   time.Time
+	lock        sync.Mutex
+}
+
+func newExternalSigner(client *signer.Client, logger log.Logger) *externalSigner {
+	return &externalSigner{
+		client: client,
+		logger: logger.Named("signer_plugin"),
+		keys:   map[string]*structs.KeyringPublicKey{},
+	}
+}
+
+// refreshLocked fetches the public keys of the plugin if they were last
+// fetched more than maxAge ago. The lock must be held.
+func (s *externalSigner) refreshLocked(maxAge time.Duration) error {
+	if !s.refreshed.IsZero() && time.Since(s.refreshed) < maxAge {
+		return nil
+	}
+
+	pubKeys, activeKeyID, err := s.client.PublicKeys()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*structs.KeyringPublicKey, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		keys[pubKey.KeyID] = pubKey
+	}
+	if activeKeyID == "" {
+		return errors.New("signer plugin has no active key")
+	}
+	if keys[activeKeyID] == nil {
+		return fmt.Errorf("signer plugin active key %q is missing from its public keys", activeKeyID)
+	}
+
+	if activeKeyID != s.activeKeyID && s.activeKeyID != "" {
+		s.logger.Info("signer plugin active key changed", "key_id", activeKeyID, "previous_key_id", s.activeKeyID)
+	}
+	s.keys = keys
+	s.activeKeyID = activeKeyID
+	s.refreshed = time.Now()
+	return nil
+}
+
+// activeKey returns the public key of the key used to sign workload
+// identities.
+func (s *externalSigner) activeKey() (*structs.KeyringPublicKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.refreshLocked(signerKeysRefreshInterval); err != nil {
+		// Keep signing with the last known key if the plugin is unavailable
+		// but its key is
+		if s.activeKeyID == "" {
+			return nil, err
+		}
+		s.logger.Warn("failed to refresh public keys", "error", err)
+	}
+	return s.keys[s.activeKeyID], nil
+}
+
+// publicKey returns the public key of a key of the plugin. The keys are
+// refreshed if the key is unknown, as it may have been rotated outside of
+// Nomad or by another server.
+func (s *externalSigner) publicKey(keyID string) (*structs.KeyringPublicKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if pubKey, ok := s.keys[keyID]; ok {
+		return pubKey, nil
+	}
+	if err := s.refreshLocked(signerKeysMinRefreshInterval); err != nil {
+		return nil, err
+	}
+	if pubKey, ok := s.keys[keyID]; ok {
+		return pubKey, nil
+	}
+	return nil, fmt.Errorf("no such key %q in keyring or signer plugin", keyID)
+}
+
+// publicKeys returns the public keys of the plugin, for publication in the
+// JWKS. The last known keys are returned if the plugin is unavailable.
+func (s *externalSigner) publicKeys() ([]*structs.KeyringPublicKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.refreshLocked(signerKeysRefreshInterval); err != nil {
+		if s.refreshed.IsZero() {
+			return nil, err
+		}
+		s.logger.Warn("failed to refresh public keys", "error", err)
+	}
+
+	pubKeys := make([]*structs.KeyringPublicKey, 0, len(s.keys))
+	for _, pubKey := range s.keys {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys, nil
+}
+
+// rotate makes the plugin create a new active key. Plugins that can't rotate
+// their keys, such as plugins relying on the automatic rotation of a KMS, are
+// left as is.
+func (s *externalSigner) rotate() error {
+	pubKey, err := s.client.Rotate()
+	if errors.Is(err, signer.ErrRotateUnsupported) {
+		s.logger.Debug("signer plugin doesn't support key rotation")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.refreshLocked(0); err != nil {
+		return err
+	}
+	if s.activeKeyID != pubKey.KeyID {
+		return fmt.Errorf("signer plugin rotated key %q is not its active key", pubKey.KeyID)
+	}
+	return nil
+}
+
+// signClaims signs the claims with the active key of the plugin and returns
+// the encoded JWT and the ID of the key.
+func (s *externalSigner) signClaims(claims *structs.IdentityClaims) (string, string, error) {
+	pubKey, err := s.activeKey()
+	if err != nil {
+		return "", "", err
+	}
+	key, err := pubKey.GetPublicKey()
+	if err != nil {
+		return "", "", err
+	}
+
+	opaque := &pluginKey{client: s.client, pubKey: pubKey, key: key}
+	alg := jose.SignatureAlgorithm(pubKey.Algorithm)
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: opaque}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", "", err
+	}
+
+	raw, err := jwt.Signed(sig).Claims(claims).CompactSerialize()
+	if err != nil {
+		return "", "", err
+	}
+
+	// Identities signed with a mismatched key would be rejected by every
+	// consumer, so catch misbehaving plugins early.
+	token, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return "", "", err
+	}
+	if err := token.Claims(key, &structs.IdentityClaims{}); err != nil {
+		return "", "", fmt.Errorf("signer plugin returned an invalid signature: %w", err)
+	}
+
+	return raw, pubKey.KeyID, nil
+}
+
+// pluginKey is a key of the signer plugin used to sign JWTs.
+type pluginKey struct {
+	client *signer.Client
+	pubKey *structs.KeyringPublicKey
+	key    any
+}
+
+var _ jose.OpaqueSigner = &pluginKey{}
+
+func (k *pluginKey) Public() *jose.JSONWebKey {
+	return &jose.JSONWebKey{
+		Key:       k.key,
+		KeyID:     k.pubKey.KeyID,
+		Algorithm: k.pubKey.Algorithm,
+		Use:       structs.PubKeyUseSig,
+	}
+}
+
+func (k *pluginKey) Algs() []jose.SignatureAlgorithm {
+	return []jose.SignatureAlgorithm{jose.SignatureAlgorithm(k.pubKey.Algorithm)}
+}
+
+func (k *pluginKey) SignPayload(payload []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	return k.client.Sign(k.pubKey.KeyID, string(alg), payload)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/signer"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+// TestEncrypter_SignerPlugin asserts workload identities are signed by the
+// signer plugin when one is set, and that its keys are published and rotated
+// along with the keys of the keyring.
+func TestEncrypter_SignerPlugin(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, rootToken, shutdown := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+	})
+	t.Cleanup(shutdown)
+	testutil.WaitForKeyring(t, srv.RPC, "global")
+	codec := rpcClient(t, srv)
+
+	// identities signed before the plugin is set are still valid
+	alloc := mock.Alloc()
+	claims := structs.NewIdentityClaims(alloc.Job, alloc, wiHandle, alloc.LookupTask("web").Identity, time.Now())
+	keyringToken, keyringKeyID, err := srv.encrypter.SignClaims(claims)
+	must.NoError(t, err)
+
+	plugin := signer.NewTestServer(t, structs.PubKeyAlgES256)
+	srv.encrypter.SetSigner(signer.NewTestClient(t, plugin))
+
+	token, keyID, err := srv.encrypter.SignClaims(claims)
+	must.NoError(t, err)
+	must.Eq(t, plugin.ActiveKeyID(), keyID)
+
+	parsed, err := jwt.ParseSigned(token)
+	must.NoError(t, err)
+	must.Eq(t, keyID, parsed.Headers[0].KeyID)
+	must.Eq(t, structs.PubKeyAlgES256, parsed.Headers[0].Algorithm)
+
+	got, err := srv.encrypter.VerifyClaim(token)
+	must.NoError(t, err)
+	must.Eq(t, claims.AllocationID, got.AllocationID)
+
+	_, err = srv.encrypter.VerifyClaim(keyringToken)
+	must.NoError(t, err)
+
+	// the keys of the keyring and of the plugin are published
+	req := structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var resp structs.KeyringListPublicResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Keyring.ListPublic", &req, &resp))
+	keyIDs := []string{}
+	for _, pubKey := range resp.PublicKeys {
+		keyIDs = append(keyIDs, pubKey.KeyID)
+	}
+	must.SliceContainsAll(t, []string{keyringKeyID, keyID}, keyIDs)
+
+	// rotating the root key rotates the key of the plugin
+	rotateReq := &structs.KeyringRotateRootKeyRequest{
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: rootToken.SecretID,
+		},
+	}
+	var rotateResp structs.KeyringRotateRootKeyResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp))
+	must.NotEq(t, keyID, plugin.ActiveKeyID())
+
+	_, newKeyID, err := srv.encrypter.SignClaims(claims)
+	must.NoError(t, err)
+	must.Eq(t, plugin.ActiveKeyID(), newKeyID)
+
+	// identities signed by the previous key are still valid
+	_, err = srv.encrypter.VerifyClaim(token)
+	must.NoError(t, err)
+
+	// plugins that can't rotate their keys are left as is
+	plugin.DisableRotate()
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp))
+	must.Eq(t, newKeyID, plugin.ActiveKeyID())
+}
@@ -74,7 +74,16 @@ type EvalBroker struct {
 	cancelable []*structs.Evaluation
 
 	// ready tracks the ready jobs by scheduler in a priority queue
-	ready map[string]ReadyEvaluations
+	ready map[string]*ReadyEvaluations
+
+	// enqueueSortIndex is the sort index of the evaluations being enqueued by
+	// EnqueueAllOrdered.
+	enqueueSortIndex map[string]uint64
+
+	// batchFairShare is the weighted recent share of the cluster used by the
+	// batch jobs of each namespace. When set, the ready evaluations of batch
+	// jobs are dequeued by fair share instead of by priority.
+	batchFairShare map[string]float64
 
 	// unack is a map of evalID to an un-acknowledged evaluation
 	unack map[string]*unackEval
@@ -124,7 +133,13 @@ type unackEval struct {
 
 // ReadyEvaluations is a list of ready evaluations across multiple jobs. We
 // implement the container/heap interface so that this is a priority queue.
-type ReadyEvaluations []*structs.Evaluation
+type ReadyEvaluations struct {
+	evals []*structs.Evaluation
+
+	// sortIndex overrides the CreateIndex that evaluations of the same
+	// priority are sorted by.
+	sortIndex map[string]uint64
+}
 
 // PendingEvaluations is a list of pending evaluations for a given job. We
 // implement the container/heap interface so that this is a priority queue.
@@ -151,7 +166,7 @@ func NewEvalBroker(timeout, initialNackDelay, subsequentNackDelay time.Duration,
 		jobEvals:             make(map[structs.NamespacedID]string),
 		pending:              make(map[structs.NamespacedID]PendingEvaluations),
 		cancelable:           make([]*structs.Evaluation, 0, structs.MaxUUIDsPerWriteRequest),
-		ready:                make(map[string]ReadyEvaluations),
+		ready:                make(map[string]*ReadyEvaluations),
 		unack:                make(map[string]*unackEval),
 		waiting:              make(map[string]chan struct{}),
 		requeue:              make(map[string]*structs.Evaluation),
@@ -225,6 +240,21 @@ func (b *EvalBroker) EnqueueAll(evals map[*structs.Evaluation]string) {
 	}
 }
 
+// EnqueueAllOrdered is like EnqueueAll, but the evaluations that become ready
+// are sorted by the given sort index instead of their CreateIndex among the
+// evaluations of the same priority.
+func (b *EvalBroker) EnqueueAllOrdered(evals map[*structs.Evaluation]string, sortIndex map[string]uint64) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.enqueueSortIndex = sortIndex
+	defer func() { b.enqueueSortIndex = nil }()
+
+	for eval, token := range evals {
+		b.processEnqueue(eval, token)
+	}
+}
+
 // processEnqueue deduplicates evals and either enqueue immediately or enforce
 // the evals wait time. If the token is passed, and the evaluation ID is
 // outstanding, the evaluation is blocked until an Ack/Nack is received.
@@ -320,15 +350,18 @@ func (b *EvalBroker) enqueueLocked(eval *structs.Evaluation, sched string) {
 	// Find the next ready eval by scheduler class
 	readyQueue, ok := b.ready[sched]
 	if !ok {
-		readyQueue = make([]*structs.Evaluation, 0, 16)
+		readyQueue = &ReadyEvaluations{evals: make([]*structs.Evaluation, 0, 16)}
+		b.ready[sched] = readyQueue
 		if _, ok := b.waiting[sched]; !ok {
 			b.waiting[sched] = make(chan struct{}, 1)
 		}
 	}
 
 	// Push onto the heap
-	heap.Push(&readyQueue, eval)
-	b.ready[sched] = readyQueue
+	if index, ok := b.enqueueSortIndex[eval.ID]; ok {
+		readyQueue.setSortIndex(eval.ID, index)
+	}
+	heap.Push(readyQueue, eval)
 
 	// Update the stats
 	b.stats.TotalReady += 1
@@ -448,8 +481,12 @@ func (b *EvalBroker) scanForSchedulers(schedulers []string) (*structs.Evaluation
 // This assumes locks are held and that this scheduler has work
 func (b *EvalBroker) dequeueForSched(sched string) (*structs.Evaluation, string, error) {
 	readyQueue := b.ready[sched]
-	raw := heap.Pop(&readyQueue)
-	b.ready[sched] = readyQueue
+	var raw interface{}
+	if sched == structs.JobTypeBatch && b.batchFairShare != nil {
+		raw = heap.Remove(readyQueue, b.nextFairShare(readyQueue))
+	} else {
+		raw = heap.Pop(readyQueue)
+	}
 	eval := raw.(*structs.Evaluation)
 
 	// Generate a UUID for the token
@@ -480,6 +517,49 @@ func (b *EvalBroker) dequeueForSched(sched string) (*structs.Evaluation, string,
 	return eval, token, nil
 }
 
+// SetBatchFairShare sets the weighted recent share of the cluster used by the
+// batch jobs of each namespace, so the ready evaluations of batch jobs are
+// dequeued by fair share. A nil map dequeues them by priority.
+func (b *EvalBroker) SetBatchFairShare(shares map[string]float64) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.batchFairShare = shares
+}
+
+// nextFairShare returns the index of the next ready evaluation to dequeue by
+// fair share, which is an evaluation of the namespace with the lowest share.
+// Namespaces with the same share are ordered by their number of unacked
+// evaluations, so they take turns between updates of the shares, and the
+// evaluations of a namespace are ordered by priority. This assumes locks are
+// held.
+func (b *EvalBroker) nextFairShare(r *ReadyEvaluations) int {
+	unacked := make(map[string]int)
+	for _, unack := range b.unack {
+		if unack.Eval.Type == structs.JobTypeBatch {
+			unacked[unack.Eval.Namespace]++
+		}
+	}
+
+	next := 0
+	for i := 1; i < len(r.evals); i++ {
+		ns, nextNs := r.evals[i].Namespace, r.evals[next].Namespace
+		share, nextShare := b.batchFairShare[ns], b.batchFairShare[nextNs]
+		switch {
+		case share != nextShare:
+			if share < nextShare {
+				next = i
+			}
+		case unacked[ns] != unacked[nextNs]:
+			if unacked[ns] < unacked[nextNs] {
+				next = i
+			}
+		case r.Less(i, next):
+			next = i
+		}
+	}
+	return next
+}
+
 // waitForSchedulers is used to wait for work on any of the scheduler or until a timeout.
 // Returns if there is work waiting potentially.
 func (b *EvalBroker) waitForSchedulers(schedulers []string, timeoutCh <-chan time.Time) bool {
@@ -763,7 +843,7 @@ func (b *EvalBroker) flush() {
 	b.jobEvals = make(map[structs.NamespacedID]string)
 	b.pending = make(map[structs.NamespacedID]PendingEvaluations)
 	b.cancelable = make([]*structs.Evaluation, 0, structs.MaxUUIDsPerWriteRequest)
-	b.ready = make(map[string]ReadyEvaluations)
+	b.ready = make(map[string]*ReadyEvaluations)
 	b.unack = make(map[string]*unackEval)
 	b.timeWait = make(map[string]*time.Timer)
 	b.delayHeap = delayheap.NewDelayHeap()
@@ -886,7 +966,7 @@ func (b *EvalBroker) Cancelable(batchSize int) []*structs.Evaluation {
 }
 
 // EmitStats is used to export metrics about the broker while enabled
-func (b *EvalBroker) EmitStats(period time.Duration, stopCh <-chan struct{}) {
+func (b *EvalBroker) EmitStats(period time.Duration, labeler *jobMetricsLabeler, stopCh <-chan struct{}) {
 	timer, stop := helper.NewSafeTimer(period)
 	defer stop()
 
@@ -902,13 +982,13 @@ func (b *EvalBroker) EmitStats(period time.Duration, stopCh <-chan struct{}) {
 			metrics.SetGauge([]string{"nomad", "broker", "total_waiting"}, float32(stats.TotalWaiting))
 			metrics.SetGauge([]string{"nomad", "broker", "total_cancelable"}, float32(stats.TotalCancelable))
 			for _, eval := range stats.DelayedEvals {
+				labels, ok := labeler.labels(eval.Namespace, eval.JobID, "")
+				if !ok {
+					continue
+				}
 				metrics.SetGaugeWithLabels([]string{"nomad", "broker", "eval_waiting"},
 					float32(time.Until(eval.WaitUntil).Seconds()),
-					[]metrics.Label{
-						{Name: "eval_id", Value: eval.ID},
-						{Name: "job", Value: eval.JobID},
-						{Name: "namespace", Value: eval.Namespace},
-					})
+					append([]metrics.Label{{Name: "eval_id", Value: eval.ID}}, labels...))
 			}
 			for sched, schedStats := range stats.ByScheduler {
 				metrics.SetGauge([]string{"nomad", "broker", sched, "ready"}, float32(schedStats.Ready))
@@ -939,46 +1019,64 @@ type SchedulerStats struct {
 }
 
 // Len is for the sorting interface
-func (r ReadyEvaluations) Len() int {
-	return len(r)
+func (r *ReadyEvaluations) Len() int {
+	return len(r.evals)
 }
 
 // Less is for the sorting interface. We flip the check
 // so that the "min" in the min-heap is the element with the
 // highest priority
-func (r ReadyEvaluations) Less(i, j int) bool {
-	if r[i].JobID != r[j].JobID && r[i].Priority != r[j].Priority {
-		return !(r[i].Priority < r[j].Priority)
+func (r *ReadyEvaluations) Less(i, j int) bool {
+	if r.evals[i].JobID != r.evals[j].JobID && r.evals[i].Priority != r.evals[j].Priority {
+		return !(r.evals[i].Priority < r.evals[j].Priority)
 	}
-	return r[i].CreateIndex < r[j].CreateIndex
+	return r.index(r.evals[i]) < r.index(r.evals[j])
 }
 
 // Swap is for the sorting interface
-func (r ReadyEvaluations) Swap(i, j int) {
-	r[i], r[j] = r[j], r[i]
+func (r *ReadyEvaluations) Swap(i, j int) {
+	r.evals[i], r.evals[j] = r.evals[j], r.evals[i]
 }
 
 // Push is used to add a new evaluation to the slice
 func (r *ReadyEvaluations) Push(e interface{}) {
-	*r = append(*r, e.(*structs.Evaluation))
+	r.evals = append(r.evals, e.(*structs.Evaluation))
 }
 
 // Pop is used to remove an evaluation from the slice
 func (r *ReadyEvaluations) Pop() interface{} {
-	n := len(*r)
-	e := (*r)[n-1]
-	(*r)[n-1] = nil
-	*r = (*r)[:n-1]
+	n := len(r.evals)
+	e := r.evals[n-1]
+	r.evals[n-1] = nil
+	r.evals = r.evals[:n-1]
+	delete(r.sortIndex, e.ID)
 	return e
 }
 
 // Peek is used to peek at the next element that would be popped
-func (r ReadyEvaluations) Peek() *structs.Evaluation {
-	n := len(r)
+func (r *ReadyEvaluations) Peek() *structs.Evaluation {
+	n := len(r.evals)
 	if n == 0 {
 		return nil
 	}
-	return r[n-1]
+	return r.evals[n-1]
+}
+
+// setSortIndex sets the index the evaluation is sorted by, it must be set
+// before the evaluation is pushed.
+func (r *ReadyEvaluations) setSortIndex(evalID string, index uint64) {
+	if r.sortIndex == nil {
+		r.sortIndex = make(map[string]uint64)
+	}
+	r.sortIndex[evalID] = index
+}
+
+// index returns the index the evaluation is sorted by.
+func (r *ReadyEvaluations) index(eval *structs.Evaluation) uint64 {
+	if index, ok := r.sortIndex[eval.ID]; ok {
+		return index
+	}
+	return eval.CreateIndex
 }
 
 // Len is for the sorting interface
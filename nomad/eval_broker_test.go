@@ -709,6 +709,49 @@ func TestEvalBroker_Dequeue_FIFO(t *testing.T) {
 	}
 }
 
+func TestEvalBroker_Dequeue_BatchFairShare(t *testing.T) {
+	ci.Parallel(t)
+	b := testBroker(t, 0)
+	b.SetEnabled(true)
+	b.SetBatchFairShare(map[string]float64{"a": 0.5, "b": 0.1})
+
+	batchEval := func(namespace string, priority int, index uint64) *structs.Evaluation {
+		eval := mock.Eval()
+		eval.Type = structs.JobTypeBatch
+		eval.Namespace = namespace
+		eval.Priority = priority
+		eval.CreateIndex = index
+		b.Enqueue(eval)
+		return eval
+	}
+
+	a1 := batchEval("a", 80, 1)
+	a2 := batchEval("a", 90, 2)
+	b1 := batchEval("b", 20, 3)
+	c1 := batchEval("c", 50, 4)
+
+	// The namespaces with the lowest share go first, and the evaluations of a
+	// namespace are dequeued by priority.
+	for _, exp := range []*structs.Evaluation{c1, b1, a2, a1} {
+		out, _, err := b.Dequeue([]string{structs.JobTypeBatch}, time.Second)
+		must.NoError(t, err)
+		must.Eq(t, exp.ID, out.ID)
+	}
+
+	// Namespaces with the same share take turns, and the evaluations of the
+	// same priority are dequeued in order.
+	b.SetBatchFairShare(map[string]float64{})
+	x1 := batchEval("x", 50, 5)
+	x2 := batchEval("x", 50, 6)
+	y1 := batchEval("y", 50, 7)
+
+	for _, exp := range []*structs.Evaluation{x1, y1, x2} {
+		out, _, err := b.Dequeue([]string{structs.JobTypeBatch}, time.Second)
+		must.NoError(t, err)
+		must.Eq(t, exp.ID, out.ID)
+	}
+}
+
 // Ensure fairness between schedulers
 func TestEvalBroker_Dequeue_Fairness(t *testing.T) {
 	ci.Parallel(t)
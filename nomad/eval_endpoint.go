@@ -116,6 +116,64 @@ func (e *Eval) GetEval(args *structs.EvalSpecificRequest,
 	return e.srv.blockingRPC(&opts)
 }
 
+// GetTrace is used to request the trace of an evaluation.
+func (e *Eval) GetTrace(args *structs.EvalSpecificRequest,
+	reply *structs.SingleEvalTraceResponse) error {
+
+	authErr := e.srv.Authenticate(e.ctx, args)
+	if done, err := e.srv.forward(structs.EvalGetTraceRPCMethod, args, args, reply); done {
+		return err
+	}
+	e.srv.MeasureRPCRate("eval", structs.RateMetricRead, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "eval", "get_trace"}, time.Now())
+
+	// Check for read-job permissions before performing blocking query.
+	allowNsOp := acl.NamespaceValidator(acl.NamespaceCapabilityReadJob)
+	aclObj, err := e.srv.ResolveACL(args)
+	if err != nil {
+		return err
+	} else if !allowNsOp(aclObj, args.RequestNamespace()) {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, store *state.StateStore) error {
+			trace, err := store.EvalTraceByID(ws, args.EvalID)
+			if err != nil {
+				return fmt.Errorf("failed to lookup eval trace: %v", err)
+			}
+
+			// Re-check namespace in case it differs from request.
+			if trace != nil && !allowNsOp(aclObj, trace.Namespace) {
+				return structs.ErrPermissionDenied
+			}
+
+			// Setup the output.
+			reply.Trace = trace
+			if trace != nil {
+				reply.Index = trace.ModifyIndex
+			} else {
+				// Use the last index that affected the eval traces table
+				index, err := store.Index(state.TableEvalTraces)
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			e.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return e.srv.blockingRPC(&opts)
+}
+
 // Dequeue is used to dequeue a pending evaluation
 func (e *Eval) Dequeue(args *structs.EvalDequeueRequest,
 	reply *structs.EvalDequeueResponse) error {
@@ -314,6 +372,42 @@ func (e *Eval) Update(args *structs.EvalUpdateRequest,
 	return nil
 }
 
+// UpsertTrace is used by schedulers to store the trace of the evaluation they
+// are processing.
+func (e *Eval) UpsertTrace(args *structs.EvalTraceUpsertRequest,
+	reply *structs.GenericResponse) error {
+
+	aclObj, err := e.srv.AuthenticateServerOnly(e.ctx, args)
+	e.srv.MeasureRPCRate("eval", structs.RateMetricWrite, args)
+	if err != nil || !aclObj.AllowServerOp() {
+		return structs.ErrPermissionDenied
+	}
+
+	if done, err := e.srv.forward(structs.EvalUpsertTraceRPCMethod, args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "eval", "upsert_trace"}, time.Now())
+
+	if args.Trace == nil {
+		return fmt.Errorf("missing eval trace")
+	}
+
+	// Verify the evaluation is outstanding, and that the tokens match.
+	if err := e.srv.evalBroker.OutstandingReset(args.Trace.EvalID, args.EvalToken); err != nil {
+		return err
+	}
+
+	// Update via Raft
+	_, index, err := e.srv.raftApply(structs.EvalTraceUpsertRequestType, args)
+	if err != nil {
+		return err
+	}
+
+	// Update the index
+	reply.Index = index
+	return nil
+}
+
 // Create is used to make a new evaluation
 func (e *Eval) Create(args *structs.EvalUpdateRequest,
 	reply *structs.GenericResponse) error {
@@ -152,6 +152,51 @@ func TestEvalEndpoint_GetEval_ACL(t *testing.T) {
 	}
 }
 
+func TestEvalEndpoint_GetTrace(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, root, cleanupS1 := TestACLServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	eval := mock.Eval()
+	state := s1.fsm.State()
+	must.NoError(t, state.UpsertEvals(structs.MsgTypeTestSetup, 1000, []*structs.Evaluation{eval}))
+	must.NoError(t, state.UpsertEvalTrace(structs.MsgTypeTestSetup, 1001, &structs.EvalTrace{
+		EvalID:    eval.ID,
+		Namespace: eval.Namespace,
+		JobID:     eval.JobID,
+	}))
+
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1002, "test-invalid",
+		mock.NamespacePolicy(structs.DefaultNamespace, "", []string{acl.NamespaceCapabilityListJobs}))
+
+	get := &structs.EvalSpecificRequest{
+		EvalID:       eval.ID,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+
+	// Try with an invalid token and expect permission denied
+	get.AuthToken = invalidToken.SecretID
+	var resp structs.SingleEvalTraceResponse
+	err := msgpackrpc.CallWithCodec(codec, structs.EvalGetTraceRPCMethod, get, &resp)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	// Lookup the trace using a root token
+	get.AuthToken = root.SecretID
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, structs.EvalGetTraceRPCMethod, get, &resp))
+	must.NotNil(t, resp.Trace)
+	must.Eq(t, eval.ID, resp.Trace.EvalID)
+	must.Eq(t, 1001, resp.Index)
+
+	// Lookup a missing trace
+	get.EvalID = uuid.Generate()
+	resp = structs.SingleEvalTraceResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, structs.EvalGetTraceRPCMethod, get, &resp))
+	must.Nil(t, resp.Trace)
+}
+
 func TestEvalEndpoint_GetEval_Blocking(t *testing.T) {
 	ci.Parallel(t)
 
@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/go-msgpack/codec"
 	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/helper/snapshot"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -65,6 +66,8 @@ const (
 	ACLAuthMethodSnapshot                SnapshotType = 26
 	ACLBindingRuleSnapshot               SnapshotType = 27
 	NodePoolSnapshot                     SnapshotType = 28
+	EvalTraceSnapshot                    SnapshotType = 29
+	LocalVolumeClaimSnapshot             SnapshotType = 30
 
 	// Namespace appliers were moved from enterprise and therefore start at 64
 	NamespaceSnapshot SnapshotType = 64
@@ -117,10 +120,29 @@ type nomadFSM struct {
 type nomadSnapshot struct {
 	snap      *state.StateSnapshot
 	timetable *TimeTable
+
+	// chunkSize is the size of the chunks the snapshot is written in, or
+	// zero to write it without chunks.
+	chunkSize int
 }
 
 // snapshotHeader is the first entry in our snapshot
 type snapshotHeader struct {
+	// ChunkSize is the size of the checksummed chunks the entries following
+	// the header are written in. Snapshots written without chunks have a
+	// zero ChunkSize.
+	ChunkSize int
+}
+
+// chunkedSnapshotSink is a snapshot sink writing the entries of the snapshot
+// in checksummed chunks.
+type chunkedSnapshotSink struct {
+	raft.SnapshotSink
+	chunks *snapshot.ChunkWriter
+}
+
+func (s *chunkedSnapshotSink) Write(p []byte) (int, error) {
+	return s.chunks.Write(p)
 }
 
 // FSMConfig is used to configure the FSM
@@ -149,19 +171,33 @@ type FSMConfig struct {
 	// EventBufferSize is the amount of messages to hold in memory
 	EventBufferSize int64
 
+	// EnableChangeFeed specifies if the FSMs state store should publish the
+	// change feed events.
+	EnableChangeFeed bool
+
 	// JobTrackedVersions is the number of historic job versions that are kept.
 	JobTrackedVersions int
+
+	// JobTrackedScalingEvents is the number of scaling events that are kept
+	// for a single task group.
+	JobTrackedScalingEvents int
+
+	// SnapshotChunkSize is the size of the checksummed chunks snapshots are
+	// written in. Snapshots are written without chunks if it's zero.
+	SnapshotChunkSize int
 }
 
 // NewFSM is used to construct a new FSM with a blank state.
 func NewFSM(config *FSMConfig) (*nomadFSM, error) {
 	// Create a state store
 	sconfig := &state.StateStoreConfig{
-		Logger:             config.Logger,
-		Region:             config.Region,
-		EnablePublisher:    config.EnableEventBroker,
-		EventBufferSize:    config.EventBufferSize,
-		JobTrackedVersions: config.JobTrackedVersions,
+		Logger:                  config.Logger,
+		Region:                  config.Region,
+		EnablePublisher:         config.EnableEventBroker,
+		EventBufferSize:         config.EventBufferSize,
+		EnableChangeFeed:        config.EnableChangeFeed,
+		JobTrackedVersions:      config.JobTrackedVersions,
+		JobTrackedScalingEvents: config.JobTrackedScalingEvents,
 	}
 	state, err := state.NewStateStore(sconfig)
 	if err != nil {
@@ -242,6 +278,10 @@ func (n *nomadFSM) Apply(log *raft.Log) interface{} {
 		return n.applyDeregisterJob(msgType, buf[1:], log.Index)
 	case structs.EvalUpdateRequestType:
 		return n.applyUpdateEval(msgType, buf[1:], log.Index)
+	case structs.EvalTraceUpsertRequestType:
+		return n.applyUpsertEvalTrace(msgType, buf[1:], log.Index)
+	case structs.LocalVolumeClaimDeleteRequestType:
+		return n.applyDeleteLocalVolumeClaims(msgType, buf[1:], log.Index)
 	case structs.EvalDeleteRequestType:
 		return n.applyDeleteEval(buf[1:], log.Index)
 	case structs.AllocUpdateRequestType:
@@ -266,6 +306,8 @@ func (n *nomadFSM) Apply(log *raft.Log) interface{} {
 		return n.applyDeploymentDelete(buf[1:], log.Index)
 	case structs.JobStabilityRequestType:
 		return n.applyJobStability(buf[1:], log.Index)
+	case structs.JobSuspendRequestType:
+		return n.applyJobSuspend(msgType, buf[1:], log.Index)
 	case structs.ACLPolicyUpsertRequestType:
 		return n.applyACLPolicyUpsert(msgType, buf[1:], log.Index)
 	case structs.ACLPolicyDeleteRequestType:
@@ -314,6 +356,8 @@ func (n *nomadFSM) Apply(log *raft.Log) interface{} {
 		return n.applyNamespaceUpsert(buf[1:], log.Index)
 	case structs.NamespaceDeleteRequestType:
 		return n.applyNamespaceDelete(buf[1:], log.Index)
+	case structs.AllocIdentityEventsRequestType:
+		return n.applyAllocIdentityEvents(buf[1:], log.Index)
 	// COMPAT(1.0): These messages were added and removed during the 1.0-beta
 	// series and should not be immediately reused for other purposes
 	case structs.EventSinkUpsertRequestType,
@@ -451,14 +495,24 @@ func (n *nomadFSM) applyStatusUpdate(msgType structs.MessageType, buf []byte, in
 		panic(fmt.Errorf("failed to decode request: %v", err))
 	}
 
-	if err := n.state.UpdateNodeStatus(msgType, index, req.NodeID, req.Status, req.UpdatedAt, req.NodeEvent); err != nil {
+	// Updates that only store the utilization of a ready node don't change
+	// its capacity, so they don't need to unblock evals.
+	utilizationOnly := false
+	if req.Utilization != nil && req.NodeEvent == nil {
+		existing, err := n.state.NodeByID(nil, req.NodeID)
+		if err == nil && existing != nil && existing.Status == req.Status {
+			utilizationOnly = true
+		}
+	}
+
+	if err := n.state.UpdateNodeStatusWithUtilization(msgType, index, req.NodeID, req.Status, req.UpdatedAt, req.NodeEvent, req.Utilization); err != nil {
 		n.logger.Error("UpdateNodeStatus failed", "error", err)
 		return err
 	}
 
 	// Unblock evals for the nodes computed node class if it is in a ready
 	// state.
-	if req.Status == structs.NodeStatusReady {
+	if req.Status == structs.NodeStatusReady && !utilizationOnly {
 		ws := memdb.NewWatchSet()
 		node, err := n.state.NodeByID(ws, req.NodeID)
 		if err != nil {
@@ -606,6 +660,13 @@ func (n *nomadFSM) applyUpsertJob(msgType structs.MessageType, buf []byte, index
 		return err
 	}
 
+	if req.Warnings != nil {
+		if err := n.state.SetJobWarnings(index, req.Namespace, req.Job.ID, req.Warnings); err != nil {
+			n.logger.Error("SetJobWarnings failed", "error", err)
+			return err
+		}
+	}
+
 	// We always add the job to the periodic dispatcher because there is the
 	// possibility that the periodic spec was removed and then we should stop
 	// tracking it.
@@ -726,7 +787,7 @@ func (n *nomadFSM) applyDeregisterJob(msgType structs.MessageType, buf []byte, i
 	}
 
 	err := n.state.WithWriteTransaction(msgType, index, func(tx state.Txn) error {
-		err := n.handleJobDeregister(index, req.JobID, req.Namespace, req.Purge, req.NoShutdownDelay, tx)
+		err := n.handleJobDeregister(index, req.JobID, req.Namespace, req.Purge, req.NoShutdownDelay, req.ServiceDrain, tx)
 
 		if err != nil {
 			n.logger.Error("deregistering job failed",
@@ -766,7 +827,7 @@ func (n *nomadFSM) applyBatchDeregisterJob(msgType structs.MessageType, buf []by
 	// evals for jobs whose deregistering didn't get committed yet.
 	err := n.state.WithWriteTransaction(msgType, index, func(tx state.Txn) error {
 		for jobNS, options := range req.Jobs {
-			if err := n.handleJobDeregister(index, jobNS.ID, jobNS.Namespace, options.Purge, false, tx); err != nil {
+			if err := n.handleJobDeregister(index, jobNS.ID, jobNS.Namespace, options.Purge, false, 0, tx); err != nil {
 				n.logger.Error("deregistering job failed", "job", jobNS.ID, "error", err)
 				return err
 			}
@@ -791,19 +852,25 @@ func (n *nomadFSM) applyBatchDeregisterJob(msgType structs.MessageType, buf []by
 
 // handleJobDeregister is used to deregister a job. Leaves error logging up to
 // caller.
-func (n *nomadFSM) handleJobDeregister(index uint64, jobID, namespace string, purge bool, noShutdownDelay bool, tx state.Txn) error {
+func (n *nomadFSM) handleJobDeregister(index uint64, jobID, namespace string, purge bool, noShutdownDelay bool, serviceDrain time.Duration, tx state.Txn) error {
 	// If it is periodic remove it from the dispatcher
 	if err := n.periodicDispatcher.Remove(namespace, jobID); err != nil {
 		return fmt.Errorf("periodicDispatcher.Remove failed: %w", err)
 	}
 
-	if noShutdownDelay {
+	if noShutdownDelay || serviceDrain > 0 {
 		ws := memdb.NewWatchSet()
 		allocs, err := n.state.AllocsByJob(ws, namespace, jobID, false)
 		if err != nil {
 			return err
 		}
-		transition := &structs.DesiredTransition{NoShutdownDelay: pointer.Of(true)}
+		transition := &structs.DesiredTransition{}
+		if noShutdownDelay {
+			transition.NoShutdownDelay = pointer.Of(true)
+		}
+		if serviceDrain > 0 {
+			transition.ServiceDrain = pointer.Of(serviceDrain)
+		}
 		for _, alloc := range allocs {
 			err := n.state.UpdateAllocDesiredTransitionTxn(tx, index, alloc.ID, transition)
 			if err != nil {
@@ -894,6 +961,39 @@ func (n *nomadFSM) handleUpsertedEval(eval *structs.Evaluation) {
 	}
 }
 
+func (n *nomadFSM) applyUpsertEvalTrace(msgType structs.MessageType, buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "upsert_eval_trace"}, time.Now())
+	var req structs.EvalTraceUpsertRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.UpsertEvalTrace(msgType, index, req.Trace); err != nil {
+		n.logger.Error("UpsertEvalTrace failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (n *nomadFSM) applyDeleteLocalVolumeClaims(msgType structs.MessageType, buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "delete_local_volume_claims"}, time.Now())
+	var req structs.LocalVolumeClaimDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	if err := n.state.DeleteLocalVolumeClaims(msgType, index, req.RequestNamespace(), req.JobID, req.AllocName); err != nil {
+		n.logger.Error("DeleteLocalVolumeClaims failed", "error", err)
+		return err
+	}
+
+	if req.Eval != nil {
+		return n.upsertEvals(msgType, index, []*structs.Evaluation{req.Eval})
+	}
+	return nil
+}
+
 func (n *nomadFSM) applyDeleteEval(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "delete_eval"}, time.Now())
 	var req structs.EvalReapRequest
@@ -1194,6 +1294,27 @@ func (n *nomadFSM) applyJobStability(buf []byte, index uint64) interface{} {
 	return nil
 }
 
+// applyJobSuspend is used to suspend or resume a job
+func (n *nomadFSM) applyJobSuspend(msgType structs.MessageType, buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_job_suspend"}, time.Now())
+	var req structs.JobSuspendRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	updated, err := n.state.UpdateJobSuspension(msgType, index, req.RequestNamespace(), req.JobID, req.Suspend, req.Eval)
+	if err != nil {
+		n.logger.Error("UpdateJobSuspension failed", "error", err)
+		return err
+	}
+
+	// The evaluation is only created if the job was updated
+	if updated {
+		n.handleUpsertedEval(req.Eval)
+	}
+	return nil
+}
+
 // applyACLPolicyUpsert is used to upsert a set of policies
 func (n *nomadFSM) applyACLPolicyUpsert(msgType structs.MessageType, buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_acl_policy_upsert"}, time.Now())
@@ -1481,6 +1602,23 @@ func (n *nomadFSM) applyNamespaceDelete(buf []byte, index uint64) interface{} {
 	return nil
 }
 
+// applyAllocIdentityEvents is used to publish lifecycle events of workload
+// identities. They don't change the state, so they're only published on the
+// event stream.
+//
+// COMPAT: The events are now published by the leader without being written to
+// raft, but the entries written by previous versions must still be applied.
+func (n *nomadFSM) applyAllocIdentityEvents(buf []byte, index uint64) interface{} {
+	defer metrics.MeasureSince([]string{"nomad", "fsm", "apply_alloc_identity_events"}, time.Now())
+	var req structs.AllocIdentityEventsRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	n.state.PublishWorkloadIdentityEvents(index, req.Events)
+	return nil
+}
+
 func (n *nomadFSM) Snapshot() (raft.FSMSnapshot, error) {
 	// Create a new snapshot
 	snap, err := n.state.Snapshot()
@@ -1491,6 +1629,7 @@ func (n *nomadFSM) Snapshot() (raft.FSMSnapshot, error) {
 	ns := &nomadSnapshot{
 		snap:      snap,
 		timetable: n.timetable,
+		chunkSize: n.config.SnapshotChunkSize,
 	}
 	return ns, nil
 }
@@ -1508,16 +1647,63 @@ func (n *nomadFSM) RestoreWithFilter(old io.ReadCloser, filter *FSMFilter) error
 	return n.restoreImpl(old, filter)
 }
 
+// SnapshotStateInfo describes the state of a snapshot verified by
+// VerifySnapshotState.
+type SnapshotStateInfo struct {
+	// ChunkSize is the size of the chunks of the snapshot, or zero if it
+	// was written without chunks.
+	ChunkSize int
+
+	// Chunks is the number of chunks of the snapshot.
+	Chunks int
+
+	// Size is the size of the entries of the snapshot, excluding its header
+	// and the headers of its chunks.
+	Size int64
+}
+
+// VerifySnapshotState reads the state of a snapshot and verifies the
+// checksum of each of its chunks. The entries of snapshots written without
+// chunks have no checksums, so they're only read.
+func VerifySnapshotState(r io.Reader) (*SnapshotStateInfo, error) {
+	dec := codec.NewDecoder(r, structs.MsgpackHandle)
+
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot header: %v", err)
+	}
+
+	info := &SnapshotStateInfo{ChunkSize: header.ChunkSize}
+	if header.ChunkSize == 0 {
+		size, err := io.Copy(io.Discard, r)
+		if err != nil {
+			return nil, err
+		}
+		info.Size = size
+		return info, nil
+	}
+
+	chunks := snapshot.NewChunkReader(r, header.ChunkSize)
+	if _, err := io.Copy(io.Discard, chunks); err != nil {
+		return nil, err
+	}
+	info.Chunks = chunks.Chunks()
+	info.Size = chunks.Size()
+	return info, nil
+}
+
 func (n *nomadFSM) restoreImpl(old io.ReadCloser, filter *FSMFilter) error {
 	defer old.Close()
 
 	// Create a new state store
 	config := &state.StateStoreConfig{
-		Logger:             n.config.Logger,
-		Region:             n.config.Region,
-		EnablePublisher:    n.config.EnableEventBroker,
-		EventBufferSize:    n.config.EventBufferSize,
-		JobTrackedVersions: n.config.JobTrackedVersions,
+		Logger:                  n.config.Logger,
+		Region:                  n.config.Region,
+		EnablePublisher:         n.config.EnableEventBroker,
+		EventBufferSize:         n.config.EventBufferSize,
+		EnableChangeFeed:        n.config.EnableChangeFeed,
+		JobTrackedVersions:      n.config.JobTrackedVersions,
+		JobTrackedScalingEvents: n.config.JobTrackedScalingEvents,
 	}
 	newState, err := state.NewStateStore(config)
 	if err != nil {
@@ -1540,11 +1726,19 @@ func (n *nomadFSM) restoreImpl(old io.ReadCloser, filter *FSMFilter) error {
 		return err
 	}
 
+	// The entries of chunked snapshots are read from their chunks, which are
+	// verified against their checksums.
+	var src io.Reader = old
+	if header.ChunkSize > 0 {
+		src = snapshot.NewChunkReader(old, header.ChunkSize)
+		dec = codec.NewDecoder(src, structs.MsgpackHandle)
+	}
+
 	// Populate the new state
 	msgType := make([]byte, 1)
 	for {
 		// Read the message type
-		_, err := old.Read(msgType)
+		_, err := src.Read(msgType)
 		if err == io.EOF {
 			break
 		} else if err != nil {
@@ -1600,6 +1794,28 @@ func (n *nomadFSM) restoreImpl(old io.ReadCloser, filter *FSMFilter) error {
 				}
 			}
 
+		case EvalTraceSnapshot:
+			trace := new(structs.EvalTrace)
+			if err := dec.Decode(trace); err != nil {
+				return err
+			}
+			if filter.Include(trace) {
+				if err := restore.EvalTraceRestore(trace); err != nil {
+					return err
+				}
+			}
+
+		case LocalVolumeClaimSnapshot:
+			claim := new(structs.LocalVolumeClaim)
+			if err := dec.Decode(claim); err != nil {
+				return err
+			}
+			if filter.Include(claim) {
+				if err := restore.LocalVolumeClaimRestore(claim); err != nil {
+					return err
+				}
+			}
+
 		case AllocSnapshot:
 			alloc := new(structs.Allocation)
 			if err := dec.Decode(alloc); err != nil {
@@ -2320,12 +2536,21 @@ func (s *nomadSnapshot) Persist(sink raft.SnapshotSink) error {
 	encoder := codec.NewEncoder(sink, structs.MsgpackHandle)
 
 	// Write the header
-	header := snapshotHeader{}
+	header := snapshotHeader{ChunkSize: s.chunkSize}
 	if err := encoder.Encode(&header); err != nil {
 		sink.Cancel()
 		return err
 	}
 
+	// Write the entries in checksummed chunks if enabled, so they're
+	// verified on restore.
+	var chunks *snapshot.ChunkWriter
+	if header.ChunkSize > 0 {
+		chunks = snapshot.NewChunkWriter(sink, header.ChunkSize)
+		sink = &chunkedSnapshotSink{SnapshotSink: sink, chunks: chunks}
+		encoder = codec.NewEncoder(sink, structs.MsgpackHandle)
+	}
+
 	// Write the time table
 	sink.Write([]byte{byte(TimeTableSnapshot)})
 	if err := s.timetable.Serialize(encoder); err != nil {
@@ -2354,10 +2579,18 @@ func (s *nomadSnapshot) Persist(sink raft.SnapshotSink) error {
 		sink.Cancel()
 		return err
 	}
+	if err := s.persistEvalTraces(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
 	if err := s.persistAllocs(sink, encoder); err != nil {
 		sink.Cancel()
 		return err
 	}
+	if err := s.persistLocalVolumeClaims(sink, encoder); err != nil {
+		sink.Cancel()
+		return err
+	}
 	if err := s.persistPeriodicLaunches(sink, encoder); err != nil {
 		sink.Cancel()
 		return err
@@ -2450,6 +2683,12 @@ func (s *nomadSnapshot) Persist(sink raft.SnapshotSink) error {
 		sink.Cancel()
 		return err
 	}
+	if chunks != nil {
+		if err := chunks.Flush(); err != nil {
+			sink.Cancel()
+			return err
+		}
+	}
 	return nil
 }
 
@@ -2585,6 +2824,46 @@ func (s *nomadSnapshot) persistEvals(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *nomadSnapshot) persistEvalTraces(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the eval traces
+	ws := memdb.NewWatchSet()
+	traces, err := s.snap.EvalTraces(ws)
+	if err != nil {
+		return err
+	}
+
+	for raw := traces.Next(); raw != nil; raw = traces.Next() {
+		trace := raw.(*structs.EvalTrace)
+
+		sink.Write([]byte{byte(EvalTraceSnapshot)})
+		if err := encoder.Encode(trace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *nomadSnapshot) persistLocalVolumeClaims(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	// Get all the local volume claims
+	ws := memdb.NewWatchSet()
+	claims, err := s.snap.LocalVolumeClaims(ws)
+	if err != nil {
+		return err
+	}
+
+	for raw := claims.Next(); raw != nil; raw = claims.Next() {
+		claim := raw.(*structs.LocalVolumeClaim)
+
+		sink.Write([]byte{byte(LocalVolumeClaimSnapshot)})
+		if err := encoder.Encode(claim); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *nomadSnapshot) persistAllocs(sink raft.SnapshotSink,
 	encoder *codec.Encoder) error {
 	// Get all the allocations
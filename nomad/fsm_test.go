@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/helper/snapshot"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
@@ -59,14 +61,15 @@ func testFSM(t *testing.T) *nomadFSM {
 	dispatcher, _ := testPeriodicDispatcher(t)
 	logger := testlog.HCLogger(t)
 	fsmConfig := &FSMConfig{
-		EvalBroker:         broker,
-		Periodic:           dispatcher,
-		Blocked:            NewBlockedEvals(broker, logger),
-		Logger:             logger,
-		Region:             "global",
-		EnableEventBroker:  true,
-		EventBufferSize:    100,
-		JobTrackedVersions: structs.JobDefaultTrackedVersions,
+		EvalBroker:              broker,
+		Periodic:                dispatcher,
+		Blocked:                 NewBlockedEvals(broker, logger),
+		Logger:                  logger,
+		Region:                  "global",
+		EnableEventBroker:       true,
+		EventBufferSize:         100,
+		JobTrackedVersions:      structs.JobDefaultTrackedVersions,
+		JobTrackedScalingEvents: structs.JobTrackedScalingEvents,
 	}
 	fsm, err := NewFSM(fsmConfig)
 	if err != nil {
@@ -1164,6 +1167,28 @@ func TestFSM_UpdateEval(t *testing.T) {
 	}
 }
 
+func TestFSM_UpsertEvalTrace(t *testing.T) {
+	ci.Parallel(t)
+	fsm := testFSM(t)
+
+	eval := mock.Eval()
+	req := structs.EvalTraceUpsertRequest{
+		Trace: &structs.EvalTrace{
+			EvalID:    eval.ID,
+			Namespace: eval.Namespace,
+			JobID:     eval.JobID,
+		},
+	}
+	buf, err := structs.Encode(structs.EvalTraceUpsertRequestType, req)
+	must.NoError(t, err)
+	must.Nil(t, fsm.Apply(makeLog(buf)))
+
+	trace, err := fsm.State().EvalTraceByID(nil, eval.ID)
+	must.NoError(t, err)
+	must.NotNil(t, trace)
+	must.Eq(t, 1, trace.CreateIndex)
+}
+
 func TestFSM_UpdateEval_Blocked(t *testing.T) {
 	ci.Parallel(t)
 	fsm := testFSM(t)
@@ -2429,6 +2454,56 @@ func TestFSM_SnapshotRestore_Nodes(t *testing.T) {
 	must.Eq(t, node, out)
 }
 
+func TestFSM_SnapshotRestore_Chunked(t *testing.T) {
+	ci.Parallel(t)
+
+	fsm := testFSM(t)
+	fsm.config.SnapshotChunkSize = 512
+	state := fsm.State()
+
+	var nodes []*structs.Node
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, uint64(1000+i), node))
+		nodes = append(nodes, node)
+	}
+
+	snap, err := fsm.Snapshot()
+	must.NoError(t, err)
+	defer snap.Release()
+
+	buf := bytes.NewBuffer(nil)
+	must.NoError(t, snap.Persist(&MockSink{buf, false}))
+	persisted := buf.Bytes()
+
+	// The state is restored from the chunks.
+	fsm2 := testFSM(t)
+	must.NoError(t, fsm2.Restore(io.NopCloser(bytes.NewReader(persisted))))
+	for _, node := range nodes {
+		out, err := fsm2.State().NodeByID(nil, node.ID)
+		must.NoError(t, err)
+		must.Eq(t, node, out)
+	}
+
+	info, err := VerifySnapshotState(bytes.NewReader(persisted))
+	must.NoError(t, err)
+	must.Eq(t, 512, info.ChunkSize)
+	must.Greater(t, 1, info.Chunks)
+
+	// Corrupted and truncated snapshots are rejected.
+	corrupted := bytes.Clone(persisted)
+	corrupted[200] ^= 0xff // in the data of the first chunk
+	fsm3 := testFSM(t)
+	err = fsm3.Restore(io.NopCloser(bytes.NewReader(corrupted)))
+	must.ErrorIs(t, err, snapshot.ErrChunkChecksum)
+	_, err = VerifySnapshotState(bytes.NewReader(corrupted))
+	must.ErrorIs(t, err, snapshot.ErrChunkChecksum)
+
+	truncated := persisted[:len(persisted)-4]
+	_, err = VerifySnapshotState(bytes.NewReader(truncated))
+	must.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
 func TestFSM_SnapshotRestore_NodePools(t *testing.T) {
 	ci.Parallel(t)
 
@@ -2493,6 +2568,55 @@ func TestFSM_SnapshotRestore_Evals(t *testing.T) {
 	}
 }
 
+func TestFSM_SnapshotRestore_EvalTraces(t *testing.T) {
+	ci.Parallel(t)
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	eval := mock.Eval()
+	trace := &structs.EvalTrace{
+		EvalID:     eval.ID,
+		Namespace:  eval.Namespace,
+		JobID:      eval.JobID,
+		Placements: []*structs.EvalTracePlacement{{TaskGroup: "web"}},
+	}
+	must.NoError(t, state.UpsertEvalTrace(structs.MsgTypeTestSetup, 1000, trace))
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	out, err := fsm2.State().EvalTraceByID(nil, eval.ID)
+	must.NoError(t, err)
+	must.NotNil(t, out)
+	must.Eq(t, 1000, out.CreateIndex)
+	must.Len(t, 1, out.Placements)
+}
+
+func TestFSM_SnapshotRestore_LocalVolumeClaims(t *testing.T) {
+	ci.Parallel(t)
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	job := mock.Job()
+	job.TaskGroups[0].Volumes = map[string]*structs.VolumeRequest{
+		"data": {Name: "data", Type: structs.VolumeTypeLocal, Source: "data"},
+	}
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+	alloc := mock.Alloc()
+	alloc.Job = job
+	alloc.JobID = job.ID
+	alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, 0)
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1001, []*structs.Allocation{alloc}))
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	out, err := fsm2.State().LocalVolumeClaim(nil, job.Namespace, job.ID, alloc.Name)
+	must.NoError(t, err)
+	must.NotNil(t, out)
+	must.Eq(t, alloc.NodeID, out.NodeID)
+	must.Eq(t, 1001, out.CreateIndex)
+}
+
 func TestFSM_SnapshotRestore_Allocs(t *testing.T) {
 	ci.Parallel(t)
 	// Add some state
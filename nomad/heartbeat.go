@@ -91,6 +91,8 @@ func (h *nodeHeartbeater) initializeHeartbeatTimers() error {
 // resetHeartbeatTimer is used to reset the TTL of a heartbeat.
 // This can be used for new heartbeats and existing ones.
 func (h *nodeHeartbeater) resetHeartbeatTimer(id string) (time.Duration, error) {
+	minTTL, grace := h.heartbeatConfig(id)
+
 	h.heartbeatTimersLock.Lock()
 	defer h.heartbeatTimersLock.Unlock()
 
@@ -104,14 +106,37 @@ func (h *nodeHeartbeater) resetHeartbeatTimer(id string) (time.Duration, error)
 
 	// Compute the target TTL value
 	n := len(h.heartbeatTimers)
-	ttl := helper.RateScaledInterval(h.srv.config.MaxHeartbeatsPerSecond, h.srv.config.MinHeartbeatTTL, n)
+	ttl := helper.RateScaledInterval(h.srv.config.MaxHeartbeatsPerSecond, minTTL, n)
 	ttl += helper.RandomStagger(ttl)
 
 	// Reset the TTL
-	h.resetHeartbeatTimerLocked(id, ttl+h.srv.config.HeartbeatGrace)
+	h.resetHeartbeatTimerLocked(id, ttl+grace)
 	return ttl, nil
 }
 
+// heartbeatConfig returns the minimum TTL and the grace period of the node
+// heartbeats, which the node pool of the node can override.
+func (h *nodeHeartbeater) heartbeatConfig(id string) (time.Duration, time.Duration) {
+	minTTL, grace := h.srv.config.MinHeartbeatTTL, h.srv.config.HeartbeatGrace
+
+	node, err := h.srv.State().NodeByID(nil, id)
+	if err != nil || node == nil {
+		return minTTL, grace
+	}
+	pool, err := h.srv.State().NodePoolByName(nil, node.NodePool)
+	if err != nil || pool == nil || pool.HeartbeatConfig == nil {
+		return minTTL, grace
+	}
+
+	if pool.HeartbeatConfig.MinHeartbeatTTL != 0 {
+		minTTL = pool.HeartbeatConfig.MinHeartbeatTTL
+	}
+	if pool.HeartbeatConfig.HeartbeatGrace != 0 {
+		grace = pool.HeartbeatConfig.HeartbeatGrace
+	}
+	return minTTL, grace
+}
+
 // resetHeartbeatTimerLocked is used to reset a heartbeat timer
 // assuming the heartbeatTimerLock is already held
 func (h *nodeHeartbeater) resetHeartbeatTimerLocked(id string, ttl time.Duration) {
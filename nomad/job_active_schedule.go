@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// applyJobActiveSchedule applies the active schedule of a job being
+// registered. The override and the suspended counts are managed by the
+// server, so the override of the existing job is kept until it expires.
+func applyJobActiveSchedule(job, existingJob *structs.Job, now time.Time) {
+	if job.ActiveSchedule == nil {
+		return
+	}
+
+	job.ActiveSchedule.Override = nil
+	job.ActiveSchedule.SuspendedCounts = nil
+	if existingJob != nil && existingJob.ActiveSchedule != nil {
+		if o := existingJob.ActiveSchedule.Override; o != nil && !o.Expired(now) {
+			job.ActiveSchedule.Override = o.Copy()
+		}
+	}
+	job.ApplyActiveSchedule(now)
+}
+
+// watchJobActiveSchedules is a long lived function that periodically scales
+// the jobs with an active schedule to zero outside of their windows, and
+// restores them inside. It runs on the leader.
+func (s *Server) watchJobActiveSchedules(stopCh chan struct{}) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-timer.C:
+			timer.Reset(structs.JobActiveScheduleInterval)
+			if err := s.applyJobActiveSchedules(time.Now()); err != nil {
+				s.logger.Error("failed to apply job active schedules", "error", err)
+			}
+		}
+	}
+}
+
+// applyJobActiveSchedules applies the active schedule of every job at the
+// given time, and removes the expired overrides.
+func (s *Server) applyJobActiveSchedules(now time.Time) error {
+	snap, err := s.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	iter, err := snap.Jobs(memdb.NewWatchSet())
+	if err != nil {
+		return err
+	}
+
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		job := raw.(*structs.Job)
+		if job.ActiveSchedule == nil || job.Stopped() {
+			continue
+		}
+
+		job = job.Copy()
+		expired := job.ActiveSchedule.Override != nil && job.ActiveSchedule.Override.Expired(now)
+		if expired {
+			job.ActiveSchedule.Override = nil
+		}
+		changes := job.ApplyActiveSchedule(now)
+		if len(changes) == 0 && !expired {
+			continue
+		}
+
+		message := "Restored inside the active schedule"
+		if job.ActiveSchedule.Suspended() {
+			message = "Scaled to zero outside of the active schedule"
+		}
+		var reply structs.JobRegisterResponse
+		if err := s.commitJobActiveSchedule(job, changes, message, structs.JobActiveScheduleActor, &reply); err != nil {
+			s.logger.Error("failed to apply job active schedule",
+				"namespace", job.Namespace, "job_id", job.ID, "error", err)
+			continue
+		}
+		s.logger.Debug("applied job active schedule",
+			"namespace", job.Namespace, "job_id", job.ID, "suspended", job.ActiveSchedule.Suspended())
+	}
+	return nil
+}
+
+// commitJobActiveSchedule commits a job whose active schedule was applied. An
+// evaluation is created if the count of any group changed, and a scaling
+// event is recorded for each of them as the audit trail of the schedule.
+func (s *Server) commitJobActiveSchedule(job *structs.Job, changes []*structs.JobActiveScheduleChange,
+	message, actor string, reply *structs.JobRegisterResponse) error {
+
+	_, jobModifyIndex, err := s.raftApply(
+		structs.JobRegisterRequestType,
+		structs.JobRegisterRequest{
+			Job:            job,
+			EnforceIndex:   true,
+			JobModifyIndex: job.ModifyIndex,
+			WriteRequest: structs.WriteRequest{
+				Region:    s.config.Region,
+				Namespace: job.Namespace,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	reply.JobModifyIndex = jobModifyIndex
+	reply.Index = jobModifyIndex
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+	eval := &structs.Evaluation{
+		ID:             uuid.Generate(),
+		Namespace:      job.Namespace,
+		Priority:       job.Priority,
+		Type:           job.Type,
+		TriggeredBy:    structs.EvalTriggerScaling,
+		JobID:          job.ID,
+		JobModifyIndex: jobModifyIndex,
+		Status:         structs.EvalStatusPending,
+		CreateTime:     now,
+		ModifyTime:     now,
+	}
+	_, evalIndex, err := s.raftApply(
+		structs.EvalUpdateRequestType,
+		&structs.EvalUpdateRequest{
+			Evals:        []*structs.Evaluation{eval},
+			WriteRequest: structs.WriteRequest{Region: s.config.Region},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	reply.EvalID = eval.ID
+	reply.EvalCreateIndex = evalIndex
+	reply.Index = evalIndex
+
+	for _, change := range changes {
+		count := int64(change.Count)
+		event := &structs.ScalingEventRequest{
+			Namespace: job.Namespace,
+			JobID:     job.ID,
+			TaskGroup: change.Group,
+			ScalingEvent: &structs.ScalingEvent{
+				Time:          now,
+				PreviousCount: int64(change.PreviousCount),
+				Count:         &count,
+				Message:       message,
+				Actor:         actor,
+				EvalID:        &eval.ID,
+			},
+		}
+		_, eventIndex, err := s.raftApply(structs.ScalingEventRegisterRequestType, event)
+		if err != nil {
+			return err
+		}
+		reply.Index = eventIndex
+	}
+	return nil
+}
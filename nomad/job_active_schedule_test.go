@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"testing"
+	"time"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobEndpoint_ActiveSchedule(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// The job is only active for a minute each year, so it's registered
+	// scaled to zero.
+	job := mock.Job()
+	job.ActiveSchedule = &structs.JobActiveSchedule{
+		Windows: []*structs.JobActiveWindow{{Cron: "0 0 1 1 *", Duration: time.Minute}},
+	}
+	count := job.TaskGroups[0].Count
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+
+	out, err := state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, 0, out.TaskGroups[0].Count)
+	must.Eq(t, map[string]int{"web": count}, out.ActiveSchedule.SuspendedCounts)
+
+	// Overriding the schedule restores the job.
+	override := &structs.JobOverrideScheduleRequest{
+		JobID:    job.ID,
+		Override: &structs.JobActiveScheduleOverride{Active: true},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	resp = structs.JobRegisterResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.OverrideSchedule", override, &resp))
+	must.NotEq(t, "", resp.EvalID)
+
+	out, err = state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, count, out.TaskGroups[0].Count)
+	must.False(t, out.ActiveSchedule.Suspended())
+	must.NotNil(t, out.ActiveSchedule.Override)
+
+	events, _, err := state.ScalingEventsByJob(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Len(t, 1, events["web"])
+	must.Eq(t, "Active schedule overridden to active", events["web"][0].Message)
+	must.Eq(t, resp.EvalID, *events["web"][0].EvalID)
+
+	// The override is kept when the job is registered again.
+	req.Job = job.Copy()
+	req.Job.Meta = map[string]string{"version": "2"}
+	resp = structs.JobRegisterResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+
+	out, err = state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, count, out.TaskGroups[0].Count)
+	must.NotNil(t, out.ActiveSchedule.Override)
+
+	// Clearing the override scales the job to zero again.
+	override.Override = nil
+	resp = structs.JobRegisterResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.OverrideSchedule", override, &resp))
+
+	out, err = state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, 0, out.TaskGroups[0].Count)
+	must.Nil(t, out.ActiveSchedule.Override)
+
+	// Jobs without an active schedule can't be overridden.
+	other := mock.Job()
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, other))
+	override.JobID = other.ID
+	err = msgpackrpc.CallWithCodec(codec, "Job.OverrideSchedule", override, &resp)
+	must.ErrorContains(t, err, "doesn't have an active schedule")
+}
+
+func TestServer_applyJobActiveSchedules(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	defer cleanupS1()
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// The job is always active, but it's suspended and forced inactive by an
+	// expired override.
+	now := time.Now()
+	job := mock.Job()
+	job.TaskGroups[0].Count = 0
+	job.ActiveSchedule = &structs.JobActiveSchedule{
+		Windows:         []*structs.JobActiveWindow{{Cron: "* * * * *", Duration: time.Hour}},
+		Override:        &structs.JobActiveScheduleOverride{Until: now.Add(-time.Minute)},
+		SuspendedCounts: map[string]int{"web": 3},
+	}
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+	// Stopped jobs are left alone.
+	stopped := job.Copy()
+	stopped.ID = "stopped"
+	stopped.Stop = true
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1001, nil, stopped))
+
+	must.NoError(t, s1.applyJobActiveSchedules(now))
+
+	out, err := state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, 3, out.TaskGroups[0].Count)
+	must.False(t, out.ActiveSchedule.Suspended())
+	must.Nil(t, out.ActiveSchedule.Override)
+
+	events, _, err := state.ScalingEventsByJob(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Len(t, 1, events["web"])
+	must.Eq(t, structs.JobActiveScheduleActor, events["web"][0].Actor)
+	must.Eq(t, "Restored inside the active schedule", events["web"][0].Message)
+
+	evals, err := state.EvalsByJob(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Len(t, 1, evals)
+	must.Eq(t, structs.EvalTriggerScaling, evals[0].TriggeredBy)
+
+	out, err = state.JobByID(nil, stopped.Namespace, stopped.ID)
+	must.NoError(t, err)
+	must.Eq(t, 0, out.TaskGroups[0].Count)
+	must.True(t, out.ActiveSchedule.Suspended())
+}
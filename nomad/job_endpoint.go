@@ -75,8 +75,10 @@ func NewJobEndpoints(s *Server, ctx *RPCContext) *Job {
 			jobExposeCheckHook{},
 			jobImpliedConstraints{},
 			jobNodePoolMutatingHook{srv: s},
+			jobNodePoolHeartbeatHook{srv: s},
 			jobImplicitIdentitiesHook{srv: s},
 			jobNumaHook{},
+			jobUserLookupHook{srv: s},
 		},
 		validators: []jobValidator{
 			jobConnectHook{},
@@ -88,6 +90,7 @@ func NewJobEndpoints(s *Server, ctx *RPCContext) *Job {
 			&jobValidate{srv: s},
 			&memoryOversubscriptionValidate{srv: s},
 			jobNumaHook{},
+			jobLintHook{srv: s},
 		},
 	}
 }
@@ -159,7 +162,7 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 				if !allowCSIMount(aclObj, args.RequestNamespace()) {
 					return structs.ErrPermissionDenied
 				}
-			case structs.VolumeTypeHost:
+			case structs.VolumeTypeHost, structs.VolumeTypeLocal:
 				// If a volume is readonly, then we allow access if the user has
 				// ReadOnly or ReadWrite access to the volume. Otherwise we only
 				// allow access if they have ReadWrite access.
@@ -194,6 +197,10 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 			}
 		}
 
+		if !allowIdentityClaims(aclObj, args.RequestNamespace(), tg) {
+			return structs.ErrPermissionDenied
+		}
+
 		// Check if override is set and we do not have permissions
 		if args.PolicyOverride {
 			if !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySentinelOverride) {
@@ -315,11 +322,18 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	// Clear the Consul token
 	args.Job.ConsulToken = ""
 
-	// Preserve the existing task group counts, if so requested
+	// Preserve the existing task group counts, if so requested. The counts of
+	// the groups scaled to zero by the active schedule of the job are the
+	// ones they are restored to.
 	if existingJob != nil && args.PreserveCounts {
 		prevCounts := make(map[string]int)
 		for _, tg := range existingJob.TaskGroups {
 			prevCounts[tg.Name] = tg.Count
+			if existingJob.ActiveSchedule.Suspended() && tg.Count == 0 {
+				if count, ok := existingJob.ActiveSchedule.SuspendedCounts[tg.Name]; ok {
+					prevCounts[tg.Name] = count
+				}
+			}
 		}
 		for _, tg := range args.Job.TaskGroups {
 			if count, ok := prevCounts[tg.Name]; ok {
@@ -328,6 +342,10 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		}
 	}
 
+	// Apply the active schedule of the job, so a job registered outside of
+	// its windows isn't started until the next one.
+	applyJobActiveSchedule(args.Job, existingJob, time.Now())
+
 	// Submit a multiregion job to other regions (enterprise only).
 	// The job will have its region interpolated.
 	var newVersion uint64
@@ -387,6 +405,14 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		// Pre-register a deployment if necessary.
 		args.Deployment = j.multiregionCreateDeployment(job, eval)
 
+		// Keep the source in the payload store if it's too large for Raft.
+		if err := j.storeSubmissionSource(args); err != nil {
+			return err
+		}
+
+		// Store the warnings in the job summary
+		args.Warnings = registerWarnings(warnings)
+
 		// Commit this update via Raft
 		_, index, err := j.srv.raftApply(structs.JobRegisterRequestType, args)
 		if err != nil {
@@ -458,6 +484,33 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	return nil
 }
 
+// registerWarnings returns the warnings raised when registering a job as the
+// warnings stored in its summary, one per warning of the multierrors. It never
+// returns nil, so the previous warnings of the job are replaced.
+func registerWarnings(warnings []error) []*structs.JobWarning {
+	out := []*structs.JobWarning{}
+	var add func(err error)
+	add = func(err error) {
+		var mErr *multierror.Error
+		switch {
+		case err == nil:
+		case errors.As(err, &mErr):
+			for _, err := range mErr.Errors {
+				add(err)
+			}
+		default:
+			out = append(out, &structs.JobWarning{
+				Type:    structs.JobWarningTypeRegister,
+				Message: strings.TrimSpace(err.Error()),
+			})
+		}
+	}
+	for _, err := range warnings {
+		add(err)
+	}
+	return out
+}
+
 // propagateScalingPolicyIDs propagates scaling policy IDs from existing job
 // to updated job, or generates random IDs in new job
 func propagateScalingPolicyIDs(old, new *structs.Job) error {
@@ -528,6 +581,20 @@ func (j *Job) Summary(args *structs.JobSummaryRequest, reply *structs.JobSummary
 			reply.JobSummary = out
 			if out != nil {
 				reply.Index = out.ModifyIndex
+				if !args.Health {
+					return nil
+				}
+
+				// The health is computed on read, do not block on the
+				// allocations and deployments it was computed from.
+				health, err := jobHealth(state, args)
+				if err != nil {
+					return err
+				}
+				if health != nil {
+					reply.JobSummary = out.Copy()
+					reply.JobSummary.Health = health
+				}
 			} else {
 				// Use the last index that affected the job_summary table
 				index, err := state.Index("job_summary")
@@ -544,6 +611,29 @@ func (j *Job) Summary(args *structs.JobSummaryRequest, reply *structs.JobSummary
 	return j.srv.blockingRPC(&opts)
 }
 
+// jobHealth computes the health of the job of the summary request, or nil if
+// the job doesn't exist.
+func jobHealth(store *state.StateStore, args *structs.JobSummaryRequest) (*structs.JobHealth, error) {
+	job, err := store.JobByID(nil, args.RequestNamespace(), args.JobID)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	allocs, err := store.AllocsByJob(nil, args.RequestNamespace(), args.JobID, false)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := store.DeploymentsByJobID(nil, args.RequestNamespace(), args.JobID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	restartWindow := args.RestartWindow
+	if restartWindow <= 0 {
+		restartWindow = structs.DefaultJobHealthRestartWindow
+	}
+	return structs.NewJobHealth(job, allocs, deployments, time.Now(), restartWindow), nil
+}
+
 // Validate validates a job.
 //
 // Must forward to the leader, because only the leader will have a live Vault
@@ -847,6 +937,12 @@ func (j *Job) Deregister(args *structs.JobDeregisterRequest, reply *structs.JobD
 	if args.JobID == "" {
 		return fmt.Errorf("missing job ID for deregistering")
 	}
+	if args.ServiceDrain < 0 {
+		return fmt.Errorf("service drain must not be negative")
+	}
+	if args.ServiceDrain > 0 && args.NoShutdownDelay {
+		return fmt.Errorf("service drain can't be combined with no shutdown delay")
+	}
 
 	// Lookup the job
 	snap, err := j.srv.fsm.State().Snapshot()
@@ -908,6 +1004,11 @@ func (j *Job) Deregister(args *structs.JobDeregisterRequest, reply *structs.JobD
 		return err
 	}
 
+	// Delete the payload and sources of the purged job
+	if args.Purge {
+		j.deleteStoredPayloads(args.RequestNamespace(), args.JobID)
+	}
+
 	// Populate the reply with job information
 	reply.JobModifyIndex = index
 	reply.EvalCreateIndex = index
@@ -1006,10 +1107,25 @@ func (j *Job) BatchDeregister(args *structs.JobBatchDeregisterRequest, reply *st
 		return err
 	}
 
+	// Delete the payloads and sources of the purged jobs
+	for jobNS, options := range args.Jobs {
+		if options.Purge {
+			j.deleteStoredPayloads(jobNS.Namespace, jobNS.ID)
+		}
+	}
+
 	reply.Index = index
 	return nil
 }
 
+// deleteStoredPayloads deletes the payload and sources of a purged job from
+// the payload store. Failures are only logged, since the job is already gone.
+func (j *Job) deleteStoredPayloads(namespace, jobID string) {
+	if err := j.srv.payloadStore.DeleteJob(namespace, jobID); err != nil {
+		j.logger.Warn("failed to delete job payloads", "namespace", namespace, "job_id", jobID, "error", err)
+	}
+}
+
 // Scale is used to modify one of the scaling targets in the job
 func (j *Job) Scale(args *structs.JobScaleRequest, reply *structs.JobRegisterResponse) error {
 	authErr := j.srv.Authenticate(j.ctx, args)
@@ -1102,6 +1218,8 @@ func (j *Job) Scale(args *structs.JobScaleRequest, reply *structs.JobRegisterRes
 			Message:       args.Message,
 			Error:         args.Error,
 			Meta:          args.Meta,
+			Actor:         args.GetIdentity().String(),
+			Annotation:    args.Annotation,
 		},
 	}
 
@@ -1199,6 +1317,168 @@ func (j *Job) Scale(args *structs.JobScaleRequest, reply *structs.JobRegisterRes
 	return nil
 }
 
+// OverrideSchedule is used to force a job with an active schedule active or
+// inactive regardless of its windows, or to clear the override.
+func (j *Job) OverrideSchedule(args *structs.JobOverrideScheduleRequest, reply *structs.JobRegisterResponse) error {
+	authErr := j.srv.Authenticate(j.ctx, args)
+	if done, err := j.srv.forward("Job.OverrideSchedule", args, args, reply); done {
+		return err
+	}
+	j.srv.MeasureRPCRate("job", structs.RateMetricWrite, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "override_schedule"}, time.Now())
+
+	namespace := args.RequestNamespace()
+
+	aclObj, err := j.srv.ResolveACL(args)
+	if err != nil {
+		return err
+	}
+
+	hasScaleJob := aclObj.AllowNsOp(namespace, acl.NamespaceCapabilityScaleJob)
+	hasSubmitJob := aclObj.AllowNsOp(namespace, acl.NamespaceCapabilitySubmitJob)
+	if !(hasScaleJob || hasSubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if ok, err := registrationsAreAllowed(aclObj, j.srv.State()); !ok || err != nil {
+		j.logger.Warn("job scaling is currently disabled for non-management ACL")
+		return structs.ErrJobRegistrationDisabled
+	}
+
+	if err := args.Validate(); err != nil {
+		return err
+	}
+
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	job, err := snap.JobByID(nil, namespace, args.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return structs.NewErrRPCCoded(http.StatusNotFound, fmt.Sprintf("job %q not found", args.JobID))
+	}
+	if job.ActiveSchedule == nil {
+		return structs.NewErrRPCCoded(http.StatusBadRequest,
+			fmt.Sprintf("job %q doesn't have an active schedule", args.JobID))
+	}
+
+	// Since job is going to be mutated we must copy it since state store methods
+	// return a shared pointer.
+	job = job.Copy()
+	job.ActiveSchedule.Override = args.Override.Copy()
+	changes := job.ApplyActiveSchedule(time.Now())
+
+	message := "Active schedule override cleared"
+	switch {
+	case args.Override == nil:
+	case args.Override.Active:
+		message = "Active schedule overridden to active"
+	default:
+		message = "Active schedule overridden to inactive"
+	}
+
+	if err := j.srv.commitJobActiveSchedule(job, changes, message, args.GetIdentity().String(), reply); err != nil {
+		j.logger.Error("job active schedule override failed", "error", err)
+		return err
+	}
+
+	j.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
+// Suspend is used to suspend a batch job, stopping its running allocations
+// while keeping the job and its version so it can be resumed later, or to
+// resume a suspended job.
+func (j *Job) Suspend(args *structs.JobSuspendRequest, reply *structs.JobSuspendResponse) error {
+	authErr := j.srv.Authenticate(j.ctx, args)
+	if done, err := j.srv.forward("Job.Suspend", args, args, reply); done {
+		return err
+	}
+	j.srv.MeasureRPCRate("job", structs.RateMetricWrite, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "suspend"}, time.Now())
+
+	// Check for submit-job permissions
+	if aclObj, err := j.srv.ResolveACL(args); err != nil {
+		return err
+	} else if !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID")
+	}
+
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	job, err := snap.JobByID(nil, args.RequestNamespace(), args.JobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return structs.NewErrRPCCoded(http.StatusNotFound, fmt.Sprintf("job %q not found", args.JobID))
+	}
+	switch {
+	case job.Type != structs.JobTypeBatch:
+		return structs.NewErrRPCCoded(http.StatusBadRequest,
+			fmt.Sprintf("only %q jobs can be suspended", structs.JobTypeBatch))
+	case job.IsPeriodic() || job.IsParameterized():
+		return structs.NewErrRPCCoded(http.StatusBadRequest,
+			"periodic and parameterized jobs can't be suspended")
+	case job.Stop:
+		return structs.NewErrRPCCoded(http.StatusBadRequest,
+			fmt.Sprintf("job %q is stopped", args.JobID))
+	case args.Suspend && job.Suspended:
+		return structs.NewErrRPCCoded(http.StatusBadRequest,
+			fmt.Sprintf("job %q is already suspended", args.JobID))
+	case !args.Suspend && !job.Suspended:
+		return structs.NewErrRPCCoded(http.StatusBadRequest,
+			fmt.Sprintf("job %q isn't suspended", args.JobID))
+	}
+
+	triggeredBy := structs.EvalTriggerJobResume
+	if args.Suspend {
+		triggeredBy = structs.EvalTriggerJobSuspend
+	}
+	now := time.Now().UnixNano()
+	args.Eval = &structs.Evaluation{
+		ID:             uuid.Generate(),
+		Namespace:      args.RequestNamespace(),
+		Priority:       job.Priority,
+		Type:           job.Type,
+		TriggeredBy:    triggeredBy,
+		JobID:          job.ID,
+		JobModifyIndex: job.ModifyIndex,
+		Status:         structs.EvalStatusPending,
+		CreateTime:     now,
+		ModifyTime:     now,
+	}
+
+	_, index, err := j.srv.raftApply(structs.JobSuspendRequestType, args)
+	if err != nil {
+		j.logger.Error("job suspend failed", "error", err)
+		return err
+	}
+
+	reply.EvalID = args.Eval.ID
+	reply.EvalCreateIndex = index
+	reply.JobModifyIndex = job.JobModifyIndex
+	reply.Index = index
+
+	j.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
 func (j *Job) GetJobSubmission(args *structs.JobSubmissionRequest, reply *structs.JobSubmissionResponse) error {
 	authErr := j.srv.Authenticate(j.ctx, args)
 	if done, err := j.srv.forward("Job.GetJobSubmission", args, args, reply); done {
@@ -1228,7 +1508,17 @@ func (j *Job) GetJobSubmission(args *structs.JobSubmissionRequest, reply *struct
 				return err
 			}
 
-			// Setup the output
+			// Setup the output, reading the source from the payload store
+			// if it's kept there
+			if out != nil && out.SourceRef != "" {
+				source, err := j.srv.payloadStore.Get(out.SourceRef)
+				if err != nil {
+					return err
+				}
+				out = out.Copy()
+				out.Source = string(source)
+				out.SourceRef = ""
+			}
 			reply.Submission = out
 			if out != nil {
 				// associate with the index of the job this submission originates from
@@ -1574,6 +1864,135 @@ func (j *Job) Allocations(args *structs.JobSpecificRequest,
 	return j.srv.blockingRPC(&opts)
 }
 
+// LocalVolumeClaims is used to list the local volume claims of a job
+func (j *Job) LocalVolumeClaims(args *structs.LocalVolumeClaimListRequest,
+	reply *structs.LocalVolumeClaimListResponse) error {
+	authErr := j.srv.Authenticate(j.ctx, args)
+	if done, err := j.srv.forward("Job.LocalVolumeClaims", args, args, reply); done {
+		return err
+	}
+	j.srv.MeasureRPCRate("job", structs.RateMetricList, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "local_volume_claims"}, time.Now())
+
+	// Check for read-job permissions
+	if aclObj, err := j.srv.ResolveACL(args); err != nil {
+		return err
+	} else if !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilityReadJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID")
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, store *state.StateStore) error {
+			iter, err := store.LocalVolumeClaimsByJob(ws, args.RequestNamespace(), args.JobID)
+			if err != nil {
+				return err
+			}
+
+			reply.Claims = []*structs.LocalVolumeClaim{}
+			for raw := iter.Next(); raw != nil; raw = iter.Next() {
+				reply.Claims = append(reply.Claims, raw.(*structs.LocalVolumeClaim))
+			}
+
+			// Use the last index that affected the local volume claims table
+			index, err := store.Index(state.TableLocalVolumeClaims)
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			j.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return j.srv.blockingRPC(&opts)
+}
+
+// DeleteLocalVolumeClaims is used to delete the local volume claims of a job,
+// allowing its allocations to be placed on other nodes. An evaluation is
+// created to place the allocations that were waiting for their claimed node.
+func (j *Job) DeleteLocalVolumeClaims(args *structs.LocalVolumeClaimDeleteRequest,
+	reply *structs.LocalVolumeClaimDeleteResponse) error {
+	authErr := j.srv.Authenticate(j.ctx, args)
+	if done, err := j.srv.forward("Job.DeleteLocalVolumeClaims", args, args, reply); done {
+		return err
+	}
+	j.srv.MeasureRPCRate("job", structs.RateMetricWrite, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "delete_local_volume_claims"}, time.Now())
+
+	// Check for submit-job permissions
+	if aclObj, err := j.srv.ResolveACL(args); err != nil {
+		return err
+	} else if !aclObj.AllowNsOp(args.RequestNamespace(), acl.NamespaceCapabilitySubmitJob) {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.JobID == "" {
+		return fmt.Errorf("missing job ID")
+	}
+
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	ws := memdb.NewWatchSet()
+	if args.AllocName != "" {
+		claim, err := snap.LocalVolumeClaim(ws, args.RequestNamespace(), args.JobID, args.AllocName)
+		if err != nil {
+			return err
+		}
+		if claim == nil {
+			return fmt.Errorf("local volume claim not found")
+		}
+	}
+
+	job, err := snap.JobByID(ws, args.RequestNamespace(), args.JobID)
+	if err != nil {
+		return err
+	}
+	args.Eval = nil
+	if job != nil && !job.Stopped() && !job.IsPeriodic() && !job.IsParameterized() {
+		now := time.Now().UnixNano()
+		args.Eval = &structs.Evaluation{
+			ID:             uuid.Generate(),
+			Namespace:      args.RequestNamespace(),
+			Priority:       job.Priority,
+			Type:           job.Type,
+			TriggeredBy:    structs.EvalTriggerJobRegister,
+			JobID:          job.ID,
+			JobModifyIndex: job.ModifyIndex,
+			Status:         structs.EvalStatusPending,
+			CreateTime:     now,
+			ModifyTime:     now,
+		}
+	}
+
+	_, index, err := j.srv.raftApply(structs.LocalVolumeClaimDeleteRequestType, args)
+	if err != nil {
+		j.logger.Error("delete local volume claims failed", "error", err)
+		return err
+	}
+
+	if args.Eval != nil {
+		reply.EvalID = args.Eval.ID
+		reply.EvalCreateIndex = index
+	}
+	reply.Index = index
+	return nil
+}
+
 // Evaluations is used to list the evaluations for a job
 func (j *Job) Evaluations(args *structs.JobSpecificRequest,
 	reply *structs.JobEvaluationsResponse) error {
@@ -1801,8 +2220,14 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 	}
 	args.Job = job
 
-	// Set the warning message
+	// Set the warning message, and return the lints separately so they can
+	// be inspected by tools.
 	reply.Warnings = helper.MergeMultierrorWarnings(warnings...)
+	for _, warning := range warnings {
+		if lint, ok := warning.(*structs.JobLint); ok {
+			reply.Lints = append(reply.Lints, lint)
+		}
+	}
 
 	// Check job submission permissions, which we assume is the same for plan
 	if aclObj, err := j.srv.ResolveACL(args); err != nil {
@@ -1862,6 +2287,9 @@ func (j *Job) Plan(args *structs.JobPlanRequest, reply *structs.JobPlanResponse)
 		return err
 	}
 
+	// Plan the job as it would be registered given its active schedule
+	applyJobActiveSchedule(args.Job, existingJob, time.Now())
+
 	var index uint64
 	var updatedIndex uint64
 
@@ -2050,7 +2478,8 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 	}
 
 	// Validate the arguments
-	if err := validateDispatchRequest(args, parameterizedJob); err != nil {
+	storePayload := j.srv.payloadStore.Keeps(len(args.Payload))
+	if err := validateDispatchRequest(args, parameterizedJob, storePayload); err != nil {
 		return err
 	}
 
@@ -2110,8 +2539,20 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 		dispatchJob.Meta[k] = v
 	}
 
-	// Compress the payload
+	// Interpolate the dispatched meta in the sources of the volumes
+	dispatchJob.InterpolateVolumeSources()
+
+	// Compress the payload, and keep it in the payload store if it's too large
+	// for Raft
 	dispatchJob.Payload = snappy.Encode(nil, args.Payload)
+	if storePayload {
+		ref, err := j.srv.payloadStore.PutDispatchPayload(dispatchJob.Namespace, dispatchJob.ID, dispatchJob.Payload)
+		if err != nil {
+			return err
+		}
+		dispatchJob.Payload = nil
+		dispatchJob.PayloadRef = ref
+	}
 
 	regReq := &structs.JobRegisterRequest{
 		Job:          dispatchJob,
@@ -2166,9 +2607,57 @@ func (j *Job) Dispatch(args *structs.JobDispatchRequest, reply *structs.JobDispa
 	return nil
 }
 
+// DispatchPayload allows nodes to retrieve the dispatch payload of one of
+// their allocations when it's kept in the payload store of the servers.
+//
+// This is an internal-only RPC and not exposed via the HTTP API.
+func (j *Job) DispatchPayload(args *structs.JobDispatchPayloadRequest, reply *structs.JobDispatchPayloadResponse) error {
+
+	aclObj, err := j.srv.AuthenticateClientOnly(j.ctx, args)
+	j.srv.MeasureRPCRate("job", structs.RateMetricRead, args)
+	if err != nil {
+		return structs.ErrPermissionDenied
+	}
+
+	if done, err := j.srv.forward("Job.DispatchPayload", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "job", "dispatch_payload"}, time.Now())
+
+	if !aclObj.AllowClientOp() {
+		return structs.ErrPermissionDenied
+	}
+
+	alloc, err := j.srv.State().AllocByID(nil, args.AllocID)
+	if err != nil {
+		return err
+	}
+	if alloc == nil {
+		return fmt.Errorf("allocation %q not found", args.AllocID)
+	}
+
+	// Nodes can only retrieve the payloads of their own allocations.
+	if alloc.NodeID != args.GetIdentity().ClientID {
+		return structs.ErrPermissionDenied
+	}
+
+	if alloc.Job == nil || alloc.Job.PayloadRef == "" {
+		return fmt.Errorf("allocation %q doesn't have a stored dispatch payload", args.AllocID)
+	}
+
+	payload, err := j.srv.payloadStore.Get(alloc.Job.PayloadRef)
+	if err != nil {
+		return err
+	}
+	reply.Payload = payload
+	j.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
 // validateDispatchRequest returns whether the request is valid given the
-// parameterized job.
-func validateDispatchRequest(req *structs.JobDispatchRequest, job *structs.Job) error {
+// parameterized job. The size of the payload isn't limited if it's kept in the
+// payload store.
+func validateDispatchRequest(req *structs.JobDispatchRequest, job *structs.Job, storePayload bool) error {
 	// Check the payload constraint is met
 	hasInputData := len(req.Payload) != 0
 	if job.ParameterizedJob.Payload == structs.DispatchPayloadRequired && !hasInputData {
@@ -2178,7 +2667,7 @@ func validateDispatchRequest(req *structs.JobDispatchRequest, job *structs.Job)
 	}
 
 	// Check the payload doesn't exceed the size limit
-	if l := len(req.Payload); l > DispatchPayloadSizeLimit {
+	if l := len(req.Payload); l > DispatchPayloadSizeLimit && !storePayload {
 		return fmt.Errorf("Payload exceeds maximum size; %d > %d", l, DispatchPayloadSizeLimit)
 	}
 
@@ -2419,3 +2908,32 @@ func (j *Job) GetServiceRegistrations(
 		},
 	})
 }
+
+// allowIdentityClaims is called on Job register to check the extra claims of
+// the workload identities of the tasks and services of the group are allowed
+// in the namespace.
+func allowIdentityClaims(aclObj *acl.ACL, namespace string, tg *structs.TaskGroup) bool {
+	var identities []*structs.WorkloadIdentity
+	for _, service := range tg.Services {
+		identities = append(identities, service.Identity)
+	}
+	for _, task := range tg.Tasks {
+		identities = append(identities, task.Identity)
+		identities = append(identities, task.Identities...)
+		for _, service := range task.Services {
+			identities = append(identities, service.Identity)
+		}
+	}
+
+	for _, wid := range identities {
+		if wid == nil {
+			continue
+		}
+		for claim := range wid.ExtraClaims {
+			if !aclObj.AllowIdentityClaim(namespace, claim) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+// jobLintHook is an admission hook that evaluates the lint rules configured
+// on the servers. Violations of the rules with the warn level are returned as
+// warnings, while violations of the rules with the deny level reject the job.
+type jobLintHook struct {
+	srv *Server
+}
+
+func (jobLintHook) Name() string {
+	return "lint"
+}
+
+func (h jobLintHook) Validate(job *structs.Job) ([]error, error) {
+	rules := h.srv.config.JobLint.Rules()
+
+	var warnings []error
+	var mErr *multierror.Error
+	for _, lint := range lintJob(job) {
+		switch rules[lint.Rule] {
+		case config.JobLintLevelWarn:
+			lint.Level = config.JobLintLevelWarn
+			warnings = append(warnings, lint)
+		case config.JobLintLevelDeny:
+			lint.Level = config.JobLintLevelDeny
+			mErr = multierror.Append(mErr, lint)
+		}
+	}
+	return warnings, mErr.ErrorOrNil()
+}
+
+// lintJob returns the violations of all the lint rules by the job, without
+// their level.
+func lintJob(job *structs.Job) []*structs.JobLint {
+	var lints []*structs.JobLint
+
+	lintServices := func(tg, task string, services []*structs.Service) {
+		for _, service := range services {
+			if len(service.Checks) == 0 {
+				lints = append(lints, &structs.JobLint{
+					Rule:      config.JobLintMissingHealthChecks,
+					TaskGroup: tg,
+					Task:      task,
+					Message:   fmt.Sprintf("service %q has no health checks", service.Name),
+				})
+			}
+		}
+	}
+
+	for _, tg := range job.TaskGroups {
+		if job.Type == structs.JobTypeService && tg.Update.IsEmpty() {
+			lints = append(lints, &structs.JobLint{
+				Rule:      config.JobLintMissingUpdate,
+				TaskGroup: tg.Name,
+				Message:   "no update block",
+			})
+		}
+		lintServices(tg.Name, "", tg.Services)
+
+		for _, task := range tg.Tasks {
+			lintServices(tg.Name, task.Name, task.Services)
+
+			if lintDefaultResources(task.Resources) {
+				lints = append(lints, &structs.JobLint{
+					Rule:      config.JobLintMissingResources,
+					TaskGroup: tg.Name,
+					Task:      task.Name,
+					Message:   "no resources block, the default resources are used",
+				})
+			}
+
+			if image, ok := task.Config["image"].(string); ok && lintLatestImage(image) {
+				lints = append(lints, &structs.JobLint{
+					Rule:      config.JobLintLatestImageTag,
+					TaskGroup: tg.Name,
+					Task:      task.Name,
+					Message:   fmt.Sprintf("image %q is not pinned to a tag other than latest", image),
+				})
+			}
+		}
+	}
+	return lints
+}
+
+// lintDefaultResources returns whether the resources of a task are the
+// defaults. Tasks without a resources block are given the default resources
+// when the job is canonicalized, so both can't be told apart.
+func lintDefaultResources(resources *structs.Resources) bool {
+	if resources == nil {
+		return true
+	}
+	defaults := structs.DefaultResources()
+	return resources.CPU == defaults.CPU &&
+		resources.Cores == 0 &&
+		resources.MemoryMB == defaults.MemoryMB &&
+		resources.MemoryMaxMB == 0
+}
+
+// lintLatestImage returns whether the container image has no tag or the
+// "latest" tag. Images pinned to a digest, and images interpolated when the
+// task starts, are ignored.
+func lintLatestImage(image string) bool {
+	if image == "" || strings.Contains(image, "@") || strings.Contains(image, "${") {
+		return false
+	}
+
+	// The registry of the image may have a port, so the tag is looked up in
+	// the last segment of the name only.
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, found := strings.Cut(name, ":")
+	return !found || tag == "latest"
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/shoenig/test/must"
+)
+
+func Test_jobLintHook_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	job := mock.Job()
+	task := job.TaskGroups[0].Tasks[0]
+	task.Driver = "docker"
+	task.Config = map[string]interface{}{"image": "redis:latest"}
+	task.Resources = structs.DefaultResources()
+
+	// All the rules are off by default.
+	hook := jobLintHook{srv: &Server{config: &Config{}}}
+	warnings, err := hook.Validate(job)
+	must.NoError(t, err)
+	must.SliceEmpty(t, warnings)
+
+	hook.srv.config.JobLint = &config.JobLintConfig{
+		MissingHealthChecks: config.JobLintLevelWarn,
+		MissingResources:    config.JobLintLevelWarn,
+		LatestImageTag:      config.JobLintLevelDeny,
+		MissingUpdate:       config.JobLintLevelOff,
+	}
+	warnings, err = hook.Validate(job)
+	must.ErrorContains(t, err, `lint latest_image_tag in task "web" of group "web": image "redis:latest" is not pinned`)
+	must.Eq(t, []error{
+		&structs.JobLint{
+			Rule:      config.JobLintMissingHealthChecks,
+			Level:     config.JobLintLevelWarn,
+			TaskGroup: "web",
+			Task:      "web",
+			Message:   `service "web-admin" has no health checks`,
+		},
+		&structs.JobLint{
+			Rule:      config.JobLintMissingResources,
+			Level:     config.JobLintLevelWarn,
+			TaskGroup: "web",
+			Task:      "web",
+			Message:   "no resources block, the default resources are used",
+		},
+	}, warnings)
+}
+
+func Test_lintJob(t *testing.T) {
+	ci.Parallel(t)
+
+	rules := func(job *structs.Job) []string {
+		var rules []string
+		for _, lint := range lintJob(job) {
+			rules = append(rules, lint.Rule)
+		}
+		return rules
+	}
+
+	job := mock.Job()
+	job.TaskGroups[0].Update = structs.DefaultUpdateStrategy.Copy()
+	job.TaskGroups[0].Tasks[0].Services[1].Checks = job.TaskGroups[0].Tasks[0].Services[0].Checks
+	must.SliceEmpty(t, rules(job))
+
+	job.TaskGroups[0].Update = nil
+	must.Eq(t, []string{config.JobLintMissingUpdate}, rules(job))
+
+	// Only service jobs are expected to have an update block.
+	job.Type = structs.JobTypeBatch
+	must.SliceEmpty(t, rules(job))
+
+	job.TaskGroups[0].Services = []*structs.Service{{Name: "group"}}
+	job.TaskGroups[0].Tasks[0].Resources = nil
+	must.Eq(t, []string{config.JobLintMissingHealthChecks, config.JobLintMissingResources}, rules(job))
+}
+
+func Test_lintLatestImage(t *testing.T) {
+	ci.Parallel(t)
+
+	for image, latest := range map[string]bool{
+		"redis":                             true,
+		"redis:latest":                      true,
+		"localhost:5000/redis":              true,
+		"localhost:5000/team/redis:latest":  true,
+		"redis:7.2":                         false,
+		"localhost:5000/redis:7.2":          false,
+		"redis@sha256:0123456789abcdef":     false,
+		"redis:${NOMAD_META_redis_version}": false,
+		"${NOMAD_META_image}":               false,
+		"":                                  false,
+	} {
+		must.Eq(t, latest, lintLatestImage(image), must.Sprintf("image %q", image))
+	}
+}
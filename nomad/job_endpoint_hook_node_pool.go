@@ -6,6 +6,7 @@ package nomad
 import (
 	"fmt"
 
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -32,3 +33,34 @@ func (j jobNodePoolValidatingHook) Validate(job *structs.Job) ([]error, error) {
 
 	return j.enterpriseValidation(job, pool)
 }
+
+// jobNodePoolHeartbeatHook is an admission hook that applies the
+// max_client_disconnect of the node pool heartbeat configuration to the task
+// groups of the job that don't configure how to handle disconnected clients.
+type jobNodePoolHeartbeatHook struct {
+	srv *Server
+}
+
+func (j jobNodePoolHeartbeatHook) Name() string {
+	return "node-pool-heartbeat"
+}
+
+func (j jobNodePoolHeartbeatHook) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	pool, err := j.srv.State().NodePoolByName(nil, job.NodePool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The node pool validating hook rejects jobs in nonexistent node pools.
+	if pool == nil || pool.HeartbeatConfig == nil || pool.HeartbeatConfig.MaxClientDisconnect == 0 {
+		return job, nil, nil
+	}
+
+	for _, tg := range job.TaskGroups {
+		if tg.MaxClientDisconnect != nil || tg.StopAfterClientDisconnect != nil {
+			continue
+		}
+		tg.MaxClientDisconnect = pointer.Of(pool.HeartbeatConfig.MaxClientDisconnect)
+	}
+	return job, nil, nil
+}
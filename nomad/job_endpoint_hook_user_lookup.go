@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"slices"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// userDirectoryLookupConstraint matches the clients resolving the users of
+// tasks with the directory service of the host.
+var userDirectoryLookupConstraint = &structs.Constraint{
+	LTarget: "${attr.user.directory_lookup}",
+	RTarget: "true",
+	Operand: "=",
+}
+
+// jobUserLookupHook is an admission hook that constrains the task groups with
+// tasks running as a user to the clients which resolve users with their
+// directory service, when the servers are configured to expect users of the
+// drivers of these tasks to be managed by the directory service. This lets
+// plans report jobs that can't be placed instead of failing at task start.
+type jobUserLookupHook struct {
+	srv *Server
+}
+
+func (jobUserLookupHook) Name() string {
+	return "user-lookup"
+}
+
+func (h jobUserLookupHook) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	drivers := h.srv.config.UserDirectoryLookupDrivers
+	if len(drivers) == 0 {
+		return job, nil, nil
+	}
+
+	for _, tg := range job.TaskGroups {
+		for _, task := range tg.Tasks {
+			if task.User != "" && slices.Contains(drivers, task.Driver) {
+				mutateConstraint(constraintMatcherFull, tg, userDirectoryLookupConstraint)
+				break
+			}
+		}
+	}
+	return job, nil, nil
+}
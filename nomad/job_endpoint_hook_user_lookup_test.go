@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/shoenig/test/must"
+)
+
+func Test_jobUserLookupHook_Mutate(t *testing.T) {
+	ci.Parallel(t)
+
+	job := mock.Job()
+	job.TaskGroups = append(job.TaskGroups, job.TaskGroups[0].Copy())
+	job.TaskGroups[1].Name = "api"
+	job.TaskGroups[0].Tasks[0].User = "svc-foo"
+	job.TaskGroups[1].Tasks[0].User = "svc-foo"
+	job.TaskGroups[1].Tasks[0].Driver = "docker"
+	constraints := len(job.TaskGroups[0].Constraints)
+
+	// Disabled by default
+	hook := jobUserLookupHook{srv: &Server{config: &Config{}}}
+	out, warnings, err := hook.Mutate(job.Copy())
+	must.NoError(t, err)
+	must.SliceEmpty(t, warnings)
+	must.Len(t, constraints, out.TaskGroups[0].Constraints)
+
+	// Only groups with tasks running as a user with the configured drivers
+	// are constrained
+	hook.srv.config.UserDirectoryLookupDrivers = []string{"exec", "java"}
+	out, _, err = hook.Mutate(job.Copy())
+	must.NoError(t, err)
+	must.SliceContains(t, out.TaskGroups[0].Constraints, userDirectoryLookupConstraint)
+	must.SliceNotContains(t, out.TaskGroups[1].Constraints, userDirectoryLookupConstraint)
+
+	// The constraint isn't added twice
+	out, _, err = hook.Mutate(out)
+	must.NoError(t, err)
+	must.Len(t, constraints+1, out.TaskGroups[0].Constraints)
+
+	// Tasks without a user are not constrained
+	job.TaskGroups[0].Tasks[0].User = ""
+	out, _, err = hook.Mutate(job.Copy())
+	must.NoError(t, err)
+	must.SliceNotContains(t, out.TaskGroups[0].Constraints, userDirectoryLookupConstraint)
+}
@@ -29,17 +29,22 @@ func (h jobVaultHook) Validate(job *structs.Job) ([]error, error) {
 		return nil, nil
 	}
 
-	vconf := h.srv.config.VaultConfig
-	if !vconf.IsEnabled() {
-		return nil, fmt.Errorf("Vault not enabled but used in the job")
-	}
-
 	err := h.validateClustersForNamespace(job, vaultBlocks)
 	if err != nil {
 		return nil, err
 	}
 
+	// Vault tokens provided on job submission are only used by the legacy
+	// token-based workflow, which is only supported for the default cluster
+	// and not used by tasks with a workload identity for Vault, including the
+	// default identity injected by the implicit identities hook.
+	vaultBlocks = legacyVaultBlocks(job, defaultClusterVaultBlocks(vaultBlocks))
+	if len(vaultBlocks) == 0 {
+		return nil, nil
+	}
+
 	// Return early if Vault configuration doesn't require authentication.
+	vconf := h.srv.config.VaultConfig
 	if vconf.AllowsUnauthenticated() {
 		return nil, nil
 	}
@@ -70,6 +75,44 @@ func (h jobVaultHook) Validate(job *structs.Job) ([]error, error) {
 	return nil, nil
 }
 
+// defaultClusterVaultBlocks returns the subset of Vault blocks that use the
+// default Vault cluster.
+func defaultClusterVaultBlocks(blocks map[string]map[string]*structs.Vault) map[string]map[string]*structs.Vault {
+	result := make(map[string]map[string]*structs.Vault, len(blocks))
+	for tg, tasks := range blocks {
+		for task, vault := range tasks {
+			if vault.Cluster != structs.VaultDefaultCluster {
+				continue
+			}
+			if result[tg] == nil {
+				result[tg] = make(map[string]*structs.Vault, len(tasks))
+			}
+			result[tg][task] = vault
+		}
+	}
+	return result
+}
+
+// legacyVaultBlocks returns the subset of Vault blocks of tasks that don't
+// have a workload identity for Vault, and so use the legacy token-based
+// workflow.
+func legacyVaultBlocks(job *structs.Job, blocks map[string]map[string]*structs.Vault) map[string]map[string]*structs.Vault {
+	result := make(map[string]map[string]*structs.Vault, len(blocks))
+	for _, tg := range job.TaskGroups {
+		for _, task := range tg.Tasks {
+			vault, ok := blocks[tg.Name][task.Name]
+			if !ok || task.GetIdentity(vault.IdentityName()) != nil {
+				continue
+			}
+			if result[tg.Name] == nil {
+				result[tg.Name] = make(map[string]*structs.Vault, len(tg.Tasks))
+			}
+			result[tg.Name][task.Name] = vault
+		}
+	}
+	return result
+}
+
 // validatePolicies returns an error if the job contains Vault blocks that
 // require policies that the request token is not allowed to access.
 func (jobVaultHook) validatePolicies(
@@ -6,10 +6,10 @@
 package nomad
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/nomad/structs"
 	vapi "github.com/hashicorp/vault/api"
 )
@@ -28,16 +28,26 @@ func (jobVaultHook) validateNamespaces(
 	return nil
 }
 
+// validateClustersForNamespace returns an error if the job contains Vault
+// blocks that reference a cluster that is not configured and enabled in the
+// server agent configuration. Nomad CE does not restrict which clusters a
+// namespace may use.
 func (h jobVaultHook) validateClustersForNamespace(_ *structs.Job, blocks map[string]map[string]*structs.Vault) error {
+	var mErr *multierror.Error
 	for _, tg := range blocks {
 		for _, vault := range tg {
-			if vault.Cluster != "default" {
-				return errors.New("non-default Vault cluster requires Nomad Enterprise")
+			conf, ok := h.srv.config.VaultConfigs[vault.Cluster]
+			if !ok || conf == nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("Vault cluster %q not present in configuration", vault.Cluster))
+				continue
+			}
+			if !conf.IsEnabled() {
+				mErr = multierror.Append(mErr, fmt.Errorf("Vault not enabled for cluster %q but used in the job", vault.Cluster))
 			}
 		}
 	}
 
-	return nil
+	return mErr.ErrorOrNil()
 }
 
 func (j jobVaultHook) Mutate(job *structs.Job) (*structs.Job, []error, error) {
@@ -46,7 +56,7 @@ func (j jobVaultHook) Mutate(job *structs.Job) (*structs.Job, []error, error) {
 			if task.Vault == nil || task.Vault.Cluster != "" {
 				continue
 			}
-			task.Vault.Cluster = "default"
+			task.Vault.Cluster = structs.VaultDefaultCluster
 		}
 	}
 
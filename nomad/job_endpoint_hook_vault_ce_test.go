@@ -8,10 +8,13 @@ package nomad
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/shoenig/test/must"
 )
@@ -42,6 +45,62 @@ func TestJobEndpointHook_VaultCE(t *testing.T) {
 	// skipping over the rest of Validate b/c it requires an actual
 	// Vault cluster
 	err = hook.validateClustersForNamespace(job, job.Vault())
-	must.EqError(t, err, "non-default Vault cluster requires Nomad Enterprise")
+	must.ErrorContains(t, err, `Vault cluster "infra" not present in configuration`)
 
+	srv.config.VaultConfigs[structs.VaultDefaultCluster].Enabled = pointer.Of(true)
+	srv.config.VaultConfigs["infra"] = &config.VaultConfig{
+		Name:    "infra",
+		Enabled: pointer.Of(false),
+	}
+	err = hook.validateClustersForNamespace(job, job.Vault())
+	must.ErrorContains(t, err, `Vault not enabled for cluster "infra" but used in the job`)
+
+	srv.config.VaultConfigs["infra"].Enabled = pointer.Of(true)
+	err = hook.validateClustersForNamespace(job, job.Vault())
+	must.NoError(t, err)
+}
+
+func TestJobEndpointHook_Vault_implicitIdentity(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, cleanup := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+	})
+	t.Cleanup(cleanup)
+	testutil.WaitForLeader(t, srv.RPC)
+
+	vconf := srv.config.VaultConfigs[structs.VaultDefaultCluster]
+	vconf.Enabled = pointer.Of(true)
+	vconf.AllowUnauthenticated = pointer.Of(false)
+	srv.config.VaultConfig = vconf
+
+	newJob := func() *structs.Job {
+		job := mock.Job()
+		job.TaskGroups[0].Tasks[0].Vault = &structs.Vault{Cluster: structs.VaultDefaultCluster}
+		return job
+	}
+	implicitHook := jobImplicitIdentitiesHook{srv}
+	vaultHook := jobVaultHook{srv}
+
+	// Tasks without a Vault identity use the legacy workflow and require a
+	// Vault token.
+	job, _, err := implicitHook.Mutate(newJob())
+	must.NoError(t, err)
+	_, err = vaultHook.Validate(job)
+	must.EqError(t, err, "Vault used in the job but missing Vault token")
+
+	// Tasks with the default identity of the cluster don't.
+	vconf.DefaultIdentity = &config.WorkloadIdentityConfig{
+		Audience: []string{"vault.io"},
+		TTL:      pointer.Of(time.Hour),
+	}
+	job, _, err = implicitHook.Mutate(newJob())
+	must.NoError(t, err)
+	wid := job.TaskGroups[0].Tasks[0].GetIdentity("vault_default")
+	must.NotNil(t, wid)
+	must.Eq(t, []string{"vault.io"}, wid.Audience)
+	must.Eq(t, time.Hour, wid.TTL)
+
+	_, err = vaultHook.Validate(job)
+	must.NoError(t, err)
 }
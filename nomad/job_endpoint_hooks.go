@@ -160,6 +160,10 @@ func (c *jobCanonicalizer) Name() string {
 func (c *jobCanonicalizer) Mutate(job *structs.Job) (*structs.Job, []error, error) {
 	job.Canonicalize()
 
+	// The sources of the volumes must be known to place the allocations, so
+	// the references to the meta are interpolated before the job is stored.
+	job.InterpolateVolumeSources()
+
 	// If the job priority is not set, we fallback on the defaults specified in the server config
 	if job.Priority == 0 {
 		job.Priority = c.srv.GetConfig().JobDefaultPriority
@@ -530,6 +534,10 @@ func (j *Job) submissionController(args *structs.JobRegisterRequest) error {
 	}
 	maxSize := j.srv.GetConfig().JobMaxSourceSize
 	submission := args.Submission
+	// the source isn't limited if it's kept in the payload store
+	if j.srv.payloadStore.Keeps(len(submission.Source)) {
+		return nil
+	}
 	// discard the submission if the source + variables is larger than the maximum
 	// allowable size as set by client config
 	totalSize := len(submission.Source)
@@ -546,3 +554,23 @@ func (j *Job) submissionController(args *structs.JobRegisterRequest) error {
 	}
 	return nil
 }
+
+// storeSubmissionSource moves the source of the submission to the payload
+// store if it's above its threshold, so only its reference is written to the
+// Raft log.
+func (j *Job) storeSubmissionSource(args *structs.JobRegisterRequest) error {
+	submission := args.Submission
+	if submission == nil || !j.srv.payloadStore.Keeps(len(submission.Source)) {
+		return nil
+	}
+
+	ref, err := j.srv.payloadStore.PutSource(args.Job.Namespace, args.Job.ID, submission.Source)
+	if err != nil {
+		return err
+	}
+	submission = submission.Copy()
+	submission.Source = ""
+	submission.SourceRef = ref
+	args.Submission = submission
+	return nil
+}
@@ -4,13 +4,17 @@
 package nomad
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang/snappy"
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-memdb"
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/acl"
@@ -21,7 +25,9 @@ import (
 	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/payloadstore"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/hashicorp/raft"
 	"github.com/kr/pretty"
@@ -2173,6 +2179,50 @@ func TestJobEndpoint_Register_ValidateMemoryMax(t *testing.T) {
 	require.Empty(t, resp.Warnings)
 }
 
+// TestJobEndpoint_Register_Warnings asserts the warnings raised when
+// registering a job are persisted in its summary, and replaced when a new
+// version of the job is registered.
+func TestJobEndpoint_Register_Warnings(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	job := mock.Job()
+	job.TaskGroups[0].Tasks[0].Resources.MemoryMaxMB = 2000
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	must.StrContains(t, resp.Warnings, "Memory oversubscription is not enabled")
+
+	summary, err := s1.fsm.State().JobSummaryByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.SliceLen(t, 1, summary.Warnings)
+	must.Eq(t, structs.JobWarningTypeRegister, summary.Warnings[0].Type)
+	must.StrContains(t, summary.Warnings[0].Message, "Memory oversubscription is not enabled")
+
+	// Registering a version of the job without warnings clears them
+	job = job.Copy()
+	job.TaskGroups[0].Tasks[0].Resources.MemoryMaxMB = 0
+	req.Job = job
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	must.Eq(t, "", resp.Warnings)
+
+	summary, err = s1.fsm.State().JobSummaryByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.SliceEmpty(t, summary.Warnings)
+}
+
 func TestJobEndpoint_Register_ValidateMemoryMax_NodePool(t *testing.T) {
 	ci.Parallel(t)
 
@@ -2395,6 +2445,49 @@ func TestJobEndpoint_Register_ACL_Namespace(t *testing.T) {
 	assert.NotNil(out, "expected job")
 }
 
+// TestJobEndpoint_Register_ACL_IdentityClaims asserts jobs setting extra
+// identity claims can only be registered by tokens allowed to set them.
+func TestJobEndpoint_Register_ACL_IdentityClaims(t *testing.T) {
+	ci.Parallel(t)
+	s1, _, cleanupS1 := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	submitToken := mock.CreatePolicyAndToken(t, s1.State(), 1001, "submit",
+		mock.NamespacePolicy(structs.DefaultNamespace, "write", nil)).SecretID
+	claimsToken := mock.CreatePolicyAndToken(t, s1.State(), 1003, "claims",
+		`namespace "default" {
+			policy          = "write"
+			identity_claims = ["team"]
+		}`).SecretID
+
+	job := mock.Job()
+	job.TaskGroups[0].Tasks[0].Identities = []*structs.WorkloadIdentity{{
+		Name:        "consul",
+		Audience:    []string{"consul.io"},
+		ExtraClaims: map[string]string{"team": "owner"},
+	}}
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+			AuthToken: submitToken,
+		},
+	}
+
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	req.AuthToken = claimsToken
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp))
+	must.NonZero(t, resp.Index)
+}
+
 func TestJobRegister_ACL_RejectedBySchedulerConfig(t *testing.T) {
 	ci.Parallel(t)
 	s1, root, cleanupS1 := TestACLServer(t, func(c *Config) {
@@ -3932,6 +4025,60 @@ func TestJobEndpoint_Deregister_NoShutdownDelay(t *testing.T) {
 
 }
 
+func TestJobEndpoint_Deregister_ServiceDrain(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	// Create the job and an allocation of it
+	job := mock.Job()
+	reg := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", reg, &regResp))
+
+	stored, err := state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	alloc := mock.Alloc()
+	alloc.Job = stored
+	alloc.JobID = job.ID
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, regResp.Index+1, []*structs.Allocation{alloc}))
+
+	// The service drain can't be combined with no shutdown delay
+	dereg := &structs.JobDeregisterRequest{
+		JobID:           job.ID,
+		NoShutdownDelay: true,
+		ServiceDrain:    30 * time.Second,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobDeregisterResponse
+	err = msgpackrpc.CallWithCodec(codec, "Job.Deregister", dereg, &resp)
+	must.ErrorContains(t, err, "service drain can't be combined with no shutdown delay")
+
+	// Deregister with a service drain
+	dereg.NoShutdownDelay = false
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Deregister", dereg, &resp))
+
+	out, err := state.AllocByID(nil, alloc.ID)
+	must.NoError(t, err)
+	must.Eq(t, 30*time.Second, out.DesiredTransition.ServiceDrainDuration())
+	must.False(t, out.DesiredTransition.ShouldIgnoreShutdownDelay())
+}
+
 func TestJobEndpoint_BatchDeregister(t *testing.T) {
 	ci.Parallel(t)
 	require := require.New(t)
@@ -4914,7 +5061,8 @@ func TestJobEndpoint_GetJobSummary(t *testing.T) {
 
 	// Lookup the job summary
 	get := &structs.JobSummaryRequest{
-		JobID: job.ID,
+		JobID:  job.ID,
+		Health: true,
 		QueryOptions: structs.QueryOptions{
 			Region:    "global",
 			Namespace: job.Namespace,
@@ -4935,10 +5083,17 @@ func TestJobEndpoint_GetJobSummary(t *testing.T) {
 			"web": {},
 		},
 		Children:    new(structs.JobChildrenSummary),
+		Warnings:    []*structs.JobWarning{},
 		CreateIndex: job.CreateIndex,
 		ModifyIndex: job.CreateIndex,
 	}
 
+	// The health depends on the progress of the scheduler, only check that
+	// it's computed when requested.
+	must.NotNil(t, resp2.JobSummary.Health)
+	must.Eq(t, 10, resp2.JobSummary.Health.DesiredAllocs)
+	resp2.JobSummary.Health = nil
+
 	if !reflect.DeepEqual(resp2.JobSummary, &expectedJobSummary) {
 		t.Fatalf("expected: %v, actual: %v", expectedJobSummary, resp2.JobSummary)
 	}
@@ -4997,6 +5152,7 @@ func TestJobEndpoint_Summary_ACL(t *testing.T) {
 			"web": {},
 		},
 		Children:    new(structs.JobChildrenSummary),
+		Warnings:    []*structs.JobWarning{},
 		CreateIndex: job.CreateIndex,
 		ModifyIndex: job.ModifyIndex,
 	}
@@ -5006,6 +5162,7 @@ func TestJobEndpoint_Summary_ACL(t *testing.T) {
 	var mgmtResp structs.JobSummaryResponse
 	err = msgpackrpc.CallWithCodec(codec, "Job.Summary", req, &mgmtResp)
 	require.Nil(err)
+	require.Nil(mgmtResp.JobSummary.Health)
 	require.Equal(expectedJobSummary, mgmtResp.JobSummary)
 
 	// Create the namespace policy and tokens
@@ -5028,6 +5185,7 @@ func TestJobEndpoint_Summary_ACL(t *testing.T) {
 	var authResp structs.JobSummaryResponse
 	err = msgpackrpc.CallWithCodec(codec, "Job.Summary", req, &authResp)
 	require.Nil(err)
+	require.Nil(authResp.JobSummary.Health)
 	require.Equal(expectedJobSummary, authResp.JobSummary)
 }
 
@@ -5598,6 +5756,152 @@ func TestJobEndpoint_Allocations(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_LocalVolumeClaims(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	job := mock.Job()
+	job.TaskGroups[0].Volumes = map[string]*structs.VolumeRequest{
+		"data": {Name: "data", Type: structs.VolumeTypeLocal, Source: "data"},
+	}
+	state := s1.fsm.State()
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+	alloc := mock.Alloc()
+	alloc.Job = job
+	alloc.JobID = job.ID
+	alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, 0)
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1001, []*structs.Allocation{alloc}))
+
+	// List the claims of the job
+	get := &structs.LocalVolumeClaimListRequest{
+		JobID: job.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var listResp structs.LocalVolumeClaimListResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.LocalVolumeClaims", get, &listResp))
+	must.Eq(t, 1001, listResp.Index)
+	must.Len(t, 1, listResp.Claims)
+	must.Eq(t, alloc.NodeID, listResp.Claims[0].NodeID)
+
+	// Deleting an unknown claim fails
+	del := &structs.LocalVolumeClaimDeleteRequest{
+		JobID:     job.ID,
+		AllocName: "unknown",
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var delResp structs.LocalVolumeClaimDeleteResponse
+	must.ErrorContains(t, msgpackrpc.CallWithCodec(codec, "Job.DeleteLocalVolumeClaims", del, &delResp), "not found")
+
+	// Deleting the claim creates an evaluation to place the allocation
+	del.AllocName = alloc.Name
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.DeleteLocalVolumeClaims", del, &delResp))
+	must.NotEq(t, "", delResp.EvalID)
+
+	claim, err := state.LocalVolumeClaim(nil, job.Namespace, job.ID, alloc.Name)
+	must.NoError(t, err)
+	must.Nil(t, claim)
+
+	eval, err := state.EvalByID(nil, delResp.EvalID)
+	must.NoError(t, err)
+	must.NotNil(t, eval)
+	must.Eq(t, delResp.EvalCreateIndex, eval.CreateIndex)
+	must.Eq(t, job.ID, eval.JobID)
+}
+
+func TestJobEndpoint_Suspend(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	job := mock.BatchJob()
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+	job, err := state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+
+	// Suspend the job
+	req := &structs.JobSuspendRequest{
+		JobID:   job.ID,
+		Suspend: true,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var resp structs.JobSuspendResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Suspend", req, &resp))
+	must.NotEq(t, "", resp.EvalID)
+
+	out, err := state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.True(t, out.Suspended)
+	must.True(t, out.Stopped())
+	must.Eq(t, job.Version, out.Version)
+	must.Eq(t, job.JobModifyIndex, out.JobModifyIndex)
+
+	eval, err := state.EvalByID(nil, resp.EvalID)
+	must.NoError(t, err)
+	must.NotNil(t, eval)
+	must.Eq(t, resp.EvalCreateIndex, eval.CreateIndex)
+	must.Eq(t, structs.EvalTriggerJobSuspend, eval.TriggeredBy)
+
+	// Suspending the job again fails
+	resp = structs.JobSuspendResponse{}
+	err = msgpackrpc.CallWithCodec(codec, "Job.Suspend", req, &resp)
+	must.ErrorContains(t, err, "is already suspended")
+
+	// Resume the job
+	req.Suspend = false
+	resp = structs.JobSuspendResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Suspend", req, &resp))
+
+	out, err = state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.False(t, out.Suspended)
+	must.Eq(t, job.Version, out.Version)
+
+	eval, err = state.EvalByID(nil, resp.EvalID)
+	must.NoError(t, err)
+	must.NotNil(t, eval)
+	must.Eq(t, structs.EvalTriggerJobResume, eval.TriggeredBy)
+
+	resp = structs.JobSuspendResponse{}
+	err = msgpackrpc.CallWithCodec(codec, "Job.Suspend", req, &resp)
+	must.ErrorContains(t, err, "isn't suspended")
+
+	// Only batch jobs can be suspended
+	service := mock.Job()
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1001, nil, service))
+	req.JobID = service.ID
+	req.Suspend = true
+	resp = structs.JobSuspendResponse{}
+	err = msgpackrpc.CallWithCodec(codec, "Job.Suspend", req, &resp)
+	must.ErrorContains(t, err, `only "batch" jobs can be suspended`)
+
+	req.JobID = "unknown"
+	resp = structs.JobSuspendResponse{}
+	err = msgpackrpc.CallWithCodec(codec, "Job.Suspend", req, &resp)
+	must.ErrorContains(t, err, "not found")
+}
+
 func TestJobEndpoint_Allocations_ACL(t *testing.T) {
 	ci.Parallel(t)
 	require := require.New(t)
@@ -6391,6 +6695,85 @@ func TestJobEndpoint_Plan_Scaling(t *testing.T) {
 	require.Contains(t, planResp.FailedTGAllocs, tg.Name)
 }
 
+// TestJobEndpoint_Plan_Lint asserts that the plan endpoint returns the
+// violations of the lint rules, and rejects the jobs violating deny rules.
+func TestJobEndpoint_Plan_Lint(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.JobLint = &config.JobLintConfig{
+			MissingHealthChecks: config.JobLintLevelWarn,
+			LatestImageTag:      config.JobLintLevelDeny,
+		}
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	job := mock.Job()
+	planReq := &structs.JobPlanRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	var planResp structs.JobPlanResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Plan", planReq, &planResp))
+	must.Len(t, 1, planResp.Lints)
+	must.Eq(t, config.JobLintMissingHealthChecks, planResp.Lints[0].Rule)
+	must.Eq(t, config.JobLintLevelWarn, planResp.Lints[0].Level)
+	must.StrContains(t, planResp.Warnings, `service "web-admin" has no health checks`)
+
+	job.TaskGroups[0].Tasks[0].Driver = "docker"
+	job.TaskGroups[0].Tasks[0].Config = map[string]interface{}{"image": "redis"}
+	err := msgpackrpc.CallWithCodec(codec, "Job.Plan", planReq, &structs.JobPlanResponse{})
+	must.ErrorContains(t, err, "lint latest_image_tag")
+}
+
+func TestJobEndpoint_Plan_UserDirectoryLookup(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.UserDirectoryLookupDrivers = []string{"exec"}
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	node := mock.Node()
+	must.NoError(t, s1.fsm.State().UpsertNode(structs.MsgTypeTestSetup, 1000, node))
+
+	job := mock.Job()
+	job.TaskGroups[0].Count = 1
+	job.TaskGroups[0].Tasks[0].User = "svc-foo"
+	planReq := &structs.JobPlanRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+
+	// The node doesn't resolve users with its directory service
+	var planResp structs.JobPlanResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Plan", planReq, &planResp))
+	must.MapContainsKey(t, planResp.FailedTGAllocs, "web")
+	must.Eq(t, map[string]int{"${attr.user.directory_lookup} = true": 1},
+		planResp.FailedTGAllocs["web"].ConstraintFiltered)
+
+	node = node.Copy()
+	node.Attributes["user.directory_lookup"] = "true"
+	must.NoError(t, s1.fsm.State().UpsertNode(structs.MsgTypeTestSetup, 1001, node))
+
+	planResp = structs.JobPlanResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Plan", planReq, &planResp))
+	must.MapEmpty(t, planResp.FailedTGAllocs)
+}
+
 func TestJobEndpoint_ImplicitConstraints_Vault(t *testing.T) {
 	ci.Parallel(t)
 
@@ -7279,6 +7662,166 @@ func TestJobEndpoint_Dispatch_JobChildrenSummary(t *testing.T) {
 	require.Equal(t, structs.JobStatusDead, dispatchedStatus())
 }
 
+// testPayloadProvider keeps the objects of the payload store in memory.
+type testPayloadProvider struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func (p *testPayloadProvider) Put(_ context.Context, key string, data []byte) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.objects[key] = data
+	return nil
+}
+
+func (p *testPayloadProvider) Get(_ context.Context, key string) ([]byte, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	data, ok := p.objects[key]
+	if !ok {
+		return nil, payloadstore.ErrNotFound
+	}
+	return data, nil
+}
+
+func (p *testPayloadProvider) DeletePrefix(_ context.Context, prefix string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key := range p.objects {
+		if strings.HasPrefix(key, prefix) {
+			delete(p.objects, key)
+		}
+	}
+	return nil
+}
+
+func (p *testPayloadProvider) len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.objects)
+}
+
+func TestJobEndpoint_Dispatch_PayloadStore(t *testing.T) {
+	ci.Parallel(t)
+
+	provider := &testPayloadProvider{objects: map[string][]byte{}}
+	providerName := "test-" + uuid.Short()
+	payloadstore.RegisterProvider(providerName, func(hclog.Logger, map[string]string) (payloadstore.Provider, error) {
+		return provider, nil
+	})
+
+	s1, cleanupS1 := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.PayloadStore = &config.PayloadStoreConfig{
+			Provider:  providerName,
+			Threshold: pointer.Of("1KiB"),
+		}
+	})
+	t.Cleanup(cleanupS1)
+
+	state := s1.fsm.State()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Register a parameterized job with a source above the threshold
+	job := mock.BatchJob()
+	job.ParameterizedJob = &structs.ParameterizedJobConfig{}
+	source := strings.Repeat("#", 2048)
+	regReq := &structs.JobRegisterRequest{
+		Job:        job,
+		Submission: &structs.JobSubmission{Source: source, Format: "hcl2"},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var regResp structs.JobRegisterResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Register", regReq, &regResp))
+
+	// Only the reference of the source is written to the state
+	stored, err := state.JobSubmission(nil, job.Namespace, job.ID, 0)
+	must.NoError(t, err)
+	must.Eq(t, "", stored.Source)
+	must.StrHasPrefix(t, "jobs/default/"+job.ID+"/source/", stored.SourceRef)
+
+	subReq := &structs.JobSubmissionRequest{
+		JobID:   job.ID,
+		Version: 0,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var subResp structs.JobSubmissionResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.GetJobSubmission", subReq, &subResp))
+	must.Eq(t, source, subResp.Submission.Source)
+	must.Eq(t, "", subResp.Submission.SourceRef)
+
+	// Dispatch the job with a payload above the dispatch size limit
+	payload := []byte(strings.Repeat("a", DispatchPayloadSizeLimit+1))
+	dispatchReq := &structs.JobDispatchRequest{
+		JobID:   job.ID,
+		Payload: payload,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: job.Namespace,
+		},
+	}
+	var dispatchResp structs.JobDispatchResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Dispatch", dispatchReq, &dispatchResp))
+
+	dispatched, err := state.JobByID(nil, job.Namespace, dispatchResp.DispatchedJobID)
+	must.NoError(t, err)
+	must.Nil(t, dispatched.Payload)
+	must.NotEq(t, "", dispatched.PayloadRef)
+
+	// Only the node of the allocation can retrieve the payload
+	node := mock.Node()
+	otherNode := mock.Node()
+	must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, 1000, node))
+	must.NoError(t, state.UpsertNode(structs.MsgTypeTestSetup, 1001, otherNode))
+
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	alloc.Job = dispatched
+	alloc.JobID = dispatched.ID
+	alloc.TaskGroup = dispatched.TaskGroups[0].Name
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1002, []*structs.Allocation{alloc}))
+
+	payloadReq := &structs.JobDispatchPayloadRequest{
+		AllocID: alloc.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: otherNode.SecretID,
+		},
+	}
+	var payloadResp structs.JobDispatchPayloadResponse
+	err = msgpackrpc.CallWithCodec(codec, "Job.DispatchPayload", payloadReq, &payloadResp)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	payloadReq.AuthToken = node.SecretID
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.DispatchPayload", payloadReq, &payloadResp))
+	decoded, err := snappy.Decode(nil, payloadResp.Payload)
+	must.NoError(t, err)
+	must.Eq(t, payload, decoded)
+
+	// Purging the jobs deletes their objects
+	for _, jobID := range []string{dispatched.ID, job.ID} {
+		deregReq := &structs.JobDeregisterRequest{
+			JobID: jobID,
+			Purge: true,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: job.Namespace,
+			},
+		}
+		var deregResp structs.JobDeregisterResponse
+		must.NoError(t, msgpackrpc.CallWithCodec(codec, "Job.Deregister", deregReq, &deregResp))
+	}
+	must.Zero(t, provider.len())
+}
+
 func TestJobEndpoint_Dispatch_ACL_RejectedBySchedulerConfig(t *testing.T) {
 	ci.Parallel(t)
 	s1, root, cleanupS1 := TestACLServer(t, nil)
@@ -7398,6 +7941,7 @@ func TestJobEndpoint_Scale(t *testing.T) {
 			},
 			"other": "value",
 		},
+		Annotation:     "traffic spike from the marketing campaign",
 		PolicyOverride: false,
 		WriteRequest: structs.WriteRequest{
 			Region:    "global",
@@ -7413,6 +7957,8 @@ func TestJobEndpoint_Scale(t *testing.T) {
 	events, _, _ := state.ScalingEventsByJob(nil, job.Namespace, job.ID)
 	require.Equal(1, len(events[groupName]))
 	require.Equal(int64(originalCount), events[groupName][0].PreviousCount)
+	require.Equal(scale.Annotation, events[groupName][0].Annotation)
+	require.NotEmpty(events[groupName][0].Actor)
 }
 
 func TestJobEndpoint_Scale_DeploymentBlocking(t *testing.T) {
@@ -7654,6 +8200,11 @@ func TestJobEndpoint_Scale_ACL(t *testing.T) {
 		require.NotNil(resp.EvalID)
 	}
 
+	// The scaling events record the accessor ID of the token
+	events, _, err := state.ScalingEventsByJob(nil, job.Namespace, job.ID)
+	require.NoError(err)
+	require.Equal("token:"+root.AccessorID, events[job.TaskGroups[0].Name][len(cases)-1].Actor)
+
 }
 
 func TestJobEndpoint_Scale_ACL_RejectedBySchedulerConfig(t *testing.T) {
@@ -7796,6 +8347,12 @@ func TestJobEndpoint_Scale_Invalid(t *testing.T) {
 	err = msgpackrpc.CallWithCodec(codec, "Job.Scale", scale, &resp)
 	require.Error(err)
 	require.Contains(err.Error(), "should not contain count if error is true")
+
+	scale.Error = false
+	scale.Annotation = strings.Repeat("a", structs.MaxScalingEventAnnotationLength+1)
+	err = msgpackrpc.CallWithCodec(codec, "Job.Scale", scale, &resp)
+	require.Error(err)
+	require.Contains(err.Error(), "scaling annotation must be at most")
 }
 
 func TestJobEndpoint_Scale_OutOfBounds(t *testing.T) {
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"slices"
+	"strings"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/ryanuber/go-glob"
+)
+
+// jobMetricsLabeler computes the labels attached to the metrics emitted per
+// job according to the job_metrics_labels server configuration. A nil
+// labeler attaches all the labels of every job.
+type jobMetricsLabeler struct {
+	config *config.JobMetricsLabelsConfig
+}
+
+func newJobMetricsLabeler(c *config.JobMetricsLabelsConfig) *jobMetricsLabeler {
+	if c == nil {
+		return nil
+	}
+	return &jobMetricsLabeler{config: c.Copy()}
+}
+
+// labels returns the labels of a metric of the job, followed by the extra
+// labels which are only kept when the job label is. The task group is
+// omitted when empty. It returns false if the metric should not be emitted.
+func (l *jobMetricsLabeler) labels(namespace, jobID, taskGroup string, extra ...metrics.Label) ([]metrics.Label, bool) {
+	jobValue, allowed := jobID, l.allowed(jobID)
+	if !allowed {
+		if l.config.OtherJobs == "" {
+			return nil, false
+		}
+		jobValue = l.config.OtherJobs
+	}
+
+	labels := make([]metrics.Label, 0, 3+len(extra))
+	if l.keep(config.JobMetricsLabelJob) {
+		labels = append(labels, l.label(config.JobMetricsLabelJob, jobValue))
+	}
+	if taskGroup != "" && l.keep(config.JobMetricsLabelTaskGroup) {
+		labels = append(labels, l.label(config.JobMetricsLabelTaskGroup, taskGroup))
+	}
+	if l.keep(config.JobMetricsLabelNamespace) {
+		labels = append(labels, l.label(config.JobMetricsLabelNamespace, namespace))
+	}
+	if allowed && l.keep(config.JobMetricsLabelJob) {
+		for _, e := range extra {
+			labels = append(labels, l.label(e.Name, e.Value))
+		}
+	}
+	return labels, true
+}
+
+// allowed returns whether the metrics of the job keep its ID as job label.
+func (l *jobMetricsLabeler) allowed(jobID string) bool {
+	if l == nil {
+		return true
+	}
+	match := func(pattern string) bool { return glob.Glob(pattern, jobID) }
+	if slices.ContainsFunc(l.config.DenyJobs, match) {
+		return false
+	}
+	return len(l.config.AllowJobs) == 0 || slices.ContainsFunc(l.config.AllowJobs, match)
+}
+
+func (l *jobMetricsLabeler) keep(name string) bool {
+	return l == nil || len(l.config.Labels) == 0 || slices.Contains(l.config.Labels, name)
+}
+
+func (l *jobMetricsLabeler) label(name, value string) metrics.Label {
+	if l != nil {
+		if renamed, ok := l.config.Rename[name]; ok {
+			name = renamed
+		}
+	}
+	return metrics.Label{Name: name, Value: value}
+}
+
+// jobGauges accumulates the gauges of many jobs before emitting them, so the
+// values of the jobs that end up with the same labels are summed instead of
+// overwriting each other.
+type jobGauges struct {
+	keys   []string
+	gauges map[string]*jobGauge
+}
+
+type jobGauge struct {
+	name   []string
+	labels []metrics.Label
+	value  float32
+}
+
+func newJobGauges() *jobGauges {
+	return &jobGauges{gauges: make(map[string]*jobGauge)}
+}
+
+func (g *jobGauges) add(name []string, value float32, labels []metrics.Label) {
+	var key strings.Builder
+	key.WriteString(strings.Join(name, "."))
+	for _, label := range labels {
+		key.WriteString("\x00" + label.Name + "=" + label.Value)
+	}
+
+	gauge, ok := g.gauges[key.String()]
+	if !ok {
+		gauge = &jobGauge{name: name, labels: labels}
+		g.gauges[key.String()] = gauge
+		g.keys = append(g.keys, key.String())
+	}
+	gauge.value += value
+}
+
+func (g *jobGauges) emit() {
+	for _, key := range g.keys {
+		gauge := g.gauges[key]
+		metrics.SetGaugeWithLabels(gauge.name, gauge.value, gauge.labels)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"testing"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobMetricsLabeler(t *testing.T) {
+	ci.Parallel(t)
+
+	extra := metrics.Label{Name: "parent_id", Value: "api"}
+
+	// A nil labeler attaches all the labels
+	var labeler *jobMetricsLabeler
+	must.Nil(t, newJobMetricsLabeler(nil))
+	labels, ok := labeler.labels("default", "api/dispatch-1", "web", extra)
+	must.True(t, ok)
+	must.Eq(t, []metrics.Label{
+		{Name: "job", Value: "api/dispatch-1"},
+		{Name: "task_group", Value: "web"},
+		{Name: "namespace", Value: "default"},
+		extra,
+	}, labels)
+
+	labeler = newJobMetricsLabeler(&config.JobMetricsLabelsConfig{
+		Labels:    []string{config.JobMetricsLabelJob, config.JobMetricsLabelNamespace},
+		AllowJobs: []string{"api*"},
+		DenyJobs:  []string{"*-canary"},
+		OtherJobs: "other",
+		Rename:    map[string]string{"job": "nomad_job"},
+	})
+
+	// Allowed jobs keep their ID and the extra labels
+	labels, ok = labeler.labels("default", "api/dispatch-1", "web", extra)
+	must.True(t, ok)
+	must.Eq(t, []metrics.Label{
+		{Name: "nomad_job", Value: "api/dispatch-1"},
+		{Name: "namespace", Value: "default"},
+		extra,
+	}, labels)
+
+	// Other jobs are relabeled, and denied jobs take precedence
+	for _, jobID := range []string{"batch", "api-canary"} {
+		labels, ok = labeler.labels("prod", jobID, "web", extra)
+		must.True(t, ok)
+		must.Eq(t, []metrics.Label{
+			{Name: "nomad_job", Value: "other"},
+			{Name: "namespace", Value: "prod"},
+		}, labels)
+	}
+
+	// Without a value to relabel to, the metrics of other jobs are dropped
+	labeler.config.OtherJobs = ""
+	_, ok = labeler.labels("prod", "batch", "web")
+	must.False(t, ok)
+}
+
+func TestJobGauges(t *testing.T) {
+	ci.Parallel(t)
+
+	gauges := newJobGauges()
+	other := []metrics.Label{{Name: "job", Value: "other"}}
+	api := []metrics.Label{{Name: "job", Value: "api"}}
+
+	gauges.add([]string{"nomad", "job_summary", "running"}, 2, other)
+	gauges.add([]string{"nomad", "job_summary", "running"}, 3, other)
+	gauges.add([]string{"nomad", "job_summary", "running"}, 1, api)
+	gauges.add([]string{"nomad", "job_summary", "failed"}, 1, api)
+
+	// Gauges with the same name and labels are summed
+	must.Len(t, 3, gauges.keys)
+	must.Eq(t, 5, gauges.gauges[gauges.keys[0]].value)
+	must.Eq(t, 1, gauges.gauges[gauges.keys[1]].value)
+	must.Eq(t, 1, gauges.gauges[gauges.keys[2]].value)
+}
@@ -51,12 +51,32 @@ func (k *Keyring) Rotate(args *structs.KeyringRotateRootKeyRequest, reply *struc
 		args.Algorithm = structs.EncryptionAlgorithmAES256GCM
 	}
 
+	if args.PrepublishDuration != 0 {
+		if args.PublishTime != 0 {
+			return fmt.Errorf("publish time and prepublish duration cannot be combined")
+		}
+		if args.PrepublishDuration < 0 {
+			return fmt.Errorf("prepublish duration must be positive")
+		}
+		args.PublishTime = time.Now().Add(args.PrepublishDuration).UnixNano()
+	}
+
+	if args.PublishTime != 0 {
+		if err := k.validatePrepublish(args); err != nil {
+			return err
+		}
+	}
+
 	rootKey, err := structs.NewRootKey(args.Algorithm)
 	if err != nil {
 		return err
 	}
 
-	rootKey.Meta.SetActive()
+	if args.PublishTime != 0 {
+		rootKey.Meta.MakePrepublished(args.PublishTime)
+	} else {
+		rootKey.Meta.SetActive()
+	}
 
 	// make sure it's been added to the local keystore before we write
 	// it to raft, so that followers don't try to Get a key that
@@ -79,6 +99,14 @@ func (k *Keyring) Rotate(args *structs.KeyringRotateRootKeyRequest, reply *struc
 	reply.Key = rootKey.Meta
 	reply.Index = index
 
+	// Rotate the key of the signer plugin along with the root key, unless the
+	// root key is only prepublished
+	if k.encrypter.signer != nil && args.PublishTime == 0 {
+		if err := k.encrypter.signer.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate signer plugin key: %w", err)
+		}
+	}
+
 	if args.Full {
 		// like most core jobs, we don't commit this to raft b/c it's not
 		// going to be periodically recreated and the ACL is from this leader
@@ -99,6 +127,40 @@ func (k *Keyring) Rotate(args *structs.KeyringRotateRootKeyRequest, reply *struc
 	return nil
 }
 
+// validatePrepublish validates a request to rotate the root key with a
+// prepublished key.
+func (k *Keyring) validatePrepublish(args *structs.KeyringRotateRootKeyRequest) error {
+	if args.Full {
+		return fmt.Errorf("full rotation cannot be combined with a prepublished key")
+	}
+	if args.PublishTime <= time.Now().UnixNano() {
+		return fmt.Errorf("publish time must be in the future")
+	}
+
+	// Only allow a single prepublished key at a time, otherwise the order in
+	// which keys become active would be ambiguous.
+	snap, err := k.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	iter, err := snap.RootKeyMetas(nil)
+	if err != nil {
+		return err
+	}
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			break
+		}
+		keyMeta := raw.(*structs.RootKeyMeta)
+		if keyMeta.Prepublished() {
+			return fmt.Errorf("key %s is already prepublished", keyMeta.KeyID)
+		}
+	}
+
+	return nil
+}
+
 func (k *Keyring) List(args *structs.KeyringListRootKeyMetaRequest, reply *structs.KeyringListRootKeyMetaResponse) error {
 
 	authErr := k.srv.Authenticate(k.ctx, args)
@@ -406,6 +468,16 @@ func (k *Keyring) ListPublic(args *structs.GenericRequest, reply *structs.Keyrin
 
 				pubKeys = append(pubKeys, pubKey)
 			}
+
+			// Publish the keys of the signer plugin along with the keys of
+			// the keyring, which may still have signed valid identities
+			if k.encrypter.signer != nil {
+				signerKeys, err := k.encrypter.signer.publicKeys()
+				if err != nil {
+					return err
+				}
+				pubKeys = append(pubKeys, signerKeys...)
+			}
 			reply.PublicKeys = pubKeys
 			return k.srv.replySetIndex(state.TableRootKeyMeta, &reply.QueryMeta)
 		},
@@ -299,6 +299,103 @@ func TestKeyringEndpoint_Rotate(t *testing.T) {
 	require.Len(t, gotKey.Key, 32)
 }
 
+// TestKeyringEndpoint_Rotate_Prepublish asserts that prepublished keys don't
+// replace the active key and are served by the JWKS endpoint.
+func TestKeyringEndpoint_Rotate_Prepublish(t *testing.T) {
+
+	ci.Parallel(t)
+	srv, rootToken, shutdown := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer shutdown()
+	testutil.WaitForKeyring(t, srv.RPC, "global")
+	codec := rpcClient(t, srv)
+
+	activeKey, err := srv.fsm.State().GetActiveRootKeyMeta(nil)
+	must.NoError(t, err)
+	must.NotNil(t, activeKey)
+
+	rotateReq := &structs.KeyringRotateRootKeyRequest{
+		PublishTime: time.Now().Add(-time.Hour).UnixNano(),
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: rootToken.SecretID,
+		},
+	}
+	var rotateResp structs.KeyringRotateRootKeyResponse
+	err = msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp)
+	must.ErrorContains(t, err, "publish time must be in the future")
+
+	rotateReq.PublishTime = time.Now().Add(time.Hour).UnixNano()
+	rotateReq.Full = true
+	err = msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp)
+	must.ErrorContains(t, err, "cannot be combined with a prepublished key")
+
+	rotateReq.Full = false
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp))
+	must.True(t, rotateResp.Key.Prepublished())
+	must.Eq(t, rotateReq.PublishTime, rotateResp.Key.PublishTime)
+
+	// The original key must still be the active key
+	gotActiveKey, err := srv.fsm.State().GetActiveRootKeyMeta(nil)
+	must.NoError(t, err)
+	must.Eq(t, activeKey.KeyID, gotActiveKey.KeyID)
+
+	// Only one key can be prepublished at a time
+	err = msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp)
+	must.ErrorContains(t, err, "is already prepublished")
+
+	// The prepublished key must be served along with the active key
+	publicReq := &structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var publicResp structs.KeyringListPublicResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Keyring.ListPublic", publicReq, &publicResp))
+
+	keyIDs := []string{}
+	for _, pubKey := range publicResp.PublicKeys {
+		keyIDs = append(keyIDs, pubKey.KeyID)
+	}
+	must.SliceContainsAll(t, []string{activeKey.KeyID, rotateResp.Key.KeyID}, keyIDs)
+}
+
+// TestKeyringEndpoint_Rotate_PrepublishDuration asserts that the server sets
+// the publish time of keys prepublished for a duration.
+func TestKeyringEndpoint_Rotate_PrepublishDuration(t *testing.T) {
+
+	ci.Parallel(t)
+	srv, rootToken, shutdown := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer shutdown()
+	testutil.WaitForKeyring(t, srv.RPC, "global")
+	codec := rpcClient(t, srv)
+
+	rotateReq := &structs.KeyringRotateRootKeyRequest{
+		PrepublishDuration: -time.Hour,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: rootToken.SecretID,
+		},
+	}
+	var rotateResp structs.KeyringRotateRootKeyResponse
+	err := msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp)
+	must.ErrorContains(t, err, "prepublish duration must be positive")
+
+	rotateReq.PrepublishDuration = time.Hour
+	rotateReq.PublishTime = time.Now().Add(time.Hour).UnixNano()
+	err = msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp)
+	must.ErrorContains(t, err, "cannot be combined")
+
+	rotateReq.PublishTime = 0
+	before := time.Now().Add(time.Hour).UnixNano()
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Keyring.Rotate", rotateReq, &rotateResp))
+	after := time.Now().Add(time.Hour).UnixNano()
+
+	must.True(t, rotateResp.Key.Prepublished())
+	must.Between(t, before, rotateResp.Key.PublishTime, after)
+}
+
 // TestKeyringEndpoint_ListPublic asserts the Keyring.ListPublic RPC returns
 // all keys which may be in use for active crytpographic material (variables,
 // valid JWTs).
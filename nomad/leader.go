@@ -397,6 +397,9 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 	// Enable the volume watcher, since we are now the leader
 	s.volumeWatcher.SetEnabled(true, s.State(), s.getLeaderAcl())
 
+	// Enable the service syncer, since we are now the leader
+	s.serviceSyncer.SetEnabled(true, s.State())
+
 	// Restore the eval broker state and blocked eval state. If these are
 	// currently paused, we do not need to do this.
 	if restoreEvals {
@@ -448,6 +451,17 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 	// Periodically publish job status metrics
 	go s.publishJobStatusMetrics(stopCh)
 
+	// Scale the jobs with an active schedule according to their windows
+	go s.watchJobActiveSchedules(stopCh)
+
+	// Update the fair share the evaluations of batch jobs are dequeued by
+	go s.watchBatchFairShare(stopCh)
+
+	// Periodically publish ACL token expiration metrics
+	if s.config.ACLEnabled {
+		go s.publishACLTokenMetrics(stopCh)
+	}
+
 	// Populate the variable lock TTL timers, so we can start tracking renewals
 	// and expirations.
 	if err := s.restoreLockTTLTimers(); err != nil {
@@ -1349,6 +1363,7 @@ func (s *Server) publishJobSummaryMetrics(stopCh chan struct{}) {
 				continue
 			}
 
+			gauges := newJobGauges()
 			for {
 				raw := iter.Next()
 				if raw == nil {
@@ -1365,68 +1380,91 @@ func (s *Server) publishJobSummaryMetrics(stopCh chan struct{}) {
 						continue
 					}
 				}
-				s.iterateJobSummaryMetrics(summary)
+				s.iterateJobSummaryMetrics(summary, gauges)
+
+				deployment, err := state.LatestDeploymentByJobID(ws, summary.Namespace, summary.JobID)
+				if err != nil {
+					s.logger.Error("error getting deployment for summary", "error", err)
+					continue
+				}
+				s.iterateJobDeploymentMetrics(deployment, gauges)
 			}
+			gauges.emit()
 		}
 	}
 }
 
-func (s *Server) iterateJobSummaryMetrics(summary *structs.JobSummary) {
+func (s *Server) iterateJobSummaryMetrics(summary *structs.JobSummary, gauges *jobGauges) {
+	var extra []metrics.Label
+	if strings.Contains(summary.JobID, "/dispatch-") {
+		jobInfo := strings.Split(summary.JobID, "/dispatch-")
+		extra = append(extra, metrics.Label{
+			Name:  "parent_id",
+			Value: jobInfo[0],
+		}, metrics.Label{
+			Name:  "dispatch_id",
+			Value: jobInfo[1],
+		})
+	}
+
+	if strings.Contains(summary.JobID, "/periodic-") {
+		jobInfo := strings.Split(summary.JobID, "/periodic-")
+		extra = append(extra, metrics.Label{
+			Name:  "parent_id",
+			Value: jobInfo[0],
+		}, metrics.Label{
+			Name:  "periodic_id",
+			Value: jobInfo[1],
+		})
+	}
+
 	for name, tgSummary := range summary.Summary {
-		labels := []metrics.Label{
-			{
-				Name:  "job",
-				Value: summary.JobID,
-			},
-			{
-				Name:  "task_group",
-				Value: name,
-			},
-			{
-				Name:  "namespace",
-				Value: summary.Namespace,
-			},
-		}
-
-		if strings.Contains(summary.JobID, "/dispatch-") {
-			jobInfo := strings.Split(summary.JobID, "/dispatch-")
-			labels = append(labels, metrics.Label{
-				Name:  "parent_id",
-				Value: jobInfo[0],
-			}, metrics.Label{
-				Name:  "dispatch_id",
-				Value: jobInfo[1],
-			})
-		}
-
-		if strings.Contains(summary.JobID, "/periodic-") {
-			jobInfo := strings.Split(summary.JobID, "/periodic-")
-			labels = append(labels, metrics.Label{
-				Name:  "parent_id",
-				Value: jobInfo[0],
-			}, metrics.Label{
-				Name:  "periodic_id",
-				Value: jobInfo[1],
-			})
-		}
-
-		metrics.SetGaugeWithLabels([]string{"nomad", "job_summary", "queued"},
+		labels, ok := s.jobMetricsLabeler.labels(summary.Namespace, summary.JobID, name, extra...)
+		if !ok {
+			return
+		}
+
+		gauges.add([]string{"nomad", "job_summary", "queued"},
 			float32(tgSummary.Queued), labels)
-		metrics.SetGaugeWithLabels([]string{"nomad", "job_summary", "complete"},
+		gauges.add([]string{"nomad", "job_summary", "complete"},
 			float32(tgSummary.Complete), labels)
-		metrics.SetGaugeWithLabels([]string{"nomad", "job_summary", "failed"},
+		gauges.add([]string{"nomad", "job_summary", "failed"},
 			float32(tgSummary.Failed), labels)
-		metrics.SetGaugeWithLabels([]string{"nomad", "job_summary", "running"},
+		gauges.add([]string{"nomad", "job_summary", "running"},
 			float32(tgSummary.Running), labels)
-		metrics.SetGaugeWithLabels([]string{"nomad", "job_summary", "starting"},
+		gauges.add([]string{"nomad", "job_summary", "starting"},
 			float32(tgSummary.Starting), labels)
-		metrics.SetGaugeWithLabels([]string{"nomad", "job_summary", "lost"},
+		gauges.add([]string{"nomad", "job_summary", "lost"},
 			float32(tgSummary.Lost), labels)
-		metrics.SetGaugeWithLabels([]string{"nomad", "job_summary", "unknown"},
+		gauges.add([]string{"nomad", "job_summary", "unknown"},
 			float32(tgSummary.Unknown), labels)
 	}
 }
 
+// iterateJobDeploymentMetrics publishes the progress of the task groups of
+// the deployment if it's still active.
+func (s *Server) iterateJobDeploymentMetrics(deployment *structs.Deployment, gauges *jobGauges) {
+	if deployment == nil || !deployment.Active() {
+		return
+	}
+
+	for name, dstate := range deployment.TaskGroups {
+		labels, ok := s.jobMetricsLabeler.labels(deployment.Namespace, deployment.JobID, name)
+		if !ok {
+			return
+		}
+
+		gauges.add([]string{"nomad", "job_deployment", "desired"},
+			float32(dstate.DesiredTotal), labels)
+		gauges.add([]string{"nomad", "job_deployment", "placed"},
+			float32(dstate.PlacedAllocs), labels)
+		gauges.add([]string{"nomad", "job_deployment", "healthy"},
+			float32(dstate.HealthyAllocs), labels)
+		gauges.add([]string{"nomad", "job_deployment", "unhealthy"},
+			float32(dstate.UnhealthyAllocs), labels)
+	}
+}
+
 // publishJobStatusMetrics publishes the job statuses as metrics
 func (s *Server) publishJobStatusMetrics(stopCh chan struct{}) {
 	timer := time.NewTimer(0)
@@ -1483,6 +1521,72 @@ func (s *Server) iterateJobStatusMetrics(jobs *memdb.ResultIterator) {
 	metrics.SetGauge([]string{"nomad", "job_status", "dead"}, float32(dead))
 }
 
+// aclTokenExpiringMetricWindow is the duration within which ACL tokens are
+// reported as expiring by the ACL token metrics.
+const aclTokenExpiringMetricWindow = 24 * time.Hour
+
+// publishACLTokenMetrics publishes the number of expired and expiring ACL
+// tokens as metrics
+func (s *Server) publishACLTokenMetrics(stopCh chan struct{}) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-timer.C:
+			timer.Reset(s.config.StatsCollectionInterval)
+			state, err := s.State().Snapshot()
+			if err != nil {
+				s.logger.Error("failed to get state", "error", err)
+				continue
+			}
+
+			for _, global := range []bool{true, false} {
+				iter, err := state.ACLTokensByExpired(global)
+				if err != nil {
+					s.logger.Error("failed to get ACL tokens", "error", err)
+					continue
+				}
+
+				expired, expiring := countExpiringACLTokens(iter, time.Now().UTC())
+
+				labels := []metrics.Label{{Name: "locality", Value: "local"}}
+				if global {
+					labels[0].Value = "global"
+				}
+				metrics.SetGaugeWithLabels([]string{"nomad", "acl", "tokens", "expired"},
+					float32(expired), labels)
+				metrics.SetGaugeWithLabels([]string{"nomad", "acl", "tokens", "expiring"},
+					float32(expiring), labels)
+			}
+		}
+	}
+}
+
+// countExpiringACLTokens returns the number of tokens of an iterator over the
+// ACL token expiration index which are expired at time now, and the number of
+// tokens expiring within aclTokenExpiringMetricWindow.
+func countExpiringACLTokens(iter memdb.ResultIterator, now time.Time) (expired, expiring int) {
+	window := now.Add(aclTokenExpiringMetricWindow)
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		token := raw.(*structs.ACLToken)
+
+		// The index is ordered by expiration time, so there are no more
+		// tokens to count once one expires after the window.
+		switch {
+		case token.IsExpired(now):
+			expired++
+		case token.IsExpired(window):
+			expiring++
+		default:
+			return
+		}
+	}
+	return
+}
+
 // revokeLeadership is invoked once we step down as leader.
 // This is used to cleanup any state that may be specific to a leader.
 func (s *Server) revokeLeadership() error {
@@ -1522,6 +1626,9 @@ func (s *Server) revokeLeadership() error {
 	// Disable the volume watcher
 	s.volumeWatcher.SetEnabled(false, nil, "")
 
+	// Disable the service syncer
+	s.serviceSyncer.SetEnabled(false, nil)
+
 	// Disable any enterprise systems required.
 	if err := s.revokeEnterpriseLeadership(); err != nil {
 		return err
@@ -1538,6 +1645,9 @@ func (s *Server) revokeLeadership() error {
 		return err
 	}
 
+	// Clear the utilization rollups since the new leader tracks them.
+	s.nodeUtilization.clear()
+
 	// Unpause our worker if we paused previously
 	s.handlePausableWorkers(false)
 
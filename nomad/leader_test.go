@@ -21,6 +21,7 @@ import (
 
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -1841,6 +1842,31 @@ func waitForStableLeadership(t *testing.T, servers []*Server) *Server {
 	return leader
 }
 
+func TestLeader_countExpiringACLTokens(t *testing.T) {
+	ci.Parallel(t)
+
+	store := state.TestStateStore(t)
+	now := time.Now().UTC()
+
+	var tokens []*structs.ACLToken
+	for _, expiresIn := range []time.Duration{
+		-2 * time.Hour, -time.Minute, time.Hour, 23 * time.Hour, 48 * time.Hour,
+	} {
+		token := mock.ACLToken()
+		token.ExpirationTime = pointer.Of(now.Add(expiresIn))
+		tokens = append(tokens, token)
+	}
+	tokens = append(tokens, mock.ACLToken())
+	must.NoError(t, store.UpsertACLTokens(structs.MsgTypeTestSetup, 10, tokens))
+
+	iter, err := store.ACLTokensByExpired(false)
+	must.NoError(t, err)
+
+	expired, expiring := countExpiringACLTokens(iter, now)
+	must.Eq(t, 2, expired)
+	must.Eq(t, 2, expiring)
+}
+
 func TestServer_getLatestIndex(t *testing.T) {
 	ci.Parallel(t)
 
@@ -62,6 +62,13 @@ func (s *Server) CreateVariableLockTTLTimer(variable structs.VariableEncrypted)
 	}
 
 	s.lockTTLTimer.Create(lockID, lockTTL, func() {
+		// Locks tied to a session are held as long as the session is alive,
+		// so the timer is reset instead of releasing the lock.
+		if s.variableLockSessionAlive(variable.Lock) {
+			s.lockTTLTimer.Create(lockID, lockTTL, nil)
+			return
+		}
+
 		s.logger.Debug("locks: lock TTL expired, starting delay",
 			"namespace", variable.Namespace, "path", variable.Path, "ttl", variable.Lock.TTL)
 		s.lockTTLTimer.StopAndRemove(lockID)
@@ -72,6 +79,36 @@ func (s *Server) CreateVariableLockTTLTimer(variable structs.VariableEncrypted)
 	})
 }
 
+// variableLockSessionAlive returns true if the lock is tied to a session that
+// is still alive: an allocation which isn't terminal, or an ACL token which
+// exists and isn't expired.
+func (s *Server) variableLockSessionAlive(lock *structs.VariableLock) bool {
+	switch {
+	case lock == nil:
+		return false
+
+	case lock.AllocID != "":
+		alloc, err := s.fsm.State().AllocByID(nil, lock.AllocID)
+		if err != nil {
+			s.logger.Error("locks: failed to look up lock session allocation",
+				"alloc_id", lock.AllocID, "error", err)
+			return false
+		}
+		return alloc != nil && !alloc.TerminalStatus()
+
+	case lock.AccessorID != "":
+		token, err := s.fsm.State().ACLTokenByAccessorID(nil, lock.AccessorID)
+		if err != nil {
+			s.logger.Error("locks: failed to look up lock session token",
+				"accessor_id", lock.AccessorID, "error", err)
+			return false
+		}
+		return token != nil && !token.IsExpired(time.Now().UTC())
+	}
+
+	return false
+}
+
 // invalidateVariableLock exponentially tries to update Nomad's state to remove
 // the lock ID from the variable. This can be used when a variable lock's TTL
 // has expired.
@@ -9,11 +9,13 @@ import (
 	"time"
 
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
 )
 
 func TestServer_restoreLockTTLTimers(t *testing.T) {
@@ -200,3 +202,85 @@ func TestServer_createAndRenewVariableLockTimer(t *testing.T) {
 	must.NoError(t, err)
 	must.Nil(t, varGetResp.Lock)
 }
+
+func TestServer_createVariableLockTimer_Session(t *testing.T) {
+	ci.Parallel(t)
+
+	testServer, testServerCleanup := TestServer(t, nil)
+	defer testServerCleanup()
+	testutil.WaitForKeyring(t, testServer.RPC, "global")
+
+	alloc := mock.Alloc()
+	must.NoError(t, testServer.fsm.State().UpsertAllocs(
+		structs.MsgTypeTestSetup, 5, []*structs.Allocation{alloc}))
+
+	// Generate a variable with a lock tied to the session of the allocation
+	// and upsert this into our state.
+	mockVar1 := mock.VariableEncrypted()
+	mockVar1.Lock = &structs.VariableLock{
+		ID:        uuid.Generate(),
+		TTL:       10 * time.Millisecond,
+		LockDelay: 10 * time.Millisecond,
+		Session:   structs.VariableLockSessionAlloc,
+		AllocID:   alloc.ID,
+	}
+
+	upsertResp1 := testServer.fsm.State().VarSet(10, &structs.VarApplyStateRequest{Var: mockVar1, Op: structs.VarOpLockAcquire})
+	must.NoError(t, upsertResp1.Error)
+
+	testServer.CreateVariableLockTTLTimer(*mockVar1)
+
+	// The lock is held past its TTL and delay while the allocation runs.
+	time.Sleep(50 * time.Millisecond)
+	must.NotNil(t, testServer.lockTTLTimer.Get(mockVar1.LockID()))
+	varGetResp, err := testServer.fsm.State().GetVariable(nil, mockVar1.Namespace, mockVar1.Path)
+	must.NoError(t, err)
+	must.Eq(t, mockVar1.LockID(), varGetResp.LockID())
+
+	// Stopping the allocation ends the session, so the lock is released
+	// after its TTL and delay.
+	alloc = alloc.Copy()
+	alloc.DesiredStatus = structs.AllocDesiredStatusStop
+	alloc.ClientStatus = structs.AllocClientStatusComplete
+	must.NoError(t, testServer.fsm.State().UpsertAllocs(
+		structs.MsgTypeTestSetup, 20, []*structs.Allocation{alloc}))
+
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			varGetResp, err := testServer.fsm.State().GetVariable(nil, mockVar1.Namespace, mockVar1.Path)
+			return err == nil && varGetResp.Lock == nil
+		}),
+		wait.Timeout(time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+	must.Nil(t, testServer.lockTTLTimer.Get(mockVar1.LockID()))
+}
+
+func TestServer_variableLockSessionAlive(t *testing.T) {
+	ci.Parallel(t)
+
+	testServer, testServerCleanup := TestServer(t, nil)
+	defer testServerCleanup()
+	testutil.WaitForLeader(t, testServer.RPC)
+
+	alloc := mock.Alloc()
+	stoppedAlloc := mock.Alloc()
+	stoppedAlloc.ClientStatus = structs.AllocClientStatusFailed
+	must.NoError(t, testServer.fsm.State().UpsertAllocs(
+		structs.MsgTypeTestSetup, 10, []*structs.Allocation{alloc, stoppedAlloc}))
+
+	token := mock.ACLToken()
+	expiredToken := mock.ACLToken()
+	expiredToken.ExpirationTime = pointer.Of(time.Now().Add(-time.Minute))
+	must.NoError(t, testServer.fsm.State().UpsertACLTokens(
+		structs.MsgTypeTestSetup, 20, []*structs.ACLToken{token, expiredToken}))
+
+	must.False(t, testServer.variableLockSessionAlive(nil))
+	must.False(t, testServer.variableLockSessionAlive(&structs.VariableLock{}))
+	must.True(t, testServer.variableLockSessionAlive(&structs.VariableLock{AllocID: alloc.ID}))
+	must.False(t, testServer.variableLockSessionAlive(&structs.VariableLock{AllocID: stoppedAlloc.ID}))
+	must.False(t, testServer.variableLockSessionAlive(&structs.VariableLock{AllocID: uuid.Generate()}))
+	must.True(t, testServer.variableLockSessionAlive(&structs.VariableLock{AccessorID: token.AccessorID}))
+	must.False(t, testServer.variableLockSessionAlive(&structs.VariableLock{AccessorID: expiredToken.AccessorID}))
+	must.False(t, testServer.variableLockSessionAlive(&structs.VariableLock{AccessorID: uuid.Generate()}))
+}
@@ -467,6 +467,7 @@ func (n *Node) deregister(args *structs.NodeBatchDeregisterRequest,
 
 		// Clear the heartbeat timer if any
 		n.srv.clearHeartbeatTimer(nodeID)
+		n.srv.nodeUtilization.remove(nodeID)
 
 		// Create the evaluations for this node
 		evalIDs, evalIndex, err := n.createNodeEvals(node, index)
@@ -637,12 +638,32 @@ func (n *Node) UpdateStatus(args *structs.NodeUpdateStatusRequest, reply *struct
 		}
 	}
 
+	// Only store the utilization reported by the client when load-aware
+	// scoring uses it or the client reports its resource usage, and when it
+	// changed enough to avoid a Raft write on every heartbeat.
+	if args.Utilization != nil {
+		now := time.Now()
+		n.srv.nodeUtilization.add(args.NodeID, args.Utilization, now)
+
+		_, schedConfig, err := snap.SchedulerConfig()
+		if err != nil {
+			return err
+		}
+		loadAware := schedConfig != nil && schedConfig.LoadAwareScoringEnabled
+		if (loadAware || args.Utilization.HasResourceUsage()) &&
+			node.Utilization.NeedsUpdate(args.Utilization, now) {
+			args.Utilization.UpdatedAt = now.UnixNano()
+		} else {
+			args.Utilization = nil
+		}
+	}
+
 	// Commit this update via Raft
 	var index uint64
-	if node.Status != args.Status || args.NodeEvent != nil {
+	if node.Status != args.Status || args.NodeEvent != nil || args.Utilization != nil {
 		// Attach an event if we are updating the node status to ready when it
 		// is down via a heartbeat
-		if node.Status == structs.NodeStatusDown && args.NodeEvent == nil {
+		if node.Status == structs.NodeStatusDown && node.Status != args.Status && args.NodeEvent == nil {
 			args.NodeEvent = structs.NewNodeEvent().
 				SetSubsystem(structs.NodeEventSubsystemCluster).
 				SetMessage(NodeHeartbeatEventReregistered)
@@ -728,6 +749,7 @@ func (n *Node) UpdateStatus(args *structs.NodeUpdateStatusRequest, reply *struct
 
 	// Set the reply index and leader
 	reply.Index = index
+	reply.ServerTime = time.Now().UnixNano()
 	n.srv.peerLock.RLock()
 	defer n.srv.peerLock.RUnlock()
 	if err := n.constructNodeServerInfoResponse(node.GetID(), snap, reply); err != nil {
@@ -738,6 +760,64 @@ func (n *Node) UpdateStatus(args *structs.NodeUpdateStatusRequest, reply *struct
 	return nil
 }
 
+// Heartbeat is used by ready nodes to renew their heartbeat TTL. Unlike
+// UpdateStatus it doesn't check the node status transitions or return the
+// server list, so nodes only need to send a full status update periodically or
+// when the server asks for it.
+func (n *Node) Heartbeat(args *structs.NodeHeartbeatRequest, reply *structs.NodeHeartbeatResponse) error {
+	authErr := n.srv.Authenticate(n.ctx, args)
+
+	isForwarded := args.IsForwarded()
+	if done, err := n.srv.forward("Node.Heartbeat", args, args, reply); done {
+		// Cache the connection to the node, as in UpdateStatus.
+		if err == nil && n.ctx != nil && n.ctx.NodeID == "" && !isForwarded {
+			n.ctx.NodeID = args.NodeID
+			n.srv.addNodeConn(n.ctx)
+		}
+
+		return err
+	}
+	n.srv.MeasureRPCRate("node", structs.RateMetricWrite, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+
+	defer metrics.MeasureSince([]string{"nomad", "client", "heartbeat"}, time.Now())
+
+	if args.NodeID == "" {
+		return fmt.Errorf("missing node ID for client heartbeat")
+	}
+
+	node, err := n.srv.State().NodeByID(nil, args.NodeID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("node not found")
+	}
+
+	if n.ctx != nil && n.ctx.NodeID == "" && !args.IsForwarded() {
+		n.ctx.NodeID = args.NodeID
+		n.srv.addNodeConn(n.ctx)
+	}
+
+	// Nodes that aren't ready, including the ones that missed their previous
+	// heartbeats, must go through UpdateStatus to become ready again.
+	if node.Status != structs.NodeStatusReady {
+		reply.StatusUpdateRequired = true
+		return nil
+	}
+
+	ttl, err := n.srv.resetHeartbeatTimer(args.NodeID)
+	if err != nil {
+		n.logger.Error("heartbeat reset failed", "error", err)
+		return err
+	}
+	reply.HeartbeatTTL = ttl
+	reply.ServerTime = time.Now().UnixNano()
+	return nil
+}
+
 // nodeStatusTransitionRequiresEval is a helper that takes a nodes new and old status and
 // returns whether it has transitioned to ready.
 func nodeStatusTransitionRequiresEval(newStatus, oldStatus string) bool {
@@ -1072,6 +1152,49 @@ func (n *Node) GetNode(args *structs.NodeSpecificRequest, reply *structs.SingleN
 	return n.srv.blockingRPC(&opts)
 }
 
+// Utilization is used to request the utilization rollups of a node, or the
+// latest rollup of every node if no node is specified.
+func (n *Node) Utilization(args *structs.NodeSpecificRequest, reply *structs.NodeUtilizationResponse) error {
+
+	authErr := n.srv.Authenticate(n.ctx, args)
+	// The rollups are only kept by the leader, so we fix the args since we are
+	// re-using a structure where we don't support all the options.
+	args.AllowStale = false
+	if done, err := n.srv.forward("Node.Utilization", args, args, reply); done {
+		return err
+	}
+	n.srv.MeasureRPCRate("node", structs.RateMetricRead, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "client", "utilization"}, time.Now())
+
+	// Check node read permissions
+	aclObj, err := n.srv.ResolveACL(args)
+	if err != nil {
+		return err
+	}
+	if !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	now := time.Now()
+	if args.NodeID != "" {
+		reply.Rollups = map[string][]*structs.NodeUtilizationRollup{
+			args.NodeID: n.srv.nodeUtilization.get(args.NodeID, now),
+		}
+	} else {
+		latest := n.srv.nodeUtilization.latest(now)
+		reply.Rollups = make(map[string][]*structs.NodeUtilizationRollup, len(latest))
+		for id, rollup := range latest {
+			reply.Rollups[id] = []*structs.NodeUtilizationRollup{rollup}
+		}
+	}
+
+	n.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
 // GetAllocs is used to request allocations for a specific node
 func (n *Node) GetAllocs(args *structs.NodeSpecificRequest,
 	reply *structs.NodeAllocsResponse) error {
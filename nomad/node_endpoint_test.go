@@ -756,6 +756,184 @@ func TestClientEndpoint_UpdateStatus(t *testing.T) {
 	})
 }
 
+func TestClientEndpoint_UpdateStatus_Utilization(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	node := mock.Node()
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeUpdateResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp))
+
+	heartbeat := func(cpu, memory float64) *structs.Node {
+		req := &structs.NodeUpdateStatusRequest{
+			NodeID: node.ID,
+			Status: structs.NodeStatusReady,
+			Utilization: &structs.NodeUtilization{
+				CPULoad:        cpu,
+				MemoryPressure: memory,
+			},
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		var resp structs.NodeUpdateResponse
+		must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.UpdateStatus", req, &resp))
+
+		out, err := state.NodeByID(nil, node.ID)
+		must.NoError(t, err)
+		return out
+	}
+
+	// The utilization isn't stored unless load-aware scoring is enabled.
+	out := heartbeat(0.5, 0.5)
+	must.Nil(t, out.Utilization)
+
+	_, schedConfig, err := state.SchedulerConfig()
+	must.NoError(t, err)
+	schedConfig = schedConfig.Copy()
+	schedConfig.LoadAwareScoringEnabled = true
+	must.NoError(t, state.SchedulerSetConfig(1000, schedConfig))
+
+	out = heartbeat(0.5, 0.5)
+	must.NotNil(t, out.Utilization)
+	must.Eq(t, 0.5, out.Utilization.CPULoad)
+	must.Eq(t, 0.5, out.Utilization.MemoryPressure)
+	must.Positive(t, out.Utilization.UpdatedAt)
+	modifyIndex := out.ModifyIndex
+
+	// Small changes don't update the node.
+	out = heartbeat(0.55, 0.45)
+	must.Eq(t, modifyIndex, out.ModifyIndex)
+	must.Eq(t, 0.5, out.Utilization.CPULoad)
+
+	// Large changes do.
+	out = heartbeat(0.9, 0.45)
+	must.Greater(t, modifyIndex, out.ModifyIndex)
+	must.Eq(t, 0.9, out.Utilization.CPULoad)
+	must.Eq(t, 0.45, out.Utilization.MemoryPressure)
+}
+
+func TestClientEndpoint_UpdateStatus_ResourceUsage(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	node := mock.Node()
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeUpdateResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp))
+
+	// The utilization is stored when the client reports its resource usage,
+	// even if load-aware scoring is disabled.
+	req := &structs.NodeUpdateStatusRequest{
+		NodeID: node.ID,
+		Status: structs.NodeStatusReady,
+		Utilization: &structs.NodeUtilization{
+			CPULoad:        0.5,
+			MemoryPressure: 0.25,
+			CPUUsedMHz:     1000,
+			MemoryUsedMB:   2048,
+			DiskUsedMB:     4096,
+		},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.UpdateStatus", req, &resp))
+
+	out, err := s1.fsm.State().NodeByID(nil, node.ID)
+	must.NoError(t, err)
+	must.NotNil(t, out.Utilization)
+	must.Eq(t, 1000, out.Utilization.CPUUsedMHz)
+	must.Eq(t, 2048, out.Utilization.MemoryUsedMB)
+	must.Eq(t, 4096, out.Utilization.DiskUsedMB)
+	must.Positive(t, out.Utilization.UpdatedAt)
+}
+
+func TestClientEndpoint_Utilization(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, root, cleanupS1 := TestACLServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	node := mock.Node()
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeUpdateResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp))
+
+	// The utilization is rolled up even if load-aware scoring is disabled.
+	for _, cpu := range []float64{0.2, 0.8} {
+		req := &structs.NodeUpdateStatusRequest{
+			NodeID: node.ID,
+			Status: structs.NodeStatusReady,
+			Utilization: &structs.NodeUtilization{
+				CPULoad:        cpu,
+				MemoryPressure: 0.5,
+			},
+			WriteRequest: structs.WriteRequest{Region: "global", AuthToken: node.SecretID},
+		}
+		must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.UpdateStatus", req, &resp))
+	}
+
+	// Reading the utilization requires node:read
+	req := &structs.NodeSpecificRequest{
+		NodeID:       node.ID,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var out structs.NodeUtilizationResponse
+	err := msgpackrpc.CallWithCodec(codec, "Node.Utilization", req, &out)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	req.AuthToken = root.SecretID
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Utilization", req, &out))
+	rollups := out.Rollups[node.ID]
+	must.SliceNotEmpty(t, rollups)
+
+	// The heartbeats may have been rolled up in different intervals.
+	var samples int
+	var cpuMax float64
+	for _, rollup := range rollups {
+		samples += rollup.Samples
+		cpuMax = max(cpuMax, rollup.CPULoadMax)
+		must.Eq(t, 0.5, rollup.MemoryPressureAvg)
+	}
+	must.Eq(t, 2, samples)
+	must.Eq(t, 0.8, cpuMax)
+
+	// Without a node, the latest rollup of every node is returned.
+	req.NodeID = ""
+	out = structs.NodeUtilizationResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Utilization", req, &out))
+	must.MapLen(t, 1, out.Rollups)
+	must.Eq(t, rollups[len(rollups)-1], out.Rollups[node.ID][0])
+
+	// Deregistering the node drops its rollups.
+	dereg := &structs.NodeBatchDeregisterRequest{
+		NodeIDs:      []string{node.ID},
+		WriteRequest: structs.WriteRequest{Region: "global", AuthToken: root.SecretID},
+	}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.BatchDeregister", dereg, &resp))
+	out = structs.NodeUtilizationResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Utilization", req, &out))
+	must.MapEmpty(t, out.Rollups)
+}
+
 func TestClientEndpoint_UpdateStatus_Vault(t *testing.T) {
 	ci.Parallel(t)
 
@@ -1345,6 +1523,66 @@ func TestClientEndpoint_UpdateStatus_HeartbeatOnly_Advertise(t *testing.T) {
 	require.Equal(resp.Servers[0].RPCAdvertiseAddr, advAddr)
 }
 
+func TestClientEndpoint_Heartbeat(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, cleanupS1 := TestServer(t, nil)
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Create a node pool that overrides the heartbeat configuration.
+	pool := mock.NodePool()
+	pool.HeartbeatConfig = &structs.NodePoolHeartbeatConfig{
+		MinHeartbeatTTL: time.Hour,
+	}
+	must.NoError(t, s1.State().UpsertNodePools(structs.MsgTypeTestSetup, 100, []*structs.NodePool{pool}))
+
+	// Heartbeats of unknown nodes are rejected.
+	node := mock.Node()
+	node.NodePool = pool.Name
+	hbReq := &structs.NodeHeartbeatRequest{
+		NodeID:       node.ID,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var hbResp structs.NodeHeartbeatResponse
+	err := msgpackrpc.CallWithCodec(codec, "Node.Heartbeat", hbReq, &hbResp)
+	must.ErrorContains(t, err, "node not found")
+
+	// Register the node as initializing.
+	node.Status = structs.NodeStatusInit
+	reg := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeUpdateResponse
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Register", reg, &resp))
+	must.GreaterEq(t, time.Hour, resp.HeartbeatTTL)
+
+	// Nodes that aren't ready must update their status.
+	hbResp = structs.NodeHeartbeatResponse{}
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Heartbeat", hbReq, &hbResp))
+	must.True(t, hbResp.StatusUpdateRequired)
+	must.Zero(t, hbResp.HeartbeatTTL)
+
+	update := &structs.NodeUpdateStatusRequest{
+		NodeID:       node.ID,
+		Status:       structs.NodeStatusReady,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	before := time.Now().UnixNano()
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.UpdateStatus", update, &resp))
+	must.Between(t, before, resp.ServerTime, time.Now().UnixNano())
+
+	// Ready nodes renew their TTL, using the node pool configuration.
+	hbResp = structs.NodeHeartbeatResponse{}
+	before = time.Now().UnixNano()
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Node.Heartbeat", hbReq, &hbResp))
+	must.False(t, hbResp.StatusUpdateRequired)
+	must.GreaterEq(t, time.Hour, hbResp.HeartbeatTTL)
+	must.Between(t, before, hbResp.ServerTime, time.Now().UnixNano())
+}
+
 func TestNode_UpdateStatus_ServiceRegistrations(t *testing.T) {
 	ci.Parallel(t)
 
@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// nodeUtilizationRollups keeps coarse rollups of the utilization the nodes
+// report in their heartbeats, so basic capacity views don't require an
+// external time series database. The rollups are only kept in the memory of
+// the leader, which handles the heartbeats, and are lost on leader election.
+type nodeUtilizationRollups struct {
+	// rollups are the rollups of each node by node ID, oldest first.
+	rollups map[string][]*structs.NodeUtilizationRollup
+
+	// lastPrune is the last time the rollups of all the nodes were pruned.
+	lastPrune time.Time
+
+	l sync.RWMutex
+}
+
+func newNodeUtilizationRollups() *nodeUtilizationRollups {
+	return &nodeUtilizationRollups{
+		rollups: make(map[string][]*structs.NodeUtilizationRollup),
+	}
+}
+
+// add adds the utilization reported by a node at the given time to its
+// rollups.
+func (r *nodeUtilizationRollups) add(nodeID string, u *structs.NodeUtilization, now time.Time) {
+	if u == nil {
+		return
+	}
+
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	start := now.Truncate(structs.NodeUtilizationRollupInterval).UnixNano()
+	rollups := r.rollups[nodeID]
+	if n := len(rollups); n == 0 || rollups[n-1].Start != start {
+		rollups = append(rollups, &structs.NodeUtilizationRollup{Start: start})
+	}
+	rollups[len(rollups)-1].Add(u)
+	r.rollups[nodeID] = pruneNodeUtilizationRollups(rollups, now)
+
+	// Prune the nodes that stopped reporting their utilization at most once
+	// per interval since it requires looking at every node.
+	if now.Sub(r.lastPrune) < structs.NodeUtilizationRollupInterval {
+		return
+	}
+	r.lastPrune = now
+	for id, rollups := range r.rollups {
+		if rollups = pruneNodeUtilizationRollups(rollups, now); len(rollups) == 0 {
+			delete(r.rollups, id)
+		} else {
+			r.rollups[id] = rollups
+		}
+	}
+}
+
+// get returns a copy of the rollups of the node, oldest first.
+func (r *nodeUtilizationRollups) get(nodeID string, now time.Time) []*structs.NodeUtilizationRollup {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	rollups := pruneNodeUtilizationRollups(r.rollups[nodeID], now)
+	out := make([]*structs.NodeUtilizationRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		out = append(out, rollup.Copy())
+	}
+	return out
+}
+
+// latest returns a copy of the latest rollup of each node, if it isn't older
+// than the retention.
+func (r *nodeUtilizationRollups) latest(now time.Time) map[string]*structs.NodeUtilizationRollup {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	out := make(map[string]*structs.NodeUtilizationRollup, len(r.rollups))
+	for id, rollups := range r.rollups {
+		rollups = pruneNodeUtilizationRollups(rollups, now)
+		if n := len(rollups); n > 0 {
+			out[id] = rollups[n-1].Copy()
+		}
+	}
+	return out
+}
+
+// remove removes the rollups of the node.
+func (r *nodeUtilizationRollups) remove(nodeID string) {
+	r.l.Lock()
+	defer r.l.Unlock()
+	delete(r.rollups, nodeID)
+}
+
+// clear removes the rollups of all the nodes.
+func (r *nodeUtilizationRollups) clear() {
+	r.l.Lock()
+	defer r.l.Unlock()
+	r.rollups = make(map[string][]*structs.NodeUtilizationRollup)
+}
+
+// pruneNodeUtilizationRollups returns the rollups that are within the
+// retention at the given time.
+func pruneNodeUtilizationRollups(rollups []*structs.NodeUtilizationRollup, now time.Time) []*structs.NodeUtilizationRollup {
+	cutoff := now.Add(-structs.NodeUtilizationRollupRetention).UnixNano()
+	for i, rollup := range rollups {
+		if rollup.Start+int64(structs.NodeUtilizationRollupInterval) > cutoff {
+			return rollups[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestNodeUtilizationRollups(t *testing.T) {
+	ci.Parallel(t)
+
+	r := newNodeUtilizationRollups()
+	start := time.Now().Truncate(structs.NodeUtilizationRollupInterval)
+
+	// Reports within an interval are rolled up together.
+	r.add("node1", &structs.NodeUtilization{CPULoad: 0.25, MemoryPressure: 0.5}, start)
+	r.add("node1", &structs.NodeUtilization{CPULoad: 0.75, MemoryPressure: 0.25}, start.Add(time.Minute))
+	r.add("node1", nil, start.Add(2*time.Minute))
+
+	rollups := r.get("node1", start.Add(2*time.Minute))
+	must.Len(t, 1, rollups)
+	must.Eq(t, start.UnixNano(), rollups[0].Start)
+	must.Eq(t, 2, rollups[0].Samples)
+	must.Eq(t, 0.5, rollups[0].CPULoadAvg)
+	must.Eq(t, 0.75, rollups[0].CPULoadMax)
+	must.Eq(t, 0.375, rollups[0].MemoryPressureAvg)
+	must.Eq(t, 0.5, rollups[0].MemoryPressureMax)
+
+	// Reports in the next interval start a new rollup.
+	next := start.Add(structs.NodeUtilizationRollupInterval)
+	r.add("node1", &structs.NodeUtilization{CPULoad: 0.9, MemoryPressure: 0.9}, next)
+	r.add("node2", &structs.NodeUtilization{CPULoad: 0.1, MemoryPressure: 0.1}, next)
+
+	rollups = r.get("node1", next)
+	must.Len(t, 2, rollups)
+	must.Eq(t, next.UnixNano(), rollups[1].Start)
+
+	latest := r.latest(next)
+	must.MapLen(t, 2, latest)
+	must.Eq(t, 0.9, latest["node1"].CPULoadAvg)
+	must.Eq(t, 0.1, latest["node2"].CPULoadAvg)
+
+	// The returned rollups are copies.
+	latest["node1"].Samples = 10
+	must.Eq(t, 1, r.latest(next)["node1"].Samples)
+
+	// Rollups older than the retention are pruned, along with the nodes that
+	// stopped reporting.
+	later := next.Add(structs.NodeUtilizationRollupRetention + structs.NodeUtilizationRollupInterval)
+	r.add("node2", &structs.NodeUtilization{CPULoad: 0.1, MemoryPressure: 0.1}, later)
+	must.SliceEmpty(t, r.get("node1", later))
+	must.Len(t, 1, r.get("node2", later))
+	must.MapNotContainsKey(t, r.rollups, "node1")
+
+	r.remove("node2")
+	must.MapEmpty(t, r.latest(later))
+}
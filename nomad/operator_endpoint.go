@@ -9,6 +9,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
@@ -229,6 +230,87 @@ REMOVE:
 	return nil
 }
 
+// RaftSetVoter is used to set whether a server is kept as a non-voting
+// server. Non-voting servers replicate the Raft log and serve stale reads, but
+// are left out of the quorum. Autopilot applies the change by promoting or
+// demoting the server.
+func (op *Operator) RaftSetVoter(args *structs.RaftSetVoterRequest, reply *struct{}) error {
+
+	authErr := op.srv.Authenticate(op.ctx, args)
+	if done, err := op.srv.forward("Operator.RaftSetVoter", args, args, reply); done {
+		return err
+	}
+	op.srv.MeasureRPCRate("operator", structs.RateMetricWrite, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+
+	// This action requires operator write access.
+	rule, err := op.srv.ResolveACL(args)
+	if err != nil {
+		return err
+	}
+	if rule != nil && !rule.AllowOperatorWrite() {
+		return structs.ErrPermissionDenied
+	}
+
+	// As with removing peers, return an error if the supplied id isn't among
+	// the peers since it's likely a mistake.
+	future := op.srv.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return err
+	}
+	found := false
+	for _, s := range future.Configuration().Servers {
+		if s.ID == args.ID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("id %q was not found in the Raft configuration", args.ID)
+	}
+
+	_, config, err := op.srv.fsm.State().AutopilotConfig()
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return fmt.Errorf("autopilot config not initialized yet")
+	}
+
+	id := string(args.ID)
+	idx := slices.Index(config.NonVoters, id)
+	if args.Voter == (idx < 0) {
+		return nil
+	}
+
+	config = config.Copy()
+	if args.Voter {
+		config.NonVoters = slices.Delete(config.NonVoters, idx, idx+1)
+	} else {
+		config.NonVoters = append(config.NonVoters, id)
+		slices.Sort(config.NonVoters)
+	}
+
+	req := &structs.AutopilotSetConfigRequest{
+		Config:       *config,
+		CAS:          true,
+		WriteRequest: args.WriteRequest,
+	}
+	resp, _, err := op.srv.raftApply(structs.AutopilotRequestType, req)
+	if err != nil {
+		op.logger.Error("failed applying AutoPilot configuration", "error", err)
+		return err
+	}
+	if ok, _ := resp.(bool); !ok {
+		return fmt.Errorf("autopilot configuration was modified concurrently, please retry")
+	}
+
+	op.logger.Info("updated Raft voter status", "peer_id", args.ID, "voter", args.Voter)
+	return nil
+}
+
 // TransferLeadershipToPeer is used to transfer leadership away from the
 // current leader to a specific target peer. This can help prevent leadership
 // flapping during a rolling upgrade by allowing the cluster operator to target
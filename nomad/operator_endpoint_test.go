@@ -375,6 +375,59 @@ func TestOperator_RaftRemovePeerByID_ACL(t *testing.T) {
 	}
 }
 
+func TestOperator_RaftSetVoter_ACL(t *testing.T) {
+	ci.Parallel(t)
+
+	s1, root, cleanupS1 := TestACLServer(t, func(c *Config) {
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer cleanupS1()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	state := s1.fsm.State()
+
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+
+	arg := structs.RaftSetVoterRequest{
+		ID:    raft.ServerID("e35bde83-4e9c-434f-a6ef-453f44ee21ea"),
+		Voter: false,
+	}
+	arg.Region = s1.config.Region
+	var reply struct{}
+
+	// Try with no token and expect permission denied
+	err := msgpackrpc.CallWithCodec(codec, "Operator.RaftSetVoter", &arg, &reply)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	// Try with an invalid token and expect permission denied
+	arg.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.RaftSetVoter", &arg, &reply)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	// Try with a management token and a peer that's not there
+	arg.AuthToken = root.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.RaftSetVoter", &arg, &reply)
+	must.ErrorContains(t, err, "not found in the Raft configuration")
+
+	ports := ci.PortAllocator.Grab(1)
+	future := s1.raft.AddNonvoter(arg.ID, raft.ServerAddress(fmt.Sprintf("127.0.0.1:%d", ports[0])), 0, 0)
+	must.NoError(t, future.Error())
+
+	// Setting the same status twice only updates the configuration once
+	for i := 0; i < 2; i++ {
+		must.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.RaftSetVoter", &arg, &reply))
+		_, config, err := state.AutopilotConfig()
+		must.NoError(t, err)
+		must.Eq(t, []string{string(arg.ID)}, config.NonVoters)
+	}
+
+	arg.Voter = true
+	must.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.RaftSetVoter", &arg, &reply))
+	_, config, err := state.AutopilotConfig()
+	must.NoError(t, err)
+	must.SliceEmpty(t, config.NonVoters)
+}
+
 type testcluster struct {
 	t       *testing.T
 	server  []*Server
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package payloadstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	log "github.com/hashicorp/go-hclog"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// gcsProvider keeps the objects in a Google Cloud Storage bucket, under an
+// optional prefix.
+type gcsProvider struct {
+	logger log.Logger
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// newGCSProvider returns a Google Cloud Storage provider. The credentials are
+// read from the application default credentials unless a credentials file is
+// configured.
+func newGCSProvider(logger log.Logger, config map[string]string) (Provider, error) {
+	p := &gcsProvider{
+		logger: logger,
+	}
+
+	var bucket string
+	var opts []option.ClientOption
+	for key, value := range config {
+		switch key {
+		case "bucket":
+			bucket = value
+		case "prefix":
+			p.prefix = value
+		case "credentials_file":
+			opts = append(opts, option.WithCredentialsFile(value))
+		case "endpoint":
+			opts = append(opts, option.WithEndpoint(value))
+		default:
+			return nil, fmt.Errorf("unknown gcs config key %q", key)
+		}
+	}
+	if bucket == "" {
+		return nil, errors.New("gcs bucket must not be empty")
+	}
+
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	p.bucket = client.Bucket(bucket)
+	return p, nil
+}
+
+// Put implements the Provider interface.
+func (p *gcsProvider) Put(ctx context.Context, key string, data []byte) error {
+	w := p.bucket.Object(p.prefix + key).NewWriter(ctx)
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Get implements the Provider interface.
+func (p *gcsProvider) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := p.bucket.Object(p.prefix + key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// DeletePrefix implements the Provider interface.
+func (p *gcsProvider) DeletePrefix(ctx context.Context, prefix string) error {
+	it := p.bucket.Objects(ctx, &storage.Query{Prefix: p.prefix + prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = p.bucket.Object(attrs.Name).Delete(ctx)
+		if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package payloadstore keeps the dispatch payloads and job sources above a
+// size threshold in an external object store, such as AWS S3 or Google Cloud
+// Storage, so only a reference to them is written to the Raft log.
+package payloadstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/hashicorp/go-hclog"
+)
+
+const (
+	// ProviderS3 keeps the objects in an AWS S3 bucket, or any object store
+	// compatible with the S3 API.
+	ProviderS3 = "s3"
+
+	// ProviderGCS keeps the objects in a Google Cloud Storage bucket.
+	ProviderGCS = "gcs"
+)
+
+// ErrNotFound is returned by providers when an object doesn't exist.
+var ErrNotFound = errors.New("object not found")
+
+// Provider is an object store the payloads are kept in.
+type Provider interface {
+	// Put writes an object, replacing any object with the same key.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get reads an object, or returns ErrNotFound if it doesn't exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// DeletePrefix deletes all the objects whose key starts with the prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// ProviderFactory returns a provider configured with the config block of the
+// payload store.
+type ProviderFactory func(logger log.Logger, config map[string]string) (Provider, error)
+
+var (
+	providersLock sync.RWMutex
+	providers     = map[string]ProviderFactory{
+		ProviderS3:  newS3Provider,
+		ProviderGCS: newGCSProvider,
+	}
+)
+
+// RegisterProvider makes a provider available to the payload store under the
+// given name, so object stores without a built-in provider can be supported by
+// custom builds of Nomad. It must be called before the server starts, and
+// replaces any provider registered under the same name.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	providers[name] = factory
+}
+
+// Providers returns the sorted names of the available providers.
+func Providers() []string {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newProvider returns the provider with the given name.
+func newProvider(logger log.Logger, name string, config map[string]string) (Provider, error) {
+	providersLock.RLock()
+	factory, ok := providers[name]
+	providersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q: must be one of %v", name, Providers())
+	}
+	return factory(logger, config)
+}
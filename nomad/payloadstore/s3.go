@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package payloadstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	log "github.com/hashicorp/go-hclog"
+)
+
+// s3DeleteBatchSize is the maximum number of objects deleted in a single
+// request, which is the limit of the S3 API.
+const s3DeleteBatchSize = 1000
+
+// s3API is the subset of the S3 API used by the provider.
+type s3API interface {
+	PutObjectWithContext(aws.Context, *s3.PutObjectInput, ...request.Option) (*s3.PutObjectOutput, error)
+	GetObjectWithContext(aws.Context, *s3.GetObjectInput, ...request.Option) (*s3.GetObjectOutput, error)
+	ListObjectsV2PagesWithContext(aws.Context, *s3.ListObjectsV2Input, func(*s3.ListObjectsV2Output, bool) bool, ...request.Option) error
+	DeleteObjectsWithContext(aws.Context, *s3.DeleteObjectsInput, ...request.Option) (*s3.DeleteObjectsOutput, error)
+}
+
+// s3Provider keeps the objects in an S3 bucket, under an optional prefix.
+type s3Provider struct {
+	logger log.Logger
+	api    s3API
+	bucket string
+	prefix string
+}
+
+// newS3Provider returns an S3 provider. The AWS credentials are read from the
+// default credentials chain.
+func newS3Provider(logger log.Logger, config map[string]string) (Provider, error) {
+	p := &s3Provider{
+		logger: logger,
+	}
+
+	awsConfig := aws.NewConfig()
+	for key, value := range config {
+		switch key {
+		case "bucket":
+			p.bucket = value
+		case "prefix":
+			p.prefix = value
+		case "region":
+			awsConfig = awsConfig.WithRegion(value)
+		case "endpoint":
+			awsConfig = awsConfig.WithEndpoint(value)
+		case "force_path_style":
+			pathStyle, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid s3 force_path_style %q: must be a boolean", value)
+			}
+			awsConfig = awsConfig.WithS3ForcePathStyle(pathStyle)
+		default:
+			return nil, fmt.Errorf("unknown s3 config key %q", key)
+		}
+	}
+	if p.bucket == "" {
+		return nil, errors.New("s3 bucket must not be empty")
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	p.api = s3.New(sess)
+	return p, nil
+}
+
+// Put implements the Provider interface.
+func (p *s3Provider) Put(ctx context.Context, key string, data []byte) error {
+	_, err := p.api.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(p.prefix + key),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+// Get implements the Provider interface.
+func (p *s3Provider) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := p.api.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(p.prefix + key),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+// DeletePrefix implements the Provider interface.
+func (p *s3Provider) DeletePrefix(ctx context.Context, prefix string) error {
+	var keys []*s3.ObjectIdentifier
+	err := p.api.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(p.bucket),
+		Prefix: aws.String(p.prefix + prefix),
+	}, func(out *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range out.Contents {
+			keys = append(keys, &s3.ObjectIdentifier{Key: obj.Key})
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), s3DeleteBatchSize)]
+		keys = keys[len(batch):]
+
+		out, err := p.api.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(p.bucket),
+			Delete: &s3.Delete{Objects: batch, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		if len(out.Errors) != 0 {
+			return fmt.Errorf("failed to delete %d objects: %s",
+				len(out.Errors), aws.StringValue(out.Errors[0].Message))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package payloadstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/shoenig/test/must"
+)
+
+// fakeS3 keeps the objects of a bucket in memory.
+type fakeS3 struct {
+	objects map[string][]byte
+	deletes int
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, in *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[aws.StringValue(in.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	data, ok := f.objects[aws.StringValue(in.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "not found", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(string(data)))}, nil
+}
+
+func (f *fakeS3) ListObjectsV2PagesWithContext(_ aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, aws.StringValue(in.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	out := &s3.ListObjectsV2Output{}
+	for _, key := range keys {
+		out.Contents = append(out.Contents, &s3.Object{Key: aws.String(key)})
+	}
+	fn(out, true)
+	return nil
+}
+
+func (f *fakeS3) DeleteObjectsWithContext(_ aws.Context, in *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	if len(in.Delete.Objects) > s3DeleteBatchSize {
+		return nil, fmt.Errorf("too many objects: %d", len(in.Delete.Objects))
+	}
+	f.deletes++
+	for _, obj := range in.Delete.Objects {
+		delete(f.objects, aws.StringValue(obj.Key))
+	}
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func TestS3Provider(t *testing.T) {
+	ci.Parallel(t)
+
+	ctx := context.Background()
+	api := &fakeS3{objects: map[string][]byte{}}
+	p := &s3Provider{
+		logger: testlog.HCLogger(t),
+		api:    api,
+		bucket: "nomad",
+		prefix: "payloads/",
+	}
+
+	must.NoError(t, p.Put(ctx, "jobs/default/example/payload", []byte("payload")))
+	must.MapContainsKey(t, api.objects, "payloads/jobs/default/example/payload")
+
+	data, err := p.Get(ctx, "jobs/default/example/payload")
+	must.NoError(t, err)
+	must.Eq(t, []byte("payload"), data)
+
+	_, err = p.Get(ctx, "jobs/default/other/payload")
+	must.ErrorIs(t, err, ErrNotFound)
+
+	for i := 0; i < s3DeleteBatchSize+1; i++ {
+		must.NoError(t, p.Put(ctx, fmt.Sprintf("jobs/default/example/source/%d", i), nil))
+	}
+	must.NoError(t, p.Put(ctx, "jobs/default/other/payload", nil))
+
+	must.NoError(t, p.DeletePrefix(ctx, "jobs/default/example/"))
+	must.Eq(t, 2, api.deletes)
+	must.MapLen(t, 1, api.objects)
+	must.MapContainsKey(t, api.objects, "payloads/jobs/default/other/payload")
+}
+
+func TestS3Provider_Config(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	_, err := newS3Provider(logger, map[string]string{"bucket": "nomad", "acl": "private"})
+	must.ErrorContains(t, err, `unknown s3 config key "acl"`)
+
+	_, err = newS3Provider(logger, map[string]string{"bucket": "nomad", "force_path_style": "maybe"})
+	must.ErrorContains(t, err, "force_path_style")
+
+	p, err := newS3Provider(logger, map[string]string{
+		"bucket":           "nomad",
+		"prefix":           "payloads/",
+		"region":           "us-east-1",
+		"force_path_style": "true",
+	})
+	must.NoError(t, err)
+	must.Eq(t, "nomad", p.(*s3Provider).bucket)
+	must.Eq(t, "payloads/", p.(*s3Provider).prefix)
+}
@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package payloadstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+// requestTimeout bounds the requests to the object store, so an unavailable
+// store doesn't block the RPCs reading or writing the payloads.
+const requestTimeout = time.Minute
+
+// Store keeps the dispatch payloads and job sources above a size threshold in
+// the object store of a provider. The objects of a job are kept under a prefix
+// of its own, so they can be deleted once the job is garbage collected.
+//
+// A nil Store is valid and keeps nothing, which is the case when no payload
+// store is configured.
+type Store struct {
+	logger    log.Logger
+	provider  Provider
+	threshold int
+}
+
+// New returns the payload store configured by the server, or nil if none is.
+func New(logger log.Logger, cfg *config.PayloadStoreConfig) (*Store, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	threshold, err := cfg.ThresholdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	logger = logger.Named("payload_store").With("provider", cfg.Provider)
+	provider, err := newProvider(logger, cfg.Provider, cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithProvider(logger, provider, threshold), nil
+}
+
+// NewWithProvider returns a payload store keeping the objects above the
+// threshold in the given provider.
+func NewWithProvider(logger log.Logger, provider Provider, threshold int) *Store {
+	return &Store{
+		logger:    logger,
+		provider:  provider,
+		threshold: threshold,
+	}
+}
+
+// Keeps returns whether data of the given size is kept in the payload store
+// instead of the Raft log.
+func (s *Store) Keeps(size int) bool {
+	return s != nil && size > s.threshold
+}
+
+// PutDispatchPayload stores the payload of a dispatched job and returns its
+// reference.
+func (s *Store) PutDispatchPayload(namespace, jobID string, payload []byte) (string, error) {
+	return s.put(path.Join(jobPrefix(namespace, jobID), "payload"), payload)
+}
+
+// PutSource stores the source of a job submission and returns its reference.
+// Each submission gets its own reference, since the version of the job isn't
+// known until it's registered.
+func (s *Store) PutSource(namespace, jobID, source string) (string, error) {
+	return s.put(path.Join(jobPrefix(namespace, jobID), "source", uuid.Generate()), []byte(source))
+}
+
+func (s *Store) put(ref string, data []byte) (string, error) {
+	if s == nil {
+		return "", errors.New("payload store is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := s.provider.Put(ctx, ref, data); err != nil {
+		return "", fmt.Errorf("failed to write %q to the payload store: %w", ref, err)
+	}
+	return ref, nil
+}
+
+// Get returns the data of a reference.
+func (s *Store) Get(ref string) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("payload store is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	data, err := s.provider.Get(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q from the payload store: %w", ref, err)
+	}
+	return data, nil
+}
+
+// DeleteJob deletes the payload and the sources of a job.
+func (s *Store) DeleteJob(namespace, jobID string) error {
+	if s == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	prefix := jobPrefix(namespace, jobID) + "/"
+	if err := s.provider.DeletePrefix(ctx, prefix); err != nil {
+		return fmt.Errorf("failed to delete %q from the payload store: %w", prefix, err)
+	}
+	return nil
+}
+
+// jobPrefix returns the prefix of the objects of a job. The namespace and the
+// ID are escaped, so the prefix of a parent job never matches the objects of
+// the jobs dispatched from it.
+func jobPrefix(namespace, jobID string) string {
+	return strings.Join([]string{"jobs", url.PathEscape(namespace), url.PathEscape(jobID)}, "/")
+}
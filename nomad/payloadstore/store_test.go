@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package payloadstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/shoenig/test/must"
+)
+
+// memProvider keeps the objects in memory.
+type memProvider struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemProvider() *memProvider {
+	return &memProvider{objects: map[string][]byte{}}
+}
+
+func (p *memProvider) Put(_ context.Context, key string, data []byte) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.objects[key] = data
+	return nil
+}
+
+func (p *memProvider) Get(_ context.Context, key string) ([]byte, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	data, ok := p.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (p *memProvider) DeletePrefix(_ context.Context, prefix string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key := range p.objects {
+		if strings.HasPrefix(key, prefix) {
+			delete(p.objects, key)
+		}
+	}
+	return nil
+}
+
+func TestStore_Nil(t *testing.T) {
+	ci.Parallel(t)
+
+	s, err := New(testlog.HCLogger(t), nil)
+	must.NoError(t, err)
+	must.Nil(t, s)
+
+	must.False(t, s.Keeps(1<<30))
+	must.NoError(t, s.DeleteJob("default", "example"))
+	_, err = s.PutDispatchPayload("default", "example", []byte("payload"))
+	must.Error(t, err)
+	_, err = s.Get("jobs/default/example/payload")
+	must.Error(t, err)
+}
+
+func TestStore_New(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	_, err := New(logger, &config.PayloadStoreConfig{Provider: "unknown"})
+	must.ErrorContains(t, err, `unknown provider "unknown"`)
+
+	_, err = New(logger, &config.PayloadStoreConfig{Provider: ProviderS3})
+	must.ErrorContains(t, err, "bucket must not be empty")
+
+	_, err = New(logger, &config.PayloadStoreConfig{
+		Provider:  ProviderS3,
+		Threshold: pointer.Of("lots"),
+	})
+	must.ErrorContains(t, err, "invalid threshold")
+
+	RegisterProvider("mem", func(log.Logger, map[string]string) (Provider, error) {
+		return newMemProvider(), nil
+	})
+	s, err := New(logger, &config.PayloadStoreConfig{
+		Provider:  "mem",
+		Threshold: pointer.Of("1KiB"),
+	})
+	must.NoError(t, err)
+	must.False(t, s.Keeps(1024))
+	must.True(t, s.Keeps(1025))
+	must.SliceContains(t, Providers(), "mem")
+}
+
+func TestStore_Objects(t *testing.T) {
+	ci.Parallel(t)
+
+	provider := newMemProvider()
+	s := NewWithProvider(testlog.HCLogger(t), provider, 0)
+
+	payloadRef, err := s.PutDispatchPayload("default", "example/dispatch-1", []byte("payload"))
+	must.NoError(t, err)
+	must.Eq(t, "jobs/default/example%2Fdispatch-1/payload", payloadRef)
+
+	sourceRef, err := s.PutSource("default", "example", "job {}")
+	must.NoError(t, err)
+	must.StrHasPrefix(t, "jobs/default/example/source/", sourceRef)
+
+	data, err := s.Get(payloadRef)
+	must.NoError(t, err)
+	must.Eq(t, []byte("payload"), data)
+
+	data, err = s.Get(sourceRef)
+	must.NoError(t, err)
+	must.Eq(t, []byte("job {}"), data)
+
+	// Deleting the parent job doesn't delete the objects of the dispatched
+	// job
+	must.NoError(t, s.DeleteJob("default", "example"))
+	_, err = s.Get(sourceRef)
+	must.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(payloadRef)
+	must.NoError(t, err)
+
+	must.NoError(t, s.DeleteJob("default", "example/dispatch-1"))
+	must.MapEmpty(t, provider.objects)
+}
@@ -278,7 +278,10 @@ func (p *planner) applyPlan(plan *structs.Plan, result *structs.PlanResult, snap
 		// to approximate the scheduling time.
 		updateAllocTimestamps(req.AllocsUpdated, unixNow)
 
-		err := signAllocIdentities(p.srv.encrypter, plan.Job, req.AllocsUpdated, now)
+		// The default identities are signed by the servers themselves, so
+		// their recorded signatures have no actor.
+		signer := p.srv.accessAuditor.signer(p.srv.encrypter, structs.AccessAuditActor{})
+		err := signAllocIdentities(signer, plan.Job, req.AllocsUpdated, now)
 		if err != nil {
 			return nil, err
 		}
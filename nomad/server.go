@@ -43,11 +43,15 @@ import (
 	"github.com/hashicorp/nomad/nomad/deploymentwatcher"
 	"github.com/hashicorp/nomad/nomad/drainer"
 	"github.com/hashicorp/nomad/nomad/lock"
+	"github.com/hashicorp/nomad/nomad/payloadstore"
 	"github.com/hashicorp/nomad/nomad/reporting"
+	"github.com/hashicorp/nomad/nomad/servicesync"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/nomad/volumewatcher"
+	"github.com/hashicorp/nomad/plugins/scoring"
+	"github.com/hashicorp/nomad/plugins/signer"
 	"github.com/hashicorp/nomad/scheduler"
 )
 
@@ -219,6 +223,22 @@ type Server struct {
 	// volumeWatcher is used to release volume claims
 	volumeWatcher *volumewatcher.Watcher
 
+	// serviceSyncer is used to export the native services to external
+	// registries.
+	serviceSyncer *servicesync.Syncer
+
+	// payloadStore keeps the dispatch payloads and job sources above a size
+	// threshold. It's nil if no payload store is configured.
+	payloadStore *payloadstore.Store
+
+	// accessAuditor records the accesses to variables and keys in the audit
+	// log of the agent. It's nil if access events aren't enabled.
+	accessAuditor *accessAuditor
+
+	// jobMetricsLabeler computes the labels of the metrics emitted per job.
+	// It's nil if all the labels are attached to the metrics of every job.
+	jobMetricsLabeler *jobMetricsLabeler
+
 	// volumeControllerFutures is a map of plugin IDs to pending controller RPCs. If
 	// no RPC is pending for a given plugin, this may be nil.
 	volumeControllerFutures map[string]context.Context
@@ -234,6 +254,18 @@ type Server struct {
 	// workload identities
 	encrypter *Encrypter
 
+	// workloadCA signs the X.509 certificates of workload identities, or is
+	// nil if workload certificates are disabled
+	workloadCA *workloadCA
+
+	// scoringPlugins are the clients of the external scoring plugins used by
+	// the scheduling workers
+	scoringPlugins []*scoring.Client
+
+	// signerPlugin is the client of the signer plugin used to sign workload
+	// identities, if configured
+	signerPlugin *signer.Client
+
 	// periodicDispatcher is used to track and create evaluations for periodic jobs.
 	periodicDispatcher *PeriodicDispatch
 
@@ -245,6 +277,10 @@ type Server struct {
 	// detects an expired node, the node status is updated to be 'down'.
 	*nodeHeartbeater
 
+	// nodeUtilization keeps the rollups of the utilization reported in the
+	// heartbeats of the nodes.
+	nodeUtilization *nodeUtilizationRollups
+
 	// consulCatalog is used for discovering other Nomad Servers via Consul
 	consulCatalog consul.CatalogAPI
 
@@ -373,6 +409,8 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 	s.shutdownCtx, s.shutdownCancel = context.WithCancel(context.Background())
 	s.shutdownCh = s.shutdownCtx.Done()
 
+	s.blockedEvals.SetNamespaceWeights(config.NamespaceUnblockWeights)
+
 	// Create the RPC handler
 	s.rpcHandler = newRpcHandler(s)
 
@@ -385,6 +423,7 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 
 	// Create the node heartbeater
 	s.nodeHeartbeater = newNodeHeartbeater(s)
+	s.nodeUtilization = newNodeUtilizationRollups()
 
 	// Create the periodic dispatcher for launching periodic jobs.
 	s.periodicDispatcher = NewPeriodicDispatch(s.logger, s)
@@ -416,6 +455,23 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 	}
 	s.encrypter = encrypter
 
+	// Set up the signer plugin used to sign workload identities in place of
+	// the keyring
+	if config.SignerPlugin != nil {
+		plugin, err := signer.NewClient(config.SignerPlugin)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to setup signer plugin: %v", err)
+		}
+		s.signerPlugin = plugin
+		encrypter.SetSigner(plugin)
+	}
+
+	workloadCA, err := newWorkloadCA(config.WorkloadCA, encrypter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workload CA: %w", err)
+	}
+	s.workloadCA = workloadCA
+
 	// Set up the OIDC discovery configuration required by third parties, such as
 	// AWS's IAM OIDC Provider, to authenticate workload identity JWTs.
 	if iss := config.OIDCIssuer; iss != "" {
@@ -423,6 +479,9 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 		if err != nil {
 			return nil, err
 		}
+		if config.SignerPlugin != nil {
+			oidcDisco.IDTokenAlgs = append(oidcDisco.IDTokenAlgs, structs.PubKeyAlgES256)
+		}
 		s.oidcDisco = oidcDisco
 		s.logger.Info("issuer set; OIDC Discovery endpoint for workload identities enabled", "issuer", iss)
 	} else {
@@ -434,6 +493,16 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 	// processes when it shuts down itself.
 	s.oidcProviderCache = oidc.NewProviderCache()
 
+	// Set up the clients of the scoring plugins
+	for _, conf := range config.ScoringPlugins {
+		plugin, err := scoring.NewClient(conf)
+		if err != nil {
+			s.Shutdown()
+			return nil, fmt.Errorf("Failed to setup scoring plugin: %v", err)
+		}
+		s.scoringPlugins = append(s.scoringPlugins, plugin)
+	}
+
 	// Initialize the RPC layer
 	if err := s.setupRPC(tlsWrap); err != nil {
 		s.Shutdown()
@@ -492,6 +561,26 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 	}
 	s.volumeControllerFutures = map[string]context.Context{}
 
+	// Setup the service syncer
+	s.serviceSyncer, err = servicesync.NewSyncer(s.logger, s.config.ServiceSyncs)
+	if err != nil {
+		s.logger.Error("failed to create service syncer", "error", err)
+		return nil, fmt.Errorf("failed to create service syncer: %v", err)
+	}
+
+	// Setup the payload store
+	s.payloadStore, err = payloadstore.New(s.logger, s.config.PayloadStore)
+	if err != nil {
+		s.logger.Error("failed to create payload store", "error", err)
+		return nil, fmt.Errorf("failed to create payload store: %v", err)
+	}
+
+	// Setup the access auditor, which records nothing until the agent sets
+	// its audit logger
+	s.accessAuditor = newAccessAuditor(s.logger, s.config.AuditAccess)
+
+	s.jobMetricsLabeler = newJobMetricsLabeler(s.config.JobMetricsLabels)
+
 	// Start the eval broker notification system so any subscribers can get
 	// updates when the processes SetEnabled is triggered.
 	go s.evalBroker.enabledNotifier.Run(s.shutdownCh)
@@ -514,7 +603,7 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 	s.startRPCListener()
 
 	// Emit metrics for the eval broker
-	go evalBroker.EmitStats(time.Second, s.shutdownCh)
+	go evalBroker.EmitStats(time.Second, s.jobMetricsLabeler, s.shutdownCh)
 
 	// Emit metrics for the plan queue
 	go s.planQueue.EmitStats(time.Second, s.shutdownCh)
@@ -523,7 +612,7 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI, consulConfigFunc
 	go s.planner.badNodeTracker.EmitStats(time.Second, s.shutdownCh)
 
 	// Emit metrics for the blocked eval tracker.
-	go s.blockedEvals.EmitStats(time.Second, s.shutdownCh)
+	go s.blockedEvals.EmitStats(time.Second, s.jobMetricsLabeler, s.shutdownCh)
 
 	// Emit metrics for the Vault client.
 	go s.vault.EmitStats(time.Second, s.shutdownCh)
@@ -757,6 +846,14 @@ func (s *Server) Shutdown() error {
 		s.oidcProviderCache.Shutdown()
 	}
 
+	// Close the connections to the scoring plugins
+	for _, plugin := range s.scoringPlugins {
+		plugin.Close()
+	}
+	if s.signerPlugin != nil {
+		s.signerPlugin.Close()
+	}
+
 	return nil
 }
 
@@ -899,6 +996,8 @@ func (s *Server) Reload(newConfig *Config) error {
 		}
 	}
 
+	s.blockedEvals.SetNamespaceWeights(newConfig.NamespaceUnblockWeights)
+
 	// Because this is a new configuration, we extract the worker pool arguments without acquiring a lock
 	workerPoolArgs := getSchedulerWorkerPoolArgsFromConfigLocked(newConfig)
 	if reload, newVals := shouldReloadSchedulers(s, workerPoolArgs); reload {
@@ -1339,14 +1438,17 @@ func (s *Server) setupRaft() error {
 
 	// Create the FSM
 	fsmConfig := &FSMConfig{
-		EvalBroker:         s.evalBroker,
-		Periodic:           s.periodicDispatcher,
-		Blocked:            s.blockedEvals,
-		Logger:             s.logger,
-		Region:             s.Region(),
-		EnableEventBroker:  s.config.EnableEventBroker,
-		EventBufferSize:    s.config.EventBufferSize,
-		JobTrackedVersions: s.config.JobTrackedVersions,
+		EvalBroker:              s.evalBroker,
+		Periodic:                s.periodicDispatcher,
+		Blocked:                 s.blockedEvals,
+		Logger:                  s.logger,
+		Region:                  s.Region(),
+		EnableEventBroker:       s.config.EnableEventBroker,
+		EventBufferSize:         s.config.EventBufferSize,
+		EnableChangeFeed:        s.config.EnableChangeFeed,
+		JobTrackedVersions:      s.config.JobTrackedVersions,
+		JobTrackedScalingEvents: s.config.JobTrackedScalingEvents,
+		SnapshotChunkSize:       s.config.RaftSnapshotChunkSize,
 	}
 	var err error
 	s.fsm, err = NewFSM(fsmConfig)
@@ -1883,6 +1985,12 @@ func (s *Server) numPeers() (int, error) {
 	return len(configuration.Servers), nil
 }
 
+// SetAuditor sets the audit logger of the agent, which receives the access
+// events of variables and keys when they're enabled.
+func (s *Server) SetAuditor(auditor Auditor) {
+	s.accessAuditor.setAuditor(auditor)
+}
+
 // IsLeader checks if this server is the cluster leader
 func (s *Server) IsLeader() bool {
 	return s.raft.State() == raft.Leader
@@ -6,6 +6,7 @@ package nomad
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -131,6 +132,45 @@ func (s *ServiceRegistration) DeleteByID(
 	return nil
 }
 
+// allowRead returns whether the caller can read the service registrations of
+// the namespace, and the ID of the job the registrations it can read are
+// limited to, if any. Workload identities without read-job on the namespace
+// can read the registrations allowed by the workload policy of the servers.
+func (s *ServiceRegistration) allowRead(
+	aclObj *acl.ACL, args structs.RequestWithIdentity, ns string) (bool, string, error) {
+
+	if aclObj.AllowServiceRegistrationReadList(ns, false) {
+		return true, "", nil
+	}
+
+	claims := args.GetIdentity().GetClaims()
+	if claims == nil {
+		return false, "", nil
+	}
+
+	switch s.srv.config.ACLWorkloadPolicy.Services {
+	case acl.WorkloadServicesNamespace:
+		return aclObj.AllowServiceRegistrationReadList(ns, true), "", nil
+	case acl.WorkloadServicesJob:
+		if ns != claims.Namespace {
+			return false, "", nil
+		}
+
+		// The services are registered with the ID of the job of the
+		// allocation, which differs from the claims for dispatched and
+		// periodic jobs.
+		alloc, err := s.srv.State().AllocByID(nil, claims.AllocationID)
+		if err != nil {
+			return false, "", err
+		}
+		if alloc == nil {
+			return false, "", nil
+		}
+		return true, alloc.JobID, nil
+	}
+	return false, "", nil
+}
+
 // serviceTagSet maps from a service name to a union of tags associated with that service.
 type serviceTagSet map[string]*set.Set[string]
 
@@ -178,8 +218,11 @@ func (s *ServiceRegistration) List(
 	if err != nil {
 		return err
 	}
-	if !aclObj.AllowServiceRegistrationReadList(args.RequestNamespace(),
-		args.GetIdentity().Claims != nil) {
+	allowed, jobID, err := s.allowRead(aclObj, args, args.RequestNamespace())
+	if err != nil {
+		return err
+	}
+	if !allowed {
 		return structs.ErrPermissionDenied
 	}
 
@@ -200,6 +243,9 @@ func (s *ServiceRegistration) List(
 
 			for raw := iter.Next(); raw != nil; raw = iter.Next() {
 				serviceReg := raw.(*structs.ServiceRegistration)
+				if jobID != "" && serviceReg.JobID != jobID {
+					continue
+				}
 				tagSet.add(serviceReg.ServiceName, serviceReg.Tags)
 			}
 
@@ -343,8 +389,11 @@ func (s *ServiceRegistration) GetService(
 	if err != nil {
 		return structs.ErrPermissionDenied
 	}
-	if !aclObj.AllowServiceRegistrationReadList(args.RequestNamespace(),
-		args.GetIdentity().Claims != nil) {
+	allowed, jobID, err := s.allowRead(aclObj, args, args.RequestNamespace())
+	if err != nil {
+		return err
+	}
+	if !allowed {
 		return structs.ErrPermissionDenied
 	}
 
@@ -359,6 +408,11 @@ func (s *ServiceRegistration) GetService(
 			if err != nil {
 				return err
 			}
+			if jobID != "" {
+				iter = memdb.NewFilterIterator(iter, func(raw interface{}) bool {
+					return raw.(*structs.ServiceRegistration).JobID != jobID
+				})
+			}
 
 			// Generate the tokenizer to use for pagination using namespace and
 			// ID to ensure complete uniqueness.
@@ -413,6 +467,179 @@ func (s *ServiceRegistration) GetService(
 	})
 }
 
+// ListInstances is used to list the healthy instances of services, along
+// with the details of the allocations that registered them. It is used to
+// feed monitoring systems such as Prometheus, so only the instances of
+// running and healthy allocations are returned.
+func (s *ServiceRegistration) ListInstances(
+	args *structs.ServiceRegistrationInstancesRequest,
+	reply *structs.ServiceRegistrationInstancesResponse) error {
+
+	authErr := s.srv.Authenticate(s.ctx, args)
+	if done, err := s.srv.forward(structs.ServiceRegistrationListInstancesRPCMethod, args, args, reply); done {
+		return err
+	}
+	s.srv.MeasureRPCRate("service_registration", structs.RateMetricList, args)
+	if authErr != nil {
+		return structs.ErrPermissionDenied
+	}
+	defer metrics.MeasureSince([]string{"nomad", "service_registration", "list_instances"}, time.Now())
+
+	aclObj, err := s.srv.ResolveACL(args)
+	if err != nil {
+		return err
+	}
+
+	// Callers listing across all namespaces are limited to the namespaces
+	// they have read-job on, while callers of a single namespace may also be
+	// workload identities allowed by the workload policy.
+	var jobID string
+	allNamespaces := args.RequestNamespace() == structs.AllNamespacesSentinel
+	if !allNamespaces {
+		var allowed bool
+		allowed, jobID, err = s.allowRead(aclObj, args, args.RequestNamespace())
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	allowFunc := func(ns string) bool {
+		return aclObj.AllowNsOp(ns, acl.NamespaceCapabilityReadJob)
+	}
+
+	// Set up and return the blocking query.
+	return s.srv.blockingRPC(&blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		run: func(ws memdb.WatchSet, stateStore *state.StateStore) error {
+
+			var iter memdb.ResultIterator
+			var allowed map[string]bool
+			var err error
+			if allNamespaces {
+				allowed, err = allowedNSes(aclObj, stateStore, allowFunc)
+				switch err {
+				case structs.ErrPermissionDenied:
+					reply.Instances = make([]*structs.ServiceInstance, 0)
+					return s.setInstancesQueryMeta(stateStore, &reply.QueryMeta)
+				case nil:
+					// Fallthrough.
+				default:
+					return err
+				}
+				iter, err = stateStore.GetServiceRegistrations(ws)
+			} else if args.ServiceName != "" {
+				iter, err = stateStore.GetServiceRegistrationByName(ws, args.RequestNamespace(), args.ServiceName)
+			} else {
+				iter, err = stateStore.GetServiceRegistrationsByNamespace(ws, args.RequestNamespace())
+			}
+			if err != nil {
+				return err
+			}
+
+			instances := make([]*structs.ServiceInstance, 0)
+			for raw := iter.Next(); raw != nil; raw = iter.Next() {
+				reg := raw.(*structs.ServiceRegistration)
+
+				if allowed != nil && !allowed[reg.Namespace] {
+					continue
+				}
+				if jobID != "" && reg.JobID != jobID {
+					continue
+				}
+				if args.ServiceName != "" && reg.ServiceName != args.ServiceName {
+					continue
+				}
+				if !hasAllTags(reg.Tags, args.Tags) {
+					continue
+				}
+
+				// The instances of allocations that are not running, or
+				// which are known to be unhealthy, should not be scraped.
+				alloc, err := stateStore.AllocByID(ws, reg.AllocID)
+				if err != nil {
+					return err
+				}
+				if !instanceAllocHealthy(alloc) {
+					continue
+				}
+
+				instance := &structs.ServiceInstance{
+					ServiceRegistration: reg,
+					AllocName:           alloc.Name,
+					TaskGroup:           alloc.TaskGroup,
+				}
+				node, err := stateStore.NodeByID(ws, reg.NodeID)
+				if err != nil {
+					return err
+				}
+				if node != nil {
+					instance.NodeName = node.Name
+				}
+				instances = append(instances, instance)
+			}
+
+			sort.Slice(instances, func(i, j int) bool {
+				a, b := instances[i], instances[j]
+				if a.Namespace != b.Namespace {
+					return a.Namespace < b.Namespace
+				}
+				if a.ServiceName != b.ServiceName {
+					return a.ServiceName < b.ServiceName
+				}
+				return a.ID < b.ID
+			})
+			reply.Instances = instances
+
+			return s.setInstancesQueryMeta(stateStore, &reply.QueryMeta)
+		},
+	})
+}
+
+// setInstancesQueryMeta populates the query meta of the instances listing.
+// The health of the instances depends on their allocations, so the index is
+// the highest of the service registrations and allocations tables.
+func (s *ServiceRegistration) setInstancesQueryMeta(stateStore *state.StateStore, reply *structs.QueryMeta) error {
+	var index uint64
+	for _, table := range []string{state.TableServiceRegistrations, state.TableAllocs} {
+		tableIndex, err := stateStore.Index(table)
+		if err != nil {
+			return err
+		}
+		index = max(index, tableIndex)
+	}
+	reply.Index = max(1, index)
+	s.srv.setQueryMeta(reply)
+	return nil
+}
+
+// instanceAllocHealthy returns whether the instances registered by the
+// allocation are healthy. Allocations without a deployment health are
+// considered healthy as long as they are running.
+func instanceAllocHealthy(alloc *structs.Allocation) bool {
+	if alloc == nil || alloc.ClientStatus != structs.AllocClientStatusRunning ||
+		alloc.DesiredStatus != structs.AllocDesiredStatusRun {
+		return false
+	}
+	if alloc.DeploymentStatus != nil && alloc.DeploymentStatus.Healthy != nil {
+		return *alloc.DeploymentStatus.Healthy
+	}
+	return true
+}
+
+// hasAllTags returns whether tags contains all the required tags.
+func hasAllTags(tags, required []string) bool {
+	for _, tag := range required {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
 // choose uses rendezvous hashing to make a stable selection of a subset of services
 // to return.
 //
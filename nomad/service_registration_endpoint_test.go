@@ -12,6 +12,7 @@ import (
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
@@ -906,8 +907,11 @@ func TestServiceRegistration_List(t *testing.T) {
 				must.NoError(t, s.State().UpsertACLPolicies(structs.MsgTypeTestSetup, 16,
 					[]*structs.ACLPolicy{policy}))
 
-				// Generate and upsert some service registrations.
+				// Generate and upsert some service registrations. The default
+				// workload policy only lets workloads read the services of
+				// their own job.
 				services := mock.ServiceRegistrations()
+				services[1].JobID = job.ID
 				require.NoError(t, s.State().UpsertServiceRegistrations(
 					structs.MsgTypeTestSetup, 20, services))
 
@@ -1161,8 +1165,18 @@ func TestServiceRegistration_GetService(t *testing.T) {
 				codec := rpcClient(t, s)
 				testutil.WaitForKeyring(t, s.RPC, "global")
 
-				// Generate mock services then upsert them individually using different indexes.
+				// Generate an allocation with a signed identity
+				allocs := []*structs.Allocation{mock.Alloc()}
+				job := allocs[0].Job
+				require.NoError(t, s.State().UpsertJob(structs.MsgTypeTestSetup, 10, nil, job))
+				signAllocIdentities(s.encrypter, job, allocs, time.Now())
+				require.NoError(t, s.State().UpsertAllocs(structs.MsgTypeTestSetup, 15, allocs))
+
+				// Generate mock services then upsert them individually using
+				// different indexes. The default workload policy only lets
+				// workloads read the services of their own job.
 				services := mock.ServiceRegistrations()
+				services[0].JobID = job.ID
 
 				require.NoError(t, s.fsm.State().UpsertServiceRegistrations(
 					structs.MsgTypeTestSetup, 10, []*structs.ServiceRegistration{services[0]}))
@@ -1170,13 +1184,6 @@ func TestServiceRegistration_GetService(t *testing.T) {
 				require.NoError(t, s.fsm.State().UpsertServiceRegistrations(
 					structs.MsgTypeTestSetup, 20, []*structs.ServiceRegistration{services[1]}))
 
-				// Generate an allocation with a signed identity
-				allocs := []*structs.Allocation{mock.Alloc()}
-				job := allocs[0].Job
-				require.NoError(t, s.State().UpsertJob(structs.MsgTypeTestSetup, 10, nil, job))
-				signAllocIdentities(s.encrypter, job, allocs, time.Now())
-				require.NoError(t, s.State().UpsertAllocs(structs.MsgTypeTestSetup, 15, allocs))
-
 				signedToken := allocs[0].SignedIdentities["web"]
 
 				// Lookup the first registration.
@@ -1450,6 +1457,178 @@ func TestServiceRegistration_GetService(t *testing.T) {
 	}
 }
 
+func TestServiceRegistration_WorkloadPolicy(t *testing.T) {
+	ci.Parallel(t)
+
+	s, _, cleanup := TestACLServer(t, nil)
+	defer cleanup()
+	codec := rpcClient(t, s)
+	testutil.WaitForKeyring(t, s.RPC, "global")
+
+	// Generate an allocation with a signed identity
+	allocs := []*structs.Allocation{mock.Alloc()}
+	job := allocs[0].Job
+	must.NoError(t, s.State().UpsertJob(structs.MsgTypeTestSetup, 10, nil, job))
+	signAllocIdentities(s.encrypter, job, allocs, time.Now())
+	must.NoError(t, s.State().UpsertAllocs(structs.MsgTypeTestSetup, 15, allocs))
+	signedToken := allocs[0].SignedIdentities["web"]
+
+	// Register the same service for the job of the allocation and for
+	// another job.
+	other := mock.ServiceRegistrations()[0]
+	own := other.Copy()
+	own.ID = "_nomad-task-" + allocs[0].ID + "-group-web-example-cache"
+	own.JobID = job.ID
+	own.AllocID = allocs[0].ID
+	must.NoError(t, s.State().UpsertServiceRegistrations(
+		structs.MsgTypeTestSetup, 20, []*structs.ServiceRegistration{other, own}))
+
+	getService := func() ([]*structs.ServiceRegistration, error) {
+		req := &structs.ServiceRegistrationByNameRequest{
+			ServiceName: other.ServiceName,
+			QueryOptions: structs.QueryOptions{
+				Namespace: structs.DefaultNamespace,
+				Region:    s.Region(),
+				AuthToken: signedToken,
+			},
+		}
+		var resp structs.ServiceRegistrationByNameResponse
+		err := msgpackrpc.CallWithCodec(codec, structs.ServiceRegistrationGetServiceRPCMethod, req, &resp)
+		return resp.Services, err
+	}
+	listServices := func(ns string) ([]*structs.ServiceRegistrationListStub, error) {
+		req := &structs.ServiceRegistrationListRequest{
+			QueryOptions: structs.QueryOptions{
+				Namespace: ns,
+				Region:    s.Region(),
+				AuthToken: signedToken,
+			},
+		}
+		var resp structs.ServiceRegistrationListResponse
+		err := msgpackrpc.CallWithCodec(codec, structs.ServiceRegistrationListRPCMethod, req, &resp)
+		return resp.Services, err
+	}
+
+	// The default workload policy only returns the services of the job.
+	services, err := getService()
+	must.NoError(t, err)
+	must.Len(t, 1, services)
+	must.Eq(t, own.ID, services[0].ID)
+
+	stubs, err := listServices(structs.DefaultNamespace)
+	must.NoError(t, err)
+	must.Len(t, 1, stubs)
+
+	_, err = listServices("platform")
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	// Workloads can read all the services with the namespace scope.
+	s.config.ACLWorkloadPolicy = &acl.WorkloadPolicy{Services: acl.WorkloadServicesNamespace}
+	services, err = getService()
+	must.NoError(t, err)
+	must.Len(t, 2, services)
+
+	// Workloads can't read services when denied.
+	s.config.ACLWorkloadPolicy = &acl.WorkloadPolicy{Services: acl.PolicyDeny}
+	_, err = getService()
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+}
+
+func TestServiceRegistration_ListInstances(t *testing.T) {
+	ci.Parallel(t)
+
+	s, root, cleanup := TestACLServer(t, nil)
+	defer cleanup()
+	codec := rpcClient(t, s)
+	testutil.WaitForKeyring(t, s.RPC, "global")
+
+	node := mock.Node()
+	must.NoError(t, s.State().UpsertNode(structs.MsgTypeTestSetup, 10, node))
+	must.NoError(t, s.State().UpsertNamespaces(11, []*structs.Namespace{{Name: "platform"}}))
+
+	// Register a service per allocation, where only the running and healthy
+	// allocations have healthy instances.
+	healthy, failed, unhealthy, other := mock.Alloc(), mock.Alloc(), mock.Alloc(), mock.Alloc()
+	other.Namespace = "platform"
+	unhealthy.DeploymentStatus = &structs.AllocDeploymentStatus{Healthy: pointer.Of(false)}
+	allocs := []*structs.Allocation{healthy, failed, unhealthy, other}
+
+	var services []*structs.ServiceRegistration
+	for i, alloc := range allocs {
+		alloc.NodeID = node.ID
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		service := mock.ServiceRegistrations()[0]
+		service.ID = fmt.Sprintf("_nomad-task-%s-group-web-example-cache", alloc.ID)
+		service.Namespace = alloc.Namespace
+		service.NodeID = node.ID
+		service.JobID = alloc.JobID
+		service.AllocID = alloc.ID
+		service.Port = 20000 + i
+		services = append(services, service)
+	}
+	failed.ClientStatus = structs.AllocClientStatusFailed
+	services[3].Tags = []string{"bar"}
+	must.NoError(t, s.State().UpsertAllocs(structs.MsgTypeTestSetup, 15, allocs))
+	must.NoError(t, s.State().UpsertServiceRegistrations(structs.MsgTypeTestSetup, 20, services))
+
+	listInstances := func(ns, service, token string, tags ...string) ([]*structs.ServiceInstance, error) {
+		req := &structs.ServiceRegistrationInstancesRequest{
+			ServiceName: service,
+			Tags:        tags,
+			QueryOptions: structs.QueryOptions{
+				Namespace: ns,
+				Region:    s.Region(),
+				AuthToken: token,
+			},
+		}
+		var resp structs.ServiceRegistrationInstancesResponse
+		err := msgpackrpc.CallWithCodec(codec, structs.ServiceRegistrationListInstancesRPCMethod, req, &resp)
+		if err == nil {
+			must.Eq(t, 20, resp.Index)
+		}
+		return resp.Instances, err
+	}
+
+	// Only the instances of the healthy allocations are listed, along with
+	// the details of their allocation.
+	instances, err := listInstances(structs.AllNamespacesSentinel, "", root.SecretID)
+	must.NoError(t, err)
+	must.Len(t, 2, instances)
+	must.Eq(t, services[0].ID, instances[0].ID)
+	must.Eq(t, healthy.Name, instances[0].AllocName)
+	must.Eq(t, healthy.TaskGroup, instances[0].TaskGroup)
+	must.Eq(t, node.Name, instances[0].NodeName)
+	must.Eq(t, services[3].ID, instances[1].ID)
+
+	instances, err = listInstances(structs.DefaultNamespace, services[0].ServiceName, root.SecretID)
+	must.NoError(t, err)
+	must.Len(t, 1, instances)
+	must.Eq(t, services[0].ID, instances[0].ID)
+
+	instances, err = listInstances(structs.AllNamespacesSentinel, "", root.SecretID, "bar")
+	must.NoError(t, err)
+	must.Len(t, 1, instances)
+	must.Eq(t, services[3].ID, instances[0].ID)
+
+	instances, err = listInstances(structs.AllNamespacesSentinel, "nope", root.SecretID)
+	must.NoError(t, err)
+	must.SliceEmpty(t, instances)
+
+	// Callers are limited to the namespaces they can read.
+	token := mock.CreatePolicyAndToken(t, s.State(), 30, "test-service-instances",
+		mock.NamespacePolicy("platform", "", []string{acl.NamespaceCapabilityReadJob})).SecretID
+	instances, err = listInstances(structs.AllNamespacesSentinel, "", token)
+	must.NoError(t, err)
+	must.Len(t, 1, instances)
+	must.Eq(t, services[3].ID, instances[0].ID)
+
+	_, err = listInstances(structs.DefaultNamespace, "", token)
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+
+	_, err = listInstances(structs.DefaultNamespace, "", "")
+	must.EqError(t, err, structs.ErrPermissionDenied.Error())
+}
+
 func TestServiceRegistration_chooseErr(t *testing.T) {
 	ci.Parallel(t)
 
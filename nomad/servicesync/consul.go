@@ -0,0 +1,178 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	consulapi "github.com/hashicorp/consul/api"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// defaultConsulNodeName is the name of the Consul node the services are
+	// registered on.
+	defaultConsulNodeName = "nomad-services"
+
+	// defaultConsulNodeAddress is the address of the Consul node the services
+	// are registered on. The services have their own address, so the node
+	// doesn't need to be reachable.
+	defaultConsulNodeAddress = "127.0.0.1"
+
+	// consulExternalSourceMeta is the key of the meta Consul uses to record
+	// the system that registered a node or a service.
+	consulExternalSourceMeta = "external-source"
+)
+
+// consulCatalog is the subset of the Consul catalog API used by the provider.
+type consulCatalog interface {
+	Node(node string, q *consulapi.QueryOptions) (*consulapi.CatalogNode, *consulapi.QueryMeta, error)
+	Register(reg *consulapi.CatalogRegistration, q *consulapi.WriteOptions) (*consulapi.WriteMeta, error)
+	Deregister(dereg *consulapi.CatalogDeregistration, q *consulapi.WriteOptions) (*consulapi.WriteMeta, error)
+}
+
+// consulProvider exports the services to the catalog of a Consul cluster. The
+// services are registered on a dedicated node, so the provider owns all the
+// services of that node.
+type consulProvider struct {
+	logger      log.Logger
+	catalog     consulCatalog
+	nodeName    string
+	nodeAddress string
+}
+
+// newConsulProvider returns a Consul provider. The client is configured from
+// the CONSUL_* environment variables, overridden by the config.
+func newConsulProvider(logger log.Logger, config map[string]string) (Provider, error) {
+	conf := consulapi.DefaultConfig()
+	for key, value := range config {
+		switch key {
+		case "address":
+			conf.Address = value
+		case "scheme":
+			conf.Scheme = value
+		case "datacenter":
+			conf.Datacenter = value
+		case "token":
+			conf.Token = value
+		case "ca_file":
+			conf.TLSConfig.CAFile = value
+		case "cert_file":
+			conf.TLSConfig.CertFile = value
+		case "key_file":
+			conf.TLSConfig.KeyFile = value
+		case "node_name", "node_address":
+		default:
+			return nil, fmt.Errorf("unknown consul config key %q", key)
+		}
+	}
+
+	client, err := consulapi.NewClient(conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %w", err)
+	}
+
+	p := &consulProvider{
+		logger:      logger,
+		catalog:     client.Catalog(),
+		nodeName:    config["node_name"],
+		nodeAddress: config["node_address"],
+	}
+	if p.nodeName == "" {
+		p.nodeName = defaultConsulNodeName
+	}
+	if p.nodeAddress == "" {
+		p.nodeAddress = defaultConsulNodeAddress
+	}
+	return p, nil
+}
+
+// Sync implements the Provider interface.
+func (p *consulProvider) Sync(ctx context.Context, services []*structs.ServiceRegistration) error {
+	node, _, err := p.catalog.Node(p.nodeName, (&consulapi.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to read consul node %q: %w", p.nodeName, err)
+	}
+
+	var registered map[string]*consulapi.AgentService
+	if node != nil {
+		registered = node.Services
+	}
+
+	wo := (&consulapi.WriteOptions{}).WithContext(ctx)
+	desired := make(map[string]struct{}, len(services))
+
+	var mErr *multierror.Error
+	for _, service := range services {
+		desired[service.ID] = struct{}{}
+
+		want := p.agentService(service)
+		if have, ok := registered[service.ID]; ok && consulServiceEqual(have, want) {
+			continue
+		}
+
+		_, err := p.catalog.Register(&consulapi.CatalogRegistration{
+			Node:     p.nodeName,
+			Address:  p.nodeAddress,
+			NodeMeta: map[string]string{consulExternalSourceMeta: "nomad"},
+			Service:  want,
+		}, wo)
+		if err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("failed to register service %q: %w", service.ID, err))
+			continue
+		}
+		p.logger.Debug("registered service", "service_id", service.ID)
+	}
+
+	for id := range registered {
+		if _, ok := desired[id]; ok {
+			continue
+		}
+
+		_, err := p.catalog.Deregister(&consulapi.CatalogDeregistration{
+			Node:      p.nodeName,
+			ServiceID: id,
+		}, wo)
+		if err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("failed to deregister service %q: %w", id, err))
+			continue
+		}
+		p.logger.Debug("deregistered service", "service_id", id)
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// agentService returns the Consul service of a Nomad service.
+func (p *consulProvider) agentService(service *structs.ServiceRegistration) *consulapi.AgentService {
+	return &consulapi.AgentService{
+		ID:      service.ID,
+		Service: service.ServiceName,
+		Tags:    slices.Clone(service.Tags),
+		Address: service.Address,
+		Port:    service.Port,
+		Meta: map[string]string{
+			consulExternalSourceMeta: "nomad",
+			"nomad_namespace":        service.Namespace,
+			"nomad_job_id":           service.JobID,
+			"nomad_alloc_id":         service.AllocID,
+			"nomad_datacenter":       service.Datacenter,
+		},
+	}
+}
+
+// consulServiceEqual returns true if the registered service a matches the
+// desired service b.
+func consulServiceEqual(a, b *consulapi.AgentService) bool {
+	return a.Service == b.Service &&
+		a.Address == b.Address &&
+		a.Port == b.Port &&
+		slices.Equal(a.Tags, b.Tags) &&
+		maps.Equal(a.Meta, b.Meta)
+}
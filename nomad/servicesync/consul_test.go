@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+// fakeConsulCatalog is an in-memory catalog of a single node.
+type fakeConsulCatalog struct {
+	services      map[string]*consulapi.AgentService
+	registers     int
+	deregisters   int
+	registerNodes []string
+}
+
+func (f *fakeConsulCatalog) Node(string, *consulapi.QueryOptions) (*consulapi.CatalogNode, *consulapi.QueryMeta, error) {
+	if f.services == nil {
+		return nil, nil, nil
+	}
+	return &consulapi.CatalogNode{Services: f.services}, nil, nil
+}
+
+func (f *fakeConsulCatalog) Register(reg *consulapi.CatalogRegistration, _ *consulapi.WriteOptions) (*consulapi.WriteMeta, error) {
+	if f.services == nil {
+		f.services = make(map[string]*consulapi.AgentService)
+	}
+	f.services[reg.Service.ID] = reg.Service
+	f.registers++
+	f.registerNodes = append(f.registerNodes, reg.Node)
+	return nil, nil
+}
+
+func (f *fakeConsulCatalog) Deregister(dereg *consulapi.CatalogDeregistration, _ *consulapi.WriteOptions) (*consulapi.WriteMeta, error) {
+	delete(f.services, dereg.ServiceID)
+	f.deregisters++
+	return nil, nil
+}
+
+func TestConsulProvider_Sync(t *testing.T) {
+	ci.Parallel(t)
+
+	catalog := &fakeConsulCatalog{}
+	p := &consulProvider{
+		logger:      testlog.HCLogger(t),
+		catalog:     catalog,
+		nodeName:    defaultConsulNodeName,
+		nodeAddress: defaultConsulNodeAddress,
+	}
+	ctx := context.Background()
+	services := mock.ServiceRegistrations()
+
+	// The services are registered on the node.
+	must.NoError(t, p.Sync(ctx, services))
+	must.Eq(t, 2, catalog.registers)
+	must.Eq(t, []string{defaultConsulNodeName, defaultConsulNodeName}, catalog.registerNodes)
+	registered := catalog.services[services[0].ID]
+	must.Eq(t, services[0].ServiceName, registered.Service)
+	must.Eq(t, services[0].Address, registered.Address)
+	must.Eq(t, services[0].Port, registered.Port)
+	must.Eq(t, "nomad", registered.Meta[consulExternalSourceMeta])
+	must.Eq(t, services[0].AllocID, registered.Meta["nomad_alloc_id"])
+
+	// Unchanged services are not registered again.
+	must.NoError(t, p.Sync(ctx, services))
+	must.Eq(t, 2, catalog.registers)
+
+	// Changed services are registered again, and removed services are
+	// deregistered.
+	changed := services[0].Copy()
+	changed.Port = 24000
+	must.NoError(t, p.Sync(ctx, []*structs.ServiceRegistration{changed}))
+	must.Eq(t, 3, catalog.registers)
+	must.Eq(t, 1, catalog.deregisters)
+	must.MapLen(t, 1, catalog.services)
+	must.Eq(t, 24000, catalog.services[changed.ID].Port)
+
+	// Services registered outside of Nomad on the node are deregistered.
+	catalog.services["manual"] = &consulapi.AgentService{ID: "manual"}
+	must.NoError(t, p.Sync(ctx, []*structs.ServiceRegistration{changed}))
+	must.MapNotContainsKey(t, catalog.services, "manual")
+}
+
+func TestConsulProvider_Config(t *testing.T) {
+	ci.Parallel(t)
+
+	provider, err := newConsulProvider(testlog.HCLogger(t), map[string]string{
+		"address":   "127.0.0.1:8501",
+		"node_name": "nomad-east",
+	})
+	must.NoError(t, err)
+	p := provider.(*consulProvider)
+	must.Eq(t, "nomad-east", p.nodeName)
+	must.Eq(t, defaultConsulNodeAddress, p.nodeAddress)
+
+	_, err = newConsulProvider(testlog.HCLogger(t), map[string]string{"nope": "nope"})
+	must.EqError(t, err, `unknown consul config key "nope"`)
+}
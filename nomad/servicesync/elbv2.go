@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// elbv2API is the subset of the Elastic Load Balancing API used by the
+// provider.
+type elbv2API interface {
+	DescribeTargetHealthWithContext(aws.Context, *elbv2.DescribeTargetHealthInput, ...request.Option) (*elbv2.DescribeTargetHealthOutput, error)
+	RegisterTargetsWithContext(aws.Context, *elbv2.RegisterTargetsInput, ...request.Option) (*elbv2.RegisterTargetsOutput, error)
+	DeregisterTargetsWithContext(aws.Context, *elbv2.DeregisterTargetsInput, ...request.Option) (*elbv2.DeregisterTargetsOutput, error)
+}
+
+// elbv2Provider exports the instances of a service as the targets of an
+// Elastic Load Balancing target group of type ip. The target group is
+// dedicated to the service, so the provider owns all its targets.
+type elbv2Provider struct {
+	logger         log.Logger
+	api            elbv2API
+	targetGroupARN string
+	service        string
+}
+
+// elbv2Target identifies a target of the target group.
+type elbv2Target struct {
+	ip   string
+	port int64
+}
+
+// newELBv2Provider returns an Elastic Load Balancing provider. The AWS
+// credentials are read from the default credentials chain.
+func newELBv2Provider(logger log.Logger, config map[string]string) (Provider, error) {
+	p := &elbv2Provider{
+		logger: logger,
+	}
+
+	awsConfig := aws.NewConfig()
+	for key, value := range config {
+		switch key {
+		case "target_group_arn":
+			p.targetGroupARN = value
+		case "service":
+			p.service = value
+		case "region":
+			awsConfig = awsConfig.WithRegion(value)
+		default:
+			return nil, fmt.Errorf("unknown elbv2 config key %q", key)
+		}
+	}
+	if p.targetGroupARN == "" {
+		return nil, errors.New("elbv2 target_group_arn must not be empty")
+	}
+	if p.service == "" {
+		return nil, errors.New("elbv2 service must not be empty")
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	p.api = elbv2.New(sess)
+	return p, nil
+}
+
+// Sync implements the Provider interface.
+func (p *elbv2Provider) Sync(ctx context.Context, services []*structs.ServiceRegistration) error {
+	desired := make(map[elbv2Target]struct{})
+	for _, service := range services {
+		if service.ServiceName != p.service {
+			continue
+		}
+
+		// Target groups of type ip only accept IP addresses.
+		addr, err := netip.ParseAddr(service.Address)
+		if err != nil {
+			p.logger.Warn("skipping service without an IP address",
+				"service_id", service.ID, "address", service.Address)
+			continue
+		}
+		desired[elbv2Target{ip: addr.Unmap().String(), port: int64(service.Port)}] = struct{}{}
+	}
+
+	out, err := p.api.DescribeTargetHealthWithContext(ctx, &elbv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(p.targetGroupARN),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe targets of %q: %w", p.targetGroupARN, err)
+	}
+
+	// Draining targets are being deregistered, so they're registered again
+	// if they're still desired.
+	existing := make(map[elbv2Target]struct{}, len(out.TargetHealthDescriptions))
+	for _, desc := range out.TargetHealthDescriptions {
+		if desc.Target == nil {
+			continue
+		}
+		if desc.TargetHealth != nil &&
+			aws.StringValue(desc.TargetHealth.State) == elbv2.TargetHealthStateEnumDraining {
+			continue
+		}
+		target := elbv2Target{ip: aws.StringValue(desc.Target.Id), port: aws.Int64Value(desc.Target.Port)}
+		existing[target] = struct{}{}
+	}
+
+	var register, deregister []*elbv2.TargetDescription
+	for target := range desired {
+		if _, ok := existing[target]; !ok {
+			register = append(register, target.description())
+		}
+	}
+	for target := range existing {
+		if _, ok := desired[target]; !ok {
+			deregister = append(deregister, target.description())
+		}
+	}
+
+	if len(register) > 0 {
+		_, err := p.api.RegisterTargetsWithContext(ctx, &elbv2.RegisterTargetsInput{
+			TargetGroupArn: aws.String(p.targetGroupARN),
+			Targets:        register,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register targets of %q: %w", p.targetGroupARN, err)
+		}
+		p.logger.Debug("registered targets", "targets", len(register))
+	}
+	if len(deregister) > 0 {
+		_, err := p.api.DeregisterTargetsWithContext(ctx, &elbv2.DeregisterTargetsInput{
+			TargetGroupArn: aws.String(p.targetGroupARN),
+			Targets:        deregister,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to deregister targets of %q: %w", p.targetGroupARN, err)
+		}
+		p.logger.Debug("deregistered targets", "targets", len(deregister))
+	}
+
+	return nil
+}
+
+func (t elbv2Target) description() *elbv2.TargetDescription {
+	return &elbv2.TargetDescription{
+		Id:   aws.String(t.ip),
+		Port: aws.Int64(t.port),
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+// fakeELBv2 is an in-memory target group.
+type fakeELBv2 struct {
+	targets     map[elbv2Target]string
+	registers   int
+	deregisters int
+}
+
+func (f *fakeELBv2) DescribeTargetHealthWithContext(aws.Context, *elbv2.DescribeTargetHealthInput,
+	...request.Option) (*elbv2.DescribeTargetHealthOutput, error) {
+	out := &elbv2.DescribeTargetHealthOutput{}
+	for target, state := range f.targets {
+		out.TargetHealthDescriptions = append(out.TargetHealthDescriptions, &elbv2.TargetHealthDescription{
+			Target:       target.description(),
+			TargetHealth: &elbv2.TargetHealth{State: aws.String(state)},
+		})
+	}
+	return out, nil
+}
+
+func (f *fakeELBv2) RegisterTargetsWithContext(_ aws.Context, in *elbv2.RegisterTargetsInput,
+	_ ...request.Option) (*elbv2.RegisterTargetsOutput, error) {
+	for _, t := range in.Targets {
+		f.targets[elbv2Target{ip: aws.StringValue(t.Id), port: aws.Int64Value(t.Port)}] = elbv2.TargetHealthStateEnumInitial
+		f.registers++
+	}
+	return &elbv2.RegisterTargetsOutput{}, nil
+}
+
+func (f *fakeELBv2) DeregisterTargetsWithContext(_ aws.Context, in *elbv2.DeregisterTargetsInput,
+	_ ...request.Option) (*elbv2.DeregisterTargetsOutput, error) {
+	for _, t := range in.Targets {
+		f.targets[elbv2Target{ip: aws.StringValue(t.Id), port: aws.Int64Value(t.Port)}] = elbv2.TargetHealthStateEnumDraining
+		f.deregisters++
+	}
+	return &elbv2.DeregisterTargetsOutput{}, nil
+}
+
+func TestELBv2Provider_Sync(t *testing.T) {
+	ci.Parallel(t)
+
+	api := &fakeELBv2{targets: map[elbv2Target]string{
+		{ip: "10.0.0.1", port: 8080}: elbv2.TargetHealthStateEnumHealthy,
+	}}
+	p := &elbv2Provider{
+		logger:         testlog.HCLogger(t),
+		api:            api,
+		targetGroupARN: "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/cache/1",
+		service:        "example-cache",
+	}
+	ctx := context.Background()
+	services := mock.ServiceRegistrations()
+
+	// Only the instances of the service are registered, and the targets
+	// registered outside of Nomad are deregistered.
+	must.NoError(t, p.Sync(ctx, services))
+	must.Eq(t, 1, api.registers)
+	must.Eq(t, 1, api.deregisters)
+	must.Eq(t, elbv2.TargetHealthStateEnumInitial, api.targets[elbv2Target{ip: "192.168.10.1", port: 23000}])
+	must.Eq(t, elbv2.TargetHealthStateEnumDraining, api.targets[elbv2Target{ip: "10.0.0.1", port: 8080}])
+
+	// Registered targets are not registered again.
+	must.NoError(t, p.Sync(ctx, services))
+	must.Eq(t, 1, api.registers)
+	must.Eq(t, 1, api.deregisters)
+
+	// Removed instances are deregistered, and draining targets are registered
+	// again if they come back.
+	must.NoError(t, p.Sync(ctx, nil))
+	must.Eq(t, 2, api.deregisters)
+	must.NoError(t, p.Sync(ctx, []*structs.ServiceRegistration{services[0]}))
+	must.Eq(t, 2, api.registers)
+	must.Eq(t, elbv2.TargetHealthStateEnumInitial, api.targets[elbv2Target{ip: "192.168.10.1", port: 23000}])
+}
+
+func TestELBv2Provider_Config(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	_, err := newELBv2Provider(logger, map[string]string{"service": "web"})
+	must.EqError(t, err, "elbv2 target_group_arn must not be empty")
+
+	_, err = newELBv2Provider(logger, map[string]string{"target_group_arn": "arn"})
+	must.EqError(t, err, "elbv2 service must not be empty")
+
+	_, err = newELBv2Provider(logger, map[string]string{"target_group_arn": "arn", "service": "web", "port": "80"})
+	must.EqError(t, err, `unknown elbv2 config key "port"`)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package servicesync exports the Nomad native services to external
+// registries, such as Consul, DNS providers, or the target groups of cloud
+// load balancers, so workloads outside of Nomad can discover them.
+package servicesync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// ProviderConsul exports the services to the catalog of a Consul
+	// cluster.
+	ProviderConsul = "consul"
+
+	// ProviderRoute53 exports the services as DNS records of an AWS Route 53
+	// hosted zone.
+	ProviderRoute53 = "route53"
+
+	// ProviderELBv2 exports the instances of a service as the targets of an
+	// AWS Elastic Load Balancing target group.
+	ProviderELBv2 = "elbv2"
+)
+
+// Provider is an external registry the Nomad services are exported to.
+type Provider interface {
+	// Sync reconciles the registry with the given services: the services
+	// missing from the registry are registered, the changed ones are
+	// updated, and the ones the provider registered before that are no
+	// longer given are deregistered. Providers must only modify the entries
+	// they own, and Sync must be safe to call again after a failure.
+	Sync(ctx context.Context, services []*structs.ServiceRegistration) error
+}
+
+// ProviderFactory returns a provider configured with the config block of a
+// service sync.
+type ProviderFactory func(logger log.Logger, config map[string]string) (Provider, error)
+
+var (
+	providersLock sync.RWMutex
+	providers     = map[string]ProviderFactory{
+		ProviderConsul:  newConsulProvider,
+		ProviderRoute53: newRoute53Provider,
+		ProviderELBv2:   newELBv2Provider,
+	}
+)
+
+// RegisterProvider makes a provider available to the service syncs under the
+// given name, so registries without a built-in provider can be supported by
+// custom builds of Nomad. It must be called before the server starts, and
+// replaces any provider registered under the same name.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	providers[name] = factory
+}
+
+// Providers returns the sorted names of the available providers.
+func Providers() []string {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newProvider returns the provider with the given name.
+func newProvider(logger log.Logger, name string, config map[string]string) (Provider, error) {
+	providersLock.RLock()
+	factory, ok := providers[name]
+	providersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q: must be one of %v", name, Providers())
+	}
+	return factory(logger, config)
+}
@@ -0,0 +1,251 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// defaultRoute53TTL is the default TTL of the records, in seconds.
+	defaultRoute53TTL = 60
+
+	// route53ChangeBatchSize is the maximum number of changes sent in a
+	// single request, below the limits of Route 53.
+	route53ChangeBatchSize = 100
+)
+
+// route53API is the subset of the Route 53 API used by the provider.
+type route53API interface {
+	ListResourceRecordSetsPagesWithContext(aws.Context, *route53.ListResourceRecordSetsInput, func(*route53.ListResourceRecordSetsOutput, bool) bool, ...request.Option) error
+	ChangeResourceRecordSetsWithContext(aws.Context, *route53.ChangeResourceRecordSetsInput, ...request.Option) (*route53.ChangeResourceRecordSetsOutput, error)
+}
+
+// route53Provider exports the services as DNS records of a Route 53 hosted
+// zone. The records are created under a domain dedicated to Nomad, so the
+// provider owns all the A, AAAA, and SRV records of that domain:
+//
+//   - <service>.<namespace>.<domain> has the addresses of all the instances
+//     of the service.
+//   - _<service>._tcp.<namespace>.<domain> has an SRV record per instance,
+//     with its port.
+//   - <alloc>-<port>.<service>.<namespace>.<domain> has the address of a single
+//     instance, and is the target of its SRV record.
+type route53Provider struct {
+	logger log.Logger
+	api    route53API
+	zoneID string
+	domain string
+	ttl    int64
+}
+
+// route53RecordKey identifies a record set of the hosted zone.
+type route53RecordKey struct {
+	name  string
+	rtype string
+}
+
+// newRoute53Provider returns a Route 53 provider. The AWS credentials are
+// read from the default credentials chain.
+func newRoute53Provider(logger log.Logger, config map[string]string) (Provider, error) {
+	p := &route53Provider{
+		logger: logger,
+		ttl:    defaultRoute53TTL,
+	}
+
+	awsConfig := aws.NewConfig()
+	for key, value := range config {
+		switch key {
+		case "zone_id":
+			p.zoneID = value
+		case "domain":
+			p.domain = strings.ToLower(strings.Trim(value, "."))
+		case "ttl":
+			ttl, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || ttl < 0 {
+				return nil, fmt.Errorf("invalid route53 ttl %q: must be a number of seconds", value)
+			}
+			p.ttl = ttl
+		case "region":
+			awsConfig = awsConfig.WithRegion(value)
+		default:
+			return nil, fmt.Errorf("unknown route53 config key %q", key)
+		}
+	}
+	if p.zoneID == "" {
+		return nil, errors.New("route53 zone_id must not be empty")
+	}
+	if p.domain == "" {
+		return nil, errors.New("route53 domain must not be empty")
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	p.api = route53.New(sess)
+	return p, nil
+}
+
+// Sync implements the Provider interface.
+func (p *route53Provider) Sync(ctx context.Context, services []*structs.ServiceRegistration) error {
+	desired := p.desiredRecords(services)
+
+	existing := make(map[route53RecordKey]*route53.ResourceRecordSet)
+	err := p.api.ListResourceRecordSetsPagesWithContext(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId: aws.String(p.zoneID),
+	}, func(out *route53.ListResourceRecordSetsOutput, _ bool) bool {
+		for _, rs := range out.ResourceRecordSets {
+			key := route53RecordKey{
+				name:  strings.ToLower(aws.StringValue(rs.Name)),
+				rtype: aws.StringValue(rs.Type),
+			}
+			if p.owns(key) {
+				existing[key] = rs
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list records of zone %q: %w", p.zoneID, err)
+	}
+
+	var changes []*route53.Change
+	for key, values := range desired {
+		if rs, ok := existing[key]; ok && aws.Int64Value(rs.TTL) == p.ttl && slices.Equal(recordValues(rs), values) {
+			continue
+		}
+
+		rs := &route53.ResourceRecordSet{
+			Name: aws.String(key.name),
+			Type: aws.String(key.rtype),
+			TTL:  aws.Int64(p.ttl),
+		}
+		for _, value := range values {
+			rs.ResourceRecords = append(rs.ResourceRecords, &route53.ResourceRecord{Value: aws.String(value)})
+		}
+		changes = append(changes, &route53.Change{
+			Action:            aws.String(route53.ChangeActionUpsert),
+			ResourceRecordSet: rs,
+		})
+	}
+	for key, rs := range existing {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		changes = append(changes, &route53.Change{
+			Action:            aws.String(route53.ChangeActionDelete),
+			ResourceRecordSet: rs,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i].ResourceRecordSet, changes[j].ResourceRecordSet
+		if aws.StringValue(a.Name) != aws.StringValue(b.Name) {
+			return aws.StringValue(a.Name) < aws.StringValue(b.Name)
+		}
+		return aws.StringValue(a.Type) < aws.StringValue(b.Type)
+	})
+
+	for len(changes) > 0 {
+		batch := changes[:min(len(changes), route53ChangeBatchSize)]
+		changes = changes[len(batch):]
+
+		_, err := p.api.ChangeResourceRecordSetsWithContext(ctx, &route53.ChangeResourceRecordSetsInput{
+			HostedZoneId: aws.String(p.zoneID),
+			ChangeBatch: &route53.ChangeBatch{
+				Comment: aws.String("nomad service sync"),
+				Changes: batch,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update records of zone %q: %w", p.zoneID, err)
+		}
+		p.logger.Debug("updated records", "changes", len(batch))
+	}
+
+	return nil
+}
+
+// desiredRecords returns the sorted values of the records of the services.
+func (p *route53Provider) desiredRecords(services []*structs.ServiceRegistration) map[route53RecordKey][]string {
+	records := make(map[route53RecordKey][]string)
+	add := func(name, rtype, value string) {
+		key := route53RecordKey{name: name, rtype: rtype}
+		if !slices.Contains(records[key], value) {
+			records[key] = append(records[key], value)
+		}
+	}
+
+	for _, service := range services {
+		base := strings.ToLower(fmt.Sprintf("%s.%s.%s.", service.ServiceName, service.Namespace, p.domain))
+		srvName := strings.ToLower(fmt.Sprintf("_%s._tcp.%s.%s.", service.ServiceName, service.Namespace, p.domain))
+
+		addr, err := netip.ParseAddr(service.Address)
+		if err != nil {
+			// The address is a hostname, so it's the target of the SRV
+			// record and doesn't get address records.
+			target := strings.ToLower(strings.TrimSuffix(service.Address, ".") + ".")
+			add(srvName, route53.RRTypeSrv, fmt.Sprintf("1 1 %d %s", service.Port, target))
+			continue
+		}
+
+		rtype := route53.RRTypeA
+		if addr.Is6() && !addr.Is4In6() {
+			rtype = route53.RRTypeAaaa
+		}
+		instance := fmt.Sprintf("%s-%d.%s", shortAllocID(service.AllocID), service.Port, base)
+		add(base, rtype, addr.Unmap().String())
+		add(instance, rtype, addr.Unmap().String())
+		add(srvName, route53.RRTypeSrv, fmt.Sprintf("1 1 %d %s", service.Port, instance))
+	}
+
+	for _, values := range records {
+		sort.Strings(values)
+	}
+	return records
+}
+
+// owns returns true if the record set is managed by the provider.
+func (p *route53Provider) owns(key route53RecordKey) bool {
+	switch key.rtype {
+	case route53.RRTypeA, route53.RRTypeAaaa, route53.RRTypeSrv:
+		return strings.HasSuffix(key.name, "."+p.domain+".")
+	default:
+		return false
+	}
+}
+
+// recordValues returns the sorted values of a record set.
+func recordValues(rs *route53.ResourceRecordSet) []string {
+	values := make([]string, 0, len(rs.ResourceRecords))
+	for _, r := range rs.ResourceRecords {
+		values = append(values, aws.StringValue(r.Value))
+	}
+	sort.Strings(values)
+	return values
+}
+
+// shortAllocID returns the short form of an allocation ID, used in the names
+// of the records of the instances.
+func shortAllocID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+// fakeRoute53 is an in-memory hosted zone.
+type fakeRoute53 struct {
+	records map[route53RecordKey]*route53.ResourceRecordSet
+	changes int
+}
+
+func (f *fakeRoute53) ListResourceRecordSetsPagesWithContext(_ aws.Context, _ *route53.ListResourceRecordSetsInput,
+	fn func(*route53.ListResourceRecordSetsOutput, bool) bool, _ ...request.Option) error {
+	out := &route53.ListResourceRecordSetsOutput{}
+	for _, rs := range f.records {
+		out.ResourceRecordSets = append(out.ResourceRecordSets, rs)
+	}
+	fn(out, true)
+	return nil
+}
+
+func (f *fakeRoute53) ChangeResourceRecordSetsWithContext(_ aws.Context, in *route53.ChangeResourceRecordSetsInput,
+	_ ...request.Option) (*route53.ChangeResourceRecordSetsOutput, error) {
+	for _, change := range in.ChangeBatch.Changes {
+		rs := change.ResourceRecordSet
+		key := route53RecordKey{name: aws.StringValue(rs.Name), rtype: aws.StringValue(rs.Type)}
+		switch aws.StringValue(change.Action) {
+		case route53.ChangeActionUpsert:
+			f.records[key] = rs
+		case route53.ChangeActionDelete:
+			delete(f.records, key)
+		}
+		f.changes++
+	}
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func (f *fakeRoute53) values(name, rtype string) []string {
+	rs, ok := f.records[route53RecordKey{name: name, rtype: rtype}]
+	if !ok {
+		return nil
+	}
+	values := recordValues(rs)
+	sort.Strings(values)
+	return values
+}
+
+func TestRoute53Provider_Sync(t *testing.T) {
+	ci.Parallel(t)
+
+	api := &fakeRoute53{records: map[route53RecordKey]*route53.ResourceRecordSet{
+		// Records outside of the domain are not managed by the provider.
+		{name: "www.example.com.", rtype: route53.RRTypeA}: {
+			Name:            aws.String("www.example.com."),
+			Type:            aws.String(route53.RRTypeA),
+			ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("10.0.0.1")}},
+		},
+	}}
+	p := &route53Provider{
+		logger: testlog.HCLogger(t),
+		api:    api,
+		zoneID: "Z123",
+		domain: "nomad.example.com",
+		ttl:    defaultRoute53TTL,
+	}
+	ctx := context.Background()
+
+	services := mock.ServiceRegistrations()
+	second := services[0].Copy()
+	second.ID = "_nomad-task-second"
+	second.AllocID = "a1b2c3d4-0000-0000-0000-000000000000"
+	second.Address = "2001:db8::1"
+	second.Port = 24000
+	hostname := services[0].Copy()
+	hostname.ID = "_nomad-task-hostname"
+	hostname.Address = "cache.internal"
+	services = append(services, second, hostname)
+
+	must.NoError(t, p.Sync(ctx, services))
+	must.Eq(t, []string{"192.168.10.1"},
+		api.values("example-cache.default.nomad.example.com.", route53.RRTypeA))
+	must.Eq(t, []string{"2001:db8::1"},
+		api.values("example-cache.default.nomad.example.com.", route53.RRTypeAaaa))
+	must.Eq(t, []string{"192.168.10.1"},
+		api.values("2873cf75-23000.example-cache.default.nomad.example.com.", route53.RRTypeA))
+	must.Eq(t, []string{
+		"1 1 23000 2873cf75-23000.example-cache.default.nomad.example.com.",
+		"1 1 23000 cache.internal.",
+		"1 1 24000 a1b2c3d4-24000.example-cache.default.nomad.example.com.",
+	}, api.values("_example-cache._tcp.default.nomad.example.com.", route53.RRTypeSrv))
+	must.Eq(t, []string{"192.168.200.200"},
+		api.values("countdash-api.platform.nomad.example.com.", route53.RRTypeA))
+
+	// Unchanged records are not updated again.
+	changes := api.changes
+	must.NoError(t, p.Sync(ctx, services))
+	must.Eq(t, changes, api.changes)
+
+	// The records of removed services are deleted, and the records outside
+	// of the domain are kept.
+	must.NoError(t, p.Sync(ctx, []*structs.ServiceRegistration{services[1]}))
+	must.MapLen(t, 4, api.records)
+	must.Nil(t, api.values("example-cache.default.nomad.example.com.", route53.RRTypeA))
+	must.Eq(t, []string{"10.0.0.1"}, api.values("www.example.com.", route53.RRTypeA))
+}
+
+func TestRoute53Provider_Config(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	_, err := newRoute53Provider(logger, map[string]string{"domain": "nomad.example.com"})
+	must.EqError(t, err, "route53 zone_id must not be empty")
+
+	_, err = newRoute53Provider(logger, map[string]string{"zone_id": "Z123"})
+	must.EqError(t, err, "route53 domain must not be empty")
+
+	_, err = newRoute53Provider(logger, map[string]string{"zone_id": "Z123", "domain": "a", "ttl": "1m"})
+	must.ErrorContains(t, err, "invalid route53 ttl")
+
+	provider, err := newRoute53Provider(logger, map[string]string{
+		"zone_id": "Z123",
+		"domain":  "Nomad.Example.com.",
+		"ttl":     "30",
+		"region":  "us-east-1",
+	})
+	must.NoError(t, err)
+	p := provider.(*route53Provider)
+	must.Eq(t, "nomad.example.com", p.domain)
+	must.Eq(t, 30, p.ttl)
+}
@@ -0,0 +1,276 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+const (
+	// syncRetryBase and syncRetryLimit bound the backoff between the retries
+	// of a failed sync.
+	syncRetryBase  = time.Second
+	syncRetryLimit = time.Minute
+)
+
+// Syncer runs the configured service syncs, which mirror the Nomad native
+// services into external registries. The syncer should only be enabled on the
+// leader, so a single server updates the registries.
+type Syncer struct {
+	enabled bool
+	logger  log.Logger
+
+	// syncs are the configured service syncs
+	syncs []*serviceSync
+
+	// state is the state that is watched for service changes.
+	state *state.StateStore
+
+	// exitFn is used to cancel the running syncs
+	exitFn context.CancelFunc
+
+	l sync.Mutex
+}
+
+// serviceSync mirrors the services matching its filters into a provider. It
+// reconciles the provider when the services change, and every interval to
+// correct the changes made outside of Nomad.
+type serviceSync struct {
+	name       string
+	logger     log.Logger
+	provider   Provider
+	namespaces []string
+	tags       []string
+	interval   time.Duration
+}
+
+// NewSyncer returns a syncer running a service sync for each config. It
+// returns an error if a config is invalid or its provider can't be created.
+func NewSyncer(logger log.Logger, configs []*config.ServiceSyncConfig) (*Syncer, error) {
+	logger = logger.Named("service_sync")
+
+	s := &Syncer{
+		logger: logger,
+		syncs:  make([]*serviceSync, 0, len(configs)),
+	}
+
+	names := make(map[string]struct{}, len(configs))
+	for _, conf := range configs {
+		if conf.Name == "" {
+			return nil, errors.New("service sync name must not be empty")
+		}
+		if _, ok := names[conf.Name]; ok {
+			return nil, fmt.Errorf("service sync %q: duplicate name", conf.Name)
+		}
+		names[conf.Name] = struct{}{}
+
+		if conf.Provider == "" {
+			return nil, fmt.Errorf("service sync %q: provider must not be empty", conf.Name)
+		}
+		if conf.Interval < 0 {
+			return nil, fmt.Errorf("service sync %q: interval must not be negative", conf.Name)
+		}
+
+		syncLogger := logger.With("sync", conf.Name, "provider", conf.Provider)
+		provider, err := newProvider(syncLogger, conf.Provider, conf.Config)
+		if err != nil {
+			return nil, fmt.Errorf("service sync %q: %w", conf.Name, err)
+		}
+
+		interval := conf.Interval
+		if interval == 0 {
+			interval = config.DefaultServiceSyncInterval
+		}
+
+		s.syncs = append(s.syncs, &serviceSync{
+			name:       conf.Name,
+			logger:     syncLogger,
+			provider:   provider,
+			namespaces: slices.Clone(conf.Namespaces),
+			tags:       slices.Clone(conf.Tags),
+			interval:   interval,
+		})
+	}
+
+	return s, nil
+}
+
+// SetEnabled is used to control if the syncer is enabled. The syncer should
+// only be enabled on the active leader. When being enabled the state is
+// passed in as it is no longer valid once a leader election has taken place.
+func (s *Syncer) SetEnabled(enabled bool, state *state.StateStore) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.enabled = enabled
+	if state != nil {
+		s.state = state
+	}
+
+	// Stop the running syncs
+	if s.exitFn != nil {
+		s.exitFn()
+		s.exitFn = nil
+	}
+
+	// Launch the syncs against the current state
+	if enabled {
+		var ctx context.Context
+		ctx, s.exitFn = context.WithCancel(context.Background())
+		for _, ss := range s.syncs {
+			go ss.run(ctx, s.state)
+		}
+	}
+}
+
+// run is the long lived go-routine that reconciles the provider with the
+// services until the context is canceled.
+func (ss *serviceSync) run(ctx context.Context, store *state.StateStore) {
+	ss.logger.Debug("starting service sync")
+	defer ss.logger.Debug("stopped service sync")
+
+	minIndex := uint64(0)
+	var failures uint64
+	for {
+		// Wait for the services to change, or for the interval to go by to
+		// reconcile the provider anyway.
+		queryCtx, cancel := context.WithTimeout(ctx, ss.interval)
+		services, index, err := ss.getServices(queryCtx, store, minIndex)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			services, index, err = ss.getServices(ctx, store, 0)
+		}
+		if err != nil {
+			ss.logger.Error("failed to retrieve services", "error", err)
+			failures++
+			if !ss.wait(ctx, helper.Backoff(syncRetryBase, syncRetryLimit, failures)) {
+				return
+			}
+			continue
+		}
+
+		if err := ss.sync(ctx, services); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			backoff := helper.Backoff(syncRetryBase, syncRetryLimit, failures)
+			ss.logger.Error("failed to sync services", "error", err, "retry", backoff)
+
+			// Reset the index so the next query returns immediately and
+			// the sync is retried after the backoff.
+			minIndex = 0
+			if !ss.wait(ctx, backoff) {
+				return
+			}
+			continue
+		}
+
+		failures = 0
+		minIndex = index
+	}
+}
+
+// sync reconciles the provider with the exported services.
+func (ss *serviceSync) sync(ctx context.Context, services []*structs.ServiceRegistration) error {
+	labels := []metrics.Label{{Name: "sync", Value: ss.name}}
+	defer metrics.MeasureSinceWithLabels([]string{"nomad", "service_sync", "sync"}, time.Now(), labels)
+
+	exported := make([]*structs.ServiceRegistration, 0, len(services))
+	for _, service := range services {
+		if ss.exports(service) {
+			exported = append(exported, service)
+		}
+	}
+	metrics.SetGaugeWithLabels([]string{"nomad", "service_sync", "services"}, float32(len(exported)), labels)
+
+	ss.logger.Trace("syncing services", "services", len(exported))
+	if err := ss.provider.Sync(ctx, exported); err != nil {
+		metrics.IncrCounterWithLabels([]string{"nomad", "service_sync", "errors"}, 1, labels)
+		return err
+	}
+	return nil
+}
+
+// exports returns true if the service matches the filters of the sync.
+func (ss *serviceSync) exports(service *structs.ServiceRegistration) bool {
+	if len(ss.namespaces) != 0 && !slices.Contains(ss.namespaces, service.Namespace) {
+		return false
+	}
+	if len(ss.tags) == 0 {
+		return true
+	}
+	for _, tag := range service.Tags {
+		if slices.Contains(ss.tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// wait waits for the given duration and returns false if the context is
+// canceled first.
+func (ss *serviceSync) wait(ctx context.Context, d time.Duration) bool {
+	timer, stop := helper.NewSafeTimer(d)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// getServices retrieves all the services, blocking until the index of the
+// services table is greater than minIndex.
+func (ss *serviceSync) getServices(ctx context.Context, store *state.StateStore, minIndex uint64) ([]*structs.ServiceRegistration, uint64, error) {
+	resp, index, err := store.BlockingQuery(getServicesImpl, minIndex, ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.([]*structs.ServiceRegistration), index, nil
+}
+
+// getServicesImpl retrieves all the services from the passed state store,
+// sorted by ID.
+func getServicesImpl(ws memdb.WatchSet, store *state.StateStore) (interface{}, uint64, error) {
+	iter, err := store.GetServiceRegistrations(ws)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var services []*structs.ServiceRegistration
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		services = append(services, raw.(*structs.ServiceRegistration))
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].ID < services[j].ID })
+
+	// Use the last index that affected the services table
+	index, err := store.Index(state.TableServiceRegistrations)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Make sure the index is greater than 0 so the blocking queries block
+	// until the first service is registered.
+	return services, max(index, 1), nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package servicesync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+// mockProvider records the services it's synced with.
+type mockProvider struct {
+	l        sync.Mutex
+	synced   [][]*structs.ServiceRegistration
+	failures int
+}
+
+func (m *mockProvider) Sync(_ context.Context, services []*structs.ServiceRegistration) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("sync failed")
+	}
+	m.synced = append(m.synced, services)
+	return nil
+}
+
+// last returns the IDs of the services of the last sync.
+func (m *mockProvider) last() ([]string, int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	if len(m.synced) == 0 {
+		return nil, 0
+	}
+	var ids []string
+	for _, service := range m.synced[len(m.synced)-1] {
+		ids = append(ids, service.ID)
+	}
+	return ids, len(m.synced)
+}
+
+func testSyncer(t *testing.T, conf *config.ServiceSyncConfig) (*Syncer, *mockProvider) {
+	provider := &mockProvider{}
+	RegisterProvider(t.Name(), func(log.Logger, map[string]string) (Provider, error) {
+		return provider, nil
+	})
+
+	conf.Name = "test"
+	conf.Provider = t.Name()
+	syncer, err := NewSyncer(testlog.HCLogger(t), []*config.ServiceSyncConfig{conf})
+	must.NoError(t, err)
+	return syncer, provider
+}
+
+func waitForSync(t *testing.T, provider *mockProvider, expected []string) {
+	t.Helper()
+	must.Wait(t, wait.InitialSuccess(
+		wait.ErrorFunc(func() error {
+			ids, _ := provider.last()
+			if len(ids) != len(expected) {
+				return errors.New("services not synced yet")
+			}
+			for i := range ids {
+				if ids[i] != expected[i] {
+					return errors.New("services not synced yet")
+				}
+			}
+			return nil
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	ci.Parallel(t)
+
+	store := state.TestStateStore(t)
+	services := mock.ServiceRegistrations()
+	must.NoError(t, store.UpsertServiceRegistrations(structs.MsgTypeTestSetup, 10, services))
+
+	syncer, provider := testSyncer(t, &config.ServiceSyncConfig{})
+	syncer.SetEnabled(true, store)
+	t.Cleanup(func() { syncer.SetEnabled(false, nil) })
+
+	// The services are synced when the syncer starts.
+	waitForSync(t, provider, []string{services[0].ID, services[1].ID})
+
+	// Deleted services are removed from the provider.
+	must.NoError(t, store.DeleteServiceRegistrationByID(
+		structs.MsgTypeTestSetup, 20, services[1].Namespace, services[1].ID))
+	waitForSync(t, provider, []string{services[0].ID})
+
+	// The syncs stop once the syncer is disabled.
+	syncer.SetEnabled(false, nil)
+	_, syncs := provider.last()
+	must.NoError(t, store.DeleteServiceRegistrationByID(
+		structs.MsgTypeTestSetup, 30, services[0].Namespace, services[0].ID))
+	time.Sleep(50 * time.Millisecond)
+	_, syncsAfter := provider.last()
+	must.Eq(t, syncs, syncsAfter)
+}
+
+func TestSyncer_Filters(t *testing.T) {
+	ci.Parallel(t)
+
+	store := state.TestStateStore(t)
+	services := mock.ServiceRegistrations()
+	extra := services[1].Copy()
+	extra.ID = "_nomad-task-extra"
+	extra.Tags = []string{"public"}
+	services = append(services, extra)
+	must.NoError(t, store.UpsertServiceRegistrations(structs.MsgTypeTestSetup, 10, services))
+
+	syncer, provider := testSyncer(t, &config.ServiceSyncConfig{
+		Namespaces: []string{"platform"},
+		Tags:       []string{"public"},
+	})
+	syncer.SetEnabled(true, store)
+	t.Cleanup(func() { syncer.SetEnabled(false, nil) })
+
+	waitForSync(t, provider, []string{extra.ID})
+}
+
+func TestSyncer_Retry(t *testing.T) {
+	ci.Parallel(t)
+
+	store := state.TestStateStore(t)
+	services := mock.ServiceRegistrations()
+	must.NoError(t, store.UpsertServiceRegistrations(structs.MsgTypeTestSetup, 10, services))
+
+	syncer, provider := testSyncer(t, &config.ServiceSyncConfig{})
+	provider.failures = 1
+	syncer.SetEnabled(true, store)
+	t.Cleanup(func() { syncer.SetEnabled(false, nil) })
+
+	// The failed sync is retried after the backoff.
+	waitForSync(t, provider, []string{services[0].ID, services[1].ID})
+}
+
+func TestSyncer_Interval(t *testing.T) {
+	ci.Parallel(t)
+
+	store := state.TestStateStore(t)
+	services := mock.ServiceRegistrations()
+	must.NoError(t, store.UpsertServiceRegistrations(structs.MsgTypeTestSetup, 10, services))
+
+	syncer, provider := testSyncer(t, &config.ServiceSyncConfig{
+		Interval: 10 * time.Millisecond,
+	})
+	syncer.SetEnabled(true, store)
+	t.Cleanup(func() { syncer.SetEnabled(false, nil) })
+
+	// The provider is reconciled periodically even if the services don't
+	// change.
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			_, syncs := provider.last()
+			return syncs >= 3
+		}),
+		wait.Timeout(5*time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+}
+
+func TestNewSyncer_Invalid(t *testing.T) {
+	ci.Parallel(t)
+
+	logger := testlog.HCLogger(t)
+
+	_, err := NewSyncer(logger, []*config.ServiceSyncConfig{{Provider: ProviderConsul}})
+	must.EqError(t, err, "service sync name must not be empty")
+
+	_, err = NewSyncer(logger, []*config.ServiceSyncConfig{{Name: "foo"}})
+	must.EqError(t, err, `service sync "foo": provider must not be empty`)
+
+	_, err = NewSyncer(logger, []*config.ServiceSyncConfig{{Name: "foo", Provider: "nope"}})
+	must.ErrorContains(t, err, `service sync "foo": unknown provider "nope"`)
+
+	_, err = NewSyncer(logger, []*config.ServiceSyncConfig{
+		{Name: "foo", Provider: ProviderConsul},
+		{Name: "foo", Provider: ProviderConsul},
+	})
+	must.EqError(t, err, `service sync "foo": duplicate name`)
+
+	_, err = NewSyncer(logger, []*config.ServiceSyncConfig{{
+		Name:     "foo",
+		Provider: ProviderConsul,
+		Config:   map[string]string{"nope": "nope"},
+	}})
+	must.EqError(t, err, `service sync "foo": unknown consul config key "nope"`)
+
+	syncer, err := NewSyncer(logger, nil)
+	must.NoError(t, err)
+	syncer.SetEnabled(true, state.TestStateStore(t))
+	syncer.SetEnabled(false, nil)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// changeFeedFromChanges processes the changes of a transaction into the events
+// of the other topics followed by the events of the change feed.
+func changeFeedFromChanges(tx ReadTxn, changes Changes) *structs.Events {
+	events := eventsFromChanges(tx, changes)
+
+	var feed []structs.Event
+	for _, change := range changes.Changes {
+		if event, ok := changeFeedEventFromChange(change); ok {
+			event.Index = changes.Index
+			feed = append(feed, event)
+		}
+	}
+	if len(feed) == 0 {
+		return events
+	}
+
+	if events == nil {
+		events = &structs.Events{Index: changes.Index}
+	}
+	events.Events = append(events.Events, feed...)
+	return events
+}
+
+// changeFeedEventFromChange returns the change feed event of a change. Unlike
+// the events of the other topics, the change feed includes every change to
+// its tables regardless of the request that made it, so it can be used to
+// maintain an external copy of the state.
+func changeFeedEventFromChange(change memdb.Change) (structs.Event, bool) {
+	obj, eventType := change.After, structs.TypeObjectUpserted
+	if change.Deleted() {
+		obj, eventType = change.Before, structs.TypeObjectDeleted
+	}
+
+	event := structs.Event{
+		Topic:      structs.TopicChangeFeed,
+		Type:       eventType,
+		FilterKeys: []string{change.Table},
+	}
+
+	switch change.Table {
+	case "jobs":
+		job, ok := obj.(*structs.Job)
+		if !ok {
+			return structs.Event{}, false
+		}
+		event.Key, event.Namespace = job.ID, job.Namespace
+	case "allocs":
+		alloc, ok := obj.(*structs.Allocation)
+		if !ok {
+			return structs.Event{}, false
+		}
+		event.Key, event.Namespace = alloc.ID, alloc.Namespace
+
+		// remove job info to help keep size of alloc event down
+		alloc = alloc.Copy()
+		alloc.Job = nil
+		obj = alloc
+	case "evals":
+		eval, ok := obj.(*structs.Evaluation)
+		if !ok {
+			return structs.Event{}, false
+		}
+		event.Key, event.Namespace = eval.ID, eval.Namespace
+	case "deployment":
+		deployment, ok := obj.(*structs.Deployment)
+		if !ok {
+			return structs.Event{}, false
+		}
+		event.Key, event.Namespace = deployment.ID, deployment.Namespace
+	case "nodes":
+		node, ok := obj.(*structs.Node)
+		if !ok {
+			return structs.Event{}, false
+		}
+		event.Key = node.ID
+		obj = node.Sanitize()
+	case TableNodePools:
+		pool, ok := obj.(*structs.NodePool)
+		if !ok {
+			return structs.Event{}, false
+		}
+		event.Key = pool.Name
+	case TableNamespaces:
+		ns, ok := obj.(*structs.Namespace)
+		if !ok {
+			return structs.Event{}, false
+		}
+		event.Key = ns.Name
+	default:
+		return structs.Event{}, false
+	}
+
+	event.Payload = &structs.ChangeFeedEvent{
+		Table:  change.Table,
+		Object: obj,
+	}
+	return event, true
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"testing"
+
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestChangeFeedFromChanges(t *testing.T) {
+	ci.Parallel(t)
+	s := TestStateStoreCfg(t, TestStateStorePublisher(t))
+	defer s.StopEventBroker()
+
+	job := mock.Job()
+	alloc := mock.Alloc()
+	node := mock.Node()
+	eval := mock.Eval()
+
+	// the change feed events follow the other events
+	changes := Changes{
+		Index:   100,
+		MsgType: structs.JobRegisterRequestType,
+		Changes: memdb.Changes{
+			{Table: "job_version", After: job},
+			{Table: "jobs", After: job},
+		},
+	}
+	out := changeFeedFromChanges(s.db.ReadTxn(), changes)
+	must.Len(t, 2, out.Events)
+	must.Eq(t, structs.TopicJob, out.Events[0].Topic)
+
+	event := out.Events[1]
+	must.Eq(t, structs.TopicChangeFeed, event.Topic)
+	must.Eq(t, structs.TypeObjectUpserted, event.Type)
+	must.Eq(t, job.ID, event.Key)
+	must.Eq(t, job.Namespace, event.Namespace)
+	must.Eq(t, []string{"jobs"}, event.FilterKeys)
+	must.Eq(t, uint64(100), event.Index)
+	must.Eq(t, &structs.ChangeFeedEvent{Table: "jobs", Object: job}, event.Payload.(*structs.ChangeFeedEvent))
+
+	// changes without events of the other topics, such as garbage
+	// collection, are in the change feed
+	changes = Changes{
+		Index:   101,
+		MsgType: structs.IgnoreUnknownTypeFlag,
+		Changes: memdb.Changes{
+			{Table: "evals", Before: eval},
+			{Table: "allocs", After: alloc},
+			{Table: "nodes", After: node},
+		},
+	}
+	out = changeFeedFromChanges(s.db.ReadTxn(), changes)
+	must.Len(t, 3, out.Events)
+	must.Eq(t, uint64(101), out.Index)
+
+	must.Eq(t, structs.TypeObjectDeleted, out.Events[0].Type)
+	must.Eq(t, eval.ID, out.Events[0].Key)
+
+	// allocs don't include their job and nodes are sanitized
+	allocEvent := out.Events[1].Payload.(*structs.ChangeFeedEvent)
+	must.Nil(t, allocEvent.Object.(*structs.Allocation).Job)
+	must.NotNil(t, alloc.Job)
+
+	nodeEvent := out.Events[2].Payload.(*structs.ChangeFeedEvent)
+	must.Eq(t, "", nodeEvent.Object.(*structs.Node).SecretID)
+	must.Eq(t, "", out.Events[2].Namespace)
+
+	// none of these changes are in the other topics
+	must.Nil(t, eventsFromChanges(s.db.ReadTxn(), changes))
+}
@@ -25,6 +25,8 @@ const (
 	TableACLAuthMethods       = "acl_auth_methods"
 	TableACLBindingRules      = "acl_binding_rules"
 	TableAllocs               = "allocs"
+	TableEvalTraces           = "eval_traces"
+	TableLocalVolumeClaims    = "local_volume_claims"
 )
 
 const (
@@ -75,6 +77,8 @@ func init() {
 		deploymentSchema,
 		periodicLaunchTableSchema,
 		evalTableSchema,
+		evalTraceTableSchema,
+		localVolumeClaimTableSchema,
 		allocTableSchema,
 		vaultAccessorTableSchema,
 		siTokenAccessorTableSchema,
@@ -382,9 +386,10 @@ func jobIsGCable(obj interface{}) (bool, error) {
 
 	switch j.Type {
 	// Otherwise, batch and sysbatch jobs are eligible because they complete on
-	// their own without a user stopping them.
+	// their own without a user stopping them, unless they are suspended and
+	// waiting to be resumed.
 	case structs.JobTypeBatch, structs.JobTypeSysBatch:
-		return true, nil
+		return !j.Suspended, nil
 
 	default:
 		// other job types may not be GC until stopped
@@ -535,6 +540,75 @@ func periodicLaunchTableSchema() *memdb.TableSchema {
 	}
 }
 
+// evalTraceTableSchema returns the MemDB schema for the eval traces table.
+// This table is used to store the traces recorded by schedulers when
+// evaluation tracing is enabled.
+func evalTraceTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: TableEvalTraces,
+		Indexes: map[string]*memdb.IndexSchema{
+			// id index is used for direct lookup of the trace of an
+			// evaluation by the evaluation ID.
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.UUIDFieldIndex{
+					Field: "EvalID",
+				},
+			},
+
+			// create index is used to prune the oldest traces.
+			"create": {
+				Name:         "create",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.UintFieldIndex{
+							Field: "CreateIndex",
+						},
+						&memdb.StringFieldIndex{
+							Field: "EvalID",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// localVolumeClaimTableSchema returns the MemDB schema for the local volume
+// claims table. This table is used to store the nodes the allocations using
+// local volumes are bound to.
+func localVolumeClaimTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: TableLocalVolumeClaims,
+		Indexes: map[string]*memdb.IndexSchema{
+			// id index is used for direct lookup of the claim of an
+			// allocation name, and to list the claims of a job with a prefix.
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+						&memdb.StringFieldIndex{
+							Field: "JobID",
+						},
+						&memdb.StringFieldIndex{
+							Field: "AllocName",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 // evalTableSchema returns the MemDB schema for the eval table.
 // This table is used to store all the evaluations that are pending
 // or recently completed.
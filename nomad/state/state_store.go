@@ -101,14 +101,25 @@ type StateStoreConfig struct {
 	// EventBufferSize configures the amount of events to hold in memory
 	EventBufferSize int64
 
+	// EnableChangeFeed is used to publish the change feed events along with
+	// the other events, if the event publisher is enabled
+	EnableChangeFeed bool
+
 	// JobTrackedVersions is the number of historic job versions that are kept.
 	JobTrackedVersions int
+
+	// JobTrackedScalingEvents is the number of scaling events that are kept
+	// for a single task group.
+	JobTrackedScalingEvents int
 }
 
 func (c *StateStoreConfig) Validate() error {
 	if c.JobTrackedVersions <= 0 {
 		return fmt.Errorf("JobTrackedVersions must be positive; got: %d", c.JobTrackedVersions)
 	}
+	if c.JobTrackedScalingEvents <= 0 {
+		return fmt.Errorf("JobTrackedScalingEvents must be positive; got: %d", c.JobTrackedScalingEvents)
+	}
 	return nil
 }
 
@@ -174,7 +185,11 @@ func NewStateStore(config *StateStoreConfig) (*StateStore, error) {
 		if err != nil {
 			return nil, fmt.Errorf("creating state store event broker %w", err)
 		}
-		s.db = NewChangeTrackerDB(db, broker, eventsFromChanges)
+		processChanges := eventsFromChanges
+		if config.EnableChangeFeed {
+			processChanges = changeFeedFromChanges
+		}
+		s.db = NewChangeTrackerDB(db, broker, processChanges)
 	} else {
 		s.db = NewChangeTrackerDB(db, nil, noOpProcessChanges)
 	}
@@ -207,6 +222,29 @@ func (s *StateStore) EventBroker() (*stream.EventBroker, error) {
 	return s.db.publisher, nil
 }
 
+// PublishWorkloadIdentityEvents publishes the lifecycle events of workload
+// identities on the event stream, if the event broker is enabled. They aren't
+// stored in the state store.
+func (s *StateStore) PublishWorkloadIdentityEvents(index uint64, idEvents []*structs.WorkloadIdentityEvent) {
+	if s.db.publisher == nil || len(idEvents) == 0 {
+		return
+	}
+
+	events := make([]structs.Event, 0, len(idEvents))
+	for _, e := range idEvents {
+		events = append(events, structs.Event{
+			Topic:      structs.TopicWorkloadIdentity,
+			Type:       e.Type,
+			Key:        e.AllocID,
+			Namespace:  e.Namespace,
+			FilterKeys: []string{e.JobID, e.IdentityName},
+			Index:      index,
+			Payload:    &structs.WorkloadIdentityStreamEvent{WorkloadIdentity: e},
+		})
+	}
+	s.db.publisher.Publish(&structs.Events{Index: index, Events: events})
+}
+
 // namespaceInit ensures the default namespace exists.
 func (s *StateStore) namespaceInit() error {
 	// Create the default namespace. This is safe to do every time we create the
@@ -834,7 +872,8 @@ func (s *StateStore) DeleteDeployment(index uint64, deploymentIDs []string) erro
 }
 
 // UpsertScalingEvent is used to insert a new scaling event.
-// Only the most recent JobTrackedScalingEvents will be kept.
+// Only the most recent JobTrackedScalingEvents of the configuration will be
+// kept.
 func (s *StateStore) UpsertScalingEvent(index uint64, req *structs.ScalingEventRequest) error {
 	txn := s.db.WriteTxn(index)
 	defer txn.Abort()
@@ -866,8 +905,8 @@ func (s *StateStore) UpsertScalingEvent(index uint64, req *structs.ScalingEventR
 		events...,
 	)
 	// Truncate older events
-	if len(events) > structs.JobTrackedScalingEvents {
-		events = events[0:structs.JobTrackedScalingEvents]
+	if len(events) > s.config.JobTrackedScalingEvents {
+		events = events[0:s.config.JobTrackedScalingEvents]
 	}
 	jobEvents.ScalingEvents[req.TaskGroup] = events
 
@@ -1052,17 +1091,25 @@ func deleteNodeTxn(txn *txn, index uint64, nodes []string) error {
 
 // UpdateNodeStatus is used to update the status of a node
 func (s *StateStore) UpdateNodeStatus(msgType structs.MessageType, index uint64, nodeID, status string, updatedAt int64, event *structs.NodeEvent) error {
+	return s.UpdateNodeStatusWithUtilization(msgType, index, nodeID, status, updatedAt, event, nil)
+}
+
+// UpdateNodeStatusWithUtilization is used to update the status of a node
+// along with the utilization it reported, which is left unchanged if nil.
+func (s *StateStore) UpdateNodeStatusWithUtilization(msgType structs.MessageType, index uint64, nodeID, status string,
+	updatedAt int64, event *structs.NodeEvent, utilization *structs.NodeUtilization) error {
 	txn := s.db.WriteTxnMsgT(msgType, index)
 	defer txn.Abort()
 
-	if err := s.updateNodeStatusTxn(txn, nodeID, status, updatedAt, event); err != nil {
+	if err := s.updateNodeStatusTxn(txn, nodeID, status, updatedAt, event, utilization); err != nil {
 		return err
 	}
 
 	return txn.Commit()
 }
 
-func (s *StateStore) updateNodeStatusTxn(txn *txn, nodeID, status string, updatedAt int64, event *structs.NodeEvent) error {
+func (s *StateStore) updateNodeStatusTxn(txn *txn, nodeID, status string, updatedAt int64,
+	event *structs.NodeEvent, utilization *structs.NodeUtilization) error {
 
 	// Lookup the node
 	existing, err := txn.First("nodes", "id", nodeID)
@@ -1086,6 +1133,9 @@ func (s *StateStore) updateNodeStatusTxn(txn *txn, nodeID, status string, update
 	// Update the status in the copy
 	copyNode.Status = status
 	copyNode.ModifyIndex = txn.Index
+	if utilization != nil {
+		copyNode.Utilization = utilization.Copy()
+	}
 
 	// Update last missed heartbeat if the node became unresponsive or reset it
 	// zero if the node became ready.
@@ -1931,6 +1981,11 @@ func (s *StateStore) DeleteJobTxn(index uint64, namespace, jobID string, txn Txn
 		return fmt.Errorf("index update failed: %v", err)
 	}
 
+	// Delete the local volume claims
+	if err := s.deleteLocalVolumeClaimsByJobTxn(index, txn, namespace, jobID); err != nil {
+		return fmt.Errorf("deleting job local volume claims failed: %v", err)
+	}
+
 	return nil
 }
 
@@ -3235,6 +3290,12 @@ func (s *StateStore) nestedUpsertEval(txn *txn, index uint64, eval *structs.Eval
 			}
 		}
 
+		// Add the warnings raised while scheduling the evaluation
+		if warnings, added := structs.MergeJobWarnings(js.Warnings, eval.Warnings...); added {
+			js.Warnings = warnings
+			hasSummaryChanged = true
+		}
+
 		// Insert the job summary
 		if hasSummaryChanged {
 			js.ModifyIndex = index
@@ -3368,6 +3429,9 @@ func (s *StateStore) DeleteEvalsByFilter(index uint64, filterExpr string, pageTo
 		if err := txn.Delete("evals", eval); err != nil {
 			return fmt.Errorf("eval delete failed: %v", err)
 		}
+		if _, err := s.deleteEvalTraceTxn(txn, eval.ID); err != nil {
+			return err
+		}
 		pageCount++
 	}
 
@@ -3475,10 +3539,10 @@ func (s *StateStore) DeleteEval(index uint64, evals, allocs []string, userInitia
 
 	jobs := make(map[structs.NamespacedID]string, len(evals))
 
-	// evalsTableUpdated and allocsTableUpdated allow us to track whether each
-	// table has been modified. This allows us to skip updating the index table
-	// entries if we do not need to.
-	var evalsTableUpdated, allocsTableUpdated bool
+	// evalsTableUpdated, allocsTableUpdated, and tracesTableUpdated allow us
+	// to track whether each table has been modified. This allows us to skip
+	// updating the index table entries if we do not need to.
+	var evalsTableUpdated, allocsTableUpdated, tracesTableUpdated bool
 
 	for _, eval := range evals {
 		existing, err := txn.First("evals", "id", eval)
@@ -3496,6 +3560,13 @@ func (s *StateStore) DeleteEval(index uint64, evals, allocs []string, userInitia
 		// table.
 		evalsTableUpdated = true
 
+		// Delete the trace of the eval along with it
+		deleted, err := s.deleteEvalTraceTxn(txn, eval)
+		if err != nil {
+			return err
+		}
+		tracesTableUpdated = tracesTableUpdated || deleted
+
 		eval := existing.(*structs.Evaluation)
 
 		tuple := structs.NamespacedID{
@@ -3533,6 +3604,11 @@ func (s *StateStore) DeleteEval(index uint64, evals, allocs []string, userInitia
 			return fmt.Errorf("index update failed: %v", err)
 		}
 	}
+	if tracesTableUpdated {
+		if err := txn.Insert("index", &IndexEntry{TableEvalTraces, index}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+	}
 
 	// Set the job's status
 	if err := s.setJobStatuses(index, txn, jobs, true); err != nil {
@@ -3803,6 +3879,7 @@ func (s *StateStore) nestedUpdateAllocFromClient(txn *txn, index uint64, alloc *
 	copyAlloc.ClientDescription = alloc.ClientDescription
 	copyAlloc.TaskStates = alloc.TaskStates
 	copyAlloc.NetworkStatus = alloc.NetworkStatus
+	copyAlloc.MigrationStatus = alloc.MigrationStatus
 
 	// The client can only set its deployment health and timestamp, so just take
 	// those
@@ -3978,6 +4055,10 @@ func (s *StateStore) upsertAllocsImpl(index uint64, allocs []*structs.Allocation
 			return err
 		}
 
+		if err := s.updateLocalVolumeClaimWithAlloc(index, alloc, exist, txn); err != nil {
+			return err
+		}
+
 		if err := txn.Insert("allocs", alloc); err != nil {
 			return fmt.Errorf("alloc insert failed: %v", err)
 		}
@@ -4704,6 +4785,45 @@ func (s *StateStore) UpdateJobStability(index uint64, namespace, jobID string, j
 	return txn.Commit()
 }
 
+// UpdateJobSuspension is used to suspend or resume a job, and upsert the
+// evaluation of the change if any. The version of the job is kept, so the
+// allocations it already ran aren't replaced once it's resumed. It returns
+// whether the job was updated; nothing is written if the job was already
+// suspended or resumed.
+func (s *StateStore) UpdateJobSuspension(msgType structs.MessageType, index uint64, namespace, jobID string,
+	suspended bool, eval *structs.Evaluation) (bool, error) {
+
+	txn := s.db.WriteTxnMsgT(msgType, index)
+	defer txn.Abort()
+
+	job, err := s.JobByIDTxn(nil, namespace, jobID, txn)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, fmt.Errorf("job %q not found", jobID)
+	}
+
+	// If the job is already suspended or resumed, nothing to do
+	if job.Suspended == suspended {
+		return false, nil
+	}
+
+	copy := job.Copy()
+	copy.Suspended = suspended
+	if err := s.upsertJobImpl(index, nil, copy, true, txn); err != nil {
+		return false, err
+	}
+
+	if eval != nil {
+		if err := s.nestedUpsertEval(txn, index, eval); err != nil {
+			return false, err
+		}
+	}
+
+	return true, txn.Commit()
+}
+
 // updateJobStabilityImpl updates the stability of the given job and version
 func (s *StateStore) updateJobStabilityImpl(index uint64, namespace, jobID string, jobVersion uint64, stable bool, txn *txn) error {
 	// Get the job that is referenced
@@ -5426,6 +5546,33 @@ func (s *StateStore) updateSummaryWithJob(index uint64, job *structs.Job,
 	return nil
 }
 
+// SetJobWarnings replaces the warnings of the summary of the job with the
+// warnings raised when it was registered. The warnings raised when the previous
+// versions of the job were scheduled are dropped.
+func (s *StateStore) SetJobWarnings(index uint64, namespace, jobID string, warnings []*structs.JobWarning) error {
+	txn := s.db.WriteTxn(index)
+	defer txn.Abort()
+
+	summaryRaw, err := txn.First("job_summary", "id", namespace, jobID)
+	if err != nil {
+		return fmt.Errorf("job summary lookup failed: %v", err)
+	}
+	if summaryRaw == nil {
+		return nil
+	}
+
+	summary := summaryRaw.(*structs.JobSummary).Copy()
+	summary.Warnings = warnings
+	summary.ModifyIndex = index
+	if err := txn.Insert("job_summary", summary); err != nil {
+		return fmt.Errorf("job summary insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"job_summary", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return txn.Commit()
+}
+
 // updateJobScalingPolicies upserts any scaling policies contained in the job and removes
 // any previous scaling policies that were removed from the job
 func (s *StateStore) updateJobScalingPolicies(index uint64, job *structs.Job, txn *txn) error {
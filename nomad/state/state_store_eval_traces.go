@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// UpsertEvalTrace is used to insert or update the trace of an evaluation.
+// Once the number of traces exceeds the maximum set in the scheduler
+// configuration, the oldest traces are deleted.
+func (s *StateStore) UpsertEvalTrace(msgType structs.MessageType, index uint64, trace *structs.EvalTrace) error {
+	txn := s.db.WriteTxnMsgT(msgType, index)
+	defer txn.Abort()
+
+	existing, err := txn.First(TableEvalTraces, indexID, trace.EvalID)
+	if err != nil {
+		return fmt.Errorf("eval trace lookup failed: %v", err)
+	}
+
+	trace = trace.Copy()
+	if existing != nil {
+		trace.CreateIndex = existing.(*structs.EvalTrace).CreateIndex
+	} else {
+		trace.CreateIndex = index
+	}
+	trace.ModifyIndex = index
+
+	if err := txn.Insert(TableEvalTraces, trace); err != nil {
+		return fmt.Errorf("eval trace insert failed: %v", err)
+	}
+
+	_, schedConfig, err := s.schedulerConfigTxn(txn)
+	if err != nil {
+		return err
+	}
+	var traceConfig structs.EvalTraceConfig
+	if schedConfig != nil {
+		traceConfig = schedConfig.EvalTraceConfig
+	}
+	if err := s.pruneEvalTracesTxn(txn, traceConfig.EffectiveMaxTraces()); err != nil {
+		return err
+	}
+
+	if err := txn.Insert(tableIndex, &IndexEntry{TableEvalTraces, index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	return txn.Commit()
+}
+
+// pruneEvalTracesTxn deletes the oldest traces until at most maxTraces are
+// left.
+func (s *StateStore) pruneEvalTracesTxn(txn *txn, maxTraces int) error {
+	iter, err := txn.Get(TableEvalTraces, "create")
+	if err != nil {
+		return fmt.Errorf("eval traces lookup failed: %v", err)
+	}
+
+	var traces []*structs.EvalTrace
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		traces = append(traces, raw.(*structs.EvalTrace))
+	}
+
+	for i := 0; i < len(traces)-maxTraces; i++ {
+		if err := txn.Delete(TableEvalTraces, traces[i]); err != nil {
+			return fmt.Errorf("eval trace delete failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// deleteEvalTraceTxn deletes the trace of the evaluation, if any. It returns
+// whether a trace was deleted.
+func (s *StateStore) deleteEvalTraceTxn(txn *txn, evalID string) (bool, error) {
+	existing, err := txn.First(TableEvalTraces, indexID, evalID)
+	if err != nil {
+		return false, fmt.Errorf("eval trace lookup failed: %v", err)
+	}
+	if existing == nil {
+		return false, nil
+	}
+	if err := txn.Delete(TableEvalTraces, existing); err != nil {
+		return false, fmt.Errorf("eval trace delete failed: %v", err)
+	}
+	return true, nil
+}
+
+// EvalTraceByID is used to lookup the trace of an evaluation by the
+// evaluation ID.
+func (s *StateStore) EvalTraceByID(ws memdb.WatchSet, evalID string) (*structs.EvalTrace, error) {
+	txn := s.db.ReadTxn()
+
+	watchCh, existing, err := txn.FirstWatch(TableEvalTraces, indexID, evalID)
+	if err != nil {
+		return nil, fmt.Errorf("eval trace lookup failed: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.EvalTrace), nil
+	}
+	return nil, nil
+}
+
+// EvalTraces returns an iterator over all the evaluation traces.
+func (s *StateStore) EvalTraces(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.ReadTxn()
+
+	iter, err := txn.Get(TableEvalTraces, indexID)
+	if err != nil {
+		return nil, fmt.Errorf("eval traces lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"testing"
+
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestStateStore_UpsertEvalTrace(t *testing.T) {
+	ci.Parallel(t)
+
+	state := testStateStore(t)
+
+	must.NoError(t, state.SchedulerSetConfig(1000, &structs.SchedulerConfiguration{
+		EvalTraceConfig: structs.EvalTraceConfig{
+			Enabled:   true,
+			MaxTraces: 2,
+		},
+	}))
+
+	evals := []*structs.Evaluation{mock.Eval(), mock.Eval(), mock.Eval()}
+	must.NoError(t, state.UpsertEvals(structs.MsgTypeTestSetup, 1001, evals))
+
+	// Insert a trace and update it.
+	trace := &structs.EvalTrace{
+		EvalID:    evals[0].ID,
+		Namespace: evals[0].Namespace,
+		JobID:     evals[0].JobID,
+	}
+	must.NoError(t, state.UpsertEvalTrace(structs.MsgTypeTestSetup, 1002, trace))
+
+	ws := memdb.NewWatchSet()
+	out, err := state.EvalTraceByID(ws, evals[0].ID)
+	must.NoError(t, err)
+	must.NotNil(t, out)
+	must.Eq(t, 1002, out.CreateIndex)
+	must.Eq(t, 1002, out.ModifyIndex)
+
+	trace.Placements = []*structs.EvalTracePlacement{{TaskGroup: "web"}}
+	must.NoError(t, state.UpsertEvalTrace(structs.MsgTypeTestSetup, 1003, trace))
+	must.True(t, watchFired(ws))
+
+	out, err = state.EvalTraceByID(nil, evals[0].ID)
+	must.NoError(t, err)
+	must.Eq(t, 1002, out.CreateIndex)
+	must.Eq(t, 1003, out.ModifyIndex)
+	must.Len(t, 1, out.Placements)
+
+	index, err := state.Index(TableEvalTraces)
+	must.NoError(t, err)
+	must.Eq(t, 1003, index)
+
+	// Inserting more traces than the retention limit deletes the oldest.
+	for i, eval := range evals[1:] {
+		must.NoError(t, state.UpsertEvalTrace(structs.MsgTypeTestSetup, uint64(1004+i), &structs.EvalTrace{
+			EvalID:    eval.ID,
+			Namespace: eval.Namespace,
+			JobID:     eval.JobID,
+		}))
+	}
+
+	out, err = state.EvalTraceByID(nil, evals[0].ID)
+	must.NoError(t, err)
+	must.Nil(t, out)
+
+	iter, err := state.EvalTraces(nil)
+	must.NoError(t, err)
+	var count int
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		count++
+	}
+	must.Eq(t, 2, count)
+
+	// Deleting an eval deletes its trace.
+	must.NoError(t, state.DeleteEval(1010, []string{evals[1].ID}, nil, false))
+
+	out, err = state.EvalTraceByID(nil, evals[1].ID)
+	must.NoError(t, err)
+	must.Nil(t, out)
+
+	index, err = state.Index(TableEvalTraces)
+	must.NoError(t, err)
+	must.Eq(t, 1010, index)
+}
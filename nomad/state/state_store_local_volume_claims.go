@@ -0,0 +1,165 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// updateLocalVolumeClaimWithAlloc claims the node of a new allocation that
+// requests local volumes for its name, or records the allocation in the
+// existing claim of its name.
+func (s *StateStore) updateLocalVolumeClaimWithAlloc(index uint64, alloc, existing *structs.Allocation, txn *txn) error {
+	if existing != nil || alloc.TerminalStatus() || alloc.Job == nil || alloc.Name == "" {
+		return nil
+	}
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil || !tg.HasLocalVolumes() {
+		return nil
+	}
+
+	raw, err := txn.First(TableLocalVolumeClaims, indexID, alloc.Namespace, alloc.JobID, alloc.Name)
+	if err != nil {
+		return fmt.Errorf("local volume claim lookup failed: %v", err)
+	}
+
+	var claim *structs.LocalVolumeClaim
+	if raw != nil {
+		claim = raw.(*structs.LocalVolumeClaim).Copy()
+
+		// The scheduler only places the allocation on the claimed node, but
+		// the claim could have been replaced since the plan was created.
+		if claim.NodeID != alloc.NodeID || claim.AllocID == alloc.ID {
+			return nil
+		}
+	} else {
+		claim = &structs.LocalVolumeClaim{
+			Namespace:   alloc.Namespace,
+			JobID:       alloc.JobID,
+			TaskGroup:   alloc.TaskGroup,
+			AllocName:   alloc.Name,
+			NodeID:      alloc.NodeID,
+			CreateIndex: index,
+		}
+	}
+	claim.AllocID = alloc.ID
+	claim.ModifyIndex = index
+
+	if err := txn.Insert(TableLocalVolumeClaims, claim); err != nil {
+		return fmt.Errorf("local volume claim insert failed: %v", err)
+	}
+	if err := txn.Insert(tableIndex, &IndexEntry{TableLocalVolumeClaims, index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// LocalVolumeClaim is used to lookup the local volume claim of an allocation
+// name.
+func (s *StateStore) LocalVolumeClaim(ws memdb.WatchSet, namespace, jobID, allocName string) (*structs.LocalVolumeClaim, error) {
+	txn := s.db.ReadTxn()
+
+	watchCh, existing, err := txn.FirstWatch(TableLocalVolumeClaims, indexID, namespace, jobID, allocName)
+	if err != nil {
+		return nil, fmt.Errorf("local volume claim lookup failed: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if existing != nil {
+		return existing.(*structs.LocalVolumeClaim), nil
+	}
+	return nil, nil
+}
+
+// LocalVolumeClaimsByJob returns an iterator over the local volume claims of
+// a job.
+func (s *StateStore) LocalVolumeClaimsByJob(ws memdb.WatchSet, namespace, jobID string) (memdb.ResultIterator, error) {
+	txn := s.db.ReadTxn()
+	return s.localVolumeClaimsByJobTxn(ws, txn, namespace, jobID)
+}
+
+func (s *StateStore) localVolumeClaimsByJobTxn(ws memdb.WatchSet, txn ReadTxn, namespace, jobID string) (memdb.ResultIterator, error) {
+	// The empty allocation name prefix limits the claims to the exact job ID.
+	iter, err := txn.Get(TableLocalVolumeClaims, indexID+"_prefix", namespace, jobID, "")
+	if err != nil {
+		return nil, fmt.Errorf("local volume claims lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// LocalVolumeClaims returns an iterator over all the local volume claims.
+func (s *StateStore) LocalVolumeClaims(ws memdb.WatchSet) (memdb.ResultIterator, error) {
+	txn := s.db.ReadTxn()
+
+	iter, err := txn.Get(TableLocalVolumeClaims, indexID)
+	if err != nil {
+		return nil, fmt.Errorf("local volume claims lookup failed: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	return iter, nil
+}
+
+// DeleteLocalVolumeClaims deletes the local volume claim of an allocation
+// name, or all the claims of the job if allocName is empty.
+func (s *StateStore) DeleteLocalVolumeClaims(msgType structs.MessageType, index uint64, namespace, jobID, allocName string) error {
+	txn := s.db.WriteTxnMsgT(msgType, index)
+	defer txn.Abort()
+
+	if allocName == "" {
+		if err := s.deleteLocalVolumeClaimsByJobTxn(index, txn, namespace, jobID); err != nil {
+			return err
+		}
+		return txn.Commit()
+	}
+
+	existing, err := txn.First(TableLocalVolumeClaims, indexID, namespace, jobID, allocName)
+	if err != nil {
+		return fmt.Errorf("local volume claim lookup failed: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("local volume claim not found")
+	}
+	if err := txn.Delete(TableLocalVolumeClaims, existing); err != nil {
+		return fmt.Errorf("local volume claim delete failed: %v", err)
+	}
+	if err := txn.Insert(tableIndex, &IndexEntry{TableLocalVolumeClaims, index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	return txn.Commit()
+}
+
+// deleteLocalVolumeClaimsByJobTxn deletes all the local volume claims of a
+// job.
+func (s *StateStore) deleteLocalVolumeClaimsByJobTxn(index uint64, txn *txn, namespace, jobID string) error {
+	iter, err := s.localVolumeClaimsByJobTxn(nil, txn, namespace, jobID)
+	if err != nil {
+		return err
+	}
+
+	// Collect the claims so they aren't deleted while iterating
+	var claims []*structs.LocalVolumeClaim
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		claims = append(claims, raw.(*structs.LocalVolumeClaim))
+	}
+	if len(claims) == 0 {
+		return nil
+	}
+
+	for _, claim := range claims {
+		if err := txn.Delete(TableLocalVolumeClaims, claim); err != nil {
+			return fmt.Errorf("local volume claim delete failed: %v", err)
+		}
+	}
+	if err := txn.Insert(tableIndex, &IndexEntry{TableLocalVolumeClaims, index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"testing"
+
+	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestStateStore_LocalVolumeClaims(t *testing.T) {
+	ci.Parallel(t)
+
+	state := testStateStore(t)
+
+	job := mock.Job()
+	job.TaskGroups[0].Volumes = map[string]*structs.VolumeRequest{
+		"data": {Name: "data", Type: structs.VolumeTypeLocal, Source: "data"},
+	}
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+	newAlloc := func(name, nodeID string) *structs.Allocation {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.Namespace = job.Namespace
+		alloc.TaskGroup = job.TaskGroups[0].Name
+		alloc.Name = name
+		alloc.NodeID = nodeID
+		return alloc
+	}
+
+	// The first placement of each allocation name claims its node.
+	node1, node2 := uuid.Generate(), uuid.Generate()
+	alloc1 := newAlloc("my-job.web[0]", node1)
+	alloc2 := newAlloc("my-job.web[1]", node2)
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1001, []*structs.Allocation{alloc1, alloc2}))
+
+	ws := memdb.NewWatchSet()
+	claim, err := state.LocalVolumeClaim(ws, job.Namespace, job.ID, alloc1.Name)
+	must.NoError(t, err)
+	must.NotNil(t, claim)
+	must.Eq(t, node1, claim.NodeID)
+	must.Eq(t, alloc1.ID, claim.AllocID)
+	must.Eq(t, 1001, claim.CreateIndex)
+
+	// A replacement on the claimed node updates the claim, while one on
+	// another node doesn't replace it.
+	replacement := newAlloc(alloc1.Name, node1)
+	other := newAlloc(alloc2.Name, node1)
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1002, []*structs.Allocation{replacement, other}))
+	must.True(t, watchFired(ws))
+
+	claim, err = state.LocalVolumeClaim(nil, job.Namespace, job.ID, alloc1.Name)
+	must.NoError(t, err)
+	must.Eq(t, replacement.ID, claim.AllocID)
+	must.Eq(t, 1001, claim.CreateIndex)
+	must.Eq(t, 1002, claim.ModifyIndex)
+
+	claim, err = state.LocalVolumeClaim(nil, job.Namespace, job.ID, alloc2.Name)
+	must.NoError(t, err)
+	must.Eq(t, node2, claim.NodeID)
+	must.Eq(t, alloc2.ID, claim.AllocID)
+
+	// Claims of other jobs aren't listed with the job.
+	prefixJob := job.Copy()
+	prefixJob.ID = job.ID + "-2"
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1003, nil, prefixJob))
+	prefixAlloc := newAlloc("my-job-2.web[0]", node1)
+	prefixAlloc.Job = prefixJob
+	prefixAlloc.JobID = prefixJob.ID
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1004, []*structs.Allocation{prefixAlloc}))
+
+	iter, err := state.LocalVolumeClaimsByJob(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	var names []string
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		names = append(names, raw.(*structs.LocalVolumeClaim).AllocName)
+	}
+	must.Eq(t, []string{alloc1.Name, alloc2.Name}, names)
+
+	// Delete a single claim, then all the claims of a job.
+	must.NoError(t, state.DeleteLocalVolumeClaims(structs.MsgTypeTestSetup, 1005, job.Namespace, job.ID, alloc1.Name))
+	claim, err = state.LocalVolumeClaim(nil, job.Namespace, job.ID, alloc1.Name)
+	must.NoError(t, err)
+	must.Nil(t, claim)
+	must.Error(t, state.DeleteLocalVolumeClaims(structs.MsgTypeTestSetup, 1006, job.Namespace, job.ID, alloc1.Name))
+
+	must.NoError(t, state.DeleteLocalVolumeClaims(structs.MsgTypeTestSetup, 1007, job.Namespace, job.ID, ""))
+	claim, err = state.LocalVolumeClaim(nil, job.Namespace, job.ID, alloc2.Name)
+	must.NoError(t, err)
+	must.Nil(t, claim)
+
+	index, err := state.Index(TableLocalVolumeClaims)
+	must.NoError(t, err)
+	must.Eq(t, 1007, index)
+
+	// Purging a job deletes its claims.
+	must.NoError(t, state.DeleteJob(1008, prefixJob.Namespace, prefixJob.ID))
+	claim, err = state.LocalVolumeClaim(nil, prefixJob.Namespace, prefixJob.ID, prefixAlloc.Name)
+	must.NoError(t, err)
+	must.Nil(t, claim)
+}
@@ -69,6 +69,22 @@ func (r *StateRestore) EvalRestore(eval *structs.Evaluation) error {
 	return nil
 }
 
+// EvalTraceRestore is used to restore an evaluation trace
+func (r *StateRestore) EvalTraceRestore(trace *structs.EvalTrace) error {
+	if err := r.txn.Insert(TableEvalTraces, trace); err != nil {
+		return fmt.Errorf("eval trace insert failed: %v", err)
+	}
+	return nil
+}
+
+// LocalVolumeClaimRestore is used to restore a local volume claim
+func (r *StateRestore) LocalVolumeClaimRestore(claim *structs.LocalVolumeClaim) error {
+	if err := r.txn.Insert(TableLocalVolumeClaims, claim); err != nil {
+		return fmt.Errorf("local volume claim insert failed: %v", err)
+	}
+	return nil
+}
+
 // AllocRestore is used to restore an allocation
 func (r *StateRestore) AllocRestore(alloc *structs.Allocation) error {
 	if err := r.txn.Insert("allocs", alloc); err != nil {
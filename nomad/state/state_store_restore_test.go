@@ -234,6 +234,30 @@ func TestStateStore_RestoreEval(t *testing.T) {
 	must.Eq(t, eval, out)
 }
 
+func TestStateStore_RestoreEvalTrace(t *testing.T) {
+	ci.Parallel(t)
+
+	state := testStateStore(t)
+	eval := mock.Eval()
+	trace := &structs.EvalTrace{
+		EvalID:      eval.ID,
+		Namespace:   eval.Namespace,
+		JobID:       eval.JobID,
+		CreateIndex: 10,
+		ModifyIndex: 10,
+	}
+
+	restore, err := state.Restore()
+	must.NoError(t, err)
+
+	must.NoError(t, restore.EvalTraceRestore(trace))
+	must.NoError(t, restore.Commit())
+
+	out, err := state.EvalTraceByID(nil, eval.ID)
+	must.NoError(t, err)
+	must.Eq(t, trace, out)
+}
+
 func TestStateStore_RestoreAlloc(t *testing.T) {
 	ci.Parallel(t)
 
@@ -4398,6 +4398,52 @@ func TestStateStore_UpsertEvals_Eval(t *testing.T) {
 	}
 }
 
+// TestStateStore_JobWarnings asserts the warnings set when registering a job
+// replace those of its summary, while the warnings of its evaluations are
+// added to them.
+func TestStateStore_JobWarnings(t *testing.T) {
+	ci.Parallel(t)
+
+	state := testStateStore(t)
+	job := mock.Job()
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+	register := &structs.JobWarning{Type: structs.JobWarningTypeRegister, Message: "register"}
+	must.NoError(t, state.SetJobWarnings(1001, job.Namespace, job.ID, []*structs.JobWarning{register}))
+
+	summary, err := state.JobSummaryByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, []*structs.JobWarning{register}, summary.Warnings)
+	must.Eq(t, 1001, summary.ModifyIndex)
+
+	affinity := &structs.JobWarning{Type: structs.JobWarningTypeAffinity, TaskGroup: "web", Message: "affinity"}
+	eval := mock.Eval()
+	eval.Namespace = job.Namespace
+	eval.JobID = job.ID
+	eval.Warnings = []*structs.JobWarning{affinity, register}
+	must.NoError(t, state.UpsertEvals(structs.MsgTypeTestSetup, 1002, []*structs.Evaluation{eval}))
+
+	summary, err = state.JobSummaryByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, []*structs.JobWarning{register, affinity}, summary.Warnings)
+	must.Eq(t, 1002, summary.ModifyIndex)
+
+	// Evaluations raising no new warnings leave the summary unchanged
+	eval = eval.Copy()
+	eval.ID = uuid.Generate()
+	eval.Warnings = []*structs.JobWarning{affinity}
+	must.NoError(t, state.UpsertEvals(structs.MsgTypeTestSetup, 1003, []*structs.Evaluation{eval}))
+
+	summary, err = state.JobSummaryByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, 1002, summary.ModifyIndex)
+
+	must.NoError(t, state.SetJobWarnings(1004, job.Namespace, job.ID, []*structs.JobWarning{}))
+	summary, err = state.JobSummaryByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.SliceEmpty(t, summary.Warnings)
+}
+
 func TestStateStore_UpsertEvals_CancelBlocked(t *testing.T) {
 	ci.Parallel(t)
 
@@ -8091,6 +8137,90 @@ func TestStateStore_UpdateJobStability(t *testing.T) {
 	require.False(t, jout.Stable)
 }
 
+func TestStateStore_UpdateJobSuspension(t *testing.T) {
+	ci.Parallel(t)
+
+	state := testStateStore(t)
+
+	// A dead batch job is garbage collectable
+	job := mock.BatchJob()
+	eval := mock.Eval()
+	eval.JobID = job.ID
+	eval.Status = structs.EvalStatusComplete
+	must.NoError(t, state.UpsertEvals(structs.MsgTypeTestSetup, 999, []*structs.Evaluation{eval}))
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+	gcable := func() bool {
+		iter, err := state.JobsByGC(nil, true)
+		must.NoError(t, err)
+		for raw := iter.Next(); raw != nil; raw = iter.Next() {
+			if raw.(*structs.Job).ID == job.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	out, err := state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.True(t, gcable())
+	version := out.Version
+
+	newEval := func() *structs.Evaluation {
+		eval := mock.Eval()
+		eval.JobID = job.ID
+		eval.Status = structs.EvalStatusComplete
+		return eval
+	}
+
+	// Suspending the job keeps its version and prevents its garbage
+	// collection, and upserts its evaluation
+	suspendEval := newEval()
+	updated, err := state.UpdateJobSuspension(structs.MsgTypeTestSetup, 1001, job.Namespace, job.ID, true, suspendEval)
+	must.NoError(t, err)
+	must.True(t, updated)
+
+	out, err = state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.True(t, out.Suspended)
+	must.Eq(t, version, out.Version)
+	must.Eq(t, 1001, out.ModifyIndex)
+	must.False(t, gcable())
+
+	outEval, err := state.EvalByID(nil, suspendEval.ID)
+	must.NoError(t, err)
+	must.NotNil(t, outEval)
+	must.Eq(t, 1001, outEval.CreateIndex)
+
+	// Suspending the job again writes nothing
+	againEval := newEval()
+	updated, err = state.UpdateJobSuspension(structs.MsgTypeTestSetup, 1002, job.Namespace, job.ID, true, againEval)
+	must.NoError(t, err)
+	must.False(t, updated)
+
+	outEval, err = state.EvalByID(nil, againEval.ID)
+	must.NoError(t, err)
+	must.Nil(t, outEval)
+	out, err = state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.Eq(t, 1001, out.ModifyIndex)
+
+	// Resuming the job makes it garbage collectable again
+	updated, err = state.UpdateJobSuspension(structs.MsgTypeTestSetup, 1003, job.Namespace, job.ID, false, nil)
+	must.NoError(t, err)
+	must.True(t, updated)
+
+	out, err = state.JobByID(nil, job.Namespace, job.ID)
+	must.NoError(t, err)
+	must.False(t, out.Suspended)
+	must.Eq(t, version, out.Version)
+	must.True(t, gcable())
+
+	// Unknown jobs can't be suspended
+	_, err = state.UpdateJobSuspension(structs.MsgTypeTestSetup, 1004, job.Namespace, "unknown", true, nil)
+	must.ErrorContains(t, err, "not found")
+}
+
 // Test that nonexistent deployment can't be promoted
 func TestStateStore_UpsertDeploymentPromotion_Nonexistent(t *testing.T) {
 	ci.Parallel(t)
@@ -10736,6 +10866,37 @@ func TestStateStore_UpsertScalingEvent_LimitAndOrder(t *testing.T) {
 	require.Equal(expectedEvents, actualEvents)
 }
 
+func TestStateStore_UpsertScalingEvent_TrackedScalingEvents(t *testing.T) {
+	ci.Parallel(t)
+
+	config := TestStateStorePublisher(t)
+	config.JobTrackedScalingEvents = 3
+	state := TestStateStoreCfg(t, config)
+
+	namespace := uuid.Generate()
+	jobID := uuid.Generate()
+	group := uuid.Generate()
+
+	for i := 1; i <= 5; i++ {
+		event := structs.NewScalingEvent("").SetMeta(map[string]interface{}{"i": i})
+		event.Annotation = fmt.Sprintf("annotation %d", i)
+		must.NoError(t, state.UpsertScalingEvent(uint64(1000+i), &structs.ScalingEventRequest{
+			Namespace:    namespace,
+			JobID:        jobID,
+			TaskGroup:    group,
+			ScalingEvent: event,
+		}))
+	}
+
+	out, _, err := state.ScalingEventsByJob(nil, namespace, jobID)
+	must.NoError(t, err)
+	must.Len(t, 3, out[group])
+	for i, event := range out[group] {
+		must.Eq(t, 5-i, event.Meta["i"].(int))
+		must.Eq(t, fmt.Sprintf("annotation %d", 5-i), event.Annotation)
+	}
+}
+
 func TestStateStore_RootKeyMetaData_CRUD(t *testing.T) {
 	ci.Parallel(t)
 	store := testStateStore(t)
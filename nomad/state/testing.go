@@ -16,9 +16,10 @@ import (
 
 func TestStateStore(t testing.TB) *StateStore {
 	config := &StateStoreConfig{
-		Logger:             testlog.HCLogger(t),
-		Region:             "global",
-		JobTrackedVersions: structs.JobDefaultTrackedVersions,
+		Logger:                  testlog.HCLogger(t),
+		Region:                  "global",
+		JobTrackedVersions:      structs.JobDefaultTrackedVersions,
+		JobTrackedScalingEvents: structs.JobTrackedScalingEvents,
 	}
 	state, err := NewStateStore(config)
 	if err != nil {
@@ -32,10 +33,11 @@ func TestStateStore(t testing.TB) *StateStore {
 
 func TestStateStorePublisher(t testing.TB) *StateStoreConfig {
 	return &StateStoreConfig{
-		Logger:             testlog.HCLogger(t),
-		Region:             "global",
-		EnablePublisher:    true,
-		JobTrackedVersions: structs.JobDefaultTrackedVersions,
+		Logger:                  testlog.HCLogger(t),
+		Region:                  "global",
+		EnablePublisher:         true,
+		JobTrackedVersions:      structs.JobDefaultTrackedVersions,
+		JobTrackedScalingEvents: structs.JobTrackedScalingEvents,
 	}
 }
 
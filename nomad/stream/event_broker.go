@@ -120,7 +120,7 @@ func (e *EventBroker) SubscribeWithACLCheck(req *SubscribeRequest) (*Subscriptio
 		return nil, nil, structs.ErrPermissionDenied
 	}
 
-	sub, err := e.Subscribe(req)
+	sub, err := e.subscribe(req, aclObj)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -140,6 +140,12 @@ func (e *EventBroker) SubscribeWithACLCheck(req *SubscribeRequest) (*Subscriptio
 // When a caller is finished with the subscription it must call Subscription.Unsubscribe
 // to free ACL tracking resources.
 func (e *EventBroker) Subscribe(req *SubscribeRequest) (*Subscription, error) {
+	return e.subscribe(req, nil)
+}
+
+// subscribe returns a new Subscription for a given request. If aclObj is set,
+// the subscription only receives the events the ACL object allows reading.
+func (e *EventBroker) subscribe(req *SubscribeRequest, aclObj *acl.ACL) (*Subscription, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -163,6 +169,7 @@ func (e *EventBroker) Subscribe(req *SubscribeRequest) (*Subscription, error) {
 	close(start.link.nextCh)
 
 	sub := newSubscription(req, start, e.subscriptions.unsubscribeFn(req))
+	sub.setACL(aclObj)
 
 	e.subscriptions.add(req, sub)
 	return sub, nil
@@ -220,7 +227,7 @@ func (e *EventBroker) handleACLUpdates(ctx context.Context) {
 				}
 
 				e.subscriptions.closeSubscriptionFunc(tokenSecretID, func(sub *Subscription) bool {
-					return !aclAllowsSubscription(aclObj, sub.req)
+					return !sub.updateACL(aclObj)
 				})
 
 			case *structs.ACLPolicyEvent, *structs.ACLRoleStreamEvent:
@@ -268,7 +275,7 @@ func (e *EventBroker) checkSubscriptionsAgainstACLChange() {
 		}
 
 		e.subscriptions.closeSubscriptionFunc(tokenSecretID, func(sub *Subscription) bool {
-			return !aclAllowsSubscription(aclObj, sub.req)
+			return !sub.updateACL(aclObj)
 		})
 	}
 }
@@ -352,6 +359,10 @@ type ACLDelegate interface {
 	TokenProvider() ACLTokenProvider
 }
 
+// aclAllowsSubscription returns true if the ACL object allows subscribing to
+// the requested topics. Subscriptions to all namespaces or to all topics are
+// allowed as long as some of their events can be read, since the events are
+// then filtered by aclAllowsEvent.
 func aclAllowsSubscription(aclObj *acl.ACL, subReq *SubscribeRequest) bool {
 	for topic := range subReq.Topics {
 		switch topic {
@@ -359,7 +370,8 @@ func aclAllowsSubscription(aclObj *acl.ACL, subReq *SubscribeRequest) bool {
 			structs.TopicEvaluation,
 			structs.TopicAllocation,
 			structs.TopicJob,
-			structs.TopicService:
+			structs.TopicService,
+			structs.TopicWorkloadIdentity:
 			if ok := aclObj.AllowNsOp(subReq.Namespace, acl.NamespaceCapabilityReadJob); !ok {
 				return false
 			}
@@ -373,6 +385,11 @@ func aclAllowsSubscription(aclObj *acl.ACL, subReq *SubscribeRequest) bool {
 			if ok := aclObj.IsManagement(); !ok {
 				return false
 			}
+		case structs.TopicAll:
+			if ok := aclObj.AllowNsOp(subReq.Namespace, acl.NamespaceCapabilityReadJob) ||
+				aclObj.AllowNodeRead(); !ok {
+				return false
+			}
 		default:
 			if ok := aclObj.IsManagement(); !ok {
 				return false
@@ -383,6 +400,30 @@ func aclAllowsSubscription(aclObj *acl.ACL, subReq *SubscribeRequest) bool {
 	return true
 }
 
+// aclAllowsEvent returns true if the ACL object allows reading the event.
+// Events of namespaced topics are only allowed if the namespace of the event
+// is readable, so tokens with access to some namespaces can subscribe to all
+// of them and only receive the events of the namespaces they can read.
+func aclAllowsEvent(aclObj *acl.ACL, event *structs.Event) bool {
+	switch event.Topic {
+	case structs.TopicDeployment,
+		structs.TopicEvaluation,
+		structs.TopicAllocation,
+		structs.TopicJob,
+		structs.TopicService,
+		structs.TopicWorkloadIdentity:
+		ns := event.Namespace
+		if ns == "" {
+			ns = structs.DefaultNamespace
+		}
+		return aclObj.AllowNsOp(ns, acl.NamespaceCapabilityReadJob)
+	case structs.TopicNode:
+		return aclObj.AllowNodeRead()
+	default:
+		return aclObj.IsManagement()
+	}
+}
+
 func (s *Subscription) forceClose() {
 	if atomic.CompareAndSwapUint32(&s.state, subscriptionStateOpen, subscriptionStateClosed) {
 		close(s.forceClosed)
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
 
 	"github.com/stretchr/testify/require"
 )
@@ -1072,6 +1073,145 @@ func TestEventBroker_NodePool_ACL(t *testing.T) {
 
 }
 
+func TestEventBroker_Namespace_ACL(t *testing.T) {
+	ci.Parallel(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	secretID := uuid.Generate()
+	policy := &structs.ACLPolicy{
+		Name:        "prod-read",
+		Rules:       mock.NamespacePolicy("prod", "", []string{acl.NamespaceCapabilityReadJob}),
+		ModifyIndex: 100,
+	}
+	policy.SetHash()
+	tokenProvider := &fakeACLTokenProvider{
+		policy: policy,
+		token: &structs.ACLToken{
+			SecretID: secretID,
+			Policies: []string{policy.Name},
+		},
+	}
+	aclDelegate := &fakeACLDelegate{tokenProvider: tokenProvider}
+
+	jobEvent := func(ns string) structs.Event {
+		return structs.Event{Topic: structs.TopicJob, Type: structs.TypeJobRegistered, Namespace: ns}
+	}
+	nextEvents := func(t *testing.T, sub *Subscription) []structs.Event {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		events, err := sub.Next(ctx)
+		must.NoError(t, err)
+		return events.Events
+	}
+
+	t.Run("single namespace token subscribes to all namespaces", func(t *testing.T) {
+		publisher, err := NewEventBroker(ctx, aclDelegate, EventBrokerCfg{})
+		must.NoError(t, err)
+
+		sub, _, err := publisher.SubscribeWithACLCheck(&SubscribeRequest{
+			Topics:    map[structs.Topic][]string{structs.TopicJob: {"*"}},
+			Namespace: "*",
+			Token:     secretID,
+		})
+		must.NoError(t, err)
+
+		publisher.Publish(&structs.Events{Index: 100, Events: []structs.Event{
+			jobEvent("dev"), jobEvent("prod"), jobEvent("dev"),
+		}})
+		events := nextEvents(t, sub)
+		must.Len(t, 1, events)
+		must.Eq(t, "prod", events[0].Namespace)
+	})
+
+	t.Run("namespaced token subscribes to all topics", func(t *testing.T) {
+		publisher, err := NewEventBroker(ctx, aclDelegate, EventBrokerCfg{})
+		must.NoError(t, err)
+
+		sub, _, err := publisher.SubscribeWithACLCheck(&SubscribeRequest{
+			Topics:    map[structs.Topic][]string{structs.TopicAll: {"*"}},
+			Namespace: "*",
+			Token:     secretID,
+		})
+		must.NoError(t, err)
+
+		publisher.Publish(&structs.Events{Index: 100, Events: []structs.Event{
+			{Topic: structs.TopicNode, Type: structs.TypeNodeRegistration},
+			{Topic: structs.TopicNodePool, Type: structs.TypeNodePoolUpserted},
+			jobEvent("dev"),
+			jobEvent("prod"),
+		}})
+		events := nextEvents(t, sub)
+		must.Len(t, 1, events)
+		must.Eq(t, structs.TopicJob, events[0].Topic)
+		must.Eq(t, "prod", events[0].Namespace)
+	})
+
+	t.Run("workload identity events are filtered by namespace", func(t *testing.T) {
+		publisher, err := NewEventBroker(ctx, aclDelegate, EventBrokerCfg{})
+		must.NoError(t, err)
+
+		sub, _, err := publisher.SubscribeWithACLCheck(&SubscribeRequest{
+			Topics:    map[structs.Topic][]string{structs.TopicWorkloadIdentity: {"*"}},
+			Namespace: "*",
+			Token:     secretID,
+		})
+		must.NoError(t, err)
+
+		publisher.Publish(&structs.Events{Index: 100, Events: []structs.Event{
+			{Topic: structs.TopicWorkloadIdentity, Type: structs.TypeWorkloadIdentitySigned, Namespace: "dev"},
+			{Topic: structs.TopicWorkloadIdentity, Type: structs.TypeWorkloadIdentityRenewed, Namespace: "prod"},
+		}})
+		events := nextEvents(t, sub)
+		must.Len(t, 1, events)
+		must.Eq(t, structs.TypeWorkloadIdentityRenewed, events[0].Type)
+		must.Eq(t, "prod", events[0].Namespace)
+	})
+
+	t.Run("policy update changes filtered namespaces", func(t *testing.T) {
+		provider := &fakeACLTokenProvider{policy: policy, token: tokenProvider.token}
+		publisher, err := NewEventBroker(ctx, &fakeACLDelegate{tokenProvider: provider}, EventBrokerCfg{})
+		must.NoError(t, err)
+
+		sub, _, err := publisher.SubscribeWithACLCheck(&SubscribeRequest{
+			Topics:    map[structs.Topic][]string{structs.TopicJob: {"*"}},
+			Namespace: "*",
+			Token:     secretID,
+		})
+		must.NoError(t, err)
+
+		policyAfter := &structs.ACLPolicy{
+			Name:        "dev-read",
+			Rules:       mock.NamespacePolicy("dev", "", []string{acl.NamespaceCapabilityReadJob}),
+			ModifyIndex: 101,
+		}
+		policyAfter.SetHash()
+		provider.policy = policyAfter
+
+		publisher.Publish(&structs.Events{Index: 101, Events: []structs.Event{{
+			Topic:   structs.TopicACLPolicy,
+			Type:    structs.TypeACLPolicyUpserted,
+			Payload: &structs.ACLPolicyEvent{ACLPolicy: policyAfter},
+		}}})
+
+		// Wait for the broker to process the policy update, which happens
+		// asynchronously to publishing events.
+		must.Wait(t, wait.InitialSuccess(wait.BoolFunc(func() bool {
+			aclObj := sub.aclObj.Load()
+			return aclObj.AllowNsOp("dev", acl.NamespaceCapabilityReadJob)
+		}), wait.Timeout(time.Second), wait.Gap(10*time.Millisecond)))
+
+		publisher.Publish(&structs.Events{Index: 102, Events: []structs.Event{
+			jobEvent("prod"), jobEvent("dev"),
+		}})
+		events := nextEvents(t, sub)
+		must.Len(t, 1, events)
+		must.Eq(t, "dev", events[0].Namespace)
+	})
+}
+
 func consumeSubscription(ctx context.Context, sub *Subscription) <-chan subNextResult {
 	eventCh := make(chan subNextResult, 1)
 	go func() {
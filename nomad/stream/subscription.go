@@ -8,6 +8,7 @@ import (
 	"errors"
 	"sync/atomic"
 
+	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -46,6 +47,11 @@ type Subscription struct {
 	// It must be safe to call the function from multiple goroutines and the function
 	// must be idempotent.
 	unsub func()
+
+	// aclObj is the ACL object of the token of the subscription, used to
+	// filter the events the token can't read. It's nil if ACLs are disabled,
+	// and is replaced by EventBroker when the token's permissions change.
+	aclObj atomic.Pointer[acl.ACL]
 }
 
 type SubscribeRequest struct {
@@ -71,6 +77,39 @@ func newSubscription(req *SubscribeRequest, item *bufferItem, unsub func()) *Sub
 	}
 }
 
+// setACL sets the ACL object used to filter the events of the subscription.
+func (s *Subscription) setACL(aclObj *acl.ACL) {
+	s.aclObj.Store(aclObj)
+}
+
+// updateACL replaces the ACL object of the subscription after the
+// permissions of its token changed. It returns false if the token isn't
+// allowed to keep the subscription, which must then be closed.
+func (s *Subscription) updateACL(aclObj *acl.ACL) bool {
+	if !aclAllowsSubscription(aclObj, s.req) {
+		return false
+	}
+	s.setACL(aclObj)
+	return true
+}
+
+// filterACL removes the events the ACL object of the subscription doesn't
+// allow reading.
+func (s *Subscription) filterACL(events []structs.Event) []structs.Event {
+	aclObj := s.aclObj.Load()
+	if aclObj == nil || aclObj.IsManagement() || len(events) == 0 {
+		return events
+	}
+
+	result := make([]structs.Event, 0, len(events))
+	for i := range events {
+		if aclAllowsEvent(aclObj, &events[i]) {
+			result = append(result, events[i])
+		}
+	}
+	return result
+}
+
 func (s *Subscription) Next(ctx context.Context) (structs.Events, error) {
 	if atomic.LoadUint32(&s.state) == subscriptionStateClosed {
 		return structs.Events{}, ErrSubscriptionClosed
@@ -86,7 +125,7 @@ func (s *Subscription) Next(ctx context.Context) (structs.Events, error) {
 		}
 		s.currentItem = next
 
-		events := filter(s.req, next.Events.Events)
+		events := s.filterACL(filter(s.req, next.Events.Events))
 		if len(events) == 0 {
 			continue
 		}
@@ -106,7 +145,7 @@ func (s *Subscription) NextNoBlock() ([]structs.Event, error) {
 		}
 		s.currentItem = next
 
-		events := filter(s.req, next.Events.Events)
+		events := s.filterACL(filter(s.req, next.Events.Events))
 		if len(events) == 0 {
 			continue
 		}
@@ -126,8 +165,13 @@ func filter(req *SubscribeRequest, events []structs.Event) []structs.Event {
 
 	allTopicKeys := req.Topics[structs.TopicAll]
 
+	// The change feed events duplicate the other events, so they're only
+	// returned to the subscriptions to their topic
+	_, changeFeed := req.Topics[structs.TopicChangeFeed]
+
 	// Return all events if subscribed to all namespaces and all topics
-	if req.Namespace == "*" && len(allTopicKeys) == 1 && allTopicKeys[0] == string(structs.TopicAll) {
+	if req.Namespace == "*" && len(allTopicKeys) == 1 && allTopicKeys[0] == string(structs.TopicAll) &&
+		(changeFeed || !hasChangeFeedEvents(events)) {
 		return events
 	}
 
@@ -137,6 +181,9 @@ func filter(req *SubscribeRequest, events []structs.Event) []structs.Event {
 		if req.Namespace != "*" && event.Namespace != "" && event.Namespace != req.Namespace {
 			continue
 		}
+		if event.Topic == structs.TopicChangeFeed && !changeFeed {
+			continue
+		}
 
 		// *[*] always matches
 		if len(allTopicKeys) == 1 && allTopicKeys[0] == string(structs.TopicAll) {
@@ -166,6 +213,15 @@ func filter(req *SubscribeRequest, events []structs.Event) []structs.Event {
 	return result
 }
 
+func hasChangeFeedEvents(events []structs.Event) bool {
+	for _, event := range events {
+		if event.Topic == structs.TopicChangeFeed {
+			return true
+		}
+	}
+	return false
+}
+
 func eventMatchesKey(event structs.Event, key string) bool {
 	if event.Key == key {
 		return true
@@ -211,3 +211,37 @@ func TestFilter_FilterKeys(t *testing.T) {
 
 	require.Equal(t, 1, cap(actual))
 }
+
+func TestFilter_ChangeFeed(t *testing.T) {
+	ci.Parallel(t)
+
+	job := structs.Event{Topic: structs.TopicJob, Key: "example"}
+	feed := structs.Event{Topic: structs.TopicChangeFeed, Key: "example", FilterKeys: []string{"jobs"}}
+	events := []structs.Event{job, feed}
+
+	// change feed events are only returned to subscriptions to their topic
+	req := &SubscribeRequest{
+		Namespace: "*",
+		Topics: map[structs.Topic][]string{
+			"*": {"*"},
+		},
+	}
+	require.Equal(t, []structs.Event{job}, filter(req, events))
+
+	req.Namespace = "default"
+	require.Equal(t, []structs.Event{job}, filter(req, events))
+
+	req.Namespace = "*"
+	req.Topics[structs.TopicChangeFeed] = []string{"*"}
+	require.Equal(t, events, filter(req, events))
+
+	req = &SubscribeRequest{
+		Topics: map[structs.Topic][]string{
+			structs.TopicChangeFeed: {"jobs"},
+		},
+	}
+	require.Equal(t, []structs.Event{feed}, filter(req, events))
+
+	req.Topics[structs.TopicChangeFeed] = []string{"allocs"}
+	require.Empty(t, filter(req, events))
+}
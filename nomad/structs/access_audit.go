@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+import (
+	"time"
+)
+
+const (
+	// AccessAuditEventVariable is the type of the audit events recording the
+	// reads and writes of variables.
+	AccessAuditEventVariable = "VariableAccessEvent"
+
+	// AccessAuditEventKeyring is the type of the audit events recording the
+	// uses of the keys of the keyring to sign identities.
+	AccessAuditEventKeyring = "KeyringAccessEvent"
+)
+
+const (
+	AccessAuditOpRead = "read"
+	AccessAuditOpSign = "sign"
+)
+
+// AccessAuditEvent records an access to a variable or to a key of the keyring,
+// for the forensics of the uses of secrets.
+type AccessAuditEvent struct {
+	// ID is the unique ID of the event.
+	ID string
+
+	// Timestamp is the time of the access.
+	Timestamp time.Time
+
+	// Operation is the operation of the access, such as "read", or the
+	// operation applied to a variable, such as "set" or "delete".
+	Operation string
+
+	// Namespace and Path are the variable accessed. For signatures, Namespace
+	// is the namespace of the signed identity.
+	Namespace string
+	Path      string `json:",omitempty"`
+
+	// KeyID is the ID of the key of the keyring used to encrypt, decrypt, or
+	// sign, if any.
+	KeyID string `json:",omitempty"`
+
+	// AllocID and Subject are the allocation and the subject of the signed
+	// identity, for signatures.
+	AllocID string `json:",omitempty"`
+	Subject string `json:",omitempty"`
+
+	// Actor is the caller responsible for the access.
+	Actor AccessAuditActor
+
+	// SampleRate is the fraction of the accesses of this kind that are
+	// recorded, so the volume of the accesses can be estimated.
+	SampleRate float64
+}
+
+// AccessAuditActor is the caller responsible for an access. Only the fields
+// of the kind of identity of the caller are set, and none are set for the
+// accesses made by the servers themselves.
+type AccessAuditActor struct {
+	// AccessorID is the accessor ID of the ACL token of the caller.
+	AccessorID string `json:",omitempty"`
+
+	// Namespace, JobID, AllocID, and TaskName are the workload of the
+	// workload identity of the caller.
+	Namespace string `json:",omitempty"`
+	JobID     string `json:",omitempty"`
+	AllocID   string `json:",omitempty"`
+	TaskName  string `json:",omitempty"`
+
+	// ClientID is the ID of the node of the caller.
+	ClientID string `json:",omitempty"`
+
+	// RemoteIP is the address of the connection of the caller.
+	RemoteIP string `json:",omitempty"`
+}
+
+// NewAccessAuditActor returns the actor of an authenticated identity.
+func NewAccessAuditActor(identity *AuthenticatedIdentity) AccessAuditActor {
+	var actor AccessAuditActor
+	if identity == nil {
+		return actor
+	}
+
+	if token := identity.GetACLToken(); token != nil {
+		actor.AccessorID = token.AccessorID
+	}
+	if claims := identity.GetClaims(); claims != nil {
+		actor.Namespace = claims.Namespace
+		actor.JobID = claims.JobID
+		actor.AllocID = claims.AllocationID
+		actor.TaskName = claims.TaskName
+	}
+	actor.ClientID = identity.ClientID
+	if identity.RemoteIP != nil {
+		actor.RemoteIP = identity.RemoteIP.String()
+	}
+	return actor
+}
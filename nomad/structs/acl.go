@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-set/v2"
 	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/pointer"
 	"github.com/hashicorp/nomad/helper/uuid"
@@ -157,6 +158,14 @@ const (
 	// Reply: ACLBindingRuleResponse
 	ACLGetBindingRuleRPCMethod = "ACL.GetBindingRule"
 
+	// ACLTestBindingRulesRPCMethod is the RPC method for evaluating a sample
+	// claim set against the binding rules of an auth method, without creating
+	// an ACL token.
+	//
+	// Args: ACLBindingRulesTestRequest
+	// Reply: ACLBindingRulesTestResponse
+	ACLTestBindingRulesRPCMethod = "ACL.TestBindingRules"
+
 	// ACLOIDCAuthURLRPCMethod is the RPC method for starting the OIDC login
 	// workflow. It generates the OIDC provider URL which will be used for user
 	// authentication.
@@ -180,6 +189,31 @@ const (
 	// Args: ACLLoginRequest
 	// Reply: ACLLoginResponse
 	ACLLoginRPCMethod = "ACL.Login"
+
+	// ACLExchangeWorkloadIdentityRPCMethod is the RPC method for exchanging a
+	// workload identity for a short-lived Nomad ACL token. It implements the
+	// token exchange grant defined in RFC 8693.
+	//
+	// Args: ACLWorkloadIdentityExchangeRequest
+	// Reply: ACLWorkloadIdentityExchangeResponse
+	ACLExchangeWorkloadIdentityRPCMethod = "ACL.ExchangeWorkloadIdentity"
+
+	// ACLExchangeWorkloadIdentityJWTRPCMethod is the RPC method for exchanging
+	// a workload identity for a JWT with another audience, as allowed by the
+	// token exchange configuration of its namespace.
+	//
+	// Args: ACLWorkloadIdentityJWTExchangeRequest
+	// Reply: ACLWorkloadIdentityJWTExchangeResponse
+	ACLExchangeWorkloadIdentityJWTRPCMethod = "ACL.ExchangeWorkloadIdentityJWT"
+
+	// ACLRenewTokenRPCMethod is the RPC method for renewing an ACL token
+	// created by an auth method. It exchanges the provided token for a new
+	// expiration time of the ACL token, which may already be expired if the
+	// auth method allows it.
+	//
+	// Args: ACLTokenRenewRequest
+	// Reply: ACLTokenRenewResponse
+	ACLRenewTokenRPCMethod = "ACL.RenewToken"
 )
 
 const (
@@ -213,6 +247,30 @@ const (
 	// ACLAuthMethodTypeJWT the ACLAuthMethod.Type and represents an auth-method
 	// which uses the JWT type.
 	ACLAuthMethodTypeJWT = "JWT"
+
+	// DefaultWorkloadIdentityExchangeTTL is the TTL of the ACL tokens created
+	// by exchanging a workload identity when the request does not set one.
+	DefaultWorkloadIdentityExchangeTTL = 15 * time.Minute
+
+	// MaxWorkloadIdentityExchangeTTL is the maximum TTL that can be requested
+	// for the ACL tokens created by exchanging a workload identity.
+	MaxWorkloadIdentityExchangeTTL = 1 * time.Hour
+)
+
+const (
+	// OAuthGrantTypeTokenExchange is the OAuth 2.0 grant type used to exchange
+	// tokens as defined in RFC 8693 section 2.1.
+	OAuthGrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	// OAuthTokenTypeJWT and OAuthTokenTypeIDToken are the token type
+	// identifiers accepted for subject tokens. Workload identities are JWTs
+	// that can also be used as OIDC ID tokens.
+	OAuthTokenTypeJWT     = "urn:ietf:params:oauth:token-type:jwt"
+	OAuthTokenTypeIDToken = "urn:ietf:params:oauth:token-type:id_token"
+
+	// OAuthTokenTypeAccessToken is the token type identifier of the issued
+	// Nomad ACL tokens.
+	OAuthTokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
 )
 
 var (
@@ -368,6 +426,9 @@ func (a *ACLToken) Validate(minTTL, maxTTL time.Duration, existing *ACLToken) er
 				mErr.Errors = append(mErr.Errors, errors.New("cannot update expiration time"))
 			}
 		}
+		if a.AuthMethod != "" && existing.AuthMethod != a.AuthMethod {
+			mErr.Errors = append(mErr.Errors, errors.New("cannot update auth method"))
+		}
 
 	}
 
@@ -749,6 +810,12 @@ type ACLAuthMethod struct {
 	Default       bool
 	Config        *ACLAuthMethodConfig
 
+	// TokenRenewalGracePeriod is the duration after the expiration of the ACL
+	// tokens created by this method during which they can still be renewed
+	// by logging in again. Expired tokens are not garbage collected until
+	// their grace period is over.
+	TokenRenewalGracePeriod time.Duration
+
 	Hash []byte
 
 	CreateTime  time.Time
@@ -773,6 +840,7 @@ func (a *ACLAuthMethod) SetHash() []byte {
 	_, _ = hash.Write([]byte(a.TokenLocality))
 	_, _ = hash.Write([]byte(a.MaxTokenTTL.String()))
 	_, _ = hash.Write([]byte(strconv.FormatBool(a.Default)))
+	_, _ = hash.Write([]byte(a.TokenRenewalGracePeriod.String()))
 
 	if a.Config != nil {
 		_, _ = hash.Write([]byte(a.Config.OIDCDiscoveryURL))
@@ -809,28 +877,36 @@ func (a *ACLAuthMethod) SetHash() []byte {
 }
 
 // MarshalJSON implements the json.Marshaler interface and allows
-// ACLAuthMethod.MaxTokenTTL to be marshaled correctly.
+// ACLAuthMethod.MaxTokenTTL and ACLAuthMethod.TokenRenewalGracePeriod to be
+// marshaled correctly.
 func (a *ACLAuthMethod) MarshalJSON() ([]byte, error) {
 	type Alias ACLAuthMethod
 	exported := &struct {
-		MaxTokenTTL string
+		MaxTokenTTL             string
+		TokenRenewalGracePeriod string
 		*Alias
 	}{
-		MaxTokenTTL: a.MaxTokenTTL.String(),
-		Alias:       (*Alias)(a),
+		MaxTokenTTL:             a.MaxTokenTTL.String(),
+		TokenRenewalGracePeriod: a.TokenRenewalGracePeriod.String(),
+		Alias:                   (*Alias)(a),
 	}
 	if a.MaxTokenTTL == 0 {
 		exported.MaxTokenTTL = ""
 	}
+	if a.TokenRenewalGracePeriod == 0 {
+		exported.TokenRenewalGracePeriod = ""
+	}
 	return json.Marshal(exported)
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface and allows
-// ACLAuthMethod.MaxTokenTTL to be unmarshalled correctly.
+// ACLAuthMethod.MaxTokenTTL and ACLAuthMethod.TokenRenewalGracePeriod to be
+// unmarshalled correctly.
 func (a *ACLAuthMethod) UnmarshalJSON(data []byte) (err error) {
 	type Alias ACLAuthMethod
 	aux := &struct {
-		MaxTokenTTL interface{}
+		MaxTokenTTL             interface{}
+		TokenRenewalGracePeriod interface{}
 		*Alias
 	}{
 		Alias: (*Alias)(a),
@@ -838,19 +914,31 @@ func (a *ACLAuthMethod) UnmarshalJSON(data []byte) (err error) {
 	if err = json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-	if aux.MaxTokenTTL != nil {
-		switch v := aux.MaxTokenTTL.(type) {
-		case string:
-			if a.MaxTokenTTL, err = time.ParseDuration(v); err != nil {
-				return err
-			}
-		case float64:
-			a.MaxTokenTTL = time.Duration(v)
-		}
+	if a.MaxTokenTTL, err = unmarshalAuthMethodDuration(aux.MaxTokenTTL); err != nil {
+		return err
+	}
+	if a.TokenRenewalGracePeriod, err = unmarshalAuthMethodDuration(aux.TokenRenewalGracePeriod); err != nil {
+		return err
 	}
 	return nil
 }
 
+// unmarshalAuthMethodDuration converts a duration of an auth method decoded
+// from JSON, which is either a duration string or a number of nanoseconds.
+// Empty strings are marshaled for zero durations.
+func unmarshalAuthMethodDuration(v interface{}) (time.Duration, error) {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		return time.ParseDuration(v)
+	case float64:
+		return time.Duration(v), nil
+	}
+	return 0, nil
+}
+
 func (a *ACLAuthMethod) Stub() *ACLAuthMethodStub {
 	return &ACLAuthMethodStub{
 		Name:        a.Name,
@@ -910,6 +998,7 @@ func (a *ACLAuthMethod) Merge(b *ACLAuthMethod) {
 		a.Type = helper.Merge(a.Type, b.Type)
 		a.TokenLocality = helper.Merge(a.TokenLocality, b.TokenLocality)
 		a.MaxTokenTTL = helper.Merge(a.MaxTokenTTL, b.MaxTokenTTL)
+		a.TokenRenewalGracePeriod = helper.Merge(a.TokenRenewalGracePeriod, b.TokenRenewalGracePeriod)
 		a.Config = helper.Merge(a.Config, b.Config)
 	}
 }
@@ -940,9 +1029,25 @@ func (a *ACLAuthMethod) Validate(minTTL, maxTTL time.Duration) error {
 			a.MaxTokenTTL.String(), minTTL.String(), maxTTL.String()))
 	}
 
+	if a.TokenRenewalGracePeriod < 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf(
+			"invalid TokenRenewalGracePeriod value '%s' (should not be negative)",
+			a.TokenRenewalGracePeriod.String()))
+	}
+
 	return mErr.ErrorOrNil()
 }
 
+// TokenRenewable returns whether an ACL token created by the auth method can
+// be renewed at time t: the token must expire, and must not have expired for
+// longer than the renewal grace period of the method.
+func (a *ACLAuthMethod) TokenRenewable(token *ACLToken, t time.Time) bool {
+	if !token.HasExpirationTime() {
+		return false
+	}
+	return !token.IsExpired(t.Add(-a.TokenRenewalGracePeriod))
+}
+
 // TokenLocalityIsGlobal returns whether the auth method creates global ACL
 // tokens or not.
 func (a *ACLAuthMethod) TokenLocalityIsGlobal() bool {
@@ -1200,6 +1305,15 @@ type ACLAuthMethodDeleteResponse struct {
 
 type ACLWhoAmIResponse struct {
 	Identity *AuthenticatedIdentity
+
+	// Policies are the names of the ACL policies applied to the identity,
+	// including the policies of the roles of ACL tokens.
+	Policies []string
+
+	// Capabilities are the effective capabilities of the identity. They are
+	// only set when ACLs are enabled.
+	Capabilities *acl.Capabilities
+
 	QueryMeta
 }
 
@@ -1506,6 +1620,75 @@ type ACLBindingRuleResponse struct {
 	QueryMeta
 }
 
+// ACLBindingRulesTestRequest is the request object to evaluate a sample claim
+// set against the claim mappings and binding rules of an auth method.
+type ACLBindingRulesTestRequest struct {
+
+	// AuthMethodName is the name of the auth method whose binding rules are
+	// evaluated. This is a required parameter.
+	AuthMethodName string
+
+	// Claims is the sample claim set, as found in a JWT or in the ID token
+	// and user info of an OIDC provider.
+	Claims map[string]interface{}
+
+	QueryOptions
+}
+
+// Validate ensures the request object contains all the required fields.
+func (a *ACLBindingRulesTestRequest) Validate() error {
+	if a.AuthMethodName == "" {
+		return errors.New("missing auth method name")
+	}
+	return nil
+}
+
+// ACLBindingRulesTestResponse is the response object when evaluating a sample
+// claim set against the binding rules of an auth method. It details the
+// roles and policies an ACL token created by logging in with these claims
+// would be granted.
+type ACLBindingRulesTestResponse struct {
+
+	// ClaimMappings are the values of the claims mapped by the auth method,
+	// which binding rules select and interpolate in their bind names.
+	ClaimMappings map[string]string
+
+	// ListClaimMappings are the values of the list claims mapped by the auth
+	// method.
+	ListClaimMappings map[string][]string
+
+	// MatchingRules are the binding rules whose selector matched the claims,
+	// in the order they were applied.
+	MatchingRules []*ACLBindingRuleTestResult
+
+	// Management indicates the token would be a management token.
+	Management bool
+
+	// Roles and Policies are the ACL roles and policies the token would be
+	// granted.
+	Roles    []*ACLTokenRoleLink
+	Policies []string
+
+	QueryMeta
+}
+
+// ACLBindingRuleTestResult is the result of a binding rule whose selector
+// matched the claims of an ACLBindingRulesTestRequest.
+type ACLBindingRuleTestResult struct {
+
+	// ID, Description and BindType are copied from the binding rule.
+	ID          string
+	Description string
+	BindType    string
+
+	// BindName is the bind name of the rule with the claims interpolated.
+	BindName string
+
+	// Bound indicates the ACL role or policy named by BindName exists, so it
+	// is granted to the token. It's always true for management rules.
+	Bound bool
+}
+
 // ACLOIDCAuthURLRequest is the request to make when starting the OIDC
 // authentication login flow.
 type ACLOIDCAuthURLRequest struct {
@@ -1639,3 +1822,145 @@ func (a *ACLLoginRequest) Validate() error {
 	}
 	return mErr.ErrorOrNil()
 }
+
+// ACLTokenRenewRequest is the request object to renew an ACL token created by
+// an auth method, by authenticating again with the auth method.
+type ACLTokenRenewRequest struct {
+
+	// SecretID is the secret ID of the ACL token to renew. This is a required
+	// parameter.
+	SecretID string
+
+	// LoginToken is the 3rd party token that would be used to login with the
+	// auth method which created the ACL token. This is a required parameter.
+	LoginToken string
+
+	WriteRequest
+}
+
+// Validate ensures the request object contains all the required fields in
+// order to renew the ACL token.
+func (a *ACLTokenRenewRequest) Validate() error {
+
+	var mErr multierror.Error
+
+	if a.SecretID == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("missing token secret ID"))
+	}
+	if a.LoginToken == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("missing login token"))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ACLTokenRenewResponse is the response object when an ACL token has been
+// renewed.
+type ACLTokenRenewResponse struct {
+	ACLToken *ACLToken
+	WriteMeta
+}
+
+// ACLWorkloadIdentityExchangeRequest is the request object used to exchange a
+// workload identity for a Nomad ACL token.
+type ACLWorkloadIdentityExchangeRequest struct {
+
+	// SubjectToken is the signed workload identity JWT to exchange. This is a
+	// required parameter.
+	SubjectToken string
+
+	// Policies is the set of ACL policies requested for the token. Each
+	// policy must be attached to the job, group, or task of the workload
+	// identity. This is a required parameter.
+	Policies []string
+
+	// TTL is the requested TTL of the ACL token. If not set, it defaults to
+	// DefaultWorkloadIdentityExchangeTTL.
+	TTL time.Duration
+
+	WriteRequest
+}
+
+// Validate ensures the request object contains all the required fields in
+// order to complete the exchange.
+func (a *ACLWorkloadIdentityExchangeRequest) Validate() error {
+
+	var mErr multierror.Error
+
+	if a.SubjectToken == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("missing subject token"))
+	}
+	if len(a.Policies) == 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("missing policies"))
+	}
+	if a.TTL < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("TTL must not be negative"))
+	}
+	if a.TTL > MaxWorkloadIdentityExchangeTTL {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf(
+			"TTL must not be greater than %s", MaxWorkloadIdentityExchangeTTL))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ACLWorkloadIdentityExchangeResponse is the response when a workload identity
+// has been successfully exchanged.
+type ACLWorkloadIdentityExchangeResponse struct {
+	ACLToken *ACLToken
+	WriteMeta
+}
+
+// ACLWorkloadIdentityJWTExchangeRequest is the request object used to
+// exchange a workload identity for a JWT with another audience.
+type ACLWorkloadIdentityJWTExchangeRequest struct {
+
+	// SubjectToken is the signed workload identity JWT to exchange. This is a
+	// required parameter.
+	SubjectToken string
+
+	// Audience is the audience of the exchanged JWT. It must be allowed by
+	// the token exchange configuration of the namespace of the workload. This
+	// is a required parameter.
+	Audience string
+
+	// TTL is the requested TTL of the JWT. If not set, it defaults to
+	// DefaultWorkloadIdentityExchangeTTL.
+	TTL time.Duration
+
+	WriteRequest
+}
+
+// Validate ensures the request object contains all the required fields in
+// order to complete the exchange.
+func (a *ACLWorkloadIdentityJWTExchangeRequest) Validate() error {
+
+	var mErr multierror.Error
+
+	if a.SubjectToken == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("missing subject token"))
+	}
+	if a.Audience == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("missing audience"))
+	}
+	if a.TTL < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("TTL must not be negative"))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ACLWorkloadIdentityJWTExchangeResponse is the response when a workload
+// identity has been successfully exchanged for a JWT.
+type ACLWorkloadIdentityJWTExchangeResponse struct {
+	JWT        string
+	Expiration time.Time
+	WriteMeta
+}
+
+// OAuthTokenExchangeResponse is the HTTP response of a successful token
+// exchange as defined in RFC 8693 section 2.2.1.
+type OAuthTokenExchangeResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+}
@@ -4,6 +4,7 @@
 package structs
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -170,6 +171,20 @@ func TestACLTokenValidate(t *testing.T) {
 			inputExistingACLToken: nil,
 			expectedErrorContains: "expiration time cannot be more than",
 		},
+		{
+			name: "auth method changed",
+			inputACLToken: &ACLToken{
+				Type:       ACLManagementToken,
+				Name:       "foo",
+				AuthMethod: "okta",
+			},
+			inputExistingACLToken: &ACLToken{
+				Type:       ACLManagementToken,
+				Name:       "foo",
+				AuthMethod: "auth0",
+			},
+			expectedErrorContains: "cannot update auth method",
+		},
 		{
 			name: "valid management",
 			inputACLToken: &ACLToken{
@@ -1024,6 +1039,7 @@ func TestACLAuthMethod_Validate(t *testing.T) {
 		{"invalid token locality", &ACLAuthMethod{TokenLocality: "regional"}, true, "invalid token locality"},
 		{"invalid type", &ACLAuthMethod{Type: "groovy"}, true, "invalid token type"},
 		{"invalid max ttl", &ACLAuthMethod{MaxTokenTTL: badTTL}, true, "invalid token type"},
+		{"invalid renewal grace period", &ACLAuthMethod{TokenRenewalGracePeriod: -time.Hour}, true, "invalid TokenRenewalGracePeriod"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1152,6 +1168,53 @@ func TestACLAuthMethod_TokenLocalityIsGlobal(t *testing.T) {
 	must.False(t, localAuthMethod.TokenLocalityIsGlobal())
 }
 
+func TestACLAuthMethod_TokenRenewable(t *testing.T) {
+	ci.Parallel(t)
+
+	now := time.Now().UTC()
+	authMethod := &ACLAuthMethod{TokenRenewalGracePeriod: time.Hour}
+
+	// Tokens without an expiration time don't need to be renewed.
+	must.False(t, authMethod.TokenRenewable(&ACLToken{}, now))
+
+	// Tokens can be renewed until the end of the grace period.
+	must.True(t, authMethod.TokenRenewable(&ACLToken{ExpirationTime: pointer.Of(now.Add(time.Hour))}, now))
+	must.True(t, authMethod.TokenRenewable(&ACLToken{ExpirationTime: pointer.Of(now.Add(-30 * time.Minute))}, now))
+	must.False(t, authMethod.TokenRenewable(&ACLToken{ExpirationTime: pointer.Of(now.Add(-2 * time.Hour))}, now))
+
+	// Without a grace period, expired tokens can't be renewed.
+	authMethod.TokenRenewalGracePeriod = 0
+	must.True(t, authMethod.TokenRenewable(&ACLToken{ExpirationTime: pointer.Of(now.Add(time.Hour))}, now))
+	must.False(t, authMethod.TokenRenewable(&ACLToken{ExpirationTime: pointer.Of(now.Add(-time.Minute))}, now))
+}
+
+func TestACLAuthMethod_MarshalJSON(t *testing.T) {
+	ci.Parallel(t)
+
+	authMethod := &ACLAuthMethod{
+		Name:                    "auth0",
+		MaxTokenTTL:             time.Hour,
+		TokenRenewalGracePeriod: 30 * time.Minute,
+	}
+	buf, err := json.Marshal(authMethod)
+	must.NoError(t, err)
+	must.StrContains(t, string(buf), `"TokenRenewalGracePeriod":"30m0s"`)
+
+	var decoded ACLAuthMethod
+	must.NoError(t, json.Unmarshal(buf, &decoded))
+	must.Eq(t, time.Hour, decoded.MaxTokenTTL)
+	must.Eq(t, 30*time.Minute, decoded.TokenRenewalGracePeriod)
+
+	// Zero durations are marshaled as empty strings.
+	authMethod.TokenRenewalGracePeriod = 0
+	buf, err = json.Marshal(authMethod)
+	must.NoError(t, err)
+
+	decoded = ACLAuthMethod{}
+	must.NoError(t, json.Unmarshal(buf, &decoded))
+	must.Eq(t, 0, decoded.TokenRenewalGracePeriod)
+}
+
 func TestACLBindingRule_Canonicalize(t *testing.T) {
 	ci.Parallel(t)
 
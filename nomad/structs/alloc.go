@@ -3,6 +3,11 @@
 
 package structs
 
+import (
+	"sort"
+	"time"
+)
+
 const (
 	// AllocServiceRegistrationsRPCMethod is the RPC method for listing all
 	// service registrations assigned to a specific allocation.
@@ -79,3 +84,212 @@ type AllocInfo struct {
 	// nomad namespace caused this registration.
 	Namespace string
 }
+
+// imagePullDriverMessage is the driver message emitted by task drivers when
+// they start downloading the image of a task.
+const imagePullDriverMessage = "Downloading image"
+
+// AllocTimeline is a summary of the time an allocation spent in each step of
+// its placement, from its creation by the scheduler until it was reported
+// healthy. It is computed from the allocation's task states and deployment
+// status, so it is subject to the same limits: only the most recent task
+// events are kept, and hook timings are only reported by clients that record
+// them.
+type AllocTimeline struct {
+	AllocID string
+
+	// CreatedAt is the time the scheduler created the allocation.
+	CreatedAt time.Time
+
+	// ReceivedAt is the time the first task of the allocation was received
+	// by the client.
+	ReceivedAt time.Time
+
+	// RunningAt is the time the first task of the allocation started.
+	RunningAt time.Time
+
+	// HealthyAt is the time the allocation was reported healthy to its
+	// deployment.
+	HealthyAt time.Time
+
+	// PendingDuration is the time between the creation of the allocation
+	// and its first task starting.
+	PendingDuration time.Duration
+
+	// HealthDuration is the time between the first task of the allocation
+	// starting and the allocation being reported healthy.
+	HealthDuration time.Duration
+
+	// Tasks is the timeline of each task of the allocation.
+	Tasks map[string]*TaskTimeline
+}
+
+// TaskTimeline is the timeline of a single task within an allocation.
+type TaskTimeline struct {
+	ReceivedAt time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	// Hooks are the spans of the prestart and poststart hooks of the task,
+	// in the order they ran.
+	Hooks []*TimelineSpan
+
+	// ImagePull is the span during which the task driver downloaded the
+	// image of the task, or nil if no download was reported.
+	ImagePull *TimelineSpan
+
+	// Restarts is the number of times the task was restarted.
+	Restarts uint64
+}
+
+// TimelineSpan is a named interval of an allocation timeline.
+type TimelineSpan struct {
+	Name     string
+	Phase    string `json:",omitempty"`
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
+func newTimelineSpan(name, phase string, start, end time.Time) *TimelineSpan {
+	span := &TimelineSpan{
+		Name:  name,
+		Phase: phase,
+		Start: start,
+		End:   end,
+	}
+	if !start.IsZero() && !end.IsZero() {
+		span.Duration = end.Sub(start)
+	}
+	return span
+}
+
+// NewAllocTimeline computes the timeline of the allocation.
+func NewAllocTimeline(alloc *Allocation) *AllocTimeline {
+	timeline := &AllocTimeline{
+		AllocID: alloc.ID,
+		Tasks:   make(map[string]*TaskTimeline, len(alloc.TaskStates)),
+	}
+	if alloc.CreateTime != 0 {
+		timeline.CreatedAt = time.Unix(0, alloc.CreateTime).UTC()
+	}
+
+	for name, state := range alloc.TaskStates {
+		if state == nil {
+			continue
+		}
+		task := newTaskTimeline(state)
+		timeline.Tasks[name] = task
+
+		timeline.ReceivedAt = earliestTime(timeline.ReceivedAt, task.ReceivedAt)
+		timeline.RunningAt = earliestTime(timeline.RunningAt, task.StartedAt)
+	}
+
+	if ds := alloc.DeploymentStatus; ds != nil && ds.IsHealthy() {
+		timeline.HealthyAt = ds.Timestamp.UTC()
+	}
+
+	if !timeline.CreatedAt.IsZero() && !timeline.RunningAt.IsZero() {
+		timeline.PendingDuration = timeline.RunningAt.Sub(timeline.CreatedAt)
+	}
+	if !timeline.RunningAt.IsZero() && !timeline.HealthyAt.IsZero() {
+		timeline.HealthDuration = timeline.HealthyAt.Sub(timeline.RunningAt)
+	}
+
+	return timeline
+}
+
+func newTaskTimeline(state *TaskState) *TaskTimeline {
+	task := &TaskTimeline{
+		StartedAt:  state.StartedAt.UTC(),
+		FinishedAt: state.FinishedAt.UTC(),
+		Hooks:      make([]*TimelineSpan, 0, len(state.HookTimings)),
+		Restarts:   state.Restarts,
+	}
+
+	for _, hook := range state.HookTimings {
+		task.Hooks = append(task.Hooks,
+			newTimelineSpan(hook.Name, hook.Phase, hook.StartedAt.UTC(), hook.FinishedAt.UTC()))
+	}
+	sort.SliceStable(task.Hooks, func(i, j int) bool {
+		return task.Hooks[i].Start.Before(task.Hooks[j].Start)
+	})
+
+	for i, event := range state.Events {
+		eventTime := time.Unix(0, event.Time).UTC()
+
+		switch {
+		case event.Type == TaskReceived:
+			task.ReceivedAt = earliestTime(task.ReceivedAt, eventTime)
+
+		case event.Type == TaskDriverMessage && event.DriverMessage == imagePullDriverMessage:
+			// The pull is done when the driver moves on to anything other
+			// than reporting progress. Only the most recent image pull is
+			// reported, earlier ones were for previous task restarts.
+			var end time.Time
+			for _, next := range state.Events[i+1:] {
+				if next.Type != TaskDriverMessage {
+					end = time.Unix(0, next.Time).UTC()
+					break
+				}
+			}
+			task.ImagePull = newTimelineSpan(event.Details["image"], "", eventTime, end)
+		}
+	}
+
+	return task
+}
+
+// earliestTime returns the earliest of the two times, ignoring zero values.
+func earliestTime(a, b time.Time) time.Time {
+	switch {
+	case a.IsZero():
+		return b
+	case b.IsZero():
+		return a
+	case b.Before(a):
+		return b
+	}
+	return a
+}
+
+// AllocMigrationIntroductionRequest is used by the client of an allocation to
+// get introduced to the client of its previous allocation, in order to
+// migrate the ephemeral disk data of the previous allocation directly from it.
+type AllocMigrationIntroductionRequest struct {
+	AllocID string
+	QueryOptions
+}
+
+// AllocMigrationIntroductionResponse is the introduction to the client of the
+// previous allocation of an allocation.
+type AllocMigrationIntroductionResponse struct {
+	PrevAllocID string
+
+	// NodeID and Address are the ID and HTTP address, including the scheme,
+	// of the client of the previous allocation.
+	NodeID  string
+	Address string
+
+	// Token is the one-time token the client of the previous allocation
+	// accepts to snapshot it, until it expires.
+	Token     string
+	ExpiresAt time.Time
+
+	QueryMeta
+}
+
+// AllocMigrationAuthorizeRequest is used by the servers to authorize a client
+// to snapshot an allocation of another client with a one-time token.
+type AllocMigrationAuthorizeRequest struct {
+	// PrevAllocID is the allocation to snapshot, and AllocID and NodeID the
+	// allocation and client its data is migrated to.
+	PrevAllocID string
+	AllocID     string
+	NodeID      string
+
+	Token     string
+	ExpiresAt time.Time
+
+	QueryOptions
+}
@@ -5,7 +5,10 @@ package structs
 
 import (
 	"testing"
+	"time"
 
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -143,3 +146,73 @@ func Test_Allocation_ServiceProviderNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAllocTimeline(t *testing.T) {
+	created := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return created.Add(d) }
+
+	alloc := &Allocation{
+		ID:         "alloc",
+		CreateTime: created.UnixNano(),
+		TaskStates: map[string]*TaskState{
+			"web": {
+				State:     TaskStateRunning,
+				StartedAt: at(20 * time.Second),
+				Restarts:  1,
+				Events: []*TaskEvent{
+					{Type: TaskReceived, Time: at(2 * time.Second).UnixNano()},
+					{Type: TaskDriverMessage, DriverMessage: imagePullDriverMessage,
+						Details: map[string]string{"image": "redis:7"}, Time: at(5 * time.Second).UnixNano()},
+					{Type: TaskDriverMessage, DriverMessage: "Image pull progress", Time: at(10 * time.Second).UnixNano()},
+					{Type: TaskStarted, Time: at(20 * time.Second).UnixNano()},
+				},
+				HookTimings: []*TaskHookTiming{
+					{Name: "artifacts", Phase: TaskHookPhasePrestart, StartedAt: at(4 * time.Second), FinishedAt: at(5 * time.Second)},
+					{Name: "validate", Phase: TaskHookPhasePrestart, StartedAt: at(3 * time.Second), FinishedAt: at(4 * time.Second)},
+				},
+			},
+			"sidecar": {
+				State:     TaskStateRunning,
+				StartedAt: at(15 * time.Second),
+				Events: []*TaskEvent{
+					{Type: TaskReceived, Time: at(time.Second).UnixNano()},
+				},
+			},
+		},
+		DeploymentStatus: &AllocDeploymentStatus{
+			Healthy:   pointer.Of(true),
+			Timestamp: at(45 * time.Second),
+		},
+	}
+
+	timeline := NewAllocTimeline(alloc)
+	must.Eq(t, "alloc", timeline.AllocID)
+	must.Eq(t, created, timeline.CreatedAt)
+	must.Eq(t, at(time.Second), timeline.ReceivedAt)
+	must.Eq(t, at(15*time.Second), timeline.RunningAt)
+	must.Eq(t, at(45*time.Second), timeline.HealthyAt)
+	must.Eq(t, 15*time.Second, timeline.PendingDuration)
+	must.Eq(t, 30*time.Second, timeline.HealthDuration)
+	must.MapLen(t, 2, timeline.Tasks)
+
+	web := timeline.Tasks["web"]
+	must.Eq(t, at(2*time.Second), web.ReceivedAt)
+	must.Eq(t, uint64(1), web.Restarts)
+	must.Len(t, 2, web.Hooks)
+	must.Eq(t, "validate", web.Hooks[0].Name)
+	must.Eq(t, "artifacts", web.Hooks[1].Name)
+	must.Eq(t, time.Second, web.Hooks[1].Duration)
+	must.NotNil(t, web.ImagePull)
+	must.Eq(t, "redis:7", web.ImagePull.Name)
+	must.Eq(t, 15*time.Second, web.ImagePull.Duration)
+
+	sidecar := timeline.Tasks["sidecar"]
+	must.Nil(t, sidecar.ImagePull)
+	must.SliceEmpty(t, sidecar.Hooks)
+
+	// An allocation that isn't healthy yet has no health duration.
+	alloc.DeploymentStatus = nil
+	timeline = NewAllocTimeline(alloc)
+	must.True(t, timeline.HealthyAt.IsZero())
+	must.Zero(t, timeline.HealthDuration)
+}
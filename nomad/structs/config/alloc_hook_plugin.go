@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"slices"
+	"time"
+)
+
+// DefaultAllocHookPluginTimeout is the default time the client waits for an
+// alloc hook plugin to handle an allocation.
+const DefaultAllocHookPluginTimeout = time.Minute
+
+// AllocHookPluginConfig is used to configure an external gRPC service that
+// takes part in the lifecycle of the allocations run by a client.
+type AllocHookPluginConfig struct {
+	// Name is the name of the plugin, used as the name of its hook.
+	Name string `hcl:",key"`
+
+	// Address is the address of the gRPC service, either as host:port or as
+	// unix:///path/to/socket.
+	Address string `hcl:"address"`
+
+	// Priority is the position of the hook relative to the built-in alloc
+	// runner hooks. The hook runs after all the built-in hooks if zero.
+	Priority int `hcl:"priority"`
+
+	// Requires is the list of the names of the alloc runner hooks that must
+	// complete before the hook runs. If set, the hook runs as soon as these
+	// hooks are done, concurrently with the other hooks.
+	Requires []string `hcl:"requires"`
+
+	// Timeout is the time the client waits for the plugin to handle an
+	// allocation before failing the hook.
+	Timeout    time.Duration `hcl:"-"`
+	TimeoutHCL string        `hcl:"timeout" json:"-"`
+
+	// CAFile, CertFile, and KeyFile configure TLS for the connection to the
+	// plugin. The connection is not encrypted if CAFile is empty.
+	CAFile   string `hcl:"ca_file"`
+	CertFile string `hcl:"cert_file"`
+	KeyFile  string `hcl:"key_file"`
+
+	// ExtraKeysHCL is used by hcl to surface unexpected keys
+	ExtraKeysHCL []string `hcl:",unusedKeys" json:"-"`
+}
+
+func (a *AllocHookPluginConfig) Copy() *AllocHookPluginConfig {
+	if a == nil {
+		return nil
+	}
+
+	na := *a
+	na.Requires = slices.Clone(a.Requires)
+	na.ExtraKeysHCL = slices.Clone(a.ExtraKeysHCL)
+	return &na
+}
+
+func (a *AllocHookPluginConfig) Merge(o *AllocHookPluginConfig) *AllocHookPluginConfig {
+	m := a.Copy()
+
+	if o.Address != "" {
+		m.Address = o.Address
+	}
+	if o.Priority != 0 {
+		m.Priority = o.Priority
+	}
+	if len(o.Requires) != 0 {
+		m.Requires = slices.Clone(o.Requires)
+	}
+	if o.Timeout != 0 {
+		m.Timeout = o.Timeout
+		m.TimeoutHCL = o.TimeoutHCL
+	}
+	if o.CAFile != "" {
+		m.CAFile = o.CAFile
+	}
+	if o.CertFile != "" {
+		m.CertFile = o.CertFile
+	}
+	if o.KeyFile != "" {
+		m.KeyFile = o.KeyFile
+	}
+
+	return m
+}
+
+// AllocHookPluginConfigSetMerge merges two sets of alloc hook plugin configs.
+// For plugins with the same name, the configs are merged.
+func AllocHookPluginConfigSetMerge(first, second []*AllocHookPluginConfig) []*AllocHookPluginConfig {
+	out := make([]*AllocHookPluginConfig, 0, len(first)+len(second))
+	index := make(map[string]int, len(first))
+	for _, p := range first {
+		index[p.Name] = len(out)
+		out = append(out, p.Copy())
+	}
+
+	for _, p := range second {
+		if i, ok := index[p.Name]; ok {
+			out[i] = out[i].Merge(p)
+			continue
+		}
+		index[p.Name] = len(out)
+		out = append(out, p.Copy())
+	}
+
+	return out
+}
@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestAllocHookPluginConfigSetMerge(t *testing.T) {
+	ci.Parallel(t)
+
+	first := []*AllocHookPluginConfig{
+		{
+			Name:     "foo",
+			Address:  "127.0.0.1:9000",
+			Priority: 950,
+			Timeout:  time.Second,
+		},
+		{
+			Name:    "bar",
+			Address: "unix:///tmp/bar.sock",
+		},
+	}
+	second := []*AllocHookPluginConfig{
+		{
+			Name:     "foo",
+			Address:  "127.0.0.1:9001",
+			Requires: []string{"network"},
+			CAFile:   "ca.pem",
+		},
+		{
+			Name:    "baz",
+			Address: "127.0.0.1:9002",
+		},
+	}
+
+	out := AllocHookPluginConfigSetMerge(first, second)
+	must.Eq(t, []*AllocHookPluginConfig{
+		{
+			Name:     "foo",
+			Address:  "127.0.0.1:9001",
+			Priority: 950,
+			Requires: []string{"network"},
+			Timeout:  time.Second,
+			CAFile:   "ca.pem",
+		},
+		{
+			Name:    "bar",
+			Address: "unix:///tmp/bar.sock",
+		},
+		{
+			Name:    "baz",
+			Address: "127.0.0.1:9002",
+		},
+	}, out)
+
+	// The inputs are not modified.
+	must.Eq(t, "127.0.0.1:9000", first[0].Address)
+}
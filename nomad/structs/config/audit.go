@@ -4,6 +4,7 @@
 package config
 
 import (
+	"fmt"
 	"slices"
 	"time"
 
@@ -22,6 +23,10 @@ type AuditConfig struct {
 	// from being written to a sink.
 	Filters []*AuditFilter `hcl:"filter"`
 
+	// Access configures the audit events recording the accesses to variables
+	// and to the keys of the keyring.
+	Access *AuditAccessConfig `hcl:"access"`
+
 	// ExtraKeysHCL is used by hcl to surface unexpected keys
 	ExtraKeysHCL []string `hcl:",unusedKeys" json:"-"`
 }
@@ -76,6 +81,19 @@ type AuditFilter struct {
 	Operations []string `hcl:"operations"`
 }
 
+// AuditAccessConfig configures the audit events recording who read or wrote
+// which variables, and which keys of the keyring were used to sign identities
+// or to encrypt and decrypt variables.
+type AuditAccessConfig struct {
+	// Enabled controls whether the access events are recorded.
+	Enabled *bool `hcl:"enabled"`
+
+	// SampleRate is the fraction of the reads of variables and of the
+	// signatures of identities that are recorded, between 0 and 1. The writes
+	// of variables are always recorded. Defaults to 1.
+	SampleRate *float64 `hcl:"sample_rate"`
+}
+
 // Copy returns a new copy of an AuditConfig
 func (a *AuditConfig) Copy() *AuditConfig {
 	if a == nil {
@@ -93,6 +111,7 @@ func (a *AuditConfig) Copy() *AuditConfig {
 	// Copy Sinks and Filters
 	nc.Sinks = copySliceAuditSink(nc.Sinks)
 	nc.Filters = copySliceAuditFilter(nc.Filters)
+	nc.Access = a.Access.Copy()
 
 	return nc
 }
@@ -119,9 +138,59 @@ func (a *AuditConfig) Merge(b *AuditConfig) *AuditConfig {
 		result.Filters = auditFilterSliceMerge(a.Filters, b.Filters)
 	}
 
+	if b.Access != nil {
+		result.Access = result.Access.Merge(b.Access)
+	}
+
+	return result
+}
+
+func (a *AuditAccessConfig) Copy() *AuditAccessConfig {
+	if a == nil {
+		return nil
+	}
+
+	return &AuditAccessConfig{
+		Enabled:    pointer.Copy(a.Enabled),
+		SampleRate: pointer.Copy(a.SampleRate),
+	}
+}
+
+func (a *AuditAccessConfig) Merge(b *AuditAccessConfig) *AuditAccessConfig {
+	if a == nil {
+		return b.Copy()
+	}
+	result := a.Copy()
+	if b == nil {
+		return result
+	}
+
+	result.Enabled = pointer.Merge(result.Enabled, b.Enabled)
+	result.SampleRate = pointer.Merge(result.SampleRate, b.SampleRate)
 	return result
 }
 
+// IsEnabled returns whether the access events are recorded.
+func (a *AuditAccessConfig) IsEnabled() bool {
+	return a != nil && a.Enabled != nil && *a.Enabled
+}
+
+// GetSampleRate returns the fraction of the reads and signatures recorded.
+func (a *AuditAccessConfig) GetSampleRate() float64 {
+	if a == nil || a.SampleRate == nil {
+		return 1
+	}
+	return *a.SampleRate
+}
+
+// Validate returns an error if the sample rate is out of bounds.
+func (a *AuditAccessConfig) Validate() error {
+	if rate := a.GetSampleRate(); rate < 0 || rate > 1 {
+		return fmt.Errorf("sample_rate must be between 0 and 1, got %v", rate)
+	}
+	return nil
+}
+
 func (a *AuditSink) Copy() *AuditSink {
 	if a == nil {
 		return nil
@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -110,3 +111,36 @@ func TestAuditConfig_Merge(t *testing.T) {
 
 	require.Equal(t, e, result)
 }
+
+func TestAuditAccessConfig_Merge(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *AuditAccessConfig
+	a := &AuditAccessConfig{Enabled: pointer.Of(true)}
+	b := &AuditAccessConfig{SampleRate: pointer.Of(0.1)}
+
+	must.Eq(t, a, nilConfig.Merge(a))
+	must.Eq(t, a, a.Merge(nil))
+	must.Eq(t, &AuditAccessConfig{
+		Enabled:    pointer.Of(true),
+		SampleRate: pointer.Of(0.1),
+	}, a.Merge(b))
+
+	// the access config is merged with the audit config
+	result := (&AuditConfig{Access: a}).Merge(&AuditConfig{Access: b})
+	must.Eq(t, 0.1, result.Access.GetSampleRate())
+	must.True(t, result.Access.IsEnabled())
+}
+
+func TestAuditAccessConfig_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *AuditAccessConfig
+	must.NoError(t, nilConfig.Validate())
+	must.False(t, nilConfig.IsEnabled())
+	must.Eq(t, 1.0, nilConfig.GetSampleRate())
+
+	must.NoError(t, (&AuditAccessConfig{SampleRate: pointer.Of(0.0)}).Validate())
+	must.ErrorContains(t, (&AuditAccessConfig{SampleRate: pointer.Of(1.5)}).Validate(), "sample_rate")
+	must.ErrorContains(t, (&AuditAccessConfig{SampleRate: pointer.Of(-0.1)}).Validate(), "sample_rate")
+}
@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import "github.com/hashicorp/nomad/helper/pointer"
+
+// DiskQuotaConfig describes how a client enforces the ephemeral disk size of
+// its allocations with filesystem project quotas.
+type DiskQuotaConfig struct {
+	// Enabled enforces the ephemeral disk size of allocations with project
+	// quotas. The filesystem of the alloc dir must be XFS or ext4 mounted
+	// with project quotas enabled.
+	Enabled *bool `hcl:"enabled"`
+
+	// WarningThreshold is the percentage of the ephemeral disk size after
+	// which a task event warns that an allocation is approaching its quota.
+	WarningThreshold *float64 `hcl:"warning_threshold"`
+
+	// CheckInterval is the interval at which the disk usage of allocations
+	// is checked.
+	CheckInterval *string `hcl:"check_interval"`
+}
+
+func (d *DiskQuotaConfig) Copy() *DiskQuotaConfig {
+	if d == nil {
+		return nil
+	}
+
+	nd := new(DiskQuotaConfig)
+	*nd = *d
+	return nd
+}
+
+func (d *DiskQuotaConfig) Merge(o *DiskQuotaConfig) *DiskQuotaConfig {
+	switch {
+	case d == nil:
+		return o.Copy()
+	case o == nil:
+		return d.Copy()
+	default:
+		nd := d.Copy()
+		if o.Enabled != nil {
+			nd.Enabled = pointer.Copy(o.Enabled)
+		}
+		if o.WarningThreshold != nil {
+			nd.WarningThreshold = pointer.Copy(o.WarningThreshold)
+		}
+		if o.CheckInterval != nil {
+			nd.CheckInterval = pointer.Copy(o.CheckInterval)
+		}
+		return nd
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import "github.com/hashicorp/nomad/helper/pointer"
+
+// DNSCacheConfig describes how a client caches the resolution of the hosts of
+// the upstream services its allocations depend on when starting, such as
+// Consul, Vault, and artifact sources.
+type DNSCacheConfig struct {
+	// Enabled caches the resolution of upstream hosts.
+	Enabled *bool `hcl:"enabled"`
+
+	// TTL is how long a resolution is used before the host is resolved
+	// again.
+	TTL *string `hcl:"ttl"`
+
+	// MaxStale is how long after its TTL a resolution is still used while
+	// the host is resolved again in the background, or if the resolver
+	// fails.
+	MaxStale *string `hcl:"max_stale"`
+}
+
+func (d *DNSCacheConfig) Copy() *DNSCacheConfig {
+	if d == nil {
+		return nil
+	}
+
+	nd := new(DNSCacheConfig)
+	*nd = *d
+	return nd
+}
+
+func (d *DNSCacheConfig) Merge(o *DNSCacheConfig) *DNSCacheConfig {
+	switch {
+	case d == nil:
+		return o.Copy()
+	case o == nil:
+		return d.Copy()
+	default:
+		nd := d.Copy()
+		if o.Enabled != nil {
+			nd.Enabled = pointer.Copy(o.Enabled)
+		}
+		if o.TTL != nil {
+			nd.TTL = pointer.Copy(o.TTL)
+		}
+		if o.MaxStale != nil {
+			nd.MaxStale = pointer.Copy(o.MaxStale)
+		}
+		return nd
+	}
+}
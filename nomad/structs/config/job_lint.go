@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// JobLintLevelOff disables a lint rule. It's the default level of all
+	// the rules.
+	JobLintLevelOff = "off"
+
+	// JobLintLevelWarn returns the violations of a lint rule as warnings,
+	// without rejecting the job.
+	JobLintLevelWarn = "warn"
+
+	// JobLintLevelDeny rejects the jobs violating a lint rule.
+	JobLintLevelDeny = "deny"
+)
+
+const (
+	// JobLintMissingHealthChecks reports services without health checks.
+	JobLintMissingHealthChecks = "missing_health_checks"
+
+	// JobLintMissingResources reports tasks using the default resources.
+	JobLintMissingResources = "missing_resources"
+
+	// JobLintLatestImageTag reports tasks using an image without a tag, or
+	// with the "latest" tag.
+	JobLintLatestImageTag = "latest_image_tag"
+
+	// JobLintMissingUpdate reports the task groups of service jobs without
+	// an update block.
+	JobLintMissingUpdate = "missing_update"
+)
+
+// JobLintConfig is used to configure the lint rules the servers evaluate
+// when jobs are planned or registered. Each rule has a level, which is one of
+// "off", "warn", or "deny".
+type JobLintConfig struct {
+	// MissingHealthChecks is the level of the rule reporting services
+	// without health checks.
+	MissingHealthChecks string `hcl:"missing_health_checks"`
+
+	// MissingResources is the level of the rule reporting tasks without a
+	// resources block, which run with the default resources.
+	MissingResources string `hcl:"missing_resources"`
+
+	// LatestImageTag is the level of the rule reporting tasks using an image
+	// without a tag, or with the "latest" tag.
+	LatestImageTag string `hcl:"latest_image_tag"`
+
+	// MissingUpdate is the level of the rule reporting the task groups of
+	// service jobs without an update block.
+	MissingUpdate string `hcl:"missing_update"`
+}
+
+func (j *JobLintConfig) Copy() *JobLintConfig {
+	if j == nil {
+		return nil
+	}
+
+	nj := *j
+	return &nj
+}
+
+func (j *JobLintConfig) Merge(o *JobLintConfig) *JobLintConfig {
+	if j == nil {
+		return o.Copy()
+	}
+	m := j.Copy()
+	if o == nil {
+		return m
+	}
+
+	if o.MissingHealthChecks != "" {
+		m.MissingHealthChecks = o.MissingHealthChecks
+	}
+	if o.MissingResources != "" {
+		m.MissingResources = o.MissingResources
+	}
+	if o.LatestImageTag != "" {
+		m.LatestImageTag = o.LatestImageTag
+	}
+	if o.MissingUpdate != "" {
+		m.MissingUpdate = o.MissingUpdate
+	}
+	return m
+}
+
+// Rules returns the level of each rule, by rule name. Rules without a level
+// are off.
+func (j *JobLintConfig) Rules() map[string]string {
+	rules := map[string]string{
+		JobLintMissingHealthChecks: JobLintLevelOff,
+		JobLintMissingResources:    JobLintLevelOff,
+		JobLintLatestImageTag:      JobLintLevelOff,
+		JobLintMissingUpdate:       JobLintLevelOff,
+	}
+	if j == nil {
+		return rules
+	}
+
+	for rule, level := range map[string]string{
+		JobLintMissingHealthChecks: j.MissingHealthChecks,
+		JobLintMissingResources:    j.MissingResources,
+		JobLintLatestImageTag:      j.LatestImageTag,
+		JobLintMissingUpdate:       j.MissingUpdate,
+	} {
+		if level != "" {
+			rules[rule] = level
+		}
+	}
+	return rules
+}
+
+// Validate returns an error if a rule has an unknown level.
+func (j *JobLintConfig) Validate() error {
+	rules := j.Rules()
+	names := make([]string, 0, len(rules))
+	for rule := range rules {
+		names = append(names, rule)
+	}
+	sort.Strings(names)
+
+	var mErr *multierror.Error
+	levels := []string{JobLintLevelOff, JobLintLevelWarn, JobLintLevelDeny}
+	for _, rule := range names {
+		if level := rules[rule]; !slices.Contains(levels, level) {
+			mErr = multierror.Append(mErr, fmt.Errorf(
+				"invalid level %q for job lint rule %q: must be one of %q, %q, or %q",
+				level, rule, JobLintLevelOff, JobLintLevelWarn, JobLintLevelDeny))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
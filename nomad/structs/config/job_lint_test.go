@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobLintConfig_Merge(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *JobLintConfig
+	must.Eq(t, JobLintLevelOff, nilConfig.Rules()[JobLintMissingUpdate])
+
+	a := &JobLintConfig{
+		MissingHealthChecks: JobLintLevelWarn,
+		LatestImageTag:      JobLintLevelWarn,
+	}
+	b := &JobLintConfig{
+		LatestImageTag: JobLintLevelDeny,
+		MissingUpdate:  JobLintLevelWarn,
+	}
+
+	must.Eq(t, map[string]string{
+		JobLintMissingHealthChecks: JobLintLevelWarn,
+		JobLintMissingResources:    JobLintLevelOff,
+		JobLintLatestImageTag:      JobLintLevelDeny,
+		JobLintMissingUpdate:       JobLintLevelWarn,
+	}, a.Merge(b).Rules())
+	must.Eq(t, b, nilConfig.Merge(b))
+	must.Eq(t, a, a.Merge(nil))
+}
+
+func TestJobLintConfig_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *JobLintConfig
+	must.NoError(t, nilConfig.Validate())
+	must.NoError(t, (&JobLintConfig{MissingResources: JobLintLevelDeny}).Validate())
+
+	err := (&JobLintConfig{
+		MissingResources: "error",
+		MissingUpdate:    "nope",
+	}).Validate()
+	must.ErrorContains(t, err, `invalid level "error" for job lint rule "missing_resources"`)
+	must.ErrorContains(t, err, `invalid level "nope" for job lint rule "missing_update"`)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// JobMetricsLabelJob is the label holding the job ID.
+	JobMetricsLabelJob = "job"
+
+	// JobMetricsLabelNamespace is the label holding the job namespace.
+	JobMetricsLabelNamespace = "namespace"
+
+	// JobMetricsLabelTaskGroup is the label holding the task group name.
+	JobMetricsLabelTaskGroup = "task_group"
+)
+
+// JobMetricsLabelsConfig controls the labels identifying jobs that the
+// servers attach to the metrics they emit per job, such as the job summary,
+// blocked evaluation, and deployment metrics. Clusters running many
+// short-lived jobs can use it to bound the cardinality of these metrics while
+// keeping per-job metrics for selected jobs.
+type JobMetricsLabelsConfig struct {
+	// Labels is the list of labels attached to the metrics among "job",
+	// "namespace", and "task_group". Defaults to all of them.
+	Labels []string `hcl:"labels"`
+
+	// AllowJobs is a list of glob patterns matching the IDs of the jobs whose
+	// metrics keep their job label. Defaults to all the jobs.
+	AllowJobs []string `hcl:"allow_jobs"`
+
+	// DenyJobs is a list of glob patterns matching the IDs of the jobs whose
+	// metrics don't keep their job label. It takes precedence over AllowJobs.
+	DenyJobs []string `hcl:"deny_jobs"`
+
+	// OtherJobs is the value the job label is set to for the jobs that are
+	// not allowed, so their metrics are aggregated together. If empty, the
+	// metrics of these jobs are not emitted.
+	OtherJobs string `hcl:"other_jobs"`
+
+	// Rename maps the name of a label to the name it is emitted with.
+	Rename map[string]string `hcl:"rename"`
+}
+
+func (j *JobMetricsLabelsConfig) Copy() *JobMetricsLabelsConfig {
+	if j == nil {
+		return nil
+	}
+
+	nj := *j
+	nj.Labels = slices.Clone(j.Labels)
+	nj.AllowJobs = slices.Clone(j.AllowJobs)
+	nj.DenyJobs = slices.Clone(j.DenyJobs)
+	nj.Rename = maps.Clone(j.Rename)
+	return &nj
+}
+
+func (j *JobMetricsLabelsConfig) Merge(o *JobMetricsLabelsConfig) *JobMetricsLabelsConfig {
+	if j == nil {
+		return o.Copy()
+	}
+	m := j.Copy()
+	if o == nil {
+		return m
+	}
+
+	if len(o.Labels) != 0 {
+		m.Labels = slices.Clone(o.Labels)
+	}
+	if len(o.AllowJobs) != 0 {
+		m.AllowJobs = slices.Clone(o.AllowJobs)
+	}
+	if len(o.DenyJobs) != 0 {
+		m.DenyJobs = slices.Clone(o.DenyJobs)
+	}
+	if o.OtherJobs != "" {
+		m.OtherJobs = o.OtherJobs
+	}
+	if len(o.Rename) != 0 {
+		if m.Rename == nil {
+			m.Rename = make(map[string]string, len(o.Rename))
+		}
+		maps.Copy(m.Rename, o.Rename)
+	}
+	return m
+}
+
+// Validate returns an error if a label is unknown or renamed to an empty
+// name.
+func (j *JobMetricsLabelsConfig) Validate() error {
+	if j == nil {
+		return nil
+	}
+
+	var mErr *multierror.Error
+	known := []string{JobMetricsLabelJob, JobMetricsLabelNamespace, JobMetricsLabelTaskGroup}
+	for _, label := range j.Labels {
+		if !slices.Contains(known, label) {
+			mErr = multierror.Append(mErr, fmt.Errorf(
+				"invalid job metrics label %q: must be one of %q, %q, or %q",
+				label, JobMetricsLabelJob, JobMetricsLabelNamespace, JobMetricsLabelTaskGroup))
+		}
+	}
+
+	names := make([]string, 0, len(j.Rename))
+	for name := range j.Rename {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if j.Rename[name] == "" {
+			mErr = multierror.Append(mErr, fmt.Errorf("job metrics label %q cannot be renamed to an empty name", name))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobMetricsLabelsConfig_Merge(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *JobMetricsLabelsConfig
+
+	a := &JobMetricsLabelsConfig{
+		Labels:    []string{JobMetricsLabelJob},
+		AllowJobs: []string{"api-*"},
+		Rename:    map[string]string{"job": "nomad_job"},
+	}
+	b := &JobMetricsLabelsConfig{
+		DenyJobs:  []string{"*-canary"},
+		OtherJobs: "other",
+		Rename:    map[string]string{"namespace": "nomad_namespace"},
+	}
+
+	must.Eq(t, &JobMetricsLabelsConfig{
+		Labels:    []string{JobMetricsLabelJob},
+		AllowJobs: []string{"api-*"},
+		DenyJobs:  []string{"*-canary"},
+		OtherJobs: "other",
+		Rename: map[string]string{
+			"job":       "nomad_job",
+			"namespace": "nomad_namespace",
+		},
+	}, a.Merge(b))
+	must.Eq(t, b, nilConfig.Merge(b))
+	must.Eq(t, a, a.Merge(nil))
+
+	// Merging doesn't modify the receiver
+	must.MapLen(t, 1, a.Rename)
+}
+
+func TestJobMetricsLabelsConfig_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *JobMetricsLabelsConfig
+	must.NoError(t, nilConfig.Validate())
+	must.NoError(t, (&JobMetricsLabelsConfig{
+		Labels: []string{JobMetricsLabelJob, JobMetricsLabelNamespace},
+		Rename: map[string]string{"job": "nomad_job"},
+	}).Validate())
+
+	err := (&JobMetricsLabelsConfig{
+		Labels: []string{"job", "alloc"},
+		Rename: map[string]string{"job": ""},
+	}).Validate()
+	must.ErrorContains(t, err, `invalid job metrics label "alloc"`)
+	must.ErrorContains(t, err, `job metrics label "job" cannot be renamed to an empty name`)
+}
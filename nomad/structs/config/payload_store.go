@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/nomad/helper/pointer"
+)
+
+// DefaultPayloadStoreThreshold is the default size above which dispatch
+// payloads and job sources are kept in the payload store.
+const DefaultPayloadStoreThreshold = "16KiB"
+
+// PayloadStoreConfig is used to configure the object store the servers keep
+// the dispatch payloads and job sources above a size threshold in, so only a
+// reference to them is written to the Raft log.
+type PayloadStoreConfig struct {
+	// Provider is the type of object store, such as "s3" or "gcs".
+	Provider string `hcl:"provider"`
+
+	// Threshold is the size above which the dispatch payloads and job sources
+	// are kept in the object store. Defaults to 16KiB.
+	Threshold *string `hcl:"threshold"`
+
+	// Config is the configuration of the provider.
+	Config map[string]string `hcl:"config"`
+}
+
+func (p *PayloadStoreConfig) Copy() *PayloadStoreConfig {
+	if p == nil {
+		return nil
+	}
+
+	np := *p
+	np.Threshold = pointer.Copy(p.Threshold)
+	np.Config = maps.Clone(p.Config)
+	return &np
+}
+
+func (p *PayloadStoreConfig) Merge(o *PayloadStoreConfig) *PayloadStoreConfig {
+	if p == nil {
+		return o.Copy()
+	}
+	m := p.Copy()
+	if o == nil {
+		return m
+	}
+
+	if o.Provider != "" {
+		m.Provider = o.Provider
+	}
+	m.Threshold = pointer.Merge(m.Threshold, o.Threshold)
+	if len(o.Config) != 0 {
+		if m.Config == nil {
+			m.Config = make(map[string]string, len(o.Config))
+		}
+		for k, v := range o.Config {
+			m.Config[k] = v
+		}
+	}
+
+	return m
+}
+
+// Validate returns an error if the provider is missing or the threshold is
+// invalid.
+func (p *PayloadStoreConfig) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.Provider == "" {
+		return errors.New("provider must be set")
+	}
+	_, err := p.ThresholdBytes()
+	return err
+}
+
+// ThresholdBytes returns the size above which the dispatch payloads and job
+// sources are kept in the object store.
+func (p *PayloadStoreConfig) ThresholdBytes() (int, error) {
+	threshold := DefaultPayloadStoreThreshold
+	if p.Threshold != nil {
+		threshold = *p.Threshold
+	}
+	size, err := humanize.ParseBytes(threshold)
+	if err != nil {
+		return 0, fmt.Errorf("invalid threshold %q: %w", threshold, err)
+	}
+	return int(size), nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/pointer"
+	"github.com/shoenig/test/must"
+)
+
+func TestPayloadStoreConfig_Merge(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *PayloadStoreConfig
+	a := &PayloadStoreConfig{
+		Provider: "s3",
+		Config:   map[string]string{"bucket": "nomad", "region": "us-east-1"},
+	}
+	b := &PayloadStoreConfig{
+		Threshold: pointer.Of("1MiB"),
+		Config:    map[string]string{"region": "eu-west-1"},
+	}
+
+	must.Eq(t, a, nilConfig.Merge(a))
+	must.Eq(t, a, a.Merge(nil))
+
+	result := a.Merge(b)
+	must.Eq(t, &PayloadStoreConfig{
+		Provider:  "s3",
+		Threshold: pointer.Of("1MiB"),
+		Config:    map[string]string{"bucket": "nomad", "region": "eu-west-1"},
+	}, result)
+
+	// The merged configs aren't modified
+	must.Eq(t, "us-east-1", a.Config["region"])
+}
+
+func TestPayloadStoreConfig_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *PayloadStoreConfig
+	must.NoError(t, nilConfig.Validate())
+
+	must.ErrorContains(t, (&PayloadStoreConfig{}).Validate(), "provider must be set")
+	must.ErrorContains(t, (&PayloadStoreConfig{
+		Provider:  "s3",
+		Threshold: pointer.Of("big"),
+	}).Validate(), "invalid threshold")
+
+	c := &PayloadStoreConfig{Provider: "s3"}
+	must.NoError(t, c.Validate())
+	size, err := c.ThresholdBytes()
+	must.NoError(t, err)
+	must.Eq(t, 16*1024, size)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"slices"
+	"time"
+)
+
+// DefaultScoringPluginTimeout is the default time the scheduler waits for a
+// scoring plugin to score a node.
+const DefaultScoringPluginTimeout = 100 * time.Millisecond
+
+// ScoringPluginConfig is used to configure an external gRPC service that
+// adjusts the score of nodes during placements.
+type ScoringPluginConfig struct {
+	// Name is the name of the plugin, reported in the placement metrics.
+	Name string `hcl:",key"`
+
+	// Address is the address of the gRPC service, either as host:port or as
+	// unix:///path/to/socket.
+	Address string `hcl:"address"`
+
+	// JobTypes are the types of jobs scored by the plugin. All the service
+	// and batch jobs are scored if empty.
+	JobTypes []string `hcl:"job_types"`
+
+	// Timeout is the time the scheduler waits for the plugin to score a node
+	// before falling back to the built-in scoring.
+	Timeout    time.Duration `hcl:"-"`
+	TimeoutHCL string        `hcl:"timeout" json:"-"`
+
+	// CAFile, CertFile, and KeyFile configure TLS for the connection to the
+	// plugin. The connection is not encrypted if CAFile is empty.
+	CAFile   string `hcl:"ca_file"`
+	CertFile string `hcl:"cert_file"`
+	KeyFile  string `hcl:"key_file"`
+
+	// ExtraKeysHCL is used by hcl to surface unexpected keys
+	ExtraKeysHCL []string `hcl:",unusedKeys" json:"-"`
+}
+
+func (s *ScoringPluginConfig) Copy() *ScoringPluginConfig {
+	if s == nil {
+		return nil
+	}
+
+	ns := *s
+	ns.JobTypes = slices.Clone(s.JobTypes)
+	ns.ExtraKeysHCL = slices.Clone(s.ExtraKeysHCL)
+	return &ns
+}
+
+func (s *ScoringPluginConfig) Merge(o *ScoringPluginConfig) *ScoringPluginConfig {
+	m := s.Copy()
+
+	if o.Address != "" {
+		m.Address = o.Address
+	}
+	if len(o.JobTypes) != 0 {
+		m.JobTypes = slices.Clone(o.JobTypes)
+	}
+	if o.Timeout != 0 {
+		m.Timeout = o.Timeout
+		m.TimeoutHCL = o.TimeoutHCL
+	}
+	if o.CAFile != "" {
+		m.CAFile = o.CAFile
+	}
+	if o.CertFile != "" {
+		m.CertFile = o.CertFile
+	}
+	if o.KeyFile != "" {
+		m.KeyFile = o.KeyFile
+	}
+
+	return m
+}
+
+// ScoringPluginConfigSetMerge merges two sets of scoring plugin configs. For
+// plugins with the same name, the configs are merged.
+func ScoringPluginConfigSetMerge(first, second []*ScoringPluginConfig) []*ScoringPluginConfig {
+	out := make([]*ScoringPluginConfig, 0, len(first)+len(second))
+	index := make(map[string]int, len(first))
+	for _, p := range first {
+		index[p.Name] = len(out)
+		out = append(out, p.Copy())
+	}
+
+	for _, p := range second {
+		if i, ok := index[p.Name]; ok {
+			out[i] = out[i].Merge(p)
+			continue
+		}
+		index[p.Name] = len(out)
+		out = append(out, p.Copy())
+	}
+
+	return out
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestScoringPluginConfigSetMerge(t *testing.T) {
+	ci.Parallel(t)
+
+	first := []*ScoringPluginConfig{
+		{
+			Name:     "foo",
+			Address:  "127.0.0.1:9000",
+			JobTypes: []string{"service"},
+			Timeout:  time.Second,
+		},
+		{
+			Name:    "bar",
+			Address: "unix:///tmp/bar.sock",
+		},
+	}
+	second := []*ScoringPluginConfig{
+		{
+			Name:    "foo",
+			Address: "127.0.0.1:9001",
+			CAFile:  "ca.pem",
+		},
+		{
+			Name:    "baz",
+			Address: "127.0.0.1:9002",
+		},
+	}
+
+	out := ScoringPluginConfigSetMerge(first, second)
+	must.Eq(t, []*ScoringPluginConfig{
+		{
+			Name:     "foo",
+			Address:  "127.0.0.1:9001",
+			JobTypes: []string{"service"},
+			Timeout:  time.Second,
+			CAFile:   "ca.pem",
+		},
+		{
+			Name:    "bar",
+			Address: "unix:///tmp/bar.sock",
+		},
+		{
+			Name:    "baz",
+			Address: "127.0.0.1:9002",
+		},
+	}, out)
+
+	// The inputs are not modified.
+	must.Eq(t, "127.0.0.1:9000", first[0].Address)
+}
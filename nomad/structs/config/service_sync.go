@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// DefaultServiceSyncInterval is the default period between two full
+// reconciliations of the services exported to an external registry.
+const DefaultServiceSyncInterval = 30 * time.Second
+
+// ServiceSyncConfig is used to configure the export of the Nomad native
+// services to an external registry, such as Consul, a DNS provider, or the
+// target groups of a cloud load balancer.
+type ServiceSyncConfig struct {
+	// Name is the name of the sync, reported in the logs and metrics.
+	Name string `hcl:",key"`
+
+	// Provider is the type of external registry the services are exported
+	// to, such as "consul", "route53", or "elbv2".
+	Provider string `hcl:"provider"`
+
+	// Namespaces are the namespaces of the exported services. The services
+	// of all the namespaces are exported if empty.
+	Namespaces []string `hcl:"namespaces"`
+
+	// Tags restrict the exported services to the ones with at least one of
+	// these tags. All the services are exported if empty.
+	Tags []string `hcl:"tags"`
+
+	// Interval is the period between two full reconciliations of the
+	// external registry, which correct the changes made outside of Nomad.
+	// Changes to the services are exported as soon as they happen.
+	Interval    time.Duration `hcl:"-"`
+	IntervalHCL string        `hcl:"interval" json:"-"`
+
+	// Config is the configuration of the provider.
+	Config map[string]string `hcl:"config"`
+
+	// ExtraKeysHCL is used by hcl to surface unexpected keys
+	ExtraKeysHCL []string `hcl:",unusedKeys" json:"-"`
+}
+
+func (s *ServiceSyncConfig) Copy() *ServiceSyncConfig {
+	if s == nil {
+		return nil
+	}
+
+	ns := *s
+	ns.Namespaces = slices.Clone(s.Namespaces)
+	ns.Tags = slices.Clone(s.Tags)
+	ns.Config = maps.Clone(s.Config)
+	ns.ExtraKeysHCL = slices.Clone(s.ExtraKeysHCL)
+	return &ns
+}
+
+func (s *ServiceSyncConfig) Merge(o *ServiceSyncConfig) *ServiceSyncConfig {
+	m := s.Copy()
+
+	if o.Provider != "" {
+		m.Provider = o.Provider
+	}
+	if len(o.Namespaces) != 0 {
+		m.Namespaces = slices.Clone(o.Namespaces)
+	}
+	if len(o.Tags) != 0 {
+		m.Tags = slices.Clone(o.Tags)
+	}
+	if o.Interval != 0 {
+		m.Interval = o.Interval
+		m.IntervalHCL = o.IntervalHCL
+	}
+	if len(o.Config) != 0 {
+		if m.Config == nil {
+			m.Config = make(map[string]string, len(o.Config))
+		}
+		for k, v := range o.Config {
+			m.Config[k] = v
+		}
+	}
+
+	return m
+}
+
+// ServiceSyncConfigSetMerge merges two sets of service sync configs. For
+// syncs with the same name, the configs are merged.
+func ServiceSyncConfigSetMerge(first, second []*ServiceSyncConfig) []*ServiceSyncConfig {
+	out := make([]*ServiceSyncConfig, 0, len(first)+len(second))
+	index := make(map[string]int, len(first))
+	for _, s := range first {
+		index[s.Name] = len(out)
+		out = append(out, s.Copy())
+	}
+
+	for _, s := range second {
+		if i, ok := index[s.Name]; ok {
+			out[i] = out[i].Merge(s)
+			continue
+		}
+		index[s.Name] = len(out)
+		out = append(out, s.Copy())
+	}
+
+	return out
+}
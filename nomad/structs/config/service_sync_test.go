@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestServiceSyncConfigSetMerge(t *testing.T) {
+	ci.Parallel(t)
+
+	first := []*ServiceSyncConfig{
+		{
+			Name:       "consul",
+			Provider:   "consul",
+			Namespaces: []string{"default"},
+			Interval:   time.Minute,
+			Config:     map[string]string{"address": "127.0.0.1:8500"},
+		},
+		{
+			Name:     "dns",
+			Provider: "route53",
+		},
+	}
+	second := []*ServiceSyncConfig{
+		{
+			Name:   "consul",
+			Tags:   []string{"public"},
+			Config: map[string]string{"token": "secret"},
+		},
+		{
+			Name:     "lb",
+			Provider: "elbv2",
+		},
+	}
+
+	out := ServiceSyncConfigSetMerge(first, second)
+	must.Eq(t, []*ServiceSyncConfig{
+		{
+			Name:       "consul",
+			Provider:   "consul",
+			Namespaces: []string{"default"},
+			Tags:       []string{"public"},
+			Interval:   time.Minute,
+			Config: map[string]string{
+				"address": "127.0.0.1:8500",
+				"token":   "secret",
+			},
+		},
+		{
+			Name:     "dns",
+			Provider: "route53",
+		},
+		{
+			Name:     "lb",
+			Provider: "elbv2",
+		},
+	}, out)
+
+	// The inputs are not modified.
+	must.MapNotContainsKey(t, first[0].Config, "token")
+	must.SliceEmpty(t, first[0].Tags)
+}
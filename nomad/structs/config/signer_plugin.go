@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"slices"
+	"time"
+)
+
+// DefaultSignerPluginTimeout is the default time a server waits for the
+// signer plugin to respond.
+const DefaultSignerPluginTimeout = 5 * time.Second
+
+// SignerPluginConfig is used to configure an external gRPC service that signs
+// workload identities in place of the keyring, such as a service backed by a
+// cloud KMS or an HSM.
+type SignerPluginConfig struct {
+	// Address is the address of the gRPC service, either as host:port or as
+	// unix:///path/to/socket.
+	Address string `hcl:"address"`
+
+	// Timeout is the time a server waits for the plugin to respond.
+	Timeout    time.Duration `hcl:"-"`
+	TimeoutHCL string        `hcl:"timeout" json:"-"`
+
+	// CAFile, CertFile, and KeyFile configure TLS for the connection to the
+	// plugin. CAFile is required unless the plugin is reached over a unix
+	// socket.
+	CAFile   string `hcl:"ca_file"`
+	CertFile string `hcl:"cert_file"`
+	KeyFile  string `hcl:"key_file"`
+
+	// ExtraKeysHCL is used by hcl to surface unexpected keys
+	ExtraKeysHCL []string `hcl:",unusedKeys" json:"-"`
+}
+
+func (s *SignerPluginConfig) Copy() *SignerPluginConfig {
+	if s == nil {
+		return nil
+	}
+
+	ns := *s
+	ns.ExtraKeysHCL = slices.Clone(s.ExtraKeysHCL)
+	return &ns
+}
+
+func (s *SignerPluginConfig) Merge(o *SignerPluginConfig) *SignerPluginConfig {
+	if s == nil {
+		return o.Copy()
+	}
+	m := s.Copy()
+	if o == nil {
+		return m
+	}
+
+	if o.Address != "" {
+		m.Address = o.Address
+	}
+	if o.Timeout != 0 {
+		m.Timeout = o.Timeout
+		m.TimeoutHCL = o.TimeoutHCL
+	}
+	if o.CAFile != "" {
+		m.CAFile = o.CAFile
+	}
+	if o.CertFile != "" {
+		m.CertFile = o.CertFile
+	}
+	if o.KeyFile != "" {
+		m.KeyFile = o.KeyFile
+	}
+
+	return m
+}
@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestSignerPluginConfig_Merge(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConf *SignerPluginConfig
+	first := &SignerPluginConfig{
+		Address: "127.0.0.1:9000",
+		Timeout: time.Second,
+		CAFile:  "ca.pem",
+	}
+	second := &SignerPluginConfig{
+		Address:  "unix:///run/signer.sock",
+		CertFile: "cert.pem",
+		KeyFile:  "key.pem",
+	}
+
+	must.Eq(t, first, nilConf.Merge(first))
+	must.Eq(t, first, first.Merge(nil))
+
+	must.Eq(t, &SignerPluginConfig{
+		Address:  "unix:///run/signer.sock",
+		Timeout:  time.Second,
+		CAFile:   "ca.pem",
+		CertFile: "cert.pem",
+		KeyFile:  "key.pem",
+	}, first.Merge(second))
+
+	// merging doesn't modify the configs
+	must.Eq(t, "127.0.0.1:9000", first.Address)
+}
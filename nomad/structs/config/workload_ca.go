@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package config
+
+import (
+	"errors"
+)
+
+// WorkloadCAConfig is used to configure the CA the servers use to sign the
+// X.509 certificates of workload identities. An external CA can be used by
+// configuring an intermediate CA it issued, or the servers can derive a
+// builtin CA from their keyring.
+type WorkloadCAConfig struct {
+	// Builtin enables the CA derived from the keyring of the servers, in
+	// place of the CA of CertFile and KeyFile. The CA rotates with the root
+	// keys of the keyring.
+	Builtin bool `hcl:"builtin"`
+
+	// CertFile is the path to the PEM encoded certificate of the CA, which
+	// may be followed by the certificates of its parent CAs.
+	CertFile string `hcl:"cert_file"`
+
+	// KeyFile is the path to the PEM encoded private key of the CA.
+	KeyFile string `hcl:"key_file"`
+
+	// TrustDomain is the SPIFFE trust domain of the workload certificates.
+	// Defaults to "nomad".
+	TrustDomain string `hcl:"trust_domain"`
+}
+
+func (w *WorkloadCAConfig) Copy() *WorkloadCAConfig {
+	if w == nil {
+		return nil
+	}
+
+	nw := *w
+	return &nw
+}
+
+func (w *WorkloadCAConfig) Merge(o *WorkloadCAConfig) *WorkloadCAConfig {
+	if w == nil {
+		return o.Copy()
+	}
+	m := w.Copy()
+	if o == nil {
+		return m
+	}
+
+	if o.Builtin {
+		m.Builtin = true
+	}
+	if o.CertFile != "" {
+		m.CertFile = o.CertFile
+	}
+	if o.KeyFile != "" {
+		m.KeyFile = o.KeyFile
+	}
+	if o.TrustDomain != "" {
+		m.TrustDomain = o.TrustDomain
+	}
+	return m
+}
+
+// Validate returns an error if the certificate or key of the CA is missing,
+// or if they are set along with the builtin CA.
+func (w *WorkloadCAConfig) Validate() error {
+	if w == nil {
+		return nil
+	}
+	if w.Builtin {
+		if w.CertFile != "" || w.KeyFile != "" {
+			return errors.New("cert_file and key_file can't be set with builtin")
+		}
+		return nil
+	}
+	if w.CertFile == "" || w.KeyFile == "" {
+		return errors.New("cert_file and key_file must both be set")
+	}
+	return nil
+}
@@ -73,7 +73,7 @@ func (j *Job) Diff(other *Job, contextual bool) (*JobDiff, error) {
 	// See agent.ApiJobToStructJob Update is a default for TaskGroups
 	diff := &JobDiff{Type: DiffTypeNone}
 	var oldPrimitiveFlat, newPrimitiveFlat map[string]string
-	filter := []string{"ID", "Status", "StatusDescription", "Version", "Stable", "CreateIndex",
+	filter := []string{"ID", "Status", "StatusDescription", "Version", "Stable", "Suspended", "CreateIndex",
 		"ModifyIndex", "JobModifyIndex", "Update", "SubmitTime", "NomadTokenID", "VaultToken"}
 
 	if j == nil && other == nil {
@@ -150,6 +150,11 @@ func (j *Job) Diff(other *Job, contextual bool) (*JobDiff, error) {
 		diff.Objects = append(diff.Objects, mrDiff)
 	}
 
+	// ActiveSchedule diff
+	if asDiff := activeScheduleDiff(j.ActiveSchedule, other.ActiveSchedule, contextual); asDiff != nil {
+		diff.Objects = append(diff.Objects, asDiff)
+	}
+
 	// Check to see if there is a diff. We don't use reflect because we are
 	// filtering quite a few fields that will change on each diff.
 	if diff.Type == DiffTypeNone {
@@ -321,6 +326,12 @@ func (tg *TaskGroup) Diff(other *TaskGroup, contextual bool) (*TaskGroupDiff, er
 		diff.Objects = append(diff.Objects, reschedDiff)
 	}
 
+	// Disconnect strategy diff
+	disconnectDiff := primitiveObjectDiff(tg.Disconnect, other.Disconnect, nil, "Disconnect", contextual)
+	if disconnectDiff != nil {
+		diff.Objects = append(diff.Objects, disconnectDiff)
+	}
+
 	// EphemeralDisk diff
 	diskDiff := primitiveObjectDiff(tg.EphemeralDisk, other.EphemeralDisk, nil, "EphemeralDisk", contextual)
 	if diskDiff != nil {
@@ -334,10 +345,28 @@ func (tg *TaskGroup) Diff(other *TaskGroup, contextual bool) (*TaskGroupDiff, er
 
 	// Update diff
 	// COMPAT: Remove "Stagger" in 0.7.0.
-	if uDiff := primitiveObjectDiff(tg.Update, other.Update, []string{"Stagger"}, "Update", contextual); uDiff != nil {
+	uDiff := primitiveObjectDiff(tg.Update, other.Update, []string{"Stagger"}, "Update", contextual)
+	if hcDiff := updateHealthChecksDiff(tg.Update, other.Update, contextual); hcDiff != nil {
+		if uDiff == nil {
+			uDiff = &ObjectDiff{Type: DiffTypeEdited, Name: "Update"}
+		}
+		uDiff.Objects = append(uDiff.Objects, hcDiff)
+	}
+	if uDiff != nil {
 		diff.Objects = append(diff.Objects, uDiff)
 	}
 
+	// Kill escalation diff
+	kDiffs := primitiveObjectSetDiff(
+		interfaceSlice(tg.KillEscalation),
+		interfaceSlice(other.KillEscalation),
+		nil,
+		"KillEscalation",
+		contextual)
+	if kDiffs != nil {
+		diff.Objects = append(diff.Objects, kDiffs...)
+	}
+
 	// Network Resources diff
 	if nDiffs := networkResourceDiffs(tg.Networks, other.Networks, contextual); nDiffs != nil {
 		diff.Objects = append(diff.Objects, nDiffs...)
@@ -537,6 +566,17 @@ func (t *Task) Diff(other *Task, contextual bool) (*TaskDiff, error) {
 		diff.Objects = append(diff.Objects, diffs...)
 	}
 
+	// Kill escalation diff
+	kDiffs := primitiveObjectSetDiff(
+		interfaceSlice(t.KillEscalation),
+		interfaceSlice(other.KillEscalation),
+		nil,
+		"KillEscalation",
+		contextual)
+	if kDiffs != nil {
+		diff.Objects = append(diff.Objects, kDiffs...)
+	}
+
 	// Services diff
 	if sDiffs := serviceDiffs(t.Services, other.Services, contextual); sDiffs != nil {
 		diff.Objects = append(diff.Objects, sDiffs...)
@@ -704,6 +744,22 @@ func (t TaskDiffs) Len() int           { return len(t) }
 func (t TaskDiffs) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
 func (t TaskDiffs) Less(i, j int) bool { return t[i].Name < t[j].Name }
 
+// updateHealthChecksDiff returns the diff of the health checks of two update
+// strategies, or nil if neither has health checks.
+func updateHealthChecksDiff(old, new *UpdateStrategy, contextual bool) *ObjectDiff {
+	var oldChecks, newChecks []string
+	if old != nil {
+		oldChecks = old.HealthChecks
+	}
+	if new != nil {
+		newChecks = new.HealthChecks
+	}
+	if len(oldChecks) == 0 && len(newChecks) == 0 {
+		return nil
+	}
+	return stringSetDiff(oldChecks, newChecks, "HealthChecks", contextual)
+}
+
 // scalingDiff returns the diff of two Scaling objects. If contextual diff is enabled, unchanged
 // fields within objects nested in the tasks will be returned.
 func scalingDiff(old, new *ScalingPolicy, contextual bool) *ObjectDiff {
@@ -813,6 +869,11 @@ func serviceDiff(old, new *Service, contextual bool) *ObjectDiff {
 		diff.Objects = append(diff.Objects, wiDiffs)
 	}
 
+	// Certificate diffs
+	if certDiff := primitiveObjectDiff(old.Certificate, new.Certificate, nil, "Certificate", contextual); certDiff != nil {
+		diff.Objects = append(diff.Objects, certDiff)
+	}
+
 	return diff
 }
 
@@ -2336,6 +2397,9 @@ func (d *DNSConfig) Diff(other *DNSConfig, contextual bool) *ObjectDiff {
 		if len(conf.Options) > 0 {
 			m["Options"] = strings.Join(conf.Options, ",")
 		}
+		if len(conf.ServiceHosts) > 0 {
+			m["ServiceHosts"] = strings.Join(conf.ServiceHosts, ",")
+		}
 		return m
 	}
 
@@ -2619,6 +2683,18 @@ func idDiff(oldWI, newWI *WorkloadIdentity, contextual bool) *ObjectDiff {
 		diff.Objects = append(diff.Objects, audDiff)
 	}
 
+	if csDiff := changeScriptDiff(oldWI.ChangeScript, newWI.ChangeScript, contextual); csDiff != nil {
+		diff.Objects = append(diff.Objects, csDiff)
+	}
+
+	if pDiff := primitiveObjectDiff(oldWI.Projection, newWI.Projection, nil, "Projection", contextual); pDiff != nil {
+		diff.Objects = append(diff.Objects, pDiff)
+	}
+
+	if sDiff := primitiveObjectDiff(oldWI.SPIFFE, newWI.SPIFFE, nil, "SPIFFE", contextual); sDiff != nil {
+		diff.Objects = append(diff.Objects, sDiff)
+	}
+
 	// Diff the primitive fields.
 	diff.Fields = fieldDiffs(oldPrimitiveFlat, newPrimitiveFlat, contextual)
 
@@ -2877,6 +2953,53 @@ func periodicDiff(old, new *PeriodicConfig, contextual bool) *ObjectDiff {
 	return diff
 }
 
+// activeScheduleDiff returns the diff of the active schedules of a job. The
+// override and the suspended counts are ignored since the server manages them.
+func activeScheduleDiff(old, new *JobActiveSchedule, contextual bool) *ObjectDiff {
+	if old != nil {
+		old = &JobActiveSchedule{TimeZone: old.TimeZone, Windows: old.Windows}
+	}
+	if new != nil {
+		new = &JobActiveSchedule{TimeZone: new.TimeZone, Windows: new.Windows}
+	}
+
+	diff := &ObjectDiff{Type: DiffTypeNone, Name: "ActiveSchedule"}
+	var oldFlat, newFlat map[string]string
+
+	if reflect.DeepEqual(old, new) {
+		return nil
+	} else if old == nil {
+		old = &JobActiveSchedule{}
+		diff.Type = DiffTypeAdded
+		newFlat = flatmap.Flatten(new, nil, true)
+	} else if new == nil {
+		new = &JobActiveSchedule{}
+		diff.Type = DiffTypeDeleted
+		oldFlat = flatmap.Flatten(old, nil, true)
+	} else {
+		diff.Type = DiffTypeEdited
+		oldFlat = flatmap.Flatten(old, nil, true)
+		newFlat = flatmap.Flatten(new, nil, true)
+	}
+
+	// Diff the primitive fields.
+	diff.Fields = fieldDiffs(oldFlat, newFlat, contextual)
+
+	// Windows diff
+	windowsDiff := primitiveObjectSetDiff(
+		interfaceSlice(old.Windows),
+		interfaceSlice(new.Windows),
+		nil,
+		"Window",
+		contextual)
+	if windowsDiff != nil {
+		diff.Objects = append(diff.Objects, windowsDiff...)
+	}
+
+	sort.Sort(FieldDiffs(diff.Fields))
+	return diff
+}
+
 // primitiveObjectDiff returns a diff of the passed objects' primitive fields.
 // The filter field can be used to exclude fields from the diff. The name is the
 // name of the objects. If contextual is set, non-changed fields will also be
@@ -165,6 +165,12 @@ func TestJobDiff(t *testing.T) {
 						Old:  "false",
 						New:  "",
 					},
+					{
+						Type: DiffTypeDeleted,
+						Name: "MaxAllocsPerNode",
+						Old:  "0",
+						New:  "",
+					},
 					{
 						Type: DiffTypeDeleted,
 						Name: "Meta[foo]",
@@ -234,6 +240,12 @@ func TestJobDiff(t *testing.T) {
 						Old:  "",
 						New:  "false",
 					},
+					{
+						Type: DiffTypeAdded,
+						Name: "MaxAllocsPerNode",
+						Old:  "",
+						New:  "0",
+					},
 					{
 						Type: DiffTypeAdded,
 						Name: "Meta[foo]",
@@ -2346,6 +2358,12 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "1000000000",
 								New:  "1000000000",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "DisconnectedMode",
+								Old:  "",
+								New:  "",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "Interval",
@@ -2663,6 +2681,18 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "0",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "MaxSurge",
+								Old:  "0",
+								New:  "",
+							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "MinHealthyChecksPercent",
+								Old:  "0",
+								New:  "",
+							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "MinHealthyTime",
@@ -2725,6 +2755,18 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "",
 								New:  "0",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "MaxSurge",
+								Old:  "",
+								New:  "0",
+							},
+							{
+								Type: DiffTypeAdded,
+								Name: "MinHealthyChecksPercent",
+								Old:  "",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeAdded,
 								Name: "MinHealthyTime",
@@ -2754,6 +2796,7 @@ func TestTaskGroupDiff(t *testing.T) {
 					AutoRevert:       true,
 					AutoPromote:      true,
 					Canary:           2,
+					MaxSurge:         1,
 				},
 			},
 			New: &TaskGroup{
@@ -2766,6 +2809,7 @@ func TestTaskGroupDiff(t *testing.T) {
 					AutoRevert:       false,
 					AutoPromote:      false,
 					Canary:           1,
+					MaxSurge:         2,
 				},
 			},
 			Expected: &TaskGroupDiff{
@@ -2811,6 +2855,12 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "5",
 								New:  "7",
 							},
+							{
+								Type: DiffTypeEdited,
+								Name: "MaxSurge",
+								Old:  "1",
+								New:  "2",
+							},
 							{
 								Type: DiffTypeEdited,
 								Name: "MinHealthyTime",
@@ -2828,6 +2878,50 @@ func TestTaskGroupDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			TestCase: "Update strategy health checks edited",
+			Old: &TaskGroup{
+				Update: &UpdateStrategy{
+					MaxParallel:  1,
+					HealthChecks: []string{"c1", "c2"},
+				},
+			},
+			New: &TaskGroup{
+				Update: &UpdateStrategy{
+					MaxParallel:  1,
+					HealthChecks: []string{"c2", "c3"},
+				},
+			},
+			Expected: &TaskGroupDiff{
+				Type: DiffTypeEdited,
+				Objects: []*ObjectDiff{
+					{
+						Type: DiffTypeEdited,
+						Name: "Update",
+						Objects: []*ObjectDiff{
+							{
+								Type: DiffTypeEdited,
+								Name: "HealthChecks",
+								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeAdded,
+										Name: "HealthChecks",
+										Old:  "",
+										New:  "c3",
+									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "HealthChecks",
+										Old:  "c1",
+										New:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			TestCase:   "Update strategy edited with context",
 			Contextual: true,
@@ -2898,6 +2992,18 @@ func TestTaskGroupDiff(t *testing.T) {
 								Old:  "5",
 								New:  "7",
 							},
+							{
+								Type: DiffTypeNone,
+								Name: "MaxSurge",
+								Old:  "0",
+								New:  "0",
+							},
+							{
+								Type: DiffTypeNone,
+								Name: "MinHealthyChecksPercent",
+								Old:  "0",
+								New:  "0",
+							},
 							{
 								Type: DiffTypeNone,
 								Name: "MinHealthyTime",
@@ -8350,6 +8456,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "",
 								New:  "0",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "X509",
+								Old:  "",
+								New:  "false",
+							},
 						},
 					},
 				},
@@ -8386,6 +8498,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "0",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "X509",
+								Old:  "false",
+								New:  "",
+							},
 						},
 					},
 				},
@@ -8493,6 +8611,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "",
 								New:  "3600000000000",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "X509",
+								Old:  "",
+								New:  "false",
+							},
 						},
 					},
 				},
@@ -8624,6 +8748,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "",
 								New:  "3600000000000",
 							},
+							{
+								Type: DiffTypeAdded,
+								Name: "X509",
+								Old:  "",
+								New:  "false",
+							},
 						},
 					},
 					{
@@ -8668,6 +8798,12 @@ func TestTaskDiff(t *testing.T) {
 								Old:  "0",
 								New:  "",
 							},
+							{
+								Type: DiffTypeDeleted,
+								Name: "X509",
+								Old:  "false",
+								New:  "",
+							},
 						},
 					},
 				},
@@ -3,6 +3,12 @@
 
 package structs
 
+import (
+	"slices"
+
+	"github.com/hashicorp/nomad/helper"
+)
+
 const (
 	// EvalDeleteRPCMethod is the RPC method for batch deleting evaluations
 	// using their IDs.
@@ -10,6 +16,20 @@ const (
 	// Args: EvalDeleteRequest
 	// Reply: EvalDeleteResponse
 	EvalDeleteRPCMethod = "Eval.Delete"
+
+	// EvalUpsertTraceRPCMethod is the RPC method used by schedulers for
+	// storing the trace of an evaluation.
+	//
+	// Args: EvalTraceUpsertRequest
+	// Reply: GenericResponse
+	EvalUpsertTraceRPCMethod = "Eval.UpsertTrace"
+
+	// EvalGetTraceRPCMethod is the RPC method for reading the trace of an
+	// evaluation.
+	//
+	// Args: EvalSpecificRequest
+	// Reply: SingleEvalTraceResponse
+	EvalGetTraceRPCMethod = "Eval.GetTrace"
 )
 
 // EvalDeleteRequest is the request object used when operators are manually
@@ -31,3 +51,121 @@ type EvalDeleteResponse struct {
 	Count int // how many Evaluations were safe to delete and/or matched the filter
 	WriteMeta
 }
+
+// EvalTrace is a structured record of the decisions made by a scheduler while
+// processing an evaluation. Traces are only recorded when enabled in the
+// scheduler configuration, and only the placements of the last scheduling
+// attempt of the evaluation are retained.
+type EvalTrace struct {
+	// EvalID is the ID of the traced evaluation.
+	EvalID string
+
+	// Namespace and JobID are the namespace and ID of the job of the
+	// evaluation.
+	Namespace string
+	JobID     string
+
+	// Placements are the placements attempted by the scheduler.
+	Placements []*EvalTracePlacement
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// EvalTracePlacement records the nodes considered for placing a single
+// allocation.
+type EvalTracePlacement struct {
+	// TaskGroup and AllocName identify the allocation being placed.
+	TaskGroup string
+	AllocName string
+
+	// NodeID is the ID of the node selected for the allocation, or empty if
+	// the allocation could not be placed.
+	NodeID string
+
+	// NodesEvaluated is the number of nodes evaluated for the placement.
+	NodesEvaluated int
+
+	// Filtered are the nodes filtered out because of a constraint, along
+	// with the reason they were filtered.
+	Filtered []*EvalTraceNode
+
+	// Exhausted are the nodes skipped because they had not enough resources
+	// left, along with the exhausted dimension.
+	Exhausted []*EvalTraceNode
+
+	// Truncated is true if more nodes were filtered or exhausted than the
+	// configured maximum and some were not recorded.
+	Truncated bool
+
+	// Scores are the top scoring nodes for the placement.
+	Scores []*NodeScoreMeta
+
+	// PreemptionAttempted is true if no node was feasible without evicting
+	// lower priority allocations and the scheduler searched again with
+	// preemption enabled.
+	PreemptionAttempted bool
+
+	// PreemptedAllocs are the IDs of the allocations selected to be
+	// preempted by the placement.
+	PreemptedAllocs []string
+}
+
+// EvalTraceNode records why a node was not selected for a placement.
+type EvalTraceNode struct {
+	NodeID string
+	Reason string
+}
+
+func (t *EvalTrace) Copy() *EvalTrace {
+	if t == nil {
+		return nil
+	}
+	nt := new(EvalTrace)
+	*nt = *t
+	nt.Placements = make([]*EvalTracePlacement, len(t.Placements))
+	for i, p := range t.Placements {
+		nt.Placements[i] = p.Copy()
+	}
+	return nt
+}
+
+func (p *EvalTracePlacement) Copy() *EvalTracePlacement {
+	if p == nil {
+		return nil
+	}
+	np := new(EvalTracePlacement)
+	*np = *p
+	np.Filtered = helper.CopySlice(p.Filtered)
+	np.Exhausted = helper.CopySlice(p.Exhausted)
+	np.Scores = CopySliceNodeScoreMeta(p.Scores)
+	np.PreemptedAllocs = slices.Clone(p.PreemptedAllocs)
+	return np
+}
+
+func (n *EvalTraceNode) Copy() *EvalTraceNode {
+	if n == nil {
+		return nil
+	}
+	nn := *n
+	return &nn
+}
+
+// EvalTraceUpsertRequest is used by schedulers to store the trace of the
+// evaluation they processed.
+type EvalTraceUpsertRequest struct {
+	Trace *EvalTrace
+
+	// EvalToken is the token of the outstanding evaluation, used to ensure
+	// only the scheduler processing the evaluation can store its trace.
+	EvalToken string
+
+	WriteRequest
+}
+
+// SingleEvalTraceResponse is used to return the trace of a single
+// evaluation.
+type SingleEvalTraceResponse struct {
+	Trace *EvalTrace
+	QueryMeta
+}
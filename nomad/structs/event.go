@@ -3,6 +3,8 @@
 
 package structs
 
+import "time"
+
 // EventStreamRequest is used to stream events from a servers EventBroker
 type EventStreamRequest struct {
 	Topics map[Topic][]string
@@ -33,6 +35,15 @@ const (
 	TopicService        Topic = "Service"
 	TopicAll            Topic = "*"
 
+	// TopicWorkloadIdentity is the topic of the lifecycle events of the
+	// workload identities of allocations, for auditing their issuance.
+	TopicWorkloadIdentity Topic = "WorkloadIdentity"
+
+	// TopicChangeFeed is the topic of the change feed, which duplicates the
+	// events of the other topics so it's only sent to the subscribers that
+	// explicitly subscribe to it.
+	TopicChangeFeed Topic = "ChangeFeed"
+
 	TypeNodeRegistration              = "NodeRegistration"
 	TypeNodeDeregistration            = "NodeDeregistration"
 	TypeNodeEligibilityUpdate         = "NodeEligibility"
@@ -63,6 +74,12 @@ const (
 	TypeACLBindingRuleDeleted         = "ACLBindingRuleDeleted"
 	TypeServiceRegistration           = "ServiceRegistration"
 	TypeServiceDeregistration         = "ServiceDeregistration"
+	TypeObjectUpserted                = "ObjectUpserted"
+	TypeObjectDeleted                 = "ObjectDeleted"
+	TypeWorkloadIdentitySigned        = "WorkloadIdentitySigned"
+	TypeWorkloadIdentityRenewed       = "WorkloadIdentityRenewed"
+	TypeWorkloadIdentityRenewalFailed = "WorkloadIdentityRenewalFailed"
+	TypeWorkloadIdentityExpired       = "WorkloadIdentityExpired"
 )
 
 // Event represents a change in Nomads state.
@@ -131,6 +148,42 @@ type DeploymentEvent struct {
 	Deployment *Deployment
 }
 
+// ChangeFeedEvent holds an object of the state store that was upserted or
+// deleted, including by garbage collection.
+type ChangeFeedEvent struct {
+	// Table is the state store table of the object.
+	Table string
+
+	// Object is the object after it was upserted, or before it was deleted.
+	Object interface{}
+}
+
+// WorkloadIdentityEvent holds a lifecycle event of a workload identity of an
+// allocation. The identities are signed and renewed by the servers, while the
+// renewal failures and expirations are reported by the clients.
+type WorkloadIdentityEvent struct {
+	AllocID   string
+	Namespace string
+	JobID     string
+	NodeID    string
+	WIHandle
+
+	// Type is the type of the event, such as TypeWorkloadIdentitySigned.
+	Type string
+
+	// Expiration is when the identity expires, or zero if it doesn't.
+	Expiration time.Time
+
+	// Error is why the identity failed to renew, for RenewalFailed events.
+	Error string
+}
+
+// WorkloadIdentityStreamEvent holds a lifecycle event of a workload identity
+// to be used as an event in the event stream.
+type WorkloadIdentityStreamEvent struct {
+	WorkloadIdentity *WorkloadIdentityEvent
+}
+
 // NodeStreamEvent holds a newly updated Node
 type NodeStreamEvent struct {
 	Node *Node
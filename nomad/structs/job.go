@@ -4,6 +4,11 @@
 package structs
 
 import (
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
 	"github.com/hashicorp/go-set/v2"
 )
 
@@ -16,6 +21,30 @@ const (
 	JobServiceRegistrationsRPCMethod = "Job.GetServiceRegistrations"
 )
 
+const (
+	// JobFailureReasonOOM is the reason of the failures of tasks killed
+	// because they ran out of memory.
+	JobFailureReasonOOM = "oom"
+
+	// JobFailureReasonExitCode is the reason of the failures of tasks that
+	// exited with a non-zero exit code. The detail is the exit code.
+	JobFailureReasonExitCode = "exit-code"
+
+	// JobFailureReasonDriverError is the reason of the failures of tasks
+	// that their driver failed to start. The detail is the driver error.
+	JobFailureReasonDriverError = "driver-error"
+
+	// JobFailureReasonConstraint is the reason of the failures to place
+	// allocations because no node satisfied a constraint. The detail is the
+	// constraint.
+	JobFailureReasonConstraint = "constraint"
+
+	// JobFailureReasonExhausted is the reason of the failures to place
+	// allocations because the feasible nodes were out of resources. The
+	// detail is the exhausted dimension.
+	JobFailureReasonExhausted = "exhausted"
+)
+
 // JobServiceRegistrationsRequest is the request object used to list all
 // service registrations belonging to the specified Job.ID.
 type JobServiceRegistrationsRequest struct {
@@ -132,3 +161,390 @@ func (j *Job) RequiredNUMA() set.Collection[string] {
 	}
 	return result
 }
+
+const (
+	// DefaultJobHealthRestartWindow is the default period over which task
+	// restarts are counted in the health of a job.
+	DefaultJobHealthRestartWindow = 15 * time.Minute
+
+	// jobHealthRecentDeployments is the number of recent deployments
+	// reported in the health of a job.
+	jobHealthRecentDeployments = 5
+)
+
+const (
+	// JobDeploymentTrendNone is the trend of jobs without deployments.
+	JobDeploymentTrendNone = "none"
+
+	// JobDeploymentTrendStable is the trend of jobs whose latest deployment
+	// succeeded after a deployment that did not fail.
+	JobDeploymentTrendStable = "stable"
+
+	// JobDeploymentTrendRecovering is the trend of jobs whose latest
+	// deployment succeeded after a failed deployment.
+	JobDeploymentTrendRecovering = "recovering"
+
+	// JobDeploymentTrendProgressing is the trend of jobs whose latest
+	// deployment is in progress without unhealthy allocations.
+	JobDeploymentTrendProgressing = "progressing"
+
+	// JobDeploymentTrendDegrading is the trend of jobs whose latest
+	// deployment is in progress with unhealthy allocations, or failed after
+	// a deployment that did not fail.
+	JobDeploymentTrendDegrading = "degrading"
+
+	// JobDeploymentTrendFailing is the trend of jobs whose last two
+	// deployments failed.
+	JobDeploymentTrendFailing = "failing"
+)
+
+// JobHealth is a summary of the health of the allocations and deployments of
+// a job, meant for dashboards and simple checks of whether a job is serving.
+type JobHealth struct {
+	// DesiredAllocs is the number of allocations the job should be running.
+	DesiredAllocs int
+
+	// HealthyAllocs is the number of allocations running and healthy, or
+	// complete for batch jobs.
+	HealthyAllocs int
+
+	// HealthyPercent is the percentage of the desired allocations that are
+	// healthy, capped at 100.
+	HealthyPercent int
+
+	// Restarts is the number of task restarts within RestartWindow.
+	Restarts      int
+	RestartWindow time.Duration
+
+	// DeploymentTrend is one of the JobDeploymentTrend constants.
+	DeploymentTrend string
+
+	// RecentDeployments are the statuses of the most recent deployments of
+	// the job that were not cancelled, newest first.
+	RecentDeployments []string
+}
+
+// Copy returns a new copy of JobHealth.
+func (h *JobHealth) Copy() *JobHealth {
+	if h == nil {
+		return nil
+	}
+	nh := new(JobHealth)
+	*nh = *h
+	nh.RecentDeployments = slices.Clone(h.RecentDeployments)
+	return nh
+}
+
+// NewJobHealth computes the health of the job from its allocations and
+// deployments. Task restarts are counted between now and the restart window.
+func NewJobHealth(job *Job, allocs []*Allocation, deployments []*Deployment,
+	now time.Time, restartWindow time.Duration) *JobHealth {
+
+	health := &JobHealth{
+		RestartWindow:   restartWindow,
+		DeploymentTrend: JobDeploymentTrendNone,
+	}
+
+	sysJob := job.Type == JobTypeSystem || job.Type == JobTypeSysBatch
+	if !job.Stopped() && !sysJob {
+		for _, tg := range job.TaskGroups {
+			health.DesiredAllocs += tg.Count
+		}
+	}
+
+	restartsSince := now.Add(-restartWindow).UnixNano()
+	for _, alloc := range allocs {
+		if sysJob && alloc.DesiredStatus == AllocDesiredStatusRun {
+			health.DesiredAllocs++
+		}
+		if allocHealthy(alloc, job.Type) {
+			health.HealthyAllocs++
+		}
+		for _, state := range alloc.TaskStates {
+			for _, event := range state.Events {
+				if event.Type == TaskRestarting && event.Time >= restartsSince {
+					health.Restarts++
+				}
+			}
+		}
+	}
+
+	switch {
+	case health.DesiredAllocs == 0:
+		health.HealthyPercent = 100
+	case health.HealthyAllocs >= health.DesiredAllocs:
+		health.HealthyPercent = 100
+	default:
+		health.HealthyPercent = health.HealthyAllocs * 100 / health.DesiredAllocs
+	}
+
+	// Cancelled deployments were superseded by a newer job version before
+	// they completed, so they don't reflect the health of the job.
+	recent := make([]*Deployment, 0, len(deployments))
+	for _, d := range deployments {
+		if d.Status != DeploymentStatusCancelled {
+			recent = append(recent, d)
+		}
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].CreateIndex > recent[j].CreateIndex
+	})
+	for i, d := range recent {
+		if i == jobHealthRecentDeployments {
+			break
+		}
+		health.RecentDeployments = append(health.RecentDeployments, d.Status)
+	}
+	if len(recent) > 0 {
+		var previous *Deployment
+		if len(recent) > 1 {
+			previous = recent[1]
+		}
+		health.DeploymentTrend = deploymentTrend(recent[0], previous)
+	}
+
+	return health
+}
+
+// allocHealthy returns whether the allocation is running and, if it is part
+// of a deployment, has been reported healthy.
+func allocHealthy(alloc *Allocation, jobType string) bool {
+	if alloc.DesiredStatus != AllocDesiredStatusRun {
+		return false
+	}
+	switch alloc.ClientStatus {
+	case AllocClientStatusRunning:
+		return alloc.DeploymentStatus == nil || alloc.DeploymentStatus.IsHealthy()
+	case AllocClientStatusComplete:
+		return jobType == JobTypeBatch || jobType == JobTypeSysBatch
+	}
+	return false
+}
+
+func deploymentTrend(latest, previous *Deployment) string {
+	previousFailed := previous != nil && previous.Status == DeploymentStatusFailed
+
+	switch latest.Status {
+	case DeploymentStatusSuccessful:
+		if previousFailed {
+			return JobDeploymentTrendRecovering
+		}
+		return JobDeploymentTrendStable
+	case DeploymentStatusFailed:
+		if previousFailed {
+			return JobDeploymentTrendFailing
+		}
+		return JobDeploymentTrendDegrading
+	}
+
+	for _, state := range latest.TaskGroups {
+		if state.UnhealthyAllocs > 0 {
+			return JobDeploymentTrendDegrading
+		}
+	}
+	return JobDeploymentTrendProgressing
+}
+
+// JobFailureHistory aggregates the failures of the allocations of a job by
+// reason over a period of time, split into windows of equal duration. It is
+// computed from the task events of the allocations and the placement metrics
+// of the evaluations still in state, so it only covers failures that have not
+// been garbage collected.
+type JobFailureHistory struct {
+	Namespace string
+	JobID     string
+
+	// Start and End are the bounds of the period covered by the history.
+	Start time.Time
+	End   time.Time
+
+	// Window is the duration of each window.
+	Window time.Duration
+
+	// Failures is the number of failures by reason over the whole period,
+	// most frequent first.
+	Failures []*JobFailureCount
+
+	// Restarts and Reschedules are the number of task restarts and
+	// allocation reschedules over the whole period.
+	Restarts    int
+	Reschedules int
+
+	// Windows are the failures of each window, oldest first.
+	Windows []*JobFailureWindow
+}
+
+// JobFailureWindow is the failures of a job within a single window of a
+// JobFailureHistory.
+type JobFailureWindow struct {
+	Start       time.Time
+	End         time.Time
+	Failures    []*JobFailureCount
+	Restarts    int
+	Reschedules int
+}
+
+// JobFailureCount is the number of failures of a job for a given reason.
+type JobFailureCount struct {
+	// Reason is one of the JobFailureReason constants.
+	Reason string
+
+	// Detail refines the reason, such as the exit code or the constraint
+	// that failed.
+	Detail string `json:",omitempty"`
+
+	Count    int
+	LastSeen time.Time
+}
+
+// jobFailureCounter accumulates failures by reason and detail.
+type jobFailureCounter struct {
+	counts      map[[2]string]*JobFailureCount
+	restarts    int
+	reschedules int
+}
+
+func (c *jobFailureCounter) add(reason, detail string, n int, at time.Time) {
+	if c.counts == nil {
+		c.counts = make(map[[2]string]*JobFailureCount)
+	}
+	key := [2]string{reason, detail}
+	count, ok := c.counts[key]
+	if !ok {
+		count = &JobFailureCount{Reason: reason, Detail: detail}
+		c.counts[key] = count
+	}
+	count.Count += n
+	if at.After(count.LastSeen) {
+		count.LastSeen = at
+	}
+}
+
+// sorted returns the failure counts, most frequent first.
+func (c *jobFailureCounter) sorted() []*JobFailureCount {
+	counts := make([]*JobFailureCount, 0, len(c.counts))
+	for _, count := range c.counts {
+		counts = append(counts, count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].Reason != counts[j].Reason {
+			return counts[i].Reason < counts[j].Reason
+		}
+		return counts[i].Detail < counts[j].Detail
+	})
+	return counts
+}
+
+// NewJobFailureHistory aggregates the failures found in the allocations and
+// evaluations of a job between start and end, in windows of the given
+// duration. The last window is truncated to end.
+func NewJobFailureHistory(namespace, jobID string, allocs []*AllocListStub,
+	evals []*Evaluation, start, end time.Time, window time.Duration) *JobFailureHistory {
+
+	var counters []*jobFailureCounter
+	history := &JobFailureHistory{
+		Namespace: namespace,
+		JobID:     jobID,
+		Start:     start,
+		End:       end,
+		Window:    window,
+	}
+	for ws := start; ws.Before(end); ws = ws.Add(window) {
+		we := ws.Add(window)
+		if we.After(end) {
+			we = end
+		}
+		history.Windows = append(history.Windows, &JobFailureWindow{Start: ws, End: we})
+		counters = append(counters, &jobFailureCounter{})
+	}
+
+	// counter returns the counter of the window containing t, or nil if t is
+	// out of the period covered by the history.
+	counter := func(t time.Time) *jobFailureCounter {
+		if t.Before(start) || !t.Before(end) || window <= 0 {
+			return nil
+		}
+		return counters[int(t.Sub(start)/window)]
+	}
+
+	for _, alloc := range allocs {
+		for _, state := range alloc.TaskStates {
+			for _, event := range state.Events {
+				at := time.Unix(0, event.Time).UTC()
+				c := counter(at)
+				if c == nil {
+					continue
+				}
+				switch event.Type {
+				case TaskRestarting:
+					c.restarts++
+				case TaskDriverFailure:
+					detail := event.Details["driver_error"]
+					if detail == "" {
+						detail = event.DriverError
+					}
+					c.add(JobFailureReasonDriverError, detail, 1, at)
+				case TaskTerminated:
+					if event.Details["oom_killed"] == "true" {
+						c.add(JobFailureReasonOOM, "", 1, at)
+						continue
+					}
+					exitCode := event.ExitCode
+					if code, err := strconv.Atoi(event.Details["exit_code"]); err == nil {
+						exitCode = code
+					}
+					if exitCode != 0 {
+						c.add(JobFailureReasonExitCode, strconv.Itoa(exitCode), 1, at)
+					}
+				}
+			}
+		}
+
+		// The reschedule tracker of an allocation holds the events of all
+		// its previous allocations, so only count the last one to not count
+		// the same reschedule multiple times.
+		if alloc.RescheduleTracker != nil && len(alloc.RescheduleTracker.Events) > 0 {
+			events := alloc.RescheduleTracker.Events
+			if c := counter(time.Unix(0, events[len(events)-1].RescheduleTime).UTC()); c != nil {
+				c.reschedules++
+			}
+		}
+	}
+
+	for _, eval := range evals {
+		at := time.Unix(0, eval.CreateTime).UTC()
+		c := counter(at)
+		if c == nil {
+			continue
+		}
+		for _, metrics := range eval.FailedTGAllocs {
+			failed := metrics.CoalescedFailures + 1
+			for constraint := range metrics.ConstraintFiltered {
+				c.add(JobFailureReasonConstraint, constraint, failed, at)
+			}
+			for dimension := range metrics.DimensionExhausted {
+				c.add(JobFailureReasonExhausted, dimension, failed, at)
+			}
+		}
+	}
+
+	total := &jobFailureCounter{}
+	for i, c := range counters {
+		w := history.Windows[i]
+		w.Failures = c.sorted()
+		w.Restarts = c.restarts
+		w.Reschedules = c.reschedules
+
+		for _, count := range w.Failures {
+			total.add(count.Reason, count.Detail, count.Count, count.LastSeen)
+		}
+		history.Restarts += c.restarts
+		history.Reschedules += c.reschedules
+	}
+	history.Failures = total.sorted()
+
+	return history
+}
@@ -0,0 +1,221 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+const (
+	// JobActiveScheduleInterval is the interval at which the leader checks
+	// whether the jobs with an active schedule must be scaled to zero or
+	// restored.
+	JobActiveScheduleInterval = time.Minute
+
+	// JobActiveScheduleActor is the actor of the scaling events recorded when
+	// the leader applies the active schedule of a job.
+	JobActiveScheduleActor = "active-schedule"
+)
+
+// JobActiveSchedule restricts the times a service job runs to a set of
+// windows, such as business hours. Outside of its windows, the groups of the
+// job are scaled to zero, and their count is restored once a window starts.
+type JobActiveSchedule struct {
+	// TimeZone is the IANA time zone the windows are evaluated in, such as
+	// "Europe/Paris". Defaults to UTC.
+	TimeZone string
+
+	// Windows are the windows the job is active in. The job is active if any
+	// of its windows is.
+	Windows []*JobActiveWindow
+
+	// Override forces the job active or inactive regardless of its windows.
+	// It's set through the API and kept across registrations of the job.
+	Override *JobActiveScheduleOverride
+
+	// SuspendedCounts are the counts of the groups of the job while it's
+	// scaled to zero outside of its windows. It's managed by the server and is
+	// nil while the job is active.
+	SuspendedCounts map[string]int
+}
+
+// JobActiveWindow is a window in which a job with an active schedule runs.
+type JobActiveWindow struct {
+	// Cron is the cron expression of the starts of the window.
+	Cron string
+
+	// Duration is the duration of the window.
+	Duration time.Duration
+}
+
+// JobActiveScheduleOverride forces a job with an active schedule active or
+// inactive.
+type JobActiveScheduleOverride struct {
+	// Active is whether the job is forced active or inactive.
+	Active bool
+
+	// Until is the time the override expires at. The zero value keeps the
+	// override until it's cleared.
+	Until time.Time
+}
+
+// JobActiveScheduleChange is a change of the count of a group applied by the
+// active schedule of its job.
+type JobActiveScheduleChange struct {
+	Group         string
+	PreviousCount int
+	Count         int
+}
+
+func (s *JobActiveSchedule) Copy() *JobActiveSchedule {
+	if s == nil {
+		return nil
+	}
+	ns := new(JobActiveSchedule)
+	*ns = *s
+	ns.Windows = helper.CopySlice(s.Windows)
+	ns.Override = s.Override.Copy()
+	ns.SuspendedCounts = maps.Clone(s.SuspendedCounts)
+	return ns
+}
+
+func (w *JobActiveWindow) Copy() *JobActiveWindow {
+	if w == nil {
+		return nil
+	}
+	nw := new(JobActiveWindow)
+	*nw = *w
+	return nw
+}
+
+func (o *JobActiveScheduleOverride) Copy() *JobActiveScheduleOverride {
+	if o == nil {
+		return nil
+	}
+	no := new(JobActiveScheduleOverride)
+	*no = *o
+	return no
+}
+
+// Expired returns whether the override is expired at the given time.
+func (o *JobActiveScheduleOverride) Expired(now time.Time) bool {
+	return !o.Until.IsZero() && !now.Before(o.Until)
+}
+
+func (s *JobActiveSchedule) Validate() error {
+	var mErr multierror.Error
+
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		_ = multierror.Append(&mErr, fmt.Errorf("Invalid time zone %q: %v", s.TimeZone, err))
+	}
+	if len(s.Windows) == 0 {
+		_ = multierror.Append(&mErr, fmt.Errorf("Must specify at least one window"))
+	}
+	for i, w := range s.Windows {
+		if w == nil {
+			_ = multierror.Append(&mErr, fmt.Errorf("Window %d is empty", i+1))
+			continue
+		}
+		if _, err := CronParseNext(time.Now(), w.Cron); err != nil {
+			_ = multierror.Append(&mErr, fmt.Errorf("Window %d has invalid cron spec %q: %v", i+1, w.Cron, err))
+		}
+		if w.Duration <= 0 {
+			_ = multierror.Append(&mErr, fmt.Errorf("Window %d must have a positive duration", i+1))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// ActiveAt returns whether the job is active at the given time, either
+// because it's in one of its windows or because of its override.
+func (s *JobActiveSchedule) ActiveAt(now time.Time) bool {
+	if s == nil {
+		return true
+	}
+	if s.Override != nil && !s.Override.Expired(now) {
+		return s.Override.Active
+	}
+
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	for _, w := range s.Windows {
+		if w != nil && w.activeAt(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeAt returns whether the window is active at the given time, which is
+// when the last start of the window is less than its duration ago.
+func (w *JobActiveWindow) activeAt(now time.Time) bool {
+	start, err := CronParseNext(now.Add(-w.Duration), w.Cron)
+	if err != nil || start.IsZero() {
+		return false
+	}
+	return !start.After(now)
+}
+
+// Suspended returns whether the groups of the job are scaled to zero because
+// the job is outside of its windows.
+func (s *JobActiveSchedule) Suspended() bool {
+	return s != nil && s.SuspendedCounts != nil
+}
+
+// ApplyActiveSchedule scales the groups of the job to zero if its active
+// schedule makes it inactive at the given time, and restores their count once
+// it's active again. Only the transitions are applied, so the count of a
+// group scaled while the job is inactive is kept until the job is active.
+//
+// It returns the changes of the counts of the groups, if any.
+func (j *Job) ApplyActiveSchedule(now time.Time) []*JobActiveScheduleChange {
+	s := j.ActiveSchedule
+	if s == nil {
+		return nil
+	}
+
+	var changes []*JobActiveScheduleChange
+	active := s.ActiveAt(now)
+	switch {
+	case active && s.Suspended():
+		for _, tg := range j.TaskGroups {
+			count, ok := s.SuspendedCounts[tg.Name]
+			if !ok || tg.Count != 0 || count == 0 {
+				continue
+			}
+			changes = append(changes, &JobActiveScheduleChange{
+				Group:         tg.Name,
+				PreviousCount: tg.Count,
+				Count:         count,
+			})
+			tg.Count = count
+		}
+		s.SuspendedCounts = nil
+
+	case !active && !s.Suspended():
+		s.SuspendedCounts = make(map[string]int, len(j.TaskGroups))
+		for _, tg := range j.TaskGroups {
+			s.SuspendedCounts[tg.Name] = tg.Count
+			if tg.Count == 0 {
+				continue
+			}
+			changes = append(changes, &JobActiveScheduleChange{
+				Group:         tg.Name,
+				PreviousCount: tg.Count,
+				Count:         0,
+			})
+			tg.Count = 0
+		}
+	}
+	return changes
+}
@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestJobActiveSchedule_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	cases := []struct {
+		name     string
+		schedule *JobActiveSchedule
+		expErr   []string
+	}{
+		{
+			name: "valid",
+			schedule: &JobActiveSchedule{
+				TimeZone: "Europe/Paris",
+				Windows:  []*JobActiveWindow{{Cron: "0 8 * * 1-5", Duration: 10 * time.Hour}},
+			},
+		},
+		{
+			name:     "no windows",
+			schedule: &JobActiveSchedule{},
+			expErr:   []string{"at least one window"},
+		},
+		{
+			name: "invalid",
+			schedule: &JobActiveSchedule{
+				TimeZone: "Nowhere/Nothing",
+				Windows:  []*JobActiveWindow{{Cron: "not a cron"}},
+			},
+			expErr: []string{
+				`Invalid time zone "Nowhere/Nothing"`,
+				`Window 1 has invalid cron spec "not a cron"`,
+				"Window 1 must have a positive duration",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.schedule.Validate()
+			if len(tc.expErr) == 0 {
+				must.NoError(t, err)
+				return
+			}
+			must.Error(t, err)
+			for _, exp := range tc.expErr {
+				must.StrContains(t, err.Error(), exp)
+			}
+		})
+	}
+}
+
+func TestJob_Validate_ActiveSchedule(t *testing.T) {
+	ci.Parallel(t)
+
+	schedule := &JobActiveSchedule{
+		Windows: []*JobActiveWindow{{Cron: "0 8 * * *", Duration: time.Hour}},
+	}
+
+	j := testJob()
+	j.ActiveSchedule = schedule
+	must.NoError(t, j.Validate())
+
+	j = testJob()
+	j.Type = JobTypeBatch
+	j.ActiveSchedule = schedule
+	err := j.Validate()
+	must.ErrorContains(t, err, "Active schedule can only be used with \"service\" jobs")
+}
+
+func TestJobActiveSchedule_ActiveAt(t *testing.T) {
+	ci.Parallel(t)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	must.NoError(t, err)
+
+	// Business hours on weekdays, in Paris.
+	schedule := &JobActiveSchedule{
+		TimeZone: "Europe/Paris",
+		Windows:  []*JobActiveWindow{{Cron: "0 8 * * 1-5", Duration: 10 * time.Hour}},
+	}
+
+	// Monday
+	monday := func(hour, min int) time.Time {
+		return time.Date(2024, 3, 4, hour, min, 0, 0, paris)
+	}
+
+	must.False(t, schedule.ActiveAt(monday(7, 59)))
+	must.True(t, schedule.ActiveAt(monday(8, 0)))
+	must.True(t, schedule.ActiveAt(monday(17, 59)))
+	must.False(t, schedule.ActiveAt(monday(18, 0)))
+
+	// The time zone of the given time doesn't matter.
+	must.True(t, schedule.ActiveAt(monday(12, 0).UTC()))
+
+	// Saturday
+	must.False(t, schedule.ActiveAt(time.Date(2024, 3, 9, 12, 0, 0, 0, paris)))
+
+	// Overrides apply until they expire.
+	schedule.Override = &JobActiveScheduleOverride{Active: true, Until: monday(22, 0)}
+	must.True(t, schedule.ActiveAt(monday(21, 0)))
+	must.False(t, schedule.ActiveAt(monday(22, 0)))
+
+	schedule.Override = &JobActiveScheduleOverride{Active: false}
+	must.False(t, schedule.ActiveAt(monday(12, 0)))
+
+	// A job without an active schedule is always active.
+	var none *JobActiveSchedule
+	must.True(t, none.ActiveAt(monday(0, 0)))
+}
+
+func TestJob_ApplyActiveSchedule(t *testing.T) {
+	ci.Parallel(t)
+
+	start := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	j := &Job{
+		ActiveSchedule: &JobActiveSchedule{
+			Windows: []*JobActiveWindow{{Cron: "0 8 * * *", Duration: time.Hour}},
+		},
+		TaskGroups: []*TaskGroup{
+			{Name: "web", Count: 3},
+			{Name: "db", Count: 0},
+		},
+	}
+
+	// Nothing changes while the job is active.
+	must.Nil(t, j.ApplyActiveSchedule(start))
+	must.False(t, j.ActiveSchedule.Suspended())
+
+	// The groups are scaled to zero once the window ends.
+	changes := j.ApplyActiveSchedule(start.Add(time.Hour))
+	must.Eq(t, []*JobActiveScheduleChange{{Group: "web", PreviousCount: 3, Count: 0}}, changes)
+	must.Eq(t, 0, j.TaskGroups[0].Count)
+	must.Eq(t, map[string]int{"web": 3, "db": 0}, j.ActiveSchedule.SuspendedCounts)
+
+	// Only the transitions are applied, so manual scaling is kept.
+	j.TaskGroups[1].Count = 1
+	must.Nil(t, j.ApplyActiveSchedule(start.Add(2*time.Hour)))
+	must.Eq(t, 1, j.TaskGroups[1].Count)
+
+	// The counts are restored once the next window starts.
+	changes = j.ApplyActiveSchedule(start.Add(24 * time.Hour))
+	must.Eq(t, []*JobActiveScheduleChange{{Group: "web", PreviousCount: 0, Count: 3}}, changes)
+	must.Eq(t, 3, j.TaskGroups[0].Count)
+	must.Eq(t, 1, j.TaskGroups[1].Count)
+	must.False(t, j.ActiveSchedule.Suspended())
+}
+
+func TestJobDiff_ActiveSchedule(t *testing.T) {
+	ci.Parallel(t)
+
+	old := &Job{
+		ID: "example",
+		ActiveSchedule: &JobActiveSchedule{
+			Windows:         []*JobActiveWindow{{Cron: "0 8 * * *", Duration: time.Hour}},
+			Override:        &JobActiveScheduleOverride{Active: true},
+			SuspendedCounts: map[string]int{"web": 3},
+		},
+	}
+
+	// The state managed by the server isn't part of the diff.
+	new := old.Copy()
+	new.ActiveSchedule.Override = nil
+	new.ActiveSchedule.SuspendedCounts = nil
+	diff, err := old.Diff(new, false)
+	must.NoError(t, err)
+	must.Eq(t, DiffTypeNone, diff.Type)
+
+	new.ActiveSchedule.Windows[0].Duration = 2 * time.Hour
+	diff, err = old.Diff(new, false)
+	must.NoError(t, err)
+	must.Eq(t, DiffTypeEdited, diff.Type)
+	must.Len(t, 1, diff.Objects)
+	must.Eq(t, "ActiveSchedule", diff.Objects[0].Name)
+}
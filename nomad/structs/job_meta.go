@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper"
+)
+
+// metaEnvPrefix is the prefix of the environment variables the meta is
+// available as in the tasks.
+const metaEnvPrefix = "NOMAD_META_"
+
+// metaRefRe matches the references to the meta in interpolated fields, such
+// as ${NOMAD_META_key}.
+var metaRefRe = regexp.MustCompile(`\$\{NOMAD_META_([^}]+)\}`)
+
+// CombinedGroupEnv returns the environment variables the group sets in its
+// tasks, on top of their own. The variables of the group override the ones
+// of the job.
+func (j *Job) CombinedGroupEnv(groupName string) map[string]string {
+	env := maps.Clone(j.Env)
+	group := j.LookupTaskGroup(groupName)
+	if group == nil {
+		return env
+	}
+	if env == nil {
+		env = make(map[string]string, len(group.Env))
+	}
+	maps.Copy(env, group.Env)
+	return env
+}
+
+// InterpolateVolumeSources replaces the references to the meta of the job and
+// the groups in the sources of the volumes of the groups, since the sources
+// must be known to place the allocations. References to meta keys the job
+// doesn't set, such as the meta of parameterized jobs, are left as they are.
+func (j *Job) InterpolateVolumeSources() {
+	for _, tg := range j.TaskGroups {
+		if len(tg.Volumes) == 0 {
+			continue
+		}
+		names := metaEnvNames(j.combinedMeta(tg.Name, ""))
+		for _, req := range tg.Volumes {
+			if req != nil {
+				req.Source = interpolateMeta(req.Source, names)
+			}
+		}
+	}
+}
+
+// combinedMeta returns the meta of the task as seen by its environment,
+// including the optional meta of dispatched jobs that defaults to empty.
+func (j *Job) combinedMeta(groupName, taskName string) map[string]string {
+	meta := j.CombinedTaskMeta(groupName, taskName)
+	if j.Dispatched && j.ParameterizedJob != nil {
+		meta = maps.Clone(meta)
+		if meta == nil {
+			meta = make(map[string]string, len(j.ParameterizedJob.MetaOptional))
+		}
+		for _, k := range j.ParameterizedJob.MetaOptional {
+			if _, ok := meta[k]; !ok {
+				meta[k] = ""
+			}
+		}
+	}
+	return meta
+}
+
+// validateMetaRefs returns an error for each field of the group and its tasks
+// referencing meta keys that neither the job, the group or the task set, nor
+// the dispatcher of a parameterized job can set.
+func (tg *TaskGroup) validateMetaRefs(j *Job) error {
+	var mErr multierror.Error
+
+	names := func(taskName string) map[string]string {
+		meta := maps.Clone(j.CombinedTaskMeta(tg.Name, taskName))
+		if meta == nil {
+			meta = make(map[string]string)
+		}
+		if j.ParameterizedJob != nil {
+			for _, k := range j.ParameterizedJob.MetaRequired {
+				meta[k] = ""
+			}
+			for _, k := range j.ParameterizedJob.MetaOptional {
+				meta[k] = ""
+			}
+		}
+		return metaEnvNames(meta)
+	}
+	check := func(names map[string]string, field, value string) {
+		for _, match := range metaRefRe.FindAllStringSubmatch(value, -1) {
+			if _, ok := names[match[1]]; !ok {
+				mErr.Errors = append(mErr.Errors,
+					fmt.Errorf("%s references undefined meta key %q", field, match[1]))
+			}
+		}
+	}
+	checkIdentity := func(names map[string]string, field string, wid *WorkloadIdentity) {
+		if wid == nil {
+			return
+		}
+		for _, aud := range wid.Audience {
+			check(names, field, aud)
+		}
+	}
+	checkServices := func(names map[string]string, services []*Service) {
+		for _, service := range services {
+			for _, tag := range service.Tags {
+				check(names, fmt.Sprintf("Service %q tag", service.Name), tag)
+			}
+			for _, tag := range service.CanaryTags {
+				check(names, fmt.Sprintf("Service %q canary tag", service.Name), tag)
+			}
+		}
+	}
+
+	groupNames := names("")
+	for name, req := range tg.Volumes {
+		if req != nil {
+			check(groupNames, fmt.Sprintf("Volume %q source", name), req.Source)
+		}
+	}
+	checkServices(groupNames, tg.Services)
+	for _, service := range tg.Services {
+		checkIdentity(groupNames, fmt.Sprintf("Service %q identity audience", service.Name), service.Identity)
+	}
+
+	for _, task := range tg.Tasks {
+		taskNames := names(task.Name)
+		checkServices(taskNames, task.Services)
+		for _, service := range task.Services {
+			// The identities of services only have the meta of their group
+			checkIdentity(groupNames, fmt.Sprintf("Service %q identity audience", service.Name), service.Identity)
+		}
+		checkIdentity(taskNames, fmt.Sprintf("Task %q identity audience", task.Name), task.Identity)
+		for _, wid := range task.Identities {
+			checkIdentity(taskNames, fmt.Sprintf("Task %q identity %q audience", task.Name, wid.Name), wid)
+		}
+		for _, tmpl := range task.Templates {
+			check(taskNames, fmt.Sprintf("Task %q template destination", task.Name), tmpl.DestPath)
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// metaEnvNames returns the meta by the names the keys have in the environment
+// of the tasks, without the NOMAD_META_ prefix. The keys are available both as
+// they are and upper cased, with the characters invalid in environment
+// variables replaced.
+func metaEnvNames(meta map[string]string) map[string]string {
+	names := make(map[string]string, len(meta)*2)
+	for k, v := range meta {
+		for _, key := range []string{k, strings.ToUpper(k)} {
+			name := helper.CleanEnvVar(metaEnvPrefix+key, '_')
+			names[strings.TrimPrefix(name, metaEnvPrefix)] = v
+		}
+	}
+	return names
+}
+
+// interpolateMeta replaces the references to the meta in the value with their
+// value, and leaves the references to unknown keys as they are.
+func interpolateMeta(value string, names map[string]string) string {
+	return metaRefRe.ReplaceAllStringFunc(value, func(ref string) string {
+		name := metaRefRe.FindStringSubmatch(ref)[1]
+		if v, ok := names[name]; ok {
+			return v
+		}
+		return ref
+	})
+}
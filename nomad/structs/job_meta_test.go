@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestJob_CombinedGroupEnv(t *testing.T) {
+	ci.Parallel(t)
+
+	j := &Job{
+		Env: map[string]string{"A": "job", "B": "job"},
+		TaskGroups: []*TaskGroup{
+			{Name: "web", Env: map[string]string{"B": "group", "C": "group"}},
+			{Name: "db"},
+		},
+	}
+
+	must.Eq(t, map[string]string{"A": "job", "B": "group", "C": "group"}, j.CombinedGroupEnv("web"))
+	must.Eq(t, map[string]string{"A": "job", "B": "job"}, j.CombinedGroupEnv("db"))
+	must.Eq(t, map[string]string{"A": "job", "B": "job"}, j.CombinedGroupEnv("missing"))
+
+	// The env of the job isn't modified
+	must.Eq(t, map[string]string{"A": "job", "B": "job"}, j.Env)
+}
+
+func TestJob_InterpolateVolumeSources(t *testing.T) {
+	ci.Parallel(t)
+
+	j := &Job{
+		Meta: map[string]string{"env": "prod", "tier": "job"},
+		TaskGroups: []*TaskGroup{{
+			Name: "web",
+			Meta: map[string]string{"tier": "group", "zone-name": "a"},
+			Volumes: map[string]*VolumeRequest{
+				"data":    {Source: "data-${NOMAD_META_env}-${NOMAD_META_TIER}"},
+				"zone":    {Source: "zone-${NOMAD_META_zone_name}"},
+				"unknown": {Source: "${NOMAD_META_missing}"},
+			},
+		}},
+	}
+
+	j.InterpolateVolumeSources()
+	must.Eq(t, "data-prod-group", j.TaskGroups[0].Volumes["data"].Source)
+	must.Eq(t, "zone-a", j.TaskGroups[0].Volumes["zone"].Source)
+	must.Eq(t, "${NOMAD_META_missing}", j.TaskGroups[0].Volumes["unknown"].Source)
+
+	// The optional meta of dispatched jobs defaults to empty
+	j.Dispatched = true
+	j.ParameterizedJob = &ParameterizedJobConfig{MetaOptional: []string{"missing"}}
+	j.InterpolateVolumeSources()
+	must.Eq(t, "", j.TaskGroups[0].Volumes["unknown"].Source)
+}
+
+func TestTaskGroup_validateMetaRefs(t *testing.T) {
+	ci.Parallel(t)
+
+	j := &Job{
+		Meta:             map[string]string{"env": "prod"},
+		ParameterizedJob: &ParameterizedJobConfig{MetaRequired: []string{"input"}},
+	}
+	tg := &TaskGroup{
+		Name: "web",
+		Meta: map[string]string{"tier": "group"},
+		Volumes: map[string]*VolumeRequest{
+			"data": {Source: "data-${NOMAD_META_ENV}-${NOMAD_META_input}"},
+		},
+		Services: []*Service{{
+			Name: "web",
+			Tags: []string{"${NOMAD_META_tier}", "${NOMAD_META_task}"},
+		}},
+		Tasks: []*Task{{
+			Name: "server",
+			Meta: map[string]string{"task": "server"},
+			Services: []*Service{{
+				Name:       "server",
+				CanaryTags: []string{"${NOMAD_META_task}"},
+			}},
+			Templates: []*Template{
+				{DestPath: "local/${NOMAD_META_env}/${NOMAD_META_version}.conf"},
+			},
+			Identities: []*WorkloadIdentity{{
+				Name:     "vault_default",
+				Audience: []string{"vault-${NOMAD_META_task}-${NOMAD_META_team}"},
+			}},
+		}},
+	}
+	j.TaskGroups = []*TaskGroup{tg}
+
+	err := tg.validateMetaRefs(j)
+	must.ErrorContains(t, err, `Service "web" tag references undefined meta key "task"`)
+	must.ErrorContains(t, err, `Task "server" template destination references undefined meta key "version"`)
+	must.ErrorContains(t, err, `Task "server" identity "vault_default" audience references undefined meta key "team"`)
+	must.StrNotContains(t, err.Error(), "Volume")
+	must.StrNotContains(t, err.Error(), "canary")
+
+	tg.Services[0].Tags = []string{"${NOMAD_META_tier}"}
+	tg.Tasks[0].Meta["version"] = "1"
+	tg.Tasks[0].Meta["team"] = "web"
+	must.NoError(t, tg.validateMetaRefs(j))
+}
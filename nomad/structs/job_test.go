@@ -5,6 +5,7 @@ package structs
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-set/v2"
 	"github.com/shoenig/test/must"
@@ -471,3 +472,137 @@ func TestJob_RequiredNUMA(t *testing.T) {
 		})
 	}
 }
+
+func TestNewJobFailureHistory(t *testing.T) {
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Hour)
+	at := func(d time.Duration) int64 { return start.Add(d).UnixNano() }
+
+	allocs := []*AllocListStub{
+		{
+			TaskStates: map[string]*TaskState{
+				"web": {Events: []*TaskEvent{
+					{Type: TaskTerminated, Time: at(10 * time.Minute), Details: map[string]string{"exit_code": "1"}},
+					{Type: TaskRestarting, Time: at(11 * time.Minute)},
+					{Type: TaskTerminated, Time: at(20 * time.Minute), Details: map[string]string{"exit_code": "1"}},
+					{Type: TaskTerminated, Time: at(90 * time.Minute), Details: map[string]string{"exit_code": "137", "oom_killed": "true"}},
+				}},
+				"sidecar": {Events: []*TaskEvent{
+					{Type: TaskTerminated, Time: at(30 * time.Minute), Details: map[string]string{"exit_code": "0"}},
+					{Type: TaskDriverFailure, Time: at(150 * time.Minute), Details: map[string]string{"driver_error": "image not found"}},
+					// Out of the period
+					{Type: TaskTerminated, Time: at(-time.Minute), Details: map[string]string{"exit_code": "2"}},
+				}},
+			},
+		},
+		{
+			RescheduleTracker: &RescheduleTracker{Events: []*RescheduleEvent{
+				{RescheduleTime: at(-time.Hour)},
+				{RescheduleTime: at(100 * time.Minute)},
+			}},
+		},
+	}
+	evals := []*Evaluation{
+		{
+			CreateTime: at(160 * time.Minute),
+			FailedTGAllocs: map[string]*AllocMetric{
+				"web": {
+					CoalescedFailures:  2,
+					ConstraintFiltered: map[string]int{"${attr.kernel.name} = linux": 5},
+				},
+			},
+		},
+	}
+
+	history := NewJobFailureHistory("default", "example", allocs, evals, start, end, time.Hour)
+	must.Eq(t, "example", history.JobID)
+	must.Len(t, 3, history.Windows)
+	must.Eq(t, 1, history.Restarts)
+	must.Eq(t, 1, history.Reschedules)
+
+	must.Eq(t, []*JobFailureCount{
+		{Reason: JobFailureReasonConstraint, Detail: "${attr.kernel.name} = linux", Count: 3, LastSeen: start.Add(160 * time.Minute)},
+		{Reason: JobFailureReasonExitCode, Detail: "1", Count: 2, LastSeen: start.Add(20 * time.Minute)},
+		{Reason: JobFailureReasonDriverError, Detail: "image not found", Count: 1, LastSeen: start.Add(150 * time.Minute)},
+		{Reason: JobFailureReasonOOM, Count: 1, LastSeen: start.Add(90 * time.Minute)},
+	}, history.Failures)
+
+	must.Len(t, 1, history.Windows[0].Failures)
+	must.Eq(t, 1, history.Windows[0].Restarts)
+	must.Len(t, 1, history.Windows[1].Failures)
+	must.Eq(t, JobFailureReasonOOM, history.Windows[1].Failures[0].Reason)
+	must.Eq(t, 1, history.Windows[1].Reschedules)
+	must.Len(t, 2, history.Windows[2].Failures)
+	must.Eq(t, end, history.Windows[2].End)
+}
+
+func TestNewJobHealth(t *testing.T) {
+	now := time.Now()
+	healthy := true
+
+	job := &Job{
+		Type: JobTypeService,
+		TaskGroups: []*TaskGroup{
+			{Name: "web", Count: 3},
+		},
+	}
+	allocs := []*Allocation{
+		{
+			DesiredStatus:    AllocDesiredStatusRun,
+			ClientStatus:     AllocClientStatusRunning,
+			DeploymentStatus: &AllocDeploymentStatus{Healthy: &healthy},
+			TaskStates: map[string]*TaskState{
+				"web": {Events: []*TaskEvent{
+					{Type: TaskRestarting, Time: now.Add(-time.Minute).UnixNano()},
+					{Type: TaskRestarting, Time: now.Add(-time.Hour).UnixNano()},
+				}},
+			},
+		},
+		{
+			DesiredStatus: AllocDesiredStatusRun,
+			ClientStatus:  AllocClientStatusRunning,
+		},
+		{
+			// Pending health
+			DesiredStatus:    AllocDesiredStatusRun,
+			ClientStatus:     AllocClientStatusRunning,
+			DeploymentStatus: &AllocDeploymentStatus{},
+		},
+		{
+			DesiredStatus: AllocDesiredStatusStop,
+			ClientStatus:  AllocClientStatusRunning,
+		},
+	}
+	deployments := []*Deployment{
+		{Status: DeploymentStatusFailed, CreateIndex: 10},
+		{Status: DeploymentStatusCancelled, CreateIndex: 30},
+		{Status: DeploymentStatusSuccessful, CreateIndex: 20},
+	}
+
+	health := NewJobHealth(job, allocs, deployments, now, 15*time.Minute)
+	must.Eq(t, &JobHealth{
+		DesiredAllocs:     3,
+		HealthyAllocs:     2,
+		HealthyPercent:    66,
+		Restarts:          1,
+		RestartWindow:     15 * time.Minute,
+		DeploymentTrend:   JobDeploymentTrendRecovering,
+		RecentDeployments: []string{DeploymentStatusSuccessful, DeploymentStatusFailed},
+	}, health)
+
+	// Trends of in progress deployments depend on their unhealthy allocs
+	running := &Deployment{
+		Status:      DeploymentStatusRunning,
+		CreateIndex: 40,
+		TaskGroups:  map[string]*DeploymentState{"web": {UnhealthyAllocs: 1}},
+	}
+	health = NewJobHealth(job, nil, append(deployments, running), now, time.Minute)
+	must.Eq(t, JobDeploymentTrendDegrading, health.DeploymentTrend)
+	must.Eq(t, 0, health.HealthyPercent)
+
+	// Stopped jobs have nothing to run
+	job.Stop = true
+	health = NewJobHealth(job, nil, nil, now, time.Minute)
+	must.Eq(t, 100, health.HealthyPercent)
+	must.Eq(t, JobDeploymentTrendNone, health.DeploymentTrend)
+}
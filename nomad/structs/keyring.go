@@ -4,6 +4,7 @@
 package structs
 
 import (
+	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
@@ -27,6 +28,10 @@ const (
 	// is required by AWS OIDC IAM Provider.
 	PubKeyAlgRS256 = string(jose.RS256)
 
+	// PubKeyAlgES256 is the JWA for P-256 ECDSA public keys used for
+	// signatures, which are only used by signer plugins.
+	PubKeyAlgES256 = string(jose.ES256)
+
 	// PubKeyUseSig is the JWK (JSON Web Key) "use" parameter value for
 	// signatures.
 	PubKeyUseSig = "sig"
@@ -84,6 +89,10 @@ type RootKeyMeta struct {
 	CreateIndex uint64
 	ModifyIndex uint64
 	State       RootKeyState
+
+	// PublishTime is the time in Unix nanoseconds at which a prepublished key
+	// becomes the active key.
+	PublishTime int64
 }
 
 // RootKeyState enum describes the lifecycle of a root key.
@@ -94,6 +103,12 @@ const (
 	RootKeyStateActive                = "active"
 	RootKeyStateRekeying              = "rekeying"
 
+	// RootKeyStatePrepublished is the state of keys that have been created
+	// and are served by the JWKS endpoint, so that third parties can fetch
+	// them ahead of time, but that won't be used for crypto operations until
+	// their PublishTime.
+	RootKeyStatePrepublished = "prepublished"
+
 	// RootKeyStateDeprecated is, itself, deprecated and is no longer in
 	// use. For backwards compatibility, any existing keys with this state will
 	// be treated as RootKeyStateInactive
@@ -116,10 +131,11 @@ func NewRootKeyMeta() *RootKeyMeta {
 // fields such as ModifyIndex so we don't have to sync them to the
 // on-disk keystore when the fields are already in raft.
 type RootKeyMetaStub struct {
-	KeyID      string
-	Algorithm  EncryptionAlgorithm
-	CreateTime int64
-	State      RootKeyState
+	KeyID       string
+	Algorithm   EncryptionAlgorithm
+	CreateTime  int64
+	State       RootKeyState
+	PublishTime int64
 }
 
 // Active indicates his key is the one currently being used for
@@ -146,6 +162,19 @@ func (rkm *RootKeyMeta) SetInactive() {
 	rkm.State = RootKeyStateInactive
 }
 
+// Prepublished indicates that this key has been published in the JWKS
+// endpoint but will only become active at its PublishTime.
+func (rkm *RootKeyMeta) Prepublished() bool {
+	return rkm.State == RootKeyStatePrepublished
+}
+
+// MakePrepublished sets the key as prepublished until the given time in Unix
+// nanoseconds.
+func (rkm *RootKeyMeta) MakePrepublished(publishTime int64) {
+	rkm.PublishTime = publishTime
+	rkm.State = RootKeyStatePrepublished
+}
+
 // Inactive indicates that this key is no longer being used to encrypt new
 // variables or workload identities.
 func (rkm *RootKeyMeta) Inactive() bool {
@@ -157,10 +186,11 @@ func (rkm *RootKeyMeta) Stub() *RootKeyMetaStub {
 		return nil
 	}
 	return &RootKeyMetaStub{
-		KeyID:      rkm.KeyID,
-		Algorithm:  rkm.Algorithm,
-		CreateTime: rkm.CreateTime,
-		State:      rkm.State,
+		KeyID:       rkm.KeyID,
+		Algorithm:   rkm.Algorithm,
+		CreateTime:  rkm.CreateTime,
+		State:       rkm.State,
+		PublishTime: rkm.PublishTime,
 	}
 
 }
@@ -183,8 +213,8 @@ func (rkm *RootKeyMeta) Validate() error {
 		return fmt.Errorf("root key algorithm is required")
 	}
 	switch rkm.State {
-	case RootKeyStateInactive, RootKeyStateActive,
-		RootKeyStateRekeying, RootKeyStateDeprecated:
+	case RootKeyStateInactive, RootKeyStateActive, RootKeyStateRekeying,
+		RootKeyStatePrepublished, RootKeyStateDeprecated:
 	default:
 		return fmt.Errorf("root key state %q is invalid", rkm.State)
 	}
@@ -213,6 +243,15 @@ const (
 type KeyringRotateRootKeyRequest struct {
 	Algorithm EncryptionAlgorithm
 	Full      bool
+
+	// PublishTime is the time in Unix nanoseconds at which the new key
+	// becomes active. If set, the key is prepublished until then.
+	PublishTime int64
+
+	// PrepublishDuration is the time after which the new key becomes active.
+	// If set, the server sets the PublishTime from its own clock.
+	PrepublishDuration time.Duration
+
 	WriteRequest
 }
 
@@ -338,6 +377,18 @@ func (pubKey *KeyringPublicKey) GetPublicKey() (any, error) {
 		}
 		return rsaPubKey, nil
 
+	case PubKeyAlgES256:
+		// PKIX -> ecdsa.PublicKey
+		pub, err := x509.ParsePKIXPublicKey(pubKey.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s public key: %w", alg, err)
+		}
+		ecPubKey, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("error parsing %s public key: unexpected key type %T", alg, pub)
+		}
+		return ecPubKey, nil
+
 	default:
 		return nil, fmt.Errorf("unknown algorithm: %q", alg)
 	}
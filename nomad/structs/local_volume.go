@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+// HasLocalVolumes returns whether the task group requests local volumes.
+func (tg *TaskGroup) HasLocalVolumes() bool {
+	for _, req := range tg.Volumes {
+		if req.Type == VolumeTypeLocal {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalVolumeClaim binds an allocation of a task group that requests local
+// volumes to the node of its first placement. The replacements of the
+// allocation, which share its name, can only be placed on that node until the
+// claim is deleted, so they find the data it left in the host volumes.
+type LocalVolumeClaim struct {
+	Namespace string
+	JobID     string
+	TaskGroup string
+
+	// AllocName is the name of the allocations bound by the claim.
+	AllocName string
+
+	// NodeID is the node the allocations are bound to.
+	NodeID string
+
+	// AllocID is the ID of the last allocation placed for the claim.
+	AllocID string
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Copy returns a copy of the claim.
+func (c *LocalVolumeClaim) Copy() *LocalVolumeClaim {
+	if c == nil {
+		return nil
+	}
+	nc := new(LocalVolumeClaim)
+	*nc = *c
+	return nc
+}
+
+// LocalVolumeClaimListRequest is used to list the local volume claims of a
+// job.
+type LocalVolumeClaimListRequest struct {
+	JobID string
+	QueryOptions
+}
+
+// LocalVolumeClaimListResponse is used to return the local volume claims of a
+// job.
+type LocalVolumeClaimListResponse struct {
+	Claims []*LocalVolumeClaim
+	QueryMeta
+}
+
+// LocalVolumeClaimDeleteRequest is used to delete local volume claims, which
+// allows the allocations of the job to be placed on other nodes. All the
+// claims of the job are deleted if AllocName is empty.
+type LocalVolumeClaimDeleteRequest struct {
+	JobID     string
+	AllocName string
+
+	// Eval is the evaluation created to place the allocations that were
+	// waiting for their claimed node, if any.
+	Eval *Evaluation
+
+	WriteRequest
+}
+
+// LocalVolumeClaimDeleteResponse is used to respond to deleting local volume
+// claims.
+type LocalVolumeClaimDeleteResponse struct {
+	EvalID          string
+	EvalCreateIndex uint64
+	WriteMeta
+}
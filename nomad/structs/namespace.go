@@ -3,6 +3,16 @@
 
 package structs
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/ryanuber/go-glob"
+)
+
 // NamespaceVaultConfiguration stores configuration about permissions to Vault
 // clusters for a namespace, for use with Nomad Enterprise.
 type NamespaceVaultConfiguration struct {
@@ -46,3 +56,71 @@ type NamespaceConsulConfiguration struct {
 	// This field cannot be used with Allowed.
 	Denied []string
 }
+
+// NamespaceTokenExchangeConfiguration stores the policy for exchanging the
+// workload identities of the jobs in a namespace for JWTs with a different
+// audience, following RFC 8693. Workload identities can't be exchanged for
+// JWTs in namespaces without this configuration.
+type NamespaceTokenExchangeConfiguration struct {
+	// Audiences specifies the audiences workload identities can be exchanged
+	// for. This field supports wildcard globbing through the use of `*` for
+	// multi-character matching.
+	Audiences []string
+
+	// MaxTTL is the maximum lifetime of the exchanged JWTs. If zero, it
+	// defaults to MaxWorkloadIdentityExchangeTTL.
+	MaxTTL time.Duration
+}
+
+func (c *NamespaceTokenExchangeConfiguration) Copy() *NamespaceTokenExchangeConfiguration {
+	if c == nil {
+		return nil
+	}
+
+	nc := *c
+	nc.Audiences = slices.Clone(c.Audiences)
+	return &nc
+}
+
+func (c *NamespaceTokenExchangeConfiguration) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	var mErr *multierror.Error
+	if len(c.Audiences) == 0 {
+		mErr = multierror.Append(mErr, errors.New("at least one audience is required"))
+	}
+	if c.MaxTTL < 0 {
+		mErr = multierror.Append(mErr, errors.New("max TTL must not be negative"))
+	}
+	if c.MaxTTL > MaxWorkloadIdentityExchangeTTL {
+		mErr = multierror.Append(mErr, fmt.Errorf(
+			"max TTL must not be greater than %s", MaxWorkloadIdentityExchangeTTL))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// AllowsAudience returns whether workload identities can be exchanged for
+// JWTs with the audience.
+func (c *NamespaceTokenExchangeConfiguration) AllowsAudience(audience string) bool {
+	if c == nil {
+		return false
+	}
+	return slices.ContainsFunc(c.Audiences, func(pattern string) bool {
+		return glob.Glob(pattern, audience)
+	})
+}
+
+// TTL returns the lifetime of a JWT exchanged with the requested TTL, which
+// defaults to DefaultWorkloadIdentityExchangeTTL and is capped by MaxTTL.
+func (c *NamespaceTokenExchangeConfiguration) TTL(requested time.Duration) time.Duration {
+	maxTTL := MaxWorkloadIdentityExchangeTTL
+	if c != nil && c.MaxTTL > 0 {
+		maxTTL = c.MaxTTL
+	}
+	if requested == 0 {
+		requested = DefaultWorkloadIdentityExchangeTTL
+	}
+	return min(requested, maxTTL)
+}
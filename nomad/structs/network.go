@@ -10,6 +10,8 @@ import (
 	"net"
 	"slices"
 	"sync"
+
+	"github.com/hashicorp/nomad/helper"
 )
 
 const (
@@ -817,6 +819,13 @@ type ClientHostNetworkConfig struct {
 	CIDR          string `hcl:"cidr"`
 	Interface     string `hcl:"interface"`
 	ReservedPorts string `hcl:"reserved_ports"`
+
+	// Fallbacks are the alternative CIDRs and interfaces of the host network,
+	// for nodes where none of the addresses match its CIDR and interface. They
+	// are tried in order, and the first one that matches an address of the
+	// node is used. The fallback blocks are parsed by hand, because HCL1 can't
+	// decode blocks nested in labeled blocks.
+	Fallbacks []*ClientHostNetworkFallback `hcl:"-"`
 }
 
 func (p *ClientHostNetworkConfig) Copy() *ClientHostNetworkConfig {
@@ -826,5 +835,24 @@ func (p *ClientHostNetworkConfig) Copy() *ClientHostNetworkConfig {
 
 	c := new(ClientHostNetworkConfig)
 	*c = *p
+	c.Fallbacks = helper.CopySlice(p.Fallbacks)
+	return c
+}
+
+// ClientHostNetworkFallback is an alternative CIDR and interface of a host
+// network, with its own reserved ports.
+type ClientHostNetworkFallback struct {
+	CIDR          string `hcl:"cidr"`
+	Interface     string `hcl:"interface"`
+	ReservedPorts string `hcl:"reserved_ports"`
+}
+
+func (f *ClientHostNetworkFallback) Copy() *ClientHostNetworkFallback {
+	if f == nil {
+		return nil
+	}
+
+	c := new(ClientHostNetworkFallback)
+	*c = *f
 	return c
 }
@@ -369,12 +369,19 @@ type NodeMetaApplyRequest struct {
 	// Meta is the new Node metadata being applied and differs slightly
 	// from Node.Meta as nil values are used to unset Node.Meta keys.
 	Meta map[string]*string
+
+	// TTL is how long the keys set by this request are kept before they are
+	// removed. Zero keeps them until they are unset.
+	TTL time.Duration
 }
 
 func (n *NodeMetaApplyRequest) Validate() error {
 	if len(n.Meta) == 0 {
 		return fmt.Errorf("missing required Meta object")
 	}
+	if n.TTL < 0 {
+		return fmt.Errorf("ttl must be >= 0")
+	}
 	for k := range n.Meta {
 		if k == "" {
 			return fmt.Errorf("metadata keys must not be empty")
@@ -403,4 +410,29 @@ type NodeMetaResponse struct {
 
 	// Static is the static Node metadata (set via agent configuration)
 	Static map[string]string
+
+	// DynamicInfo describes the writer and expiration of the dynamic Node
+	// metadata keys that are set.
+	DynamicInfo map[string]*NodeMetaInfo
+}
+
+// NodeMetaInfo describes a dynamic Node metadata key.
+type NodeMetaInfo struct {
+	// Writer is the identity of the request that last set the key.
+	Writer string
+
+	// ModifyTime is when the key was last set.
+	ModifyTime time.Time
+
+	// ExpireTime is when the key is removed, or zero if it doesn't expire.
+	ExpireTime time.Time
+}
+
+// Copy returns a copy of the NodeMetaInfo.
+func (n *NodeMetaInfo) Copy() *NodeMetaInfo {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	return &c
 }
@@ -129,6 +129,8 @@ func constraintTargetEscapes(target string) bool {
 		return true
 	case strings.HasPrefix(target, "${meta.unique."):
 		return true
+	case target == "${node.uptime}", target == "${node.alloc_churn}":
+		return true
 	default:
 		return false
 	}
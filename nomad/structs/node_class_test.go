@@ -275,7 +275,12 @@ func TestNode_EscapedConstraints(t *testing.T) {
 		RTarget: "test",
 		Operand: "!=",
 	}
-	constraints := []*Constraint{ne1, ne2, ne3, e1, e2, e3}
+	e4 := &Constraint{
+		LTarget: "${node.uptime}",
+		RTarget: "3600",
+		Operand: ">",
+	}
+	constraints := []*Constraint{ne1, ne2, ne3, e1, e2, e3, e4}
 	expected := []*Constraint{ne1, ne2, ne3}
 	if act := EscapedConstraints(constraints); reflect.DeepEqual(act, expected) {
 		t.Fatalf("EscapedConstraints(%v) returned %v; want %v", constraints, act, expected)
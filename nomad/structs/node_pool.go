@@ -4,9 +4,11 @@
 package structs
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/helper/pointer"
@@ -58,6 +60,10 @@ type NodePool struct {
 	// node pool.
 	SchedulerConfiguration *NodePoolSchedulerConfiguration
 
+	// HeartbeatConfig is the heartbeat configuration of the nodes in the
+	// node pool.
+	HeartbeatConfig *NodePoolHeartbeatConfig
+
 	// Hash is the hash of the node pool which is used to efficiently diff when
 	// we replicate pools across regions.
 	Hash []byte
@@ -83,6 +89,7 @@ func (n *NodePool) Validate() error {
 	}
 
 	mErr = multierror.Append(mErr, n.SchedulerConfiguration.Validate())
+	mErr = multierror.Append(mErr, n.HeartbeatConfig.Validate())
 
 	return mErr.ErrorOrNil()
 }
@@ -97,6 +104,7 @@ func (n *NodePool) Copy() *NodePool {
 	*nc = *n
 	nc.Meta = maps.Clone(nc.Meta)
 	nc.SchedulerConfiguration = nc.SchedulerConfiguration.Copy()
+	nc.HeartbeatConfig = nc.HeartbeatConfig.Copy()
 
 	nc.Hash = make([]byte, len(n.Hash))
 	copy(nc.Hash, n.Hash)
@@ -159,6 +167,12 @@ func (n *NodePool) SetHash() []byte {
 		}
 	}
 
+	if n.HeartbeatConfig != nil {
+		_, _ = hash.Write([]byte(n.HeartbeatConfig.MinHeartbeatTTL.String()))
+		_, _ = hash.Write([]byte(n.HeartbeatConfig.HeartbeatGrace.String()))
+		_, _ = hash.Write([]byte(n.HeartbeatConfig.MaxClientDisconnect.String()))
+	}
+
 	// sort keys to ensure hash stability when meta is stored later
 	var keys []string
 	for k := range n.Meta {
@@ -211,6 +225,57 @@ func (n *NodePoolSchedulerConfiguration) Copy() *NodePoolSchedulerConfiguration
 	return nc
 }
 
+// NodePoolHeartbeatConfig tunes how the servers track the heartbeats of the
+// nodes in a node pool and handle the allocations of the nodes that miss them.
+// Zero values use the server configuration.
+type NodePoolHeartbeatConfig struct {
+	// MinHeartbeatTTL is the minimum TTL given to the nodes of the pool. The
+	// servers still increase it to respect max_heartbeats_per_second.
+	MinHeartbeatTTL time.Duration
+
+	// HeartbeatGrace is the additional time given to the nodes of the pool
+	// to heartbeat after their TTL before being marked as down.
+	HeartbeatGrace time.Duration
+
+	// MaxClientDisconnect is the max_client_disconnect applied to the task
+	// groups of the jobs in the pool that don't configure it or
+	// stop_after_client_disconnect. The allocations of a node that misses its
+	// heartbeats are marked unknown for this duration instead of being
+	// replaced immediately.
+	MaxClientDisconnect time.Duration
+}
+
+// Copy returns a copy of the node pool heartbeat configuration.
+func (n *NodePoolHeartbeatConfig) Copy() *NodePoolHeartbeatConfig {
+	if n == nil {
+		return nil
+	}
+
+	nc := new(NodePoolHeartbeatConfig)
+	*nc = *n
+	return nc
+}
+
+// Validate returns an error if the node pool heartbeat configuration is
+// invalid.
+func (n *NodePoolHeartbeatConfig) Validate() error {
+	if n == nil {
+		return nil
+	}
+
+	var mErr *multierror.Error
+	if n.MinHeartbeatTTL < 0 {
+		mErr = multierror.Append(mErr, errors.New("min_heartbeat_ttl must not be negative"))
+	}
+	if n.HeartbeatGrace < 0 {
+		mErr = multierror.Append(mErr, errors.New("heartbeat_grace must not be negative"))
+	}
+	if n.MaxClientDisconnect < 0 {
+		mErr = multierror.Append(mErr, errors.New("max_client_disconnect must not be negative"))
+	}
+	return mErr.ErrorOrNil()
+}
+
 // NodePoolListRequest is used to list node pools.
 type NodePoolListRequest struct {
 	QueryOptions
@@ -6,6 +6,7 @@ package structs
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/helper/pointer"
@@ -23,6 +24,9 @@ func TestNodePool_Copy(t *testing.T) {
 			SchedulerAlgorithm:            SchedulerAlgorithmSpread,
 			MemoryOversubscriptionEnabled: pointer.Of(false),
 		},
+		HeartbeatConfig: &NodePoolHeartbeatConfig{
+			MinHeartbeatTTL: 5 * time.Second,
+		},
 	}
 	poolCopy := pool.Copy()
 	poolCopy.Name = "copy"
@@ -31,10 +35,12 @@ func TestNodePool_Copy(t *testing.T) {
 	poolCopy.Meta["new_key"] = "true"
 	poolCopy.SchedulerConfiguration.SchedulerAlgorithm = SchedulerAlgorithmBinpack
 	poolCopy.SchedulerConfiguration.MemoryOversubscriptionEnabled = pointer.Of(true)
+	poolCopy.HeartbeatConfig.MinHeartbeatTTL = time.Minute
 
 	must.NotEq(t, pool, poolCopy)
 	must.NotEq(t, pool.Meta, poolCopy.Meta)
 	must.NotEq(t, pool.SchedulerConfiguration, poolCopy.SchedulerConfiguration)
+	must.NotEq(t, pool.HeartbeatConfig, poolCopy.HeartbeatConfig)
 }
 
 func TestNodePool_Validate(t *testing.T) {
@@ -74,6 +80,26 @@ func TestNodePool_Validate(t *testing.T) {
 			},
 			expectedErr: "description longer",
 		},
+		{
+			name: "valid heartbeat config",
+			pool: &NodePool{
+				Name: "valid",
+				HeartbeatConfig: &NodePoolHeartbeatConfig{
+					MinHeartbeatTTL:     5 * time.Second,
+					MaxClientDisconnect: time.Hour,
+				},
+			},
+		},
+		{
+			name: "invalid heartbeat config",
+			pool: &NodePool{
+				Name: "valid",
+				HeartbeatConfig: &NodePoolHeartbeatConfig{
+					HeartbeatGrace: -time.Second,
+				},
+			},
+			expectedErr: "heartbeat_grace must not be negative",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -6,7 +6,9 @@ package structs
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"net/netip"
+	"slices"
 	"time"
 
 	"github.com/hashicorp/go-uuid"
@@ -182,6 +184,12 @@ type AutopilotConfig struct {
 	// upgrade versions when performing migrations.
 	EnableCustomUpgrades bool
 
+	// NonVoters are the IDs of the servers autopilot keeps as non-voting
+	// servers, in addition to the servers started with non_voting_server.
+	// Non-voting servers replicate the state and serve stale reads and the
+	// event stream without being part of the quorum.
+	NonVoters []string
+
 	// CreateIndex/ModifyIndex store the create/modify indexes of this configuration.
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -193,9 +201,23 @@ func (a *AutopilotConfig) Copy() *AutopilotConfig {
 	}
 
 	na := *a
+	na.NonVoters = slices.Clone(a.NonVoters)
 	return &na
 }
 
+// RaftSetVoterRequest is used by the Operator endpoint to set whether a Raft
+// peer is a voter or a non-voting server.
+type RaftSetVoterRequest struct {
+	// ID is the ID of the peer.
+	ID raft.ServerID
+
+	// Voter is whether the peer must be a voter.
+	Voter bool
+
+	// WriteRequest holds the Region for this request.
+	WriteRequest
+}
+
 // SchedulerAlgorithm is an enum string that encapsulates the valid options for a
 // SchedulerConfiguration block's SchedulerAlgorithm. These modes will allow the
 // scheduler to be user-selectable.
@@ -223,6 +245,10 @@ type SchedulerConfiguration struct {
 	// MemoryOversubscriptionEnabled specifies whether memory oversubscription is enabled
 	MemoryOversubscriptionEnabled bool `hcl:"memory_oversubscription_enabled"`
 
+	// LoadAwareScoringEnabled specifies whether the utilization reported by
+	// nodes in their heartbeats is stored and used to score nodes
+	LoadAwareScoringEnabled bool `hcl:"load_aware_scoring_enabled"`
+
 	// RejectJobRegistration disables new job registrations except with a
 	// management ACL token
 	RejectJobRegistration bool `hcl:"reject_job_registration"`
@@ -233,6 +259,21 @@ type SchedulerConfiguration struct {
 	// during leadership transitions.
 	PauseEvalBroker bool `hcl:"pause_eval_broker"`
 
+	// EvalTraceConfig specifies whether schedulers should retain a trace of
+	// the decisions made while processing evaluations, and how many traces
+	// are retained.
+	EvalTraceConfig EvalTraceConfig `hcl:"eval_trace_config"`
+
+	// GCConfig overrides the garbage collection thresholds and batch sizes of
+	// the server configuration per object type, so they can be tuned without
+	// restarting the servers. It's only set with the operator API.
+	GCConfig GCConfig `hcl:"-"`
+
+	// BatchFairShareConfig specifies whether the evaluations of batch jobs
+	// are dequeued according to the weights and recent usage of their
+	// namespaces instead of their priority.
+	BatchFairShareConfig BatchFairShareConfig `hcl:"batch_fair_share_config"`
+
 	// CreateIndex/ModifyIndex store the create/modify indexes of this configuration.
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -244,6 +285,7 @@ func (s *SchedulerConfiguration) Copy() *SchedulerConfiguration {
 	}
 
 	ns := *s
+	ns.BatchFairShareConfig.NamespaceWeights = maps.Clone(s.BatchFairShareConfig.NamespaceWeights)
 	return &ns
 }
 
@@ -292,6 +334,21 @@ func (s *SchedulerConfiguration) Validate() error {
 		return fmt.Errorf("invalid scheduler algorithm: %v", s.SchedulerAlgorithm)
 	}
 
+	if s.EvalTraceConfig.MaxNodes < 0 {
+		return fmt.Errorf("eval trace max nodes must not be negative")
+	}
+	if s.EvalTraceConfig.MaxTraces < 0 {
+		return fmt.Errorf("eval trace max traces must not be negative")
+	}
+
+	if err := s.GCConfig.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.BatchFairShareConfig.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -328,6 +385,171 @@ type PreemptionConfig struct {
 	ServiceSchedulerEnabled bool `hcl:"service_scheduler_enabled"`
 }
 
+const (
+	// DefaultEvalTraceMaxNodes is the default number of nodes recorded per
+	// placement in an evaluation trace.
+	DefaultEvalTraceMaxNodes = 100
+
+	// DefaultEvalTraceMaxTraces is the default number of evaluation traces
+	// retained in state.
+	DefaultEvalTraceMaxTraces = 1000
+)
+
+// EvalTraceConfig specifies whether evaluation traces are recorded and the
+// bounds of their retention.
+type EvalTraceConfig struct {
+	// Enabled specifies if schedulers record a trace of the evaluations they
+	// process.
+	Enabled bool `hcl:"enabled"`
+
+	// MaxNodes is the maximum number of filtered and exhausted nodes
+	// recorded for each placement. Defaults to DefaultEvalTraceMaxNodes.
+	MaxNodes int `hcl:"max_nodes"`
+
+	// MaxTraces is the maximum number of traces retained in state, the
+	// oldest traces are deleted once the limit is reached. Defaults to
+	// DefaultEvalTraceMaxTraces.
+	MaxTraces int `hcl:"max_traces"`
+}
+
+// EffectiveMaxNodes returns the maximum number of nodes recorded for each
+// placement, taking the default into account.
+func (c EvalTraceConfig) EffectiveMaxNodes() int {
+	if c.MaxNodes == 0 {
+		return DefaultEvalTraceMaxNodes
+	}
+	return c.MaxNodes
+}
+
+// EffectiveMaxTraces returns the maximum number of traces retained in state,
+// taking the default into account.
+func (c EvalTraceConfig) EffectiveMaxTraces() int {
+	if c.MaxTraces == 0 {
+		return DefaultEvalTraceMaxTraces
+	}
+	return c.MaxTraces
+}
+
+// BatchFairShareConfig specifies whether the ready evaluations of batch jobs
+// are dequeued according to the weights of their namespaces and their recent
+// usage of the cluster, in the manner of dominant resource fairness, instead
+// of strictly by priority and creation order.
+type BatchFairShareConfig struct {
+	// Enabled specifies if the evaluations of batch jobs are dequeued by fair
+	// share.
+	Enabled bool `hcl:"enabled"`
+
+	// NamespaceWeights are the weights of the namespaces. A namespace with
+	// twice the weight of another is entitled to twice its share of the
+	// cluster. Namespaces without a weight have a weight of 1.
+	NamespaceWeights map[string]int `hcl:"namespace_weights"`
+}
+
+// Weight returns the weight of the namespace.
+func (c BatchFairShareConfig) Weight(namespace string) int {
+	if w, ok := c.NamespaceWeights[namespace]; ok {
+		return w
+	}
+	return 1
+}
+
+// Validate returns an error if the weight of a namespace isn't positive.
+func (c BatchFairShareConfig) Validate() error {
+	for ns, w := range c.NamespaceWeights {
+		if w <= 0 {
+			return fmt.Errorf("batch fair share weight of namespace %q must be positive", ns)
+		}
+	}
+	return nil
+}
+
+// GCConfig overrides the garbage collection thresholds and batch sizes of the
+// servers per object type. Zero values fall back to the thresholds of the
+// server configuration and to MaxUUIDsPerWriteRequest. Allocations are
+// collected with their evaluations, so the evaluation thresholds and batch
+// size apply to them.
+type GCConfig struct {
+	// JobThreshold is how old a job must be to be collected.
+	JobThreshold time.Duration
+
+	// JobBatchSize is the maximum number of jobs deregistered per request.
+	JobBatchSize int
+
+	// EvalThreshold is how old an evaluation must be to be collected.
+	EvalThreshold time.Duration
+
+	// BatchEvalThreshold is how old an evaluation of a batch job must be to
+	// be collected.
+	BatchEvalThreshold time.Duration
+
+	// EvalBatchSize is the maximum number of evaluations and allocations
+	// reaped per request.
+	EvalBatchSize int
+
+	// DeploymentThreshold is how old a deployment must be to be collected.
+	DeploymentThreshold time.Duration
+
+	// DeploymentBatchSize is the maximum number of deployments reaped per
+	// request.
+	DeploymentBatchSize int
+
+	// CSIVolumeClaimThreshold is how old a CSI volume must be for its
+	// claims to be collected.
+	CSIVolumeClaimThreshold time.Duration
+}
+
+// Validate returns an error if a threshold or batch size is negative.
+func (c GCConfig) Validate() error {
+	thresholds := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"job", c.JobThreshold},
+		{"eval", c.EvalThreshold},
+		{"batch eval", c.BatchEvalThreshold},
+		{"deployment", c.DeploymentThreshold},
+		{"CSI volume claim", c.CSIVolumeClaimThreshold},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value < 0 {
+			return fmt.Errorf("%s GC threshold must not be negative", threshold.name)
+		}
+	}
+
+	batchSizes := []struct {
+		name  string
+		value int
+	}{
+		{"job", c.JobBatchSize},
+		{"eval", c.EvalBatchSize},
+		{"deployment", c.DeploymentBatchSize},
+	}
+	for _, size := range batchSizes {
+		if size.value < 0 {
+			return fmt.Errorf("%s GC batch size must not be negative", size.name)
+		}
+	}
+	return nil
+}
+
+// EffectiveThreshold returns the threshold to use, given a threshold of the
+// GC configuration and the one of the server configuration.
+func (c GCConfig) EffectiveThreshold(threshold, serverThreshold time.Duration) time.Duration {
+	if threshold == 0 {
+		return serverThreshold
+	}
+	return threshold
+}
+
+// EffectiveBatchSize returns the batch size to use, given a batch size of the
+// GC configuration.
+func (c GCConfig) EffectiveBatchSize(size int) int {
+	if size == 0 {
+		return MaxUUIDsPerWriteRequest
+	}
+	return size
+}
+
 // SchedulerSetConfigRequest is used by the Operator endpoint to update the
 // current Scheduler configuration of the cluster.
 type SchedulerSetConfigRequest struct {
@@ -5,6 +5,7 @@ package structs
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/ci"
 	"github.com/hashicorp/nomad/helper/pointer"
@@ -106,3 +107,48 @@ func TestSchedulerConfiguration_WithNodePool(t *testing.T) {
 		})
 	}
 }
+
+func TestGCConfig(t *testing.T) {
+	ci.Parallel(t)
+
+	var c GCConfig
+	must.NoError(t, c.Validate())
+	must.Eq(t, time.Hour, c.EffectiveThreshold(c.JobThreshold, time.Hour))
+	must.Eq(t, MaxUUIDsPerWriteRequest, c.EffectiveBatchSize(c.JobBatchSize))
+
+	c.JobThreshold = time.Minute
+	c.JobBatchSize = 10
+	must.NoError(t, c.Validate())
+	must.Eq(t, time.Minute, c.EffectiveThreshold(c.JobThreshold, time.Hour))
+	must.Eq(t, 10, c.EffectiveBatchSize(c.JobBatchSize))
+
+	c.EvalThreshold = -time.Minute
+	must.EqError(t, c.Validate(), "eval GC threshold must not be negative")
+
+	c.EvalThreshold = 0
+	c.DeploymentBatchSize = -1
+	must.EqError(t, c.Validate(), "deployment GC batch size must not be negative")
+
+	sc := &SchedulerConfiguration{GCConfig: c}
+	must.Error(t, sc.Validate())
+}
+
+func TestBatchFairShareConfig(t *testing.T) {
+	ci.Parallel(t)
+
+	c := BatchFairShareConfig{
+		Enabled:          true,
+		NamespaceWeights: map[string]int{"research": 3},
+	}
+	must.NoError(t, c.Validate())
+	must.Eq(t, 3, c.Weight("research"))
+	must.Eq(t, 1, c.Weight("default"))
+
+	// Copies don't share the weights.
+	sc := &SchedulerConfiguration{BatchFairShareConfig: c}
+	sc.Copy().BatchFairShareConfig.NamespaceWeights["research"] = 5
+	must.Eq(t, 3, sc.BatchFairShareConfig.Weight("research"))
+
+	sc.BatchFairShareConfig.NamespaceWeights["default"] = 0
+	must.EqError(t, sc.Validate(), `batch fair share weight of namespace "default" must be positive`)
+}
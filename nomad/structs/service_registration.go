@@ -41,6 +41,13 @@ const (
 	// Args: ServiceRegistrationByNameRequest
 	// Reply: ServiceRegistrationByNameResponse
 	ServiceRegistrationGetServiceRPCMethod = "ServiceRegistration.GetService"
+
+	// ServiceRegistrationListInstancesRPCMethod is the RPC method for listing
+	// the healthy instances of services along with their allocation.
+	//
+	// Args: ServiceRegistrationInstancesRequest
+	// Reply: ServiceRegistrationInstancesResponse
+	ServiceRegistrationListInstancesRPCMethod = "ServiceRegistration.ListInstances"
 )
 
 // ServiceRegistration is the internal representation of a Nomad service
@@ -266,6 +273,38 @@ type ServiceRegistrationStub struct {
 	Tags        []string
 }
 
+// ServiceRegistrationInstancesRequest is the request object to list the
+// healthy instances of services. It supports single and wildcard namespace
+// listings.
+type ServiceRegistrationInstancesRequest struct {
+	// ServiceName restricts the instances to the ones of this service, if
+	// set.
+	ServiceName string
+
+	// Tags restrict the instances to the ones with all these tags, if set.
+	Tags []string
+
+	QueryOptions
+}
+
+// ServiceRegistrationInstancesResponse is the response object when listing
+// the healthy instances of services.
+type ServiceRegistrationInstancesResponse struct {
+	Instances []*ServiceInstance
+	QueryMeta
+}
+
+// ServiceInstance is a service registration along with the details of the
+// allocation that registered it, used to label the instance in monitoring
+// systems.
+type ServiceInstance struct {
+	*ServiceRegistration
+
+	AllocName string
+	TaskGroup string
+	NodeName  string
+}
+
 // ServiceRegistrationByNameRequest is the request object to perform a lookup
 // of services matching a specific name.
 type ServiceRegistrationByNameRequest struct {
@@ -622,6 +622,37 @@ type Service struct {
 	// Its name will be `consul-service/${service_name}`, and its contents will
 	// match the server's `consul.service_identity` configuration block.
 	Identity *WorkloadIdentity
+
+	// Certificate requests a TLS certificate for the service from the
+	// workload CA of the servers. Only services using the Nomad provider can
+	// request certificates.
+	Certificate *ServiceCertificate
+}
+
+// ServiceCertificate requests a TLS certificate for a service, issued by the
+// workload CA of the servers and delivered to the secrets dirs of the tasks
+// implementing the service. The certificate has the service name, and the
+// tags of the service prefixed to its name, as DNS subject alternative names,
+// along with the IP addresses of the allocation.
+type ServiceCertificate struct {
+	// TTL is how long the certificate is valid for. It is renewed before it
+	// expires.
+	TTL time.Duration
+}
+
+func (c *ServiceCertificate) Copy() *ServiceCertificate {
+	if c == nil {
+		return nil
+	}
+	nc := *c
+	return &nc
+}
+
+func (c *ServiceCertificate) Equal(o *ServiceCertificate) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+	return c.TTL == o.TTL
 }
 
 // Copy the block recursively. Returns nil if nil.
@@ -649,6 +680,7 @@ func (s *Service) Copy() *Service {
 	ns.TaggedAddresses = maps.Clone(s.TaggedAddresses)
 
 	ns.Identity = s.Identity.Copy()
+	ns.Certificate = s.Certificate.Copy()
 
 	return ns
 }
@@ -769,6 +801,10 @@ func (s *Service) Validate() error {
 		mErr.Errors = append(mErr.Errors, err)
 	}
 
+	if err := s.validateCertificate(); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -867,6 +903,24 @@ func (s *Service) validateNomadService(mErr *multierror.Error) {
 	}
 }
 
+// validateCertificate checks the service can be issued a certificate, which
+// is signed by the servers before the service name is interpolated.
+func (s *Service) validateCertificate() error {
+	if s.Certificate == nil {
+		return nil
+	}
+	if s.Provider != ServiceProviderNomad {
+		return fmt.Errorf("Service certificate requires provider %q", ServiceProviderNomad)
+	}
+	if strings.Contains(s.Name, "${") {
+		return fmt.Errorf("Service with a certificate cannot interpolate its name: %q", s.Name)
+	}
+	if s.Certificate.TTL < 0 {
+		return errors.New("Service certificate ttl must not be negative")
+	}
+	return nil
+}
+
 // validateIdentity performs validation on workload identity field populated by
 // the job mutating hook
 func (s *Service) validateIdentity() error {
@@ -1071,6 +1125,10 @@ func (s *Service) Equal(o *Service) bool {
 		return false
 	}
 
+	if !s.Certificate.Equal(o.Certificate) {
+		return false
+	}
+
 	return true
 }
 
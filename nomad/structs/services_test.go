@@ -1994,6 +1994,54 @@ func TestService_Equal(t *testing.T) {
 
 	o.TaggedAddresses = map[string]string{"foo": "bar"}
 	assertDiff()
+
+	o.Certificate = &ServiceCertificate{TTL: time.Hour}
+	assertDiff()
+}
+
+func TestService_validateCertificate(t *testing.T) {
+	ci.Parallel(t)
+
+	testCases := []struct {
+		name    string
+		service *Service
+		expErr  string
+	}{
+		{
+			name:    "no certificate",
+			service: &Service{Name: "${NOMAD_JOB_NAME}", Provider: ServiceProviderConsul},
+		},
+		{
+			name:    "valid",
+			service: &Service{Name: "api", Provider: ServiceProviderNomad, Certificate: &ServiceCertificate{TTL: time.Hour}},
+		},
+		{
+			name:    "consul provider",
+			service: &Service{Name: "api", Provider: ServiceProviderConsul, Certificate: &ServiceCertificate{}},
+			expErr:  `Service certificate requires provider "nomad"`,
+		},
+		{
+			name:    "interpolated name",
+			service: &Service{Name: "${NOMAD_JOB_NAME}", Provider: ServiceProviderNomad, Certificate: &ServiceCertificate{}},
+			expErr:  "Service with a certificate cannot interpolate its name",
+		},
+		{
+			name:    "negative ttl",
+			service: &Service{Name: "api", Provider: ServiceProviderNomad, Certificate: &ServiceCertificate{TTL: -time.Hour}},
+			expErr:  "Service certificate ttl must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.service.validateCertificate()
+			if tc.expErr == "" {
+				must.NoError(t, err)
+			} else {
+				must.ErrorContains(t, err, tc.expErr)
+			}
+		})
+	}
 }
 
 func TestService_validateNomadService(t *testing.T) {
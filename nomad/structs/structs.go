@@ -13,6 +13,7 @@ import (
 	"encoding/base32"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
@@ -128,10 +129,15 @@ const (
 	ACLBindingRulesDeleteRequestType             MessageType = 58
 	NodePoolUpsertRequestType                    MessageType = 59
 	NodePoolDeleteRequestType                    MessageType = 60
+	EvalTraceUpsertRequestType                   MessageType = 61
+	LocalVolumeClaimDeleteRequestType            MessageType = 62
+	JobSuspendRequestType                        MessageType = 63
 
 	// Namespace types were moved from enterprise and therefore start at 64
 	NamespaceUpsertRequestType MessageType = 64
 	NamespaceDeleteRequestType MessageType = 65
+
+	AllocIdentityEventsRequestType MessageType = 66
 )
 
 const (
@@ -640,9 +646,38 @@ type NodeUpdateStatusRequest struct {
 	Status    string
 	NodeEvent *NodeEvent
 	UpdatedAt int64
+
+	// Utilization is the recent utilization reported by the client. It is
+	// only written to state when load-aware scoring is enabled or the client
+	// reports its resource usage.
+	Utilization *NodeUtilization
+
+	WriteRequest
+}
+
+// NodeHeartbeatRequest is used for the Node.Heartbeat endpoint to renew the
+// heartbeat TTL of a ready node without updating its status.
+type NodeHeartbeatRequest struct {
+	NodeID string
 	WriteRequest
 }
 
+// NodeHeartbeatResponse is used to respond to a node heartbeat.
+type NodeHeartbeatResponse struct {
+	HeartbeatTTL time.Duration
+
+	// StatusUpdateRequired is true when the node isn't ready, so its TTL must
+	// be renewed with a status update that moves it through the node status
+	// transitions.
+	StatusUpdateRequired bool
+
+	// ServerTime is the time of the server handling the heartbeat, in
+	// nanoseconds since the Unix epoch, so clients can detect clock skew.
+	ServerTime int64
+
+	WriteMeta
+}
+
 // NodeUpdateDrainRequest is used for updating the drain strategy
 type NodeUpdateDrainRequest struct {
 	NodeID        string
@@ -751,6 +786,11 @@ type JobRegisterRequest struct {
 	// there is an active deployment for the job it will be canceled.
 	Deployment *Deployment
 
+	// Warnings are the warnings raised when the job was registered, which
+	// replace the warnings of its summary. They're set by the servers, and
+	// nil leaves the warnings of the summary unchanged.
+	Warnings []*JobWarning
+
 	WriteRequest
 }
 
@@ -780,6 +820,12 @@ type JobDeregisterRequest struct {
 	// allocations stopped as a result of this Deregister call.
 	NoShutdownDelay bool
 
+	// ServiceDrain, if set, deregisters the services of the allocations
+	// stopped as a result of this Deregister call, and waits for the
+	// duration before killing their tasks, so load balancers can remove the
+	// services first. It can't be combined with NoShutdownDelay.
+	ServiceDrain time.Duration
+
 	// Eval is the evaluation to create that's associated with job deregister
 	Eval *Evaluation
 
@@ -872,6 +918,9 @@ type JobScaleRequest struct {
 	Message string
 	Error   bool
 	Meta    map[string]interface{}
+	// Annotation is a free-form note explaining why the scaling action was
+	// performed, stored with the scaling event.
+	Annotation string
 	// PolicyOverride is set when the user is attempting to override any policies
 	PolicyOverride bool
 	WriteRequest
@@ -894,6 +943,11 @@ func (r *JobScaleRequest) Validate() error {
 		return NewErrRPCCoded(400, "missing task group name for scaling action")
 	}
 
+	if len(r.Annotation) > MaxScalingEventAnnotationLength {
+		return NewErrRPCCoded(400,
+			fmt.Sprintf("scaling annotation must be at most %d bytes", MaxScalingEventAnnotationLength))
+	}
+
 	if r.Count != nil {
 		if *r.Count < 0 {
 			return NewErrRPCCoded(400, "scaling action count can't be negative")
@@ -913,9 +967,55 @@ func (r *JobScaleRequest) Validate() error {
 	return nil
 }
 
-// JobSummaryRequest is used when we just need to get a specific job summary
+// JobOverrideScheduleRequest is used for the Job.OverrideSchedule endpoint to
+// force a job with an active schedule active or inactive.
+type JobOverrideScheduleRequest struct {
+	JobID string
+
+	// Override is the override of the active schedule of the job. A nil
+	// override clears the current one.
+	Override *JobActiveScheduleOverride
+
+	WriteRequest
+}
+
+// Validate is used to validate the arguments in the request
+func (r *JobOverrideScheduleRequest) Validate() error {
+	if r.JobID == "" {
+		return NewErrRPCCoded(400, "missing job ID")
+	}
+	if r.Override != nil && !r.Override.Until.IsZero() && !r.Override.Until.After(time.Now()) {
+		return NewErrRPCCoded(400, "override expiration must be in the future")
+	}
+	return nil
+}
+
+// JobSuspendRequest is used for the Job.Suspend endpoint to suspend or
+// resume a batch job.
+type JobSuspendRequest struct {
+	JobID string
+
+	// Suspend is whether to suspend the job, or to resume it.
+	Suspend bool
+
+	// Eval is the evaluation created to stop the allocations of the job or
+	// to place them again.
+	Eval *Evaluation
+
+	WriteRequest
+}
+
 type JobSummaryRequest struct {
 	JobID string
+
+	// Health requests the health of the job to be computed and included in
+	// the summary.
+	Health bool
+
+	// RestartWindow is the period over which task restarts are counted in
+	// the health of the job. Defaults to DefaultJobHealthRestartWindow.
+	RestartWindow time.Duration
+
 	QueryOptions
 }
 
@@ -1479,7 +1579,14 @@ type JobRegisterResponse struct {
 	QueryMeta
 }
 
-// JobDeregisterResponse is used to respond to a job deregistration
+// JobSuspendResponse is used to respond to suspending or resuming a job.
+type JobSuspendResponse struct {
+	EvalID          string
+	EvalCreateIndex uint64
+	JobModifyIndex  uint64
+	QueryMeta
+}
+
 type JobDeregisterResponse struct {
 	EvalID          string
 	EvalCreateIndex uint64
@@ -1540,6 +1647,10 @@ type NodeUpdateResponse struct {
 	// has for their scheduling status during heartbeats.
 	SchedulingEligibility string
 
+	// ServerTime is the time of the server handling the request, in
+	// nanoseconds since the Unix epoch, so clients can detect clock skew.
+	ServerTime int64
+
 	QueryMeta
 }
 
@@ -1582,6 +1693,14 @@ type SingleNodeResponse struct {
 	QueryMeta
 }
 
+// NodeUtilizationResponse is used to return the utilization rollups of nodes
+type NodeUtilizationResponse struct {
+	// Rollups are the utilization rollups by node ID, oldest first. Only the
+	// latest rollup of each node is returned when no node is requested.
+	Rollups map[string][]*NodeUtilizationRollup
+	QueryMeta
+}
+
 // NodeListResponse is used for a list request
 type NodeListResponse struct {
 	Nodes []*NodeListStub
@@ -1633,6 +1752,20 @@ type JobDispatchResponse struct {
 	WriteMeta
 }
 
+// JobDispatchPayloadRequest is used by nodes to retrieve the dispatch payload
+// of one of their allocations when it's kept in the payload store.
+type JobDispatchPayloadRequest struct {
+	AllocID string
+	QueryOptions
+}
+
+// JobDispatchPayloadResponse is the snappy compressed dispatch payload of an
+// allocation.
+type JobDispatchPayloadResponse struct {
+	Payload []byte
+	QueryMeta
+}
+
 // JobListResponse is used for a list request
 type JobListResponse struct {
 	Jobs []*JobListStub
@@ -1682,9 +1815,43 @@ type JobPlanResponse struct {
 	// deprecation warnings.
 	Warnings string
 
+	// Lints are the violations of the lint rules configured with the warn
+	// level. They are also included in Warnings.
+	Lints []*JobLint
+
 	WriteMeta
 }
 
+// JobLint is a violation of a job lint rule configured on the servers.
+type JobLint struct {
+	// Rule is the name of the violated rule.
+	Rule string
+
+	// Level is the level of the rule, either "warn" or "deny".
+	Level string
+
+	// TaskGroup and Task are the task group and task violating the rule, if
+	// any.
+	TaskGroup string
+	Task      string
+
+	// Message describes the violation.
+	Message string
+}
+
+// Error implements the error interface, so lints can be returned along with
+// the other validation warnings and errors.
+func (l *JobLint) Error() string {
+	var location string
+	switch {
+	case l.Task != "":
+		location = fmt.Sprintf(" in task %q of group %q", l.Task, l.TaskGroup)
+	case l.TaskGroup != "":
+		location = fmt.Sprintf(" in group %q", l.TaskGroup)
+	}
+	return fmt.Sprintf("lint %s%s: %s", l.Rule, location, l.Message)
+}
+
 // SingleAllocResponse is used to return a single allocation
 type SingleAllocResponse struct {
 	Alloc *Allocation
@@ -1854,6 +2021,7 @@ const (
 	NodeEventSubsystemCluster   = "Cluster"
 	NodeEventSubsystemScheduler = "Scheduler"
 	NodeEventSubsystemStorage   = "Storage"
+	NodeEventSubsystemMeta      = "Meta"
 )
 
 // NodeEvent is a single unit representing a node’s state change
@@ -2068,6 +2236,129 @@ func (m *DrainMetadata) Copy() *DrainMetadata {
 	return c
 }
 
+const (
+	// NodeUtilizationStaleAfter is the age after which the utilization of a
+	// node is no longer used for scoring.
+	NodeUtilizationStaleAfter = 5 * time.Minute
+
+	// NodeUtilizationMinChange is the change in CPU load or memory pressure
+	// after which a heartbeat updates the stored utilization of a node.
+	NodeUtilizationMinChange = 0.1
+
+	// NodeUtilizationRollupInterval is the duration covered by each rollup of
+	// the utilization reported by a node.
+	NodeUtilizationRollupInterval = 5 * time.Minute
+
+	// NodeUtilizationRollupRetention is how long the servers keep the
+	// utilization rollups of a node.
+	NodeUtilizationRollupRetention = 24 * time.Hour
+)
+
+// NodeUtilization is the recent utilization of a node, as observed by the
+// client rather than computed from the resources allocated on the node.
+type NodeUtilization struct {
+	// CPULoad is the 1 minute load average divided by the number of cores.
+	CPULoad float64
+
+	// MemoryPressure is the fraction of the host memory that is unavailable.
+	MemoryPressure float64
+
+	// CPUUsedMHz, MemoryUsedMB, and DiskUsedMB summarize the resources used
+	// on the host. They are only reported by the clients configured with
+	// report_resource_usage, and are zero otherwise.
+	CPUUsedMHz   int64
+	MemoryUsedMB int64
+	DiskUsedMB   int64
+
+	// UpdatedAt is the time at which the utilization was stored, in
+	// nanoseconds since the Unix epoch.
+	UpdatedAt int64
+}
+
+func (u *NodeUtilization) Copy() *NodeUtilization {
+	if u == nil {
+		return nil
+	}
+	nu := *u
+	return &nu
+}
+
+// Stale returns true if the utilization is missing or too old to reflect the
+// current state of the node.
+func (u *NodeUtilization) Stale(now time.Time) bool {
+	return u == nil || now.Sub(time.Unix(0, u.UpdatedAt)) > NodeUtilizationStaleAfter
+}
+
+// NodeUtilizationRollup summarizes the utilization a node reported over a
+// NodeUtilizationRollupInterval.
+type NodeUtilizationRollup struct {
+	// Start is the start of the interval, in nanoseconds since the Unix
+	// epoch.
+	Start int64
+
+	// Samples is the number of utilization reports in the interval.
+	Samples int
+
+	CPULoadAvg        float64
+	CPULoadMax        float64
+	MemoryPressureAvg float64
+	MemoryPressureMax float64
+}
+
+func (r *NodeUtilizationRollup) Copy() *NodeUtilizationRollup {
+	if r == nil {
+		return nil
+	}
+	nr := *r
+	return &nr
+}
+
+// Add adds the reported utilization to the rollup.
+func (r *NodeUtilizationRollup) Add(u *NodeUtilization) {
+	n := float64(r.Samples)
+	r.CPULoadAvg = (r.CPULoadAvg*n + u.CPULoad) / (n + 1)
+	r.MemoryPressureAvg = (r.MemoryPressureAvg*n + u.MemoryPressure) / (n + 1)
+	r.CPULoadMax = max(r.CPULoadMax, u.CPULoad)
+	r.MemoryPressureMax = max(r.MemoryPressureMax, u.MemoryPressure)
+	r.Samples++
+}
+
+// HasResourceUsage returns true if the utilization includes the summary of
+// the resources used on the host.
+func (u *NodeUtilization) HasResourceUsage() bool {
+	return u != nil && (u.CPUUsedMHz > 0 || u.MemoryUsedMB > 0 || u.DiskUsedMB > 0)
+}
+
+// CPUUtilization returns the fraction of the CPU of the node that is used.
+// The CPU used is preferred to the load average when reported, since the
+// load average also counts the processes waiting on IO.
+func (u *NodeUtilization) CPUUtilization(node *Node) float64 {
+	if u.CPUUsedMHz > 0 && node != nil && node.NodeResources != nil {
+		if total := node.NodeResources.Processors.TotalCompute(); total > 0 {
+			return float64(u.CPUUsedMHz) / float64(total)
+		}
+	}
+	return u.CPULoad
+}
+
+// NeedsUpdate returns true if the newly reported utilization differs enough
+// from the stored one, or the stored one is about to become stale, that it
+// should be written to state.
+func (u *NodeUtilization) NeedsUpdate(reported *NodeUtilization, now time.Time) bool {
+	switch {
+	case reported == nil:
+		return false
+	case u == nil:
+		return true
+	case now.Sub(time.Unix(0, u.UpdatedAt)) > NodeUtilizationStaleAfter/2:
+		return true
+	case u.HasResourceUsage() != reported.HasResourceUsage():
+		return true
+	}
+	return math.Abs(u.CPULoad-reported.CPULoad) >= NodeUtilizationMinChange ||
+		math.Abs(u.MemoryPressure-reported.MemoryPressure) >= NodeUtilizationMinChange
+}
+
 // Node is a representation of a schedulable client node
 type Node struct {
 	// ID is a unique identifier for the node. It can be constructed
@@ -2096,6 +2387,12 @@ type Node struct {
 	// TLSEnabled indicates if the Agent has TLS enabled for the HTTP API
 	TLSEnabled bool
 
+	// ReverseTunnel indicates that the HTTP address of the node isn't
+	// reachable by users, such as when the client is behind NAT. The client
+	// API of the node must then be accessed through the servers, which reach
+	// the client over the connection it maintains to them.
+	ReverseTunnel bool
+
 	// Attributes is an arbitrary set of key/value
 	// data that can be used for constraints. Examples
 	// include "kernel.name=linux", "arch=386", "driver.docker=1",
@@ -2138,6 +2435,10 @@ type Node struct {
 	// NodePool is the node pool the node belongs to.
 	NodePool string
 
+	// MaxAllocs is the maximum number of non-terminal allocations the
+	// scheduler places on the node. Zero means unlimited.
+	MaxAllocs int
+
 	// ComputedClass is a unique id that identifies nodes with a common set of
 	// attributes and capabilities.
 	ComputedClass string
@@ -2189,6 +2490,11 @@ type Node struct {
 	// updatedd its allocations status.
 	LastAllocUpdateIndex uint64
 
+	// Utilization is the most recent utilization of the node reported in its
+	// heartbeats. It is only stored when load-aware scoring is enabled or the
+	// client reports its resource usage.
+	Utilization *NodeUtilization
+
 	// Raft Indexes
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -2291,6 +2597,7 @@ func (n *Node) Copy() *Node {
 	nn.HostVolumes = helper.DeepCopyMap(n.HostVolumes)
 	nn.HostNetworks = helper.DeepCopyMap(n.HostNetworks)
 	nn.LastDrain = nn.LastDrain.Copy()
+	nn.Utilization = nn.Utilization.Copy()
 	return &nn
 }
 
@@ -2803,6 +3110,11 @@ type DNSConfig struct {
 	Servers  []string
 	Searches []string
 	Options  []string
+
+	// ServiceHosts is the names of the Nomad services whose addresses are
+	// written to the hosts file shared by the tasks of the allocation. The
+	// entries are kept up to date as the services change.
+	ServiceHosts []string
 }
 
 func (d *DNSConfig) Equal(o *DNSConfig) bool {
@@ -2817,6 +3129,8 @@ func (d *DNSConfig) Equal(o *DNSConfig) bool {
 		return false
 	case !slices.Equal(d.Options, o.Options):
 		return false
+	case !slices.Equal(d.ServiceHosts, o.ServiceHosts):
+		return false
 	}
 
 	return true
@@ -2827,10 +3141,39 @@ func (d *DNSConfig) Copy() *DNSConfig {
 		return nil
 	}
 	return &DNSConfig{
-		Servers:  slices.Clone(d.Servers),
-		Searches: slices.Clone(d.Searches),
-		Options:  slices.Clone(d.Options),
+		Servers:      slices.Clone(d.Servers),
+		Searches:     slices.Clone(d.Searches),
+		Options:      slices.Clone(d.Options),
+		ServiceHosts: slices.Clone(d.ServiceHosts),
+	}
+}
+
+// Validate validates the DNS configuration of a network with the given mode.
+func (d *DNSConfig) Validate(mode string) error {
+	if d == nil {
+		return nil
+	}
+
+	var mErr multierror.Error
+	for _, option := range d.Options {
+		value, ok := strings.CutPrefix(option, "ndots:")
+		if !ok {
+			continue
+		}
+		if ndots, err := strconv.Atoi(value); err != nil || ndots < 0 || ndots > 15 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("DNS option %q must set ndots between 0 and 15", option))
+		}
+	}
+
+	if len(d.ServiceHosts) > 0 && mode != "bridge" && !strings.HasPrefix(mode, "cni/") {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("DNS service hosts require the bridge or a CNI network mode, not %q", mode))
+	}
+	for _, service := range d.ServiceHosts {
+		if _, ok := dns.IsDomainName(service); !ok || service == "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("DNS service host %q is not a valid host name", service))
+		}
 	}
+	return mErr.ErrorOrNil()
 }
 
 func (d *DNSConfig) IsZero() bool {
@@ -4249,9 +4592,13 @@ const (
 	// kept.
 	JobDefaultTrackedVersions = 6
 
-	// JobTrackedScalingEvents is the number of scaling events that are
-	// kept for a single task group.
+	// JobTrackedScalingEvents is the default number of scaling events that
+	// are kept for a single task group.
 	JobTrackedScalingEvents = 20
+
+	// MaxScalingEventAnnotationLength is the maximum length of the annotation
+	// of a scaling event.
+	MaxScalingEventAnnotationLength = 1024
 )
 
 // A JobSubmission contains the original job specification, along with the Variables
@@ -4271,6 +4618,12 @@ type JobSubmission struct {
 	// webUI (hcl2 only).
 	Variables string
 
+	// SourceRef is managed internally, do not set.
+	//
+	// The reference of the source if it's kept in the payload store of the
+	// servers instead of Source.
+	SourceRef string
+
 	// Namespace is managed internally, do not set.
 	//
 	// The namespace the associated job belongs to.
@@ -4308,6 +4661,7 @@ func (js *JobSubmission) Copy() *JobSubmission {
 		Format:         js.Format,
 		VariableFlags:  maps.Clone(js.VariableFlags),
 		Variables:      js.Variables,
+		SourceRef:      js.SourceRef,
 		Namespace:      js.Namespace,
 		JobID:          js.JobID,
 		Version:        js.Version,
@@ -4326,6 +4680,12 @@ type Job struct {
 	// queried and the job to be inspected as it is being killed.
 	Stop bool
 
+	// Suspended marks whether the user has suspended the batch job. A
+	// suspended job has its running allocations stopped like a stopped job,
+	// but it keeps its version and isn't garbage collected, so it only runs
+	// the allocations that didn't complete once it's resumed.
+	Suspended bool
+
 	// Region is the Nomad region that handles scheduling this job
 	Region string
 
@@ -4369,6 +4729,10 @@ type Job struct {
 	// that will happen in the admission mutators.
 	NodePool string
 
+	// MaxAllocsPerNode is the maximum number of non-terminal allocations of
+	// the job the scheduler places on a node. Zero means unlimited.
+	MaxAllocsPerNode int
+
 	// Constraints can be specified at a job level and apply to
 	// all the task groups and tasks.
 	Constraints []*Constraint
@@ -4398,6 +4762,10 @@ type Job struct {
 	// for dispatching.
 	ParameterizedJob *ParameterizedJobConfig
 
+	// ActiveSchedule restricts the times a service job runs to a set of
+	// windows, outside of which its groups are scaled to zero.
+	ActiveSchedule *JobActiveSchedule
+
 	// Dispatched is used to identify if the Job has been dispatched from a
 	// parameterized job.
 	Dispatched bool
@@ -4409,10 +4777,19 @@ type Job struct {
 	// Payload is the payload supplied when the job was dispatched.
 	Payload []byte
 
+	// PayloadRef is the reference of the payload supplied when the job was
+	// dispatched if it's kept in the payload store of the servers instead of
+	// Payload.
+	PayloadRef string
+
 	// Meta is used to associate arbitrary metadata with this
 	// job. This is opaque to Nomad.
 	Meta map[string]string
 
+	// Env is the environment variables set in all the tasks of the job, unless
+	// overridden by their group or the task.
+	Env map[string]string
+
 	// ConsulToken is the Consul token that proves the submitter of the job has
 	// access to the Service Identity policies associated with the job's
 	// Consul Connect enabled services. This field is only used to transfer the
@@ -4559,7 +4936,9 @@ func (j *Job) Copy() *Job {
 
 	nj.Periodic = nj.Periodic.Copy()
 	nj.Meta = maps.Clone(nj.Meta)
+	nj.Env = maps.Clone(nj.Env)
 	nj.ParameterizedJob = nj.ParameterizedJob.Copy()
+	nj.ActiveSchedule = nj.ActiveSchedule.Copy()
 	return nj
 }
 
@@ -4605,6 +4984,9 @@ func (j *Job) Validate() error {
 	if len(j.TaskGroups) == 0 {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing job task groups"))
 	}
+	if j.MaxAllocsPerNode < 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Job max_allocs_per_node must be >= 0"))
+	}
 	for idx, constr := range j.Constraints {
 		if err := constr.Validate(); err != nil {
 			outer := fmt.Errorf("Constraint %d validation failed: %s", idx+1, err)
@@ -4707,6 +5089,19 @@ func (j *Job) Validate() error {
 		}
 	}
 
+	if j.ActiveSchedule != nil {
+		if j.Type != JobTypeService || (j.IsPeriodic() && j.Periodic.Enabled) || j.IsParameterized() {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf(
+				"Active schedule can only be used with %q jobs that are neither periodic nor parameterized", JobTypeService,
+			))
+		}
+
+		if err := j.ActiveSchedule.Validate(); err != nil {
+			outer := fmt.Errorf("Active schedule validation failed: %v", err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -4794,9 +5189,9 @@ func (j *Job) CombinedTaskMeta(groupName, taskName string) map[string]string {
 	return meta
 }
 
-// Stopped returns if a job is stopped.
+// Stopped returns if a job is stopped, or suspended.
 func (j *Job) Stopped() bool {
-	return j == nil || j.Stop
+	return j == nil || j.Stop || j.Suspended
 }
 
 // HasUpdateStrategy returns if any task group in the job has an update strategy
@@ -4825,6 +5220,7 @@ func (j *Job) Stub(summary *JobSummary, fields *JobStubFields) *JobListStub {
 		Periodic:          j.IsPeriodic(),
 		ParameterizedJob:  j.IsParameterized(),
 		Stop:              j.Stop,
+		Suspended:         j.Suspended,
 		Status:            j.Status,
 		StatusDescription: j.StatusDescription,
 		CreateIndex:       j.CreateIndex,
@@ -5018,6 +5414,7 @@ type JobListStub struct {
 	Periodic          bool
 	ParameterizedJob  bool
 	Stop              bool
+	Suspended         bool
 	Status            string
 	StatusDescription string
 	JobSummary        *JobSummary
@@ -5042,6 +5439,15 @@ type JobSummary struct {
 	// Children contains a summary for the children of this job.
 	Children *JobChildrenSummary
 
+	// Health is computed from the allocations and deployments of the job
+	// when the summary is read with JobSummaryRequest.Health set. It is
+	// never stored in state.
+	Health *JobHealth `json:",omitempty"`
+
+	// Warnings are the non-fatal warnings raised when the current version of
+	// the job was registered, and when it was scheduled since.
+	Warnings []*JobWarning
+
 	// Raft Indexes
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -5057,9 +5463,58 @@ func (js *JobSummary) Copy() *JobSummary {
 	}
 	newJobSummary.Summary = newTGSummary
 	newJobSummary.Children = newJobSummary.Children.Copy()
+	newJobSummary.Health = newJobSummary.Health.Copy()
+	newJobSummary.Warnings = slices.Clone(js.Warnings)
 	return newJobSummary
 }
 
+const (
+	// JobWarningTypeRegister is the type of the warnings about the job
+	// specification raised when it's registered, such as deprecated fields.
+	JobWarningTypeRegister = "register"
+
+	// JobWarningTypeAffinity is the type of the warnings raised when
+	// allocations are placed on nodes that don't satisfy an affinity.
+	JobWarningTypeAffinity = "affinity"
+
+	// JobWarningTypeOversubscription is the type of the warnings raised when
+	// allocations are placed with memory oversubscription.
+	JobWarningTypeOversubscription = "oversubscription"
+)
+
+// JobWarning is a non-fatal warning about a job, raised when it's registered
+// or scheduled.
+type JobWarning struct {
+	// Type is what raised the warning, such as JobWarningTypeAffinity.
+	Type string
+
+	// TaskGroup is the task group the warning is about, if any.
+	TaskGroup string
+
+	Message string
+}
+
+// Equal returns true if both warnings are the same.
+func (w *JobWarning) Equal(o *JobWarning) bool {
+	if w == nil || o == nil {
+		return w == o
+	}
+	return *w == *o
+}
+
+// MergeJobWarnings returns the warnings with the new warnings they don't
+// already have appended, and whether any was appended.
+func MergeJobWarnings(warnings []*JobWarning, newWarnings ...*JobWarning) ([]*JobWarning, bool) {
+	added := false
+	for _, w := range newWarnings {
+		if !slices.ContainsFunc(warnings, w.Equal) {
+			warnings = append(warnings, w)
+			added = true
+		}
+	}
+	return warnings, added
+}
+
 // JobChildrenSummary contains the summary of children job statuses
 type JobChildrenSummary struct {
 	Pending int64
@@ -5162,6 +5617,22 @@ type UpdateStrategy struct {
 	// Canary is the number of canaries to deploy when a change to the task
 	// group is detected.
 	Canary int
+
+	// MaxSurge is the number of allocations that can run over the group
+	// count during a rolling update. When set, the allocations are updated
+	// by placing their replacement first, and stopping them once the
+	// replacement is healthy, so the group never runs below its count.
+	MaxSurge int
+
+	// HealthChecks is the names of the checks that gate the health of the
+	// allocations when HealthCheck is "checks". If empty, all the checks of
+	// the group and its tasks are used.
+	HealthChecks []string
+
+	// MinHealthyChecksPercent is the percentage of the gating checks of an
+	// allocation that must be passing for the allocation to be healthy. If
+	// zero, all the checks must be passing.
+	MinHealthyChecksPercent int
 }
 
 func (u *UpdateStrategy) Copy() *UpdateStrategy {
@@ -5171,6 +5642,7 @@ func (u *UpdateStrategy) Copy() *UpdateStrategy {
 
 	c := new(UpdateStrategy)
 	*c = *u
+	c.HealthChecks = slices.Clone(u.HealthChecks)
 	return c
 }
 
@@ -5192,6 +5664,15 @@ func (u *UpdateStrategy) Validate() error {
 	if u.Canary < 0 {
 		_ = multierror.Append(&mErr, fmt.Errorf("Canary count can not be less than zero: %d < 0", u.Canary))
 	}
+	if u.MaxSurge < 0 {
+		_ = multierror.Append(&mErr, fmt.Errorf("Max surge can not be less than zero: %d < 0", u.MaxSurge))
+	}
+	if u.MinHealthyChecksPercent < 0 || u.MinHealthyChecksPercent > 100 {
+		_ = multierror.Append(&mErr, fmt.Errorf("Minimum healthy checks percent must be between 0 and 100: %d", u.MinHealthyChecksPercent))
+	}
+	if (len(u.HealthChecks) > 0 || u.MinHealthyChecksPercent > 0) && u.HealthCheck != UpdateStrategyHealthCheck_Checks {
+		_ = multierror.Append(&mErr, fmt.Errorf("Health checks and minimum healthy checks percent require the %q health check", UpdateStrategyHealthCheck_Checks))
+	}
 	if u.Canary == 0 && u.AutoPromote {
 		_ = multierror.Append(&mErr, fmt.Errorf("Auto Promote requires a Canary count greater than zero"))
 	}
@@ -5319,6 +5800,10 @@ type Namespace struct {
 	VaultConfiguration  *NamespaceVaultConfiguration
 	ConsulConfiguration *NamespaceConsulConfiguration
 
+	// TokenExchangeConfiguration is the policy for exchanging the workload
+	// identities of the namespace for JWTs with other audiences.
+	TokenExchangeConfiguration *NamespaceTokenExchangeConfiguration
+
 	// Meta is the set of metadata key/value pairs that attached to the namespace
 	Meta map[string]string
 
@@ -5403,6 +5888,16 @@ func (n *Namespace) Validate() error {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid consul configuration: %v", e))
 	}
 
+	err = n.TokenExchangeConfiguration.Validate()
+	switch e := err.(type) {
+	case *multierror.Error:
+		for _, tErr := range e.Errors {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid token exchange configuration: %v", tErr))
+		}
+	case error:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid token exchange configuration: %v", e))
+	}
+
 	return mErr.ErrorOrNil()
 }
 
@@ -5456,6 +5951,13 @@ func (n *Namespace) SetHash() []byte {
 		}
 	}
 
+	if n.TokenExchangeConfiguration != nil {
+		for _, aud := range n.TokenExchangeConfiguration.Audiences {
+			_, _ = hash.Write([]byte(aud))
+		}
+		_, _ = hash.Write([]byte(n.TokenExchangeConfiguration.MaxTTL.String()))
+	}
+
 	// sort keys to ensure hash stability when meta is stored later
 	var keys []string
 	for k := range n.Meta {
@@ -5505,6 +6007,7 @@ func (n *Namespace) Copy() *Namespace {
 		nc.Allowed = slices.Clone(n.ConsulConfiguration.Allowed)
 		nc.Denied = slices.Clone(n.ConsulConfiguration.Denied)
 	}
+	nc.TokenExchangeConfiguration = n.TokenExchangeConfiguration.Copy()
 
 	if n.Meta != nil {
 		nc.Meta = make(map[string]string, len(n.Meta))
@@ -5895,6 +6398,46 @@ func (d *TaskLifecycleConfig) Validate() error {
 	return nil
 }
 
+// KillEscalationStep is a step of the escalation used to kill a task. The
+// signal of the step is sent to the task, and the next step starts if the task
+// is still running after the timeout. The task is killed forcefully if it's
+// still running after the timeout of the last step.
+type KillEscalationStep struct {
+	// Signal is the signal sent to the task.
+	Signal string
+
+	// Timeout is the time to wait for the task to exit before moving to the
+	// next step.
+	Timeout time.Duration
+}
+
+func (k *KillEscalationStep) Copy() *KillEscalationStep {
+	if k == nil {
+		return nil
+	}
+	nk := new(KillEscalationStep)
+	*nk = *k
+	return nk
+}
+
+func (k *KillEscalationStep) Equal(o *KillEscalationStep) bool {
+	if k == nil || o == nil {
+		return k == o
+	}
+	return *k == *o
+}
+
+func (k *KillEscalationStep) Validate() error {
+	var mErr multierror.Error
+	if k.Signal == "" {
+		_ = multierror.Append(&mErr, errors.New("Kill escalation signal must not be empty"))
+	}
+	if k.Timeout <= 0 {
+		_ = multierror.Append(&mErr, fmt.Errorf("Kill escalation timeout must be greater than zero: %v", k.Timeout))
+	}
+	return mErr.ErrorOrNil()
+}
+
 var (
 	// These default restart policies needs to be in sync with
 	// Canonicalize in api/tasks.go
@@ -5942,6 +6485,15 @@ const (
 	// attempts are reached within an interval.
 	RestartPolicyModeFail = "fail"
 
+	// RestartPolicyDisconnectedModeRestart restarts failed tasks according to
+	// the restart policy while the client is disconnected from the servers.
+	// It is the default if no disconnected mode is set.
+	RestartPolicyDisconnectedModeRestart = "restart"
+
+	// RestartPolicyDisconnectedModeFail fails tasks that fail while the client
+	// is disconnected from the servers instead of restarting them locally.
+	RestartPolicyDisconnectedModeFail = "fail"
+
 	// RestartPolicyMinInterval is the minimum interval that is accepted for a
 	// restart policy.
 	RestartPolicyMinInterval = 5 * time.Second
@@ -5995,6 +6547,14 @@ type ScalingEvent struct {
 	// EvalID is the ID for an evaluation if one was created as part of a scaling event
 	EvalID *string
 
+	// Actor identifies who performed the scaling action, such as the accessor
+	// ID of the ACL token of the request
+	Actor string
+
+	// Annotation is a free-form note explaining why the scaling action was
+	// performed
+	Annotation string
+
 	// Raft index
 	CreateIndex uint64
 }
@@ -6247,6 +6807,11 @@ type RestartPolicy struct {
 
 	// RenderTemplates is flag to explicitly render all templates on task restart
 	RenderTemplates bool
+
+	// DisconnectedMode controls whether tasks that fail while the client is
+	// disconnected from the servers are restarted locally. An empty value is
+	// equivalent to RestartPolicyDisconnectedModeRestart.
+	DisconnectedMode string
 }
 
 func (r *RestartPolicy) Copy() *RestartPolicy {
@@ -6266,6 +6831,12 @@ func (r *RestartPolicy) Validate() error {
 		_ = multierror.Append(&mErr, fmt.Errorf("Unsupported restart mode: %q", r.Mode))
 	}
 
+	switch r.DisconnectedMode {
+	case "", RestartPolicyDisconnectedModeRestart, RestartPolicyDisconnectedModeFail:
+	default:
+		_ = multierror.Append(&mErr, fmt.Errorf("Unsupported restart disconnected mode: %q", r.DisconnectedMode))
+	}
+
 	// Check for ambiguous/confusing settings
 	if r.Attempts == 0 && r.Mode != RestartPolicyModeFail {
 		_ = multierror.Append(&mErr, fmt.Errorf("Restart policy %q with %d attempts is ambiguous", r.Mode, r.Attempts))
@@ -6562,6 +7133,62 @@ func (m *MigrateStrategy) Validate() error {
 	return mErr.ErrorOrNil()
 }
 
+const (
+	// DisconnectReconcileBestScore keeps the allocation with the best
+	// placement score when a disconnected client reconnects, preferring the
+	// original allocation when it is still running. It is the default if no
+	// reconcile strategy is set.
+	DisconnectReconcileBestScore = "best_score"
+
+	// DisconnectReconcileKeepOriginal always keeps the original allocation
+	// and stops its replacements when a disconnected client reconnects.
+	DisconnectReconcileKeepOriginal = "keep_original"
+
+	// DisconnectReconcileKeepReplacement always keeps the replacement and
+	// stops the original allocation when a disconnected client reconnects.
+	DisconnectReconcileKeepReplacement = "keep_replacement"
+)
+
+// DisconnectStrategy configures how the allocations of a task group are
+// handled when their client reconnects after being disconnected.
+type DisconnectStrategy struct {
+	// Reconcile is the strategy used to pick between an allocation that
+	// reconnects and its replacements. An empty value is equivalent to
+	// DisconnectReconcileBestScore.
+	Reconcile string
+}
+
+func (ds *DisconnectStrategy) Copy() *DisconnectStrategy {
+	if ds == nil {
+		return nil
+	}
+	nds := new(DisconnectStrategy)
+	*nds = *ds
+	return nds
+}
+
+// ReconcileStrategy returns the reconcile strategy to use, defaulting to
+// DisconnectReconcileBestScore.
+func (ds *DisconnectStrategy) ReconcileStrategy() string {
+	if ds == nil || ds.Reconcile == "" {
+		return DisconnectReconcileBestScore
+	}
+	return ds.Reconcile
+}
+
+func (ds *DisconnectStrategy) Validate() error {
+	if ds == nil {
+		return nil
+	}
+
+	switch ds.Reconcile {
+	case "", DisconnectReconcileBestScore, DisconnectReconcileKeepOriginal, DisconnectReconcileKeepReplacement:
+	default:
+		return fmt.Errorf("Unsupported disconnect reconcile strategy: %q", ds.Reconcile)
+	}
+	return nil
+}
+
 // TaskGroup is an atomic unit of placement. Each task group belongs to
 // a job and may contain any number of tasks. A task group support running
 // in many replicas using the same configuration..
@@ -6599,6 +7226,10 @@ type TaskGroup struct {
 	// task group. This is opaque to Nomad.
 	Meta map[string]string
 
+	// Env is the environment variables set in all the tasks of the group,
+	// unless overridden by the task.
+	Env map[string]string
+
 	// ReschedulePolicy is used to configure how the scheduler should
 	// retry failed allocations.
 	ReschedulePolicy *ReschedulePolicy
@@ -6635,6 +7266,14 @@ type TaskGroup struct {
 	// MaxClientDisconnect, if set, configures the client to allow placed
 	// allocations for tasks in this group to attempt to resume running without a restart.
 	MaxClientDisconnect *time.Duration
+
+	// Disconnect configures how allocations of this group are reconciled
+	// when their client reconnects.
+	Disconnect *DisconnectStrategy
+
+	// KillEscalation is the default kill escalation of the tasks of the
+	// group that don't set one.
+	KillEscalation []*KillEscalationStep
 }
 
 func (tg *TaskGroup) Copy() *TaskGroup {
@@ -6652,6 +7291,8 @@ func (tg *TaskGroup) Copy() *TaskGroup {
 	ntg.Volumes = CopyMapVolumeRequest(ntg.Volumes)
 	ntg.Scaling = ntg.Scaling.Copy()
 	ntg.Consul = ntg.Consul.Copy()
+	ntg.Disconnect = ntg.Disconnect.Copy()
+	ntg.KillEscalation = helper.CopySlice(ntg.KillEscalation)
 
 	// Copy the network objects
 	if tg.Networks != nil {
@@ -6671,6 +7312,7 @@ func (tg *TaskGroup) Copy() *TaskGroup {
 	}
 
 	ntg.Meta = maps.Clone(ntg.Meta)
+	ntg.Env = maps.Clone(ntg.Env)
 
 	if tg.EphemeralDisk != nil {
 		ntg.EphemeralDisk = tg.EphemeralDisk.Copy()
@@ -6801,6 +7443,10 @@ func (tg *TaskGroup) Validate(j *Job) error {
 		mErr.Errors = append(mErr.Errors, errors.New("max_client_disconnect cannot be negative"))
 	}
 
+	if err := tg.Disconnect.Validate(); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+
 	for idx, constr := range tg.Constraints {
 		if err := constr.Validate(); err != nil {
 			outer := fmt.Errorf("Constraint %d validation failed: %s", idx+1, err)
@@ -6873,6 +7519,16 @@ func (tg *TaskGroup) Validate(j *Job) error {
 		if err := u.Validate(); err != nil {
 			mErr.Errors = append(mErr.Errors, err)
 		}
+		if err := tg.validateUpdateHealthChecks(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+
+	for idx, step := range tg.KillEscalation {
+		if err := step.Validate(); err != nil {
+			outer := fmt.Errorf("Kill escalation step %d validation failed: %s", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
 	}
 
 	// Validate the migration strategy
@@ -6946,6 +7602,11 @@ func (tg *TaskGroup) Validate(j *Job) error {
 		mErr.Errors = append(mErr.Errors, outer)
 	}
 
+	// Validate the references to the meta in interpolated fields
+	if err := tg.validateMetaRefs(j); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+
 	// Validate the tasks
 	for _, task := range tg.Tasks {
 		if err := task.Validate(j.Type, tg); err != nil {
@@ -7011,6 +7672,10 @@ func (tg *TaskGroup) validateNetworks() error {
 				mErr.Errors = append(mErr.Errors, errors.New("Hostname is not a valid DNS name"))
 			}
 		}
+
+		if err := net.DNS.Validate(net.Mode); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
 	}
 
 	// Check for duplicate tasks or port labels, and no duplicated static ports
@@ -7202,6 +7867,35 @@ func (tg *TaskGroup) validateScriptChecksInGroupServices() error {
 	return mErr.ErrorOrNil()
 }
 
+// validateUpdateHealthChecks ensures that the checks gating the health of the
+// allocations in the update block exist in the group or its tasks.
+func (tg *TaskGroup) validateUpdateHealthChecks() error {
+	if tg.Update == nil || len(tg.Update.HealthChecks) == 0 {
+		return nil
+	}
+
+	checks := make(map[string]struct{})
+	addChecks := func(services []*Service) {
+		for _, service := range services {
+			for _, check := range service.Checks {
+				checks[check.Name] = struct{}{}
+			}
+		}
+	}
+	addChecks(tg.Services)
+	for _, task := range tg.Tasks {
+		addChecks(task.Services)
+	}
+
+	var mErr multierror.Error
+	for _, name := range tg.Update.HealthChecks {
+		if _, ok := checks[name]; !ok {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Update health check %q not found in task group %q", name, tg.Name))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
 // validateScalingPolicy ensures that the scaling policy has consistent
 // min and max, not in conflict with the task group count
 func (tg *TaskGroup) validateScalingPolicy(j *Job) error {
@@ -7530,6 +8224,10 @@ type Task struct {
 	// specification and defaults to SIGINT
 	KillSignal string
 
+	// KillEscalation is the sequence of signals sent to kill the task, each
+	// with its own timeout. If set, it replaces KillSignal and KillTimeout.
+	KillEscalation []*KillEscalationStep
+
 	// Used internally to manage tasks according to their TaskKind. Initial use case
 	// is for Consul Connect
 	Kind TaskKind
@@ -7642,6 +8340,7 @@ func (t *Task) Copy() *Task {
 	nt.Identity = nt.Identity.Copy()
 	nt.Identities = helper.CopySlice(nt.Identities)
 	nt.Actions = helper.CopySlice(nt.Actions)
+	nt.KillEscalation = helper.CopySlice(nt.KillEscalation)
 
 	if t.Artifacts != nil {
 		artifacts := make([]*TaskArtifact, 0, len(t.Artifacts))
@@ -7697,6 +8396,10 @@ func (t *Task) Canonicalize(job *Job, tg *TaskGroup) {
 		t.RestartPolicy = tg.RestartPolicy
 	}
 
+	if len(t.KillEscalation) == 0 {
+		t.KillEscalation = helper.CopySlice(tg.KillEscalation)
+	}
+
 	// Set the default timeout if it is not specified.
 	if t.KillTimeout == 0 {
 		t.KillTimeout = DefaultKillTimeout
@@ -7775,6 +8478,12 @@ func (t *Task) Validate(jobType string, tg *TaskGroup) error {
 	if t.ShutdownDelay < 0 {
 		mErr.Errors = append(mErr.Errors, errors.New("ShutdownDelay must be a positive value"))
 	}
+	for idx, step := range t.KillEscalation {
+		if err := step.Validate(); err != nil {
+			outer := fmt.Errorf("Kill escalation step %d validation failed: %s", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
 
 	// Validate the resources.
 	if t.Resources == nil {
@@ -7929,6 +8638,39 @@ func (t *Task) Validate(jobType string, tg *TaskGroup) error {
 	}
 
 	// Validate Identity/Identities
+	projections := map[string]string{}
+	for _, wid := range append([]*WorkloadIdentity{t.Identity}, t.Identities...) {
+		if wid == nil || wid.Projection == nil {
+			continue
+		}
+		if other, ok := projections[wid.Projection.Destination]; ok {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Identities %q and %q are projected into the same destination %q",
+				other, wid.Name, wid.Projection.Destination))
+		}
+		projections[wid.Projection.Destination] = wid.Name
+	}
+	if t.Identity != nil && t.Identity.Projection != nil {
+		if err := t.Identity.Projection.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Identity %q is invalid: %w", t.Identity.Name, err))
+		}
+	}
+	if t.Identity != nil && t.Identity.SPIFFE != nil {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Identity %q is invalid: spiffe for default identity not supported", t.Identity.Name))
+	}
+	if t.Identity != nil && t.Identity.Cloud != nil {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Identity %q is invalid: cloud for default identity not supported", t.Identity.Name))
+	}
+	spiffeIdentity := ""
+	for _, wid := range t.Identities {
+		// The SVIDs are written to the same files of the secrets dir, so only
+		// one identity of the task can set spiffe.
+		if wid.SPIFFE != nil {
+			if spiffeIdentity != "" {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("Identities %q and %q both set spiffe", spiffeIdentity, wid.Name))
+			}
+			spiffeIdentity = wid.Name
+		}
+	}
 	for _, wid := range t.Identities {
 		// Task.Canonicalize should move the default identity out of the Identities
 		// slice, so if one is found that means it is a duplicate.
@@ -8694,11 +9436,226 @@ type TaskState struct {
 	// Series of task events that transition the state of the task.
 	Events []*TaskEvent
 
+	// HookTimings records when the prestart and poststart hooks of the task
+	// last ran. It is used to compute the allocation timeline.
+	HookTimings []*TaskHookTiming
+
+	// Result classifies how the last run of the task ended. It's nil until a
+	// run of the task ends, and is kept when the task restarts.
+	Result *TaskResult
+
 	// Experimental -  TaskHandle is based on drivers.TaskHandle and used
 	// by remote task drivers to migrate task handles between allocations.
 	TaskHandle *TaskHandle
 }
 
+const (
+	// TaskHookPhasePrestart and TaskHookPhasePoststart are the lifecycle
+	// phases of task hooks recorded in TaskHookTiming.
+	TaskHookPhasePrestart  = "prestart"
+	TaskHookPhasePoststart = "poststart"
+)
+
+// TaskHookTiming records the last run of a task hook.
+type TaskHookTiming struct {
+	// Name is the name of the hook.
+	Name string
+
+	// Phase is the lifecycle phase the hook ran in.
+	Phase string
+
+	// StartedAt and FinishedAt are the times the hook started and finished
+	// running.
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+func (t *TaskHookTiming) Copy() *TaskHookTiming {
+	if t == nil {
+		return nil
+	}
+	nt := *t
+	return &nt
+}
+
+func (t *TaskHookTiming) Equal(o *TaskHookTiming) bool {
+	if t == nil || o == nil {
+		return t == o
+	}
+	return t.Name == o.Name &&
+		t.Phase == o.Phase &&
+		t.StartedAt.Equal(o.StartedAt) &&
+		t.FinishedAt.Equal(o.FinishedAt)
+}
+
+// SetHookTiming records the timing of a hook run, replacing any previous run
+// of the same hook in the same phase.
+func (ts *TaskState) SetHookTiming(timing *TaskHookTiming) {
+	for i, t := range ts.HookTimings {
+		if t.Name == timing.Name && t.Phase == timing.Phase {
+			ts.HookTimings[i] = timing
+			return
+		}
+	}
+	ts.HookTimings = append(ts.HookTimings, timing)
+}
+
+const (
+	// TaskResultSuccess is the result of tasks that exited with a zero exit
+	// code.
+	TaskResultSuccess = "success"
+
+	// TaskResultExitFailure is the result of tasks that exited with a
+	// non-zero exit code.
+	TaskResultExitFailure = "exit_failure"
+
+	// TaskResultSignaled is the result of tasks terminated by a signal they
+	// didn't receive from Nomad.
+	TaskResultSignaled = "signaled"
+
+	// TaskResultOOMKilled is the result of tasks killed for exceeding their
+	// memory limit.
+	TaskResultOOMKilled = "oom_killed"
+
+	// TaskResultKilled is the result of tasks killed by Nomad, for example
+	// when their allocation is stopped.
+	TaskResultKilled = "killed"
+
+	// TaskResultDriverError is the result of tasks the driver failed to
+	// start or wait on. The error is classified by DriverErrorClass.
+	TaskResultDriverError = "driver_error"
+
+	// TaskResultHookFailure is the result of tasks that didn't start because
+	// a hook failed. The hook is identified by HookName and HookPhase.
+	TaskResultHookFailure = "hook_failure"
+)
+
+const (
+	// TaskDriverErrorConfig classifies errors building the driver
+	// configuration of the task, such as invalid config blocks.
+	TaskDriverErrorConfig = "config"
+
+	// TaskDriverErrorPluginExited classifies errors of driver plugins that
+	// exited unexpectedly and couldn't be restarted.
+	TaskDriverErrorPluginExited = "plugin_exited"
+
+	// TaskDriverErrorStart classifies errors returned by the driver when
+	// starting the task.
+	TaskDriverErrorStart = "start"
+
+	// TaskDriverErrorWait classifies errors returned by the driver while
+	// waiting for the task to exit.
+	TaskDriverErrorWait = "wait"
+)
+
+const (
+	// AllocHookPhasePrerun is the phase of the alloc runner hooks recorded
+	// in the TaskResult of the tasks of an allocation that failed to start.
+	AllocHookPhasePrerun = "prerun"
+)
+
+// TaskResult is a machine-readable classification of how a run of a task
+// ended, meant to be used by automation instead of the messages of task
+// events.
+type TaskResult struct {
+	// Class is the classification of the result, and is one of the
+	// TaskResult constants.
+	Class string
+
+	// ExitCode, Signal, and OOMKilled are the exit status of the task, when
+	// it ran.
+	ExitCode  int
+	Signal    int
+	OOMKilled bool
+
+	// DriverErrorClass classifies the errors of the TaskResultDriverError
+	// results, and is one of the TaskDriverError constants.
+	DriverErrorClass string
+
+	// HookName and HookPhase are the name and lifecycle phase of the hook
+	// that failed, for TaskResultHookFailure results. The phase is
+	// AllocHookPhasePrerun for alloc runner hooks.
+	HookName  string
+	HookPhase string
+
+	// Error is the message of the error of driver errors and hook failures.
+	Error string
+
+	// Time is the time the run ended.
+	Time time.Time
+}
+
+// NewTaskExitResult returns the result of a task run that exited with the
+// given status. Tasks killed by Nomad are classified as killed regardless of
+// their exit status, unless they ran out of memory.
+func NewTaskExitResult(exitCode, signal int, oomKilled, killed bool) *TaskResult {
+	r := &TaskResult{
+		ExitCode:  exitCode,
+		Signal:    signal,
+		OOMKilled: oomKilled,
+		Time:      time.Now(),
+	}
+	switch {
+	case oomKilled:
+		r.Class = TaskResultOOMKilled
+	case killed:
+		r.Class = TaskResultKilled
+	case signal != 0:
+		r.Class = TaskResultSignaled
+	case exitCode != 0:
+		r.Class = TaskResultExitFailure
+	default:
+		r.Class = TaskResultSuccess
+	}
+	return r
+}
+
+// NewTaskDriverErrorResult returns the result of a task run that failed
+// because of a driver error.
+func NewTaskDriverErrorResult(class string, err error) *TaskResult {
+	return &TaskResult{
+		Class:            TaskResultDriverError,
+		DriverErrorClass: class,
+		Error:            err.Error(),
+		Time:             time.Now(),
+	}
+}
+
+// NewTaskHookFailureResult returns the result of a task run that failed
+// because of a hook.
+func NewTaskHookFailureResult(name, phase string, err error) *TaskResult {
+	return &TaskResult{
+		Class:     TaskResultHookFailure,
+		HookName:  name,
+		HookPhase: phase,
+		Error:     err.Error(),
+		Time:      time.Now(),
+	}
+}
+
+func (r *TaskResult) Copy() *TaskResult {
+	if r == nil {
+		return nil
+	}
+	nr := *r
+	return &nr
+}
+
+func (r *TaskResult) Equal(o *TaskResult) bool {
+	if r == nil || o == nil {
+		return r == o
+	}
+	return r.Class == o.Class &&
+		r.ExitCode == o.ExitCode &&
+		r.Signal == o.Signal &&
+		r.OOMKilled == o.OOMKilled &&
+		r.DriverErrorClass == o.DriverErrorClass &&
+		r.HookName == o.HookName &&
+		r.HookPhase == o.HookPhase &&
+		r.Error == o.Error &&
+		r.Time.Equal(o.Time)
+}
+
 // NewTaskState returns a TaskState initialized in the Pending state.
 func NewTaskState() *TaskState {
 	return &TaskState{
@@ -8728,6 +9685,8 @@ func (ts *TaskState) Copy() *TaskState {
 		}
 	}
 
+	newTS.HookTimings = helper.CopySlice(ts.HookTimings)
+	newTS.Result = ts.Result.Copy()
 	newTS.TaskHandle = ts.TaskHandle.Copy()
 	return newTS
 }
@@ -8763,6 +9722,14 @@ func (ts *TaskState) Equal(o *TaskState) bool {
 	}) {
 		return false
 	}
+	if !slices.EqualFunc(ts.HookTimings, o.HookTimings, func(ts, o *TaskHookTiming) bool {
+		return ts.Equal(o)
+	}) {
+		return false
+	}
+	if !ts.Result.Equal(o.Result) {
+		return false
+	}
 	if !ts.TaskHandle.Equal(o.TaskHandle) {
 		return false
 	}
@@ -8833,6 +9800,14 @@ const (
 	// exceeded the requested disk resources.
 	TaskDiskExceeded = "Disk Resources Exceeded"
 
+	// TaskDiskQuotaWarning indicates that the allocation of a task is
+	// approaching its ephemeral disk quota.
+	TaskDiskQuotaWarning = "Disk Quota Warning"
+
+	// TaskMigratingData indicates the progress of the migration of the
+	// ephemeral disk data of the previous allocation.
+	TaskMigratingData = "Migrating Data"
+
 	// TaskSiblingFailed indicates that a sibling task in the task group has
 	// failed.
 	TaskSiblingFailed = "Sibling Task Failed"
@@ -8875,6 +9850,24 @@ const (
 	// TaskSkippingShutdownDelay indicates that the task operation was
 	// configured to ignore the shutdown delay value set for the tas.
 	TaskSkippingShutdownDelay = "Skipping shutdown delay"
+
+	// TaskHookOverridden indicates that an operator skipped or failed a
+	// running alloc runner or task runner hook.
+	TaskHookOverridden = "Hook overridden"
+
+	// TaskHookTimedOut indicates that an alloc runner or task runner hook
+	// didn't complete before the hook timeout of the client.
+	TaskHookTimedOut = "Hook timed out"
+
+	// TaskDrainingServices indicates that the services of the task were
+	// deregistered, and the task is waiting for the service drain of the
+	// stopped job before being killed.
+	TaskDrainingServices = "Draining services"
+
+	// TaskIdentitiesExpired indicates that the workload identities of the
+	// task expired past the identity grace period of the client without being
+	// renewed.
+	TaskIdentitiesExpired = "Identities expired"
 )
 
 // TaskEvent is an event that effects the state of a task and contains meta-data
@@ -9041,6 +10034,16 @@ func (e *TaskEvent) PopulateEventDisplayMessage() {
 		if e.Message != "" {
 			parts = append(parts, fmt.Sprintf("Exit Message: %q", e.Message))
 		}
+
+		if rss, err := strconv.ParseUint(e.Details["peak_memory_rss"], 10, 64); err == nil {
+			parts = append(parts, fmt.Sprintf("Peak RSS: %d MiB", rss/1024/1024))
+		}
+		if throttled, ok := e.Details["cpu_throttled_time"]; ok {
+			parts = append(parts, fmt.Sprintf("CPU Throttled: %s", throttled))
+		}
+		if fds, ok := e.Details["open_fds"]; ok {
+			parts = append(parts, fmt.Sprintf("Open FDs: %s", fds))
+		}
 		desc = strings.Join(parts, ", ")
 	case TaskRestarting:
 		in := fmt.Sprintf("Task restarting in %v", time.Duration(e.StartDelay))
@@ -9273,6 +10276,18 @@ func (e *TaskEvent) SetDiskLimit(limit int64) *TaskEvent {
 	return e
 }
 
+func (e *TaskEvent) SetMigrationStatus(s *AllocMigrationStatus) *TaskEvent {
+	e.Details["previous_alloc_id"] = s.PreviousAllocID
+	e.Details["bytes_migrated"] = strconv.FormatInt(s.BytesMigrated, 10)
+	if s.BytesTotal > 0 {
+		e.Details["bytes_total"] = strconv.FormatInt(s.BytesTotal, 10)
+	}
+	if s.ETA > 0 {
+		e.Details["eta"] = s.ETA.String()
+	}
+	return e
+}
+
 func (e *TaskEvent) SetFailedSibling(sibling string) *TaskEvent {
 	e.FailedSibling = sibling
 	e.Details["failed_sibling"] = sibling
@@ -9298,6 +10313,25 @@ func (e *TaskEvent) SetOOMKilled(oom bool) *TaskEvent {
 	return e
 }
 
+// SetResourceUsage sets the resource usage of the task when it exited: its
+// peak RSS in bytes, the time it was throttled by its CPU quota, and the
+// number of file descriptors it had open, which is omitted if negative.
+func (e *TaskEvent) SetResourceUsage(peakRSS uint64, throttledTime time.Duration, openFDs int) *TaskEvent {
+	e.Details["peak_memory_rss"] = strconv.FormatUint(peakRSS, 10)
+	e.Details["cpu_throttled_time"] = throttledTime.String()
+	if openFDs >= 0 {
+		e.Details["open_fds"] = strconv.Itoa(openFDs)
+	}
+	return e
+}
+
+func (e *TaskEvent) SetHookTimeout(name, phase string, timeout time.Duration) *TaskEvent {
+	e.Details["hook_name"] = name
+	e.Details["hook_phase"] = phase
+	e.Details["hook_timeout"] = timeout.String()
+	return e
+}
+
 // TaskArtifact is an artifact to download before running the task.
 type TaskArtifact struct {
 	// GetterSource is the source to download an artifact using go-getter
@@ -9484,6 +10518,7 @@ const (
 	ConstraintSetContains       = "set_contains"
 	ConstraintSetContainsAll    = "set_contains_all"
 	ConstraintSetContainsAny    = "set_contains_any"
+	ConstraintCIDRContains      = "cidr_contains"
 	ConstraintAttributeIsSet    = "is_set"
 	ConstraintAttributeIsNotSet = "is_not_set"
 )
@@ -9548,6 +10583,10 @@ func (c *Constraint) Validate() error {
 		if _, err := semver.NewConstraint(c.RTarget); err != nil {
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("Semver constraint is invalid: %v", err))
 		}
+	case ConstraintCIDRContains:
+		if err := validateCIDRList(c.RTarget); err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("CIDR constraint is invalid: %v", err))
+		}
 	case ConstraintDistinctProperty:
 		// If a count is set, make sure it is convertible to a uint64
 		if c.RTarget != "" {
@@ -9578,6 +10617,20 @@ func (c *Constraint) Validate() error {
 	return mErr.ErrorOrNil()
 }
 
+// validateCIDRList validates the comma separated list of CIDR blocks of a
+// cidr_contains constraint or affinity.
+func validateCIDRList(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return errors.New("at least one CIDR block is required")
+	}
+	for _, cidr := range strings.Split(s, ",") {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type Constraints []*Constraint
 
 // Equal compares Constraints as a set
@@ -9669,6 +10722,10 @@ func (a *Affinity) Validate() error {
 		if _, err := semver.NewConstraint(a.RTarget); err != nil {
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("Semver affinity is invalid: %v", err))
 		}
+	case ConstraintCIDRContains:
+		if err := validateCIDRList(a.RTarget); err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("CIDR affinity is invalid: %v", err))
+		}
 	case "=", "==", "is", "!=", "not", "<", "<=", ">", ">=":
 		if a.RTarget == "" {
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("Operator %q requires an RTarget", a.Operand))
@@ -10438,6 +11495,11 @@ type DesiredTransition struct {
 	// task shutdown_delay configuration and ignore the delay for any
 	// allocations stopped as a result of this Deregister call.
 	NoShutdownDelay *bool
+
+	// ServiceDrain, if set, is the duration to wait after deregistering the
+	// services of the allocation before killing its tasks, for allocations
+	// stopped as a result of a Deregister call.
+	ServiceDrain *time.Duration
 }
 
 // Merge merges the two desired transitions, preferring the values from the
@@ -10458,6 +11520,10 @@ func (d *DesiredTransition) Merge(o *DesiredTransition) {
 	if o.NoShutdownDelay != nil {
 		d.NoShutdownDelay = o.NoShutdownDelay
 	}
+
+	if o.ServiceDrain != nil {
+		d.ServiceDrain = o.ServiceDrain
+	}
 }
 
 // ShouldMigrate returns whether the transition object dictates a migration.
@@ -10489,6 +11555,16 @@ func (d *DesiredTransition) ShouldIgnoreShutdownDelay() bool {
 	return d.NoShutdownDelay != nil && *d.NoShutdownDelay
 }
 
+// ServiceDrainDuration returns the duration the transition object dictates
+// to wait after deregistering the services of the allocation before killing
+// its tasks.
+func (d *DesiredTransition) ServiceDrainDuration() time.Duration {
+	if d == nil || d.ServiceDrain == nil {
+		return 0
+	}
+	return *d.ServiceDrain
+}
+
 const (
 	AllocDesiredStatusRun   = "run"   // Allocation should run
 	AllocDesiredStatusStop  = "stop"  // Allocation should stop
@@ -10603,6 +11679,10 @@ type Allocation struct {
 	// NetworkStatus captures networking details of an allocation known at runtime
 	NetworkStatus *AllocNetworkStatus
 
+	// MigrationStatus captures the progress of the migration of the ephemeral
+	// disk data of the previous allocation, if any.
+	MigrationStatus *AllocMigrationStatus
+
 	// FollowupEvalID captures a follow up evaluation created to handle a failed allocation
 	// that can be rescheduled in the future
 	FollowupEvalID string
@@ -10787,6 +11867,7 @@ func (a *Allocation) copyImpl(job bool) *Allocation {
 
 	na.Metrics = na.Metrics.Copy()
 	na.DeploymentStatus = na.DeploymentStatus.Copy()
+	na.MigrationStatus = na.MigrationStatus.Copy()
 
 	if a.TaskStates != nil {
 		ts := make(map[string]*TaskState, len(na.TaskStates))
@@ -11281,9 +12362,60 @@ type IdentityClaims struct {
 	TaskName     string `json:"nomad_task,omitempty"`
 	ServiceName  string `json:"nomad_service,omitempty"`
 
+	// ExtraClaims are the extra claims of the workload identity sourced from
+	// meta, which are serialized as top level claims.
+	ExtraClaims map[string]string `json:"-"`
+
 	jwt.Claims
 }
 
+// identityClaimsJSON is IdentityClaims without its JSON methods.
+type identityClaimsJSON IdentityClaims
+
+// MarshalJSON marshals the claims along with their extra claims.
+func (claims *IdentityClaims) MarshalJSON() ([]byte, error) {
+	buf, err := json.Marshal((*identityClaimsJSON)(claims))
+	if err != nil || len(claims.ExtraClaims) == 0 {
+		return buf, err
+	}
+
+	var all map[string]any
+	if err := json.Unmarshal(buf, &all); err != nil {
+		return nil, err
+	}
+	for claim, value := range claims.ExtraClaims {
+		if _, ok := all[claim]; !ok {
+			all[claim] = value
+		}
+	}
+	return json.Marshal(all)
+}
+
+// UnmarshalJSON unmarshals the claims, keeping the string claims not set by
+// Nomad as extra claims.
+func (claims *IdentityClaims) UnmarshalJSON(buf []byte) error {
+	if err := json.Unmarshal(buf, (*identityClaimsJSON)(claims)); err != nil {
+		return err
+	}
+
+	var all map[string]any
+	if err := json.Unmarshal(buf, &all); err != nil {
+		return err
+	}
+	claims.ExtraClaims = nil
+	for claim, value := range all {
+		s, ok := value.(string)
+		if !ok || slices.Contains(reservedIdentityClaims, claim) || strings.HasPrefix(claim, "nomad_") {
+			continue
+		}
+		if claims.ExtraClaims == nil {
+			claims.ExtraClaims = make(map[string]string)
+		}
+		claims.ExtraClaims[claim] = s
+	}
+	return nil
+}
+
 // NewIdentityClaims returns new workload identity claims. Since it may be
 // called with a denormalized Allocation, the Job must be passed in distinctly.
 //
@@ -11326,7 +12458,8 @@ func NewIdentityClaims(job *Job, alloc *Allocation, wihandle *WIHandle, wid *Wor
 		return nil
 	}
 
-	claims.Audience = slices.Clone(wid.Audience)
+	claims.Audience = wid.InterpolateAudience(job, alloc, wihandle)
+	claims.ExtraClaims = wid.InterpolateExtraClaims(job, alloc.TaskGroup, wihandle)
 	claims.setSubject(job, alloc.TaskGroup, wihandle.WorkloadIdentifier, wid.Name)
 	claims.setExp(now, wid)
 
@@ -11357,6 +12490,34 @@ func (claims *IdentityClaims) setExp(now time.Time, wid *WorkloadIdentity) {
 	claims.Expiry = jwt.NewNumericDate(now.Add(wid.TTL))
 }
 
+// ExchangedClaims returns the claims of a JWT exchanged for these claims with
+// a single audience. The JWT expires after the TTL, or when these claims
+// expire if sooner.
+func (claims *IdentityClaims) ExchangedClaims(audience string, now time.Time, ttl time.Duration) *IdentityClaims {
+	exp := now.Add(ttl)
+	if claims.Expiry != nil && claims.Expiry.Time().Before(exp) {
+		exp = claims.Expiry.Time()
+	}
+
+	jwtnow := jwt.NewNumericDate(now.UTC())
+	return &IdentityClaims{
+		Namespace:    claims.Namespace,
+		JobID:        claims.JobID,
+		AllocationID: claims.AllocationID,
+		TaskName:     claims.TaskName,
+		ServiceName:  claims.ServiceName,
+		ExtraClaims:  maps.Clone(claims.ExtraClaims),
+		Claims: jwt.Claims{
+			ID:        uuid.Generate(),
+			Subject:   claims.Subject,
+			Audience:  jwt.Audience{audience},
+			NotBefore: jwtnow,
+			IssuedAt:  jwtnow,
+			Expiry:    jwt.NewNumericDate(exp.UTC()),
+		},
+	}
+}
+
 // AllocationDiff is another named type for Allocation (to use the same fields),
 // which is used to represent the delta for an Allocation. If you need a method
 // defined on the al
@@ -11496,6 +12657,51 @@ type AllocMetric struct {
 	// This is to prevent creating many failed allocations for a
 	// single task group.
 	CoalescedFailures int
+
+	// trace records the nodes filtered and exhausted when evaluation
+	// tracing is enabled. It is not serialized.
+	trace *allocMetricTrace
+}
+
+// allocMetricTrace records the individual nodes behind the counts of an
+// AllocMetric, up to a maximum number of nodes.
+type allocMetricTrace struct {
+	maxNodes  int
+	filtered  []*EvalTraceNode
+	exhausted []*EvalTraceNode
+	truncated bool
+}
+
+func (t *allocMetricTrace) record(nodes []*EvalTraceNode, node *Node, reason string) []*EvalTraceNode {
+	if node == nil {
+		return nodes
+	}
+	if len(t.filtered)+len(t.exhausted) >= t.maxNodes {
+		t.truncated = true
+		return nodes
+	}
+	return append(nodes, &EvalTraceNode{NodeID: node.ID, Reason: reason})
+}
+
+// EnableTrace enables recording the nodes filtered and exhausted, up to
+// maxNodes nodes.
+func (a *AllocMetric) EnableTrace(maxNodes int) {
+	a.trace = &allocMetricTrace{maxNodes: maxNodes}
+}
+
+// TracePlacement returns the trace of the placement the metric was collected
+// for, or nil if tracing is not enabled.
+func (a *AllocMetric) TracePlacement() *EvalTracePlacement {
+	if a == nil || a.trace == nil {
+		return nil
+	}
+	return &EvalTracePlacement{
+		NodesEvaluated: a.NodesEvaluated,
+		Filtered:       a.trace.filtered,
+		Exhausted:      a.trace.exhausted,
+		Truncated:      a.trace.truncated,
+		Scores:         CopySliceNodeScoreMeta(a.ScoreMetaData),
+	}
 }
 
 func (a *AllocMetric) Copy() *AllocMetric {
@@ -11521,6 +12727,9 @@ func (a *AllocMetric) EvaluateNode() {
 
 func (a *AllocMetric) FilterNode(node *Node, constraint string) {
 	a.NodesFiltered += 1
+	if a.trace != nil {
+		a.trace.filtered = a.trace.record(a.trace.filtered, node, constraint)
+	}
 	if node != nil && node.NodeClass != "" {
 		if a.ClassFiltered == nil {
 			a.ClassFiltered = make(map[string]int)
@@ -11537,6 +12746,9 @@ func (a *AllocMetric) FilterNode(node *Node, constraint string) {
 
 func (a *AllocMetric) ExhaustedNode(node *Node, dimension string) {
 	a.NodesExhausted += 1
+	if a.trace != nil {
+		a.trace.exhausted = a.trace.record(a.trace.exhausted, node, dimension)
+	}
 	if node != nil && node.NodeClass != "" {
 		if a.ClassExhausted == nil {
 			a.ClassExhausted = make(map[string]int)
@@ -11677,6 +12889,14 @@ type AllocNetworkStatus struct {
 	InterfaceName string
 	Address       string
 	DNS           *DNSConfig
+
+	// NamespacePath is the path of the network namespace of the allocation
+	// on the client.
+	NamespacePath string
+
+	// BridgeName is the name of the host bridge the network namespace of the
+	// allocation is attached to, in bridge network mode.
+	BridgeName string
 }
 
 func (a *AllocNetworkStatus) Copy() *AllocNetworkStatus {
@@ -11687,6 +12907,8 @@ func (a *AllocNetworkStatus) Copy() *AllocNetworkStatus {
 		InterfaceName: a.InterfaceName,
 		Address:       a.Address,
 		DNS:           a.DNS.Copy(),
+		NamespacePath: a.NamespacePath,
+		BridgeName:    a.BridgeName,
 	}
 }
 
@@ -11708,6 +12930,10 @@ func (a *AllocNetworkStatus) Equal(o *AllocNetworkStatus) bool {
 		return false
 	case !a.DNS.Equal(o.DNS):
 		return false
+	case a.NamespacePath != o.NamespacePath:
+		return false
+	case a.BridgeName != o.BridgeName:
+		return false
 	}
 	return true
 }
@@ -11716,7 +12942,7 @@ func (a *AllocNetworkStatus) IsZero() bool {
 	if a == nil {
 		return true
 	}
-	if a.InterfaceName != "" || a.Address != "" {
+	if a.InterfaceName != "" || a.Address != "" || a.NamespacePath != "" || a.BridgeName != "" {
 		return false
 	}
 	if !a.DNS.IsZero() {
@@ -11725,6 +12951,59 @@ func (a *AllocNetworkStatus) IsZero() bool {
 	return true
 }
 
+// AllocMigrationStatus captures the progress of the migration of the ephemeral
+// disk data of a previous allocation by the client.
+type AllocMigrationStatus struct {
+	// PreviousAllocID is the allocation the data is migrated from.
+	PreviousAllocID string
+
+	// BytesMigrated is the number of bytes of file contents received so far.
+	BytesMigrated int64
+
+	// BytesTotal is the size of the file contents to migrate, or 0 if the
+	// node of the previous allocation didn't report it.
+	BytesTotal int64
+
+	// StartedAt is the time the migration started at, and UpdatedAt the time
+	// the status was last updated at.
+	StartedAt time.Time
+	UpdatedAt time.Time
+
+	// ETA is the estimated time left until the migration completes, or 0 if
+	// it can't be estimated yet.
+	ETA time.Duration
+
+	// Complete is true once the data has been migrated, and Error is set if
+	// the migration failed.
+	Complete bool
+	Error    string
+}
+
+func (a *AllocMigrationStatus) Copy() *AllocMigrationStatus {
+	if a == nil {
+		return nil
+	}
+	na := new(AllocMigrationStatus)
+	*na = *a
+	return na
+}
+
+func (a *AllocMigrationStatus) Equal(o *AllocMigrationStatus) bool {
+	if a == nil || o == nil {
+		return a == o
+	}
+	return *a == *o
+}
+
+// Percent returns the percentage of the data migrated, or -1 if the size of
+// the data is unknown.
+func (a *AllocMigrationStatus) Percent() float64 {
+	if a.BytesTotal <= 0 {
+		return -1
+	}
+	return min(float64(a.BytesMigrated)/float64(a.BytesTotal)*100, 100)
+}
+
 // NetworkStatus is an interface satisfied by alloc runner, for acquiring the
 // network status of an allocation.
 type NetworkStatus interface {
@@ -11843,6 +13122,8 @@ const (
 	EvalTriggerQueuedAllocs         = "queued-allocs"
 	EvalTriggerPreemption           = "preemption"
 	EvalTriggerScaling              = "job-scaling"
+	EvalTriggerJobSuspend           = "job-suspend"
+	EvalTriggerJobResume            = "job-resume"
 	EvalTriggerMaxDisconnectTimeout = "max-disconnect-timeout"
 	EvalTriggerReconnect            = "reconnect"
 )
@@ -11993,6 +13274,11 @@ type Evaluation struct {
 	// to determine the cause.
 	FailedTGAllocs map[string]*AllocMetric
 
+	// Warnings are the non-fatal warnings raised by the scheduler while
+	// placing the allocations, which are added to the job summary once the
+	// evaluation completes.
+	Warnings []*JobWarning
+
 	// ClassEligibility tracks computed node classes that have been explicitly
 	// marked as eligible or ineligible.
 	ClassEligibility map[string]bool
@@ -12164,6 +13450,8 @@ func (e *Evaluation) Copy() *Evaluation {
 		ne.FailedTGAllocs = failedTGs
 	}
 
+	ne.Warnings = slices.Clone(e.Warnings)
+
 	// Copy queued allocations
 	if e.QueuedAllocations != nil {
 		queuedAllocations := make(map[string]int, len(e.QueuedAllocations))
@@ -12965,6 +14253,11 @@ type ACLToken struct {
 	// creation. This is a string version of a time.Duration like "2m".
 	ExpirationTTL time.Duration
 
+	// AuthMethod is the name of the auth method which created the token by
+	// logging in. It is empty for tokens created otherwise, and tokens
+	// created by an auth method can be renewed by logging in again.
+	AuthMethod string
+
 	CreateIndex uint64
 	ModifyIndex uint64
 }
@@ -13040,13 +14333,15 @@ type ACLTokenListStub struct {
 	Hash           []byte
 	CreateTime     time.Time
 	ExpirationTime *time.Time
+	AuthMethod     string
 	CreateIndex    uint64
 	ModifyIndex    uint64
 }
 
 // SetHash is used to compute and set the hash of the ACL token. It only hashes
 // fields which can be updated, and as such, does not hash fields such as
-// ExpirationTime.
+// ExpirationTTL. The ExpirationTime is hashed, since renewing a token created
+// by an auth method updates it.
 func (a *ACLToken) SetHash() []byte {
 	// Initialize a 256bit Blake2 hash (32 bytes)
 	hash, err := blake2b.New256(nil)
@@ -13073,6 +14368,10 @@ func (a *ACLToken) SetHash() []byte {
 		_, _ = hash.Write([]byte(roleLink.ID))
 	}
 
+	if a.HasExpirationTime() {
+		_, _ = hash.Write([]byte(a.ExpirationTime.UTC().Format(time.RFC3339Nano)))
+	}
+
 	// Finalize the hash
 	hashVal := hash.Sum(nil)
 
@@ -13092,6 +14391,7 @@ func (a *ACLToken) Stub() *ACLTokenListStub {
 		Hash:           a.Hash,
 		CreateTime:     a.CreateTime,
 		ExpirationTime: a.ExpirationTime,
+		AuthMethod:     a.AuthMethod,
 		CreateIndex:    a.CreateIndex,
 		ModifyIndex:    a.ModifyIndex,
 	}
@@ -13100,6 +14400,11 @@ func (a *ACLToken) Stub() *ACLTokenListStub {
 // ACLTokenListRequest is used to request a list of tokens
 type ACLTokenListRequest struct {
 	GlobalOnly bool
+
+	// ExpiringWithin restricts the list to the tokens which are not expired
+	// yet, but expire within this duration.
+	ExpiringWithin time.Duration
+
 	QueryOptions
 }
 
@@ -13181,8 +14486,21 @@ type OneTimeToken struct {
 	ModifyIndex     uint64
 }
 
+const (
+	// DefaultOneTimeTokenTTL is the time-to-live of the one-time tokens
+	// created without an explicit TTL.
+	DefaultOneTimeTokenTTL = 10 * time.Minute
+
+	// MaxOneTimeTokenTTL is the maximum time-to-live of one-time tokens.
+	MaxOneTimeTokenTTL = DefaultOneTimeTokenTTL
+)
+
 // OneTimeTokenUpsertRequest is the request for a UpsertOneTimeToken RPC
 type OneTimeTokenUpsertRequest struct {
+	// TTL is the time-to-live of the one-time token. Defaults to
+	// DefaultOneTimeTokenTTL and can't exceed MaxOneTimeTokenTTL.
+	TTL time.Duration
+
 	WriteRequest
 }
 
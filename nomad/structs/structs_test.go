@@ -88,6 +88,32 @@ func TestNamespace_Validate(t *testing.T) {
 	}
 }
 
+func TestNamespaceTokenExchangeConfiguration(t *testing.T) {
+	ci.Parallel(t)
+
+	var nilConfig *NamespaceTokenExchangeConfiguration
+	must.NoError(t, nilConfig.Validate())
+	must.False(t, nilConfig.AllowsAudience("vault.io"))
+
+	err := (&NamespaceTokenExchangeConfiguration{MaxTTL: 2 * time.Hour}).Validate()
+	must.ErrorContains(t, err, "at least one audience is required")
+	must.ErrorContains(t, err, "max TTL must not be greater than")
+
+	c := &NamespaceTokenExchangeConfiguration{
+		Audiences: []string{"vault.io", "*.example.com"},
+		MaxTTL:    30 * time.Minute,
+	}
+	must.NoError(t, c.Validate())
+	must.True(t, c.AllowsAudience("vault.io"))
+	must.True(t, c.AllowsAudience("api.example.com"))
+	must.False(t, c.AllowsAudience("consul.io"))
+
+	must.Eq(t, DefaultWorkloadIdentityExchangeTTL, c.TTL(0))
+	must.Eq(t, 5*time.Minute, c.TTL(5*time.Minute))
+	must.Eq(t, 30*time.Minute, c.TTL(time.Hour))
+	must.Eq(t, MaxWorkloadIdentityExchangeTTL, nilConfig.TTL(2*time.Hour))
+}
+
 func TestNamespace_SetHash(t *testing.T) {
 	ci.Parallel(t)
 
@@ -178,6 +204,16 @@ func TestNamespace_SetHash(t *testing.T) {
 	must.NotNil(t, ns.Hash)
 	must.Eq(t, out8, ns.Hash)
 	must.NotEq(t, out7, out8)
+
+	ns.TokenExchangeConfiguration = &NamespaceTokenExchangeConfiguration{
+		Audiences: []string{"vault.io"},
+	}
+	out9 := ns.SetHash()
+	must.NotEq(t, out8, out9)
+
+	ns.TokenExchangeConfiguration.MaxTTL = time.Minute
+	out10 := ns.SetHash()
+	must.NotEq(t, out9, out10)
 }
 
 func TestNamespace_Copy(t *testing.T) {
@@ -1975,6 +2011,37 @@ func TestTaskGroupNetwork_Validate(t *testing.T) {
 	}
 }
 
+func TestTask_Canonicalize_KillEscalation(t *testing.T) {
+	ci.Parallel(t)
+
+	job := &Job{Name: "job"}
+	tg := &TaskGroup{
+		Name: "group",
+		KillEscalation: []*KillEscalationStep{
+			{Signal: "SIGTERM", Timeout: 30 * time.Second},
+			{Signal: "SIGQUIT", Timeout: 10 * time.Second},
+		},
+	}
+
+	// Tasks without a kill escalation inherit the one of the group.
+	task := &Task{Name: "inherit"}
+	task.Canonicalize(job, tg)
+	must.Eq(t, tg.KillEscalation, task.KillEscalation)
+
+	// Tasks with a kill escalation keep their own.
+	own := []*KillEscalationStep{{Signal: "SIGUSR1", Timeout: time.Second}}
+	task = &Task{Name: "own", KillEscalation: own}
+	task.Canonicalize(job, tg)
+	must.Eq(t, own, task.KillEscalation)
+
+	// Steps must have a signal and a timeout.
+	step := &KillEscalationStep{}
+	requireErrors(t, step.Validate(),
+		"Kill escalation signal must not be empty",
+		"Kill escalation timeout must be greater than zero",
+	)
+}
+
 func TestTask_Validate(t *testing.T) {
 	ci.Parallel(t)
 
@@ -2024,6 +2091,43 @@ func TestTask_Validate(t *testing.T) {
 	)
 }
 
+func TestTask_Validate_SPIFFE(t *testing.T) {
+	ci.Parallel(t)
+
+	tg := &TaskGroup{EphemeralDisk: DefaultEphemeralDisk()}
+	task := &Task{
+		Name:      "web",
+		Driver:    "docker",
+		Resources: MinResources(),
+		LogConfig: DefaultLogConfig(),
+		Identity: &WorkloadIdentity{
+			Name:   WorkloadIdentityDefaultName,
+			SPIFFE: &WorkloadIdentitySPIFFE{X509: true},
+		},
+		Identities: []*WorkloadIdentity{
+			{
+				Name:     "spire",
+				Audience: []string{"spire"},
+				TTL:      time.Hour,
+				SPIFFE:   &WorkloadIdentitySPIFFE{X509: true},
+			},
+			{
+				Name:     "other",
+				Audience: []string{"other"},
+				TTL:      time.Hour,
+				SPIFFE:   &WorkloadIdentitySPIFFE{JWT: true},
+			},
+		},
+	}
+	err := task.Validate(JobTypeBatch, tg)
+	must.ErrorContains(t, err, "spiffe for default identity not supported")
+	must.ErrorContains(t, err, `Identities "spire" and "other" both set spiffe`)
+
+	task.Identity = nil
+	task.Identities = task.Identities[:1]
+	must.NoError(t, task.Validate(JobTypeBatch, tg))
+}
+
 func TestTask_Validate_Resources(t *testing.T) {
 	ci.Parallel(t)
 
@@ -3363,6 +3467,19 @@ func TestConstraint_Validate(t *testing.T) {
 	c.RTarget = ">= 0.6.1"
 	require.NoError(t, c.Validate())
 
+	// Perform cidr_contains validation
+	c.Operand = ConstraintCIDRContains
+	c.RTarget = "10.0.0.0/8,foo"
+	err = c.Validate()
+	require.Error(t, err, "CIDR constraint is invalid")
+
+	c.RTarget = ""
+	err = c.Validate()
+	require.Error(t, err, "at least one CIDR block")
+
+	c.RTarget = "10.0.0.0/8, 2001:db8::/32"
+	require.NoError(t, c.Validate())
+
 	// Perform distinct_property validation
 	c.Operand = ConstraintDistinctProperty
 	c.RTarget = "0"
@@ -3475,6 +3592,15 @@ func TestAffinity_Validate(t *testing.T) {
 			},
 			err: fmt.Errorf("Regular expression failed to compile"),
 		},
+		{
+			affinity: &Affinity{
+				Operand: "cidr_contains",
+				LTarget: "${attr.unique.network.ip-address}",
+				RTarget: "10.0.0.0",
+				Weight:  50,
+			},
+			err: fmt.Errorf("CIDR affinity is invalid"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -3501,6 +3627,9 @@ func TestUpdateStrategy_Validate(t *testing.T) {
 		ProgressDeadline: -25,
 		AutoRevert:       false,
 		Canary:           -1,
+
+		HealthChecks:            []string{"c1"},
+		MinHealthyChecksPercent: 101,
 	}
 
 	err := u.Validate()
@@ -3508,6 +3637,8 @@ func TestUpdateStrategy_Validate(t *testing.T) {
 		"Invalid health check given",
 		"Max parallel can not be less than zero",
 		"Canary count can not be less than zero",
+		"Minimum healthy checks percent must be between 0 and 100",
+		"Health checks and minimum healthy checks percent require the \"checks\" health check",
 		"Minimum healthy time may not be less than zero",
 		"Healthy deadline must be greater than zero",
 		"Progress deadline must be zero or greater",
@@ -3516,6 +3647,30 @@ func TestUpdateStrategy_Validate(t *testing.T) {
 	)
 }
 
+func TestTaskGroup_validateUpdateHealthChecks(t *testing.T) {
+	ci.Parallel(t)
+
+	tg := &TaskGroup{
+		Name: "web",
+		Services: []*Service{{
+			Name:   "group",
+			Checks: []*ServiceCheck{{Name: "c1"}},
+		}},
+		Tasks: []*Task{{
+			Services: []*Service{{
+				Name:   "task",
+				Checks: []*ServiceCheck{{Name: "c2"}},
+			}},
+		}},
+		Update: &UpdateStrategy{HealthChecks: []string{"c1", "c2"}},
+	}
+	must.NoError(t, tg.validateUpdateHealthChecks())
+
+	tg.Update.HealthChecks = []string{"c1", "c3"}
+	must.EqError(t, tg.validateUpdateHealthChecks(),
+		"1 error occurred:\n\t* Update health check \"c3\" not found in task group \"web\"\n\n")
+}
+
 func TestResource_NetIndex(t *testing.T) {
 	ci.Parallel(t)
 
@@ -6564,6 +6719,12 @@ func TestACLTokenSetHash(t *testing.T) {
 	assert.NotNil(t, tk.Hash)
 	assert.Equal(t, out2, tk.Hash)
 	assert.NotEqual(t, out1, out2)
+
+	// Renewing a token updates its expiration time, which must be hashed so
+	// the renewal is replicated.
+	tk.ExpirationTime = pointer.Of(time.Date(2022, time.July, 11, 16, 23, 0, 0, time.UTC))
+	out3 := tk.SetHash()
+	assert.NotEqual(t, out2, out3)
 }
 
 func TestACLPolicySetHash(t *testing.T) {
@@ -6587,6 +6748,34 @@ func TestACLPolicySetHash(t *testing.T) {
 	assert.NotEqual(t, out1, out2)
 }
 
+func TestNewTaskExitResult(t *testing.T) {
+	ci.Parallel(t)
+
+	cases := []struct {
+		name      string
+		exitCode  int
+		signal    int
+		oomKilled bool
+		killed    bool
+		expected  string
+	}{
+		{name: "success", expected: TaskResultSuccess},
+		{name: "exit failure", exitCode: 1, expected: TaskResultExitFailure},
+		{name: "signaled", exitCode: 137, signal: 9, expected: TaskResultSignaled},
+		{name: "killed", exitCode: 137, signal: 9, killed: true, expected: TaskResultKilled},
+		{name: "oom killed", exitCode: 137, signal: 9, oomKilled: true, killed: true, expected: TaskResultOOMKilled},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewTaskExitResult(tc.exitCode, tc.signal, tc.oomKilled, tc.killed)
+			must.Eq(t, tc.expected, r.Class)
+			must.Eq(t, tc.exitCode, r.ExitCode)
+			must.Eq(t, tc.signal, r.Signal)
+			must.True(t, r.Equal(r.Copy()))
+		})
+	}
+}
+
 func TestTaskEventPopulate(t *testing.T) {
 	ci.Parallel(t)
 
@@ -6617,6 +6806,8 @@ func TestTaskEventPopulate(t *testing.T) {
 		{NewTaskEvent(TaskKilling).SetKillTimeout(10*time.Second, 5*time.Second), "Sent interrupt. Waiting 5s before force killing"},
 		{NewTaskEvent(TaskTerminated).SetExitCode(-1).SetSignal(3), "Exit Code: -1, Signal: 3"},
 		{NewTaskEvent(TaskTerminated).SetMessage("Goodbye"), "Exit Code: 0, Exit Message: \"Goodbye\""},
+		{NewTaskEvent(TaskTerminated).SetExitCode(137).SetResourceUsage(256*1024*1024, 1500*time.Millisecond, 12), "Exit Code: 137, Peak RSS: 256 MiB, CPU Throttled: 1.5s, Open FDs: 12"},
+		{NewTaskEvent(TaskTerminated).SetExitCode(1).SetResourceUsage(0, 0, -1), "Exit Code: 1, Peak RSS: 0 MiB, CPU Throttled: 0s"},
 		{NewTaskEvent(TaskKilled), "Task successfully killed"},
 		{NewTaskEvent(TaskKilled).SetKillError(fmt.Errorf("undead creatures can't be killed")), "undead creatures can't be killed"},
 		{NewTaskEvent(TaskNotRestarting).SetRestartReason("Chaos Monkey did it"), "Chaos Monkey did it"},
@@ -6824,6 +7015,51 @@ func TestNode_Canonicalize(t *testing.T) {
 	require.Equal(NodeSchedulingIneligible, node.SchedulingEligibility)
 }
 
+func TestNodeUtilization_NeedsUpdate(t *testing.T) {
+	ci.Parallel(t)
+
+	now := time.Now()
+	stored := &NodeUtilization{CPULoad: 0.5, MemoryPressure: 0.5, UpdatedAt: now.UnixNano()}
+
+	must.False(t, stored.NeedsUpdate(nil, now))
+	must.True(t, (*NodeUtilization)(nil).NeedsUpdate(stored, now))
+	must.False(t, stored.NeedsUpdate(&NodeUtilization{CPULoad: 0.55, MemoryPressure: 0.45}, now))
+	must.True(t, stored.NeedsUpdate(&NodeUtilization{CPULoad: 0.7, MemoryPressure: 0.5}, now))
+	must.True(t, stored.NeedsUpdate(&NodeUtilization{CPULoad: 0.5, MemoryPressure: 0.3}, now))
+
+	// Stored utilization is refreshed before it becomes stale.
+	must.False(t, stored.Stale(now))
+	must.True(t, stored.NeedsUpdate(stored, now.Add(NodeUtilizationStaleAfter/2+time.Second)))
+	must.True(t, stored.Stale(now.Add(NodeUtilizationStaleAfter+time.Second)))
+	must.True(t, (*NodeUtilization)(nil).Stale(now))
+
+	// Reporting the resource usage for the first time updates the node.
+	must.True(t, stored.NeedsUpdate(&NodeUtilization{CPULoad: 0.5, MemoryPressure: 0.5, MemoryUsedMB: 512}, now))
+}
+
+func TestNodeUtilization_CPUUtilization(t *testing.T) {
+	ci.Parallel(t)
+
+	node := &Node{
+		NodeResources: &NodeResources{
+			Processors: NodeProcessorResources{
+				Topology: MockBasicTopology(),
+			},
+		},
+	}
+	total := float64(node.NodeResources.Processors.TotalCompute())
+
+	// The load average is used unless the CPU used is reported.
+	u := &NodeUtilization{CPULoad: 0.8}
+	must.False(t, u.HasResourceUsage())
+	must.Eq(t, 0.8, u.CPUUtilization(node))
+
+	u.CPUUsedMHz = int64(total / 4)
+	must.True(t, u.HasResourceUsage())
+	must.Eq(t, 0.25, u.CPUUtilization(node))
+	must.Eq(t, 0.8, u.CPUUtilization(&Node{}))
+}
+
 func TestNode_Copy(t *testing.T) {
 	ci.Parallel(t)
 
@@ -7621,9 +7857,36 @@ func TestDNSConfig_Equal(t *testing.T) {
 	}, {
 		Field: "Options",
 		Apply: func(c *DNSConfig) { c.Options = []string{"opt2"} },
+	}, {
+		Field: "ServiceHosts",
+		Apply: func(c *DNSConfig) { c.ServiceHosts = []string{"db"} },
 	}})
 }
 
+func TestDNSConfig_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	must.NoError(t, (*DNSConfig)(nil).Validate("host"))
+	must.NoError(t, (&DNSConfig{
+		Options:      []string{"ndots:2", "edns0"},
+		ServiceHosts: []string{"db", "cache.example"},
+	}).Validate("bridge"))
+	must.NoError(t, (&DNSConfig{ServiceHosts: []string{"db"}}).Validate("cni/mynet"))
+
+	err := (&DNSConfig{Options: []string{"ndots:16"}}).Validate("bridge")
+	must.ErrorContains(t, err, `DNS option "ndots:16" must set ndots between 0 and 15`)
+
+	err = (&DNSConfig{Options: []string{"ndots:x"}}).Validate("bridge")
+	must.ErrorContains(t, err, `DNS option "ndots:x" must set ndots between 0 and 15`)
+
+	err = (&DNSConfig{ServiceHosts: []string{"db"}}).Validate("host")
+	must.ErrorContains(t, err, `DNS service hosts require the bridge or a CNI network mode, not "host"`)
+
+	err = (&DNSConfig{ServiceHosts: []string{"db..x", ""}}).Validate("bridge")
+	must.ErrorContains(t, err, `DNS service host "db..x" is not a valid host name`)
+	must.ErrorContains(t, err, `DNS service host "" is not a valid host name`)
+}
+
 func TestChangeScript_Equal(t *testing.T) {
 	ci.Parallel(t)
 
@@ -7946,3 +8209,23 @@ func TestTaskIdentity_Canonicalize(t *testing.T) {
 	must.True(t, task.Identities[1].Env)
 	must.False(t, task.Identities[1].File)
 }
+
+func TestMergeJobWarnings(t *testing.T) {
+	ci.Parallel(t)
+
+	a := &JobWarning{Type: JobWarningTypeRegister, Message: "a"}
+	b := &JobWarning{Type: JobWarningTypeAffinity, TaskGroup: "web", Message: "b"}
+
+	warnings, added := MergeJobWarnings(nil)
+	must.False(t, added)
+	must.SliceEmpty(t, warnings)
+
+	warnings, added = MergeJobWarnings([]*JobWarning{a}, b, b)
+	must.True(t, added)
+	must.Eq(t, []*JobWarning{a, b}, warnings)
+
+	// Warnings are compared by value
+	warnings, added = MergeJobWarnings(warnings, &JobWarning{Type: JobWarningTypeRegister, Message: "a"})
+	must.False(t, added)
+	must.Eq(t, []*JobWarning{a, b}, warnings)
+}
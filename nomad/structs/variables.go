@@ -51,6 +51,14 @@ const (
 	// discourage DoS'ing the cluster
 	maxVariableSize = 65536
 
+	// VariableLockSessionAlloc ties a lock to the allocation of the workload
+	// identity that acquired it. The lock is held until the allocation stops.
+	VariableLockSessionAlloc = "alloc"
+
+	// VariableLockSessionToken ties a lock to the ACL token that acquired it.
+	// The lock is held until the token is deleted or expires.
+	VariableLockSessionToken = "token"
+
 	// minVariableLockTTL and maxVariableLockTTL determine the range of valid durations for the
 	// TTL on a lock.They come from the experience on Consul.
 	minVariableLockTTL = 10 * time.Second
@@ -74,6 +82,8 @@ var (
 	errQuotaExhausted     = errors.New("variables are limited to 64KiB in total size")
 	errNegativeDelayOrTTL = errors.New("Lock delay and TTL must be positive")
 	errInvalidTTL         = errors.New("TTL must be between 10 seconds and 24 hours")
+	errInvalidLockSession = fmt.Errorf("lock session must be %q or %q",
+		VariableLockSessionAlloc, VariableLockSessionToken)
 )
 
 // VariableMetadata is the metadata envelope for a Variable, it is the list
@@ -134,6 +144,20 @@ type VariableLock struct {
 	// before another client may acquire the lock. This helps protect against
 	// split-brains.
 	LockDelay time.Duration
+
+	// Session ties the lock to the identity of the caller that acquired it,
+	// either VariableLockSessionAlloc or VariableLockSessionToken. The
+	// servers renew the lock while its session is alive, so the holder
+	// doesn't have to.
+	Session string
+
+	// AllocID is the ID of the allocation holding a lock tied to an alloc
+	// session. It's set by the servers when the lock is acquired.
+	AllocID string
+
+	// AccessorID is the accessor ID of the ACL token holding a lock tied to
+	// a token session. It's set by the servers when the lock is acquired.
+	AccessorID string
 }
 
 // Equal performs an equality check on the two variable lock objects. It
@@ -151,6 +175,15 @@ func (vl *VariableLock) Equal(vl2 *VariableLock) bool {
 	if vl.LockDelay != vl2.LockDelay {
 		return false
 	}
+	if vl.Session != vl2.Session {
+		return false
+	}
+	if vl.AllocID != vl2.AllocID {
+		return false
+	}
+	if vl.AccessorID != vl2.AccessorID {
+		return false
+	}
 	return true
 }
 
@@ -264,6 +297,12 @@ func (vl *VariableLock) Validate() error {
 		mErr = multierror.Append(mErr, errInvalidTTL)
 	}
 
+	switch vl.Session {
+	case "", VariableLockSessionAlloc, VariableLockSessionToken:
+	default:
+		mErr = multierror.Append(mErr, errInvalidLockSession)
+	}
+
 	return mErr.ErrorOrNil()
 }
 
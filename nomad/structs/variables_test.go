@@ -401,6 +401,24 @@ func TestStructs_Lock_Validate(t *testing.T) {
 			},
 			expErr: errInvalidTTL,
 		},
+		{
+			name: "lock_session_is_invalid",
+			lock: &VariableLock{
+				TTL:       minVariableLockTTL,
+				LockDelay: 5 * time.Second,
+				Session:   "node",
+			},
+			expErr: errInvalidLockSession,
+		},
+		{
+			name: "lock_session_is_valid",
+			lock: &VariableLock{
+				TTL:       minVariableLockTTL,
+				LockDelay: 5 * time.Second,
+				Session:   VariableLockSessionAlloc,
+			},
+			expErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
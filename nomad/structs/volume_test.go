@@ -50,6 +50,16 @@ func TestVolumeRequest_Validate(t *testing.T) {
 				PerAlloc: true,
 			},
 		},
+		{
+			name: "local volume with system job",
+			expected: []string{
+				"local volumes cannot be used by system or sysbatch jobs",
+			},
+			req: &VolumeRequest{
+				Type:   VolumeTypeLocal,
+				Source: "data",
+			},
+		},
 		{
 			name: "CSI volume multi-reader-single-writer access mode",
 			expected: []string{
@@ -11,6 +11,10 @@ import (
 
 const (
 	VolumeTypeHost = "host"
+
+	// VolumeTypeLocal is a host volume that binds the allocations using it to
+	// the node of their first placement, see LocalVolumeClaim.
+	VolumeTypeLocal = "local"
 )
 
 const (
@@ -130,8 +134,15 @@ func (v *VolumeRequest) Equal(o *VolumeRequest) bool {
 	return true
 }
 
+// IsHostVolume returns whether the volume is mounted from a host volume of the
+// client.
+func (v *VolumeRequest) IsHostVolume() bool {
+	return v.Type == VolumeTypeHost || v.Type == VolumeTypeLocal
+}
+
 func (v *VolumeRequest) Validate(jobType string, taskGroupCount, canaries int) error {
 	if !(v.Type == VolumeTypeHost ||
+		v.Type == VolumeTypeLocal ||
 		v.Type == VolumeTypeCSI) {
 		return fmt.Errorf("volume has unrecognized type %s", v.Type)
 	}
@@ -155,15 +166,18 @@ func (v *VolumeRequest) Validate(jobType string, taskGroupCount, canaries int) e
 
 	switch v.Type {
 
-	case VolumeTypeHost:
+	case VolumeTypeHost, VolumeTypeLocal:
 		if v.AttachmentMode != CSIVolumeAttachmentModeUnknown {
-			addErr("host volumes cannot have an attachment mode")
+			addErr("%s volumes cannot have an attachment mode", v.Type)
 		}
 		if v.AccessMode != CSIVolumeAccessModeUnknown {
-			addErr("host volumes cannot have an access mode")
+			addErr("%s volumes cannot have an access mode", v.Type)
 		}
 		if v.MountOptions != nil {
-			addErr("host volumes cannot have mount options")
+			addErr("%s volumes cannot have mount options", v.Type)
+		}
+		if v.Type == VolumeTypeLocal && (jobType == JobTypeSystem || jobType == JobTypeSysBatch) {
+			addErr("local volumes cannot be used by system or sysbatch jobs")
 		}
 
 	case VolumeTypeCSI:
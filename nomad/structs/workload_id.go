@@ -5,12 +5,17 @@ package structs
 
 import (
 	"fmt"
+	"maps"
+	"net/url"
+	"os"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/helper/args"
 )
 
 const (
@@ -38,6 +43,10 @@ const (
 	// returned when the requested identity does not exist on the allocation.
 	WIRejectionReasonMissingIdentity = "identity not found"
 
+	// WIRejectionReasonMissingService is the error returned when the service
+	// of a requested certificate does not exist on the allocation.
+	WIRejectionReasonMissingService = "service not found"
+
 	// WIChangeModeNoop takes no action when a new token is retrieved.
 	WIChangeModeNoop = "noop"
 
@@ -46,12 +55,77 @@ const (
 
 	// WIChangeModeRestart restarts the task when a new token is retrieved.
 	WIChangeModeRestart = "restart"
+
+	// WIChangeModeScript runs a script in the task when a new token is
+	// retrieved.
+	WIChangeModeScript = "script"
+
+	// WIProjectionDefaultFile is the default name of the file the token of a
+	// projected identity is written to.
+	WIProjectionDefaultFile = "token"
+
+	// WIProjectionDefaultPerms are the default permissions of the files of a
+	// projected identity.
+	WIProjectionDefaultPerms = "0644"
+
+	// SPIFFEDefaultTrustDomain is the SPIFFE trust domain of workload
+	// identities if none is configured.
+	SPIFFEDefaultTrustDomain = "nomad"
+
+	// WISPIFFEDir is the directory of the secrets directory of a task the
+	// SVIDs of its identity are written to. The names of the files match the
+	// ones written by the spiffe-helper of SPIRE.
+	WISPIFFEDir        = "spiffe"
+	WISPIFFECertFile   = "svid.pem"
+	WISPIFFEKeyFile    = "svid_key.pem"
+	WISPIFFEBundleFile = "bundle.pem"
+	WISPIFFEJWTFile    = "jwt_svid.token"
+
+	// WIProjectionCAFile and WIProjectionNamespaceFile are the names of the
+	// files with the CA bundle of the client and the namespace of the
+	// allocation written along with the token of a projected identity.
+	WIProjectionCAFile        = "ca.crt"
+	WIProjectionNamespaceFile = "namespace"
+
+	// WICloudProviderAWS, WICloudProviderGCP and WICloudProviderAzure are the
+	// cloud providers workload identities can be exchanged with for cloud
+	// credentials.
+	WICloudProviderAWS   = "aws"
+	WICloudProviderGCP   = "gcp"
+	WICloudProviderAzure = "azure"
 )
 
 var (
 	// validIdentityName is used to validate workload identity Name fields. Must
 	// be safe to use in filenames.
 	validIdentityName = regexp.MustCompile("^[a-zA-Z0-9-_]{1,128}$")
+
+	// audienceRefRe matches the references to variables in audiences, such as
+	// ${NOMAD_NAMESPACE}.
+	audienceRefRe = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+	// identityAudienceEnv are the variables of the environment of a workload
+	// that can be referenced in the audience of its identities, besides the
+	// meta as NOMAD_META_<key>.
+	identityAudienceEnv = []string{
+		"NOMAD_ALLOC_ID",
+		"NOMAD_ALLOC_INDEX",
+		"NOMAD_ALLOC_NAME",
+		"NOMAD_GROUP_NAME",
+		"NOMAD_JOB_ID",
+		"NOMAD_JOB_NAME",
+		"NOMAD_JOB_PARENT_ID",
+		"NOMAD_NAMESPACE",
+		"NOMAD_REGION",
+		"NOMAD_TASK_NAME",
+	}
+
+	// reservedIdentityClaims are the names of the claims set by Nomad, which
+	// extra claims can't override.
+	reservedIdentityClaims = []string{
+		"aud", "exp", "iat", "iss", "jti", "nbf", "sub",
+		"nomad_namespace", "nomad_job_id", "nomad_allocation_id", "nomad_task", "nomad_service",
+	}
 )
 
 // WorkloadIdentity is the jobspec block which determines if and how a workload
@@ -75,6 +149,10 @@ type WorkloadIdentity struct {
 	// retrieved. This is only valid when using the signal change mode.
 	ChangeSignal string
 
+	// ChangeScript is the script run in the task when a new token is
+	// retrieved. This is only valid when using the script change mode.
+	ChangeScript *ChangeScript
+
 	// Env injects the Workload Identity into the Task's environment if
 	// set.
 	Env bool
@@ -89,6 +167,29 @@ type WorkloadIdentity struct {
 	// TTL is used to determine the expiration of the credentials created for
 	// this identity (eg the JWT "exp" claim).
 	TTL time.Duration
+
+	// X509 writes an X.509 certificate and private key for the identity into
+	// the Task's secrets directory if set. The certificate is signed by the
+	// workload CA of the servers, and renewed along with the identity.
+	X509 bool
+
+	// Projection projects the identity into a directory of the task if set,
+	// like Kubernetes projects the tokens of service accounts.
+	Projection *WorkloadIdentityProjection
+
+	// SPIFFE writes SPIFFE SVIDs for the identity into the Task's secrets
+	// directory if set. The SVIDs are renewed along with the identity.
+	SPIFFE *WorkloadIdentitySPIFFE
+
+	// Cloud exchanges the identity for the credentials of a cloud provider
+	// and writes them into the Task's secrets directory if set. The
+	// credentials are refreshed along with the identity.
+	Cloud *WorkloadIdentityCloud
+
+	// ExtraClaims maps the names of extra claims of the identity to the keys
+	// of the meta of the task, group or job they are sourced from. The
+	// claims must be allowed by the ACL policies of the submitter of the job.
+	ExtraClaims map[string]string
 }
 
 func (wi *WorkloadIdentity) Copy() *WorkloadIdentity {
@@ -100,10 +201,16 @@ func (wi *WorkloadIdentity) Copy() *WorkloadIdentity {
 		Audience:     slices.Clone(wi.Audience),
 		ChangeMode:   wi.ChangeMode,
 		ChangeSignal: wi.ChangeSignal,
+		ChangeScript: wi.ChangeScript.Copy(),
 		Env:          wi.Env,
 		File:         wi.File,
 		ServiceName:  wi.ServiceName,
 		TTL:          wi.TTL,
+		X509:         wi.X509,
+		Projection:   wi.Projection.Copy(),
+		SPIFFE:       wi.SPIFFE.Copy(),
+		Cloud:        wi.Cloud.Copy(),
+		ExtraClaims:  maps.Clone(wi.ExtraClaims),
 	}
 }
 
@@ -128,6 +235,10 @@ func (wi *WorkloadIdentity) Equal(other *WorkloadIdentity) bool {
 		return false
 	}
 
+	if !wi.ChangeScript.Equal(other.ChangeScript) {
+		return false
+	}
+
 	if wi.Env != other.Env {
 		return false
 	}
@@ -144,6 +255,26 @@ func (wi *WorkloadIdentity) Equal(other *WorkloadIdentity) bool {
 		return false
 	}
 
+	if wi.X509 != other.X509 {
+		return false
+	}
+
+	if !wi.Projection.Equal(other.Projection) {
+		return false
+	}
+
+	if !wi.SPIFFE.Equal(other.SPIFFE) {
+		return false
+	}
+
+	if !wi.Cloud.Equal(other.Cloud) {
+		return false
+	}
+
+	if !maps.Equal(wi.ExtraClaims, other.ExtraClaims) {
+		return false
+	}
+
 	return true
 }
 
@@ -164,6 +295,8 @@ func (wi *WorkloadIdentity) Canonicalize() {
 	if wi.ChangeSignal != "" {
 		wi.ChangeSignal = strings.ToUpper(wi.ChangeSignal)
 	}
+
+	wi.Projection.Canonicalize()
 }
 
 func (wi *WorkloadIdentity) Validate() error {
@@ -182,6 +315,12 @@ func (wi *WorkloadIdentity) Validate() error {
 		if aud == "" {
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("an empty string is an invalid audience (%d)", i+1))
 		}
+		for _, match := range audienceRefRe.FindAllStringSubmatch(aud, -1) {
+			name := match[1]
+			if !slices.Contains(identityAudienceEnv, name) && !strings.HasPrefix(name, metaEnvPrefix) {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("audience %q references unsupported variable %q", aud, name))
+			}
+		}
 	}
 
 	switch wi.ChangeMode {
@@ -195,11 +334,25 @@ func (wi *WorkloadIdentity) Validate() error {
 		if wi.ChangeSignal == "" {
 			mErr.Errors = append(mErr.Errors, fmt.Errorf("change_signal must be specified when using change_mode=%q", WIChangeModeSignal))
 		}
+	case WIChangeModeScript:
+		if wi.ChangeSignal != "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("can only use change_signal=%q with change_mode=%q",
+				wi.ChangeSignal, WIChangeModeSignal))
+		}
+		if wi.ChangeScript == nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("change_script must be specified when using change_mode=%q", WIChangeModeScript))
+		} else if err := wi.ChangeScript.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
 	default:
 		// Unknown change_mode
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid change_mode: %s", wi.ChangeMode))
 	}
 
+	if wi.ChangeScript != nil && wi.ChangeMode != WIChangeModeScript {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("can only use change_script with change_mode=%q", WIChangeModeScript))
+	}
+
 	if wi.TTL > 0 && (wi.Name == "" || wi.Name == WorkloadIdentityDefaultName) {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("ttl for default identity not yet supported"))
 	}
@@ -208,9 +361,103 @@ func (wi *WorkloadIdentity) Validate() error {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("ttl must be >= 0"))
 	}
 
+	if wi.X509 {
+		if wi.Name == "" || wi.Name == WorkloadIdentityDefaultName {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("x509 for default identity not supported"))
+		}
+		if wi.TTL == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("ttl must be set when using x509"))
+		}
+		if wi.ServiceName != "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("x509 for service identities not supported"))
+		}
+	}
+
+	if wi.Projection != nil {
+		if wi.ServiceName != "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("projection for service identities not supported"))
+		}
+		if err := wi.Projection.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+
+	if wi.SPIFFE != nil {
+		if wi.Name == "" || wi.Name == WorkloadIdentityDefaultName {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("spiffe for default identity not supported"))
+		}
+		if wi.TTL == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("ttl must be set when using spiffe"))
+		}
+		if wi.ServiceName != "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("spiffe for service identities not supported"))
+		}
+		if err := wi.SPIFFE.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+		if wi.SPIFFE.JWT && len(wi.Audience) == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("audience must be set when using spiffe jwt"))
+		}
+	}
+
+	if wi.Cloud != nil {
+		if wi.Name == "" || wi.Name == WorkloadIdentityDefaultName {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("cloud for default identity not supported"))
+		}
+		if wi.TTL == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("ttl must be set when using cloud"))
+		}
+		if wi.ServiceName != "" {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("cloud for service identities not supported"))
+		}
+		if len(wi.Audience) == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("audience must be set when using cloud"))
+		}
+		if err := wi.Cloud.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+
+	if len(wi.ExtraClaims) > 0 && (wi.Name == "" || wi.Name == WorkloadIdentityDefaultName) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("extra_claims for default identity not supported"))
+	}
+	for claim, key := range wi.ExtraClaims {
+		switch {
+		case claim == "":
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("an empty string is an invalid extra claim name"))
+		case slices.Contains(reservedIdentityClaims, claim) || strings.HasPrefix(claim, "nomad_"):
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("extra claim %q is reserved", claim))
+		case key == "":
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("extra claim %q must be sourced from a meta key", claim))
+		}
+	}
+
 	return mErr.ErrorOrNil()
 }
 
+// InterpolateExtraClaims returns the values of the extra claims of the
+// identity from the meta of its workload. Only the identities of tasks have
+// the meta of their task. Claims whose meta key is not set are omitted.
+func (wi *WorkloadIdentity) InterpolateExtraClaims(job *Job, group string, wihandle *WIHandle) map[string]string {
+	if len(wi.ExtraClaims) == 0 {
+		return nil
+	}
+
+	taskName := ""
+	if wihandle.WorkloadType == WorkloadTypeTask {
+		taskName = wihandle.WorkloadIdentifier
+	}
+	meta := job.combinedMeta(group, taskName)
+
+	claims := make(map[string]string, len(wi.ExtraClaims))
+	for claim, key := range wi.ExtraClaims {
+		if value, ok := meta[key]; ok {
+			claims[claim] = value
+		}
+	}
+	return claims
+}
+
 func (wi *WorkloadIdentity) Warnings() error {
 	if wi == nil {
 		return fmt.Errorf("must not be nil")
@@ -239,11 +486,285 @@ func (wi *WorkloadIdentity) Warnings() error {
 	return mErr.ErrorOrNil()
 }
 
+// WorkloadIdentityProjection is the jobspec block projecting a workload
+// identity into a directory of its task, along with the CA bundle of the
+// client and the namespace of the allocation, so applications reading the
+// service account tokens projected by Kubernetes can read it unchanged. The
+// files are renewed along with the identity.
+type WorkloadIdentityProjection struct {
+	// Destination is the path of the directory in the task.
+	Destination string
+
+	// File is the name of the file the token is written to.
+	File string
+
+	// Perms are the permissions of the files, as an octal string.
+	Perms string
+
+	// CABundle writes the CA bundle the client uses to verify the servers if
+	// set.
+	CABundle bool
+}
+
+func (p *WorkloadIdentityProjection) Copy() *WorkloadIdentityProjection {
+	if p == nil {
+		return nil
+	}
+	np := *p
+	return &np
+}
+
+func (p *WorkloadIdentityProjection) Equal(o *WorkloadIdentityProjection) bool {
+	if p == nil || o == nil {
+		return p == o
+	}
+	return *p == *o
+}
+
+func (p *WorkloadIdentityProjection) Canonicalize() {
+	if p == nil {
+		return
+	}
+	if p.File == "" {
+		p.File = WIProjectionDefaultFile
+	}
+	if p.Perms == "" {
+		p.Perms = WIProjectionDefaultPerms
+	}
+}
+
+func (p *WorkloadIdentityProjection) Validate() error {
+	var mErr multierror.Error
+
+	if p.Destination == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("projection destination must be set"))
+	} else if !strings.HasPrefix(p.Destination, "/") {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("projection destination %q must be an absolute path", p.Destination))
+	}
+
+	switch p.File {
+	case "", ".", "..", WIProjectionCAFile, WIProjectionNamespaceFile:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid projection file %q", p.File))
+	default:
+		if strings.ContainsAny(p.File, `/\`) {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("projection file %q must be a file name", p.File))
+		}
+	}
+
+	if _, err := strconv.ParseUint(p.Perms, 8, 12); err != nil {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("failed to parse projection perms %q as octal: %v", p.Perms, err))
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// FileMode returns the file mode of the files of the projection.
+func (p *WorkloadIdentityProjection) FileMode() os.FileMode {
+	perms, err := strconv.ParseUint(p.Perms, 8, 12)
+	if err != nil {
+		perms, _ = strconv.ParseUint(WIProjectionDefaultPerms, 8, 12)
+	}
+	return os.FileMode(perms)
+}
+
+// RequiresCertificate returns true if an X.509 certificate must be signed for
+// the identity, either because it sets x509 or a SPIFFE X.509-SVID.
+func (wi *WorkloadIdentity) RequiresCertificate() bool {
+	return wi.X509 || (wi.SPIFFE != nil && wi.SPIFFE.X509)
+}
+
+// WorkloadIdentitySPIFFE is the jobspec block writing SPIFFE Verifiable
+// Identity Documents (SVIDs) derived from a workload identity into the
+// secrets directory of its task, in the layout of the files written by the
+// spiffe-helper of SPIRE. The X.509-SVID is signed by the workload CA of the
+// servers and the JWT-SVID by their keyring, and both carry the SPIFFE ID of
+// the task.
+type WorkloadIdentitySPIFFE struct {
+	// JWT writes a JWT-SVID if set.
+	JWT bool
+
+	// X509 writes an X.509-SVID, its private key, and the trust bundle if
+	// set.
+	X509 bool
+}
+
+func (s *WorkloadIdentitySPIFFE) Copy() *WorkloadIdentitySPIFFE {
+	if s == nil {
+		return nil
+	}
+	ns := *s
+	return &ns
+}
+
+func (s *WorkloadIdentitySPIFFE) Equal(o *WorkloadIdentitySPIFFE) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return *s == *o
+}
+
+func (s *WorkloadIdentitySPIFFE) Validate() error {
+	if !s.JWT && !s.X509 {
+		return fmt.Errorf("spiffe must set at least one of jwt or x509")
+	}
+	return nil
+}
+
+// WorkloadIdentityCloud is the jobspec block exchanging a workload identity
+// for the short-lived credentials of a cloud provider, using the identity
+// federation of the provider: AssumeRoleWithWebIdentity for AWS, workload
+// identity federation for GCP, and federated credentials of applications for
+// Azure. The credentials are written into the secrets directory of the task,
+// so tasks don't need a sidecar to obtain them.
+type WorkloadIdentityCloud struct {
+	// Provider is the cloud provider, one of aws, gcp or azure.
+	Provider string
+
+	// RoleARN is the ARN of the AWS IAM role to assume.
+	RoleARN string
+
+	// Region is the AWS region of the STS endpoint. The global endpoint is
+	// used if unset.
+	Region string
+
+	// WorkloadIdentityProvider is the resource name of the GCP workload
+	// identity pool provider trusting the identity.
+	WorkloadIdentityProvider string
+
+	// ServiceAccount is the email of the GCP service account to impersonate.
+	// The federated access token is used as is if unset.
+	ServiceAccount string
+
+	// TenantID and ClientID are the Azure tenant and the client ID of the
+	// application trusting the identity.
+	TenantID string
+	ClientID string
+
+	// Scopes are the OAuth scopes of the GCP or Azure access token.
+	Scopes []string
+}
+
+func (c *WorkloadIdentityCloud) Copy() *WorkloadIdentityCloud {
+	if c == nil {
+		return nil
+	}
+	nc := *c
+	nc.Scopes = slices.Clone(c.Scopes)
+	return &nc
+}
+
+func (c *WorkloadIdentityCloud) Equal(o *WorkloadIdentityCloud) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+	return c.Provider == o.Provider &&
+		c.RoleARN == o.RoleARN &&
+		c.Region == o.Region &&
+		c.WorkloadIdentityProvider == o.WorkloadIdentityProvider &&
+		c.ServiceAccount == o.ServiceAccount &&
+		c.TenantID == o.TenantID &&
+		c.ClientID == o.ClientID &&
+		slices.Equal(c.Scopes, o.Scopes)
+}
+
+func (c *WorkloadIdentityCloud) Validate() error {
+	var mErr multierror.Error
+
+	// Each field must be set for its provider if required, and must not be
+	// set for the other providers.
+	fields := []struct {
+		name     string
+		set      bool
+		provider string
+		required bool
+	}{
+		{"role_arn", c.RoleARN != "", WICloudProviderAWS, true},
+		{"region", c.Region != "", WICloudProviderAWS, false},
+		{"workload_identity_provider", c.WorkloadIdentityProvider != "", WICloudProviderGCP, true},
+		{"service_account", c.ServiceAccount != "", WICloudProviderGCP, false},
+		{"tenant_id", c.TenantID != "", WICloudProviderAzure, true},
+		{"client_id", c.ClientID != "", WICloudProviderAzure, true},
+	}
+
+	switch c.Provider {
+	case WICloudProviderAWS, WICloudProviderGCP, WICloudProviderAzure:
+		for _, field := range fields {
+			switch {
+			case field.provider == c.Provider && field.required && !field.set:
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("cloud %s must be set for provider %q", field.name, c.Provider))
+			case field.provider != c.Provider && field.set:
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("cloud %s not supported for provider %q", field.name, c.Provider))
+			}
+		}
+		if c.Provider == WICloudProviderAWS && len(c.Scopes) > 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("cloud scopes not supported for provider %q", c.Provider))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid cloud provider %q", c.Provider))
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// SPIFFEID returns the SPIFFE ID of the task of the allocation in the trust
+// domain.
+func SPIFFEID(trustDomain string, alloc *Allocation, taskName string) *url.URL {
+	return &url.URL{
+		Scheme: "spiffe",
+		Host:   trustDomain,
+		Path: fmt.Sprintf("/ns/%s/job/%s/group/%s/task/%s",
+			alloc.Namespace, alloc.JobID, alloc.TaskGroup, taskName),
+	}
+}
+
+// InterpolateAudience returns the audience of the identity of a workload of
+// the allocation, with the references to the environment of the workload
+// replaced, such as ${NOMAD_NAMESPACE} or ${NOMAD_META_key}. It's evaluated
+// when the identity is signed, so references to variables the workload
+// doesn't set are left as they are.
+func (wi *WorkloadIdentity) InterpolateAudience(job *Job, alloc *Allocation, wihandle *WIHandle) []string {
+	if !slices.ContainsFunc(wi.Audience, args.ContainsEnv) {
+		return slices.Clone(wi.Audience)
+	}
+
+	env := map[string]string{
+		"NOMAD_ALLOC_ID":      alloc.ID,
+		"NOMAD_ALLOC_INDEX":   strconv.FormatUint(uint64(alloc.Index()), 10),
+		"NOMAD_ALLOC_NAME":    alloc.Name,
+		"NOMAD_GROUP_NAME":    alloc.TaskGroup,
+		"NOMAD_JOB_ID":        job.ID,
+		"NOMAD_JOB_NAME":      job.Name,
+		"NOMAD_JOB_PARENT_ID": job.ParentID,
+		"NOMAD_NAMESPACE":     alloc.Namespace,
+		"NOMAD_REGION":        job.Region,
+	}
+
+	// Only the identities of tasks have the meta of their task
+	taskName := ""
+	if wihandle.WorkloadType == WorkloadTypeTask {
+		taskName = wihandle.WorkloadIdentifier
+		env["NOMAD_TASK_NAME"] = taskName
+	}
+	for name, value := range metaEnvNames(job.combinedMeta(alloc.TaskGroup, taskName)) {
+		env[metaEnvPrefix+name] = value
+	}
+
+	aud := make([]string, len(wi.Audience))
+	for i, a := range wi.Audience {
+		aud[i] = args.ReplaceEnv(a, env)
+	}
+	return aud
+}
+
 // WorkloadIdentityRequest encapsulates the 3 parameters used to generated a
 // signed workload identity: the alloc, task, and specific identity's name.
 type WorkloadIdentityRequest struct {
 	AllocID string
 	WIHandle
+
+	// Renewal is set when the identity replaces a previously signed one, so
+	// its signature is reported as a renewal on the event stream.
+	Renewal bool
 }
 
 // SignedWorkloadIdentity is the response to a WorkloadIdentityRequest and
@@ -261,6 +782,29 @@ type WorkloadIdentityRejection struct {
 	Reason string
 }
 
+// AllocCertificateRequest is the RPC arguments for requesting an X.509
+// certificate for a workload identity.
+type AllocCertificateRequest struct {
+	WorkloadIdentityRequest
+
+	// CSR is the PEM encoded certificate signing request of the workload.
+	CSR []byte
+
+	QueryOptions
+}
+
+// AllocCertificateResponse is the RPC response for a requested workload
+// certificate.
+type AllocCertificateResponse struct {
+	// Certificate is the PEM encoded certificate signed by the workload CA.
+	Certificate []byte
+
+	// CACertificate is the PEM encoded certificate of the workload CA.
+	CACertificate []byte
+
+	QueryMeta
+}
+
 // AllocIdentitiesRequest is the RPC arguments for requesting signed workload
 // identities.
 type AllocIdentitiesRequest struct {
@@ -276,6 +820,13 @@ type AllocIdentitiesResponse struct {
 	QueryMeta
 }
 
+// AllocIdentityEventsRequest is the RPC arguments for publishing lifecycle
+// events of workload identities on the event stream.
+type AllocIdentityEventsRequest struct {
+	Events []*WorkloadIdentityEvent
+	WriteRequest
+}
+
 type WorkloadType int
 
 const (
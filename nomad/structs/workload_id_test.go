@@ -4,6 +4,7 @@
 package structs
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -38,6 +39,15 @@ func TestWorkloadIdentity_Equal(t *testing.T) {
 	orig.ChangeSignal = ""
 	must.Equal(t, orig, newWI)
 
+	orig.ChangeScript = &ChangeScript{Command: "/bin/reauth"}
+	must.NotEqual(t, orig, newWI)
+
+	newWI.ChangeScript = orig.ChangeScript.Copy()
+	must.Equal(t, orig, newWI)
+
+	orig.ChangeScript, newWI.ChangeScript = nil, nil
+	must.Equal(t, orig, newWI)
+
 	orig.Env = true
 	must.NotEqual(t, orig, newWI)
 
@@ -64,6 +74,30 @@ func TestWorkloadIdentity_Equal(t *testing.T) {
 
 	newWI.TTL = 123 * time.Hour
 	must.NotEqual(t, orig, newWI)
+
+	newWI.TTL = orig.TTL
+	newWI.SPIFFE = &WorkloadIdentitySPIFFE{JWT: true}
+	must.NotEqual(t, orig, newWI)
+
+	orig.SPIFFE = newWI.SPIFFE.Copy()
+	must.Equal(t, orig, newWI)
+
+	newWI.Cloud = &WorkloadIdentityCloud{Provider: "gcp", Scopes: []string{"scope"}}
+	must.NotEqual(t, orig, newWI)
+
+	orig.Cloud = newWI.Cloud.Copy()
+	must.Equal(t, orig, newWI)
+
+	newWI.Cloud.Scopes[0] = "other"
+	must.NotEqual(t, orig, newWI)
+
+	newWI.Cloud = orig.Cloud.Copy()
+
+	newWI.ExtraClaims = map[string]string{"team": "team"}
+	must.NotEqual(t, orig, newWI)
+
+	orig = newWI.Copy()
+	must.Equal(t, orig, newWI)
 }
 
 // TestWorkloadIdentity_Validate asserts that canonicalized workload identities
@@ -173,6 +207,48 @@ func TestWorkloadIdentity_Validate(t *testing.T) {
 			},
 			Err: `can only use change_signal=`,
 		},
+		{
+			Desc: "OkScript",
+			In: WorkloadIdentity{
+				Name:         "foo-id",
+				Audience:     []string{"http://nomadproject.io/"},
+				ChangeMode:   WIChangeModeScript,
+				ChangeScript: &ChangeScript{Command: "/bin/reauth", Timeout: time.Second},
+				File:         true,
+				TTL:          time.Hour,
+			},
+			Exp: WorkloadIdentity{
+				Name:         "foo-id",
+				Audience:     []string{"http://nomadproject.io/"},
+				ChangeMode:   WIChangeModeScript,
+				ChangeScript: &ChangeScript{Command: "/bin/reauth", Timeout: time.Second},
+				File:         true,
+				TTL:          time.Hour,
+			},
+		},
+		{
+			Desc: "Script without script",
+			In: WorkloadIdentity{
+				Name:       "foo-id",
+				Audience:   []string{"http://nomadproject.io/"},
+				ChangeMode: WIChangeModeScript,
+				File:       true,
+				TTL:        time.Hour,
+			},
+			Err: `change_script must be specified`,
+		},
+		{
+			Desc: "Restart with script",
+			In: WorkloadIdentity{
+				Name:         "foo-id",
+				Audience:     []string{"http://nomadproject.io/"},
+				ChangeMode:   WIChangeModeRestart,
+				ChangeScript: &ChangeScript{Command: "/bin/reauth"},
+				File:         true,
+				TTL:          time.Hour,
+			},
+			Err: `can only use change_script with`,
+		},
 		{
 			Desc: "Be reasonable",
 			In: WorkloadIdentity{
@@ -244,6 +320,215 @@ func TestWorkloadIdentity_Validate(t *testing.T) {
 			},
 			Warn: "identities without an expiration are insecure",
 		},
+		{
+			Desc: "X509",
+			In: WorkloadIdentity{
+				Name:     "foo",
+				Audience: []string{"foo"},
+				X509:     true,
+				TTL:      time.Hour,
+			},
+			Exp: WorkloadIdentity{
+				Name:     "foo",
+				Audience: []string{"foo"},
+				X509:     true,
+				TTL:      time.Hour,
+			},
+		},
+		{
+			Desc: "X509 without TTL",
+			In: WorkloadIdentity{
+				Name:     "foo",
+				Audience: []string{"foo"},
+				X509:     true,
+			},
+			Err: "ttl must be set when using x509",
+		},
+		{
+			Desc: "X509 default identity",
+			In: WorkloadIdentity{
+				Name: WorkloadIdentityDefaultName,
+				X509: true,
+				TTL:  time.Hour,
+			},
+			Err: "x509 for default identity not supported",
+		},
+		{
+			Desc: "Projection",
+			In: WorkloadIdentity{
+				Name:       "foo",
+				Audience:   []string{"foo"},
+				TTL:        time.Hour,
+				Projection: &WorkloadIdentityProjection{Destination: "/var/run/secrets/tokens"},
+			},
+			Exp: WorkloadIdentity{
+				Name:     "foo",
+				Audience: []string{"foo"},
+				TTL:      time.Hour,
+				Projection: &WorkloadIdentityProjection{
+					Destination: "/var/run/secrets/tokens",
+					File:        WIProjectionDefaultFile,
+					Perms:       WIProjectionDefaultPerms,
+				},
+			},
+		},
+		{
+			Desc: "Projection relative destination",
+			In: WorkloadIdentity{
+				Name:       "foo",
+				Audience:   []string{"foo"},
+				Projection: &WorkloadIdentityProjection{Destination: "secrets"},
+			},
+			Err: "must be an absolute path",
+		},
+		{
+			Desc: "Projection reserved file",
+			In: WorkloadIdentity{
+				Name:       "foo",
+				Audience:   []string{"foo"},
+				Projection: &WorkloadIdentityProjection{Destination: "/secrets", File: WIProjectionCAFile},
+			},
+			Err: "invalid projection file",
+		},
+		{
+			Desc: "Projection file path",
+			In: WorkloadIdentity{
+				Name:       "foo",
+				Audience:   []string{"foo"},
+				Projection: &WorkloadIdentityProjection{Destination: "/secrets", File: "../token"},
+			},
+			Err: "must be a file name",
+		},
+		{
+			Desc: "Projection invalid perms",
+			In: WorkloadIdentity{
+				Name:       "foo",
+				Audience:   []string{"foo"},
+				Projection: &WorkloadIdentityProjection{Destination: "/secrets", Perms: "0999"},
+			},
+			Err: "failed to parse projection perms",
+		},
+		{
+			Desc: "SPIFFE",
+			In: WorkloadIdentity{
+				Name:     "spire",
+				Audience: []string{"spire"},
+				TTL:      time.Hour,
+				SPIFFE:   &WorkloadIdentitySPIFFE{JWT: true, X509: true},
+			},
+			Exp: WorkloadIdentity{
+				Name:     "spire",
+				Audience: []string{"spire"},
+				TTL:      time.Hour,
+				SPIFFE:   &WorkloadIdentitySPIFFE{JWT: true, X509: true},
+			},
+		},
+		{
+			Desc: "SPIFFE without SVIDs",
+			In: WorkloadIdentity{
+				Name:     "spire",
+				Audience: []string{"spire"},
+				TTL:      time.Hour,
+				SPIFFE:   &WorkloadIdentitySPIFFE{},
+			},
+			Err: "spiffe must set at least one of jwt or x509",
+		},
+		{
+			Desc: "SPIFFE without TTL",
+			In: WorkloadIdentity{
+				Name:     "spire",
+				Audience: []string{"spire"},
+				SPIFFE:   &WorkloadIdentitySPIFFE{X509: true},
+			},
+			Err: "ttl must be set when using spiffe",
+		},
+		{
+			Desc: "SPIFFE JWT without audience",
+			In: WorkloadIdentity{
+				Name:   "spire",
+				TTL:    time.Hour,
+				SPIFFE: &WorkloadIdentitySPIFFE{JWT: true},
+			},
+			Err: "audience must be set when using spiffe jwt",
+		},
+		{
+			Desc: "SPIFFE service identity",
+			In: WorkloadIdentity{
+				Name:        "consul-service_web",
+				Audience:    []string{"consul.io"},
+				ServiceName: "web",
+				TTL:         time.Hour,
+				SPIFFE:      &WorkloadIdentitySPIFFE{X509: true},
+			},
+			Err: "spiffe for service identities not supported",
+		},
+		{
+			Desc: "Cloud AWS",
+			In: WorkloadIdentity{
+				Name:     "aws",
+				Audience: []string{"sts.amazonaws.com"},
+				TTL:      time.Hour,
+				Cloud:    &WorkloadIdentityCloud{Provider: "aws", RoleARN: "arn:aws:iam::123456789012:role/web"},
+			},
+			Exp: WorkloadIdentity{
+				Name:     "aws",
+				Audience: []string{"sts.amazonaws.com"},
+				TTL:      time.Hour,
+				Cloud:    &WorkloadIdentityCloud{Provider: "aws", RoleARN: "arn:aws:iam::123456789012:role/web"},
+			},
+		},
+		{
+			Desc: "Cloud invalid provider",
+			In: WorkloadIdentity{
+				Name:     "cloud",
+				Audience: []string{"cloud"},
+				TTL:      time.Hour,
+				Cloud:    &WorkloadIdentityCloud{Provider: "oracle"},
+			},
+			Err: `invalid cloud provider "oracle"`,
+		},
+		{
+			Desc: "Cloud missing field",
+			In: WorkloadIdentity{
+				Name:     "azure",
+				Audience: []string{"api://AzureADTokenExchange"},
+				TTL:      time.Hour,
+				Cloud:    &WorkloadIdentityCloud{Provider: "azure", TenantID: "tenant"},
+			},
+			Err: `cloud client_id must be set for provider "azure"`,
+		},
+		{
+			Desc: "Cloud field of other provider",
+			In: WorkloadIdentity{
+				Name:     "gcp",
+				Audience: []string{"gcp"},
+				TTL:      time.Hour,
+				Cloud: &WorkloadIdentityCloud{
+					Provider:                 "gcp",
+					WorkloadIdentityProvider: "projects/1/locations/global/workloadIdentityPools/nomad/providers/nomad",
+					RoleARN:                  "arn:aws:iam::123456789012:role/web",
+				},
+			},
+			Err: `cloud role_arn not supported for provider "gcp"`,
+		},
+		{
+			Desc: "Cloud without TTL",
+			In: WorkloadIdentity{
+				Name:     "aws",
+				Audience: []string{"sts.amazonaws.com"},
+				Cloud:    &WorkloadIdentityCloud{Provider: "aws", RoleARN: "arn:aws:iam::123456789012:role/web"},
+			},
+			Err: "ttl must be set when using cloud",
+		},
+		{
+			Desc: "Cloud without audience",
+			In: WorkloadIdentity{
+				Name:  "aws",
+				TTL:   time.Hour,
+				Cloud: &WorkloadIdentityCloud{Provider: "aws", RoleARN: "arn:aws:iam::123456789012:role/web"},
+			},
+			Err: "audience must be set when using cloud",
+		},
 	}
 
 	for _, tc := range cases {
@@ -272,6 +557,135 @@ func TestWorkloadIdentity_Validate(t *testing.T) {
 	}
 }
 
+func TestWorkloadIdentity_InterpolateAudience(t *testing.T) {
+	ci.Parallel(t)
+
+	job := MockJob()
+	job.Meta = map[string]string{"team": "web"}
+	alloc := &Allocation{
+		ID:        "c7e5a2a0-44f4-4ab4-9ea8-cfd3a4c6e1a4",
+		Namespace: "tenant-a",
+		JobID:     job.ID,
+		Name:      job.ID + ".web[2]",
+		TaskGroup: job.TaskGroups[0].Name,
+	}
+	task := job.TaskGroups[0].Tasks[0]
+	task.Meta = map[string]string{"tier": "backend"}
+
+	wid := &WorkloadIdentity{
+		Name: "vault_default",
+		Audience: []string{
+			"vault-${NOMAD_NAMESPACE}",
+			"${NOMAD_REGION}/${NOMAD_JOB_ID}/${NOMAD_GROUP_NAME}/${NOMAD_TASK_NAME}/${NOMAD_ALLOC_INDEX}",
+			"${NOMAD_META_team}-${NOMAD_META_tier}-${NOMAD_META_unknown}",
+			"static",
+		},
+	}
+	must.NoError(t, wid.Validate())
+
+	aud := wid.InterpolateAudience(job, alloc, &WIHandle{
+		WorkloadIdentifier: task.Name,
+		WorkloadType:       WorkloadTypeTask,
+	})
+	must.Eq(t, []string{
+		"vault-tenant-a",
+		"global/" + job.ID + "/web/web/2",
+		"web-backend-${NOMAD_META_unknown}",
+		"static",
+	}, aud)
+
+	// services don't have the task variables
+	aud = wid.InterpolateAudience(job, alloc, &WIHandle{
+		WorkloadIdentifier: "web-svc",
+		WorkloadType:       WorkloadTypeService,
+	})
+	must.Eq(t, "global/"+job.ID+"/web/${NOMAD_TASK_NAME}/2", aud[1])
+	must.Eq(t, "web-${NOMAD_META_tier}-${NOMAD_META_unknown}", aud[2])
+
+	// the audience isn't modified
+	must.Eq(t, "vault-${NOMAD_NAMESPACE}", wid.Audience[0])
+
+	wid.Audience = []string{"vault-${NOMAD_NODE_ID}"}
+	must.ErrorContains(t, wid.Validate(), `references unsupported variable "NOMAD_NODE_ID"`)
+}
+
+func TestWorkloadIdentity_ExtraClaims(t *testing.T) {
+	ci.Parallel(t)
+
+	job := MockJob()
+	job.Meta = map[string]string{"team": "web", "cost-center": "1234"}
+	task := job.TaskGroups[0].Tasks[0]
+	task.Meta = map[string]string{"team": "backend"}
+
+	wid := &WorkloadIdentity{
+		Name:     "aws",
+		Audience: []string{"sts.amazonaws.com"},
+		ExtraClaims: map[string]string{
+			"team":        "team",
+			"cost_center": "cost-center",
+			"owner":       "owner",
+		},
+	}
+	must.NoError(t, wid.Validate())
+
+	claims := wid.InterpolateExtraClaims(job, job.TaskGroups[0].Name, &WIHandle{
+		WorkloadIdentifier: task.Name,
+		WorkloadType:       WorkloadTypeTask,
+	})
+	must.Eq(t, map[string]string{"team": "backend", "cost_center": "1234"}, claims)
+
+	// services don't have the task meta
+	claims = wid.InterpolateExtraClaims(job, job.TaskGroups[0].Name, &WIHandle{
+		WorkloadIdentifier: "web-svc",
+		WorkloadType:       WorkloadTypeService,
+	})
+	must.Eq(t, "web", claims["team"])
+
+	wid.ExtraClaims = map[string]string{"sub": "team", "nomad_team": "team", "team": ""}
+	err := wid.Validate()
+	must.ErrorContains(t, err, `extra claim "sub" is reserved`)
+	must.ErrorContains(t, err, `extra claim "nomad_team" is reserved`)
+	must.ErrorContains(t, err, `extra claim "team" must be sourced from a meta key`)
+
+	wid = &WorkloadIdentity{Name: WorkloadIdentityDefaultName, ExtraClaims: map[string]string{"team": "team"}}
+	must.ErrorContains(t, wid.Validate(), "extra_claims for default identity not supported")
+}
+
+func TestIdentityClaims_ExtraClaimsJSON(t *testing.T) {
+	ci.Parallel(t)
+
+	claims := &IdentityClaims{
+		Namespace:   "default",
+		JobID:       "example",
+		TaskName:    "web",
+		ExtraClaims: map[string]string{"team": "web", "nomad_job_id": "ignored"},
+	}
+	claims.Subject = "global:default:example:web:web:aws"
+
+	buf, err := json.Marshal(claims)
+	must.NoError(t, err)
+
+	var raw map[string]any
+	must.NoError(t, json.Unmarshal(buf, &raw))
+	must.Eq(t, "web", raw["team"])
+	must.Eq(t, "example", raw["nomad_job_id"])
+	must.Eq(t, "global:default:example:web:web:aws", raw["sub"])
+
+	var out IdentityClaims
+	must.NoError(t, json.Unmarshal(buf, &out))
+	must.Eq(t, map[string]string{"team": "web"}, out.ExtraClaims)
+	must.Eq(t, "example", out.JobID)
+	must.Eq(t, claims.Subject, out.Subject)
+
+	// Claims without extra claims are unchanged
+	claims.ExtraClaims = nil
+	buf, err = json.Marshal(claims)
+	must.NoError(t, err)
+	out = IdentityClaims{}
+	must.NoError(t, json.Unmarshal(buf, &out))
+	must.Nil(t, out.ExtraClaims)
+}
+
 func TestWorkloadIdentity_Nil(t *testing.T) {
 	ci.Parallel(t)
 
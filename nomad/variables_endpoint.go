@@ -5,6 +5,7 @@ package nomad
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -96,7 +97,8 @@ func (sv *Variables) Apply(args *structs.VariablesApplyRequest, reply *structs.V
 	if err != nil {
 		return err
 	}
-	err = hasOperationPermissions(aclObj, args.Var.Namespace, args.Var.Path, args.Op)
+	claim := sv.workloadClaim(args)
+	err = hasOperationPermissions(aclObj, args.Var.Namespace, args.Var.Path, args.Op, claim)
 	if err != nil {
 		return err
 	}
@@ -145,7 +147,7 @@ func (sv *Variables) Apply(args *structs.VariablesApplyRequest, reply *structs.V
 	out, _ := o.(*structs.VarApplyStateResponse)
 
 	// The return value depends on the operation results and the callers permissions
-	r, err := sv.makeVariablesApplyResponse(args, out, aclObj)
+	r, err := sv.makeVariablesApplyResponse(args, out, aclObj, claim)
 	if err != nil {
 		return err
 	}
@@ -154,6 +156,8 @@ func (sv *Variables) Apply(args *structs.VariablesApplyRequest, reply *structs.V
 	reply.Index = index
 
 	if out.IsOk() {
+		sv.srv.accessAuditor.variable(string(args.Op), ev.Namespace, ev.Path, ev.KeyID, args.GetIdentity())
+
 		switch args.Op {
 		case structs.VarOpLockAcquire:
 			sv.timers.CreateVariableLockTTLTimer(ev.Copy())
@@ -165,16 +169,16 @@ func (sv *Variables) Apply(args *structs.VariablesApplyRequest, reply *structs.V
 	return nil
 }
 
-func hasReadPermission(aclObj *acl.ACL, namespace, path string) bool {
+func hasReadPermission(aclObj *acl.ACL, namespace, path string, claim *acl.ACLClaim) bool {
 	return aclObj.AllowVariableOperation(namespace,
-		path, acl.VariablesCapabilityRead, nil)
+		path, acl.VariablesCapabilityRead, claim)
 }
 
-func hasOperationPermissions(aclObj *acl.ACL, namespace, path string, op structs.VarOp) error {
+func hasOperationPermissions(aclObj *acl.ACL, namespace, path string, op structs.VarOp, claim *acl.ACLClaim) error {
 
 	hasPerm := func(perm string) bool {
 		return aclObj.AllowVariableOperation(namespace,
-			path, perm, nil)
+			path, perm, claim)
 	}
 
 	switch op {
@@ -210,6 +214,11 @@ func canonicalizeAndValidate(args *structs.VariablesApplyRequest) error {
 		if err != nil {
 			return structs.NewErrRPCCoded(http.StatusBadRequest, err.Error())
 		}
+
+		err = bindLockSession(args)
+		if err != nil {
+			return structs.NewErrRPCCoded(http.StatusBadRequest, err.Error())
+		}
 		return nil
 
 	case structs.VarOpSet, structs.VarOpCAS:
@@ -250,11 +259,48 @@ func canonicalizeAndValidate(args *structs.VariablesApplyRequest) error {
 	return nil
 }
 
+// bindLockSession ties the lock of a lock acquire request to the identity of
+// the caller when the lock asks for a session. The holder of the session is
+// always set by the servers, never by the caller.
+func bindLockSession(args *structs.VariablesApplyRequest) error {
+	lock := args.Var.Lock
+	lock.AllocID = ""
+	lock.AccessorID = ""
+
+	switch lock.Session {
+	case structs.VariableLockSessionAlloc:
+		claims := args.GetIdentity().GetClaims()
+		if claims == nil {
+			return errors.New("alloc lock sessions require a workload identity")
+		}
+		lock.AllocID = claims.AllocationID
+
+	case structs.VariableLockSessionToken:
+		token := args.GetIdentity().GetACLToken()
+		if token == nil || token == structs.AnonymousACLToken {
+			return errors.New("token lock sessions require an ACL token")
+		}
+		lock.AccessorID = token.AccessorID
+	}
+	return nil
+}
+
+// workloadClaim returns the ACL claim of the workload identity of the
+// request, if any. The claim lets workloads write the variables of their job
+// when the workload policy of the servers allows it.
+func (sv *Variables) workloadClaim(args structs.RequestWithIdentity) *acl.ACLClaim {
+	claim := auth.IdentityToACLClaim(args.GetIdentity(), sv.srv.State())
+	if claim != nil {
+		claim.WriteVariables = sv.srv.config.ACLWorkloadPolicy.AllowVariablesWrite()
+	}
+	return claim
+}
+
 // MakeVariablesApplyResponse merges the output of this VarApplyStateResponse with the
 // VariableDataItems
 func (sv *Variables) makeVariablesApplyResponse(
 	req *structs.VariablesApplyRequest, eResp *structs.VarApplyStateResponse,
-	aclObj *acl.ACL) (*structs.VariablesApplyResponse, error) {
+	aclObj *acl.ACL, claim *acl.ACLClaim) (*structs.VariablesApplyResponse, error) {
 
 	out := structs.VariablesApplyResponse{
 		Op:        eResp.Op,
@@ -266,7 +312,7 @@ func (sv *Variables) makeVariablesApplyResponse(
 
 	// The read permission modify the way the response is populated. If ACL is not
 	// used, read permission is granted by default and every call is treated as management.
-	canRead := hasReadPermission(aclObj, req.Var.Namespace, req.Var.Path)
+	canRead := hasReadPermission(aclObj, req.Var.Namespace, req.Var.Path, claim)
 	isManagement := aclObj.IsManagement()
 
 	if eResp.IsOk() {
@@ -349,6 +395,7 @@ func (sv *Variables) Read(args *structs.VariablesReadRequest, reply *structs.Var
 	}
 
 	// Setup the blocking query
+	var keyID string
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
 		queryMeta: &reply.QueryMeta,
@@ -361,6 +408,7 @@ func (sv *Variables) Read(args *structs.VariablesReadRequest, reply *structs.Var
 			// Setup the output
 			reply.Data = nil
 			if out != nil {
+				keyID = out.KeyID
 
 				dv, err := sv.decrypt(out)
 				if err != nil {
@@ -379,7 +427,17 @@ func (sv *Variables) Read(args *structs.VariablesReadRequest, reply *structs.Var
 			}
 			return nil
 		}}
-	return sv.srv.blockingRPC(&opts)
+	if err := sv.srv.blockingRPC(&opts); err != nil {
+		return err
+	}
+
+	// Only the read of the returned variable is recorded, not every run of
+	// the blocking query
+	if reply.Data != nil {
+		sv.srv.accessAuditor.variable(structs.AccessAuditOpRead,
+			reply.Data.Namespace, reply.Data.Path, keyID, args.GetIdentity())
+	}
+	return nil
 }
 
 // List is used to list variables held within state. It supports single
@@ -626,7 +684,7 @@ func (sv *Variables) RenewLock(args *structs.VariablesRenewLockRequest, reply *s
 		return err
 	}
 	if !aclObj.AllowVariableOperation(args.WriteRequest.Namespace, args.Path,
-		acl.VariablesCapabilityWrite, nil) {
+		acl.VariablesCapabilityWrite, sv.workloadClaim(args)) {
 		return structs.ErrPermissionDenied
 	}
 
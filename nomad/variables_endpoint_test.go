@@ -832,6 +832,142 @@ func TestVariablesEndpoint_auth(t *testing.T) {
 	}
 }
 
+func TestVariablesEndpoint_Apply_WorkloadPolicy(t *testing.T) {
+	ci.Parallel(t)
+
+	testFn := func(t *testing.T, policy *acl.WorkloadPolicy, path string) error {
+		srv, _, shutdown := TestACLServer(t, func(c *Config) {
+			c.NumSchedulers = 0 // Prevent automatic dequeue
+			c.ACLWorkloadPolicy = policy
+		})
+		defer shutdown()
+		testutil.WaitForKeyring(t, srv.RPC, "global")
+		codec := rpcClient(t, srv)
+
+		alloc := mock.Alloc()
+		alloc.ClientStatus = structs.AllocClientStatusRunning
+		must.NoError(t, srv.fsm.State().UpsertAllocs(
+			structs.MsgTypeTestSetup, 1000, []*structs.Allocation{alloc}))
+
+		wiHandle := &structs.WIHandle{
+			WorkloadIdentifier: "web",
+			WorkloadType:       structs.WorkloadTypeTask,
+		}
+		claims := structs.NewIdentityClaims(alloc.Job, alloc, wiHandle, alloc.LookupTask("web").Identity, time.Now())
+		idToken, _, err := srv.encrypter.SignClaims(claims)
+		must.NoError(t, err)
+
+		sv := mock.Variable()
+		sv.Namespace = alloc.Namespace
+		sv.Path = fmt.Sprintf(path, alloc.JobID)
+		req := &structs.VariablesApplyRequest{
+			Op:  structs.VarOpSet,
+			Var: sv,
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: alloc.Namespace,
+				AuthToken: idToken,
+			},
+		}
+		var resp structs.VariablesApplyResponse
+		return msgpackrpc.CallWithCodec(codec, structs.VariablesApplyRPCMethod, req, &resp)
+	}
+
+	t.Run("default policy writes own job", func(t *testing.T) {
+		must.NoError(t, testFn(t, acl.DefaultWorkloadPolicy(), "nomad/jobs/%s/web/web"))
+	})
+	t.Run("default policy can't write other job", func(t *testing.T) {
+		err := testFn(t, acl.DefaultWorkloadPolicy(), "nomad/jobs/other-%s")
+		must.EqError(t, err, structs.ErrPermissionDenied.Error())
+	})
+	t.Run("read policy can't write own job", func(t *testing.T) {
+		err := testFn(t, &acl.WorkloadPolicy{Variables: acl.PolicyRead}, "nomad/jobs/%s")
+		must.EqError(t, err, structs.ErrPermissionDenied.Error())
+	})
+}
+
+func TestVariablesEndpoint_Apply_LockSession(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, rootToken, shutdown := TestACLServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer shutdown()
+	testutil.WaitForKeyring(t, srv.RPC, "global")
+	codec := rpcClient(t, srv)
+
+	alloc := mock.Alloc()
+	alloc.ClientStatus = structs.AllocClientStatusRunning
+	must.NoError(t, srv.fsm.State().UpsertAllocs(
+		structs.MsgTypeTestSetup, 1000, []*structs.Allocation{alloc}))
+
+	wiHandle := &structs.WIHandle{
+		WorkloadIdentifier: "web",
+		WorkloadType:       structs.WorkloadTypeTask,
+	}
+	claims := structs.NewIdentityClaims(alloc.Job, alloc, wiHandle, alloc.LookupTask("web").Identity, time.Now())
+	idToken, _, err := srv.encrypter.SignClaims(claims)
+	must.NoError(t, err)
+
+	acquire := func(session, authToken, path string) (*structs.VariableLock, error) {
+		sv := mock.VariableEncrypted()
+		sv.Namespace = alloc.Namespace
+		sv.Path = fmt.Sprintf(path, alloc.JobID)
+		req := &structs.VariablesApplyRequest{
+			Op: structs.VarOpLockAcquire,
+			Var: &structs.VariableDecrypted{
+				VariableMetadata: sv.VariableMetadata,
+				Items:            structs.VariableItems{"k": "v"},
+			},
+			WriteRequest: structs.WriteRequest{
+				Region:    "global",
+				Namespace: alloc.Namespace,
+				AuthToken: authToken,
+			},
+		}
+		req.Var.Lock = &structs.VariableLock{
+			TTL:        10 * time.Second,
+			LockDelay:  time.Second,
+			Session:    session,
+			AllocID:    "spoofed",
+			AccessorID: "spoofed",
+		}
+		var resp structs.VariablesApplyResponse
+		err := msgpackrpc.CallWithCodec(codec, structs.VariablesApplyRPCMethod, req, &resp)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Output.Lock, nil
+	}
+
+	t.Run("alloc session binds the allocation", func(t *testing.T) {
+		lock, err := acquire(structs.VariableLockSessionAlloc, idToken, "nomad/jobs/%s")
+		must.NoError(t, err)
+		must.Eq(t, alloc.ID, lock.AllocID)
+		must.Eq(t, "", lock.AccessorID)
+	})
+	t.Run("alloc session requires a workload identity", func(t *testing.T) {
+		_, err := acquire(structs.VariableLockSessionAlloc, rootToken.SecretID, "nomad/jobs/%s/web")
+		must.ErrorContains(t, err, "alloc lock sessions require a workload identity")
+	})
+	t.Run("token session binds the token", func(t *testing.T) {
+		lock, err := acquire(structs.VariableLockSessionToken, rootToken.SecretID, "nomad/jobs/%s/web/web")
+		must.NoError(t, err)
+		must.Eq(t, rootToken.AccessorID, lock.AccessorID)
+		must.Eq(t, "", lock.AllocID)
+	})
+	t.Run("token session requires a token", func(t *testing.T) {
+		_, err := acquire(structs.VariableLockSessionToken, idToken, "nomad/jobs/%s/web")
+		must.ErrorContains(t, err, "token lock sessions require an ACL token")
+	})
+	t.Run("no session", func(t *testing.T) {
+		lock, err := acquire("", rootToken.SecretID, "locks/%s")
+		must.NoError(t, err)
+		must.Eq(t, "", lock.AllocID)
+		must.Eq(t, "", lock.AccessorID)
+	})
+}
+
 func TestVariablesEndpoint_ListFiltering(t *testing.T) {
 	ci.Parallel(t)
 	srv, _, shutdown := TestACLServer(t, func(c *Config) {
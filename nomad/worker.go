@@ -640,6 +640,19 @@ func (w *Worker) ServersMeetMinimumVersion(minVersion *version.Version, checkFai
 	return ServersMeetMinimumVersion(w.srv.Members(), w.srv.Region(), minVersion, checkFailedServers)
 }
 
+// ScoringPlugins returns the scoring plugins configured on the server that
+// apply to the job. This allows the worker to act as the planner for the
+// scheduler.
+func (w *Worker) ScoringPlugins(job *structs.Job) []scheduler.ScoringPlugin {
+	var plugins []scheduler.ScoringPlugin
+	for _, plugin := range w.srv.scoringPlugins {
+		if plugin.Applies(job) {
+			plugins = append(plugins, plugin)
+		}
+	}
+	return plugins
+}
+
 // SubmitPlan is used to submit a plan for consideration. This allows
 // the worker to act as the planner for the scheduler.
 func (w *Worker) SubmitPlan(plan *structs.Plan) (*structs.PlanResult, scheduler.State, error) {
@@ -750,6 +763,34 @@ SUBMIT:
 	return nil
 }
 
+// UpdateEvalTrace is used to store the trace of an evaluation. This allows
+// the worker to act as the planner for the scheduler.
+func (w *Worker) UpdateEvalTrace(trace *structs.EvalTrace) error {
+	// Check for a shutdown before submission. Checking server state rather
+	// than worker state to allow a workers work in flight to complete before
+	// stopping.
+	if w.srv.IsShutdown() {
+		return fmt.Errorf("shutdown while planning")
+	}
+	defer metrics.MeasureSince([]string{"nomad", "worker", "update_eval_trace"}, time.Now())
+
+	req := structs.EvalTraceUpsertRequest{
+		Trace:     trace,
+		EvalToken: w.evalToken,
+		WriteRequest: structs.WriteRequest{
+			Region: w.srv.config.Region,
+		},
+	}
+	var resp structs.GenericResponse
+
+	// The trace is only informational so it is not resubmitted on failure.
+	if err := w.srv.RPC(structs.EvalUpsertTraceRPCMethod, &req, &resp); err != nil {
+		return err
+	}
+	w.logger.Debug("updated evaluation trace", "eval_id", trace.EvalID)
+	return nil
+}
+
 // CreateEval is used to create a new evaluation. This allows
 // the worker to act as the planner for the scheduler.
 func (w *Worker) CreateEval(eval *structs.Evaluation) error {
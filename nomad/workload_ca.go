@@ -0,0 +1,342 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+)
+
+const (
+	// workloadCertBackdate is how far in the past the workload certificates
+	// are valid from, to tolerate clock skew between servers and clients.
+	workloadCertBackdate = time.Minute
+
+	// builtinWorkloadCATTL is how long the CA certificates derived from the
+	// root keys of the keyring are valid. The root keys are rotated long
+	// before, and the CA certificates of the keys still in the keyring remain
+	// trusted.
+	builtinWorkloadCATTL = 10 * 365 * 24 * time.Hour
+
+	// defaultServiceCertTTL is the TTL of service certificates which don't
+	// set one.
+	defaultServiceCertTTL = 24 * time.Hour
+)
+
+// workloadCA signs the X.509 certificates of workload identities with the CA
+// configured on the servers.
+type workloadCA struct {
+	cert        *x509.Certificate
+	certPEM     []byte
+	key         crypto.Signer
+	trustDomain string
+
+	// encrypter is the keyring the builtin CA is derived from, in which case
+	// cert, certPEM and key are unset.
+	encrypter *Encrypter
+
+	// builtinCerts caches the CA certificates derived from the root keys by
+	// key ID.
+	builtinCerts     map[string]*x509.Certificate
+	builtinCertsLock sync.Mutex
+}
+
+// newWorkloadCA loads the workload CA from its configuration, or returns nil
+// if it isn't configured. The builtin CA is derived from the keyring of the
+// encrypter.
+func newWorkloadCA(conf *config.WorkloadCAConfig, encrypter *Encrypter) (*workloadCA, error) {
+	if conf == nil {
+		return nil, nil
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	trustDomain := conf.TrustDomain
+	if trustDomain == "" {
+		trustDomain = structs.SPIFFEDefaultTrustDomain
+	}
+
+	if conf.Builtin {
+		return &workloadCA{
+			trustDomain:  trustDomain,
+			encrypter:    encrypter,
+			builtinCerts: make(map[string]*x509.Certificate),
+		}, nil
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, errors.New("certificate is not a CA certificate")
+	}
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+
+	var certPEM []byte
+	for _, der := range keyPair.Certificate {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	return &workloadCA{
+		cert:        cert,
+		certPEM:     certPEM,
+		key:         key,
+		trustDomain: trustDomain,
+	}, nil
+}
+
+// spiffeTrustDomain returns the SPIFFE trust domain of the workload
+// identities, which is the one of the workload CA if it's configured.
+func (s *Server) spiffeTrustDomain() string {
+	if s.workloadCA != nil {
+		return s.workloadCA.trustDomain
+	}
+	return structs.SPIFFEDefaultTrustDomain
+}
+
+// signTask returns the PEM encoded certificate for the PEM encoded
+// certificate signing request of the task of the allocation, along with the
+// PEM encoded certificates of the CA. The certificate has the SPIFFE ID of the
+// task.
+func (ca *workloadCA) signTask(csrPEM []byte, alloc *structs.Allocation, taskName string, ttl time.Duration, now time.Time) ([]byte, []byte, error) {
+	template := &x509.Certificate{
+		Subject: pkix.Name{CommonName: alloc.ID},
+		URIs:    []*url.URL{structs.SPIFFEID(ca.trustDomain, alloc, taskName)},
+	}
+	return ca.sign(csrPEM, template, ttl, now)
+}
+
+// signService returns the PEM encoded certificate for the PEM encoded
+// certificate signing request of the service of the allocation, along with
+// the PEM encoded certificates of the CA. The certificate has the service name
+// and the tags of the service which are valid DNS labels prefixed to its name
+// as DNS names, and the addresses of the allocation as IP addresses.
+func (ca *workloadCA) signService(csrPEM []byte, alloc *structs.Allocation, service *structs.Service, now time.Time) ([]byte, []byte, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: service.Name},
+		DNSNames:    serviceCertDNSNames(service),
+		IPAddresses: allocIPAddresses(alloc),
+	}
+
+	ttl := service.Certificate.TTL
+	if ttl == 0 {
+		ttl = defaultServiceCertTTL
+	}
+	return ca.sign(csrPEM, template, ttl, now)
+}
+
+// sign completes the template of the certificate for the PEM encoded
+// certificate signing request and signs it. The certificate expires after the
+// TTL or with the CA, whichever comes first.
+func (ca *workloadCA) sign(csrPEM []byte, template *x509.Certificate, ttl time.Duration, now time.Time) ([]byte, []byte, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, nil, errors.New("invalid certificate signing request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate signing request: %w", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate signing request signature: %w", err)
+	}
+
+	caCert, caKey, caPEM, err := ca.signer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notAfter := now.Add(ttl)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+
+	template.SerialNumber = serial
+	template.NotBefore = now.Add(-workloadCertBackdate)
+	template.NotAfter = notAfter
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	template.BasicConstraintsValid = true
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, csr.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), caPEM, nil
+}
+
+// signer returns the certificate and the key signing the workload
+// certificates, along with the PEM encoded certificates of the CA trusted by
+// the workloads. The builtin CA signs with the active root key, and is
+// trusted with the CA certificates of every root key of the keyring, so the
+// certificates signed before a rotation remain valid.
+func (ca *workloadCA) signer() (*x509.Certificate, crypto.Signer, []byte, error) {
+	if ca.encrypter == nil {
+		return ca.cert, ca.key, ca.certPEM, nil
+	}
+
+	active, err := ca.encrypter.activeKeySet()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if active.rsaPrivateKey == nil {
+		return nil, nil, nil, fmt.Errorf("active root key %s has no RSA key for the builtin workload CA, the keyring must be rotated",
+			active.rootKey.Meta.KeyID)
+	}
+	cert, err := ca.builtinCert(active)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ca.encrypter.lock.RLock()
+	keysets := make([]*keyset, 0, len(ca.encrypter.keyring))
+	for _, ks := range ca.encrypter.keyring {
+		if ks.rsaPrivateKey != nil {
+			keysets = append(keysets, ks)
+		}
+	}
+	ca.encrypter.lock.RUnlock()
+
+	sort.Slice(keysets, func(i, j int) bool {
+		return keysets[i].rootKey.Meta.CreateTime < keysets[j].rootKey.Meta.CreateTime
+	})
+
+	var certPEM []byte
+	for _, ks := range keysets {
+		caCert, err := ca.builtinCert(ks)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})...)
+	}
+	return cert, active.rsaPrivateKey, certPEM, nil
+}
+
+// builtinCert returns the self-signed CA certificate of the builtin CA for
+// the RSA key of the root key. The certificate only depends on the root key,
+// so every server derives the same certificate.
+func (ca *workloadCA) builtinCert(ks *keyset) (*x509.Certificate, error) {
+	ca.builtinCertsLock.Lock()
+	defer ca.builtinCertsLock.Unlock()
+
+	keyID := ks.rootKey.Meta.KeyID
+	if cert, ok := ca.builtinCerts[keyID]; ok {
+		return cert, nil
+	}
+
+	serial, ok := new(big.Int).SetString(strings.ReplaceAll(keyID, "-", ""), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid root key ID %q", keyID)
+	}
+
+	created := time.Unix(0, ks.rootKey.Meta.CreateTime).UTC()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Nomad Workload CA " + keyID},
+		NotBefore:             created.Add(-workloadCertBackdate),
+		NotAfter:              created.Add(builtinWorkloadCATTL),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &ks.rsaPrivateKey.PublicKey, ks.rsaPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create builtin workload CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	ca.builtinCerts[keyID] = cert
+	return cert, nil
+}
+
+// serviceCertDNSNames returns the DNS names of the certificate of the
+// service, which are its name followed by its tags prefixed to its name.
+// Interpolated tags and tags which aren't valid DNS labels are ignored.
+func serviceCertDNSNames(service *structs.Service) []string {
+	names := []string{service.Name}
+	for _, tag := range service.Tags {
+		if strings.Contains(tag, "${") || service.ValidateName(tag) != nil {
+			continue
+		}
+		name := tag + "." + service.Name
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// allocIPAddresses returns the IP addresses of the networks and ports
+// allocated to the allocation.
+func allocIPAddresses(alloc *structs.Allocation) []net.IP {
+	var ips []net.IP
+	add := func(addr string) {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return
+		}
+		for _, existing := range ips {
+			if existing.Equal(ip) {
+				return
+			}
+		}
+		ips = append(ips, ip)
+	}
+
+	resources := alloc.AllocatedResources
+	if resources == nil {
+		return nil
+	}
+	for _, network := range resources.Shared.Networks {
+		add(network.IP)
+	}
+	for _, port := range resources.Shared.Ports {
+		add(port.HostIP)
+	}
+
+	taskNames := make([]string, 0, len(resources.Tasks))
+	for name := range resources.Tasks {
+		taskNames = append(taskNames, name)
+	}
+	sort.Strings(taskNames)
+	for _, name := range taskNames {
+		for _, network := range resources.Tasks[name].Networks {
+			add(network.IP)
+		}
+	}
+	return ips
+}
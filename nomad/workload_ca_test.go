@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package nomad
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/shoenig/test/must"
+)
+
+// testWorkloadCAConfig writes a self-signed CA expiring after the TTL to a
+// temporary directory, and returns the workload CA configuration using it.
+func testWorkloadCAConfig(t *testing.T, isCA bool, ttl time.Duration) *config.WorkloadCAConfig {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	must.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "workload CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(ttl),
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	must.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	must.NoError(t, err)
+
+	dir := t.TempDir()
+	conf := &config.WorkloadCAConfig{
+		CertFile: filepath.Join(dir, "ca.pem"),
+		KeyFile:  filepath.Join(dir, "ca-key.pem"),
+	}
+	must.NoError(t, os.WriteFile(conf.CertFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	must.NoError(t, os.WriteFile(conf.KeyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+	return conf
+}
+
+// testWorkloadCSR returns a PEM encoded certificate signing request.
+func testWorkloadCSR(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	must.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	must.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestWorkloadCA_New(t *testing.T) {
+	ci.Parallel(t)
+
+	ca, err := newWorkloadCA(nil, nil)
+	must.NoError(t, err)
+	must.Nil(t, ca)
+
+	_, err = newWorkloadCA(&config.WorkloadCAConfig{CertFile: "ca.pem"}, nil)
+	must.ErrorContains(t, err, "cert_file and key_file must both be set")
+
+	_, err = newWorkloadCA(&config.WorkloadCAConfig{Builtin: true, CertFile: "ca.pem"}, nil)
+	must.ErrorContains(t, err, "can't be set with builtin")
+
+	_, err = newWorkloadCA(testWorkloadCAConfig(t, false, time.Hour), nil)
+	must.ErrorContains(t, err, "not a CA certificate")
+
+	conf := testWorkloadCAConfig(t, true, time.Hour)
+	ca, err = newWorkloadCA(conf, nil)
+	must.NoError(t, err)
+	must.Eq(t, structs.SPIFFEDefaultTrustDomain, ca.trustDomain)
+
+	conf.TrustDomain = "example.com"
+	ca, err = newWorkloadCA(conf, nil)
+	must.NoError(t, err)
+	must.Eq(t, "example.com", ca.trustDomain)
+
+	ca, err = newWorkloadCA(&config.WorkloadCAConfig{Builtin: true}, nil)
+	must.NoError(t, err)
+	must.Nil(t, ca.cert)
+	must.Eq(t, structs.SPIFFEDefaultTrustDomain, ca.trustDomain)
+}
+
+func TestWorkloadCA_Sign(t *testing.T) {
+	ci.Parallel(t)
+
+	ca, err := newWorkloadCA(testWorkloadCAConfig(t, true, 2*time.Hour), nil)
+	must.NoError(t, err)
+
+	alloc := mock.Alloc()
+	now := time.Now().UTC()
+
+	certPEM, caPEM, err := ca.signTask(testWorkloadCSR(t), alloc, "web", time.Hour, now)
+	must.NoError(t, err)
+	must.Eq(t, ca.certPEM, caPEM)
+
+	block, _ := pem.Decode(certPEM)
+	must.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	must.NoError(t, err)
+
+	must.Eq(t, alloc.ID, cert.Subject.CommonName)
+	must.SliceLen(t, 1, cert.URIs)
+	must.Eq(t, "spiffe://nomad/ns/default/job/"+alloc.JobID+"/group/web/task/web", cert.URIs[0].String())
+	must.Eq(t, now.Add(time.Hour).Truncate(time.Second), cert.NotAfter.Truncate(time.Second))
+
+	// The certificate is trusted by the CA for both clients and servers.
+	roots := x509.NewCertPool()
+	must.True(t, roots.AppendCertsFromPEM(ca.certPEM))
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	})
+	must.NoError(t, err)
+
+	// Certificates don't outlive the CA.
+	certPEM, _, err = ca.signTask(testWorkloadCSR(t), alloc, "web", 24*time.Hour, now)
+	must.NoError(t, err)
+	block, _ = pem.Decode(certPEM)
+	cert, err = x509.ParseCertificate(block.Bytes)
+	must.NoError(t, err)
+	must.Eq(t, ca.cert.NotAfter, cert.NotAfter)
+
+	_, _, err = ca.signTask([]byte("not a csr"), alloc, "web", time.Hour, now)
+	must.ErrorContains(t, err, "invalid certificate signing request")
+}
+
+func TestWorkloadCA_SignService(t *testing.T) {
+	ci.Parallel(t)
+
+	ca, err := newWorkloadCA(testWorkloadCAConfig(t, true, 48*time.Hour), nil)
+	must.NoError(t, err)
+
+	alloc := mock.Alloc()
+	service := &structs.Service{
+		Name:        "api",
+		Provider:    structs.ServiceProviderNomad,
+		Tags:        []string{"v1", "${NOMAD_ALLOC_INDEX}", "not a label", "v1"},
+		Certificate: &structs.ServiceCertificate{},
+	}
+	now := time.Now().UTC()
+
+	certPEM, _, err := ca.signService(testWorkloadCSR(t), alloc, service, now)
+	must.NoError(t, err)
+
+	block, _ := pem.Decode(certPEM)
+	must.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	must.NoError(t, err)
+
+	must.Eq(t, "api", cert.Subject.CommonName)
+	must.Eq(t, []string{"api", "v1.api"}, cert.DNSNames)
+	must.SliceLen(t, 1, cert.IPAddresses)
+	must.Eq(t, "192.168.0.100", cert.IPAddresses[0].String())
+	must.SliceEmpty(t, cert.URIs)
+	must.Eq(t, now.Add(defaultServiceCertTTL).Truncate(time.Second), cert.NotAfter.Truncate(time.Second))
+
+	roots := x509.NewCertPool()
+	must.True(t, roots.AppendCertsFromPEM(ca.certPEM))
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "v1.api"})
+	must.NoError(t, err)
+
+	service.Certificate.TTL = time.Hour
+	certPEM, _, err = ca.signService(testWorkloadCSR(t), alloc, service, now)
+	must.NoError(t, err)
+	block, _ = pem.Decode(certPEM)
+	cert, err = x509.ParseCertificate(block.Bytes)
+	must.NoError(t, err)
+	must.Eq(t, now.Add(time.Hour).Truncate(time.Second), cert.NotAfter.Truncate(time.Second))
+}
+
+func TestWorkloadCA_Builtin(t *testing.T) {
+	ci.Parallel(t)
+
+	srv, shutdown := TestServer(t, func(c *Config) {
+		c.NumSchedulers = 0
+		c.WorkloadCA = &config.WorkloadCAConfig{Builtin: true}
+	})
+	defer shutdown()
+	testutil.WaitForKeyring(t, srv.RPC, "global")
+
+	alloc := mock.Alloc()
+	now := time.Now().UTC()
+
+	parse := func(certPEM []byte) *x509.Certificate {
+		block, _ := pem.Decode(certPEM)
+		must.NotNil(t, block)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		must.NoError(t, err)
+		return cert
+	}
+
+	certPEM, caPEM, err := srv.workloadCA.signTask(testWorkloadCSR(t), alloc, "web", time.Hour, now)
+	must.NoError(t, err)
+	cert := parse(certPEM)
+
+	caCert := parse(caPEM)
+	must.True(t, caCert.IsCA)
+	must.StrHasPrefix(t, "Nomad Workload CA ", caCert.Subject.CommonName)
+
+	roots := x509.NewCertPool()
+	must.True(t, roots.AppendCertsFromPEM(caPEM))
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots})
+	must.NoError(t, err)
+
+	// After a rotation, certificates are signed with the new root key and the
+	// certificates signed with the previous one remain trusted.
+	var rotateResp structs.KeyringRotateRootKeyResponse
+	must.NoError(t, srv.RPC("Keyring.Rotate", &structs.KeyringRotateRootKeyRequest{
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}, &rotateResp))
+
+	rotatedPEM, bundlePEM, err := srv.workloadCA.signTask(testWorkloadCSR(t), alloc, "web", time.Hour, now)
+	must.NoError(t, err)
+	rotated := parse(rotatedPEM)
+	must.Eq(t, "Nomad Workload CA "+rotateResp.Key.KeyID, rotated.Issuer.CommonName)
+
+	roots = x509.NewCertPool()
+	must.True(t, roots.AppendCertsFromPEM(bundlePEM))
+	for _, c := range []*x509.Certificate{cert, rotated} {
+		_, err = c.Verify(x509.VerifyOptions{Roots: roots})
+		must.NoError(t, err)
+	}
+}
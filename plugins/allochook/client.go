@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package allochook provides the client of the external gRPC services that
+// take part in the lifecycle of the allocations run by a client.
+package allochook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/helper/pluginutils/grpcutils"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/plugins/allochook/proto"
+)
+
+// Client is the client of an alloc hook plugin.
+type Client struct {
+	name     string
+	priority int
+	requires []string
+	timeout  time.Duration
+
+	conn   *grpc.ClientConn
+	client proto.AllocHookPluginClient
+}
+
+// NewClient returns a client of the alloc hook plugin. The connection to the
+// plugin is established in the background, so the plugin doesn't need to be
+// running when the client is created.
+func NewClient(conf *config.AllocHookPluginConfig) (*Client, error) {
+	if conf.Name == "" {
+		return nil, errors.New("alloc hook plugin name must not be empty")
+	}
+	if conf.Address == "" {
+		return nil, fmt.Errorf("alloc hook plugin %q: address must not be empty", conf.Name)
+	}
+	if conf.Priority < 0 {
+		return nil, fmt.Errorf("alloc hook plugin %q: priority must not be negative", conf.Name)
+	}
+
+	creds, err := grpcutils.TransportCredentials(conf.CAFile, conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("alloc hook plugin %q: %w", conf.Name, err)
+	}
+
+	conn, err := grpc.Dial(conf.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("alloc hook plugin %q: failed to create connection: %w", conf.Name, err)
+	}
+
+	priority := conf.Priority
+	if priority == 0 {
+		priority = interfaces.AllocHookPriorityDefault
+	}
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultAllocHookPluginTimeout
+	}
+
+	return &Client{
+		name:     conf.Name,
+		priority: priority,
+		requires: slices.Clone(conf.Requires),
+		timeout:  timeout,
+		conn:     conn,
+		client:   proto.NewAllocHookPluginClient(conn),
+	}, nil
+}
+
+// Name returns the name of the plugin.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// NewHook returns the alloc runner hook of the plugin for an allocation. The
+// network status is used to pass the address of the allocation to the
+// plugin, and may be nil.
+func (c *Client) NewHook(alloc *structs.Allocation, allocDir string, ns structs.NetworkStatus) interfaces.RunnerHook {
+	h := &hook{
+		client:        c,
+		alloc:         alloc,
+		allocDir:      allocDir,
+		networkStatus: ns,
+	}
+
+	// Only hooks with requirements declare their dependencies, so the other
+	// hooks keep running after all the hooks before them.
+	if len(c.requires) != 0 {
+		return &dependentHook{h}
+	}
+	return h
+}
+
+// Close closes the connection to the plugin.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// hook calls the plugin in the Prerun and Postrun phases of an allocation.
+type hook struct {
+	client        *Client
+	allocDir      string
+	networkStatus structs.NetworkStatus
+
+	// alloc is the latest version of the allocation, updated concurrently
+	// with the other phases.
+	alloc     *structs.Allocation
+	allocLock sync.Mutex
+}
+
+func (h *hook) Name() string {
+	return "alloc_hook_plugin_" + h.client.name
+}
+
+func (h *hook) Priority() int {
+	return h.client.priority
+}
+
+// dependentHook is the hook of a plugin configured with the hooks it
+// requires.
+type dependentHook struct {
+	*hook
+}
+
+func (h *dependentHook) Requires() []string {
+	return h.client.requires
+}
+
+func (h *hook) Prerun() error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.client.timeout)
+	defer cancel()
+
+	_, err := h.client.client.Prerun(ctx, &proto.PrerunRequest{Allocation: h.allocation()})
+	if err != nil {
+		return fmt.Errorf("alloc hook plugin %q: %w", h.client.name, err)
+	}
+	return nil
+}
+
+func (h *hook) Postrun() error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.client.timeout)
+	defer cancel()
+
+	_, err := h.client.client.Postrun(ctx, &proto.PostrunRequest{Allocation: h.allocation()})
+	if err != nil {
+		return fmt.Errorf("alloc hook plugin %q: %w", h.client.name, err)
+	}
+	return nil
+}
+
+func (h *hook) Update(req *interfaces.RunnerUpdateRequest) error {
+	h.allocLock.Lock()
+	defer h.allocLock.Unlock()
+	h.alloc = req.Alloc
+	return nil
+}
+
+// allocation returns the allocation passed to the plugin.
+func (h *hook) allocation() *proto.Allocation {
+	h.allocLock.Lock()
+	alloc := h.alloc
+	h.allocLock.Unlock()
+
+	pa := &proto.Allocation{
+		Id:        alloc.ID,
+		Name:      alloc.Name,
+		Namespace: alloc.Namespace,
+		JobId:     alloc.JobID,
+		TaskGroup: alloc.TaskGroup,
+		NodeId:    alloc.NodeID,
+		Meta:      map[string]string{},
+		AllocDir:  h.allocDir,
+		Ports:     map[string]int32{},
+	}
+
+	if alloc.Job != nil {
+		maps.Copy(pa.Meta, alloc.Job.Meta)
+		if tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup); tg != nil {
+			maps.Copy(pa.Meta, tg.Meta)
+		}
+	}
+	if alloc.AllocatedResources != nil {
+		for _, port := range alloc.AllocatedResources.Shared.Ports {
+			pa.Ports[port.Label] = int32(port.Value)
+		}
+	}
+	if h.networkStatus != nil {
+		if status := h.networkStatus.NetworkStatus(); status != nil {
+			pa.Address = status.Address
+		}
+	}
+	return pa
+}
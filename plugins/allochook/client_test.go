@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package allochook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/plugins/allochook/proto"
+	"github.com/shoenig/test/must"
+	"google.golang.org/grpc"
+)
+
+// testServer is an alloc hook plugin recording the allocations it's called
+// with.
+type testServer struct {
+	proto.UnimplementedAllocHookPluginServer
+
+	l        sync.Mutex
+	prerun   []*proto.Allocation
+	postrun  []*proto.Allocation
+	failures int
+}
+
+func (s *testServer) Prerun(_ context.Context, req *proto.PrerunRequest) (*proto.PrerunResponse, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return nil, errors.New("license unavailable")
+	}
+	s.prerun = append(s.prerun, req.Allocation)
+	return &proto.PrerunResponse{}, nil
+}
+
+func (s *testServer) Postrun(_ context.Context, req *proto.PostrunRequest) (*proto.PostrunResponse, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.postrun = append(s.postrun, req.Allocation)
+	return &proto.PostrunResponse{}, nil
+}
+
+// testNetworkStatus is a static network status.
+type testNetworkStatus string
+
+func (s testNetworkStatus) NetworkStatus() *structs.AllocNetworkStatus {
+	return &structs.AllocNetworkStatus{Address: string(s)}
+}
+
+func testClient(t *testing.T, srv *testServer, conf *config.AllocHookPluginConfig) *Client {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	must.NoError(t, err)
+
+	s := grpc.NewServer()
+	proto.RegisterAllocHookPluginServer(s, srv)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	conf.Name = "test"
+	conf.Address = l.Addr().String()
+	c, err := NewClient(conf)
+	must.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_Hook(t *testing.T) {
+	ci.Parallel(t)
+
+	srv := &testServer{}
+	c := testClient(t, srv, &config.AllocHookPluginConfig{Timeout: 5 * time.Second})
+
+	alloc := mock.Alloc()
+	alloc.Job.Meta = map[string]string{"owner": "platform", "team": "infra"}
+	alloc.Job.TaskGroups[0].Meta = map[string]string{"team": "web"}
+	alloc.AllocatedResources.Shared.Ports = structs.AllocatedPorts{
+		{Label: "http", Value: 25000},
+	}
+	h := c.NewHook(alloc, "/var/nomad/alloc/"+alloc.ID, testNetworkStatus("172.26.64.10"))
+	must.Eq(t, "alloc_hook_plugin_test", h.Name())
+	must.Eq(t, interfaces.AllocHookPriorityDefault, h.(interfaces.HookPriority).Priority())
+	_, ok := h.(interfaces.HookDependencies)
+	must.False(t, ok)
+
+	must.NoError(t, h.(interfaces.RunnerPrerunHook).Prerun())
+	must.SliceLen(t, 1, srv.prerun)
+	pa := srv.prerun[0]
+	must.Eq(t, alloc.ID, pa.Id)
+	must.Eq(t, alloc.Namespace, pa.Namespace)
+	must.Eq(t, alloc.JobID, pa.JobId)
+	must.Eq(t, alloc.TaskGroup, pa.TaskGroup)
+	must.Eq(t, alloc.NodeID, pa.NodeId)
+	must.Eq(t, "/var/nomad/alloc/"+alloc.ID, pa.AllocDir)
+	must.Eq(t, "172.26.64.10", pa.Address)
+	must.Eq(t, map[string]string{"owner": "platform", "team": "web"}, pa.Meta)
+	must.Eq(t, map[string]int32{"http": 25000}, pa.Ports)
+
+	// Postrun is called with the latest version of the allocation.
+	updated := alloc.Copy()
+	updated.Name = "renamed"
+	must.NoError(t, h.(interfaces.RunnerUpdateHook).Update(&interfaces.RunnerUpdateRequest{Alloc: updated}))
+	must.NoError(t, h.(interfaces.RunnerPostrunHook).Postrun())
+	must.SliceLen(t, 1, srv.postrun)
+	must.Eq(t, "renamed", srv.postrun[0].Name)
+}
+
+func TestClient_Hook_Error(t *testing.T) {
+	ci.Parallel(t)
+
+	srv := &testServer{failures: 1}
+	c := testClient(t, srv, &config.AllocHookPluginConfig{
+		Priority: interfaces.AllocHookPriorityNetwork + 50,
+		Requires: []string{"network"},
+		Timeout:  5 * time.Second,
+	})
+
+	h := c.NewHook(mock.Alloc(), "", nil)
+	must.Eq(t, interfaces.AllocHookPriorityNetwork+50, h.(interfaces.HookPriority).Priority())
+	must.Eq(t, []string{"network"}, h.(interfaces.HookDependencies).Requires())
+
+	err := h.(interfaces.RunnerPrerunHook).Prerun()
+	must.ErrorContains(t, err, `alloc hook plugin "test"`)
+	must.ErrorContains(t, err, "license unavailable")
+}
+
+func TestNewClient_Invalid(t *testing.T) {
+	ci.Parallel(t)
+
+	_, err := NewClient(&config.AllocHookPluginConfig{Address: "127.0.0.1:9000"})
+	must.EqError(t, err, "alloc hook plugin name must not be empty")
+
+	_, err = NewClient(&config.AllocHookPluginConfig{Name: "foo"})
+	must.EqError(t, err, `alloc hook plugin "foo": address must not be empty`)
+
+	_, err = NewClient(&config.AllocHookPluginConfig{Name: "foo", Address: "127.0.0.1:9000", Priority: -1})
+	must.EqError(t, err, `alloc hook plugin "foo": priority must not be negative`)
+}
@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package scoring provides the client of the external gRPC services that
+// adjust the score of nodes during placements.
+package scoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/hashicorp/nomad/helper/pluginutils/grpcutils"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/plugins/scoring/proto"
+)
+
+// Client is the client of a scoring plugin.
+type Client struct {
+	name     string
+	jobTypes []string
+	timeout  time.Duration
+
+	conn   *grpc.ClientConn
+	client proto.ScoringPluginClient
+}
+
+// NewClient returns a client of the scoring plugin. The connection to the
+// plugin is established in the background, so the plugin doesn't need to be
+// running when the client is created.
+func NewClient(conf *config.ScoringPluginConfig) (*Client, error) {
+	if conf.Name == "" {
+		return nil, errors.New("scoring plugin name must not be empty")
+	}
+	if conf.Address == "" {
+		return nil, fmt.Errorf("scoring plugin %q: address must not be empty", conf.Name)
+	}
+	for _, jobType := range conf.JobTypes {
+		switch jobType {
+		case structs.JobTypeService, structs.JobTypeBatch:
+		default:
+			return nil, fmt.Errorf("scoring plugin %q: unsupported job type %q", conf.Name, jobType)
+		}
+	}
+
+	creds, err := grpcutils.TransportCredentials(conf.CAFile, conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("scoring plugin %q: %w", conf.Name, err)
+	}
+
+	conn, err := grpc.Dial(conf.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("scoring plugin %q: failed to create connection: %w", conf.Name, err)
+	}
+
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultScoringPluginTimeout
+	}
+
+	return &Client{
+		name:     conf.Name,
+		jobTypes: slices.Clone(conf.JobTypes),
+		timeout:  timeout,
+		conn:     conn,
+		client:   proto.NewScoringPluginClient(conn),
+	}, nil
+}
+
+// Name returns the name of the plugin.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Applies returns true if the plugin scores the nodes for the job.
+func (c *Client) Applies(job *structs.Job) bool {
+	switch job.Type {
+	case structs.JobTypeService, structs.JobTypeBatch:
+	default:
+		return false
+	}
+	return len(c.jobTypes) == 0 || slices.Contains(c.jobTypes, job.Type)
+}
+
+// ScoreNode returns the score of the node for placing an allocation of the
+// task group, or false if the plugin leaves the node to the built-in
+// scoring.
+func (c *Client) ScoreNode(job *structs.Job, tg string, node *structs.Node) (float64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ScoreNode(ctx, &proto.ScoreNodeRequest{
+		Job: &proto.Job{
+			Namespace: job.Namespace,
+			Id:        job.ID,
+			Type:      job.Type,
+			Priority:  int32(job.Priority),
+			Meta:      job.Meta,
+		},
+		TaskGroup: tg,
+		Node: &proto.Node{
+			Id:         node.ID,
+			Name:       node.Name,
+			Datacenter: node.Datacenter,
+			NodeClass:  node.NodeClass,
+			NodePool:   node.NodePool,
+			Attributes: node.Attributes,
+			Meta:       node.Meta,
+		},
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if resp.Skip {
+		return 0, false, nil
+	}
+	if math.IsNaN(resp.Score) {
+		return 0, false, errors.New("plugin returned an invalid score")
+	}
+	return resp.Score, true, nil
+}
+
+// Close closes the connection to the plugin.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package scoring
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/plugins/scoring/proto"
+	"github.com/shoenig/test/must"
+	"google.golang.org/grpc"
+)
+
+// testServer is a scoring plugin preferring the nodes of a datacenter.
+type testServer struct {
+	proto.UnimplementedScoringPluginServer
+	delay time.Duration
+}
+
+func (s *testServer) ScoreNode(ctx context.Context, req *proto.ScoreNodeRequest) (*proto.ScoreNodeResponse, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	switch req.Node.Datacenter {
+	case "dc1":
+		return &proto.ScoreNodeResponse{Score: 1}, nil
+	case "dc2":
+		return &proto.ScoreNodeResponse{Score: -1}, nil
+	default:
+		return &proto.ScoreNodeResponse{Skip: true}, nil
+	}
+}
+
+func testClient(t *testing.T, srv *testServer, conf *config.ScoringPluginConfig) *Client {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	must.NoError(t, err)
+
+	s := grpc.NewServer()
+	proto.RegisterScoringPluginServer(s, srv)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	conf.Name = "test"
+	conf.Address = l.Addr().String()
+	c, err := NewClient(conf)
+	must.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_ScoreNode(t *testing.T) {
+	ci.Parallel(t)
+
+	c := testClient(t, &testServer{}, &config.ScoringPluginConfig{Timeout: 5 * time.Second})
+	job := mock.Job()
+	node := mock.Node()
+
+	node.Datacenter = "dc1"
+	score, ok, err := c.ScoreNode(job, "web", node)
+	must.NoError(t, err)
+	must.True(t, ok)
+	must.Eq(t, 1.0, score)
+
+	node.Datacenter = "dc2"
+	score, ok, err = c.ScoreNode(job, "web", node)
+	must.NoError(t, err)
+	must.True(t, ok)
+	must.Eq(t, -1.0, score)
+
+	node.Datacenter = "dc3"
+	_, ok, err = c.ScoreNode(job, "web", node)
+	must.NoError(t, err)
+	must.False(t, ok)
+}
+
+func TestClient_ScoreNode_Timeout(t *testing.T) {
+	ci.Parallel(t)
+
+	c := testClient(t, &testServer{delay: time.Second}, &config.ScoringPluginConfig{Timeout: 10 * time.Millisecond})
+
+	_, ok, err := c.ScoreNode(mock.Job(), "web", mock.Node())
+	must.Error(t, err)
+	must.False(t, ok)
+}
+
+func TestClient_Applies(t *testing.T) {
+	ci.Parallel(t)
+
+	c, err := NewClient(&config.ScoringPluginConfig{Name: "test", Address: "127.0.0.1:1"})
+	must.NoError(t, err)
+	defer c.Close()
+
+	job := mock.Job()
+	must.True(t, c.Applies(job))
+	job.Type = structs.JobTypeSystem
+	must.False(t, c.Applies(job))
+
+	c, err = NewClient(&config.ScoringPluginConfig{
+		Name:     "test",
+		Address:  "127.0.0.1:1",
+		JobTypes: []string{structs.JobTypeBatch},
+	})
+	must.NoError(t, err)
+	defer c.Close()
+
+	job.Type = structs.JobTypeService
+	must.False(t, c.Applies(job))
+	job.Type = structs.JobTypeBatch
+	must.True(t, c.Applies(job))
+
+	_, err = NewClient(&config.ScoringPluginConfig{Name: "test"})
+	must.ErrorContains(t, err, "address must not be empty")
+	_, err = NewClient(&config.ScoringPluginConfig{
+		Name:     "test",
+		Address:  "127.0.0.1:1",
+		JobTypes: []string{structs.JobTypeSystem},
+	})
+	must.ErrorContains(t, err, "unsupported job type")
+}
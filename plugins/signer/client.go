@@ -0,0 +1,187 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package signer provides the client of the external gRPC service that signs
+// workload identities in place of the keyring of the servers.
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hashicorp/nomad/helper/pluginutils/grpcutils"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/plugins/signer/proto"
+)
+
+// ErrRotateUnsupported is returned by Rotate if the plugin doesn't implement
+// key rotation.
+var ErrRotateUnsupported = errors.New("signer plugin doesn't support key rotation")
+
+// Client is the client of a signer plugin.
+type Client struct {
+	timeout time.Duration
+
+	conn   *grpc.ClientConn
+	client proto.SignerPluginClient
+}
+
+// NewClient returns a client of the signer plugin. The connection to the
+// plugin is established in the background, so the plugin doesn't need to be
+// running when the client is created.
+func NewClient(conf *config.SignerPluginConfig) (*Client, error) {
+	if conf.Address == "" {
+		return nil, errors.New("signer plugin address must not be empty")
+	}
+
+	// The plugin is trusted to sign identities, so its responses must not be
+	// tampered with on the network.
+	if !strings.HasPrefix(conf.Address, "unix://") && conf.CAFile == "" {
+		return nil, errors.New("signer plugin address must be a unix socket unless ca_file is set")
+	}
+
+	creds, err := grpcutils.TransportCredentials(conf.CAFile, conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("signer plugin: %w", err)
+	}
+
+	conn, err := grpc.Dial(conf.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("signer plugin: failed to create connection: %w", err)
+	}
+
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultSignerPluginTimeout
+	}
+
+	return &Client{
+		timeout: timeout,
+		conn:    conn,
+		client:  proto.NewSignerPluginClient(conn),
+	}, nil
+}
+
+// PublicKeys returns the public keys of the plugin and the ID of its active
+// key.
+func (c *Client) PublicKeys() ([]*structs.KeyringPublicKey, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.PublicKeys(ctx, &proto.PublicKeysRequest{})
+	if err != nil {
+		return nil, "", fmt.Errorf("signer plugin: %w", err)
+	}
+
+	pubKeys := make([]*structs.KeyringPublicKey, 0, len(resp.PublicKeys))
+	for _, pk := range resp.PublicKeys {
+		pubKey, err := convertPublicKey(pk)
+		if err != nil {
+			return nil, "", fmt.Errorf("signer plugin: invalid key %q: %w", pk.KeyId, err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys, resp.ActiveKeyId, nil
+}
+
+// Sign returns the JWS signature of the payload by the key.
+func (c *Client) Sign(keyID, alg string, payload []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Sign(ctx, &proto.SignRequest{
+		KeyId:     keyID,
+		Algorithm: alg,
+		Payload:   payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("signer plugin: %w", err)
+	}
+	if len(resp.Signature) == 0 {
+		return nil, errors.New("signer plugin: empty signature")
+	}
+	return resp.Signature, nil
+}
+
+// Rotate creates a new active key and returns its public key, or
+// ErrRotateUnsupported if the plugin can't rotate its keys.
+func (c *Client) Rotate() (*structs.KeyringPublicKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Rotate(ctx, &proto.RotateRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil, ErrRotateUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signer plugin: %w", err)
+	}
+	if resp.PublicKey == nil {
+		return nil, errors.New("signer plugin: missing public key of rotated key")
+	}
+
+	pubKey, err := convertPublicKey(resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("signer plugin: invalid key %q: %w", resp.PublicKey.KeyId, err)
+	}
+	return pubKey, nil
+}
+
+// Close closes the connection to the plugin.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// convertPublicKey converts the PKIX public key of the plugin to the encoding
+// of the public keys of the keyring.
+func convertPublicKey(pk *proto.PublicKey) (*structs.KeyringPublicKey, error) {
+	if pk.KeyId == "" {
+		return nil, errors.New("missing key ID")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(pk.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	pubKey := &structs.KeyringPublicKey{
+		KeyID:      pk.KeyId,
+		Algorithm:  pk.Algorithm,
+		Use:        structs.PubKeyUseSig,
+		CreateTime: pk.CreateTime,
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if pk.Algorithm != structs.PubKeyAlgRS256 {
+			return nil, fmt.Errorf("algorithm %q doesn't match RSA key", pk.Algorithm)
+		}
+		pubKey.PublicKey = x509.MarshalPKCS1PublicKey(k)
+	case *ecdsa.PublicKey:
+		if pk.Algorithm != structs.PubKeyAlgES256 || k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("algorithm %q doesn't match ECDSA key", pk.Algorithm)
+		}
+		pubKey.PublicKey = pk.PublicKey
+	case ed25519.PublicKey:
+		if pk.Algorithm != structs.PubKeyAlgEdDSA {
+			return nil, fmt.Errorf("algorithm %q doesn't match Ed25519 key", pk.Algorithm)
+		}
+		pubKey.PublicKey = k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return pubKey, nil
+}
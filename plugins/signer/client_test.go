@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/shoenig/test/must"
+)
+
+func TestClient_PublicKeys(t *testing.T) {
+	ci.Parallel(t)
+
+	for _, alg := range []string{structs.PubKeyAlgRS256, structs.PubKeyAlgES256} {
+		t.Run(alg, func(t *testing.T) {
+			srv := NewTestServer(t, alg)
+			c := NewTestClient(t, srv)
+
+			pubKeys, activeKeyID, err := c.PublicKeys()
+			must.NoError(t, err)
+			must.Len(t, 1, pubKeys)
+			must.Eq(t, srv.ActiveKeyID(), activeKeyID)
+			must.Eq(t, activeKeyID, pubKeys[0].KeyID)
+			must.Eq(t, alg, pubKeys[0].Algorithm)
+			must.Eq(t, structs.PubKeyUseSig, pubKeys[0].Use)
+
+			// the public key is encoded like the keys of the keyring
+			key, err := pubKeys[0].GetPublicKey()
+			must.NoError(t, err)
+
+			payload := []byte("header.claims")
+			digest := sha256.Sum256(payload)
+			sig, err := c.Sign(activeKeyID, alg, payload)
+			must.NoError(t, err)
+
+			switch k := key.(type) {
+			case *rsa.PublicKey:
+				must.NoError(t, rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig))
+			case *ecdsa.PublicKey:
+				must.Len(t, 64, sig)
+				r := new(big.Int).SetBytes(sig[:32])
+				s := new(big.Int).SetBytes(sig[32:])
+				must.True(t, ecdsa.Verify(k, digest[:], r, s))
+			default:
+				t.Fatalf("unexpected key type %T", key)
+			}
+
+			_, err = c.Sign(activeKeyID, structs.PubKeyAlgEdDSA, payload)
+			must.ErrorContains(t, err, "doesn't support")
+		})
+	}
+}
+
+func TestClient_Rotate(t *testing.T) {
+	ci.Parallel(t)
+
+	srv := NewTestServer(t, structs.PubKeyAlgES256)
+	c := NewTestClient(t, srv)
+	oldKeyID := srv.ActiveKeyID()
+
+	pubKey, err := c.Rotate()
+	must.NoError(t, err)
+	must.NotEq(t, oldKeyID, pubKey.KeyID)
+
+	// the previous key is still published
+	pubKeys, activeKeyID, err := c.PublicKeys()
+	must.NoError(t, err)
+	must.Len(t, 2, pubKeys)
+	must.Eq(t, pubKey.KeyID, activeKeyID)
+
+	srv.DisableRotate()
+	_, err = c.Rotate()
+	must.ErrorIs(t, err, ErrRotateUnsupported)
+}
+
+func TestNewClient_Validate(t *testing.T) {
+	ci.Parallel(t)
+
+	_, err := NewClient(&config.SignerPluginConfig{})
+	must.ErrorContains(t, err, "address must not be empty")
+
+	// TCP connections must use TLS
+	_, err = NewClient(&config.SignerPluginConfig{Address: "127.0.0.1:9000"})
+	must.ErrorContains(t, err, "must be a unix socket unless ca_file is set")
+
+	_, err = NewClient(&config.SignerPluginConfig{Address: "127.0.0.1:9000", CAFile: "/nonexistent/ca.pem"})
+	must.ErrorContains(t, err, "failed to read CA file")
+}
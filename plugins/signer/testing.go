@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+	"google.golang.org/grpc"
+
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/structs/config"
+	"github.com/hashicorp/nomad/plugins/signer/proto"
+)
+
+// TestServer is a signer plugin holding its keys in memory, for testing. Its
+// last key is the active key.
+type TestServer struct {
+	proto.UnimplementedSignerPluginServer
+
+	// Algorithm is the algorithm of the keys created by Rotate, either
+	// RS256 or ES256.
+	Algorithm string
+
+	l              sync.Mutex
+	keys           []*testKey
+	rotateDisabled bool
+}
+
+type testKey struct {
+	pk  *proto.PublicKey
+	key crypto.Signer
+}
+
+// NewTestServer returns a signer plugin with one key of the algorithm.
+func NewTestServer(t *testing.T, alg string) *TestServer {
+	srv := &TestServer{Algorithm: alg}
+	_, err := srv.rotate()
+	must.NoError(t, err)
+	return srv
+}
+
+// NewTestClient serves the signer plugin and returns a client of it.
+func NewTestClient(t *testing.T, srv *TestServer) *Client {
+	t.Helper()
+
+	sockPath := filepath.Join(t.TempDir(), "signer.sock")
+	l, err := net.Listen("unix", sockPath)
+	must.NoError(t, err)
+
+	s := grpc.NewServer()
+	proto.RegisterSignerPluginServer(s, srv)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	c, err := NewClient(&config.SignerPluginConfig{Address: "unix://" + sockPath})
+	must.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// DisableRotate makes Rotate unimplemented.
+func (s *TestServer) DisableRotate() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.rotateDisabled = true
+}
+
+// ActiveKeyID returns the ID of the active key.
+func (s *TestServer) ActiveKeyID() string {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.keys[len(s.keys)-1].pk.KeyId
+}
+
+func (s *TestServer) PublicKeys(context.Context, *proto.PublicKeysRequest) (*proto.PublicKeysResponse, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	resp := &proto.PublicKeysResponse{ActiveKeyId: s.keys[len(s.keys)-1].pk.KeyId}
+	for _, k := range s.keys {
+		resp.PublicKeys = append(resp.PublicKeys, k.pk)
+	}
+	return resp, nil
+}
+
+func (s *TestServer) Sign(_ context.Context, req *proto.SignRequest) (*proto.SignResponse, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	for _, k := range s.keys {
+		if k.pk.KeyId != req.KeyId {
+			continue
+		}
+		if k.pk.Algorithm != req.Algorithm {
+			return nil, fmt.Errorf("key %q doesn't support %q", req.KeyId, req.Algorithm)
+		}
+
+		digest := sha256.Sum256(req.Payload)
+		switch key := k.key.(type) {
+		case *rsa.PrivateKey:
+			sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+			if err != nil {
+				return nil, err
+			}
+			return &proto.SignResponse{Signature: sig}, nil
+		case *ecdsa.PrivateKey:
+			r, ss, err := ecdsa.Sign(rand.Reader, key, digest[:])
+			if err != nil {
+				return nil, err
+			}
+			sig := make([]byte, 64)
+			r.FillBytes(sig[:32])
+			ss.FillBytes(sig[32:])
+			return &proto.SignResponse{Signature: sig}, nil
+		}
+	}
+	return nil, fmt.Errorf("no such key %q", req.KeyId)
+}
+
+func (s *TestServer) Rotate(context.Context, *proto.RotateRequest) (*proto.RotateResponse, error) {
+	s.l.Lock()
+	disabled := s.rotateDisabled
+	s.l.Unlock()
+	if disabled {
+		return s.UnimplementedSignerPluginServer.Rotate(context.Background(), nil)
+	}
+
+	pk, err := s.rotate()
+	if err != nil {
+		return nil, err
+	}
+	return &proto.RotateResponse{PublicKey: pk}, nil
+}
+
+func (s *TestServer) rotate() (*proto.PublicKey, error) {
+	var key crypto.Signer
+	var err error
+	switch s.Algorithm {
+	case structs.PubKeyAlgRS256:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case structs.PubKeyAlgES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		err = fmt.Errorf("unsupported algorithm %q", s.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	pk := &proto.PublicKey{
+		KeyId:      uuid.Generate(),
+		Algorithm:  s.Algorithm,
+		PublicKey:  der,
+		CreateTime: time.Now().UnixNano(),
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.keys = append(s.keys, &testKey{pk: pk, key: key})
+	return pk, nil
+}
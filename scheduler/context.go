@@ -135,6 +135,10 @@ type EvalContext struct {
 	logger      log.Logger
 	metrics     *structs.AllocMetric
 	eligibility *EvalEligibility
+
+	// traceMaxNodes is the maximum number of nodes recorded in the trace of
+	// each placement. Zero means tracing is disabled.
+	traceMaxNodes int
 }
 
 // NewEvalContext constructs a new EvalContext
@@ -171,6 +175,16 @@ func (e *EvalContext) SetState(s State) {
 
 func (e *EvalContext) Reset() {
 	e.metrics = new(structs.AllocMetric)
+	if e.traceMaxNodes > 0 {
+		e.metrics.EnableTrace(e.traceMaxNodes)
+	}
+}
+
+// EnableTrace enables recording the nodes filtered and exhausted in the
+// metrics of each placement, up to maxNodes nodes per placement.
+func (e *EvalContext) EnableTrace(maxNodes int) {
+	e.traceMaxNodes = maxNodes
+	e.metrics.EnableTrace(maxNodes)
 }
 
 func (e *EvalContext) ProposedAllocs(nodeID string) ([]*structs.Allocation, error) {
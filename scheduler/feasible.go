@@ -5,10 +5,12 @@ package scheduler
 
 import (
 	"fmt"
+	"net"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/go-version"
@@ -20,6 +22,8 @@ import (
 
 const (
 	FilterConstraintHostVolumes                    = "missing compatible host volumes"
+	FilterConstraintLocalVolumeClaimed             = "local volumes claimed on another node"
+	FilterConstraintLocalVolumeLookupFailed        = "local volume claim lookup failed"
 	FilterConstraintCSIPluginTemplate              = "CSI plugin %s is missing from client %s"
 	FilterConstraintCSIPluginUnhealthyTemplate     = "CSI plugin %s is unhealthy on client %s"
 	FilterConstraintCSIPluginMaxVolumesTemplate    = "CSI plugin %s has the maximum number of volumes on client %s"
@@ -137,11 +141,20 @@ func NewRandomIterator(ctx Context, nodes []*structs.Node) *StaticIterator {
 // HostVolumeChecker is a FeasibilityChecker which returns whether a node has
 // the host volumes necessary to schedule a task group.
 type HostVolumeChecker struct {
-	ctx Context
+	ctx       Context
+	namespace string
+	jobID     string
 
 	// volumes is a map[HostVolumeName][]RequestedVolume. The requested volumes are
 	// a slice because a single task group may request the same volume multiple times.
 	volumes map[string][]*structs.VolumeRequest
+
+	// claimNodeID is the node the allocation is bound to when it requests
+	// local volumes and its name was placed before.
+	claimNodeID string
+
+	// claimErr is set if the local volume claim could not be looked up.
+	claimErr bool
 }
 
 // NewHostVolumeChecker creates a HostVolumeChecker from a set of volumes
@@ -151,15 +164,27 @@ func NewHostVolumeChecker(ctx Context) *HostVolumeChecker {
 	}
 }
 
+func (h *HostVolumeChecker) SetJobID(jobID string) {
+	h.jobID = jobID
+}
+
+func (h *HostVolumeChecker) SetNamespace(namespace string) {
+	h.namespace = namespace
+}
+
 // SetVolumes takes the volumes required by a task group and updates the checker.
 func (h *HostVolumeChecker) SetVolumes(allocName string, volumes map[string]*structs.VolumeRequest) {
 	lookupMap := make(map[string][]*structs.VolumeRequest)
+	hasLocal := false
 	// Convert the map from map[DesiredName]Request to map[Source][]Request to improve
 	// lookup performance. Also filter non-host volumes.
 	for _, req := range volumes {
-		if req.Type != structs.VolumeTypeHost {
+		if !req.IsHostVolume() {
 			continue
 		}
+		if req.Type == structs.VolumeTypeLocal {
+			hasLocal = true
+		}
 
 		if req.PerAlloc {
 			// provide a unique volume source per allocation
@@ -171,9 +196,32 @@ func (h *HostVolumeChecker) SetVolumes(allocName string, volumes map[string]*str
 		}
 	}
 	h.volumes = lookupMap
+
+	// Allocations using local volumes are bound to the node their name was
+	// first placed on.
+	h.claimNodeID = ""
+	h.claimErr = false
+	if hasLocal && allocName != "" {
+		claim, err := h.ctx.State().LocalVolumeClaim(nil, h.namespace, h.jobID, allocName)
+		if err != nil {
+			h.ctx.Logger().Error("failed to lookup local volume claim", "alloc_name", allocName, "error", err)
+			h.claimErr = true
+		} else if claim != nil {
+			h.claimNodeID = claim.NodeID
+		}
+	}
 }
 
 func (h *HostVolumeChecker) Feasible(candidate *structs.Node) bool {
+	if h.claimErr {
+		h.ctx.Metrics().FilterNode(candidate, FilterConstraintLocalVolumeLookupFailed)
+		return false
+	}
+	if h.claimNodeID != "" && h.claimNodeID != candidate.ID {
+		h.ctx.Metrics().FilterNode(candidate, FilterConstraintLocalVolumeClaimed)
+		return false
+	}
+
 	if h.hasVolumes(candidate) {
 		return true
 	}
@@ -642,6 +690,93 @@ func (iter *DistinctHostsIterator) Reset() {
 	iter.source.Reset()
 }
 
+const (
+	// FilterNodeMaxAllocs is the reason nodes are filtered when they reached
+	// their max_allocs.
+	FilterNodeMaxAllocs = "node max_allocs reached"
+
+	// FilterJobMaxAllocsPerNode is the reason nodes are filtered when the job
+	// reached its max_allocs_per_node on them.
+	FilterJobMaxAllocsPerNode = "job max_allocs_per_node reached"
+)
+
+// MaxAllocsIterator is a FeasibleIterator which returns nodes that have room
+// for another allocation, given the max_allocs of the nodes and the
+// max_allocs_per_node of the job.
+type MaxAllocsIterator struct {
+	ctx    Context
+	source FeasibleIterator
+	job    *structs.Job
+
+	// Store the max_allocs_per_node of the job so the hot-path doesn't need
+	// the job to be set.
+	jobMaxAllocs int
+}
+
+// NewMaxAllocsIterator creates a MaxAllocsIterator from a source.
+func NewMaxAllocsIterator(ctx Context, source FeasibleIterator) *MaxAllocsIterator {
+	return &MaxAllocsIterator{
+		ctx:    ctx,
+		source: source,
+	}
+}
+
+func (iter *MaxAllocsIterator) SetJob(job *structs.Job) {
+	iter.job = job
+	iter.jobMaxAllocs = job.MaxAllocsPerNode
+}
+
+func (iter *MaxAllocsIterator) Next() *structs.Node {
+	for {
+		option := iter.source.Next()
+
+		// Hot-path if the option is nil or there are no limits.
+		if option == nil || (option.MaxAllocs == 0 && iter.jobMaxAllocs == 0) {
+			return option
+		}
+
+		if reason := iter.exceeded(option); reason != "" {
+			iter.ctx.Metrics().FilterNode(option, reason)
+			continue
+		}
+
+		return option
+	}
+}
+
+// exceeded returns the reason the node is filtered if placing another
+// allocation on it would exceed its max_allocs or the max_allocs_per_node of
+// the job, or an empty string.
+func (iter *MaxAllocsIterator) exceeded(option *structs.Node) string {
+	proposed, err := iter.ctx.ProposedAllocs(option.ID)
+	if err != nil {
+		iter.ctx.Logger().Named("max_allocs").Error("failed to get proposed allocations", "error", err)
+		return FilterNodeMaxAllocs
+	}
+
+	if option.MaxAllocs > 0 && len(proposed) >= option.MaxAllocs {
+		return FilterNodeMaxAllocs
+	}
+
+	if iter.jobMaxAllocs > 0 {
+		count := 0
+		for _, alloc := range proposed {
+			if alloc.JobID == iter.job.ID && alloc.Namespace == iter.job.Namespace {
+				count++
+			}
+		}
+		if count >= iter.jobMaxAllocs {
+			return FilterJobMaxAllocsPerNode
+		}
+	}
+
+	return ""
+}
+
+func (iter *MaxAllocsIterator) Reset() {
+	iter.source.Reset()
+}
+
 // DistinctPropertyIterator is a FeasibleIterator which returns nodes that pass the
 // distinct_property constraint. The constraint ensures that multiple allocations
 // do not use the same value of the given property.
@@ -781,13 +916,71 @@ func (c *ConstraintChecker) Feasible(option *structs.Node) bool {
 func (c *ConstraintChecker) meetsConstraint(constraint *structs.Constraint, option *structs.Node) bool {
 	// Resolve the targets. Targets that are not present are treated as `nil`.
 	// This is to allow for matching constraints where a target is not present.
-	lVal, lOk := resolveTarget(constraint.LTarget, option)
-	rVal, rOk := resolveTarget(constraint.RTarget, option)
+	lVal, lOk := resolveNodeTarget(c.ctx, constraint.LTarget, option)
+	rVal, rOk := resolveNodeTarget(c.ctx, constraint.RTarget, option)
 
 	// Check if satisfied
 	return checkConstraint(c.ctx, constraint.Operand, lVal, rVal, lOk, rOk)
 }
 
+const (
+	// nodeBootTimeAttr is the fingerprinted attribute holding the unix time
+	// at which the node booted.
+	nodeBootTimeAttr = "unique.host.boot_time"
+
+	// nodeChurnWindow is how far back the allocations placed on a node are
+	// counted by the ${node.alloc_churn} target.
+	nodeChurnWindow = time.Hour
+)
+
+// resolveNodeTarget is used to resolve the targets of constraints and
+// affinities. It handles the targets derived from the time of scheduling and
+// the allocations of the node, and falls back to resolveTarget otherwise.
+func resolveNodeTarget(ctx Context, target string, node *structs.Node) (string, bool) {
+	switch target {
+	case "${node.uptime}":
+		return nodeUptime(node, time.Now())
+	case "${node.alloc_churn}":
+		return nodeAllocChurn(ctx, node, time.Now())
+	default:
+		return resolveTarget(target, node)
+	}
+}
+
+// nodeUptime returns the number of seconds since the node booted.
+func nodeUptime(node *structs.Node, now time.Time) (string, bool) {
+	bootTime, err := strconv.ParseInt(node.Attributes[nodeBootTimeAttr], 10, 64)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(max(now.Unix()-bootTime, 0), 10), true
+}
+
+// nodeAllocChurn returns the number of allocations placed on the node within
+// the churn window, including the ones placed by the current plan.
+func nodeAllocChurn(ctx Context, node *structs.Node, now time.Time) (string, bool) {
+	allocs, err := ctx.State().AllocsByNode(nil, node.ID)
+	if err != nil {
+		ctx.Logger().Error("failed to lookup allocations of node", "node_id", node.ID, "error", err)
+		return "", false
+	}
+
+	since := now.Add(-nodeChurnWindow).UnixNano()
+	churn := 0
+	for _, alloc := range allocs {
+		if alloc.CreateTime >= since {
+			churn++
+		}
+	}
+	for _, alloc := range ctx.Plan().NodeAllocation[node.ID] {
+		// Allocations updated in-place already exist in the state
+		if alloc.CreateIndex == 0 {
+			churn++
+		}
+	}
+	return strconv.Itoa(churn), true
+}
+
 // resolveTarget is used to resolve the LTarget and RTarget of a Constraint.
 func resolveTarget(target string, node *structs.Node) (string, bool) {
 	// If no prefix, this must be a literal value
@@ -861,6 +1054,8 @@ func checkConstraint(ctx Context, operand string, lVal, rVal interface{}, lFound
 		return lFound && rFound && checkSetContainsAll(ctx, lVal, rVal)
 	case structs.ConstraintSetContainsAny:
 		return lFound && rFound && checkSetContainsAny(lVal, rVal)
+	case structs.ConstraintCIDRContains:
+		return lFound && rFound && checkCIDRContains(lVal, rVal)
 	default:
 		return false
 	}
@@ -1108,6 +1303,55 @@ func checkSetContainsAny(lVal, rVal interface{}) bool {
 	return false
 }
 
+// checkCIDRContains is used to see if the left-hand IP address, or CIDR block,
+// is contained by any of the CIDR blocks of the right-hand comma separated list.
+func checkCIDRContains(lVal, rVal interface{}) bool {
+	// Ensure left-hand is string
+	lStr, ok := lVal.(string)
+	if !ok {
+		return false
+	}
+
+	// RHS must be a string
+	rStr, ok := rVal.(string)
+	if !ok {
+		return false
+	}
+
+	lStr = strings.TrimSpace(lStr)
+	ip := net.ParseIP(lStr)
+	var lNet *net.IPNet
+	if ip == nil {
+		var err error
+		ip, lNet, err = net.ParseCIDR(lStr)
+		if err != nil {
+			return false
+		}
+	}
+
+	for _, r := range strings.Split(rStr, ",") {
+		_, rNet, err := net.ParseCIDR(strings.TrimSpace(r))
+		if err != nil {
+			return false
+		}
+		if !rNet.Contains(ip) {
+			continue
+		}
+
+		// A left-hand CIDR block must be fully contained
+		if lNet != nil {
+			lOnes, lBits := lNet.Mask.Size()
+			rOnes, rBits := rNet.Mask.Size()
+			if lBits != rBits || lOnes < rOnes {
+				continue
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
 // FeasibilityWrapper is a FeasibleIterator which wraps both job and task group
 // FeasibilityCheckers in which feasibility checking can be skipped if the
 // computed node class has previously been marked as eligible or ineligible.
@@ -1528,6 +1772,18 @@ func checkAttributeConstraint(ctx Context, operand string, lVal, rVal *psstructs
 		}
 
 		return checkSetContainsAny(ls, rs)
+	case structs.ConstraintCIDRContains:
+		if !(lFound && rFound) {
+			return false
+		}
+
+		ls, ok := lVal.GetString()
+		rs, ok2 := rVal.GetString()
+		if !ok || !ok2 {
+			return false
+		}
+
+		return checkCIDRContains(ls, rs)
 	case structs.ConstraintAttributeIsSet:
 		return lFound
 	case structs.ConstraintAttributeIsNotSet:
@@ -6,6 +6,7 @@ package scheduler
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -185,6 +186,45 @@ func TestHostVolumeChecker(t *testing.T) {
 	}
 }
 
+func TestHostVolumeChecker_LocalVolumeClaim(t *testing.T) {
+	ci.Parallel(t)
+
+	state, ctx := testContext(t)
+	nodes := []*structs.Node{mock.Node(), mock.Node()}
+	for _, node := range nodes {
+		node.HostVolumes = map[string]*structs.ClientHostVolumeConfig{"data": {Name: "data"}}
+	}
+
+	job := mock.Job()
+	job.TaskGroups[0].Volumes = map[string]*structs.VolumeRequest{
+		"data": {Name: "data", Type: structs.VolumeTypeLocal, Source: "data"},
+	}
+	must.NoError(t, state.UpsertJob(structs.MsgTypeTestSetup, 1000, nil, job))
+
+	// Place an allocation on the second node to claim it.
+	alloc := mock.Alloc()
+	alloc.Job = job
+	alloc.JobID = job.ID
+	alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, 0)
+	alloc.NodeID = nodes[1].ID
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1001, []*structs.Allocation{alloc}))
+
+	checker := NewHostVolumeChecker(ctx)
+	checker.SetNamespace(job.Namespace)
+	checker.SetJobID(job.ID)
+
+	// The claimed allocation name can only be placed on the claimed node.
+	checker.SetVolumes(alloc.Name, job.TaskGroups[0].Volumes)
+	must.False(t, checker.Feasible(nodes[0]))
+	must.True(t, checker.Feasible(nodes[1]))
+	must.Eq(t, 1, ctx.Metrics().ConstraintFiltered[FilterConstraintLocalVolumeClaimed])
+
+	// Other allocation names can be placed on any node with the volume.
+	checker.SetVolumes(structs.AllocName(job.ID, job.TaskGroups[0].Name, 1), job.TaskGroups[0].Volumes)
+	must.True(t, checker.Feasible(nodes[0]))
+	must.True(t, checker.Feasible(nodes[1]))
+}
+
 func TestHostVolumeChecker_ReadOnly(t *testing.T) {
 	ci.Parallel(t)
 
@@ -1017,6 +1057,49 @@ func TestResolveConstraintTarget(t *testing.T) {
 	}
 }
 
+func TestResolveNodeTarget(t *testing.T) {
+	ci.Parallel(t)
+
+	state, ctx := testContext(t)
+	now := time.Now()
+
+	node := mock.Node()
+	node.Attributes["unique.host.boot_time"] = strconv.FormatInt(now.Add(-2*time.Hour).Unix(), 10)
+
+	uptime, ok := resolveNodeTarget(ctx, "${node.uptime}", node)
+	must.True(t, ok)
+	secs, err := strconv.Atoi(uptime)
+	must.NoError(t, err)
+	must.GreaterEq(t, 7200, secs)
+
+	// Only allocs placed within the churn window and the new placements of
+	// the plan are counted
+	recent, old, placed := mock.Alloc(), mock.Alloc(), mock.Alloc()
+	for _, alloc := range []*structs.Allocation{recent, old, placed} {
+		alloc.NodeID = node.ID
+	}
+	recent.CreateTime = now.UnixNano()
+	old.CreateTime = now.Add(-2 * nodeChurnWindow).UnixNano()
+	must.NoError(t, state.UpsertAllocs(structs.MsgTypeTestSetup, 1000, []*structs.Allocation{recent, old}))
+
+	inplace, err := state.AllocByID(nil, recent.ID)
+	must.NoError(t, err)
+	ctx.Plan().NodeAllocation[node.ID] = []*structs.Allocation{placed, inplace}
+
+	churn, ok := resolveNodeTarget(ctx, "${node.alloc_churn}", node)
+	must.True(t, ok)
+	must.Eq(t, "2", churn)
+
+	// Other targets are resolved as usual
+	dc, ok := resolveNodeTarget(ctx, "${node.datacenter}", node)
+	must.True(t, ok)
+	must.Eq(t, node.Datacenter, dc)
+
+	delete(node.Attributes, "unique.host.boot_time")
+	_, ok = resolveNodeTarget(ctx, "${node.uptime}", node)
+	must.False(t, ok)
+}
+
 func TestCheckConstraint(t *testing.T) {
 	ci.Parallel(t)
 
@@ -1370,6 +1453,58 @@ func TestCheckRegexpConstraint(t *testing.T) {
 	}
 }
 
+func TestCheckCIDRContainsConstraint(t *testing.T) {
+	ci.Parallel(t)
+
+	type tcase struct {
+		lVal, rVal interface{}
+		result     bool
+	}
+	cases := []tcase{
+		{
+			lVal: "10.1.2.3", rVal: "10.0.0.0/8",
+			result: true,
+		},
+		{
+			lVal: "192.168.1.10", rVal: "10.0.0.0/8, 192.168.0.0/16",
+			result: true,
+		},
+		{
+			lVal: "172.16.0.1", rVal: "10.0.0.0/8,192.168.0.0/16",
+			result: false,
+		},
+		{
+			lVal: "2001:db8::1", rVal: "2001:db8::/32",
+			result: true,
+		},
+		{
+			lVal: "10.1.0.0/16", rVal: "10.0.0.0/8",
+			result: true,
+		},
+		{
+			lVal: "10.0.0.0/8", rVal: "10.1.0.0/16",
+			result: false,
+		},
+		{
+			lVal: "foo", rVal: "10.0.0.0/8",
+			result: false,
+		},
+		{
+			lVal: "10.1.2.3", rVal: "foo",
+			result: false,
+		},
+		{
+			lVal: 1, rVal: "10.0.0.0/8",
+			result: false,
+		},
+	}
+	for _, tc := range cases {
+		if res := checkCIDRContains(tc.lVal, tc.rVal); res != tc.result {
+			t.Fatalf("TC: %#v, Result: %v", tc, res)
+		}
+	}
+}
+
 // This test puts allocations on the node to test if it detects infeasibility of
 // nodes correctly and picks the only feasible one
 func TestDistinctHostsIterator_JobDistinctHosts(t *testing.T) {
@@ -1667,6 +1802,52 @@ func TestDistinctHostsIterator_TaskGroupDistinctHosts(t *testing.T) {
 	}
 }
 
+func TestMaxAllocsIterator(t *testing.T) {
+	ci.Parallel(t)
+
+	_, ctx := testContext(t)
+	nodes := []*structs.Node{
+		mock.Node(),
+		mock.Node(),
+		mock.Node(),
+		mock.Node(),
+	}
+	nodes[0].MaxAllocs = 2
+	static := NewStaticIterator(ctx, nodes)
+
+	job := mock.Job()
+	alloc := func(jobID, namespace string) *structs.Allocation {
+		return &structs.Allocation{
+			ID:        uuid.Generate(),
+			Namespace: namespace,
+			JobID:     jobID,
+			TaskGroup: "web",
+		}
+	}
+
+	// node0 is full, node1 has an alloc of the job, node2 has an alloc of a
+	// job with the same ID in another namespace, and node3 is empty
+	plan := ctx.Plan()
+	plan.NodeAllocation[nodes[0].ID] = []*structs.Allocation{
+		alloc("other", job.Namespace),
+		alloc("other", job.Namespace),
+	}
+	plan.NodeAllocation[nodes[1].ID] = []*structs.Allocation{alloc(job.ID, job.Namespace)}
+	plan.NodeAllocation[nodes[2].ID] = []*structs.Allocation{alloc(job.ID, "platform")}
+
+	iter := NewMaxAllocsIterator(ctx, static)
+	iter.SetJob(job)
+	must.Eq(t, nodes[1:], collectFeasible(iter))
+	must.Eq(t, 1, ctx.Metrics().ConstraintFiltered[FilterNodeMaxAllocs])
+
+	// the job can have a single alloc per node
+	job.MaxAllocsPerNode = 1
+	iter.Reset()
+	iter.SetJob(job)
+	must.Eq(t, nodes[2:], collectFeasible(iter))
+	must.Eq(t, 1, ctx.Metrics().ConstraintFiltered[FilterJobMaxAllocsPerNode])
+}
+
 // This test puts creates allocations across task groups that use a property
 // value to detect if the constraint at the job level properly considers all
 // task groups.
@@ -3091,6 +3272,18 @@ func TestCheckAttributeConstraint(t *testing.T) {
 			rVal:   psstructs.NewStringAttribute("foo,bam"),
 			result: true,
 		},
+		{
+			op:     structs.ConstraintCIDRContains,
+			lVal:   psstructs.NewStringAttribute("10.1.2.3"),
+			rVal:   psstructs.NewStringAttribute("10.0.0.0/8"),
+			result: true,
+		},
+		{
+			op:     structs.ConstraintCIDRContains,
+			lVal:   psstructs.NewStringAttribute("10.1.2.3"),
+			rVal:   psstructs.NewStringAttribute("192.168.0.0/16"),
+			result: false,
+		},
 		{
 			op:     structs.ConstraintAttributeIsSet,
 			lVal:   psstructs.NewStringAttribute("foo,bar,baz"),
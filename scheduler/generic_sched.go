@@ -30,10 +30,20 @@ const (
 	// allocNotNeeded is the status used when a job no longer requires an allocation
 	allocNotNeeded = "alloc not needed due to job update"
 
+	// allocSuspended is the status used when an allocation is stopped because
+	// its batch job is suspended. Such allocations are placed again once the
+	// job is resumed, even if their tasks exited successfully when killed.
+	allocSuspended = "alloc stopped since job is suspended"
+
 	// allocReconnected is the status to use when a replacement allocation is stopped
 	// because a disconnected node reconnects.
 	allocReconnected = "alloc not needed due to disconnected client reconnect"
 
+	// allocReplacedOnReconnect is the status to use when an allocation is
+	// stopped because its replacement is kept when its disconnected node
+	// reconnects.
+	allocReplacedOnReconnect = "alloc not needed as its replacement was kept on disconnected client reconnect"
+
 	// allocMigrating is the status used when we must migrate an allocation
 	allocMigrating = "alloc is being migrated"
 
@@ -119,6 +129,13 @@ type GenericScheduler struct {
 	blocked        *structs.Evaluation
 	failedTGAllocs map[string]*structs.AllocMetric
 	queuedAllocs   map[string]int
+
+	// warnings are the non-fatal warnings raised while placing allocations.
+	warnings []*structs.JobWarning
+
+	// trace is the trace of the last scheduling attempt, or nil if
+	// evaluation tracing is disabled.
+	trace *structs.EvalTrace
 }
 
 // NewServiceScheduler is a factory function to instantiate a new service scheduler
@@ -161,6 +178,9 @@ func (s *GenericScheduler) Process(eval *structs.Evaluation) (err error) {
 	// Update our logger with the eval's information
 	s.logger = s.logger.With("eval_id", eval.ID, "job_id", eval.JobID, "namespace", eval.Namespace)
 
+	// Store the trace of the evaluation once it has been processed
+	defer func() { updateEvalTrace(s.logger, s.planner, s.trace) }()
+
 	// Verify the evaluation trigger reason is understood
 	switch eval.TriggeredBy {
 	case structs.EvalTriggerJobRegister, structs.EvalTriggerJobDeregister,
@@ -170,13 +190,14 @@ func (s *GenericScheduler) Process(eval *structs.Evaluation) (err error) {
 		structs.EvalTriggerPeriodicJob, structs.EvalTriggerMaxPlans,
 		structs.EvalTriggerDeploymentWatcher, structs.EvalTriggerRetryFailedAlloc,
 		structs.EvalTriggerFailedFollowUp, structs.EvalTriggerPreemption,
-		structs.EvalTriggerScaling, structs.EvalTriggerMaxDisconnectTimeout, structs.EvalTriggerReconnect:
+		structs.EvalTriggerScaling, structs.EvalTriggerMaxDisconnectTimeout, structs.EvalTriggerReconnect,
+		structs.EvalTriggerJobSuspend, structs.EvalTriggerJobResume:
 	default:
 		desc := fmt.Sprintf("scheduler cannot handle '%s' evaluation reason",
 			eval.TriggeredBy)
 		return setStatus(s.logger, s.planner, s.eval, nil, s.blocked,
-			s.failedTGAllocs, structs.EvalStatusFailed, desc, s.queuedAllocs,
-			s.deployment.GetID())
+			s.failedTGAllocs, s.warnings, structs.EvalStatusFailed, desc,
+			s.queuedAllocs, s.deployment.GetID())
 	}
 
 	// Retry up to the maxScheduleAttempts and reset if progress is made.
@@ -194,7 +215,7 @@ func (s *GenericScheduler) Process(eval *structs.Evaluation) (err error) {
 				mErr.Errors = append(mErr.Errors, err)
 			}
 			if err := setStatus(s.logger, s.planner, s.eval, nil, s.blocked,
-				s.failedTGAllocs, statusErr.EvalStatus, err.Error(),
+				s.failedTGAllocs, s.warnings, statusErr.EvalStatus, err.Error(),
 				s.queuedAllocs, s.deployment.GetID()); err != nil {
 				mErr.Errors = append(mErr.Errors, err)
 			}
@@ -216,8 +237,8 @@ func (s *GenericScheduler) Process(eval *structs.Evaluation) (err error) {
 
 	// Update the status to complete
 	return setStatus(s.logger, s.planner, s.eval, nil, s.blocked,
-		s.failedTGAllocs, structs.EvalStatusComplete, "", s.queuedAllocs,
-		s.deployment.GetID())
+		s.failedTGAllocs, s.warnings, structs.EvalStatusComplete, "",
+		s.queuedAllocs, s.deployment.GetID())
 }
 
 // createBlockedEval creates a blocked eval and submits it to the planner. If
@@ -273,12 +294,16 @@ func (s *GenericScheduler) process() (bool, error) {
 		}
 	}
 
-	// Reset the failed allocations
+	// Reset the failed allocations and warnings
 	s.failedTGAllocs = nil
+	s.warnings = nil
 
 	// Create an evaluation context
 	s.ctx = NewEvalContext(s.eventsCh, s.state, s.plan, s.logger)
 
+	// Start a new trace, only the last scheduling attempt is traced
+	s.trace = newEvalTrace(s.ctx, s.eval)
+
 	// Construct the placement stack
 	s.stack = NewGenericStack(s.batch, s.ctx)
 	if !s.job.Stopped() {
@@ -608,6 +633,8 @@ func (s *GenericScheduler) computePlacements(destructive, place []placementResul
 			// Compute top K scoring node metadata
 			s.ctx.Metrics().PopulateScoreMetaData()
 
+			tracePlacement(s.trace, s.ctx, tg.Name, missing.Name(), option, selectOptions.Preempt)
+
 			// Restore stack job and nodes now that placement is done, to use
 			// plan job version
 			if downgradedJob != nil {
@@ -623,6 +650,9 @@ func (s *GenericScheduler) computePlacements(destructive, place []placementResul
 
 			// Set fields based on if we found an allocation option
 			if option != nil {
+				s.warnings, _ = structs.MergeJobWarnings(s.warnings,
+					placementWarnings(s.ctx, s.job, tg, option)...)
+
 				resources := &structs.AllocatedResources{
 					Tasks:          option.TaskResources,
 					TaskLifecycles: option.TaskLifecycles,
@@ -749,6 +779,7 @@ func (s *GenericScheduler) setJob(job *structs.Job) error {
 
 	s.stack.SetJob(job)
 	s.stack.SetSchedulerConfiguration(schedConfig.WithNodePool(pool))
+	s.stack.SetScoringPlugins(s.planner.ScoringPlugins(job))
 	return nil
 }
 
@@ -294,6 +294,78 @@ func TestServiceSched_JobRegister_DiskConstraints(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestServiceSched_JobRegister_EvalTrace(t *testing.T) {
+	ci.Parallel(t)
+
+	h := NewHarness(t)
+
+	// Without tracing enabled no trace is recorded.
+	job := mock.Job()
+	job.TaskGroups[0].Count = 1
+	must.NoError(t, h.State.UpsertJob(structs.MsgTypeTestSetup, h.NextIndex(), nil, job))
+
+	node := mock.Node()
+	must.NoError(t, h.State.UpsertNode(structs.MsgTypeTestSetup, h.NextIndex(), node))
+
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+		Status:      structs.EvalStatusPending,
+	}
+	must.NoError(t, h.State.UpsertEvals(structs.MsgTypeTestSetup, h.NextIndex(), []*structs.Evaluation{eval}))
+	must.NoError(t, h.Process(NewServiceScheduler, eval))
+	must.SliceEmpty(t, h.EvalTraces)
+
+	// Enable tracing and add nodes that are filtered by the job constraint.
+	must.NoError(t, h.State.SchedulerSetConfig(h.NextIndex(), &structs.SchedulerConfiguration{
+		EvalTraceConfig: structs.EvalTraceConfig{
+			Enabled:  true,
+			MaxNodes: 1,
+		},
+	}))
+	for i := 0; i < 2; i++ {
+		windows := mock.Node()
+		windows.Attributes["kernel.name"] = "windows"
+		windows.ComputeClass()
+		must.NoError(t, h.State.UpsertNode(structs.MsgTypeTestSetup, h.NextIndex(), windows))
+	}
+
+	job = mock.Job()
+	job.TaskGroups[0].Count = 1
+	must.NoError(t, h.State.UpsertJob(structs.MsgTypeTestSetup, h.NextIndex(), nil, job))
+
+	eval = &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+		Status:      structs.EvalStatusPending,
+	}
+	must.NoError(t, h.State.UpsertEvals(structs.MsgTypeTestSetup, h.NextIndex(), []*structs.Evaluation{eval}))
+	must.NoError(t, h.Process(NewServiceScheduler, eval))
+
+	must.Len(t, 1, h.EvalTraces)
+	trace := h.EvalTraces[0]
+	must.Eq(t, eval.ID, trace.EvalID)
+	must.Eq(t, job.ID, trace.JobID)
+	must.Len(t, 1, trace.Placements)
+
+	// Only one of the two filtered nodes is recorded since MaxNodes is 1.
+	placement := trace.Placements[0]
+	must.Eq(t, "web", placement.TaskGroup)
+	must.Eq(t, node.ID, placement.NodeID)
+	must.Eq(t, 3, placement.NodesEvaluated)
+	must.Len(t, 1, placement.Filtered)
+	must.NotEq(t, node.ID, placement.Filtered[0].NodeID)
+	must.True(t, placement.Truncated)
+	must.Len(t, 1, placement.Scores)
+	must.False(t, placement.PreemptionAttempted)
+}
+
 func TestServiceSched_JobRegister_DistinctHosts(t *testing.T) {
 	ci.Parallel(t)
 
@@ -378,6 +450,52 @@ func TestServiceSched_JobRegister_DistinctHosts(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestServiceSched_JobRegister_MaxAllocs(t *testing.T) {
+	ci.Parallel(t)
+
+	h := NewHarness(t)
+
+	// Create some nodes, one of which can only run a single alloc
+	var nodes []*structs.Node
+	for i := 0; i < 3; i++ {
+		node := mock.Node()
+		nodes = append(nodes, node)
+	}
+	nodes[0].MaxAllocs = 1
+	for _, node := range nodes {
+		must.NoError(t, h.State.UpsertNode(structs.MsgTypeTestSetup, h.NextIndex(), node))
+	}
+
+	// Create a job that can run two allocs per node and has count 1 higher
+	// than what is possible.
+	job := mock.Job()
+	job.TaskGroups[0].Count = 6
+	job.MaxAllocsPerNode = 2
+	must.NoError(t, h.State.UpsertJob(structs.MsgTypeTestSetup, h.NextIndex(), nil, job))
+
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+		Status:      structs.EvalStatusPending,
+	}
+	must.NoError(t, h.State.UpsertEvals(structs.MsgTypeTestSetup, h.NextIndex(), []*structs.Evaluation{eval}))
+	must.NoError(t, h.Process(NewServiceScheduler, eval))
+
+	must.Len(t, 1, h.Plans)
+	must.Len(t, 1, h.CreateEvals)
+	must.MapLen(t, 1, h.Evals[0].FailedTGAllocs)
+
+	plan := h.Plans[0]
+	must.Len(t, 1, plan.NodeAllocation[nodes[0].ID])
+	must.Len(t, 2, plan.NodeAllocation[nodes[1].ID])
+	must.Len(t, 2, plan.NodeAllocation[nodes[2].ID])
+
+	h.AssertEvalStatus(t, structs.EvalStatusComplete)
+}
+
 func TestServiceSched_JobRegister_DistinctProperty(t *testing.T) {
 	ci.Parallel(t)
 
@@ -5170,6 +5288,89 @@ func TestBatchSched_ReRun_SuccessfullyFinishedAlloc(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+// This test checks that suspending a batch job stops its running allocations,
+// and that resuming it only places the allocations that didn't complete.
+func TestBatchSched_SuspendResume(t *testing.T) {
+	ci.Parallel(t)
+
+	h := NewHarness(t)
+
+	node := mock.Node()
+	must.NoError(t, h.State.UpsertNode(structs.MsgTypeTestSetup, h.NextIndex(), node))
+
+	job := mock.Job()
+	job.Type = structs.JobTypeBatch
+	job.TaskGroups[0].Count = 2
+	must.NoError(t, h.State.UpsertJob(structs.MsgTypeTestSetup, h.NextIndex(), nil, job))
+
+	successful := map[string]*structs.TaskState{
+		"web": {State: structs.TaskStateDead},
+	}
+
+	// The first allocation completed, and the second one is running
+	complete := mock.Alloc()
+	complete.Job = job
+	complete.JobID = job.ID
+	complete.NodeID = node.ID
+	complete.Name = structs.AllocName(job.ID, "web", 0)
+	complete.ClientStatus = structs.AllocClientStatusComplete
+	complete.TaskStates = successful
+
+	running := mock.Alloc()
+	running.Job = job
+	running.JobID = job.ID
+	running.NodeID = node.ID
+	running.Name = structs.AllocName(job.ID, "web", 1)
+	running.ClientStatus = structs.AllocClientStatusRunning
+	must.NoError(t, h.State.UpsertAllocs(structs.MsgTypeTestSetup, h.NextIndex(),
+		[]*structs.Allocation{complete, running}))
+
+	process := func(suspended bool, triggeredBy string) {
+		_, err := h.State.UpdateJobSuspension(structs.MsgTypeTestSetup, h.NextIndex(),
+			job.Namespace, job.ID, suspended, nil)
+		must.NoError(t, err)
+		eval := &structs.Evaluation{
+			Namespace:   structs.DefaultNamespace,
+			ID:          uuid.Generate(),
+			Priority:    job.Priority,
+			TriggeredBy: triggeredBy,
+			JobID:       job.ID,
+			Status:      structs.EvalStatusPending,
+		}
+		must.NoError(t, h.State.UpsertEvals(structs.MsgTypeTestSetup, h.NextIndex(), []*structs.Evaluation{eval}))
+		must.NoError(t, h.Process(NewBatchScheduler, eval))
+	}
+
+	// Suspending the job only stops the running allocation
+	process(true, structs.EvalTriggerJobSuspend)
+	must.Len(t, 1, h.Plans)
+	stopped := h.Plans[0].NodeUpdate[node.ID]
+	must.Len(t, 1, stopped)
+	must.Eq(t, running.ID, stopped[0].ID)
+	must.Eq(t, allocSuspended, stopped[0].DesiredDescription)
+
+	// The tasks of the stopped allocation exit successfully once killed
+	out, err := h.State.AllocByID(nil, running.ID)
+	must.NoError(t, err)
+	out = out.Copy()
+	out.ClientStatus = structs.AllocClientStatusComplete
+	out.TaskStates = successful
+	must.NoError(t, h.State.UpsertAllocs(structs.MsgTypeTestSetup, h.NextIndex(), []*structs.Allocation{out}))
+
+	// Resuming the job places the stopped allocation again
+	process(false, structs.EvalTriggerJobResume)
+	must.Len(t, 2, h.Plans)
+	placed := h.Plans[1].NodeAllocation[node.ID]
+	must.Len(t, 1, placed)
+	must.Eq(t, running.Name, placed[0].Name)
+	must.Eq(t, job.Version, placed[0].Job.Version)
+
+	must.Len(t, 2, h.Evals)
+	for _, eval := range h.Evals {
+		must.Eq(t, structs.EvalStatusComplete, eval.Status)
+	}
+}
+
 // This test checks that terminal allocations that receive an in-place updated
 // are not added to the plan
 func TestBatchSched_JobModify_InPlace_Terminal(t *testing.T) {
@@ -7355,3 +7556,45 @@ func initNodeAndAllocs(t *testing.T, h *Harness, allocCount int,
 	require.NoError(t, h.State.UpsertAllocs(structs.MsgTypeTestSetup, h.NextIndex(), allocs))
 	return node, job, allocs
 }
+
+func TestServiceSched_ScoringPlugins(t *testing.T) {
+	ci.Parallel(t)
+
+	h := NewHarness(t)
+
+	nodes := []*structs.Node{mock.Node(), mock.Node()}
+	for _, node := range nodes {
+		must.NoError(t, h.State.UpsertNode(structs.MsgTypeTestSetup, h.NextIndex(), node))
+	}
+
+	// The plugin prefers the second node.
+	plugin := &testScoringPlugin{
+		name: "test",
+		scores: map[string]float64{
+			nodes[0].ID: -1,
+			nodes[1].ID: 1,
+		},
+	}
+	h.Scorers = []ScoringPlugin{plugin}
+
+	job := mock.Job()
+	job.TaskGroups[0].Count = 1
+	must.NoError(t, h.State.UpsertJob(structs.MsgTypeTestSetup, h.NextIndex(), nil, job))
+
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+		Status:      structs.EvalStatusPending,
+	}
+	must.NoError(t, h.State.UpsertEvals(structs.MsgTypeTestSetup, h.NextIndex(), []*structs.Evaluation{eval}))
+
+	must.NoError(t, h.Process(NewServiceScheduler, eval))
+	must.Len(t, 1, h.Plans)
+	must.Len(t, 1, h.Plans[0].NodeAllocation[nodes[1].ID])
+
+	alloc := h.Plans[0].NodeAllocation[nodes[1].ID][0]
+	must.MapContainsKey(t, alloc.Metrics.ScoreMetaData[0].Scores, "plugin.test")
+}
@@ -6,6 +6,7 @@ package scheduler
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/hashicorp/nomad/client/lib/idset"
 	"github.com/hashicorp/nomad/client/lib/numalib/hw"
@@ -695,6 +696,144 @@ func (iter *NodeReschedulingPenaltyIterator) Reset() {
 	iter.source.Reset()
 }
 
+// NodeLoadIterator is used to apply a score to nodes based on the recent
+// utilization reported in their heartbeats, so that placements prefer nodes
+// that are idle rather than nodes that merely have unreserved capacity.
+type NodeLoadIterator struct {
+	ctx     Context
+	source  RankIterator
+	enabled bool
+	now     func() time.Time
+}
+
+// NewNodeLoadIterator is used to create a NodeLoadIterator. It is disabled
+// until enabled by SetSchedulerConfiguration.
+func NewNodeLoadIterator(ctx Context, source RankIterator) *NodeLoadIterator {
+	return &NodeLoadIterator{
+		ctx:    ctx,
+		source: source,
+		now:    time.Now,
+	}
+}
+
+func (iter *NodeLoadIterator) SetSchedulerConfiguration(schedConfig *structs.SchedulerConfiguration) {
+	iter.enabled = schedConfig != nil && schedConfig.LoadAwareScoringEnabled
+}
+
+func (iter *NodeLoadIterator) Next() *RankedNode {
+	option := iter.source.Next()
+	if option == nil || !iter.enabled {
+		return option
+	}
+
+	utilization := option.Node.Utilization
+	if utilization.Stale(iter.now()) {
+		return option
+	}
+
+	// Score the node on its most utilized resource, from 1 for an idle node
+	// to -1 for a fully loaded one.
+	load := math.Max(utilization.CPUUtilization(option.Node), utilization.MemoryPressure)
+	score := 1 - 2*math.Min(math.Max(load, 0), 1)
+	option.Scores = append(option.Scores, score)
+	iter.ctx.Metrics().ScoreNode(option.Node, "node-load", score)
+	return option
+}
+
+func (iter *NodeLoadIterator) Reset() {
+	iter.source.Reset()
+}
+
+// scoringPluginEvalBudget is the total time a scoring plugin may spend
+// scoring nodes for a single evaluation. Each node is scored with its own
+// call, so without a budget a slow plugin would add its timeout for every
+// node visited by every placement.
+const scoringPluginEvalBudget = time.Second
+
+// ScoringPluginIterator is used to apply the scores returned by external
+// scoring plugins. A plugin that fails to score a node is skipped for the
+// rest of the placement, and a plugin that exceeds its time budget is skipped
+// for the rest of the evaluation, leaving the nodes to the built-in scoring.
+type ScoringPluginIterator struct {
+	ctx     Context
+	source  RankIterator
+	plugins []ScoringPlugin
+	job     *structs.Job
+	tg      string
+	failed  map[string]bool
+
+	// budget is the time each plugin may spend for the evaluation and spent
+	// tracks the time used so far. The iterator lives as long as its stack,
+	// so spent isn't cleared on Reset.
+	budget time.Duration
+	spent  map[string]time.Duration
+}
+
+// NewScoringPluginIterator is used to create a ScoringPluginIterator. It
+// doesn't change the scores until plugins are set.
+func NewScoringPluginIterator(ctx Context, source RankIterator) *ScoringPluginIterator {
+	return &ScoringPluginIterator{
+		ctx:    ctx,
+		source: source,
+		failed: make(map[string]bool),
+		budget: scoringPluginEvalBudget,
+		spent:  make(map[string]time.Duration),
+	}
+}
+
+func (iter *ScoringPluginIterator) SetPlugins(plugins []ScoringPlugin) {
+	iter.plugins = plugins
+}
+
+func (iter *ScoringPluginIterator) SetJob(job *structs.Job) {
+	iter.job = job
+}
+
+func (iter *ScoringPluginIterator) SetTaskGroup(tg *structs.TaskGroup) {
+	iter.tg = tg.Name
+}
+
+func (iter *ScoringPluginIterator) Next() *RankedNode {
+	option := iter.source.Next()
+	if option == nil {
+		return nil
+	}
+
+	for _, plugin := range iter.plugins {
+		name := plugin.Name()
+		if iter.failed[name] || iter.spent[name] >= iter.budget {
+			continue
+		}
+
+		start := time.Now()
+		score, ok, err := plugin.ScoreNode(iter.job, iter.tg, option.Node)
+		iter.spent[name] += time.Since(start)
+		if iter.spent[name] >= iter.budget {
+			iter.ctx.Logger().Warn("scoring plugin exceeded its time budget, falling back to built-in scoring for the evaluation",
+				"plugin", name, "budget", iter.budget)
+		}
+		if err != nil {
+			iter.ctx.Logger().Warn("scoring plugin failed, falling back to built-in scoring",
+				"plugin", name, "node_id", option.Node.ID, "error", err)
+			iter.failed[name] = true
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		score = math.Min(math.Max(score, -1), 1)
+		option.Scores = append(option.Scores, score)
+		iter.ctx.Metrics().ScoreNode(option.Node, "plugin."+name, score)
+	}
+	return option
+}
+
+func (iter *ScoringPluginIterator) Reset() {
+	iter.failed = make(map[string]bool)
+	iter.source.Reset()
+}
+
 // NodeAffinityIterator is used to resolve any affinity rules in the job or task group,
 // and apply a weighted score to nodes if they match.
 type NodeAffinityIterator struct {
@@ -777,8 +916,8 @@ func (iter *NodeAffinityIterator) Next() *RankedNode {
 func matchesAffinity(ctx Context, affinity *structs.Affinity, option *structs.Node) bool {
 	//TODO(preetha): Add a step here that filters based on computed node class for potential speedup
 	// Resolve the targets
-	lVal, lOk := resolveTarget(affinity.LTarget, option)
-	rVal, rOk := resolveTarget(affinity.RTarget, option)
+	lVal, lOk := resolveNodeTarget(ctx, affinity.LTarget, option)
+	rVal, rOk := resolveNodeTarget(ctx, affinity.RTarget, option)
 
 	// Check if satisfied
 	return checkAffinity(ctx, affinity.Operand, lVal, rVal, lOk, rOk)
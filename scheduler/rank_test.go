@@ -4,8 +4,10 @@
 package scheduler
 
 import (
+	"errors"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/client/lib/idset"
 	"github.com/hashicorp/nomad/client/lib/numalib"
@@ -13,6 +15,7 @@ import (
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
 	"github.com/stretchr/testify/require"
 )
 
@@ -2220,3 +2223,184 @@ func TestNodeAffinityIterator(t *testing.T) {
 	}
 
 }
+
+// testScoringPlugin is a scoring plugin returning fixed scores by node ID.
+type testScoringPlugin struct {
+	name   string
+	scores map[string]float64
+	err    error
+	delay  time.Duration
+	calls  int
+}
+
+func (p *testScoringPlugin) Name() string { return p.name }
+
+func (p *testScoringPlugin) ScoreNode(_ *structs.Job, _ string, node *structs.Node) (float64, bool, error) {
+	p.calls++
+	time.Sleep(p.delay)
+	if p.err != nil {
+		return 0, false, p.err
+	}
+	score, ok := p.scores[node.ID]
+	return score, ok, nil
+}
+
+func TestScoringPluginIterator(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*RankedNode{
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+	}
+
+	plugin := &testScoringPlugin{
+		name: "test",
+		scores: map[string]float64{
+			nodes[0].Node.ID: 0.5,
+			nodes[1].Node.ID: -3,
+		},
+	}
+	failing := &testScoringPlugin{name: "failing", err: errors.New("unavailable")}
+
+	job := mock.Job()
+	static := NewStaticRankIterator(ctx, nodes)
+	scoringPlugins := NewScoringPluginIterator(ctx, static)
+	scoringPlugins.SetPlugins([]ScoringPlugin{plugin, failing})
+	scoringPlugins.SetJob(job)
+	scoringPlugins.SetTaskGroup(job.TaskGroups[0])
+	scoreNorm := NewScoreNormalizationIterator(ctx, scoringPlugins)
+
+	out := collectRanked(scoreNorm)
+	must.Len(t, 3, out)
+
+	// Scores are capped, nodes without scores are left to the other scores,
+	// and the failing plugin isn't called again for the placement.
+	expectedScores := map[string]float64{
+		nodes[0].Node.ID: 0.5,
+		nodes[1].Node.ID: -1,
+		nodes[2].Node.ID: 0,
+	}
+	for _, n := range out {
+		must.Eq(t, expectedScores[n.Node.ID], n.FinalScore)
+	}
+	must.Eq(t, 3, plugin.calls)
+	must.Eq(t, 1, failing.calls)
+
+	// The failing plugin is called again for the next placement.
+	scoreNorm.Reset()
+	collectRanked(scoreNorm)
+	must.Eq(t, 2, failing.calls)
+}
+
+func TestScoringPluginIterator_Budget(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*RankedNode{
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+	}
+
+	slow := &testScoringPlugin{
+		name:  "slow",
+		delay: 20 * time.Millisecond,
+		scores: map[string]float64{
+			nodes[0].Node.ID: 1,
+			nodes[1].Node.ID: 1,
+			nodes[2].Node.ID: 1,
+		},
+	}
+
+	job := mock.Job()
+	static := NewStaticRankIterator(ctx, nodes)
+	scoringPlugins := NewScoringPluginIterator(ctx, static)
+	scoringPlugins.budget = 30 * time.Millisecond
+	scoringPlugins.SetPlugins([]ScoringPlugin{slow})
+	scoringPlugins.SetJob(job)
+	scoringPlugins.SetTaskGroup(job.TaskGroups[0])
+
+	// The plugin exhausts its budget on the second node, so the last node is
+	// left to the built-in scoring.
+	out := collectRanked(scoringPlugins)
+	must.Len(t, 3, out)
+	must.Eq(t, 2, slow.calls)
+	must.Len(t, 1, out[0].Scores)
+	must.Len(t, 1, out[1].Scores)
+	must.Len(t, 0, out[2].Scores)
+
+	// The budget covers the whole evaluation, so the plugin isn't called for
+	// the next placement either.
+	scoringPlugins.Reset()
+	collectRanked(scoringPlugins)
+	must.Eq(t, 2, slow.calls)
+}
+
+func TestNodeLoadIterator(t *testing.T) {
+	_, ctx := testContext(t)
+	now := time.Now()
+
+	nodes := []*RankedNode{
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+		{Node: mock.Node()},
+	}
+
+	// Idle node
+	nodes[0].Node.Utilization = &structs.NodeUtilization{
+		CPULoad: 0, MemoryPressure: 0, UpdatedAt: now.UnixNano()}
+	// Node scored on its memory pressure
+	nodes[1].Node.Utilization = &structs.NodeUtilization{
+		CPULoad: 0.25, MemoryPressure: 0.75, UpdatedAt: now.UnixNano()}
+	// Overloaded node
+	nodes[2].Node.Utilization = &structs.NodeUtilization{
+		CPULoad: 3, MemoryPressure: 0.5, UpdatedAt: now.UnixNano()}
+	// Stale utilization
+	nodes[3].Node.Utilization = &structs.NodeUtilization{
+		CPULoad: 1, MemoryPressure: 1, UpdatedAt: now.Add(-time.Hour).UnixNano()}
+	// nodes[4] has no utilization
+	// Node scored on its CPU used rather than its load average
+	totalCompute := nodes[5].Node.NodeResources.Processors.TotalCompute()
+	nodes[5].Node.Utilization = &structs.NodeUtilization{
+		CPULoad: 1, MemoryPressure: 0, CPUUsedMHz: int64(totalCompute / 4), UpdatedAt: now.UnixNano()}
+
+	static := NewStaticRankIterator(ctx, nodes)
+	nodeLoad := NewNodeLoadIterator(ctx, static)
+	nodeLoad.now = func() time.Time { return now }
+
+	// Nodes aren't scored unless enabled.
+	scoreNorm := NewScoreNormalizationIterator(ctx, nodeLoad)
+	for _, n := range collectRanked(scoreNorm) {
+		must.SliceEmpty(t, n.Scores)
+	}
+
+	nodes = []*RankedNode{
+		{Node: nodes[0].Node},
+		{Node: nodes[1].Node},
+		{Node: nodes[2].Node},
+		{Node: nodes[3].Node},
+		{Node: nodes[4].Node},
+		{Node: nodes[5].Node},
+	}
+	static = NewStaticRankIterator(ctx, nodes)
+	nodeLoad = NewNodeLoadIterator(ctx, static)
+	nodeLoad.now = func() time.Time { return now }
+	nodeLoad.SetSchedulerConfiguration(&structs.SchedulerConfiguration{LoadAwareScoringEnabled: true})
+	scoreNorm = NewScoreNormalizationIterator(ctx, nodeLoad)
+
+	out := collectRanked(scoreNorm)
+	must.Len(t, 6, out)
+
+	expectedScores := map[string]float64{
+		nodes[0].Node.ID: 1,
+		nodes[1].Node.ID: -0.5,
+		nodes[2].Node.ID: -1,
+		nodes[3].Node.ID: 0,
+		nodes[4].Node.ID: 0,
+		nodes[5].Node.ID: 0.5,
+	}
+	for _, n := range out {
+		must.Eq(t, expectedScores[n.Node.ID], n.FinalScore)
+	}
+}
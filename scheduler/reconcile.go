@@ -369,13 +369,18 @@ func (a *allocReconciler) handleStop(m allocMatrix) {
 // filterAndStopAll stops all allocations in an allocSet. This is useful in when
 // stopping an entire job or task group.
 func (a *allocReconciler) filterAndStopAll(set allocSet) uint64 {
+	desc := allocNotNeeded
+	if a.job != nil && a.job.Suspended && !a.job.Stop {
+		desc = allocSuspended
+	}
+
 	untainted, migrate, lost, disconnecting, reconnecting, ignore := set.filterByTainted(a.taintedNodes, a.supportsDisconnectedClients, a.now)
-	a.markStop(untainted, "", allocNotNeeded)
-	a.markStop(migrate, "", allocNotNeeded)
+	a.markStop(untainted, "", desc)
+	a.markStop(migrate, "", desc)
 	a.markStop(lost, structs.AllocClientStatusLost, allocLost)
-	a.markStop(disconnecting, "", allocNotNeeded)
-	a.markStop(reconnecting, "", allocNotNeeded)
-	a.markStop(ignore.filterByClientStatus(structs.AllocClientStatusUnknown), "", allocNotNeeded)
+	a.markStop(disconnecting, "", desc)
+	a.markStop(reconnecting, "", desc)
+	a.markStop(ignore.filterByClientStatus(structs.AllocClientStatusUnknown), "", desc)
 	return uint64(len(set))
 }
 
@@ -504,6 +509,13 @@ func (a *allocReconciler) computeGroup(groupName string, all allocSet) bool {
 	nameIndex := newAllocNameIndex(a.jobID, groupName, tg.Count, untainted.union(migrate, rescheduleNow, lost))
 	a.result.taskGroupAllocNameIndexes[groupName] = nameIndex
 
+	// Set aside the allocations being replaced by a surge update, so they
+	// are neither stopped as extra allocations nor updated again.
+	surging := 0
+	if !tg.Update.IsEmpty() && tg.Update.MaxSurge > 0 {
+		untainted, surging = a.computeSurges(untainted, desiredChanges)
+	}
+
 	// Stop any unneeded allocations and update the untainted set to not
 	// include stopped allocations.
 	isCanarying := dstate != nil && dstate.DesiredCanaries != 0 && !dstate.Promoted
@@ -556,7 +568,9 @@ func (a *allocReconciler) computeGroup(groupName string, all allocSet) bool {
 
 	underProvisionedBy = a.computeReplacements(deploymentPlaceReady, desiredChanges, place, rescheduleNow, lost, underProvisionedBy)
 
-	if deploymentPlaceReady {
+	if deploymentPlaceReady && !tg.Update.IsEmpty() && tg.Update.MaxSurge > 0 {
+		a.computeSurgeUpdates(destructive, underProvisionedBy, surging, desiredChanges, tg)
+	} else if deploymentPlaceReady {
 		a.computeDestructiveUpdates(destructive, underProvisionedBy, desiredChanges, tg)
 	} else {
 		desiredChanges.Ignore += uint64(len(destructive))
@@ -902,6 +916,72 @@ func (a *allocReconciler) computeDestructiveUpdates(destructive allocSet, underP
 	}
 }
 
+// computeSurgeUpdates places the replacements of the allocations requiring a
+// destructive update over the group count, without stopping them. At most
+// MaxSurge replacements are placed over the group count at any time, and the
+// allocations they replace are stopped by computeSurges once they are
+// healthy.
+func (a *allocReconciler) computeSurgeUpdates(destructive allocSet, underProvisionedBy, surging int,
+	desiredChanges *structs.DesiredUpdates, tg *structs.TaskGroup) {
+
+	minimum := max(min(len(destructive), underProvisionedBy, tg.Update.MaxSurge-surging), 0)
+	desiredChanges.DestructiveUpdate += uint64(minimum)
+	desiredChanges.Ignore += uint64(len(destructive) - minimum)
+	for _, alloc := range destructive.nameOrder()[:minimum] {
+		a.result.place = append(a.result.place, allocPlaceResult{
+			name:      alloc.Name,
+			taskGroup: tg,
+		})
+	}
+}
+
+// computeSurges finds the allocations of an older version of the job that
+// are being replaced by a surge update, which share their name with an
+// allocation of the current version of the job placed over the group count.
+// The replaced allocations are stopped once their replacement is healthy,
+// and ignored until then. It returns the untainted set without the replaced
+// allocations, and the number of replacements that aren't healthy yet.
+func (a *allocReconciler) computeSurges(untainted allocSet, desiredChanges *structs.DesiredUpdates) (allocSet, int) {
+	isCurrent := func(alloc *structs.Allocation) bool {
+		return alloc.Job.Version == a.job.Version && alloc.Job.CreateIndex == a.job.CreateIndex
+	}
+
+	// Canaries share their name with the allocations they replace as well,
+	// but those are stopped by computeStop once the canaries are promoted.
+	replacements := make(map[string]*structs.Allocation)
+	for _, alloc := range untainted {
+		if !alloc.TerminalStatus() && !alloc.DeploymentStatus.IsCanary() && isCurrent(alloc) {
+			replacements[alloc.Name] = alloc
+		}
+	}
+
+	replaced := make(allocSet)
+	surging := 0
+	for id, alloc := range untainted {
+		if alloc.TerminalStatus() || alloc.DeploymentStatus.IsCanary() || isCurrent(alloc) {
+			continue
+		}
+		replacement, ok := replacements[alloc.Name]
+		if !ok {
+			continue
+		}
+
+		replaced[id] = alloc
+		if replacement.DeploymentStatus.IsHealthy() {
+			a.result.stop = append(a.result.stop, allocStopResult{
+				alloc:             alloc,
+				statusDescription: allocUpdating,
+			})
+			desiredChanges.Stop++
+		} else {
+			surging++
+			desiredChanges.Ignore++
+		}
+	}
+
+	return untainted.difference(replaced), surging
+}
+
 func (a *allocReconciler) computeMigrations(desiredChanges *structs.DesiredUpdates, migrate allocSet, tg *structs.TaskGroup, isCanarying bool) {
 	desiredChanges.Migrate += uint64(len(migrate))
 	for _, alloc := range migrate.nameOrder() {
@@ -1146,6 +1226,10 @@ func (a *allocReconciler) reconcileReconnecting(reconnecting allocSet, all alloc
 
 		// Find replacement allocations and decide which one to stop. A
 		// reconnecting allocation may have multiple replacements.
+		var reconcile string
+		if tg := a.job.LookupTaskGroup(reconnectingAlloc.TaskGroup); tg != nil {
+			reconcile = tg.Disconnect.ReconcileStrategy()
+		}
 		for _, replacementAlloc := range all {
 
 			// Skip allocations that are not a replacement of the one
@@ -1161,7 +1245,7 @@ func (a *allocReconciler) reconcileReconnecting(reconnecting allocSet, all alloc
 			}
 
 			// Pick which allocation we want to keep.
-			keepAlloc := pickReconnectingAlloc(reconnectingAlloc, replacementAlloc)
+			keepAlloc := pickReconnectingAlloc(reconcile, reconnectingAlloc, replacementAlloc)
 			if keepAlloc == replacementAlloc {
 				// The replacement allocation is preferred, so stop the one
 				// reconnecting if not stopped yet.
@@ -1169,7 +1253,7 @@ func (a *allocReconciler) reconcileReconnecting(reconnecting allocSet, all alloc
 					stop[reconnectingAlloc.ID] = reconnectingAlloc
 					a.result.stop = append(a.result.stop, allocStopResult{
 						alloc:             reconnectingAlloc,
-						statusDescription: allocNotNeeded,
+						statusDescription: allocReplacedOnReconnect,
 					})
 				}
 			} else {
@@ -1200,9 +1284,10 @@ func (a *allocReconciler) reconcileReconnecting(reconnecting allocSet, all alloc
 // one that is reconnecting and one of its replacements.
 //
 // This function is not commutative, meaning that pickReconnectingAlloc(A, B)
-// is not the same as pickReconnectingAlloc(B, A). Preference is given to keep
-// the original allocation when possible.
-func pickReconnectingAlloc(original *structs.Allocation, replacement *structs.Allocation) *structs.Allocation {
+// is not the same as pickReconnectingAlloc(B, A). Unless the task group
+// reconcile strategy says otherwise, preference is given to keep the original
+// allocation when possible.
+func pickReconnectingAlloc(reconcile string, original *structs.Allocation, replacement *structs.Allocation) *structs.Allocation {
 	// Check if the replacement is newer.
 	// Always prefer the replacement if true.
 	replacementIsNewer := replacement.Job.Version > original.Job.Version ||
@@ -1211,6 +1296,13 @@ func pickReconnectingAlloc(original *structs.Allocation, replacement *structs.Al
 		return replacement
 	}
 
+	switch reconcile {
+	case structs.DisconnectReconcileKeepOriginal:
+		return original
+	case structs.DisconnectReconcileKeepReplacement:
+		return replacement
+	}
+
 	// Check if the replacement has better placement score.
 	// If any of the scores is not available, only pick the replacement if
 	// itself does have scores.
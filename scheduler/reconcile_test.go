@@ -2850,6 +2850,129 @@ func TestReconciler_CreateDeployment_RollingUpgrade_Destructive(t *testing.T) {
 	assertNamesHaveIndexes(t, intRange(0, 3), destructiveResultsToNames(r.destructiveUpdate))
 }
 
+// Tests the reconciler places the replacements of destructive updates over
+// the group count when the update strategy has a max surge
+func TestReconciler_SurgeUpdate_Place(t *testing.T) {
+	ci.Parallel(t)
+
+	job := mock.Job()
+	job.TaskGroups[0].Update = noCanaryUpdate.Copy()
+	job.TaskGroups[0].Update.MaxSurge = 2
+	oldJob := job.Copy()
+	job.Version++
+
+	// Create 10 allocations from the old job
+	var allocs []*structs.Allocation
+	for i := 0; i < 10; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = oldJob
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, uint(i))
+		alloc.TaskGroup = job.TaskGroups[0].Name
+		allocs = append(allocs, alloc)
+	}
+
+	reconciler := NewAllocReconciler(testlog.HCLogger(t), allocUpdateFnDestructive, false, job.ID, job,
+		nil, allocs, nil, "", 50, true)
+	r := reconciler.Compute()
+
+	d := structs.NewDeployment(job, 50)
+	d.TaskGroups[job.TaskGroups[0].Name] = &structs.DeploymentState{
+		DesiredTotal: 10,
+	}
+
+	// Assert the replacements are placed without stopping the allocations
+	assertResults(t, r, &resultExpectation{
+		createDeployment:  d,
+		deploymentUpdates: nil,
+		place:             2,
+		desiredTGUpdates: map[string]*structs.DesiredUpdates{
+			job.TaskGroups[0].Name: {
+				DestructiveUpdate: 2,
+				Ignore:            8,
+			},
+		},
+	})
+
+	assertNamesHaveIndexes(t, intRange(0, 1), placeResultsToNames(r.place))
+}
+
+// Tests the reconciler stops the allocations replaced by a surge update once
+// their replacement is healthy, and keeps at most max surge replacements over
+// the group count
+func TestReconciler_SurgeUpdate_Replace(t *testing.T) {
+	ci.Parallel(t)
+
+	job := mock.Job()
+	job.TaskGroups[0].Update = noCanaryUpdate.Copy()
+	job.TaskGroups[0].Update.MaxSurge = 2
+	oldJob := job.Copy()
+	job.Version++
+
+	d := structs.NewDeployment(job, 50)
+	d.TaskGroups[job.TaskGroups[0].Name] = &structs.DeploymentState{
+		DesiredTotal: 10,
+		PlacedAllocs: 2,
+	}
+
+	// Create 10 allocations from the old job
+	var allocs []*structs.Allocation
+	for i := 0; i < 10; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = oldJob
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, uint(i))
+		alloc.TaskGroup = job.TaskGroups[0].Name
+		allocs = append(allocs, alloc)
+	}
+
+	// Create the replacements of the first two allocations, of which only
+	// the first one is healthy
+	handled := make(map[string]allocUpdateType)
+	for i := 0; i < 2; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = uuid.Generate()
+		alloc.Name = structs.AllocName(job.ID, job.TaskGroups[0].Name, uint(i))
+		alloc.TaskGroup = job.TaskGroups[0].Name
+		alloc.DeploymentID = d.ID
+		alloc.DeploymentStatus = &structs.AllocDeploymentStatus{}
+		if i == 0 {
+			alloc.DeploymentStatus.Healthy = pointer.Of(true)
+		}
+		allocs = append(allocs, alloc)
+		handled[alloc.ID] = allocUpdateFnIgnore
+	}
+
+	mockUpdateFn := allocUpdateFnMock(handled, allocUpdateFnDestructive)
+	reconciler := NewAllocReconciler(testlog.HCLogger(t), mockUpdateFn, false, job.ID, job,
+		d, allocs, nil, "", 50, true)
+	r := reconciler.Compute()
+
+	// Assert the allocation with a healthy replacement is stopped, and a
+	// single replacement is placed since the other one isn't healthy yet
+	assertResults(t, r, &resultExpectation{
+		createDeployment:  nil,
+		deploymentUpdates: nil,
+		place:             1,
+		stop:              1,
+		desiredTGUpdates: map[string]*structs.DesiredUpdates{
+			job.TaskGroups[0].Name: {
+				DestructiveUpdate: 1,
+				Stop:              1,
+				Ignore:            10,
+			},
+		},
+	})
+
+	assertNamesHaveIndexes(t, intRange(0, 0), stopResultsToNames(r.stop))
+	assertNamesHaveIndexes(t, intRange(2, 2), placeResultsToNames(r.place))
+	require.Equal(t, allocs[0].ID, r.stop[0].alloc.ID)
+}
+
 // Tests the reconciler creates a deployment for inplace updates
 func TestReconciler_CreateDeployment_RollingUpgrade_Inplace(t *testing.T) {
 	ci.Parallel(t)
@@ -5336,6 +5459,7 @@ func TestReconciler_Disconnected_Client(t *testing.T) {
 		replaceFailedReplacement     bool
 		shouldStopOnDisconnectedNode bool
 		maxDisconnect                *time.Duration
+		disconnect                   *structs.DisconnectStrategy
 		expected                     *resultExpectation
 	}
 
@@ -5398,6 +5522,52 @@ func TestReconciler_Disconnected_Client(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:                    "keep-original-with-lower-node-score",
+			allocCount:              4,
+			replace:                 true,
+			disconnectedAllocCount:  1,
+			disconnectedAllocStatus: structs.AllocClientStatusRunning,
+
+			disconnectedAllocStates:      disconnectAllocState,
+			shouldStopOnDisconnectedNode: false,
+			nodeScoreIncrement:           1,
+			disconnect: &structs.DisconnectStrategy{
+				Reconcile: structs.DisconnectReconcileKeepOriginal,
+			},
+			expected: &resultExpectation{
+				stop:             1,
+				reconnectUpdates: 1,
+				desiredTGUpdates: map[string]*structs.DesiredUpdates{
+					"web": {
+						Stop:   1,
+						Ignore: 4,
+					},
+				},
+			},
+		},
+		{
+			name:                    "keep-replacement-with-lower-node-score",
+			allocCount:              3,
+			replace:                 true,
+			disconnectedAllocCount:  1,
+			disconnectedAllocStatus: structs.AllocClientStatusRunning,
+
+			disconnectedAllocStates:      disconnectAllocState,
+			shouldStopOnDisconnectedNode: true,
+			disconnect: &structs.DisconnectStrategy{
+				Reconcile: structs.DisconnectReconcileKeepReplacement,
+			},
+			expected: &resultExpectation{
+				stop: 1,
+				desiredTGUpdates: map[string]*structs.DesiredUpdates{
+					"web": {
+						Stop:   1,
+						Ignore: 3,
+					},
+				},
+			},
+		},
 		{
 			name:                    "stop-original-failed-on-reconnect",
 			allocCount:              4,
@@ -5691,6 +5861,7 @@ func TestReconciler_Disconnected_Client(t *testing.T) {
 				if tc.maxDisconnect != nil {
 					alloc.Job.TaskGroups[0].MaxClientDisconnect = tc.maxDisconnect
 				}
+				alloc.Job.TaskGroups[0].Disconnect = tc.disconnect
 
 				if disconnectedAllocCount > 0 {
 					alloc.ClientStatus = tc.disconnectedAllocStatus
@@ -440,11 +440,12 @@ func shouldFilter(alloc *structs.Allocation, isBatch bool) (untainted, ignore bo
 	// Allocs from batch jobs should be filtered when the desired status
 	// is terminal and the client did not finish or when the client
 	// status is failed so that they will be replaced. If they are
-	// complete but not failed, they shouldn't be replaced.
+	// complete but not failed, they shouldn't be replaced, unless they were
+	// stopped because the job was suspended.
 	if isBatch {
 		switch alloc.DesiredStatus {
 		case structs.AllocDesiredStatusStop:
-			if alloc.RanSuccessfully() {
+			if alloc.RanSuccessfully() && alloc.DesiredDescription != allocSuspended {
 				return true, false
 			}
 
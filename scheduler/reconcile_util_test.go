@@ -833,6 +833,7 @@ func TestReconcile_shouldFilter(t *testing.T) {
 		batch         bool
 		failed        bool
 		desiredStatus string
+		desiredDesc   string
 		clientStatus  string
 
 		untainted bool
@@ -865,6 +866,16 @@ func TestReconcile_shouldFilter(t *testing.T) {
 			untainted:     false,
 			ignore:        true,
 		},
+		{
+			description:   "batch suspended",
+			batch:         true,
+			failed:        false,
+			desiredStatus: structs.AllocDesiredStatusStop,
+			desiredDesc:   allocSuspended,
+			clientStatus:  structs.AllocClientStatusComplete,
+			untainted:     false,
+			ignore:        true,
+		},
 		{
 			description:   "batch evicted",
 			batch:         true,
@@ -922,9 +933,10 @@ func TestReconcile_shouldFilter(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			alloc := &structs.Allocation{
-				DesiredStatus: tc.desiredStatus,
-				TaskStates:    map[string]*structs.TaskState{"task": {State: structs.TaskStateDead, Failed: tc.failed}},
-				ClientStatus:  tc.clientStatus,
+				DesiredStatus:      tc.desiredStatus,
+				DesiredDescription: tc.desiredDesc,
+				TaskStates:         map[string]*structs.TaskState{"task": {State: structs.TaskStateDead, Failed: tc.failed}},
+				ClientStatus:       tc.clientStatus,
 			}
 
 			untainted, ignore := shouldFilter(alloc, tc.batch)
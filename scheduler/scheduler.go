@@ -118,6 +118,9 @@ type State interface {
 	// CSIVolumeByID fetch CSI volumes, containing controller jobs
 	CSIVolumesByNodeID(memdb.WatchSet, string, string) (memdb.ResultIterator, error)
 
+	// LocalVolumeClaim returns the local volume claim of an allocation name
+	LocalVolumeClaim(ws memdb.WatchSet, namespace, jobID, allocName string) (*structs.LocalVolumeClaim, error)
+
 	// LatestIndex returns the greatest index value for all indexes.
 	LatestIndex() (uint64, error)
 }
@@ -143,9 +146,30 @@ type Planner interface {
 	// that on leader changes, the evaluation will be reblocked properly.
 	ReblockEval(*structs.Evaluation) error
 
+	// UpdateEvalTrace is used to store the trace of the decisions made while
+	// processing an evaluation. It is only called when evaluation tracing is
+	// enabled in the scheduler configuration.
+	UpdateEvalTrace(*structs.EvalTrace) error
+
 	// ServersMeetMinimumVersion returns whether the Nomad servers in the
 	// worker's region are at least on the given Nomad version. The
 	// checkFailedServers parameter specifies whether version for the failed
 	// servers should be verified.
 	ServersMeetMinimumVersion(minVersion *version.Version, checkFailedServers bool) bool
+
+	// ScoringPlugins returns the external scoring plugins that apply to the
+	// job, if any.
+	ScoringPlugins(job *structs.Job) []ScoringPlugin
+}
+
+// ScoringPlugin is an external service that adjusts the score of the nodes
+// ranked for the placements of a job.
+type ScoringPlugin interface {
+	// Name returns the name of the plugin, used to report its scores.
+	Name() string
+
+	// ScoreNode returns the score of the node, between -1 and 1, for placing
+	// an allocation of the task group. It returns false if the node should
+	// only be scored by the scheduler.
+	ScoreNode(job *structs.Job, tg string, node *structs.Node) (float64, bool, error)
 }
@@ -51,6 +51,13 @@ type SystemScheduler struct {
 
 	failedTGAllocs map[string]*structs.AllocMetric
 	queuedAllocs   map[string]int
+
+	// warnings are the non-fatal warnings raised while placing allocations.
+	warnings []*structs.JobWarning
+
+	// trace is the trace of the last scheduling attempt, or nil if
+	// evaluation tracing is disabled.
+	trace *structs.EvalTrace
 }
 
 // NewSystemScheduler is a factory function to instantiate a new system
@@ -91,10 +98,13 @@ func (s *SystemScheduler) Process(eval *structs.Evaluation) (err error) {
 	// Update our logger with the eval's information
 	s.logger = s.logger.With("eval_id", eval.ID, "job_id", eval.JobID, "namespace", eval.Namespace)
 
+	// Store the trace of the evaluation once it has been processed
+	defer func() { updateEvalTrace(s.logger, s.planner, s.trace) }()
+
 	// Verify the evaluation trigger reason is understood
 	if !s.canHandle(eval.TriggeredBy) {
 		desc := fmt.Sprintf("scheduler cannot handle '%s' evaluation reason", eval.TriggeredBy)
-		return setStatus(s.logger, s.planner, s.eval, s.nextEval, nil, s.failedTGAllocs, structs.EvalStatusFailed, desc,
+		return setStatus(s.logger, s.planner, s.eval, s.nextEval, nil, s.failedTGAllocs, s.warnings, structs.EvalStatusFailed, desc,
 			s.queuedAllocs, "")
 	}
 
@@ -107,14 +117,14 @@ func (s *SystemScheduler) Process(eval *structs.Evaluation) (err error) {
 	progress := func() bool { return progressMade(s.planResult) }
 	if err := retryMax(limit, s.process, progress); err != nil {
 		if statusErr, ok := err.(*SetStatusError); ok {
-			return setStatus(s.logger, s.planner, s.eval, s.nextEval, nil, s.failedTGAllocs, statusErr.EvalStatus, err.Error(),
+			return setStatus(s.logger, s.planner, s.eval, s.nextEval, nil, s.failedTGAllocs, s.warnings, statusErr.EvalStatus, err.Error(),
 				s.queuedAllocs, "")
 		}
 		return err
 	}
 
 	// Update the status to complete
-	return setStatus(s.logger, s.planner, s.eval, s.nextEval, nil, s.failedTGAllocs, structs.EvalStatusComplete, "",
+	return setStatus(s.logger, s.planner, s.eval, s.nextEval, nil, s.failedTGAllocs, s.warnings, structs.EvalStatusComplete, "",
 		s.queuedAllocs, "")
 }
 
@@ -147,12 +157,16 @@ func (s *SystemScheduler) process() (bool, error) {
 	// Create a plan
 	s.plan = s.eval.MakePlan(s.job)
 
-	// Reset the failed allocations
+	// Reset the failed allocations and warnings
 	s.failedTGAllocs = nil
+	s.warnings = nil
 
 	// Create an evaluation context
 	s.ctx = NewEvalContext(s.eventsCh, s.state, s.plan, s.logger)
 
+	// Start a new trace, only the last scheduling attempt is traced
+	s.trace = newEvalTrace(s.ctx, s.eval)
+
 	// Construct the placement stack
 	s.stack = NewSystemStack(s.sysbatch, s.ctx)
 	if !s.job.Stopped() {
@@ -372,6 +386,7 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 
 		// Attempt to match the task group
 		option := s.stack.Select(missing.TaskGroup, &SelectOptions{AllocName: missing.Name})
+		tracePlacement(s.trace, s.ctx, tgName, missing.Name, option, false)
 
 		if option == nil {
 			// If the task can't be placed on this node, update reporting data
@@ -444,6 +459,11 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 		// Compute top K scoring node metadata
 		s.ctx.Metrics().PopulateScoreMetaData()
 
+		// The system stack doesn't score affinities, so only oversubscription
+		// raises warnings
+		s.warnings, _ = structs.MergeJobWarnings(s.warnings,
+			oversubscriptionWarnings(missing.TaskGroup, option)...)
+
 		// Set fields based on if we found an allocation option
 		resources := &structs.AllocatedResources{
 			Tasks:          option.TaskResources,
@@ -61,6 +61,7 @@ type GenericStack struct {
 
 	distinctHostsConstraint    *DistinctHostsIterator
 	distinctPropertyConstraint *DistinctPropertyIterator
+	maxAllocs                  *MaxAllocsIterator
 	binPack                    *BinPackIterator
 	jobAntiAff                 *JobAntiAffinityIterator
 	nodeReschedulingPenalty    *NodeReschedulingPenaltyIterator
@@ -68,6 +69,8 @@ type GenericStack struct {
 	maxScore                   *MaxScoreIterator
 	nodeAffinity               *NodeAffinityIterator
 	spread                     *SpreadIterator
+	nodeLoad                   *NodeLoadIterator
+	scoringPlugins             *ScoringPluginIterator
 	scoreNorm                  *ScoreNormalizationIterator
 }
 
@@ -105,11 +108,15 @@ func (s *GenericStack) SetJob(job *structs.Job) {
 	s.jobConstraint.SetConstraints(job.Constraints)
 	s.distinctHostsConstraint.SetJob(job)
 	s.distinctPropertyConstraint.SetJob(job)
+	s.maxAllocs.SetJob(job)
 	s.binPack.SetJob(job)
 	s.jobAntiAff.SetJob(job)
 	s.nodeAffinity.SetJob(job)
 	s.spread.SetJob(job)
+	s.scoringPlugins.SetJob(job)
 	s.ctx.Eligibility().SetJob(job)
+	s.taskGroupHostVolumes.SetNamespace(job.Namespace)
+	s.taskGroupHostVolumes.SetJobID(job.ID)
 	s.taskGroupCSIVolumes.SetNamespace(job.Namespace)
 	s.taskGroupCSIVolumes.SetJobID(job.ID)
 
@@ -123,6 +130,13 @@ func (s *GenericStack) SetJob(job *structs.Job) {
 // on the node pool being used.
 func (s *GenericStack) SetSchedulerConfiguration(schedConfig *structs.SchedulerConfiguration) {
 	s.binPack.SetSchedulerConfiguration(schedConfig)
+	s.nodeLoad.SetSchedulerConfiguration(schedConfig)
+}
+
+// SetScoringPlugins sets the external scoring plugins that adjust the score of
+// nodes for the job.
+func (s *GenericStack) SetScoringPlugins(plugins []ScoringPlugin) {
+	s.scoringPlugins.SetPlugins(plugins)
 }
 
 func (s *GenericStack) Select(tg *structs.TaskGroup, options *SelectOptions) *RankedNode {
@@ -172,6 +186,7 @@ func (s *GenericStack) Select(tg *structs.TaskGroup, options *SelectOptions) *Ra
 	}
 	s.nodeAffinity.SetTaskGroup(tg)
 	s.spread.SetTaskGroup(tg)
+	s.scoringPlugins.SetTaskGroup(tg)
 
 	if s.nodeAffinity.hasAffinities() || s.spread.hasSpreads() {
 		// scoring spread across all nodes has quadratic behavior, so
@@ -213,6 +228,7 @@ type SystemStack struct {
 	taskGroupNetwork     *NetworkChecker
 
 	distinctPropertyConstraint *DistinctPropertyIterator
+	maxAllocs                  *MaxAllocsIterator
 	binPack                    *BinPackIterator
 	scoreNorm                  *ScoreNormalizationIterator
 }
@@ -271,12 +287,15 @@ func NewSystemStack(sysbatch bool, ctx Context) *SystemStack {
 	// Filter on distinct property constraints.
 	s.distinctPropertyConstraint = NewDistinctPropertyIterator(ctx, s.wrappedChecks)
 
+	// Filter on the max allocs of the nodes and the job.
+	s.maxAllocs = NewMaxAllocsIterator(ctx, s.distinctPropertyConstraint)
+
 	// Create the quota iterator to determine if placements would result in
 	// the quota attached to the namespace of the job to go over.
 	// Note: the quota iterator must be the last feasibility iterator before
 	// we upgrade to ranking, or our quota usage will include ineligible
 	// nodes!
-	s.quota = NewQuotaIterator(ctx, s.distinctPropertyConstraint)
+	s.quota = NewQuotaIterator(ctx, s.maxAllocs)
 
 	// Upgrade from feasible to rank iterator
 	rankSource := NewFeasibleRankIterator(ctx, s.quota)
@@ -315,8 +334,11 @@ func (s *SystemStack) SetNodes(baseNodes []*structs.Node) {
 func (s *SystemStack) SetJob(job *structs.Job) {
 	s.jobConstraint.SetConstraints(job.Constraints)
 	s.distinctPropertyConstraint.SetJob(job)
+	s.maxAllocs.SetJob(job)
 	s.binPack.SetJob(job)
 	s.ctx.Eligibility().SetJob(job)
+	s.taskGroupHostVolumes.SetNamespace(job.Namespace)
+	s.taskGroupHostVolumes.SetJobID(job.ID)
 	s.taskGroupCSIVolumes.SetNamespace(job.Namespace)
 	s.taskGroupCSIVolumes.SetJobID(job.ID)
 
@@ -423,12 +445,15 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 	// Filter on distinct property constraints.
 	s.distinctPropertyConstraint = NewDistinctPropertyIterator(ctx, s.distinctHostsConstraint)
 
+	// Filter on the max allocs of the nodes and the job.
+	s.maxAllocs = NewMaxAllocsIterator(ctx, s.distinctPropertyConstraint)
+
 	// Create the quota iterator to determine if placements would result in
 	// the quota attached to the namespace of the job to go over.
 	// Note: the quota iterator must be the last feasibility iterator before
 	// we upgrade to ranking, or our quota usage will include ineligible
 	// nodes!
-	s.quota = NewQuotaIterator(ctx, s.distinctPropertyConstraint)
+	s.quota = NewQuotaIterator(ctx, s.maxAllocs)
 
 	// Upgrade from feasible to rank iterator
 	rankSource := NewFeasibleRankIterator(ctx, s.quota)
@@ -451,8 +476,14 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 	// Apply scores based on spread block
 	s.spread = NewSpreadIterator(ctx, s.nodeAffinity)
 
+	// Apply scores based on the recent utilization of nodes
+	s.nodeLoad = NewNodeLoadIterator(ctx, s.spread)
+
+	// Apply scores from external scoring plugins
+	s.scoringPlugins = NewScoringPluginIterator(ctx, s.nodeLoad)
+
 	// Add the preemption options scoring iterator
-	preemptionScorer := NewPreemptionScoringIterator(ctx, s.spread)
+	preemptionScorer := NewPreemptionScoringIterator(ctx, s.scoringPlugins)
 
 	// Normalizes scores by averaging them across various scorers
 	s.scoreNorm = NewScoreNormalizationIterator(ctx, preemptionScorer)
@@ -45,6 +45,14 @@ func (r *RejectPlan) ReblockEval(*structs.Evaluation) error {
 	return nil
 }
 
+func (r *RejectPlan) UpdateEvalTrace(*structs.EvalTrace) error {
+	return nil
+}
+
+func (r *RejectPlan) ScoringPlugins(*structs.Job) []ScoringPlugin {
+	return nil
+}
+
 // Harness is a lightweight testing harness for schedulers. It manages a state
 // store copy and provides the planner interface. It can be extended for various
 // testing uses or for invoking the scheduler without side effects.
@@ -59,6 +67,10 @@ type Harness struct {
 	Evals        []*structs.Evaluation
 	CreateEvals  []*structs.Evaluation
 	ReblockEvals []*structs.Evaluation
+	EvalTraces   []*structs.EvalTrace
+
+	// Scorers are the scoring plugins returned for every job.
+	Scorers []ScoringPlugin
 
 	nextIndex     uint64
 	nextIndexLock sync.Mutex
@@ -253,10 +265,29 @@ func (h *Harness) ReblockEval(eval *structs.Evaluation) error {
 	return nil
 }
 
+func (h *Harness) UpdateEvalTrace(trace *structs.EvalTrace) error {
+	// Ensure sequential plan application
+	h.planLock.Lock()
+	defer h.planLock.Unlock()
+
+	// Store the trace
+	h.EvalTraces = append(h.EvalTraces, trace)
+
+	// Check for custom planner
+	if h.Planner != nil {
+		return h.Planner.UpdateEvalTrace(trace)
+	}
+	return nil
+}
+
 func (h *Harness) ServersMeetMinimumVersion(_ *version.Version, _ bool) bool {
 	return h.serversMeetMinimumVersion
 }
 
+func (h *Harness) ScoringPlugins(*structs.Job) []ScoringPlugin {
+	return h.Scorers
+}
+
 // NextIndex returns the next index
 func (h *Harness) NextIndex() uint64 {
 	h.nextIndexLock.Lock()
@@ -619,8 +619,8 @@ func renderTemplatesUpdated(a, b *structs.RestartPolicy, msg string) comparison
 // setStatus is used to update the status of the evaluation
 func setStatus(logger log.Logger, planner Planner,
 	eval, nextEval, spawnedBlocked *structs.Evaluation,
-	tgMetrics map[string]*structs.AllocMetric, status, desc string,
-	queuedAllocs map[string]int, deploymentID string) error {
+	tgMetrics map[string]*structs.AllocMetric, warnings []*structs.JobWarning,
+	status, desc string, queuedAllocs map[string]int, deploymentID string) error {
 
 	logger.Debug("setting eval status", "status", status)
 	newEval := eval.Copy()
@@ -628,6 +628,7 @@ func setStatus(logger log.Logger, planner Planner,
 	newEval.StatusDescription = desc
 	newEval.DeploymentID = deploymentID
 	newEval.FailedTGAllocs = tgMetrics
+	newEval.Warnings = warnings
 	if nextEval != nil {
 		newEval.NextEval = nextEval.ID
 	}
@@ -641,6 +642,63 @@ func setStatus(logger log.Logger, planner Planner,
 	return planner.UpdateEval(newEval)
 }
 
+// newEvalTrace returns a new trace for the evaluation and enables tracing in
+// the context, or returns nil if evaluation tracing is disabled.
+func newEvalTrace(ctx *EvalContext, eval *structs.Evaluation) *structs.EvalTrace {
+	_, schedConfig, err := ctx.State().SchedulerConfig()
+	if err != nil || schedConfig == nil || !schedConfig.EvalTraceConfig.Enabled {
+		return nil
+	}
+
+	ctx.EnableTrace(schedConfig.EvalTraceConfig.EffectiveMaxNodes())
+	return &structs.EvalTrace{
+		EvalID:    eval.ID,
+		Namespace: eval.Namespace,
+		JobID:     eval.JobID,
+	}
+}
+
+// tracePlacement records the outcome of selecting a node for an allocation
+// in the evaluation trace. It must be called before the context is reset for
+// the next placement.
+func tracePlacement(trace *structs.EvalTrace, ctx Context, tgName, allocName string,
+	option *RankedNode, preempt bool) {
+
+	if trace == nil {
+		return
+	}
+
+	metrics := ctx.Metrics()
+	metrics.PopulateScoreMetaData()
+
+	placement := metrics.TracePlacement()
+	if placement == nil {
+		return
+	}
+	placement.TaskGroup = tgName
+	placement.AllocName = allocName
+	placement.PreemptionAttempted = preempt
+	if option != nil {
+		placement.NodeID = option.Node.ID
+		for _, alloc := range option.PreemptedAllocs {
+			placement.PreemptedAllocs = append(placement.PreemptedAllocs, alloc.ID)
+		}
+	}
+
+	trace.Placements = append(trace.Placements, placement)
+}
+
+// updateEvalTrace stores the evaluation trace, if any. Failing to store the
+// trace doesn't fail the evaluation.
+func updateEvalTrace(logger log.Logger, planner Planner, trace *structs.EvalTrace) {
+	if trace == nil {
+		return
+	}
+	if err := planner.UpdateEvalTrace(trace); err != nil {
+		logger.Warn("failed to store evaluation trace", "error", err)
+	}
+}
+
 // inplaceUpdate attempts to update allocations in-place where possible. It
 // returns the allocs that couldn't be done inplace and then those that could.
 func inplaceUpdate(ctx Context, eval *structs.Evaluation, job *structs.Job,
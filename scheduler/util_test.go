@@ -704,7 +704,7 @@ func TestSetStatus(t *testing.T) {
 	eval := mock.Eval()
 	status := "a"
 	desc := "b"
-	require.NoError(t, setStatus(logger, h, eval, nil, nil, nil, status, desc, nil, ""))
+	require.NoError(t, setStatus(logger, h, eval, nil, nil, nil, nil, status, desc, nil, ""))
 	require.Equal(t, 1, len(h.Evals), "setStatus() didn't update plan: %v", h.Evals)
 
 	newEval := h.Evals[0]
@@ -714,7 +714,7 @@ func TestSetStatus(t *testing.T) {
 	// Test next evals
 	h = NewHarness(t)
 	next := mock.Eval()
-	require.NoError(t, setStatus(logger, h, eval, next, nil, nil, status, desc, nil, ""))
+	require.NoError(t, setStatus(logger, h, eval, next, nil, nil, nil, status, desc, nil, ""))
 	require.Equal(t, 1, len(h.Evals), "setStatus() didn't update plan: %v", h.Evals)
 
 	newEval = h.Evals[0]
@@ -723,7 +723,7 @@ func TestSetStatus(t *testing.T) {
 	// Test blocked evals
 	h = NewHarness(t)
 	blocked := mock.Eval()
-	require.NoError(t, setStatus(logger, h, eval, nil, blocked, nil, status, desc, nil, ""))
+	require.NoError(t, setStatus(logger, h, eval, nil, blocked, nil, nil, status, desc, nil, ""))
 	require.Equal(t, 1, len(h.Evals), "setStatus() didn't update plan: %v", h.Evals)
 
 	newEval = h.Evals[0]
@@ -732,7 +732,7 @@ func TestSetStatus(t *testing.T) {
 	// Test metrics
 	h = NewHarness(t)
 	metrics := map[string]*structs.AllocMetric{"foo": nil}
-	require.NoError(t, setStatus(logger, h, eval, nil, nil, metrics, status, desc, nil, ""))
+	require.NoError(t, setStatus(logger, h, eval, nil, nil, metrics, nil, status, desc, nil, ""))
 	require.Equal(t, 1, len(h.Evals), "setStatus() didn't update plan: %v", h.Evals)
 
 	newEval = h.Evals[0]
@@ -743,7 +743,7 @@ func TestSetStatus(t *testing.T) {
 	h = NewHarness(t)
 	queuedAllocs := map[string]int{"web": 1}
 
-	require.NoError(t, setStatus(logger, h, eval, nil, nil, metrics, status, desc, queuedAllocs, ""))
+	require.NoError(t, setStatus(logger, h, eval, nil, nil, metrics, nil, status, desc, queuedAllocs, ""))
 	require.Equal(t, 1, len(h.Evals), "setStatus() didn't update plan: %v", h.Evals)
 
 	newEval = h.Evals[0]
@@ -751,11 +751,20 @@ func TestSetStatus(t *testing.T) {
 
 	h = NewHarness(t)
 	dID := uuid.Generate()
-	require.NoError(t, setStatus(logger, h, eval, nil, nil, metrics, status, desc, queuedAllocs, dID))
+	require.NoError(t, setStatus(logger, h, eval, nil, nil, metrics, nil, status, desc, queuedAllocs, dID))
 	require.Equal(t, 1, len(h.Evals), "setStatus() didn't update plan: %v", h.Evals)
 
 	newEval = h.Evals[0]
 	require.Equal(t, dID, newEval.DeploymentID, "setStatus() didn't set deployment id correctly: %v", newEval)
+
+	// Test warnings
+	h = NewHarness(t)
+	warnings := []*structs.JobWarning{{Type: structs.JobWarningTypeAffinity, TaskGroup: "web", Message: "foo"}}
+	require.NoError(t, setStatus(logger, h, eval, nil, nil, nil, warnings, status, desc, nil, ""))
+	require.Equal(t, 1, len(h.Evals), "setStatus() didn't update plan: %v", h.Evals)
+
+	newEval = h.Evals[0]
+	require.Equal(t, warnings, newEval.Warnings, "setStatus() didn't set warnings correctly: %v", newEval)
 }
 
 func TestInplaceUpdate_ChangedTaskGroup(t *testing.T) {
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package scheduler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// placementWarnings returns the non-fatal warnings raised by placing an
+// allocation of the task group on the selected node, which are reported on the
+// evaluation and persisted in the job summary. The messages don't depend on the
+// node so that the warnings of multiple placements are deduplicated.
+func placementWarnings(ctx Context, job *structs.Job, tg *structs.TaskGroup, option *RankedNode) []*structs.JobWarning {
+	if option == nil {
+		return nil
+	}
+	warnings := affinityWarnings(ctx, job, tg, option.Node)
+	return append(warnings, oversubscriptionWarnings(tg, option)...)
+}
+
+// affinityWarnings returns a warning for each affinity of the job, task group
+// or its tasks the node doesn't satisfy. Affinities with a negative weight are
+// not satisfied when the node matches them.
+func affinityWarnings(ctx Context, job *structs.Job, tg *structs.TaskGroup, node *structs.Node) []*structs.JobWarning {
+	affinities := append(slices.Clone(job.Affinities), tg.Affinities...)
+	for _, task := range tg.Tasks {
+		affinities = append(affinities, task.Affinities...)
+	}
+
+	var warnings []*structs.JobWarning
+	for _, affinity := range affinities {
+		if affinity.Weight == 0 || matchesAffinity(ctx, affinity, node) == (affinity.Weight > 0) {
+			continue
+		}
+		warnings = append(warnings, &structs.JobWarning{
+			Type:      structs.JobWarningTypeAffinity,
+			TaskGroup: tg.Name,
+			Message:   fmt.Sprintf("Allocations placed on nodes not satisfying affinity %s", affinity),
+		})
+	}
+	return warnings
+}
+
+// oversubscriptionWarnings returns a warning for each task of the task group
+// allowed to use more memory than it reserved on the node.
+func oversubscriptionWarnings(tg *structs.TaskGroup, option *RankedNode) []*structs.JobWarning {
+	var warnings []*structs.JobWarning
+	for _, task := range tg.Tasks {
+		resources, ok := option.TaskResources[task.Name]
+		if !ok || resources.Memory.MemoryMaxMB <= resources.Memory.MemoryMB {
+			continue
+		}
+		warnings = append(warnings, &structs.JobWarning{
+			Type:      structs.JobWarningTypeOversubscription,
+			TaskGroup: tg.Name,
+			Message: fmt.Sprintf("Memory oversubscription applied to task %q: it may use up to %d MB, above the %d MB reserved",
+				task.Name, resources.Memory.MemoryMaxMB, resources.Memory.MemoryMB),
+		})
+	}
+	return warnings
+}
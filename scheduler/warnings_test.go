@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/hashicorp/nomad/helper/uuid"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/shoenig/test/must"
+)
+
+func TestPlacementWarnings(t *testing.T) {
+	ci.Parallel(t)
+
+	_, ctx := testContext(t)
+	node := mock.Node()
+	job := mock.Job()
+	job.Affinities = []*structs.Affinity{
+		{LTarget: "${attr.kernel.name}", RTarget: "linux", Operand: "=", Weight: 50},
+		{LTarget: "${attr.kernel.name}", RTarget: "windows", Operand: "=", Weight: 50},
+	}
+	tg := job.TaskGroups[0]
+	tg.Affinities = []*structs.Affinity{
+		{LTarget: "${attr.kernel.name}", RTarget: "linux", Operand: "=", Weight: -50},
+		{LTarget: "${attr.kernel.name}", RTarget: "windows", Operand: "=", Weight: -50},
+	}
+
+	option := &RankedNode{
+		Node: node,
+		TaskResources: map[string]*structs.AllocatedTaskResources{
+			"web": {Memory: structs.AllocatedMemoryResources{MemoryMB: 256, MemoryMaxMB: 512}},
+		},
+	}
+	must.Eq(t, []*structs.JobWarning{
+		{
+			Type:      structs.JobWarningTypeAffinity,
+			TaskGroup: "web",
+			Message:   "Allocations placed on nodes not satisfying affinity ${attr.kernel.name} = windows 50",
+		},
+		{
+			Type:      structs.JobWarningTypeAffinity,
+			TaskGroup: "web",
+			Message:   "Allocations placed on nodes not satisfying affinity ${attr.kernel.name} = linux -50",
+		},
+		{
+			Type:      structs.JobWarningTypeOversubscription,
+			TaskGroup: "web",
+			Message:   `Memory oversubscription applied to task "web": it may use up to 512 MB, above the 256 MB reserved`,
+		},
+	}, placementWarnings(ctx, job, tg, option))
+
+	// Placements satisfying the affinities without oversubscription raise no
+	// warnings
+	job.Affinities = job.Affinities[:1]
+	tg.Affinities = tg.Affinities[1:]
+	option.TaskResources["web"].Memory.MemoryMaxMB = 0
+	must.SliceEmpty(t, placementWarnings(ctx, job, tg, option))
+}
+
+// TestServiceSched_Warnings asserts the warnings raised while placing the
+// allocations of a job are set on the evaluation.
+func TestServiceSched_Warnings(t *testing.T) {
+	ci.Parallel(t)
+
+	h := NewHarness(t)
+	node := mock.Node()
+	must.NoError(t, h.State.UpsertNode(structs.MsgTypeTestSetup, h.NextIndex(), node))
+
+	job := mock.Job()
+	job.TaskGroups[0].Count = 2
+	job.Affinities = []*structs.Affinity{
+		{LTarget: "${attr.kernel.name}", RTarget: "windows", Operand: "=", Weight: 50},
+	}
+	must.NoError(t, h.State.UpsertJob(structs.MsgTypeTestSetup, h.NextIndex(), nil, job))
+
+	eval := &structs.Evaluation{
+		Namespace:   structs.DefaultNamespace,
+		ID:          uuid.Generate(),
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+		Status:      structs.EvalStatusPending,
+	}
+	must.NoError(t, h.State.UpsertEvals(structs.MsgTypeTestSetup, h.NextIndex(), []*structs.Evaluation{eval}))
+	must.NoError(t, h.Process(NewServiceScheduler, eval))
+
+	must.SliceLen(t, 1, h.Evals)
+	must.Eq(t, []*structs.JobWarning{{
+		Type:      structs.JobWarningTypeAffinity,
+		TaskGroup: "web",
+		Message:   "Allocations placed on nodes not satisfying affinity ${attr.kernel.name} = windows 50",
+	}}, h.Evals[0].Warnings)
+}
@@ -37,6 +37,15 @@ export default class File extends Component {
   clientTimeout = 1000;
   serverTimeout = 5000;
 
+  didReceiveAttrs() {
+    super.didReceiveAttrs(...arguments);
+
+    // Clients in reverse tunnel mode are only reachable through the servers
+    if (this.get('allocation.node.reverseTunnel')) {
+      this.set('useServer', true);
+    }
+  }
+
   mode = 'head';
 
   @computed('stat.ContentType')
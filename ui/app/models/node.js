@@ -32,6 +32,7 @@ export default class Node extends Model {
   // Available from single response
   @attr('string') httpAddr;
   @attr('boolean') tlsEnabled;
+  @attr('boolean') reverseTunnel;
   @fragment('structured-attributes') attributes;
   @fragment('structured-attributes') meta;
   @fragment('resources') resources;